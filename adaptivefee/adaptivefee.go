@@ -0,0 +1,315 @@
+package adaptivefee
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Config of a Learner, zero values take the defaults of DefaultConfig
+type Config struct {
+	// TargetConfirmation is the time within which txs should confirm
+	TargetConfirmation time.Duration
+	// TargetSuccessRate is the fraction of txs that should confirm within TargetConfirmation
+	TargetSuccessRate float64
+	// Window is the number of recent outcomes kept per chain
+	Window int
+	// MinSamples is the number of outcomes required before adjusting the multiplier
+	MinSamples int
+	// Step is the relative increase of the multiplier when too many txs are late,
+	// the multiplier decreases by half a Step when txs confirm in less than half the target
+	Step float64
+	// Bounds of the multiplier applied to estimates
+	MinMultiplier float64
+	MaxMultiplier float64
+}
+
+// DefaultConfig targets 90% of txs confirming within 10 minutes
+var DefaultConfig = Config{
+	TargetConfirmation: 10 * time.Minute,
+	TargetSuccessRate:  0.9,
+	Window:             50,
+	MinSamples:         5,
+	Step:               0.1,
+	MinMultiplier:      0.5,
+	MaxMultiplier:      3,
+}
+
+func (config Config) withDefaults() Config {
+	if config.TargetConfirmation <= 0 {
+		config.TargetConfirmation = DefaultConfig.TargetConfirmation
+	}
+	if config.TargetSuccessRate <= 0 {
+		config.TargetSuccessRate = DefaultConfig.TargetSuccessRate
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultConfig.Window
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = DefaultConfig.MinSamples
+	}
+	if config.Step <= 0 {
+		config.Step = DefaultConfig.Step
+	}
+	if config.MinMultiplier <= 0 {
+		config.MinMultiplier = DefaultConfig.MinMultiplier
+	}
+	if config.MaxMultiplier <= 0 {
+		config.MaxMultiplier = DefaultConfig.MaxMultiplier
+	}
+	return config
+}
+
+// Outcome is the fee paid by a tx of the service and the time it took to confirm
+type Outcome struct {
+	Chain  xc.NativeAsset `json:"chain"`
+	TxHash xc.TxHash      `json:"tx_hash"`
+	// FeeRate is the fee paid per unit (gas price, sat/vB...), in the unit of the chain client EstimateGas
+	FeeRate     xc.AmountBlockchain `json:"fee_rate"`
+	SubmittedAt time.Time           `json:"submitted_at"`
+	// ConfirmedAt is zero for txs that never confirmed, e.g. dropped or replaced
+	ConfirmedAt time.Time `json:"confirmed_at"`
+}
+
+// Confirmed returns true if the tx confirmed
+func (outcome *Outcome) Confirmed() bool {
+	return !outcome.ConfirmedAt.IsZero()
+}
+
+// TimeToConfirm returns the time the tx took to confirm, 0 if it didn't
+func (outcome *Outcome) TimeToConfirm() time.Duration {
+	if !outcome.Confirmed() {
+		return 0
+	}
+	return outcome.ConfirmedAt.Sub(outcome.SubmittedAt)
+}
+
+// State is what a Learner learned for a chain
+type State struct {
+	Chain xc.NativeAsset `json:"chain"`
+	// Multiplier applied to fee estimates
+	Multiplier float64 `json:"multiplier"`
+	Samples    int     `json:"samples"`
+	// SuccessRate is the fraction of txs of the window confirmed within the target
+	SuccessRate float64 `json:"success_rate"`
+	// MedianConfirmation is the median time to confirm of the confirmed txs of the window
+	MedianConfirmation time.Duration `json:"median_confirmation"`
+	// MedianFeeRate is the median fee rate paid by the txs of the window
+	MedianFeeRate xc.AmountBlockchain `json:"median_fee_rate"`
+}
+
+type chainState struct {
+	multiplier float64
+	outcomes   []Outcome
+	// fresh is the number of outcomes recorded since the multiplier last changed
+	fresh int
+}
+
+// Learner learns per chain fee multipliers from the confirmation outcomes of past txs:
+// fees are raised while too few txs confirm within the target, and lowered while txs confirm well ahead of it
+type Learner struct {
+	Config Config
+
+	mu     sync.Mutex
+	chains map[xc.NativeAsset]*chainState
+}
+
+// NewLearner creates a new Learner
+func NewLearner(config Config) *Learner {
+	return &Learner{
+		Config: config.withDefaults(),
+		chains: map[xc.NativeAsset]*chainState{},
+	}
+}
+
+func (learner *Learner) chain(chain xc.NativeAsset) *chainState {
+	state, ok := learner.chains[chain]
+	if !ok {
+		state = &chainState{multiplier: 1}
+		learner.chains[chain] = state
+	}
+	return state
+}
+
+// Record records the outcome of a tx and adjusts the multiplier of its chain
+// The multiplier is adjusted from the outcomes recorded since it last changed, once there are MinSamples of them:
+// outcomes of txs priced with a former multiplier don't raise it again
+func (learner *Learner) Record(outcome Outcome) error {
+	if outcome.Chain == "" {
+		return errors.New("outcome chain is required")
+	}
+	if outcome.SubmittedAt.IsZero() {
+		return errors.New("outcome submission time is required")
+	}
+	learner.mu.Lock()
+	defer learner.mu.Unlock()
+	state := learner.chain(outcome.Chain)
+	state.outcomes = append(state.outcomes, outcome)
+	if len(state.outcomes) > learner.Config.Window {
+		state.outcomes = state.outcomes[len(state.outcomes)-learner.Config.Window:]
+	}
+	state.fresh++
+	if state.fresh > len(state.outcomes) {
+		state.fresh = len(state.outcomes)
+	}
+	if state.fresh < learner.Config.MinSamples {
+		return nil
+	}
+
+	multiplier := state.multiplier
+	successRate, median := learner.stats(state.outcomes[len(state.outcomes)-state.fresh:])
+	switch {
+	case successRate < learner.Config.TargetSuccessRate:
+		multiplier *= 1 + learner.Config.Step
+	case median < learner.Config.TargetConfirmation/2:
+		multiplier *= 1 - learner.Config.Step/2
+	}
+	if multiplier < learner.Config.MinMultiplier {
+		multiplier = learner.Config.MinMultiplier
+	}
+	if multiplier > learner.Config.MaxMultiplier {
+		multiplier = learner.Config.MaxMultiplier
+	}
+	if multiplier != state.multiplier {
+		state.multiplier = multiplier
+		state.fresh = 0
+	}
+	return nil
+}
+
+func (learner *Learner) stats(outcomes []Outcome) (float64, time.Duration) {
+	if len(outcomes) == 0 {
+		return 0, 0
+	}
+	onTime := 0
+	durations := []time.Duration{}
+	for i := range outcomes {
+		if !outcomes[i].Confirmed() {
+			continue
+		}
+		duration := outcomes[i].TimeToConfirm()
+		durations = append(durations, duration)
+		if duration <= learner.Config.TargetConfirmation {
+			onTime++
+		}
+	}
+	median := time.Duration(0)
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		median = durations[len(durations)/2]
+	}
+	return float64(onTime) / float64(len(outcomes)), median
+}
+
+// Multiplier returns the multiplier to apply to the fee estimates of chain, 1 until enough outcomes are recorded
+func (learner *Learner) Multiplier(chain xc.NativeAsset) float64 {
+	learner.mu.Lock()
+	defer learner.mu.Unlock()
+	if state, ok := learner.chains[chain]; ok {
+		return state.multiplier
+	}
+	return 1
+}
+
+// State returns what was learned for chain
+func (learner *Learner) State(chain xc.NativeAsset) State {
+	learner.mu.Lock()
+	defer learner.mu.Unlock()
+	state, ok := learner.chains[chain]
+	if !ok || len(state.outcomes) == 0 {
+		multiplier := 1.0
+		if ok {
+			multiplier = state.multiplier
+		}
+		return State{Chain: chain, Multiplier: multiplier, MedianFeeRate: xc.NewAmountBlockchainFromUint64(0)}
+	}
+	successRate, median := learner.stats(state.outcomes)
+	feeRates := make([]xc.AmountBlockchain, len(state.outcomes))
+	for i := range state.outcomes {
+		feeRates[i] = state.outcomes[i].FeeRate
+	}
+	sort.Slice(feeRates, func(i, j int) bool { return feeRates[i].Cmp(&feeRates[j]) < 0 })
+	return State{
+		Chain:              chain,
+		Multiplier:         state.multiplier,
+		Samples:            len(state.outcomes),
+		SuccessRate:        successRate,
+		MedianConfirmation: median,
+		MedianFeeRate:      feeRates[len(feeRates)/2],
+	}
+}
+
+// Restore sets the multiplier of chain, e.g. from a State persisted before a restart
+func (learner *Learner) Restore(chain xc.NativeAsset, multiplier float64) {
+	learner.mu.Lock()
+	defer learner.mu.Unlock()
+	state := learner.chain(chain)
+	state.multiplier = multiplier
+	state.fresh = 0
+}
+
+// Apply returns estimate adjusted by the multiplier of chain
+func (learner *Learner) Apply(chain xc.NativeAsset, estimate xc.AmountBlockchain) xc.AmountBlockchain {
+	return multiply(estimate, learner.Multiplier(chain))
+}
+
+func multiply(amount xc.AmountBlockchain, multiplier float64) xc.AmountBlockchain {
+	product := new(big.Float).Mul(new(big.Float).SetInt(amount.Int()), big.NewFloat(multiplier))
+	result, _ := product.Int(nil)
+	return xc.AmountBlockchain(*result)
+}
+
+// Estimator is a GasEstimator adjusting the estimates of a chain client with what a Learner learned
+type Estimator struct {
+	xc.GasEstimator
+	Chain   xc.NativeAsset
+	Learner *Learner
+	// Floor of the adjusted estimates, e.g. the floor of the chain client, which a multiplier below 1 would undercut
+	Floor xc.AmountBlockchain
+}
+
+var _ xc.GasEstimator = &Estimator{}
+
+// NewEstimator creates a new Estimator of chain
+func NewEstimator(estimator xc.GasEstimator, chain xc.NativeAsset, learner *Learner) *Estimator {
+	return &Estimator{
+		GasEstimator: estimator,
+		Chain:        chain,
+		Learner:      learner,
+	}
+}
+
+// NewAssetEstimator creates a new Estimator of the chain of asset, floored like its client, see FeeEstimateFloor
+func NewAssetEstimator(estimator xc.GasEstimator, asset xc.ITask, learner *Learner) *Estimator {
+	native := asset.GetNativeAsset()
+	adjusted := NewEstimator(estimator, native.NativeAsset, learner)
+	adjusted.Floor = FeeEstimateFloor(native)
+	return adjusted
+}
+
+// FeeEstimateFloor returns the floor applied by the client of asset to its estimates, in the unit of EstimateGas:
+// min_fee_rate for UTXO chains, and min_gas_price for others
+func FeeEstimateFloor(asset *xc.NativeAssetConfig) xc.AmountBlockchain {
+	switch xc.Driver(asset.Driver) {
+	case xc.DriverBitcoin:
+		return xc.NewAmountBlockchainFromUint64(uint64(math.Ceil(asset.MinFeeRate)))
+	case xc.DriverCosmos, xc.DriverCosmosEvmos:
+		return xc.NewAmountBlockchainToMaskFloat64(asset.MinGasPrice)
+	}
+	return xc.NewAmountBlockchainFromUint64(uint64(asset.MinGasPrice))
+}
+
+// EstimateGas returns the estimate of the chain client adjusted by the learned multiplier, and floored by Floor
+func (estimator *Estimator) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	estimate, err := estimator.GasEstimator.EstimateGas(ctx)
+	if err != nil {
+		return estimate, err
+	}
+	return estimator.Learner.Apply(estimator.Chain, estimate).ApplyFloor(estimator.Floor), nil
+}
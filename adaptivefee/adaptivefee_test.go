@@ -0,0 +1,158 @@
+package adaptivefee
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestAdaptiveFeeTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+var submittedAt = time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+
+func outcome(chain xc.NativeAsset, feeRate uint64, timeToConfirm time.Duration) Outcome {
+	confirmedAt := time.Time{}
+	if timeToConfirm > 0 {
+		confirmedAt = submittedAt.Add(timeToConfirm)
+	}
+	return Outcome{
+		Chain:       chain,
+		FeeRate:     xc.NewAmountBlockchainFromUint64(feeRate),
+		SubmittedAt: submittedAt,
+		ConfirmedAt: confirmedAt,
+	}
+}
+
+type fakeEstimator struct {
+	estimate xc.AmountBlockchain
+	err      error
+}
+
+func (estimator *fakeEstimator) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	return estimator.estimate, estimator.err
+}
+
+func (estimator *fakeEstimator) RegisterEstimateGasCallback(fn xc.EstimateGasFunc) {
+}
+
+func (s *CrosschainTestSuite) TestNewLearner() {
+	require := s.Require()
+	learner := NewLearner(Config{TargetConfirmation: time.Minute})
+	require.Equal(time.Minute, learner.Config.TargetConfirmation)
+	require.Equal(DefaultConfig.Window, learner.Config.Window)
+	require.Equal(DefaultConfig.MaxMultiplier, learner.Config.MaxMultiplier)
+	require.Equal(1.0, learner.Multiplier(xc.BTC))
+	require.Equal(State{Chain: xc.BTC, Multiplier: 1, MedianFeeRate: xc.NewAmountBlockchainFromUint64(0)}, learner.State(xc.BTC))
+
+	require.ErrorContains(learner.Record(Outcome{SubmittedAt: submittedAt}), "chain is required")
+	require.ErrorContains(learner.Record(Outcome{Chain: xc.BTC}), "submission time is required")
+}
+
+func (s *CrosschainTestSuite) TestLearnerRaisesLateFees() {
+	require := s.Require()
+	learner := NewLearner(Config{TargetConfirmation: 10 * time.Minute, MinSamples: 3, Step: 0.1})
+
+	// not enough samples
+	require.NoError(learner.Record(outcome(xc.BTC, 10, 30*time.Minute)))
+	require.NoError(learner.Record(outcome(xc.BTC, 10, 0)))
+	require.Equal(1.0, learner.Multiplier(xc.BTC))
+
+	require.NoError(learner.Record(outcome(xc.BTC, 10, 5*time.Minute)))
+	require.InDelta(1.1, learner.Multiplier(xc.BTC), 1e-9)
+
+	// the late outcomes priced before the raise don't raise it again
+	require.NoError(learner.Record(outcome(xc.BTC, 11, 8*time.Minute)))
+	require.NoError(learner.Record(outcome(xc.BTC, 11, 0)))
+	require.InDelta(1.1, learner.Multiplier(xc.BTC), 1e-9)
+	require.NoError(learner.Record(outcome(xc.BTC, 11, 20*time.Minute)))
+	require.InDelta(1.21, learner.Multiplier(xc.BTC), 1e-9)
+
+	state := learner.State(xc.BTC)
+	require.Equal(6, state.Samples)
+	require.InDelta(1.0/3, state.SuccessRate, 1e-9)
+	require.Equal(20*time.Minute, state.MedianConfirmation)
+	require.Equal("11", state.MedianFeeRate.String())
+
+	// txs priced with the raised multiplier confirming on time keep it
+	for i := 0; i < 3; i++ {
+		require.NoError(learner.Record(outcome(xc.BTC, 12, 8*time.Minute)))
+	}
+	require.InDelta(1.21, learner.Multiplier(xc.BTC), 1e-9)
+
+	// other chains are unaffected
+	require.Equal(1.0, learner.Multiplier(xc.LTC))
+}
+
+func (s *CrosschainTestSuite) TestLearnerLowersFastFees() {
+	require := s.Require()
+	learner := NewLearner(Config{TargetConfirmation: 10 * time.Minute, MinSamples: 1, Step: 0.2, MinMultiplier: 0.85})
+	require.NoError(learner.Record(outcome(xc.ETH, 100, time.Minute)))
+	require.InDelta(0.9, learner.Multiplier(xc.ETH), 1e-9)
+	// bounded
+	require.NoError(learner.Record(outcome(xc.ETH, 90, time.Minute)))
+	require.InDelta(0.85, learner.Multiplier(xc.ETH), 1e-9)
+
+	// on time but not well ahead of the target, the multiplier is kept
+	learner = NewLearner(Config{TargetConfirmation: 10 * time.Minute, MinSamples: 1})
+	require.NoError(learner.Record(outcome(xc.ETH, 100, 7*time.Minute)))
+	require.Equal(1.0, learner.Multiplier(xc.ETH))
+}
+
+func (s *CrosschainTestSuite) TestLearnerWindow() {
+	require := s.Require()
+	learner := NewLearner(Config{TargetConfirmation: 10 * time.Minute, Window: 2, MinSamples: 2, MaxMultiplier: 1.5})
+	for i := 0; i < 10; i++ {
+		require.NoError(learner.Record(outcome(xc.BTC, 10, 0)))
+	}
+	require.Equal(1.5, learner.Multiplier(xc.BTC))
+	require.Equal(2, learner.State(xc.BTC).Samples)
+
+	// the dropped txs leave the window
+	require.NoError(learner.Record(outcome(xc.BTC, 15, time.Minute)))
+	require.NoError(learner.Record(outcome(xc.BTC, 15, time.Minute)))
+	require.Equal(1.0, learner.State(xc.BTC).SuccessRate)
+	require.Less(learner.Multiplier(xc.BTC), 1.5)
+
+	learner.Restore(xc.BTC, 2)
+	require.Equal(2.0, learner.Multiplier(xc.BTC))
+}
+
+func (s *CrosschainTestSuite) TestEstimator() {
+	require := s.Require()
+	learner := NewLearner(Config{})
+	learner.Restore(xc.ETH, 1.25)
+	estimator := NewEstimator(&fakeEstimator{estimate: xc.NewAmountBlockchainFromUint64(20_000_000_000)}, xc.ETH, learner)
+
+	estimate, err := estimator.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("25000000000", estimate.String())
+	require.Equal("40", learner.Apply(xc.BTC, xc.NewAmountBlockchainFromUint64(40)).String())
+
+	// lowered estimates are floored like the estimates of the client
+	learner.Restore(xc.ETH, 0.5)
+	asset := &xc.NativeAssetConfig{NativeAsset: xc.ETH, Driver: string(xc.DriverEVM), MinGasPrice: 15_000_000_000}
+	estimator = NewAssetEstimator(&fakeEstimator{estimate: xc.NewAmountBlockchainFromUint64(20_000_000_000)}, asset, learner)
+	estimate, err = estimator.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("15000000000", estimate.String())
+	require.Equal("3", FeeEstimateFloor(&xc.NativeAssetConfig{Driver: string(xc.DriverBitcoin), MinFeeRate: 2.5}).String())
+
+	estimator = NewEstimator(&fakeEstimator{err: errors.New("rpc error")}, xc.ETH, learner)
+	_, err = estimator.EstimateGas(s.Ctx)
+	require.ErrorContains(err, "rpc error")
+}
@@ -12,6 +12,12 @@ type AddressBuilder interface {
 	GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]PossibleAddress, error)
 }
 
+// AddressValidator is implemented by the AddressBuilder of chains checking the format and checksum of addresses,
+// e.g. of destinations before sending to them
+type AddressValidator interface {
+	ValidateAddress(address Address) error
+}
+
 // AddressType represents the type of an address, for discovery purposes
 type AddressType string
 
@@ -24,6 +30,8 @@ const (
 	AddressTypeP2TR      AddressType = AddressType("P2TR")
 	AddressTypeETHKeccak AddressType = AddressType("ETHKeccak")
 	AddressTypeDefault   AddressType = AddressType("Default")
+	// AddressTypeValoper is the validator operator form of a Cosmos account address, e.g. cosmosvaloper1...
+	AddressTypeValoper AddressType = AddressType("Valoper")
 )
 
 // PossibleAddress is a pair of (Address, AddressType) used to derive all possible addresses from a public key
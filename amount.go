@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"strings"
 
 	"github.com/shopspring/decimal"
 )
@@ -53,35 +54,40 @@ func (amount *AmountBlockchain) Cmp(other *AmountBlockchain) int {
 	return amount.Int().Cmp(other.Int())
 }
 
-// Use the underlying big.Int.Add()
+// Add returns amount + x, without modifying amount or x
 func (amount *AmountBlockchain) Add(x *AmountBlockchain) AmountBlockchain {
-	sum := *amount
-	return AmountBlockchain(*sum.Int().Add(sum.Int(), x.Int()))
+	return AmountBlockchain(*new(big.Int).Add(amount.Int(), x.Int()))
 }
 
-// Use the underlying big.Int.Sub()
+// Sub returns amount - x, without modifying amount or x
 func (amount *AmountBlockchain) Sub(x *AmountBlockchain) AmountBlockchain {
-	diff := *amount
-	return AmountBlockchain(*diff.Int().Sub(diff.Int(), x.Int()))
+	return AmountBlockchain(*new(big.Int).Sub(amount.Int(), x.Int()))
 }
 
-// Use the underlying big.Int.Mul()
+// Mul returns amount * x, without modifying amount or x
 func (amount *AmountBlockchain) Mul(x *AmountBlockchain) AmountBlockchain {
-	prod := *amount
-	return AmountBlockchain(*prod.Int().Mul(prod.Int(), x.Int()))
+	return AmountBlockchain(*new(big.Int).Mul(amount.Int(), x.Int()))
 }
 
-// Use the underlying big.Int.Div()
+// Div returns amount / x rounded towards -inf (big.Int.Div), without modifying amount or x
 func (amount *AmountBlockchain) Div(x *AmountBlockchain) AmountBlockchain {
-	quot := *amount
-	return AmountBlockchain(*quot.Int().Div(quot.Int(), x.Int()))
+	return AmountBlockchain(*new(big.Int).Div(amount.Int(), x.Int()))
 }
 
+// ApplyFloor returns amount, or floor if amount is lower
+func (amount AmountBlockchain) ApplyFloor(floor AmountBlockchain) AmountBlockchain {
+	if amount.Cmp(&floor) < 0 {
+		return floor
+	}
+	return amount
+}
+
+// Abs returns |amount|, without modifying amount
 func (amount *AmountBlockchain) Abs() AmountBlockchain {
-	abs := *amount
-	return AmountBlockchain(*abs.Int().Abs(abs.Int()))
+	return AmountBlockchain(*new(big.Int).Abs(amount.Int()))
 }
 
+// ToHuman converts an amount in the smallest unit of an asset into the amount in units of the asset, given its decimals
 func (amount *AmountBlockchain) ToHuman(decimals int32) AmountHumanReadable {
 	dec := decimal.NewFromBigInt(amount.Int(), -decimals)
 	return AmountHumanReadable(dec)
@@ -109,15 +115,41 @@ func NewAmountBlockchainFromStr(str string) AmountBlockchain {
 	return AmountBlockchain(*bigInt)
 }
 
+// ParseAmountBlockchain parses a base 10 integer string into an AmountBlockchain, e.g. "1000000"
+func ParseAmountBlockchain(str string) (AmountBlockchain, error) {
+	bigInt, ok := new(big.Int).SetString(strings.TrimSpace(str), 10)
+	if !ok {
+		return AmountBlockchain{}, fmt.Errorf("not a valid integer amount: '%s'", str)
+	}
+	return AmountBlockchain(*bigInt), nil
+}
+
 // NewAmountHumanReadableFromStr creates a new AmountHumanReadable from a string
 func NewAmountHumanReadableFromStr(str string) AmountHumanReadable {
 	decimal, _ := decimal.NewFromString(str)
 	return AmountHumanReadable(decimal)
 }
 
+// ParseAmountHumanReadable parses a decimal string into an AmountHumanReadable, e.g. "1.5"
+func ParseAmountHumanReadable(str string) (AmountHumanReadable, error) {
+	dec, err := decimal.NewFromString(strings.TrimSpace(str))
+	if err != nil {
+		return AmountHumanReadable{}, fmt.Errorf("not a valid decimal amount: '%s'", str)
+	}
+	return AmountHumanReadable(dec), nil
+}
+
+// NewAmountHumanReadableFromFloat64 creates a new AmountHumanReadable from the shortest decimal representing a float64,
+// e.g. 0.1 and not 0.1000000000000000055511151231257827
+func NewAmountHumanReadableFromFloat64(f64 float64) AmountHumanReadable {
+	return AmountHumanReadable(decimal.NewFromFloat(f64))
+}
+
+// ToBlockchain converts an amount in units of an asset into the amount in the smallest unit of the asset, given its decimals
+// The fraction of the smallest unit, beyond decimals, is truncated
 func (amount AmountHumanReadable) ToBlockchain(decimals int32) AmountBlockchain {
-	factor := decimal.NewFromInt32(10).Pow(decimal.NewFromInt32(decimals))
-	raised := ((decimal.Decimal)(amount)).Mul(factor)
+	// shifting the exponent is exact and avoids computing 10^decimals
+	raised := ((decimal.Decimal)(amount)).Shift(decimals)
 	return AmountBlockchain(*raised.BigInt())
 }
 
@@ -129,7 +161,74 @@ func (amount AmountHumanReadable) Div(x AmountHumanReadable) AmountHumanReadable
 	return AmountHumanReadable(decimal.Decimal(amount).Div(decimal.Decimal(x)))
 }
 
-func (b *AmountBlockchain) MarshalJSON() ([]byte, error) {
+// AmountFormat configures the display of an AmountHumanReadable
+type AmountFormat struct {
+	// ThousandsSeparator groups the digits of the integer part by 3, no grouping if empty
+	ThousandsSeparator string
+	// DecimalSeparator separates the fraction, "." if empty
+	DecimalSeparator string
+	// SignificantDigits rounds the amount to a number of significant digits, if > 0
+	SignificantDigits int32
+	// Fixed rounds the amount to FractionDigits, padding with zeros
+	// Otherwise trailing zeros of the fraction are trimmed
+	Fixed          bool
+	FractionDigits int32
+}
+
+// Common amount formats
+var (
+	AmountFormatEN = AmountFormat{ThousandsSeparator: ",", DecimalSeparator: "."}
+	AmountFormatDE = AmountFormat{ThousandsSeparator: ".", DecimalSeparator: ","}
+	AmountFormatFR = AmountFormat{ThousandsSeparator: "\u202f", DecimalSeparator: ","}
+	AmountFormatCH = AmountFormat{ThousandsSeparator: "'", DecimalSeparator: "."}
+)
+
+// Format formats the amount for display, rounding half away from zero
+func (amount AmountHumanReadable) Format(format AmountFormat) string {
+	dec := decimal.Decimal(amount)
+	if format.SignificantDigits > 0 && !dec.IsZero() {
+		// position of the most significant digit, e.g. 2 for 123.4 and -2 for 0.012
+		digits := int32(len(dec.Coefficient().String())) - 1
+		if dec.Sign() < 0 {
+			digits--
+		}
+		position := digits + dec.Exponent()
+		dec = dec.Round(format.SignificantDigits - 1 - position)
+	}
+	var str string
+	if format.Fixed {
+		str = dec.StringFixed(format.FractionDigits)
+	} else {
+		str = dec.String()
+	}
+
+	sign := ""
+	if strings.HasPrefix(str, "-") {
+		sign, str = "-", str[1:]
+	}
+	integer, fraction, _ := strings.Cut(str, ".")
+	if format.ThousandsSeparator != "" && len(integer) > 3 {
+		var grouped strings.Builder
+		for i, digit := range integer {
+			if i > 0 && (len(integer)-i)%3 == 0 {
+				grouped.WriteString(format.ThousandsSeparator)
+			}
+			grouped.WriteRune(digit)
+		}
+		integer = grouped.String()
+	}
+	if fraction == "" {
+		return sign + integer
+	}
+	separator := format.DecimalSeparator
+	if separator == "" {
+		separator = "."
+	}
+	return sign + integer + separator + fraction
+}
+
+// MarshalJSON marshals the amount as a JSON number, also for amounts that aren't addressable such as map values
+func (b AmountBlockchain) MarshalJSON() ([]byte, error) {
 	return []byte(b.String()), nil
 }
 
@@ -138,7 +237,8 @@ func (b *AmountBlockchain) UnmarshalJSON(p []byte) error {
 		return nil
 	}
 	var z big.Int
-	_, ok := z.SetString(string(p), 10)
+	// also quoted, as JSON APIs quote large integers
+	_, ok := z.SetString(strings.Trim(string(p), `"`), 10)
 	if !ok {
 		return fmt.Errorf("not a valid big integer: %s", p)
 	}
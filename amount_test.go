@@ -1,6 +1,8 @@
 package crosschain
 
 import (
+	"testing"
+
 	"github.com/shopspring/decimal"
 )
 
@@ -49,3 +51,165 @@ func (s *CrosschainTestSuite) TestNewAmountHumanReadableFromStr() {
 	require.NotNil(amount)
 	require.Equal(amount.String(), "0")
 }
+
+func (s *CrosschainTestSuite) TestParseAmount() {
+	require := s.Require()
+	amount, err := ParseAmountBlockchain("1000000000000000000000")
+	require.NoError(err)
+	require.Equal("1000000000000000000000", amount.String())
+	_, err = ParseAmountBlockchain("1.5")
+	require.EqualError(err, "not a valid integer amount: '1.5'")
+	_, err = ParseAmountBlockchain("")
+	require.Error(err)
+
+	human, err := ParseAmountHumanReadable(" 1.5 ")
+	require.NoError(err)
+	require.Equal("1.5", human.String())
+	_, err = ParseAmountHumanReadable("invalid")
+	require.EqualError(err, "not a valid decimal amount: 'invalid'")
+
+	require.Equal("0.1", NewAmountHumanReadableFromFloat64(0.1).String())
+	require.Equal("-1234.5678", NewAmountHumanReadableFromFloat64(-1234.5678).String())
+}
+
+func (s *CrosschainTestSuite) TestAmountConversion() {
+	require := s.Require()
+	amount := NewAmountHumanReadableFromStr("1.5").ToBlockchain(18)
+	require.Equal("1500000000000000000", amount.String())
+	require.Equal("1.5", amount.ToHuman(18).String())
+	require.Equal("0.0000000000015", amount.ToHuman(30).String())
+
+	// the fraction of the smallest unit is truncated
+	require.Equal("1234567", NewAmountHumanReadableFromStr("1.2345678").ToBlockchain(6).String())
+	require.Equal("1", NewAmountHumanReadableFromFloat64(0.1).ToBlockchain(1).String())
+	require.Equal("-15", NewAmountHumanReadableFromStr("-1.5").ToBlockchain(1).String())
+}
+
+func (s *CrosschainTestSuite) TestAmountBlockchainArithmetic() {
+	require := s.Require()
+	a := NewAmountBlockchainFromStr("1000000000000000000000000000000")
+	b := NewAmountBlockchainFromUint64(5)
+
+	sum := a.Add(&b)
+	require.Equal("1000000000000000000000000000005", sum.String())
+	diff := a.Sub(&b)
+	require.Equal("999999999999999999999999999995", diff.String())
+	prod := a.Mul(&b)
+	require.Equal("5000000000000000000000000000000", prod.String())
+	quot := a.Div(&b)
+	require.Equal("200000000000000000000000000000", quot.String())
+	negative := b.Sub(&a)
+	abs := negative.Abs()
+	require.Equal("999999999999999999999999999995", abs.String())
+	require.Equal("-999999999999999999999999999995", negative.String())
+
+	// the operands are unchanged
+	require.Equal("1000000000000000000000000000000", a.String())
+	require.Equal("5", b.String())
+	require.Equal(1, a.Cmp(&b))
+	require.Equal(-1, b.Cmp(&a))
+	require.Equal(0, diff.Cmp(&abs))
+
+	// the zero value is 0
+	zero := AmountBlockchain{}
+	sum = zero.Add(&b)
+	require.Equal("5", sum.String())
+}
+
+func (s *CrosschainTestSuite) TestAmountHumanReadableFormat() {
+	require := s.Require()
+	vectors := []struct {
+		amount string
+		format AmountFormat
+		str    string
+	}{
+		{"1234567.891", AmountFormat{}, "1234567.891"},
+		{"1234567.891", AmountFormatEN, "1,234,567.891"},
+		{"1234567.891", AmountFormatDE, "1.234.567,891"},
+		{"1234567.891", AmountFormatFR, "1\u202f234\u202f567,891"},
+		{"1234567.891", AmountFormatCH, "1'234'567.891"},
+		{"-1234567.891", AmountFormatEN, "-1,234,567.891"},
+		{"123", AmountFormatEN, "123"},
+		{"1000", AmountFormatEN, "1,000"},
+		{"-100", AmountFormatEN, "-100"},
+		{"0", AmountFormatEN, "0"},
+		{"1.5000", AmountFormat{}, "1.5"},
+		// fixed fraction digits
+		{"1.5", AmountFormat{Fixed: true, FractionDigits: 2}, "1.50"},
+		{"1.555", AmountFormat{Fixed: true, FractionDigits: 2}, "1.56"},
+		{"-1.555", AmountFormat{Fixed: true, FractionDigits: 2}, "-1.56"},
+		{"1234.5", AmountFormat{ThousandsSeparator: ",", Fixed: true}, "1,235"},
+		{"0", AmountFormat{Fixed: true, FractionDigits: 3}, "0.000"},
+		// significant digits
+		{"123.456", AmountFormat{SignificantDigits: 4}, "123.5"},
+		{"0.00123456", AmountFormat{SignificantDigits: 3}, "0.00123"},
+		{"-0.00123456", AmountFormat{SignificantDigits: 3}, "-0.00123"},
+		{"123456", AmountFormat{SignificantDigits: 2, ThousandsSeparator: ","}, "120,000"},
+		{"1.0000001", AmountFormat{SignificantDigits: 3}, "1"},
+		{"0", AmountFormat{SignificantDigits: 3}, "0"},
+		{"1.0000001", AmountFormat{SignificantDigits: 3, Fixed: true, FractionDigits: 2}, "1.00"},
+		{"0.000001234", AmountFormat{SignificantDigits: 2, Fixed: true, FractionDigits: 8, DecimalSeparator: ","}, "0,00000120"},
+	}
+	for _, v := range vectors {
+		amount := NewAmountHumanReadableFromStr(v.amount)
+		require.Equal(v.str, amount.Format(v.format), v.amount)
+	}
+	// formatting blockchain amounts with many decimals is exact
+	wei := NewAmountBlockchainFromStr("123456789012345678901234567")
+	require.Equal("123,456,789.012345678901234567", wei.ToHuman(18).Format(AmountFormatEN))
+}
+
+func (s *CrosschainTestSuite) TestAmountBlockchainApplyFloor() {
+	require := s.Require()
+	floor := NewAmountBlockchainFromUint64(10)
+	require.Equal("10", NewAmountBlockchainFromUint64(0).ApplyFloor(floor).String())
+	require.Equal("10", NewAmountBlockchainFromUint64(10).ApplyFloor(floor).String())
+	require.Equal("11", NewAmountBlockchainFromUint64(11).ApplyFloor(floor).String())
+	require.Equal("0", NewAmountBlockchainFromUint64(0).ApplyFloor(NewAmountBlockchainFromUint64(0)).String())
+}
+
+func (s *CrosschainTestSuite) TestAmountAllocationBudget() {
+	require := s.Require()
+	a := NewAmountBlockchainFromUint64(1_000_000)
+	b := NewAmountBlockchainFromUint64(300)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		sum := a.Add(&b)
+		_ = sum.Sub(&b)
+		_ = a.Cmp(&b)
+	})
+	require.LessOrEqual(allocs, float64(4))
+
+	human := NewAmountHumanReadableFromStr("1.234567890123456789")
+	allocs = testing.AllocsPerRun(100, func() {
+		_ = human.ToBlockchain(18)
+	})
+	require.LessOrEqual(allocs, float64(15))
+}
+
+func BenchmarkAmountBlockchainMath(b *testing.B) {
+	x := NewAmountBlockchainFromUint64(1_000_000)
+	y := NewAmountBlockchainFromUint64(300)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sum := x.Add(&y)
+		_ = sum.Sub(&y)
+		_ = x.Cmp(&y)
+	}
+}
+
+func BenchmarkAmountBlockchainToHuman(b *testing.B) {
+	amount := NewAmountBlockchainFromStr("1234567890123456789")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = amount.ToHuman(18).String()
+	}
+}
+
+func BenchmarkAmountHumanToBlockchain(b *testing.B) {
+	amount := NewAmountHumanReadableFromStr("1.234567890123456789")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = amount.ToBlockchain(18).String()
+	}
+}
@@ -3,6 +3,9 @@ package crosschain
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/cosmos/btcutil/bech32"
 )
 
 // Asset is an asset on a blockchain. It can be a token or native asset.
@@ -18,43 +21,12 @@ const (
 	AssetTypeTask   = AssetType("task")
 )
 
-// AssetType returns the type of an Asset
+// AssetType returns the type of an Asset: native for the NativeAsset of a registered chain, see RegisterChain
 func (asset Asset) AssetType() AssetType {
-	switch native := NativeAsset(asset); native {
-	case BCH, BTC, DOGE, LTC:
-		return AssetTypeNative
-	case ACA,
-		APTOS,
-		ArbETH,
-		ATOM,
-		AurETH,
-		AVAX,
-		BNB,
-		CELO,
-		CHZ,
-		CHZ2,
-		ETC,
-		ETH,
-		ETHW,
-		FTM,
-		INJ,
-		KAR,
-		KLAY,
-		LUNA,
-		LUNC,
-		MATIC,
-		XDC,
-		OAS,
-		OasisROSE,
-		OptETH,
-		ROSE,
-		SOL,
-		SUI,
-		XPLA:
+	if _, ok := LookupChain(NativeAsset(asset)); ok {
 		return AssetTypeNative
-	default:
-		return AssetTypeToken
 	}
+	return AssetTypeToken
 }
 
 // ChainType is the type of a chain
@@ -73,45 +45,16 @@ const (
 	K256    = SignatureType("k256")
 	Ed255   = SignatureType("ed255")
 	Schnorr = SignatureType("schnorr")
+	Sr25519 = SignatureType("sr25519")
+	Stark   = SignatureType("stark")
 )
 
-// ChainType returns the type of a chain, represented as its NativeAsset
+// ChainType returns the type of a chain, represented as its NativeAsset, unknown if it isn't registered
 func (native NativeAsset) ChainType() ChainType {
-	switch native {
-	case BCH, BTC, DOGE, LTC:
-		return ChainTypeUTXO
-	case ACA,
-		APTOS,
-		ArbETH,
-		ATOM,
-		AurETH,
-		AVAX,
-		BNB,
-		CELO,
-		CHZ,
-		CHZ2,
-		ETC,
-		ETH,
-		ETHW,
-		FTM,
-		INJ,
-		KAR,
-		KLAY,
-		LUNA,
-		LUNC,
-		MATIC,
-		XDC,
-		OAS,
-		OasisROSE,
-		OptETH,
-		ROSE,
-		SOL,
-		SUI,
-		XPLA:
-		return ChainTypeAccount
-	default:
-		return ChainTypeUnknown
+	if info, ok := LookupChain(native); ok {
+		return info.ChainType
 	}
+	return ChainTypeUnknown
 }
 
 // NativeAsset is an asset on a blockchain used to pay gas fees.
@@ -121,13 +64,17 @@ type NativeAsset Asset
 // List of supported NativeAsset
 const (
 	// UTXO
-	BCH  = NativeAsset("BCH")  // Bitcoin Cash
-	BTC  = NativeAsset("BTC")  // Bitcoin
-	DOGE = NativeAsset("DOGE") // Dogecoin
-	LTC  = NativeAsset("LTC")  // Litecoin
+	BCH   = NativeAsset("BCH")   // Bitcoin Cash
+	BTC   = NativeAsset("BTC")   // Bitcoin
+	DASH  = NativeAsset("DASH")  // Dash
+	DOGE  = NativeAsset("DOGE")  // Dogecoin
+	LTC   = NativeAsset("LTC")   // Litecoin
+	PAVAX = NativeAsset("PAVAX") // Avalanche P-Chain
+	XAVAX = NativeAsset("XAVAX") // Avalanche X-Chain
 
 	// Account-based
 	ACA       = NativeAsset("ACA")       // Acala
+	ALGO      = NativeAsset("ALGO")      // Algorand
 	APTOS     = NativeAsset("APTOS")     // APTOS
 	ArbETH    = NativeAsset("ArbETH")    // Arbitrum
 	ATOM      = NativeAsset("ATOM")      // Cosmos
@@ -137,6 +84,7 @@ const (
 	CELO      = NativeAsset("CELO")      // Celo
 	CHZ       = NativeAsset("CHZ")       // Chiliz
 	CHZ2      = NativeAsset("CHZ2")      // Chiliz 2.0
+	DOT       = NativeAsset("DOT")       // Polkadot
 	ETC       = NativeAsset("ETC")       // Ethereum Classic
 	ETH       = NativeAsset("ETH")       // Ethereum
 	ETHW      = NativeAsset("ETHW")      // Ethereum PoW
@@ -145,15 +93,22 @@ const (
 	LUNA      = NativeAsset("LUNA")      // Terra V2
 	LUNC      = NativeAsset("LUNC")      // Terra Classic
 	KAR       = NativeAsset("KAR")       // Karura
+	KSM       = NativeAsset("KSM")       // Kusama
 	KLAY      = NativeAsset("KLAY")      // Klaytn
 	XDC       = NativeAsset("XDC")       // XinFin
 	MATIC     = NativeAsset("MATIC")     // Polygon
+	NEAR      = NativeAsset("NEAR")      // NEAR
 	OAS       = NativeAsset("OAS")       // Oasys (not Oasis!)
 	OasisROSE = NativeAsset("OasisROSE") // Rose (Oasis = main chain)
 	OptETH    = NativeAsset("OptETH")    // Optimism
+	OSMO      = NativeAsset("OSMO")      // Osmosis
 	ROSE      = NativeAsset("ROSE")      // Rose (Oasis Emerald parachain)
 	SOL       = NativeAsset("SOL")       // Solana
+	STRK      = NativeAsset("STRK")      // StarkNet
 	SUI       = NativeAsset("SUI")       // SUI
+	TIA       = NativeAsset("TIA")       // Celestia
+	TRX       = NativeAsset("TRX")       // Tron
+	XLM       = NativeAsset("XLM")       // Stellar
 	XPLA      = NativeAsset("XPLA")      // XPLA
 )
 
@@ -162,14 +117,21 @@ type Driver string
 
 // List of supported Driver
 const (
+	DriverAlgorand    = Driver("algorand")
 	DriverAptos       = Driver("aptos")
+	DriverAvalanche   = Driver("avalanche")
 	DriverSui         = Driver("sui")
 	DriverBitcoin     = Driver("bitcoin")
 	DriverCosmos      = Driver("cosmos")
 	DriverCosmosEvmos = Driver("evmos")
 	DriverEVM         = Driver("evm")
 	DriverEVMLegacy   = Driver("evm-legacy")
+	DriverNear        = Driver("near")
 	DriverSolana      = Driver("solana")
+	DriverStarknet    = Driver("starknet")
+	DriverStellar     = Driver("stellar")
+	DriverSubstrate   = Driver("substrate")
+	DriverTron        = Driver("tron")
 )
 
 var SupportedDrivers = []Driver{
@@ -181,38 +143,91 @@ var SupportedDrivers = []Driver{
 	DriverSolana,
 	DriverSui,
 	DriverAptos,
+	DriverSubstrate,
+	DriverStarknet,
+	DriverTron,
+	DriverAvalanche,
+	DriverNear,
+	DriverStellar,
+	DriverAlgorand,
 }
 
+// Driver returns the driver of a chain, empty if it isn't registered
 func (native NativeAsset) Driver() Driver {
-	switch native {
-	case BCH, BTC, DOGE, LTC:
-		return DriverBitcoin
-	case AVAX, CELO, ETH, ETHW, MATIC, OasisROSE, OptETH:
-		return DriverEVM
-	case BNB, FTM, ETC, ROSE, AurETH, ACA, KAR, KLAY, OAS, CHZ, XDC, CHZ2:
-		return DriverEVMLegacy
-	case APTOS:
-		return DriverAptos
-	case ATOM, XPLA, INJ, LUNC, LUNA:
-		return DriverCosmos
-	case SUI:
-		return DriverSui
-	case SOL:
-		return DriverSolana
+	if info, ok := LookupChain(native); ok {
+		return info.Driver
 	}
 	return ""
 }
 
 func (driver Driver) SignatureAlgorithm() SignatureType {
 	switch driver {
-	case DriverBitcoin, DriverEVM, DriverEVMLegacy, DriverCosmos, DriverCosmosEvmos:
+	case DriverBitcoin, DriverEVM, DriverEVMLegacy, DriverCosmos, DriverCosmosEvmos, DriverTron, DriverAvalanche:
 		return K256
-	case DriverAptos, DriverSolana, DriverSui:
+	case DriverAptos, DriverSolana, DriverSui, DriverNear, DriverStellar, DriverAlgorand:
 		return Ed255
+	case DriverSubstrate:
+		return Sr25519
+	case DriverStarknet:
+		return Stark
+	}
+	if constructors, ok := LookupDriver(driver); ok {
+		return constructors.SignatureAlgorithm
 	}
 	return ""
 }
 
+// RecoverableSignature returns true if the k256 signatures of a driver end with the recovery id, i.e. R || S || V
+func (driver Driver) RecoverableSignature() bool {
+	switch driver {
+	case DriverBitcoin, DriverEVM, DriverEVMLegacy, DriverTron, DriverAvalanche:
+		return true
+	}
+	return false
+}
+
+// SignatureAlgorithm returns the curve used to sign txs of a chain
+func (native NativeAsset) SignatureAlgorithm() SignatureType {
+	return native.Driver().SignatureAlgorithm()
+}
+
+// CoinType returns the SLIP-44 coin type used by wallets to derive keys of a chain
+// EVM chains without a dedicated coin type use Ethereum's, as most wallets do
+func (native NativeAsset) CoinType() uint32 {
+	if info, ok := LookupChain(native); ok {
+		return info.CoinType
+	}
+	return 0
+}
+
+// Decimals returns the decimals of a native asset, 0 if its chain isn't registered
+func (native NativeAsset) Decimals() int32 {
+	if info, ok := LookupChain(native); ok {
+		return info.Decimals
+	}
+	return 0
+}
+
+// DerivationPath returns the default BIP-44 derivation path of the first account of a chain
+// Ed25519 chains only support hardened derivation (SLIP-10)
+func (native NativeAsset) DerivationPath() string {
+	return derivationPath(native.Driver(), native.CoinType())
+}
+
+func derivationPath(driver Driver, coinType uint32) string {
+	switch driver {
+	case DriverSolana:
+		return fmt.Sprintf("m/44'/%d'/0'/0'", coinType)
+	case DriverNear, DriverStellar, DriverAlgorand:
+		return fmt.Sprintf("m/44'/%d'/0'", coinType)
+	case DriverAptos, DriverSui, DriverSubstrate:
+		return fmt.Sprintf("m/44'/%d'/0'/0'/0'", coinType)
+	case "":
+		return ""
+	}
+	return fmt.Sprintf("m/44'/%d'/0'/0/0", coinType)
+}
+
 // AssetID is an internal identifier for each asset
 // Examples: ETH, USDC, USDC.SOL - see tests for details
 type AssetID string
@@ -230,20 +245,23 @@ type AssetConfig struct {
 	//     net = "mainnet"
 	//     contract = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
 	//     decimals = 6
-	Asset                string  `yaml:"asset"`
-	Driver               string  `yaml:"driver"`
-	Net                  string  `yaml:"net"`
-	URL                  string  `yaml:"url"`
-	FcdURL               string  `yaml:"fcd_url"`
-	Auth                 string  `yaml:"auth"`
-	Provider             string  `yaml:"provider"`
-	ChainID              int64   `yaml:"chain_id"`
-	ChainIDStr           string  `yaml:"chain_id_str"`
-	ChainName            string  `yaml:"chain_name"`
-	ChainPrefix          string  `yaml:"chain_prefix"`
-	ChainCoin            string  `yaml:"chain_coin"`
-	GasCoin              string  `yaml:"gas_coin"`
-	ChainCoinHDPath      uint32  `yaml:"chain_coin_hd_path"`
+	Asset           string `yaml:"asset"`
+	Driver          string `yaml:"driver"`
+	Net             Net    `yaml:"net"`
+	URL             string `yaml:"url"`
+	FcdURL          string `yaml:"fcd_url"`
+	Auth            string `yaml:"auth"`
+	AuthKeyID       string `yaml:"auth_key_id"`
+	Provider        string `yaml:"provider"`
+	ChainID         int64  `yaml:"chain_id"`
+	ChainIDStr      string `yaml:"chain_id_str"`
+	ChainName       string `yaml:"chain_name"`
+	ChainPrefix     string `yaml:"chain_prefix"`
+	ChainCoin       string `yaml:"chain_coin"`
+	GasCoin         string `yaml:"gas_coin"`
+	ChainCoinHDPath uint32 `yaml:"chain_coin_hd_path"`
+	// DerivationPath of the first address, overriding the default BIP-44 path of the chain, see GetDerivationPath
+	DerivationPath       string  `yaml:"derivation_path"`
 	ChainGasPriceDefault float64 `yaml:"chain_gas_price_default"`
 	ChainGasMultiplier   float64 `yaml:"chain_gas_multiplier"`
 	ChainGasTip          uint64  `yaml:"chain_gas_tip"`
@@ -254,6 +272,70 @@ type AssetConfig struct {
 	IndexerType          string  `yaml:"indexer_type"`
 	NoGasFees            bool    `yaml:"no_gas_fees"`
 
+	// Floors of fee estimates, e.g. for providers returning zero fees on quiet chains
+	// MinGasPrice is in the unit of the gas price of the chain: wei (EVM), mist (Sui), octas (Aptos),
+	// or a decimal price per gas unit like chain_gas_price_default (Cosmos)
+	MinGasPrice float64 `yaml:"min_gas_price"`
+	// MinFeeRate is in sats per byte (UTXO chains)
+	MinFeeRate float64 `yaml:"min_fee_rate"`
+
+	// Coin selection of UTXO chains: largest-first or branch-and-bound, and the smallest change output in sats
+	CoinSelection string `yaml:"coin_selection"`
+	DustThreshold uint64 `yaml:"dust_threshold"`
+	// ReplaceByFee signals BIP 125 replaceability in the txs of UTXO chains, so their fee can be bumped
+	ReplaceByFee bool `yaml:"replace_by_fee"`
+
+	// Region of url, and failover endpoints tried in order when url is unavailable
+	Region    Region     `yaml:"region"`
+	Endpoints []Endpoint `yaml:"endpoints"`
+	// AllowedRegions and DeniedRegions constrain the endpoints requests are sent to, e.g. for sanctioned jurisdictions
+	AllowedRegions []Region `yaml:"allowed_regions"`
+	DeniedRegions  []Region `yaml:"denied_regions"`
+	// HostRegions are the regions of the other hosts drivers send requests to, e.g. indexers or the FCD of Terra,
+	// by host name: requests to a host in a disallowed region, or unknown once allowed_regions is set, are rejected
+	HostRegions map[string]Region `yaml:"host_regions"`
+	// QuorumReads is the number of providers, among url and endpoints, that must answer critical reads (balances,
+	// nonces) for them to be cross-checked, see QuorumClient; 0 disables quorum reads
+	QuorumReads int `yaml:"quorum_reads"`
+
+	// RequestSigning signs each RPC request with auth_key_id and the auth secret, see RequestSigning
+	RequestSigning RequestSigning `yaml:"request_signing"`
+
+	// Halt detection, see CheckChainHealth
+	// BlockTime is the expected block time, the measured average block time if not set
+	BlockTime time.Duration `yaml:"block_time"`
+	// HaltBlocks is the number of block times without a new block of a halted chain, DefaultHaltBlocks if not set
+	HaltBlocks int `yaml:"halt_blocks"`
+	// MaintenanceURL lists the maintenances of the provider, see FetchMaintenances
+	MaintenanceURL string `yaml:"maintenance_url"`
+
+	// DryRun simulates txs without broadcasting them, e.g. for staging environments on mainnet, see WithDryRun
+	DryRun bool `yaml:"dry_run"`
+
+	// Status of the asset, e.g. to halt its withdrawals during an incident, see CheckSendAllowed
+	// Enabled is true if not set, ReceiveOnly assets can't be sent, and assets are paused while PausedWithReason is set
+	Enabled          *bool  `yaml:"enabled"`
+	ReceiveOnly      bool   `yaml:"receive_only"`
+	PausedWithReason string `yaml:"paused_with_reason"`
+
+	// StakingContract delegates to validators on EVM chains, see TxStakingBuilder
+	StakingContract StakingContract `yaml:"staking_contract"`
+
+	// Rollup selects the L2 behaviors of EVM rollups, see GetRollup
+	Rollup RollupConfig `yaml:"rollup"`
+
+	// Substrate configures the addresses and extrinsics of Substrate chains, see GetSubstrate
+	Substrate SubstrateConfig `yaml:"substrate"`
+
+	// Starknet configures the accounts of StarkNet chains, see GetStarknet
+	Starknet StarknetConfig `yaml:"starknet"`
+
+	// Avalanche configures the network of the X-Chain and the P-Chain of Avalanche, see GetAvalanche
+	Avalanche AvalancheConfig `yaml:"avalanche"`
+
+	// UTXO configures the addresses and the signature hashing of Bitcoin-family chains, see GetUTXO
+	UTXO UTXOConfig `yaml:"utxo"`
+
 	// Tokens
 	Chain    string `yaml:"chain"`
 	Contract string `yaml:"contract"`
@@ -263,17 +345,27 @@ type AssetConfig struct {
 	Type        AssetType           `yaml:"-"`
 	NativeAsset NativeAsset         `yaml:"-"`
 	Metadata    AssetMetadataConfig `yaml:"-"`
+	// RequestSigner overrides request_signing, for custom signing schemes
+	RequestSigner RequestSigner `yaml:"-"`
+	// Unverified is set on the configs of tokens resolved on chain rather than configured: Asset is empty, and the
+	// symbol claimed by the contract, which any contract can fake, is only in Name
+	Unverified bool `yaml:"-"`
 }
 type NativeAssetConfig = AssetConfig
 
 type TokenAssetConfig struct {
 	Asset    string    `yaml:"asset"`
 	Chain    string    `yaml:"chain"`
-	Net      string    `yaml:"net"`
+	Net      Net       `yaml:"net"`
 	Decimals int32     `yaml:"decimals"`
 	Contract string    `yaml:"contract"`
 	Type     AssetType `yaml:"type"`
 
+	// Status of the token, in addition to the status of its chain, see AssetConfig
+	Enabled          *bool  `yaml:"enabled"`
+	ReceiveOnly      bool   `yaml:"receive_only"`
+	PausedWithReason string `yaml:"paused_with_reason"`
+
 	AssetConfig       `yaml:"-"`
 	NativeAssetConfig *NativeAssetConfig  `yaml:"-"`
 	Metadata          AssetMetadataConfig `yaml:"-"`
@@ -295,11 +387,97 @@ type Config struct {
 	AllAssets    []ITask              `yaml:"-"`
 }
 
+// GetAsset returns the chain or token of an AssetID, e.g. ETH or USDC.SOL, in a config loaded with its AllAssets
+func (c *Config) GetAsset(id AssetID) (ITask, error) {
+	for _, asset := range c.AllAssets {
+		if asset.ID() == id {
+			return asset, nil
+		}
+	}
+	return nil, fmt.Errorf("could not lookup asset: '%s'", id)
+}
+
+// GetAssetByContract returns the token of a chain with a contract address,
+// or the native asset of the chain if contract is its chain coin
+// Contracts are compared normalized, see NormalizeContractAddress
+func (c *Config) GetAssetByContract(native NativeAsset, contract string) (ITask, error) {
+	var nativeCfg *NativeAssetConfig
+	for _, chain := range c.Chains {
+		if strings.EqualFold(chain.Asset, string(native)) {
+			nativeCfg = chain
+			break
+		}
+	}
+	driver := native.Driver()
+	if nativeCfg != nil && nativeCfg.Driver != "" {
+		driver = Driver(nativeCfg.Driver)
+	}
+	normalized := NormalizeContractAddress(driver, contract)
+	if normalized == "" {
+		return nil, fmt.Errorf("invalid contract: '%s'", contract)
+	}
+	for _, token := range c.Tokens {
+		if !strings.EqualFold(token.Chain, string(native)) {
+			continue
+		}
+		if NormalizeContractAddress(driver, token.Contract) == normalized {
+			found := *token
+			if found.NativeAssetConfig == nil {
+				found.NativeAssetConfig = nativeCfg
+			}
+			return &found, nil
+		}
+	}
+	if nativeCfg != nil && nativeCfg.ChainCoin != "" && NormalizeContractAddress(driver, nativeCfg.ChainCoin) == normalized {
+		return nativeCfg, nil
+	}
+	return nil, fmt.Errorf("no asset with contract '%s' on %s", contract, native)
+}
+
+// NormalizeContractAddress normalizes a contract address so that equivalent addresses compare equal:
+// hex and bech32 addresses are lowercased (ignoring EIP-55 checksums) and Move type tags are reduced to their address,
+// StarkNet felts lose their leading zeros, base58 addresses and Cosmos denoms are case sensitive and only trimmed
+func NormalizeContractAddress(driver Driver, contract string) string {
+	contract = strings.TrimSpace(contract)
+	switch driver {
+	case DriverEVM, DriverEVMLegacy:
+		// also xdc prefixed addresses
+		return strings.ToLower(contract)
+	case DriverCosmos, DriverCosmosEvmos:
+		// bech32 addresses, e.g. cw20 contracts, are case insensitive but denoms are case sensitive
+		if _, _, err := bech32.DecodeNoLimit(contract); err == nil {
+			return strings.ToLower(contract)
+		}
+		return contract
+	case DriverAptos, DriverSui:
+		contract = strings.TrimSuffix(strings.TrimPrefix(contract, "coin::Coin<"), ">")
+		address, rest, _ := strings.Cut(contract, "::")
+		address = strings.ToLower(address)
+		if rest != "" {
+			return address + "::" + rest
+		}
+		return address
+	case DriverStarknet:
+		// felts, with or without leading zeros
+		contract = strings.TrimLeft(strings.TrimPrefix(strings.ToLower(contract), "0x"), "0")
+		if contract == "" {
+			return ""
+		}
+		return "0x" + contract
+	case DriverBitcoin:
+		// remove bitcoincash: prefix
+		if _, address, ok := strings.Cut(contract, ":"); ok {
+			return address
+		}
+	}
+	return contract
+}
+
 func (c NativeAssetConfig) String() string {
 	// do NOT print AuthSecret
 	return fmt.Sprintf(
 		"NativeAssetConfig(id=%s asset=%s chainId=%d driver=%s type=%s chainCoin=%s prefix=%s net=%s url=%s auth=%s provider=%s native_asset=%s)",
-		c.ID(), c.Asset, c.ChainID, c.Driver, c.Type, c.ChainCoin, c.ChainPrefix, c.Net, c.URL, c.Auth, c.Provider, c.NativeAsset,
+		c.ID(), c.Asset, c.ChainID, c.Driver, c.Type, c.ChainCoin, c.ChainPrefix, c.Net, DefaultRedactor.Redact(c.URL), c.Auth, c.Provider, c.NativeAsset,
 	)
 }
 
@@ -323,6 +501,27 @@ func (asset NativeAssetConfig) GetTask() *TaskConfig {
 	return nil
 }
 
+// GetCoinType returns the configured chain_coin_hd_path, or the SLIP-44 coin type of the chain
+func (asset NativeAssetConfig) GetCoinType() uint32 {
+	if asset.ChainCoinHDPath != 0 {
+		return asset.ChainCoinHDPath
+	}
+	return asset.NativeAsset.CoinType()
+}
+
+// GetDerivationPath returns the configured derivation_path, or the BIP-44 path of the first address of the chain
+// with its coin type, e.g. m/44'/60'/0'/0/0
+func (asset NativeAssetConfig) GetDerivationPath() string {
+	if asset.DerivationPath != "" {
+		return asset.DerivationPath
+	}
+	driver := Driver(asset.Driver)
+	if driver == "" {
+		driver = asset.NativeAsset.Driver()
+	}
+	return derivationPath(driver, asset.GetCoinType())
+}
+
 func (c TokenAssetConfig) String() string {
 	return fmt.Sprintf(
 		"TokenAssetConfig(id=%s asset=%s chain=%s net=%s decimals=%d contract=%s)",
@@ -0,0 +1,19 @@
+package crosschain
+
+import "context"
+
+// TxAtomicBuilder is a Builder of atomic txs, moving funds between chains sharing memory, e.g. the X-Chain and the
+// P-Chain of Avalanche: funds exported by a chain are owned by an address in the shared memory of the destination
+// chain, until the address imports them
+type TxAtomicBuilder interface {
+	// NewExport exports amount from the chain to destination, owned by to
+	NewExport(from Address, to Address, amount AmountBlockchain, destination NativeAsset, input TxInput) (Tx, error)
+	// NewImport imports the funds of address exported from source, the fee of the import deducted, see ClientAtomic
+	NewImport(address Address, source NativeAsset, input TxInput) (Tx, error)
+}
+
+// ClientAtomic is a specific Client that can fetch the input of atomic txs
+type ClientAtomic interface {
+	// FetchImportInput returns the input of an import of the funds of address exported from source
+	FetchImportInput(ctx context.Context, address Address, source NativeAsset) (TxInput, error)
+}
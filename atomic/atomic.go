@@ -0,0 +1,289 @@
+// Package atomic orchestrates atomic transfers between chains sharing memory, e.g. from the X-Chain to the P-Chain
+// of Avalanche: an export from the source chain, then once the export is accepted, an import on the destination
+// chain by the recipient
+package atomic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+	"github.com/jumpcrypto/crosschain/lease"
+)
+
+// DefaultPollInterval is the interval between the steps of Run
+const DefaultPollInterval = 2 * time.Second
+
+// State is the state of a Transfer
+type State string
+
+// List of states of a Transfer, in order
+const (
+	// StatePending is a transfer whose export isn't submitted yet
+	StatePending = State("pending")
+	// StateExporting is a transfer whose export is submitted, not accepted yet
+	StateExporting = State("exporting")
+	// StateExported is a transfer whose funds are in the shared memory of the destination chain, owned by To
+	StateExported = State("exported")
+	// StateImporting is a transfer whose import is submitted, not accepted yet
+	StateImporting = State("importing")
+	// StateImported is a completed transfer
+	StateImported = State("imported")
+	// StateFailed is a transfer whose export or import failed, see Transfer.Error
+	StateFailed = State("failed")
+)
+
+// Done returns true for the final states
+func (state State) Done() bool {
+	return state == StateImported || state == StateFailed
+}
+
+// Transfer is an atomic transfer of Amount from From on the Source chain to To on the Destination chain
+type Transfer struct {
+	ID          string
+	Source      xc.ITask
+	Destination xc.ITask
+	From        xc.Address
+	To          xc.Address
+	Amount      xc.AmountBlockchain
+	State       State
+	ExportTx    xc.TxHash
+	ImportTx    xc.TxHash
+	Error       string
+}
+
+// NewTransfer creates a new pending Transfer
+func NewTransfer(id string, source xc.ITask, destination xc.ITask, from xc.Address, to xc.Address, amount xc.AmountBlockchain) *Transfer {
+	return &Transfer{
+		ID:          id,
+		Source:      source,
+		Destination: destination,
+		From:        from,
+		To:          to,
+		Amount:      amount,
+		State:       StatePending,
+	}
+}
+
+// Orchestrator moves Transfers through their states, signing the export and the import with Signer: From and To
+// must be addresses of its key
+// Transfers are updated in place, e.g. to be persisted by the caller after each Step
+type Orchestrator struct {
+	Factory      factory.FactoryContext
+	Signer       xc.KeySigner
+	PollInterval time.Duration
+	// Locker, if set, leases each transfer while it's stepped, so that replicas don't submit its txs twice
+	Locker   lease.Locker
+	LeaseTTL time.Duration
+}
+
+// NewOrchestrator creates a new Orchestrator
+func NewOrchestrator(f factory.FactoryContext, signer xc.KeySigner) *Orchestrator {
+	return &Orchestrator{
+		Factory:      f,
+		Signer:       signer,
+		PollInterval: DefaultPollInterval,
+	}
+}
+
+// Step advances a transfer by at most one state: submits its export or import, or checks if the tx submitted is
+// accepted
+// The hash of a tx is recorded before its submission: a Step after a failed submission checks if the tx was
+// accepted anyway before submitting another
+// With a Locker, Step returns lease.ErrLeaseHeld while another replica steps the transfer
+func (o *Orchestrator) Step(ctx context.Context, transfer *Transfer) error {
+	if o.Locker == nil {
+		return o.advance(ctx, transfer)
+	}
+	ttl := o.LeaseTTL
+	if ttl <= 0 {
+		ttl = lease.DefaultTTL
+	}
+	return lease.WithLease(ctx, o.Locker, lease.AtomicKey(transfer.ID), ttl, func(ctx context.Context) error {
+		return o.advance(ctx, transfer)
+	})
+}
+
+func (o *Orchestrator) advance(ctx context.Context, transfer *Transfer) error {
+	switch transfer.State {
+	case StatePending:
+		if transfer.ExportTx != "" && o.accepted(ctx, transfer.Source, transfer.ExportTx) {
+			transfer.State = StateExporting
+			return nil
+		}
+		return o.export(ctx, transfer)
+	case StateExporting:
+		return o.check(ctx, transfer, transfer.Source, transfer.ExportTx, StateExported)
+	case StateExported:
+		if transfer.ImportTx != "" && o.accepted(ctx, transfer.Destination, transfer.ImportTx) {
+			transfer.State = StateImporting
+			return nil
+		}
+		return o.importFunds(ctx, transfer)
+	case StateImporting:
+		return o.check(ctx, transfer, transfer.Destination, transfer.ImportTx, StateImported)
+	case StateImported, StateFailed:
+		return nil
+	}
+	return fmt.Errorf("invalid state of transfer %s: '%s'", transfer.ID, transfer.State)
+}
+
+// Run steps a transfer every PollInterval until it's imported or failed
+// Errors of steps are passed to onError, if set, and retried
+func (o *Orchestrator) Run(ctx context.Context, transfer *Transfer, onError func(error)) error {
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := o.Step(ctx, transfer)
+		if err != nil && onError != nil {
+			onError(err)
+		}
+		if transfer.State.Done() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Orchestrator) export(ctx context.Context, transfer *Transfer) error {
+	if err := o.checkSigner(transfer.Source, transfer.From); err != nil {
+		return err
+	}
+	if err := o.checkSigner(transfer.Destination, transfer.To); err != nil {
+		return err
+	}
+	client, err := o.Factory.NewClient(transfer.Source)
+	if err != nil {
+		return err
+	}
+	builder, err := o.atomicBuilder(transfer.Source)
+	if err != nil {
+		return err
+	}
+	input, err := client.FetchTxInput(ctx, transfer.From, transfer.To)
+	if err != nil {
+		return fmt.Errorf("fetching input of export: %v", err)
+	}
+	tx, err := builder.NewExport(transfer.From, transfer.To, transfer.Amount, transfer.Destination.GetNativeAsset().NativeAsset, input)
+	if err != nil {
+		return fmt.Errorf("building export: %v", err)
+	}
+	if err := xc.SignTx(o.Signer, tx); err != nil {
+		return fmt.Errorf("signing export: %v", err)
+	}
+	transfer.ExportTx = tx.Hash()
+	if err := client.SubmitTx(ctx, tx); err != nil {
+		return fmt.Errorf("submitting export %s: %v", transfer.ExportTx, err)
+	}
+	transfer.State = StateExporting
+	return nil
+}
+
+func (o *Orchestrator) importFunds(ctx context.Context, transfer *Transfer) error {
+	client, err := o.Factory.NewClient(transfer.Destination)
+	if err != nil {
+		return err
+	}
+	atomicClient, ok := xc.UnwrapClient(client).(xc.ClientAtomic)
+	if !ok {
+		return fmt.Errorf("atomic txs are not supported by %s", transfer.Destination.GetNativeAsset().NativeAsset)
+	}
+	builder, err := o.atomicBuilder(transfer.Destination)
+	if err != nil {
+		return err
+	}
+	source := transfer.Source.GetNativeAsset().NativeAsset
+	input, err := atomicClient.FetchImportInput(ctx, transfer.To, source)
+	if err != nil {
+		return fmt.Errorf("fetching input of import: %v", err)
+	}
+	tx, err := builder.NewImport(transfer.To, source, input)
+	if err != nil {
+		return fmt.Errorf("building import: %v", err)
+	}
+	if err := xc.SignTx(o.Signer, tx); err != nil {
+		return fmt.Errorf("signing import: %v", err)
+	}
+	transfer.ImportTx = tx.Hash()
+	if err := client.SubmitTx(ctx, tx); err != nil {
+		return fmt.Errorf("submitting import %s: %v", transfer.ImportTx, err)
+	}
+	transfer.State = StateImporting
+	return nil
+}
+
+// check moves a transfer to next once txHash is accepted, or to StateFailed if it failed
+// The funds of a failed import stay in the shared memory of the destination chain: setting the state of the
+// transfer back to StateExported retries the import
+func (o *Orchestrator) check(ctx context.Context, transfer *Transfer, asset xc.ITask, txHash xc.TxHash, next State) error {
+	client, err := o.Factory.NewClient(asset)
+	if err != nil {
+		return err
+	}
+	info, err := client.FetchTxInfo(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("fetching tx %s: %v", txHash, err)
+	}
+	if info.Status == xc.TxStatusFailure {
+		transfer.State = StateFailed
+		transfer.Error = fmt.Sprintf("tx %s failed: %s", txHash, info.Error)
+		return nil
+	}
+	if info.Confirmations > 0 {
+		transfer.State = next
+	}
+	return nil
+}
+
+// accepted returns true if a tx is known, e.g. submitted despite the error of its submission
+func (o *Orchestrator) accepted(ctx context.Context, asset xc.ITask, txHash xc.TxHash) bool {
+	client, err := o.Factory.NewClient(asset)
+	if err != nil {
+		return false
+	}
+	info, err := client.FetchTxInfo(ctx, txHash)
+	return err == nil && info.Status == xc.TxStatusSuccess
+}
+
+func (o *Orchestrator) atomicBuilder(asset xc.ITask) (xc.TxAtomicBuilder, error) {
+	builder, err := o.Factory.NewTxBuilder(asset)
+	if err != nil {
+		return nil, err
+	}
+	// atomic txs aren't transfers built through middlewares
+	atomicBuilder, ok := xc.UnwrapTxBuilder(builder).(xc.TxAtomicBuilder)
+	if !ok {
+		return nil, fmt.Errorf("atomic txs are not supported by %s", asset.GetNativeAsset().NativeAsset)
+	}
+	return atomicBuilder, nil
+}
+
+// checkSigner checks address is the address of the key of the Signer on the chain of asset
+func (o *Orchestrator) checkSigner(asset xc.ITask, address xc.Address) error {
+	if o.Signer == nil {
+		return errors.New("missing signer")
+	}
+	publicKey, err := o.Signer.PublicKey()
+	if err != nil {
+		return err
+	}
+	expected, err := o.Factory.GetAddressFromPublicKey(asset, publicKey)
+	if err != nil {
+		return err
+	}
+	if expected != address {
+		return fmt.Errorf("address %s of %s isn't the address of the signer: %s", address, asset.GetNativeAsset().NativeAsset, expected)
+	}
+	return nil
+}
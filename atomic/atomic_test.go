@@ -0,0 +1,232 @@
+package atomic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/avalanche"
+	"github.com/jumpcrypto/crosschain/lease"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestAtomicTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+// the ewoq key of the local networks of avalanchego
+const testPrivateKey = "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+
+var xAsset = &xc.NativeAssetConfig{NativeAsset: xc.XAVAX, Driver: string(xc.DriverAvalanche), Net: xc.Fuji}
+var pAsset = &xc.NativeAssetConfig{NativeAsset: xc.PAVAX, Driver: string(xc.DriverAvalanche), Net: xc.Fuji}
+var xAddress = xc.Address("X-fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4t")
+var pAddress = xc.Address("P-fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4t")
+
+func testUTXO(amount uint64) avalanche.UTXO {
+	assetID, _ := avalanche.DecodeID(xc.KnownAvalancheNetworks[xc.Fuji].AssetID)
+	_, _, owner, _ := avalanche.ParseAddress(xAddress)
+	return avalanche.UTXO{
+		TxID:   make([]byte, 32),
+		Output: avalanche.Output{AssetID: assetID, Amount: amount, Threshold: 1, Addresses: [][]byte{owner}},
+	}
+}
+
+func testInput(amount uint64) *avalanche.TxInput {
+	input := avalanche.NewTxInput()
+	input.UTXOs = []avalanche.UTXO{testUTXO(amount)}
+	input.Fee = 1_000_000
+	return input
+}
+
+func (s *CrosschainTestSuite) newOrchestrator(xClient *testutil.MockedClient, pClient *testutil.MockedClient) *Orchestrator {
+	require := s.Require()
+	f := testutil.NewDefaultFactoryWithConfig(map[string]interface{}{})
+	f.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		if asset.GetNativeAsset().NativeAsset == xc.PAVAX {
+			return pClient, nil
+		}
+		return xClient, nil
+	}
+	signer, err := f.NewLocalSigner(xAsset, testPrivateKey)
+	require.NoError(err)
+	return NewOrchestrator(&f, signer)
+}
+
+func (s *CrosschainTestSuite) TestStep() {
+	require := s.Require()
+	xClient := &testutil.MockedClient{}
+	pClient := &testutil.MockedClient{}
+	orchestrator := s.newOrchestrator(xClient, pClient)
+	transfer := NewTransfer("1", xAsset, pAsset, xAddress, pAddress, xc.NewAmountBlockchainFromUint64(100_000_000))
+
+	// export
+	xClient.On("FetchTxInput", mock.Anything, xAddress, pAddress).Return(testInput(200_000_000), nil)
+	xClient.On("SubmitTx", mock.Anything, mock.Anything).Return(nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	require.Equal(StateExporting, transfer.State)
+	require.NotEmpty(transfer.ExportTx)
+	exported := xClient.Calls[1].Arguments.Get(1).(*avalanche.Tx)
+	require.Len(exported.ExportedOutputs, 1)
+	require.EqualValues(100_000_000, exported.ExportedOutputs[0].Amount)
+	require.Len(exported.Credentials, 1)
+
+	// not accepted yet
+	xClient.On("FetchTxInfo", mock.Anything, transfer.ExportTx).Return(xc.TxInfo{}, nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	require.Equal(StateExporting, transfer.State)
+	xClient.On("FetchTxInfo", mock.Anything, transfer.ExportTx).Return(xc.TxInfo{Confirmations: 1}, nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	require.Equal(StateExported, transfer.State)
+
+	// import
+	pClient.On("FetchImportInput", mock.Anything, pAddress, xc.XAVAX).Return(testInput(100_000_000), nil)
+	pClient.On("SubmitTx", mock.Anything, mock.Anything).Return(nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	require.Equal(StateImporting, transfer.State)
+	require.NotEmpty(transfer.ImportTx)
+	imported := pClient.Calls[1].Arguments.Get(1).(*avalanche.Tx)
+	require.Len(imported.ImportedInputs, 1)
+	require.EqualValues(99_000_000, imported.Outputs[0].Amount)
+
+	pClient.On("FetchTxInfo", mock.Anything, transfer.ImportTx).Return(xc.TxInfo{Confirmations: 1}, nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	require.Equal(StateImported, transfer.State)
+	require.True(transfer.State.Done())
+
+	// done
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	xClient.AssertExpectations(s.T())
+	pClient.AssertExpectations(s.T())
+}
+
+func (s *CrosschainTestSuite) TestStepMiddleware() {
+	require := s.Require()
+	xClient := &testutil.MockedClient{}
+	orchestrator := s.newOrchestrator(xClient, &testutil.MockedClient{})
+	orchestrator.Factory.UseTxBuilderMiddleware(xc.BeforeBuild(func(req *xc.TxBuildRequest) error {
+		return nil
+	}))
+	transfer := NewTransfer("1", xAsset, pAsset, xAddress, pAddress, xc.NewAmountBlockchainFromUint64(100_000_000))
+
+	// the builder wrapped by the middleware exports
+	xClient.On("FetchTxInput", mock.Anything, xAddress, pAddress).Return(testInput(200_000_000), nil)
+	xClient.On("SubmitTx", mock.Anything, mock.Anything).Return(nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	require.Equal(StateExporting, transfer.State)
+	require.NotEmpty(transfer.ExportTx)
+	xClient.AssertExpectations(s.T())
+}
+
+func (s *CrosschainTestSuite) TestStepRetries() {
+	require := s.Require()
+	xClient := &testutil.MockedClient{}
+	pClient := &testutil.MockedClient{}
+	orchestrator := s.newOrchestrator(xClient, pClient)
+	transfer := NewTransfer("1", xAsset, pAsset, xAddress, pAddress, xc.NewAmountBlockchainFromUint64(100_000_000))
+
+	// the export is recorded despite the error of its submission
+	xClient.On("FetchTxInput", mock.Anything, xAddress, pAddress).Return(testInput(200_000_000), nil)
+	xClient.On("SubmitTx", mock.Anything, mock.Anything).Return(errors.New("timeout")).Once()
+	err := orchestrator.Step(s.Ctx, transfer)
+	require.ErrorContains(err, "submitting export")
+	require.Equal(StatePending, transfer.State)
+	exportTx := transfer.ExportTx
+	require.NotEmpty(exportTx)
+
+	// and found by the next step
+	xClient.On("FetchTxInfo", mock.Anything, exportTx).Return(xc.TxInfo{Status: xc.TxStatusSuccess}, nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	require.Equal(StateExporting, transfer.State)
+	require.Equal(exportTx, transfer.ExportTx)
+
+	// a failed import
+	transfer.State = StateImporting
+	transfer.ImportTx = "import"
+	pClient.On("FetchTxInfo", mock.Anything, xc.TxHash("import")).Return(xc.TxInfo{Status: xc.TxStatusFailure, Error: "Dropped"}, nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	require.Equal(StateFailed, transfer.State)
+	require.Equal("tx import failed: Dropped", transfer.Error)
+
+	transfer.State = "unknown"
+	require.EqualError(orchestrator.Step(s.Ctx, transfer), "invalid state of transfer 1: 'unknown'")
+}
+
+func (s *CrosschainTestSuite) TestStepErrors() {
+	require := s.Require()
+	xClient := &testutil.MockedClient{}
+	pClient := &testutil.MockedClient{}
+	orchestrator := s.newOrchestrator(xClient, pClient)
+
+	// addresses of another key
+	other := xc.Address("P-fuji1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq2nffjl")
+	transfer := NewTransfer("1", xAsset, pAsset, xAddress, other, xc.NewAmountBlockchainFromUint64(100_000_000))
+	err := orchestrator.Step(s.Ctx, transfer)
+	require.ErrorContains(err, "isn't the address of the signer")
+
+	// chains without atomic txs
+	eth := &xc.NativeAssetConfig{NativeAsset: xc.ETH, Driver: string(xc.DriverEVM)}
+	transfer = NewTransfer("1", xAsset, eth, xAddress, pAddress, xc.NewAmountBlockchainFromUint64(100_000_000))
+	transfer.State = StateExported
+	require.EqualError(orchestrator.Step(s.Ctx, transfer), "atomic txs are not supported by ETH")
+}
+
+func (s *CrosschainTestSuite) TestStepLeased() {
+	require := s.Require()
+	xClient := &testutil.MockedClient{}
+	pClient := &testutil.MockedClient{}
+	orchestrator := s.newOrchestrator(xClient, pClient)
+	locker := lease.NewMemoryLocker()
+	orchestrator.Locker = locker
+
+	transfer := NewTransfer("1", xAsset, pAsset, xAddress, pAddress, xc.NewAmountBlockchainFromUint64(100_000_000))
+	transfer.State = StateImporting
+	transfer.ImportTx = "import"
+	pClient.On("FetchTxInfo", mock.Anything, xc.TxHash("import")).Return(xc.TxInfo{Confirmations: 1}, nil)
+
+	// another replica steps the transfer
+	held, err := locker.Acquire(s.Ctx, lease.AtomicKey("1"), time.Minute)
+	require.NoError(err)
+	require.ErrorIs(orchestrator.Step(s.Ctx, transfer), lease.ErrLeaseHeld)
+	require.Equal(StateImporting, transfer.State)
+	require.NoError(held.Release(s.Ctx))
+
+	require.NoError(orchestrator.Step(s.Ctx, transfer))
+	require.Equal(StateImported, transfer.State)
+}
+
+func (s *CrosschainTestSuite) TestRun() {
+	require := s.Require()
+	xClient := &testutil.MockedClient{}
+	pClient := &testutil.MockedClient{}
+	orchestrator := s.newOrchestrator(xClient, pClient)
+	orchestrator.PollInterval = 1
+
+	transfer := NewTransfer("1", xAsset, pAsset, xAddress, pAddress, xc.NewAmountBlockchainFromUint64(100_000_000))
+	transfer.State = StateImporting
+	transfer.ImportTx = "import"
+	pClient.On("FetchTxInfo", mock.Anything, xc.TxHash("import")).Return(xc.TxInfo{}, errors.New("not found")).Once()
+	pClient.On("FetchTxInfo", mock.Anything, xc.TxHash("import")).Return(xc.TxInfo{Confirmations: 1}, nil).Once()
+	errs := []error{}
+	require.NoError(orchestrator.Run(s.Ctx, transfer, func(err error) { errs = append(errs, err) }))
+	require.Equal(StateImported, transfer.State)
+	require.Len(errs, 1)
+
+	ctx, cancel := context.WithCancel(s.Ctx)
+	cancel()
+	transfer.State = StateImporting
+	pClient.On("FetchTxInfo", mock.Anything, xc.TxHash("import")).Return(xc.TxInfo{}, nil)
+	require.ErrorIs(orchestrator.Run(ctx, transfer, nil), context.Canceled)
+}
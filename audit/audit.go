@@ -0,0 +1,313 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+	"github.com/jumpcrypto/crosschain/firehose"
+)
+
+// BalanceSource fetches the balance of an address at the end of a block, e.g. from an archive node
+// The contract is empty for the native asset
+type BalanceSource interface {
+	FetchBalanceAt(ctx context.Context, address xc.Address, contract xc.ContractAddress, height int64) (xc.AmountBlockchain, error)
+}
+
+// DefaultMaxSnapshots bounds the balance snapshots fetched by an audit
+const DefaultMaxSnapshots = 1000
+
+// Request is the address, the range of blocks and the assets to audit
+type Request struct {
+	Chain   xc.NativeAsset `json:"chain"`
+	Address xc.Address     `json:"address"`
+	// FromHeight and ToHeight are included, balances are compared at FromHeight-1 and ToHeight
+	FromHeight int64 `json:"from_height"`
+	ToHeight   int64 `json:"to_height"`
+	// Contracts of the audited assets, empty for the native asset, which is audited if Contracts is empty
+	Contracts []xc.ContractAddress `json:"contracts,omitempty"`
+	// MaxSnapshots bounds the balances fetched to locate gaps, DefaultMaxSnapshots if zero
+	MaxSnapshots int `json:"max_snapshots,omitempty"`
+}
+
+// GapKind is the kind of a gap between parsed transfers and balances
+type GapKind string
+
+// List of GapKind
+const (
+	// GapMissedTransfer is a balance change of a block not explained by its parsed transfers, e.g. an internal transfer
+	GapMissedTransfer GapKind = "missed_transfer"
+	// GapUnknownToken is a parsed transfer of a token that isn't audited, e.g. missing from the config
+	GapUnknownToken GapKind = "unknown_token"
+)
+
+// Gap is a block range where parsed transfers and balances disagree, a single block when located
+type Gap struct {
+	Kind       GapKind            `json:"kind"`
+	Contract   xc.ContractAddress `json:"contract,omitempty"`
+	FromHeight int64              `json:"from_height"`
+	ToHeight   int64              `json:"to_height"`
+	// Actual is the change of the balance snapshots, Computed the change from parsed transfers
+	Actual   xc.AmountBlockchain `json:"actual"`
+	Computed xc.AmountBlockchain `json:"computed"`
+	// TxHash is the tx of an unknown token
+	TxHash string `json:"tx_hash,omitempty"`
+}
+
+// Missing returns the balance change not explained by parsed transfers
+func (gap *Gap) Missing() xc.AmountBlockchain {
+	return sub(gap.Actual, gap.Computed)
+}
+
+// add and sub allocate the result, as AmountBlockchain.Add and Sub may reuse the memory of cached amounts
+func add(x xc.AmountBlockchain, y xc.AmountBlockchain) xc.AmountBlockchain {
+	return xc.AmountBlockchain(*new(big.Int).Add(x.Int(), y.Int()))
+}
+
+func sub(x xc.AmountBlockchain, y xc.AmountBlockchain) xc.AmountBlockchain {
+	return xc.AmountBlockchain(*new(big.Int).Sub(x.Int(), y.Int()))
+}
+
+// Report is the result of an audit
+type Report struct {
+	Request Request `json:"request"`
+	// Changes are the balance changes computed from parsed transfers over the range, per contract
+	Changes map[xc.ContractAddress]xc.AmountBlockchain `json:"changes"`
+	Gaps    []*Gap                                     `json:"gaps"`
+	// Snapshots is the number of balances fetched
+	Snapshots int `json:"snapshots"`
+}
+
+// Reconciled returns true if parsed transfers explain all balance changes
+func (report *Report) Reconciled() bool {
+	return len(report.Gaps) == 0
+}
+
+// Auditor reconciles the transfers parsed from blocks with the balance snapshots of an address
+type Auditor struct {
+	Blocks   firehose.BlockSource
+	Balances BalanceSource
+}
+
+// NewAuditor creates an Auditor
+func NewAuditor(blocks firehose.BlockSource, balances BalanceSource) *Auditor {
+	return &Auditor{
+		Blocks:   blocks,
+		Balances: balances,
+	}
+}
+
+type audit struct {
+	*Auditor
+	request Request
+	report  *Report
+	// deltas are the computed changes per block, for audited contracts
+	deltas    map[int64]map[xc.ContractAddress]xc.AmountBlockchain
+	snapshots map[xc.ContractAddress]map[int64]xc.AmountBlockchain
+	max       int
+}
+
+// Audit computes the balance changes of an address from the transfers parsed in each block of the range,
+// and compares them with its balances before and after the range
+// Mismatches are bisected with more balance snapshots down to single blocks, within MaxSnapshots
+func (auditor *Auditor) Audit(ctx context.Context, request Request) (*Report, error) {
+	if request.Address == "" {
+		return nil, errors.New("address is required")
+	}
+	if request.FromHeight <= 0 || request.ToHeight < request.FromHeight {
+		return nil, fmt.Errorf("invalid block range %d-%d", request.FromHeight, request.ToHeight)
+	}
+	contracts := []xc.ContractAddress{}
+	for _, contract := range request.Contracts {
+		contracts = append(contracts, normalizeContract(request.Chain, contract))
+	}
+	if len(contracts) == 0 {
+		contracts = []xc.ContractAddress{""}
+	}
+	a := &audit{
+		Auditor: auditor,
+		request: request,
+		report: &Report{
+			Request: request,
+			Changes: map[xc.ContractAddress]xc.AmountBlockchain{},
+			Gaps:    []*Gap{},
+		},
+		deltas:    map[int64]map[xc.ContractAddress]xc.AmountBlockchain{},
+		snapshots: map[xc.ContractAddress]map[int64]xc.AmountBlockchain{},
+		max:       request.MaxSnapshots,
+	}
+	if a.max <= 0 {
+		a.max = DefaultMaxSnapshots
+	}
+	audited := map[xc.ContractAddress]bool{}
+	for _, contract := range contracts {
+		audited[contract] = true
+		a.report.Changes[contract] = xc.NewAmountBlockchainFromUint64(0)
+		a.snapshots[contract] = map[int64]xc.AmountBlockchain{}
+	}
+
+	for height := request.FromHeight; height <= request.ToHeight; height++ {
+		infos, err := auditor.Blocks.FetchBlockTxInfos(ctx, height)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch block %d: %v", height, err)
+		}
+		for _, info := range infos {
+			for contract, change := range BalanceChanges(request.Chain, request.Address, info) {
+				if !audited[contract] {
+					if contract == "" {
+						// fees of token audits
+						continue
+					}
+					a.report.Gaps = append(a.report.Gaps, &Gap{
+						Kind:       GapUnknownToken,
+						Contract:   contract,
+						FromHeight: height,
+						ToHeight:   height,
+						Computed:   change,
+						TxHash:     info.TxID,
+					})
+					continue
+				}
+				if a.deltas[height] == nil {
+					a.deltas[height] = map[xc.ContractAddress]xc.AmountBlockchain{}
+				}
+				a.deltas[height][contract] = add(a.deltas[height][contract], change)
+				a.report.Changes[contract] = add(a.report.Changes[contract], change)
+			}
+		}
+	}
+
+	for _, contract := range contracts {
+		if err := a.reconcile(ctx, contract, request.FromHeight, request.ToHeight); err != nil {
+			return a.report, err
+		}
+	}
+	sort.SliceStable(a.report.Gaps, func(i, j int) bool {
+		return a.report.Gaps[i].FromHeight < a.report.Gaps[j].FromHeight
+	})
+	return a.report, nil
+}
+
+// balanceAt returns the balance at the end of a block, fetched once
+func (a *audit) balanceAt(ctx context.Context, contract xc.ContractAddress, height int64) (xc.AmountBlockchain, error) {
+	if balance, ok := a.snapshots[contract][height]; ok {
+		return balance, nil
+	}
+	if a.report.Snapshots >= a.max {
+		return xc.AmountBlockchain{}, fmt.Errorf("too many balance snapshots, max %d", a.max)
+	}
+	balance, err := a.Balances.FetchBalanceAt(ctx, a.request.Address, contract, height)
+	if err != nil {
+		return balance, fmt.Errorf("could not fetch balance of %s at %d: %v", a.request.Address, height, err)
+	}
+	a.report.Snapshots++
+	a.snapshots[contract][height] = balance
+	return balance, nil
+}
+
+// computed returns the computed change of the blocks from fromHeight to toHeight
+func (a *audit) computed(contract xc.ContractAddress, fromHeight int64, toHeight int64) xc.AmountBlockchain {
+	total := xc.NewAmountBlockchainFromUint64(0)
+	for height := fromHeight; height <= toHeight; height++ {
+		if delta, ok := a.deltas[height][contract]; ok {
+			total = add(total, delta)
+		}
+	}
+	return total
+}
+
+// reconcile compares the computed and actual changes of a range, bisecting it on mismatch
+func (a *audit) reconcile(ctx context.Context, contract xc.ContractAddress, fromHeight int64, toHeight int64) error {
+	before, err := a.balanceAt(ctx, contract, fromHeight-1)
+	if err != nil {
+		return err
+	}
+	after, err := a.balanceAt(ctx, contract, toHeight)
+	if err != nil {
+		return err
+	}
+	actual := sub(after, before)
+	computed := a.computed(contract, fromHeight, toHeight)
+	if actual.Cmp(&computed) == 0 {
+		return nil
+	}
+	if fromHeight == toHeight || a.report.Snapshots+1 > a.max {
+		// located, or the range can't be bisected within the snapshots left
+		a.report.Gaps = append(a.report.Gaps, &Gap{
+			Kind:       GapMissedTransfer,
+			Contract:   contract,
+			FromHeight: fromHeight,
+			ToHeight:   toHeight,
+			Actual:     actual,
+			Computed:   computed,
+		})
+		return nil
+	}
+	middle := fromHeight + (toHeight-fromHeight)/2
+	if err := a.reconcile(ctx, contract, fromHeight, middle); err != nil {
+		return err
+	}
+	return a.reconcile(ctx, contract, middle+1, toHeight)
+}
+
+// normalizeContract normalizes a contract like an address of the chain, so that it matches parsed transfers
+func normalizeContract(chain xc.NativeAsset, contract xc.ContractAddress) xc.ContractAddress {
+	return xc.ContractAddress(factory.NormalizeAddressStringByDriver(string(contract), chain.Driver()))
+}
+
+// BalanceChanges returns the changes of the balances of address in a tx, per contract, empty for the native asset
+// Addresses and contracts are compared once normalized for the chain, e.g. hex addresses are case-insensitive
+// The fee is paid by the sender in the native asset, except on UTXO chains where it's the difference of inputs and outputs
+func BalanceChanges(chain xc.NativeAsset, address xc.Address, info xc.TxInfo) map[xc.ContractAddress]xc.AmountBlockchain {
+	changes := map[xc.ContractAddress]xc.AmountBlockchain{}
+	driver := chain.Driver()
+	normalized := factory.NormalizeAddressStringByDriver(string(address), driver)
+	matches := func(other xc.Address) bool {
+		return other != "" && factory.NormalizeAddressStringByDriver(string(other), driver) == normalized
+	}
+	apply := func(contract xc.ContractAddress, amount xc.AmountBlockchain, sign int) {
+		if amount.Sign() == 0 {
+			return
+		}
+		contract = normalizeContract(chain, contract)
+		if sign < 0 {
+			changes[contract] = sub(changes[contract], amount)
+		} else {
+			changes[contract] = add(changes[contract], amount)
+		}
+	}
+
+	if info.Status == xc.TxStatusSuccess {
+		if len(info.Sources) > 0 {
+			for _, source := range info.Sources {
+				if matches(source.Address) {
+					apply(source.ContractAddress, source.Amount, -1)
+				}
+			}
+		} else if matches(info.From) {
+			apply(info.ContractAddress, info.Amount, -1)
+		}
+		if len(info.Destinations) > 0 {
+			for _, destination := range info.Destinations {
+				if matches(destination.Address) {
+					apply(destination.ContractAddress, destination.Amount, 1)
+				}
+			}
+		} else if matches(info.To) {
+			apply(info.ContractAddress, info.Amount, 1)
+		}
+		for _, change := range info.Change {
+			if matches(change.Address) {
+				apply(change.ContractAddress, change.Amount, 1)
+			}
+		}
+	}
+	if matches(info.From) && chain.ChainType() != xc.ChainTypeUTXO {
+		apply("", info.Fee, -1)
+	}
+	return changes
+}
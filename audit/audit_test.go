@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/evm"
+	"github.com/stretchr/testify/suite"
+)
+
+var _ BalanceSource = &evm.Client{}
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestAuditTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+const address = xc.Address("0xaaaa")
+const other = xc.Address("0xbbbb")
+const usdc = xc.ContractAddress("0xusdc")
+
+type mockChain struct {
+	blocks map[int64][]xc.TxInfo
+	// changes are the actual balance changes per block, including the ones missed by the parser
+	changes  map[xc.ContractAddress]map[int64]uint64
+	spent    map[xc.ContractAddress]map[int64]uint64
+	fetched  int
+	blockErr error
+}
+
+func (chain *mockChain) FetchLatestBlock(ctx context.Context) (int64, error) {
+	return 1000, nil
+}
+
+func (chain *mockChain) FetchBlockTxInfos(ctx context.Context, height int64) ([]xc.TxInfo, error) {
+	return chain.blocks[height], chain.blockErr
+}
+
+func (chain *mockChain) FetchBalanceAt(ctx context.Context, address xc.Address, contract xc.ContractAddress, height int64) (xc.AmountBlockchain, error) {
+	chain.fetched++
+	balance := uint64(1_000_000)
+	for h, change := range chain.changes[contract] {
+		if h <= height {
+			balance += change
+		}
+	}
+	for h, spent := range chain.spent[contract] {
+		if h <= height {
+			balance -= spent
+		}
+	}
+	return xc.NewAmountBlockchainFromUint64(balance), nil
+}
+
+func endpoint(address xc.Address, contract xc.ContractAddress, amount uint64) *xc.TxInfoEndpoint {
+	return &xc.TxInfoEndpoint{Address: address, ContractAddress: contract, Amount: xc.NewAmountBlockchainFromUint64(amount)}
+}
+
+func (s *CrosschainTestSuite) TestBalanceChanges() {
+	require := s.Require()
+	fee := xc.NewAmountBlockchainFromUint64(21)
+
+	// account chain, sent by address
+	changes := BalanceChanges(xc.ETH, address, xc.TxInfo{
+		From:         address,
+		Fee:          fee,
+		Sources:      []*xc.TxInfoEndpoint{endpoint(address, usdc, 500)},
+		Destinations: []*xc.TxInfoEndpoint{endpoint(other, usdc, 500)},
+	})
+	require.Equal("-500", changes[usdc].String())
+	require.Equal("-21", changes[""].String())
+
+	// received, without endpoints
+	changes = BalanceChanges(xc.ETH, address, xc.TxInfo{From: other, To: address, Amount: xc.NewAmountBlockchainFromUint64(300), Fee: fee})
+	require.Equal("300", changes[""].String())
+	require.Len(changes, 1)
+
+	// failed, only the fee is paid
+	changes = BalanceChanges(xc.ETH, address, xc.TxInfo{From: address, To: other, Amount: xc.NewAmountBlockchainFromUint64(300), Fee: fee, Status: xc.TxStatusFailure})
+	require.Equal("-21", changes[""].String())
+
+	// UTXO, the fee is the difference of inputs and outputs
+	changes = BalanceChanges(xc.BTC, address, xc.TxInfo{
+		From:         address,
+		Fee:          fee,
+		Sources:      []*xc.TxInfoEndpoint{endpoint(address, "", 1000)},
+		Destinations: []*xc.TxInfoEndpoint{endpoint(other, "", 700)},
+		Change:       []*xc.TxInfoEndpoint{endpoint(address, "", 279)},
+	})
+	require.Equal("-721", changes[""].String())
+
+	// hex addresses and contracts are case-insensitive
+	changes = BalanceChanges(xc.ETH, "0xAAAA", xc.TxInfo{
+		From:         other,
+		Destinations: []*xc.TxInfoEndpoint{endpoint(address, "0xUSDC", 500)},
+	})
+	require.Equal("500", changes[usdc].String())
+	require.Len(changes, 1)
+
+	// unrelated
+	require.Empty(BalanceChanges(xc.ETH, address, xc.TxInfo{From: other, To: other, Amount: fee, Fee: fee}))
+}
+
+func (s *CrosschainTestSuite) TestAudit() {
+	require := s.Require()
+	chain := &mockChain{
+		blocks: map[int64][]xc.TxInfo{
+			3: {{TxID: "0x3", From: other, To: address, Amount: xc.NewAmountBlockchainFromUint64(300), Fee: xc.NewAmountBlockchainFromUint64(1)}},
+			9: {{TxID: "0x9", From: address, To: other, Amount: xc.NewAmountBlockchainFromUint64(100), Fee: xc.NewAmountBlockchainFromUint64(2)}},
+		},
+		changes: map[xc.ContractAddress]map[int64]uint64{"": {3: 300}},
+		spent:   map[xc.ContractAddress]map[int64]uint64{"": {9: 102}},
+	}
+	auditor := NewAuditor(chain, chain)
+
+	report, err := auditor.Audit(s.Ctx, Request{Chain: xc.ETH, Address: address, FromHeight: 1, ToHeight: 16})
+	require.NoError(err)
+	require.True(report.Reconciled())
+	require.Equal("198", report.Changes[""].String())
+	require.Equal(2, report.Snapshots)
+
+	// internal transfer in block 11, not parsed
+	chain.changes[""][11] = 50
+	report, err = auditor.Audit(s.Ctx, Request{Chain: xc.ETH, Address: address, FromHeight: 1, ToHeight: 16})
+	require.NoError(err)
+	require.False(report.Reconciled())
+	require.Len(report.Gaps, 1)
+	gap := report.Gaps[0]
+	require.Equal(GapMissedTransfer, gap.Kind)
+	require.EqualValues(11, gap.FromHeight)
+	require.EqualValues(11, gap.ToHeight)
+	require.Equal("50", gap.Actual.String())
+	require.Equal("0", gap.Computed.String())
+	require.Equal("50", gap.Missing().String())
+	// bisected with a snapshot per halving
+	require.Equal(2+4, report.Snapshots)
+
+	// not enough snapshots to locate the gap
+	report, err = auditor.Audit(s.Ctx, Request{Chain: xc.ETH, Address: address, FromHeight: 1, ToHeight: 16, MaxSnapshots: 3})
+	require.NoError(err)
+	require.Len(report.Gaps, 1)
+	require.EqualValues(9, report.Gaps[0].FromHeight)
+	require.EqualValues(16, report.Gaps[0].ToHeight)
+	require.Equal("-52", report.Gaps[0].Actual.String())
+	require.Equal("-102", report.Gaps[0].Computed.String())
+	require.Equal(3, report.Snapshots)
+}
+
+func (s *CrosschainTestSuite) TestAuditTokens() {
+	require := s.Require()
+	chain := &mockChain{
+		blocks: map[int64][]xc.TxInfo{
+			5: {{
+				TxID:         "0x5",
+				From:         address,
+				Fee:          xc.NewAmountBlockchainFromUint64(2),
+				Sources:      []*xc.TxInfoEndpoint{endpoint(address, usdc, 400)},
+				Destinations: []*xc.TxInfoEndpoint{endpoint(other, usdc, 400)},
+			}},
+			6: {{
+				TxID:         "0x6",
+				From:         other,
+				Destinations: []*xc.TxInfoEndpoint{endpoint(address, "0xairdrop", 1)},
+			}},
+		},
+		spent: map[xc.ContractAddress]map[int64]uint64{usdc: {5: 400}},
+	}
+	report, err := NewAuditor(chain, chain).Audit(s.Ctx, Request{Chain: xc.ETH, Address: address, FromHeight: 1, ToHeight: 8, Contracts: []xc.ContractAddress{"0xUSDC"}})
+	require.NoError(err)
+	require.Equal("-400", report.Changes[usdc].String())
+	// native fees aren't audited, unknown tokens are flagged
+	require.Len(report.Gaps, 1)
+	require.Equal(GapUnknownToken, report.Gaps[0].Kind)
+	require.Equal(xc.ContractAddress("0xairdrop"), report.Gaps[0].Contract)
+	require.Equal("0x6", report.Gaps[0].TxHash)
+	require.EqualValues(6, report.Gaps[0].FromHeight)
+}
+
+func (s *CrosschainTestSuite) TestAuditErrors() {
+	require := s.Require()
+	chain := &mockChain{}
+	auditor := NewAuditor(chain, chain)
+
+	_, err := auditor.Audit(s.Ctx, Request{Chain: xc.ETH, FromHeight: 1, ToHeight: 2})
+	require.ErrorContains(err, "address is required")
+	_, err = auditor.Audit(s.Ctx, Request{Chain: xc.ETH, Address: address, FromHeight: 5, ToHeight: 2})
+	require.ErrorContains(err, "invalid block range")
+	_, err = auditor.Audit(s.Ctx, Request{Chain: xc.ETH, Address: address, FromHeight: 0, ToHeight: 2})
+	require.ErrorContains(err, "invalid block range")
+
+	chain.blockErr = errors.New("block pruned")
+	_, err = auditor.Audit(s.Ctx, Request{Chain: xc.ETH, Address: address, FromHeight: 1, ToHeight: 2})
+	require.ErrorContains(err, "could not fetch block 1: block pruned")
+}
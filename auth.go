@@ -0,0 +1,306 @@
+package crosschain
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RequestSigning is the scheme used to sign each request to a provider API
+type RequestSigning string
+
+// List of supported RequestSigning
+const (
+	// static credentials only, e.g. in the URL
+	RequestSigningNone = RequestSigning("")
+	// HMAC-SHA256 of timestamp+method+path+body, e.g. Coinbase Cloud
+	RequestSigningHMAC = RequestSigning("hmac-sha256")
+	// JWT bound to the path and body of the request, e.g. Fireblocks
+	RequestSigningJWT = RequestSigning("jwt")
+)
+
+// RequestSigner signs requests to a provider API, setting headers from the request and its body
+type RequestSigner interface {
+	SignRequest(req *http.Request, body []byte) error
+}
+
+// RequestSignerFunc is a func implementing RequestSigner
+type RequestSignerFunc func(req *http.Request, body []byte) error
+
+// SignRequest calls f
+func (f RequestSignerFunc) SignRequest(req *http.Request, body []byte) error {
+	return f(req, body)
+}
+
+// Default headers of HMACSigner
+const (
+	DefaultHMACKeyHeader       = "X-Api-Key"
+	DefaultHMACTimestampHeader = "X-Timestamp"
+	DefaultHMACSignatureHeader = "X-Signature"
+)
+
+// HMACSigner signs requests with hex(HMAC-SHA256(secret, timestamp + method + path + body))
+type HMACSigner struct {
+	KeyID  string
+	Secret string
+	// Headers set on requests, defaults to DefaultHMAC*Header
+	KeyHeader       string
+	TimestampHeader string
+	SignatureHeader string
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+var _ RequestSigner = &HMACSigner{}
+
+// SignRequest sets the key, timestamp and signature headers
+func (signer *HMACSigner) SignRequest(req *http.Request, body []byte) error {
+	if signer.Secret == "" {
+		return errors.New("missing secret for hmac request signing")
+	}
+	now := time.Now
+	if signer.Now != nil {
+		now = signer.Now
+	}
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(signer.Secret))
+	mac.Write([]byte(timestamp + req.Method + req.URL.RequestURI()))
+	mac.Write(body)
+
+	req.Header.Set(headerOrDefault(signer.KeyHeader, DefaultHMACKeyHeader), signer.KeyID)
+	req.Header.Set(headerOrDefault(signer.TimestampHeader, DefaultHMACTimestampHeader), timestamp)
+	req.Header.Set(headerOrDefault(signer.SignatureHeader, DefaultHMACSignatureHeader), hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+func headerOrDefault(header string, defaultHeader string) string {
+	if header == "" {
+		return defaultHeader
+	}
+	return header
+}
+
+// DefaultJWTExpiration is the validity of the JWT of a request
+const DefaultJWTExpiration = 30 * time.Second
+
+// JWTSigner signs requests with a short lived JWT, sent as a bearer token along the API key
+// Claims bind the token to the request: uri, nonce, iat, exp, sub (API key) and bodyHash (hex SHA-256 of the body)
+type JWTSigner struct {
+	KeyID      string
+	PrivateKey crypto.Signer
+	Expiration time.Duration
+	// Now returns the current time, defaults to time.Now
+	Now func() time.Time
+}
+
+var _ RequestSigner = &JWTSigner{}
+
+// NewJWTSigner creates a JWTSigner from a PEM encoded RSA (RS256), P-256 (ES256) or Ed25519 (EdDSA) private key
+func NewJWTSigner(keyID string, privateKeyPEM string) (*JWTSigner, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("invalid private key for jwt request signing: no PEM block")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key for jwt request signing: %v", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key for jwt request signing: %T", key)
+	}
+	if _, err := jwtAlgorithm(signer); err != nil {
+		return nil, err
+	}
+	return &JWTSigner{
+		KeyID:      keyID,
+		PrivateKey: signer,
+		Expiration: DefaultJWTExpiration,
+	}, nil
+}
+
+func jwtAlgorithm(key crypto.Signer) (string, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return "RS256", nil
+	case *ecdsa.PrivateKey:
+		if key.Curve.Params().BitSize != 256 {
+			return "", fmt.Errorf("unsupported curve for jwt request signing: %s", key.Curve.Params().Name)
+		}
+		return "ES256", nil
+	case ed25519.PrivateKey:
+		return "EdDSA", nil
+	}
+	return "", fmt.Errorf("unsupported private key for jwt request signing: %T", key)
+}
+
+// SignRequest sets the Authorization and X-API-Key headers
+func (signer *JWTSigner) SignRequest(req *http.Request, body []byte) error {
+	token, err := signer.Token(req.URL.RequestURI(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-API-Key", signer.KeyID)
+	return nil
+}
+
+// Token returns a signed JWT for a request to uri with body
+func (signer *JWTSigner) Token(uri string, body []byte) (string, error) {
+	algorithm, err := jwtAlgorithm(signer.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now
+	if signer.Now != nil {
+		now = signer.Now
+	}
+	expiration := signer.Expiration
+	if expiration == 0 {
+		expiration = DefaultJWTExpiration
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	bodyHash := sha256.Sum256(body)
+	issuedAt := now().Unix()
+
+	header, _ := json.Marshal(map[string]string{"alg": algorithm, "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"uri":      uri,
+		"nonce":    hex.EncodeToString(nonce),
+		"iat":      issuedAt,
+		"exp":      issuedAt + int64(expiration/time.Second),
+		"sub":      signer.KeyID,
+		"bodyHash": hex.EncodeToString(bodyHash[:]),
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	var signature []byte
+	switch key := signer.PrivateKey.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(signingInput))
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signingInput))
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			return "", err
+		}
+		// JWS uses the fixed size r||s encoding
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	default:
+		digest := sha256.Sum256([]byte(signingInput))
+		signature, err = signer.PrivateKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+		if err != nil {
+			return "", err
+		}
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// SigningTransport is a http.RoundTripper signing each request before sending it
+type SigningTransport struct {
+	Transport http.RoundTripper
+	Signer    RequestSigner
+}
+
+var _ http.RoundTripper = &SigningTransport{}
+
+// NewSigningTransport creates a SigningTransport, transport defaults to http.DefaultTransport
+func NewSigningTransport(transport http.RoundTripper, signer RequestSigner) *SigningTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &SigningTransport{
+		Transport: transport,
+		Signer:    signer,
+	}
+}
+
+// RoundTrip signs a copy of req and sends it
+func (t *SigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	signed := req.Clone(req.Context())
+	signed.Body = io.NopCloser(bytes.NewReader(body))
+	signed.ContentLength = int64(len(body))
+	if err := t.Signer.SignRequest(signed, body); err != nil {
+		return nil, fmt.Errorf("could not sign request: %v", err)
+	}
+	return t.Transport.RoundTrip(signed)
+}
+
+// GetRequestSigner returns the request signer of a chain: the RequestSigner hook if set,
+// or a signer for the configured request_signing with auth_key_id and the auth secret
+// Returns nil if requests aren't signed
+func (asset *NativeAssetConfig) GetRequestSigner() (RequestSigner, error) {
+	if asset.RequestSigner != nil {
+		return asset.RequestSigner, nil
+	}
+	switch asset.RequestSigning {
+	case RequestSigningNone:
+		return nil, nil
+	case RequestSigningHMAC:
+		return &HMACSigner{KeyID: asset.AuthKeyID, Secret: asset.AuthSecret}, nil
+	case RequestSigningJWT:
+		return NewJWTSigner(asset.AuthKeyID, asset.AuthSecret)
+	}
+	return nil, fmt.Errorf("unsupported request signing: '%s'", asset.RequestSigning)
+}
+
+// HTTPTransport wraps transport to sign requests to the chain RPC and fail over to other endpoints, if configured
+// Requests are only sent to endpoints in allowed regions, see AllowedEndpoints, and to other hosts in allowed
+// regions, see HostAllowed
+func (asset *NativeAssetConfig) HTTPTransport(transport http.RoundTripper) (http.RoundTripper, error) {
+	signer, err := asset.GetRequestSigner()
+	if err != nil {
+		return transport, err
+	}
+	if signer != nil {
+		transport = NewSigningTransport(transport, signer)
+	}
+	if len(asset.Endpoints) > 0 || asset.hasRegionConstraints() {
+		endpoints, err := asset.AllowedEndpoints()
+		if err != nil {
+			return transport, err
+		}
+		failover := NewFailoverTransport(transport, asset.URL, endpoints)
+		failover.AllowHost = asset.HostAllowed
+		transport = failover
+	}
+	return transport, nil
+}
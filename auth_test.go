@@ -0,0 +1,210 @@
+package crosschain
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func (s *CrosschainTestSuite) TestHMACSigner() {
+	require := s.Require()
+	signer := &HMACSigner{
+		KeyID:  "key",
+		Secret: "secret",
+		Now:    func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	req, _ := http.NewRequest("POST", "https://api.example.com/v2/rpc?chain=eth", nil)
+	body := []byte(`{"method":"eth_blockNumber"}`)
+	require.NoError(signer.SignRequest(req, body))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000POST/v2/rpc?chain=eth" + string(body)))
+	require.Equal("key", req.Header.Get(DefaultHMACKeyHeader))
+	require.Equal("1700000000", req.Header.Get(DefaultHMACTimestampHeader))
+	require.Equal(hex.EncodeToString(mac.Sum(nil)), req.Header.Get(DefaultHMACSignatureHeader))
+
+	signer.KeyHeader = "CB-ACCESS-KEY"
+	signer.SignatureHeader = "CB-ACCESS-SIGN"
+	signer.TimestampHeader = "CB-ACCESS-TIMESTAMP"
+	require.NoError(signer.SignRequest(req, body))
+	require.Equal("key", req.Header.Get("CB-ACCESS-KEY"))
+	require.Equal(req.Header.Get(DefaultHMACSignatureHeader), req.Header.Get("CB-ACCESS-SIGN"))
+
+	signer.Secret = ""
+	require.EqualError(signer.SignRequest(req, body), "missing secret for hmac request signing")
+}
+
+func decodeJWT(s *CrosschainTestSuite, token string) (header map[string]string, claims map[string]interface{}, signingInput string, signature []byte) {
+	require := s.Require()
+	parts := strings.Split(token, ".")
+	require.Len(parts, 3)
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(err)
+	require.NoError(json.Unmarshal(data, &header))
+	data, err = base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(err)
+	require.NoError(json.Unmarshal(data, &claims))
+	signature, err = base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(err)
+	return header, claims, parts[0] + "." + parts[1], signature
+}
+
+func (s *CrosschainTestSuite) TestJWTSigner() {
+	require := s.Require()
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(ed25519Key)
+	ec, _ := x509.MarshalECPrivateKey(ecdsaKey)
+	vectors := []struct {
+		pem       string
+		algorithm string
+		verify    func(digest []byte, signingInput string, signature []byte) bool
+	}{
+		{
+			string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})),
+			"EdDSA",
+			func(_ []byte, signingInput string, signature []byte) bool {
+				return ed25519.Verify(ed25519Key.Public().(ed25519.PublicKey), []byte(signingInput), signature)
+			},
+		},
+		{
+			string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ec})),
+			"ES256",
+			func(digest []byte, _ string, signature []byte) bool {
+				r := new(big.Int).SetBytes(signature[:32])
+				s := new(big.Int).SetBytes(signature[32:])
+				return len(signature) == 64 && ecdsa.Verify(&ecdsaKey.PublicKey, digest, r, s)
+			},
+		},
+		{
+			string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})),
+			"RS256",
+			func(digest []byte, _ string, signature []byte) bool {
+				return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, 5, digest, signature) == nil
+			},
+		},
+	}
+	body := []byte(`{"jsonrpc":"2.0"}`)
+	bodyHash := sha256.Sum256(body)
+	for _, v := range vectors {
+		signer, err := NewJWTSigner("api-key", v.pem)
+		require.NoError(err, v.algorithm)
+		signer.Now = func() time.Time { return time.Unix(1700000000, 0) }
+
+		req, _ := http.NewRequest("POST", "https://api.example.com/v1/rpc?x=1", nil)
+		require.NoError(signer.SignRequest(req, body))
+		require.Equal("api-key", req.Header.Get("X-API-Key"))
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+
+		header, claims, signingInput, signature := decodeJWT(s, token)
+		require.Equal(v.algorithm, header["alg"])
+		require.Equal("JWT", header["typ"])
+		require.Equal("/v1/rpc?x=1", claims["uri"])
+		require.Equal("api-key", claims["sub"])
+		require.Equal(float64(1700000000), claims["iat"])
+		require.Equal(float64(1700000030), claims["exp"])
+		require.Equal(hex.EncodeToString(bodyHash[:]), claims["bodyHash"])
+		require.Len(claims["nonce"], 32)
+		digest := sha256.Sum256([]byte(signingInput))
+		require.True(v.verify(digest[:], signingInput, signature), v.algorithm)
+	}
+}
+
+func (s *CrosschainTestSuite) TestNewJWTSignerErrors() {
+	require := s.Require()
+	_, err := NewJWTSigner("key", "not a key")
+	require.EqualError(err, "invalid private key for jwt request signing: no PEM block")
+
+	_, err = NewJWTSigner("key", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1, 2}})))
+	require.ErrorContains(err, "invalid private key for jwt request signing")
+
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	ec, _ := x509.MarshalECPrivateKey(ecdsaKey)
+	_, err = NewJWTSigner("key", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ec})))
+	require.EqualError(err, "unsupported curve for jwt request signing: P-384")
+}
+
+func (s *CrosschainTestSuite) TestSigningTransport() {
+	require := s.Require()
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received = req
+		receivedBody, _ = io.ReadAll(req.Body)
+		rw.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	signed := []byte{}
+	transport := NewSigningTransport(nil, RequestSignerFunc(func(req *http.Request, body []byte) error {
+		signed = body
+		req.Header.Set("X-Signature", "signature")
+		return nil
+	}))
+	client := &http.Client{Transport: transport}
+	res, err := client.Post(server.URL, "application/json", strings.NewReader(`{"id":1}`))
+	require.NoError(err)
+	res.Body.Close()
+	require.Equal(`{"id":1}`, string(signed))
+	require.Equal(`{"id":1}`, string(receivedBody))
+	require.Equal("signature", received.Header.Get("X-Signature"))
+
+	transport.Signer = RequestSignerFunc(func(req *http.Request, body []byte) error {
+		return errors.New("no key")
+	})
+	_, err = client.Get(server.URL)
+	require.ErrorContains(err, "could not sign request: no key")
+}
+
+func (s *CrosschainTestSuite) TestGetRequestSigner() {
+	require := s.Require()
+	asset := &NativeAssetConfig{}
+	signer, err := asset.GetRequestSigner()
+	require.NoError(err)
+	require.Nil(signer)
+	transport, err := asset.HTTPTransport(http.DefaultTransport)
+	require.NoError(err)
+	require.Equal(http.DefaultTransport, transport)
+
+	asset = &NativeAssetConfig{RequestSigning: RequestSigningHMAC, AuthKeyID: "key", AuthSecret: "secret"}
+	signer, err = asset.GetRequestSigner()
+	require.NoError(err)
+	require.Equal(&HMACSigner{KeyID: "key", Secret: "secret"}, signer)
+	transport, err = asset.HTTPTransport(http.DefaultTransport)
+	require.NoError(err)
+	require.IsType(&SigningTransport{}, transport)
+
+	asset = &NativeAssetConfig{RequestSigning: RequestSigningJWT, AuthKeyID: "key", AuthSecret: "secret"}
+	_, err = asset.GetRequestSigner()
+	require.ErrorContains(err, "invalid private key")
+	_, err = asset.HTTPTransport(http.DefaultTransport)
+	require.ErrorContains(err, "invalid private key")
+
+	// the hook takes precedence
+	hook := RequestSignerFunc(func(req *http.Request, body []byte) error { return nil })
+	asset.RequestSigner = hook
+	signer, err = asset.GetRequestSigner()
+	require.NoError(err)
+	require.NotNil(signer)
+
+	asset = &NativeAssetConfig{RequestSigning: "oauth"}
+	_, err = asset.GetRequestSigner()
+	require.EqualError(err, "unsupported request signing: 'oauth'")
+}
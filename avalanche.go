@@ -0,0 +1,102 @@
+package crosschain
+
+import "time"
+
+// AvalancheConfig is the config of the network of an Avalanche X-Chain or P-Chain: known networks complete it, see
+// GetAvalanche
+type AvalancheConfig struct {
+	// NetworkID is the id of the network, e.g. 1 on mainnet and 5 on Fuji
+	NetworkID uint32 `yaml:"network_id"`
+	// HRP is the human readable part of the bech32 addresses of the network, e.g. avax or fuji
+	HRP string `yaml:"hrp"`
+	// AssetID is the cb58 id of AVAX on the network
+	AssetID string `yaml:"asset_id"`
+	// ChainIDs are the cb58 ids of the chains of the network by alias: X, P and C
+	ChainIDs map[string]string `yaml:"chain_ids"`
+	// TxFee is the fee of txs in nAVAX, DefaultAvalancheTxFee if not set
+	TxFee uint64 `yaml:"tx_fee"`
+	// DelegationPeriod is the time stake is delegated for on the P-Chain, the minimum of the network if not set
+	DelegationPeriod time.Duration `yaml:"delegation_period"`
+}
+
+// DefaultAvalancheTxFee is the fee of the txs of the X-Chain and of the atomic txs of the P-Chain, in nAVAX
+const DefaultAvalancheTxFee = 1_000_000
+
+// Aliases of the chains of Avalanche
+const (
+	AvalancheXChain = "X"
+	AvalanchePChain = "P"
+	AvalancheCChain = "C"
+)
+
+// KnownAvalancheNetworks are the templates of Avalanche networks by net, Fuji for testnets
+var KnownAvalancheNetworks = map[Net]AvalancheConfig{
+	Mainnet: {
+		NetworkID: 1,
+		HRP:       "avax",
+		AssetID:   "FvwEAhmxKfeiG8SnEvq42hc6whRyY3EFYAvebMqDNDGCgxN5Z",
+		ChainIDs: map[string]string{
+			AvalancheXChain: "2oYMBNV4eNHyqk2fjjV5nVQLDbtmNJzq5s3qs3Lo6ftnC6FByM",
+			AvalanchePChain: "11111111111111111111111111111111LpoYY",
+			AvalancheCChain: "2q9e4r6Mu3U68nU1fYjgbR6JvwrRx36CohpAX5UQxse55x1Q5",
+		},
+		DelegationPeriod: 14 * 24 * time.Hour,
+	},
+	Fuji: {
+		NetworkID: 5,
+		HRP:       "fuji",
+		AssetID:   "U8iRqJoiJm8xZHAacmvYyZVwqQx6uDNtQeP3CQ6fcgQk3JqnK",
+		ChainIDs: map[string]string{
+			AvalancheXChain: "2JVSBoinj9C2J33VntvzYtVJNZdN2NKiwwKjcumHUWEb5DbBrm",
+			AvalanchePChain: "11111111111111111111111111111111LpoYY",
+			AvalancheCChain: "yH8D7ThNJkxmtkuv2jgBa4P1Rn3Qpr4pPr7QYNfcdoS6k6HWp",
+		},
+		DelegationPeriod: 24 * time.Hour,
+	},
+}
+
+// AvalancheChainAlias returns the alias of a chain of Avalanche, empty for other chains
+func AvalancheChainAlias(native NativeAsset) string {
+	switch native {
+	case XAVAX:
+		return AvalancheXChain
+	case PAVAX:
+		return AvalanchePChain
+	case AVAX:
+		return AvalancheCChain
+	}
+	return ""
+}
+
+// GetAvalanche returns the Avalanche config of a chain, completed with the template of its network
+func (asset *NativeAssetConfig) GetAvalanche() AvalancheConfig {
+	avalanche := asset.Avalanche
+	known, ok := KnownAvalancheNetworks[asset.Net]
+	if !ok && asset.Net.Kind() == Testnet {
+		known = KnownAvalancheNetworks[Fuji]
+	}
+	if avalanche.NetworkID == 0 {
+		avalanche.NetworkID = known.NetworkID
+	}
+	if avalanche.HRP == "" {
+		avalanche.HRP = known.HRP
+	}
+	if avalanche.AssetID == "" {
+		avalanche.AssetID = known.AssetID
+	}
+	chainIDs := map[string]string{}
+	for alias, id := range known.ChainIDs {
+		chainIDs[alias] = id
+	}
+	for alias, id := range avalanche.ChainIDs {
+		chainIDs[alias] = id
+	}
+	avalanche.ChainIDs = chainIDs
+	if avalanche.TxFee == 0 {
+		avalanche.TxFee = DefaultAvalancheTxFee
+	}
+	if avalanche.DelegationPeriod == 0 {
+		avalanche.DelegationPeriod = known.DelegationPeriod
+	}
+	return avalanche
+}
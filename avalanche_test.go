@@ -0,0 +1,33 @@
+package crosschain
+
+func (s *CrosschainTestSuite) TestGetAvalanche() {
+	require := s.Require()
+
+	asset := &NativeAssetConfig{NativeAsset: XAVAX, Net: Mainnet}
+	avalanche := asset.GetAvalanche()
+	require.EqualValues(1, avalanche.NetworkID)
+	require.Equal("avax", avalanche.HRP)
+	require.Equal(KnownAvalancheNetworks[Mainnet].AssetID, avalanche.AssetID)
+	require.Equal(KnownAvalancheNetworks[Mainnet].ChainIDs[AvalancheXChain], avalanche.ChainIDs[AvalancheXChain])
+	require.EqualValues(DefaultAvalancheTxFee, avalanche.TxFee)
+
+	// testnets default to Fuji
+	asset = &NativeAssetConfig{NativeAsset: PAVAX, Net: Testnet, Avalanche: AvalancheConfig{TxFee: 2, ChainIDs: map[string]string{AvalancheXChain: "x"}}}
+	avalanche = asset.GetAvalanche()
+	require.EqualValues(5, avalanche.NetworkID)
+	require.Equal("fuji", avalanche.HRP)
+	require.EqualValues(2, avalanche.TxFee)
+	require.Equal("x", avalanche.ChainIDs[AvalancheXChain])
+	require.Equal(KnownAvalancheNetworks[Fuji].ChainIDs[AvalanchePChain], avalanche.ChainIDs[AvalanchePChain])
+	// the template isn't modified
+	require.NotEqual("x", KnownAvalancheNetworks[Fuji].ChainIDs[AvalancheXChain])
+
+	require.Equal(AvalancheXChain, AvalancheChainAlias(XAVAX))
+	require.Equal(AvalanchePChain, AvalancheChainAlias(PAVAX))
+	require.Equal(AvalancheCChain, AvalancheChainAlias(AVAX))
+	require.Equal("", AvalancheChainAlias(ETH))
+
+	require.Equal(DriverAvalanche, XAVAX.Driver())
+	require.Equal(K256, PAVAX.SignatureAlgorithm())
+	require.EqualValues(9, PAVAX.Decimals())
+}
@@ -0,0 +1,126 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+)
+
+// DefaultConcurrency is the number of concurrent requests to the node of a chain
+const DefaultConcurrency = 8
+
+// Lookup is a tx of an asset to fetch
+type Lookup struct {
+	Asset  xc.ITask
+	TxHash xc.TxHash
+}
+
+// Result is the TxInfo of a Lookup, or the error fetching it
+type Result struct {
+	Lookup
+	TxInfo xc.TxInfo
+	Err    error
+}
+
+// Fetcher fetches the TxInfo of txs of any asset, e.g. for reconciliation jobs checking historical txs
+type Fetcher struct {
+	Factory factory.FactoryContext
+	// Concurrency is the number of concurrent requests per chain, DefaultConcurrency if zero
+	Concurrency int
+
+	mu      sync.Mutex
+	clients map[xc.AssetID]xc.Client
+}
+
+// NewFetcher creates a new Fetcher creating the clients of assets with f
+func NewFetcher(f factory.FactoryContext) *Fetcher {
+	return &Fetcher{
+		Factory:     f,
+		Concurrency: DefaultConcurrency,
+		clients:     map[xc.AssetID]xc.Client{},
+	}
+}
+
+// FetchTxInfos fetches the txs of lookups concurrently, chains in parallel, and returns a Result per lookup, in order
+// A failed lookup doesn't fail the others: its Result has an Err
+func (fetcher *Fetcher) FetchTxInfos(ctx context.Context, lookups []Lookup) []*Result {
+	concurrency := fetcher.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	// a semaphore per chain, so a slow chain doesn't hold back the others
+	semaphores := map[xc.NativeAsset]chan struct{}{}
+	results := make([]*Result, len(lookups))
+	var wg sync.WaitGroup
+	for i, lookup := range lookups {
+		results[i] = &Result{Lookup: lookup}
+		if lookup.Asset == nil {
+			results[i].Err = errors.New("lookup has no asset")
+			continue
+		}
+		chain := lookup.Asset.GetNativeAsset().NativeAsset
+		semaphore, ok := semaphores[chain]
+		if !ok {
+			semaphore = make(chan struct{}, concurrency)
+			semaphores[chain] = semaphore
+		}
+		wg.Add(1)
+		go func(result *Result, semaphore chan struct{}) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return
+			}
+			result.TxInfo, result.Err = fetcher.fetchTxInfo(ctx, result.Lookup)
+		}(results[i], semaphore)
+	}
+	wg.Wait()
+	return results
+}
+
+func (fetcher *Fetcher) fetchTxInfo(ctx context.Context, lookup Lookup) (xc.TxInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return xc.TxInfo{}, err
+	}
+	client, err := fetcher.client(lookup.Asset)
+	if err != nil {
+		return xc.TxInfo{}, fmt.Errorf("could not create client of %s: %v", lookup.Asset.ID(), err)
+	}
+	return client.FetchTxInfo(ctx, lookup.TxHash)
+}
+
+// client returns the client of asset, created once
+func (fetcher *Fetcher) client(asset xc.ITask) (xc.Client, error) {
+	fetcher.mu.Lock()
+	defer fetcher.mu.Unlock()
+	if fetcher.clients == nil {
+		fetcher.clients = map[xc.AssetID]xc.Client{}
+	}
+	if client, ok := fetcher.clients[asset.ID()]; ok {
+		return client, nil
+	}
+	client, err := fetcher.Factory.NewClient(asset)
+	if err != nil {
+		return nil, err
+	}
+	fetcher.clients[asset.ID()] = client
+	return client, nil
+}
+
+// Failed returns the results that failed, e.g. to retry them
+func Failed(results []*Result) []*Result {
+	failed := []*Result{}
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
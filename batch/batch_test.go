@@ -0,0 +1,117 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestBatchTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func newTestFetcher(clients map[xc.NativeAsset]*testutil.MockedClient, created *int32) (*Fetcher, *testutil.TestFactory) {
+	f := testutil.NewDefaultFactory()
+	f.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		atomic.AddInt32(created, 1)
+		client, ok := clients[asset.GetNativeAsset().NativeAsset]
+		if !ok {
+			return nil, errors.New("no client")
+		}
+		return client, nil
+	}
+	return NewFetcher(&f), &f
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfos() {
+	require := s.Require()
+	eth := &testutil.MockedClient{}
+	eth.On("FetchTxInfo", mock.Anything, xc.TxHash("0x01")).Return(xc.TxInfo{TxID: "0x01", BlockIndex: 1}, nil)
+	eth.On("FetchTxInfo", mock.Anything, xc.TxHash("0x02")).Return(xc.TxInfo{}, errors.New("not found"))
+	sol := &testutil.MockedClient{}
+	sol.On("FetchTxInfo", mock.Anything, xc.TxHash("5x")).Return(xc.TxInfo{TxID: "5x", BlockIndex: 2}, nil)
+	created := int32(0)
+	fetcher, f := newTestFetcher(map[xc.NativeAsset]*testutil.MockedClient{xc.ETH: eth, xc.SOL: sol}, &created)
+
+	ethAsset, _ := f.GetAssetConfig("", "ETH")
+	solAsset, _ := f.GetAssetConfig("", "SOL")
+	maticAsset, _ := f.GetAssetConfig("", "MATIC")
+	results := fetcher.FetchTxInfos(s.Ctx, []Lookup{
+		{Asset: ethAsset, TxHash: "0x01"},
+		{Asset: solAsset, TxHash: "5x"},
+		{Asset: ethAsset, TxHash: "0x02"},
+		{Asset: maticAsset, TxHash: "0x03"},
+		{TxHash: "0x04"},
+	})
+	require.Len(results, 5)
+	require.NoError(results[0].Err)
+	require.Equal("0x01", results[0].TxInfo.TxID)
+	require.NoError(results[1].Err)
+	require.EqualValues(2, results[1].TxInfo.BlockIndex)
+	require.EqualError(results[2].Err, "not found")
+	require.EqualError(results[3].Err, "could not create client of MATIC: no client")
+	require.EqualError(results[4].Err, "lookup has no asset")
+	require.Equal(xc.TxHash("0x04"), results[4].TxHash)
+
+	failed := Failed(results)
+	require.Len(failed, 3)
+	require.Equal(xc.TxHash("0x02"), failed[0].TxHash)
+
+	// clients are created once per asset
+	require.EqualValues(3, created)
+	fetcher.FetchTxInfos(s.Ctx, []Lookup{{Asset: ethAsset, TxHash: "0x01"}})
+	require.EqualValues(3, created)
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfosConcurrency() {
+	require := s.Require()
+	inFlight := int32(0)
+	maxInFlight := int32(0)
+	eth := &testutil.MockedClient{}
+	eth.On("FetchTxInfo", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}).Return(xc.TxInfo{}, nil)
+	created := int32(0)
+	fetcher, f := newTestFetcher(map[xc.NativeAsset]*testutil.MockedClient{xc.ETH: eth}, &created)
+	fetcher.Concurrency = 2
+
+	ethAsset, _ := f.GetAssetConfig("", "ETH")
+	lookups := []Lookup{}
+	for i := 0; i < 10; i++ {
+		lookups = append(lookups, Lookup{Asset: ethAsset, TxHash: "0x01"})
+	}
+	results := fetcher.FetchTxInfos(s.Ctx, lookups)
+	require.Empty(Failed(results))
+	require.LessOrEqual(maxInFlight, int32(2))
+
+	// cancelled
+	ctx, cancel := context.WithCancel(s.Ctx)
+	cancel()
+	results = fetcher.FetchTxInfos(ctx, lookups)
+	require.Len(Failed(results), 10)
+	require.ErrorIs(results[0].Err, context.Canceled)
+}
@@ -0,0 +1,220 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+)
+
+// Transfer is a planned transfer of a payout run
+type Transfer struct {
+	Asset  xc.ITask
+	From   xc.Address
+	To     xc.Address
+	Amount xc.AmountBlockchain
+}
+
+// WalletBudget is the native asset a wallet needs for its planned transfers on a chain
+type WalletBudget struct {
+	Chain     xc.NativeAsset `json:"chain"`
+	Address   xc.Address     `json:"address"`
+	Transfers int            `json:"transfers"`
+	// Fees is the sum of the max fees of the transfers, after the fee multiplier
+	Fees xc.AmountBlockchain `json:"fees"`
+	// Amount is the sum of the native amounts transferred
+	Amount xc.AmountBlockchain `json:"amount"`
+	// Required is Fees plus Amount
+	Required xc.AmountBlockchain `json:"required"`
+	Balance  xc.AmountBlockchain `json:"balance"`
+	// Shortfall is the native amount to top up before starting, zero if the balance is enough
+	Shortfall xc.AmountBlockchain `json:"shortfall"`
+}
+
+// Failure is a planned transfer whose fee couldn't be estimated
+type Failure struct {
+	Transfer *Transfer  `json:"-"`
+	Asset    xc.AssetID `json:"asset"`
+	From     xc.Address `json:"from"`
+	To       xc.Address `json:"to"`
+	Error    string     `json:"error"`
+}
+
+// Report is the pre-flight budget of a batch of planned transfers
+type Report struct {
+	// Wallets sorted by chain and address
+	Wallets []*WalletBudget `json:"wallets"`
+	// Failures are not included in the budget of their wallet
+	Failures []*Failure `json:"failures"`
+}
+
+// Shortfalls returns the wallets to top up
+func (report *Report) Shortfalls() []*WalletBudget {
+	wallets := []*WalletBudget{}
+	for _, wallet := range report.Wallets {
+		if wallet.Shortfall.Sign() > 0 {
+			wallets = append(wallets, wallet)
+		}
+	}
+	return wallets
+}
+
+// txWithFee is a Tx that can report its fee before being submitted
+type txWithFee interface {
+	Fee() xc.AmountBlockchain
+}
+
+// Planner estimates the native asset required by planned transfers, per chain and sending wallet
+type Planner struct {
+	Factory factory.FactoryContext
+	// FeeMultiplier is applied to estimated fees as a margin for fee changes during the run, 1 if zero
+	FeeMultiplier float64
+}
+
+// NewPlanner creates a new Planner
+func NewPlanner(f factory.FactoryContext) *Planner {
+	return &Planner{
+		Factory:       f,
+		FeeMultiplier: 1,
+	}
+}
+
+type walletKey struct {
+	chain   xc.NativeAsset
+	address xc.Address
+}
+
+// Plan estimates the fee of each planned transfer by building its tx with fetched tx input,
+// then compares the native asset required per wallet with its balance
+func (planner *Planner) Plan(ctx context.Context, transfers []*Transfer) (*Report, error) {
+	report := &Report{
+		Wallets:  []*WalletBudget{},
+		Failures: []*Failure{},
+	}
+	wallets := map[walletKey]*WalletBudget{}
+	clients := map[xc.AssetID]xc.Client{}
+
+	for _, transfer := range transfers {
+		client, ok := clients[transfer.Asset.ID()]
+		if !ok {
+			var err error
+			client, err = planner.Factory.NewClient(transfer.Asset)
+			if err != nil {
+				return nil, err
+			}
+			clients[transfer.Asset.ID()] = client
+		}
+		fee, err := planner.estimateFee(ctx, client, transfer)
+		if err != nil {
+			report.Failures = append(report.Failures, &Failure{
+				Transfer: transfer,
+				Asset:    transfer.Asset.ID(),
+				From:     transfer.From,
+				To:       transfer.To,
+				Error:    err.Error(),
+			})
+			continue
+		}
+
+		chain := transfer.Asset.GetNativeAsset().NativeAsset
+		key := walletKey{chain, transfer.From}
+		wallet, ok := wallets[key]
+		if !ok {
+			balanceClient, ok := client.(xc.ClientBalance)
+			if !ok {
+				return nil, fmt.Errorf("balances are not supported for %s", transfer.Asset.ID())
+			}
+			balance, err := balanceClient.FetchNativeBalance(ctx, transfer.From)
+			if err != nil {
+				return nil, fmt.Errorf("could not fetch balance of %s: %v", transfer.From, err)
+			}
+			wallet = &WalletBudget{
+				Chain:   chain,
+				Address: transfer.From,
+				Fees:    xc.NewAmountBlockchainFromUint64(0),
+				Amount:  xc.NewAmountBlockchainFromUint64(0),
+				Balance: balance,
+			}
+			wallets[key] = wallet
+			report.Wallets = append(report.Wallets, wallet)
+		}
+		wallet.Transfers++
+		wallet.Fees = add(wallet.Fees, fee)
+		if isNative(transfer.Asset) {
+			wallet.Amount = add(wallet.Amount, transfer.Amount)
+		}
+	}
+
+	for _, wallet := range report.Wallets {
+		wallet.Required = add(wallet.Fees, wallet.Amount)
+		wallet.Shortfall = xc.NewAmountBlockchainFromUint64(0)
+		if wallet.Required.Cmp(&wallet.Balance) > 0 {
+			wallet.Shortfall = xc.AmountBlockchain(*new(big.Int).Sub(wallet.Required.Int(), wallet.Balance.Int()))
+		}
+	}
+	sort.Slice(report.Wallets, func(i, j int) bool {
+		if report.Wallets[i].Chain != report.Wallets[j].Chain {
+			return report.Wallets[i].Chain < report.Wallets[j].Chain
+		}
+		return report.Wallets[i].Address < report.Wallets[j].Address
+	})
+	return report, nil
+}
+
+// EstimateFee returns the fee of a transfer, after the fee multiplier, see Plan
+func (planner *Planner) EstimateFee(ctx context.Context, transfer *Transfer) (xc.AmountBlockchain, error) {
+	client, err := planner.Factory.NewClient(transfer.Asset)
+	if err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	return planner.estimateFee(ctx, client, transfer)
+}
+
+// estimateFee builds the tx of a transfer and returns its fee, or the max fee of its input
+func (planner *Planner) estimateFee(ctx context.Context, client xc.Client, transfer *Transfer) (xc.AmountBlockchain, error) {
+	input, err := client.FetchTxInput(ctx, transfer.From, transfer.To)
+	if err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	builder, err := planner.Factory.NewTxBuilder(transfer.Asset)
+	if err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	tx, err := builder.NewTransfer(transfer.From, transfer.To, transfer.Amount, input)
+	if err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	var fee xc.AmountBlockchain
+	if txFee, ok := tx.(txWithFee); ok {
+		fee = txFee.Fee()
+	} else if inputFee, ok := input.(xc.TxInputFee); ok {
+		fee = inputFee.MaxFee()
+	} else {
+		return xc.AmountBlockchain{}, fmt.Errorf("fee estimation is not supported for %s", transfer.Asset.ID())
+	}
+	return planner.applyMultiplier(fee), nil
+}
+
+func (planner *Planner) applyMultiplier(fee xc.AmountBlockchain) xc.AmountBlockchain {
+	if planner.FeeMultiplier <= 0 || planner.FeeMultiplier == 1 {
+		return fee
+	}
+	result, _ := new(big.Float).Mul(new(big.Float).SetInt(fee.Int()), big.NewFloat(planner.FeeMultiplier)).Int(nil)
+	return xc.AmountBlockchain(*result)
+}
+
+// add allocates the sum, as AmountBlockchain.Add may reuse the memory of its receiver
+func add(x xc.AmountBlockchain, y xc.AmountBlockchain) xc.AmountBlockchain {
+	return xc.AmountBlockchain(*new(big.Int).Add(x.Int(), y.Int()))
+}
+
+// isNative returns true if the asset is the native asset of its chain
+func isNative(asset xc.ITask) bool {
+	if token, ok := asset.(*xc.TokenAssetConfig); ok {
+		return token.Contract == ""
+	}
+	return asset.GetAssetConfig().Contract == ""
+}
@@ -0,0 +1,136 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/cosmos"
+	"github.com/jumpcrypto/crosschain/chain/evm"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestBudgetTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+var ethAsset = &xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, Driver: "evm", Decimals: 18}
+var usdcAsset = &xc.TokenAssetConfig{Asset: "USDC", Chain: "ETH", Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6, AssetConfig: *ethAsset, NativeAssetConfig: ethAsset}
+var lunaAsset = &xc.AssetConfig{Asset: "LUNA", NativeAsset: "LUNA", Driver: "cosmos", ChainCoin: "uluna", ChainPrefix: "terra"}
+
+const hotWallet = xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+const payee = xc.Address("0x24b3A3F3B8e2D2eC7E44A1c8FBbA0C8d2e7bA0bd")
+const lunaWallet = xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+
+func newTestPlanner(clients map[xc.NativeAsset]*testutil.MockedClient) *Planner {
+	f := testutil.NewDefaultFactoryWithConfig(map[string]interface{}{})
+	f.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		return clients[asset.GetNativeAsset().NativeAsset], nil
+	}
+	return NewPlanner(&f)
+}
+
+func evmInput() *evm.TxInput {
+	input := evm.NewTxInput()
+	input.GasFeeCap = xc.NewAmountBlockchainFromUint64(10)
+	input.GasTipCap = xc.NewAmountBlockchainFromUint64(1)
+	return input
+}
+
+func (s *CrosschainTestSuite) TestPlan() {
+	require := s.Require()
+	eth := &testutil.MockedClient{}
+	// a new input per transfer, as builders set its gas limit
+	eth.On("FetchTxInput", mock.Anything, hotWallet, payee).Return(evmInput(), nil).Once()
+	eth.On("FetchTxInput", mock.Anything, hotWallet, payee).Return(evmInput(), nil).Once()
+	eth.On("FetchNativeBalance", mock.Anything, hotWallet).Return(xc.NewAmountBlockchainFromUint64(2_000_000), nil)
+	luna := &testutil.MockedClient{}
+	luna.On("FetchTxInput", mock.Anything, lunaWallet, lunaWallet).Return(&cosmos.TxInput{GasPrice: 0.01}, nil)
+	luna.On("FetchNativeBalance", mock.Anything, lunaWallet).Return(xc.NewAmountBlockchainFromUint64(1_000_000), nil)
+
+	planner := newTestPlanner(map[xc.NativeAsset]*testutil.MockedClient{xc.ETH: eth, "LUNA": luna})
+	report, err := planner.Plan(s.Ctx, []*Transfer{
+		{Asset: ethAsset, From: hotWallet, To: payee, Amount: xc.NewAmountBlockchainFromUint64(1_000_000)},
+		{Asset: usdcAsset, From: hotWallet, To: payee, Amount: xc.NewAmountBlockchainFromUint64(5_000_000)},
+		{Asset: lunaAsset, From: lunaWallet, To: lunaWallet, Amount: xc.NewAmountBlockchainFromUint64(1000)},
+	})
+	require.NoError(err)
+	require.Empty(report.Failures)
+	require.Len(report.Wallets, 2)
+
+	wallet := report.Wallets[0]
+	require.Equal(xc.ETH, wallet.Chain)
+	require.Equal(hotWallet, wallet.Address)
+	require.Equal(2, wallet.Transfers)
+	// 90k gas for the native transfer and 350k gas for the token transfer, at 10 per gas
+	require.Equal("4400000", wallet.Fees.String())
+	// token amounts aren't paid in the native asset
+	require.Equal("1000000", wallet.Amount.String())
+	require.Equal("5400000", wallet.Required.String())
+	require.Equal("3400000", wallet.Shortfall.String())
+
+	wallet = report.Wallets[1]
+	require.Equal(xc.NativeAsset("LUNA"), wallet.Chain)
+	// 400k gas at 0.01
+	require.Equal("4000", wallet.Fees.String())
+	require.Equal("5000", wallet.Required.String())
+	require.Equal("0", wallet.Shortfall.String())
+
+	require.Equal([]*WalletBudget{report.Wallets[0]}, report.Shortfalls())
+	eth.AssertNumberOfCalls(s.T(), "FetchNativeBalance", 1)
+}
+
+func (s *CrosschainTestSuite) TestPlanFeeMultiplier() {
+	require := s.Require()
+	eth := &testutil.MockedClient{}
+	eth.On("FetchTxInput", mock.Anything, hotWallet, payee).Return(evmInput(), nil)
+	eth.On("FetchNativeBalance", mock.Anything, hotWallet).Return(xc.NewAmountBlockchainFromUint64(0), nil)
+
+	planner := newTestPlanner(map[xc.NativeAsset]*testutil.MockedClient{xc.ETH: eth})
+	planner.FeeMultiplier = 1.5
+	report, err := planner.Plan(s.Ctx, []*Transfer{
+		{Asset: ethAsset, From: hotWallet, To: payee, Amount: xc.NewAmountBlockchainFromUint64(1)},
+	})
+	require.NoError(err)
+	require.Equal("1350000", report.Wallets[0].Fees.String())
+	require.Equal("1350001", report.Wallets[0].Shortfall.String())
+}
+
+func (s *CrosschainTestSuite) TestPlanFailures() {
+	require := s.Require()
+	eth := &testutil.MockedClient{}
+	eth.On("FetchTxInput", mock.Anything, hotWallet, payee).Return(evmInput(), errors.New("rpc unavailable"))
+
+	planner := newTestPlanner(map[xc.NativeAsset]*testutil.MockedClient{xc.ETH: eth})
+	report, err := planner.Plan(s.Ctx, []*Transfer{
+		{Asset: ethAsset, From: hotWallet, To: payee, Amount: xc.NewAmountBlockchainFromUint64(1)},
+	})
+	require.NoError(err)
+	require.Empty(report.Wallets)
+	require.Len(report.Failures, 1)
+	require.Equal(xc.AssetID("ETH"), report.Failures[0].Asset)
+	require.Equal("rpc unavailable", report.Failures[0].Error)
+	eth.AssertNotCalled(s.T(), "FetchNativeBalance", mock.Anything, mock.Anything)
+
+	// balance errors fail the report
+	eth = &testutil.MockedClient{}
+	eth.On("FetchTxInput", mock.Anything, hotWallet, payee).Return(evmInput(), nil)
+	eth.On("FetchNativeBalance", mock.Anything, hotWallet).Return(xc.AmountBlockchain{}, errors.New("timeout"))
+	planner = newTestPlanner(map[xc.NativeAsset]*testutil.MockedClient{xc.ETH: eth})
+	_, err = planner.Plan(s.Ctx, []*Transfer{
+		{Asset: ethAsset, From: hotWallet, To: payee, Amount: xc.NewAmountBlockchainFromUint64(1)},
+	})
+	require.ErrorContains(err, "could not fetch balance")
+}
@@ -0,0 +1,56 @@
+package crosschain
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBufferSize is the capacity above which buffers are not returned to the pool,
+// so that a few large txs don't pin memory
+const maxPooledBufferSize = 64 * 1024
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// GetBuffer returns an empty buffer from a shared pool, to be returned with PutBuffer
+func GetBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// PutBuffer returns a buffer to the pool, it must not be used afterwards
+func PutBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// SerializeTo serializes tx into w, streaming if tx is a TxStreamSerializer
+func SerializeTo(w io.Writer, tx Tx) error {
+	if streamer, ok := tx.(TxStreamSerializer); ok {
+		return streamer.SerializeTo(w)
+	}
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(serialized)
+	return err
+}
+
+// SerializePooled serializes tx into a pooled buffer and calls fn with the serialized tx
+// The serialized bytes are only valid during fn and must be copied to be retained
+func SerializePooled(tx Tx, fn func(serialized []byte) error) error {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	if err := SerializeTo(buf, tx); err != nil {
+		return err
+	}
+	return fn(buf.Bytes())
+}
@@ -0,0 +1,55 @@
+package crosschain
+
+import (
+	"bytes"
+	"errors"
+)
+
+type bufferTestTx struct {
+	serialized []byte
+	err        error
+}
+
+func (tx bufferTestTx) Hash() TxHash                       { return "" }
+func (tx bufferTestTx) Sighashes() ([]TxDataToSign, error) { return nil, nil }
+func (tx bufferTestTx) AddSignatures(...TxSignature) error { return nil }
+func (tx bufferTestTx) Serialize() ([]byte, error)         { return tx.serialized, tx.err }
+
+func (s *CrosschainTestSuite) TestBufferPool() {
+	require := s.Require()
+	buf := GetBuffer()
+	buf.WriteString("data")
+	PutBuffer(buf)
+	require.Equal(0, GetBuffer().Len())
+
+	// large buffers are dropped, and don't fail
+	large := GetBuffer()
+	large.Grow(maxPooledBufferSize + 1)
+	PutBuffer(large)
+}
+
+func (s *CrosschainTestSuite) TestSerializeTo() {
+	require := s.Require()
+	buf := &bytes.Buffer{}
+	require.NoError(SerializeTo(buf, bufferTestTx{serialized: []byte{1, 2, 3}}))
+	require.Equal([]byte{1, 2, 3}, buf.Bytes())
+
+	err := SerializeTo(buf, bufferTestTx{err: errors.New("not signed")})
+	require.EqualError(err, "not signed")
+}
+
+func (s *CrosschainTestSuite) TestSerializePooled() {
+	require := s.Require()
+	var serialized []byte
+	err := SerializePooled(bufferTestTx{serialized: []byte{1, 2, 3}}, func(data []byte) error {
+		serialized = append([]byte{}, data...)
+		return nil
+	})
+	require.NoError(err)
+	require.Equal([]byte{1, 2, 3}, serialized)
+
+	err = SerializePooled(bufferTestTx{serialized: []byte{1}}, func(data []byte) error {
+		return errors.New("submit failed")
+	})
+	require.EqualError(err, "submit failed")
+}
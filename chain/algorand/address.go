@@ -0,0 +1,85 @@
+package algorand
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/base32"
+	"errors"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// checksumLength is the length of the checksum of addresses, the last bytes of the sha512/256 of the public key
+const checksumLength = 4
+
+var addressEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// AddressBuilder for Algorand
+type AddressBuilder struct {
+}
+
+var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
+
+// NewAddressBuilder creates a new Algorand AddressBuilder
+func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
+	return AddressBuilder{}, nil
+}
+
+// GetAddressFromPublicKey returns the address of an ed25519 public key: the base32 of the key and its checksum
+func (ab AddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (xc.Address, error) {
+	if len(publicKeyBytes) != ed25519.PublicKeySize {
+		return xc.Address(""), fmt.Errorf("invalid ed25519 public key length %d", len(publicKeyBytes))
+	}
+	return encodeAddress(publicKeyBytes), nil
+}
+
+// GetAllPossibleAddressesFromPublicKey returns all PossubleAddress(es) given a public key
+func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]xc.PossibleAddress, error) {
+	address, err := ab.GetAddressFromPublicKey(publicKeyBytes)
+	return []xc.PossibleAddress{
+		{
+			Address: address,
+			Type:    xc.AddressTypeDefault,
+		},
+	}, err
+}
+
+// ValidateAddress checks an address is the base32 of a public key and its checksum
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	if _, err := DecodeAddress(address); err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	return nil
+}
+
+// DecodeAddress returns the ed25519 public key of an address
+func DecodeAddress(address xc.Address) ([]byte, error) {
+	decoded, err := addressEncoding.DecodeString(string(address))
+	if err != nil {
+		return nil, errors.New("not base32")
+	}
+	if len(decoded) != ed25519.PublicKeySize+checksumLength {
+		return nil, fmt.Errorf("invalid length %d", len(decoded))
+	}
+	publicKey := decoded[:ed25519.PublicKeySize]
+	if !bytes.Equal(decoded[ed25519.PublicKeySize:], addressChecksum(publicKey)) {
+		return nil, errors.New("invalid checksum")
+	}
+	// the encoding of the last bits is canonical
+	if encodeAddress(publicKey) != address {
+		return nil, errors.New("invalid encoding")
+	}
+	return publicKey, nil
+}
+
+func encodeAddress(publicKey []byte) xc.Address {
+	return xc.Address(addressEncoding.EncodeToString(append(append([]byte{}, publicKey...), addressChecksum(publicKey)...)))
+}
+
+func addressChecksum(publicKey []byte) []byte {
+	hash := sha512.Sum512_256(publicKey)
+	return hash[len(hash)-checksumLength:]
+}
@@ -0,0 +1,55 @@
+package algorand
+
+import (
+	"encoding/hex"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// the keys of the tests 1 and 2 of RFC 8032
+const testSeed = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+const testMnemonic = "crisp sheriff solution ten remove object chair enhance future rather biology era myth image swap crash coffee scatter buffalo depart day twist advance about unfair"
+const testPublicKey = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+const testAddress = xc.Address("25NJQAMCWEFLPVKL73J4SZAHHIHOC4XT3KTCGJNPAINGR5YHKENMEF5QTE")
+const testRecipientPublicKey = "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c"
+const testRecipient = xc.Address("HVABPQ7IIOEVVEVXBKTU2G36XSOJQLGPF3CJNDGAZVK7CKXUMYGA6EOE6Y")
+
+func (s *CrosschainTestSuite) TestGetAddressFromPublicKey() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.ALGO})
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	address, err := builder.GetAddressFromPublicKey(publicKey)
+	require.NoError(err)
+	require.Equal(testAddress, address)
+
+	addresses, err := builder.GetAllPossibleAddressesFromPublicKey(publicKey)
+	require.NoError(err)
+	require.Len(addresses, 1)
+	require.Equal(testAddress, addresses[0].Address)
+
+	// the zero address, the sender of the fees of the network
+	address, _ = builder.GetAddressFromPublicKey(make([]byte, 32))
+	require.Equal(xc.Address("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAY5HFKQ"), address)
+
+	_, err = builder.GetAddressFromPublicKey(publicKey[1:])
+	require.EqualError(err, "invalid ed25519 public key length 31")
+}
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.ALGO})
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress(testAddress))
+	require.NoError(validator.ValidateAddress(testRecipient))
+
+	vectors := map[xc.Address]string{
+		"25NJQAMCWEFLPVKL73J4SZAHHIHOC4XT3KTCGJNPAINGR5YHKENMEF5QTA": "invalid checksum",
+		"25NJQAMCWEFLPVKL73J4SZAHHIHOC4XT3KTCGJNPAINGR5YHKENMEF5QTF": "invalid encoding",
+		"25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenmef5qte": "not base32",
+		"25NJQAMCWEFLPVKL73J4SZAHHIHOC4XT3KTCGJNPAINGR5YHKENME":      "invalid length 33",
+		"0xd75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68": "not base32",
+	}
+	for address, msg := range vectors {
+		require.EqualError(validator.ValidateAddress(address), "invalid address '"+string(address)+"': "+msg)
+	}
+}
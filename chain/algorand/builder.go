@@ -0,0 +1,131 @@
+package algorand
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// maxNoteLength is the max length of the notes of txs
+const maxNoteLength = 1024
+
+// signatureOverhead is the size of the signature in a signed tx: the key and the bytes of the sig, and the key txn
+const signatureOverhead = 75
+
+// estimatedTxSize is the usual size of the signed txs of the driver, for their max fee
+const estimatedTxSize = 250
+
+// TxBuilder for Algorand
+type TxBuilder struct {
+	Asset xc.ITask
+}
+
+var _ xc.TxBuilder = &TxBuilder{}
+var _ xc.TxTokenBuilder = &TxBuilder{}
+
+// NewTxBuilder creates a new Algorand TxBuilder
+func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
+	return &TxBuilder{
+		Asset: asset,
+	}, nil
+}
+
+// NewTransfer creates a new transfer for an Asset, either native or token
+func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		return txBuilder.NewTokenTransfer(from, to, amount, input)
+	}
+	return txBuilder.NewNativeTransfer(from, to, amount, input)
+}
+
+// NewNativeTransfer creates a new payment of microalgos
+func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	localInput, sender, receiver, err := parseTransfer(from, to, amount, input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	transaction := newTransaction(txTypePayment, localInput, sender)
+	transaction.Receiver = receiver
+	transaction.Amount = amount.Uint64()
+	return newTx(localInput, transaction), nil
+}
+
+// NewTokenTransfer creates a new transfer of an ASA, whose contract is its asset id
+// The receiver must have opted in to the asset
+func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	localInput, sender, receiver, err := parseTransfer(from, to, amount, input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	contract := txBuilder.Asset.GetAssetConfig().Contract
+	if token, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		contract = token.Contract
+	}
+	assetID, err := ParseAssetID(contract)
+	if err != nil {
+		return &Tx{}, err
+	}
+	transaction := newTransaction(txTypeAssetTransfer, localInput, sender)
+	transaction.AssetID = assetID
+	transaction.AssetReceiver = receiver
+	transaction.AssetAmount = amount.Uint64()
+	return newTx(localInput, transaction), nil
+}
+
+// ParseAssetID parses the id of an ASA, the contract of tokens
+func ParseAssetID(contract string) (uint64, error) {
+	assetID, err := strconv.ParseUint(contract, 10, 64)
+	if err != nil || assetID == 0 {
+		return 0, fmt.Errorf("invalid asset id '%s'", contract)
+	}
+	return assetID, nil
+}
+
+func parseTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (*TxInput, []byte, []byte, error) {
+	localInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, nil, nil, errors.New("xc.TxInput is not from an algorand chain")
+	}
+	sender, err := DecodeAddress(from)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid from address '%s': %v", from, err)
+	}
+	receiver, err := DecodeAddress(to)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid to address '%s': %v", to, err)
+	}
+	if amount.Int().Sign() <= 0 || !amount.Int().IsUint64() {
+		return nil, nil, nil, fmt.Errorf("invalid amount %s", amount.String())
+	}
+	if len(localInput.Note) > maxNoteLength {
+		return nil, nil, nil, fmt.Errorf("invalid note: notes have up to %d bytes", maxNoteLength)
+	}
+	if len(localInput.GenesisHash) != 32 || localInput.LastValid == 0 {
+		return nil, nil, nil, errors.New("invalid input: missing genesis hash or rounds")
+	}
+	return localInput, sender, receiver, nil
+}
+
+func newTransaction(txType string, input *TxInput, sender []byte) Transaction {
+	return Transaction{
+		Type:        txType,
+		Sender:      sender,
+		FirstValid:  input.FirstValid,
+		LastValid:   input.LastValid,
+		GenesisID:   input.GenesisID,
+		GenesisHash: input.GenesisHash,
+		Note:        input.Note,
+	}
+}
+
+// newTx returns the tx of a transaction, with the fee of its size once signed
+func newTx(input *TxInput, transaction Transaction) *Tx {
+	transaction.Fee = input.MinFee
+	transaction.Fee = input.fee(len(transaction.serialize()) + signatureOverhead)
+	return &Tx{Transaction: transaction}
+}
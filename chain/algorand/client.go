@@ -0,0 +1,321 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// validityWindow is the number of rounds during which txs built from a TxInput can be included, the max allowed
+const validityWindow = 1000
+
+// Client for Algorand, using the REST API of algod
+type Client struct {
+	Asset           xc.ITask
+	HttpClient      *http.Client
+	URL             string
+	EstimateGasFunc xc.EstimateGasFunc
+}
+
+var _ xc.FullClientWithGas = &Client{}
+
+// algodError is an error returned by algod, e.g. the rejection of a tx by the tx pool
+type algodError struct {
+	Message string `json:"message"`
+	Status  int    `json:"-"`
+}
+
+func (e *algodError) Error() string {
+	return e.Message
+}
+
+type algodParams struct {
+	Fee         uint64 `json:"fee"`
+	GenesisHash []byte `json:"genesis-hash"`
+	GenesisID   string `json:"genesis-id"`
+	LastRound   uint64 `json:"last-round"`
+	MinFee      uint64 `json:"min-fee"`
+}
+
+type algodTransaction struct {
+	Type          string `json:"type"`
+	Sender        string `json:"snd"`
+	Fee           uint64 `json:"fee"`
+	Receiver      string `json:"rcv"`
+	Amount        uint64 `json:"amt"`
+	AssetID       uint64 `json:"xaid"`
+	AssetReceiver string `json:"arcv"`
+	AssetAmount   uint64 `json:"aamt"`
+}
+
+type algodPendingTransaction struct {
+	ConfirmedRound uint64 `json:"confirmed-round"`
+	PoolError      string `json:"pool-error"`
+	Txn            struct {
+		Txn algodTransaction `json:"txn"`
+	} `json:"txn"`
+}
+
+type algodBlock struct {
+	Block struct {
+		Timestamp int64 `json:"ts"`
+	} `json:"block"`
+}
+
+type algodBlockHash struct {
+	BlockHash string `json:"blockHash"`
+}
+
+type algodStatus struct {
+	LastRound uint64 `json:"last-round"`
+}
+
+type algodAccount struct {
+	Amount uint64 `json:"amount"`
+}
+
+type algodAssetHolding struct {
+	AssetHolding struct {
+		Amount uint64 `json:"amount"`
+	} `json:"asset-holding"`
+}
+
+type algodSubmitResult struct {
+	TxID string `json:"txId"`
+}
+
+// NewClient returns a new Algorand Client
+func NewClient(cfgI xc.ITask) (*Client, error) {
+	cfg := cfgI.GetNativeAsset()
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Asset:      cfgI,
+		HttpClient: &http.Client{Transport: transport},
+		URL:        strings.TrimSuffix(cfg.URL, "/"),
+	}, nil
+}
+
+// FetchTxInput returns tx input for an Algorand tx: the network, the valid rounds and the fees
+// For transfers of tokens, the recipient must have opted in to the asset
+func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, to xc.Address) (xc.TxInput, error) {
+	input := NewTxInput()
+	params, err := client.fetchParams(ctx)
+	if err != nil {
+		return input, err
+	}
+	input.GenesisID = params.GenesisID
+	input.GenesisHash = params.GenesisHash
+	input.FirstValid = params.LastRound
+	input.LastValid = params.LastRound + validityWindow
+	input.FeePerByte = params.Fee
+	input.MinFee = params.MinFee
+
+	if token, ok := client.Asset.(*xc.TokenAssetConfig); ok && to != "" {
+		assetID, err := ParseAssetID(token.Contract)
+		if err != nil {
+			return input, err
+		}
+		holding, err := client.fetchAssetHolding(ctx, to, assetID)
+		if err != nil {
+			return input, err
+		}
+		if holding == nil {
+			return input, fmt.Errorf("recipient '%s' hasn't opted in to asset %d", to, assetID)
+		}
+	}
+	return input, nil
+}
+
+// SubmitTx submits an Algorand tx to the tx pool of algod
+func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		return xc.RecordDryRun(ctx, client.Asset, tx, false)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.URL+"/v2/transactions", bytes.NewReader(serialized))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-binary")
+	var res algodSubmitResult
+	return client.do(req, &res)
+}
+
+// FetchTxInfo returns tx info for an Algorand tx, final once confirmed in a round
+// algod only has the recent txs, in its pending pool and for a while once confirmed
+func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
+	var pending algodPendingTransaction
+	if err := client.get(ctx, "/v2/transactions/pending/"+string(txHash)+"?format=json", &pending); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching tx '%s': %v", txHash, err)
+	}
+	txn := pending.Txn.Txn
+	nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+	info := xc.TxInfo{
+		TxID:        string(txHash),
+		ExplorerURL: fmt.Sprintf("/tx/%s", txHash),
+		From:        xc.Address(txn.Sender),
+		Fee:         xc.NewAmountBlockchainFromUint64(txn.Fee),
+		BlockIndex:  int64(pending.ConfirmedRound),
+	}
+	switch txn.Type {
+	case txTypePayment:
+		info.To = xc.Address(txn.Receiver)
+		info.Amount = xc.NewAmountBlockchainFromUint64(txn.Amount)
+	case txTypeAssetTransfer:
+		info.To = xc.Address(txn.AssetReceiver)
+		info.Amount = xc.NewAmountBlockchainFromUint64(txn.AssetAmount)
+		info.ContractAddress = xc.ContractAddress(fmt.Sprint(txn.AssetID))
+	default:
+		info.Amount = xc.NewAmountBlockchainFromUint64(0)
+	}
+	if info.To != "" {
+		info.Sources = []*xc.TxInfoEndpoint{{Address: info.From, ContractAddress: info.ContractAddress, Amount: info.Amount, NativeAsset: nativeAsset}}
+		info.Destinations = []*xc.TxInfoEndpoint{{Address: info.To, ContractAddress: info.ContractAddress, Amount: info.Amount, NativeAsset: nativeAsset}}
+	}
+	if pending.PoolError != "" {
+		info.Status = xc.TxStatusFailure
+		info.Error = pending.PoolError
+		return info, nil
+	}
+	if pending.ConfirmedRound == 0 {
+		return info, nil
+	}
+
+	var block algodBlock
+	if err := client.get(ctx, fmt.Sprintf("/v2/blocks/%d?format=json", pending.ConfirmedRound), &block); err != nil {
+		return info, fmt.Errorf("fetching block %d: %v", pending.ConfirmedRound, err)
+	}
+	info.BlockTime = block.Block.Timestamp
+	var blockHash algodBlockHash
+	if err := client.get(ctx, fmt.Sprintf("/v2/blocks/%d/hash", pending.ConfirmedRound), &blockHash); err != nil {
+		return info, fmt.Errorf("fetching hash of block %d: %v", pending.ConfirmedRound, err)
+	}
+	info.BlockHash = blockHash.BlockHash
+	var status algodStatus
+	if err := client.get(ctx, "/v2/status", &status); err != nil {
+		return info, fmt.Errorf("fetching status: %v", err)
+	}
+	if status.LastRound >= pending.ConfirmedRound {
+		info.Confirmations = int64(status.LastRound - pending.ConfirmedRound + 1)
+	}
+	return info, nil
+}
+
+// FetchBalance fetches the balance of an asset, 0 for tokens the account hasn't opted in to
+func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	token, ok := client.Asset.(*xc.TokenAssetConfig)
+	if !ok {
+		return client.FetchNativeBalance(ctx, address)
+	}
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	assetID, err := ParseAssetID(token.Contract)
+	if err != nil {
+		return zero, err
+	}
+	holding, err := client.fetchAssetHolding(ctx, address, assetID)
+	if err != nil || holding == nil {
+		return zero, err
+	}
+	return xc.NewAmountBlockchainFromUint64(holding.AssetHolding.Amount), nil
+}
+
+// FetchNativeBalance fetches the balance of microalgos of an account, including its min balance
+func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	var account algodAccount
+	if err := client.get(ctx, "/v2/accounts/"+string(address)+"?exclude=all", &account); err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), fmt.Errorf("fetching account '%s': %v", address, err)
+	}
+	return xc.NewAmountBlockchainFromUint64(account.Amount), nil
+}
+
+func (client *Client) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc) {
+	client.EstimateGasFunc = estimateGas
+}
+
+// EstimateGas returns the fee of a tx of the usual size in microalgos, the min fee unless the network is congested
+func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	if client.EstimateGasFunc != nil {
+		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+		if res, err := client.EstimateGasFunc(nativeAsset); err == nil {
+			return res, nil
+		}
+		// continue with default implementation as fallback
+	}
+	params, err := client.fetchParams(ctx)
+	if err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), err
+	}
+	input := TxInput{FeePerByte: params.Fee, MinFee: params.MinFee}
+	return input.MaxFee(), nil
+}
+
+func (client *Client) fetchParams(ctx context.Context) (*algodParams, error) {
+	var params algodParams
+	if err := client.get(ctx, "/v2/transactions/params", &params); err != nil {
+		return nil, fmt.Errorf("fetching tx params: %v", err)
+	}
+	return &params, nil
+}
+
+// fetchAssetHolding returns the holding of an asset of an account, nil if it hasn't opted in to the asset
+func (client *Client) fetchAssetHolding(ctx context.Context, address xc.Address, assetID uint64) (*algodAssetHolding, error) {
+	var holding algodAssetHolding
+	err := client.get(ctx, fmt.Sprintf("/v2/accounts/%s/assets/%d", address, assetID), &holding)
+	if err != nil {
+		var algodErr *algodError
+		if errors.As(err, &algodErr) && algodErr.Status == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fetching asset %d of '%s': %v", assetID, address, err)
+	}
+	return &holding, nil
+}
+
+func (client *Client) get(ctx context.Context, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	return client.do(req, result)
+}
+
+func (client *Client) do(req *http.Request, result interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	if token := client.Asset.GetNativeAsset().AuthSecret; token != "" {
+		req.Header.Set("X-Algo-API-Token", token)
+	}
+	resp, err := client.HttpClient.Do(req)
+	if err != nil {
+		return xc.DefaultRedactor.RedactError(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		algodErr := &algodError{}
+		if err := json.Unmarshal(data, algodErr); err != nil || algodErr.Message == "" {
+			return fmt.Errorf("%s returned %s: %s", req.URL.Path, resp.Status, xc.DefaultRedactor.Redact(string(data)))
+		}
+		algodErr.Status = resp.StatusCode
+		return algodErr
+	}
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,188 @@
+package algorand
+
+import (
+	"net/http"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const testTxHash = "B2JMAY55HK7TGQGCNDOY2EDGAVCWM5KDQGE5S67YKTA5VSW7MTUQ"
+
+const testParams = `{"consensus-version":"https://github.com/algorandfoundation/specs/tree/925a46433742afb0b51bb939354bd907fa88bf95","fee":0,"genesis-hash":"SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI=","genesis-id":"testnet-v1.0","last-round":35000000,"min-fee":1000}`
+
+const testNotFound = `{"message":"account asset info not found"}`
+
+func (s *CrosschainTestSuite) TestFetchTxInput() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, testParams)
+	defer close()
+
+	client, err := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ALGO, URL: server.URL})
+	require.NoError(err)
+	input, err := client.FetchTxInput(s.Ctx, testAddress, testRecipient)
+	require.NoError(err)
+	txInput := input.(*TxInput)
+	require.Equal(xc.DriverAlgorand, txInput.Type)
+	require.Equal("testnet-v1.0", txInput.GenesisID)
+	require.Equal(testInput().GenesisHash, txInput.GenesisHash)
+	require.EqualValues(35000000, txInput.FirstValid)
+	require.EqualValues(35001000, txInput.LastValid)
+	require.EqualValues(1000, txInput.MinFee)
+	require.EqualValues(0, txInput.FeePerByte)
+	require.Equal("1000", txInput.MaxFee().String())
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenTxInput() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{testParams, `{"asset-holding":{"amount":0,"asset-id":10458941,"is-frozen":false},"round":35000000}`})
+	defer close()
+
+	token := *testToken
+	token.NativeAssetConfig = &xc.NativeAssetConfig{NativeAsset: xc.ALGO, URL: server.URL}
+	client, _ := NewClient(&token)
+	_, err := client.FetchTxInput(s.Ctx, testAddress, testRecipient)
+	require.NoError(err)
+
+	// the recipient hasn't opted in
+	server.Counter = 0
+	server.Response = []string{testParams, testNotFound}
+	server.StatusCodes = []int{http.StatusOK, http.StatusNotFound}
+	_, err = client.FetchTxInput(s.Ctx, testAddress, testRecipient)
+	require.EqualError(err, "recipient '"+string(testRecipient)+"' hasn't opted in to asset 10458941")
+}
+
+func (s *CrosschainTestSuite) TestSubmitTx() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, `{"txId":"`+testTxHash+`"}`)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ALGO, URL: server.URL})
+	builder, _ := NewTxBuilder(testNativeAsset)
+	tx, _ := builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(1), testInput())
+	require.Error(client.SubmitTx(s.Ctx, tx))
+	require.NoError(tx.AddSignatures(make([]byte, 64)))
+	require.NoError(client.SubmitTx(s.Ctx, tx))
+
+	server.Counter = 0
+	server.Response = `{"message":"TransactionPool.Remember: transaction ` + testTxHash + `: overspend (account ` + string(testAddress) + `, data {_struct:{} Status:Offline MicroAlgos:{Raw:100000}}, tried to spend {1000000})"}`
+	server.StatusCodes = []int{http.StatusBadRequest}
+	err := client.SubmitTx(s.Ctx, tx)
+	require.ErrorContains(err, "overspend")
+	require.Equal(xc.NoBalance, CheckError(err))
+
+	server.Counter = 0
+	server.Response = `{"message":"TransactionPool.Remember: txn dead: round 36000000 outside of 35000000--35001000"}`
+	err = client.SubmitTx(s.Ctx, tx)
+	require.Equal(xc.TransactionFailure, CheckError(err))
+
+	server.Counter = 0
+	server.Response = `{"message":"TransactionPool.Remember: transaction already in ledger: ` + testTxHash + `"}`
+	err = client.SubmitTx(s.Ctx, tx)
+	require.Equal(xc.TransactionExists, CheckError(err))
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfo() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{
+		`{"confirmed-round":35000010,"pool-error":"","txn":{"sig":"AAAA","txn":{"amt":1000000,"fee":1000,"fv":35000000,"gen":"testnet-v1.0","gh":"SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI=","lv":35001000,"rcv":"` + string(testRecipient) + `","snd":"` + string(testAddress) + `","type":"pay"}}}`,
+		`{"block":{"rnd":35000010,"ts":1700000000}}`,
+		`{"blockHash":"AFSUTV3H4MZGW2K4ZJPCFPQYN2GX5OWALXTTMQZ6PZP7Y5PKFBGA"}`,
+		`{"last-round":35000019}`,
+	})
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ALGO, URL: server.URL})
+	info, err := client.FetchTxInfo(s.Ctx, testTxHash)
+	require.NoError(err)
+	require.Equal(testTxHash, info.TxID)
+	require.Equal("/tx/"+testTxHash, info.ExplorerURL)
+	require.Equal(testAddress, info.From)
+	require.Equal(testRecipient, info.To)
+	require.Equal("1000000", info.Amount.String())
+	require.Equal("1000", info.Fee.String())
+	require.Equal(xc.ContractAddress(""), info.ContractAddress)
+	require.EqualValues(35000010, info.BlockIndex)
+	require.EqualValues(1700000000, info.BlockTime)
+	require.Equal("AFSUTV3H4MZGW2K4ZJPCFPQYN2GX5OWALXTTMQZ6PZP7Y5PKFBGA", info.BlockHash)
+	require.EqualValues(10, info.Confirmations)
+	require.Equal(xc.TxStatusSuccess, info.Status)
+	require.Len(info.Sources, 1)
+	require.Len(info.Destinations, 1)
+	require.Equal(xc.ALGO, info.Destinations[0].NativeAsset)
+
+	server.Counter = 0
+	server.Response = `{"message":"txn does not exist"}`
+	server.StatusCodes = []int{http.StatusNotFound}
+	_, err = client.FetchTxInfo(s.Ctx, testTxHash)
+	require.EqualError(err, "fetching tx '"+testTxHash+"': txn does not exist")
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenTxInfo() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, `{"confirmed-round":0,"pool-error":"","txn":{"txn":{"aamt":5,"arcv":"`+string(testRecipient)+`","fee":1000,"snd":"`+string(testAddress)+`","type":"axfer","xaid":10458941}}}`)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ALGO, URL: server.URL})
+	info, err := client.FetchTxInfo(s.Ctx, testTxHash)
+	require.NoError(err)
+	require.Equal(testRecipient, info.To)
+	require.Equal("5", info.Amount.String())
+	require.Equal(xc.ContractAddress("10458941"), info.ContractAddress)
+	// still pending
+	require.EqualValues(0, info.Confirmations)
+	require.Equal(1, server.Counter)
+
+	server.Counter = 0
+	server.Response = `{"confirmed-round":0,"pool-error":"transaction rejected: asset 10458941 missing from ` + string(testRecipient) + `","txn":{"txn":{"type":"axfer"}}}`
+	info, err = client.FetchTxInfo(s.Ctx, testTxHash)
+	require.NoError(err)
+	require.Equal(xc.TxStatusFailure, info.Status)
+	require.Contains(info.Error, "missing from")
+}
+
+func (s *CrosschainTestSuite) TestFetchBalance() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, `{"address":"`+string(testAddress)+`","amount":5000000,"min-balance":100000}`)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ALGO, URL: server.URL})
+	balance, err := client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("5000000", balance.String())
+
+	server.Counter = 0
+	server.Response = `{"asset-holding":{"amount":1250000,"asset-id":10458941,"is-frozen":false}}`
+	token := *testToken
+	token.NativeAssetConfig = &xc.NativeAssetConfig{NativeAsset: xc.ALGO, URL: server.URL}
+	client, _ = NewClient(&token)
+	balance, err = client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("1250000", balance.String())
+
+	// not opted in
+	server.Counter = 0
+	server.Response = testNotFound
+	server.StatusCodes = []int{http.StatusNotFound}
+	balance, err = client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("0", balance.String())
+}
+
+func (s *CrosschainTestSuite) TestEstimateGas() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, `{"fee":10,"genesis-id":"testnet-v1.0","last-round":35000000,"min-fee":1000}`)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ALGO, URL: server.URL})
+	fee, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("2500", fee.String())
+
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(2000), nil
+	})
+	fee, err = client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("2000", fee.String())
+}
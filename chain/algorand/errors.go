@@ -0,0 +1,33 @@
+package algorand
+
+import (
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// CheckError classifies the errors of algod, the rejections of the tx pool
+func CheckError(err error) xc.ClientError {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "overspend") ||
+		strings.Contains(msg, "below min") ||
+		strings.Contains(msg, "underflow on subtracting") {
+		return xc.NoBalance
+	}
+	if strings.Contains(msg, "already in ledger") {
+		return xc.TransactionExists
+	}
+	if strings.Contains(msg, "txn dead") ||
+		strings.Contains(msg, "below threshold") ||
+		strings.Contains(msg, "less than the minimum") ||
+		strings.Contains(msg, "must optin") ||
+		strings.Contains(msg, "missing from") {
+		return xc.TransactionFailure
+	}
+	if strings.Contains(msg, "timeout") ||
+		strings.Contains(msg, "response body closed") ||
+		strings.Contains(msg, "eof") {
+		return xc.NetworkError
+	}
+	return xc.UnknownError
+}
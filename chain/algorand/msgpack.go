@@ -0,0 +1,108 @@
+package algorand
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+)
+
+// msgpackField is a field of a map encoded in canonical msgpack, as hashed and signed by Algorand: keys are sorted,
+// fields with zero values are omitted and integers have their shortest encoding
+type msgpackField struct {
+	key   string
+	value []byte
+}
+
+// msgpackMap returns the canonical encoding of a map of fields, skipping the fields without value
+func msgpackMap(fields ...msgpackField) []byte {
+	nonEmpty := []msgpackField{}
+	for _, field := range fields {
+		if field.value != nil {
+			nonEmpty = append(nonEmpty, field)
+		}
+	}
+	sort.Slice(nonEmpty, func(i, j int) bool { return nonEmpty[i].key < nonEmpty[j].key })
+
+	w := &bytes.Buffer{}
+	if len(nonEmpty) < 16 {
+		w.WriteByte(0x80 | byte(len(nonEmpty)))
+	} else {
+		w.WriteByte(0xde)
+		binary.Write(w, binary.BigEndian, uint16(len(nonEmpty)))
+	}
+	for _, field := range nonEmpty {
+		w.Write(msgpackString(field.key))
+		w.Write(field.value)
+	}
+	return w.Bytes()
+}
+
+// msgpackUint returns the encoding of a uint, nil for 0
+func msgpackUint(value uint64) []byte {
+	w := &bytes.Buffer{}
+	switch {
+	case value == 0:
+		return nil
+	case value < 0x80:
+		w.WriteByte(byte(value))
+	case value <= 0xff:
+		w.Write([]byte{0xcc, byte(value)})
+	case value <= 0xffff:
+		w.WriteByte(0xcd)
+		binary.Write(w, binary.BigEndian, uint16(value))
+	case value <= 0xffffffff:
+		w.WriteByte(0xce)
+		binary.Write(w, binary.BigEndian, uint32(value))
+	default:
+		w.WriteByte(0xcf)
+		binary.Write(w, binary.BigEndian, value)
+	}
+	return w.Bytes()
+}
+
+// msgpackString returns the encoding of a string, nil for ""
+func msgpackString(value string) []byte {
+	if value == "" {
+		return nil
+	}
+	w := &bytes.Buffer{}
+	writeLength(w, len(value), 0xa0, 32, 0xd9)
+	w.WriteString(value)
+	return w.Bytes()
+}
+
+// msgpackBytes returns the encoding of bytes, nil if empty
+func msgpackBytes(value []byte) []byte {
+	if len(value) == 0 {
+		return nil
+	}
+	w := &bytes.Buffer{}
+	writeLength(w, len(value), 0, 0, 0xc4)
+	w.Write(value)
+	return w.Bytes()
+}
+
+// msgpackDigest returns the encoding of a fixed size value, an address or a hash, nil if all zero
+func msgpackDigest(value []byte) []byte {
+	if bytes.Equal(value, make([]byte, len(value))) {
+		return nil
+	}
+	return msgpackBytes(value)
+}
+
+// writeLength writes the header of a string or bytes: a fix type up to fixLimit, else the type of 8, 16 or 32 bits
+// lengths, following the type of 8 bits
+func writeLength(w *bytes.Buffer, length int, fixType byte, fixLimit int, type8 byte) {
+	switch {
+	case length < fixLimit:
+		w.WriteByte(fixType | byte(length))
+	case length <= 0xff:
+		w.Write([]byte{type8, byte(length)})
+	case length <= 0xffff:
+		w.WriteByte(type8 + 1)
+		binary.Write(w, binary.BigEndian, uint16(length))
+	default:
+		w.WriteByte(type8 + 2)
+		binary.Write(w, binary.BigEndian, uint32(length))
+	}
+}
@@ -0,0 +1,108 @@
+package algorand
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	bip39 "github.com/cosmos/go-bip39"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// mnemonicLength is the number of words of the mnemonics of keys: 24 words of 11 bits for 32 bytes, and a checksum
+const mnemonicLength = 25
+
+// Signer for Algorand
+type Signer struct {
+}
+
+var _ xc.Signer = &Signer{}
+var _ xc.PublicKeyDeriver = &Signer{}
+
+// NewSigner creates a new Algorand Signer
+func NewSigner(asset xc.ITask) (xc.Signer, error) {
+	return Signer{}, nil
+}
+
+// ImportPrivateKey imports the 25 words mnemonic of a key, as exported by wallets, or the hex of a 32 bytes seed
+func (signer Signer) ImportPrivateKey(privateKey string) (xc.PrivateKey, error) {
+	if words := strings.Fields(privateKey); len(words) == mnemonicLength {
+		seed, err := seedFromMnemonic(words)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mnemonic: %v", err)
+		}
+		return xc.PrivateKey(seed), nil
+	}
+	seed, err := hex.DecodeString(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+	return xc.PrivateKey(seed), nil
+}
+
+// Sign the tagged bytes of an Algorand tx
+func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	if len(privateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+	}
+	return xc.TxSignature(ed25519.Sign(ed25519.NewKeyFromSeed(privateKey), []byte(data))), nil
+}
+
+// DerivePublicKey returns the ed25519 public key of a private key seed
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	if len(privateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+	}
+	return xc.PublicKey(ed25519.NewKeyFromSeed(privateKey).Public().(ed25519.PublicKey)), nil
+}
+
+// seedFromMnemonic returns the seed of a mnemonic: the words of the bip39 english list are 11 bits, little endian,
+// and the last word is the first 11 bits of the sha512/256 of the seed
+func seedFromMnemonic(words []string) ([]byte, error) {
+	indexes := make([]int, len(words))
+	for i, word := range words {
+		index, ok := bip39.ReverseWordMap[word]
+		if !ok {
+			return nil, fmt.Errorf("unknown word '%s'", word)
+		}
+		indexes[i] = index
+	}
+	seed := unpack11Bits(indexes[:mnemonicLength-1])
+	// the 264 bits of the words end with a zero byte
+	if len(seed) != ed25519.SeedSize+1 || seed[ed25519.SeedSize] != 0 {
+		return nil, errors.New("invalid encoding")
+	}
+	seed = seed[:ed25519.SeedSize]
+	hash := sha512.Sum512_256(seed)
+	if checksumIndex(hash[:]) != indexes[mnemonicLength-1] {
+		return nil, errors.New("invalid checksum")
+	}
+	return seed, nil
+}
+
+// checksumIndex returns the index of the checksum word of a hash, its first 11 bits, little endian
+func checksumIndex(hash []byte) int {
+	return (int(hash[0]) | int(hash[1])<<8) & 0x7ff
+}
+
+// unpack11Bits returns the bytes of little endian 11 bits values
+func unpack11Bits(values []int) []byte {
+	out := []byte{}
+	buffer, bits := 0, 0
+	for _, value := range values {
+		buffer |= value << bits
+		bits += 11
+		for bits >= 8 {
+			out = append(out, byte(buffer&0xff))
+			buffer >>= 8
+			bits -= 8
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(buffer&0xff))
+	}
+	return out
+}
@@ -0,0 +1,147 @@
+package algorand
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Types of the txs built by the driver
+const (
+	txTypePayment       = "pay"
+	txTypeAssetTransfer = "axfer"
+)
+
+// txTag is the domain separation prefix of the txs, signed and hashed with them
+const txTag = "TX"
+
+// TxInput for Algorand
+type TxInput struct {
+	xc.TxInputEnvelope
+	// FirstValid and LastValid are the rounds between which the tx can be included, up to 1000 rounds apart
+	FirstValid uint64
+	LastValid  uint64
+	// GenesisID and GenesisHash identify the network, e.g. testnet-v1.0
+	GenesisID   string
+	GenesisHash []byte
+	// FeePerByte is the fee per byte of the signed tx when the network is congested, usually 0
+	FeePerByte uint64
+	// MinFee is the min fee of a tx, in microalgos
+	MinFee uint64
+	// Note is an arbitrary note of up to 1024 bytes
+	Note []byte
+}
+
+var _ xc.TxInputFee = &TxInput{}
+
+// NewTxInput returns a new Algorand TxInput
+func NewTxInput() *TxInput {
+	return &TxInput{
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverAlgorand),
+	}
+}
+
+// MaxFee returns the fee of txs built with the input, of the usual size
+func (input *TxInput) MaxFee() xc.AmountBlockchain {
+	return xc.NewAmountBlockchainFromUint64(input.fee(estimatedTxSize))
+}
+
+// fee returns the fee of a tx of a size: the fee per byte, and at least the min fee
+func (input *TxInput) fee(size int) uint64 {
+	fee := input.FeePerByte * uint64(size)
+	if fee < input.MinFee {
+		return input.MinFee
+	}
+	return fee
+}
+
+// Transaction is an unsigned payment of Amount microalgos to Receiver, or transfer of AssetAmount of the asset
+// AssetID to AssetReceiver
+type Transaction struct {
+	Type        string
+	Sender      []byte
+	Fee         uint64
+	FirstValid  uint64
+	LastValid   uint64
+	GenesisID   string
+	GenesisHash []byte
+	Note        []byte
+	// payments
+	Receiver []byte
+	Amount   uint64
+	// asset transfers
+	AssetID       uint64
+	AssetReceiver []byte
+	AssetAmount   uint64
+}
+
+// Tx for Algorand
+type Tx struct {
+	Transaction
+	signature []byte
+}
+
+var _ xc.Tx = &Tx{}
+
+// Hash returns the id of the tx: the base32 of the sha512/256 of the tagged tx
+func (tx Tx) Hash() xc.TxHash {
+	hash := sha512.Sum512_256(tx.bytesToSign())
+	return xc.TxHash(addressEncoding.EncodeToString(hash[:]))
+}
+
+// Sighashes returns the bytes signed with ed25519: the tagged tx, not a hash of it
+func (tx Tx) Sighashes() ([]xc.TxDataToSign, error) {
+	if len(tx.Sender) == 0 {
+		return []xc.TxDataToSign{}, errors.New("transaction not initialized")
+	}
+	return []xc.TxDataToSign{tx.bytesToSign()}, nil
+}
+
+// AddSignatures adds the ed25519 signature of the sender
+func (tx *Tx) AddSignatures(signatures ...xc.TxSignature) error {
+	if len(signatures) != 1 {
+		return errors.New("expecting 1 signature")
+	}
+	if len(signatures[0]) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature length %d", len(signatures[0]))
+	}
+	tx.signature = signatures[0]
+	return nil
+}
+
+// Serialize returns the msgpack of the signed tx, as submitted to algod
+func (tx Tx) Serialize() ([]byte, error) {
+	if len(tx.signature) == 0 {
+		return []byte{}, errors.New("unable to serialize without first calling AddSignatures(...)")
+	}
+	return msgpackMap(
+		msgpackField{"sig", msgpackBytes(tx.signature)},
+		msgpackField{"txn", tx.Transaction.serialize()},
+	), nil
+}
+
+func (tx Tx) bytesToSign() []byte {
+	return append([]byte(txTag), tx.Transaction.serialize()...)
+}
+
+// serialize returns the canonical msgpack of the tx
+func (transaction Transaction) serialize() []byte {
+	return msgpackMap(
+		msgpackField{"type", msgpackString(transaction.Type)},
+		msgpackField{"snd", msgpackDigest(transaction.Sender)},
+		msgpackField{"fee", msgpackUint(transaction.Fee)},
+		msgpackField{"fv", msgpackUint(transaction.FirstValid)},
+		msgpackField{"lv", msgpackUint(transaction.LastValid)},
+		msgpackField{"gen", msgpackString(transaction.GenesisID)},
+		msgpackField{"gh", msgpackDigest(transaction.GenesisHash)},
+		msgpackField{"note", msgpackBytes(transaction.Note)},
+		msgpackField{"rcv", msgpackDigest(transaction.Receiver)},
+		msgpackField{"amt", msgpackUint(transaction.Amount)},
+		msgpackField{"xaid", msgpackUint(transaction.AssetID)},
+		msgpackField{"arcv", msgpackDigest(transaction.AssetReceiver)},
+		msgpackField{"aamt", msgpackUint(transaction.AssetAmount)},
+	)
+}
@@ -0,0 +1,185 @@
+package algorand
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+const testGenesisHash = "4863b518a4b3c84ec810f22d4f1081cb0f71f059a7ac20dec62f7f70e5093a22"
+
+var testNativeAsset = &xc.NativeAssetConfig{NativeAsset: xc.ALGO}
+
+var testToken = &xc.TokenAssetConfig{
+	Asset:             "USDC",
+	Contract:          "10458941",
+	Decimals:          6,
+	NativeAssetConfig: testNativeAsset,
+}
+
+func testInput() *TxInput {
+	input := NewTxInput()
+	input.FirstValid = 1000
+	input.LastValid = 2000
+	input.GenesisID = "testnet-v1.0"
+	input.GenesisHash, _ = hex.DecodeString(testGenesisHash)
+	input.MinFee = 1000
+	return input
+}
+
+func (s *CrosschainTestSuite) TestNewNativeTransfer() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testNativeAsset)
+	tx, err := builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(1_000_000), testInput())
+	require.NoError(err)
+	algorandTx := tx.(*Tx)
+	require.Equal(txTypePayment, algorandTx.Type)
+	require.EqualValues(1000, algorandTx.Fee)
+
+	// canonical msgpack of the tx: sorted keys and shortest integers
+	expected := strings.Join([]string{
+		"89",
+		"a3616d74" + "ce000f4240", // amt
+		"a3666565" + "cd03e8",     // fee
+		"a26676" + "cd03e8",       // fv
+		"a367656e" + "ac" + hex.EncodeToString([]byte("testnet-v1.0")), // gen
+		"a26768" + "c420" + testGenesisHash,                            // gh
+		"a26c76" + "cd07d0",                                            // lv
+		"a3726376" + "c420" + testRecipientPublicKey,                   // rcv
+		"a3736e64" + "c420" + testPublicKey,                            // snd
+		"a474797065" + "a3706179",                                      // type
+	}, "")
+	require.Equal(expected, hex.EncodeToString(algorandTx.Transaction.serialize()))
+
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes, 1)
+	require.Equal("5458"+expected, hex.EncodeToString(sighashes[0]))
+	require.Len(tx.Hash(), 52)
+
+	_, err = tx.Serialize()
+	require.Error(err)
+	signer, _ := NewSigner(testNativeAsset)
+	privateKey, err := signer.ImportPrivateKey(testMnemonic)
+	require.NoError(err)
+	signature, err := signer.Sign(privateKey, sighashes[0])
+	require.NoError(err)
+	require.NoError(tx.AddSignatures(signature))
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	require.Equal("82"+"a3736967"+"c440"+hex.EncodeToString(signature)+"a374786e"+expected, hex.EncodeToString(serialized))
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	require.True(ed25519.Verify(publicKey, sighashes[0], signature))
+}
+
+func (s *CrosschainTestSuite) TestNewTokenTransfer() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testToken)
+	input := testInput()
+	input.Note = []byte("memo")
+	tx, err := builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(5), input)
+	require.NoError(err)
+
+	expected := strings.Join([]string{
+		"8b",
+		"a461616d74" + "05", // aamt
+		"a461726376" + "c420" + testRecipientPublicKey,                 // arcv
+		"a3666565" + "cd03e8",                                          // fee
+		"a26676" + "cd03e8",                                            // fv
+		"a367656e" + "ac" + hex.EncodeToString([]byte("testnet-v1.0")), // gen
+		"a26768" + "c420" + testGenesisHash,                            // gh
+		"a26c76" + "cd07d0",                                            // lv
+		"a46e6f7465" + "c404" + hex.EncodeToString([]byte("memo")),     // note
+		"a3736e64" + "c420" + testPublicKey,                            // snd
+		"a474797065" + "a56178666572",                                  // type
+		"a478616964" + "ce009f973d",                                    // xaid
+	}, "")
+	require.Equal(expected, hex.EncodeToString(tx.(*Tx).Transaction.serialize()))
+}
+
+func (s *CrosschainTestSuite) TestFeePerByte() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testNativeAsset)
+	input := testInput()
+	input.FeePerByte = 10
+	tx, err := builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(1_000_000), input)
+	require.NoError(err)
+	// the size of the signed tx
+	require.NoError(tx.AddSignatures(make([]byte, 64)))
+	serialized, _ := tx.Serialize()
+	require.EqualValues(10*len(serialized), tx.(*Tx).Fee)
+	require.Equal("2500", input.MaxFee().String())
+
+	// congestion below the min fee
+	input.FeePerByte = 1
+	tx, _ = builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(1_000_000), input)
+	require.EqualValues(1000, tx.(*Tx).Fee)
+}
+
+func (s *CrosschainTestSuite) TestNewTransferErrors() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testNativeAsset)
+	amount := xc.NewAmountBlockchainFromUint64(1)
+
+	_, err := builder.NewTransfer(testAddress, testRecipient, amount, &xc.TxInputEnvelope{})
+	require.EqualError(err, "xc.TxInput is not from an algorand chain")
+	_, err = builder.NewTransfer(testAddress, testRecipient, amount, NewTxInput())
+	require.EqualError(err, "invalid input: missing genesis hash or rounds")
+	_, err = builder.NewTransfer("ALGO", testRecipient, amount, testInput())
+	require.ErrorContains(err, "invalid from address 'ALGO'")
+	_, err = builder.NewTransfer(testAddress, "25NJQAMCWEFLPVKL73J4SZAHHIHOC4XT3KTCGJNPAINGR5YHKENMEF5QTA", amount, testInput())
+	require.ErrorContains(err, "invalid to address")
+	_, err = builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(0), testInput())
+	require.EqualError(err, "invalid amount 0")
+	_, err = builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromStr("18446744073709551616"), testInput())
+	require.EqualError(err, "invalid amount 18446744073709551616")
+	input := testInput()
+	input.Note = make([]byte, 1025)
+	_, err = builder.NewTransfer(testAddress, testRecipient, amount, input)
+	require.EqualError(err, "invalid note: notes have up to 1024 bytes")
+
+	token := *testToken
+	token.Contract = "USDC"
+	builder, _ = NewTxBuilder(&token)
+	_, err = builder.NewTransfer(testAddress, testRecipient, amount, testInput())
+	require.EqualError(err, "invalid asset id 'USDC'")
+}
+
+func (s *CrosschainTestSuite) TestAddSignatures() {
+	require := s.Require()
+	tx := &Tx{}
+	_, err := tx.Sighashes()
+	require.EqualError(err, "transaction not initialized")
+	require.EqualError(tx.AddSignatures(), "expecting 1 signature")
+	require.EqualError(tx.AddSignatures(make([]byte, 65)), "invalid signature length 65")
+}
+
+func (s *CrosschainTestSuite) TestImportPrivateKey() {
+	require := s.Require()
+	signer, _ := NewSigner(testNativeAsset)
+	seed, _ := hex.DecodeString(testSeed)
+
+	privateKey, err := signer.ImportPrivateKey(testMnemonic)
+	require.NoError(err)
+	require.Equal(seed, []byte(privateKey))
+
+	privateKey, err = signer.ImportPrivateKey(testSeed)
+	require.NoError(err)
+	require.Equal(seed, []byte(privateKey))
+
+	publicKey, err := signer.(xc.PublicKeyDeriver).DerivePublicKey(privateKey)
+	require.NoError(err)
+	require.Equal(testPublicKey, hex.EncodeToString(publicKey))
+
+	// the checksum word of another key
+	_, err = signer.ImportPrivateKey(strings.Replace(testMnemonic, "unfair", "abandon", 1))
+	require.EqualError(err, "invalid mnemonic: invalid checksum")
+	_, err = signer.ImportPrivateKey(strings.Replace(testMnemonic, "crisp", "crispy", 1))
+	require.EqualError(err, "invalid mnemonic: unknown word 'crispy'")
+	_, err = signer.ImportPrivateKey(strings.Replace(testMnemonic, "about", "zoo", 1))
+	require.EqualError(err, "invalid mnemonic: invalid encoding")
+	_, err = signer.ImportPrivateKey(string(testAddress))
+	require.EqualError(err, "invalid ed25519 private key")
+}
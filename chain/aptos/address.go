@@ -3,7 +3,10 @@ package aptos
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
 
+	transactionbuilder "github.com/coming-chat/go-aptos/transaction_builder"
 	xc "github.com/jumpcrypto/crosschain"
 	"golang.org/x/crypto/sha3"
 )
@@ -13,6 +16,7 @@ type AddressBuilder struct {
 }
 
 var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
 
 // NewAddressBuilder creates a new Template AddressBuilder
 func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
@@ -46,3 +50,28 @@ func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []b
 		},
 	}, err
 }
+
+// ValidateAddress checks an address is 0x and up to 32 bytes of hex, leading zeros may be omitted as in 0x1
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	_, err := decodeAddress(address)
+	return err
+}
+
+// decodeAddress parses an Aptos account address into its 32 byte form.
+// Short addresses such as 0x1 are left padded with zeros.
+func decodeAddress(address xc.Address) ([transactionbuilder.ADDRESS_LENGTH]byte, error) {
+	addr := [transactionbuilder.ADDRESS_LENGTH]byte{}
+	str := string(address)
+	if !strings.HasPrefix(str, "0x") || len(str) == 2 || len(str) > 2+64 {
+		return addr, fmt.Errorf("invalid address '%s': expected 0x and up to 32 bytes of hex", address)
+	}
+	if len(str)%2 != 0 {
+		str = "0x0" + str[2:]
+	}
+	bz, err := hex.DecodeString(str[2:])
+	if err != nil {
+		return addr, fmt.Errorf("invalid address '%s': not hex", address)
+	}
+	copy(addr[len(addr)-len(bz):], bz)
+	return addr, nil
+}
@@ -55,3 +55,18 @@ func (s *CrosschainTestSuite) TestGetAllPossibleAddressesFromPublicKey() {
 	require.Equal(xc.Address("0xa589a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab85"), addresses[0].Address)
 	require.Equal(xc.AddressTypeDefault, addresses[0].Type)
 }
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{})
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("0xa589a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab85"))
+	// leading zeros omitted
+	require.NoError(validator.ValidateAddress("0x1"))
+	require.NoError(validator.ValidateAddress("0x2"))
+
+	require.EqualError(validator.ValidateAddress("a589a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab85"), "invalid address 'a589a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab85': expected 0x and up to 32 bytes of hex")
+	require.EqualError(validator.ValidateAddress("0xa589a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab8500"), "invalid address '0xa589a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab8500': expected 0x and up to 32 bytes of hex")
+	require.EqualError(validator.ValidateAddress("0x1z"), "invalid address '0x1z': not hex")
+	require.Error(validator.ValidateAddress("0x"))
+}
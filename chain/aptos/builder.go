@@ -2,6 +2,7 @@ package aptos
 
 import (
 	"errors"
+	"fmt"
 
 	transactionbuilder "github.com/coming-chat/go-aptos/transaction_builder"
 	xc "github.com/jumpcrypto/crosschain"
@@ -23,6 +24,9 @@ func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
 
 // NewTransfer creates a new transfer for an Asset, either native or token
 func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
 	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
 		return txBuilder.NewTokenTransfer(from, to, amount, input)
 	}
@@ -31,52 +35,29 @@ func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc
 
 // NewNativeTransfer creates a new transfer for a native asset
 func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
-	var local_input TxInput
-	var ok bool
-	// Either ptr or full type is okay.
-	if local_input, ok = input.(TxInput); !ok {
-		var ptr *TxInput
-		if ptr, ok = (input.(*TxInput)); !ok {
-			return &Tx{}, errors.New("xc.TxInput is not from an aptos chain")
-		}
-		local_input = *ptr
-	}
-	to_addr := [transactionbuilder.ADDRESS_LENGTH]byte{}
-	from_addr := [transactionbuilder.ADDRESS_LENGTH]byte{}
-	copy(from_addr[:], mustDecodeHex(string(from)))
-	copy(to_addr[:], mustDecodeHex(string(to)))
-	toAmountBytes := transactionbuilder.BCSSerializeBasicValue(amount.Int().Uint64())
+	return txBuilder.newEntryFunctionTx(from, to, amount, input, "transfer", nil)
+}
 
-	chain_id := local_input.ChainId
-	moduleName, err := transactionbuilder.NewModuleIdFromString("0x1::aptos_account")
-	if err != nil {
-		return &Tx{}, err
+// NewTokenTransfer creates a new transfer for a token asset, using the contract as the coin type.
+// aptos_account::transfer_coins registers the coin store for the recipient if needed.
+func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	contract := ""
+	if token, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		contract = token.Contract
 	}
-	payload := transactionbuilder.TransactionPayloadEntryFunction{
-		ModuleName:   *moduleName,
-		FunctionName: "transfer",
-		Args: [][]byte{
-			to_addr[:], toAmountBytes,
-		},
+	if contract == "" {
+		contract = txBuilder.Asset.GetAssetConfig().Contract
 	}
 
-	return &Tx{
-		tx: transactionbuilder.RawTransaction{
-			Sender:         from_addr,
-			SequenceNumber: local_input.SequenceNumber,
-			Payload:        payload,
-			MaxGasAmount:   local_input.GasLimit,
-			GasUnitPrice:   local_input.GasPrice,
-			// ~1 hour expiration
-			ExpirationTimestampSecs: local_input.Timestamp + 60*60,
-			ChainId:                 uint8(chain_id),
-		},
-		Input: local_input,
-	}, nil
+	typeTag, err := transactionbuilder.NewTypeTagStructFromString(contract)
+	if err != nil {
+		return nil, err
+	}
+	return txBuilder.newEntryFunctionTx(from, to, amount, input, "transfer_coins", []transactionbuilder.TypeTag{*typeTag})
 }
 
-// NewTokenTransfer creates a new transfer for a token asset
-func (txb *TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+// newEntryFunctionTx builds a call to 0x1::aptos_account::<function>(to, amount)
+func (txBuilder TxBuilder) newEntryFunctionTx(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput, function string, typeArgs []transactionbuilder.TypeTag) (xc.Tx, error) {
 	var local_input TxInput
 	var ok bool
 	// Either ptr or full type is okay.
@@ -87,38 +68,33 @@ func (txb *TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amount xc
 		}
 		local_input = *ptr
 	}
-	to_addr := [transactionbuilder.ADDRESS_LENGTH]byte{}
-	from_addr := [transactionbuilder.ADDRESS_LENGTH]byte{}
-	copy(from_addr[:], mustDecodeHex(string(from)))
-	copy(to_addr[:], mustDecodeHex(string(to)))
-	toAmountBytes := transactionbuilder.BCSSerializeBasicValue(amount.Int().Uint64())
-
-	contract := ""
-	if token, ok := txb.Asset.(*xc.TokenAssetConfig); ok {
-		contract = token.Contract
-	}
-	if contract == "" {
-		contract = txb.Asset.GetAssetConfig().Contract
+	from_addr, err := decodeAddress(from)
+	if err != nil {
+		return &Tx{}, err
 	}
-
-	typeTag, err := transactionbuilder.NewTypeTagStructFromString(contract)
+	to_addr, err := decodeAddress(to)
 	if err != nil {
-		return nil, err
+		return &Tx{}, err
+	}
+	if amount.Int().Sign() < 0 || !amount.Int().IsUint64() {
+		return &Tx{}, fmt.Errorf("invalid amount %s: must fit in a u64", amount.String())
 	}
+	toAmountBytes := transactionbuilder.BCSSerializeBasicValue(amount.Int().Uint64())
 
 	chain_id := local_input.ChainId
-	moduleName, err := transactionbuilder.NewModuleIdFromString("0x1::coin")
+	moduleName, err := transactionbuilder.NewModuleIdFromString("0x1::aptos_account")
 	if err != nil {
 		return &Tx{}, err
 	}
 	payload := transactionbuilder.TransactionPayloadEntryFunction{
 		ModuleName:   *moduleName,
-		FunctionName: "transfer",
-		TyArgs:       []transactionbuilder.TypeTag{*typeTag},
+		FunctionName: transactionbuilder.Identifier(function),
+		TyArgs:       typeArgs,
 		Args: [][]byte{
 			to_addr[:], toAmountBytes,
 		},
 	}
+
 	return &Tx{
 		tx: transactionbuilder.RawTransaction{
 			Sender:         from_addr,
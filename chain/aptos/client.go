@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/coming-chat/go-aptos/aptosclient"
 	"github.com/coming-chat/go-aptos/aptostypes"
@@ -22,7 +24,18 @@ var _ xc.FullClientWithGas = &Client{}
 // NewClient returns a new Aptos Client
 func NewClient(cfgI xc.ITask) (*Client, error) {
 	cfg := cfgI.GetNativeAsset()
-	client, err := aptosclient.Dial(context.Background(), cfg.URL)
+	// same defaults as aptosclient.Dial
+	transport, err := cfg.HTTPTransport(&http.Transport{
+		MaxIdleConns:    3,
+		IdleConnTimeout: 30 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := aptosclient.DialWithClient(context.Background(), cfg.URL, &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+	})
 	return &Client{
 		Asset:       cfgI,
 		AptosClient: client,
@@ -45,23 +58,28 @@ func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, _ xc.Ad
 	}
 
 	return &TxInput{
-		TxInputEnvelope: xc.TxInputEnvelope{
-			Type: xc.DriverAptos,
-		},
-		SequenceNumber: acc.SequenceNumber,
-		ChainId:        ledger.ChainId,
-		GasLimit:       2000,
-		Timestamp:      ledger.LedgerTimestamp,
-		GasPrice:       gas_price.Uint64(),
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverAptos),
+		SequenceNumber:  acc.SequenceNumber,
+		ChainId:         ledger.ChainId,
+		GasLimit:        2000,
+		Timestamp:       ledger.LedgerTimestamp,
+		GasPrice:        gas_price.Uint64(),
 	}, nil
 }
 
 // SubmitTx submits a Aptos tx
 func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
 	tx_bz, err := tx.Serialize()
 	if err != nil {
 		return err
 	}
+	// simulations of aptos nodes reject signed txs
+	if xc.IsDryRun(ctx, client.Asset) {
+		return xc.RecordDryRun(ctx, client.Asset, tx, false)
+	}
 	newTxn, err := client.AptosClient.SubmitSignedBCSTransaction(tx_bz)
 	_ = newTxn
 	return err
@@ -102,7 +120,7 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxI
 		BlockTime:   int64((tx.Timestamp / 1000) / 1000),
 		TxID:        tx.Hash,
 		BlockIndex:  int64(tx.Version),
-		ExplorerURL: fmt.Sprintf("/txn/%d?network=%s", tx.Version, client.Asset.GetNativeAsset().Net),
+		ExplorerURL: fmt.Sprintf("/txn/%d?network=%s", tx.Version, client.Asset.GetNativeAsset().Net.Kind()),
 	}, nil
 }
 
@@ -131,7 +149,16 @@ func (client *Client) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc
 	client.EstimateGasFunc = estimateGas
 }
 
+// EstimateGas estimates the gas unit price, floored to the configured min gas price
 func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	gasPrice, err := client.estimateGas(ctx)
+	if err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), err
+	}
+	return gasPrice.ApplyFloor(xc.NewAmountBlockchainFromUint64(uint64(client.Asset.GetNativeAsset().MinGasPrice))), nil
+}
+
+func (client *Client) estimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
 	// invoke EstimateGasFunc callback, if registered
 	if client.EstimateGasFunc != nil {
 		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 
+	transactionbuilder "github.com/coming-chat/go-aptos/transaction_builder"
 	xc "github.com/jumpcrypto/crosschain"
 	"github.com/jumpcrypto/crosschain/test"
 )
@@ -349,7 +350,7 @@ func (s *CrosschainTestSuite) TestNewTokenTransfer() {
 	ser, err := tf.Serialize()
 	require.NoError(err)
 	require.True(len(ser) > 64)
-	require.Equal("a589a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab85030000000000000002000000000000000000000000000000000000000000000000000000000000000104636f696e087472616e736665720107000000000000000000000000000000000000000000000000000000000000000104436f696e0455534443000220bb89a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab00080100000000000000d0070000000000000a00000000000000493e000000000000010020010203040506070801020304050607080102030405060708010203040506070840000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f", hex.EncodeToString(ser))
+	require.Equal("a589a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab8503000000000000000200000000000000000000000000000000000000000000000000000000000000010d6170746f735f6163636f756e740e7472616e736665725f636f696e730107000000000000000000000000000000000000000000000000000000000000000104436f696e0455534443000220bb89a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab00080100000000000000d0070000000000000a00000000000000493e000000000000010020010203040506070801020304050607080102030405060708010203040506070840000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f", hex.EncodeToString(ser))
 
 	// use invalid contract address
 	bad_asset := &xc.TokenAssetConfig{Asset: "USDC", Contract: "0x112345", NativeAssetConfig: native_asset}
@@ -357,3 +358,59 @@ func (s *CrosschainTestSuite) TestNewTokenTransfer() {
 	_, err = builder.(xc.TxTokenBuilder).NewTokenTransfer(from, to, amount, input)
 	require.ErrorContains(err, "Invalid struct tag string literal")
 }
+
+func (s *CrosschainTestSuite) TestEstimateGasMinGasPrice() {
+	require := s.Require()
+	resp := `{"chain_id":38,"epoch":"133","ledger_version":"13087045","oldest_ledger_version":"0","ledger_timestamp":"1669676013555573","node_role":"full_node","oldest_block_height":"0","block_height":"5435983","git_hash":"2c74a456298fcd520241a562119b6fe30abdaae2"}`
+	server, close := test.MockHTTP(&s.Suite, resp)
+	defer close()
+
+	client, err := NewClient(&xc.AssetConfig{NativeAsset: xc.APTOS, URL: server.URL, MinGasPrice: 100})
+	require.NoError(err)
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(0), nil
+	})
+	gasPrice, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("100", gasPrice.String())
+}
+
+func (s *CrosschainTestSuite) TestNewTransferAddressesAndAmount() {
+	require := s.Require()
+
+	asset := &xc.AssetConfig{NativeAsset: xc.APTOS, Net: "devnet"}
+	builder := TxBuilder{Asset: asset}
+	from := xc.Address("0xa589a80d61ec380c24a5fdda109c3848c082584e6cb725e5ab19b18354b2ab85")
+	input := &TxInput{
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverAptos),
+		SequenceNumber:  3,
+		GasLimit:        2000,
+		GasPrice:        10,
+		Timestamp:       12345,
+		ChainId:         1,
+	}
+
+	// short addresses are left padded
+	tf, err := builder.NewNativeTransfer(from, xc.Address("0xabc"), xc.NewAmountBlockchainFromUint64(1), input)
+	require.NoError(err)
+	payload := tf.(*Tx).tx.Payload.(transactionbuilder.TransactionPayloadEntryFunction)
+	require.Equal("0000000000000000000000000000000000000000000000000000000000000abc", hex.EncodeToString(payload.Args[0]))
+
+	_, err = builder.NewNativeTransfer(from, xc.Address("abc"), xc.NewAmountBlockchainFromUint64(1), input)
+	require.EqualError(err, "invalid address 'abc': expected 0x and up to 32 bytes of hex")
+	_, err = builder.NewNativeTransfer(xc.Address("0xzz"), xc.Address("0x1"), xc.NewAmountBlockchainFromUint64(1), input)
+	require.EqualError(err, "invalid address '0xzz': not hex")
+
+	// amounts are u64
+	_, err = builder.NewNativeTransfer(from, xc.Address("0x1"), xc.NewAmountBlockchainFromStr("18446744073709551616"), input)
+	require.EqualError(err, "invalid amount 18446744073709551616: must fit in a u64")
+}
+
+func (s *CrosschainTestSuite) TestCheckError() {
+	require := s.Require()
+	require.Equal(xc.NoBalanceForGas, CheckError(fmt.Errorf("Invalid transaction: Type: Validation Code: INSUFFICIENT_BALANCE_FOR_TRANSACTION_FEE")))
+	require.Equal(xc.NoBalance, CheckError(fmt.Errorf("Move abort in 0x1::coin: EINSUFFICIENT_BALANCE(0x10006): Not enough coins to complete transaction")))
+	require.Equal(xc.TransactionFailure, CheckError(fmt.Errorf("Invalid transaction: Type: Validation Code: SEQUENCE_NUMBER_TOO_OLD")))
+	require.Equal(xc.NetworkError, CheckError(fmt.Errorf("Transaction not found by Transaction hash")))
+	require.Equal(xc.UnknownError, CheckError(fmt.Errorf("unknown")))
+}
@@ -8,6 +8,16 @@ import (
 
 func CheckError(err error) xc.ClientError {
 	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "insufficient_balance_for_transaction_fee") {
+		return xc.NoBalanceForGas
+	}
+	if strings.Contains(msg, "einsufficient_balance") {
+		return xc.NoBalance
+	}
+	if strings.Contains(msg, "sequence_number_too_old") ||
+		strings.Contains(msg, "gas_unit_price_below_min_bound") {
+		return xc.TransactionFailure
+	}
 	if strings.Contains(msg, "transaction underpriced") {
 		return xc.TransactionFailure
 	}
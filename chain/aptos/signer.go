@@ -3,6 +3,7 @@ package aptos
 import (
 	"crypto/ed25519"
 	"encoding/hex"
+	"fmt"
 
 	"github.com/coming-chat/go-aptos/aptosaccount"
 	xc "github.com/jumpcrypto/crosschain"
@@ -56,3 +57,12 @@ func (signer Signer) Sign(privateKeyBz xc.PrivateKey, data xc.TxDataToSign) (xc.
 	privateKey := ed25519.NewKeyFromSeed(privateKeyBz)
 	return ed25519.Sign(privateKey, data), nil
 }
+
+// DerivePublicKey returns the public key of an Aptos private key seed
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	if len(privateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+	}
+	publicKey := ed25519.NewKeyFromSeed(privateKey).Public().(ed25519.PublicKey)
+	return xc.PublicKey(publicKey), nil
+}
@@ -24,12 +24,17 @@ type TxInput struct {
 
 func NewTxInput() *TxInput {
 	return &TxInput{
-		TxInputEnvelope: xc.TxInputEnvelope{
-			Type: xc.DriverAptos,
-		},
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverAptos),
 	}
 }
 
+var _ xc.TxInputNonce = &TxInput{}
+
+// GetNonce returns the sequence number of the sender
+func (input *TxInput) GetNonce() uint64 {
+	return input.SequenceNumber
+}
+
 type Tx struct {
 	Input              TxInput
 	tx                 transactionbuilder.RawTransaction
@@ -2,7 +2,6 @@ package aptos
 
 import (
 	"encoding/hex"
-	"strings"
 
 	"github.com/coming-chat/go-aptos/aptostypes"
 	transactionbuilder "github.com/coming-chat/go-aptos/transaction_builder"
@@ -11,15 +10,6 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func mustDecodeHex(h string) []byte {
-	h = strings.Replace(h, "0x", "", 1)
-	bz, err := hex.DecodeString(h)
-	if err != nil {
-		panic(err)
-	}
-	return bz
-}
-
 func valueFromTxPayload(payload transactionbuilder.TransactionPayload) xc.AmountBlockchain {
 	zero := xc.NewAmountBlockchainFromUint64(0)
 	switch payload := payload.(type) {
@@ -0,0 +1,165 @@
+package avalanche
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/cosmos/btcutil/bech32"
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/mr-tron/base58"
+)
+
+// nodeIDPrefix prefixes the cb58 ids of nodes, e.g. NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg
+const nodeIDPrefix = "NodeID-"
+
+// AddressBuilder for the X-Chain and the P-Chain of Avalanche
+type AddressBuilder struct {
+	Alias string
+	HRP   string
+}
+
+var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
+
+// NewAddressBuilder creates a new Avalanche AddressBuilder, of the chain and network of asset
+func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
+	native := asset.GetNativeAsset()
+	alias := xc.AvalancheChainAlias(native.NativeAsset)
+	if alias != xc.AvalancheXChain && alias != xc.AvalanchePChain {
+		return nil, fmt.Errorf("unsupported avalanche chain: %s", native.NativeAsset)
+	}
+	return AddressBuilder{
+		Alias: alias,
+		HRP:   native.GetAvalanche().HRP,
+	}, nil
+}
+
+// GetAddressFromPublicKey returns the address of a k256 public key, compressed or not: the ripemd160 of the sha256
+// of the compressed key, in bech32 prefixed with the alias of the chain, e.g. X-avax1...
+func (ab AddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (xc.Address, error) {
+	shortID, err := ShortID(publicKeyBytes)
+	if err != nil {
+		return xc.Address(""), err
+	}
+	return FormatAddress(ab.Alias, ab.HRP, shortID)
+}
+
+// GetAllPossibleAddressesFromPublicKey returns all PossubleAddress(es) given a public key
+func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]xc.PossibleAddress, error) {
+	address, err := ab.GetAddressFromPublicKey(publicKeyBytes)
+	return []xc.PossibleAddress{
+		{
+			Address: address,
+			Type:    xc.AddressTypeDefault,
+		},
+	}, err
+}
+
+// ValidateAddress checks an address is a bech32 address of the network, with the alias of the chain or without alias
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	alias, hrp, _, err := ParseAddress(address)
+	if err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	if alias != "" && alias != ab.Alias {
+		return fmt.Errorf("invalid address '%s': address of the %s-Chain, expected the %s-Chain", address, alias, ab.Alias)
+	}
+	if hrp != ab.HRP {
+		return fmt.Errorf("invalid address '%s': address of network '%s', expected '%s'", address, hrp, ab.HRP)
+	}
+	return nil
+}
+
+// ShortID returns the 20-byte short id of a k256 public key, compressed or not
+func ShortID(publicKeyBytes []byte) ([]byte, error) {
+	if len(publicKeyBytes) != 33 {
+		publicKey, err := crypto.UnmarshalPubkey(publicKeyBytes)
+		if err != nil {
+			return nil, errors.New("invalid k256 public key")
+		}
+		publicKeyBytes = crypto.CompressPubkey(publicKey)
+	} else if _, err := crypto.DecompressPubkey(publicKeyBytes); err != nil {
+		return nil, errors.New("invalid k256 public key")
+	}
+	return btcutil.Hash160(publicKeyBytes), nil
+}
+
+// FormatAddress returns the address of a short id on a chain
+func FormatAddress(alias string, hrp string, shortID []byte) (xc.Address, error) {
+	address, err := bech32.EncodeFromBase256(hrp, shortID)
+	if err != nil {
+		return xc.Address(""), err
+	}
+	return xc.Address(alias + "-" + address), nil
+}
+
+// ParseAddress returns the alias of the chain, the hrp and the short id of an address, e.g. X-avax1..., the alias
+// is empty for addresses without alias
+func ParseAddress(address xc.Address) (string, string, []byte, error) {
+	str := strings.TrimSpace(string(address))
+	alias, bech, ok := strings.Cut(str, "-")
+	if !ok {
+		alias, bech = "", str
+	}
+	hrp, shortID, err := bech32.DecodeToBase256(bech)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if len(shortID) != 20 {
+		return "", "", nil, errors.New("invalid length")
+	}
+	return alias, hrp, shortID, nil
+}
+
+// EncodeCB58 returns the cb58 encoding of data: base58 with the last 4 bytes of its sha256 as checksum
+func EncodeCB58(data []byte) string {
+	checksum := sha256.Sum256(data)
+	return base58.Encode(append(append([]byte{}, data...), checksum[len(checksum)-4:]...))
+}
+
+// DecodeCB58 decodes a cb58 string, e.g. an id
+func DecodeCB58(str string) ([]byte, error) {
+	decoded, err := base58.Decode(str)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 4 {
+		return nil, errors.New("invalid cb58: missing checksum")
+	}
+	data, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	expected := sha256.Sum256(data)
+	if !bytes.Equal(checksum, expected[len(expected)-4:]) {
+		return nil, errors.New("invalid cb58: invalid checksum")
+	}
+	return data, nil
+}
+
+// DecodeID decodes a cb58 id of 32 bytes, e.g. of a tx, an asset or a chain
+func DecodeID(id string) ([]byte, error) {
+	decoded, err := DecodeCB58(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id '%s': %v", id, err)
+	}
+	if len(decoded) != 32 {
+		return nil, fmt.Errorf("invalid id '%s': invalid length", id)
+	}
+	return decoded, nil
+}
+
+// ParseNodeID returns the 20 bytes of the id of a node, e.g. NodeID-7Xhw2mDxuDS44j42TCB6U5579esbSt3Lg
+func ParseNodeID(nodeID xc.Address) ([]byte, error) {
+	str := strings.TrimSpace(string(nodeID))
+	if !strings.HasPrefix(str, nodeIDPrefix) {
+		return nil, fmt.Errorf("invalid node id '%s': expected %s prefix", nodeID, nodeIDPrefix)
+	}
+	decoded, err := DecodeCB58(strings.TrimPrefix(str, nodeIDPrefix))
+	if err != nil || len(decoded) != 20 {
+		return nil, fmt.Errorf("invalid node id '%s'", nodeID)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,99 @@
+package avalanche
+
+import (
+	"encoding/hex"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestNewAddressBuilder() {
+	require := s.Require()
+	builder, err := NewAddressBuilder(testAsset(xc.XAVAX, ""))
+	require.NoError(err)
+	require.NotNil(builder)
+
+	_, err = NewAddressBuilder(testAsset(xc.AVAX, ""))
+	require.EqualError(err, "unsupported avalanche chain: AVAX")
+}
+
+func (s *CrosschainTestSuite) TestGetAddressFromPublicKey() {
+	require := s.Require()
+	publicKey, _ := hex.DecodeString(testPublicKey)
+
+	vectors := []struct {
+		asset   *xc.NativeAssetConfig
+		address string
+	}{
+		{testAsset(xc.XAVAX, ""), "X-fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4t"},
+		{testAsset(xc.PAVAX, ""), "P-fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4t"},
+		{&xc.NativeAssetConfig{NativeAsset: xc.XAVAX, Net: xc.Mainnet}, "X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5"},
+		{&xc.NativeAssetConfig{NativeAsset: xc.PAVAX, Avalanche: xc.AvalancheConfig{NetworkID: 12345, HRP: "local"}}, "P-local18jma8ppw3nhx5r4ap8clazz0dps7rv5u00z96u"},
+	}
+	for _, v := range vectors {
+		builder, _ := NewAddressBuilder(v.asset)
+		address, err := builder.GetAddressFromPublicKey(publicKey)
+		require.NoError(err)
+		require.Equal(xc.Address(v.address), address)
+
+		addresses, err := builder.GetAllPossibleAddressesFromPublicKey(publicKey)
+		require.NoError(err)
+		require.Len(addresses, 1)
+		require.Equal(xc.Address(v.address), addresses[0].Address)
+	}
+
+	builder, _ := NewAddressBuilder(testAsset(xc.XAVAX, ""))
+	_, err := builder.GetAddressFromPublicKey(publicKey[1:])
+	require.Error(err)
+}
+
+func (s *CrosschainTestSuite) TestParseAddress() {
+	require := s.Require()
+	alias, hrp, shortID, err := ParseAddress("P-fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4t")
+	require.NoError(err)
+	require.Equal("P", alias)
+	require.Equal("fuji", hrp)
+	require.Equal(testShortID, hex.EncodeToString(shortID))
+
+	alias, _, _, err = ParseAddress("fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4t")
+	require.NoError(err)
+	require.Equal("", alias)
+
+	for _, address := range []xc.Address{
+		"P-fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4u",
+		"P-",
+	} {
+		_, _, _, err := ParseAddress(address)
+		require.Error(err, address)
+	}
+}
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builderI, _ := NewAddressBuilder(testAsset(xc.XAVAX, ""))
+	builder := builderI.(xc.AddressValidator)
+	require.NoError(builder.ValidateAddress("X-fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4t"))
+	require.Error(builder.ValidateAddress("P-fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4t"))
+	require.Error(builder.ValidateAddress("X-avax18jma8ppw3nhx5r4ap8clazz0dps7rv5ukulre5"))
+	require.Error(builder.ValidateAddress("0x8fd379246834eac74B8419FfdA202CF8051F7A03"))
+}
+
+func (s *CrosschainTestSuite) TestCB58() {
+	require := s.Require()
+	require.Equal("11111111111111111111111111111111LpoYY", EncodeCB58(make([]byte, 32)))
+
+	id, err := DecodeID("11111111111111111111111111111111LpoYY")
+	require.NoError(err)
+	require.Equal(make([]byte, 32), id)
+
+	_, err = DecodeID("11111111111111111111111111111111LpoYZ")
+	require.Error(err)
+	_, err = DecodeID(EncodeCB58(make([]byte, 20)))
+	require.Error(err)
+
+	nodeID, err := ParseNodeID("NodeID-" + xc.Address(EncodeCB58(make([]byte, 20))))
+	require.NoError(err)
+	require.Equal(make([]byte, 20), nodeID)
+
+	_, err = ParseNodeID(xc.Address(EncodeCB58(make([]byte, 20))))
+	require.Error(err)
+}
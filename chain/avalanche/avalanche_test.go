@@ -0,0 +1,37 @@
+package avalanche
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+// the ewoq key of the local networks of avalanchego
+const testPrivateKey = "PrivateKey-ewoqjP7PxY4yr3iLTpLisriqt94hdyDFNgchSxGGztUrTXtNN"
+const testPublicKey = "0327448e78ffa8cdb24cf19be0204ad954b1bdb4db8c51183534c1eecf2ebd094e"
+const testShortID = "3cb7d3842e8cee6a0ebd09f1fe884f6861e1b29c"
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+	now = func() time.Time { return time.Unix(1700000000, 0) }
+}
+
+func (s *CrosschainTestSuite) TearDownTest() {
+	now = time.Now
+}
+
+func TestAvalancheTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func testAsset(native xc.NativeAsset, url string) *xc.NativeAssetConfig {
+	return &xc.NativeAssetConfig{NativeAsset: native, Net: xc.Fuji, URL: url}
+}
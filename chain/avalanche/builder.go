@@ -0,0 +1,318 @@
+package avalanche
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// TxBuilder for the X-Chain and the P-Chain of Avalanche
+type TxBuilder struct {
+	Asset xc.ITask
+}
+
+var _ xc.TxBuilder = &TxBuilder{}
+var _ xc.TxAtomicBuilder = &TxBuilder{}
+var _ xc.TxStakingBuilder = &TxBuilder{}
+
+// NewTxBuilder creates a new Avalanche TxBuilder
+func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
+	return &TxBuilder{
+		Asset: asset,
+	}, nil
+}
+
+// network is the chain of a TxBuilder: its alias, id, and the network and AVAX ids
+type network struct {
+	Alias        string
+	Config       xc.AvalancheConfig
+	BlockchainID []byte
+	AssetID      []byte
+}
+
+func (txBuilder TxBuilder) network() (network, error) {
+	native := txBuilder.Asset.GetNativeAsset()
+	alias := xc.AvalancheChainAlias(native.NativeAsset)
+	if alias != xc.AvalancheXChain && alias != xc.AvalanchePChain {
+		return network{}, fmt.Errorf("unsupported avalanche chain: %s", native.NativeAsset)
+	}
+	config := native.GetAvalanche()
+	blockchainID, err := DecodeID(config.ChainIDs[alias])
+	if err != nil {
+		return network{}, fmt.Errorf("invalid id of the %s-Chain: %v", alias, err)
+	}
+	assetID, err := DecodeID(config.AssetID)
+	if err != nil {
+		return network{}, fmt.Errorf("invalid asset id: %v", err)
+	}
+	return network{Alias: alias, Config: config, BlockchainID: blockchainID, AssetID: assetID}, nil
+}
+
+// chainID returns the id of another chain of the network, for atomic txs
+func (net network) chainID(native xc.NativeAsset) ([]byte, error) {
+	alias := xc.AvalancheChainAlias(native)
+	if alias == xc.AvalancheCChain {
+		return nil, errors.New("atomic txs of the C-Chain aren't supported")
+	}
+	if alias == "" || alias == net.Alias {
+		return nil, fmt.Errorf("invalid chain of atomic tx: %s", native)
+	}
+	return DecodeID(net.Config.ChainIDs[alias])
+}
+
+// NewTransfer creates a new transfer of AVAX, tokens of the X-Chain aren't supported
+func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		return nil, errors.New("token transfers are not supported on avalanche chains")
+	}
+	return txBuilder.NewNativeTransfer(from, to, amount, input)
+}
+
+// NewNativeTransfer creates a new BaseTx transferring AVAX, the change returned to the sender
+func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	net, localInput, err := txBuilder.parse(input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	recipient, err := parseAddress(to, "to")
+	if err != nil {
+		return &Tx{}, err
+	}
+	value, err := parseAmount(amount)
+	if err != nil {
+		return &Tx{}, err
+	}
+	tx, err := txBuilder.newTx(net, from, value, localInput)
+	if err != nil {
+		return &Tx{}, err
+	}
+	tx.TypeID = typeXBaseTx
+	if net.Alias == xc.AvalanchePChain {
+		tx.TypeID = typePBaseTx
+	}
+	tx.Outputs = append(tx.Outputs, newOutput(net.AssetID, value, recipient))
+	sortOutputs(tx.Outputs)
+	return tx, nil
+}
+
+// NewExport creates a new ExportTx of amount to the shared memory of destination, the X-Chain or the P-Chain,
+// owned by to
+func (txBuilder TxBuilder) NewExport(from xc.Address, to xc.Address, amount xc.AmountBlockchain, destination xc.NativeAsset, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	net, localInput, err := txBuilder.parse(input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	chainID, err := net.chainID(destination)
+	if err != nil {
+		return &Tx{}, err
+	}
+	recipient, err := parseAddress(to, "to")
+	if err != nil {
+		return &Tx{}, err
+	}
+	value, err := parseAmount(amount)
+	if err != nil {
+		return &Tx{}, err
+	}
+	tx, err := txBuilder.newTx(net, from, value, localInput)
+	if err != nil {
+		return &Tx{}, err
+	}
+	tx.TypeID = typeXExportTx
+	if net.Alias == xc.AvalanchePChain {
+		tx.TypeID = typePExportTx
+	}
+	tx.Chain = chainID
+	tx.ExportedOutputs = []Output{newOutput(net.AssetID, value, recipient)}
+	return tx, nil
+}
+
+// NewImport creates a new ImportTx of the UTXOs of address exported from source, the UTXOs of the input, to address
+// The fee is paid by the imported UTXOs
+func (txBuilder TxBuilder) NewImport(address xc.Address, source xc.NativeAsset, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	net, localInput, err := txBuilder.parse(input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	chainID, err := net.chainID(source)
+	if err != nil {
+		return &Tx{}, err
+	}
+	owner, err := parseAddress(address, "")
+	if err != nil {
+		return &Tx{}, err
+	}
+	imported, total := spendable(localInput.UTXOs, net.AssetID, owner, ^uint64(0))
+	if total <= localInput.Fee {
+		return &Tx{}, fmt.Errorf("no funds to import from %s: %d nAVAX, for a fee of %d nAVAX", source, total, localInput.Fee)
+	}
+	tx := &Tx{
+		TypeID:         typeXImportTx,
+		NetworkID:      net.Config.NetworkID,
+		BlockchainID:   net.BlockchainID,
+		Outputs:        []Output{newOutput(net.AssetID, total-localInput.Fee, owner)},
+		Inputs:         []Input{},
+		Chain:          chainID,
+		ImportedInputs: imported,
+	}
+	if net.Alias == xc.AvalanchePChain {
+		tx.TypeID = typePImportTx
+	}
+	return tx, nil
+}
+
+// NewDelegate creates a new AddPermissionlessDelegatorTx of the P-Chain, delegating amount to a validator of the
+// primary network (NodeID-...) for the delegation period of the input, rewards paid to the sender
+func (txBuilder TxBuilder) NewDelegate(from xc.Address, validator xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	net, localInput, err := txBuilder.parse(input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	if net.Alias != xc.AvalanchePChain {
+		return &Tx{}, errors.New("delegations are only supported on the P-Chain")
+	}
+	nodeID, err := ParseNodeID(validator)
+	if err != nil {
+		return &Tx{}, err
+	}
+	if localInput.DelegationEnd <= localInput.DelegationStart {
+		return &Tx{}, errors.New("invalid delegation period")
+	}
+	owner, err := parseAddress(from, "from")
+	if err != nil {
+		return &Tx{}, err
+	}
+	value, err := parseAmount(amount)
+	if err != nil {
+		return &Tx{}, err
+	}
+	tx, err := txBuilder.newTx(net, from, value, localInput)
+	if err != nil {
+		return &Tx{}, err
+	}
+	tx.TypeID = typePAddPermissionlessDelegatorTx
+	tx.Delegation = &Delegation{
+		NodeID:       nodeID,
+		Start:        localInput.DelegationStart,
+		End:          localInput.DelegationEnd,
+		Weight:       value,
+		Stake:        []Output{newOutput(net.AssetID, value, owner)},
+		RewardsOwner: Output{Threshold: 1, Addresses: [][]byte{owner}},
+	}
+	return tx, nil
+}
+
+// NewUndelegate isn't supported: delegations of the P-Chain end at the end of their period
+func (txBuilder TxBuilder) NewUndelegate(from xc.Address, validator xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	return nil, errors.New("delegations of the P-Chain can't be undelegated, stake is returned at the end of their period")
+}
+
+// NewWithdrawRewards isn't supported: rewards of the P-Chain are paid at the end of delegations
+func (txBuilder TxBuilder) NewWithdrawRewards(from xc.Address, validator xc.Address, input xc.TxInput) (xc.Tx, error) {
+	return nil, errors.New("rewards of the P-Chain are paid at the end of delegations")
+}
+
+func (txBuilder TxBuilder) parse(input xc.TxInput) (network, TxInput, error) {
+	var localInput TxInput
+	switch typed := input.(type) {
+	case TxInput:
+		localInput = typed
+	case *TxInput:
+		localInput = *typed
+	default:
+		return network{}, localInput, errors.New("xc.TxInput is not from an avalanche chain")
+	}
+	net, err := txBuilder.network()
+	return net, localInput, err
+}
+
+// newTx returns a tx spending the UTXOs of from for amount and the fee, with an output of the change
+func (txBuilder TxBuilder) newTx(net network, from xc.Address, amount uint64, input TxInput) (*Tx, error) {
+	owner, err := parseAddress(from, "from")
+	if err != nil {
+		return nil, err
+	}
+	needed := amount + input.Fee
+	if needed < amount {
+		return nil, errors.New("invalid amount: overflow")
+	}
+	inputs, total := spendable(input.UTXOs, net.AssetID, owner, needed)
+	if total < needed {
+		return nil, fmt.Errorf("insufficient funds: %d nAVAX spendable, %d nAVAX needed", total, needed)
+	}
+	outputs := []Output{}
+	if total > needed {
+		outputs = append(outputs, newOutput(net.AssetID, total-needed, owner))
+	}
+	return &Tx{
+		NetworkID:    net.Config.NetworkID,
+		BlockchainID: net.BlockchainID,
+		Outputs:      outputs,
+		Inputs:       inputs,
+	}, nil
+}
+
+// spendable returns inputs spending the UTXOs of AVAX owner can sign alone, until amount, and their total
+func spendable(utxos []UTXO, assetID []byte, owner []byte, amount uint64) ([]Input, uint64) {
+	inputs := []Input{}
+	total := uint64(0)
+	for _, utxo := range utxos {
+		if total >= amount {
+			break
+		}
+		if !bytes.Equal(utxo.AssetID, assetID) || utxo.Locktime != 0 || utxo.Threshold != 1 {
+			continue
+		}
+		for i, address := range utxo.Addresses {
+			if bytes.Equal(address, owner) {
+				inputs = append(inputs, Input{
+					TxID:        utxo.TxID,
+					OutputIndex: utxo.OutputIndex,
+					AssetID:     utxo.AssetID,
+					Amount:      utxo.Amount,
+					SigIndices:  []uint32{uint32(i)},
+				})
+				total += utxo.Amount
+				break
+			}
+		}
+	}
+	sortInputs(inputs)
+	return inputs, total
+}
+
+func newOutput(assetID []byte, amount uint64, owner []byte) Output {
+	return Output{AssetID: assetID, Amount: amount, Threshold: 1, Addresses: [][]byte{owner}}
+}
+
+func parseAddress(address xc.Address, name string) ([]byte, error) {
+	_, _, shortID, err := ParseAddress(address)
+	if err != nil {
+		if name != "" {
+			return nil, fmt.Errorf("invalid %s address '%s': %v", name, address, err)
+		}
+		return nil, fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	return shortID, nil
+}
+
+func parseAmount(amount xc.AmountBlockchain) (uint64, error) {
+	if amount.Int().Sign() <= 0 || !amount.Int().IsUint64() {
+		return 0, fmt.Errorf("invalid amount %s", amount.String())
+	}
+	return amount.Uint64(), nil
+}
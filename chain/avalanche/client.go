@@ -0,0 +1,351 @@
+package avalanche
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// utxosPageSize is the max number of UTXOs returned by a call of getUTXOs
+const utxosPageSize = 1024
+
+// maxUTXOPages is the max number of pages of UTXOs fetched, for addresses with many UTXOs
+const maxUTXOPages = 10
+
+var now = time.Now
+
+// Client for the X-Chain and the P-Chain of Avalanche, using the API of the chain: avm or platform
+type Client struct {
+	Asset           xc.ITask
+	RpcClient       *rpc.Client
+	EstimateGasFunc xc.EstimateGasFunc
+}
+
+var _ xc.FullClientWithGas = &Client{}
+var _ xc.ClientAtomic = &Client{}
+
+type apiIndex struct {
+	Address string `json:"address"`
+	UTXO    string `json:"utxo"`
+}
+
+type apiUTXOs struct {
+	NumFetched string    `json:"numFetched"`
+	UTXOs      []string  `json:"utxos"`
+	EndIndex   *apiIndex `json:"endIndex"`
+}
+
+type apiTx struct {
+	Tx string `json:"tx"`
+}
+
+type apiTxStatus struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// NewClient returns a new Avalanche Client, of the url of the X-Chain (/ext/bc/X) or of the P-Chain (/ext/bc/P)
+func NewClient(cfgI xc.ITask) (*Client, error) {
+	cfg := cfgI.GetNativeAsset()
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	client, err := rpc.DialHTTPWithClient(cfg.URL, &http.Client{Transport: transport})
+	if err != nil {
+		return nil, fmt.Errorf("dialing url: %v", cfg.URL)
+	}
+	return &Client{
+		Asset:     cfgI,
+		RpcClient: client,
+	}, nil
+}
+
+// FetchTxInput returns tx input for an Avalanche tx: the UTXOs of the sender, the fee, and the period of
+// delegations starting now
+func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, _ xc.Address) (xc.TxInput, error) {
+	input := NewTxInput()
+	utxos, err := client.fetchUTXOs(ctx, from, "")
+	if err != nil {
+		return input, err
+	}
+	fee, err := client.EstimateGas(ctx)
+	if err != nil {
+		return input, err
+	}
+	input.UTXOs = utxos
+	input.Fee = fee.Uint64()
+	start := now()
+	input.DelegationStart = uint64(start.Unix())
+	input.DelegationEnd = uint64(start.Add(client.Asset.GetNativeAsset().GetAvalanche().DelegationPeriod).Unix())
+	return input, nil
+}
+
+// FetchImportInput returns the input of an import of the UTXOs of address exported from source
+func (client *Client) FetchImportInput(ctx context.Context, address xc.Address, source xc.NativeAsset) (xc.TxInput, error) {
+	input := NewTxInput()
+	alias := xc.AvalancheChainAlias(source)
+	if alias == "" {
+		return input, fmt.Errorf("invalid chain of atomic tx: %s", source)
+	}
+	utxos, err := client.fetchUTXOs(ctx, address, alias)
+	if err != nil {
+		return input, err
+	}
+	fee, err := client.EstimateGas(ctx)
+	if err != nil {
+		return input, err
+	}
+	input.UTXOs = utxos
+	input.Fee = fee.Uint64()
+	return input, nil
+}
+
+// SubmitTx submits an Avalanche tx
+func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		return xc.RecordDryRun(ctx, client.Asset, tx, false)
+	}
+	var res struct {
+		TxID string `json:"txID"`
+	}
+	params := map[string]interface{}{"tx": encodeHex(serialized), "encoding": "hex"}
+	return client.RpcClient.CallContext(ctx, &res, client.method("issueTx"), params)
+}
+
+// FetchTxInfo returns tx info for an Avalanche tx, with its sources (the inputs of the signer), its destinations,
+// exported and staked outputs included, and its change
+// Txs are final once accepted (X-Chain) or committed (P-Chain): they have a single confirmation
+func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
+	var res apiTx
+	params := map[string]interface{}{"txID": string(txHash), "encoding": "hex"}
+	if err := client.RpcClient.CallContext(ctx, &res, client.method("getTx"), params); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching tx '%s': %v", txHash, err)
+	}
+	data, err := decodeHex(res.Tx)
+	if err != nil {
+		return xc.TxInfo{}, fmt.Errorf("invalid tx '%s': %v", txHash, err)
+	}
+	tx, err := ParseTx(data)
+	if err != nil {
+		return xc.TxInfo{}, fmt.Errorf("invalid tx '%s': %v", txHash, err)
+	}
+	var status apiTxStatus
+	if err := client.RpcClient.CallContext(ctx, &status, client.method("getTxStatus"), map[string]interface{}{"txID": string(txHash)}); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching status of tx '%s': %v", txHash, err)
+	}
+
+	native := client.Asset.GetNativeAsset()
+	config := native.GetAvalanche()
+	alias := xc.AvalancheChainAlias(native.NativeAsset)
+	info := xc.TxInfo{
+		TxID:        string(tx.Hash()),
+		ExplorerURL: fmt.Sprintf("/tx/%s", tx.Hash()),
+		Fee:         xc.NewAmountBlockchainFromUint64(0),
+		Amount:      xc.NewAmountBlockchainFromUint64(0),
+	}
+	switch status.Status {
+	case "Accepted", "Committed":
+		info.Confirmations = 1
+	case "Rejected", "Aborted", "Dropped":
+		info.Status = xc.TxStatusFailure
+		info.Error = strings.TrimSpace(status.Status + " " + status.Reason)
+	}
+
+	signer, err := tx.Signer()
+	if err != nil {
+		return info, fmt.Errorf("invalid signature of tx '%s': %v", txHash, err)
+	}
+	info.From, _ = FormatAddress(alias, config.HRP, signer)
+	assetID, _ := DecodeID(config.AssetID)
+	in, out := uint64(0), uint64(0)
+	for _, input := range append(append([]Input{}, tx.Inputs...), tx.ImportedInputs...) {
+		if bytes.Equal(input.AssetID, assetID) {
+			in += input.Amount
+			info.Sources = append(info.Sources, &xc.TxInfoEndpoint{Address: info.From, Amount: xc.NewAmountBlockchainFromUint64(input.Amount), NativeAsset: native.NativeAsset})
+		}
+	}
+	addEndpoint := func(output Output, chainAlias string, change bool) {
+		if !bytes.Equal(output.AssetID, assetID) || len(output.Addresses) == 0 {
+			return
+		}
+		out += output.Amount
+		address, _ := FormatAddress(chainAlias, config.HRP, output.Addresses[0])
+		endpoint := &xc.TxInfoEndpoint{Address: address, Amount: xc.NewAmountBlockchainFromUint64(output.Amount), NativeAsset: native.NativeAsset}
+		if change {
+			info.Change = append(info.Change, endpoint)
+			return
+		}
+		info.Destinations = append(info.Destinations, endpoint)
+		if info.To == "" {
+			info.To = address
+			info.Amount = endpoint.Amount
+		}
+	}
+	isImport := tx.TypeID == typeXImportTx || tx.TypeID == typePImportTx
+	for _, output := range tx.Outputs {
+		addEndpoint(output, alias, !isImport && len(output.Addresses) > 0 && bytes.Equal(output.Addresses[0], signer))
+	}
+	exportAlias := chainAlias(config, tx.Chain)
+	for _, output := range tx.ExportedOutputs {
+		addEndpoint(output, exportAlias, false)
+	}
+	if tx.Delegation != nil {
+		for _, output := range tx.Delegation.Stake {
+			addEndpoint(output, alias, false)
+		}
+	}
+	if in > out {
+		info.Fee = xc.NewAmountBlockchainFromUint64(in - out)
+	}
+	return info, nil
+}
+
+// FetchBalance fetches the AVAX balance of an address, see FetchNativeBalance
+func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	if _, ok := client.Asset.(*xc.TokenAssetConfig); ok {
+		return xc.NewAmountBlockchainFromUint64(0), errors.New("token balances are not supported on avalanche chains")
+	}
+	return client.FetchNativeBalance(ctx, address)
+}
+
+// FetchNativeBalance fetches the spendable AVAX of an address: its UTXOs neither locked nor shared with other owners
+func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	_, _, owner, err := ParseAddress(address)
+	if err != nil {
+		return zero, fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	assetID, err := DecodeID(client.Asset.GetNativeAsset().GetAvalanche().AssetID)
+	if err != nil {
+		return zero, err
+	}
+	utxos, err := client.fetchUTXOs(ctx, address, "")
+	if err != nil {
+		return zero, err
+	}
+	_, total := spendable(utxos, assetID, owner, ^uint64(0))
+	return xc.NewAmountBlockchainFromUint64(total), nil
+}
+
+func (client *Client) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc) {
+	client.EstimateGasFunc = estimateGas
+}
+
+// EstimateGas returns the fee of txs in nAVAX, tx_fee of the network if not estimated by the callback
+func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	if client.EstimateGasFunc != nil {
+		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+		if res, err := client.EstimateGasFunc(nativeAsset); err == nil {
+			return res, nil
+		}
+		// continue with default implementation as fallback
+	}
+	return xc.NewAmountBlockchainFromUint64(client.Asset.GetNativeAsset().GetAvalanche().TxFee), nil
+}
+
+// method returns a method of the API of the chain of the client
+func (client *Client) method(name string) string {
+	if xc.AvalancheChainAlias(client.Asset.GetNativeAsset().NativeAsset) == xc.AvalanchePChain {
+		return "platform." + name
+	}
+	return "avm." + name
+}
+
+// fetchUTXOs fetches the UTXOs of an address, or its UTXOs exported from the chain of sourceAlias
+func (client *Client) fetchUTXOs(ctx context.Context, address xc.Address, sourceAlias string) ([]UTXO, error) {
+	_, hrp, owner, err := ParseAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	alias := xc.AvalancheChainAlias(client.Asset.GetNativeAsset().NativeAsset)
+	// the API expects the alias of the chain
+	formatted, _ := FormatAddress(alias, hrp, owner)
+	utxos := []UTXO{}
+	var startIndex *apiIndex
+	for page := 0; page < maxUTXOPages; page++ {
+		params := map[string]interface{}{
+			"addresses": []string{string(formatted)},
+			"limit":     utxosPageSize,
+			"encoding":  "hex",
+		}
+		if sourceAlias != "" {
+			params["sourceChain"] = sourceAlias
+		}
+		if startIndex != nil {
+			params["startIndex"] = startIndex
+		}
+		var res apiUTXOs
+		if err := client.RpcClient.CallContext(ctx, &res, client.method("getUTXOs"), params); err != nil {
+			return nil, fmt.Errorf("fetching utxos of '%s': %v", address, err)
+		}
+		for _, encoded := range res.UTXOs {
+			data, err := decodeHex(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("invalid utxo: %v", err)
+			}
+			utxo, err := ParseUTXO(data)
+			if err != nil {
+				// e.g. outputs of NFTs
+				continue
+			}
+			utxos = append(utxos, utxo)
+		}
+		fetched, _ := strconv.Atoi(res.NumFetched)
+		if fetched < utxosPageSize || res.EndIndex == nil {
+			break
+		}
+		startIndex = res.EndIndex
+	}
+	return utxos, nil
+}
+
+// chainAlias returns the alias of a chain of the network by id
+func chainAlias(config xc.AvalancheConfig, id []byte) string {
+	for alias, chainID := range config.ChainIDs {
+		if decoded, err := DecodeCB58(chainID); err == nil && bytes.Equal(decoded, id) {
+			return alias
+		}
+	}
+	return EncodeCB58(id)
+}
+
+// encodeHex returns the hex encoding of the API of avalanchego: 0x, and the data followed by the last 4 bytes of
+// its sha256
+func encodeHex(data []byte) string {
+	checksum := sha256.Sum256(data)
+	return "0x" + hex.EncodeToString(append(append([]byte{}, data...), checksum[len(checksum)-4:]...))
+}
+
+func decodeHex(str string) ([]byte, error) {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 4 {
+		return nil, errors.New("missing checksum")
+	}
+	data, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	expected := sha256.Sum256(data)
+	if !bytes.Equal(checksum, expected[len(expected)-4:]) {
+		return nil, errors.New("invalid checksum")
+	}
+	return data, nil
+}
@@ -0,0 +1,165 @@
+package avalanche
+
+import (
+	"errors"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func testUTXOs(utxos ...UTXO) string {
+	encoded := ""
+	for i, utxo := range utxos {
+		if i > 0 {
+			encoded += ","
+		}
+		encoded += fmt.Sprintf(`"%s"`, encodeHex(utxo.bytes()))
+	}
+	return fmt.Sprintf(`{"numFetched":"%d","utxos":[%s],"endIndex":{"address":"%s","utxo":"0"},"encoding":"hex"}`, len(utxos), encoded, testFrom)
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInput() {
+	require := s.Require()
+	locked := testUTXO(2, 500_000_000)
+	locked.Locktime = 1800000000
+	server, close := test.MockJSONRPC(&s.Suite, testUTXOs(testUTXO(0, 200_000_000), testUTXO(1, 300_000_000), locked))
+	defer close()
+
+	client, err := NewClient(testAsset(xc.PAVAX, server.URL))
+	require.NoError(err)
+	input, err := client.FetchTxInput(s.Ctx, "P-"+testFrom[2:], testTo)
+	require.NoError(err)
+	txInput := input.(*TxInput)
+	require.Equal(xc.DriverAvalanche, txInput.Type)
+	require.Len(txInput.UTXOs, 3)
+	require.Equal(testUTXO(1, 300_000_000), txInput.UTXOs[1])
+	require.EqualValues(1800000000, txInput.UTXOs[2].Locktime)
+	require.EqualValues(xc.DefaultAvalancheTxFee, txInput.Fee)
+	require.EqualValues(1700000000, txInput.DelegationStart)
+	require.EqualValues(1700000000+24*3600, txInput.DelegationEnd)
+
+	server.Response = errors.New("internal error")
+	_, err = client.FetchTxInput(s.Ctx, "P-"+testFrom[2:], testTo)
+	require.ErrorContains(err, "fetching utxos of")
+	_, err = client.FetchTxInput(s.Ctx, "0x00", testTo)
+	require.ErrorContains(err, "invalid address '0x00'")
+}
+
+func (s *CrosschainTestSuite) TestFetchImportInput() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, testUTXOs(testUTXO(0, 200_000_000)))
+	defer close()
+
+	client, _ := NewClient(testAsset(xc.PAVAX, server.URL))
+	input, err := client.FetchImportInput(s.Ctx, "P-"+testFrom[2:], xc.XAVAX)
+	require.NoError(err)
+	require.Len(input.(*TxInput).UTXOs, 1)
+
+	_, err = client.FetchImportInput(s.Ctx, "P-"+testFrom[2:], xc.ETH)
+	require.EqualError(err, "invalid chain of atomic tx: ETH")
+}
+
+func (s *CrosschainTestSuite) TestFetchNativeBalance() {
+	require := s.Require()
+	locked := testUTXO(2, 500_000_000)
+	locked.Locktime = 1800000000
+	server, close := test.MockJSONRPC(&s.Suite, testUTXOs(testUTXO(0, 200_000_000), testUTXO(1, 300_000_000), locked))
+	defer close()
+
+	client, _ := NewClient(testAsset(xc.XAVAX, server.URL))
+	balance, err := client.FetchBalance(s.Ctx, testFrom)
+	require.NoError(err)
+	require.Equal("500000000", balance.String())
+}
+
+func (s *CrosschainTestSuite) TestSubmitTx() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"txID":"2QouvFWUbjuySRxeX5xMbNCuAaKWfbk5FeEa2JmoF85RKLk2dD"}`)
+	defer close()
+
+	client, _ := NewClient(testAsset(xc.XAVAX, server.URL))
+	tx, _ := TxBuilder{Asset: client.Asset}.NewNativeTransfer(testFrom, testTo, xc.NewAmountBlockchainFromUint64(100_000_000), testInput(testUTXO(0, 200_000_000)))
+	s.sign(tx)
+	require.NoError(client.SubmitTx(s.Ctx, tx))
+	require.Equal(1, server.Counter)
+
+	server.Response = errors.New("failed to get utxo")
+	err := client.SubmitTx(s.Ctx, tx)
+	require.Error(err)
+	require.Equal(xc.TransactionExists, CheckError(err))
+
+	require.Error(client.SubmitTx(s.Ctx, &Tx{}))
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfo() {
+	require := s.Require()
+	pAddress := xc.Address("P-" + testFrom[2:])
+	tx, _ := TxBuilder{Asset: testAsset(xc.XAVAX, "")}.NewExport(testFrom, pAddress, xc.NewAmountBlockchainFromUint64(100_000_000), xc.PAVAX, testInput(testUTXO(0, 200_000_000)))
+	s.sign(tx)
+	serialized, _ := tx.Serialize()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		fmt.Sprintf(`{"tx":"%s","encoding":"hex"}`, encodeHex(serialized)),
+		`{"status":"Accepted"}`,
+	})
+	defer close()
+
+	client, _ := NewClient(testAsset(xc.XAVAX, server.URL))
+	info, err := client.FetchTxInfo(s.Ctx, tx.Hash())
+	require.NoError(err)
+	require.Equal(string(tx.Hash()), info.TxID)
+	require.EqualValues(1, info.Confirmations)
+	require.Equal(xc.TxStatusSuccess, info.Status)
+	require.Equal(testFrom, info.From)
+	require.Equal(pAddress, info.To)
+	require.Equal("100000000", info.Amount.String())
+	require.Equal("1000000", info.Fee.String())
+	require.Len(info.Sources, 1)
+	require.Len(info.Destinations, 1)
+	require.Len(info.Change, 1)
+	require.Equal("99000000", info.Change[0].Amount.String())
+
+	server.Counter = 0
+	server.Response = []string{
+		fmt.Sprintf(`{"tx":"%s","encoding":"hex"}`, encodeHex(serialized)),
+		`{"status":"Rejected","reason":"failed to get utxo"}`,
+	}
+	info, err = client.FetchTxInfo(s.Ctx, tx.Hash())
+	require.NoError(err)
+	require.Equal(xc.TxStatusFailure, info.Status)
+	require.Equal("Rejected failed to get utxo", info.Error)
+	require.EqualValues(0, info.Confirmations)
+
+	server.Counter = 0
+	server.Response = []string{`{"tx":"0x0000","encoding":"hex"}`}
+	_, err = client.FetchTxInfo(s.Ctx, tx.Hash())
+	require.ErrorContains(err, "invalid tx")
+}
+
+func (s *CrosschainTestSuite) TestEstimateGas() {
+	require := s.Require()
+	client, _ := NewClient(testAsset(xc.XAVAX, "http://localhost"))
+	fee, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("1000000", fee.String())
+
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(2_000_000), nil
+	})
+	fee, err = client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("2000000", fee.String())
+}
+
+func (s *CrosschainTestSuite) TestHex() {
+	require := s.Require()
+	encoded := encodeHex([]byte{1, 2, 3})
+	decoded, err := decodeHex(encoded)
+	require.NoError(err)
+	require.Equal([]byte{1, 2, 3}, decoded)
+
+	_, err = decodeHex("0x010203")
+	require.Error(err)
+	_, err = decodeHex("0x0102030405")
+	require.Error(err)
+}
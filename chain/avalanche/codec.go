@@ -0,0 +1,205 @@
+package avalanche
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// The linear codec of avalanchego, used by the X-Chain (AVM) and the P-Chain (platformvm): big endian integers,
+// slices prefixed with their length, and interfaces prefixed with the type id of their implementation
+
+// codecVersion prefixes txs and UTXOs
+const codecVersion = 0
+
+// Type ids of the secp256k1fx types, shared by the codecs of the X-Chain and the P-Chain
+const (
+	typeTransferInput  = 5
+	typeTransferOutput = 7
+	typeCredential     = 9
+	typeOutputOwners   = 11
+)
+
+// Type ids of the txs of the X-Chain
+const (
+	typeXBaseTx   = 0
+	typeXImportTx = 3
+	typeXExportTx = 4
+)
+
+// Type ids of the txs and outputs of the P-Chain
+const (
+	typePImportTx                     = 17
+	typePExportTx                     = 18
+	typePStakeableLockOut             = 22
+	typePAddPermissionlessDelegatorTx = 26
+	typePBaseTx                       = 34
+)
+
+var errTruncated = errors.New("truncated data")
+
+type packer struct {
+	bytes.Buffer
+}
+
+func (p *packer) u16(v uint16) {
+	_ = binary.Write(p, binary.BigEndian, v)
+}
+
+func (p *packer) u32(v uint32) {
+	_ = binary.Write(p, binary.BigEndian, v)
+}
+
+func (p *packer) u64(v uint64) {
+	_ = binary.Write(p, binary.BigEndian, v)
+}
+
+// fixed writes fixed length bytes, e.g. ids
+func (p *packer) fixed(v []byte) {
+	p.Write(v)
+}
+
+// bytes writes bytes prefixed with their length
+func (p *packer) bytes(v []byte) {
+	p.u32(uint32(len(v)))
+	p.Write(v)
+}
+
+type unpacker struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (u *unpacker) next(n int) []byte {
+	if u.err != nil || n < 0 || u.offset+n > len(u.data) {
+		u.err = errTruncated
+		return make([]byte, n)
+	}
+	res := u.data[u.offset : u.offset+n]
+	u.offset += n
+	return res
+}
+
+func (u *unpacker) u16() uint16 {
+	return binary.BigEndian.Uint16(u.next(2))
+}
+
+func (u *unpacker) u32() uint32 {
+	return binary.BigEndian.Uint32(u.next(4))
+}
+
+func (u *unpacker) u64() uint64 {
+	return binary.BigEndian.Uint64(u.next(8))
+}
+
+func (u *unpacker) fixed(n int) []byte {
+	return append([]byte{}, u.next(n)...)
+}
+
+func (u *unpacker) bytes() []byte {
+	length := u.u32()
+	if int(length) > len(u.data) {
+		u.err = errTruncated
+		return nil
+	}
+	return u.fixed(int(length))
+}
+
+// Output is a secp256k1fx.TransferOutput: an amount of an asset owned by threshold of addresses, locked until locktime
+type Output struct {
+	AssetID   []byte
+	Amount    uint64
+	Locktime  uint64
+	Threshold uint32
+	// Addresses are the 20-byte short ids of the owners, sorted
+	Addresses [][]byte
+}
+
+func (output Output) pack(p *packer) {
+	p.fixed(output.AssetID)
+	p.u32(typeTransferOutput)
+	p.u64(output.Amount)
+	p.u64(output.Locktime)
+	p.u32(output.Threshold)
+	p.u32(uint32(len(output.Addresses)))
+	for _, address := range output.Addresses {
+		p.fixed(address)
+	}
+}
+
+func (output Output) bytes() []byte {
+	p := &packer{}
+	output.pack(p)
+	return p.Bytes()
+}
+
+// unpackTransferOutput unpacks the fields of a secp256k1fx.TransferOutput, after its type id
+func unpackTransferOutput(u *unpacker, assetID []byte) Output {
+	output := Output{AssetID: assetID}
+	output.Amount = u.u64()
+	output.Locktime = u.u64()
+	output.Threshold = u.u32()
+	count := u.u32()
+	for i := uint32(0); i < count && u.err == nil; i++ {
+		output.Addresses = append(output.Addresses, u.fixed(20))
+	}
+	return output
+}
+
+// Input spends a UTXO owned by secp256k1fx addresses, signed by the owners at SigIndices
+type Input struct {
+	TxID        []byte
+	OutputIndex uint32
+	AssetID     []byte
+	Amount      uint64
+	SigIndices  []uint32
+}
+
+func (input Input) pack(p *packer) {
+	p.fixed(input.TxID)
+	p.u32(input.OutputIndex)
+	p.fixed(input.AssetID)
+	p.u32(typeTransferInput)
+	p.u64(input.Amount)
+	p.u32(uint32(len(input.SigIndices)))
+	for _, index := range input.SigIndices {
+		p.u32(index)
+	}
+}
+
+func unpackInput(u *unpacker) (Input, error) {
+	input := Input{TxID: u.fixed(32), OutputIndex: u.u32(), AssetID: u.fixed(32)}
+	if typeID := u.u32(); u.err == nil && typeID != typeTransferInput {
+		return input, errors.New("unsupported input type")
+	}
+	input.Amount = u.u64()
+	count := u.u32()
+	for i := uint32(0); i < count && u.err == nil; i++ {
+		input.SigIndices = append(input.SigIndices, u.u32())
+	}
+	return input, u.err
+}
+
+// sortOutputs sorts outputs by their bytes and the addresses of each output, as required by avalanchego
+func sortOutputs(outputs []Output) {
+	for _, output := range outputs {
+		sort.Slice(output.Addresses, func(i, j int) bool {
+			return bytes.Compare(output.Addresses[i], output.Addresses[j]) < 0
+		})
+	}
+	sort.SliceStable(outputs, func(i, j int) bool {
+		return bytes.Compare(outputs[i].bytes(), outputs[j].bytes()) < 0
+	})
+}
+
+// sortInputs sorts inputs by the UTXOs they spend, as required by avalanchego
+func sortInputs(inputs []Input) {
+	sort.SliceStable(inputs, func(i, j int) bool {
+		if c := bytes.Compare(inputs[i].TxID, inputs[j].TxID); c != 0 {
+			return c < 0
+		}
+		return inputs[i].OutputIndex < inputs[j].OutputIndex
+	})
+}
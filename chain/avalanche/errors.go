@@ -0,0 +1,35 @@
+package avalanche
+
+import (
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// CheckError classifies the errors of the APIs of the X-Chain and the P-Chain
+func CheckError(err error) xc.ClientError {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "insufficient funds") ||
+		strings.Contains(msg, "insufficient unlocked funds") {
+		return xc.NoBalance
+	}
+	if strings.Contains(msg, "no funds to import") {
+		return xc.NoBalanceForGas
+	}
+	if strings.Contains(msg, "already issued") ||
+		strings.Contains(msg, "duplicated") ||
+		strings.Contains(msg, "missing utxo") ||
+		strings.Contains(msg, "failed to get utxo") {
+		return xc.TransactionExists
+	}
+	if strings.Contains(msg, "invalid signature") ||
+		strings.Contains(msg, "failed verification") ||
+		strings.Contains(msg, "flow check failed") {
+		return xc.TransactionFailure
+	}
+	if strings.Contains(msg, "response body closed") ||
+		strings.Contains(msg, "eof") {
+		return xc.NetworkError
+	}
+	return xc.UnknownError
+}
@@ -0,0 +1,62 @@
+package avalanche
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// privateKeyPrefix prefixes the cb58 private keys exported by Avalanche wallets
+const privateKeyPrefix = "PrivateKey-"
+
+// Signer for Avalanche
+type Signer struct {
+}
+
+var _ xc.Signer = &Signer{}
+var _ xc.PublicKeyDeriver = &Signer{}
+
+// NewSigner creates a new Avalanche Signer
+func NewSigner(asset xc.ITask) (xc.Signer, error) {
+	return Signer{}, nil
+}
+
+// ImportPrivateKey imports an Avalanche private key: cb58 prefixed with PrivateKey-, or hex
+func (signer Signer) ImportPrivateKey(privateKey string) (xc.PrivateKey, error) {
+	var bytesPri []byte
+	var err error
+	if strings.HasPrefix(privateKey, privateKeyPrefix) {
+		bytesPri, err = DecodeCB58(strings.TrimPrefix(privateKey, privateKeyPrefix))
+	} else {
+		bytesPri, err = hex.DecodeString(strings.TrimPrefix(privateKey, "0x"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := crypto.ToECDSA(bytesPri); err != nil {
+		return nil, errors.New("invalid k256 private key")
+	}
+	return xc.PrivateKey(bytesPri), nil
+}
+
+// Sign the sha256 of an Avalanche tx, returning R || S || V with V 0 or 1
+func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	ecdsaKey, err := crypto.ToECDSA(privateKey)
+	if err != nil {
+		return nil, errors.New("invalid k256 private key")
+	}
+	signature, err := crypto.Sign([]byte(data), ecdsaKey)
+	return xc.TxSignature(signature), err
+}
+
+// DerivePublicKey returns the compressed public key of an Avalanche private key
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	ecdsaKey, err := crypto.ToECDSA(privateKey)
+	if err != nil {
+		return nil, errors.New("invalid k256 private key")
+	}
+	return xc.PublicKey(crypto.CompressPubkey(&ecdsaKey.PublicKey)), nil
+}
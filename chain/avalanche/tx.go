@@ -0,0 +1,345 @@
+package avalanche
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// UTXO is an unspent output, spendable by the inputs of txs
+type UTXO struct {
+	TxID        []byte
+	OutputIndex uint32
+	Output
+}
+
+// TxInput for Avalanche
+type TxInput struct {
+	xc.TxInputEnvelope
+	// UTXOs are the outputs of the sender, or for imports its outputs exported from the source chain
+	UTXOs []UTXO
+	// Fee of the tx, in nAVAX
+	Fee uint64
+	// DelegationStart and DelegationEnd are the period of delegations, in unix seconds
+	DelegationStart uint64
+	DelegationEnd   uint64
+}
+
+// NewTxInput returns a new Avalanche TxInput
+func NewTxInput() *TxInput {
+	return &TxInput{
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverAvalanche),
+	}
+}
+
+// Delegation is the stake of an AddPermissionlessDelegatorTx of the P-Chain
+type Delegation struct {
+	NodeID []byte
+	// Start and End of the delegation, in unix seconds
+	Start  uint64
+	End    uint64
+	Weight uint64
+	// SubnetID is the subnet of the validator, the primary network if empty
+	SubnetID []byte
+	// Stake are the outputs locked until End, returned after
+	Stake []Output
+	// RewardsOwner is the owner of the rewards: locktime, threshold and addresses
+	RewardsOwner Output
+}
+
+// Tx for Avalanche: a BaseTx, ImportTx, ExportTx or AddPermissionlessDelegatorTx of the X-Chain or the P-Chain
+type Tx struct {
+	// TypeID of the tx in the codec of its chain
+	TypeID       uint32
+	NetworkID    uint32
+	BlockchainID []byte
+	Outputs      []Output
+	Inputs       []Input
+	Memo         []byte
+	// Chain is the source chain of imports, or the destination chain of exports
+	Chain []byte
+	// ImportedInputs and ExportedOutputs are the atomic inputs and outputs of imports and exports
+	ImportedInputs  []Input
+	ExportedOutputs []Output
+	Delegation      *Delegation
+	// Credentials are the signatures of the inputs, then of the imported inputs
+	Credentials [][][]byte
+}
+
+var _ xc.Tx = &Tx{}
+
+// Hash returns the id of the tx: the cb58 of the sha256 of the signed tx, empty until the tx is signed
+func (tx Tx) Hash() xc.TxHash {
+	if len(tx.Credentials) == 0 {
+		return xc.TxHash("")
+	}
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return xc.TxHash("")
+	}
+	id := sha256.Sum256(serialized)
+	return xc.TxHash(EncodeCB58(id[:]))
+}
+
+// Sighashes returns the sha256 of the unsigned tx, signed once for all the inputs of the sender
+func (tx Tx) Sighashes() ([]xc.TxDataToSign, error) {
+	if len(tx.BlockchainID) == 0 {
+		return []xc.TxDataToSign{}, errors.New("transaction not initialized")
+	}
+	hash := sha256.Sum256(tx.unsignedBytes())
+	return []xc.TxDataToSign{hash[:]}, nil
+}
+
+// AddSignatures adds the signature of the sender, R || S || V with V 0 or 1, as the credential of every input
+func (tx *Tx) AddSignatures(signatures ...xc.TxSignature) error {
+	if len(signatures) != 1 {
+		return errors.New("expecting 1 signature")
+	}
+	if len(signatures[0]) != crypto.SignatureLength {
+		return fmt.Errorf("invalid signature length %d", len(signatures[0]))
+	}
+	signature := append([]byte{}, signatures[0]...)
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	tx.Credentials = make([][][]byte, len(tx.Inputs)+len(tx.ImportedInputs))
+	for i := range tx.Credentials {
+		tx.Credentials[i] = [][]byte{signature}
+	}
+	return nil
+}
+
+// Serialize returns the signed tx
+func (tx Tx) Serialize() ([]byte, error) {
+	if len(tx.Credentials) == 0 {
+		return []byte{}, errors.New("unable to serialize without first calling AddSignatures(...)")
+	}
+	p := &packer{}
+	p.fixed(tx.unsignedBytes())
+	p.u32(uint32(len(tx.Credentials)))
+	for _, credential := range tx.Credentials {
+		p.u32(typeCredential)
+		p.u32(uint32(len(credential)))
+		for _, signature := range credential {
+			p.fixed(signature)
+		}
+	}
+	return p.Bytes(), nil
+}
+
+// unsignedBytes returns the unsigned tx, prefixed with the codec version
+func (tx Tx) unsignedBytes() []byte {
+	p := &packer{}
+	p.u16(codecVersion)
+	p.u32(tx.TypeID)
+	p.u32(tx.NetworkID)
+	p.fixed(tx.BlockchainID)
+	packOutputs(p, tx.Outputs)
+	packInputs(p, tx.Inputs)
+	p.bytes(tx.Memo)
+	switch tx.TypeID {
+	case typeXImportTx, typePImportTx:
+		p.fixed(tx.Chain)
+		packInputs(p, tx.ImportedInputs)
+	case typeXExportTx, typePExportTx:
+		p.fixed(tx.Chain)
+		packOutputs(p, tx.ExportedOutputs)
+	case typePAddPermissionlessDelegatorTx:
+		delegation := tx.Delegation
+		p.fixed(delegation.NodeID)
+		p.u64(delegation.Start)
+		p.u64(delegation.End)
+		p.u64(delegation.Weight)
+		subnetID := delegation.SubnetID
+		if len(subnetID) == 0 {
+			subnetID = make([]byte, 32)
+		}
+		p.fixed(subnetID)
+		packOutputs(p, delegation.Stake)
+		p.u32(typeOutputOwners)
+		p.u64(delegation.RewardsOwner.Locktime)
+		p.u32(delegation.RewardsOwner.Threshold)
+		p.u32(uint32(len(delegation.RewardsOwner.Addresses)))
+		for _, address := range delegation.RewardsOwner.Addresses {
+			p.fixed(address)
+		}
+	}
+	return p.Bytes()
+}
+
+func packOutputs(p *packer, outputs []Output) {
+	p.u32(uint32(len(outputs)))
+	for _, output := range outputs {
+		output.pack(p)
+	}
+}
+
+func packInputs(p *packer, inputs []Input) {
+	p.u32(uint32(len(inputs)))
+	for _, input := range inputs {
+		input.pack(p)
+	}
+}
+
+// ParseTx parses a signed tx of the X-Chain or the P-Chain, of one of the types of Tx
+func ParseTx(data []byte) (*Tx, error) {
+	u := &unpacker{data: data}
+	if version := u.u16(); u.err == nil && version != codecVersion {
+		return nil, fmt.Errorf("unsupported codec version %d", version)
+	}
+	tx := &Tx{TypeID: u.u32()}
+	switch tx.TypeID {
+	case typeXBaseTx, typeXImportTx, typeXExportTx, typePImportTx, typePExportTx, typePAddPermissionlessDelegatorTx, typePBaseTx:
+	default:
+		return nil, fmt.Errorf("unsupported tx type %d", tx.TypeID)
+	}
+	tx.NetworkID = u.u32()
+	tx.BlockchainID = u.fixed(32)
+	var err error
+	if tx.Outputs, err = unpackOutputs(u); err != nil {
+		return nil, err
+	}
+	if tx.Inputs, err = unpackInputs(u); err != nil {
+		return nil, err
+	}
+	tx.Memo = u.bytes()
+	switch tx.TypeID {
+	case typeXImportTx, typePImportTx:
+		tx.Chain = u.fixed(32)
+		if tx.ImportedInputs, err = unpackInputs(u); err != nil {
+			return nil, err
+		}
+	case typeXExportTx, typePExportTx:
+		tx.Chain = u.fixed(32)
+		if tx.ExportedOutputs, err = unpackOutputs(u); err != nil {
+			return nil, err
+		}
+	case typePAddPermissionlessDelegatorTx:
+		delegation := &Delegation{NodeID: u.fixed(20), Start: u.u64(), End: u.u64(), Weight: u.u64(), SubnetID: u.fixed(32)}
+		if delegation.Stake, err = unpackOutputs(u); err != nil {
+			return nil, err
+		}
+		if typeID := u.u32(); u.err == nil && typeID != typeOutputOwners {
+			return nil, fmt.Errorf("unsupported rewards owner type %d", typeID)
+		}
+		delegation.RewardsOwner = unpackTransferOutputOwners(u)
+		tx.Delegation = delegation
+	}
+	count := u.u32()
+	for i := uint32(0); i < count && u.err == nil; i++ {
+		if typeID := u.u32(); u.err == nil && typeID != typeCredential {
+			return nil, fmt.Errorf("unsupported credential type %d", typeID)
+		}
+		signatures := [][]byte{}
+		sigCount := u.u32()
+		for j := uint32(0); j < sigCount && u.err == nil; j++ {
+			signatures = append(signatures, u.fixed(crypto.SignatureLength))
+		}
+		tx.Credentials = append(tx.Credentials, signatures)
+	}
+	if u.err != nil {
+		return nil, u.err
+	}
+	if u.offset != len(data) {
+		return nil, errors.New("unexpected trailing data")
+	}
+	return tx, nil
+}
+
+// Signer returns the short id of the signer of the first credential, recovered from its signature
+func (tx Tx) Signer() ([]byte, error) {
+	if len(tx.Credentials) == 0 || len(tx.Credentials[0]) == 0 {
+		return nil, errors.New("unsigned tx")
+	}
+	hash := sha256.Sum256(tx.unsignedBytes())
+	publicKey, err := crypto.SigToPub(hash[:], tx.Credentials[0][0])
+	if err != nil {
+		return nil, err
+	}
+	return ShortID(crypto.CompressPubkey(publicKey))
+}
+
+func unpackOutputs(u *unpacker) ([]Output, error) {
+	outputs := []Output{}
+	count := u.u32()
+	for i := uint32(0); i < count && u.err == nil; i++ {
+		assetID := u.fixed(32)
+		output, err := unpackOutput(u, assetID)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, u.err
+}
+
+// unpackOutput unpacks an output after its asset id: a secp256k1fx.TransferOutput, or one locked until a time by
+// a stakeable.LockOut of the P-Chain
+func unpackOutput(u *unpacker, assetID []byte) (Output, error) {
+	typeID := u.u32()
+	var locktime uint64
+	if typeID == typePStakeableLockOut {
+		locktime = u.u64()
+		typeID = u.u32()
+	}
+	if u.err != nil {
+		return Output{}, u.err
+	}
+	if typeID != typeTransferOutput {
+		return Output{}, fmt.Errorf("unsupported output type %d", typeID)
+	}
+	output := unpackTransferOutput(u, assetID)
+	if locktime > output.Locktime {
+		output.Locktime = locktime
+	}
+	return output, u.err
+}
+
+func unpackTransferOutputOwners(u *unpacker) Output {
+	owners := Output{Locktime: u.u64(), Threshold: u.u32()}
+	count := u.u32()
+	for i := uint32(0); i < count && u.err == nil; i++ {
+		owners.Addresses = append(owners.Addresses, u.fixed(20))
+	}
+	return owners
+}
+
+func unpackInputs(u *unpacker) ([]Input, error) {
+	inputs := []Input{}
+	count := u.u32()
+	for i := uint32(0); i < count && u.err == nil; i++ {
+		input, err := unpackInput(u)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, u.err
+}
+
+// ParseUTXO parses a UTXO, as returned by the getUTXOs API of avalanchego
+func ParseUTXO(data []byte) (UTXO, error) {
+	u := &unpacker{data: data}
+	if version := u.u16(); u.err == nil && version != codecVersion {
+		return UTXO{}, fmt.Errorf("unsupported codec version %d", version)
+	}
+	utxo := UTXO{TxID: u.fixed(32), OutputIndex: u.u32()}
+	output, err := unpackOutput(u, u.fixed(32))
+	if err != nil {
+		return UTXO{}, err
+	}
+	utxo.Output = output
+	return utxo, nil
+}
+
+func (utxo UTXO) bytes() []byte {
+	p := &packer{}
+	p.u16(codecVersion)
+	p.fixed(utxo.TxID)
+	p.u32(utxo.OutputIndex)
+	utxo.Output.pack(p)
+	return p.Bytes()
+}
@@ -0,0 +1,233 @@
+package avalanche
+
+import (
+	"encoding/hex"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+const testFrom = xc.Address("X-fuji18jma8ppw3nhx5r4ap8clazz0dps7rv5u6wmu4t")
+const testTo = xc.Address("X-fuji1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq2nffjl")
+
+func testUTXO(index uint32, amount uint64) UTXO {
+	assetID, _ := DecodeID(xc.KnownAvalancheNetworks[xc.Fuji].AssetID)
+	owner, _ := hex.DecodeString(testShortID)
+	txID := make([]byte, 32)
+	txID[0] = byte(index)
+	return UTXO{TxID: txID, OutputIndex: index, Output: newOutput(assetID, amount, owner)}
+}
+
+func testInput(utxos ...UTXO) *TxInput {
+	input := NewTxInput()
+	input.UTXOs = utxos
+	input.Fee = 1_000_000
+	input.DelegationStart = 1700000000
+	input.DelegationEnd = 1700086400
+	return input
+}
+
+func (s *CrosschainTestSuite) sign(tx xc.Tx) {
+	require := s.Require()
+	signer, _ := NewSigner(testAsset(xc.XAVAX, ""))
+	privateKey, err := signer.ImportPrivateKey(testPrivateKey)
+	require.NoError(err)
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes, 1)
+	signature, err := signer.Sign(privateKey, sighashes[0])
+	require.NoError(err)
+	require.NoError(tx.AddSignatures(signature))
+}
+
+// roundTrip checks the parsed tx serializes as tx, signed by the ewoq key
+func (s *CrosschainTestSuite) roundTrip(tx xc.Tx) *Tx {
+	require := s.Require()
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	parsed, err := ParseTx(serialized)
+	require.NoError(err)
+	reserialized, err := parsed.Serialize()
+	require.NoError(err)
+	require.Equal(serialized, reserialized)
+	require.Equal(tx.Hash(), parsed.Hash())
+
+	signer, err := parsed.Signer()
+	require.NoError(err)
+	require.Equal(testShortID, hex.EncodeToString(signer))
+	return parsed
+}
+
+func (s *CrosschainTestSuite) TestNewNativeTransfer() {
+	require := s.Require()
+	builder := TxBuilder{Asset: testAsset(xc.XAVAX, "")}
+	input := testInput(testUTXO(1, 300_000_000), testUTXO(0, 200_000_000), testUTXO(2, 100_000_000))
+	tx, err := builder.NewTransfer(testFrom, testTo, xc.NewAmountBlockchainFromUint64(400_000_000), input)
+	require.NoError(err)
+	avaxTx := tx.(*Tx)
+	require.EqualValues(typeXBaseTx, avaxTx.TypeID)
+	require.EqualValues(5, avaxTx.NetworkID)
+	require.Equal(xc.TxHash(""), tx.Hash())
+
+	// the first utxos are spent, sorted by tx id
+	require.Len(avaxTx.Inputs, 2)
+	require.EqualValues(0, avaxTx.Inputs[0].OutputIndex)
+	require.EqualValues(1, avaxTx.Inputs[1].OutputIndex)
+	require.Equal([]uint32{0}, avaxTx.Inputs[0].SigIndices)
+
+	// outputs are sorted by their bytes: the change, then the recipient
+	require.Len(avaxTx.Outputs, 2)
+	require.EqualValues(99_000_000, avaxTx.Outputs[0].Amount)
+	require.Equal(testShortID, hex.EncodeToString(avaxTx.Outputs[0].Addresses[0]))
+	require.EqualValues(400_000_000, avaxTx.Outputs[1].Amount)
+	require.Equal(make([]byte, 20), avaxTx.Outputs[1].Addresses[0])
+
+	s.sign(tx)
+	require.Len(avaxTx.Credentials, 2)
+	require.NotEqual(xc.TxHash(""), tx.Hash())
+	parsed := s.roundTrip(tx)
+	require.Equal(avaxTx.Outputs, parsed.Outputs)
+	require.Equal(avaxTx.Inputs, parsed.Inputs)
+
+	// P-Chain
+	builder = TxBuilder{Asset: testAsset(xc.PAVAX, "")}
+	tx, err = builder.NewNativeTransfer("P-"+testFrom[2:], "P-"+testTo[2:], xc.NewAmountBlockchainFromUint64(400_000_000), input)
+	require.NoError(err)
+	require.EqualValues(typePBaseTx, tx.(*Tx).TypeID)
+	s.sign(tx)
+	s.roundTrip(tx)
+}
+
+func (s *CrosschainTestSuite) TestNewTransferErrors() {
+	require := s.Require()
+	builder := TxBuilder{Asset: testAsset(xc.XAVAX, "")}
+	input := testInput(testUTXO(0, 200_000_000))
+	amount := xc.NewAmountBlockchainFromUint64(100_000_000)
+
+	_, err := builder.NewNativeTransfer(testFrom, testTo, xc.NewAmountBlockchainFromUint64(200_000_000), input)
+	require.EqualError(err, "insufficient funds: 200000000 nAVAX spendable, 201000000 nAVAX needed")
+
+	locked := testUTXO(1, 500_000_000)
+	locked.Locktime = 1800000000
+	_, err = builder.NewNativeTransfer(testFrom, testTo, xc.NewAmountBlockchainFromUint64(400_000_000), testInput(locked))
+	require.EqualError(err, "insufficient funds: 0 nAVAX spendable, 401000000 nAVAX needed")
+
+	_, err = builder.NewNativeTransfer(testFrom, "0x00", amount, input)
+	require.ErrorContains(err, "invalid to address '0x00'")
+
+	_, err = builder.NewNativeTransfer(testFrom, testTo, xc.NewAmountBlockchainFromUint64(0), input)
+	require.EqualError(err, "invalid amount 0")
+
+	_, err = builder.NewNativeTransfer(testFrom, testTo, amount, nil)
+	require.EqualError(err, "xc.TxInput is not from an avalanche chain")
+
+	_, err = builder.NewTransfer(testFrom, testTo, amount, &xc.TokenAssetConfig{NativeAssetConfig: testAsset(xc.XAVAX, "")})
+	require.Error(err)
+	tokenBuilder, _ := NewTxBuilder(&xc.TokenAssetConfig{NativeAssetConfig: testAsset(xc.XAVAX, "")})
+	_, err = tokenBuilder.NewTransfer(testFrom, testTo, amount, input)
+	require.EqualError(err, "token transfers are not supported on avalanche chains")
+
+	builder = TxBuilder{Asset: testAsset(xc.AVAX, "")}
+	_, err = builder.NewNativeTransfer(testFrom, testTo, amount, input)
+	require.EqualError(err, "unsupported avalanche chain: AVAX")
+}
+
+func (s *CrosschainTestSuite) TestNewExportImport() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testAsset(xc.XAVAX, ""))
+	atomicBuilder := builder.(xc.TxAtomicBuilder)
+	pAddress := xc.Address("P-" + testFrom[2:])
+	amount := xc.NewAmountBlockchainFromUint64(100_000_000)
+
+	tx, err := atomicBuilder.NewExport(testFrom, pAddress, amount, xc.PAVAX, testInput(testUTXO(0, 200_000_000)))
+	require.NoError(err)
+	export := tx.(*Tx)
+	require.EqualValues(typeXExportTx, export.TypeID)
+	require.Equal(make([]byte, 32), export.Chain)
+	require.Len(export.ExportedOutputs, 1)
+	require.EqualValues(100_000_000, export.ExportedOutputs[0].Amount)
+	require.Len(export.Outputs, 1)
+	require.EqualValues(99_000_000, export.Outputs[0].Amount)
+	s.sign(tx)
+	parsed := s.roundTrip(tx)
+	require.Equal(export.ExportedOutputs, parsed.ExportedOutputs)
+
+	_, err = atomicBuilder.NewExport(testFrom, pAddress, amount, xc.XAVAX, testInput(testUTXO(0, 200_000_000)))
+	require.EqualError(err, "invalid chain of atomic tx: XAVAX")
+	_, err = atomicBuilder.NewExport(testFrom, pAddress, amount, xc.AVAX, testInput(testUTXO(0, 200_000_000)))
+	require.EqualError(err, "atomic txs of the C-Chain aren't supported")
+
+	// the exported utxo, imported on the P-Chain
+	builder, _ = NewTxBuilder(testAsset(xc.PAVAX, ""))
+	atomicBuilder = builder.(xc.TxAtomicBuilder)
+	tx, err = atomicBuilder.NewImport(pAddress, xc.XAVAX, testInput(testUTXO(0, 100_000_000)))
+	require.NoError(err)
+	imported := tx.(*Tx)
+	require.EqualValues(typePImportTx, imported.TypeID)
+	require.Empty(imported.Inputs)
+	require.Len(imported.ImportedInputs, 1)
+	require.Len(imported.Outputs, 1)
+	require.EqualValues(99_000_000, imported.Outputs[0].Amount)
+	s.sign(tx)
+	require.Len(imported.Credentials, 1)
+	parsed = s.roundTrip(tx)
+	require.Equal(imported.ImportedInputs, parsed.ImportedInputs)
+
+	_, err = atomicBuilder.NewImport(pAddress, xc.XAVAX, testInput(testUTXO(0, 1_000_000)))
+	require.EqualError(err, "no funds to import from XAVAX: 1000000 nAVAX, for a fee of 1000000 nAVAX")
+	require.Equal(xc.NoBalanceForGas, CheckError(err))
+}
+
+func (s *CrosschainTestSuite) TestNewDelegate() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testAsset(xc.PAVAX, ""))
+	stakingBuilder := builder.(xc.TxStakingBuilder)
+	pAddress := xc.Address("P-" + testFrom[2:])
+	validator := xc.Address("NodeID-" + EncodeCB58(make([]byte, 20)))
+	amount := xc.NewAmountBlockchainFromUint64(1_000_000_000)
+
+	tx, err := stakingBuilder.NewDelegate(pAddress, validator, amount, testInput(testUTXO(0, 2_000_000_000)))
+	require.NoError(err)
+	delegation := tx.(*Tx)
+	require.EqualValues(typePAddPermissionlessDelegatorTx, delegation.TypeID)
+	require.EqualValues(1700000000, delegation.Delegation.Start)
+	require.EqualValues(1700086400, delegation.Delegation.End)
+	require.EqualValues(1_000_000_000, delegation.Delegation.Weight)
+	require.Len(delegation.Delegation.Stake, 1)
+	require.EqualValues(999_000_000, delegation.Outputs[0].Amount)
+	s.sign(tx)
+	parsed := s.roundTrip(tx)
+	require.Equal(delegation.Delegation.Stake, parsed.Delegation.Stake)
+	require.Equal(delegation.Delegation.NodeID, parsed.Delegation.NodeID)
+
+	_, err = stakingBuilder.NewDelegate(pAddress, "NodeID-invalid", amount, testInput(testUTXO(0, 2_000_000_000)))
+	require.Error(err)
+	_, err = stakingBuilder.NewUndelegate(pAddress, validator, amount, testInput())
+	require.Error(err)
+	_, err = stakingBuilder.NewWithdrawRewards(pAddress, validator, testInput())
+	require.Error(err)
+
+	builder, _ = NewTxBuilder(testAsset(xc.XAVAX, ""))
+	_, err = builder.(xc.TxStakingBuilder).NewDelegate(testFrom, validator, amount, testInput(testUTXO(0, 2_000_000_000)))
+	require.EqualError(err, "delegations are only supported on the P-Chain")
+}
+
+func (s *CrosschainTestSuite) TestAddSignatures() {
+	require := s.Require()
+	tx := &Tx{}
+	_, err := tx.Sighashes()
+	require.EqualError(err, "transaction not initialized")
+	_, err = tx.Serialize()
+	require.Error(err)
+
+	tx = &Tx{Inputs: []Input{{}, {}}}
+	require.EqualError(tx.AddSignatures(), "expecting 1 signature")
+	require.EqualError(tx.AddSignatures(make([]byte, 64)), "invalid signature length 64")
+
+	signature := make([]byte, 65)
+	signature[64] = 28
+	require.NoError(tx.AddSignatures(signature))
+	require.Len(tx.Credentials, 2)
+	require.EqualValues(1, tx.Credentials[1][0][64])
+	// the signature isn't modified
+	require.EqualValues(28, signature[64])
+}
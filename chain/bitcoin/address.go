@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcutil"
@@ -19,9 +20,12 @@ type AddressBuilder struct {
 	asset         xc.ITask
 	UseLegacy     bool
 	UseScriptHash bool
+	// cashAddr is set for chains with cashaddr addresses, e.g. Bitcoin Cash
+	cashAddr bool
 }
 
 var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
 
 var (
 	// Alphabet used by Bitcoin Cash to encode addresses.
@@ -47,12 +51,13 @@ func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
 		UseLegacy:     true,
 		UseScriptHash: false,
 		params:        params,
+		cashAddr:      asset.GetNativeAsset().GetUTXO().CashAddrPrefix != "",
 	}, nil
 }
 
 // GetAddressFromPublicKey returns an Address given a public key
 func (ab AddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (xc.Address, error) {
-	if ab.asset.GetNativeAsset().NativeAsset == xc.BCH {
+	if ab.cashAddr {
 		addressPubKey, err := NewBchAddressPubKey(publicKeyBytes, ab.params)
 		if err != nil {
 			return "", err
@@ -113,6 +118,10 @@ func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []b
 		Type:    xc.AddressTypeP2PKH,
 	})
 
+	// chains without segwit, e.g. Dash, have no bech32 HRP
+	if ab.params.Bech32HRPSegwit == "" {
+		return possibles, nil
+	}
 	witnessProg := btcutil.Hash160(publicKeyBytes)
 	addressWitness, err := btcutil.NewAddressWitnessPubKeyHash(witnessProg, ab.params)
 	if err != nil {
@@ -128,6 +137,25 @@ func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []b
 	return possibles, nil
 }
 
+// ValidateAddress checks the checksum of a base58 or bech32 address, and its version or prefix is of the network of the chain
+// Addresses of chains with cashaddr addresses, e.g. Bitcoin Cash, are checked in the cashaddr format, or the legacy format
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	if ab.cashAddr {
+		if _, err := DecodeBchAddress(string(address), ab.params); err != nil {
+			return fmt.Errorf("invalid address '%s': %v", address, err)
+		}
+		return nil
+	}
+	decoded, err := btcutil.DecodeAddress(string(address), ab.params)
+	if err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	if !decoded.IsForNet(ab.params) {
+		return fmt.Errorf("invalid address '%s': not an address of %s %s", address, ab.asset.GetNativeAsset().NativeAsset, ab.params.Name)
+	}
+	return nil
+}
+
 func BchAddressFromBytes(addrBytes []byte, params *chaincfg.Params) (btcutil.Address, error) {
 	switch len(addrBytes) - 1 {
 	case ripemd160.Size: // P2PKH or P2SH
@@ -252,21 +280,35 @@ func PolyMod(v []byte) uint64 {
 	return c ^ 1
 }
 
+// cashAddrPrefixes are the cashaddr prefixes of the params of the chains with cashaddr addresses, see GetParams
+var cashAddrPrefixes sync.Map
+
 // The bch prefix is different for each network type
 func AddressPrefix(params *chaincfg.Params) string {
 	if params == nil {
 		panic(fmt.Errorf("non-exhaustive pattern: params %v", params))
 	}
+	prefix, ok := cashAddrPrefix(params)
+	if !ok {
+		panic(fmt.Errorf("non-exhaustive pattern: params %v", params.Name))
+	}
+	return prefix
+}
+
+// cashAddrPrefix returns the cashaddr prefix of params, of a chain configured with one or of the networks of Bitcoin
+func cashAddrPrefix(params *chaincfg.Params) (string, bool) {
+	if prefix, ok := cashAddrPrefixes.Load(params); ok {
+		return prefix.(string), true
+	}
 	switch params {
 	case &chaincfg.MainNetParams:
-		return "bitcoincash"
+		return "bitcoincash", true
 	case &chaincfg.TestNet3Params:
-		return "bchtest"
+		return "bchtest", true
 	case &chaincfg.RegressionNetParams:
-		return "bchreg"
-	default:
-		panic(fmt.Errorf("non-exhaustive pattern: params %v", params.Name))
+		return "bchreg", true
 	}
+	return "", false
 }
 
 // https://github.com/bitcoincashorg/bitcoincash.org/blob/master/spec/cashaddr.md#checksum
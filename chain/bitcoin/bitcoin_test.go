@@ -1,15 +1,25 @@
 package bitcoin
 
 import (
+	"bytes"
+
 	"context"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
 	xc "github.com/jumpcrypto/crosschain"
 	"github.com/jumpcrypto/crosschain/test"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -18,6 +28,7 @@ var UXTO_ASSETS []xc.NativeAsset = []xc.NativeAsset{
 	xc.BCH,
 	xc.DOGE,
 	xc.LTC,
+	xc.DASH,
 }
 
 type CrosschainTestSuite struct {
@@ -74,6 +85,10 @@ func (s *CrosschainTestSuite) TestGetAddressFromPublicKey() {
 			address, err := builder.GetAddressFromPublicKey(pubkey)
 			require.NoError(err)
 			require.Equal(xc.Address("mhYWE7RrYCgbq4RJDaqZp8fvzVmYnPVnFD"), address)
+		case xc.DASH:
+			address, err := builder.GetAddressFromPublicKey(pubkey)
+			require.NoError(err)
+			require.Equal(xc.Address("yNLznG5D8S81YdTovkVosmZkF8EuNq9dJM"), address)
 		default:
 			panic("need to add address test case for " + nativeAsset)
 		}
@@ -111,6 +126,171 @@ func (s *CrosschainTestSuite) TestGetAllPossibleAddressesFromPublicKey() {
 	require.True(validated_p2wkh)
 }
 
+func (s *CrosschainTestSuite) TestGetAddressFromPublicKeyWithType() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{
+		Net:         "testnet",
+		NativeAsset: "BTC",
+	})
+	pubkey, _ := base64.RawStdEncoding.DecodeString("AptrsfXbXbvnsWxobWNFoUXHLO5nmgrQb3PDmGGu1CSS")
+
+	address, err := builder.(AddressBuilder).GetAddressFromPublicKeyWithType(pubkey, xc.AddressTypeP2PKH)
+	require.NoError(err)
+	require.Equal(xc.Address("mhYWE7RrYCgbq4RJDaqZp8fvzVmYnPVnFD"), address)
+	address, err = builder.(AddressBuilder).GetAddressFromPublicKeyWithType(pubkey, xc.AddressTypeP2WPKH)
+	require.NoError(err)
+	require.Equal(xc.Address("tb1qzca49vcyxkt989qcmhjfp7wyze7n9pq50k2cfd"), address)
+	address, err = builder.(AddressBuilder).GetAddressFromPublicKeyWithType(pubkey, xc.AddressTypeP2SH)
+	require.NoError(err)
+	require.Equal(xc.Address("2MtTvJ3YsaYjic7fNdvWC2EaeFu4uPbowt3"), address)
+	_, err = builder.(AddressBuilder).GetAddressFromPublicKeyWithType(pubkey, xc.AddressTypeETHKeccak)
+	require.ErrorContains(err, "unsupported address type")
+
+	require.Equal(xc.AddressTypeP2WPKH, ExtendedKeyAddressType(vpubVersion))
+	require.Equal(xc.AddressTypeP2SH, ExtendedKeyAddressType(ypubVersion))
+	require.Equal(xc.AddressTypeP2PKH, ExtendedKeyAddressType([]byte{0x01, 0x9d, 0xa4, 0x62}))
+}
+
+func (s *CrosschainTestSuite) TestNewTaprootAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{
+		Net:         "mainnet",
+		NativeAsset: "BTC",
+	})
+	// BIP-86 m/86'/0'/0'/0/0
+	internalKey, _ := hex.DecodeString("cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115")
+	outputKey, err := TaprootOutputKey(internalKey)
+	require.NoError(err)
+	require.Equal("a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c", hex.EncodeToString(outputKey))
+
+	expected := xc.Address("bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr")
+	address, err := builder.(AddressBuilder).GetAddressFromPublicKeyWithType(internalKey, xc.AddressTypeP2TR)
+	require.NoError(err)
+	require.Equal(expected, address)
+	// the parity of the compressed key is ignored
+	for _, prefix := range []byte{0x02, 0x03} {
+		address, err = builder.(AddressBuilder).GetAddressFromPublicKeyWithType(append([]byte{prefix}, internalKey...), xc.AddressTypeP2TR)
+		require.NoError(err)
+		require.Equal(expected, address)
+	}
+
+	_, err = TaprootOutputKey([]byte{1, 2, 3})
+	require.Error(err)
+	_, err = NewTaprootAddress(internalKey, &chaincfg.Params{})
+	require.ErrorContains(err, "segwit is not supported")
+}
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{Net: "mainnet", NativeAsset: "BTC"})
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"))
+	require.NoError(validator.ValidateAddress("3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy"))
+	require.NoError(validator.ValidateAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"))
+	// checksum
+	require.ErrorContains(validator.ValidateAddress("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb"), "invalid address '1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb'")
+	require.ErrorContains(validator.ValidateAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5"), "invalid address")
+	// network
+	require.Error(validator.ValidateAddress("tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"))
+	require.Error(validator.ValidateAddress("mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn"))
+	require.Error(validator.ValidateAddress(""))
+
+	builder, _ = NewAddressBuilder(&xc.AssetConfig{Net: "testnet", NativeAsset: "BTC"})
+	validator = builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"))
+	require.NoError(validator.ValidateAddress("mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn"))
+	require.Error(validator.ValidateAddress("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"))
+
+	builder, _ = NewAddressBuilder(&xc.AssetConfig{Net: "mainnet", NativeAsset: "DOGE"})
+	validator = builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("DH5yaieqoZN36fDVciNyRueRGvGLR3mr7L"))
+	require.Error(validator.ValidateAddress("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"))
+
+	builder, _ = NewAddressBuilder(&xc.AssetConfig{Net: "mainnet", NativeAsset: "BCH"})
+	validator = builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a"))
+	require.NoError(validator.ValidateAddress("qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a"))
+	require.NoError(validator.ValidateAddress("1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu"))
+	require.Error(validator.ValidateAddress("bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6b"))
+}
+
+func (s *CrosschainTestSuite) TestGetParamsFromUTXOConfig() {
+	require := s.Require()
+	pubkey, err := base64.RawStdEncoding.DecodeString("AptrsfXbXbvnsWxobWNFoUXHLO5nmgrQb3PDmGGu1CSS")
+	require.NoError(err)
+
+	// known chains reuse their params
+	params, err := GetParams(&xc.AssetConfig{NativeAsset: xc.LTC, Net: "mainnet"})
+	require.NoError(err)
+	require.Same(LtcNetworks.Mainnet, params)
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{NativeAsset: xc.LTC, Net: "mainnet"})
+	addresses, err := builder.GetAllPossibleAddressesFromPublicKey(pubkey)
+	require.NoError(err)
+	require.Equal(xc.Address("LMFWCGehoqVQJkdqg9rVGEXNLiY7y8hNem"), addresses[0].Address)
+	require.Equal(xc.Address("ltc1qzca49vcyxkt989qcmhjfp7wyze7n9pq5pvt02w"), addresses[1].Address)
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("ltc1qzca49vcyxkt989qcmhjfp7wyze7n9pq5pvt02w"))
+	require.Error(validator.ValidateAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"))
+
+	// the regtest of litecoin shares its magic with bitcoin, its HRP is registered anyway
+	builder, _ = NewAddressBuilder(&xc.AssetConfig{NativeAsset: xc.LTC, Net: "regtest"})
+	require.NoError(builder.(xc.AddressValidator).ValidateAddress("rltc1qzca49vcyxkt989qcmhjfp7wyze7n9pq5njfuf6"))
+
+	// dash has no segwit
+	builder, _ = NewAddressBuilder(&xc.AssetConfig{NativeAsset: xc.DASH, Net: "mainnet"})
+	addresses, err = builder.GetAllPossibleAddressesFromPublicKey(pubkey)
+	require.NoError(err)
+	require.Len(addresses, 1)
+	require.Equal(xc.Address("XciPmJzmgtTwCtYGMuBQqk9PxqkXpbEfJE"), addresses[0].Address)
+	require.NoError(builder.(xc.AddressValidator).ValidateAddress("XciPmJzmgtTwCtYGMuBQqk9PxqkXpbEfJE"))
+	require.Error(builder.(xc.AddressValidator).ValidateAddress("LMFWCGehoqVQJkdqg9rVGEXNLiY7y8hNem"))
+
+	// other chains are configured
+	asset := &xc.AssetConfig{NativeAsset: "XYZ", Net: "mainnet", UTXO: xc.UTXOConfig{
+		PubKeyHashAddrID: newUint8(48),
+		ScriptHashAddrID: newUint8(50),
+		Bech32HRP:        "xyz",
+		NetMagic:         0x12345678,
+	}}
+	params, err = GetParams(asset)
+	require.NoError(err)
+	require.EqualValues(48, params.PubKeyHashAddrID)
+	require.Equal("xyz", params.Bech32HRPSegwit)
+	require.EqualValues(0x12345678, params.Net)
+	same, _ := GetParams(asset)
+	require.Same(params, same)
+	builder, err = NewAddressBuilder(asset)
+	require.NoError(err)
+	addresses, err = builder.GetAllPossibleAddressesFromPublicKey(pubkey)
+	require.NoError(err)
+	require.Equal(xc.Address("LMFWCGehoqVQJkdqg9rVGEXNLiY7y8hNem"), addresses[0].Address)
+	require.NoError(builder.(xc.AddressValidator).ValidateAddress(addresses[1].Address))
+
+	// cashaddr chains
+	asset = &xc.AssetConfig{NativeAsset: "XYZCASH", Net: "mainnet", UTXO: xc.UTXOConfig{
+		PubKeyHashAddrID: newUint8(0),
+		ScriptHashAddrID: newUint8(5),
+		CashAddrPrefix:   "xyzcash",
+		SigHash:          xc.UTXOSigHashForkID,
+	}}
+	builder, _ = NewAddressBuilder(asset)
+	address, err := builder.GetAddressFromPublicKey(pubkey)
+	require.NoError(err)
+	require.True(strings.HasPrefix(string(address), "xyzcash:q"))
+	require.NoError(builder.(xc.AddressValidator).ValidateAddress(address))
+	txBuilder, _ := NewTxBuilder(asset)
+	require.True(txBuilder.(TxBuilder).isBch)
+
+	_, err = GetParams(&xc.AssetConfig{NativeAsset: "XYZ"})
+	require.ErrorContains(err, "unsupported utxo asset: XYZ")
+	_, err = GetParams(&xc.AssetConfig{NativeAsset: xc.LTC, UTXO: xc.UTXOConfig{HDPublicKeyID: "xpub"}})
+	require.ErrorContains(err, "invalid hd_public_key_id")
+}
+
+func newUint8(value uint8) *uint8 {
+	return &value
+}
+
 // TxBuilder
 
 func (s *CrosschainTestSuite) TestNewTxBuilder() {
@@ -208,6 +388,10 @@ func (s *CrosschainTestSuite) TestNewNativeTransfer() {
 			ser, err := tf.Serialize()
 			require.NoError(err)
 			require.True(len(ser) > 64)
+
+			streamed := &bytes.Buffer{}
+			require.NoError(xc.SerializeTo(streamed, tf))
+			require.Equal(ser, streamed.Bytes())
 		}
 	}
 }
@@ -261,6 +445,184 @@ func (s *CrosschainTestSuite) TestNewTransfer_Token() {
 	require.EqualError(err, "not implemented")
 }
 
+// Coin selection
+
+func utxos(values ...uint64) []Output {
+	outputs := []Output{}
+	for i, value := range values {
+		outputs = append(outputs, Output{Outpoint: Outpoint{Index: uint32(i)}, Value: xc.NewAmountBlockchainFromUint64(value)})
+	}
+	return outputs
+}
+
+func selectedValues(selection *CoinSelection) []uint64 {
+	values := []uint64{}
+	for _, input := range selection.Inputs {
+		values = append(values, input.Value.Uint64())
+	}
+	return values
+}
+
+func (s *CrosschainTestSuite) TestNewCoinSelector() {
+	require := s.Require()
+	selector := NewCoinSelector(&xc.AssetConfig{NativeAsset: xc.BTC})
+	require.Equal(CoinSelectionLargestFirst, selector.Strategy)
+	require.EqualValues(DefaultDustThreshold, selector.DustThreshold)
+	require.EqualValues(255, selector.InputSize)
+
+	require.EqualValues(300, NewCoinSelector(&xc.AssetConfig{NativeAsset: xc.BCH}).InputSize)
+	require.EqualValues(1_000_000, NewCoinSelector(&xc.AssetConfig{NativeAsset: xc.DOGE}).DustThreshold)
+
+	selector = NewCoinSelector(&xc.AssetConfig{NativeAsset: xc.BTC, CoinSelection: "branch-and-bound", DustThreshold: 1000})
+	require.Equal(CoinSelectionBranchAndBound, selector.Strategy)
+	require.EqualValues(1000, selector.DustThreshold)
+}
+
+func (s *CrosschainTestSuite) TestCoinSelectionLargestFirst() {
+	require := s.Require()
+	selector := NewCoinSelector(&xc.AssetConfig{NativeAsset: xc.BTC})
+	feeRate := xc.NewAmountBlockchainFromUint64(2)
+
+	// largest first until the amount and the fee are covered, then consolidates outputs worth spending
+	selection, err := selector.Select(utxos(20_000, 400, 100_000, 3_000, 50_000), xc.NewAmountBlockchainFromUint64(120_000), feeRate)
+	require.NoError(err)
+	require.Equal([]uint64{100_000, 50_000, 3_000, 20_000}, selectedValues(selection))
+	require.Equal("173000", selection.Total.String())
+	require.Equal("2040", selection.Fee.String())
+	require.Equal("50960", selection.Change.String())
+
+	selector.MinInputs = 0
+	selection, err = selector.Select(utxos(20_000, 400, 100_000, 3_000, 50_000), xc.NewAmountBlockchainFromUint64(120_000), feeRate)
+	require.NoError(err)
+	require.Equal([]uint64{100_000, 50_000}, selectedValues(selection))
+
+	// the fee of an input needs another input
+	selection, err = selector.Select(utxos(100_000, 50_000), xc.NewAmountBlockchainFromUint64(99_900), feeRate)
+	require.NoError(err)
+	require.Len(selection.Inputs, 2)
+
+	// dust change is left to the fee
+	selection, err = selector.Select(utxos(100_000), xc.NewAmountBlockchainFromUint64(99_000), feeRate)
+	require.NoError(err)
+	require.Equal("1000", selection.Fee.String())
+	require.Equal("0", selection.Change.String())
+
+	_, err = selector.Select(utxos(100_000, 50_000), xc.NewAmountBlockchainFromUint64(150_000), feeRate)
+	require.EqualError(err, "insufficient funds: 150000 available, 151020 needed including a fee of 1020")
+	_, err = selector.Select(nil, xc.NewAmountBlockchainFromUint64(1), feeRate)
+	require.ErrorContains(err, "insufficient funds")
+
+	selector.Strategy = "random"
+	_, err = selector.Select(utxos(100_000), xc.NewAmountBlockchainFromUint64(1), feeRate)
+	require.EqualError(err, "unknown coin selection strategy: random")
+}
+
+func (s *CrosschainTestSuite) TestCoinSelectionBranchAndBound() {
+	require := s.Require()
+	selector := NewCoinSelector(&xc.AssetConfig{NativeAsset: xc.BTC, CoinSelection: "branch-and-bound"})
+	feeRate := xc.NewAmountBlockchainFromUint64(1)
+
+	// 30_255 + 20_255 match 50_000 after their fee, without change
+	selection, err := selector.Select(utxos(100_000, 30_255, 60_000, 20_255, 5_000), xc.NewAmountBlockchainFromUint64(50_000), feeRate)
+	require.NoError(err)
+	require.Equal([]uint64{30_255, 20_255}, selectedValues(selection))
+	require.Equal("510", selection.Fee.String())
+	require.Equal("0", selection.Change.String())
+
+	// the excess within the dust threshold is left to the fee
+	selection, err = selector.Select(utxos(100_000, 50_500), xc.NewAmountBlockchainFromUint64(50_000), feeRate)
+	require.NoError(err)
+	require.Equal([]uint64{50_500}, selectedValues(selection))
+	require.Equal("500", selection.Fee.String())
+
+	// no match without change, largest first
+	selection, err = selector.Select(utxos(100_000, 70_000), xc.NewAmountBlockchainFromUint64(50_000), feeRate)
+	require.NoError(err)
+	require.Equal([]uint64{100_000, 70_000}, selectedValues(selection))
+	require.Equal("119490", selection.Change.String())
+}
+
+func (s *CrosschainTestSuite) TestNewNativeTransferCoinSelection() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: xc.BTC, Net: "testnet"}
+	builder, _ := NewTxBuilder(asset)
+	from := xc.Address("mpjwFvP88ZwAt3wEHY6irKkGhxcsv22BP6")
+	to := xc.Address("tb1qtpqqpgadjr2q3f4wrgd6ndclqtfg7cz5evtvs0")
+
+	// no change output for dust change, outputs not worth their fee aren't consolidated
+	input := &TxInput{UnspentOutputs: utxos(200, 5_000), GasPricePerByte: xc.NewAmountBlockchainFromUint64(1)}
+	tf, err := builder.(xc.TxTokenBuilder).NewNativeTransfer(from, to, xc.NewAmountBlockchainFromUint64(4_500), input)
+	require.NoError(err)
+	tx := tf.(*Tx)
+	require.Len(tx.input.Inputs, 1)
+	require.Len(tx.msgTx.TxOut, 1)
+	require.EqualValues(4_500, tx.msgTx.TxOut[0].Value)
+
+	// change output
+	input = &TxInput{UnspentOutputs: utxos(3_000, 5_000), GasPricePerByte: xc.NewAmountBlockchainFromUint64(1)}
+	tf, err = builder.(xc.TxTokenBuilder).NewNativeTransfer(from, to, xc.NewAmountBlockchainFromUint64(3_000), input)
+	require.NoError(err)
+	tx = tf.(*Tx)
+	require.Len(tx.input.Inputs, 2)
+	require.Len(tx.msgTx.TxOut, 2)
+	require.EqualValues(8_000-3_000-2*255, tx.msgTx.TxOut[1].Value)
+}
+
+// RBF
+
+func (s *CrosschainTestSuite) TestBumpFee() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: xc.BTC, Net: "testnet", ReplaceByFee: true}
+	builder, _ := NewTxBuilder(asset)
+	from := xc.Address("mpjwFvP88ZwAt3wEHY6irKkGhxcsv22BP6")
+	to := xc.Address("tb1qtpqqpgadjr2q3f4wrgd6ndclqtfg7cz5evtvs0")
+	input := &TxInput{UnspentOutputs: utxos(3_000, 5_000), GasPricePerByte: xc.NewAmountBlockchainFromUint64(1)}
+	tf, err := builder.(TxBuilder).NewNativeTransfer(from, to, xc.NewAmountBlockchainFromUint64(3_000), input)
+	require.NoError(err)
+	tx := tf.(*Tx)
+	require.True(tx.SignalsReplaceByFee())
+	require.EqualValues(ReplaceByFeeSequence, tx.msgTx.TxIn[0].Sequence)
+
+	// the fee increase is taken from the change
+	bumped, err := builder.(TxBuilder).BumpFee(tx, xc.NewAmountBlockchainFromUint64(3))
+	require.NoError(err)
+	replacement := bumped.(*Tx)
+	require.True(replacement.SignalsReplaceByFee())
+	require.NotEqual(tx.Hash(), replacement.Hash())
+	require.Len(replacement.msgTx.TxIn, 2)
+	require.Equal(tx.msgTx.TxIn[1].PreviousOutPoint, replacement.msgTx.TxIn[1].PreviousOutPoint)
+	require.Len(replacement.msgTx.TxOut, 2)
+	require.EqualValues(3_000, replacement.msgTx.TxOut[0].Value)
+	require.EqualValues(8_000-3_000-2*3*255, replacement.msgTx.TxOut[1].Value)
+	require.EqualValues(8_000-3_000-2*3*255, replacement.recipients[1].Value.Uint64())
+	fee, _ := replacement.fee()
+	require.EqualValues(2*3*255, fee)
+	sighashes, err := replacement.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes, 2)
+
+	// the change is dropped below the dust threshold
+	bumped, err = builder.(TxBuilder).BumpFee(replacement, xc.NewAmountBlockchainFromUint64(9))
+	require.NoError(err)
+	require.Len(bumped.(*Tx).msgTx.TxOut, 1)
+	require.Len(bumped.(*Tx).recipients, 1)
+
+	_, err = builder.(TxBuilder).BumpFee(replacement, xc.NewAmountBlockchainFromUint64(3))
+	require.ErrorContains(err, "must exceed the fee of 1530")
+	_, err = builder.(TxBuilder).BumpFee(bumped, xc.NewAmountBlockchainFromUint64(10))
+	require.ErrorContains(err, "has no change to bump its fee")
+	_, err = builder.(TxBuilder).BumpFee(replacement, xc.NewAmountBlockchainFromUint64(20))
+	require.ErrorContains(err, "can't cover a fee increase")
+
+	// txs built without replace_by_fee are final
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.BTC, Net: "testnet"})
+	input = &TxInput{UnspentOutputs: utxos(3_000, 5_000), GasPricePerByte: xc.NewAmountBlockchainFromUint64(1)}
+	tf, _ = builder.(TxBuilder).NewNativeTransfer(from, to, xc.NewAmountBlockchainFromUint64(3_000), input)
+	require.False(tf.(*Tx).SignalsReplaceByFee())
+	_, err = builder.(TxBuilder).BumpFee(tf, xc.NewAmountBlockchainFromUint64(3))
+	require.ErrorContains(err, "does not signal replace-by-fee")
+}
+
 // Client
 
 func (s *CrosschainTestSuite) TestNewClient() {
@@ -287,6 +649,55 @@ func (s *CrosschainTestSuite) TestSubmitTx() {
 	require.NoError(err)
 }
 
+func (s *CrosschainTestSuite) TestSubmitTxDryRun() {
+	require := s.Require()
+	tx := &Tx{msgTx: wire.NewMsgTx(2)}
+	serialized, _ := tx.Serialize()
+
+	// native nodes simulate txs with testmempoolaccept
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		fmt.Sprintf(`[{"txid":"%s","allowed":true}]`, tx.Hash()),
+		fmt.Sprintf(`[{"txid":"%s","allowed":false,"reject-reason":"missing-inputs"}]`, tx.Hash()),
+	})
+	defer close()
+	client, err := NewNativeClient(&xc.AssetConfig{NativeAsset: xc.BTC, URL: server.URL, Net: "testnet"})
+	require.NoError(err)
+	dryRun := &xc.DryRun{}
+	err = client.SubmitTx(xc.WithDryRun(s.Ctx, dryRun), tx)
+	require.NoError(err)
+	require.Equal([]*xc.DryRunSubmission{{Chain: xc.BTC, TxHash: tx.Hash(), Serialized: serialized, Simulated: true}}, dryRun.Submissions())
+	err = client.SubmitTx(xc.WithDryRun(s.Ctx, dryRun), tx)
+	require.EqualError(err, `tx rejected by "testmempoolaccept": missing-inputs`)
+	require.Len(dryRun.Submissions(), 1)
+
+	// blockchair doesn't simulate txs
+	blockchairClient, err := NewBlockchairClient(&xc.AssetConfig{NativeAsset: xc.BTC, URL: server.URL, Net: "testnet", DryRun: true})
+	require.NoError(err)
+	err = blockchairClient.SubmitTx(s.Ctx, tx)
+	require.NoError(err)
+	require.Equal(2, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestEstimateFee() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: xc.BTC, Net: "testnet"}
+	builder, _ := NewTxBuilder(asset)
+	input := &TxInput{UnspentOutputs: utxos(3_000, 5_000), GasPricePerByte: xc.NewAmountBlockchainFromUint64(1)}
+	tx, err := builder.(TxBuilder).NewNativeTransfer("mpjwFvP88ZwAt3wEHY6irKkGhxcsv22BP6", "tb1qtpqqpgadjr2q3f4wrgd6ndclqtfg7cz5evtvs0", xc.NewAmountBlockchainFromUint64(3_000), input)
+	require.NoError(err)
+
+	client, _ := NewBlockchairClient(asset)
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(3), nil
+	})
+	fee, err := client.EstimateFee(s.Ctx, "", tx)
+	require.NoError(err)
+	require.EqualValues(2*3*255, fee.Uint64())
+
+	_, err = client.EstimateFee(s.Ctx, "", &test.MockXcTx{})
+	require.EqualError(err, "tx is not a bitcoin tx")
+}
+
 func (s *CrosschainTestSuite) TestFetchTxInfo() {
 	require := s.Require()
 	server, close := test.MockHTTP(&s.Suite, []string{
@@ -308,11 +719,83 @@ func (s *CrosschainTestSuite) TestFetchTxInfo() {
 	require.EqualValues("tb1qtpqqpgadjr2q3f4wrgd6ndclqtfg7cz5evtvs0", info.Destinations[0].Address)
 	require.EqualValues("mpjwFvP88ZwAt3wEHY6irKkGhxcsv22BP6", info.Sources[0].Address)
 	require.EqualValues(100000, info.Destinations[0].Amount.Uint64())
+	require.Len(info.Change, 1)
+	require.EqualValues("mpjwFvP88ZwAt3wEHY6irKkGhxcsv22BP6", info.Change[0].Address)
+	require.EqualValues(2291980, info.Change[0].Amount.Uint64())
 	require.EqualValues(xc.TxStatusSuccess, info.Status)
 	require.EqualValues(12, info.Confirmations)
 	require.EqualValues(255, info.Fee.Uint64())
 }
 
+func (s *CrosschainTestSuite) TestFetchTxInfoActualFee() {
+	require := s.Require()
+	// the fee reported by the indexer is ignored for the inputs minus the outputs
+	server, close := test.MockHTTP(&s.Suite, []string{
+		`{"data":{"227178d784150211e8ea5a586ee75bc97655e61f02bc8c07557e475cfecea3cd":{"transaction":{"block_id":2428751,"id":65331999,"hash":"227178d784150211e8ea5a586ee75bc97655e61f02bc8c07557e475cfecea3cd","date":"2023-04-13","time":"2023-04-13 15:29:58","size":255,"weight":1020,"version":2,"lock_time":0,"is_coinbase":false,"has_witness":false,"input_count":1,"output_count":2,"input_total":2392235,"input_total_usd":0,"output_total":2391980,"output_total_usd":0,"fee":0,"fee_usd":0,"fee_per_kb":1000,"fee_per_kb_usd":0,"fee_per_kwu":250,"fee_per_kwu_usd":0,"cdd_total":0,"is_rbf":false},"inputs":[{"block_id":2428751,"transaction_id":65331998,"index":1,"transaction_hash":"c4979460bb03a1877bbf23571c83edbd02cb4da20049916fa6c5fbf77470e027","date":"2023-04-13","time":"2023-04-13 15:29:58","value":2392235,"value_usd":0,"recipient":"mpjwFvP88ZwAt3wEHY6irKkGhxcsv22BP6","type":"pubkeyhash","script_hex":"76a914652dac91ff1b130616cb11ce33b0ac2f1b4df89188ac","is_from_coinbase":false,"is_spendable":null,"is_spent":true,"spending_block_id":2428751,"spending_transaction_id":65331999,"spending_index":0,"spending_transaction_hash":"227178d784150211e8ea5a586ee75bc97655e61f02bc8c07557e475cfecea3cd","spending_date":"2023-04-13","spending_time":"2023-04-13 15:29:58","spending_value_usd":0,"spending_sequence":4294967295,"spending_signature_hex":"483045022100ad6a8b65d8eeeecf729d1ff6a8af95f3e9049944d3ca9ef5a9f7410dab494fc80220350555ff6b2911abbeda5190bbe3220d57e703feaf4edf393edc3fa2ec390bdf014104e6d880f2d81328599fd482d6e1e3a4ff5698dabccd1969d88a4134c113e17e3df7497d8133d5b3146f79b841e4e3c9e8d07c8a61cf423399e597352da50510e2","spending_witness":"","lifespan":0,"cdd":0}],"outputs":[{"block_id":2428751,"transaction_id":65331999,"index":0,"transaction_hash":"227178d784150211e8ea5a586ee75bc97655e61f02bc8c07557e475cfecea3cd","date":"2023-04-13","time":"2023-04-13 15:29:58","value":100000,"value_usd":0,"recipient":"tb1qtpqqpgadjr2q3f4wrgd6ndclqtfg7cz5evtvs0","type":"witness_v0_keyhash","script_hex":"0014584000a3ad90d408a6ae1a1ba9b71f02d28f6054","is_from_coinbase":false,"is_spendable":null,"is_spent":true,"spending_block_id":2428757,"spending_transaction_id":65332310,"spending_index":2,"spending_transaction_hash":"5e87a2a3d459c438cde63e536f40124f2acaf8d0158931144698da58b9476a0b","spending_date":"2023-04-13","spending_time":"2023-04-13 16:15:41","spending_value_usd":0,"spending_sequence":4294967294,"spending_signature_hex":"","spending_witness":"30440220044a4568409ced4aa381d8bdf3d3af23f063aec0a44feb009a7f345fc2ca25d10220485d78aafc21debedbe60f5196d8f67bbfc397815025c15de16bfbfa9b6fede201,0294fb77024c22c688ca1f548b4878d5a0cc24cb57aec750c87b19c1b780baa7a8","lifespan":2743,"cdd":0},{"block_id":2428751,"transaction_id":65331999,"index":1,"transaction_hash":"227178d784150211e8ea5a586ee75bc97655e61f02bc8c07557e475cfecea3cd","date":"2023-04-13","time":"2023-04-13 15:29:58","value":2291980,"value_usd":0,"recipient":"mpjwFvP88ZwAt3wEHY6irKkGhxcsv22BP6","type":"pubkeyhash","script_hex":"76a914652dac91ff1b130616cb11ce33b0ac2f1b4df89188ac","is_from_coinbase":false,"is_spendable":null,"is_spent":true,"spending_block_id":2428751,"spending_transaction_id":65332000,"spending_index":0,"spending_transaction_hash":"129b73fa6de24c8ebbbca2b4d8f4702ddbf1e02cb6d22cc5cf743d2a92b87880","spending_date":"2023-04-13","spending_time":"2023-04-13 15:29:58","spending_value_usd":0,"spending_sequence":4294967295,"spending_signature_hex":"473044022059a5def9aa5436c923e12f56ad48a75dcaf8e7667191e4ec34e9213482769fe9022063f8f52c7d21031b0d0db04ffb32277447835a87913435f60f439f63133627a4014104e6d880f2d81328599fd482d6e1e3a4ff5698dabccd1969d88a4134c113e17e3df7497d8133d5b3146f79b841e4e3c9e8d07c8a61cf423399e597352da50510e2","spending_witness":"","lifespan":0,"cdd":0}]}},"context":{"code":200,"source":"D","results":1,"state":2428762,"market_price_usd":30389,"cache":{"live":true,"duration":20,"since":"2023-04-13 17:27:13","until":"2023-04-13 17:27:33","time":null},"api":{"version":"2.0.95-ie","last_major_update":"2022-11-07 02:00:00","next_major_update":null,"documentation":"https:\/\/blockchair.com\/api\/docs","notice":"Please note that on November 7th, 2022 public support for the following blockchains was dropped: EOS, Bitcoin SV"},"servers":"API4,TBTC0","time":1.1531751155853271,"render_time":0.049282073974609375,"full_time":1.2024571895599365,"request_cost":1}}`,
+	})
+	defer close()
+	client, err := NewClient(&xc.AssetConfig{NativeAsset: xc.BTC, URL: server.URL, Net: "testnet"})
+	require.NoError(err)
+	info, err := client.FetchTxInfo(s.Ctx, xc.TxHash("227178d784150211e8ea5a586ee75bc97655e61f02bc8c07557e475cfecea3cd"))
+	require.NoError(err)
+	require.EqualValues(2392235-100000-2291980, info.Fee.Uint64())
+}
+
+func (s *CrosschainTestSuite) TestDetectChange() {
+	require := s.Require()
+	tx := &Tx{
+		input: TxInput{
+			Inputs: []Input{
+				{Address: "from1", Output: Output{Value: xc.NewAmountBlockchainFromUint64(1000)}},
+				{Address: "from2", Output: Output{Value: xc.NewAmountBlockchainFromUint64(500)}},
+			},
+		},
+		recipients: []Recipient{
+			{To: "to", Value: xc.NewAmountBlockchainFromUint64(700)},
+			{To: "from2", Value: xc.NewAmountBlockchainFromUint64(300)},
+			{To: "change", Value: xc.NewAmountBlockchainFromUint64(400)},
+		},
+	}
+	// the wallet of the sender owns from1 and change
+	detector := func(address xc.Address) bool {
+		return address == "from1" || address == "change"
+	}
+	require.True(tx.IsChange("from1", nil))
+	require.True(tx.IsChange("from2", nil))
+	require.False(tx.IsChange("change", nil))
+	require.True(tx.IsChange("change", detector))
+	require.False(tx.IsChange("to", detector))
+
+	from, _ := tx.DetectFrom()
+	require.Equal("from1", from)
+	// without a detector, the change output to a wallet address is taken as the recipient
+	to, amount, totalOut := tx.DetectToAndAmount(from, "")
+	require.Equal("change", to)
+	require.EqualValues(400, amount.Uint64())
+	require.EqualValues(1400, totalOut.Uint64())
+	to, amount, _ = tx.DetectToAndAmountWithChange(from, "", detector)
+	require.Equal("to", to)
+	require.EqualValues(700, amount.Uint64())
+
+	// a third party paying the wallet: its outputs are destinations, whatever the detector
+	deposit := &Tx{
+		input: TxInput{
+			Inputs: []Input{
+				{Address: "outsider", Output: Output{Value: xc.NewAmountBlockchainFromUint64(1000)}},
+			},
+		},
+		recipients: []Recipient{
+			{To: "change", Value: xc.NewAmountBlockchainFromUint64(600)},
+			{To: "outsider", Value: xc.NewAmountBlockchainFromUint64(300)},
+		},
+	}
+	require.False(deposit.IsChange("change", detector))
+	require.True(deposit.IsChange("outsider", detector))
+	to, amount, _ = deposit.DetectToAndAmountWithChange("outsider", "", detector)
+	require.Equal("change", to)
+	require.EqualValues(600, amount.Uint64())
+}
+
 // Signer
 
 func (s *CrosschainTestSuite) TestNewSigner() {
@@ -431,3 +914,395 @@ func (s *CrosschainTestSuite) TestTxAddSignature() {
 	}...)
 	require.NoError(err)
 }
+
+// Multisig
+
+func (s *CrosschainTestSuite) TestMultisigScript() {
+	require := s.Require()
+	keys := [][]byte{}
+	for i := byte(1); i <= 3; i++ {
+		privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{i}, 32))
+		keys = append(keys, privateKey.PubKey().SerializeCompressed())
+	}
+	script, err := NewMultisigScript(2, keys)
+	require.NoError(err)
+	threshold, publicKeys, err := parseMultisigScript(script)
+	require.NoError(err)
+	require.Equal(2, threshold)
+	require.Equal(keys, publicKeys)
+
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{NativeAsset: xc.BTC, Net: "testnet"})
+	address, err := builder.(AddressBuilder).GetMultisigAddress(script)
+	require.NoError(err)
+	require.True(strings.HasPrefix(string(address), "tb1q"))
+	require.Len(address, 62)
+	_, err = builder.(AddressBuilder).GetMultisigAddress([]byte{1, 2, 3})
+	require.ErrorContains(err, "invalid multisig script")
+
+	_, err = NewMultisigScript(1, nil)
+	require.EqualError(err, "multisig must have between 1 and 15 public keys, got 0")
+	_, err = NewMultisigScript(4, keys)
+	require.EqualError(err, "invalid threshold 4 of 3 public keys")
+	_, err = NewMultisigScript(1, [][]byte{{1, 2, 3}})
+	require.EqualError(err, "invalid compressed public key 010203")
+}
+
+func (s *CrosschainTestSuite) TestMultisigTransfer() {
+	require := s.Require()
+	privateKeys := []*btcec.PrivateKey{}
+	keys := [][]byte{}
+	for i := byte(1); i <= 3; i++ {
+		privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{i}, 32))
+		privateKeys = append(privateKeys, privateKey)
+		keys = append(keys, privateKey.PubKey().SerializeCompressed())
+	}
+	script, _ := NewMultisigScript(2, keys)
+	asset := &xc.AssetConfig{NativeAsset: xc.BTC, Net: "testnet"}
+	addressBuilder, _ := NewAddressBuilder(asset)
+	from, _ := addressBuilder.(AddressBuilder).GetMultisigAddress(script)
+	address, _ := btcutil.DecodeAddress(string(from), &chaincfg.TestNet3Params)
+	pkScript, _ := txscript.PayToAddrScript(address)
+
+	builder, _ := NewTxBuilder(asset)
+	input := &TxInput{
+		UnspentOutputs: []Output{
+			{Outpoint: Outpoint{Hash: bytes.Repeat([]byte{1}, 32), Index: 1}, Value: xc.NewAmountBlockchainFromUint64(10_000), PubKeyScript: pkScript},
+			{Outpoint: Outpoint{Hash: bytes.Repeat([]byte{2}, 32), Index: 0}, Value: xc.NewAmountBlockchainFromUint64(8_000), PubKeyScript: pkScript},
+		},
+		GasPricePerByte: xc.NewAmountBlockchainFromUint64(1),
+		MultisigScript:  script,
+	}
+	tf, err := builder.(TxBuilder).NewNativeTransfer(from, "tb1qtpqqpgadjr2q3f4wrgd6ndclqtfg7cz5evtvs0", xc.NewAmountBlockchainFromUint64(15_000), input)
+	require.NoError(err)
+	tx, ok := xc.AsMultisig(tf)
+	require.True(ok)
+	require.Equal(2, tx.Threshold())
+	require.Len(tx.Signers(), 3)
+	require.Empty(tx.SignedBy())
+
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes, 2)
+	sign := func(privateKey *btcec.PrivateKey) []xc.TxSignature {
+		signatures := []xc.TxSignature{}
+		for _, sighash := range sighashes {
+			signatures = append(signatures, psbtTestSign(require, privateKey, sighash))
+		}
+		return signatures
+	}
+
+	err = tx.AddSignaturesOf(keys[2], sign(privateKeys[0])...)
+	require.EqualError(err, fmt.Sprintf("signature of input 0 is not signed by %x", keys[2]))
+	other, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{4}, 32))
+	err = tx.AddSignaturesOf(other.PubKey().SerializeCompressed(), sign(other)...)
+	require.EqualError(err, fmt.Sprintf("%x is not a signer of the multisig", other.PubKey().SerializeCompressed()))
+	require.ErrorContains(tx.AddSignaturesOf(keys[2], sign(privateKeys[2])[0]), "expected 2 signatures, got 1 signatures")
+
+	// signers sign in any order
+	require.NoError(tx.AddSignaturesOf(keys[2], sign(privateKeys[2])...))
+	require.Equal([]xc.PublicKey{keys[2]}, tx.SignedBy())
+	require.False(tf.(*Tx).signed)
+	require.NoError(tx.AddSignaturesOf(keys[0], sign(privateKeys[0])...))
+	require.Equal([]xc.PublicKey{keys[0], keys[2]}, tx.SignedBy())
+	require.True(tf.(*Tx).signed)
+	require.EqualError(tx.AddSignaturesOf(keys[1], sign(privateKeys[1])...), "already signed")
+
+	msgTx := tf.(*Tx).msgTx
+	for i, input := range tf.(*Tx).input.Inputs {
+		require.Len(msgTx.TxIn[i].Witness, 4)
+		engine, err := txscript.NewEngine(input.PubKeyScript, msgTx, i, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(msgTx), input.Value.Int().Int64())
+		require.NoError(err)
+		require.NoError(engine.Execute())
+	}
+
+	// single signer txs aren't multisig
+	_, single := psbtTestTransfer(require, privateKeys[0])
+	_, ok = xc.AsMultisig(single)
+	require.False(ok)
+	require.EqualError(single.AddSignaturesOf(keys[0]), "tx is not sent from a multisig")
+}
+
+// PSBT
+
+// psbtTestTransfer returns a transfer spending two P2WPKH outputs of the public key of privateKey
+func psbtTestTransfer(require *require.Assertions, privateKey *btcec.PrivateKey) (TxBuilder, *Tx) {
+	asset := &xc.AssetConfig{NativeAsset: xc.BTC, Net: "testnet"}
+	builder, _ := NewTxBuilder(asset)
+	publicKey := privateKey.PubKey().SerializeCompressed()
+	address, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(publicKey), &chaincfg.TestNet3Params)
+	require.NoError(err)
+	pkScript, _ := txscript.PayToAddrScript(address)
+	input := &TxInput{
+		UnspentOutputs: []Output{
+			{Outpoint: Outpoint{Hash: bytes.Repeat([]byte{1}, 32), Index: 1}, Value: xc.NewAmountBlockchainFromUint64(10_000), PubKeyScript: pkScript},
+			{Outpoint: Outpoint{Hash: bytes.Repeat([]byte{2}, 32), Index: 0}, Value: xc.NewAmountBlockchainFromUint64(8_000), PubKeyScript: pkScript},
+		},
+		FromPublicKey:   publicKey,
+		GasPricePerByte: xc.NewAmountBlockchainFromUint64(1),
+	}
+	tf, err := builder.(TxBuilder).NewNativeTransfer(xc.Address(address.EncodeAddress()), "tb1qtpqqpgadjr2q3f4wrgd6ndclqtfg7cz5evtvs0", xc.NewAmountBlockchainFromUint64(15_000), input)
+	require.NoError(err)
+	return builder.(TxBuilder), tf.(*Tx)
+}
+
+func psbtTestSign(require *require.Assertions, privateKey *btcec.PrivateKey, sighash []byte) []byte {
+	signature, err := privateKey.Sign(sighash)
+	require.NoError(err)
+	return append(signature.R.FillBytes(make([]byte, 32)), signature.S.FillBytes(make([]byte, 32))...)
+}
+
+func (s *CrosschainTestSuite) TestPSBT() {
+	require := s.Require()
+	privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{7}, 32))
+	builder, tx := psbtTestTransfer(require, privateKey)
+	require.Len(tx.msgTx.TxIn, 2)
+
+	serialized, err := tx.SerializePSBT()
+	require.NoError(err)
+	require.True(bytes.HasPrefix(serialized, []byte("psbt\xff")))
+
+	// an external signer derives the sighash of each input from the PSBT and signs them one by one
+	external, err := builder.NewTxFromPSBT(serialized)
+	require.NoError(err)
+	require.Equal(tx.Hash(), external.Hash())
+	require.Equal(tx.recipients, external.recipients)
+	require.Equal(tx.input.Inputs[1].Value, external.input.Inputs[1].Value)
+	for i := range tx.msgTx.TxIn {
+		expected, _ := tx.Sighash(i)
+		sighash, err := external.Sighash(i)
+		require.NoError(err)
+		require.Equal(expected, sighash)
+	}
+	_, err = external.Sighash(2)
+	require.EqualError(err, "no input 2")
+
+	external.input.FromPublicKey = privateKey.PubKey().SerializeCompressed()
+	sighash, _ := external.Sighash(1)
+	require.NoError(external.AddSignature(1, psbtTestSign(require, privateKey, sighash)))
+	require.False(external.signed)
+	sighash, _ = external.Sighash(0)
+	require.NoError(external.AddSignature(0, psbtTestSign(require, privateKey, sighash)))
+	require.True(external.signed)
+	require.EqualError(external.AddSignature(0, make([]byte, 64)), "already signed")
+
+	// merged back
+	signed, err := external.SerializePSBT()
+	require.NoError(err)
+	require.NoError(tx.MergePSBT(signed))
+	require.True(tx.signed)
+	expected, _ := external.Serialize()
+	raw, _ := tx.Serialize()
+	require.Equal(expected, raw)
+	for i, input := range tx.input.Inputs {
+		engine, err := txscript.NewEngine(input.PubKeyScript, tx.msgTx, i, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(tx.msgTx), input.Value.Int().Int64())
+		require.NoError(err)
+		require.NoError(engine.Execute())
+	}
+
+	_, other := psbtTestTransfer(require, privateKey)
+	other.msgTx.LockTime = 1
+	require.EqualError(other.MergePSBT(signed), "psbt is of another tx")
+	_, err = builder.NewTxFromPSBT([]byte("psbt"))
+	require.EqualError(err, "invalid psbt magic")
+}
+
+func (s *CrosschainTestSuite) TestPSBTPartialSignatures() {
+	require := s.Require()
+	privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{7}, 32))
+	publicKey := privateKey.PubKey().SerializeCompressed()
+	builder, tx := psbtTestTransfer(require, privateKey)
+	serialized, _ := tx.SerializePSBT()
+
+	// a signer adds partial signatures, DER encoded with their sighash type, keyed by public key
+	msgTx, inputs, err := parsePSBT(serialized)
+	require.NoError(err)
+	unsignedTx := &bytes.Buffer{}
+	require.NoError(msgTx.SerializeNoWitness(unsignedTx))
+	partial := &bytes.Buffer{}
+	partial.Write(psbtMagic)
+	require.NoError(writePSBTPair(partial, psbtGlobalUnsignedTx, nil, unsignedTx.Bytes()))
+	partial.WriteByte(0)
+	for i, input := range inputs {
+		utxo := &bytes.Buffer{}
+		require.NoError(wire.WriteTxOut(utxo, 0, 0, input.witnessUtxo))
+		require.NoError(writePSBTPair(partial, psbtInWitnessUtxo, nil, utxo.Bytes()))
+		sighash, _ := tx.Sighash(i)
+		signature, _ := privateKey.Sign(sighash)
+		require.NoError(writePSBTPair(partial, psbtInPartialSig, publicKey, append(signature.Serialize(), byte(txscript.SigHashAll))))
+		partial.WriteByte(0)
+	}
+	partial.Write([]byte{0, 0})
+
+	imported, err := builder.NewTxFromPSBT(partial.Bytes())
+	require.NoError(err)
+	require.True(imported.signed)
+	require.Equal(publicKey, imported.input.FromPublicKey)
+	for i, input := range imported.input.Inputs {
+		engine, err := txscript.NewEngine(input.PubKeyScript, imported.msgTx, i, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(imported.msgTx), input.Value.Int().Int64())
+		require.NoError(err)
+		require.NoError(engine.Execute())
+	}
+
+	require.NoError(tx.MergePSBT(partial.Bytes()))
+	require.True(tx.signed)
+	expected, _ := imported.Serialize()
+	raw, _ := tx.Serialize()
+	require.Equal(expected, raw)
+
+	// signatures of another sighash or public key are rejected
+	_, tx = psbtTestTransfer(require, privateKey)
+	msgTx, inputs, _ = parsePSBT(serialized)
+	invalid := &bytes.Buffer{}
+	invalid.Write(psbtMagic)
+	require.NoError(writePSBTPair(invalid, psbtGlobalUnsignedTx, nil, unsignedTx.Bytes()))
+	invalid.WriteByte(0)
+	for _, input := range inputs {
+		utxo := &bytes.Buffer{}
+		require.NoError(wire.WriteTxOut(utxo, 0, 0, input.witnessUtxo))
+		require.NoError(writePSBTPair(invalid, psbtInWitnessUtxo, nil, utxo.Bytes()))
+		signature, _ := privateKey.Sign(bytes.Repeat([]byte{1}, 32))
+		require.NoError(writePSBTPair(invalid, psbtInPartialSig, publicKey, append(signature.Serialize(), byte(txscript.SigHashAll))))
+		invalid.WriteByte(0)
+	}
+	invalid.Write([]byte{0, 0})
+	require.EqualError(tx.MergePSBT(invalid.Bytes()), "partial signature of input 0 doesn't verify")
+	require.False(tx.isInputSigned(0))
+	_, err = builder.NewTxFromPSBT(invalid.Bytes())
+	require.EqualError(err, "partial signature of input 0 doesn't verify")
+
+	otherKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{8}, 32))
+	_, tx = psbtTestTransfer(require, otherKey)
+	tx.msgTx = msgTx
+	require.EqualError(tx.MergePSBT(partial.Bytes()), "partial signature of input 0 is of another public key")
+}
+
+func (s *CrosschainTestSuite) TestPSBTNonWitnessUtxo() {
+	require := s.Require()
+	privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{7}, 32))
+	publicKey := privateKey.PubKey().SerializeCompressed()
+	asset := &xc.AssetConfig{NativeAsset: xc.BTC, Net: "testnet"}
+	builder, _ := NewTxBuilder(asset)
+	address, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(publicKey), &chaincfg.TestNet3Params)
+	require.NoError(err)
+	pkScript, _ := txscript.PayToAddrScript(address)
+
+	previous := wire.NewMsgTx(TxVersion)
+	previous.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{3}, 0), nil, nil))
+	previous.AddTxOut(wire.NewTxOut(1_000, pkScript))
+	previous.AddTxOut(wire.NewTxOut(20_000, pkScript))
+	previousTx := &bytes.Buffer{}
+	require.NoError(previous.Serialize(previousTx))
+	hash := previous.TxHash()
+	input := &TxInput{
+		UnspentOutputs: []Output{
+			{Outpoint: Outpoint{Hash: hash[:], Index: 1}, Value: xc.NewAmountBlockchainFromUint64(20_000), PubKeyScript: pkScript, PreviousTx: previousTx.Bytes()},
+		},
+		FromPublicKey:   publicKey,
+		GasPricePerByte: xc.NewAmountBlockchainFromUint64(1),
+	}
+	tf, err := builder.(TxBuilder).NewNativeTransfer(xc.Address(address.EncodeAddress()), "tb1qtpqqpgadjr2q3f4wrgd6ndclqtfg7cz5evtvs0", xc.NewAmountBlockchainFromUint64(15_000), input)
+	require.NoError(err)
+	tx := tf.(*Tx)
+
+	serialized, err := tx.SerializePSBT()
+	require.NoError(err)
+	_, inputs, err := parsePSBT(serialized)
+	require.NoError(err)
+	require.Nil(inputs[0].witnessUtxo)
+	require.Equal(hash, inputs[0].nonWitnessUtxo.TxHash())
+
+	external, err := builder.(TxBuilder).NewTxFromPSBT(serialized)
+	require.NoError(err)
+	require.Equal(previousTx.Bytes(), external.input.Inputs[0].PreviousTx)
+	require.Equal("20000", external.input.Inputs[0].Value.String())
+	external.input.FromPublicKey = publicKey
+	sighash, _ := external.Sighash(0)
+	require.NoError(external.AddSignature(0, psbtTestSign(require, privateKey, sighash)))
+	signed, err := external.SerializePSBT()
+	require.NoError(err)
+	require.NoError(tx.MergePSBT(signed))
+	engine, err := txscript.NewEngine(pkScript, tx.msgTx, 0, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(tx.msgTx), 20_000)
+	require.NoError(err)
+	require.NoError(engine.Execute())
+
+	// the previous tx must be of the outpoint
+	tx.input.Inputs[0].PreviousTx = previousTx.Bytes()[1:]
+	tx.signed = false
+	tx.signedInputs = nil
+	_, err = tx.SerializePSBT()
+	require.ErrorContains(err, "previous tx of input 0")
+}
+
+func (s *CrosschainTestSuite) TestAddSignatureFailure() {
+	require := s.Require()
+	privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{7}, 32))
+	_, tx := psbtTestTransfer(require, privateKey)
+
+	// a non-segwit script sig pushing more than 520 bytes can't be built
+	tx.input.Inputs[0].PubKeyScript = []byte{txscript.OP_TRUE}
+	tx.input.Inputs[0].SigScript = bytes.Repeat([]byte{1}, 600)
+	require.Error(tx.AddSignature(0, make([]byte, 64)))
+	require.False(tx.isInputSigned(0))
+	require.Nil(tx.msgTx.TxIn[0].SignatureScript)
+}
+
+func (s *CrosschainTestSuite) TestReadWitness() {
+	require := s.Require()
+	witness := &bytes.Buffer{}
+	require.NoError(writeWitness(witness, wire.TxWitness{{1}, {2, 3}}))
+	read, err := readWitness(bytes.NewReader(witness.Bytes()))
+	require.NoError(err)
+	require.Equal(wire.TxWitness{{1}, {2, 3}}, read)
+
+	tooMany := &bytes.Buffer{}
+	require.NoError(wire.WriteVarInt(tooMany, 0, maxWitnessItems+1))
+	_, err = readWitness(bytes.NewReader(tooMany.Bytes()))
+	require.EqualError(err, "too many witness items, max 1000")
+}
+
+func (s *CrosschainTestSuite) TestGetParams() {
+	require := s.Require()
+	params, err := GetParams(&xc.AssetConfig{NativeAsset: xc.BTC, Net: xc.Mainnet})
+	require.NoError(err)
+	require.Equal("mainnet", params.Name)
+	params, _ = GetParams(&xc.AssetConfig{NativeAsset: xc.BTC, Net: xc.Testnet})
+	require.Equal("testnet3", params.Name)
+	params, _ = GetParams(&xc.AssetConfig{NativeAsset: xc.BTC})
+	require.Equal("regtest", params.Name)
+}
+
+func (s *CrosschainTestSuite) TestEstimateGasMinFeeRate() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: xc.BTC, Net: "testnet", MinFeeRate: 4.5}
+	nativeClient, err := NewNativeClient(asset)
+	require.NoError(err)
+	blockchairClient, err := NewBlockchairClient(asset)
+	require.NoError(err)
+
+	for _, client := range []xc.GasEstimator{nativeClient, blockchairClient} {
+		client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+			return xc.NewAmountBlockchainFromUint64(1), nil
+		})
+		feeRate, err := client.EstimateGas(s.Ctx)
+		require.NoError(err)
+		// rounded up
+		require.Equal("5", feeRate.String())
+
+		client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+			return xc.NewAmountBlockchainFromUint64(20), nil
+		})
+		feeRate, err = client.EstimateGas(s.Ctx)
+		require.NoError(err)
+		require.Equal("20", feeRate.String())
+
+		// no floor on errors, the fallback to the node is cancelled
+		client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+			return xc.NewAmountBlockchainFromUint64(0), errors.New("no estimate")
+		})
+		ctx, cancel := context.WithCancel(s.Ctx)
+		cancel()
+		feeRate, err = client.EstimateGas(ctx)
+		require.Error(err)
+		require.Equal("0", feeRate.String())
+	}
+}
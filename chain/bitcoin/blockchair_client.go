@@ -22,6 +22,7 @@ type BlockchairClient struct {
 	httpClient      http.Client
 	Asset           *xc.AssetConfig
 	EstimateGasFunc xc.EstimateGasFunc
+	ChangeDetector  ChangeDetector
 }
 
 var _ xc.FullClientWithGas = &BlockchairClient{}
@@ -33,6 +34,11 @@ func NewBlockchairClient(cfgI xc.ITask) (*BlockchairClient, error) {
 	opts := DefaultClientOptions()
 	httpClient := http.Client{}
 	httpClient.Timeout = opts.Timeout
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return &BlockchairClient{}, err
+	}
+	httpClient.Transport = transport
 	params, err := GetParams(cfg)
 	if err != nil {
 		return &BlockchairClient{}, err
@@ -59,13 +65,24 @@ func (client *BlockchairClient) LatestBlock(ctx context.Context) (uint64, error)
 }
 
 func (client *BlockchairClient) SubmitTx(ctx context.Context, tx xc.Tx) error {
-	serial, err := tx.Serialize()
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	var serial string
+	err := xc.SerializePooled(tx, func(serialized []byte) error {
+		serial = hex.EncodeToString(serialized)
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("bad tx: %v", err)
 	}
+	// blockchair can't simulate txs
+	if xc.IsDryRun(ctx, client.Asset) {
+		return xc.RecordDryRun(ctx, client.Asset, tx, false)
+	}
 
 	postUrl := fmt.Sprintf("%s/push/transaction?key=%s", client.opts.Host, client.opts.Password)
-	postData := fmt.Sprintf("data=%s", hex.EncodeToString(serial))
+	postData := fmt.Sprintf("data=%s", serial)
 	log.Debug(postData)
 	res, err := client.httpClient.Post(postUrl, "application/x-www-form-urlencoded", bytes.NewBuffer([]byte(postData)))
 	if err != nil {
@@ -170,8 +187,11 @@ func (client *BlockchairClient) FetchTxInput(ctx context.Context, from xc.Addres
 }
 
 type blockchairStatsData struct {
-	Blocks       uint64  `json:"blocks"`
-	SuggestedFee float64 `json:"suggested_transaction_fee_per_byte_sat"`
+	Blocks              uint64  `json:"blocks"`
+	BestBlockHeight     uint64  `json:"best_block_height"`
+	Blocks24h           uint64  `json:"blocks_24h"`
+	MempoolTransactions uint64  `json:"mempool_transactions"`
+	SuggestedFee        float64 `json:"suggested_transaction_fee_per_byte_sat"`
 }
 
 type blockchairStats struct {
@@ -361,18 +381,26 @@ func (client *BlockchairClient) FetchTxInfo(ctx context.Context, txHash xc.TxHas
 	}
 
 	// detect from, to, amount
-	from, _ := tx.DetectFrom()
-	to, amount, _ := tx.DetectToAndAmount(from, expectedTo)
+	from, totalIn := tx.DetectFrom()
+	to, amount, totalOut := tx.DetectToAndAmountWithChange(from, expectedTo, client.ChangeDetector)
+	// the fee actually paid is the inputs minus the outputs, coinbase txs have no inputs
+	if len(data.Inputs) > 0 && totalIn.Cmp(&totalOut) >= 0 {
+		txWithInfo.Fee = totalIn.Sub(&totalOut)
+	}
+	change := []*xc.TxInfoEndpoint{}
 	for _, out := range data.Outputs {
-		if out.Recipient != from {
-			destinations = append(destinations, &xc.TxInfoEndpoint{
-				Address:         xc.Address(out.Recipient),
-				ContractAddress: "",
-				Amount:          xc.NewAmountBlockchainFromUint64(out.Value),
-				NativeAsset:     client.Asset.NativeAsset,
-				Asset:           xc.Asset(client.Asset.NativeAsset),
-				AssetConfig:     client.Asset,
-			})
+		endpoint := &xc.TxInfoEndpoint{
+			Address:         xc.Address(out.Recipient),
+			ContractAddress: "",
+			Amount:          xc.NewAmountBlockchainFromUint64(out.Value),
+			NativeAsset:     client.Asset.NativeAsset,
+			Asset:           xc.Asset(client.Asset.NativeAsset),
+			AssetConfig:     client.Asset,
+		}
+		if tx.IsChange(endpoint.Address, client.ChangeDetector) {
+			change = append(change, endpoint)
+		} else {
+			destinations = append(destinations, endpoint)
 		}
 	}
 
@@ -384,16 +412,39 @@ func (client *BlockchairClient) FetchTxInfo(ctx context.Context, txHash xc.TxHas
 	txWithInfo.Amount = amount
 	txWithInfo.Sources = sources
 	txWithInfo.Destinations = destinations
+	txWithInfo.Change = change
 
 	return *txWithInfo, nil
 }
 
+// RegisterChangeDetector registers a callback recognizing the sender's own addresses,
+// so that outputs to them are reported as change rather than destinations
+func (client *BlockchairClient) RegisterChangeDetector(detector ChangeDetector) {
+	client.ChangeDetector = detector
+}
+
 // EstimateGas(ctx context.Context) (AmountBlockchain, error)
 func (client *BlockchairClient) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc) {
 	client.EstimateGasFunc = estimateGas
 }
 
+var _ xc.FeeEstimator = &BlockchairClient{}
+
+// EstimateFee estimates the fee of tx at the estimated fee rate
+func (client *BlockchairClient) EstimateFee(ctx context.Context, _ xc.Address, tx xc.Tx) (xc.AmountBlockchain, error) {
+	return estimateFee(ctx, client, client.Asset, tx)
+}
+
+// EstimateGas estimates the fee rate in sats per byte, floored to the configured min fee rate
 func (client *BlockchairClient) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	feeRate, err := client.estimateGas(ctx)
+	if err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), err
+	}
+	return feeRate.ApplyFloor(minFeeRate(client.Asset)), nil
+}
+
+func (client *BlockchairClient) estimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
 	// invoke EstimateGasFunc callback, if registered
 	if client.EstimateGasFunc != nil {
 		nativeAsset := client.Asset.NativeAsset
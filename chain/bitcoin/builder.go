@@ -16,9 +16,13 @@ const TxVersion int32 = 2
 
 // TxBuilder for Bitcoin
 type TxBuilder struct {
-	Asset  *xc.AssetConfig
-	Params *chaincfg.Params
-	isBch  bool
+	Asset        *xc.AssetConfig
+	Params       *chaincfg.Params
+	CoinSelector CoinSelector
+	// ReplaceByFee signals replaceability of txs, see BumpFee
+	ReplaceByFee bool
+	// isBch is set for chains signing with SIGHASH_FORKID, e.g. Bitcoin Cash
+	isBch bool
 }
 
 // NewTxBuilder creates a new Bitcoin TxBuilder
@@ -29,14 +33,19 @@ func NewTxBuilder(cfgI xc.ITask) (xc.TxBuilder, error) {
 		return TxBuilder{}, err
 	}
 	return TxBuilder{
-		Asset:  asset,
-		Params: params,
-		isBch:  asset.NativeAsset == xc.BCH,
+		Asset:        asset,
+		Params:       params,
+		CoinSelector: NewCoinSelector(asset),
+		ReplaceByFee: asset.ReplaceByFee,
+		isBch:        asset.GetUTXO().SigHash == xc.UTXOSigHashForkID,
 	}, nil
 }
 
 // NewTransfer creates a new transfer for an Asset, either native or token
 func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
 	if txBuilder.Asset.Type == xc.AssetTypeToken {
 		return txBuilder.NewTokenTransfer(from, to, amount, input)
 	}
@@ -56,29 +65,35 @@ func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amo
 		}
 		local_input = *ptr
 	}
-	// Only need to save min utxo for the transfer.
-	totalSpend := local_input.allocateMinUtxoSet(amount, 10)
+	selection, err := txBuilder.CoinSelector.Select(local_input.UnspentOutputs, amount, local_input.GasPricePerByte)
+	if err != nil {
+		return nil, err
+	}
+	// Only need to save the selected utxo for the transfer.
+	local_input.Inputs = selection.Inputs
 	local_input.UnspentOutputs = []Output{}
-
-	gasPrice := local_input.GasPricePerByte
-	// 255 for bitcoin, 300 for bch
-	estimatedTxBytesLength := xc.NewAmountBlockchainFromUint64(uint64(255 * len(local_input.Inputs)))
-	if txBuilder.Asset.NativeAsset == xc.BCH {
-		estimatedTxBytesLength = xc.NewAmountBlockchainFromUint64(uint64(300 * len(local_input.Inputs)))
+	if len(local_input.MultisigScript) > 0 {
+		if txBuilder.isBch {
+			return nil, errors.New("bch multisig is not supported")
+		}
+		// inputs are signed against the witness script
+		for i := range local_input.Inputs {
+			local_input.Inputs[i].SigScript = local_input.MultisigScript
+		}
 	}
-	fee := gasPrice.Mul(&estimatedTxBytesLength)
 
-	transferAmountAndFee := amount.Add(&fee)
-	unspentAmountMinusTransferAndFee := totalSpend.Sub(&transferAmountAndFee)
 	recipients := []Recipient{
 		{
 			To:    to,
 			Value: amount,
 		},
-		{
+	}
+	// change below the dust threshold is left to the fee
+	if selection.Change.Sign() > 0 {
+		recipients = append(recipients, Recipient{
 			To:    from,
-			Value: unspentAmountMinusTransferAndFee,
-		},
+			Value: selection.Change,
+		})
 	}
 
 	msgTx := wire.NewMsgTx(TxVersion)
@@ -86,14 +101,18 @@ func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amo
 	for _, input := range local_input.Inputs {
 		hash := chainhash.Hash{}
 		copy(hash[:], input.Hash)
-		msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&hash, input.Index), nil, nil))
+		txIn := wire.NewTxIn(wire.NewOutPoint(&hash, input.Index), nil, nil)
+		if txBuilder.ReplaceByFee && !txBuilder.isBch {
+			txIn.Sequence = ReplaceByFeeSequence
+		}
+		msgTx.AddTxIn(txIn)
 	}
 
 	// Outputs
 	for _, recipient := range recipients {
 		addr, err := btcutil.DecodeAddress(string(recipient.To), txBuilder.Params)
 		fmt.Println("trying to decode ", recipient.To)
-		if err != nil {
+		if _, ok := cashAddrPrefix(txBuilder.Params); err != nil && ok {
 			// try to decode as BCH
 			bchaddr, err2 := DecodeBchAddress(string(recipient.To), txBuilder.Params)
 			if err2 != nil {
@@ -103,6 +122,8 @@ func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amo
 			if err != nil {
 				return nil, err
 			}
+		} else if err != nil {
+			return nil, err
 		}
 		script, err := txscript.PayToAddrScript(addr)
 		if err != nil {
@@ -0,0 +1,98 @@
+package bitcoin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+var _ xc.ClientChainStats = &NativeClient{}
+var _ xc.ClientChainStats = &BlockchairClient{}
+
+// FetchChainStats returns the latest block, the average block time, the fee rate estimate in sats per byte
+// and the number of txs in the mempool of the node
+// Bitcoin chains have no base fee, only the min relay fee of nodes
+func (client *NativeClient) FetchChainStats(ctx context.Context) (*xc.ChainStats, error) {
+	height, err := client.LatestBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch latest block: %v", err)
+	}
+	stats := &xc.ChainStats{
+		Chain:  client.Asset.NativeAsset,
+		Height: height,
+	}
+	stats.AverageBlockTime, err = client.fetchAverageBlockTime(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	stats.FeeRate, err = client.EstimateGas(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not estimate fee rate: %v", err)
+	}
+	mempool := btcjson.GetMempoolInfoResult{}
+	if err := client.send(ctx, &mempool, "getmempoolinfo"); err != nil {
+		return nil, fmt.Errorf("could not fetch mempool info: %v", err)
+	}
+	size := uint64(mempool.Size)
+	stats.MempoolSize = &size
+	return stats, nil
+}
+
+// fetchAverageBlockTime returns the average block time over the ChainStatsBlocks blocks up to height
+func (client *NativeClient) fetchAverageBlockTime(ctx context.Context, height uint64) (time.Duration, error) {
+	if height == 0 {
+		return 0, nil
+	}
+	fromHeight := uint64(0)
+	if height > xc.ChainStatsBlocks {
+		fromHeight = height - xc.ChainStatsBlocks
+	}
+	fromTime, err := client.fetchBlockTime(ctx, fromHeight)
+	if err != nil {
+		return 0, err
+	}
+	latestTime, err := client.fetchBlockTime(ctx, height)
+	if err != nil {
+		return 0, err
+	}
+	return xc.AverageBlockTime(fromHeight, fromTime, height, latestTime), nil
+}
+
+// fetchBlockTime returns the time of the block at height
+func (client *NativeClient) fetchBlockTime(ctx context.Context, height uint64) (time.Time, error) {
+	var hash string
+	if err := client.send(ctx, &hash, "getblockhash", height); err != nil {
+		return time.Time{}, fmt.Errorf("could not fetch hash of block %d: %v", height, err)
+	}
+	header := btcjson.GetBlockHeaderVerboseResult{}
+	if err := client.send(ctx, &header, "getblockheader", hash, true); err != nil {
+		return time.Time{}, fmt.Errorf("could not fetch block %d: %v", height, err)
+	}
+	return time.Unix(header.Time, 0), nil
+}
+
+// FetchChainStats returns the latest block, the average block time over the last 24h, the fee rate estimate
+// in sats per byte and the number of txs in the mempool, from the stats of blockchair
+func (client *BlockchairClient) FetchChainStats(ctx context.Context) (*xc.ChainStats, error) {
+	var stats blockchairStats
+	if _, err := client.send(ctx, &stats, "/stats"); err != nil {
+		return nil, fmt.Errorf("could not fetch stats: %v", err)
+	}
+	feeRate, err := client.EstimateGas(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not estimate fee rate: %v", err)
+	}
+	chainStats := &xc.ChainStats{
+		Chain:       client.Asset.NativeAsset,
+		Height:      stats.Data.BestBlockHeight,
+		FeeRate:     feeRate,
+		MempoolSize: &stats.Data.MempoolTransactions,
+	}
+	if stats.Data.Blocks24h > 0 {
+		chainStats.AverageBlockTime = 24 * time.Hour / time.Duration(stats.Data.Blocks24h)
+	}
+	return chainStats, nil
+}
@@ -0,0 +1,68 @@
+package bitcoin
+
+import (
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestFetchChainStatsNative() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// getblockcount
+		`800020`,
+		// getblockhash and getblockheader of block 800000
+		`"00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054"`,
+		`{"hash":"00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054","height":800000,"time":1690168629}`,
+		// getblockhash and getblockheader of block 800020, 20 blocks in 12000s
+		`"000000000000000000034b4ed4e8e5b2da3a6c4e4a4f40b31b5b2e33fe2b11a3"`,
+		`{"hash":"000000000000000000034b4ed4e8e5b2da3a6c4e4a4f40b31b5b2e33fe2b11a3","height":800020,"time":1690180629}`,
+		// getmempoolinfo
+		`{"size":1234,"bytes":567890}`,
+	})
+	defer close()
+	client, err := NewNativeClient(&xc.AssetConfig{NativeAsset: xc.BTC, URL: server.URL, Net: "mainnet"})
+	require.NoError(err)
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(20), nil
+	})
+
+	stats, err := client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.BTC, stats.Chain)
+	require.EqualValues(800020, stats.Height)
+	require.Equal(10*time.Minute, stats.AverageBlockTime)
+	require.Equal("20", stats.FeeRate.String())
+	require.Equal("0", stats.BaseFee.String())
+	require.NotNil(stats.MempoolSize)
+	require.EqualValues(1234, *stats.MempoolSize)
+	require.Equal(6, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestFetchChainStatsBlockchair() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{
+		`{"data":{"blocks":800021,"best_block_height":800020,"blocks_24h":144,"mempool_transactions":1234,"suggested_transaction_fee_per_byte_sat":20},"context":{"code":200}}`,
+		`{"data":{"blocks":800021,"best_block_height":800020,"blocks_24h":144,"mempool_transactions":1234,"suggested_transaction_fee_per_byte_sat":20},"context":{"code":200}}`,
+	})
+	defer close()
+	client, err := NewBlockchairClient(&xc.AssetConfig{NativeAsset: xc.BTC, URL: server.URL, Net: "mainnet"})
+	require.NoError(err)
+
+	stats, err := client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.BTC, stats.Chain)
+	require.EqualValues(800020, stats.Height)
+	require.Equal(10*time.Minute, stats.AverageBlockTime)
+	// 5x the suggested fee
+	require.Equal("100", stats.FeeRate.String())
+	require.NotNil(stats.MempoolSize)
+	require.EqualValues(1234, *stats.MempoolSize)
+
+	server, close = test.MockHTTP(&s.Suite, `{"data":null,"context":{"code":430,"error":"rate limited"}}`)
+	defer close()
+	client, _ = NewBlockchairClient(&xc.AssetConfig{NativeAsset: xc.BTC, URL: server.URL, Net: "mainnet"})
+	_, err = client.FetchChainStats(s.Ctx)
+	require.ErrorContains(err, "could not fetch stats: error code failure: 430: rate limited")
+}
@@ -0,0 +1,175 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// MaxMultisigPublicKeys is the max number of public keys of a standard multisig script
+const MaxMultisigPublicKeys = 15
+
+var _ xc.TxMultisig = &Tx{}
+
+// NewMultisigScript returns the witness script of a threshold of len(publicKeys) multisig, with the compressed public keys in order
+// The order of the public keys changes the script, hence the address: sort them (BIP 67) to share the address between wallets
+func NewMultisigScript(threshold int, publicKeys [][]byte) ([]byte, error) {
+	if len(publicKeys) == 0 || len(publicKeys) > MaxMultisigPublicKeys {
+		return nil, fmt.Errorf("multisig must have between 1 and %d public keys, got %d", MaxMultisigPublicKeys, len(publicKeys))
+	}
+	if threshold < 1 || threshold > len(publicKeys) {
+		return nil, fmt.Errorf("invalid threshold %d of %d public keys", threshold, len(publicKeys))
+	}
+	builder := txscript.NewScriptBuilder().AddInt64(int64(threshold))
+	for _, publicKey := range publicKeys {
+		if _, err := btcec.ParsePubKey(publicKey, btcec.S256()); err != nil || len(publicKey) != btcec.PubKeyBytesLenCompressed {
+			return nil, fmt.Errorf("invalid compressed public key %x", publicKey)
+		}
+		builder.AddData(publicKey)
+	}
+	return builder.AddInt64(int64(len(publicKeys))).AddOp(txscript.OP_CHECKMULTISIG).Script()
+}
+
+// GetMultisigAddress returns the P2WSH address of a multisig witness script
+func (ab AddressBuilder) GetMultisigAddress(script []byte) (xc.Address, error) {
+	if _, _, err := parseMultisigScript(script); err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(script)
+	address, err := btcutil.NewAddressWitnessScriptHash(hash[:], ab.params)
+	if err != nil {
+		return "", err
+	}
+	return xc.Address(address.EncodeAddress()), nil
+}
+
+// parseMultisigScript returns the threshold and public keys of a multisig script
+func parseMultisigScript(script []byte) (int, [][]byte, error) {
+	_, threshold, err := txscript.CalcMultiSigStats(script)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid multisig script: %v", err)
+	}
+	publicKeys, err := txscript.PushedData(script)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid multisig script: %v", err)
+	}
+	return threshold, publicKeys, nil
+}
+
+// multisig returns the threshold and the public keys of the multisig sender, or 0 for a single signer
+func (tx *Tx) multisig() (int, [][]byte) {
+	if len(tx.input.MultisigScript) == 0 {
+		return 0, nil
+	}
+	threshold, publicKeys, err := parseMultisigScript(tx.input.MultisigScript)
+	if err != nil {
+		return 0, nil
+	}
+	return threshold, publicKeys
+}
+
+// Threshold is the number of signatures required by the multisig script of the sender, 0 for a single signer
+func (tx *Tx) Threshold() int {
+	threshold, _ := tx.multisig()
+	return threshold
+}
+
+// Signers are the public keys of the multisig script of the sender
+func (tx *Tx) Signers() []xc.PublicKey {
+	_, publicKeys := tx.multisig()
+	signers := make([]xc.PublicKey, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		signers[i] = publicKey
+	}
+	return signers
+}
+
+// SignedBy returns the public keys of the signers whose signatures were added
+func (tx *Tx) SignedBy() []xc.PublicKey {
+	signers := []xc.PublicKey{}
+	if len(tx.multisigSignatures) == 0 {
+		return signers
+	}
+	_, publicKeys := tx.multisig()
+	for i, signature := range tx.multisigSignatures[0] {
+		if signature != nil {
+			signers = append(signers, publicKeys[i])
+		}
+	}
+	return signers
+}
+
+// AddSignaturesOf adds the signatures of signer, one per input, checked against the sighashes
+// Once Threshold signers have signed, the witness of each input is set with their signatures in the order of the script
+func (tx *Tx) AddSignaturesOf(signer xc.PublicKey, signatures ...xc.TxSignature) error {
+	if tx.signed {
+		return errors.New("already signed")
+	}
+	threshold, publicKeys := tx.multisig()
+	if threshold == 0 {
+		return errors.New("tx is not sent from a multisig")
+	}
+	index := -1
+	for i, publicKey := range publicKeys {
+		if bytes.Equal(publicKey, signer) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%x is not a signer of the multisig", []byte(signer))
+	}
+	if len(signatures) != len(tx.msgTx.TxIn) {
+		return fmt.Errorf("expected %v signatures, got %v signatures", len(tx.msgTx.TxIn), len(signatures))
+	}
+	publicKey, err := btcec.ParsePubKey(signer, btcec.S256())
+	if err != nil {
+		return err
+	}
+	sighashes, err := tx.Sighashes()
+	if err != nil {
+		return err
+	}
+	serialized := make([][]byte, len(signatures))
+	for i, rsvBytes := range signatures {
+		signature, err := parseSignature(rsvBytes)
+		if err != nil {
+			return err
+		}
+		if !signature.Verify(sighashes[i], publicKey) {
+			return fmt.Errorf("signature of input %d is not signed by %x", i, []byte(signer))
+		}
+		serialized[i] = append(signature.Serialize(), byte(txscript.SigHashAll))
+	}
+
+	if len(tx.multisigSignatures) != len(tx.msgTx.TxIn) {
+		tx.multisigSignatures = make([][][]byte, len(tx.msgTx.TxIn))
+		for i := range tx.multisigSignatures {
+			tx.multisigSignatures[i] = make([][]byte, len(publicKeys))
+		}
+	}
+	for i := range serialized {
+		tx.multisigSignatures[i][index] = serialized[i]
+	}
+	if len(tx.SignedBy()) < threshold {
+		return nil
+	}
+	for i, inputSignatures := range tx.multisigSignatures {
+		// OP_CHECKMULTISIG pops an extra item
+		witness := wire.TxWitness{[]byte{}}
+		for _, signature := range inputSignatures {
+			if signature != nil && len(witness) <= threshold {
+				witness = append(witness, signature)
+			}
+		}
+		tx.msgTx.TxIn[i].Witness = append(witness, tx.input.MultisigScript)
+		tx.setInputSigned(i)
+	}
+	return nil
+}
@@ -67,6 +67,7 @@ type NativeClient struct {
 	httpClient      http.Client
 	Asset           *xc.AssetConfig
 	EstimateGasFunc xc.EstimateGasFunc
+	ChangeDetector  ChangeDetector
 }
 
 var _ xc.FullClientWithGas = &NativeClient{}
@@ -80,6 +81,11 @@ func NewNativeClient(cfgI xc.ITask) (*NativeClient, error) {
 	opts := DefaultClientOptions()
 	httpClient := http.Client{}
 	httpClient.Timeout = opts.Timeout
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return &NativeClient{}, err
+	}
+	httpClient.Transport = transport
 	opts.Host = cfg.URL
 	params, err := GetParams(cfg)
 	if err != nil {
@@ -110,14 +116,42 @@ func (client *NativeClient) FetchTxInput(ctx context.Context, from xc.Address, t
 	return input, nil
 }
 
+// testMempoolAcceptResult is the result of testmempoolaccept of a tx
+type testMempoolAcceptResult struct {
+	TxID         string `json:"txid"`
+	Allowed      bool   `json:"allowed"`
+	RejectReason string `json:"reject-reason"`
+}
+
 // SubmitTx submits a Bitcoin tx
 func (client *NativeClient) SubmitTx(ctx context.Context, txInput xc.Tx) error {
-	serial, err := txInput.Serialize()
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	var serial string
+	err := xc.SerializePooled(txInput, func(serialized []byte) error {
+		serial = hex.EncodeToString(serialized)
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("bad tx: %v", err)
 	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		accepted := []testMempoolAcceptResult{}
+		if err := client.send(ctx, &accepted, "testmempoolaccept", []string{serial}); err != nil {
+			return fmt.Errorf("bad \"testmempoolaccept\": %v", err)
+		}
+		if len(accepted) != 1 || !accepted[0].Allowed {
+			reason := ""
+			if len(accepted) == 1 {
+				reason = accepted[0].RejectReason
+			}
+			return fmt.Errorf("tx rejected by \"testmempoolaccept\": %s", reason)
+		}
+		return xc.RecordDryRun(ctx, client.Asset, txInput, true)
+	}
 	resp := ""
-	if err := client.send(ctx, &resp, "sendrawtransaction", hex.EncodeToString(serial)); err != nil {
+	if err := client.send(ctx, &resp, "sendrawtransaction", serial); err != nil {
 		return fmt.Errorf("bad \"sendrawtransaction\": %v", err)
 	}
 	return nil
@@ -188,7 +222,7 @@ func (client *NativeClient) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (
 	// detect from address
 	// single input: from is the address of the single input = unspent output
 	// multiple inputs: from is the address of the unspent output with highest value
-	from, totalIn := tx.DetectFrom()
+	from, _ := tx.DetectFrom()
 
 	// detect recipient addresses and fields: to, amount
 	// two outputs:
@@ -196,6 +230,7 @@ func (client *NativeClient) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (
 	// - amount is the value received
 	// more outputs: not really well defined, currently the last recipient
 	outputs, _ := tx.Outputs()
+	change := []*xc.TxInfoEndpoint{}
 	for _, output := range outputs {
 		value := output.Value
 		_, addresses, _, err := txscript.ExtractPkScriptAddrs(output.PubKeyScript, client.opts.Chaincfg)
@@ -208,20 +243,25 @@ func (client *NativeClient) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (
 			Value: value,
 		}
 		tx.recipients = append(tx.recipients, recipient)
-		destinations = append(destinations, &xc.TxInfoEndpoint{
+		endpoint := &xc.TxInfoEndpoint{
 			Address:         xc.Address(recipientAddr),
 			ContractAddress: "",
 			Amount:          value,
 			NativeAsset:     client.Asset.NativeAsset,
 			Asset:           xc.Asset(client.Asset.NativeAsset),
 			AssetConfig:     client.Asset,
-		})
+		}
+		if tx.IsChange(endpoint.Address, client.ChangeDetector) {
+			change = append(change, endpoint)
+		} else {
+			destinations = append(destinations, endpoint)
+		}
 	}
 
-	to, amount, totalOut := tx.DetectToAndAmount(from, expectedTo)
-	if resp.Fee == 0 && totalIn.Cmp(&totalOut) > 0 {
-		newfee := totalIn.Sub(&totalOut)
-		fee = (*big.Int)(&newfee)
+	to, amount, _ := tx.DetectToAndAmountWithChange(from, expectedTo, client.ChangeDetector)
+	// the fee actually paid is the inputs minus the outputs, gettransaction only reports the fee of wallet txs
+	if actual, err := tx.fee(); err == nil && len(tx.input.Inputs) > 0 && actual >= 0 {
+		fee = big.NewInt(actual)
 	}
 
 	return xc.TxInfo{
@@ -235,6 +275,7 @@ func (client *NativeClient) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (
 		BlockTime:     resp.BlockTime,
 		Sources:       sources,
 		Destinations:  destinations,
+		Change:        change,
 		TxID:          resp.TxID,
 		Time:          resp.Time,
 		TimeReceived:  resp.TimeReceived,
@@ -434,7 +475,29 @@ func (client *NativeClient) RegisterEstimateGasCallback(estimateGas xc.EstimateG
 	client.EstimateGasFunc = estimateGas
 }
 
+// RegisterChangeDetector registers a callback recognizing the sender's own addresses,
+// so that outputs to them are reported as change rather than destinations
+func (client *NativeClient) RegisterChangeDetector(detector ChangeDetector) {
+	client.ChangeDetector = detector
+}
+
+var _ xc.FeeEstimator = &NativeClient{}
+
+// EstimateFee estimates the fee of tx at the estimated fee rate
+func (client *NativeClient) EstimateFee(ctx context.Context, _ xc.Address, tx xc.Tx) (xc.AmountBlockchain, error) {
+	return estimateFee(ctx, client, client.Asset, tx)
+}
+
+// EstimateGas estimates the fee rate in sats per byte, floored to the configured min fee rate
 func (client *NativeClient) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	feeRate, err := client.estimateGas(ctx)
+	if err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), err
+	}
+	return feeRate.ApplyFloor(minFeeRate(client.Asset)), nil
+}
+
+func (client *NativeClient) estimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
 	// invoke EstimateGasFunc callback, if registered
 	if client.EstimateGasFunc != nil {
 		nativeAsset := client.Asset.NativeAsset
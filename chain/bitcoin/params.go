@@ -1,9 +1,13 @@
 package bitcoin
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
 	xc "github.com/jumpcrypto/crosschain"
 )
 
@@ -56,16 +60,116 @@ func (n *NetworkTriple) GetParams(network string) *chaincfg.Params {
 	}
 }
 
+// GetParams returns the network params of a Bitcoin-family chain, from its UTXO config, see xc.GetUTXO
+// The params of a config are created once, and the bech32 HRP of their segwit addresses is registered to be decoded
 func GetParams(cfg *xc.AssetConfig) (*chaincfg.Params, error) {
-	switch cfg.NativeAsset {
-	case xc.BTC, xc.BCH:
-		return BtcNetworks.GetParams(cfg.Net), nil
-	case xc.DOGE:
-		return DogeNetworks.GetParams(cfg.Net), nil
-	case xc.LTC:
-		return LtcNetworks.GetParams(cfg.Net), nil
-	}
-	return &chaincfg.Params{}, errors.New("unsupported utxo asset: " + string(cfg.NativeAsset))
+	utxo := cfg.GetUTXO()
+	if utxo.PubKeyHashAddrID == nil || utxo.ScriptHashAddrID == nil {
+		return &chaincfg.Params{}, errors.New("unsupported utxo asset: " + string(cfg.NativeAsset))
+	}
+	hdPublicKeyID, err := parseHDKeyID(utxo.HDPublicKeyID)
+	if err != nil {
+		return &chaincfg.Params{}, fmt.Errorf("invalid hd_public_key_id: %v", err)
+	}
+	hdPrivateKeyID, err := parseHDKeyID(utxo.HDPrivateKeyID)
+	if err != nil {
+		return &chaincfg.Params{}, fmt.Errorf("invalid hd_private_key_id: %v", err)
+	}
+	key := paramsKey{
+		pubKeyHashAddrID: *utxo.PubKeyHashAddrID,
+		scriptHashAddrID: *utxo.ScriptHashAddrID,
+		bech32HRP:        utxo.Bech32HRP,
+		hdPublicKeyID:    hdPublicKeyID,
+		hdPrivateKeyID:   hdPrivateKeyID,
+		cashAddrPrefix:   utxo.CashAddrPrefix,
+	}
+	if utxo.PrivateKeyID != nil {
+		key.privateKeyID = *utxo.PrivateKeyID
+	}
+
+	paramsMutex.Lock()
+	defer paramsMutex.Unlock()
+	if params, ok := paramsByKey[key]; ok {
+		return params, nil
+	}
+	var params *chaincfg.Params
+	for _, known := range knownParams {
+		if key.matches(known) {
+			params = known
+			break
+		}
+	}
+	if params == nil {
+		kind := cfg.Net.Kind()
+		if kind != xc.Mainnet && kind != xc.Testnet {
+			kind = xc.Regtest
+		}
+		params = &chaincfg.Params{
+			Name:             string(kind),
+			Net:              wire.BitcoinNet(utxo.NetMagic),
+			PubKeyHashAddrID: key.pubKeyHashAddrID,
+			ScriptHashAddrID: key.scriptHashAddrID,
+			PrivateKeyID:     key.privateKeyID,
+			HDPublicKeyID:    hdPublicKeyID,
+			HDPrivateKeyID:   hdPrivateKeyID,
+			Bech32HRPSegwit:  utxo.Bech32HRP,
+		}
+	}
+	registerBech32HRP(params)
+	if key.cashAddrPrefix != "" {
+		cashAddrPrefixes.Store(params, key.cashAddrPrefix)
+	}
+	paramsByKey[key] = params
+	return params, nil
+}
+
+// paramsKey identifies the params of a chain by the fields of its UTXO config
+type paramsKey struct {
+	pubKeyHashAddrID byte
+	scriptHashAddrID byte
+	privateKeyID     byte
+	bech32HRP        string
+	hdPublicKeyID    [4]byte
+	hdPrivateKeyID   [4]byte
+	cashAddrPrefix   string
+}
+
+func (key paramsKey) matches(params *chaincfg.Params) bool {
+	return key.pubKeyHashAddrID == params.PubKeyHashAddrID && key.scriptHashAddrID == params.ScriptHashAddrID &&
+		key.privateKeyID == params.PrivateKeyID && key.bech32HRP == params.Bech32HRPSegwit &&
+		key.hdPublicKeyID == params.HDPublicKeyID && key.hdPrivateKeyID == params.HDPrivateKeyID
+}
+
+var (
+	paramsMutex sync.Mutex
+	paramsByKey = map[paramsKey]*chaincfg.Params{}
+	// knownParams are reused by the chains of the same config, e.g. by Bitcoin with the params of btcd
+	knownParams = []*chaincfg.Params{
+		&chaincfg.MainNetParams, &chaincfg.TestNet3Params, &chaincfg.RegressionNetParams,
+		DogeNetworks.Mainnet, DogeNetworks.Testnet, DogeNetworks.Regtest,
+		LtcNetworks.Mainnet, LtcNetworks.Testnet, LtcNetworks.Regtest,
+	}
+)
+
+func parseHDKeyID(id string) ([4]byte, error) {
+	decoded, err := hex.DecodeString(id)
+	if err != nil || len(decoded) != 4 {
+		return [4]byte{}, fmt.Errorf("expected 4 hex bytes, got '%s'", id)
+	}
+	return [4]byte{decoded[0], decoded[1], decoded[2], decoded[3]}, nil
+}
+
+// registerBech32HRP registers the HRP of params, so that btcutil decodes their segwit addresses
+// Only the HRP of the registered params matters: their magic is changed if it's taken, e.g. by the regtest of
+// another chain
+func registerBech32HRP(params *chaincfg.Params) {
+	if params.Bech32HRPSegwit == "" || chaincfg.IsBech32SegwitPrefix(params.Bech32HRPSegwit+"1") {
+		return
+	}
+	registered := *params
+	for chaincfg.Register(&registered) == chaincfg.ErrDuplicateNet {
+		registered.Net++
+	}
 }
 
 var BtcNetworks *NetworkTriple = &NetworkTriple{
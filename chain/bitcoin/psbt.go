@@ -0,0 +1,438 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// psbtMagic prefixes PSBTs, "psbt" and a separator
+var psbtMagic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// Key types of BIP-174 used by Tx
+const (
+	psbtGlobalUnsignedTx     = 0x00
+	psbtInNonWitnessUtxo     = 0x00
+	psbtInWitnessUtxo        = 0x01
+	psbtInPartialSig         = 0x02
+	psbtInSighashType        = 0x03
+	psbtInRedeemScript       = 0x04
+	psbtInWitnessScript      = 0x05
+	psbtInFinalScriptSig     = 0x07
+	psbtInFinalScriptWitness = 0x08
+)
+
+// maxPSBTValueSize bounds the values read from a PSBT, e.g. a previous tx
+const maxPSBTValueSize = 4_000_000
+
+// maxWitnessItems bounds the items of a witness read from a PSBT, the max stack size of scripts
+const maxWitnessItems = 1000
+
+// psbtPair is a key-value pair of a PSBT map
+type psbtPair struct {
+	keyType byte
+	keyData []byte
+	value   []byte
+}
+
+// psbtInput is the PSBT map of an input, without unknown keys
+type psbtInput struct {
+	nonWitnessUtxo *wire.MsgTx
+	witnessUtxo    *wire.TxOut
+	// partialSigs are DER signatures with their sighash type, by public key
+	partialSigs        map[string][]byte
+	redeemScript       []byte
+	witnessScript      []byte
+	finalScriptSig     []byte
+	finalScriptWitness wire.TxWitness
+}
+
+// SerializePSBT returns the tx as a PSBT (BIP-174), e.g. to hand it to an external signer
+// Segwit inputs have a witness UTXO, non-segwit inputs the non-witness UTXO of their PreviousTx, or a witness
+// UTXO if it isn't known, and every input its redeem or witness script. Signed inputs are finalized.
+func (tx *Tx) SerializePSBT() ([]byte, error) {
+	unsignedTx := bytes.NewBuffer(nil)
+	if err := tx.unsignedMsgTx().SerializeNoWitness(unsignedTx); err != nil {
+		return nil, err
+	}
+
+	w := bytes.NewBuffer(nil)
+	w.Write(psbtMagic)
+	if err := writePSBTPair(w, psbtGlobalUnsignedTx, nil, unsignedTx.Bytes()); err != nil {
+		return nil, err
+	}
+	w.WriteByte(0x00)
+
+	for i, txIn := range tx.msgTx.TxIn {
+		if i >= len(tx.input.Inputs) {
+			return nil, fmt.Errorf("no utxo of input %d", i)
+		}
+		input := tx.input.Inputs[i]
+		if !isSegwit(input) && len(input.PreviousTx) > 0 {
+			if err := checkPreviousTx(input.PreviousTx, txIn.PreviousOutPoint); err != nil {
+				return nil, fmt.Errorf("previous tx of input %d: %v", i, err)
+			}
+			if err := writePSBTPair(w, psbtInNonWitnessUtxo, nil, input.PreviousTx); err != nil {
+				return nil, err
+			}
+		} else {
+			utxo := bytes.NewBuffer(nil)
+			if err := wire.WriteTxOut(utxo, 0, 0, wire.NewTxOut(input.Value.Int().Int64(), input.PubKeyScript)); err != nil {
+				return nil, err
+			}
+			if err := writePSBTPair(w, psbtInWitnessUtxo, nil, utxo.Bytes()); err != nil {
+				return nil, err
+			}
+		}
+		if tx.isInputSigned(i) {
+			if len(txIn.SignatureScript) > 0 {
+				if err := writePSBTPair(w, psbtInFinalScriptSig, nil, txIn.SignatureScript); err != nil {
+					return nil, err
+				}
+			}
+			if len(txIn.Witness) > 0 {
+				witness := bytes.NewBuffer(nil)
+				if err := writeWitness(witness, txIn.Witness); err != nil {
+					return nil, err
+				}
+				if err := writePSBTPair(w, psbtInFinalScriptWitness, nil, witness.Bytes()); err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			sighashType := make([]byte, 4)
+			binary.LittleEndian.PutUint32(sighashType, uint32(tx.sigHashType()))
+			if err := writePSBTPair(w, psbtInSighashType, nil, sighashType); err != nil {
+				return nil, err
+			}
+			if input.SigScript != nil {
+				keyType := byte(psbtInWitnessScript)
+				if txscript.IsPayToScriptHash(input.PubKeyScript) {
+					keyType = psbtInRedeemScript
+				}
+				if err := writePSBTPair(w, keyType, nil, input.SigScript); err != nil {
+					return nil, err
+				}
+			}
+		}
+		w.WriteByte(0x00)
+	}
+	for range tx.msgTx.TxOut {
+		w.WriteByte(0x00)
+	}
+	return w.Bytes(), nil
+}
+
+// NewTxFromPSBT creates a Tx from a PSBT, e.g. created by another wallet, whose partial signatures are added
+func (txBuilder TxBuilder) NewTxFromPSBT(data []byte) (*Tx, error) {
+	msgTx, inputs, err := parsePSBT(data)
+	if err != nil {
+		return nil, err
+	}
+	tx := &Tx{
+		msgTx:      msgTx,
+		input:      *NewTxInput(),
+		recipients: []Recipient{},
+		isBch:      txBuilder.isBch,
+	}
+	for i, txIn := range msgTx.TxIn {
+		utxo := inputs[i].witnessUtxo
+		var previousTx []byte
+		if utxo == nil && inputs[i].nonWitnessUtxo != nil {
+			previous := inputs[i].nonWitnessUtxo
+			if previous.TxHash() != txIn.PreviousOutPoint.Hash || int(txIn.PreviousOutPoint.Index) >= len(previous.TxOut) {
+				return nil, fmt.Errorf("non-witness utxo of input %d doesn't match its outpoint", i)
+			}
+			utxo = previous.TxOut[txIn.PreviousOutPoint.Index]
+			serialized := bytes.NewBuffer(nil)
+			if err := previous.Serialize(serialized); err != nil {
+				return nil, err
+			}
+			previousTx = serialized.Bytes()
+		}
+		if utxo == nil {
+			return nil, fmt.Errorf("no utxo of input %d", i)
+		}
+		input := Input{
+			Output: Output{
+				Outpoint: Outpoint{
+					Hash:  append([]byte{}, txIn.PreviousOutPoint.Hash[:]...),
+					Index: txIn.PreviousOutPoint.Index,
+				},
+				Value:        xc.NewAmountBlockchainFromUint64(uint64(utxo.Value)),
+				PubKeyScript: utxo.PkScript,
+				PreviousTx:   previousTx,
+			},
+			Address: txBuilder.scriptAddress(utxo.PkScript),
+		}
+		if inputs[i].redeemScript != nil {
+			input.SigScript = inputs[i].redeemScript
+		} else if inputs[i].witnessScript != nil {
+			input.SigScript = inputs[i].witnessScript
+		}
+		tx.input.Inputs = append(tx.input.Inputs, input)
+	}
+	for _, txOut := range msgTx.TxOut {
+		tx.recipients = append(tx.recipients, Recipient{
+			To:    txBuilder.scriptAddress(txOut.PkScript),
+			Value: xc.NewAmountBlockchainFromUint64(uint64(txOut.Value)),
+		})
+	}
+	if err := tx.mergePSBTInputs(inputs); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// MergePSBT adds the signatures of a PSBT of the tx, e.g. returned by an external signer
+func (tx *Tx) MergePSBT(data []byte) error {
+	msgTx, inputs, err := parsePSBT(data)
+	if err != nil {
+		return err
+	}
+	if msgTx.TxHash() != tx.unsignedMsgTx().TxHash() {
+		return errors.New("psbt is of another tx")
+	}
+	return tx.mergePSBTInputs(inputs)
+}
+
+// mergePSBTInputs adds the final scripts and partial signatures of inputs, skipping signed inputs
+// Partial signatures must verify against the sighash of their input
+func (tx *Tx) mergePSBTInputs(inputs []*psbtInput) error {
+	for i, input := range inputs {
+		if tx.isInputSigned(i) {
+			continue
+		}
+		if input.finalScriptSig != nil || input.finalScriptWitness != nil {
+			tx.msgTx.TxIn[i].SignatureScript = input.finalScriptSig
+			tx.msgTx.TxIn[i].Witness = input.finalScriptWitness
+			tx.setInputSigned(i)
+			continue
+		}
+		// inputs of Tx have a single signer
+		for publicKey, sig := range input.partialSigs {
+			if len(sig) == 0 {
+				return fmt.Errorf("empty partial signature of input %d", i)
+			}
+			signature, err := btcec.ParseDERSignature(sig[:len(sig)-1], btcec.S256())
+			if err != nil {
+				return fmt.Errorf("invalid partial signature of input %d: %v", i, err)
+			}
+			if len(tx.input.FromPublicKey) > 0 && !bytes.Equal(tx.input.FromPublicKey, []byte(publicKey)) {
+				return fmt.Errorf("partial signature of input %d is of another public key", i)
+			}
+			key, err := btcec.ParsePubKey([]byte(publicKey), btcec.S256())
+			if err != nil {
+				return fmt.Errorf("invalid public key of input %d: %v", i, err)
+			}
+			sighash, err := tx.Sighash(i)
+			if err != nil {
+				return err
+			}
+			if !signature.Verify(sighash, key) {
+				return fmt.Errorf("partial signature of input %d doesn't verify", i)
+			}
+			if len(tx.input.FromPublicKey) == 0 {
+				tx.input.FromPublicKey = []byte(publicKey)
+			}
+			if err := tx.addInputSignature(i, signature, []byte(publicKey)); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// isSegwit returns true if the input is spent with a witness, natively or nested in a P2SH script
+func isSegwit(input Input) bool {
+	return txscript.IsWitnessProgram(input.PubKeyScript) || (input.SigScript != nil && txscript.IsWitnessProgram(input.SigScript))
+}
+
+// checkPreviousTx checks that a serialized tx is the previous tx of an outpoint
+func checkPreviousTx(previousTx []byte, outpoint wire.OutPoint) error {
+	previous := wire.NewMsgTx(TxVersion)
+	if err := previous.Deserialize(bytes.NewReader(previousTx)); err != nil {
+		return err
+	}
+	if previous.TxHash() != outpoint.Hash || int(outpoint.Index) >= len(previous.TxOut) {
+		return errors.New("doesn't match its outpoint")
+	}
+	return nil
+}
+
+// isInputSigned returns true if the input at index is signed
+func (tx *Tx) isInputSigned(index int) bool {
+	if tx.signed {
+		return true
+	}
+	return index < len(tx.signedInputs) && tx.signedInputs[index]
+}
+
+// unsignedMsgTx returns the tx without script sigs and witnesses, the unsigned tx of its PSBT
+func (tx *Tx) unsignedMsgTx() *wire.MsgTx {
+	unsigned := tx.msgTx.Copy()
+	for _, txIn := range unsigned.TxIn {
+		txIn.SignatureScript = nil
+		txIn.Witness = nil
+	}
+	return unsigned
+}
+
+// scriptAddress returns the address of a pubkey script, or an empty address for non-standard scripts
+func (txBuilder TxBuilder) scriptAddress(pkScript []byte) xc.Address {
+	_, addresses, _, err := txscript.ExtractPkScriptAddrs(pkScript, txBuilder.Params)
+	if err != nil || len(addresses) != 1 {
+		return ""
+	}
+	return xc.Address(addresses[0].EncodeAddress())
+}
+
+// parsePSBT returns the unsigned tx and the input maps of a PSBT
+func parsePSBT(data []byte) (*wire.MsgTx, []*psbtInput, error) {
+	if !bytes.HasPrefix(data, psbtMagic) {
+		return nil, nil, errors.New("invalid psbt magic")
+	}
+	r := bytes.NewReader(data[len(psbtMagic):])
+
+	global, err := readPSBTMap(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid psbt global map: %v", err)
+	}
+	var msgTx *wire.MsgTx
+	for _, pair := range global {
+		if pair.keyType == psbtGlobalUnsignedTx {
+			msgTx = wire.NewMsgTx(TxVersion)
+			if err := msgTx.DeserializeNoWitness(bytes.NewReader(pair.value)); err != nil {
+				return nil, nil, fmt.Errorf("invalid psbt unsigned tx: %v", err)
+			}
+		}
+	}
+	if msgTx == nil {
+		return nil, nil, errors.New("psbt has no unsigned tx")
+	}
+	for _, txIn := range msgTx.TxIn {
+		if len(txIn.SignatureScript) > 0 || len(txIn.Witness) > 0 {
+			return nil, nil, errors.New("psbt unsigned tx has script sigs")
+		}
+	}
+
+	inputs := make([]*psbtInput, len(msgTx.TxIn))
+	for i := range inputs {
+		pairs, err := readPSBTMap(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid psbt input %d: %v", i, err)
+		}
+		input := &psbtInput{partialSigs: map[string][]byte{}}
+		for _, pair := range pairs {
+			switch pair.keyType {
+			case psbtInNonWitnessUtxo:
+				input.nonWitnessUtxo = wire.NewMsgTx(TxVersion)
+				if err := input.nonWitnessUtxo.Deserialize(bytes.NewReader(pair.value)); err != nil {
+					return nil, nil, fmt.Errorf("invalid non-witness utxo of input %d: %v", i, err)
+				}
+			case psbtInWitnessUtxo:
+				input.witnessUtxo, err = readTxOut(bytes.NewReader(pair.value))
+				if err != nil {
+					return nil, nil, fmt.Errorf("invalid witness utxo of input %d: %v", i, err)
+				}
+			case psbtInPartialSig:
+				input.partialSigs[string(pair.keyData)] = pair.value
+			case psbtInRedeemScript:
+				input.redeemScript = pair.value
+			case psbtInWitnessScript:
+				input.witnessScript = pair.value
+			case psbtInFinalScriptSig:
+				input.finalScriptSig = pair.value
+			case psbtInFinalScriptWitness:
+				input.finalScriptWitness, err = readWitness(bytes.NewReader(pair.value))
+				if err != nil {
+					return nil, nil, fmt.Errorf("invalid final witness of input %d: %v", i, err)
+				}
+			}
+		}
+		inputs[i] = input
+	}
+	for i := range msgTx.TxOut {
+		if _, err := readPSBTMap(r); err != nil {
+			return nil, nil, fmt.Errorf("invalid psbt output %d: %v", i, err)
+		}
+	}
+	return msgTx, inputs, nil
+}
+
+// readPSBTMap reads the key-value pairs of a map, up to its separator
+func readPSBTMap(r io.Reader) ([]psbtPair, error) {
+	pairs := []psbtPair{}
+	for {
+		key, err := wire.ReadVarBytes(r, 0, maxPSBTValueSize, "key")
+		if err != nil {
+			return nil, err
+		}
+		if len(key) == 0 {
+			return pairs, nil
+		}
+		value, err := wire.ReadVarBytes(r, 0, maxPSBTValueSize, "value")
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, psbtPair{keyType: key[0], keyData: key[1:], value: value})
+	}
+}
+
+func writePSBTPair(w io.Writer, keyType byte, keyData []byte, value []byte) error {
+	if err := wire.WriteVarBytes(w, 0, append([]byte{keyType}, keyData...)); err != nil {
+		return err
+	}
+	return wire.WriteVarBytes(w, 0, value)
+}
+
+// readTxOut reads a serialized tx output, as written by wire.WriteTxOut
+func readTxOut(r io.Reader) (*wire.TxOut, error) {
+	value := make([]byte, 8)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return nil, err
+	}
+	pkScript, err := wire.ReadVarBytes(r, 0, maxPSBTValueSize, "pkScript")
+	if err != nil {
+		return nil, err
+	}
+	return wire.NewTxOut(int64(binary.LittleEndian.Uint64(value)), pkScript), nil
+}
+
+func writeWitness(w io.Writer, witness wire.TxWitness) error {
+	if err := wire.WriteVarInt(w, 0, uint64(len(witness))); err != nil {
+		return err
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(w, 0, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readWitness(r io.Reader) (wire.TxWitness, error) {
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > maxWitnessItems {
+		return nil, fmt.Errorf("too many witness items, max %d", maxWitnessItems)
+	}
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(r, 0, maxPSBTValueSize, "witness item")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return witness, nil
+}
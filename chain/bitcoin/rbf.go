@@ -0,0 +1,121 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// ReplaceByFeeSequence is the sequence of inputs signaling replaceability (BIP 125), as set by Bitcoin Core
+const ReplaceByFeeSequence = wire.MaxTxInSequenceNum - 2
+
+// SignalsReplaceByFee returns true if an input of the tx signals replaceability, so the tx can be replaced with BumpFee
+func (tx *Tx) SignalsReplaceByFee() bool {
+	for _, txIn := range tx.msgTx.TxIn {
+		if txIn.Sequence < wire.MaxTxInSequenceNum-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// fee returns the inputs minus the outputs of the tx
+func (tx *Tx) fee() (int64, error) {
+	if len(tx.input.Inputs) != len(tx.msgTx.TxIn) {
+		return 0, errors.New("missing the values of the inputs")
+	}
+	fee := int64(0)
+	for _, input := range tx.input.Inputs {
+		fee += input.Value.Int().Int64()
+	}
+	for _, txOut := range tx.msgTx.TxOut {
+		fee -= txOut.Value
+	}
+	return fee, nil
+}
+
+// changeIndex returns the index of the change output of the tx, or -1
+func (tx *Tx) changeIndex() int {
+	for i := len(tx.recipients) - 1; i >= 0; i-- {
+		to := tx.recipients[i].To
+		if to != tx.to && (to == tx.from || tx.IsChange(to, nil)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// BumpFee rebuilds oldTx, a pending tx signaling replaceability, with the same inputs and outputs at feeRate, in sats per byte
+// The fee increase is taken from the change, whose output is dropped if it falls below the dust threshold
+// The new tx is unsigned and also signals replaceability, so its fee can be bumped again
+func (txBuilder TxBuilder) BumpFee(oldTx xc.Tx, feeRate xc.AmountBlockchain) (xc.Tx, error) {
+	if txBuilder.isBch {
+		return nil, errors.New("bch does not support replace-by-fee")
+	}
+	old, ok := oldTx.(*Tx)
+	if !ok || old.msgTx == nil {
+		return nil, errors.New("tx is not a bitcoin tx")
+	}
+	if !old.SignalsReplaceByFee() {
+		return nil, fmt.Errorf("tx %s does not signal replace-by-fee", old.Hash())
+	}
+	oldFee, err := old.fee()
+	if err != nil {
+		return nil, fmt.Errorf("could not compute the fee of tx %s: %v", old.Hash(), err)
+	}
+	// the replacement has the inputs of oldTx
+	fee := txBuilder.CoinSelector.EstimateFee(old, feeRate).Int().Int64()
+	if fee <= oldFee {
+		return nil, fmt.Errorf("fee of %d at the new fee rate must exceed the fee of %d of tx %s", fee, oldFee, old.Hash())
+	}
+	change := old.changeIndex()
+	if change < 0 || change >= len(old.msgTx.TxOut) {
+		return nil, fmt.Errorf("tx %s has no change to bump its fee", old.Hash())
+	}
+	changeValue := old.msgTx.TxOut[change].Value - (fee - oldFee)
+	if changeValue < 0 {
+		return nil, fmt.Errorf("change of %d of tx %s can't cover a fee increase of %d", old.msgTx.TxOut[change].Value, old.Hash(), fee-oldFee)
+	}
+
+	msgTx := wire.NewMsgTx(old.msgTx.Version)
+	msgTx.LockTime = old.msgTx.LockTime
+	for _, txIn := range old.msgTx.TxIn {
+		newTxIn := wire.NewTxIn(&txIn.PreviousOutPoint, nil, nil)
+		newTxIn.Sequence = ReplaceByFeeSequence
+		msgTx.AddTxIn(newTxIn)
+	}
+	recipients := []Recipient{}
+	for i, txOut := range old.msgTx.TxOut {
+		value := txOut.Value
+		if i == change {
+			// change below the dust threshold is left to the fee
+			if changeValue < txBuilder.CoinSelector.DustThreshold {
+				continue
+			}
+			value = changeValue
+		}
+		msgTx.AddTxOut(wire.NewTxOut(value, txOut.PkScript))
+		if i < len(old.recipients) {
+			recipients = append(recipients, Recipient{
+				To:    old.recipients[i].To,
+				Value: xc.NewAmountBlockchainFromUint64(uint64(value)),
+			})
+		}
+	}
+
+	input := old.input
+	input.GasPricePerByte = feeRate
+	return &Tx{
+		msgTx: msgTx,
+
+		from:   old.from,
+		to:     old.to,
+		amount: old.amount,
+		input:  input,
+
+		recipients: recipients,
+		isBch:      old.isBch,
+	}, nil
+}
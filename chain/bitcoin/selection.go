@@ -0,0 +1,215 @@
+package bitcoin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// CoinSelectionStrategy is how the inputs of a transfer are selected among the unspent outputs
+type CoinSelectionStrategy string
+
+// List of CoinSelectionStrategy
+const (
+	// CoinSelectionLargestFirst spends the largest outputs first, then consolidates small outputs up to MinInputs
+	CoinSelectionLargestFirst CoinSelectionStrategy = "largest-first"
+	// CoinSelectionBranchAndBound searches for inputs matching the amount without change, else spends the largest first
+	CoinSelectionBranchAndBound CoinSelectionStrategy = "branch-and-bound"
+)
+
+// DefaultDustThreshold is the smallest output relayed by nodes, in sats
+const DefaultDustThreshold = 546
+
+// DefaultMinInputs is the number of inputs largest first consolidates small outputs up to
+const DefaultMinInputs = 10
+
+// branchAndBoundMaxTries bounds the search of branch and bound, as in Bitcoin Core
+const branchAndBoundMaxTries = 100_000
+
+// CoinSelector selects the inputs of a transfer and computes its fee and change
+type CoinSelector struct {
+	Strategy CoinSelectionStrategy
+	// DustThreshold is the smallest change output, smaller change is added to the fee
+	DustThreshold int64
+	// InputSize is the estimated size in bytes added to a tx by an input, including its share of the outputs
+	InputSize int64
+	// MinInputs is the number of inputs largest first consolidates small outputs up to,
+	// only spending outputs worth more than their fee
+	MinInputs int
+}
+
+// CoinSelection is the inputs of a transfer, their total value, the fee and the change
+type CoinSelection struct {
+	Inputs []Input
+	Total  xc.AmountBlockchain
+	Fee    xc.AmountBlockchain
+	// Change is zero if it's below the dust threshold, and then added to Fee
+	Change xc.AmountBlockchain
+}
+
+// NewCoinSelector returns the CoinSelector of a chain, with the coin_selection and dust_threshold of asset, if set
+func NewCoinSelector(asset *xc.NativeAssetConfig) CoinSelector {
+	utxo := asset.GetUTXO()
+	selector := CoinSelector{
+		Strategy:      CoinSelectionLargestFirst,
+		DustThreshold: DefaultDustThreshold,
+		InputSize:     utxo.InputSize,
+		MinInputs:     DefaultMinInputs,
+	}
+	if utxo.DustThreshold > 0 {
+		selector.DustThreshold = int64(utxo.DustThreshold)
+	}
+	if asset.CoinSelection != "" {
+		selector.Strategy = CoinSelectionStrategy(asset.CoinSelection)
+	}
+	if asset.DustThreshold > 0 {
+		selector.DustThreshold = int64(asset.DustThreshold)
+	}
+	return selector
+}
+
+// Select selects inputs among unspentOutputs to transfer amount at feeRate, in sats per byte
+func (selector CoinSelector) Select(unspentOutputs []Output, amount xc.AmountBlockchain, feeRate xc.AmountBlockchain) (*CoinSelection, error) {
+	target := amount.Int().Int64()
+	inputFee := feeRate.Int().Int64() * selector.InputSize
+
+	outputs := make([]Output, len(unspentOutputs))
+	copy(outputs, unspentOutputs)
+	sort.SliceStable(outputs, func(i, j int) bool {
+		return outputs[i].Value.Cmp(&outputs[j].Value) > 0
+	})
+
+	var selected []Output
+	switch selector.Strategy {
+	case CoinSelectionBranchAndBound:
+		selected = selector.branchAndBound(outputs, target, inputFee)
+		if selected == nil {
+			selected = selector.largestFirst(outputs, target, inputFee)
+		}
+	case CoinSelectionLargestFirst, "":
+		selected = selector.largestFirst(outputs, target, inputFee)
+	default:
+		return nil, fmt.Errorf("unknown coin selection strategy: %s", selector.Strategy)
+	}
+
+	total := int64(0)
+	inputs := []Input{}
+	for _, output := range selected {
+		total += output.Value.Int().Int64()
+		inputs = append(inputs, Input{Output: output})
+	}
+	fee := inputFee * int64(len(inputs))
+	change := total - target - fee
+	if change < 0 {
+		return nil, fmt.Errorf("insufficient funds: %d available, %d needed including a fee of %d", total, target+fee, fee)
+	}
+	if change < selector.DustThreshold {
+		fee += change
+		change = 0
+	}
+	return &CoinSelection{
+		Inputs: inputs,
+		Total:  xc.NewAmountBlockchainFromUint64(uint64(total)),
+		Fee:    xc.NewAmountBlockchainFromUint64(uint64(fee)),
+		Change: xc.NewAmountBlockchainFromUint64(uint64(change)),
+	}, nil
+}
+
+// largestFirst selects the largest outputs until target and their fee are covered,
+// then the smallest outputs until MinInputs if spending them costs less than their value
+// outputs are sorted from the largest
+func (selector CoinSelector) largestFirst(outputs []Output, target int64, inputFee int64) []Output {
+	selected := []Output{}
+	total := int64(0)
+	next := 0
+	for ; next < len(outputs) && total < target+inputFee*int64(len(selected)); next++ {
+		selected = append(selected, outputs[next])
+		total += outputs[next].Value.Int().Int64()
+	}
+	for i := len(outputs) - 1; i >= next && len(selected) < selector.MinInputs; i-- {
+		if outputs[i].Value.Int().Int64() > inputFee {
+			selected = append(selected, outputs[i])
+		}
+	}
+	return selected
+}
+
+// branchAndBound searches for the outputs whose value after their fee is within the dust threshold above target,
+// so the transfer needs no change, and returns those with the least excess, or nil if none is found
+// outputs are sorted from the largest
+func (selector CoinSelector) branchAndBound(outputs []Output, target int64, inputFee int64) []Output {
+	effective := []int64{}
+	candidates := []Output{}
+	available := int64(0)
+	for _, output := range outputs {
+		value := output.Value.Int().Int64() - inputFee
+		if value > 0 {
+			effective = append(effective, value)
+			candidates = append(candidates, output)
+			available += value
+		}
+	}
+	if available < target {
+		return nil
+	}
+
+	var best []int
+	bestExcess := int64(-1)
+	current := []int{}
+	tries := 0
+	var search func(index int, sum int64, remaining int64)
+	search = func(index int, sum int64, remaining int64) {
+		tries++
+		if tries > branchAndBoundMaxTries || sum+remaining < target || sum >= target+selector.DustThreshold {
+			return
+		}
+		if sum >= target {
+			if excess := sum - target; bestExcess < 0 || excess < bestExcess {
+				best = append([]int{}, current...)
+				bestExcess = excess
+			}
+			return
+		}
+		if index >= len(effective) {
+			return
+		}
+		// include the output first, to find selections of few inputs first
+		current = append(current, index)
+		search(index+1, sum+effective[index], remaining-effective[index])
+		current = current[:len(current)-1]
+		search(index+1, sum, remaining-effective[index])
+	}
+	search(0, 0, available)
+
+	if best == nil {
+		return nil
+	}
+	selected := []Output{}
+	for _, index := range best {
+		selected = append(selected, candidates[index])
+	}
+	return selected
+}
+
+// EstimateFee returns the fee of tx at feeRate, in sats per byte, as estimated when its inputs were selected
+func (selector CoinSelector) EstimateFee(tx *Tx, feeRate xc.AmountBlockchain) xc.AmountBlockchain {
+	fee := feeRate.Int().Int64() * selector.InputSize * int64(len(tx.msgTx.TxIn))
+	return xc.NewAmountBlockchainFromUint64(uint64(fee))
+}
+
+// estimateFee estimates the fee of tx at the fee rate estimated by client, for the clients of asset
+func estimateFee(ctx context.Context, client xc.GasEstimator, asset *xc.AssetConfig, tx xc.Tx) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	btcTx, ok := tx.(*Tx)
+	if !ok || btcTx.msgTx == nil {
+		return zero, errors.New("tx is not a bitcoin tx")
+	}
+	feeRate, err := client.EstimateGas(ctx)
+	if err != nil {
+		return zero, fmt.Errorf("could not estimate fee rate: %v", err)
+	}
+	return NewCoinSelector(asset.GetNativeAsset()).EstimateFee(btcTx, feeRate), nil
+}
@@ -2,6 +2,7 @@ package bitcoin
 
 import (
 	"encoding/hex"
+	"errors"
 
 	"github.com/btcsuite/btcutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -45,3 +46,12 @@ func (signer *Signer) Sign(privateKeyBytes xc.PrivateKey, data xc.TxDataToSign)
 	signatureRaw, err := crypto.Sign([]byte(data), ecdsaKey)
 	return xc.TxSignature(signatureRaw), err
 }
+
+// DerivePublicKey returns the compressed public key of a Bitcoin private key
+func (signer *Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	ecdsaKey, err := crypto.ToECDSA(privateKey)
+	if err != nil {
+		return nil, errors.New("invalid k256 private key")
+	}
+	return xc.PublicKey(crypto.CompressPubkey(&ecdsaKey.PublicKey)), nil
+}
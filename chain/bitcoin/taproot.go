@@ -0,0 +1,108 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/cosmos/btcutil/bech32"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// bech32m checksum constant (BIP-350), used by segwit v1+ addresses
+const bech32mConst = 0x2bc830a3
+
+// TaprootOutputKey returns the x-only output key of a key-path only P2TR output (BIP-86)
+// given a compressed or x-only internal public key
+func TaprootOutputKey(publicKeyBytes []byte) ([]byte, error) {
+	if len(publicKeyBytes) == 32 {
+		publicKeyBytes = append([]byte{0x02}, publicKeyBytes...)
+	}
+	curve := btcec.S256()
+	publicKey, err := btcec.ParsePubKey(publicKeyBytes, curve)
+	if err != nil {
+		return nil, err
+	}
+	// BIP-340 keys are x-only with an implicit even y
+	xOnly := publicKey.X.FillBytes(make([]byte, 32))
+	internalKey, err := btcec.ParsePubKey(append([]byte{0x02}, xOnly...), curve)
+	if err != nil {
+		return nil, err
+	}
+	tweak := chainhash.TaggedHash([]byte("TapTweak"), xOnly)
+	if new(big.Int).SetBytes(tweak[:]).Cmp(curve.N) >= 0 {
+		return nil, errors.New("invalid taproot tweak")
+	}
+	tweakX, tweakY := curve.ScalarBaseMult(tweak[:])
+	outputX, _ := curve.Add(internalKey.X, internalKey.Y, tweakX, tweakY)
+	return outputX.FillBytes(make([]byte, 32)), nil
+}
+
+// NewTaprootAddress returns the bech32m P2TR address of an internal public key, without script path
+func NewTaprootAddress(publicKeyBytes []byte, params *chaincfg.Params) (xc.Address, error) {
+	outputKey, err := TaprootOutputKey(publicKeyBytes)
+	if err != nil {
+		return "", err
+	}
+	program, err := bech32.ConvertBits(outputKey, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	// witness version 1
+	data := append([]byte{1}, program...)
+	address, err := encodeBech32m(params.Bech32HRPSegwit, data)
+	if err != nil {
+		return "", err
+	}
+	return xc.Address(address), nil
+}
+
+func encodeBech32m(hrp string, data []byte) (string, error) {
+	if hrp == "" {
+		return "", fmt.Errorf("segwit is not supported")
+	}
+	values := append(bech32HrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ bech32mConst
+	var encoded strings.Builder
+	encoded.WriteString(hrp)
+	encoded.WriteString("1")
+	for _, value := range data {
+		encoded.WriteByte(Alphabet[value])
+	}
+	for i := 0; i < 6; i++ {
+		encoded.WriteByte(Alphabet[(mod>>uint(5*(5-i)))&31])
+	}
+	return encoded.String(), nil
+}
+
+func bech32HrpExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for _, c := range hrp {
+		expanded = append(expanded, byte(c>>5))
+	}
+	expanded = append(expanded, 0)
+	for _, c := range hrp {
+		expanded = append(expanded, byte(c&31))
+	}
+	return expanded
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := []uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, value := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(value)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
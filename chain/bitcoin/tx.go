@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 
 	"github.com/btcsuite/btcd/btcec"
@@ -25,6 +26,9 @@ type Output struct {
 	Outpoint     `json:"outpoint"`
 	Value        xc.AmountBlockchain `json:"value"`
 	PubKeyScript []byte              `json:"pubKeyScript"`
+	// PreviousTx is the serialized tx of the output, if known, exported as the non-witness UTXO of
+	// non-segwit inputs in PSBTs, as signers need it to verify the value of the output
+	PreviousTx []byte `json:"previousTx,omitempty"`
 }
 
 type Input struct {
@@ -40,9 +44,13 @@ type Recipient struct {
 
 // Tx for Bitcoin
 type Tx struct {
-	msgTx      *wire.MsgTx
-	signed     bool
-	recipients []Recipient
+	msgTx  *wire.MsgTx
+	signed bool
+	// signedInputs are the inputs signed by AddSignature
+	signedInputs []bool
+	// multisigSignatures are the signatures of a multisig tx per input, per public key of the multisig script
+	multisigSignatures [][][]byte
+	recipients         []Recipient
 
 	amount xc.AmountBlockchain
 	input  TxInput
@@ -52,6 +60,10 @@ type Tx struct {
 }
 
 var _ xc.Tx = &Tx{}
+var _ xc.TxStreamSerializer = &Tx{}
+
+// ChangeDetector returns true if address belongs to the sender, e.g. an address of its wallet's change chain
+type ChangeDetector func(address xc.Address) bool
 
 // Hash returns the tx hash or id
 func (tx *Tx) Hash() xc.TxHash {
@@ -85,123 +97,187 @@ func bzToString(bz []byte) string {
 // Sighashes returns the tx payload to sign, aka sighash
 func (tx *Tx) Sighashes() ([]xc.TxDataToSign, error) {
 	sighashes := make([]xc.TxDataToSign, len(tx.input.Inputs))
+	for i := range tx.input.Inputs {
+		hash, err := tx.Sighash(i)
+		if err != nil {
+			return []xc.TxDataToSign{}, err
+		}
+		sighashes[i] = hash
+	}
+	return sighashes, nil
+}
 
-	for i, txin := range tx.input.Inputs {
-		pubKeyScript := txin.PubKeyScript
-		sigScript := txin.SigScript
-		value := txin.Value.Uint64()
-
-		var hash []byte
-		var err error
-		log.Debugf("Sighashes params: sigScript=%s IsPayToWitnessPubKeyHash(pubKeyScript)=%t", bzToString(sigScript), txscript.IsPayToWitnessPubKeyHash(pubKeyScript))
-		if tx.isBch {
-			if sigScript == nil {
-				hash = CalculateBchBip143Sighash(pubKeyScript, txscript.NewTxSigHashes(tx.msgTx), txscript.SigHashAll, tx.msgTx, i, int64(value))
+// Sighash returns the payload to sign of the input at index, e.g. of a tx imported from a PSBT
+func (tx *Tx) Sighash(index int) (xc.TxDataToSign, error) {
+	if index < 0 || index >= len(tx.input.Inputs) {
+		return nil, fmt.Errorf("no input %d", index)
+	}
+	txin := tx.input.Inputs[index]
+	pubKeyScript := txin.PubKeyScript
+	sigScript := txin.SigScript
+	value := txin.Value.Uint64()
+
+	var hash []byte
+	var err error
+	log.Debugf("Sighashes params: sigScript=%s IsPayToWitnessPubKeyHash(pubKeyScript)=%t", bzToString(sigScript), txscript.IsPayToWitnessPubKeyHash(pubKeyScript))
+	if tx.isBch {
+		if sigScript == nil {
+			hash = CalculateBchBip143Sighash(pubKeyScript, txscript.NewTxSigHashes(tx.msgTx), txscript.SigHashAll, tx.msgTx, index, int64(value))
+		} else {
+			hash = CalculateBchBip143Sighash(sigScript, txscript.NewTxSigHashes(tx.msgTx), txscript.SigHashAll, tx.msgTx, index, int64(value))
+		}
+	} else {
+		if sigScript == nil {
+			if txscript.IsPayToWitnessPubKeyHash(pubKeyScript) {
+				log.Debugf("CalcWitnessSigHash with pubKeyScript: %s", base64.RawURLEncoding.EncodeToString(pubKeyScript))
+				hash, err = txscript.CalcWitnessSigHash(pubKeyScript, txscript.NewTxSigHashes(tx.msgTx), txscript.SigHashAll, tx.msgTx, index, int64(value))
 			} else {
-				hash = CalculateBchBip143Sighash(sigScript, txscript.NewTxSigHashes(tx.msgTx), txscript.SigHashAll, tx.msgTx, i, int64(value))
+				log.Debugf("CalcSignatureHash with pubKeyScript: %s", base64.RawURLEncoding.EncodeToString(pubKeyScript))
+				hash, err = txscript.CalcSignatureHash(pubKeyScript, txscript.SigHashAll, tx.msgTx, index)
 			}
 		} else {
-			if sigScript == nil {
-				if txscript.IsPayToWitnessPubKeyHash(pubKeyScript) {
-					log.Debugf("CalcWitnessSigHash with pubKeyScript: %s", base64.RawURLEncoding.EncodeToString(pubKeyScript))
-					hash, err = txscript.CalcWitnessSigHash(pubKeyScript, txscript.NewTxSigHashes(tx.msgTx), txscript.SigHashAll, tx.msgTx, i, int64(value))
-				} else {
-					log.Debugf("CalcSignatureHash with pubKeyScript: %s", base64.RawURLEncoding.EncodeToString(pubKeyScript))
-					hash, err = txscript.CalcSignatureHash(pubKeyScript, txscript.SigHashAll, tx.msgTx, i)
-				}
+			if txscript.IsPayToWitnessScriptHash(pubKeyScript) {
+				log.Debugf("CalcWitnessSigHash with sigScript: %s", base64.RawURLEncoding.EncodeToString(sigScript))
+				hash, err = txscript.CalcWitnessSigHash(sigScript, txscript.NewTxSigHashes(tx.msgTx), txscript.SigHashAll, tx.msgTx, index, int64(value))
 			} else {
-				if txscript.IsPayToWitnessScriptHash(pubKeyScript) {
-					log.Debugf("CalcWitnessSigHash with sigScript: %s", base64.RawURLEncoding.EncodeToString(sigScript))
-					hash, err = txscript.CalcWitnessSigHash(sigScript, txscript.NewTxSigHashes(tx.msgTx), txscript.SigHashAll, tx.msgTx, i, int64(value))
-				} else {
-					log.Debugf("CalcSignatureHash with sigScript: %s", base64.RawURLEncoding.EncodeToString(sigScript))
-					hash, err = txscript.CalcSignatureHash(sigScript, txscript.SigHashAll, tx.msgTx, i)
-				}
+				log.Debugf("CalcSignatureHash with sigScript: %s", base64.RawURLEncoding.EncodeToString(sigScript))
+				hash, err = txscript.CalcSignatureHash(sigScript, txscript.SigHashAll, tx.msgTx, index)
 			}
 		}
-		if err != nil {
-			return []xc.TxDataToSign{}, err
-		}
-
-		sighashes[i] = hash
 	}
-
-	return sighashes, nil
+	if err != nil {
+		return nil, err
+	}
+	return hash, nil
 }
 
-// AddSignatures adds a signature to Tx
+// AddSignatures adds a signature per input to Tx
+// Signatures of a multisig tx are the signatures of the signer of FromPublicKey, see AddSignaturesOf
 func (tx *Tx) AddSignatures(signatures ...xc.TxSignature) error {
 	if tx.signed {
 		return fmt.Errorf("already signed")
 	}
+	if len(tx.input.MultisigScript) > 0 {
+		return tx.AddSignaturesOf(tx.input.FromPublicKey, signatures...)
+	}
 	if len(signatures) != len(tx.msgTx.TxIn) {
 		return fmt.Errorf("expected %v signatures, got %v signatures", len(tx.msgTx.TxIn), len(signatures))
 	}
-
-	for i, rsvBytes := range signatures {
-		var err error
-		rsv := [65]byte{}
-		if len(rsvBytes) != 65 && len(rsvBytes) != 64 {
-			return errors.New("signature must be 64 or 65 length serialized bytestring of r,s, and recovery byte")
-		}
-		copy(rsv[:], rsvBytes)
-
-		// Decode the signature and the pubkey script.
-		r := new(big.Int).SetBytes(rsv[:32])
-		s := new(big.Int).SetBytes(rsv[32:64])
-		signature := btcec.Signature{
-			R: r,
-			S: s,
+	for i, signature := range signatures {
+		if err := tx.AddSignature(i, signature); err != nil {
+			return err
 		}
-		pubKeyScript := tx.input.Inputs[i].Output.PubKeyScript
-		sigScript := tx.input.Inputs[i].SigScript
+	}
+	return nil
+}
 
-		// Support segwit.
-		if sigScript == nil {
-			if txscript.IsPayToWitnessPubKeyHash(pubKeyScript) || txscript.IsPayToWitnessScriptHash(pubKeyScript) {
-				log.Debug("append signature (segwit)")
-				tx.msgTx.TxIn[i].Witness = wire.TxWitness([][]byte{append(signature.Serialize(), byte(txscript.SigHashAll)), tx.input.FromPublicKey})
-				continue
-			}
-		} else {
-			if txscript.IsPayToWitnessScriptHash(sigScript) {
-				log.Debug("append signature + sigscript (segwit)")
-				tx.msgTx.TxIn[i].Witness = wire.TxWitness([][]byte{append(signature.Serialize(), byte(txscript.SigHashAll)), tx.input.FromPublicKey, sigScript})
-				continue
-			}
-		}
+// AddSignature adds the signature of the input at index, e.g. returned by an external signer of a PSBT
+// Tx is signed once all its inputs are
+func (tx *Tx) AddSignature(index int, rsvBytes xc.TxSignature) error {
+	if tx.signed {
+		return fmt.Errorf("already signed")
+	}
+	if index < 0 || index >= len(tx.msgTx.TxIn) {
+		return fmt.Errorf("no input %d", index)
+	}
+	signature, err := parseSignature(rsvBytes)
+	if err != nil {
+		return err
+	}
+	return tx.addInputSignature(index, signature, tx.input.FromPublicKey)
+}
 
-		// Support non-segwit
-		builder := txscript.NewScriptBuilder()
-		sigHashByte := txscript.SigHashAll
-		if tx.isBch {
-			sigHashByte = sigHashByte | SighashForkID
-		}
-		builder.AddData(append(signature.Serialize(), byte(sigHashByte)))
-		builder.AddData(tx.input.FromPublicKey)
-		log.Debug("append signature (non-segwit)")
-		if sigScript != nil {
-			log.Debug("append sigScript (non-segwit)")
-			builder.AddData(sigScript)
+// parseSignature decodes a r,s signature, optionally followed by a recovery byte
+func parseSignature(rsvBytes xc.TxSignature) (*btcec.Signature, error) {
+	rsv := [65]byte{}
+	if len(rsvBytes) != 65 && len(rsvBytes) != 64 {
+		return nil, errors.New("signature must be 64 or 65 length serialized bytestring of r,s, and recovery byte")
+	}
+	copy(rsv[:], rsvBytes)
+
+	r := new(big.Int).SetBytes(rsv[:32])
+	s := new(big.Int).SetBytes(rsv[32:64])
+	return &btcec.Signature{
+		R: r,
+		S: s,
+	}, nil
+}
+
+// addInputSignature sets the script sig or witness of the input at index, signed by publicKey
+func (tx *Tx) addInputSignature(index int, signature *btcec.Signature, publicKey []byte) error {
+	pubKeyScript := tx.input.Inputs[index].Output.PubKeyScript
+	sigScript := tx.input.Inputs[index].SigScript
+
+	// Support segwit.
+	if sigScript == nil {
+		if txscript.IsPayToWitnessPubKeyHash(pubKeyScript) || txscript.IsPayToWitnessScriptHash(pubKeyScript) {
+			log.Debug("append signature (segwit)")
+			tx.msgTx.TxIn[index].Witness = wire.TxWitness([][]byte{append(signature.Serialize(), byte(txscript.SigHashAll)), publicKey})
+			tx.setInputSigned(index)
+			return nil
 		}
-		tx.msgTx.TxIn[i].SignatureScript, err = builder.Script()
-		if err != nil {
-			return err
+	} else {
+		if txscript.IsPayToWitnessScriptHash(sigScript) {
+			log.Debug("append signature + sigscript (segwit)")
+			tx.msgTx.TxIn[index].Witness = wire.TxWitness([][]byte{append(signature.Serialize(), byte(txscript.SigHashAll)), publicKey, sigScript})
+			tx.setInputSigned(index)
+			return nil
 		}
 	}
 
-	tx.signed = true
+	// Support non-segwit
+	builder := txscript.NewScriptBuilder()
+	builder.AddData(append(signature.Serialize(), byte(tx.sigHashType())))
+	builder.AddData(publicKey)
+	log.Debug("append signature (non-segwit)")
+	if sigScript != nil {
+		log.Debug("append sigScript (non-segwit)")
+		builder.AddData(sigScript)
+	}
+	signatureScript, err := builder.Script()
+	if err != nil {
+		return err
+	}
+	tx.msgTx.TxIn[index].SignatureScript = signatureScript
+	tx.setInputSigned(index)
 	return nil
 }
 
+// setInputSigned marks the input at index as signed, and Tx as signed once all its inputs are
+func (tx *Tx) setInputSigned(index int) {
+	if len(tx.signedInputs) != len(tx.msgTx.TxIn) {
+		tx.signedInputs = make([]bool, len(tx.msgTx.TxIn))
+	}
+	tx.signedInputs[index] = true
+	for _, signed := range tx.signedInputs {
+		if !signed {
+			return
+		}
+	}
+	tx.signed = true
+}
+
+// sigHashType returns the sighash type of signatures of non-segwit inputs
+func (tx *Tx) sigHashType() txscript.SigHashType {
+	if tx.isBch {
+		return txscript.SigHashAll | SighashForkID
+	}
+	return txscript.SigHashAll
+}
+
 func (tx *Tx) Serialize() ([]byte, error) {
-	buf := new(bytes.Buffer)
+	buf := bytes.NewBuffer(make([]byte, 0, tx.msgTx.SerializeSize()))
 	if err := tx.msgTx.Serialize(buf); err != nil {
 		return []byte{}, err
 	}
 	return buf.Bytes(), nil
 }
 
+// SerializeTo writes the serialized tx, as Serialize, into w
+func (tx *Tx) SerializeTo(w io.Writer) error {
+	return tx.msgTx.Serialize(w)
+}
+
 // Outputs returns the UTXO outputs in the underlying transaction.
 func (tx *Tx) Outputs() ([]Output, error) {
 	hash := tx.txHashNormal()
@@ -238,7 +314,32 @@ func (tx *Tx) DetectFrom() (string, xc.AmountBlockchain) {
 	return from, totalIn
 }
 
+// IsChange returns true if an output to address returns funds to the sender,
+// i.e. address is one of the inputs or is recognized by detector, e.g. as a change address of the sender's wallet
+// detector is only consulted if it recognizes an input too: otherwise the tx pays the wallet, and isn't sent by it
+func (tx *Tx) IsChange(address xc.Address, detector ChangeDetector) bool {
+	for _, input := range tx.input.Inputs {
+		if input.Address == address {
+			return true
+		}
+	}
+	if detector == nil || !detector(address) {
+		return false
+	}
+	for _, input := range tx.input.Inputs {
+		if detector(input.Address) {
+			return true
+		}
+	}
+	return false
+}
+
 func (tx *Tx) DetectToAndAmount(from string, expectedTo string) (string, xc.AmountBlockchain, xc.AmountBlockchain) {
+	return tx.DetectToAndAmountWithChange(from, expectedTo, nil)
+}
+
+// DetectToAndAmountWithChange is DetectToAndAmount ignoring change outputs as recipients
+func (tx *Tx) DetectToAndAmountWithChange(from string, expectedTo string, detector ChangeDetector) (string, xc.AmountBlockchain, xc.AmountBlockchain) {
 	to := expectedTo
 	amount := xc.NewAmountBlockchainFromUint64(0)
 	totalOut := xc.NewAmountBlockchainFromUint64(0)
@@ -252,8 +353,8 @@ func (tx *Tx) DetectToAndAmount(from string, expectedTo string) (string, xc.Amou
 			amount = amount.Add(&value)
 		}
 
-		// if we don't know "to", we set "to" as anything different than "from"
-		if expectedTo == "" && addr != from {
+		// if we don't know "to", we set "to" as anything different than "from" or change
+		if expectedTo == "" && addr != from && !tx.IsChange(recipient.To, detector) {
 			amount = value
 			to = addr
 		}
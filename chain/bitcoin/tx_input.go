@@ -2,12 +2,9 @@ package bitcoin
 
 import (
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
-	"sort"
 
 	xc "github.com/jumpcrypto/crosschain"
-	log "github.com/sirupsen/logrus"
 )
 
 // TxInput for Bitcoin
@@ -17,6 +14,8 @@ type TxInput struct {
 	Inputs          []Input             `json:"input"`
 	FromPublicKey   []byte              `json:"from_public_key"`
 	GasPricePerByte xc.AmountBlockchain `json:"gas_price_per_byte"`
+	// MultisigScript is the witness script of a P2WSH multisig sender, see NewMultisigScript
+	MultisigScript []byte `json:"multisig_script,omitempty"`
 }
 
 var _ xc.TxInputWithPublicKey = &TxInput{}
@@ -45,46 +44,3 @@ func (txInput *TxInput) SetPublicKeyFromStr(publicKeyStr string) error {
 
 	return err
 }
-
-// 1. sort unspentOutputs from lowest to highest
-// 2. grab the minimum amount of UTXO needed to satify amount
-// 3. tack on the smallest utxo's until `minUtxo` is reached.
-// This ensures a small number of UTXO are used for transaction while also consolidating some
-// smaller utxo into the transaction.
-// Returns the total balance of the min utxo set.  txInput.inputs are updated to the new set.
-func (txInput *TxInput) allocateMinUtxoSet(targetAmount xc.AmountBlockchain, minUtxo int) *xc.AmountBlockchain {
-	balance := xc.NewAmountBlockchainFromUint64(0)
-
-	// 1. sort from lowest to higher
-	if len(txInput.UnspentOutputs) > 1 {
-		sort.Slice(txInput.UnspentOutputs, func(i, j int) bool {
-			return txInput.UnspentOutputs[i].Value.Cmp(&txInput.UnspentOutputs[j].Value) <= 0
-		})
-	}
-
-	inputs := []Input{}
-	lenUTXOIndex := len(txInput.UnspentOutputs)
-	for balance.Cmp(&targetAmount) < 0 && lenUTXOIndex > 0 {
-		o := txInput.UnspentOutputs[lenUTXOIndex-1]
-		log.Infof("unspent output h2l: %s (%s)", hex.EncodeToString(o.PubKeyScript), o.Value.String())
-		balance = balance.Add(&o.Value)
-
-		inputs = append(inputs, Input{
-			Output: o,
-		})
-		lenUTXOIndex--
-	}
-
-	// add the smallest utxo until we reach `minUtxo` inputs
-	// lenUTXOIndex wasn't used, so i can grow up to lenUTXOIndex (included)
-	for i := 0; len(inputs) < minUtxo && i < lenUTXOIndex; i++ {
-		o := txInput.UnspentOutputs[i]
-		log.Infof("unspent output l2h: %s (%s)", hex.EncodeToString(o.PubKeyScript), o.Value.String())
-		balance = balance.Add(&o.Value)
-		inputs = append(inputs, Input{
-			Output: o,
-		})
-	}
-	txInput.Inputs = inputs
-	return &balance
-}
@@ -5,12 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"math/rand"
 	"time"
 
+	xc "github.com/jumpcrypto/crosschain"
 	log "github.com/sirupsen/logrus"
 )
 
+// minFeeRate returns the configured min fee rate in sats per byte, rounded up
+func minFeeRate(asset *xc.AssetConfig) xc.AmountBlockchain {
+	return xc.NewAmountBlockchainFromUint64(uint64(math.Ceil(asset.MinFeeRate)))
+}
+
 func retry(ctx context.Context, dur time.Duration, f func() error) error {
 	ticker := time.NewTicker(dur)
 	err := f()
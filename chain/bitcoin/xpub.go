@@ -0,0 +1,61 @@
+package bitcoin
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcutil"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// SLIP-132 version bytes of extended public keys, which imply the address type
+var (
+	xpubVersion = []byte{0x04, 0x88, 0xb2, 0x1e}
+	ypubVersion = []byte{0x04, 0x9d, 0x7c, 0xb2}
+	zpubVersion = []byte{0x04, 0xb2, 0x47, 0x46}
+	tpubVersion = []byte{0x04, 0x35, 0x87, 0xcf}
+	upubVersion = []byte{0x04, 0x4a, 0x52, 0x62}
+	vpubVersion = []byte{0x04, 0x5f, 0x1c, 0xf6}
+)
+
+// ExtendedKeyAddressType returns the AddressType implied by the version of an extended public key
+// Unknown versions (e.g. Ltub) default to P2PKH
+func ExtendedKeyAddressType(version []byte) xc.AddressType {
+	switch {
+	case bytes.Equal(version, ypubVersion), bytes.Equal(version, upubVersion):
+		return xc.AddressTypeP2SH
+	case bytes.Equal(version, zpubVersion), bytes.Equal(version, vpubVersion):
+		return xc.AddressTypeP2WPKH
+	case bytes.Equal(version, xpubVersion), bytes.Equal(version, tpubVersion):
+		return xc.AddressTypeP2PKH
+	}
+	return xc.AddressTypeP2PKH
+}
+
+// GetAddressFromPublicKeyWithType returns an Address of the given type given a compressed public key
+// P2SH is a P2WPKH nested in P2SH, as used by ypub (BIP-49), P2TR is a key-path only Taproot output (BIP-86)
+func (ab AddressBuilder) GetAddressFromPublicKeyWithType(publicKeyBytes []byte, addressType xc.AddressType) (xc.Address, error) {
+	if ab.cashAddr {
+		return ab.GetAddressFromPublicKey(publicKeyBytes)
+	}
+	pubKeyHash := btcutil.Hash160(publicKeyBytes)
+	var address btcutil.Address
+	var err error
+	switch addressType {
+	case xc.AddressTypeP2PKH:
+		address, err = btcutil.NewAddressPubKeyHash(pubKeyHash, ab.params)
+	case xc.AddressTypeP2SH:
+		redeemScript := append([]byte{0x00, 0x14}, pubKeyHash...)
+		address, err = btcutil.NewAddressScriptHash(redeemScript, ab.params)
+	case xc.AddressTypeP2WPKH:
+		address, err = btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, ab.params)
+	case xc.AddressTypeP2TR:
+		return NewTaprootAddress(publicKeyBytes, ab.params)
+	default:
+		return "", fmt.Errorf("unsupported address type: %s", addressType)
+	}
+	if err != nil {
+		return "", err
+	}
+	return xc.Address(address.EncodeAddress()), nil
+}
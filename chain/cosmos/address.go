@@ -1,6 +1,8 @@
 package cosmos
 
 import (
+	"fmt"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	xc "github.com/jumpcrypto/crosschain"
 )
@@ -10,6 +12,8 @@ type AddressBuilder struct {
 	Asset *xc.AssetConfig
 }
 
+var _ xc.AddressValidator = &AddressBuilder{}
+
 // NewAddressBuilder creates a new Cosmos AddressBuilder
 func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
 	return AddressBuilder{
@@ -30,13 +34,38 @@ func (ab AddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (xc.Addr
 	return xc.Address(bech32Addr), err
 }
 
-// GetAllPossibleAddressesFromPublicKey returns all PossubleAddress(es) given a public key
+// GetAllPossibleAddressesFromPublicKey returns all PossubleAddress(es) given a public key:
+// the account address, and its validator operator form used by staking msgs
 func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]xc.PossibleAddress, error) {
 	address, err := ab.GetAddressFromPublicKey(publicKeyBytes)
+	if err != nil {
+		return []xc.PossibleAddress{
+			{
+				Address: address,
+				Type:    xc.AddressTypeDefault,
+			},
+		}, err
+	}
+	valoper, err := ValoperAddressFromAddress(address, ab.Asset.ChainPrefix)
+	if err != nil {
+		return []xc.PossibleAddress{}, err
+	}
 	return []xc.PossibleAddress{
 		{
 			Address: address,
 			Type:    xc.AddressTypeDefault,
 		},
-	}, err
+		{
+			Address: valoper,
+			Type:    xc.AddressTypeValoper,
+		},
+	}, nil
+}
+
+// ValidateAddress checks the bech32 checksum of an account or contract address, and its prefix is the prefix of the chain
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	if _, err := accAddressFromBech32WithPrefix(string(address), ab.Asset.ChainPrefix); err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	return nil
 }
@@ -74,7 +74,25 @@ func (s *CrosschainTestSuite) TestGetAllPossibleAddressesFromPublicKey() {
 	bytes, _ := hex.DecodeString("02E8445082A72F29B75CA48748A914DF60622A609CACFCE8ED0E35804560741D29")
 	addresses, err := builder.GetAllPossibleAddressesFromPublicKey(bytes)
 	require.Nil(err)
-	require.Equal(1, len(addresses))
+	require.Equal(2, len(addresses))
 	require.Equal(xc.Address("terra1mzqd0kynsjzsnf3d37m5uvs53kkxssf0aasf27"), addresses[0].Address)
 	require.Equal(xc.AddressTypeDefault, addresses[0].Type)
+	require.Equal(xc.Address("terravaloper1mzqd0kynsjzsnf3d37m5uvs53kkxssf0aju56d"), addresses[1].Address)
+	require.Equal(xc.AddressTypeValoper, addresses[1].Type)
+}
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{NativeAsset: "LUNA", ChainPrefix: "terra"})
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("terra1mzqd0kynsjzsnf3d37m5uvs53kkxssf0aasf27"))
+	// contract
+	require.NoError(validator.ValidateAddress("terra1nc5tatafv6eyq7llkr2gv50ff9e22mnf70qgjlv737ktmt4eswrquka9l6"))
+
+	// checksum
+	require.ErrorContains(validator.ValidateAddress("terra1mzqd0kynsjzsnf3d37m5uvs53kkxssf0aasf28"), "invalid address 'terra1mzqd0kynsjzsnf3d37m5uvs53kkxssf0aasf28'")
+	// prefix
+	require.ErrorContains(validator.ValidateAddress("cosmos1mzqd0kynsjzsnf3d37m5uvs53kkxssf0me2fg7"), "invalid Bech32 prefix")
+	require.ErrorContains(validator.ValidateAddress("terravaloper1mzqd0kynsjzsnf3d37m5uvs53kkxssf0aju56d"), "invalid Bech32 prefix")
+	require.ErrorContains(validator.ValidateAddress(""), "empty address")
 }
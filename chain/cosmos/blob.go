@@ -0,0 +1,61 @@
+package cosmos
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Celestia wraps the txs paying for blobs: in a BlobTx when they're broadcast, with the blobs, and in an IndexWrapper
+// in blocks, with the indexes of the shares of the blobs. Both are protobuf messages of the tx (1), the blobs or the
+// share indexes (2) and a type id (3)
+const (
+	blobTxTypeID       = "BLOB"
+	indexWrapperTypeID = "INDX"
+)
+
+// PayForBlobsTypeURL is the type url of the msg of Celestia paying for blobs, unknown to the codec: txs paying for
+// blobs are decoded without it, see UnknownMsgTypes
+const PayForBlobsTypeURL = "/celestia.blob.v1.MsgPayForBlobs"
+
+// unwrapBlobTx returns the tx wrapped by a Celestia BlobTx or IndexWrapper, skipping the blob data, or txBytes and
+// false if it isn't wrapped
+// The hash of wrapped txs is the hash of the tx they wrap
+func unwrapBlobTx(txBytes []byte) ([]byte, bool) {
+	var tx []byte
+	var typeID string
+	data := txBytes
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return txBytes, false
+		}
+		data = data[n:]
+		switch {
+		case number == 1 && wireType == protowire.BytesType:
+			tx, n = protowire.ConsumeBytes(data)
+		case number == 3 && wireType == protowire.BytesType:
+			var value []byte
+			value, n = protowire.ConsumeBytes(data)
+			typeID = string(value)
+		default:
+			n = protowire.ConsumeFieldValue(number, wireType, data)
+		}
+		if n < 0 {
+			return txBytes, false
+		}
+		data = data[n:]
+	}
+	if len(tx) == 0 || (typeID != blobTxTypeID && typeID != indexWrapperTypeID) {
+		return txBytes, false
+	}
+	return tx, true
+}
+
+// PaysForBlobs returns whether a tx pays for Celestia blobs
+func (tx Tx) PaysForBlobs() bool {
+	for _, typeURL := range tx.UnknownMsgTypes() {
+		if typeURL == PayForBlobsTypeURL {
+			return true
+		}
+	}
+	return false
+}
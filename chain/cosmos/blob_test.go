@@ -0,0 +1,147 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// encodeCelestiaTx encodes a tx paying fee utia, with msgs of any type
+func encodeCelestiaTx(fee int64, msgs ...*codectypes.Any) []byte {
+	body := txtypes.TxBody{Messages: msgs}
+	bodyBytes, _ := body.Marshal()
+	authInfo := txtypes.AuthInfo{Fee: &txtypes.Fee{
+		Amount:   types.NewCoins(types.NewInt64Coin("utia", fee)),
+		GasLimit: 100000,
+	}}
+	authInfoBytes, _ := authInfo.Marshal()
+	raw := txtypes.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: authInfoBytes, Signatures: [][]byte{make([]byte, 64)}}
+	txBytes, _ := raw.Marshal()
+	return txBytes
+}
+
+// wrapBlobTx wraps a tx as a BlobTx, with a blob, or as an IndexWrapper, with share indexes
+func wrapBlobTx(tx []byte, typeID string) []byte {
+	wrapped := protowire.AppendTag(nil, 1, protowire.BytesType)
+	wrapped = protowire.AppendBytes(wrapped, tx)
+	wrapped = protowire.AppendTag(wrapped, 2, protowire.BytesType)
+	if typeID == blobTxTypeID {
+		// namespace and data of the blob
+		blob := protowire.AppendTag(nil, 1, protowire.BytesType)
+		blob = protowire.AppendBytes(blob, make([]byte, 29))
+		blob = protowire.AppendTag(blob, 2, protowire.BytesType)
+		blob = protowire.AppendBytes(blob, []byte("not a tx"))
+		wrapped = protowire.AppendBytes(wrapped, blob)
+	} else {
+		wrapped = protowire.AppendBytes(wrapped, []byte{0x05, 0x06})
+	}
+	wrapped = protowire.AppendTag(wrapped, 3, protowire.BytesType)
+	return protowire.AppendBytes(wrapped, []byte(typeID))
+}
+
+// jsonRPCResult is a response to the request id of the tendermint client
+func jsonRPCResult(id int, result string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, id, result)
+}
+
+func (s *CrosschainTestSuite) TestParseTxPayForBlobs() {
+	require := s.Require()
+	pfb := &codectypes.Any{TypeUrl: PayForBlobsTypeURL, Value: []byte{0x0a, 0x01, 0x61}}
+	txBytes := encodeCelestiaTx(2000, pfb)
+	hash := sha256.Sum256(txBytes)
+
+	for _, typeID := range []string{blobTxTypeID, indexWrapperTypeID} {
+		wrapped := wrapBlobTx(txBytes, typeID)
+		unwrapped, ok := unwrapBlobTx(wrapped)
+		require.True(ok)
+		require.Equal(txBytes, unwrapped)
+
+		tx, err := ParseTx(wrapped)
+		require.NoError(err)
+		require.True(tx.PaysForBlobs())
+		require.Equal("2000", tx.Fee().String())
+		// the hash of the wrapped tx
+		require.Equal(xc.TxHash(hex.EncodeToString(hash[:])), tx.Hash())
+	}
+
+	// other txs aren't wrapped
+	unwrapped, ok := unwrapBlobTx(txBytes)
+	require.False(ok)
+	require.Equal(txBytes, unwrapped)
+	_, ok = unwrapBlobTx(wrapBlobTx(txBytes, "OTHR"))
+	require.False(ok)
+	_, ok = unwrapBlobTx([]byte{0x0a, 0xff})
+	require.False(ok)
+	tx, err := ParseTx(txBytes)
+	require.NoError(err)
+	require.True(tx.PaysForBlobs())
+	tx, err = ParseTx(encodeCelestiaTx(2000))
+	require.NoError(err)
+	require.False(tx.PaysForBlobs())
+}
+
+func (s *CrosschainTestSuite) TestFetchBlockTxInfos() {
+	require := s.Require()
+	send, _ := codectypes.NewAnyWithValue(&banktypes.MsgSend{
+		FromAddress: "celestia1h8ljdmae7lx05kjj79c9ekscwsyjd3yrxh0m0r",
+		ToAddress:   "celestia1dp3q305hgttt8n34rt8rg9xpanc42z4y8vmh5n",
+		Amount:      types.NewCoins(types.NewInt64Coin("utia", 5000000)),
+	})
+	sendTx := encodeCelestiaTx(800, send)
+	pfbTx := encodeCelestiaTx(2000, &codectypes.Any{TypeUrl: PayForBlobsTypeURL, Value: []byte{0x0a, 0x01, 0x61}})
+	block := fmt.Sprintf(`{"block_id":{"hash":"55DF5840E4D24A53DF08E7D7D2B99DDAC9B60F2A683AF12542F1446E9966599A","parts":{"total":1,"hash":""}},"block":{"header":{"chain_id":"mocha-4","height":"100","time":"2023-11-14T22:13:20Z"},"data":{"txs":["%s","%s"]},"evidence":{"evidence":[]},"last_commit":null}}`,
+		base64.StdEncoding.EncodeToString(sendTx), base64.StdEncoding.EncodeToString(wrapBlobTx(pfbTx, indexWrapperTypeID)))
+	// the fee of the first tx as deducted, and a failed second tx
+	fee := base64.StdEncoding.EncodeToString([]byte("fee"))
+	blockResults := `{"height":"100","txs_results":[` +
+		`{"code":0,"gas_used":"70000","events":[{"type":"tx","attributes":[{"key":"` + fee + `","value":"` + base64.StdEncoding.EncodeToString([]byte("800utia")) + `"}]}]},` +
+		`{"code":11,"gas_used":"100000","events":[]}]}`
+
+	abciInfo := `{"response":{"last_block_height":"110"}}`
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		jsonRPCResult(0, block),
+		jsonRPCResult(1, blockResults),
+		jsonRPCResult(2, abciInfo),
+	})
+	defer close()
+	asset := &xc.AssetConfig{NativeAsset: xc.TIA, ChainCoin: "utia", ChainPrefix: "celestia", URL: server.URL}
+	client, err := NewClient(asset)
+	require.NoError(err)
+
+	infos, err := client.FetchBlockTxInfos(s.Ctx, 100)
+	require.NoError(err)
+	require.Len(infos, 2)
+	sendHash := sha256.Sum256(sendTx)
+	require.Equal(hex.EncodeToString(sendHash[:]), infos[0].TxID)
+	require.Equal("55DF5840E4D24A53DF08E7D7D2B99DDAC9B60F2A683AF12542F1446E9966599A", infos[0].BlockHash)
+	require.EqualValues(100, infos[0].BlockIndex)
+	require.EqualValues(1700000000, infos[0].BlockTime)
+	require.EqualValues(10, infos[0].Confirmations)
+	require.Equal(xc.Address("celestia1dp3q305hgttt8n34rt8rg9xpanc42z4y8vmh5n"), infos[0].To)
+	require.Equal("5000000", infos[0].Amount.String())
+	require.Equal("800", infos[0].Fee.String())
+	require.EqualValues(70000, infos[0].GasUsed)
+	require.Equal(xc.TxStatusSuccess, infos[0].Status)
+
+	// the tx paying for blobs, unwrapped
+	pfbHash := sha256.Sum256(pfbTx)
+	require.Equal(hex.EncodeToString(pfbHash[:]), infos[1].TxID)
+	require.Equal("2000", infos[1].Fee.String())
+	require.Equal(xc.TxStatusFailure, infos[1].Status)
+	require.Len(infos[1].Destinations, 0)
+
+	// blocks and their results must match, the ids of the requests continue
+	server.Counter = 0
+	server.Response = []string{jsonRPCResult(3, block), jsonRPCResult(4, `{"height":"100","txs_results":[]}`), jsonRPCResult(5, abciInfo)}
+	_, err = client.FetchBlockTxInfos(s.Ctx, 100)
+	require.EqualError(err, "block 100 has 2 txs but 0 results")
+}
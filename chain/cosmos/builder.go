@@ -1,16 +1,17 @@
 package cosmos
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
 	"math/big"
 	"strings"
 
 	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	multisigtypes "github.com/cosmos/cosmos-sdk/crypto/types/multisig"
 	"github.com/cosmos/cosmos-sdk/types"
 	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
 	"github.com/cosmos/cosmos-sdk/x/auth/signing"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	xc "github.com/jumpcrypto/crosschain"
@@ -22,6 +23,7 @@ type TxBuilder struct {
 	Asset           xc.ITask
 	CosmosTxConfig  client.TxConfig
 	CosmosTxBuilder client.TxBuilder
+	CosmosAmino     *codec.LegacyAmino
 }
 
 // NewTxBuilder creates a new Cosmos TxBuilder
@@ -32,11 +34,15 @@ func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
 		Asset:           asset,
 		CosmosTxConfig:  cosmosCfg.TxConfig,
 		CosmosTxBuilder: cosmosCfg.TxConfig.NewTxBuilder(),
+		CosmosAmino:     cosmosCfg.Amino,
 	}, nil
 }
 
 // NewTransfer creates a new transfer for an Asset, either native or token
 func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
 	if isNativeAsset(txBuilder.Asset.GetAssetConfig()) {
 		return txBuilder.NewNativeTransfer(from, to, amount, input)
 	}
@@ -53,12 +59,7 @@ func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amo
 		txInput.GasLimit = 400_000
 	}
 
-	denom := asset.GetNativeAsset().ChainCoin
-	if token, ok := asset.(*xc.TokenAssetConfig); ok {
-		if token.Contract != "" {
-			denom = token.Contract
-		}
-	}
+	denom := assetDenom(asset)
 
 	msgSend := &banktypes.MsgSend{
 		FromAddress: string(from),
@@ -90,11 +91,14 @@ func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amou
 		txInput.GasLimit = 900_000
 	}
 
-	contractTransferMsg := fmt.Sprintf(`{"transfer": {"amount": "%s", "recipient": "%s"}}`, amount.String(), to)
+	contractTransferMsg, err := newCW20TransferMsg(to, amount)
+	if err != nil {
+		return nil, err
+	}
 	msgSend := &wasmtypes.MsgExecuteContract{
 		Sender:   string(from),
 		Contract: asset.GetAssetConfig().Contract,
-		Msg:      wasmtypes.RawContractMessage(json.RawMessage(contractTransferMsg)),
+		Msg:      wasmtypes.RawContractMessage(contractTransferMsg),
 	}
 
 	return txBuilder.createTxWithMsg(from, to, amount, txInput, msgSend)
@@ -118,26 +122,71 @@ func accAddressFromBech32WithPrefix(address string, prefix string) ([]byte, erro
 	return addressBytes, nil
 }
 
+// NewTxWithMsgs creates a new tx with msgs of any module, e.g. the msgs of an app-chain registered with RegisterMsgs
+// The gas limit of the input defaults to 200_000 per msg, estimate it with SimulateTx for costlier msgs
+func (txBuilder TxBuilder) NewTxWithMsgs(input xc.TxInput, msgs ...types.Msg) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	txInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, errors.New("xc.TxInput is not from a cosmos chain")
+	}
+	if len(msgs) == 0 {
+		return nil, errors.New("no msgs")
+	}
+	for _, msg := range msgs {
+		if err := msg.ValidateBasic(); err != nil {
+			return nil, err
+		}
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 200_000 * uint64(len(msgs))
+	}
+	return txBuilder.createTxWithMsgs(txInput, msgs...)
+}
+
 // createTxWithMsg creates a new Tx given Cosmos Msg
 func (txBuilder TxBuilder) createTxWithMsg(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input *TxInput, msg types.Msg) (xc.Tx, error) {
 	asset := txBuilder.Asset
-	cosmosTxConfig := txBuilder.CosmosTxConfig
-	cosmosBuilder := txBuilder.CosmosTxBuilder
 
-	err := cosmosBuilder.SetMsgs(msg)
+	_, err := accAddressFromBech32WithPrefix(string(from), asset.GetNativeAsset().ChainPrefix)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = accAddressFromBech32WithPrefix(string(from), asset.GetNativeAsset().ChainPrefix)
+	_, err = accAddressFromBech32WithPrefix(string(to), asset.GetNativeAsset().ChainPrefix)
 	if err != nil {
 		return nil, err
 	}
+	return txBuilder.createTxWithMsgs(input, msg)
+}
 
-	_, err = accAddressFromBech32WithPrefix(string(to), asset.GetNativeAsset().ChainPrefix)
+// createTxWithMsgs creates a new Tx given one or more Cosmos Msg, without validating addresses
+func (txBuilder TxBuilder) createTxWithMsgs(input *TxInput, msgs ...types.Msg) (xc.Tx, error) {
+	asset := txBuilder.Asset
+	cosmosTxConfig := txBuilder.CosmosTxConfig
+	cosmosBuilder := txBuilder.CosmosTxBuilder
+	sigMode := signingtypes.SignMode_SIGN_MODE_DIRECT
+	if input.LegacyAmino {
+		// pre-Stargate nodes only accept amino encoded StdTx, signed over the amino JSON sign doc
+		cosmosTxConfig = legacytx.StdTxConfig{Cdc: txBuilder.CosmosAmino}
+		cosmosBuilder = cosmosTxConfig.NewTxBuilder()
+		sigMode = signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+	} else if input.SignMode != signingtypes.SignMode_SIGN_MODE_UNSPECIFIED {
+		sigMode = input.SignMode
+	}
+	multisig := input.isMultisig()
+	if multisig {
+		// members of a multisig sign the amino JSON sign doc
+		sigMode = signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+	}
+
+	err := cosmosBuilder.SetMsgs(msgs...)
 	if err != nil {
 		return nil, err
 	}
+
 	gasDenom := asset.GetNativeAsset().GasCoin
 	if gasDenom == "" {
 		gasDenom = asset.GetNativeAsset().ChainCoin
@@ -151,7 +200,6 @@ func (txBuilder TxBuilder) createTxWithMsg(from xc.Address, to xc.Address, amoun
 		},
 	})
 
-	sigMode := signingtypes.SignMode_SIGN_MODE_DIRECT
 	sigsV2 := []signingtypes.SignatureV2{
 		{
 			PubKey: getPublicKey(*asset.GetNativeAsset(), input.FromPublicKey),
@@ -162,6 +210,14 @@ func (txBuilder TxBuilder) createTxWithMsg(from xc.Address, to xc.Address, amoun
 			Sequence: input.Sequence,
 		},
 	}
+	if multisig {
+		multisigPublicKey, err := getMultisigPublicKey(*asset.GetNativeAsset(), input.MultisigThreshold, input.MultisigPublicKeys)
+		if err != nil {
+			return nil, err
+		}
+		sigsV2[0].PubKey = multisigPublicKey
+		sigsV2[0].Data = multisigtypes.NewMultisig(len(input.MultisigPublicKeys))
+	}
 	err = cosmosBuilder.SetSignatures(sigsV2...)
 	if err != nil {
 		return nil, err
@@ -179,10 +235,11 @@ func (txBuilder TxBuilder) createTxWithMsg(from xc.Address, to xc.Address, amoun
 	sighash := getSighash(*asset.GetNativeAsset(), sighashData)
 	return &Tx{
 		CosmosTx:        cosmosBuilder.GetTx(),
-		ParsedTransfers: []types.Msg{msg},
+		ParsedTransfers: msgs,
 		CosmosTxBuilder: cosmosBuilder,
 		CosmosTxEncoder: cosmosTxConfig.TxEncoder(),
 		SigsV2:          sigsV2,
 		TxDataToSign:    sighash,
+		SignBytes:       sighashData,
 	}, nil
 }
@@ -0,0 +1,81 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// FetchChainStats returns the latest block, the average block time, the gas price estimate
+// and the number of unconfirmed txs if the node exposes them
+// Cosmos chains have no base fee, only the min gas price of validators
+func (client *Client) FetchChainStats(ctx context.Context) (*xc.ChainStats, error) {
+	status, err := client.Ctx.Client.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch node status: %v", err)
+	}
+	latest := status.SyncInfo
+	stats := &xc.ChainStats{
+		Chain:  client.Asset.GetNativeAsset().NativeAsset,
+		Height: uint64(latest.LatestBlockHeight),
+	}
+
+	stats.AverageBlockTime, err = client.fetchAverageBlockTime(ctx, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.Asset.GetNativeAsset().NoGasFees {
+		stats.FeeRate, err = client.EstimateGas(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not estimate gas price: %v", err)
+		}
+	}
+
+	if unconfirmed, err := client.Ctx.Client.NumUnconfirmedTxs(ctx); err == nil {
+		size := uint64(unconfirmed.Total)
+		stats.MempoolSize = &size
+	}
+	return stats, nil
+}
+
+// FetchChainHealth returns whether the chain is halted, e.g. at an upgrade height, from the age of the latest block,
+// or in maintenance at the maintenance_url of the asset
+func (client *Client) FetchChainHealth(ctx context.Context) (*xc.ChainHealth, error) {
+	status, err := client.Ctx.Client.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch node status: %v", err)
+	}
+	latest := status.SyncInfo
+	var average time.Duration
+	if client.Asset.GetNativeAsset().BlockTime <= 0 {
+		if average, err = client.fetchAverageBlockTime(ctx, latest); err != nil {
+			return nil, err
+		}
+	}
+	return xc.CheckChainHealth(ctx, client.Asset.GetNativeAsset(), uint64(latest.LatestBlockHeight), latest.LatestBlockTime, average, now())
+}
+
+// fetchAverageBlockTime returns the average block time over the ChainStatsBlocks blocks up to the latest block
+// of the node, or its earliest block if pruned
+func (client *Client) fetchAverageBlockTime(ctx context.Context, latest ctypes.SyncInfo) (time.Duration, error) {
+	fromHeight := latest.LatestBlockHeight - xc.ChainStatsBlocks
+	if fromHeight < latest.EarliestBlockHeight {
+		fromHeight = latest.EarliestBlockHeight
+	}
+	if fromHeight <= 0 || fromHeight >= latest.LatestBlockHeight {
+		return 0, nil
+	}
+	blocks, err := client.Ctx.Client.BlockchainInfo(ctx, fromHeight, fromHeight)
+	if err != nil {
+		return 0, fmt.Errorf("could not fetch block %d: %v", fromHeight, err)
+	}
+	if len(blocks.BlockMetas) == 0 {
+		return 0, nil
+	}
+	from := blocks.BlockMetas[0].Header
+	return xc.AverageBlockTime(uint64(from.Height), from.Time, uint64(latest.LatestBlockHeight), latest.LatestBlockTime), nil
+}
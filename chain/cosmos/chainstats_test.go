@@ -0,0 +1,94 @@
+package cosmos
+
+import (
+	"fmt"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestFetchChainStats() {
+	require := s.Require()
+	status := fmt.Sprintf(`{"node_info":{"protocol_version":{"p2p":"8","block":"11","app":"0"},"id":"","listen_addr":"","network":"cosmoshub-4","version":"0.34.27","channels":"","moniker":"","other":{"tx_index":"on","rpc_address":""}},"sync_info":{"latest_block_hash":"","latest_app_hash":"","latest_block_height":"%s","latest_block_time":"2023-05-03T14:00:00Z","earliest_block_hash":"","earliest_app_hash":"","earliest_block_height":"1","earliest_block_time":"2023-05-03T13:00:00Z","catching_up":false}}`, "100")
+	blockchainInfo := `{"jsonrpc":"2.0","id":1,"result":{"last_height":"100","block_metas":[{"block_id":{"hash":"","parts":{"total":0,"hash":""}},"block_size":"1024","header":{"version":{"block":"11","app":"0"},"chain_id":"cosmoshub-4","height":"80","time":"2023-05-03T13:58:00Z","last_block_id":{"hash":"","parts":{"total":0,"hash":""}},"last_commit_hash":"","data_hash":"","validators_hash":"","next_validators_hash":"","consensus_hash":"","app_hash":"","last_results_hash":"","evidence_hash":"","proposer_address":""},"num_txs":"0"}]}}`
+	// tendermint checks the id of responses, incremented by request
+	unconfirmed := func(id int) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"n_txs":"3","total":"12","total_bytes":"4096","txs":null}}`, id)
+	}
+	asset := &xc.AssetConfig{NativeAsset: xc.ATOM, ChainGasPriceDefault: 0.025}
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{status, blockchainInfo, unconfirmed(2)})
+	defer close()
+	asset.URL = server.URL
+	client, _ := NewClient(asset)
+	stats, err := client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.ATOM, stats.Chain)
+	require.EqualValues(100, stats.Height)
+	require.Equal(6*time.Second, stats.AverageBlockTime)
+	require.Equal("0", stats.BaseFee.String())
+	require.Equal(xc.NewAmountBlockchainToMaskFloat64(0.025).String(), stats.FeeRate.String())
+	require.NotNil(stats.MempoolSize)
+	require.EqualValues(12, *stats.MempoolSize)
+
+	// unconfirmed txs not exposed
+	server, close = test.MockJSONRPC(&s.Suite, []string{status, blockchainInfo, `{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"Method not found"}}`})
+	defer close()
+	asset.URL = server.URL
+	client, _ = NewClient(asset)
+	stats, err = client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.Nil(stats.MempoolSize)
+
+	// chain of a single block
+	server, close = test.MockJSONRPC(&s.Suite, []string{fmt.Sprintf(`{"sync_info":{"latest_block_height":"%s","latest_block_time":"2023-05-03T14:00:00Z","earliest_block_height":"1","catching_up":false}}`, "1"), unconfirmed(1)})
+	defer close()
+	asset.URL = server.URL
+	client, _ = NewClient(asset)
+	stats, err = client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.EqualValues(1, stats.Height)
+	require.Zero(stats.AverageBlockTime)
+}
+
+func (s *CrosschainTestSuite) TestFetchChainStatsError() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"jsonrpc":"2.0","id":0,"error":{"code":-32603,"message":"Internal error"}}`)
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM, URL: server.URL})
+
+	_, err := client.FetchChainStats(s.Ctx)
+	require.ErrorContains(err, "could not fetch node status")
+}
+
+func (s *CrosschainTestSuite) TestFetchChainHealth() {
+	require := s.Require()
+	status := `{"sync_info":{"latest_block_height":"100","latest_block_time":"2023-05-03T14:00:00Z","earliest_block_height":"1","catching_up":false}}`
+	blockchainInfo := `{"jsonrpc":"2.0","id":1,"result":{"last_height":"100","block_metas":[{"block_id":{"hash":"","parts":{"total":0,"hash":""}},"block_size":"1024","header":{"version":{"block":"11","app":"0"},"chain_id":"cosmoshub-4","height":"80","time":"2023-05-03T13:58:00Z","last_block_id":{"hash":"","parts":{"total":0,"hash":""}},"last_commit_hash":"","data_hash":"","validators_hash":"","next_validators_hash":"","consensus_hash":"","app_hash":"","last_results_hash":"","evidence_hash":"","proposer_address":""},"num_txs":"0"}]}}`
+	latest := time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+	defer func() { now = time.Now }()
+	asset := &xc.AssetConfig{NativeAsset: xc.ATOM}
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{status, blockchainInfo})
+	defer close()
+	asset.URL = server.URL
+	client, _ := NewClient(asset)
+	now = func() time.Time { return latest.Add(10 * time.Second) }
+	health, err := client.FetchChainHealth(s.Ctx)
+	require.NoError(err)
+	require.True(health.Healthy())
+	require.EqualValues(100, health.Height)
+	require.Equal(time.Minute, health.HaltThreshold)
+
+	// halted at an upgrade height
+	server, close = test.MockJSONRPC(&s.Suite, []string{status, blockchainInfo})
+	defer close()
+	asset.URL = server.URL
+	client, _ = NewClient(asset)
+	now = func() time.Time { return latest.Add(time.Hour) }
+	health, err = client.FetchChainHealth(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.ChainStatusHalted, health.Status)
+	require.Equal("latest block 100 is 1h0m0s old", health.Details)
+}
@@ -8,10 +8,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 
 	xc "github.com/jumpcrypto/crosschain"
 
@@ -22,8 +24,11 @@ import (
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/query"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	abci "github.com/tendermint/tendermint/abci/types"
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
 )
 
@@ -36,6 +41,13 @@ type TxInput struct {
 	GasPrice      float64
 	Memo          string
 	FromPublicKey []byte
+	// SignMode of the node, SIGN_MODE_DIRECT if unspecified
+	SignMode signingtypes.SignMode
+	// LegacyAmino is set for pre-Stargate nodes, only accepting amino encoded StdTx
+	LegacyAmino bool
+	// MultisigThreshold and MultisigPublicKeys are set for a multisig sender, see AddressBuilder.GetMultisigAddress
+	MultisigThreshold  uint32
+	MultisigPublicKeys [][]byte
 }
 
 func (txInput *TxInput) SetPublicKey(publicKeyBytes xc.PublicKey) error {
@@ -58,12 +70,30 @@ func NewTxInput() *TxInput {
 	}
 }
 
+var _ xc.TxInputNonce = &TxInput{}
+
+// GetNonce returns the sequence of the account signing the tx
+func (txInput *TxInput) GetNonce() uint64 {
+	return txInput.Sequence
+}
+
+var _ xc.TxInputFee = &TxInput{}
+
+// MaxFee returns the fee amount of the tx, the gas limit times the gas price
+func (txInput *TxInput) MaxFee() xc.AmountBlockchain {
+	return xc.NewAmountBlockchainFromUint64(uint64(txInput.GasPrice * float64(txInput.GasLimit)))
+}
+
 // Client for Cosmos
 type Client struct {
 	Asset           xc.ITask
 	Ctx             client.Context
 	Prefix          string
 	EstimateGasFunc xc.EstimateGasFunc
+	// HttpClient sends the requests to other APIs than the RPC, e.g. the FCD, through the transport of the asset
+	HttpClient *http.Client
+	version    *NodeVersion
+	versionMu  sync.RWMutex
 }
 
 var _ xc.FullClientWithGas = &Client{}
@@ -73,6 +103,10 @@ func NewClient(cfgI xc.ITask) (*Client, error) {
 	asset := cfgI
 	cfg := cfgI.GetNativeAsset()
 	host := cfg.URL
+	proxy, err := cfg.HTTPTransport(&http.Transport{})
+	if err != nil {
+		return nil, err
+	}
 	httpClient, err := rpchttp.NewWithClient(
 		host,
 		"websocket",
@@ -82,7 +116,7 @@ func NewClient(cfgI xc.ITask) (*Client, error) {
 			// We override the transport layer with a custom implementation as
 			// there is an issue with the Cosmos SDK that causes it to
 			// incorrectly parse URLs.
-			Transport: newTransport(host, &http.Transport{}),
+			Transport: newTransport(host, proxy),
 		})
 	if err != nil {
 		panic(err)
@@ -103,6 +137,7 @@ func NewClient(cfgI xc.ITask) (*Client, error) {
 		Ctx:             cliCtx,
 		Prefix:          cfg.ChainPrefix,
 		EstimateGasFunc: nil,
+		HttpClient:      &http.Client{Transport: proxy},
 	}, nil
 }
 
@@ -140,6 +175,10 @@ func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, _ xc.Ad
 	}
 	txInput.AccountNumber = account.GetAccountNumber()
 	txInput.Sequence = account.GetSequence()
+	if version := client.nodeVersion(); version != nil {
+		txInput.SignMode = version.SignMode()
+		txInput.LegacyAmino = version.Legacy()
+	}
 
 	if !client.Asset.GetNativeAsset().NoGasFees {
 		gasPrice, err := client.EstimateGas(ctx)
@@ -154,6 +193,16 @@ func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, _ xc.Ad
 
 // SubmitTx submits a Cosmos tx
 func (client *Client) SubmitTx(ctx context.Context, txInput xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		simulated, err := client.simulateTx(ctx, txInput)
+		if err != nil {
+			return err
+		}
+		return xc.RecordDryRun(ctx, client.Asset, txInput, simulated)
+	}
 	tx := txInput.(*Tx)
 	txBytes, _ := tx.Serialize()
 	txID := tx.Hash()
@@ -170,6 +219,68 @@ func (client *Client) SubmitTx(ctx context.Context, txInput xc.Tx) error {
 	return nil
 }
 
+// simulateTx simulates tx with the tx service, returning false for pre-Stargate nodes without simulation
+func (client *Client) simulateTx(ctx context.Context, tx xc.Tx) (bool, error) {
+	result, err := client.SimulateTx(ctx, tx)
+	if errors.Is(err, xc.ErrSimulationNotSupported) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !result.Success {
+		return false, fmt.Errorf("failed to simulate tx %v: %v", tx.Hash(), result.FailureReason)
+	}
+	return true, nil
+}
+
+var _ xc.FeeEstimator = &Client{}
+
+// EstimateFee estimates the fee of tx, the gas used by its simulation at the gas price of tx, i.e. its fee over its gas limit
+// Pre-Stargate nodes can't simulate txs: the fee of tx is returned
+func (client *Client) EstimateFee(ctx context.Context, _ xc.Address, tx xc.Tx) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	var cosmosTx types.Tx
+	switch tx := tx.(type) {
+	case *Tx:
+		cosmosTx = tx.CosmosTx
+	case Tx:
+		cosmosTx = tx.CosmosTx
+	}
+	feeTx, ok := cosmosTx.(types.FeeTx)
+	if !ok || feeTx.GetGas() == 0 {
+		return zero, errors.New("tx is not a cosmos tx with a gas limit")
+	}
+	fee := feeTx.GetFee().AmountOf(client.gasDenom()).BigInt()
+	if version := client.nodeVersion(); version != nil && version.Legacy() {
+		return xc.AmountBlockchain(*fee), nil
+	}
+	txBytes, err := tx.Serialize()
+	if err != nil {
+		return zero, err
+	}
+	res, err := txtypes.NewServiceClient(client.Ctx).Simulate(ctx, &txtypes.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		return zero, fmt.Errorf("failed to simulate tx %v: %v", tx.Hash(), err)
+	}
+	if res.GasInfo == nil {
+		return zero, fmt.Errorf("no gas info simulating tx %v", tx.Hash())
+	}
+	// rounded up, as the fee of the gas limit is
+	estimate := new(big.Int).Mul(fee, new(big.Int).SetUint64(res.GasInfo.GasUsed))
+	gasLimit := new(big.Int).SetUint64(feeTx.GetGas())
+	estimate.Add(estimate, new(big.Int).Sub(gasLimit, big.NewInt(1)))
+	return xc.AmountBlockchain(*estimate.Div(estimate, gasLimit)), nil
+}
+
+// gasDenom returns the denom of the fees of the chain
+func (client *Client) gasDenom() string {
+	if denom := client.Asset.GetNativeAsset().GasCoin; denom != "" {
+		return denom
+	}
+	return client.Asset.GetNativeAsset().ChainCoin
+}
+
 // FetchTxInfo returns tx info for a Cosmos tx
 func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
 	result := xc.TxInfo{
@@ -199,20 +310,21 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxI
 		return result, err
 	}
 
-	decoder := client.Ctx.TxConfig.TxDecoder()
-	decodedTx, err := decoder(resultRaw.Tx)
+	tx, err := decodeTx(client.Ctx.TxConfig, client.Ctx.InterfaceRegistry, resultRaw.Tx)
 	if err != nil {
 		return result, err
 	}
 
-	tx := &Tx{
-		CosmosTx:        decodedTx,
-		CosmosTxEncoder: client.Ctx.TxConfig.TxEncoder(),
-	}
-
 	result.TxID = string(txHash)
+	result.BlockIndex = resultRaw.Height
+	result.BlockTime = blockResultRaw.Block.Header.Time.Unix()
+	result.Confirmations = abciInfo.Response.LastBlockHeight - result.BlockIndex
+	return client.parseTxInfo(result, tx, resultRaw.TxResult), nil
+}
+
+// parseTxInfo completes the tx info of a tx with its transfers and its result
+func (client *Client) parseTxInfo(result xc.TxInfo, tx *Tx, txResult abci.ResponseDeliverTx) xc.TxInfo {
 	result.ExplorerURL = client.Asset.GetNativeAsset().ExplorerURL + "/tx/" + result.TxID
-	tx.ParseTransfer()
 
 	// parse tx info - this should happen after ATA is set
 	// (in most cases it works also in case or error)
@@ -221,18 +333,65 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxI
 	result.ContractAddress = tx.ContractAddress()
 	result.Amount = tx.Amount()
 	result.Fee = tx.Fee()
+	// fee grants and chains deducting fees of their own aren't reflected by the fee of the tx
+	if fee, ok := DeductedFee(txResult.Events, client.gasDenom()); ok {
+		result.Fee = fee
+	}
+	result.GasUsed = uint64(txResult.GasUsed)
 	result.Sources = tx.Sources()
 	result.Destinations = tx.Destinations()
 
-	result.BlockIndex = resultRaw.Height
-	result.BlockTime = blockResultRaw.Block.Header.Time.Unix()
-	result.Confirmations = abciInfo.Response.LastBlockHeight - result.BlockIndex
-
-	if resultRaw.TxResult.Code != 0 {
+	if txResult.Code != 0 {
 		result.Status = xc.TxStatusFailure
 	}
+	return result
+}
+
+// FetchLatestBlock returns the height of the latest block
+func (client *Client) FetchLatestBlock(ctx context.Context) (int64, error) {
+	abciInfo, err := client.Ctx.Client.ABCIInfo(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return abciInfo.Response.LastBlockHeight, nil
+}
+
+// FetchBlockTxInfos returns tx info for each tx of a block
+// The txs of Celestia paying for blobs are wrapped with the indexes of their blobs in blocks, and are unwrapped
+func (client *Client) FetchBlockTxInfos(ctx context.Context, height int64) ([]xc.TxInfo, error) {
+	block, err := client.Ctx.Client.Block(ctx, &height)
+	if err != nil {
+		return nil, fmt.Errorf("fetching block %d: %v", height, err)
+	}
+	blockResults, err := client.Ctx.Client.BlockResults(ctx, &height)
+	if err != nil {
+		return nil, fmt.Errorf("fetching results of block %d: %v", height, err)
+	}
+	abciInfo, err := client.Ctx.Client.ABCIInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Block.Data.Txs
+	if len(blockResults.TxsResults) != len(txs) {
+		return nil, fmt.Errorf("block %d has %d txs but %d results", height, len(txs), len(blockResults.TxsResults))
+	}
 
-	return result, nil
+	infos := make([]xc.TxInfo, 0, len(txs))
+	for i, txBytes := range txs {
+		tx, err := decodeTx(client.Ctx.TxConfig, client.Ctx.InterfaceRegistry, txBytes)
+		if err != nil {
+			return nil, fmt.Errorf("decoding tx %d of block %d: %v", i, height, err)
+		}
+		result := xc.TxInfo{
+			TxID:          string(tx.Hash()),
+			BlockHash:     block.BlockID.Hash.String(),
+			BlockIndex:    height,
+			BlockTime:     block.Block.Header.Time.Unix(),
+			Confirmations: abciInfo.Response.LastBlockHeight - height,
+		}
+		infos = append(infos, client.parseTxInfo(result, tx, *blockResults.TxsResults[i]))
+	}
+	return infos, nil
 }
 
 // GetAccount returns a Cosmos account
@@ -242,8 +401,18 @@ func (client *Client) GetAccount(ctx context.Context, address xc.Address) (clien
 	if err != nil {
 		return nil, fmt.Errorf("bad address: '%v': %v", address, err)
 	}
+	if version := client.nodeVersion(); version != nil && version.Legacy() {
+		return client.getLegacyAccount(ctx, address)
+	}
 
 	res, err := authtypes.NewQueryClient(client.Ctx).Account(ctx, &authtypes.QueryAccountRequest{Address: string(address)})
+	if isUnknownQueryPath(err) {
+		// pre-Stargate nodes don't serve gRPC queries
+		version, versionErr := client.NegotiateVersion(ctx)
+		if versionErr == nil && version.Legacy() {
+			return client.getLegacyAccount(ctx, address)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -260,7 +429,11 @@ func (client *Client) estimateGasFcd(ctx context.Context) (xc.AmountBlockchain,
 	zero := xc.NewAmountBlockchainFromUint64(0)
 	asset := client.Asset
 	fdcURL := asset.GetNativeAsset().FcdURL
-	resp, err := http.Get(fdcURL + "/v1/txs/gas_prices")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fdcURL+"/v1/txs/gas_prices", nil)
+	if err != nil {
+		return zero, err
+	}
+	resp, err := client.HttpClient.Do(req)
 	if err != nil {
 		return zero, err
 	}
@@ -298,8 +471,24 @@ func (client *Client) estimateGasFcd(ctx context.Context) (xc.AmountBlockchain,
 	return xc.NewAmountBlockchainToMaskFloat64(gasPrice * multiplier), nil
 }
 
-// EstimateGas estimates gas price for a Cosmos chain
+// EstimateGas estimates gas price for a Cosmos chain, floored to the configured min gas price
 func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	gasPrice, err := client.estimateGas(ctx)
+	minGasPrice := client.Asset.GetNativeAsset().MinGasPrice
+	if errors.Is(err, errNoGasEstimator) && minGasPrice > 0 {
+		// the min gas price is enough without estimator
+		return xc.NewAmountBlockchainToMaskFloat64(minGasPrice), nil
+	}
+	if err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), err
+	}
+	return gasPrice.ApplyFloor(xc.NewAmountBlockchainToMaskFloat64(minGasPrice)), nil
+}
+
+// errNoGasEstimator is returned when no gas price source is configured
+var errNoGasEstimator = errors.New("not implemented")
+
+func (client *Client) estimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
 	// invoke EstimateGasFunc callback, if registered
 	if client.EstimateGasFunc != nil {
 		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
@@ -318,7 +507,7 @@ func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, err
 	if client.Asset.GetNativeAsset().ChainGasPriceDefault > 0 {
 		return xc.NewAmountBlockchainToMaskFloat64(client.Asset.GetNativeAsset().ChainGasPriceDefault), nil
 	}
-	return zero, errors.New("not implemented")
+	return zero, errNoGasEstimator
 }
 
 // RegisterEstimateGasCallback registers a callback to get gas price
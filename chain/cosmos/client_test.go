@@ -2,8 +2,12 @@ package cosmos
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 
+	"github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
 	xc "github.com/jumpcrypto/crosschain"
 	"github.com/jumpcrypto/crosschain/test"
 )
@@ -18,6 +22,12 @@ func (s *CrosschainTestSuite) TestNewClient() {
 func ignoreError(val []byte, err error) []byte {
 	return val
 }
+func (s *CrosschainTestSuite) TestTxInputMaxFee() {
+	require := s.Require()
+	input := &TxInput{GasLimit: 400_000, GasPrice: 0.0125}
+	require.Equal("5000", input.MaxFee().String())
+}
+
 func (s *CrosschainTestSuite) TestFetchTxInput() {
 	require := s.Require()
 
@@ -39,7 +49,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			`{"jsonrpc":"2.0","id":0,"result":{"response":{"code":0,"log":"","info":"","index":"0","key":null,"value":"CqABCiAvY29zbW9zLmF1dGgudjFiZXRhMS5CYXNlQWNjb3VudBJ8Cix0ZXJyYTFkcDNxMzA1aGd0dHQ4bjM0cnQ4cmc5eHBhbmM0Mno0eWU3dXBmZxJGCh8vY29zbW9zLmNyeXB0by5zZWNwMjU2azEuUHViS2V5EiMKIQL89yTJff+sICHvoYGML+87y7dTyiKROo21557Eo97g0RjZhgEgAw==","proofOps":null,"height":"2803726","codespace":""}}}`,
 			`{"uluna": "0.015"}`,
 			&TxInput{
-				TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"},
+				TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos),
 				FromPublicKey:   ignoreError(base64.StdEncoding.DecodeString("Avz3JMl9/6wgIe+hgYwv7zvLt1PKIpE6jbXnnsSj3uDR")),
 				AccountNumber:   17241,
 				Sequence:        3,
@@ -57,7 +67,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			`{"jsonrpc":"2.0","id":0,"result":{"response":{"code":0,"log":"","info":"","index":"0","key":null,"value":"CqgBCiAvY29zbW9zLmF1dGgudjFiZXRhMS5CYXNlQWNjb3VudBKDAQoreHBsYTFoZHZmNnZ2NWFtYzd3cDg0anMwbHMyN2FwZWt3eHByMGdlOTZrZxJPCigvZXRoZXJtaW50LmNyeXB0by52MS5ldGhzZWNwMjU2azEuUHViS2V5EiMKIQK3jbFRLCBKbDkZ7HGZcc6O14WuCUStGu7+qycD0eVNAhiiCyAE","proofOps":null,"height":"1359950","codespace":""}}}`,
 			`{"axpla":"850000000000"}`,
 			&TxInput{
-				TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"},
+				TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos),
 				FromPublicKey:   ignoreError(base64.StdEncoding.DecodeString("AreNsVEsIEpsORnscZlxzo7Xha4JRK0a7v6rJwPR5U0C")),
 				AccountNumber:   1442,
 				Sequence:        4,
@@ -75,7 +85,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			"terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn",
 			``,
 			`{"uluna": "0.015"}`,
-			&TxInput{TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"}},
+			&TxInput{TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos)},
 			"failed to get account data",
 		},
 		{
@@ -85,7 +95,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			"terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn",
 			`null`,
 			`{"uluna": "0.015"}`,
-			&TxInput{TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"}},
+			&TxInput{TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos)},
 			"failed to get account data",
 		},
 		{
@@ -95,7 +105,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			"terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn",
 			`{}`,
 			`{"uluna": "0.015"}`,
-			&TxInput{TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"}},
+			&TxInput{TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos)},
 			"failed to get account data",
 		},
 		{
@@ -105,7 +115,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			"terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn",
 			errors.New(`{"message": "custom RPC error", "code": 123}`),
 			`{"uluna": "0.015"}`,
-			&TxInput{TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"}},
+			&TxInput{TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos)},
 			"failed to get account data",
 		},
 		// error getting gas
@@ -117,7 +127,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			`{"jsonrpc":"2.0","id":0,"result":{"response":{"code":0,"log":"","info":"","index":"0","key":null,"value":"CqABCiAvY29zbW9zLmF1dGgudjFiZXRhMS5CYXNlQWNjb3VudBJ8Cix0ZXJyYTFkcDNxMzA1aGd0dHQ4bjM0cnQ4cmc5eHBhbmM0Mno0eWU3dXBmZxJGCh8vY29zbW9zLmNyeXB0by5zZWNwMjU2azEuUHViS2V5EiMKIQL89yTJff+sICHvoYGML+87y7dTyiKROo21557Eo97g0RjZhgEgAw==","proofOps":null,"height":"2803726","codespace":""}}}`,
 			``,
 			&TxInput{
-				TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"},
+				TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos),
 				AccountNumber:   17241,
 				Sequence:        3,
 			},
@@ -131,7 +141,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			`{"jsonrpc":"2.0","id":0,"result":{"response":{"code":0,"log":"","info":"","index":"0","key":null,"value":"CqABCiAvY29zbW9zLmF1dGgudjFiZXRhMS5CYXNlQWNjb3VudBJ8Cix0ZXJyYTFkcDNxMzA1aGd0dHQ4bjM0cnQ4cmc5eHBhbmM0Mno0eWU3dXBmZxJGCh8vY29zbW9zLmNyeXB0by5zZWNwMjU2azEuUHViS2V5EiMKIQL89yTJff+sICHvoYGML+87y7dTyiKROo21557Eo97g0RjZhgEgAw==","proofOps":null,"height":"2803726","codespace":""}}}`,
 			`null`,
 			&TxInput{
-				TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"},
+				TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos),
 				AccountNumber:   17241,
 				Sequence:        3,
 			},
@@ -145,7 +155,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			`{"jsonrpc":"2.0","id":0,"result":{"response":{"code":0,"log":"","info":"","index":"0","key":null,"value":"CqABCiAvY29zbW9zLmF1dGgudjFiZXRhMS5CYXNlQWNjb3VudBJ8Cix0ZXJyYTFkcDNxMzA1aGd0dHQ4bjM0cnQ4cmc5eHBhbmM0Mno0eWU3dXBmZxJGCh8vY29zbW9zLmNyeXB0by5zZWNwMjU2azEuUHViS2V5EiMKIQL89yTJff+sICHvoYGML+87y7dTyiKROo21557Eo97g0RjZhgEgAw==","proofOps":null,"height":"2803726","codespace":""}}}`,
 			`{}`,
 			&TxInput{
-				TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"},
+				TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos),
 				AccountNumber:   17241,
 				Sequence:        3,
 			},
@@ -159,7 +169,7 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			`{"jsonrpc":"2.0","id":0,"result":{"response":{"code":0,"log":"","info":"","index":"0","key":null,"value":"CqABCiAvY29zbW9zLmF1dGgudjFiZXRhMS5CYXNlQWNjb3VudBJ8Cix0ZXJyYTFkcDNxMzA1aGd0dHQ4bjM0cnQ4cmc5eHBhbmM0Mno0eWU3dXBmZxJGCh8vY29zbW9zLmNyeXB0by5zZWNwMjU2azEuUHViS2V5EiMKIQL89yTJff+sICHvoYGML+87y7dTyiKROo21557Eo97g0RjZhgEgAw==","proofOps":null,"height":"2803726","codespace":""}}}`,
 			errors.New(`{"message": "custom HTTP error", "code": 123}`),
 			&TxInput{
-				TxInputEnvelope: xc.TxInputEnvelope{Type: "cosmos"},
+				TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverCosmos),
 				AccountNumber:   17241,
 				Sequence:        3,
 			},
@@ -205,6 +215,32 @@ func (s *CrosschainTestSuite) TestSubmitTxErr() {
 	require.ErrorContains(err, "unsupported protocol scheme")
 }
 
+func (s *CrosschainTestSuite) TestEstimateFee() {
+	require := s.Require()
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	to := xc.Address("terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn")
+	publicKey, _ := hex.DecodeString("02afeedb21a149fc0237978dccfe15d2c20e518eb77681eae2a5af9a973e83d893")
+	asset := &xc.AssetConfig{NativeAsset: xc.LUNA, ChainCoin: "uluna", ChainPrefix: "terra", ChainIDStr: "phoenix-1", Type: xc.AssetTypeNative}
+	input := &TxInput{AccountNumber: 1, Sequence: 2, GasLimit: 200_000, GasPrice: 0.015, FromPublicKey: publicKey}
+	builder, _ := NewTxBuilder(asset)
+	tx, err := builder.(TxBuilder).NewNativeTransfer(from, to, xc.NewAmountBlockchainFromUint64(1_000), input)
+	require.NoError(err)
+
+	simulated, _ := (&txtypes.SimulateResponse{GasInfo: &types.GasInfo{GasWanted: 200_000, GasUsed: 100_001}}).Marshal()
+	server, close := test.MockJSONRPC(&s.Suite, fmt.Sprintf(`{"response":{"code":0,"value":"%s","height":"100"}}`, base64.StdEncoding.EncodeToString(simulated)))
+	defer close()
+	asset.URL = server.URL
+	client, _ := NewClient(asset)
+
+	// 3000uluna for 200k gas, rounded up
+	fee, err := client.EstimateFee(s.Ctx, from, tx)
+	require.NoError(err)
+	require.Equal("1501", fee.String())
+
+	_, err = client.EstimateFee(s.Ctx, from, &test.MockXcTx{})
+	require.EqualError(err, "tx is not a cosmos tx with a gas limit")
+}
+
 func (s *CrosschainTestSuite) TestFetchTxInfo() {
 	require := s.Require()
 
@@ -236,6 +272,7 @@ func (s *CrosschainTestSuite) TestFetchTxInfo() {
 				ContractAddress: "",
 				Amount:          xc.NewAmountBlockchainFromUint64(5000000),
 				Fee:             xc.NewAmountBlockchainFromUint64(1000000),
+				GasUsed:         80283,
 				BlockIndex:      2754866,
 				BlockTime:       1668891362,
 				Confirmations:   48860,
@@ -276,6 +313,7 @@ func (s *CrosschainTestSuite) TestFetchTxInfo() {
 				ContractAddress: "",
 				Amount:          xc.NewAmountBlockchainFromUint64(5000000000000000),
 				Fee:             xc.NewAmountBlockchainFromUint64(112200000000000000),
+				GasUsed:         93146,
 				BlockIndex:      1359533,
 				BlockTime:       1669849454,
 				Confirmations:   107,
@@ -464,3 +502,30 @@ func (s *CrosschainTestSuite) TestFetchBalance() {
 		}
 	}
 }
+
+func (s *CrosschainTestSuite) TestEstimateGasMinGasPrice() {
+	require := s.Require()
+	client, err := NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM})
+	require.NoError(err)
+	_, err = client.EstimateGas(s.Ctx)
+	require.EqualError(err, "not implemented")
+
+	// the min gas price is used without estimator
+	client, err = NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM, MinGasPrice: 0.025})
+	require.NoError(err)
+	gasPrice, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.NewAmountBlockchainToMaskFloat64(0.025), gasPrice)
+
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainToMaskFloat64(0.001), nil
+	})
+	gasPrice, err = client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.NewAmountBlockchainToMaskFloat64(0.025), gasPrice)
+
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM, MinGasPrice: 0.025, ChainGasPriceDefault: 0.1})
+	gasPrice, err = client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.NewAmountBlockchainToMaskFloat64(0.1), gasPrice)
+}
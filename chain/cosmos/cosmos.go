@@ -150,6 +150,7 @@ func MakeCosmosConfig() EncodingConfig {
 	injectivecodec.RegisterInterfaces(cosmosCfg.InterfaceRegistry)
 	authVestingTypes.RegisterInterfaces(cosmosCfg.InterfaceRegistry)
 	cosmosCfg.InterfaceRegistry.RegisterImplementations((*cryptotypes.PubKey)(nil), &injethsecp256k1.PubKey{})
+	applyRegistrations(cosmosCfg.InterfaceRegistry)
 	return cosmosCfg
 }
 
@@ -0,0 +1,60 @@
+package cosmos
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+var now = time.Now
+
+// Diagnose inspects the result of a tx and the sync status of the node to tell why a tx is not confirmed
+func (client *Client) Diagnose(ctx context.Context, request xc.DiagnoseRequest) (*xc.Diagnosis, error) {
+	hash, err := hex.DecodeString(string(request.TxHash))
+	if err != nil {
+		return nil, err
+	}
+
+	resultRaw, err := client.Ctx.Client.Tx(ctx, hash, false)
+	if err == nil {
+		result := resultRaw.TxResult
+		if result.Code == 0 {
+			return xc.NewDiagnosis(request.TxHash, xc.StuckReasonNone, ""), nil
+		}
+		details := fmt.Sprintf("code %d: %s", result.Code, result.Log)
+		if result.Codespace == sdkerrors.RootCodespace {
+			switch result.Code {
+			case sdkerrors.ErrOutOfGas.ABCICode():
+				return xc.NewDiagnosis(request.TxHash, xc.StuckReasonOutOfGas, details), nil
+			case sdkerrors.ErrInsufficientFee.ABCICode():
+				return xc.NewDiagnosis(request.TxHash, xc.StuckReasonFeeTooLow, details), nil
+			}
+		}
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonFromError(result.Log), details), nil
+	}
+	// e.g. "tx (E9C2...) not found"
+	if !strings.Contains(err.Error(), "not found") {
+		return nil, fmt.Errorf("could not fetch tx %s: %v", request.TxHash, err)
+	}
+
+	status, err := client.Ctx.Client.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch node status: %v", err)
+	}
+	if status.SyncInfo.CatchingUp {
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonProviderLagging, fmt.Sprintf("catching up at block %d", status.SyncInfo.LatestBlockHeight)), nil
+	}
+	age := now().Sub(status.SyncInfo.LatestBlockTime)
+	if age > xc.DefaultProviderLagThreshold {
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonProviderLagging, fmt.Sprintf("latest block %d is %s old", status.SyncInfo.LatestBlockHeight, age.Round(time.Second))), nil
+	}
+	if request.IsPropagating(now()) {
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonPending, "not included yet"), nil
+	}
+	return xc.NewDiagnosis(request.TxHash, xc.StuckReasonDropped, "not included in a block"), nil
+}
@@ -0,0 +1,57 @@
+package cosmos
+
+import (
+	"fmt"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestDiagnose() {
+	require := s.Require()
+	latestBlockTime := time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+	defer func() { now = time.Now }()
+
+	txResult := func(code int, codespace string, log string) string {
+		return fmt.Sprintf(`{"hash":"E9C24C2E23CDCA56C8CE87A583149F8F88E75923F0CD958C003A84F631948978","height":"11","index":0,"tx_result":{"code":%d,"data":null,"log":"%s","info":"","gas_wanted":"100000","gas_used":"100000","events":[],"codespace":"%s"},"tx":"CgA="}`, code, log, codespace)
+	}
+	notFound := `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error","data":"tx (E9C24C2E23CDCA56C8CE87A583149F8F88E75923F0CD958C003A84F631948978) not found"},"id":0}`
+	status := func(catchingUp string) string {
+		// tendermint checks the id of responses
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"result":{"node_info":{"protocol_version":{"p2p":"8","block":"11","app":"0"},"id":"","listen_addr":"","network":"cosmoshub-4","version":"0.34.27","channels":"","moniker":"","other":{"tx_index":"on","rpc_address":""}},"sync_info":{"latest_block_hash":"","latest_app_hash":"","latest_block_height":"100","latest_block_time":"2023-05-03T14:00:00Z","earliest_block_hash":"","earliest_app_hash":"","earliest_block_height":"1","earliest_block_time":"2023-05-03T13:00:00Z","catching_up":%s}}}`, catchingUp)
+	}
+	vectors := []struct {
+		name    string
+		resp    []string
+		now     time.Time
+		request xc.DiagnoseRequest
+		reason  xc.StuckReason
+	}{
+		{"confirmed", []string{txResult(0, "", "")}, latestBlockTime, xc.DiagnoseRequest{}, xc.StuckReasonNone},
+		{"out of gas", []string{txResult(11, "sdk", "out of gas in location: WriteFlat; gasWanted: 100000, gasUsed: 100311")}, latestBlockTime, xc.DiagnoseRequest{}, xc.StuckReasonOutOfGas},
+		{"fee too low", []string{txResult(13, "sdk", "insufficient fees")}, latestBlockTime, xc.DiagnoseRequest{}, xc.StuckReasonFeeTooLow},
+		{"memo", []string{txResult(18, "wasm", "memo is required")}, latestBlockTime, xc.DiagnoseRequest{}, xc.StuckReasonMemoRequired},
+		{"failed", []string{txResult(5, "sdk", "insufficient funds")}, latestBlockTime, xc.DiagnoseRequest{}, xc.StuckReasonFailed},
+		{"catching up", []string{notFound, status("true")}, latestBlockTime, xc.DiagnoseRequest{}, xc.StuckReasonProviderLagging},
+		{"lagging", []string{notFound, status("false")}, latestBlockTime.Add(time.Hour), xc.DiagnoseRequest{}, xc.StuckReasonProviderLagging},
+		{"propagating", []string{notFound, status("false")}, latestBlockTime, xc.DiagnoseRequest{SubmittedAt: latestBlockTime.Add(-10 * time.Second)}, xc.StuckReasonPending},
+		{"dropped", []string{notFound, status("false")}, latestBlockTime, xc.DiagnoseRequest{SubmittedAt: latestBlockTime.Add(-10 * time.Minute)}, xc.StuckReasonDropped},
+	}
+	for _, v := range vectors {
+		server, close := test.MockJSONRPC(&s.Suite, v.resp)
+		client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM, URL: server.URL})
+		now = func() time.Time { return v.now }
+		v.request.TxHash = "E9C24C2E23CDCA56C8CE87A583149F8F88E75923F0CD958C003A84F631948978"
+
+		diagnosis, err := client.Diagnose(s.Ctx, v.request)
+		require.NoError(err, v.name)
+		require.Equal(v.reason, diagnosis.Reason, v.name)
+		require.Equal(len(v.resp), server.Counter, v.name)
+		close()
+	}
+
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM})
+	_, err := client.Diagnose(s.Ctx, xc.DiagnoseRequest{TxHash: "0xinvalid"})
+	require.Error(err)
+}
@@ -0,0 +1,76 @@
+package dydx
+
+import (
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/cosmos"
+)
+
+// TxBuilder for dYdX, a Cosmos TxBuilder building the txs of the subaccounts as well
+type TxBuilder struct {
+	cosmos.TxBuilder
+}
+
+// NewTxBuilder creates a new dYdX TxBuilder
+func NewTxBuilder(asset xc.ITask) (*TxBuilder, error) {
+	builder, err := cosmos.NewTxBuilder(asset)
+	if err != nil {
+		return nil, err
+	}
+	return &TxBuilder{builder.(cosmos.TxBuilder)}, nil
+}
+
+// NewDepositToSubaccount deposits amount of USDC of from to one of its subaccounts
+func (txBuilder TxBuilder) NewDepositToSubaccount(from xc.Address, subaccount uint32, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	quantums, err := parseQuantums(amount)
+	if err != nil {
+		return nil, err
+	}
+	msg := &MsgDepositToSubaccount{
+		Sender:    string(from),
+		Recipient: SubaccountId{Owner: string(from), Number: subaccount},
+		AssetId:   AssetUSDC,
+		Quantums:  quantums,
+	}
+	return txBuilder.NewTxWithMsgs(input, msg)
+}
+
+// NewWithdrawFromSubaccount withdraws amount of USDC of a subaccount of from to the address to
+func (txBuilder TxBuilder) NewWithdrawFromSubaccount(from xc.Address, subaccount uint32, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	quantums, err := parseQuantums(amount)
+	if err != nil {
+		return nil, err
+	}
+	msg := &MsgWithdrawFromSubaccount{
+		Sender:    SubaccountId{Owner: string(from), Number: subaccount},
+		Recipient: string(to),
+		AssetId:   AssetUSDC,
+		Quantums:  quantums,
+	}
+	return txBuilder.NewTxWithMsgs(input, msg)
+}
+
+// NewSubaccountTransfer transfers amount of USDC between subaccounts, signed by the owner of from
+func (txBuilder TxBuilder) NewSubaccountTransfer(from SubaccountId, to SubaccountId, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	quantums, err := parseQuantums(amount)
+	if err != nil {
+		return nil, err
+	}
+	msg := &MsgCreateTransfer{
+		Transfer: &Transfer{
+			Sender:    from,
+			Recipient: to,
+			AssetId:   AssetUSDC,
+			Amount:    quantums,
+		},
+	}
+	return txBuilder.NewTxWithMsgs(input, msg)
+}
+
+func parseQuantums(amount xc.AmountBlockchain) (uint64, error) {
+	if !amount.Int().IsUint64() {
+		return 0, fmt.Errorf("invalid amount %s: must fit in a uint64", amount.String())
+	}
+	return amount.Uint64(), nil
+}
@@ -0,0 +1,219 @@
+// Package dydx adds the subaccounts of dYdX v4 to the Cosmos driver, and is an example of the support of an
+// app-chain by a package outside of the driver:
+//   - the msgs of its modules are registered with cosmos.RegisterMsgs, so txs with them are decoded by the clients
+//     and by cosmos.ParseTx instead of skipping them
+//   - the msgs that are transfers are parsed by a parser registered with cosmos.RegisterTransferParser, so these txs
+//     have their from, to, amount, sources and destinations
+//   - txs with these msgs are built with cosmos.TxBuilder.NewTxWithMsgs
+//
+// Registrations are done by init, importing the package is enough, before creating clients and builders.
+// The msgs are hand-written gogoproto types, with the fields of the protobuf definitions of the chain.
+package dydx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/gogo/protobuf/proto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/cosmos"
+)
+
+// Prefix is the bech32 prefix of dYdX addresses
+const Prefix = "dydx"
+
+// AssetUSDC is the id of USDC in the subaccounts, the only asset of their transfers, in quantums of 10^-6 USDC
+const AssetUSDC = 0
+
+// USDCDenom is the denom of USDC on dYdX, transferred via IBC from Noble
+const USDCDenom = "ibc/8E27BA2D5493AF5636760E354E46004562C46AB7EC0CC4C1CA14E9E20E2545B5"
+
+// SubaccountId is dydxprotocol.subaccounts.SubaccountId, a subaccount of an address
+type SubaccountId struct {
+	Owner  string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Number uint32 `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+}
+
+func (m *SubaccountId) Reset()         { *m = SubaccountId{} }
+func (m *SubaccountId) String() string { return proto.CompactTextString(m) }
+func (*SubaccountId) ProtoMessage()    {}
+
+// Transfer is dydxprotocol.sending.Transfer, a transfer of Amount quantums of an asset between subaccounts
+type Transfer struct {
+	Sender    SubaccountId `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender"`
+	Recipient SubaccountId `protobuf:"bytes,2,opt,name=recipient,proto3" json:"recipient"`
+	AssetId   uint32       `protobuf:"varint,3,opt,name=asset_id,json=assetId,proto3" json:"asset_id,omitempty"`
+	Amount    uint64       `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+}
+
+func (m *Transfer) Reset()         { *m = Transfer{} }
+func (m *Transfer) String() string { return proto.CompactTextString(m) }
+func (*Transfer) ProtoMessage()    {}
+
+// MsgCreateTransfer is dydxprotocol.sending.MsgCreateTransfer, a transfer between subaccounts
+type MsgCreateTransfer struct {
+	Transfer *Transfer `protobuf:"bytes,1,opt,name=transfer,proto3" json:"transfer,omitempty"`
+}
+
+func (m *MsgCreateTransfer) Reset()         { *m = MsgCreateTransfer{} }
+func (m *MsgCreateTransfer) String() string { return proto.CompactTextString(m) }
+func (*MsgCreateTransfer) ProtoMessage()    {}
+
+// MsgDepositToSubaccount is dydxprotocol.sending.MsgDepositToSubaccount, a transfer from the bank balance of an
+// address to a subaccount
+type MsgDepositToSubaccount struct {
+	Sender    string       `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Recipient SubaccountId `protobuf:"bytes,2,opt,name=recipient,proto3" json:"recipient"`
+	AssetId   uint32       `protobuf:"varint,3,opt,name=asset_id,json=assetId,proto3" json:"asset_id,omitempty"`
+	Quantums  uint64       `protobuf:"varint,4,opt,name=quantums,proto3" json:"quantums,omitempty"`
+}
+
+func (m *MsgDepositToSubaccount) Reset()         { *m = MsgDepositToSubaccount{} }
+func (m *MsgDepositToSubaccount) String() string { return proto.CompactTextString(m) }
+func (*MsgDepositToSubaccount) ProtoMessage()    {}
+
+// MsgWithdrawFromSubaccount is dydxprotocol.sending.MsgWithdrawFromSubaccount, a transfer from a subaccount to the
+// bank balance of an address
+type MsgWithdrawFromSubaccount struct {
+	Recipient string       `protobuf:"bytes,1,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	Sender    SubaccountId `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender"`
+	AssetId   uint32       `protobuf:"varint,3,opt,name=asset_id,json=assetId,proto3" json:"asset_id,omitempty"`
+	Quantums  uint64       `protobuf:"varint,4,opt,name=quantums,proto3" json:"quantums,omitempty"`
+}
+
+func (m *MsgWithdrawFromSubaccount) Reset()         { *m = MsgWithdrawFromSubaccount{} }
+func (m *MsgWithdrawFromSubaccount) String() string { return proto.CompactTextString(m) }
+func (*MsgWithdrawFromSubaccount) ProtoMessage()    {}
+
+var _ types.Msg = &MsgCreateTransfer{}
+var _ types.Msg = &MsgDepositToSubaccount{}
+var _ types.Msg = &MsgWithdrawFromSubaccount{}
+
+func init() {
+	proto.RegisterType((*SubaccountId)(nil), "dydxprotocol.subaccounts.SubaccountId")
+	proto.RegisterType((*Transfer)(nil), "dydxprotocol.sending.Transfer")
+	proto.RegisterType((*MsgCreateTransfer)(nil), "dydxprotocol.sending.MsgCreateTransfer")
+	proto.RegisterType((*MsgDepositToSubaccount)(nil), "dydxprotocol.sending.MsgDepositToSubaccount")
+	proto.RegisterType((*MsgWithdrawFromSubaccount)(nil), "dydxprotocol.sending.MsgWithdrawFromSubaccount")
+	cosmos.RegisterMsgs(&MsgCreateTransfer{}, &MsgDepositToSubaccount{}, &MsgWithdrawFromSubaccount{})
+	cosmos.RegisterTransferParser(parseTransfer)
+}
+
+// ValidateBasic checks the subaccounts, the asset and the amount
+func (m *MsgCreateTransfer) ValidateBasic() error {
+	if m.Transfer == nil {
+		return errors.New("invalid transfer: missing transfer")
+	}
+	if err := m.Transfer.Sender.validate(); err != nil {
+		return fmt.Errorf("invalid sender: %v", err)
+	}
+	if err := m.Transfer.Recipient.validate(); err != nil {
+		return fmt.Errorf("invalid recipient: %v", err)
+	}
+	if m.Transfer.Sender == m.Transfer.Recipient {
+		return errors.New("invalid transfer: the sender and the recipient are the same subaccount")
+	}
+	return validateAmount(m.Transfer.AssetId, m.Transfer.Amount)
+}
+
+// GetSigners returns the owner of the sender subaccount
+func (m *MsgCreateTransfer) GetSigners() []types.AccAddress {
+	if m.Transfer == nil {
+		return []types.AccAddress{}
+	}
+	return []types.AccAddress{accAddress(m.Transfer.Sender.Owner)}
+}
+
+// ValidateBasic checks the sender, the subaccount, the asset and the amount
+func (m *MsgDepositToSubaccount) ValidateBasic() error {
+	if _, err := accAddressFromBech32(m.Sender); err != nil {
+		return fmt.Errorf("invalid sender: %v", err)
+	}
+	if err := m.Recipient.validate(); err != nil {
+		return fmt.Errorf("invalid recipient: %v", err)
+	}
+	return validateAmount(m.AssetId, m.Quantums)
+}
+
+// GetSigners returns the sender
+func (m *MsgDepositToSubaccount) GetSigners() []types.AccAddress {
+	return []types.AccAddress{accAddress(m.Sender)}
+}
+
+// ValidateBasic checks the subaccount, the recipient, the asset and the amount
+func (m *MsgWithdrawFromSubaccount) ValidateBasic() error {
+	if err := m.Sender.validate(); err != nil {
+		return fmt.Errorf("invalid sender: %v", err)
+	}
+	if _, err := accAddressFromBech32(m.Recipient); err != nil {
+		return fmt.Errorf("invalid recipient: %v", err)
+	}
+	return validateAmount(m.AssetId, m.Quantums)
+}
+
+// GetSigners returns the owner of the sender subaccount
+func (m *MsgWithdrawFromSubaccount) GetSigners() []types.AccAddress {
+	return []types.AccAddress{accAddress(m.Sender.Owner)}
+}
+
+// parseTransfer returns the transfer of USDC of a msg of the sending module, between the owners of the subaccounts
+func parseTransfer(msg types.Msg) (*cosmos.Transfer, bool) {
+	var sender, receiver string
+	var assetID uint32
+	var amount uint64
+	switch msg := msg.(type) {
+	case *MsgCreateTransfer:
+		if msg.Transfer == nil {
+			return nil, false
+		}
+		sender, receiver = msg.Transfer.Sender.Owner, msg.Transfer.Recipient.Owner
+		assetID, amount = msg.Transfer.AssetId, msg.Transfer.Amount
+	case *MsgDepositToSubaccount:
+		sender, receiver, assetID, amount = msg.Sender, msg.Recipient.Owner, msg.AssetId, msg.Quantums
+	case *MsgWithdrawFromSubaccount:
+		sender, receiver, assetID, amount = msg.Sender.Owner, msg.Recipient, msg.AssetId, msg.Quantums
+	default:
+		return nil, false
+	}
+	if assetID != AssetUSDC {
+		return nil, false
+	}
+	return &cosmos.Transfer{
+		Sender:   xc.Address(sender),
+		Receiver: xc.Address(receiver),
+		Denom:    USDCDenom,
+		Amount:   xc.NewAmountBlockchainFromUint64(amount),
+	}, true
+}
+
+func (subaccount SubaccountId) validate() error {
+	_, err := accAddressFromBech32(subaccount.Owner)
+	return err
+}
+
+func validateAmount(assetID uint32, amount uint64) error {
+	if assetID != AssetUSDC {
+		return fmt.Errorf("invalid asset id %d: only USDC is supported", assetID)
+	}
+	if amount == 0 {
+		return errors.New("invalid amount: must be positive")
+	}
+	return nil
+}
+
+func accAddressFromBech32(address string) (types.AccAddress, error) {
+	addressBytes, err := types.GetFromBech32(address, Prefix)
+	if err != nil {
+		return nil, err
+	}
+	if err := types.VerifyAddressFormat(addressBytes); err != nil {
+		return nil, err
+	}
+	return addressBytes, nil
+}
+
+func accAddress(address string) types.AccAddress {
+	addressBytes, _ := accAddressFromBech32(address)
+	return addressBytes
+}
@@ -0,0 +1,140 @@
+package dydx
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/cosmos"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+}
+
+func TestDydxTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+const testAddress = xc.Address("dydx1dp3q305hgttt8n34rt8rg9xpanc42z4ykrg9tl")
+const testRecipient = xc.Address("dydx1zyg3zyg3zyg3zyg3zyg3zyg3zyg3zyg3gyexjc")
+
+var testAsset = &xc.NativeAssetConfig{NativeAsset: "DYDX", ChainCoin: "adydx", ChainPrefix: Prefix, ChainIDStr: "dydx-mainnet-1", ChainGasPriceDefault: 12_500_000_000}
+
+func (s *CrosschainTestSuite) TestWireFormat() {
+	require := s.Require()
+	vectors := []struct {
+		msg      proto.Message
+		expected string
+	}{
+		{
+			&MsgDepositToSubaccount{Sender: "a", Recipient: SubaccountId{Owner: "b", Number: 1}, Quantums: 1_000_000},
+			"0a0161" + "12050a01621001" + "20c0843d",
+		},
+		{
+			&MsgWithdrawFromSubaccount{Recipient: "a", Sender: SubaccountId{Owner: "b"}, Quantums: 1},
+			"0a0161" + "12030a0162" + "2001",
+		},
+		{
+			&MsgCreateTransfer{Transfer: &Transfer{Sender: SubaccountId{Owner: "a"}, Recipient: SubaccountId{Owner: "b", Number: 1}, Amount: 5}},
+			"0a0e" + "0a030a0161" + "12050a01621001" + "2005",
+		},
+	}
+	for _, v := range vectors {
+		encoded, err := proto.Marshal(v.msg)
+		require.NoError(err)
+		require.Equal(v.expected, hex.EncodeToString(encoded))
+	}
+}
+
+func (s *CrosschainTestSuite) TestNewDepositToSubaccount() {
+	require := s.Require()
+	builder, err := NewTxBuilder(testAsset)
+	require.NoError(err)
+	tx, err := builder.NewDepositToSubaccount(testAddress, 0, xc.NewAmountBlockchainFromUint64(25_000_000), &cosmos.TxInput{GasPrice: 12_500_000_000})
+	require.NoError(err)
+	msg := tx.(*cosmos.Tx).ParsedTransfers[0].(*MsgDepositToSubaccount)
+	require.Equal(string(testAddress), msg.Sender)
+	require.Equal(SubaccountId{Owner: string(testAddress), Number: 0}, msg.Recipient)
+	require.EqualValues(25_000_000, msg.Quantums)
+
+	// the transfer of the msg is parsed by the driver
+	require.Equal(testAddress, tx.(*cosmos.Tx).From())
+	require.Equal(testAddress, tx.(*cosmos.Tx).To())
+	require.Equal("25000000", tx.(*cosmos.Tx).Amount().String())
+	require.Equal(xc.ContractAddress(USDCDenom), tx.(*cosmos.Tx).ContractAddress())
+	// 200_000 gas per msg by default
+	require.Equal("2500000000000000", tx.(*cosmos.Tx).Fee().String())
+
+	// and the msg of a signed tx is decoded
+	require.NoError(tx.AddSignatures(make([]byte, 64)))
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	parsed, err := cosmos.ParseTx(serialized)
+	require.NoError(err)
+	require.Len(parsed.UnknownMsgTypes(), 0)
+	require.Equal(msg, parsed.CosmosTx.GetMsgs()[0])
+	require.Equal(testAddress, parsed.From())
+	require.Equal("25000000", parsed.Amount().String())
+}
+
+func (s *CrosschainTestSuite) TestNewWithdrawFromSubaccount() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testAsset)
+	tx, err := builder.NewWithdrawFromSubaccount(testAddress, 1, testRecipient, xc.NewAmountBlockchainFromUint64(7), &cosmos.TxInput{})
+	require.NoError(err)
+	msg := tx.(*cosmos.Tx).ParsedTransfers[0].(*MsgWithdrawFromSubaccount)
+	require.Equal(SubaccountId{Owner: string(testAddress), Number: 1}, msg.Sender)
+	require.Equal(string(testRecipient), msg.Recipient)
+
+	destinations := tx.(*cosmos.Tx).Destinations()
+	require.Len(destinations, 1)
+	require.Equal(testRecipient, destinations[0].Address)
+	require.Equal(xc.ContractAddress(USDCDenom), destinations[0].ContractAddress)
+	require.Equal("7", destinations[0].Amount.String())
+	require.Equal(testAddress, tx.(*cosmos.Tx).Sources()[0].Address)
+}
+
+func (s *CrosschainTestSuite) TestNewSubaccountTransfer() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testAsset)
+	from := SubaccountId{Owner: string(testAddress), Number: 0}
+	to := SubaccountId{Owner: string(testRecipient), Number: 127}
+	tx, err := builder.NewSubaccountTransfer(from, to, xc.NewAmountBlockchainFromUint64(1_000_000), &cosmos.TxInput{})
+	require.NoError(err)
+	msg := tx.(*cosmos.Tx).ParsedTransfers[0].(*MsgCreateTransfer)
+	require.Equal(&Transfer{Sender: from, Recipient: to, AssetId: AssetUSDC, Amount: 1_000_000}, msg.Transfer)
+	require.Equal(testRecipient, tx.(*cosmos.Tx).To())
+	signer, _ := accAddressFromBech32(string(testAddress))
+	require.Equal(signer, msg.GetSigners()[0])
+}
+
+func (s *CrosschainTestSuite) TestSubaccountErrors() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testAsset)
+	amount := xc.NewAmountBlockchainFromUint64(1)
+	from := SubaccountId{Owner: string(testAddress)}
+
+	_, err := builder.NewDepositToSubaccount(testAddress, 0, xc.NewAmountBlockchainFromUint64(0), &cosmos.TxInput{})
+	require.EqualError(err, "invalid amount: must be positive")
+	_, err = builder.NewDepositToSubaccount(testAddress, 0, xc.NewAmountBlockchainFromStr("18446744073709551616"), &cosmos.TxInput{})
+	require.EqualError(err, "invalid amount 18446744073709551616: must fit in a uint64")
+	_, err = builder.NewDepositToSubaccount("cosmos1dp3q305hgttt8n34rt8rg9xpanc42z4yl6xptg", 0, amount, &cosmos.TxInput{})
+	require.ErrorContains(err, "invalid sender: invalid Bech32 prefix")
+	_, err = builder.NewWithdrawFromSubaccount(testAddress, 0, "dydx1", amount, &cosmos.TxInput{})
+	require.ErrorContains(err, "invalid recipient")
+	_, err = builder.NewSubaccountTransfer(from, from, amount, &cosmos.TxInput{})
+	require.EqualError(err, "invalid transfer: the sender and the recipient are the same subaccount")
+	_, err = builder.NewSubaccountTransfer(from, SubaccountId{}, amount, &cosmos.TxInput{})
+	require.ErrorContains(err, "invalid recipient")
+	_, err = builder.NewDepositToSubaccount(testAddress, 0, amount, &xc.TxInputEnvelope{})
+	require.EqualError(err, "xc.TxInput is not from a cosmos chain")
+	_, err = builder.NewTxWithMsgs(&cosmos.TxInput{})
+	require.EqualError(err, "no msgs")
+
+	msg := &MsgDepositToSubaccount{Sender: string(testAddress), Recipient: from, AssetId: 1, Quantums: 1}
+	require.EqualError(msg.ValidateBasic(), "invalid asset id 1: only USDC is supported")
+	require.EqualError((&MsgCreateTransfer{}).ValidateBasic(), "invalid transfer: missing transfer")
+}
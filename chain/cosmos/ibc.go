@@ -0,0 +1,130 @@
+package cosmos
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v3/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v3/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v3/modules/core/04-channel/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// DefaultIBCTransferTimeout is the timeout of IBC transfers without a timeout
+const DefaultIBCTransferTimeout = 10 * time.Minute
+
+// IBCTransferOptions are the channel and timeout of an IBC transfer
+type IBCTransferOptions struct {
+	// SourcePort of the channel, transfer if empty
+	SourcePort string
+	// SourceChannel is the channel to the destination chain, e.g. channel-141 from the Cosmos Hub to Osmosis
+	SourceChannel string
+	// TimeoutHeight is the height of the destination chain after which the transfer is refunded, none if zero
+	TimeoutHeight clienttypes.Height
+	// Timeout is the time after which the transfer is refunded,
+	// DefaultIBCTransferTimeout if zero and there's no TimeoutHeight
+	Timeout time.Duration
+}
+
+// NewIBCTransfer creates a new transfer of amount to an address of another chain, through the channel of options
+func (txBuilder TxBuilder) NewIBCTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, options IBCTransferOptions, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	asset := txBuilder.Asset
+	amountInt := big.Int(amount)
+
+	if options.SourceChannel == "" {
+		return nil, errors.New("ibc transfer requires a source channel")
+	}
+	if to == "" {
+		return nil, errors.New("ibc transfer requires a receiver")
+	}
+	if !isNativeAsset(asset.GetAssetConfig()) {
+		return nil, errors.New("cw20 tokens can't be transferred with ibc transfers")
+	}
+	_, err := accAddressFromBech32WithPrefix(string(from), asset.GetNativeAsset().ChainPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	denom := asset.GetNativeAsset().ChainCoin
+	if token, ok := asset.(*xc.TokenAssetConfig); ok {
+		if token.Contract != "" {
+			denom = token.Contract
+		}
+	}
+	port := options.SourcePort
+	if port == "" {
+		port = transfertypes.PortID
+	}
+	timeout := options.Timeout
+	if timeout == 0 && options.TimeoutHeight.IsZero() {
+		timeout = DefaultIBCTransferTimeout
+	}
+	var timeoutTimestamp uint64
+	if timeout > 0 {
+		timeoutTimestamp = uint64(now().Add(timeout).UnixNano())
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 400_000
+	}
+
+	msg := &transfertypes.MsgTransfer{
+		SourcePort:    port,
+		SourceChannel: options.SourceChannel,
+		Token: types.Coin{
+			Denom:  denom,
+			Amount: types.NewIntFromBigInt(&amountInt),
+		},
+		Sender:           string(from),
+		Receiver:         string(to),
+		TimeoutHeight:    options.TimeoutHeight,
+		TimeoutTimestamp: timeoutTimestamp,
+	}
+	return txBuilder.createTxWithMsgs(txInput, msg)
+}
+
+// parseIBCTransfer returns the IBC transfer of fungible tokens of a MsgTransfer (outgoing) or MsgRecvPacket (incoming)
+func parseIBCTransfer(msg types.Msg) (*Transfer, bool) {
+	switch msg := msg.(type) {
+	case *transfertypes.MsgTransfer:
+		return &Transfer{
+			Sender:   xc.Address(msg.Sender),
+			Receiver: xc.Address(msg.Receiver),
+			Denom:    msg.Token.Denom,
+			Amount:   xc.AmountBlockchain(*msg.Token.Amount.BigInt()),
+		}, true
+	case *channeltypes.MsgRecvPacket:
+		packet := msg.Packet
+		if packet.DestinationPort != transfertypes.PortID {
+			return nil, false
+		}
+		var data transfertypes.FungibleTokenPacketData
+		if err := transfertypes.ModuleCdc.UnmarshalJSON(packet.GetData(), &data); err != nil {
+			return nil, false
+		}
+		amount, ok := types.NewIntFromString(data.Amount)
+		if !ok || amount.IsNegative() {
+			return nil, false
+		}
+		// tokens returning to their source chain are unwrapped, others are received as an ibc/ denom
+		var denom string
+		if transfertypes.ReceiverChainIsSource(packet.SourcePort, packet.SourceChannel, data.Denom) {
+			denom = strings.TrimPrefix(data.Denom, transfertypes.GetDenomPrefix(packet.SourcePort, packet.SourceChannel))
+			if trace := transfertypes.ParseDenomTrace(denom); trace.Path != "" {
+				denom = trace.IBCDenom()
+			}
+		} else {
+			denom = transfertypes.GetTransferCoin(packet.DestinationPort, packet.DestinationChannel, data.Denom, amount).Denom
+		}
+		return &Transfer{
+			Sender:   xc.Address(data.Sender),
+			Receiver: xc.Address(data.Receiver),
+			Denom:    denom,
+			Amount:   xc.AmountBlockchain(*amount.BigInt()),
+		}, true
+	}
+	return nil, false
+}
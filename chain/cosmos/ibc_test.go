@@ -0,0 +1,108 @@
+package cosmos
+
+import (
+	"encoding/hex"
+	"time"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	transfertypes "github.com/cosmos/ibc-go/v3/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v3/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v3/modules/core/04-channel/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestNewIBCTransfer() {
+	require := s.Require()
+	from, _ := convertBech32Prefix("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "terra", "cosmos")
+	to, _ := convertBech32Prefix("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "terra", "osmo")
+	publicKey, _ := hex.DecodeString("02afeedb21a149fc0237978dccfe15d2c20e518eb77681eae2a5af9a973e83d893")
+	sent := time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+	defer func() { now = time.Now }()
+	now = func() time.Time { return sent }
+	asset := &xc.AssetConfig{NativeAsset: "ATOM", ChainCoin: "uatom", ChainPrefix: "cosmos", ChainIDStr: "cosmoshub-4", Type: xc.AssetTypeNative}
+
+	builder, _ := NewTxBuilder(asset)
+	tx, err := builder.(TxBuilder).NewIBCTransfer(from, to, xc.NewAmountBlockchainFromUint64(1_000_000), IBCTransferOptions{SourceChannel: "channel-141"}, &TxInput{FromPublicKey: publicKey})
+	require.NoError(err)
+	msg := tx.(*Tx).ParsedTransfers[0].(*transfertypes.MsgTransfer)
+	require.Equal("transfer", msg.SourcePort)
+	require.Equal("channel-141", msg.SourceChannel)
+	require.Equal("1000000uatom", msg.Token.String())
+	require.True(msg.TimeoutHeight.IsZero())
+	require.EqualValues(sent.Add(DefaultIBCTransferTimeout).UnixNano(), msg.TimeoutTimestamp)
+
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	parsed, err := ParseTx(serialized)
+	require.NoError(err)
+	require.Equal(from, parsed.From())
+	require.Equal(to, parsed.To())
+	require.Equal("1000000", parsed.Amount().String())
+	require.Equal(xc.ContractAddress(""), parsed.ContractAddress())
+	require.Equal([]*xc.TxInfoEndpoint{{Address: from}}, parsed.Sources())
+	require.Equal([]*xc.TxInfoEndpoint{{Address: to, ContractAddress: "uatom", Amount: xc.NewAmountBlockchainFromUint64(1_000_000)}}, parsed.Destinations())
+
+	// ibc denom, timeout height only
+	token := &xc.TokenAssetConfig{Asset: "OSMO", Contract: "ibc/14F9BC3E44B8A9C1BE1FB08980FAB87034C9905EF17CF2F5008FC085218811CC", NativeAssetConfig: asset}
+	builder, _ = NewTxBuilder(token)
+	options := IBCTransferOptions{SourcePort: "transfer", SourceChannel: "channel-141", TimeoutHeight: clienttypes.NewHeight(1, 9_000_000)}
+	tx, err = builder.(TxBuilder).NewIBCTransfer(from, to, xc.NewAmountBlockchainFromUint64(5), options, &TxInput{FromPublicKey: publicKey})
+	require.NoError(err)
+	msg = tx.(*Tx).ParsedTransfers[0].(*transfertypes.MsgTransfer)
+	require.Equal(token.Contract, msg.Token.Denom)
+	require.EqualValues(9_000_000, msg.TimeoutHeight.RevisionHeight)
+	require.Zero(msg.TimeoutTimestamp)
+	require.Equal(xc.ContractAddress(token.Contract), tx.(*Tx).ContractAddress())
+}
+
+func (s *CrosschainTestSuite) TestNewIBCTransferErrors() {
+	require := s.Require()
+	from, _ := convertBech32Prefix("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "terra", "cosmos")
+	to, _ := convertBech32Prefix("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "terra", "osmo")
+	options := IBCTransferOptions{SourceChannel: "channel-141"}
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: "ATOM", ChainCoin: "uatom", ChainPrefix: "cosmos", Type: xc.AssetTypeNative})
+
+	_, err := builder.(TxBuilder).NewIBCTransfer(from, to, xc.NewAmountBlockchainFromUint64(1), IBCTransferOptions{}, &TxInput{})
+	require.EqualError(err, "ibc transfer requires a source channel")
+	_, err = builder.(TxBuilder).NewIBCTransfer(from, "", xc.NewAmountBlockchainFromUint64(1), options, &TxInput{})
+	require.EqualError(err, "ibc transfer requires a receiver")
+	_, err = builder.(TxBuilder).NewIBCTransfer(to, from, xc.NewAmountBlockchainFromUint64(1), options, &TxInput{})
+	require.ErrorContains(err, "invalid Bech32 prefix")
+
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: "LUNA", ChainPrefix: "terra", Type: xc.AssetTypeToken, Contract: "terra14z56l0fp2lsf86zy3hty2z47ezkhnthtr9yq76"})
+	_, err = builder.(TxBuilder).NewIBCTransfer("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", to, xc.NewAmountBlockchainFromUint64(1), options, &TxInput{})
+	require.EqualError(err, "cw20 tokens can't be transferred with ibc transfers")
+}
+
+func (s *CrosschainTestSuite) TestParseIBCTransferReceived() {
+	require := s.Require()
+	sender := "osmo1dp3q305hgttt8n34rt8rg9xpanc42z4yhvpsdj"
+	receiver := "cosmos1ykhtdnhm9vjltkm4ec9ylwf2f7l9ar99m0tcmf"
+	recv := func(denom string, destinationPort string) []byte {
+		data := transfertypes.NewFungibleTokenPacketData(denom, "2500000", sender, receiver)
+		packet := channeltypes.NewPacket(data.GetBytes(), 7, "transfer", "channel-0", destinationPort, "channel-141", clienttypes.NewHeight(0, 0), 1)
+		msg, _ := codectypes.NewAnyWithValue(channeltypes.NewMsgRecvPacket(packet, []byte("proof"), clienttypes.NewHeight(1, 100), receiver))
+		return encodeTxWithMsgs(msg)
+	}
+
+	// tokens of the sender chain are received as an ibc denom
+	tx, err := ParseTx(recv("uosmo", "transfer"))
+	require.NoError(err)
+	require.Equal(xc.Address(sender), tx.From())
+	require.Equal(xc.Address(receiver), tx.To())
+	require.Equal("2500000", tx.Amount().String())
+	require.Equal(xc.ContractAddress("ibc/14F9BC3E44B8A9C1BE1FB08980FAB87034C9905EF17CF2F5008FC085218811CC"), tx.ContractAddress())
+	require.Equal([]*xc.TxInfoEndpoint{{Address: xc.Address(sender)}}, tx.Sources())
+
+	// tokens returning to their chain are unwrapped
+	tx, err = ParseTx(recv("transfer/channel-0/uatom", "transfer"))
+	require.NoError(err)
+	require.Equal(xc.ContractAddress(""), tx.ContractAddress())
+	require.Equal(xc.ContractAddress("uatom"), tx.Destinations()[0].ContractAddress)
+
+	// packets of other applications aren't transfers
+	tx, err = ParseTx(recv("uosmo", "icahost"))
+	require.NoError(err)
+	require.Empty(tx.ParsedTransfers)
+	require.Equal(xc.Address(""), tx.To())
+}
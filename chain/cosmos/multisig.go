@@ -0,0 +1,134 @@
+package cosmos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	multisigtypes "github.com/cosmos/cosmos-sdk/crypto/types/multisig"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+var _ xc.TxMultisig = Tx{}
+
+func (txInput *TxInput) isMultisig() bool {
+	return len(txInput.MultisigPublicKeys) > 0
+}
+
+// getMultisigPublicKey returns the legacy amino multisig public key of a threshold of publicKeys, in order
+func getMultisigPublicKey(asset xc.NativeAssetConfig, threshold uint32, publicKeys [][]byte) (*kmultisig.LegacyAminoPubKey, error) {
+	if threshold < 1 || int(threshold) > len(publicKeys) {
+		return nil, fmt.Errorf("invalid threshold %d of %d public keys", threshold, len(publicKeys))
+	}
+	keys := make([]cryptotypes.PubKey, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		keys[i] = getPublicKey(asset, publicKey)
+	}
+	return kmultisig.NewLegacyAminoPubKey(int(threshold), keys), nil
+}
+
+// GetMultisigAddress returns the address of the multisig account of a threshold of publicKeys
+// The order of the public keys changes the address, as in `<chain>d keys add --multisig --nosort`
+func (ab AddressBuilder) GetMultisigAddress(threshold uint32, publicKeys [][]byte) (xc.Address, error) {
+	publicKey, err := getMultisigPublicKey(*ab.Asset, threshold, publicKeys)
+	if err != nil {
+		return "", err
+	}
+	bech32Addr, err := sdk.Bech32ifyAddressBytes(ab.Asset.ChainPrefix, publicKey.Address())
+	return xc.Address(bech32Addr), err
+}
+
+// multisig returns the multisig public key and signatures of a multisig tx
+func (tx Tx) multisig() (*kmultisig.LegacyAminoPubKey, *signingtypes.MultiSignatureData, bool) {
+	if len(tx.SigsV2) != 1 {
+		return nil, nil, false
+	}
+	publicKey, ok := tx.SigsV2[0].PubKey.(*kmultisig.LegacyAminoPubKey)
+	if !ok {
+		return nil, nil, false
+	}
+	data, ok := tx.SigsV2[0].Data.(*signingtypes.MultiSignatureData)
+	return publicKey, data, ok
+}
+
+// Threshold is the number of signatures required by the multisig account of the sender, 0 for a single signer
+func (tx Tx) Threshold() int {
+	publicKey, _, ok := tx.multisig()
+	if !ok {
+		return 0
+	}
+	return int(publicKey.GetThreshold())
+}
+
+// Signers are the public keys of the multisig account of the sender
+func (tx Tx) Signers() []xc.PublicKey {
+	signers := []xc.PublicKey{}
+	publicKey, _, ok := tx.multisig()
+	if !ok {
+		return signers
+	}
+	for _, key := range publicKey.GetPubKeys() {
+		signers = append(signers, key.Bytes())
+	}
+	return signers
+}
+
+// SignedBy returns the public keys of the signers whose signatures were added
+func (tx Tx) SignedBy() []xc.PublicKey {
+	signers := []xc.PublicKey{}
+	publicKey, data, ok := tx.multisig()
+	if !ok {
+		return signers
+	}
+	for i, key := range publicKey.GetPubKeys() {
+		if data.BitArray.GetIndex(i) {
+			signers = append(signers, key.Bytes())
+		}
+	}
+	return signers
+}
+
+// AddSignaturesOf adds the signature of signer over the sighash, checked against it
+// The multisig signature of the tx is valid once Threshold signers have signed
+func (tx Tx) AddSignaturesOf(signer xc.PublicKey, signatures ...xc.TxSignature) error {
+	if tx.CosmosTxBuilder == nil {
+		return errors.New("transaction not initialized")
+	}
+	publicKey, data, ok := tx.multisig()
+	if !ok {
+		return errors.New("tx is not sent from a multisig")
+	}
+	index := -1
+	for i, key := range publicKey.GetPubKeys() {
+		if bytes.Equal(key.Bytes(), signer) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%x is not a signer of the multisig", []byte(signer))
+	}
+	if len(signatures) != 1 {
+		return errors.New("invalid signatures size")
+	}
+	signature := signatures[0]
+	if len(signature) < 64 {
+		return errors.New("signature must be 64 or 65 length serialized bytestring of r,s, and recovery byte")
+	}
+	btcecPublicKey, err := btcec.ParsePubKey(signer, btcec.S256())
+	if err != nil {
+		return err
+	}
+	if !signatureFromBytes(signature).Verify(tx.TxDataToSign, btcecPublicKey) {
+		return fmt.Errorf("signature is not signed by %x", []byte(signer))
+	}
+	multisigtypes.AddSignature(data, &signingtypes.SingleSignatureData{
+		SignMode:  signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+		Signature: reserializeSig(signature),
+	}, index)
+	return tx.CosmosTxBuilder.SetSignatures(tx.SigsV2...)
+}
@@ -0,0 +1,81 @@
+package cosmos
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth/signing"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestMultisigTransfer() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra", ChainIDStr: "phoenix-1"}
+	privateKeys := []*secp256k1.PrivKey{}
+	publicKeys := [][]byte{}
+	for i := byte(1); i <= 3; i++ {
+		privateKey := &secp256k1.PrivKey{Key: bytes.Repeat([]byte{i}, 32)}
+		privateKeys = append(privateKeys, privateKey)
+		publicKeys = append(publicKeys, privateKey.PubKey().Bytes())
+	}
+	addressBuilder, _ := NewAddressBuilder(asset)
+	from, err := addressBuilder.(AddressBuilder).GetMultisigAddress(2, publicKeys)
+	require.NoError(err)
+	require.True(len(from) > len("terra1"))
+	_, err = addressBuilder.(AddressBuilder).GetMultisigAddress(3, publicKeys[:2])
+	require.EqualError(err, "invalid threshold 3 of 2 public keys")
+
+	builder, _ := NewTxBuilder(asset)
+	input := &TxInput{AccountNumber: 10, Sequence: 2, GasLimit: 200_000, GasPrice: 0.015, MultisigThreshold: 2, MultisigPublicKeys: publicKeys}
+	tf, err := builder.(xc.TxTokenBuilder).NewNativeTransfer(from, "terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", xc.NewAmountBlockchainFromUint64(1000), input)
+	require.NoError(err)
+	tx, ok := xc.AsMultisig(tf)
+	require.True(ok)
+	require.Equal(2, tx.Threshold())
+	require.Equal([]xc.PublicKey{publicKeys[0], publicKeys[1], publicKeys[2]}, tx.Signers())
+	require.Empty(tx.SignedBy())
+
+	// members sign the amino JSON sign doc
+	signerData := signing.SignerData{AccountNumber: 10, ChainID: "phoenix-1", Sequence: 2}
+	signBytes, err := builder.(TxBuilder).CosmosTxConfig.SignModeHandler().GetSignBytes(signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON, signerData, tf.(*Tx).CosmosTxBuilder.GetTx())
+	require.NoError(err)
+	require.Contains(string(signBytes), `"chain_id":"phoenix-1"`)
+	sign := func(privateKey *secp256k1.PrivKey) xc.TxSignature {
+		signature, err := privateKey.Sign(signBytes)
+		require.NoError(err)
+		return signature
+	}
+
+	require.EqualError(tf.AddSignatures(sign(privateKeys[0])), "signatures of a multisig tx are added with AddSignaturesOf")
+	err = tx.AddSignaturesOf(publicKeys[1], sign(privateKeys[0]))
+	require.EqualError(err, fmt.Sprintf("signature is not signed by %x", publicKeys[1]))
+	other := &secp256k1.PrivKey{Key: bytes.Repeat([]byte{4}, 32)}
+	err = tx.AddSignaturesOf(other.PubKey().Bytes(), sign(other))
+	require.EqualError(err, fmt.Sprintf("%x is not a signer of the multisig", other.PubKey().Bytes()))
+
+	require.NoError(tx.AddSignaturesOf(publicKeys[2], sign(privateKeys[2])))
+	require.NoError(tx.AddSignaturesOf(publicKeys[0], sign(privateKeys[0])))
+	require.Equal([]xc.PublicKey{publicKeys[0], publicKeys[2]}, tx.SignedBy())
+
+	multisigPublicKey, data, ok := tf.(*Tx).multisig()
+	require.True(ok)
+	err = multisigPublicKey.VerifyMultisignature(func(mode signingtypes.SignMode) ([]byte, error) {
+		return signBytes, nil
+	}, data)
+	require.NoError(err)
+
+	serialized, err := tf.Serialize()
+	require.NoError(err)
+	parsed, err := ParseTx(serialized)
+	require.NoError(err)
+	require.Equal(from, parsed.From())
+	require.Equal(tf.Hash(), parsed.Hash())
+
+	// single signer txs aren't multisig
+	tf, _ = builder.(xc.TxTokenBuilder).NewNativeTransfer(from, "terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", xc.NewAmountBlockchainFromUint64(1000), &TxInput{FromPublicKey: publicKeys[0]})
+	_, ok = xc.AsMultisig(tf)
+	require.False(ok)
+	require.EqualError(tf.(*Tx).AddSignaturesOf(publicKeys[0], sign(privateKeys[0])), "tx is not sent from a multisig")
+}
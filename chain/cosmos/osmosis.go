@@ -0,0 +1,248 @@
+package cosmos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/gogo/protobuf/proto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// OsmosisPrefix is the bech32 prefix of Osmosis addresses
+const OsmosisPrefix = "osmo"
+
+// Queries of the poolmanager module of Osmosis
+const (
+	osmosisQueryEstimateSwapExactAmountIn = "/osmosis.poolmanager.v1beta1.Query/EstimateSwapExactAmountIn"
+	osmosisQuerySpotPrice                 = "/osmosis.poolmanager.v1beta1.Query/SpotPrice"
+	osmosisQueryTotalPoolLiquidity        = "/osmosis.poolmanager.v1beta1.Query/TotalPoolLiquidity"
+)
+
+// SwapAmountInRoute is osmosis.poolmanager.v1beta1.SwapAmountInRoute, a hop of a swap through a pool
+type SwapAmountInRoute struct {
+	PoolId        uint64 `protobuf:"varint,1,opt,name=pool_id,json=poolId,proto3" json:"pool_id,omitempty"`
+	TokenOutDenom string `protobuf:"bytes,2,opt,name=token_out_denom,json=tokenOutDenom,proto3" json:"token_out_denom,omitempty"`
+}
+
+func (m *SwapAmountInRoute) Reset()         { *m = SwapAmountInRoute{} }
+func (m *SwapAmountInRoute) String() string { return proto.CompactTextString(m) }
+func (*SwapAmountInRoute) ProtoMessage()    {}
+
+// MsgSwapExactAmountIn is osmosis.poolmanager.v1beta1.MsgSwapExactAmountIn, swapping TokenIn through the pools of
+// Routes for at least TokenOutMinAmount of the token out of the last route
+type MsgSwapExactAmountIn struct {
+	Sender            string              `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Routes            []SwapAmountInRoute `protobuf:"bytes,2,rep,name=routes,proto3" json:"routes"`
+	TokenIn           types.Coin          `protobuf:"bytes,3,opt,name=token_in,json=tokenIn,proto3" json:"token_in"`
+	TokenOutMinAmount string              `protobuf:"bytes,4,opt,name=token_out_min_amount,json=tokenOutMinAmount,proto3" json:"token_out_min_amount"`
+}
+
+var _ types.Msg = &MsgSwapExactAmountIn{}
+
+func init() {
+	proto.RegisterType((*SwapAmountInRoute)(nil), "osmosis.poolmanager.v1beta1.SwapAmountInRoute")
+	proto.RegisterType((*MsgSwapExactAmountIn)(nil), "osmosis.poolmanager.v1beta1.MsgSwapExactAmountIn")
+	// decode swaps of txs instead of skipping them
+	RegisterMsgs(&MsgSwapExactAmountIn{})
+}
+
+func (m *MsgSwapExactAmountIn) Reset()         { *m = MsgSwapExactAmountIn{} }
+func (m *MsgSwapExactAmountIn) String() string { return proto.CompactTextString(m) }
+func (*MsgSwapExactAmountIn) ProtoMessage()    {}
+
+// ValidateBasic checks the sender, the routes and the amounts
+func (m *MsgSwapExactAmountIn) ValidateBasic() error {
+	_, err := accAddressFromBech32WithPrefix(m.Sender, OsmosisPrefix)
+	if err != nil {
+		return err
+	}
+	if len(m.Routes) == 0 {
+		return errors.New("invalid swap: no routes")
+	}
+	for _, route := range m.Routes {
+		if err := types.ValidateDenom(route.TokenOutDenom); err != nil {
+			return fmt.Errorf("invalid swap route of pool %d: %v", route.PoolId, err)
+		}
+	}
+	if !m.TokenIn.IsValid() || !m.TokenIn.IsPositive() {
+		return fmt.Errorf("invalid swap amount: %s", m.TokenIn)
+	}
+	minAmount, ok := types.NewIntFromString(m.TokenOutMinAmount)
+	if !ok || !minAmount.IsPositive() {
+		return fmt.Errorf("invalid swap min amount out: %s", m.TokenOutMinAmount)
+	}
+	return nil
+}
+
+// GetSigners returns the sender
+func (m *MsgSwapExactAmountIn) GetSigners() []types.AccAddress {
+	sender, _ := accAddressFromBech32WithPrefix(m.Sender, OsmosisPrefix)
+	return []types.AccAddress{sender}
+}
+
+// estimateSwapExactAmountInRequest is osmosis.poolmanager.v1beta1.EstimateSwapExactAmountInRequest, its pool id
+// is deprecated in favor of the routes
+type estimateSwapExactAmountInRequest struct {
+	TokenIn string              `protobuf:"bytes,3,opt,name=token_in,json=tokenIn,proto3"`
+	Routes  []SwapAmountInRoute `protobuf:"bytes,4,rep,name=routes,proto3"`
+}
+
+func (m *estimateSwapExactAmountInRequest) Reset()         { *m = estimateSwapExactAmountInRequest{} }
+func (m *estimateSwapExactAmountInRequest) String() string { return proto.CompactTextString(m) }
+func (*estimateSwapExactAmountInRequest) ProtoMessage()    {}
+
+type estimateSwapExactAmountInResponse struct {
+	TokenOutAmount string `protobuf:"bytes,1,opt,name=token_out_amount,json=tokenOutAmount,proto3"`
+}
+
+func (m *estimateSwapExactAmountInResponse) Reset()         { *m = estimateSwapExactAmountInResponse{} }
+func (m *estimateSwapExactAmountInResponse) String() string { return proto.CompactTextString(m) }
+func (*estimateSwapExactAmountInResponse) ProtoMessage()    {}
+
+type spotPriceRequest struct {
+	PoolId          uint64 `protobuf:"varint,1,opt,name=pool_id,json=poolId,proto3"`
+	BaseAssetDenom  string `protobuf:"bytes,2,opt,name=base_asset_denom,json=baseAssetDenom,proto3"`
+	QuoteAssetDenom string `protobuf:"bytes,3,opt,name=quote_asset_denom,json=quoteAssetDenom,proto3"`
+}
+
+func (m *spotPriceRequest) Reset()         { *m = spotPriceRequest{} }
+func (m *spotPriceRequest) String() string { return proto.CompactTextString(m) }
+func (*spotPriceRequest) ProtoMessage()    {}
+
+type spotPriceResponse struct {
+	SpotPrice string `protobuf:"bytes,1,opt,name=spot_price,json=spotPrice,proto3"`
+}
+
+func (m *spotPriceResponse) Reset()         { *m = spotPriceResponse{} }
+func (m *spotPriceResponse) String() string { return proto.CompactTextString(m) }
+func (*spotPriceResponse) ProtoMessage()    {}
+
+type totalPoolLiquidityRequest struct {
+	PoolId uint64 `protobuf:"varint,1,opt,name=pool_id,json=poolId,proto3"`
+}
+
+func (m *totalPoolLiquidityRequest) Reset()         { *m = totalPoolLiquidityRequest{} }
+func (m *totalPoolLiquidityRequest) String() string { return proto.CompactTextString(m) }
+func (*totalPoolLiquidityRequest) ProtoMessage()    {}
+
+type totalPoolLiquidityResponse struct {
+	Liquidity []types.Coin `protobuf:"bytes,1,rep,name=liquidity,proto3"`
+}
+
+func (m *totalPoolLiquidityResponse) Reset()         { *m = totalPoolLiquidityResponse{} }
+func (m *totalPoolLiquidityResponse) String() string { return proto.CompactTextString(m) }
+func (*totalPoolLiquidityResponse) ProtoMessage()    {}
+
+// NewSwapExactAmountIn swaps amount of the asset of the builder through the pools of routes, for at least
+// minAmountOut of the token out of the last route
+func (txBuilder TxBuilder) NewSwapExactAmountIn(from xc.Address, amount xc.AmountBlockchain, routes []SwapAmountInRoute, minAmountOut xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	txInput := input.(*TxInput)
+	asset := txBuilder.Asset
+	if asset.GetNativeAsset().ChainPrefix != OsmosisPrefix {
+		return nil, errors.New("swaps must be built for the osmosis chain")
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 400_000
+	}
+	amountInt := big.Int(amount)
+
+	msg := &MsgSwapExactAmountIn{
+		Sender: string(from),
+		Routes: routes,
+		TokenIn: types.Coin{
+			Denom:  assetDenom(asset),
+			Amount: types.NewIntFromBigInt(&amountInt),
+		},
+		TokenOutMinAmount: minAmountOut.String(),
+	}
+	err := msg.ValidateBasic()
+	if err != nil {
+		return nil, err
+	}
+	return txBuilder.createTxWithMsgs(txInput, msg)
+}
+
+// EstimateSwapExactAmountIn returns the amount out of a swap of tokenIn through the pools of routes
+// The client must be configured for the Osmosis chain
+func (client *Client) EstimateSwapExactAmountIn(ctx context.Context, tokenIn types.Coin, routes []SwapAmountInRoute) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	res := &estimateSwapExactAmountInResponse{}
+	err := client.queryOsmosis(ctx, osmosisQueryEstimateSwapExactAmountIn, &estimateSwapExactAmountInRequest{
+		TokenIn: tokenIn.String(),
+		Routes:  routes,
+	}, res)
+	if err != nil {
+		return zero, err
+	}
+	amount, err := xc.ParseAmountBlockchain(res.TokenOutAmount)
+	if err != nil {
+		return zero, fmt.Errorf("invalid swap estimate: %v", err)
+	}
+	return amount, nil
+}
+
+// FetchSpotPrice returns the spot price of baseDenom in quoteDenom of a pool
+// The client must be configured for the Osmosis chain
+func (client *Client) FetchSpotPrice(ctx context.Context, poolID uint64, baseDenom string, quoteDenom string) (xc.AmountHumanReadable, error) {
+	res := &spotPriceResponse{}
+	err := client.queryOsmosis(ctx, osmosisQuerySpotPrice, &spotPriceRequest{
+		PoolId:          poolID,
+		BaseAssetDenom:  baseDenom,
+		QuoteAssetDenom: quoteDenom,
+	}, res)
+	if err != nil {
+		return xc.AmountHumanReadable{}, err
+	}
+	price, err := xc.ParseAmountHumanReadable(res.SpotPrice)
+	if err != nil {
+		return xc.AmountHumanReadable{}, fmt.Errorf("invalid spot price: %v", err)
+	}
+	return price, nil
+}
+
+// FetchPoolLiquidity returns the total liquidity of a pool
+// The client must be configured for the Osmosis chain
+func (client *Client) FetchPoolLiquidity(ctx context.Context, poolID uint64) (types.Coins, error) {
+	res := &totalPoolLiquidityResponse{}
+	err := client.queryOsmosis(ctx, osmosisQueryTotalPoolLiquidity, &totalPoolLiquidityRequest{PoolId: poolID}, res)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewCoins(res.Liquidity...), nil
+}
+
+// queryOsmosis queries a gRPC method of Osmosis over ABCI
+func (client *Client) queryOsmosis(ctx context.Context, method string, req proto.Message, res proto.Message) error {
+	if client.Prefix != OsmosisPrefix {
+		return errors.New("pools must be queried on the osmosis chain")
+	}
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	result, err := client.Ctx.Client.ABCIQuery(ctx, method, data)
+	if err != nil {
+		return err
+	}
+	if !result.Response.IsOK() {
+		return fmt.Errorf("could not query %s: %s", method, result.Response.Log)
+	}
+	if err := proto.Unmarshal(result.Response.Value, res); err != nil {
+		return fmt.Errorf("could not decode %s: %v", method, err)
+	}
+	return nil
+}
+
+// assetDenom returns the denom of an asset: the contract of tokens, or the chain coin
+func assetDenom(asset xc.ITask) string {
+	if token, ok := asset.(*xc.TokenAssetConfig); ok && token.Contract != "" {
+		return token.Contract
+	}
+	return asset.GetNativeAsset().ChainCoin
+}
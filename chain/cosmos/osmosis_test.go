@@ -0,0 +1,114 @@
+package cosmos
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/gogo/protobuf/proto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+var osmosisTestAsset = &xc.NativeAssetConfig{NativeAsset: xc.OSMO, ChainCoin: "uosmo", ChainPrefix: OsmosisPrefix, ChainIDStr: "osmosis-1"}
+
+// abciQueryResult is the result of an abci_query returning res
+func abciQueryResult(id int, res proto.Message) string {
+	value, _ := proto.Marshal(res)
+	return jsonRPCResult(id, fmt.Sprintf(`{"response":{"code":0,"value":"%s","height":"100"}}`, base64.StdEncoding.EncodeToString(value)))
+}
+
+func (s *CrosschainTestSuite) TestNewSwapExactAmountIn() {
+	require := s.Require()
+	from := xc.Address("osmo1dp3q305hgttt8n34rt8rg9xpanc42z4yhp43a6")
+	routes := []SwapAmountInRoute{{PoolId: 1, TokenOutDenom: "uion"}, {PoolId: 2, TokenOutDenom: "uatom"}}
+
+	builder, _ := NewTxBuilder(osmosisTestAsset)
+	tx, err := builder.(TxBuilder).NewSwapExactAmountIn(from, xc.NewAmountBlockchainFromUint64(1_000_000), routes, xc.NewAmountBlockchainFromUint64(900), &TxInput{})
+	require.NoError(err)
+	msg := tx.(*Tx).ParsedTransfers[0].(*MsgSwapExactAmountIn)
+	require.Equal(string(from), msg.Sender)
+	require.Equal("1000000uosmo", msg.TokenIn.String())
+	require.Equal("900", msg.TokenOutMinAmount)
+	require.Equal(routes, msg.Routes)
+
+	// the wire format of osmosis
+	encoded, err := proto.Marshal(&MsgSwapExactAmountIn{Sender: "a", Routes: routes[:1], TokenIn: msg.TokenIn, TokenOutMinAmount: "900"})
+	require.NoError(err)
+	require.Equal("0a0161"+"12080801120475696f6e"+"1a100a05756f736d6f120731303030303030"+"2203393030", hex.EncodeToString(encoded))
+
+	// swaps of txs are decoded
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	parsed, err := ParseTx(serialized)
+	require.NoError(err)
+	require.Len(parsed.UnknownMsgTypes(), 0)
+	require.Equal(msg, parsed.CosmosTx.GetMsgs()[0])
+
+	// tokens are swapped by denom
+	ion := &xc.TokenAssetConfig{Asset: "ION", Contract: "uion", NativeAssetConfig: osmosisTestAsset}
+	builder, _ = NewTxBuilder(ion)
+	tx, err = builder.(TxBuilder).NewSwapExactAmountIn(from, xc.NewAmountBlockchainFromUint64(5), routes[1:], xc.NewAmountBlockchainFromUint64(1), &TxInput{})
+	require.NoError(err)
+	require.Equal("5uion", tx.(*Tx).ParsedTransfers[0].(*MsgSwapExactAmountIn).TokenIn.String())
+}
+
+func (s *CrosschainTestSuite) TestNewSwapExactAmountInErrors() {
+	require := s.Require()
+	from := xc.Address("osmo1dp3q305hgttt8n34rt8rg9xpanc42z4yhp43a6")
+	routes := []SwapAmountInRoute{{PoolId: 1, TokenOutDenom: "uion"}}
+	amount := xc.NewAmountBlockchainFromUint64(100)
+	builder, _ := NewTxBuilder(osmosisTestAsset)
+	osmosis := builder.(TxBuilder)
+
+	_, err := osmosis.NewSwapExactAmountIn(from, amount, nil, amount, &TxInput{})
+	require.EqualError(err, "invalid swap: no routes")
+	_, err = osmosis.NewSwapExactAmountIn(from, amount, []SwapAmountInRoute{{PoolId: 1}}, amount, &TxInput{})
+	require.ErrorContains(err, "invalid swap route of pool 1")
+	_, err = osmosis.NewSwapExactAmountIn(from, xc.NewAmountBlockchainFromUint64(0), routes, amount, &TxInput{})
+	require.EqualError(err, "invalid swap amount: 0uosmo")
+	_, err = osmosis.NewSwapExactAmountIn(from, amount, routes, xc.NewAmountBlockchainFromUint64(0), &TxInput{})
+	require.EqualError(err, "invalid swap min amount out: 0")
+	_, err = osmosis.NewSwapExactAmountIn("cosmos1dp3q305hgttt8n34rt8rg9xpanc42z4yl6xptg", amount, routes, amount, &TxInput{})
+	require.ErrorContains(err, "invalid Bech32 prefix")
+
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.ATOM, ChainCoin: "uatom", ChainPrefix: "cosmos"})
+	_, err = builder.(TxBuilder).NewSwapExactAmountIn(from, amount, routes, amount, &TxInput{})
+	require.EqualError(err, "swaps must be built for the osmosis chain")
+}
+
+func (s *CrosschainTestSuite) TestOsmosisPoolQueries() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		abciQueryResult(0, &estimateSwapExactAmountInResponse{TokenOutAmount: "4521"}),
+		abciQueryResult(1, &spotPriceResponse{SpotPrice: "0.004521000000000000"}),
+		abciQueryResult(2, &totalPoolLiquidityResponse{Liquidity: []types.Coin{types.NewInt64Coin("uosmo", 2000), types.NewInt64Coin("uion", 9)}}),
+		jsonRPCResult(3, `{"response":{"code":5,"log":"pool 99 does not exist","height":"100"}}`),
+	})
+	defer close()
+	asset := *osmosisTestAsset
+	asset.URL = server.URL
+	client, err := NewClient(&asset)
+	require.NoError(err)
+
+	estimate, err := client.EstimateSwapExactAmountIn(s.Ctx, types.NewInt64Coin("uosmo", 1_000_000), []SwapAmountInRoute{{PoolId: 1, TokenOutDenom: "uion"}})
+	require.NoError(err)
+	require.Equal("4521", estimate.String())
+
+	price, err := client.FetchSpotPrice(s.Ctx, 1, "uosmo", "uion")
+	require.NoError(err)
+	require.Equal("0.004521", price.String())
+
+	liquidity, err := client.FetchPoolLiquidity(s.Ctx, 1)
+	require.NoError(err)
+	require.Equal("9uion,2000uosmo", liquidity.String())
+
+	_, err = client.FetchPoolLiquidity(s.Ctx, 99)
+	require.EqualError(err, "could not query /osmosis.poolmanager.v1beta1.Query/TotalPoolLiquidity: pool 99 does not exist")
+
+	// other chains have no pools
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM, ChainPrefix: "cosmos", URL: server.URL})
+	_, err = client.FetchPoolLiquidity(s.Ctx, 1)
+	require.EqualError(err, "pools must be queried on the osmosis chain")
+}
@@ -0,0 +1,195 @@
+package cosmos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
+)
+
+// Operator of a condition of a Query
+type Operator string
+
+// List of Operator supported by Tendermint
+const (
+	OpEqual          = Operator("=")
+	OpLess           = Operator("<")
+	OpLessOrEqual    = Operator("<=")
+	OpGreater        = Operator(">")
+	OpGreaterOrEqual = Operator(">=")
+	OpContains       = Operator("CONTAINS")
+	OpExists         = Operator("EXISTS")
+)
+
+// Well-known event attributes
+const (
+	EventTxHeight          = "tx.height"
+	EventTxHash            = "tx.hash"
+	EventTransferRecipient = "transfer.recipient"
+	EventTransferSender    = "transfer.sender"
+	EventMessageAction     = "message.action"
+	EventMessageSender     = "message.sender"
+	EventMessageModule     = "message.module"
+)
+
+// DefaultSearchPerPage is the number of txs per page of SearchTxs, the max allowed by Tendermint
+const DefaultSearchPerPage = 100
+
+// composite keys are <event type>.<attribute>
+var queryKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)+$`)
+
+// Query is a Tendermint event query, for tx_search and event subscriptions, e.g.
+// transfer.recipient = 'cosmos1...' AND message.action = '/cosmos.bank.v1beta1.MsgSend'
+// Conditions are joined with AND, Tendermint doesn't support OR
+type Query struct {
+	conditions []string
+	err        error
+}
+
+// NewQuery creates an empty Query
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Where adds the condition `key op value`
+// value is a string, an integer, a time.Time or an xc.Address, and is ignored for OpExists
+func (query *Query) Where(key string, op Operator, value interface{}) *Query {
+	if query.err != nil {
+		return query
+	}
+	if !queryKeyRegex.MatchString(key) {
+		query.err = fmt.Errorf("invalid query key: '%s'", key)
+		return query
+	}
+	if op == OpExists {
+		query.conditions = append(query.conditions, key+" EXISTS")
+		return query
+	}
+	switch op {
+	case OpEqual, OpLess, OpLessOrEqual, OpGreater, OpGreaterOrEqual, OpContains:
+	default:
+		query.err = fmt.Errorf("invalid query operator: '%s'", op)
+		return query
+	}
+	var formatted string
+	switch value := value.(type) {
+	case string:
+		formatted, query.err = quoteQueryValue(value)
+	case xc.Address:
+		formatted, query.err = quoteQueryValue(string(value))
+	case int, int32, int64, uint, uint32, uint64:
+		formatted = fmt.Sprintf("%d", value)
+	case time.Time:
+		formatted = "TIME " + value.UTC().Format(time.RFC3339)
+	default:
+		query.err = fmt.Errorf("invalid query value for '%s': %T", key, value)
+	}
+	if query.err != nil {
+		return query
+	}
+	if op == OpContains && !strings.HasPrefix(formatted, "'") {
+		query.err = fmt.Errorf("CONTAINS requires a string value for '%s'", key)
+		return query
+	}
+	query.conditions = append(query.conditions, fmt.Sprintf("%s %s %s", key, op, formatted))
+	return query
+}
+
+// tendermint strings can't contain quotes, there's no escaping
+func quoteQueryValue(value string) (string, error) {
+	if strings.Contains(value, "'") {
+		return "", fmt.Errorf("invalid query value: %s", value)
+	}
+	return "'" + value + "'", nil
+}
+
+// Equals adds the condition key = 'value'
+func (query *Query) Equals(key string, value string) *Query {
+	return query.Where(key, OpEqual, value)
+}
+
+// Exists adds the condition `key EXISTS`
+func (query *Query) Exists(key string) *Query {
+	return query.Where(key, OpExists, nil)
+}
+
+// TransferRecipient selects txs transferring to address, e.g. deposits
+func (query *Query) TransferRecipient(address xc.Address) *Query {
+	return query.Where(EventTransferRecipient, OpEqual, address)
+}
+
+// TransferSender selects txs transferring from address
+func (query *Query) TransferSender(address xc.Address) *Query {
+	return query.Where(EventTransferSender, OpEqual, address)
+}
+
+// MessageAction selects txs with a message of type action, e.g. /cosmos.bank.v1beta1.MsgSend
+func (query *Query) MessageAction(action string) *Query {
+	return query.Where(EventMessageAction, OpEqual, action)
+}
+
+// MessageSender selects txs with a message signed by address
+func (query *Query) MessageSender(address xc.Address) *Query {
+	return query.Where(EventMessageSender, OpEqual, address)
+}
+
+// FromHeight selects txs from height included
+func (query *Query) FromHeight(height int64) *Query {
+	return query.Where(EventTxHeight, OpGreaterOrEqual, height)
+}
+
+// ToHeight selects txs up to height included
+func (query *Query) ToHeight(height int64) *Query {
+	return query.Where(EventTxHeight, OpLessOrEqual, height)
+}
+
+// Build returns the query string, or the first invalid condition
+func (query *Query) Build() (string, error) {
+	if query.err != nil {
+		return "", query.err
+	}
+	if len(query.conditions) == 0 {
+		return "", errors.New("empty query")
+	}
+	built := strings.Join(query.conditions, " AND ")
+	// same parser as the node
+	if _, err := tmquery.New(built); err != nil {
+		return "", fmt.Errorf("invalid query '%s': %v", built, err)
+	}
+	return built, nil
+}
+
+// String returns the query string, empty if invalid
+func (query *Query) String() string {
+	built, _ := query.Build()
+	return built
+}
+
+// SearchTxs returns the hashes of the txs matching query in ascending order, and the total number of matching txs
+// page starts at 1, perPage defaults to DefaultSearchPerPage
+func (client *Client) SearchTxs(ctx context.Context, query *Query, page int, perPage int) ([]xc.TxHash, int, error) {
+	built, err := query.Build()
+	if err != nil {
+		return nil, 0, err
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = DefaultSearchPerPage
+	}
+	result, err := client.Ctx.Client.TxSearch(ctx, built, false, &page, &perPage, "asc")
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not search txs '%s': %v", built, err)
+	}
+	hashes := make([]xc.TxHash, len(result.Txs))
+	for i, tx := range result.Txs {
+		hashes[i] = xc.TxHash(tx.Hash.String())
+	}
+	return hashes, result.TotalCount, nil
+}
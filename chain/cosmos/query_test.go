@@ -0,0 +1,80 @@
+package cosmos
+
+import (
+	"errors"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestQueryBuild() {
+	require := s.Require()
+	query := NewQuery().
+		TransferRecipient("cosmos1ykhtdnhm9vjltkm4ec9ylwf2f7l9ar99m0tcmf").
+		MessageAction("/cosmos.bank.v1beta1.MsgSend").
+		FromHeight(100).
+		ToHeight(200)
+	built, err := query.Build()
+	require.NoError(err)
+	require.Equal("transfer.recipient = 'cosmos1ykhtdnhm9vjltkm4ec9ylwf2f7l9ar99m0tcmf' AND message.action = '/cosmos.bank.v1beta1.MsgSend' AND tx.height >= 100 AND tx.height <= 200", built)
+	require.Equal(built, query.String())
+
+	query = NewQuery().
+		Where("wasm._contract_address", OpContains, "juno1").
+		Exists("ibc_transfer.receiver").
+		Where("block.time", OpGreater, time.Date(2023, 5, 3, 14, 45, 0, 0, time.UTC)).
+		TransferSender(xc.Address("cosmos1sender")).
+		MessageSender(xc.Address("cosmos1signer"))
+	require.Equal("wasm._contract_address CONTAINS 'juno1' AND ibc_transfer.receiver EXISTS AND block.time > TIME 2023-05-03T14:45:00Z AND transfer.sender = 'cosmos1sender' AND message.sender = 'cosmos1signer'", query.String())
+}
+
+func (s *CrosschainTestSuite) TestQueryBuildErrors() {
+	require := s.Require()
+	_, err := NewQuery().Build()
+	require.EqualError(err, "empty query")
+
+	vectors := []struct {
+		query *Query
+		err   string
+	}{
+		{NewQuery().Equals("recipient", "x"), "invalid query key: 'recipient'"},
+		{NewQuery().Equals("transfer.recipient' OR 'a", "x"), "invalid query key: 'transfer.recipient' OR 'a'"},
+		{NewQuery().Equals("transfer.recipient", "x' OR tx.height > '0"), "invalid query value: x' OR tx.height > '0"},
+		{NewQuery().Where("tx.height", "!=", 1), "invalid query operator: '!='"},
+		{NewQuery().Where("tx.height", OpEqual, 1.5), "invalid query value for 'tx.height': float64"},
+		{NewQuery().Where("tx.height", OpContains, 1), "CONTAINS requires a string value for 'tx.height'"},
+		// the first error is kept
+		{NewQuery().Equals("a", "x").Equals("b", "y"), "invalid query key: 'a'"},
+	}
+	for _, v := range vectors {
+		_, err := v.query.Build()
+		require.EqualError(err, v.err)
+		require.Equal("", v.query.String())
+	}
+}
+
+func (s *CrosschainTestSuite) TestSearchTxs() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"txs":[{"hash":"E9C24C2E23CDCA56C8CE87A583149F8F88E75923F0CD958C003A84F631948978","height":"11","index":0,"tx_result":{"code":0,"data":null,"log":"","info":"","gas_wanted":"0","gas_used":"0","events":[],"codespace":""},"tx":"CgA="},{"hash":"6F4B2D2DBE4D5C1F5A1E8F3D4A2E0D2B1C3E4F5A6B7C8D9E0F1A2B3C4D5E6F70","height":"12","index":1,"tx_result":{"code":0,"data":null,"log":"","info":"","gas_wanted":"0","gas_used":"0","events":[],"codespace":""},"tx":"CgA="}],"total_count":"5"}`)
+	defer close()
+	client, err := NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM, URL: server.URL})
+	require.NoError(err)
+
+	hashes, total, err := client.SearchTxs(s.Ctx, NewQuery().TransferRecipient("cosmos1ykhtdnhm9vjltkm4ec9ylwf2f7l9ar99m0tcmf"), 1, 2)
+	require.NoError(err)
+	require.Equal(5, total)
+	require.Equal([]xc.TxHash{
+		"E9C24C2E23CDCA56C8CE87A583149F8F88E75923F0CD958C003A84F631948978",
+		"6F4B2D2DBE4D5C1F5A1E8F3D4A2E0D2B1C3E4F5A6B7C8D9E0F1A2B3C4D5E6F70",
+	}, hashes)
+
+	// invalid queries aren't sent
+	_, _, err = client.SearchTxs(s.Ctx, NewQuery(), 0, 0)
+	require.EqualError(err, "empty query")
+	require.Equal(1, server.Counter)
+
+	server.Response = errors.New(`{"message": "too many results", "code": -32603}`)
+	_, _, err = client.SearchTxs(s.Ctx, NewQuery().MessageAction("/cosmos.bank.v1beta1.MsgSend"), 0, 0)
+	require.ErrorContains(err, "could not search txs 'message.action = '/cosmos.bank.v1beta1.MsgSend''")
+}
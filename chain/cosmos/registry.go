@@ -0,0 +1,212 @@
+package cosmos
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// RegisterInterfacesFunc registers types in the interface registry of a codec, e.g. the RegisterInterfaces of a module
+type RegisterInterfacesFunc func(registry codectypes.InterfaceRegistry)
+
+var registrations struct {
+	mu      sync.Mutex
+	fns     []RegisterInterfacesFunc
+	version int
+}
+
+// RegisterInterfaces registers additional types, e.g. osmosis or wasm msgs, in the codec of clients created afterwards and of ParseTx
+// Txs with msgs of unregistered types are still decoded, without these msgs
+func RegisterInterfaces(fn RegisterInterfacesFunc) {
+	registrations.mu.Lock()
+	defer registrations.mu.Unlock()
+	registrations.fns = append(registrations.fns, fn)
+	registrations.version++
+}
+
+// RegisterMsgs registers additional msg implementations, see RegisterInterfaces
+func RegisterMsgs(msgs ...types.Msg) {
+	RegisterInterfaces(func(registry codectypes.InterfaceRegistry) {
+		for _, msg := range msgs {
+			registry.RegisterImplementations((*types.Msg)(nil), msg)
+		}
+	})
+}
+
+// Transfer is a transfer of fungible tokens of a msg other than a bank send or a CW20 transfer, e.g. an IBC transfer
+// or a transfer of a custom module
+type Transfer struct {
+	Sender   xc.Address
+	Receiver xc.Address
+	// Denom on the chain of the tx, e.g. the ibc/ denom of received tokens
+	Denom  string
+	Amount xc.AmountBlockchain
+}
+
+// TransferParser returns the transfer of a msg of a custom module, false for other msgs
+type TransferParser func(msg types.Msg) (*Transfer, bool)
+
+var transferParsers struct {
+	mu      sync.RWMutex
+	parsers []TransferParser
+}
+
+// RegisterTransferParser registers a parser of the transfers of a custom module, whose msgs are registered with
+// RegisterMsgs: txs with these transfers then have their from, to, amount, sources and destinations
+func RegisterTransferParser(parser TransferParser) {
+	transferParsers.mu.Lock()
+	defer transferParsers.mu.Unlock()
+	transferParsers.parsers = append(transferParsers.parsers, parser)
+}
+
+// parseMsgTransfer returns the IBC transfer of a msg, or the transfer of a registered parser
+func parseMsgTransfer(msg types.Msg) (*Transfer, bool) {
+	if transfer, ok := parseIBCTransfer(msg); ok {
+		return transfer, true
+	}
+	transferParsers.mu.RLock()
+	defer transferParsers.mu.RUnlock()
+	for _, parser := range transferParsers.parsers {
+		if transfer, ok := parser(msg); ok {
+			return transfer, true
+		}
+	}
+	return nil, false
+}
+
+// applyRegistrations applies the additional registrations to registry
+func applyRegistrations(registry codectypes.InterfaceRegistry) {
+	registrations.mu.Lock()
+	defer registrations.mu.Unlock()
+	for _, fn := range registrations.fns {
+		fn(registry)
+	}
+}
+
+func registrationsVersion() int {
+	registrations.mu.Lock()
+	defer registrations.mu.Unlock()
+	return registrations.version
+}
+
+var parseTxConfig struct {
+	mu      sync.Mutex
+	config  *EncodingConfig
+	version int
+}
+
+// parseTxEncodingConfig returns the config of ParseTx, created again after new registrations
+func parseTxEncodingConfig() EncodingConfig {
+	parseTxConfig.mu.Lock()
+	defer parseTxConfig.mu.Unlock()
+	if version := registrationsVersion(); parseTxConfig.config == nil || parseTxConfig.version != version {
+		config := MakeCosmosConfig()
+		parseTxConfig.config = &config
+		parseTxConfig.version = version
+	}
+	return *parseTxConfig.config
+}
+
+// partialTx is a tx with msgs of unregistered types, which are skipped
+type partialTx struct {
+	txBytes []byte
+	msgs    []types.Msg
+	fee     *txtypes.Fee
+	unknown []string
+}
+
+var _ types.FeeTx = &partialTx{}
+
+func (tx *partialTx) GetMsgs() []types.Msg {
+	return tx.msgs
+}
+
+func (tx *partialTx) ValidateBasic() error {
+	return fmt.Errorf("tx has msgs of unregistered types: %v", tx.unknown)
+}
+
+func (tx *partialTx) GetGas() uint64 {
+	return tx.fee.GetGasLimit()
+}
+
+func (tx *partialTx) GetFee() types.Coins {
+	return tx.fee.GetAmount()
+}
+
+func (tx *partialTx) FeePayer() types.AccAddress {
+	if payer := tx.fee.GetPayer(); payer != "" {
+		_, address, _ := bech32.DecodeAndConvert(payer)
+		return address
+	}
+	// the first signer of the known msgs, as the fee payer is the first signer of the tx
+	for _, msg := range tx.msgs {
+		if signers := msg.GetSigners(); len(signers) > 0 {
+			return signers[0]
+		}
+	}
+	return nil
+}
+
+func (tx *partialTx) FeeGranter() types.AccAddress {
+	if granter := tx.fee.GetGranter(); granter != "" {
+		_, address, _ := bech32.DecodeAndConvert(granter)
+		return address
+	}
+	return nil
+}
+
+// decodePartialTx decodes a tx whose msgs can't all be unpacked by registry, keeping the known msgs
+func decodePartialTx(registry codectypes.InterfaceRegistry, txBytes []byte) (*partialTx, error) {
+	var raw txtypes.TxRaw
+	if err := raw.Unmarshal(txBytes); err != nil {
+		return nil, err
+	}
+	var body txtypes.TxBody
+	if err := body.Unmarshal(raw.BodyBytes); err != nil {
+		return nil, err
+	}
+	var authInfo txtypes.AuthInfo
+	if err := authInfo.Unmarshal(raw.AuthInfoBytes); err != nil {
+		return nil, err
+	}
+	if len(body.Messages) == 0 {
+		return nil, errors.New("tx has no msg")
+	}
+	tx := &partialTx{
+		txBytes: txBytes,
+		fee:     authInfo.Fee,
+	}
+	for _, any := range body.Messages {
+		var msg types.Msg
+		if err := registry.UnpackAny(any, &msg); err != nil {
+			tx.unknown = append(tx.unknown, any.TypeUrl)
+			continue
+		}
+		tx.msgs = append(tx.msgs, msg)
+	}
+	return tx, nil
+}
+
+// partialTxEncoder encodes partial txs as decoded, and other txs with encoder
+func partialTxEncoder(encoder types.TxEncoder) types.TxEncoder {
+	return func(tx types.Tx) ([]byte, error) {
+		if partial, ok := tx.(*partialTx); ok {
+			return partial.txBytes, nil
+		}
+		return encoder(tx)
+	}
+}
+
+// UnknownMsgTypes returns the type urls of the msgs of a decoded tx that aren't registered, see RegisterInterfaces
+func (tx Tx) UnknownMsgTypes() []string {
+	if partial, ok := tx.CosmosTx.(*partialTx); ok {
+		return partial.unknown
+	}
+	return []string{}
+}
@@ -0,0 +1,105 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/std"
+	"github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+const osmosisSwapTypeURL = "/osmosis.gamm.v1beta1.MsgSwapExactAmountIn"
+
+// encodeTxWithMsgs encodes a tx as broadcast, with msgs of any type
+func encodeTxWithMsgs(msgs ...*codectypes.Any) []byte {
+	body := txtypes.TxBody{Messages: msgs, Memo: "swap"}
+	bodyBytes, _ := body.Marshal()
+	authInfo := txtypes.AuthInfo{Fee: &txtypes.Fee{
+		Amount:   types.NewCoins(types.NewInt64Coin("uosmo", 2500)),
+		GasLimit: 250000,
+	}}
+	authInfoBytes, _ := authInfo.Marshal()
+	raw := txtypes.TxRaw{BodyBytes: bodyBytes, AuthInfoBytes: authInfoBytes, Signatures: [][]byte{make([]byte, 64)}}
+	txBytes, _ := raw.Marshal()
+	return txBytes
+}
+
+func (s *CrosschainTestSuite) TestParseTxUnknownMsgs() {
+	require := s.Require()
+	send, _ := codectypes.NewAnyWithValue(&banktypes.MsgSend{
+		FromAddress: "osmo1h8ljdmae7lx05kjj79c9ekscwsyjd3yrvn4yxv",
+		ToAddress:   "osmo1dp3q305hgttt8n34rt8rg9xpanc42z4yhvpsdj",
+		Amount:      types.NewCoins(types.NewInt64Coin("uosmo", 5000000)),
+	})
+	swap := &codectypes.Any{TypeUrl: osmosisSwapTypeURL, Value: []byte{0x0a, 0x01, 0x61}}
+	txBytes := encodeTxWithMsgs(swap, send)
+
+	tx, err := ParseTx(txBytes)
+	require.NoError(err)
+	require.Equal([]string{osmosisSwapTypeURL}, tx.UnknownMsgTypes())
+	// what is known is still parsed
+	require.Equal(xc.Address("osmo1h8ljdmae7lx05kjj79c9ekscwsyjd3yrvn4yxv"), tx.From())
+	require.Equal(xc.Address("osmo1dp3q305hgttt8n34rt8rg9xpanc42z4yhvpsdj"), tx.To())
+	require.Equal("5000000", tx.Amount().String())
+	require.Equal("2500", tx.Fee().String())
+	hash := sha256.Sum256(txBytes)
+	require.Equal(xc.TxHash(hex.EncodeToString(hash[:])), tx.Hash())
+	require.ErrorContains(tx.CosmosTx.ValidateBasic(), osmosisSwapTypeURL)
+
+	// no known msg
+	tx, err = ParseTx(encodeTxWithMsgs(swap))
+	require.NoError(err)
+	require.Len(tx.CosmosTx.GetMsgs(), 0)
+	require.Equal(xc.Address(""), tx.From())
+	require.Equal("2500", tx.Fee().String())
+
+	// txs with registered msgs only are decoded as usual
+	tx, err = ParseTx(encodeTxWithMsgs(send))
+	require.NoError(err)
+	require.Equal([]string{}, tx.UnknownMsgTypes())
+	require.Equal("5000000", tx.Amount().String())
+}
+
+func (s *CrosschainTestSuite) TestRegisterInterfaces() {
+	require := s.Require()
+	delegate, _ := codectypes.NewAnyWithValue(&stakingtypes.MsgDelegate{
+		DelegatorAddress: "cosmos1h8ljdmae7lx05kjj79c9ekscwsyjd3yr3kn8ae",
+		ValidatorAddress: "cosmosvaloper1sjllsnramtg3ewxqwwrwjxfgc4n4ef9u2lcnj0",
+		Amount:           types.NewInt64Coin("uatom", 1000000),
+	})
+	txBytes := encodeTxWithMsgs(delegate)
+
+	config := NewEncodingConfig()
+	std.RegisterInterfaces(config.InterfaceRegistry)
+	tx, err := decodeTx(config.TxConfig, config.InterfaceRegistry, txBytes)
+	require.NoError(err)
+	require.Equal([]string{"/cosmos.staking.v1beta1.MsgDelegate"}, tx.UnknownMsgTypes())
+
+	var register RegisterInterfacesFunc = stakingtypes.RegisterInterfaces
+	register(config.InterfaceRegistry)
+	tx, err = decodeTx(config.TxConfig, config.InterfaceRegistry, txBytes)
+	require.NoError(err)
+	require.Equal([]string{}, tx.UnknownMsgTypes())
+	require.Len(tx.CosmosTx.GetMsgs(), 1)
+
+	// registrations apply to the codec of clients and ParseTx
+	registered := 0
+	RegisterInterfaces(func(registry codectypes.InterfaceRegistry) {
+		registered++
+	})
+	RegisterMsgs(&stakingtypes.MsgDelegate{})
+	_, err = NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM})
+	require.NoError(err)
+	require.Equal(1, registered)
+	_, err = ParseTx(txBytes)
+	require.NoError(err)
+	require.Equal(2, registered)
+	_, err = ParseTx(txBytes)
+	require.NoError(err)
+	require.Equal(2, registered)
+}
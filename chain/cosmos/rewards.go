@@ -0,0 +1,131 @@
+package cosmos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// FetchClaimableRewards fetches the staking rewards of a delegator, per validator and denom
+// Rewards in the chain coin have an empty Contract
+func (client *Client) FetchClaimableRewards(ctx context.Context, address xc.Address) ([]xc.ClaimableReward, error) {
+	_, err := types.GetFromBech32(string(address), client.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("bad address: '%v': %v", address, err)
+	}
+
+	queryClient := distrtypes.NewQueryClient(client.Ctx)
+	res, err := queryClient.DelegationTotalRewards(ctx, &distrtypes.QueryDelegationTotalRewardsRequest{
+		DelegatorAddress: string(address),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delegation rewards: '%v': %v", address, err)
+	}
+
+	chainCoin := client.Asset.GetNativeAsset().ChainCoin
+	rewards := []xc.ClaimableReward{}
+	for _, delegation := range res.Rewards {
+		for _, coin := range delegation.Reward {
+			// rewards are tracked with decimals, only the integer part can be withdrawn
+			amount := coin.Amount.TruncateInt()
+			if !amount.IsPositive() {
+				continue
+			}
+			contract := xc.ContractAddress(coin.Denom)
+			if coin.Denom == chainCoin {
+				contract = ""
+			}
+			rewards = append(rewards, xc.ClaimableReward{
+				Validator: xc.Address(delegation.ValidatorAddress),
+				Contract:  contract,
+				Amount:    xc.AmountBlockchain(*amount.BigInt()),
+			})
+		}
+	}
+	return rewards, nil
+}
+
+// rewardValidators returns the distinct validators of rewards, in order
+func rewardValidators(rewards []xc.ClaimableReward) []xc.Address {
+	validators := []xc.Address{}
+	seen := map[xc.Address]bool{}
+	for _, reward := range rewards {
+		if !seen[reward.Validator] {
+			seen[reward.Validator] = true
+			validators = append(validators, reward.Validator)
+		}
+	}
+	return validators
+}
+
+// NewClaimRewards creates a tx withdrawing rewards from every validator in rewards
+func (txBuilder TxBuilder) NewClaimRewards(from xc.Address, rewards []xc.ClaimableReward, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	asset := txBuilder.Asset
+
+	_, err := accAddressFromBech32WithPrefix(string(from), asset.GetNativeAsset().ChainPrefix)
+	if err != nil {
+		return nil, err
+	}
+	validators := rewardValidators(rewards)
+	if len(validators) == 0 {
+		return nil, errors.New("no rewards to claim")
+	}
+
+	msgs := []types.Msg{}
+	for _, validator := range validators {
+		msgs = append(msgs, &distrtypes.MsgWithdrawDelegatorReward{
+			DelegatorAddress: string(from),
+			ValidatorAddress: string(validator),
+		})
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 200_000 + 100_000*uint64(len(msgs))
+	}
+	return txBuilder.createTxWithMsgs(txInput, msgs...)
+}
+
+// NewCompoundRewards creates a tx withdrawing rewards and delegating the chain coin rewards back to each validator
+func (txBuilder TxBuilder) NewCompoundRewards(from xc.Address, rewards []xc.ClaimableReward, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	asset := txBuilder.Asset
+
+	_, err := accAddressFromBech32WithPrefix(string(from), asset.GetNativeAsset().ChainPrefix)
+	if err != nil {
+		return nil, err
+	}
+	validators := rewardValidators(rewards)
+	if len(validators) == 0 {
+		return nil, errors.New("no rewards to claim")
+	}
+
+	msgs := []types.Msg{}
+	for _, validator := range validators {
+		msgs = append(msgs, &distrtypes.MsgWithdrawDelegatorReward{
+			DelegatorAddress: string(from),
+			ValidatorAddress: string(validator),
+		})
+		for _, reward := range rewards {
+			if reward.Validator != validator || reward.Contract != "" || reward.Amount.Sign() <= 0 {
+				continue
+			}
+			msgs = append(msgs, &stakingtypes.MsgDelegate{
+				DelegatorAddress: string(from),
+				ValidatorAddress: string(validator),
+				Amount: types.Coin{
+					Denom:  asset.GetNativeAsset().ChainCoin,
+					Amount: types.NewIntFromBigInt(reward.Amount.Int()),
+				},
+			})
+		}
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 200_000 + 150_000*uint64(len(msgs))
+	}
+	return txBuilder.createTxWithMsgs(txInput, msgs...)
+}
@@ -0,0 +1,89 @@
+package cosmos
+
+import (
+	"encoding/base64"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestFetchClaimableRewards() {
+	require := s.Require()
+	valoper := "terravaloper1dp3q305hgttt8n34rt8rg9xpanc42z4ye3suem"
+	value, _ := (&distrtypes.QueryDelegationTotalRewardsResponse{
+		Rewards: []distrtypes.DelegationDelegatorReward{
+			{
+				ValidatorAddress: valoper,
+				Reward: types.DecCoins{
+					types.NewDecCoinFromDec("uluna", types.MustNewDecFromStr("1234.9")),
+					types.NewDecCoinFromDec("uusd", types.MustNewDecFromStr("0.5")),
+					types.NewDecCoinFromDec("ukrw", types.MustNewDecFromStr("10")),
+				},
+			},
+		},
+	}).Marshal()
+
+	server, close := test.MockJSONRPC(&s.Suite, `{"response":{"code":0,"log":"","info":"","index":"0","key":null,"value":"`+base64.StdEncoding.EncodeToString(value)+`","proofOps":null,"height":"1","codespace":""}}`)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra", URL: server.URL})
+	rewards, err := client.FetchClaimableRewards(s.Ctx, "terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	require.Nil(err)
+	require.Equal([]xc.ClaimableReward{
+		{Validator: xc.Address(valoper), Contract: "", Amount: xc.NewAmountBlockchainFromUint64(1234)},
+		{Validator: xc.Address(valoper), Contract: "ukrw", Amount: xc.NewAmountBlockchainFromUint64(10)},
+	}, rewards)
+
+	_, err = client.FetchClaimableRewards(s.Ctx, "xpla1hdvf6vv5amc7wp84js0ls27apekwxpr0ge96kg")
+	require.ErrorContains(err, "bad address")
+}
+
+func (s *CrosschainTestSuite) TestNewClaimRewards() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra"})
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	rewards := []xc.ClaimableReward{
+		{Validator: "terravaloper1a", Amount: xc.NewAmountBlockchainFromUint64(100)},
+		{Validator: "terravaloper1a", Contract: "ukrw", Amount: xc.NewAmountBlockchainFromUint64(5)},
+		{Validator: "terravaloper1b", Amount: xc.NewAmountBlockchainFromUint64(200)},
+	}
+
+	input := &TxInput{}
+	tx, err := builder.(xc.TxRewardsBuilder).NewClaimRewards(from, rewards, input)
+	require.Nil(err)
+	msgs := tx.(*Tx).ParsedTransfers
+	require.Len(msgs, 2)
+	require.Equal("terravaloper1a", msgs[0].(*distrtypes.MsgWithdrawDelegatorReward).ValidatorAddress)
+	require.Equal("terravaloper1b", msgs[1].(*distrtypes.MsgWithdrawDelegatorReward).ValidatorAddress)
+	require.Equal(uint64(400_000), input.GasLimit)
+
+	_, err = builder.(xc.TxRewardsBuilder).NewClaimRewards(from, []xc.ClaimableReward{}, &TxInput{})
+	require.EqualError(err, "no rewards to claim")
+	_, err = builder.(xc.TxRewardsBuilder).NewClaimRewards("xpla1hdvf6vv5amc7wp84js0ls27apekwxpr0ge96kg", rewards, &TxInput{})
+	require.ErrorContains(err, "invalid Bech32 prefix")
+}
+
+func (s *CrosschainTestSuite) TestNewCompoundRewards() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra"})
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	rewards := []xc.ClaimableReward{
+		{Validator: "terravaloper1a", Amount: xc.NewAmountBlockchainFromUint64(100)},
+		{Validator: "terravaloper1a", Contract: "ukrw", Amount: xc.NewAmountBlockchainFromUint64(5)},
+		{Validator: "terravaloper1b", Amount: xc.NewAmountBlockchainFromUint64(200)},
+	}
+
+	tx, err := builder.(xc.TxRewardsBuilder).NewCompoundRewards(from, rewards, &TxInput{})
+	require.Nil(err)
+	msgs := tx.(*Tx).ParsedTransfers
+	require.Len(msgs, 4)
+	require.IsType(&distrtypes.MsgWithdrawDelegatorReward{}, msgs[0])
+	delegate := msgs[1].(*stakingtypes.MsgDelegate)
+	require.Equal("terravaloper1a", delegate.ValidatorAddress)
+	require.Equal("100uluna", delegate.Amount.String())
+	require.IsType(&distrtypes.MsgWithdrawDelegatorReward{}, msgs[2])
+	require.Equal("200uluna", msgs[3].(*stakingtypes.MsgDelegate).Amount.String())
+}
@@ -2,6 +2,7 @@ package cosmos
 
 import (
 	"encoding/hex"
+	"errors"
 	"strings"
 
 	xc "github.com/jumpcrypto/crosschain"
@@ -27,7 +28,7 @@ func NewSigner(asset xc.ITask) (xc.Signer, error) {
 func (signer Signer) ImportPrivateKey(privateKeyOrMnemonic string) (xc.PrivateKey, error) {
 	keyHex := privateKeyOrMnemonic
 	if strings.Contains(privateKeyOrMnemonic, " ") {
-		hdPath := hd.CreateHDPath(signer.Asset.GetNativeAsset().ChainCoinHDPath, 0, 0).String()
+		hdPath := hd.CreateHDPath(signer.Asset.GetNativeAsset().GetCoinType(), 0, 0).String()
 		kb := keyring.NewUnsafe(keyring.NewInMemory())
 		_, err := kb.NewAccount("key", privateKeyOrMnemonic, keyring.DefaultBIP39Passphrase, hdPath, hd.Secp256k1)
 		if err != nil {
@@ -63,3 +64,12 @@ func (signer Signer) Sign(privateKeyBytes xc.PrivateKey, data xc.TxDataToSign) (
 	}
 	return xc.TxSignature(serializeSig(signature)), nil
 }
+
+// DerivePublicKey returns the compressed public key of a Cosmos private key
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	if len(privateKey) != btcec.PrivKeyBytesLen {
+		return nil, errors.New("invalid k256 private key")
+	}
+	_, publicKey := btcec.PrivKeyFromBytes(btcec.S256(), []byte(privateKey))
+	return xc.PublicKey(publicKey.SerializeCompressed()), nil
+}
@@ -0,0 +1,71 @@
+package cosmos
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	xc "github.com/jumpcrypto/crosschain"
+	"google.golang.org/grpc/status"
+)
+
+// sourceLocation is the location of an error in the code of the node, added to ABCI logs in debug mode,
+// e.g. [cosmos/cosmos-sdk@v0.45.12/x/bank/keeper/send.go:186]
+var sourceLocation = regexp.MustCompile(`\s*\[[^\]]+\.go:\d+\]`)
+
+// ParseABCILog returns the human readable reason of the ABCI log of a failed tx,
+// e.g. "0uatom is smaller than 10uatom: insufficient funds" for
+// "failed to execute message; message index: 0: 0uatom is smaller than 10uatom: insufficient funds"
+func ParseABCILog(log string) string {
+	reason := sourceLocation.ReplaceAllString(log, "")
+	if index := strings.Index(reason, " With gas wanted:"); index >= 0 {
+		reason = reason[:index]
+	}
+	reason = strings.TrimPrefix(reason, "failed to execute message; ")
+	if strings.HasPrefix(reason, "message index: ") {
+		if index := strings.Index(reason[len("message index: "):], ": "); index >= 0 {
+			reason = reason[len("message index: ")+index+2:]
+		}
+	}
+	return strings.TrimSpace(reason)
+}
+
+var _ xc.ClientSimulator = &Client{}
+
+// SimulateTx simulates tx with the tx service, pre-Stargate nodes can't simulate txs
+func (client *Client) SimulateTx(ctx context.Context, tx xc.Tx) (*xc.SimulationResult, error) {
+	if version := client.nodeVersion(); version != nil && version.Legacy() {
+		return nil, xc.ErrSimulationNotSupported
+	}
+	txBytes, err := tx.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	result := &xc.SimulationResult{
+		Chain:   client.Asset.GetNativeAsset().NativeAsset,
+		TxHash:  tx.Hash(),
+		Success: true,
+	}
+	res, err := txtypes.NewServiceClient(client.Ctx).Simulate(ctx, &txtypes.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		// failed txs are queries returning the ABCI log of their error
+		grpcStatus, ok := status.FromError(err)
+		if !ok {
+			return nil, fmt.Errorf("failed to simulate tx %v: %v", tx.Hash(), err)
+		}
+		result.Success = false
+		result.RawError = grpcStatus.Message()
+		result.FailureReason = ParseABCILog(grpcStatus.Message())
+		result.Logs = []string{grpcStatus.Message()}
+		return result, nil
+	}
+	if res.GasInfo != nil {
+		result.GasUsed = res.GasInfo.GasUsed
+	}
+	if res.Result != nil && res.Result.Log != "" {
+		result.Logs = []string{res.Result.Log}
+	}
+	return result, nil
+}
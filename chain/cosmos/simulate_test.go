@@ -0,0 +1,89 @@
+package cosmos
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestParseABCILog() {
+	require := s.Require()
+	vectors := []struct {
+		log    string
+		reason string
+	}{
+		{
+			"failed to execute message; message index: 0: 0uluna is smaller than 1000uluna: insufficient funds",
+			"0uluna is smaller than 1000uluna: insufficient funds",
+		},
+		{
+			"failed to execute message; message index: 1: 0uatom is smaller than 10uatom: insufficient funds [cosmos/cosmos-sdk@v0.45.12/x/bank/keeper/send.go:186] With gas wanted: '200000' and gas used: '61234' : unknown request",
+			"0uatom is smaller than 10uatom: insufficient funds",
+		},
+		{
+			"account sequence mismatch, expected 3, got 2: incorrect account sequence",
+			"account sequence mismatch, expected 3, got 2: incorrect account sequence",
+		},
+		{"", ""},
+	}
+	for _, v := range vectors {
+		require.Equal(v.reason, ParseABCILog(v.log))
+	}
+}
+
+func (s *CrosschainTestSuite) TestSimulateTx() {
+	require := s.Require()
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	to := xc.Address("terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn")
+	publicKey, _ := hex.DecodeString("02afeedb21a149fc0237978dccfe15d2c20e518eb77681eae2a5af9a973e83d893")
+	asset := &xc.AssetConfig{NativeAsset: xc.LUNA, ChainCoin: "uluna", ChainPrefix: "terra", ChainIDStr: "phoenix-1", Type: xc.AssetTypeNative}
+	input := &TxInput{AccountNumber: 1, Sequence: 2, GasLimit: 200_000, GasPrice: 0.015, FromPublicKey: publicKey}
+	builder, _ := NewTxBuilder(asset)
+	tx, err := builder.(TxBuilder).NewNativeTransfer(from, to, xc.NewAmountBlockchainFromUint64(1_000), input)
+	require.NoError(err)
+
+	simulated, _ := (&txtypes.SimulateResponse{
+		GasInfo: &types.GasInfo{GasWanted: 200_000, GasUsed: 100_001},
+		Result:  &types.Result{Log: `[{"events":[{"type":"transfer"}]}]`},
+	}).Marshal()
+	server, close := test.MockJSONRPC(&s.Suite, fmt.Sprintf(`{"response":{"code":0,"value":"%s","height":"100"}}`, base64.StdEncoding.EncodeToString(simulated)))
+	defer close()
+	asset.URL = server.URL
+	client, _ := NewClient(asset)
+	result, err := client.SimulateTx(s.Ctx, tx)
+	require.NoError(err)
+	require.Equal(&xc.SimulationResult{
+		Chain:   xc.LUNA,
+		TxHash:  tx.Hash(),
+		Success: true,
+		GasUsed: 100_001,
+		Logs:    []string{`[{"events":[{"type":"transfer"}]}]`},
+	}, result)
+
+	log := "failed to execute message; message index: 0: 0uluna is smaller than 1000uluna: insufficient funds"
+	server, close = test.MockJSONRPC(&s.Suite, fmt.Sprintf(`{"response":{"code":5,"log":"%s","codespace":"sdk","height":"100"}}`, log))
+	defer close()
+	asset.URL = server.URL
+	client, _ = NewClient(asset)
+	result, err = client.SimulateTx(s.Ctx, tx)
+	require.NoError(err)
+	require.False(result.Success)
+	require.Equal("0uluna is smaller than 1000uluna: insufficient funds", result.FailureReason)
+	require.Equal(log, result.RawError)
+	require.EqualError(result.Err(), fmt.Sprintf("simulation of tx %s failed: 0uluna is smaller than 1000uluna: insufficient funds", tx.Hash()))
+
+	// dry runs fail with the reason
+	client, _ = NewClient(asset)
+	err = client.SubmitTx(xc.WithDryRun(s.Ctx, &xc.DryRun{}), tx)
+	require.EqualError(err, fmt.Sprintf("failed to simulate tx %s: 0uluna is smaller than 1000uluna: insufficient funds", tx.Hash()))
+
+	// not simulated
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.LUNA, URL: "http://127.0.0.1:1"})
+	_, err = client.SimulateTx(s.Ctx, tx)
+	require.ErrorContains(err, "failed to simulate tx")
+}
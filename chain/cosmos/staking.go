@@ -0,0 +1,93 @@
+package cosmos
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+var _ xc.TxStakingBuilder = TxBuilder{}
+
+// checkStakingAddresses checks the delegator and validator operator addresses of a staking tx
+func (txBuilder TxBuilder) checkStakingAddresses(from xc.Address, validator xc.Address) error {
+	prefix := txBuilder.Asset.GetNativeAsset().ChainPrefix
+	_, err := accAddressFromBech32WithPrefix(string(from), prefix)
+	if err != nil {
+		return err
+	}
+	_, err = types.GetFromBech32(string(validator), prefix+"valoper")
+	if err != nil {
+		return fmt.Errorf("bad validator address: '%v': %v", validator, err)
+	}
+	return nil
+}
+
+// stakingCoin returns amount of the chain coin
+func (txBuilder TxBuilder) stakingCoin(amount xc.AmountBlockchain) (types.Coin, error) {
+	if amount.Sign() <= 0 {
+		return types.Coin{}, errors.New("amount must be positive")
+	}
+	return types.Coin{
+		Denom:  txBuilder.Asset.GetNativeAsset().ChainCoin,
+		Amount: types.NewIntFromBigInt(amount.Int()),
+	}, nil
+}
+
+// NewDelegate creates a MsgDelegate tx of amount of the chain coin to a validator operator address
+func (txBuilder TxBuilder) NewDelegate(from xc.Address, validator xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	if err := txBuilder.checkStakingAddresses(from, validator); err != nil {
+		return nil, err
+	}
+	coin, err := txBuilder.stakingCoin(amount)
+	if err != nil {
+		return nil, err
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 300_000
+	}
+	return txBuilder.createTxWithMsgs(txInput, &stakingtypes.MsgDelegate{
+		DelegatorAddress: string(from),
+		ValidatorAddress: string(validator),
+		Amount:           coin,
+	})
+}
+
+// NewUndelegate creates a MsgUndelegate tx, unbonding amount of the chain coin from a validator over the unbonding period
+func (txBuilder TxBuilder) NewUndelegate(from xc.Address, validator xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	if err := txBuilder.checkStakingAddresses(from, validator); err != nil {
+		return nil, err
+	}
+	coin, err := txBuilder.stakingCoin(amount)
+	if err != nil {
+		return nil, err
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 350_000
+	}
+	return txBuilder.createTxWithMsgs(txInput, &stakingtypes.MsgUndelegate{
+		DelegatorAddress: string(from),
+		ValidatorAddress: string(validator),
+		Amount:           coin,
+	})
+}
+
+// NewWithdrawRewards creates a MsgWithdrawDelegatorReward tx withdrawing the rewards of a validator
+func (txBuilder TxBuilder) NewWithdrawRewards(from xc.Address, validator xc.Address, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	if err := txBuilder.checkStakingAddresses(from, validator); err != nil {
+		return nil, err
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 300_000
+	}
+	return txBuilder.createTxWithMsgs(txInput, &distrtypes.MsgWithdrawDelegatorReward{
+		DelegatorAddress: string(from),
+		ValidatorAddress: string(validator),
+	})
+}
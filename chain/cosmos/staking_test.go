@@ -0,0 +1,45 @@
+package cosmos
+
+import (
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestStakingTxs() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra"})
+	stakingBuilder := builder.(xc.TxStakingBuilder)
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	validator := xc.Address("terravaloper1dp3q305hgttt8n34rt8rg9xpanc42z4ye3suem")
+	amount := xc.NewAmountBlockchainFromUint64(1_000_000)
+
+	input := &TxInput{}
+	tx, err := stakingBuilder.NewDelegate(from, validator, amount, input)
+	require.Nil(err)
+	delegate := tx.(*Tx).ParsedTransfers[0].(*stakingtypes.MsgDelegate)
+	require.Equal(string(from), delegate.DelegatorAddress)
+	require.Equal(string(validator), delegate.ValidatorAddress)
+	require.Equal("1000000uluna", delegate.Amount.String())
+	require.Equal(uint64(300_000), input.GasLimit)
+
+	tx, err = stakingBuilder.NewUndelegate(from, validator, amount, &TxInput{})
+	require.Nil(err)
+	undelegate := tx.(*Tx).ParsedTransfers[0].(*stakingtypes.MsgUndelegate)
+	require.Equal(string(validator), undelegate.ValidatorAddress)
+	require.Equal("1000000uluna", undelegate.Amount.String())
+
+	tx, err = stakingBuilder.NewWithdrawRewards(from, validator, &TxInput{})
+	require.Nil(err)
+	withdraw := tx.(*Tx).ParsedTransfers[0].(*distrtypes.MsgWithdrawDelegatorReward)
+	require.Equal(string(from), withdraw.DelegatorAddress)
+	require.Equal(string(validator), withdraw.ValidatorAddress)
+
+	// errors
+	_, err = stakingBuilder.NewDelegate(from, from, amount, &TxInput{})
+	require.ErrorContains(err, "bad validator address")
+	_, err = stakingBuilder.NewDelegate("xpla1hdvf6vv5amc7wp84js0ls27apekwxpr0ge96kg", validator, amount, &TxInput{})
+	require.ErrorContains(err, "invalid Bech32 prefix")
+	_, err = stakingBuilder.NewUndelegate(from, validator, xc.NewAmountBlockchainFromUint64(0), &TxInput{})
+	require.EqualError(err, "amount must be positive")
+}
@@ -0,0 +1,229 @@
+package cosmos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v3/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v3/modules/core/02-client/types"
+	"github.com/gogo/protobuf/proto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// StrideHostZone describes a chain whose native asset can be liquid staked on Stride
+type StrideHostZone struct {
+	// ChainPrefix of the host chain, e.g. cosmos
+	ChainPrefix string
+	// HostZoneID is the chain id of the host chain, as registered on Stride
+	HostZoneID string
+	// HostDenom is the denom staked on the host chain, e.g. uatom
+	HostDenom string
+	// StDenom is the liquid staking denom on Stride, e.g. stuatom
+	StDenom string
+	// TransferChannel is the IBC channel from the host chain to Stride
+	TransferChannel string
+}
+
+// StridePrefix is the bech32 prefix of Stride addresses
+const StridePrefix = "stride"
+
+// StrideHostZones lists supported Stride host zones by host chain prefix
+var StrideHostZones = map[string]StrideHostZone{
+	"cosmos": {
+		ChainPrefix:     "cosmos",
+		HostZoneID:      "cosmoshub-4",
+		HostDenom:       "uatom",
+		StDenom:         "stuatom",
+		TransferChannel: "channel-391",
+	},
+}
+
+// strideTransferTimeout is the IBC timeout of autopilot transfers to Stride
+var strideTransferTimeout = 10 * time.Minute
+
+// MsgRedeemStake is stride.stakeibc.MsgRedeemStake, redeeming stTokens for native tokens on the host zone
+type MsgRedeemStake struct {
+	Creator  string `protobuf:"bytes,1,opt,name=creator,proto3" json:"creator,omitempty"`
+	Amount   string `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount"`
+	HostZone string `protobuf:"bytes,3,opt,name=host_zone,json=hostZone,proto3" json:"host_zone,omitempty"`
+	Receiver string `protobuf:"bytes,4,opt,name=receiver,proto3" json:"receiver,omitempty"`
+}
+
+var _ types.Msg = &MsgRedeemStake{}
+
+func init() {
+	proto.RegisterType((*MsgRedeemStake)(nil), "stride.stakeibc.MsgRedeemStake")
+}
+
+func (m *MsgRedeemStake) Reset()         { *m = MsgRedeemStake{} }
+func (m *MsgRedeemStake) String() string { return proto.CompactTextString(m) }
+func (*MsgRedeemStake) ProtoMessage()    {}
+
+// ValidateBasic checks the creator address and amount
+func (m *MsgRedeemStake) ValidateBasic() error {
+	_, err := accAddressFromBech32WithPrefix(m.Creator, StridePrefix)
+	if err != nil {
+		return err
+	}
+	amount, ok := types.NewIntFromString(m.Amount)
+	if !ok || !amount.IsPositive() {
+		return fmt.Errorf("invalid redeem amount: %s", m.Amount)
+	}
+	return nil
+}
+
+// GetSigners returns the creator
+func (m *MsgRedeemStake) GetSigners() []types.AccAddress {
+	creator, _ := accAddressFromBech32WithPrefix(m.Creator, StridePrefix)
+	return []types.AccAddress{creator}
+}
+
+func getStrideHostZone(asset xc.ITask) (StrideHostZone, error) {
+	prefix := asset.GetNativeAsset().ChainPrefix
+	zone, ok := StrideHostZones[prefix]
+	if !ok {
+		return StrideHostZone{}, fmt.Errorf("stride liquid staking is not supported for chain prefix %s", prefix)
+	}
+	return zone, nil
+}
+
+func convertBech32Prefix(address xc.Address, fromPrefix string, toPrefix string) (xc.Address, error) {
+	addressBytes, err := accAddressFromBech32WithPrefix(string(address), fromPrefix)
+	if err != nil {
+		return xc.Address(""), err
+	}
+	converted, err := types.Bech32ifyAddressBytes(toPrefix, addressBytes)
+	return xc.Address(converted), err
+}
+
+// NewLiquidStake transfers amount to Stride via IBC, where autopilot liquid stakes it for the same account
+func (txBuilder TxBuilder) NewLiquidStake(from xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	cosmosInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, errors.New("xc.TxInput is not from a cosmos chain")
+	}
+	asset := txBuilder.Asset
+	amountInt := big.Int(amount)
+
+	zone, err := getStrideHostZone(asset)
+	if err != nil {
+		return nil, err
+	}
+	strideAddress, err := convertBech32Prefix(from, zone.ChainPrefix, StridePrefix)
+	if err != nil {
+		return nil, err
+	}
+	// copy the input so the default gas limit doesn't leak into the caller's input
+	txInput := *cosmosInput
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 400_000
+	}
+
+	// autopilot v1 reads its instructions from the receiver field
+	autopilot, err := json.Marshal(map[string]interface{}{
+		"autopilot": map[string]interface{}{
+			"receiver": strideAddress,
+			"stakeibc": map[string]interface{}{
+				"stride_address": strideAddress,
+				"action":         "LiquidStake",
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &transfertypes.MsgTransfer{
+		SourcePort:    transfertypes.PortID,
+		SourceChannel: zone.TransferChannel,
+		Token: types.Coin{
+			Denom:  zone.HostDenom,
+			Amount: types.NewIntFromBigInt(&amountInt),
+		},
+		Sender:           string(from),
+		Receiver:         string(autopilot),
+		TimeoutHeight:    clienttypes.ZeroHeight(),
+		TimeoutTimestamp: uint64(time.Now().Add(strideTransferTimeout).UnixNano()),
+	}
+	return txBuilder.createTxWithMsgs(&txInput, msg)
+}
+
+// NewLiquidUnstake redeems amount of stTokens on Stride, sending native tokens back to the host chain account
+// The builder must be configured for a stToken on the Stride chain, e.g. stuatom
+func (txBuilder TxBuilder) NewLiquidUnstake(from xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	cosmosInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, errors.New("xc.TxInput is not from a cosmos chain")
+	}
+	asset := txBuilder.Asset
+	if asset.GetNativeAsset().ChainPrefix != StridePrefix {
+		return nil, errors.New("liquid unstake must be built for the stride chain")
+	}
+	contract := asset.GetAssetConfig().Contract
+	if token, ok := asset.(*xc.TokenAssetConfig); ok && token.Contract != "" {
+		contract = token.Contract
+	}
+	var zone StrideHostZone
+	for _, hostZone := range StrideHostZones {
+		if hostZone.StDenom == contract {
+			zone = hostZone
+		}
+	}
+	if zone.StDenom == "" {
+		return nil, fmt.Errorf("unknown stride liquid staking denom: %s", contract)
+	}
+	receiver, err := convertBech32Prefix(from, StridePrefix, zone.ChainPrefix)
+	if err != nil {
+		return nil, err
+	}
+	// copy the input so the default gas limit doesn't leak into the caller's input
+	txInput := *cosmosInput
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 400_000
+	}
+
+	msg := &MsgRedeemStake{
+		Creator:  string(from),
+		Amount:   amount.String(),
+		HostZone: zone.HostZoneID,
+		Receiver: string(receiver),
+	}
+	err = msg.ValidateBasic()
+	if err != nil {
+		return nil, err
+	}
+	return txBuilder.createTxWithMsgs(&txInput, msg)
+}
+
+// FetchStakedBalance fetches the Stride stToken balances of an address
+// The client must be configured for the Stride chain
+func (client *Client) FetchStakedBalance(ctx context.Context, address xc.Address) ([]xc.StakedBalance, error) {
+	if client.Prefix != StridePrefix {
+		return nil, errors.New("staked balance must be fetched from the stride chain")
+	}
+	positions := []xc.StakedBalance{}
+	for _, zone := range StrideHostZones {
+		stAsset := &xc.AssetConfig{Contract: zone.StDenom}
+		balance, err := client.fetchBankModuleBalance(ctx, address, stAsset)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Sign() == 0 {
+			continue
+		}
+		positions = append(positions, xc.StakedBalance{
+			Protocol:   xc.StakingProtocolStride,
+			Address:    address,
+			Contract:   xc.ContractAddress(zone.StDenom),
+			Amount:     balance,
+			Underlying: xc.NewAmountBlockchainFromUint64(0),
+			Pending:    xc.NewAmountBlockchainFromUint64(0),
+		})
+	}
+	return positions, nil
+}
@@ -0,0 +1,110 @@
+package cosmos
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	transfertypes "github.com/cosmos/ibc-go/v3/modules/apps/transfer/types"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestConvertBech32Prefix() {
+	require := s.Require()
+
+	strideAddress, err := convertBech32Prefix("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "terra", StridePrefix)
+	require.Nil(err)
+	terraAddress, err := convertBech32Prefix(strideAddress, StridePrefix, "terra")
+	require.Nil(err)
+	require.Equal(xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg"), terraAddress)
+
+	_, err = convertBech32Prefix("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "cosmos", StridePrefix)
+	require.ErrorContains(err, "invalid Bech32 prefix")
+}
+
+func (s *CrosschainTestSuite) TestNewLiquidStakeStride() {
+	require := s.Require()
+	from, _ := convertBech32Prefix("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "terra", "cosmos")
+	strideAddress, _ := convertBech32Prefix(from, "cosmos", StridePrefix)
+
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: "ATOM", ChainCoin: "uatom", ChainPrefix: "cosmos", ChainIDStr: "cosmoshub-4"})
+	input := &TxInput{}
+	tx, err := builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, xc.NewAmountBlockchainFromUint64(1_000_000), input)
+	require.Nil(err)
+	require.Equal(uint64(400_000), tx.(*Tx).CosmosTxBuilder.GetTx().GetGas())
+	// the default gas limit isn't set on the caller's input
+	require.Equal(uint64(0), input.GasLimit)
+	msg := tx.(*Tx).ParsedTransfers[0].(*transfertypes.MsgTransfer)
+	require.Equal("channel-391", msg.SourceChannel)
+	require.Equal("1000000uatom", msg.Token.String())
+	require.Equal(string(from), msg.Sender)
+	require.NotZero(msg.TimeoutTimestamp)
+
+	receiver := map[string]map[string]interface{}{}
+	require.Nil(json.Unmarshal([]byte(msg.Receiver), &receiver))
+	require.Equal(string(strideAddress), receiver["autopilot"]["receiver"])
+	require.Equal("LiquidStake", receiver["autopilot"]["stakeibc"].(map[string]interface{})["action"])
+
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, xc.NewAmountBlockchainFromUint64(1_000_000), &struct{ xc.TxInputEnvelope }{})
+	require.ErrorContains(err, "not from a cosmos chain")
+
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra"})
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidStake("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", xc.NewAmountBlockchainFromUint64(1), &TxInput{})
+	require.ErrorContains(err, "not supported for chain prefix terra")
+}
+
+func (s *CrosschainTestSuite) TestNewLiquidUnstakeStride() {
+	require := s.Require()
+	from, _ := convertBech32Prefix("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "terra", StridePrefix)
+	receiver, _ := convertBech32Prefix(from, StridePrefix, "cosmos")
+	stride := &xc.NativeAssetConfig{NativeAsset: "STRD", ChainCoin: "ustrd", ChainPrefix: StridePrefix, ChainIDStr: "stride-1"}
+
+	builder, _ := NewTxBuilder(&xc.TokenAssetConfig{Asset: "stATOM", Contract: "stuatom", NativeAssetConfig: stride})
+	tx, err := builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, xc.NewAmountBlockchainFromUint64(500), &TxInput{})
+	require.Nil(err)
+	msg := tx.(*Tx).ParsedTransfers[0].(*MsgRedeemStake)
+	require.Equal("cosmoshub-4", msg.HostZone)
+	require.Equal("500", msg.Amount)
+	require.Equal(string(receiver), msg.Receiver)
+	serialized, err := tx.Serialize()
+	require.Nil(err)
+	require.Contains(string(serialized), "/stride.stakeibc.MsgRedeemStake")
+
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, xc.NewAmountBlockchainFromUint64(0), &TxInput{})
+	require.ErrorContains(err, "invalid redeem amount")
+
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, xc.NewAmountBlockchainFromUint64(500), &struct{ xc.TxInputEnvelope }{})
+	require.ErrorContains(err, "not from a cosmos chain")
+
+	builder, _ = NewTxBuilder(&xc.TokenAssetConfig{Asset: "stOSMO", Contract: "stuosmo", NativeAssetConfig: stride})
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, xc.NewAmountBlockchainFromUint64(500), &TxInput{})
+	require.ErrorContains(err, "unknown stride liquid staking denom: stuosmo")
+
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: "ATOM", ChainCoin: "uatom", ChainPrefix: "cosmos"})
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, xc.NewAmountBlockchainFromUint64(500), &TxInput{})
+	require.ErrorContains(err, "must be built for the stride chain")
+}
+
+func (s *CrosschainTestSuite) TestFetchStakedBalanceStride() {
+	require := s.Require()
+	address, _ := convertBech32Prefix("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "terra", StridePrefix)
+	coin := types.NewInt64Coin("stuatom", 1234)
+	value, _ := (&banktypes.QueryBalanceResponse{Balance: &coin}).Marshal()
+
+	server, close := test.MockJSONRPC(&s.Suite, `{"response":{"code":0,"log":"","info":"","index":"0","key":null,"value":"`+base64.StdEncoding.EncodeToString(value)+`","proofOps":null,"height":"1","codespace":""}}`)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: "STRD", ChainCoin: "ustrd", ChainPrefix: StridePrefix, URL: server.URL})
+	positions, err := client.FetchStakedBalance(s.Ctx, address)
+	require.Nil(err)
+	require.Len(positions, 1)
+	require.Equal(xc.StakingProtocolStride, positions[0].Protocol)
+	require.Equal(xc.ContractAddress("stuatom"), positions[0].Contract)
+	require.Equal("1234", positions[0].Amount.String())
+
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: "ATOM", ChainCoin: "uatom", ChainPrefix: "cosmos", URL: server.URL})
+	_, err = client.FetchStakedBalance(s.Ctx, address)
+	require.ErrorContains(err, "must be fetched from the stride chain")
+}
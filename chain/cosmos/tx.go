@@ -1,17 +1,25 @@
 package cosmos
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	"github.com/btcsuite/btcd/btcec"
 	xc "github.com/jumpcrypto/crosschain"
 
 	"github.com/cosmos/cosmos-sdk/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/types"
 	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto/tmhash"
 )
 
@@ -24,9 +32,12 @@ type Tx struct {
 	CosmosTxEncoder types.TxEncoder
 	SigsV2          []signingtypes.SignatureV2
 	TxDataToSign    []byte
+	// SignBytes are the bytes TxDataToSign is the hash of, e.g. the amino JSON sign doc
+	SignBytes []byte
 }
 
 var _ xc.Tx = Tx{}
+var _ xc.TxWithSignPayloads = Tx{}
 
 // Hash returns the tx hash or id
 func (tx Tx) Hash() xc.TxHash {
@@ -46,6 +57,14 @@ func (tx Tx) Sighashes() ([]xc.TxDataToSign, error) {
 	return []xc.TxDataToSign{tx.TxDataToSign}, nil
 }
 
+// SignPayloads returns the sign bytes of the tx in its sign mode, hashed by Sighashes
+func (tx Tx) SignPayloads() ([]xc.TxDataToSign, error) {
+	if tx.SignBytes == nil {
+		return nil, errors.New("transaction not initialized")
+	}
+	return []xc.TxDataToSign{tx.SignBytes}, nil
+}
+
 func signatureFromBytes(sigStr []byte) *btcec.Signature {
 	return &btcec.Signature{
 		R: new(big.Int).SetBytes(sigStr[:32]),
@@ -70,8 +89,11 @@ func (tx Tx) AddSignatures(signatures ...xc.TxSignature) error {
 		return errors.New("invalid signatures size")
 	}
 	for i, signature := range signatures {
-		data := tx.SigsV2[i].Data
-		signMode := data.(*signingtypes.SingleSignatureData).SignMode
+		data, ok := tx.SigsV2[i].Data.(*signingtypes.SingleSignatureData)
+		if !ok {
+			return errors.New("signatures of a multisig tx are added with AddSignaturesOf")
+		}
+		signMode := data.SignMode
 		tx.SigsV2[i].Data = &signingtypes.SingleSignatureData{
 			SignMode:  signMode,
 			Signature: reserializeSig(signature),
@@ -99,13 +121,62 @@ func (tx Tx) Serialize() ([]byte, error) {
 	return serialized, err
 }
 
+// cw20Transfer is the execute message of a CW20 transfer: {"transfer":{"amount":"...","recipient":"..."}}
+type cw20Transfer struct {
+	Transfer *cw20TransferParams `json:"transfer"`
+}
+
+type cw20TransferParams struct {
+	Amount    string `json:"amount"`
+	Recipient string `json:"recipient"`
+}
+
+// newCW20TransferMsg returns the execute message transferring amount of a CW20 token to recipient
+func newCW20TransferMsg(recipient xc.Address, amount xc.AmountBlockchain) ([]byte, error) {
+	return json.Marshal(cw20Transfer{
+		Transfer: &cw20TransferParams{Amount: amount.String(), Recipient: string(recipient)},
+	})
+}
+
+// parseCW20Transfer returns the recipient and amount of a MsgExecuteContract executing a CW20 transfer
+// The execute message is JSON, or its base64 encoding as in the amino JSON of txs
+func parseCW20Transfer(msg *wasmtypes.MsgExecuteContract) (xc.Address, xc.AmountBlockchain, bool) {
+	data := bytes.TrimSpace(msg.Msg)
+	if !json.Valid(data) {
+		decoded, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return "", xc.AmountBlockchain{}, false
+		}
+		data = decoded
+	}
+	transfer := cw20Transfer{}
+	if err := json.Unmarshal(data, &transfer); err != nil || transfer.Transfer == nil {
+		return "", xc.AmountBlockchain{}, false
+	}
+	amount, ok := new(big.Int).SetString(transfer.Transfer.Amount, 10)
+	if !ok || amount.Sign() < 0 || transfer.Transfer.Recipient == "" {
+		return "", xc.AmountBlockchain{}, false
+	}
+	return xc.Address(transfer.Transfer.Recipient), xc.AmountBlockchain(*amount), true
+}
+
 // ParseTransfer parses a Tx as a transfer
-// Currently only banktypes.MsgSend is implemented, i.e. only native tokens
+// Native transfers are banktypes.MsgSend, CW20 transfers are wasmtypes.MsgExecuteContract of a transfer,
+// IBC transfers are transfertypes.MsgTransfer (outgoing) or channeltypes.MsgRecvPacket of a transfer (incoming),
+// and transfers of custom modules are parsed by the parsers of RegisterTransferParser
 func (tx *Tx) ParseTransfer() {
 	for _, msg := range tx.CosmosTx.GetMsgs() {
 		switch msg := msg.(type) {
 		case *banktypes.MsgSend:
 			tx.ParsedTransfers = append(tx.ParsedTransfers, msg)
+		case *wasmtypes.MsgExecuteContract:
+			if _, _, ok := parseCW20Transfer(msg); ok {
+				tx.ParsedTransfers = append(tx.ParsedTransfers, msg)
+			}
+		default:
+			if _, ok := parseMsgTransfer(msg); ok {
+				tx.ParsedTransfers = append(tx.ParsedTransfers, msg)
+			}
 		}
 	}
 }
@@ -117,6 +188,14 @@ func (tx Tx) From() xc.Address {
 		case *banktypes.MsgSend:
 			from := tf.FromAddress
 			return xc.Address(from)
+		case *wasmtypes.MsgExecuteContract:
+			if _, _, ok := parseCW20Transfer(tf); ok {
+				return xc.Address(tf.Sender)
+			}
+		default:
+			if transfer, ok := parseMsgTransfer(tf); ok {
+				return transfer.Sender
+			}
 		}
 	}
 	return xc.Address("")
@@ -129,6 +208,14 @@ func (tx Tx) To() xc.Address {
 		case *banktypes.MsgSend:
 			to := tf.ToAddress
 			return xc.Address(to)
+		case *wasmtypes.MsgExecuteContract:
+			if to, _, ok := parseCW20Transfer(tf); ok {
+				return to
+			}
+		default:
+			if transfer, ok := parseMsgTransfer(tf); ok {
+				return transfer.Receiver
+			}
 		}
 	}
 	return xc.Address("")
@@ -145,6 +232,18 @@ func (tx Tx) ContractAddress() xc.ContractAddress {
 				denom = ""
 			}
 			return xc.ContractAddress(denom)
+		case *wasmtypes.MsgExecuteContract:
+			if _, _, ok := parseCW20Transfer(tf); ok {
+				return xc.ContractAddress(tf.Contract)
+			}
+		default:
+			if transfer, ok := parseMsgTransfer(tf); ok {
+				denom := transfer.Denom
+				if len(denom) < LEN_NATIVE_ASSET {
+					denom = ""
+				}
+				return xc.ContractAddress(denom)
+			}
 		}
 	}
 	return xc.ContractAddress("")
@@ -157,6 +256,14 @@ func (tx Tx) Amount() xc.AmountBlockchain {
 		case *banktypes.MsgSend:
 			amount := tf.Amount[0].Amount.BigInt()
 			return xc.AmountBlockchain(*amount)
+		case *wasmtypes.MsgExecuteContract:
+			if _, amount, ok := parseCW20Transfer(tf); ok {
+				return amount
+			}
+		default:
+			if transfer, ok := parseMsgTransfer(tf); ok {
+				return transfer.Amount
+			}
 		}
 	}
 	return xc.NewAmountBlockchainFromUint64(0)
@@ -166,12 +273,42 @@ func (tx Tx) Amount() xc.AmountBlockchain {
 func (tx Tx) Fee() xc.AmountBlockchain {
 	switch tf := tx.CosmosTx.(type) {
 	case types.FeeTx:
-		fee := tf.GetFee()[0].Amount.BigInt()
-		return xc.AmountBlockchain(*fee)
+		if fees := tf.GetFee(); len(fees) > 0 {
+			fee := fees[0].Amount.BigInt()
+			return xc.AmountBlockchain(*fee)
+		}
 	}
 	return xc.NewAmountBlockchainFromUint64(0)
 }
 
+// DeductedFee returns the fee deducted from the fee payer of a confirmed tx, from the "fee" attribute of its "tx"
+// event, in denom or else the first denom of the fee, false for nodes not emitting it
+func DeductedFee(events []abci.Event, denom string) (xc.AmountBlockchain, bool) {
+	for _, event := range events {
+		if event.Type != "tx" {
+			continue
+		}
+		for _, attribute := range event.Attributes {
+			if string(attribute.Key) != "fee" {
+				continue
+			}
+			fees, err := types.ParseCoinsNormalized(string(attribute.Value))
+			if err != nil {
+				return xc.AmountBlockchain{}, false
+			}
+			if len(fees) == 0 {
+				return xc.NewAmountBlockchainFromUint64(0), true
+			}
+			fee := fees.AmountOf(denom)
+			if !fee.IsPositive() {
+				fee = fees[0].Amount
+			}
+			return xc.AmountBlockchain(*fee.BigInt()), true
+		}
+	}
+	return xc.AmountBlockchain{}, false
+}
+
 // Sources returns the sources of a Tx
 func (tx Tx) Sources() []*xc.TxInfoEndpoint {
 	sources := []*xc.TxInfoEndpoint{}
@@ -184,6 +321,20 @@ func (tx Tx) Sources() []*xc.TxInfoEndpoint {
 			})
 			// currently assume/support single-source transfers
 			return sources
+		case *wasmtypes.MsgExecuteContract:
+			if _, _, ok := parseCW20Transfer(tf); ok {
+				sources = append(sources, &xc.TxInfoEndpoint{
+					Address: xc.Address(tf.Sender),
+				})
+				return sources
+			}
+		default:
+			if transfer, ok := parseMsgTransfer(tf); ok {
+				sources = append(sources, &xc.TxInfoEndpoint{
+					Address: transfer.Sender,
+				})
+				return sources
+			}
 		}
 	}
 	return sources
@@ -203,7 +354,72 @@ func (tx Tx) Destinations() []*xc.TxInfoEndpoint {
 				ContractAddress: xc.ContractAddress(denom),
 				Amount:          xc.AmountBlockchain(*amount),
 			})
+		case *wasmtypes.MsgExecuteContract:
+			if to, amount, ok := parseCW20Transfer(tf); ok {
+				destinations = append(destinations, &xc.TxInfoEndpoint{
+					Address:         to,
+					ContractAddress: xc.ContractAddress(tf.Contract),
+					Amount:          amount,
+				})
+			}
+		default:
+			if transfer, ok := parseMsgTransfer(tf); ok {
+				destinations = append(destinations, &xc.TxInfoEndpoint{
+					Address:         transfer.Receiver,
+					ContractAddress: xc.ContractAddress(transfer.Denom),
+					Amount:          transfer.Amount,
+				})
+			}
 		}
 	}
 	return destinations
 }
+
+// ParseTx decodes a tx not built by this package, e.g. from a broadcast payload or a tx_search result,
+// and parses its transfers. data is the protobuf encoding of the tx, or its hex or base64 encoding.
+func ParseTx(data []byte) (*Tx, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty tx")
+	}
+	config := parseTxEncodingConfig()
+	// hex first, as hex strings are also valid base64
+	candidates := [][]byte{}
+	text := strings.TrimSpace(string(data))
+	if txBytes, err := hex.DecodeString(strings.TrimPrefix(text, "0x")); err == nil {
+		candidates = append(candidates, txBytes)
+	}
+	if txBytes, err := base64.StdEncoding.DecodeString(text); err == nil {
+		candidates = append(candidates, txBytes)
+	}
+	candidates = append(candidates, data)
+
+	var err error
+	for _, txBytes := range candidates {
+		var tx *Tx
+		tx, err = decodeTx(config.TxConfig, config.InterfaceRegistry, txBytes)
+		if err == nil {
+			return tx, nil
+		}
+	}
+	return nil, fmt.Errorf("could not decode tx: %v", err)
+}
+
+// decodeTx returns a Tx, with its transfers parsed, from the protobuf encoding of a tx
+// Msgs of types unknown to registry are skipped, see UnknownMsgTypes, and the blobs of Celestia txs as well
+func decodeTx(txConfig client.TxConfig, registry codectypes.InterfaceRegistry, txBytes []byte) (*Tx, error) {
+	txBytes, _ = unwrapBlobTx(txBytes)
+	decodedTx, err := txConfig.TxDecoder()(txBytes)
+	if err != nil {
+		partial, partialErr := decodePartialTx(registry, txBytes)
+		if partialErr != nil {
+			return nil, err
+		}
+		decodedTx = partial
+	}
+	tx := &Tx{
+		CosmosTx:        decodedTx,
+		CosmosTxEncoder: partialTxEncoder(txConfig.TxEncoder()),
+	}
+	tx.ParseTransfer()
+	return tx, nil
+}
@@ -1,12 +1,17 @@
 package cosmos
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"strings"
 
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	ethermintCodec "github.com/evmos/ethermint/encoding/codec"
 	xc "github.com/jumpcrypto/crosschain"
+	abci "github.com/tendermint/tendermint/abci/types"
 )
 
 func (s *CrosschainTestSuite) TestTx() {
@@ -145,3 +150,133 @@ func (s *CrosschainTestSuite) TestTxSerialize() {
 	require.EqualError(err, "transaction not initialized")
 	require.Equal(serialized, []byte{})
 }
+
+func (s *CrosschainTestSuite) TestParseTx() {
+	require := s.Require()
+	// received LUNA from faucet
+	txHex := "0a99010a8e010a1c2f636f736d6f732e62616e6b2e763162657461312e4d736753656e64126e0a2c74657272613168386c6a646d6165376c7830356b6a6a37396339656b73637773796a6433797238777976646e122c746572726131647033713330356867747474386e33347274387267397870616e6334327a34796537757066671a100a05756c756e611207353030303030301206666175636574126c0a520a460a1f2f636f736d6f732e63727970746f2e736563703235366b312e5075624b657912230a2102afeedb21a149fc0237978dccfe15d2c20e518eb77681eae2a5af9a973e83d89312040a02080118f58a0112160a100a05756c756e6112073130303030303010f093091a40ebd5b4de486a6a521a13f7e787b6fb9b764f84910bf0264e100ecff8620e73b775f325f0ed969e7a09cd4c83be126d1756547fca6d6297dfd0e8b75d857d360c"
+	txBytes, _ := hex.DecodeString(txHex)
+
+	for _, data := range [][]byte{
+		txBytes,
+		[]byte(txHex),
+		[]byte("0x" + strings.ToUpper(txHex)),
+		// as in tx_search results
+		[]byte(base64.StdEncoding.EncodeToString(txBytes) + "\n"),
+	} {
+		tx, err := ParseTx(data)
+		require.NoError(err)
+		require.Equal("e9c24c2e23cdca56c8ce87a583149f8f88e75923f0cd958c003a84f631948978", string(tx.Hash()))
+		require.Equal("terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn", string(tx.From()))
+		require.Equal("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", string(tx.To()))
+		require.Equal("5000000", tx.Amount().String())
+		require.Equal("1000000", tx.Fee().String())
+		require.Len(tx.ParsedTransfers, 1)
+	}
+
+	_, err := ParseTx([]byte{})
+	require.EqualError(err, "empty tx")
+	_, err = ParseTx([]byte("not a tx"))
+	require.ErrorContains(err, "could not decode tx")
+}
+
+func (s *CrosschainTestSuite) TestCW20Transfer() {
+	require := s.Require()
+	contract := "terra14z56l0fp2lsf86zy3hty2z47ezkhnthtr9yq76"
+	from := xc.Address("terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn")
+	to := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	publicKey, _ := hex.DecodeString("02afeedb21a149fc0237978dccfe15d2c20e518eb77681eae2a5af9a973e83d893")
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra", Type: xc.AssetTypeToken, Contract: contract})
+	tx, err := builder.NewTransfer(from, to, xc.NewAmountBlockchainFromUint64(1200000), &TxInput{FromPublicKey: publicKey, GasPrice: 0.015})
+	require.NoError(err)
+	msg := tx.(*Tx).ParsedTransfers[0].(*wasmtypes.MsgExecuteContract)
+	require.JSONEq(`{"transfer":{"amount":"1200000","recipient":"terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg"}}`, string(msg.Msg))
+
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	parsed, err := ParseTx(serialized)
+	require.NoError(err)
+	require.Equal(from, parsed.From())
+	require.Equal(to, parsed.To())
+	require.Equal("1200000", parsed.Amount().String())
+	require.Equal(xc.ContractAddress(contract), parsed.ContractAddress())
+	require.Equal([]*xc.TxInfoEndpoint{{Address: from}}, parsed.Sources())
+	require.Equal([]*xc.TxInfoEndpoint{{Address: to, ContractAddress: xc.ContractAddress(contract), Amount: xc.NewAmountBlockchainFromUint64(1200000)}}, parsed.Destinations())
+
+	// base64 execute message, as in amino JSON
+	msg.Msg = wasmtypes.RawContractMessage(base64.StdEncoding.EncodeToString(msg.Msg))
+	require.Equal(to, tx.(*Tx).To())
+	require.Equal("1200000", tx.(*Tx).Amount().String())
+
+	// other execute messages aren't transfers
+	for _, execute := range []string{
+		`{"send":{"amount":"1","contract":"terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg","msg":""}}`,
+		`{"transfer":{"amount":"-1","recipient":"terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg"}}`,
+		`not json`,
+	} {
+		other := &Tx{CosmosTx: tx.(*Tx).CosmosTxBuilder.GetTx()}
+		msg.Msg = wasmtypes.RawContractMessage(execute)
+		other.ParseTransfer()
+		require.Empty(other.ParsedTransfers, execute)
+		require.Equal(xc.Address(""), other.To())
+	}
+}
+
+func (s *CrosschainTestSuite) TestDeductedFee() {
+	require := s.Require()
+	feeEvent := func(fee string) []abci.Event {
+		return []abci.Event{
+			{Type: "coin_spent", Attributes: []abci.EventAttribute{{Key: []byte("amount"), Value: []byte("5uluna")}}},
+			{Type: "tx", Attributes: []abci.EventAttribute{{Key: []byte("acc_seq"), Value: []byte("terra1/1")}}},
+			{Type: "tx", Attributes: []abci.EventAttribute{{Key: []byte("fee"), Value: []byte(fee)}, {Key: []byte("fee_payer"), Value: []byte("terra1")}}},
+		}
+	}
+
+	fee, ok := DeductedFee(feeEvent("1500uluna"), "uluna")
+	require.True(ok)
+	require.EqualValues(1500, fee.Uint64())
+
+	fee, ok = DeductedFee(feeEvent("20ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2,1500uluna"), "uluna")
+	require.True(ok)
+	require.EqualValues(1500, fee.Uint64())
+
+	// first denom if not paid in the gas denom
+	fee, ok = DeductedFee(feeEvent("20uusd"), "uluna")
+	require.True(ok)
+	require.EqualValues(20, fee.Uint64())
+
+	fee, ok = DeductedFee(feeEvent(""), "uluna")
+	require.True(ok)
+	require.EqualValues(0, fee.Uint64())
+
+	_, ok = DeductedFee(feeEvent("invalid"), "uluna")
+	require.False(ok)
+	_, ok = DeductedFee(feeEvent("1500uluna")[:2], "uluna")
+	require.False(ok)
+}
+
+func (s *CrosschainTestSuite) TestTxSignPayloads() {
+	require := s.Require()
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	to := xc.Address("terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn")
+	publicKey, _ := hex.DecodeString("02afeedb21a149fc0237978dccfe15d2c20e518eb77681eae2a5af9a973e83d893")
+	asset := &xc.AssetConfig{NativeAsset: xc.LUNA, ChainCoin: "uluna", ChainPrefix: "terra", ChainIDStr: "phoenix-1", Type: xc.AssetTypeNative}
+	builder, _ := NewTxBuilder(asset)
+	for _, signMode := range []signing.SignMode{signing.SignMode_SIGN_MODE_DIRECT, signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON} {
+		input := &TxInput{AccountNumber: 1, Sequence: 2, GasLimit: 200_000, GasPrice: 0.015, FromPublicKey: publicKey, SignMode: signMode}
+		tx, err := builder.(TxBuilder).NewNativeTransfer(from, to, xc.NewAmountBlockchainFromUint64(1_000), input)
+		require.NoError(err)
+		payloads, err := tx.(*Tx).SignPayloads()
+		require.NoError(err)
+		require.Len(payloads, 1)
+		sighashes, _ := tx.Sighashes()
+		sighash := sha256.Sum256(payloads[0])
+		require.Equal([]byte(sighashes[0]), sighash[:])
+		if signMode == signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON {
+			require.Contains(string(payloads[0]), `"chain_id":"phoenix-1"`)
+		}
+	}
+
+	_, err := Tx{}.SignPayloads()
+	require.EqualError(err, "transaction not initialized")
+}
@@ -0,0 +1,213 @@
+package cosmos
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// ValidatorDescription describes a validator, e.g. as shown in explorers and wallets
+type ValidatorDescription struct {
+	Moniker         string
+	Identity        string
+	Website         string
+	SecurityContact string
+	Details         string
+}
+
+// ValidatorCommission holds commission rates as decimal strings, e.g. "0.05" for 5%
+type ValidatorCommission struct {
+	Rate          string
+	MaxRate       string
+	MaxChangeRate string
+}
+
+// ValidatorParams are the parameters required to create a validator
+type ValidatorParams struct {
+	Description       ValidatorDescription
+	Commission        ValidatorCommission
+	MinSelfDelegation xc.AmountBlockchain
+	// ConsensusPubKey is the raw ed25519 Tendermint consensus public key (32 bytes)
+	ConsensusPubKey []byte
+}
+
+// ValidatorEditParams are the parameters to edit an existing validator
+// Empty description fields are left unchanged, as are nil CommissionRate and MinSelfDelegation
+type ValidatorEditParams struct {
+	Description       ValidatorDescription
+	CommissionRate    string
+	MinSelfDelegation *xc.AmountBlockchain
+}
+
+// ValoperAddressFromAddress converts an account address to its validator operator address, e.g. cosmos1... to cosmosvaloper1...
+func ValoperAddressFromAddress(address xc.Address, prefix string) (xc.Address, error) {
+	addressBytes, err := accAddressFromBech32WithPrefix(string(address), prefix)
+	if err != nil {
+		return xc.Address(""), err
+	}
+	valoper, err := types.Bech32ifyAddressBytes(prefix+types.PrefixValidator+types.PrefixOperator, addressBytes)
+	return xc.Address(valoper), err
+}
+
+func (desc ValidatorDescription) toStaking(defaultValue string) stakingtypes.Description {
+	orDefault := func(value string) string {
+		if value == "" {
+			return defaultValue
+		}
+		return value
+	}
+	return stakingtypes.Description{
+		Moniker:         orDefault(desc.Moniker),
+		Identity:        orDefault(desc.Identity),
+		Website:         orDefault(desc.Website),
+		SecurityContact: orDefault(desc.SecurityContact),
+		Details:         orDefault(desc.Details),
+	}
+}
+
+func (commission ValidatorCommission) toStaking() (stakingtypes.CommissionRates, error) {
+	rate, err := types.NewDecFromStr(commission.Rate)
+	if err != nil {
+		return stakingtypes.CommissionRates{}, fmt.Errorf("invalid commission rate: %v", err)
+	}
+	maxRate, err := types.NewDecFromStr(commission.MaxRate)
+	if err != nil {
+		return stakingtypes.CommissionRates{}, fmt.Errorf("invalid commission max rate: %v", err)
+	}
+	maxChangeRate, err := types.NewDecFromStr(commission.MaxChangeRate)
+	if err != nil {
+		return stakingtypes.CommissionRates{}, fmt.Errorf("invalid commission max change rate: %v", err)
+	}
+	rates := stakingtypes.NewCommissionRates(rate, maxRate, maxChangeRate)
+	return rates, rates.Validate()
+}
+
+// NewCreateValidator creates a MsgCreateValidator tx, self-delegating amount from the operator account
+func (txBuilder TxBuilder) NewCreateValidator(from xc.Address, amount xc.AmountBlockchain, params ValidatorParams, input xc.TxInput) (xc.Tx, error) {
+	cosmosInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, errors.New("xc.TxInput is not from a cosmos chain")
+	}
+	asset := txBuilder.Asset
+	amountInt := big.Int(amount)
+	minSelfDelegation := big.Int(params.MinSelfDelegation)
+
+	// copy the input so the default gas limit doesn't leak into the caller's input
+	txInput := *cosmosInput
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 400_000
+	}
+
+	valoper, err := ValoperAddressFromAddress(from, asset.GetNativeAsset().ChainPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(params.ConsensusPubKey) != ed25519.PubKeySize {
+		return nil, fmt.Errorf("invalid consensus public key size: %d", len(params.ConsensusPubKey))
+	}
+	pubKey, err := codectypes.NewAnyWithValue(&ed25519.PubKey{Key: params.ConsensusPubKey})
+	if err != nil {
+		return nil, err
+	}
+	commission, err := params.Commission.toStaking()
+	if err != nil {
+		return nil, err
+	}
+	description, err := params.Description.toStaking("").EnsureLength()
+	if err != nil {
+		return nil, err
+	}
+	if description.Moniker == "" {
+		return nil, errors.New("validator moniker is required")
+	}
+	if amountInt.Cmp(&minSelfDelegation) < 0 {
+		return nil, errors.New("self delegation is below the minimum self delegation")
+	}
+
+	msg := &stakingtypes.MsgCreateValidator{
+		Description:       description,
+		Commission:        commission,
+		MinSelfDelegation: types.NewIntFromBigInt(&minSelfDelegation),
+		DelegatorAddress:  string(from),
+		ValidatorAddress:  string(valoper),
+		Pubkey:            pubKey,
+		Value: types.Coin{
+			Denom:  asset.GetNativeAsset().ChainCoin,
+			Amount: types.NewIntFromBigInt(&amountInt),
+		},
+	}
+	return txBuilder.createTxWithMsgs(&txInput, msg)
+}
+
+// NewEditValidator creates a MsgEditValidator tx for the validator operated by from
+func (txBuilder TxBuilder) NewEditValidator(from xc.Address, params ValidatorEditParams, input xc.TxInput) (xc.Tx, error) {
+	cosmosInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, errors.New("xc.TxInput is not from a cosmos chain")
+	}
+	asset := txBuilder.Asset
+
+	// copy the input so the default gas limit doesn't leak into the caller's input
+	txInput := *cosmosInput
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 400_000
+	}
+
+	valoper, err := ValoperAddressFromAddress(from, asset.GetNativeAsset().ChainPrefix)
+	if err != nil {
+		return nil, err
+	}
+	description, err := params.Description.toStaking(stakingtypes.DoNotModifyDesc).EnsureLength()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &stakingtypes.MsgEditValidator{
+		Description:      description,
+		ValidatorAddress: string(valoper),
+	}
+	if params.CommissionRate != "" {
+		rate, err := types.NewDecFromStr(params.CommissionRate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commission rate: %v", err)
+		}
+		msg.CommissionRate = &rate
+	}
+	if params.MinSelfDelegation != nil {
+		minSelfDelegationInt := big.Int(*params.MinSelfDelegation)
+		minSelfDelegation := types.NewIntFromBigInt(&minSelfDelegationInt)
+		msg.MinSelfDelegation = &minSelfDelegation
+	}
+	return txBuilder.createTxWithMsgs(&txInput, msg)
+}
+
+// NewUnjail creates a MsgUnjail tx for the validator operated by from
+func (txBuilder TxBuilder) NewUnjail(from xc.Address, input xc.TxInput) (xc.Tx, error) {
+	cosmosInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, errors.New("xc.TxInput is not from a cosmos chain")
+	}
+	asset := txBuilder.Asset
+
+	// copy the input so the default gas limit doesn't leak into the caller's input
+	txInput := *cosmosInput
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 400_000
+	}
+
+	valoper, err := ValoperAddressFromAddress(from, asset.GetNativeAsset().ChainPrefix)
+	if err != nil {
+		return nil, err
+	}
+	msg := &slashingtypes.MsgUnjail{
+		ValidatorAddr: string(valoper),
+	}
+	return txBuilder.createTxWithMsgs(&txInput, msg)
+}
@@ -0,0 +1,150 @@
+package cosmos
+
+import (
+	"encoding/hex"
+
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestValoperAddressFromAddress() {
+	require := s.Require()
+
+	valoper, err := ValoperAddressFromAddress("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "terra")
+	require.Nil(err)
+	require.Equal(xc.Address("terravaloper1dp3q305hgttt8n34rt8rg9xpanc42z4ye3suem"), valoper)
+
+	_, err = ValoperAddressFromAddress("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", "xpla")
+	require.ErrorContains(err, "invalid Bech32 prefix")
+}
+
+func (s *CrosschainTestSuite) TestNewCreateValidator() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra", ChainIDStr: "pisco-1"}
+	builder, _ := NewTxBuilder(asset)
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	consensusPubKey, _ := hex.DecodeString("b7a3c12dc0c8c748ab07525b701122b88bd78f600c76342d27f25e5f92444cde")
+	params := ValidatorParams{
+		Description: ValidatorDescription{Moniker: "my-validator"},
+		Commission: ValidatorCommission{
+			Rate:          "0.05",
+			MaxRate:       "0.2",
+			MaxChangeRate: "0.01",
+		},
+		MinSelfDelegation: xc.NewAmountBlockchainFromUint64(1),
+		ConsensusPubKey:   consensusPubKey,
+	}
+
+	tx, err := builder.(TxBuilder).NewCreateValidator(from, xc.NewAmountBlockchainFromUint64(1_000_000), params, &TxInput{GasPrice: 0.015})
+	require.Nil(err)
+	sighashes, err := tx.Sighashes()
+	require.Nil(err)
+	require.Len(sighashes, 1)
+
+	msg := tx.(*Tx).ParsedTransfers[0].(*stakingtypes.MsgCreateValidator)
+	require.Equal("terravaloper1dp3q305hgttt8n34rt8rg9xpanc42z4ye3suem", msg.ValidatorAddress)
+	require.Equal(string(from), msg.DelegatorAddress)
+	require.Equal("1000000uluna", msg.Value.String())
+	require.Equal("0.050000000000000000", msg.Commission.Rate.String())
+	require.Equal("my-validator", msg.Description.Moniker)
+	require.NotNil(msg.Pubkey)
+}
+
+func (s *CrosschainTestSuite) TestNewCreateValidatorErr() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra"}
+	builder, _ := NewTxBuilder(asset)
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	valid := ValidatorParams{
+		Description:       ValidatorDescription{Moniker: "my-validator"},
+		Commission:        ValidatorCommission{Rate: "0.05", MaxRate: "0.2", MaxChangeRate: "0.01"},
+		MinSelfDelegation: xc.NewAmountBlockchainFromUint64(1),
+		ConsensusPubKey:   make([]byte, 32),
+	}
+
+	params := valid
+	params.ConsensusPubKey = []byte{1, 2, 3}
+	_, err := builder.(TxBuilder).NewCreateValidator(from, xc.NewAmountBlockchainFromUint64(10), params, &TxInput{})
+	require.ErrorContains(err, "invalid consensus public key size")
+
+	params = valid
+	params.Commission.Rate = "0.5"
+	_, err = builder.(TxBuilder).NewCreateValidator(from, xc.NewAmountBlockchainFromUint64(10), params, &TxInput{})
+	require.ErrorContains(err, "commission cannot be more than the max rate")
+
+	params = valid
+	params.Commission.MaxRate = "abc"
+	_, err = builder.(TxBuilder).NewCreateValidator(from, xc.NewAmountBlockchainFromUint64(10), params, &TxInput{})
+	require.ErrorContains(err, "invalid commission max rate")
+
+	params = valid
+	params.Description.Moniker = ""
+	_, err = builder.(TxBuilder).NewCreateValidator(from, xc.NewAmountBlockchainFromUint64(10), params, &TxInput{})
+	require.ErrorContains(err, "moniker is required")
+
+	params = valid
+	params.MinSelfDelegation = xc.NewAmountBlockchainFromUint64(100)
+	_, err = builder.(TxBuilder).NewCreateValidator(from, xc.NewAmountBlockchainFromUint64(10), params, &TxInput{})
+	require.ErrorContains(err, "below the minimum self delegation")
+
+	_, err = builder.(TxBuilder).NewCreateValidator("xpla1hdvf6vv5amc7wp84js0ls27apekwxpr0ge96kg", xc.NewAmountBlockchainFromUint64(10), valid, &TxInput{})
+	require.ErrorContains(err, "invalid Bech32 prefix")
+
+	_, err = builder.(TxBuilder).NewCreateValidator(from, xc.NewAmountBlockchainFromUint64(10), valid, &struct{ xc.TxInputEnvelope }{})
+	require.ErrorContains(err, "not from a cosmos chain")
+}
+
+func (s *CrosschainTestSuite) TestNewEditValidator() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra"}
+	builder, _ := NewTxBuilder(asset)
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+
+	minSelfDelegation := xc.NewAmountBlockchainFromUint64(5)
+	tx, err := builder.(TxBuilder).NewEditValidator(from, ValidatorEditParams{
+		Description:       ValidatorDescription{Website: "https://example.com"},
+		CommissionRate:    "0.1",
+		MinSelfDelegation: &minSelfDelegation,
+	}, &TxInput{})
+	require.Nil(err)
+	msg := tx.(*Tx).ParsedTransfers[0].(*stakingtypes.MsgEditValidator)
+	require.Equal("terravaloper1dp3q305hgttt8n34rt8rg9xpanc42z4ye3suem", msg.ValidatorAddress)
+	require.Equal(stakingtypes.DoNotModifyDesc, msg.Description.Moniker)
+	require.Equal("https://example.com", msg.Description.Website)
+	require.Equal("0.100000000000000000", msg.CommissionRate.String())
+	require.Equal("5", msg.MinSelfDelegation.String())
+
+	tx, err = builder.(TxBuilder).NewEditValidator(from, ValidatorEditParams{}, &TxInput{})
+	require.Nil(err)
+	msg = tx.(*Tx).ParsedTransfers[0].(*stakingtypes.MsgEditValidator)
+	require.Nil(msg.CommissionRate)
+	require.Nil(msg.MinSelfDelegation)
+
+	_, err = builder.(TxBuilder).NewEditValidator(from, ValidatorEditParams{CommissionRate: "x"}, &TxInput{})
+	require.ErrorContains(err, "invalid commission rate")
+
+	_, err = builder.(TxBuilder).NewEditValidator(from, ValidatorEditParams{}, &struct{ xc.TxInputEnvelope }{})
+	require.ErrorContains(err, "not from a cosmos chain")
+}
+
+func (s *CrosschainTestSuite) TestNewUnjail() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: "LUNA", ChainCoin: "uluna", ChainPrefix: "terra"}
+	builder, _ := NewTxBuilder(asset)
+
+	input := &TxInput{}
+	tx, err := builder.(TxBuilder).NewUnjail("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", input)
+	require.Nil(err)
+	require.Equal(uint64(400_000), tx.(*Tx).CosmosTxBuilder.GetTx().GetGas())
+	// the default gas limit isn't set on the caller's input
+	require.Equal(uint64(0), input.GasLimit)
+	msg := tx.(*Tx).ParsedTransfers[0].(*slashingtypes.MsgUnjail)
+	require.Equal("terravaloper1dp3q305hgttt8n34rt8rg9xpanc42z4ye3suem", msg.ValidatorAddr)
+
+	_, err = builder.(TxBuilder).NewUnjail("invalid", input)
+	require.Error(err)
+
+	_, err = builder.(TxBuilder).NewUnjail("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg", &struct{ xc.TxInputEnvelope }{})
+	require.ErrorContains(err, "not from a cosmos chain")
+}
@@ -0,0 +1,154 @@
+package cosmos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// NodeVersion is the version of a node, telling the breaking differences of its Cosmos SDK version
+// The SDK version isn't exposed over Tendermint RPC: it's inferred from the Tendermint (or CometBFT) version
+type NodeVersion struct {
+	// Tendermint version of the node, e.g. 0.34.27
+	Tendermint string `json:"tendermint"`
+	// App is the name of the application, e.g. GaiaApp
+	App string `json:"app"`
+	// AppVersion is the version of the application, e.g. v9.0.0
+	AppVersion string `json:"app_version"`
+	// SDK is the range of Cosmos SDK versions running on the Tendermint version, e.g. 0.40-0.46
+	SDK string `json:"sdk"`
+}
+
+// sdkVersions are the Cosmos SDK versions by Tendermint minor version
+var sdkVersions = map[int]string{
+	33: "0.38-0.39",
+	34: "0.40-0.46",
+	37: "0.47",
+	38: "0.50",
+}
+
+// NewNodeVersion returns the NodeVersion of a node running tendermint and appVersion of app
+func NewNodeVersion(tendermint string, app string, appVersion string) *NodeVersion {
+	version := &NodeVersion{
+		Tendermint: tendermint,
+		App:        app,
+		AppVersion: appVersion,
+	}
+	if minor, ok := version.tendermintMinor(); ok {
+		version.SDK = sdkVersions[minor]
+	}
+	return version
+}
+
+// tendermintMinor returns the minor version of Tendermint, e.g. 34 for 0.34.27
+func (version *NodeVersion) tendermintMinor() (int, bool) {
+	parts := strings.Split(strings.TrimPrefix(version.Tendermint, "v"), ".")
+	if len(parts) < 2 || parts[0] != "0" {
+		return 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	return minor, err == nil
+}
+
+// Legacy returns true for nodes older than Stargate (SDK 0.40): txs are amino encoded StdTx signed over
+// the amino JSON sign doc, and queries are legacy custom queries instead of gRPC queries
+func (version *NodeVersion) Legacy() bool {
+	minor, ok := version.tendermintMinor()
+	return ok && minor < 34
+}
+
+// SignMode returns the sign mode of txs sent to the node
+func (version *NodeVersion) SignMode() signingtypes.SignMode {
+	if version.Legacy() {
+		return signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+	}
+	return signingtypes.SignMode_SIGN_MODE_DIRECT
+}
+
+// NegotiateVersion fetches the version of the node, then cached for the lifetime of the client
+func (client *Client) NegotiateVersion(ctx context.Context) (*NodeVersion, error) {
+	if version := client.nodeVersion(); version != nil {
+		return version, nil
+	}
+	status, err := client.Ctx.Client.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch node status: %v", err)
+	}
+	info, err := client.Ctx.Client.ABCIInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch abci info: %v", err)
+	}
+	version := NewNodeVersion(status.NodeInfo.Version, info.Response.Data, info.Response.Version)
+	client.versionMu.Lock()
+	client.version = version
+	client.versionMu.Unlock()
+	return version, nil
+}
+
+func (client *Client) nodeVersion() *NodeVersion {
+	client.versionMu.RLock()
+	defer client.versionMu.RUnlock()
+	return client.version
+}
+
+// isUnknownQueryPath returns true for the error of pre-Stargate nodes to gRPC queries
+func isUnknownQueryPath(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "unknown query path")
+}
+
+// legacyAccount is the amino JSON of an account returned by the legacy account query
+type legacyAccount struct {
+	Value struct {
+		Address       string `json:"address"`
+		AccountNumber string `json:"account_number"`
+		Sequence      string `json:"sequence"`
+	} `json:"value"`
+}
+
+// getLegacyAccount returns an account of a pre-Stargate node, from the custom/acc/account query
+func (client *Client) getLegacyAccount(ctx context.Context, address xc.Address) (client.Account, error) {
+	params, err := json.Marshal(map[string]string{"Address": string(address)})
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Ctx.Client.ABCIQuery(ctx, "custom/acc/account", params)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Response.IsOK() {
+		return nil, fmt.Errorf("could not query account: %s", res.Response.Log)
+	}
+	account := legacyAccount{}
+	if err := json.Unmarshal(res.Response.Value, &account); err != nil {
+		return nil, fmt.Errorf("could not decode account: %v", err)
+	}
+	accountNumber, err := parseLegacyUint(account.Value.AccountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account number: %v", err)
+	}
+	sequence, err := parseLegacyUint(account.Value.Sequence)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sequence: %v", err)
+	}
+	accAddress, err := types.GetFromBech32(string(address), client.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	return authtypes.NewBaseAccount(accAddress, nil, accountNumber, sequence), nil
+}
+
+// parseLegacyUint parses an amino JSON uint64, a string omitted when zero
+func parseLegacyUint(value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
@@ -0,0 +1,125 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestNewNodeVersion() {
+	require := s.Require()
+
+	vectors := []struct {
+		tendermint string
+		sdk        string
+		legacy     bool
+		signMode   signingtypes.SignMode
+	}{
+		{"0.33.9", "0.38-0.39", true, signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON},
+		{"0.34.27", "0.40-0.46", false, signingtypes.SignMode_SIGN_MODE_DIRECT},
+		{"v0.37.1", "0.47", false, signingtypes.SignMode_SIGN_MODE_DIRECT},
+		{"0.38.2", "0.50", false, signingtypes.SignMode_SIGN_MODE_DIRECT},
+		{"", "", false, signingtypes.SignMode_SIGN_MODE_DIRECT},
+		{"1.0.0", "", false, signingtypes.SignMode_SIGN_MODE_DIRECT},
+	}
+	for _, v := range vectors {
+		version := NewNodeVersion(v.tendermint, "GaiaApp", "v9.0.0")
+		require.Equal(v.sdk, version.SDK, v.tendermint)
+		require.Equal(v.legacy, version.Legacy(), v.tendermint)
+		require.Equal(v.signMode, version.SignMode(), v.tendermint)
+	}
+}
+
+func (s *CrosschainTestSuite) TestNegotiateVersion() {
+	require := s.Require()
+	status := `{"node_info":{"version":"0.34.27","network":"cosmoshub-4"},"sync_info":{"latest_block_height":"100","latest_block_time":"2023-05-03T14:00:00Z","catching_up":false}}`
+	abciInfo := `{"jsonrpc":"2.0","id":1,"result":{"response":{"data":"GaiaApp","version":"v9.0.0","last_block_height":"100"}}}`
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{status, abciInfo})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ATOM, URL: server.URL})
+	version, err := client.NegotiateVersion(s.Ctx)
+	require.NoError(err)
+	require.Equal(&NodeVersion{Tendermint: "0.34.27", App: "GaiaApp", AppVersion: "v9.0.0", SDK: "0.40-0.46"}, version)
+
+	// cached
+	version, err = client.NegotiateVersion(s.Ctx)
+	require.NoError(err)
+	require.Equal("0.40-0.46", version.SDK)
+	require.Equal(2, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInputLegacyNode() {
+	require := s.Require()
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	unknownQueryPath := `{"jsonrpc":"2.0","id":0,"result":{"response":{"code":6,"log":"unknown request: unknown query path","codespace":"sdk","height":"100"}}}`
+	status := `{"jsonrpc":"2.0","id":1,"result":{"node_info":{"version":"0.33.9","network":"columbus-4"},"sync_info":{"latest_block_height":"100","latest_block_time":"2021-05-03T14:00:00Z","catching_up":false}}}`
+	abciInfo := `{"jsonrpc":"2.0","id":2,"result":{"response":{"data":"TerraApp","version":"0.4.6","last_block_height":"100"}}}`
+	account := func(id int) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"response":{"code":0,"value":"eyJ0eXBlIjoiY29yZS9BY2NvdW50IiwidmFsdWUiOnsiYWRkcmVzcyI6InRlcnJhMWRwM3EzMDVoZ3R0dDhuMzRydDhyZzl4cGFuYzQyejR5ZTd1cGZnIiwiYWNjb3VudF9udW1iZXIiOiIxNzI0MSIsInNlcXVlbmNlIjoiMyJ9fQ==","height":"100"}}}`, id)
+	}
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{unknownQueryPath, status, abciInfo, account(3), account(4)})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.LUNA, ChainPrefix: "terra", NoGasFees: true, URL: server.URL})
+	input, err := client.FetchTxInput(s.Ctx, from, "")
+	require.NoError(err)
+	txInput := input.(*TxInput)
+	require.EqualValues(17241, txInput.AccountNumber)
+	require.EqualValues(3, txInput.Sequence)
+	require.True(txInput.LegacyAmino)
+	require.Equal(signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON, txInput.SignMode)
+
+	// the version is negotiated once
+	input, err = client.FetchTxInput(s.Ctx, from, "")
+	require.NoError(err)
+	require.True(input.(*TxInput).LegacyAmino)
+	require.Equal(5, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestNewNativeTransferLegacyAmino() {
+	require := s.Require()
+	from := xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+	to := xc.Address("terra1h8ljdmae7lx05kjj79c9ekscwsyjd3yr8wyvdn")
+	publicKey, _ := hex.DecodeString("02afeedb21a149fc0237978dccfe15d2c20e518eb77681eae2a5af9a973e83d893")
+	asset := &xc.AssetConfig{NativeAsset: xc.LUNA, ChainCoin: "uluna", ChainPrefix: "terra", ChainIDStr: "columbus-4", Type: xc.AssetTypeNative}
+	input := &TxInput{
+		AccountNumber: 17241,
+		Sequence:      3,
+		GasLimit:      200_000,
+		GasPrice:      0.015,
+		FromPublicKey: publicKey,
+		SignMode:      signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+		LegacyAmino:   true,
+	}
+
+	builder, _ := NewTxBuilder(asset)
+	tx, err := builder.(TxBuilder).NewNativeTransfer(from, to, xc.NewAmountBlockchainFromUint64(1_000), input)
+	require.NoError(err)
+	_, ok := tx.(*Tx).CosmosTx.(legacytx.StdTx)
+	require.True(ok)
+
+	msg := &banktypes.MsgSend{FromAddress: string(from), ToAddress: string(to), Amount: types.NewCoins(types.NewInt64Coin("uluna", 1_000))}
+	fee := legacytx.NewStdFee(200_000, types.NewCoins(types.NewInt64Coin("uluna", 3_000)))
+	signDoc := legacytx.StdSignBytes("columbus-4", 17241, 3, 0, fee, []types.Msg{msg}, "")
+	require.Contains(string(signDoc), `"chain_id":"columbus-4"`)
+	sighash := sha256.Sum256(signDoc)
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Equal(sighash[:], []byte(sighashes[0]))
+
+	err = tx.AddSignatures(make([]byte, 64))
+	require.NoError(err)
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	decoded, err := legacytx.StdTxConfig{Cdc: MakeCosmosConfig().Amino}.TxDecoder()(serialized)
+	require.NoError(err)
+	require.Equal("3000uluna", decoded.(legacytx.StdTx).Fee.Amount.String())
+}
@@ -2,7 +2,10 @@ package evm
 
 import (
 	"crypto/ecdsa"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -13,6 +16,8 @@ import (
 type AddressBuilder struct {
 }
 
+var _ xc.AddressValidator = &AddressBuilder{}
+
 // NewAddressBuilder creates a new EVM AddressBuilder
 func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
 	return AddressBuilder{}, nil
@@ -49,6 +54,25 @@ func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []b
 	}, err
 }
 
+// ValidateAddress checks an address is 20 bytes of hex, with a valid EIP-55 checksum if it's mixed case
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	str := TrimPrefixes(string(address))
+	if len(str) != 2*common.AddressLength {
+		return fmt.Errorf("invalid address '%s': expected %d bytes", address, common.AddressLength)
+	}
+	if _, err := hex.DecodeString(str); err != nil {
+		return fmt.Errorf("invalid address '%s': not hex", address)
+	}
+	if str == strings.ToLower(str) || str == strings.ToUpper(str) {
+		// no checksum
+		return nil
+	}
+	if common.HexToAddress(str).Hex()[2:] != str {
+		return fmt.Errorf("invalid EIP-55 checksum of address '%s'", address)
+	}
+	return nil
+}
+
 // HexToAddress returns a go-ethereum Address decoded Crosschain address (hex string).
 func HexToAddress(address xc.Address) (common.Address, error) {
 	str := TrimPrefixes(string(address))
@@ -73,3 +73,19 @@ func (s *CrosschainTestSuite) TestHexToAddress() {
 	require.Nil(err)
 	require.Equal(common.Address{0x58, 0x91, 0x90, 0x6f, 0xEf, 0x64, 0xA5, 0xae, 0x92, 0x4C, 0x7F, 0xc5, 0xed, 0x48, 0xc0, 0xF6, 0x4a, 0x55, 0xfC, 0xe1}, address)
 }
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{})
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fCe1"))
+	// no checksum
+	require.NoError(validator.ValidateAddress("0x5891906fef64a5ae924c7fc5ed48c0f64a55fce1"))
+	require.NoError(validator.ValidateAddress("0x5891906FEF64A5AE924C7FC5ED48C0F64A55FCE1"))
+	require.NoError(validator.ValidateAddress("xdc5891906fEf64A5ae924C7Fc5ed48c0F64a55fCe1"))
+
+	require.EqualError(validator.ValidateAddress("0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fcE1"), "invalid EIP-55 checksum of address '0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fcE1'")
+	require.EqualError(validator.ValidateAddress("0x891906fEf64A5ae924C7Fc5ed48c0F64a55fCe1"), "invalid address '0x891906fEf64A5ae924C7Fc5ed48c0F64a55fCe1': expected 20 bytes")
+	require.EqualError(validator.ValidateAddress("0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fCeZ"), "invalid address '0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fCeZ': not hex")
+	require.Error(validator.ValidateAddress(""))
+}
@@ -0,0 +1,173 @@
+package evm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// BlobTxType is the type of EIP-4844 blob txs, not supported by the go-ethereum version in use
+const BlobTxType = 3
+
+// Min fee bumps in percent for a tx to replace a pending tx with the same nonce (geth tx pools)
+const (
+	ReplacementBump     = 10
+	BlobReplacementBump = 100
+)
+
+// ErrBlobTxReplacement is returned when a pending blob tx would be replaced by a tx without blobs
+var ErrBlobTxReplacement = errors.New("blob tx can only be replaced by a blob tx")
+
+// RPCTransaction is a tx as returned by the RPC, for tx types that go-ethereum can't decode such as blob txs
+type RPCTransaction struct {
+	Type                 hexutil.Uint64  `json:"type"`
+	Hash                 common.Hash     `json:"hash"`
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Value                *hexutil.Big    `json:"value"`
+	Input                hexutil.Bytes   `json:"input"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	MaxFeePerBlobGas     *hexutil.Big    `json:"maxFeePerBlobGas"`
+	BlobVersionedHashes  []common.Hash   `json:"blobVersionedHashes"`
+	BlockNumber          *hexutil.Big    `json:"blockNumber"`
+}
+
+// IsBlobTx returns true for EIP-4844 blob txs
+func (tx *RPCTransaction) IsBlobTx() bool {
+	return tx.Type == BlobTxType
+}
+
+// RPCReceipt is a receipt including the blob gas fields of EIP-4844
+type RPCReceipt struct {
+	types.Receipt
+	BlobGasUsed  uint64
+	BlobGasPrice *big.Int
+}
+
+// UnmarshalJSON decodes the receipt and its blob gas fields
+func (receipt *RPCReceipt) UnmarshalJSON(data []byte) error {
+	if err := receipt.Receipt.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	blob := struct {
+		BlobGasUsed  hexutil.Uint64 `json:"blobGasUsed"`
+		BlobGasPrice *hexutil.Big   `json:"blobGasPrice"`
+	}{}
+	if err := json.Unmarshal(data, &blob); err != nil {
+		return err
+	}
+	receipt.BlobGasUsed = uint64(blob.BlobGasUsed)
+	receipt.BlobGasPrice = (*big.Int)(blob.BlobGasPrice)
+	return nil
+}
+
+// BlobFee returns the fee paid for blob gas, zero for txs without blobs
+func (receipt *RPCReceipt) BlobFee() xc.AmountBlockchain {
+	if receipt.BlobGasPrice == nil {
+		return xc.NewAmountBlockchainFromUint64(0)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.BlobGasUsed), receipt.BlobGasPrice)
+	return xc.AmountBlockchain(*fee)
+}
+
+// effectiveGasPrice returns the price paid per execution gas, computed from the fee caps if the RPC doesn't return it
+func (tx *RPCTransaction) effectiveGasPrice(receipt *RPCReceipt, baseFee *big.Int) *big.Int {
+	if receipt.EffectiveGasPrice != nil {
+		return receipt.EffectiveGasPrice
+	}
+	if tx.MaxFeePerGas == nil || tx.MaxPriorityFeePerGas == nil || baseFee == nil {
+		return bigOrZero(tx.GasPrice)
+	}
+	price := new(big.Int).Add(baseFee, tx.MaxPriorityFeePerGas.ToInt())
+	if price.Cmp(tx.MaxFeePerGas.ToInt()) > 0 {
+		return tx.MaxFeePerGas.ToInt()
+	}
+	return price
+}
+
+// parseRPCTxInfo fills result from a tx that go-ethereum can't decode, and its receipt
+// Fee includes the blob gas fee
+func parseRPCTxInfo(result xc.TxInfo, tx *RPCTransaction, receipt *RPCReceipt, baseFee *big.Int, nativeAsset xc.NativeAsset) xc.TxInfo {
+	gasUsed := new(big.Int).SetUint64(receipt.GasUsed)
+	gasPrice := tx.effectiveGasPrice(receipt, baseFee)
+	executionFee := xc.AmountBlockchain(*new(big.Int).Mul(gasUsed, gasPrice))
+	blobFee := receipt.BlobFee()
+	result.Fee = executionFee.Add(&blobFee)
+	result.GasUsed = receipt.GasUsed
+	result.GasPrice = xc.AmountBlockchain(*new(big.Int).Set(gasPrice))
+
+	result.From = xc.Address(tx.From.String())
+	if tx.To != nil {
+		result.To = xc.Address(tx.To.String())
+	}
+	amount := xc.NewAmountBlockchainFromUint64(0)
+	if tx.Value != nil {
+		amount = xc.AmountBlockchain(*tx.Value.ToInt())
+	}
+	result.Amount = amount
+	if len(tx.Input) > 0 && tx.To != nil {
+		result.ContractAddress = xc.ContractAddress(tx.To.String())
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		result.Status = xc.TxStatusFailure
+	}
+
+	// token transfers are taken from the logs, blob txs are typically calls to rollup contracts
+	info := (&Tx{}).parseReceipt(&receipt.Receipt, nativeAsset)
+	if len(info.Destinations) == 0 && amount.Sign() > 0 {
+		info = parsedTxInfo{
+			Sources:      []*xc.TxInfoEndpoint{{Address: result.From, NativeAsset: nativeAsset, Amount: amount}},
+			Destinations: []*xc.TxInfoEndpoint{{Address: result.To, NativeAsset: nativeAsset, Amount: amount}},
+		}
+	}
+	result.Sources = info.Sources
+	result.Destinations = info.Destinations
+	return applyTokenTransfer(result, info)
+}
+
+// CheckReplacement checks that replacement can replace the pending tx with the same nonce
+// Tx pools require a fee bump of ReplacementBump percent, and pending blob txs can only be
+// replaced by blob txs with a fee bump of BlobReplacementBump percent, which can't be built here
+func CheckReplacement(pending *RPCTransaction, replacement *types.Transaction) error {
+	if uint64(pending.Nonce) != replacement.Nonce() {
+		return fmt.Errorf("replacement nonce %d does not match nonce %d of pending tx %s", replacement.Nonce(), pending.Nonce, pending.Hash)
+	}
+	if pending.IsBlobTx() {
+		return fmt.Errorf("%w: %s", ErrBlobTxReplacement, pending.Hash)
+	}
+	feeCap, tipCap := pending.GasPrice, pending.GasPrice
+	if pending.MaxFeePerGas != nil && pending.MaxPriorityFeePerGas != nil {
+		feeCap, tipCap = pending.MaxFeePerGas, pending.MaxPriorityFeePerGas
+	}
+	if !isBumped(feeCap.ToInt(), replacement.GasFeeCap(), ReplacementBump) || !isBumped(tipCap.ToInt(), replacement.GasTipCap(), ReplacementBump) {
+		return fmt.Errorf("replacement fees must be at least %d%% higher than pending tx %s", ReplacementBump, pending.Hash)
+	}
+	return nil
+}
+
+func bigOrZero(value *hexutil.Big) *big.Int {
+	if value == nil {
+		return big.NewInt(0)
+	}
+	return value.ToInt()
+}
+
+// isBumped returns true if value is at least bump percent higher than previous
+func isBumped(previous *big.Int, value *big.Int, bump int64) bool {
+	if previous == nil {
+		return true
+	}
+	threshold := new(big.Int).Mul(previous, big.NewInt(100+bump))
+	threshold.Div(threshold, big.NewInt(100))
+	return value.Cmp(threshold) >= 0
+}
@@ -0,0 +1,183 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const blobTxJSON = `{"type":"0x3","hash":"0x3d2b4c0bde6a0e7a8d6bb4b86f8b5b8d7b2b1e4b0e6f1f3a5c0a8e2a9b4d5c6e","from":"0x17519be39a6b67a19468dfbdc1d795c38232c274","to":"0xa0a5c02f0371ccc142ad5ad170c291c86c3e6379","nonce":"0x5","value":"0x0","input":"0x1234","gas":"0x5208","gasPrice":"0x3b9aca0b","maxFeePerGas":"0x77359400","maxPriorityFeePerGas":"0x3b9aca00","maxFeePerBlobGas":"0x3b9aca00","blobVersionedHashes":["0x01b0761f87b081d5cf10757ccc89f12be355c70e2e29df288b65b30710dcbcd1"],"accessList":[],"chainId":"0x5","v":"0x0","r":"0x1","s":"0x1","blockHash":"0xd090a9e97e00aa135710a92c827def07e4c8ff2269fd69411c48402e0a6a2a89","blockNumber":"0x8914cc","transactionIndex":"0x1"}`
+
+const blobReceiptJSON = `{"blockHash":"0xd090a9e97e00aa135710a92c827def07e4c8ff2269fd69411c48402e0a6a2a89","blockNumber":"0x8914cc","contractAddress":null,"cumulativeGasUsed":"0xa410","effectiveGasPrice":"0x3b9aca0b","from":"0x17519be39a6b67a19468dfbdc1d795c38232c274","gasUsed":"0x5208","logs":[],"logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","status":"0x1","to":"0xa0a5c02f0371ccc142ad5ad170c291c86c3e6379","transactionHash":"0x3d2b4c0bde6a0e7a8d6bb4b86f8b5b8d7b2b1e4b0e6f1f3a5c0a8e2a9b4d5c6e","transactionIndex":"0x1","type":"0x3","blobGasUsed":"0x20000","blobGasPrice":"0x2"}`
+
+const legacyTxJSON = `{"blockHash":"0xd090a9e97e00aa135710a92c827def07e4c8ff2269fd69411c48402e0a6a2a89","blockNumber":"0x8914cc","from":"0x17519be39a6b67a19468dfbdc1d795c38232c274","gas":"0x5208","gasPrice":"0x3e22ba6cde5","hash":"0xbca068cf854af49fc6b28ff5405068d51d3cefb870e624d22d046005a22349d0","input":"0x","nonce":"0x1","to":"0xa0a5c02f0371ccc142ad5ad170c291c86c3e6379","transactionIndex":"0x0","value":"0x49da27372c2bdee6","type":"0x0","chainId":"0x5","v":"0x2d","r":"0xbc8fc6daecc690912d8e6b7ab4e47188b562a2d5094a04b23b116753a077099a","s":"0x5ae2837a3b5b6cdf88c0cd367a6faaa52f38d7f71bc4f871dba389ad18237015"}`
+
+const legacyReceiptJSON = `{"blockHash":"0xd090a9e97e00aa135710a92c827def07e4c8ff2269fd69411c48402e0a6a2a89","blockNumber":"0x8914cc","contractAddress":null,"cumulativeGasUsed":"0x5208","effectiveGasPrice":"0x3e22ba6cde5","from":"0x17519be39a6b67a19468dfbdc1d795c38232c274","gasUsed":"0x5208","logs":[],"logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","status":"0x1","to":"0xa0a5c02f0371ccc142ad5ad170c291c86c3e6379","transactionHash":"0xbca068cf854af49fc6b28ff5405068d51d3cefb870e624d22d046005a22349d0","transactionIndex":"0x0","type":"0x0"}`
+
+// block 0x8914cc with its txs (hashes or full txs)
+func blockJSON(transactions string) string {
+	return fmt.Sprintf(`{"baseFeePerGas":"0xb","difficulty":"0x0","extraData":"0x","gasLimit":"0x1c9c380","gasUsed":"0xa410","hash":"0xd090a9e97e00aa135710a92c827def07e4c8ff2269fd69411c48402e0a6a2a89","logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","miner":"0x0000000000000000000000000000000000000000","mixHash":"0xb460ee4e35822216ac484dfcb7641fef4b9afed393279b13ec4faeade6bbce99","nonce":"0x0000000000000000","number":"0x8914cc","parentHash":"0xc6c2e8a0f3395d584ad2bcff333736a9dd7245d9a223a4e4bc252e32b442c610","receiptsRoot":"0xcc3d1989ea341f5f696ad76b34b60971687ab688f573eecd5e50302d140021e5","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","stateRoot":"0x6401e2e073ac8a27bb99b42151333f172c8aef69c54d33805e463c3deafde36b","timestamp":"0x645d6cb0","transactionsRoot":"0x69f8755536692771ea8dcfc81dd31df84fe3829c59aa6ae8b46d5de3ebf944aa","blobGasUsed":"0x20000","excessBlobGas":"0x0","transactions":%s}`, transactions)
+}
+
+func (s *CrosschainTestSuite) TestRPCReceiptUnmarshal() {
+	require := s.Require()
+	receipt := &RPCReceipt{}
+	require.NoError(receipt.UnmarshalJSON([]byte(blobReceiptJSON)))
+	require.EqualValues(BlobTxType, receipt.Type)
+	require.EqualValues(21000, receipt.GasUsed)
+	require.EqualValues(131072, receipt.BlobGasUsed)
+	require.Equal("262144", receipt.BlobFee().String())
+
+	receipt = &RPCReceipt{}
+	require.NoError(receipt.UnmarshalJSON([]byte(legacyReceiptJSON)))
+	require.EqualValues(0, receipt.BlobGasUsed)
+	require.Equal("0", receipt.BlobFee().String())
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfoBlobTx() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_getTransactionByHash, not supported by go-ethereum
+		blobTxJSON,
+		// eth_getTransactionByHash
+		blobTxJSON,
+		// eth_getTransactionReceipt
+		blobReceiptJSON,
+		// eth_getBlockByNumber
+		blockJSON(`[]`),
+		// eth_blockNumber
+		`"0x8914ec"`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, Net: "testnet", URL: server.URL, ChainID: 5})
+
+	txInfo, err := client.FetchTxInfo(s.Ctx, xc.TxHash("0x3d2b4c0bde6a0e7a8d6bb4b86f8b5b8d7b2b1e4b0e6f1f3a5c0a8e2a9b4d5c6e"))
+	require.NoError(err)
+	require.Equal("0", txInfo.Amount.String())
+	txInfo.Amount = xc.AmountBlockchain{}
+	require.Equal(xc.TxInfo{
+		BlockHash:       "0xd090a9e97e00aa135710a92c827def07e4c8ff2269fd69411c48402e0a6a2a89",
+		TxID:            "3d2b4c0bde6a0e7a8d6bb4b86f8b5b8d7b2b1e4b0e6f1f3a5c0a8e2a9b4d5c6e",
+		ExplorerURL:     "/tx/0x3d2b4c0bde6a0e7a8d6bb4b86f8b5b8d7b2b1e4b0e6f1f3a5c0a8e2a9b4d5c6e",
+		From:            "0x17519Be39A6B67a19468dfbDc1D795c38232c274",
+		To:              "0xa0a5C02F0371cCc142ad5AD170C291c86c3E6379",
+		ContractAddress: "0xa0a5C02F0371cCc142ad5AD170C291c86c3E6379",
+		// 21000 gas * 1000000011 + 131072 blob gas * 2
+		Fee:           xc.NewAmountBlockchainFromStr("21000000493144"),
+		GasUsed:       21000,
+		GasPrice:      xc.NewAmountBlockchainFromUint64(1000000011),
+		BlockIndex:    8983756,
+		BlockTime:     1683844272,
+		Confirmations: 32,
+		Sources:       []*xc.TxInfoEndpoint{},
+		Destinations:  []*xc.TxInfoEndpoint{},
+	}, txInfo)
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfoBlobTxPending() {
+	require := s.Require()
+	pending := strings.Replace(blobTxJSON, `"blockNumber":"0x8914cc"`, `"blockNumber":null`, 1)
+	server, close := test.MockJSONRPC(&s.Suite, []string{pending, pending})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL, ChainID: 5})
+
+	txInfo, err := client.FetchTxInfo(s.Ctx, xc.TxHash("0x3d2b4c0bde6a0e7a8d6bb4b86f8b5b8d7b2b1e4b0e6f1f3a5c0a8e2a9b4d5c6e"))
+	require.NoError(err)
+	require.EqualValues(0, txInfo.BlockIndex)
+	require.Equal(2, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestFetchBlockTxInfos() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_blockNumber
+		`"0x8914cc"`,
+		// eth_getBlockByNumber
+		blockJSON(`[` + legacyTxJSON + `,` + blobTxJSON + `]`),
+		// eth_blockNumber
+		`"0x8914ec"`,
+		// eth_getTransactionReceipt
+		legacyReceiptJSON,
+		blobReceiptJSON,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, Net: "testnet", URL: server.URL, ChainID: 5})
+
+	latest, err := client.FetchLatestBlock(s.Ctx)
+	require.NoError(err)
+	require.EqualValues(0x8914cc, latest)
+
+	txInfos, err := client.FetchBlockTxInfos(s.Ctx, 0x8914cc)
+	require.NoError(err)
+	require.Len(txInfos, 2)
+
+	require.Equal("bca068cf854af49fc6b28ff5405068d51d3cefb870e624d22d046005a22349d0", txInfos[0].TxID)
+	require.EqualValues("0x17519Be39A6B67a19468dfbDc1D795c38232c274", txInfos[0].From)
+	require.Equal("5321609027609419494", txInfos[0].Amount.String())
+	require.Equal("89668526728137000", txInfos[0].Fee.String())
+	require.Len(txInfos[0].Destinations, 1)
+	require.EqualValues(32, txInfos[0].Confirmations)
+	require.EqualValues(1683844272, txInfos[0].BlockTime)
+
+	require.Equal("3d2b4c0bde6a0e7a8d6bb4b86f8b5b8d7b2b1e4b0e6f1f3a5c0a8e2a9b4d5c6e", txInfos[1].TxID)
+	require.EqualValues("0x17519Be39A6B67a19468dfbDc1D795c38232c274", txInfos[1].From)
+	require.Equal("21000000493144", txInfos[1].Fee.String())
+	require.EqualValues(8983756, txInfos[1].BlockIndex)
+}
+
+func (s *CrosschainTestSuite) TestCheckReplacement() {
+	require := s.Require()
+	to := common.HexToAddress("0xa0a5c02f0371ccc142ad5ad170c291c86c3e6379")
+	pending := &RPCTransaction{
+		Type:                 types.DynamicFeeTxType,
+		Nonce:                5,
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(100)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(10)),
+	}
+	replacement := func(nonce uint64, feeCap int64, tipCap int64) *types.Transaction {
+		return types.NewTx(&types.DynamicFeeTx{Nonce: nonce, To: &to, GasFeeCap: big.NewInt(feeCap), GasTipCap: big.NewInt(tipCap)})
+	}
+	require.NoError(CheckReplacement(pending, replacement(5, 110, 11)))
+	require.ErrorContains(CheckReplacement(pending, replacement(5, 109, 11)), "at least 10% higher")
+	require.ErrorContains(CheckReplacement(pending, replacement(5, 200, 10)), "at least 10% higher")
+	require.ErrorContains(CheckReplacement(pending, replacement(6, 200, 20)), "replacement nonce 6 does not match nonce 5")
+
+	// legacy
+	pending = &RPCTransaction{Nonce: 5, GasPrice: (*hexutil.Big)(big.NewInt(100))}
+	require.NoError(CheckReplacement(pending, types.NewTx(&types.LegacyTx{Nonce: 5, To: &to, GasPrice: big.NewInt(110)})))
+	require.Error(CheckReplacement(pending, types.NewTx(&types.LegacyTx{Nonce: 5, To: &to, GasPrice: big.NewInt(105)})))
+
+	// blob txs can't be replaced by txs without blobs, whatever the fees
+	pending = &RPCTransaction{
+		Type:                 BlobTxType,
+		Nonce:                5,
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(100)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(10)),
+	}
+	err := CheckReplacement(pending, replacement(5, 1000, 100))
+	require.True(errors.Is(err, ErrBlobTxReplacement))
+}
+
+func (s *CrosschainTestSuite) TestClientCheckReplacement() {
+	require := s.Require()
+	pending := strings.Replace(blobTxJSON, `"blockNumber":"0x8914cc"`, `"blockNumber":null`, 1)
+	server, close := test.MockJSONRPC(&s.Suite, pending)
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL, ChainID: 5})
+
+	to := common.HexToAddress("0xa0a5c02f0371ccc142ad5ad170c291c86c3e6379")
+	replacement := &Tx{EthTx: types.NewTx(&types.DynamicFeeTx{Nonce: 5, To: &to, GasFeeCap: big.NewInt(1e12), GasTipCap: big.NewInt(1e11)})}
+	err := client.CheckReplacement(s.Ctx, "0x3d2b4c0bde6a0e7a8d6bb4b86f8b5b8d7b2b1e4b0e6f1f3a5c0a8e2a9b4d5c6e", replacement)
+	require.ErrorIs(err, ErrBlobTxReplacement)
+
+	server.Response = blobTxJSON
+	err = client.CheckReplacement(s.Ctx, "0x3d2b4c0bde6a0e7a8d6bb4b86f8b5b8d7b2b1e4b0e6f1f3a5c0a8e2a9b4d5c6e", replacement)
+	require.ErrorContains(err, "already confirmed")
+}
@@ -1,9 +1,10 @@
 package evm
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	xc "github.com/jumpcrypto/crosschain"
 	"golang.org/x/crypto/sha3"
@@ -25,7 +26,8 @@ func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
 	}, nil
 }
 
-// NewTxBuilder creates a new EVM TxBuilder for legacy tx
+// NewLegacyTxBuilder creates a new EVM TxBuilder for legacy tx, for chains configured with driver evm-legacy
+// Chains configured with driver evm build legacy txs as well when FetchTxInput finds no base fee
 func NewLegacyTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
 	return TxBuilder{
 		Asset:  asset,
@@ -35,6 +37,9 @@ func NewLegacyTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
 
 // NewTransfer creates a new transfer for an Asset, either native or token
 func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
 	if _, ok := txBuilder.Asset.(*xc.TaskConfig); ok {
 		return txBuilder.NewTask(from, to, amount, input)
 	}
@@ -42,6 +47,10 @@ func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc
 	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
 		return txBuilder.NewTokenTransfer(from, to, amount, input)
 	}
+	// tokens configured without a chain, e.g. loaded from a db
+	if asset := txBuilder.Asset.GetAssetConfig(); asset.Type == xc.AssetTypeToken && asset.Contract != "" {
+		return txBuilder.NewTokenTransfer(from, to, amount, input)
+	}
 
 	return txBuilder.NewNativeTransfer(from, to, amount, input)
 }
@@ -55,8 +64,11 @@ func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amo
 	if asset.NativeAsset == xc.ArbETH {
 		txInput.GasLimit = 4_000_000
 	}
+	if asset.IsRollup(xc.RollupZkSync) {
+		txInput.GasLimit = zkSyncGasLimit
+	}
 
-	return txBuilder.buildEvmTxWithPayload(to, amount, []byte{}, txInput)
+	return txBuilder.buildTransferTx(from, to, amount, []byte{}, txInput)
 }
 
 // NewTokenTransfer creates a new transfer for a token asset
@@ -71,37 +83,106 @@ func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amou
 	if asset.NativeAsset == xc.ArbETH {
 		txInput.GasLimit = 4_000_000
 	}
+	if asset.IsRollup(xc.RollupZkSync) {
+		txInput.GasLimit = zkSyncGasLimit
+	}
 
-	zero := xc.NewAmountBlockchainFromUint64(0)
 	contract := xc.Address(asset.Contract)
+	if token, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok && contract == "" {
+		contract = xc.Address(token.Contract)
+	}
+	if contract == "" {
+		return nil, fmt.Errorf("token %s has no contract", asset.Asset)
+	}
+	zero := xc.NewAmountBlockchainFromUint64(0)
 	payload, err := txBuilder.buildERC20Payload(to, amount)
 	if err != nil {
 		return nil, err
 	}
-	return txBuilder.buildEvmTxWithPayload(contract, zero, payload, txInput)
+	return txBuilder.buildTransferTx(from, contract, zero, payload, txInput)
 }
 
-func (txBuilder TxBuilder) buildERC20Payload(to xc.Address, amount xc.AmountBlockchain) ([]byte, error) {
-	transferFnSignature := []byte("transfer(address,uint256)")
+// buildTransferTx builds a transfer, as an EIP-712 tx on zkSync chains with a paymaster paying its fees
+func (txBuilder TxBuilder) buildTransferTx(from xc.Address, to xc.Address, value xc.AmountBlockchain, data []byte, input *TxInput) (xc.Tx, error) {
+	if rollup := txBuilder.Asset.GetAssetConfig().GetRollup(); rollup.Stack == xc.RollupZkSync && rollup.Paymaster != "" {
+		return txBuilder.buildZkSyncTx(from, to, value, data, input, rollup)
+	}
+	return txBuilder.buildEvmTxWithPayload(to, value, data, input)
+}
+
+// ReplaceTx rebuilds pending, an unconfirmed tx, with the same nonce, recipient, value and payload to replace it, e.g. when it's stuck
+// Its fees are those of input if higher, else those of pending bumped by ReplacementBump percent as required by tx pools
+// input may be nil to only bump the fees of pending
+func (txBuilder TxBuilder) ReplaceTx(pending xc.Tx, input xc.TxInput) (xc.Tx, error) {
+	pendingTx, ok := pending.(*Tx)
+	if !ok || pendingTx.EthTx == nil {
+		return nil, errors.New("pending tx is not an EVM tx")
+	}
+	ethTx := pendingTx.EthTx
+	if ethTx.To() == nil {
+		return nil, fmt.Errorf("pending tx %s deploys a contract", pendingTx.Hash())
+	}
+	replacement := &TxInput{
+		TxInputEnvelope: NewTxInput().TxInputEnvelope,
+		Nonce:           ethTx.Nonce(),
+		GasLimit:        ethTx.Gas(),
+		GasTipCap:       xc.AmountBlockchain(*bumpedFee(ethTx.GasTipCap())),
+		GasFeeCap:       xc.AmountBlockchain(*bumpedFee(ethTx.GasFeeCap())),
+		GasPrice:        xc.AmountBlockchain(*bumpedFee(ethTx.GasPrice())),
+	}
+	if input != nil {
+		txInput, ok := input.(*TxInput)
+		if !ok {
+			return nil, errors.New("xc.TxInput is not from an evm chain")
+		}
+		if txInput.GasTipCap.Cmp(&replacement.GasTipCap) > 0 {
+			replacement.GasTipCap = txInput.GasTipCap
+		}
+		if txInput.GasFeeCap.Cmp(&replacement.GasFeeCap) > 0 {
+			replacement.GasFeeCap = txInput.GasFeeCap
+		}
+		if txInput.GasPrice.Cmp(&replacement.GasPrice) > 0 {
+			replacement.GasPrice = txInput.GasPrice
+		}
+	}
+	// the tx type is kept, so legacy chains get a legacy tx
+	builder := txBuilder
+	builder.Legacy = ethTx.Type() == types.LegacyTxType
+	value := xc.AmountBlockchain(*ethTx.Value())
+	return builder.buildEvmTxWithPayload(xc.Address(ethTx.To().Hex()), value, ethTx.Data(), replacement)
+}
+
+// bumpedFee returns fee increased by ReplacementBump percent, rounded up
+func bumpedFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+ReplacementBump))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// methodID returns the 4-byte selector of a function signature
+func methodID(signature string) []byte {
 	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
-	// fmt.Println(hexutil.Encode(methodID)) // 0xa9059cbb
+	hash.Write([]byte(signature))
+	return hash.Sum(nil)[:4]
+}
+
+// method selectors are computed once rather than on each tx
+var erc20TransferMethodID = methodID("transfer(address,uint256)") // 0xa9059cbb
 
+func (txBuilder TxBuilder) buildERC20Payload(to xc.Address, amount xc.AmountBlockchain) ([]byte, error) {
 	toAddress, err := HexToAddress(to)
 	if err != nil {
 		return nil, err
 	}
-	paddedAddress := common.LeftPadBytes(toAddress.Bytes(), 32)
-	// fmt.Println(hexutil.Encode(paddedAddress)) // 0x0000000000000000000000004592d8f8d7b001e72cb26a73e4fa1806a51ac79d
 
-	paddedAmount := common.LeftPadBytes(amount.Int().Bytes(), 32)
-	// fmt.Println(hexutil.Encode(paddedAmount)) // 0x00000000000000000000000000000000000000000000003635c9adc5dea00000
+	if amount.Int().BitLen() > 256 {
+		return nil, fmt.Errorf("amount %s overflows uint256", amount.String())
+	}
 
-	var data []byte
-	data = append(data, methodID...)
-	data = append(data, paddedAddress...)
-	data = append(data, paddedAmount...)
+	data := make([]byte, 4+32+32)
+	copy(data, erc20TransferMethodID)
+	copy(data[4+12:], toAddress.Bytes())
+	amount.Int().FillBytes(data[4+32:])
 
 	return data, nil
 }
@@ -114,7 +195,7 @@ func (txBuilder TxBuilder) buildEvmTxWithPayload(to xc.Address, value xc.AmountB
 	chainID := new(big.Int).SetInt64(txBuilder.Asset.GetNativeAsset().ChainID)
 	// fmt.Println("chainID", chainID)
 
-	if txBuilder.Legacy {
+	if txBuilder.Legacy || input.Legacy {
 		return &Tx{
 			EthTx: types.NewTransaction(
 				input.Nonce,
@@ -128,11 +209,16 @@ func (txBuilder TxBuilder) buildEvmTxWithPayload(to xc.Address, value xc.AmountB
 		}, nil
 	}
 
+	// nodes reject a max priority fee per gas above the max fee per gas
+	gasTipCap := input.GasTipCap.Int()
+	if gasTipCap.Cmp(input.GasFeeCap.Int()) > 0 {
+		gasTipCap = input.GasFeeCap.Int()
+	}
 	return &Tx{
 		EthTx: types.NewTx(&types.DynamicFeeTx{
 			ChainID:   chainID,
 			Nonce:     input.Nonce,
-			GasTipCap: input.GasTipCap.Int(),
+			GasTipCap: gasTipCap,
 			GasFeeCap: input.GasFeeCap.Int(),
 			Gas:       input.GasLimit,
 			To:        &address,
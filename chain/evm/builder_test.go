@@ -1,6 +1,12 @@
 package evm
 
 import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	xc "github.com/jumpcrypto/crosschain"
 )
 
@@ -19,6 +25,174 @@ func (s *CrosschainTestSuite) TestNewTxBuilder() {
 	require.Equal("USDC", builder.(TxBuilder).Asset.GetAssetConfig().Asset)
 }
 
+func (s *CrosschainTestSuite) TestNewTransferToken() {
+	require := s.Require()
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	to := xc.Address("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+	contract := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	amount := xc.NewAmountBlockchainFromUint64(1_500_000)
+
+	// token configured without a chain
+	builder, _ := NewTxBuilder(&xc.AssetConfig{Asset: "USDC", NativeAsset: xc.ETH, ChainID: 1, Type: xc.AssetTypeToken, Contract: contract, Decimals: 6})
+	tx, err := builder.NewTransfer(from, to, amount, NewTxInput())
+	require.NoError(err)
+	ethTx := tx.(*Tx).EthTx
+	require.Equal(contract, ethTx.To().String())
+	require.Equal("0", ethTx.Value().String())
+	require.Equal("a9059cbb00000000000000000000000024b3a3f3b8e2d2ec7e44a1c8fbba0c8d2e7ba0bd000000000000000000000000000000000000000000000000000000000016e360", hex.EncodeToString(ethTx.Data()))
+	require.Equal(to, tx.(*Tx).To())
+	require.Equal(amount, tx.(*Tx).Amount())
+	require.Equal(xc.ContractAddress(contract), tx.(*Tx).ContractAddress())
+
+	// the contract of a token config is set on the token only
+	native := &xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, ChainID: 1}
+	builder, _ = NewTxBuilder(&xc.TokenAssetConfig{Asset: "USDC", Chain: "ETH", Contract: contract, Decimals: 6, AssetConfig: *native, NativeAssetConfig: native})
+	tx, err = builder.NewTransfer(from, to, amount, NewTxInput())
+	require.NoError(err)
+	require.Equal(contract, tx.(*Tx).EthTx.To().String())
+	require.Equal(xc.ContractAddress(contract), tx.(*Tx).ContractAddress())
+
+	// native asset
+	builder, _ = NewTxBuilder(&xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, ChainID: 1, Type: xc.AssetTypeNative})
+	tx, err = builder.NewTransfer(from, to, amount, NewTxInput())
+	require.NoError(err)
+	require.Equal(string(to), tx.(*Tx).EthTx.To().String())
+	require.Empty(tx.(*Tx).EthTx.Data())
+
+	_, err = builder.(TxBuilder).NewTokenTransfer(from, to, amount, NewTxInput())
+	require.EqualError(err, "token ETH has no contract")
+}
+
+func (s *CrosschainTestSuite) TestNewTransferPaused() {
+	require := s.Require()
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	to := xc.Address("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+	amount := xc.NewAmountBlockchainFromUint64(1_500_000)
+
+	builder, _ := NewTxBuilder(&xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, ChainID: 1, PausedWithReason: "incident"})
+	_, err := builder.NewTransfer(from, to, amount, NewTxInput())
+	require.EqualError(err, "asset is paused: ETH: incident")
+
+	// token of a receive-only chain
+	eth := &xc.NativeAssetConfig{Asset: "ETH", NativeAsset: xc.ETH, ChainID: 1, ReceiveOnly: true}
+	usdc := &xc.TokenAssetConfig{Asset: "USDC", Chain: "ETH", NativeAssetConfig: eth}
+	usdc.AssetConfig = xc.AssetConfig{Asset: "USDC", NativeAsset: xc.ETH, ChainID: 1, Type: xc.AssetTypeToken, Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6}
+	builder, _ = NewTxBuilder(usdc)
+	_, err = builder.NewTransfer(from, to, amount, NewTxInput())
+	require.ErrorIs(err, xc.ErrAssetReceiveOnly)
+
+	eth.ReceiveOnly = false
+	_, err = builder.NewTransfer(from, to, amount, NewTxInput())
+	require.NoError(err)
+}
+
+func (s *CrosschainTestSuite) TestNewTransferDynamicFees() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	from := xc.Address(crypto.PubkeyToAddress(key.PublicKey).Hex())
+	to := xc.Address("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+	amount := xc.NewAmountBlockchainFromUint64(1_500_000)
+	builder, _ := NewTxBuilder(&xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, ChainID: 1, Type: xc.AssetTypeNative})
+
+	vectors := []struct {
+		legacy    bool
+		txType    uint8
+		gasTipCap string
+		gasFeeCap string
+	}{
+		// the priority fee is capped to the max fee
+		{false, types.DynamicFeeTxType, "20000", "20000"},
+		// chains without dynamic fees
+		{true, types.LegacyTxType, "30000", "30000"},
+	}
+	for _, v := range vectors {
+		input := NewTxInput()
+		input.Nonce = 1
+		input.GasTipCap = xc.NewAmountBlockchainFromUint64(25_000)
+		input.GasFeeCap = xc.NewAmountBlockchainFromUint64(20_000)
+		input.GasPrice = xc.NewAmountBlockchainFromUint64(30_000)
+		input.Legacy = v.legacy
+		tx, err := builder.NewTransfer(from, to, amount, input)
+		require.NoError(err)
+		ethTx := tx.(*Tx).EthTx
+		require.Equal(v.txType, ethTx.Type())
+		require.Equal(v.gasTipCap, ethTx.GasTipCap().String())
+		require.Equal(v.gasFeeCap, ethTx.GasFeeCap().String())
+
+		// signed and serialized in the format of its type
+		sighashes, err := tx.Sighashes()
+		require.NoError(err)
+		signature, err := crypto.Sign(sighashes[0], key)
+		require.NoError(err)
+		require.NoError(tx.AddSignatures(signature))
+		serialized, err := tx.Serialize()
+		require.NoError(err)
+		decoded := &types.Transaction{}
+		require.NoError(decoded.UnmarshalBinary(serialized))
+		require.Equal(v.txType, decoded.Type())
+		require.Equal(tx.Hash(), xc.TxHash(decoded.Hash().Hex()))
+		sender, err := types.Sender(types.LatestSignerForChainID(decoded.ChainId()), decoded)
+		require.NoError(err)
+		require.Equal(string(from), sender.Hex())
+	}
+}
+
+func (s *CrosschainTestSuite) TestReplaceTx() {
+	require := s.Require()
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	to := xc.Address("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+	contract := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	amount := xc.NewAmountBlockchainFromUint64(1_500_000)
+	builder, _ := NewTxBuilder(&xc.AssetConfig{Asset: "USDC", NativeAsset: xc.ETH, ChainID: 1, Type: xc.AssetTypeToken, Contract: contract, Decimals: 6})
+	input := NewTxInput()
+	input.Nonce = 7
+	input.GasTipCap = xc.NewAmountBlockchainFromUint64(1_000)
+	input.GasFeeCap = xc.NewAmountBlockchainFromUint64(20_001)
+	pending, err := builder.NewTransfer(from, to, amount, input)
+	require.NoError(err)
+
+	// fees bumped by 10%, rounded up
+	tx, err := builder.(TxBuilder).ReplaceTx(pending, nil)
+	require.NoError(err)
+	ethTx := tx.(*Tx).EthTx
+	pendingTx := pending.(*Tx).EthTx
+	require.EqualValues(7, ethTx.Nonce())
+	require.Equal(pendingTx.Gas(), ethTx.Gas())
+	require.Equal(pendingTx.To(), ethTx.To())
+	require.Equal(pendingTx.Data(), ethTx.Data())
+	require.Equal("1100", ethTx.GasTipCap().String())
+	require.Equal("22002", ethTx.GasFeeCap().String())
+	require.NoError(CheckReplacement(&RPCTransaction{
+		Nonce:                7,
+		MaxFeePerGas:         (*hexutil.Big)(pendingTx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(pendingTx.GasTipCap()),
+	}, ethTx))
+
+	// higher fees of a new input
+	input = NewTxInput()
+	input.GasTipCap = xc.NewAmountBlockchainFromUint64(2_000)
+	input.GasFeeCap = xc.NewAmountBlockchainFromUint64(21_000)
+	tx, err = builder.(TxBuilder).ReplaceTx(pending, input)
+	require.NoError(err)
+	require.Equal("2000", tx.(*Tx).EthTx.GasTipCap().String())
+	require.Equal("22002", tx.(*Tx).EthTx.GasFeeCap().String())
+
+	// legacy txs stay legacy
+	legacyBuilder, _ := NewLegacyTxBuilder(&xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, ChainID: 1})
+	input = NewTxInput()
+	input.Nonce = 3
+	input.GasPrice = xc.NewAmountBlockchainFromUint64(100)
+	pending, _ = legacyBuilder.NewTransfer(from, to, amount, input)
+	tx, err = builder.(TxBuilder).ReplaceTx(pending, nil)
+	require.NoError(err)
+	require.EqualValues(types.LegacyTxType, tx.(*Tx).EthTx.Type())
+	require.Equal("110", tx.(*Tx).EthTx.GasPrice().String())
+	require.Equal(amount.Int(), tx.(*Tx).EthTx.Value())
+
+	_, err = builder.(TxBuilder).ReplaceTx(&Tx{}, nil)
+	require.EqualError(err, "pending tx is not an EVM tx")
+}
+
 // func (s *CrosschainTestSuite) TestNewNativeTransfer() {
 // 	require := s.Require()
 // 	builder, _ := NewTxBuilder(&xc.AssetConfig{})
@@ -195,3 +369,82 @@ func (s *CrosschainTestSuite) TestNewTxBuilder() {
 // 	require.Equal(1, len(solTx.Message.Instructions))
 // 	require.Equal(uint16(0x4), solTx.Message.Instructions[0].ProgramIDIndex) // token tx
 // }
+
+func newBenchmarkTransfer() (TxBuilder, xc.Address, xc.Address, xc.AmountBlockchain) {
+	builder := TxBuilder{Asset: &xc.AssetConfig{
+		NativeAsset: xc.ETH,
+		ChainID:     1,
+		Contract:    "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		Type:        xc.AssetTypeToken,
+	}}
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	to := xc.Address("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	return builder, from, to, xc.NewAmountBlockchainFromUint64(1_000_000)
+}
+
+// allocation budgets of the hot paths, raise only with a reason
+const (
+	nativeTransferAllocBudget = 35
+	tokenTransferAllocBudget  = 40
+	erc20PayloadAllocBudget   = 2
+)
+
+func (s *CrosschainTestSuite) TestAllocationBudget() {
+	require := s.Require()
+	builder, from, to, amount := newBenchmarkTransfer()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		tx, _ := builder.NewNativeTransfer(from, to, amount, NewTxInput())
+		_, _ = tx.Serialize()
+	})
+	require.LessOrEqual(allocs, float64(nativeTransferAllocBudget))
+
+	allocs = testing.AllocsPerRun(100, func() {
+		tx, _ := builder.NewTokenTransfer(from, to, amount, NewTxInput())
+		_, _ = tx.Serialize()
+	})
+	require.LessOrEqual(allocs, float64(tokenTransferAllocBudget))
+
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _ = builder.buildERC20Payload(to, amount)
+	})
+	require.LessOrEqual(allocs, float64(erc20PayloadAllocBudget))
+}
+
+func BenchmarkNewNativeTransfer(b *testing.B) {
+	builder, from, to, amount := newBenchmarkTransfer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tx, err := builder.NewNativeTransfer(from, to, amount, NewTxInput())
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := tx.Serialize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewTokenTransfer(b *testing.B) {
+	builder, from, to, amount := newBenchmarkTransfer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tx, err := builder.NewTokenTransfer(from, to, amount, NewTxInput())
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := tx.Serialize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildERC20Payload(b *testing.B) {
+	builder, _, to, amount := newBenchmarkTransfer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := builder.buildERC20Payload(to, amount); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// txpoolStatus is the result of txpool_status, not exposed by all providers
+type txpoolStatus struct {
+	Pending hexutil.Uint64 `json:"pending"`
+	Queued  hexutil.Uint64 `json:"queued"`
+}
+
+// FetchChainStats returns the latest block, the average block time, the base fee and the gas price estimate,
+// and the size of the mempool if the provider exposes txpool_status
+func (client *Client) FetchChainStats(ctx context.Context) (*xc.ChainStats, error) {
+	latest, err := client.EthClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch latest block: %v", err)
+	}
+	stats := &xc.ChainStats{
+		Chain:  client.Asset.GetNativeAsset().NativeAsset,
+		Height: latest.Number.Uint64(),
+	}
+	if latest.BaseFee != nil {
+		stats.BaseFee = xc.AmountBlockchain(*latest.BaseFee)
+	}
+
+	stats.AverageBlockTime, err = client.fetchAverageBlockTime(ctx, latest)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.FeeRate, err = client.EstimateGas(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not estimate gas price: %v", err)
+	}
+
+	var status txpoolStatus
+	if err := client.RpcClient.CallContext(ctx, &status, "txpool_status"); err == nil {
+		size := uint64(status.Pending + status.Queued)
+		stats.MempoolSize = &size
+	}
+	return stats, nil
+}
+
+// FetchChainHealth returns whether the chain is halted, from the age of the latest block,
+// or in maintenance at the maintenance_url of the asset
+func (client *Client) FetchChainHealth(ctx context.Context) (*xc.ChainHealth, error) {
+	latest, err := client.EthClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch latest block: %v", err)
+	}
+	height := latest.Number.Uint64()
+	latestTime := time.Unix(int64(latest.Time), 0)
+	var average time.Duration
+	if client.Asset.GetNativeAsset().BlockTime <= 0 {
+		if average, err = client.fetchAverageBlockTime(ctx, latest); err != nil {
+			return nil, err
+		}
+	}
+	return xc.CheckChainHealth(ctx, client.Asset.GetNativeAsset(), height, latestTime, average, now())
+}
+
+// fetchAverageBlockTime returns the average block time over the ChainStatsBlocks blocks up to latest
+func (client *Client) fetchAverageBlockTime(ctx context.Context, latest *types.Header) (time.Duration, error) {
+	height := latest.Number.Uint64()
+	if height == 0 {
+		return 0, nil
+	}
+	fromHeight := uint64(0)
+	if height > xc.ChainStatsBlocks {
+		fromHeight = height - xc.ChainStatsBlocks
+	}
+	from, err := client.EthClient.HeaderByNumber(ctx, new(big.Int).SetUint64(fromHeight))
+	if err != nil {
+		return 0, fmt.Errorf("could not fetch block %d: %v", fromHeight, err)
+	}
+	return xc.AverageBlockTime(fromHeight, time.Unix(int64(from.Time), 0), height, time.Unix(int64(latest.Time), 0)), nil
+}
@@ -0,0 +1,98 @@
+package evm
+
+import (
+	"strings"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+// blockAtJSON is blockJSON at another height and time
+func blockAtJSON(number string, timestamp string) string {
+	block := strings.Replace(blockJSON(`[]`), `"number":"0x8914cc"`, `"number":"`+number+`"`, 1)
+	return strings.Replace(block, `"timestamp":"0x645d6cb0"`, `"timestamp":"`+timestamp+`"`, 1)
+}
+
+func (s *CrosschainTestSuite) TestFetchChainStats() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_getBlockByNumber latest
+		blockJSON(`[]`),
+		// eth_getBlockByNumber 20 blocks before, 240s before
+		blockAtJSON("0x8914b8", "0x645d6bc0"),
+		// eth_getBlockByNumber for the gas estimate
+		blockJSON(`[]`),
+		// txpool_status
+		`{"pending":"0x10","queued":"0x2"}`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	stats, err := client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.ETH, stats.Chain)
+	require.EqualValues(0x8914cc, stats.Height)
+	require.Equal(12*time.Second, stats.AverageBlockTime)
+	require.Equal("11", stats.BaseFee.String())
+	require.Equal("46000000000", stats.FeeRate.String())
+	require.NotNil(stats.MempoolSize)
+	require.EqualValues(18, *stats.MempoolSize)
+	require.Equal(4, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestFetchChainStatsNoMempool() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		blockJSON(`[]`),
+		blockAtJSON("0x8914b8", "0x645d6bc0"),
+		blockJSON(`[]`),
+		`{"jsonrpc":"2.0","error":{"code":-32601,"message":"the method txpool_status does not exist/is not available"},"id":0}`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	stats, err := client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.EqualValues(0x8914cc, stats.Height)
+	require.Nil(stats.MempoolSize)
+}
+
+func (s *CrosschainTestSuite) TestFetchChainStatsError() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"jsonrpc":"2.0","error":{"code":-32000,"message":"header not found"},"id":0}`)
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	_, err := client.FetchChainStats(s.Ctx)
+	require.ErrorContains(err, "could not fetch latest block")
+}
+
+func (s *CrosschainTestSuite) TestFetchChainHealth() {
+	require := s.Require()
+	// timestamp of blockJSON
+	blockTime := time.Unix(1683844272, 0)
+	defer func() { now = time.Now }()
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{blockJSON(`[]`), blockAtJSON("0x8914b8", "0x645d6bc0")})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+	now = func() time.Time { return blockTime.Add(time.Minute) }
+	health, err := client.FetchChainHealth(s.Ctx)
+	require.NoError(err)
+	require.True(health.Healthy())
+	require.EqualValues(0x8914cc, health.Height)
+	require.Equal(blockTime, health.LatestBlockTime)
+	require.Equal(2*time.Minute, health.HaltThreshold)
+
+	// configured block time
+	server, close = test.MockJSONRPC(&s.Suite, []string{blockJSON(`[]`)})
+	defer close()
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL, BlockTime: 12 * time.Second, HaltBlocks: 5})
+	now = func() time.Time { return blockTime.Add(5 * time.Minute) }
+	health, err = client.FetchChainHealth(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.ChainStatusHalted, health.Status)
+	require.Equal("latest block 8983756 is 5m0s old", health.Details)
+	require.Equal(1, server.Counter)
+}
@@ -3,6 +3,8 @@ package evm
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -10,7 +12,10 @@ import (
 	"math/big"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -41,9 +46,18 @@ type Client struct {
 	EthClient       *ethclient.Client
 	RpcClient       *rpc.Client
 	ChainId         *big.Int
+	chainIdMu       sync.Mutex
 	Interceptor     *HttpInterceptor
 	EstimateGasFunc xc.EstimateGasFunc
 	Legacy          bool
+	// ErrorRegistry decodes the custom errors of reverted simulations, only Error(string) and Panic(uint256) if nil
+	ErrorRegistry *ErrorRegistry
+	// dynamicFeesChecked is set once the latest block is fetched, and noDynamicFees if it has no base fee
+	dynamicFeesChecked bool
+	noDynamicFees      bool
+	noDynamicFeesMu    sync.Mutex
+	// SequencerClient submits txs to the sequencer of a rollup, if configured, see xc.RollupConfig
+	SequencerClient *rpc.Client
 }
 
 var _ xc.FullClientWithGas = &Client{}
@@ -58,32 +72,62 @@ type TxInput struct {
 	GasFeeCap xc.AmountBlockchain // maxFeePerGas
 	// LegacyTx
 	GasPrice xc.AmountBlockchain // wei per gas
+	// Legacy is set for chains without dynamic fee txs (EIP-1559), for which a LegacyTx is built
+	Legacy bool
+	// GasPerPubdata is the max gas per byte of pubdata of the EIP-712 txs of zkSync, DefaultGasPerPubdata if 0
+	GasPerPubdata uint64
 	// Task params
 	Params []string
 }
 
 func NewTxInput() *TxInput {
 	return &TxInput{
-		TxInputEnvelope: xc.TxInputEnvelope{
-			Type: xc.DriverEVM,
-		},
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverEVM),
 	}
 }
 
-// Interceptor
+var _ xc.TxInputNonce = &TxInput{}
+
+// GetNonce returns the nonce of the sender
+func (txInput *TxInput) GetNonce() uint64 {
+	return txInput.Nonce
+}
+
+var _ xc.TxInputFee = &TxInput{}
+
+// MaxFee returns the gas limit times the max fee per gas, or the gas price of legacy txs
+func (txInput *TxInput) MaxFee() xc.AmountBlockchain {
+	feePerGas := txInput.GasFeeCap
+	if feePerGas.Cmp(&txInput.GasPrice) < 0 {
+		feePerGas = txInput.GasPrice
+	}
+	gasLimit := xc.NewAmountBlockchainFromUint64(txInput.GasLimit)
+	return feePerGas.Mul(&gasLimit)
+}
+
+// Interceptor rewrites the responses that go-ethereum can't decode, e.g. of KLAY, CELO and XDC,
+// for all requests once enabled, or for the requests under a context returned by withInterception
 type HttpInterceptor struct {
 	core    http.RoundTripper
-	enabled bool
+	enabled int32
 }
 
 func (i *HttpInterceptor) Enable() {
-	i.enabled = true
+	atomic.StoreInt32(&i.enabled, 1)
 }
 func (i *HttpInterceptor) Disable() {
-	i.enabled = false
+	atomic.StoreInt32(&i.enabled, 0)
 }
 
-func (i HttpInterceptor) RoundTrip(req *http.Request) (*http.Response, error) {
+type interceptionKey struct{}
+
+// withInterception returns a context under which requests are intercepted, so that concurrent requests
+// of a shared client are not
+func withInterception(ctx context.Context) context.Context {
+	return context.WithValue(ctx, interceptionKey{}, true)
+}
+
+func (i *HttpInterceptor) RoundTrip(req *http.Request) (*http.Response, error) {
 	defer func() {
 		_ = req.Body.Close()
 	}()
@@ -92,7 +136,7 @@ func (i HttpInterceptor) RoundTrip(req *http.Request) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	if i.enabled {
+	if atomic.LoadInt32(&i.enabled) == 1 || req.Context().Value(interceptionKey{}) != nil {
 		defer func() {
 			_ = res.Body.Close()
 		}()
@@ -148,8 +192,13 @@ func NewClient(asset xc.ITask) (*Client, error) {
 	nativeAsset := asset.GetNativeAsset()
 	url := configToEVMClientURL(asset)
 
+	transport, err := nativeAsset.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+
 	// c, err := rpc.DialContext(context.Background(), url)
-	interceptor := &HttpInterceptor{http.DefaultTransport, false}
+	interceptor := &HttpInterceptor{core: transport}
 	httpClient := &http.Client{
 		Transport: interceptor,
 	}
@@ -158,6 +207,14 @@ func NewClient(asset xc.ITask) (*Client, error) {
 		return nil, fmt.Errorf(fmt.Sprintf("dialing url: %v", nativeAsset.URL))
 	}
 
+	var sequencer *rpc.Client
+	if sequencerURL := nativeAsset.GetRollup().SequencerURL; sequencerURL != "" {
+		sequencer, err = rpc.DialHTTPWithClient(sequencerURL, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("dialing sequencer url: %v", sequencerURL)
+		}
+	}
+
 	client := ethclient.NewClient(c)
 	return &Client{
 		Asset:           asset,
@@ -167,11 +224,14 @@ func NewClient(asset xc.ITask) (*Client, error) {
 		Interceptor:     interceptor,
 		EstimateGasFunc: nil,
 		Legacy:          false,
+		SequencerClient: sequencer,
 	}, nil
 }
 
-// ChainID returns the ChainID
+// ChainID returns the ChainID, fetched once
 func (client *Client) ChainID() (*big.Int, error) {
+	client.chainIdMu.Lock()
+	defer client.chainIdMu.Unlock()
 	var err error
 	if client.ChainId == nil {
 		client.ChainId, err = client.EthClient.ChainID(context.Background())
@@ -233,19 +293,46 @@ func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, _ xc.Ad
 	if !nativeAsset.NoGasFees {
 		gas, err := client.EstimateGas(ctx)
 		if err != nil {
-			// pass, return err later
+			return result, err
 		}
 		result.GasPrice = gas  // legacy
 		result.GasFeeCap = gas // new
 	} else {
 		result.GasTipCap = zero
 	}
+	// Polygon zkEVM prices legacy txs only
+	zkEVM := nativeAsset.IsRollup(xc.RollupPolygonZkEVM)
+	if !client.Legacy && !zkEVM {
+		// gas may not be estimated from the latest block, e.g. with an EstimateGasFunc, for KLAY or without gas fees
+		client.checkDynamicFees(ctx)
+	}
+	result.Legacy = client.Legacy || !client.dynamicFees() || zkEVM
 
-	return result, err
+	return result, nil
 }
 
 // SubmitTx submits a EVM tx
 func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		simulated, err := client.simulateTx(ctx, tx)
+		if err != nil {
+			return err
+		}
+		return xc.RecordDryRun(ctx, client.Asset, tx, simulated)
+	}
+	if client.SequencerClient != nil {
+		bz, err := tx.Serialize()
+		if err != nil {
+			return err
+		}
+		if err := client.SequencerClient.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(bz)); err != nil {
+			return fmt.Errorf("sending transaction '%v' to the sequencer: %v", tx.Hash(), err)
+		}
+		return nil
+	}
 	switch tx := tx.(type) {
 	case *Tx:
 		err := client.EthClient.SendTransaction(ctx, tx.EthTx)
@@ -262,6 +349,77 @@ func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
 	}
 }
 
+// simulateTx executes tx with eth_call, returning false for txs that can't be simulated
+func (client *Client) simulateTx(ctx context.Context, tx xc.Tx) (bool, error) {
+	result, err := client.SimulateTx(ctx, tx)
+	if errors.Is(err, xc.ErrSimulationNotSupported) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !result.Success {
+		return false, fmt.Errorf("simulating transaction '%v': %s", tx.Hash(), result.FailureReason)
+	}
+	return true, nil
+}
+
+// callMsg returns the call of ethTx by from, without gas limit
+func callMsg(from common.Address, ethTx *types.Transaction) ethereum.CallMsg {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    ethTx.To(),
+		Value: ethTx.Value(),
+		Data:  ethTx.Data(),
+	}
+	if ethTx.Type() == types.DynamicFeeTxType {
+		msg.GasFeeCap = ethTx.GasFeeCap()
+		msg.GasTipCap = ethTx.GasTipCap()
+	} else {
+		msg.GasPrice = ethTx.GasPrice()
+	}
+	return msg
+}
+
+var _ xc.FeeEstimator = &Client{}
+
+// EstimateFee estimates the max fee of tx sent by from: the gas estimated by eth_estimateGas times
+// the max fee per gas of tx, or its gas price if legacy, or the estimated gas price if tx has none
+// The L1 data fee of OP Stack chains is added, see EstimateL1Fee
+func (client *Client) EstimateFee(ctx context.Context, from xc.Address, tx xc.Tx) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	if zkSyncTx, ok := tx.(*ZkSyncTx); ok {
+		return client.estimateZkSyncFee(ctx, zkSyncTx)
+	}
+	evmTx, ok := tx.(*Tx)
+	if !ok || evmTx.EthTx == nil {
+		return zero, errors.New("tx is not an EVM tx")
+	}
+	sender, err := HexToAddress(from)
+	if err != nil {
+		return zero, fmt.Errorf("bad from address '%v': %v", from, err)
+	}
+	ethTx := evmTx.EthTx
+	gas, err := client.EthClient.EstimateGas(ctx, callMsg(sender, ethTx))
+	if err != nil {
+		return zero, fmt.Errorf("estimating gas of transaction '%v': %v", tx.Hash(), err)
+	}
+	gasPrice := xc.AmountBlockchain(*ethTx.GasFeeCap())
+	if gasPrice.Sign() == 0 {
+		gasPrice, err = client.EstimateGas(ctx)
+		if err != nil {
+			return zero, err
+		}
+	}
+	gasLimit := xc.NewAmountBlockchainFromUint64(gas)
+	fee := gasLimit.Mul(&gasPrice)
+	l1Fee, err := client.EstimateL1Fee(ctx, ethTx)
+	if err != nil {
+		return zero, err
+	}
+	return fee.Add(&l1Fee), nil
+}
+
 // FetchTxInfo returns tx info for a EVM tx
 func (client *Client) FetchTxInfo(ctx context.Context, txHashStr xc.TxHash) (xc.TxInfo, error) {
 	nativeAsset := client.Asset.GetNativeAsset()
@@ -274,11 +432,13 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHashStr xc.TxHash) (xc.
 	}
 
 	tx, pending, err := client.EthClient.TransactionByHash(ctx, txHash)
+	if errors.Is(err, types.ErrTxTypeNotSupported) {
+		// e.g. blob txs
+		return client.fetchRPCTxInfo(ctx, result, txHash)
+	}
 	if err != nil {
 		// TODO retry only for KLAY
-		client.Interceptor.Enable()
-		tx, pending, err = client.EthClient.TransactionByHash(ctx, txHash)
-		client.Interceptor.Disable()
+		tx, pending, err = client.EthClient.TransactionByHash(withInterception(ctx), txHash)
 		if err != nil {
 			return result, fmt.Errorf(fmt.Sprintf("fetching tx by hash '%s': %v", txHashStr, err))
 		}
@@ -298,9 +458,7 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHashStr xc.TxHash) (xc.
 	receipt, err := client.EthClient.TransactionReceipt(ctx, txHash)
 	if err != nil {
 		// TODO retry only for KLAY
-		client.Interceptor.Enable()
-		receipt, err = client.EthClient.TransactionReceipt(ctx, txHash)
-		client.Interceptor.Disable()
+		receipt, err = client.EthClient.TransactionReceipt(withInterception(ctx), txHash)
 		if err != nil {
 			return result, fmt.Errorf("fetching receipt for tx %v : %v", txHashStr, err)
 		}
@@ -311,20 +469,13 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHashStr xc.TxHash) (xc.
 		return result, nil
 	}
 
-	// reverted tx
 	result.BlockIndex = receipt.BlockNumber.Int64()
 	result.BlockHash = receipt.BlockHash.Hex()
-	gasUsed := receipt.GasUsed
-	if receipt.Status == 0 {
-		result.Status = xc.TxStatusFailure
-	}
 
 	// tx confirmed
 	currentHeader, err := client.EthClient.HeaderByNumber(ctx, receipt.BlockNumber)
 	if err != nil {
-		client.Interceptor.Enable()
-		currentHeader, err = client.EthClient.HeaderByNumber(ctx, receipt.BlockNumber)
-		client.Interceptor.Disable()
+		currentHeader, err = client.EthClient.HeaderByNumber(withInterception(ctx), receipt.BlockNumber)
 		if err != nil {
 			return result, fmt.Errorf("fetching current header: (%T) %v", err, err)
 		}
@@ -337,14 +488,36 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHashStr xc.TxHash) (xc.
 
 	latestHeader, err := client.EthClient.HeaderByNumber(ctx, nil)
 	if err != nil {
-		client.Interceptor.Enable()
-		latestHeader, err = client.EthClient.HeaderByNumber(ctx, nil)
-		client.Interceptor.Disable()
+		latestHeader, err = client.EthClient.HeaderByNumber(withInterception(ctx), nil)
 		if err != nil {
 			return result, fmt.Errorf("fetching latest header: %v", err)
 		}
 	}
 	result.Confirmations = latestHeader.Number.Int64() - receipt.BlockNumber.Int64()
+	if nativeAsset.IsZkRollup() {
+		result.Confirmations, err = client.finalizedConfirmations(ctx, receipt.BlockNumber, result.Confirmations)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result = parseTxInfo(result, tx, receipt, baseFee, chainID, nativeAsset.NativeAsset)
+	if client.chargesL1Fee() {
+		l1Fee, err := client.fetchL1Fee(ctx, txHash)
+		if err != nil {
+			return result, err
+		}
+		result.Fee = result.Fee.Add(&l1Fee)
+	}
+	return result, nil
+}
+
+// parseTxInfo fills result from a confirmed tx and its receipt
+func parseTxInfo(result xc.TxInfo, tx *types.Transaction, receipt *types.Receipt, baseFee uint64, chainID *big.Int, nativeAsset xc.NativeAsset) xc.TxInfo {
+	// reverted tx
+	if receipt.Status == 0 {
+		result.Status = xc.TxStatusFailure
+	}
 
 	// // tx confirmed
 	confirmedTx := Tx{
@@ -352,21 +525,174 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHashStr xc.TxHash) (xc.
 		Signer: types.LatestSignerForChainID(chainID),
 	}
 
-	info := confirmedTx.ParseTransfer(receipt, nativeAsset.NativeAsset)
+	info := confirmedTx.ParseTransfer(receipt, nativeAsset)
 
 	result.From = confirmedTx.From()
 	result.To = confirmedTx.To()
 	result.ContractAddress = confirmedTx.ContractAddress()
 	result.Amount = confirmedTx.Amount()
-	result.Fee = confirmedTx.Fee(baseFee, gasUsed)
+	// the price actually paid, reported by the receipt of recent nodes
+	gasPrice := confirmedTx.EffectiveGasPrice(baseFee)
+	if receipt.EffectiveGasPrice != nil {
+		gasPrice = xc.AmountBlockchain(*receipt.EffectiveGasPrice)
+	}
+	gasUsed := xc.NewAmountBlockchainFromUint64(receipt.GasUsed)
+	result.Fee = gasUsed.Mul(&gasPrice)
+	result.GasUsed = receipt.GasUsed
+	result.GasPrice = gasPrice
 	result.Sources = info.Sources
 	result.Destinations = info.Destinations
+	return applyTokenTransfer(result, info)
+}
 
-	return result, nil
+// fetchRPCTxInfo returns tx info for a tx type that go-ethereum can't decode, e.g. blob txs
+func (client *Client) fetchRPCTxInfo(ctx context.Context, result xc.TxInfo, txHash common.Hash) (xc.TxInfo, error) {
+	nativeAsset := client.Asset.GetNativeAsset()
+	var tx *RPCTransaction
+	if err := client.RpcClient.CallContext(ctx, &tx, "eth_getTransactionByHash", txHash); err != nil {
+		return result, fmt.Errorf("fetching tx by hash '%s': %v", txHash.Hex(), err)
+	}
+	if tx == nil {
+		return result, fmt.Errorf("fetching tx by hash '%s': %v", txHash.Hex(), ethereum.NotFound)
+	}
+	// pending
+	if tx.BlockNumber == nil {
+		return result, nil
+	}
+	var receipt *RPCReceipt
+	if err := client.RpcClient.CallContext(ctx, &receipt, "eth_getTransactionReceipt", txHash); err != nil {
+		return result, fmt.Errorf("fetching receipt for tx %v : %v", txHash.Hex(), err)
+	}
+	if receipt == nil {
+		return result, nil
+	}
+	result.BlockIndex = receipt.BlockNumber.Int64()
+	result.BlockHash = receipt.BlockHash.Hex()
+
+	header, err := client.EthClient.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return result, fmt.Errorf("fetching current header: (%T) %v", err, err)
+	}
+	result.BlockTime = int64(header.Time)
+	latest, err := client.EthClient.BlockNumber(ctx)
+	if err != nil {
+		return result, fmt.Errorf("fetching latest header: %v", err)
+	}
+	result.Confirmations = int64(latest) - receipt.BlockNumber.Int64()
+	if nativeAsset.IsZkRollup() {
+		result.Confirmations, err = client.finalizedConfirmations(ctx, receipt.BlockNumber, result.Confirmations)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return parseRPCTxInfo(result, tx, receipt, header.BaseFee, nativeAsset.NativeAsset), nil
 }
 
-// EstimateGas estimates gas price for a Cosmos chain
+// FetchLatestBlock returns the height of the latest block
+func (client *Client) FetchLatestBlock(ctx context.Context) (int64, error) {
+	height, err := client.EthClient.BlockNumber(ctx)
+	return int64(height), err
+}
+
+// FetchBlockTxInfos returns tx info for each tx of a block, including tx types that go-ethereum can't decode
+func (client *Client) FetchBlockTxInfos(ctx context.Context, height int64) ([]xc.TxInfo, error) {
+	nativeAsset := client.Asset.GetNativeAsset()
+	chainID := new(big.Int).SetInt64(nativeAsset.ChainID)
+
+	var block *struct {
+		Hash         common.Hash       `json:"hash"`
+		Timestamp    hexutil.Uint64    `json:"timestamp"`
+		BaseFee      *hexutil.Big      `json:"baseFeePerGas"`
+		Transactions []json.RawMessage `json:"transactions"`
+	}
+	if err := client.RpcClient.CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.EncodeBig(big.NewInt(height)), true); err != nil {
+		return nil, fmt.Errorf("fetching block %d: %v", height, err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("fetching block %d: %v", height, ethereum.NotFound)
+	}
+	latest, err := client.EthClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching latest header: %v", err)
+	}
+	var baseFee uint64
+	if block.BaseFee != nil {
+		baseFee = block.BaseFee.ToInt().Uint64()
+	}
+
+	infos := make([]xc.TxInfo, 0, len(block.Transactions))
+	for _, raw := range block.Transactions {
+		tx := &types.Transaction{}
+		var rpcTx *RPCTransaction
+		txHash := common.Hash{}
+		if err := tx.UnmarshalJSON(raw); errors.Is(err, types.ErrTxTypeNotSupported) {
+			if err := json.Unmarshal(raw, &rpcTx); err != nil {
+				return nil, fmt.Errorf("decoding tx of block %d: %v", height, err)
+			}
+			txHash = rpcTx.Hash
+		} else if err != nil {
+			return nil, fmt.Errorf("decoding tx of block %d: %v", height, err)
+		} else {
+			txHash = tx.Hash()
+		}
+
+		var receipt *RPCReceipt
+		if err := client.RpcClient.CallContext(ctx, &receipt, "eth_getTransactionReceipt", txHash); err != nil {
+			return nil, fmt.Errorf("fetching receipt for tx %v : %v", txHash.Hex(), err)
+		}
+		if receipt == nil {
+			return nil, fmt.Errorf("fetching receipt for tx %v : %v", txHash.Hex(), ethereum.NotFound)
+		}
+		result := xc.TxInfo{
+			TxID:          TrimPrefixes(txHash.Hex()),
+			ExplorerURL:   nativeAsset.ExplorerURL + "/tx/" + txHash.Hex(),
+			BlockHash:     block.Hash.Hex(),
+			BlockIndex:    height,
+			BlockTime:     int64(block.Timestamp),
+			Confirmations: int64(latest) - height,
+		}
+		if rpcTx != nil {
+			result = parseRPCTxInfo(result, rpcTx, receipt, block.BaseFee.ToInt(), nativeAsset.NativeAsset)
+		} else {
+			result = parseTxInfo(result, tx, &receipt.Receipt, baseFee, chainID, nativeAsset.NativeAsset)
+		}
+		infos = append(infos, result)
+	}
+	return infos, nil
+}
+
+// CheckReplacement checks that replacement can replace the pending tx pendingTxHash, e.g. to bump its fee
+func (client *Client) CheckReplacement(ctx context.Context, pendingTxHash xc.TxHash, replacement xc.Tx) error {
+	evmTx, ok := replacement.(*Tx)
+	if !ok || evmTx.EthTx == nil {
+		return errors.New("replacement is not an EVM tx")
+	}
+	var pending *RPCTransaction
+	txHash := common.HexToHash(TrimPrefixes(string(pendingTxHash)))
+	if err := client.RpcClient.CallContext(ctx, &pending, "eth_getTransactionByHash", txHash); err != nil {
+		return fmt.Errorf("fetching tx by hash '%s': %v", pendingTxHash, err)
+	}
+	if pending == nil {
+		return fmt.Errorf("fetching tx by hash '%s': %v", pendingTxHash, ethereum.NotFound)
+	}
+	if pending.BlockNumber != nil {
+		return fmt.Errorf("tx %s is already confirmed", pendingTxHash)
+	}
+	return CheckReplacement(pending, evmTx.EthTx)
+}
+
+// EstimateGas estimates gas price for an EVM chain, floored to the configured min gas price
 func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	gasPrice, err := client.estimateGas(ctx)
+	if err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), err
+	}
+	minGasPrice := xc.NewAmountBlockchainFromUint64(uint64(client.Asset.GetNativeAsset().MinGasPrice))
+	return gasPrice.ApplyFloor(minGasPrice), nil
+}
+
+func (client *Client) estimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
 	asset := client.Asset.GetNativeAsset()
 
 	// invoke EstimateGasFunc callback, if registered
@@ -387,7 +713,8 @@ func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, err
 
 	// legacy gas estimation via SuggestGasPrice
 	var baseFee uint64
-	if client.Legacy {
+	// the gas price of Polygon zkEVM accounts for the L1 cost of txs
+	if client.Legacy || asset.IsRollup(xc.RollupPolygonZkEVM) {
 		baseFeeInt, err := client.EthClient.SuggestGasPrice(ctx)
 		if err != nil {
 			// pass
@@ -398,7 +725,15 @@ func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, err
 		latest, err := client.EthClient.HeaderByNumber(ctx, nil)
 		if err != nil {
 			// pass
+		} else if latest.BaseFee == nil {
+			// the chain doesn't support dynamic fee txs, though not configured as legacy
+			client.setDynamicFees(false)
+			baseFeeInt, err := client.EthClient.SuggestGasPrice(ctx)
+			if err == nil {
+				baseFee = baseFeeInt.Uint64()
+			}
 		} else {
+			client.setDynamicFees(true)
 			baseFee = latest.BaseFee.Uint64()
 		}
 	}
@@ -415,6 +750,37 @@ func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, err
 	return xc.NewAmountBlockchainFromUint64(gasPrice), nil
 }
 
+// dynamicFees returns false once the chain is found not to support dynamic fee txs (EIP-1559), its latest block
+// having no base fee
+func (client *Client) dynamicFees() bool {
+	client.noDynamicFeesMu.Lock()
+	defer client.noDynamicFeesMu.Unlock()
+	return !client.noDynamicFees
+}
+
+func (client *Client) setDynamicFees(supported bool) {
+	client.noDynamicFeesMu.Lock()
+	defer client.noDynamicFeesMu.Unlock()
+	client.dynamicFeesChecked = true
+	client.noDynamicFees = !supported
+}
+
+// checkDynamicFees fetches the latest block for its base fee, unless fetched already, e.g. when estimating gas
+// Dynamic fees are assumed while the block can't be fetched
+func (client *Client) checkDynamicFees(ctx context.Context) {
+	client.noDynamicFeesMu.Lock()
+	checked := client.dynamicFeesChecked
+	client.noDynamicFeesMu.Unlock()
+	if checked {
+		return
+	}
+	latest, err := client.EthClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return
+	}
+	client.setDynamicFees(latest.BaseFee != nil)
+}
+
 // RegisterEstimateGasCallback registers a callback to get gas price
 func (client *Client) RegisterEstimateGasCallback(fn xc.EstimateGasFunc) {
 	client.EstimateGasFunc = fn
@@ -458,3 +824,60 @@ func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (xc.
 	}
 	return xc.AmountBlockchain(*balance), nil
 }
+
+// FetchBalanceAt fetches the balance of address at the end of block height, of the native asset if contract is
+// empty, or else of the ERC20 contract, e.g. for audits; nodes must be archive nodes for blocks older than 128
+func (client *Client) FetchBalanceAt(ctx context.Context, address xc.Address, contract xc.ContractAddress, height int64) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	targetAddr, err := HexToAddress(address)
+	if err != nil {
+		return zero, fmt.Errorf("bad address '%v': %v", address, err)
+	}
+	blockNumber := big.NewInt(height)
+	if contract == "" {
+		balance, err := client.EthClient.BalanceAt(ctx, targetAddr, blockNumber)
+		if err != nil {
+			return zero, fmt.Errorf("failed to get balance for '%v' at %d: %v", address, height, err)
+		}
+		return xc.AmountBlockchain(*balance), nil
+	}
+	tokenAddress, err := HexToAddress(xc.Address(contract))
+	if err != nil {
+		return zero, fmt.Errorf("bad contract '%v': %v", contract, err)
+	}
+	instance, err := erc20.NewErc20(tokenAddress, client.EthClient)
+	if err != nil {
+		return zero, err
+	}
+	balance, err := instance.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: blockNumber}, targetAddr)
+	if err != nil {
+		return zero, fmt.Errorf("failed to get balance of %s for '%v' at %d: %v", contract, address, height, err)
+	}
+	return xc.AmountBlockchain(*balance), nil
+}
+
+// FetchTokenMetadata fetches the symbol and decimals of an ERC20 token
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc.ContractAddress) (xc.TokenMetadata, error) {
+	tokenAddress, err := HexToAddress(xc.Address(contract))
+	if err != nil {
+		return xc.TokenMetadata{}, err
+	}
+	instance, err := erc20.NewErc20(tokenAddress, client.EthClient)
+	if err != nil {
+		return xc.TokenMetadata{}, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	symbol, err := instance.Symbol(opts)
+	if err != nil {
+		return xc.TokenMetadata{}, fmt.Errorf("failed to fetch symbol of %s: %v", contract, err)
+	}
+	decimals, err := instance.Decimals(opts)
+	if err != nil {
+		return xc.TokenMetadata{}, fmt.Errorf("failed to fetch decimals of %s: %v", contract, err)
+	}
+	return xc.TokenMetadata{
+		Contract: contract,
+		Symbol:   symbol,
+		Decimals: int32(decimals),
+	}, nil
+}
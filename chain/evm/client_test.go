@@ -3,7 +3,14 @@ package evm
 import (
 	"errors"
 	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	xc "github.com/jumpcrypto/crosschain"
 	"github.com/jumpcrypto/crosschain/test"
 )
@@ -73,6 +80,20 @@ func (s *CrosschainTestSuite) TestAccountBalance() {
 	}
 }
 
+func (s *CrosschainTestSuite) TestTxInputMaxFee() {
+	require := s.Require()
+	input := NewTxInput()
+	input.GasLimit = 21_000
+	input.GasFeeCap = xc.NewAmountBlockchainFromUint64(30)
+	require.Equal("630000", input.MaxFee().String())
+
+	// legacy
+	input.GasFeeCap = xc.NewAmountBlockchainFromUint64(0)
+	input.GasPrice = xc.NewAmountBlockchainFromUint64(20)
+	require.Equal("420000", input.MaxFee().String())
+	require.Equal("20", input.GasPrice.String())
+}
+
 // func (s *CrosschainTestSuite) TestFetchTxInput() {
 // 	require := s.Require()
 // 	client, _ := NewClient(xc.AssetConfig{})
@@ -129,6 +150,51 @@ func (s *CrosschainTestSuite) TestSubmitTx() {
 	}
 }
 
+func (s *CrosschainTestSuite) TestSubmitTxDryRun() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	ethTx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5)})
+	tx := &Tx{EthTx: ethTx, Signer: signer}
+
+	// simulated with eth_call, not broadcast
+	server, close := test.MockJSONRPC(&s.Suite, `"0x"`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+	dryRun := &xc.DryRun{}
+	err := client.SubmitTx(xc.WithDryRun(s.Ctx, dryRun), tx)
+	require.NoError(err)
+	require.Equal(1, server.Counter)
+	serialized, _ := tx.Serialize()
+	require.Equal([]*xc.DryRunSubmission{{Chain: xc.ETH, TxHash: tx.Hash(), Serialized: serialized, Simulated: true}}, dryRun.Submissions())
+
+	// reverted
+	server, close = test.MockJSONRPC(&s.Suite, errors.New(`{"message": "execution reverted", "code": 3}`))
+	defer close()
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH, URL: server.URL, DryRun: true})
+	err = client.SubmitTx(s.Ctx, tx)
+	require.ErrorContains(err, "execution reverted")
+}
+
+func (s *CrosschainTestSuite) TestSubmitTxDisabled() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	ethTx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5)})
+	tx := &Tx{EthTx: ethTx, Signer: signer}
+
+	server, close := test.MockJSONRPC(&s.Suite, `"0x"`)
+	defer close()
+	disabled := false
+	client, _ := NewClient(&xc.NativeAssetConfig{Asset: "ETH", NativeAsset: xc.ETH, URL: server.URL, Enabled: &disabled})
+	err := client.SubmitTx(s.Ctx, tx)
+	require.ErrorIs(err, xc.ErrAssetDisabled)
+	// not broadcast
+	require.Equal(0, server.Counter)
+}
+
 func (s *CrosschainTestSuite) TestFetchTxInfo() {
 	require := s.Require()
 
@@ -176,8 +242,10 @@ func (s *CrosschainTestSuite) TestFetchTxInfo() {
 						NativeAsset: "ETH",
 					},
 				},
-				Fee:    xc.NewAmountBlockchainFromStr("89668526728137000"),
-				Amount: xc.NewAmountBlockchainFromStr("5321609027609419494"),
+				Fee:      xc.NewAmountBlockchainFromStr("89668526728137000"),
+				GasUsed:  21000,
+				GasPrice: xc.NewAmountBlockchainFromUint64(4269929844197),
+				Amount:   xc.NewAmountBlockchainFromStr("5321609027609419494"),
 			},
 			"",
 		},
@@ -221,8 +289,10 @@ func (s *CrosschainTestSuite) TestFetchTxInfo() {
 						NativeAsset:     "ETH",
 					},
 				},
-				Fee:    xc.NewAmountBlockchainFromStr("51970500381117"),
-				Amount: xc.NewAmountBlockchainFromStr("10000000000000"),
+				Fee:      xc.NewAmountBlockchainFromStr("51970500381117"),
+				GasUsed:  34647,
+				GasPrice: xc.NewAmountBlockchainFromUint64(1500000011),
+				Amount:   xc.NewAmountBlockchainFromStr("10000000000000"),
 			},
 			"",
 		},
@@ -291,7 +361,9 @@ func (s *CrosschainTestSuite) TestFetchTxInfo() {
 						NativeAsset:     "ETH",
 					},
 				},
-				Fee: xc.NewAmountBlockchainFromStr("248127001985016"),
+				Fee:      xc.NewAmountBlockchainFromStr("248127001985016"),
+				GasUsed:  165418,
+				GasPrice: xc.NewAmountBlockchainFromUint64(1500000012),
 				// amount is the first destination
 				Amount: xc.NewAmountBlockchainFromStr("30000000000000000"),
 			},
@@ -390,7 +462,9 @@ func (s *CrosschainTestSuite) TestFetchTxInfo() {
 						Asset:   "ETH",
 					},
 				},
-				Fee: xc.NewAmountBlockchainFromStr("6231934410218064"),
+				Fee:      xc.NewAmountBlockchainFromStr("6231934410218064"),
+				GasUsed:  150134,
+				GasPrice: xc.NewAmountBlockchainFromUint64(41509147896),
 				// amount is the first destination
 			},
 			"",
@@ -417,3 +491,253 @@ func (s *CrosschainTestSuite) TestFetchTxInfo() {
 		}
 	}
 }
+
+func (s *CrosschainTestSuite) TestFetchTokenMetadata() {
+	require := s.Require()
+	// symbol() returns "USDT", decimals() returns 6
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`"0x000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000045553445400000000000000000000000000000000000000000000000000000000"`,
+		`"0x0000000000000000000000000000000000000000000000000000000000000006"`,
+	})
+	defer close()
+	asset := &xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL}
+	client, err := NewClient(asset)
+	require.NoError(err)
+
+	metadata, err := client.FetchTokenMetadata(s.Ctx, "0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	require.NoError(err)
+	require.Equal(xc.TokenMetadata{Contract: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Symbol: "USDT", Decimals: 6}, metadata)
+
+	server, close = test.MockJSONRPC(&s.Suite, errors.New(`{"message": "execution reverted", "code": 3}`))
+	defer close()
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+	_, err = client.FetchTokenMetadata(s.Ctx, "0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	require.ErrorContains(err, "failed to fetch symbol")
+}
+
+func (s *CrosschainTestSuite) TestFetchBalanceAt() {
+	require := s.Require()
+	address := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+
+	server, close := test.MockJSONRPC(&s.Suite, `"0x123"`)
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+	balance, err := client.FetchBalanceAt(s.Ctx, address, "", 100)
+	require.NoError(err)
+	require.Equal("291", balance.String())
+
+	// balanceOf() returns 1000000
+	server, close = test.MockJSONRPC(&s.Suite, `"0x00000000000000000000000000000000000000000000000000000000000f4240"`)
+	defer close()
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+	balance, err = client.FetchBalanceAt(s.Ctx, address, "0xdAC17F958D2ee523a2206206994597C13D831ec7", 100)
+	require.NoError(err)
+	require.Equal("1000000", balance.String())
+
+	server, close = test.MockJSONRPC(&s.Suite, errors.New(`{"message": "missing trie node", "code": -32000}`))
+	defer close()
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+	balance, err = client.FetchBalanceAt(s.Ctx, address, "", 100)
+	require.ErrorContains(err, "missing trie node")
+	require.Equal("0", balance.String())
+}
+
+func (s *CrosschainTestSuite) TestConcurrentClient() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `"0x5"`)
+	defer close()
+	asset := &xc.AssetConfig{NativeAsset: xc.ETH, Net: "testnet", URL: server.URL, ChainID: 5}
+	client, err := NewClient(asset)
+	require.NoError(err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chainID, err := client.ChainID()
+			require.NoError(err)
+			require.EqualValues(5, chainID.Int64())
+			balance, err := client.FetchNativeBalance(s.Ctx, "0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+			require.NoError(err)
+			require.Equal("5", balance.String())
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *CrosschainTestSuite) TestConcurrentInterception() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `"xdc5"`)
+	defer close()
+	client, err := NewClient(&xc.AssetConfig{NativeAsset: xc.XDC, URL: server.URL})
+	require.NoError(err)
+
+	// only the requests under an intercepted context are rewritten
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(intercepted bool) {
+			defer wg.Done()
+			ctx := s.Ctx
+			expected := "xdc5"
+			if intercepted {
+				ctx = withInterception(ctx)
+				expected = "0x5"
+			}
+			var result string
+			require.NoError(client.RpcClient.CallContext(ctx, &result, "eth_chainId"))
+			require.Equal(expected, result)
+		}(i%2 == 0)
+	}
+	wg.Wait()
+
+	client.Interceptor.Enable()
+	var result string
+	require.NoError(client.RpcClient.CallContext(s.Ctx, &result, "eth_chainId"))
+	require.Equal("0x5", result)
+	client.Interceptor.Disable()
+}
+
+func (s *CrosschainTestSuite) TestReplayFetchNativeBalance() {
+	require := s.Require()
+	// record with XC_RECORD_FIXTURES=1 against a goerli node
+	server, close := test.ReplayServer(&s.Suite, "testdata/fetch_native_balance.json", "https://rpc.ankr.com/eth_goerli")
+	defer close()
+	asset := &xc.AssetConfig{NativeAsset: xc.ETH, Net: "testnet", URL: server.URL, ChainID: 5}
+	client, err := NewClient(asset)
+	require.NoError(err)
+
+	balance, err := client.FetchNativeBalance(s.Ctx, "0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	require.NoError(err)
+	require.Equal("2000000000000000000", balance.String())
+}
+
+func (s *CrosschainTestSuite) TestEstimateGasMinGasPrice() {
+	require := s.Require()
+	asset := &xc.AssetConfig{NativeAsset: xc.ETH, MinGasPrice: 5_000_000_000}
+	client, err := NewClient(asset)
+	require.NoError(err)
+
+	// providers returning zero on quiet chains
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(0), nil
+	})
+	gasPrice, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("5000000000", gasPrice.String())
+
+	// higher estimates are kept
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(7_000_000_000), nil
+	})
+	gasPrice, err = client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("7000000000", gasPrice.String())
+}
+
+func (s *CrosschainTestSuite) TestEstimateFee() {
+	require := s.Require()
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	to := common.HexToAddress("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_estimateGas
+		`"0x5208"`,
+		`"0x5208"`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	// at the max fee per gas of the tx
+	tx := &Tx{EthTx: types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), To: &to, GasFeeCap: big.NewInt(40_000_000_000), GasTipCap: big.NewInt(1)})}
+	fee, err := client.EstimateFee(s.Ctx, from, tx)
+	require.NoError(err)
+	require.Equal("840000000000000", fee.String())
+
+	// at the estimated gas price of a tx without fees
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(10_000_000_000), nil
+	})
+	tx = &Tx{EthTx: types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), To: &to})}
+	fee, err = client.EstimateFee(s.Ctx, from, tx)
+	require.NoError(err)
+	require.Equal("210000000000000", fee.String())
+	require.Equal(2, server.Counter)
+
+	_, err = client.EstimateFee(s.Ctx, from, &Tx{})
+	require.EqualError(err, "tx is not an EVM tx")
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInputNoDynamicFees() {
+	require := s.Require()
+	// a block without baseFeePerGas
+	header := `{"difficulty":"0x2","extraData":"0x","gasLimit":"0x1c9c380","gasUsed":"0x0","hash":"0xd090a9e97e00aa135710a92c827def07e4c8ff2269fd69411c48402e0a6a2a89","logsBloom":"0x` + strings.Repeat("00", 256) + `","miner":"0x0000000000000000000000000000000000000000","mixHash":"0x0000000000000000000000000000000000000000000000000000000000000000","nonce":"0x0000000000000000","number":"0x10","parentHash":"0x0000000000000000000000000000000000000000000000000000000000000000","receiptsRoot":"0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","stateRoot":"0x0000000000000000000000000000000000000000000000000000000000000000","timestamp":"0x64","transactionsRoot":"0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421"}`
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_getTransactionCount
+		`"0x6"`,
+		// eth_getBlockByNumber
+		header,
+		// eth_gasPrice
+		`"0x6fc23ac00"`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+	input, err := client.FetchTxInput(s.Ctx, xc.Address(""), xc.Address(""))
+	require.NoError(err)
+	txInput := input.(*TxInput)
+	require.True(txInput.Legacy)
+	require.EqualValues(6, txInput.Nonce)
+	// (30 gwei + the default tip) * 2
+	require.Equal("66000000000", txInput.GasPrice.String())
+	require.Equal(3, server.Counter)
+
+	builder, _ := NewTxBuilder(&xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, ChainID: 1})
+	tx, err := builder.NewTransfer("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B", "0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD", xc.NewAmountBlockchainFromUint64(1), txInput)
+	require.NoError(err)
+	require.EqualValues(types.LegacyTxType, tx.(*Tx).EthTx.Type())
+
+	// detected as well when gas isn't estimated from the latest block, once per client
+	for _, asset := range []*xc.NativeAssetConfig{
+		{NativeAsset: xc.ETH, URL: server.URL},
+		{NativeAsset: xc.ETH, URL: server.URL, NoGasFees: true},
+	} {
+		server.Counter = 0
+		server.Response = []string{`"0x6"`, header, `"0x7"`}
+		client, _ = NewClient(asset)
+		client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+			return xc.NewAmountBlockchainFromUint64(10_000_000_000), nil
+		})
+		input, err = client.FetchTxInput(s.Ctx, xc.Address(""), xc.Address(""))
+		require.NoError(err)
+		require.True(input.(*TxInput).Legacy)
+		input, err = client.FetchTxInput(s.Ctx, xc.Address(""), xc.Address(""))
+		require.NoError(err)
+		require.True(input.(*TxInput).Legacy)
+		require.EqualValues(7, input.(*TxInput).GetNonce())
+		require.Equal(3, server.Counter)
+	}
+}
+
+func (s *CrosschainTestSuite) TestNewClientRequestSigning() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `"0x1"`)
+	defer close()
+
+	signed := []string{}
+	client, err := NewClient(&xc.NativeAssetConfig{
+		NativeAsset: xc.ETH,
+		URL:         server.URL,
+		RequestSigner: xc.RequestSignerFunc(func(req *http.Request, body []byte) error {
+			signed = append(signed, string(body))
+			return nil
+		}),
+	})
+	require.NoError(err)
+	_, err = client.EthClient.BlockNumber(s.Ctx)
+	require.NoError(err)
+	require.Len(signed, 1)
+	require.Contains(signed[0], "eth_blockNumber")
+
+	_, err = NewClient(&xc.NativeAssetConfig{URL: server.URL, RequestSigning: "oauth"})
+	require.EqualError(err, "unsupported request signing: 'oauth'")
+}
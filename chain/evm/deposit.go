@@ -0,0 +1,295 @@
+package evm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// DepositContractAddress is the beacon chain deposit contract on Ethereum mainnet
+const DepositContractAddress = "0x00000000219ab540356cBB839Cbe05303d7705Fa"
+
+// MainnetGenesisForkVersion and MainnetGenesisValidatorsRoot are used to compute BLS-to-execution change signing roots
+var MainnetGenesisForkVersion = []byte{0, 0, 0, 0}
+var MainnetGenesisValidatorsRoot = common.FromHex("0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95")
+
+// DomainBLSToExecutionChange is the signature domain type of BLSToExecutionChange messages
+var DomainBLSToExecutionChange = []byte{0x0a, 0, 0, 0}
+
+const (
+	blsPublicKeyLen          = 48
+	blsSignatureLen          = 96
+	withdrawalCredentialsLen = 32
+	weiPerGwei               = 1_000_000_000
+)
+
+// DepositData is a beacon chain deposit, as generated by the staking deposit cli
+type DepositData struct {
+	PublicKey             []byte
+	WithdrawalCredentials []byte
+	// Amount in wei, must be a multiple of 1 gwei
+	Amount          xc.AmountBlockchain
+	Signature       []byte
+	DepositDataRoot []byte
+}
+
+// depositDataJSON is an entry of deposit_data-*.json
+type depositDataJSON struct {
+	PublicKey             string `json:"pubkey"`
+	WithdrawalCredentials string `json:"withdrawal_credentials"`
+	Amount                uint64 `json:"amount"`
+	Signature             string `json:"signature"`
+	DepositDataRoot       string `json:"deposit_data_root"`
+}
+
+// ParseDepositDataJSON parses a deposit_data-*.json file, validating the deposit data root of every entry
+func ParseDepositDataJSON(data []byte) ([]DepositData, error) {
+	entries := []depositDataJSON{}
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, err
+	}
+	deposits := []DepositData{}
+	for _, entry := range entries {
+		gwei := xc.NewAmountBlockchainFromUint64(entry.Amount)
+		weiPerGweiAmount := xc.NewAmountBlockchainFromUint64(weiPerGwei)
+		deposit := DepositData{
+			PublicKey:             common.FromHex(entry.PublicKey),
+			WithdrawalCredentials: common.FromHex(entry.WithdrawalCredentials),
+			Amount:                gwei.Mul(&weiPerGweiAmount),
+			Signature:             common.FromHex(entry.Signature),
+			DepositDataRoot:       common.FromHex(entry.DepositDataRoot),
+		}
+		err = deposit.Validate()
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, deposit)
+	}
+	return deposits, nil
+}
+
+// AmountGwei returns the deposit amount in gwei, as stored in the beacon chain
+func (deposit DepositData) AmountGwei() (uint64, error) {
+	amountWei := deposit.Amount.Int()
+	gwei, remainder := new(big.Int).QuoRem(amountWei, big.NewInt(weiPerGwei), new(big.Int))
+	if remainder.Sign() != 0 {
+		return 0, errors.New("deposit amount must be a multiple of 1 gwei")
+	}
+	if !gwei.IsUint64() {
+		return 0, errors.New("deposit amount is too large")
+	}
+	return gwei.Uint64(), nil
+}
+
+// HashTreeRoot computes the SSZ hash tree root of the DepositData
+func (deposit DepositData) HashTreeRoot() ([]byte, error) {
+	if len(deposit.PublicKey) != blsPublicKeyLen {
+		return nil, fmt.Errorf("invalid deposit pubkey length: %d", len(deposit.PublicKey))
+	}
+	if len(deposit.WithdrawalCredentials) != withdrawalCredentialsLen {
+		return nil, fmt.Errorf("invalid deposit withdrawal credentials length: %d", len(deposit.WithdrawalCredentials))
+	}
+	if len(deposit.Signature) != blsSignatureLen {
+		return nil, fmt.Errorf("invalid deposit signature length: %d", len(deposit.Signature))
+	}
+	amountGwei, err := deposit.AmountGwei()
+	if err != nil {
+		return nil, err
+	}
+
+	pubkeyRoot := hashBLSPublicKey(deposit.PublicKey)
+	signatureRoot := sha256Concat(
+		sha256Concat(deposit.Signature[:64]),
+		sha256Concat(deposit.Signature[64:], make([]byte, 32)),
+	)
+	return sha256Concat(
+		sha256Concat(pubkeyRoot, deposit.WithdrawalCredentials),
+		sha256Concat(uint64Chunk(amountGwei), signatureRoot),
+	), nil
+}
+
+// Validate checks field sizes and that DepositDataRoot matches the deposit data
+func (deposit DepositData) Validate() error {
+	root, err := deposit.HashTreeRoot()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root, deposit.DepositDataRoot) {
+		return fmt.Errorf("invalid deposit data root: expected %s, got %s", hex.EncodeToString(root), hex.EncodeToString(deposit.DepositDataRoot))
+	}
+	return nil
+}
+
+// depositArguments are the arguments of deposit(bytes,bytes,bytes,bytes32), parsed once
+var depositArguments = func() abi.Arguments {
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	bytes32Type, _ := abi.NewType("bytes32", "", nil)
+	return abi.Arguments{
+		{Type: bytesType},
+		{Type: bytesType},
+		{Type: bytesType},
+		{Type: bytes32Type},
+	}
+}()
+
+func buildDepositPayload(deposit DepositData) ([]byte, error) {
+	var root [32]byte
+	copy(root[:], deposit.DepositDataRoot)
+	params, err := depositArguments.Pack(deposit.PublicKey, deposit.WithdrawalCredentials, deposit.Signature, root)
+	if err != nil {
+		return nil, err
+	}
+	// deposit(bytes,bytes,bytes,bytes32)
+	methodID := []byte{0x22, 0x89, 0x51, 0x18}
+	return append(methodID, params...), nil
+}
+
+// NewDeposit creates a beacon chain deposit contract tx, validating the deposit data root
+// The deposit contract is the asset contract, if set, or DepositContractAddress
+func (txBuilder TxBuilder) NewDeposit(from xc.Address, deposit DepositData, input xc.TxInput) (xc.Tx, error) {
+	evmInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, errors.New("xc.TxInput is not from an evm chain")
+	}
+	asset := txBuilder.Asset.GetAssetConfig()
+
+	err := deposit.Validate()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := buildDepositPayload(deposit)
+	if err != nil {
+		return nil, err
+	}
+
+	// copy the input so the default gas limit doesn't leak into the caller's input
+	txInput := *evmInput
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = 100_000
+	}
+	contract := xc.Address(DepositContractAddress)
+	if asset.Contract != "" {
+		contract = xc.Address(asset.Contract)
+	}
+	return txBuilder.buildEvmTxWithPayload(contract, deposit.Amount, payload, &txInput)
+}
+
+// BLSWithdrawalCredentials returns 0x00 withdrawal credentials for a BLS withdrawal public key
+func BLSWithdrawalCredentials(publicKey []byte) ([]byte, error) {
+	if len(publicKey) != blsPublicKeyLen {
+		return nil, fmt.Errorf("invalid bls pubkey length: %d", len(publicKey))
+	}
+	hash := sha256.Sum256(publicKey)
+	credentials := append([]byte{0x00}, hash[1:]...)
+	return credentials, nil
+}
+
+// ExecutionWithdrawalCredentials returns 0x01 withdrawal credentials for an execution address
+func ExecutionWithdrawalCredentials(address xc.Address) ([]byte, error) {
+	addr, err := HexToAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	credentials := make([]byte, 12, withdrawalCredentialsLen)
+	credentials[0] = 0x01
+	return append(credentials, addr.Bytes()...), nil
+}
+
+// BLSToExecutionChange changes 0x00 withdrawal credentials of a validator to an execution address
+type BLSToExecutionChange struct {
+	ValidatorIndex     uint64
+	FromBLSPublicKey   []byte
+	ToExecutionAddress xc.Address
+}
+
+// SignedBLSToExecutionChange is the beacon API format of a signed BLSToExecutionChange
+type SignedBLSToExecutionChange struct {
+	Message   BLSToExecutionChangeMessage `json:"message"`
+	Signature string                      `json:"signature"`
+}
+
+// BLSToExecutionChangeMessage is the beacon API format of a BLSToExecutionChange
+type BLSToExecutionChangeMessage struct {
+	ValidatorIndex     string `json:"validator_index"`
+	FromBLSPublicKey   string `json:"from_bls_pubkey"`
+	ToExecutionAddress string `json:"to_execution_address"`
+}
+
+// HashTreeRoot computes the SSZ hash tree root of the BLSToExecutionChange
+func (change BLSToExecutionChange) HashTreeRoot() ([]byte, error) {
+	if len(change.FromBLSPublicKey) != blsPublicKeyLen {
+		return nil, fmt.Errorf("invalid bls pubkey length: %d", len(change.FromBLSPublicKey))
+	}
+	addr, err := HexToAddress(change.ToExecutionAddress)
+	if err != nil {
+		return nil, err
+	}
+	addressChunk := common.RightPadBytes(addr.Bytes(), 32)
+	return sha256Concat(
+		sha256Concat(uint64Chunk(change.ValidatorIndex), hashBLSPublicKey(change.FromBLSPublicKey)),
+		sha256Concat(addressChunk, make([]byte, 32)),
+	), nil
+}
+
+// SigningRoot returns the root to be signed by the BLS withdrawal key
+// Changes are always signed with the genesis fork version, e.g. MainnetGenesisForkVersion and MainnetGenesisValidatorsRoot
+func (change BLSToExecutionChange) SigningRoot(genesisForkVersion []byte, genesisValidatorsRoot []byte) ([]byte, error) {
+	if len(genesisForkVersion) != 4 || len(genesisValidatorsRoot) != 32 {
+		return nil, errors.New("invalid genesis fork version or validators root")
+	}
+	root, err := change.HashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	forkDataRoot := sha256Concat(common.RightPadBytes(genesisForkVersion, 32), genesisValidatorsRoot)
+	domain := append(append([]byte{}, DomainBLSToExecutionChange...), forkDataRoot[:28]...)
+	return sha256Concat(root, domain), nil
+}
+
+// Signed returns the change with a BLS signature, ready to be submitted to a beacon node
+func (change BLSToExecutionChange) Signed(signature []byte) (SignedBLSToExecutionChange, error) {
+	if len(signature) != blsSignatureLen {
+		return SignedBLSToExecutionChange{}, fmt.Errorf("invalid bls signature length: %d", len(signature))
+	}
+	addr, err := HexToAddress(change.ToExecutionAddress)
+	if err != nil {
+		return SignedBLSToExecutionChange{}, err
+	}
+	return SignedBLSToExecutionChange{
+		Message: BLSToExecutionChangeMessage{
+			ValidatorIndex:     fmt.Sprint(change.ValidatorIndex),
+			FromBLSPublicKey:   "0x" + hex.EncodeToString(change.FromBLSPublicKey),
+			ToExecutionAddress: strings.ToLower(addr.Hex()),
+		},
+		Signature: "0x" + hex.EncodeToString(signature),
+	}, nil
+}
+
+func hashBLSPublicKey(publicKey []byte) []byte {
+	return sha256Concat(publicKey, make([]byte, 16))
+}
+
+func uint64Chunk(value uint64) []byte {
+	chunk := make([]byte, 32)
+	binary.LittleEndian.PutUint64(chunk, value)
+	return chunk
+}
+
+func sha256Concat(chunks ...[]byte) []byte {
+	hash := sha256.New()
+	for _, chunk := range chunks {
+		hash.Write(chunk)
+	}
+	return hash.Sum(nil)
+}
@@ -0,0 +1,151 @@
+package evm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+var depositDataJSONFixture = `[{"pubkey": "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f", "withdrawal_credentials": "0100000000000000000000004592d8f8d7b001e72cb26a73e4fa1806a51ac79d", "amount": 32000000000, "signature": "00070e151c232a31383f464d545b626970777e858c939aa1a8afb6bdc4cbd2d9e0e7eef5fc030a11181f262d343b424950575e656c737a81888f969da4abb2b9c0c7ced5dce3eaf1f8ff060d141b222930373e454c535a61686f767d848b9299", "deposit_data_root": "d30267386257e6ca8d7bd22c4cf4ad8e3bbf3ef4a64d1246b79c616949bc4018"}]`
+
+func (s *CrosschainTestSuite) TestParseDepositDataJSON() {
+	require := s.Require()
+
+	deposits, err := ParseDepositDataJSON([]byte(depositDataJSONFixture))
+	require.Nil(err)
+	require.Len(deposits, 1)
+	require.Equal("32000000000000000000", deposits[0].Amount.String())
+	gwei, err := deposits[0].AmountGwei()
+	require.Nil(err)
+	require.Equal(uint64(32_000_000_000), gwei)
+
+	invalidRoot := []map[string]interface{}{}
+	_ = json.Unmarshal([]byte(depositDataJSONFixture), &invalidRoot)
+	invalidRoot[0]["amount"] = 1_000_000_000
+	invalidJSON, _ := json.Marshal(invalidRoot)
+	_, err = ParseDepositDataJSON(invalidJSON)
+	require.ErrorContains(err, "invalid deposit data root")
+}
+
+func (s *CrosschainTestSuite) TestDepositDataValidateErr() {
+	require := s.Require()
+	deposits, _ := ParseDepositDataJSON([]byte(depositDataJSONFixture))
+
+	deposit := deposits[0]
+	deposit.PublicKey = deposit.PublicKey[:47]
+	require.ErrorContains(deposit.Validate(), "invalid deposit pubkey length")
+
+	deposit = deposits[0]
+	deposit.WithdrawalCredentials = []byte{}
+	require.ErrorContains(deposit.Validate(), "invalid deposit withdrawal credentials length")
+
+	deposit = deposits[0]
+	deposit.Signature = deposit.Signature[1:]
+	require.ErrorContains(deposit.Validate(), "invalid deposit signature length")
+
+	deposit = deposits[0]
+	deposit.Amount = xc.NewAmountBlockchainFromUint64(1)
+	require.ErrorContains(deposit.Validate(), "multiple of 1 gwei")
+
+	deposit = deposits[0]
+	deposit.DepositDataRoot = make([]byte, 32)
+	require.ErrorContains(deposit.Validate(), "invalid deposit data root")
+}
+
+func (s *CrosschainTestSuite) TestNewDeposit() {
+	require := s.Require()
+	deposits, _ := ParseDepositDataJSON([]byte(depositDataJSONFixture))
+
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.ETH, ChainID: 1})
+	input := &TxInput{}
+	tx, err := builder.(TxBuilder).NewDeposit("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d", deposits[0], input)
+	require.Nil(err)
+	ethTx := tx.(*Tx).EthTx
+	require.Equal(common.HexToAddress(DepositContractAddress), *ethTx.To())
+	require.Equal("32000000000000000000", ethTx.Value().String())
+	require.Equal(uint64(100_000), ethTx.Gas())
+	data := ethTx.Data()
+	require.Equal("22895118", hex.EncodeToString(data[:4]))
+	// 4 head words + 3 dynamic bytes (length word + padded content)
+	require.Len(data, 4+4*32+(32+64)+(32+32)+(32+96))
+	require.Equal(deposits[0].DepositDataRoot, data[4+3*32:4+4*32])
+
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.ETH, ChainID: 5, Contract: "0xff50ed3d0ec03aC01D4C79aAd74928BFF48a7b2b"})
+	tx, err = builder.(TxBuilder).NewDeposit("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d", deposits[0], input)
+	require.Nil(err)
+	require.Equal(common.HexToAddress("0xff50ed3d0ec03aC01D4C79aAd74928BFF48a7b2b"), *tx.(*Tx).EthTx.To())
+
+	deposit := deposits[0]
+	deposit.DepositDataRoot = make([]byte, 32)
+	_, err = builder.(TxBuilder).NewDeposit("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d", deposit, input)
+	require.ErrorContains(err, "invalid deposit data root")
+
+	_, err = builder.(TxBuilder).NewDeposit("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d", deposits[0], &struct{ xc.TxInputEnvelope }{})
+	require.EqualError(err, "xc.TxInput is not from an evm chain")
+}
+
+func (s *CrosschainTestSuite) TestNewDepositGasLimit() {
+	require := s.Require()
+	deposits, _ := ParseDepositDataJSON([]byte(depositDataJSONFixture))
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.ETH, ChainID: 1})
+
+	// the default gas limit isn't written into the caller's input
+	input := &TxInput{}
+	_, err := builder.(TxBuilder).NewDeposit("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d", deposits[0], input)
+	require.Nil(err)
+	require.Equal(uint64(0), input.GasLimit)
+
+	// an estimated gas limit is kept
+	input = &TxInput{GasLimit: 150_000}
+	tx, err := builder.(TxBuilder).NewDeposit("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d", deposits[0], input)
+	require.Nil(err)
+	require.Equal(uint64(150_000), tx.(*Tx).EthTx.Gas())
+	require.Equal(uint64(150_000), input.GasLimit)
+}
+
+func (s *CrosschainTestSuite) TestWithdrawalCredentials() {
+	require := s.Require()
+	pubkey, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f")
+
+	credentials, err := BLSWithdrawalCredentials(pubkey)
+	require.Nil(err)
+	require.Equal("00bdc2b2b62cb00749785bc84202236dbc3777d74660611b8e58812f0cfde6c3", hex.EncodeToString(credentials))
+	_, err = BLSWithdrawalCredentials(pubkey[:10])
+	require.ErrorContains(err, "invalid bls pubkey length")
+
+	credentials, err = ExecutionWithdrawalCredentials("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	require.Nil(err)
+	require.Equal("0100000000000000000000004592d8f8d7b001e72cb26a73e4fa1806a51ac79d", hex.EncodeToString(credentials))
+}
+
+func (s *CrosschainTestSuite) TestBLSToExecutionChange() {
+	require := s.Require()
+	pubkey, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f")
+	change := BLSToExecutionChange{
+		ValidatorIndex:     12345,
+		FromBLSPublicKey:   pubkey,
+		ToExecutionAddress: "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d",
+	}
+
+	root, err := change.HashTreeRoot()
+	require.Nil(err)
+	require.Equal("b01988a8038601eab6fafd3bbcc0c356b7196d6bf543a61a4635811a5e64354d", hex.EncodeToString(root))
+
+	signingRoot, err := change.SigningRoot(MainnetGenesisForkVersion, MainnetGenesisValidatorsRoot)
+	require.Nil(err)
+	require.Equal("90a521bb44ba81a22c87b2a97a70762d942ce721f30bd866be4db4421044ac4e", hex.EncodeToString(signingRoot))
+
+	_, err = change.SigningRoot([]byte{}, MainnetGenesisValidatorsRoot)
+	require.ErrorContains(err, "invalid genesis fork version")
+
+	signed, err := change.Signed(make([]byte, 96))
+	require.Nil(err)
+	require.Equal("12345", signed.Message.ValidatorIndex)
+	require.Equal("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d", signed.Message.ToExecutionAddress)
+	require.Equal("0x"+hex.EncodeToString(pubkey), signed.Message.FromBLSPublicKey)
+
+	_, err = change.Signed(make([]byte, 64))
+	require.ErrorContains(err, "invalid bls signature length")
+}
@@ -0,0 +1,93 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+var now = time.Now
+
+// Diagnose inspects the receipt, the pending tx and the account of its sender to tell why a tx is not confirmed
+func (client *Client) Diagnose(ctx context.Context, request xc.DiagnoseRequest) (*xc.Diagnosis, error) {
+	txHash := common.HexToHash(TrimPrefixes(string(request.TxHash)))
+
+	receipt, err := client.EthClient.TransactionReceipt(ctx, txHash)
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("could not fetch receipt of %s: %v", request.TxHash, err)
+	}
+	if err == nil && receipt != nil {
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			return xc.NewDiagnosis(request.TxHash, xc.StuckReasonNone, ""), nil
+		}
+		tx, _, err := client.EthClient.TransactionByHash(ctx, txHash)
+		if err == nil && receipt.GasUsed >= tx.Gas() {
+			return xc.NewDiagnosis(request.TxHash, xc.StuckReasonOutOfGas, fmt.Sprintf("used all %d gas in block %d", receipt.GasUsed, receipt.BlockNumber)), nil
+		}
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonFailed, fmt.Sprintf("reverted in block %d", receipt.BlockNumber)), nil
+	}
+
+	// the tx may be unknown to a provider behind the chain
+	header, err := client.EthClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch latest block: %v", err)
+	}
+	age := now().Sub(time.Unix(int64(header.Time), 0))
+	if age > xc.DefaultProviderLagThreshold {
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonProviderLagging, fmt.Sprintf("latest block %d is %s old", header.Number, age.Round(time.Second))), nil
+	}
+
+	tx, pending, err := client.EthClient.TransactionByHash(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		if request.IsPropagating(now()) {
+			return xc.NewDiagnosis(request.TxHash, xc.StuckReasonPending, "not propagated yet"), nil
+		}
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonDropped, "not found in mempool"), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch tx %s: %v", request.TxHash, err)
+	}
+	if !pending {
+		// mined, the receipt isn't indexed yet
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonPending, "receipt not indexed yet"), nil
+	}
+
+	var from common.Address
+	if request.From != "" {
+		from, err = HexToAddress(request.From)
+	} else {
+		from, err = types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not recover sender of %s: %v", request.TxHash, err)
+	}
+	nonce, err := client.EthClient.NonceAt(ctx, from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch nonce of %s: %v", from, err)
+	}
+	if tx.Nonce() > nonce {
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonNonceGap, fmt.Sprintf("nonce %d, next nonce of %s is %d", tx.Nonce(), from, nonce)), nil
+	}
+
+	if header.BaseFee != nil && tx.GasFeeCap().Cmp(header.BaseFee) < 0 {
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonFeeTooLow, fmt.Sprintf("max fee %s below base fee %s", tx.GasFeeCap(), header.BaseFee)), nil
+	}
+	if tx.Type() == types.LegacyTxType {
+		gasPrice, err := client.EthClient.SuggestGasPrice(ctx)
+		if err == nil && tx.GasPrice().Cmp(gasPrice) < 0 {
+			return xc.NewDiagnosis(request.TxHash, xc.StuckReasonFeeTooLow, fmt.Sprintf("gas price %s below %s", tx.GasPrice(), gasPrice)), nil
+		}
+	} else {
+		gasTipCap, err := client.EthClient.SuggestGasTipCap(ctx)
+		if err == nil && tx.GasTipCap().Cmp(gasTipCap) < 0 {
+			return xc.NewDiagnosis(request.TxHash, xc.StuckReasonFeeTooLow, fmt.Sprintf("priority fee %s below %s", tx.GasTipCap(), gasTipCap)), nil
+		}
+	}
+	return xc.NewDiagnosis(request.TxHash, xc.StuckReasonPending, ""), nil
+}
@@ -0,0 +1,59 @@
+package evm
+
+import (
+	"strings"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestDiagnose() {
+	require := s.Require()
+	// timestamp of blockJSON
+	blockTime := time.Unix(1683844272, 0)
+	defer func() { now = time.Now }()
+
+	pendingTx := strings.Replace(legacyTxJSON, `"blockNumber":"0x8914cc"`, `"blockNumber":null`, 1)
+	failedReceipt := strings.Replace(legacyReceiptJSON, `"status":"0x1"`, `"status":"0x0"`, 1)
+	vectors := []struct {
+		name    string
+		resp    []string
+		now     time.Time
+		request xc.DiagnoseRequest
+		reason  xc.StuckReason
+	}{
+		{"confirmed", []string{legacyReceiptJSON}, blockTime, xc.DiagnoseRequest{}, xc.StuckReasonNone},
+		{"out of gas", []string{failedReceipt, legacyTxJSON}, blockTime, xc.DiagnoseRequest{}, xc.StuckReasonOutOfGas},
+		{"lagging", []string{`null`, blockJSON(`[]`)}, blockTime.Add(time.Hour), xc.DiagnoseRequest{}, xc.StuckReasonProviderLagging},
+		{"propagating", []string{`null`, blockJSON(`[]`), `null`}, blockTime, xc.DiagnoseRequest{SubmittedAt: blockTime.Add(-10 * time.Second)}, xc.StuckReasonPending},
+		{"dropped", []string{`null`, blockJSON(`[]`), `null`}, blockTime, xc.DiagnoseRequest{SubmittedAt: blockTime.Add(-10 * time.Minute)}, xc.StuckReasonDropped},
+		{"receipt not indexed", []string{`null`, blockJSON(`[]`), legacyTxJSON}, blockTime, xc.DiagnoseRequest{}, xc.StuckReasonPending},
+		{"nonce gap", []string{`null`, blockJSON(`[]`), pendingTx, `"0x0"`}, blockTime, xc.DiagnoseRequest{From: "0x17519be39a6b67a19468dfbdc1d795c38232c274"}, xc.StuckReasonNonceGap},
+		{"fee too low", []string{`null`, blockJSON(`[]`), pendingTx, `"0x1"`, `"0x4000000000000"`}, blockTime, xc.DiagnoseRequest{From: "0x17519be39a6b67a19468dfbdc1d795c38232c274"}, xc.StuckReasonFeeTooLow},
+		{"pending", []string{`null`, blockJSON(`[]`), pendingTx, `"0x1"`, `"0x1"`}, blockTime, xc.DiagnoseRequest{From: "0x17519be39a6b67a19468dfbdc1d795c38232c274"}, xc.StuckReasonPending},
+	}
+	for _, v := range vectors {
+		server, close := test.MockJSONRPC(&s.Suite, v.resp)
+		client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+		now = func() time.Time { return v.now }
+		v.request.TxHash = "0xbca068cf854af49fc6b28ff5405068d51d3cefb870e624d22d046005a22349d0"
+
+		diagnosis, err := client.Diagnose(s.Ctx, v.request)
+		require.NoError(err, v.name)
+		require.Equal(v.reason, diagnosis.Reason, v.name)
+		require.Equal(v.reason.Remediation(), diagnosis.Remediation, v.name)
+		require.Equal(len(v.resp), server.Counter, v.name)
+		close()
+	}
+}
+
+func (s *CrosschainTestSuite) TestDiagnoseError() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{`null`, `{"jsonrpc":"2.0","error":{"code":-32000,"message":"header not found"},"id":0}`})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	_, err := client.Diagnose(s.Ctx, xc.DiagnoseRequest{TxHash: "0xbca068cf854af49fc6b28ff5405068d51d3cefb870e624d22d046005a22349d0"})
+	require.ErrorContains(err, "could not fetch latest block: header not found")
+}
@@ -0,0 +1,427 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// DefaultLogRangeSize is the number of blocks fetched per eth_getLogs request, within the limits of most providers
+const DefaultLogRangeSize = 2000
+
+// DefaultEventPollInterval is the interval between polls of the tip of a chain for new events
+const DefaultEventPollInterval = 5 * time.Second
+
+// DefaultEventReorgDepth is the number of blocks below the tip checked for reorgs by subscriptions
+const DefaultEventReorgDepth = 64
+
+// ErrUnknownEvent is returned when decoding a log of an event not registered
+var ErrUnknownEvent = errors.New("unknown event")
+
+// Event is a decoded contract event
+type Event struct {
+	Chain    xc.NativeAsset
+	Contract xc.ContractAddress
+	Name     string
+	// Signature is the canonical signature, e.g. Transfer(address,address,uint256)
+	Signature  string
+	BlockIndex int64
+	BlockHash  string
+	TxHash     string
+	LogIndex   uint
+	// Removed is set for events of a reorganized block
+	Removed bool
+	// Args are the decoded indexed and non-indexed arguments by name
+	Args map[string]interface{}
+}
+
+// EventRegistry decodes the logs of registered events
+// Events sharing a signature but not the indexed arguments (ERC-20 and ERC-721 Transfer) are told apart by their topics
+type EventRegistry struct {
+	mu     sync.RWMutex
+	events map[common.Hash][]abi.Event
+}
+
+// NewEventRegistry creates an EventRegistry decoding the events of abis
+func NewEventRegistry(abis ...abi.ABI) *EventRegistry {
+	registry := &EventRegistry{
+		events: map[common.Hash][]abi.Event{},
+	}
+	for _, contractABI := range abis {
+		registry.RegisterABI(contractABI)
+	}
+	return registry
+}
+
+// RegisterABI registers the events of an ABI
+func (registry *EventRegistry) RegisterABI(contractABI abi.ABI) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, event := range contractABI.Events {
+		if event.Anonymous {
+			// no topic to match
+			continue
+		}
+		registered := registry.events[event.ID]
+		replaced := false
+		for i, other := range registered {
+			if indexedCount(other) == indexedCount(event) {
+				registered[i] = event
+				replaced = true
+			}
+		}
+		if !replaced {
+			registry.events[event.ID] = append(registered, event)
+		}
+	}
+}
+
+// RegisterABIJSON registers the events of a JSON ABI
+func (registry *EventRegistry) RegisterABIJSON(abiJSON string) error {
+	contractABI, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return fmt.Errorf("invalid abi: %v", err)
+	}
+	registry.RegisterABI(contractABI)
+	return nil
+}
+
+// Topics returns the topics of the registered events
+func (registry *EventRegistry) Topics() []common.Hash {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	topics := make([]common.Hash, 0, len(registry.events))
+	for topic := range registry.events {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+func indexedCount(event abi.Event) int {
+	count := 0
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			count++
+		}
+	}
+	return count
+}
+
+// Decode decodes a log of chain, returns ErrUnknownEvent if no registered event matches
+func (registry *EventRegistry) Decode(chain xc.NativeAsset, log types.Log) (*Event, error) {
+	if len(log.Topics) == 0 {
+		return nil, ErrUnknownEvent
+	}
+	registry.mu.RLock()
+	candidates := registry.events[log.Topics[0]]
+	registry.mu.RUnlock()
+	for _, event := range candidates {
+		if indexedCount(event) != len(log.Topics)-1 {
+			continue
+		}
+		args := map[string]interface{}{}
+		if err := event.Inputs.UnpackIntoMap(args, log.Data); err != nil {
+			return nil, fmt.Errorf("could not decode %s data: %v", event.Sig, err)
+		}
+		indexed := abi.Arguments{}
+		for _, input := range event.Inputs {
+			if input.Indexed {
+				indexed = append(indexed, input)
+			}
+		}
+		if err := abi.ParseTopicsIntoMap(args, indexed, log.Topics[1:]); err != nil {
+			return nil, fmt.Errorf("could not decode %s topics: %v", event.Sig, err)
+		}
+		return &Event{
+			Chain:      chain,
+			Contract:   xc.ContractAddress(log.Address.String()),
+			Name:       event.RawName,
+			Signature:  event.Sig,
+			BlockIndex: int64(log.BlockNumber),
+			BlockHash:  log.BlockHash.Hex(),
+			TxHash:     log.TxHash.Hex(),
+			LogIndex:   log.Index,
+			Removed:    log.Removed,
+			Args:       args,
+		}, nil
+	}
+	return nil, ErrUnknownEvent
+}
+
+// FetchEvents returns the registered events of contracts between fromBlock and toBlock included,
+// all contracts if none is given, fetching rangeSize blocks per request
+func (client *Client) FetchEvents(ctx context.Context, registry *EventRegistry, contracts []xc.ContractAddress, fromBlock int64, toBlock int64, rangeSize int64) ([]*Event, error) {
+	nativeAsset := client.Asset.GetNativeAsset()
+	if rangeSize <= 0 {
+		rangeSize = DefaultLogRangeSize
+	}
+	addresses := make([]common.Address, len(contracts))
+	for i, contract := range contracts {
+		address, err := HexToAddress(xc.Address(contract))
+		if err != nil {
+			return nil, fmt.Errorf("invalid contract '%s': %v", contract, err)
+		}
+		addresses[i] = address
+	}
+	topics := registry.Topics()
+	events := []*Event{}
+	if len(topics) == 0 {
+		return events, nil
+	}
+	for start := fromBlock; start <= toBlock; start += rangeSize {
+		end := start + rangeSize - 1
+		if end > toBlock {
+			end = toBlock
+		}
+		logs, err := client.EthClient.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: big.NewInt(start),
+			ToBlock:   big.NewInt(end),
+			Addresses: addresses,
+			Topics:    [][]common.Hash{topics},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch logs of blocks %d to %d: %v", start, end, err)
+		}
+		for _, log := range logs {
+			event, err := registry.Decode(nativeAsset.NativeAsset, log)
+			if errors.Is(err, ErrUnknownEvent) {
+				// e.g. a different number of indexed arguments
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// EventSubscription delivers the registered events of watched contracts on a chain,
+// backfilling from FromBlock and then polling the tip of the chain
+// Events of reorganized blocks are delivered again with Removed set, then the events of the new blocks
+type EventSubscription struct {
+	Client    *Client
+	Registry  *EventRegistry
+	Contracts []xc.ContractAddress
+	// FromBlock is the first block to fetch, the subscription starts from the latest block if 0
+	FromBlock    int64
+	RangeSize    int64
+	PollInterval time.Duration
+	// Confirmations is the number of blocks on top of a block before its events are delivered
+	Confirmations int64
+	// ReorgDepth is the number of blocks below the tip checked for reorgs, reorgs aren't detected if 0
+	ReorgDepth int64
+
+	// next is the first block not fully delivered, and nextLog the first log of next not delivered
+	next    int64
+	nextLog uint
+	// hashes of the recently delivered blocks by height, and their events
+	hashes map[int64]common.Hash
+	recent []*Event
+}
+
+// NewEventSubscription creates a new EventSubscription of contracts, all contracts if none is given
+func NewEventSubscription(client *Client, registry *EventRegistry, contracts ...xc.ContractAddress) *EventSubscription {
+	return &EventSubscription{
+		Client:       client,
+		Registry:     registry,
+		Contracts:    contracts,
+		RangeSize:    DefaultLogRangeSize,
+		PollInterval: DefaultEventPollInterval,
+		ReorgDepth:   DefaultEventReorgDepth,
+	}
+}
+
+// NextBlock returns the next block to fetch, e.g. to persist the position of the subscription
+func (sub *EventSubscription) NextBlock() int64 {
+	return sub.next
+}
+
+// Poll delivers the events from the next block to the tip of the chain minus Confirmations
+// The subscription advances after each event, so that events are delivered once even if handler fails
+func (sub *EventSubscription) Poll(ctx context.Context, handler func(*Event) error) error {
+	latest, err := sub.Client.FetchLatestBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch latest block: %v", err)
+	}
+	head := latest - sub.Confirmations
+	if head < 0 {
+		return nil
+	}
+	if sub.next == 0 {
+		sub.next = sub.FromBlock
+		if sub.next == 0 {
+			sub.next = head
+		}
+	}
+	if err := sub.revertReorg(ctx, handler); err != nil {
+		return err
+	}
+	if sub.next > head {
+		return nil
+	}
+	var headHash common.Hash
+	if sub.ReorgDepth > 0 {
+		// before the logs so that a reorg in between is detected by the next poll
+		if headHash, err = sub.Client.fetchBlockHash(ctx, head); err != nil {
+			return err
+		}
+	}
+	events, err := sub.Client.FetchEvents(ctx, sub.Registry, sub.Contracts, sub.next, head, sub.RangeSize)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if event.BlockIndex == sub.next && event.LogIndex < sub.nextLog {
+			// delivered by a previous poll
+			continue
+		}
+		if err := handler(event); err != nil {
+			return err
+		}
+		sub.next = event.BlockIndex
+		sub.nextLog = event.LogIndex + 1
+		sub.remember(event.BlockIndex, common.HexToHash(event.BlockHash), event)
+	}
+	sub.next = head + 1
+	sub.nextLog = 0
+	sub.remember(head, headHash, nil)
+	return nil
+}
+
+// remember records the hash of a delivered block and its event, if any, to detect reorgs
+func (sub *EventSubscription) remember(height int64, hash common.Hash, event *Event) {
+	if sub.ReorgDepth <= 0 {
+		return
+	}
+	if sub.hashes == nil {
+		sub.hashes = map[int64]common.Hash{}
+	}
+	sub.hashes[height] = hash
+	if event != nil {
+		sub.recent = append(sub.recent, event)
+	}
+	oldest := sub.next - sub.ReorgDepth
+	for height := range sub.hashes {
+		if height < oldest {
+			delete(sub.hashes, height)
+		}
+	}
+	for len(sub.recent) > 0 && sub.recent[0].BlockIndex < oldest {
+		sub.recent = sub.recent[1:]
+	}
+}
+
+// revertReorg delivers the events of the reorganized blocks with Removed set, latest first,
+// and rewinds the subscription to the first reorganized block
+func (sub *EventSubscription) revertReorg(ctx context.Context, handler func(*Event) error) error {
+	if len(sub.hashes) == 0 {
+		return nil
+	}
+	heights := make([]int64, 0, len(sub.hashes))
+	for height := range sub.hashes {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+	// reorgs deeper than the recorded blocks revert all of them
+	fork := heights[len(heights)-1] - 1
+	for i, height := range heights {
+		hash, err := sub.Client.fetchBlockHash(ctx, height)
+		if err != nil {
+			return err
+		}
+		if hash == sub.hashes[height] {
+			if i == 0 {
+				return nil
+			}
+			fork = height
+			break
+		}
+	}
+	for i := len(sub.recent) - 1; i >= 0 && sub.recent[i].BlockIndex > fork; i-- {
+		removed := *sub.recent[i]
+		removed.Removed = true
+		if err := handler(&removed); err != nil {
+			return err
+		}
+		sub.recent = sub.recent[:i]
+	}
+	for height := range sub.hashes {
+		if height > fork {
+			delete(sub.hashes, height)
+		}
+	}
+	sub.next = fork + 1
+	sub.nextLog = 0
+	return nil
+}
+
+// fetchBlockHash returns the hash of the block at height
+func (client *Client) fetchBlockHash(ctx context.Context, height int64) (common.Hash, error) {
+	var block *struct {
+		Hash common.Hash `json:"hash"`
+	}
+	if err := client.RpcClient.CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.EncodeBig(big.NewInt(height)), false); err != nil {
+		return common.Hash{}, fmt.Errorf("could not fetch block %d: %v", height, err)
+	}
+	if block == nil {
+		return common.Hash{}, fmt.Errorf("could not fetch block %d: %v", height, ethereum.NotFound)
+	}
+	return block.Hash, nil
+}
+
+// Run delivers events until ctx is done or handler fails
+func (sub *EventSubscription) Run(ctx context.Context, handler func(*Event) error) error {
+	interval := sub.PollInterval
+	if interval <= 0 {
+		interval = DefaultEventPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := sub.Poll(ctx, handler); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WatchEvents runs subscriptions, e.g. one per EVM chain, until ctx is done or one of them fails
+// handler is called concurrently for events of different subscriptions
+func WatchEvents(ctx context.Context, subs []*EventSubscription, handler func(*Event) error) error {
+	if len(subs) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, len(subs))
+	for _, sub := range subs {
+		go func(sub *EventSubscription) {
+			errs <- sub.Run(ctx, handler)
+		}(sub)
+	}
+	// the first error stops the other subscriptions
+	err := <-errs
+	cancel()
+	for i := 1; i < len(subs); i++ {
+		<-errs
+	}
+	return err
+}
@@ -0,0 +1,281 @@
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const erc721TransferABI = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"}]`
+
+const erc20TransferLog = `{"address":"0xb4fbf271143f4fbf7b91a5ded31805e42b2208d6","topics":["0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef","0x000000000000000000000000e8be958f910fb1bb439eafbcfd0475509ab6d43f","0x0000000000000000000000005d2ebdf613d50dc598a09d8ebdc3f285be6cf8ed"],"data":"0x000000000000000000000000000000000000000000000000000009184e72a000","blockNumber":"0x891528","transactionHash":"0x7fba5ad368aab2490731a31d490e22d905c9b47ac2ca03e41b2021bfb76b423b","transactionIndex":"0xe","blockHash":"0x03d633a561d2217f8d7ae529ed90f3f4709fd62a5fb1b0ff6f7ce487f2113ba7","logIndex":"0x18","removed":false}`
+
+func (s *CrosschainTestSuite) TestEventRegistryDecode() {
+	require := s.Require()
+	registry := NewEventRegistry(ERC20)
+	require.NoError(registry.RegisterABIJSON(erc721TransferABI))
+	require.ErrorContains(registry.RegisterABIJSON("{"), "invalid abi")
+	// ERC-20 and ERC-721 Transfer share their topic
+	require.Len(registry.Topics(), 2)
+
+	log := types.Log{}
+	require.NoError(log.UnmarshalJSON([]byte(erc20TransferLog)))
+	event, err := registry.Decode(xc.ETH, log)
+	require.NoError(err)
+	require.Equal(&Event{
+		Chain:      xc.ETH,
+		Contract:   "0xB4FBF271143F4FBf7B91A5ded31805e42b2208d6",
+		Name:       "Transfer",
+		Signature:  "Transfer(address,address,uint256)",
+		BlockIndex: 8983848,
+		BlockHash:  "0x03d633a561d2217f8d7ae529ed90f3f4709fd62a5fb1b0ff6f7ce487f2113ba7",
+		TxHash:     "0x7fba5ad368aab2490731a31d490e22d905c9b47ac2ca03e41b2021bfb76b423b",
+		LogIndex:   24,
+		Args: map[string]interface{}{
+			"from":   common.HexToAddress("0xe8be958f910fb1bb439eafbcfd0475509ab6d43f"),
+			"to":     common.HexToAddress("0x5d2ebdf613d50dc598a09d8ebdc3f285be6cf8ed"),
+			"tokens": big.NewInt(10000000000000),
+		},
+	}, event)
+
+	// ERC-721 has the token id indexed
+	nftLog := log
+	nftLog.Topics = append(append([]common.Hash{}, log.Topics...), common.BigToHash(big.NewInt(42)))
+	nftLog.Data = nil
+	event, err = registry.Decode(xc.ETH, nftLog)
+	require.NoError(err)
+	require.Equal(big.NewInt(42), event.Args["tokenId"])
+	require.Equal(common.HexToAddress("0x5d2ebdf613d50dc598a09d8ebdc3f285be6cf8ed"), event.Args["to"])
+
+	unknown := log
+	unknown.Topics = []common.Hash{common.HexToHash("0x01")}
+	_, err = registry.Decode(xc.ETH, unknown)
+	require.True(errors.Is(err, ErrUnknownEvent))
+	_, err = registry.Decode(xc.ETH, types.Log{})
+	require.True(errors.Is(err, ErrUnknownEvent))
+
+	invalid := log
+	invalid.Data = []byte{1, 2}
+	_, err = registry.Decode(xc.ETH, invalid)
+	require.ErrorContains(err, "could not decode Transfer(address,address,uint256) data")
+}
+
+func (s *CrosschainTestSuite) TestFetchEvents() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_getLogs of blocks 8983000 to 8983999
+		`[]`,
+		// eth_getLogs of blocks 8984000 to 8984500
+		`[` + erc20TransferLog + `]`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	events, err := client.FetchEvents(s.Ctx, NewEventRegistry(ERC20), []xc.ContractAddress{"0xb4fbf271143f4fbf7b91a5ded31805e42b2208d6"}, 8983000, 8984500, 1000)
+	require.NoError(err)
+	require.Equal(2, server.Counter)
+	require.Len(events, 1)
+	require.Equal("Transfer", events[0].Name)
+
+	// nothing registered
+	events, err = client.FetchEvents(s.Ctx, NewEventRegistry(), nil, 0, 1, 0)
+	require.NoError(err)
+	require.Len(events, 0)
+	require.Equal(2, server.Counter)
+}
+
+const erc20TransferBlock = `{"hash":"0x03d633a561d2217f8d7ae529ed90f3f4709fd62a5fb1b0ff6f7ce487f2113ba7"}`
+
+func (s *CrosschainTestSuite) TestEventSubscriptionPoll() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_blockNumber
+		`"0x891528"`,
+		// eth_getBlockByNumber of the head
+		erc20TransferBlock,
+		// eth_getLogs
+		`[` + erc20TransferLog + `]`,
+		// eth_blockNumber, no new block
+		`"0x891528"`,
+		// eth_getBlockByNumber, no reorg
+		erc20TransferBlock,
+		// eth_blockNumber
+		`"0x891529"`,
+		// eth_getBlockByNumber, no reorg
+		erc20TransferBlock,
+		// eth_getBlockByNumber of the head
+		`{"hash":"0x0000000000000000000000000000000000000000000000000000000000000001"}`,
+		// eth_getLogs
+		`[]`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	sub := NewEventSubscription(client, NewEventRegistry(ERC20))
+	sub.FromBlock = 8983800
+	events := []*Event{}
+	handler := func(event *Event) error {
+		events = append(events, event)
+		return nil
+	}
+	require.NoError(sub.Poll(s.Ctx, handler))
+	require.Len(events, 1)
+	require.EqualValues(8983849, sub.NextBlock())
+
+	require.NoError(sub.Poll(s.Ctx, handler))
+	require.Equal(5, server.Counter)
+	require.NoError(sub.Poll(s.Ctx, handler))
+	require.Equal(9, server.Counter)
+	require.EqualValues(8983850, sub.NextBlock())
+	require.Len(events, 1)
+}
+
+func (s *CrosschainTestSuite) TestEventSubscriptionConfirmations() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_blockNumber, the event has 1 confirmation
+		`"0x891529"`,
+		// eth_blockNumber
+		`"0x89152a"`,
+		// eth_getLogs
+		`[` + erc20TransferLog + `]`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	sub := NewEventSubscription(client, NewEventRegistry(ERC20))
+	sub.FromBlock = 8983848
+	sub.Confirmations = 2
+	sub.ReorgDepth = 0
+	events := []*Event{}
+	handler := func(event *Event) error {
+		events = append(events, event)
+		return nil
+	}
+	require.NoError(sub.Poll(s.Ctx, handler))
+	require.Len(events, 0)
+	require.EqualValues(8983848, sub.NextBlock())
+
+	require.NoError(sub.Poll(s.Ctx, handler))
+	require.Len(events, 1)
+	require.EqualValues(8983849, sub.NextBlock())
+}
+
+func (s *CrosschainTestSuite) TestEventSubscriptionHandlerError() {
+	require := s.Require()
+	secondLog := strings.Replace(erc20TransferLog, `"logIndex":"0x18"`, `"logIndex":"0x19"`, 1)
+	logs := `[` + erc20TransferLog + `,` + secondLog + `]`
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_blockNumber
+		`"0x891528"`,
+		// eth_getLogs
+		logs,
+		// eth_blockNumber
+		`"0x891528"`,
+		// eth_getLogs, the first event was delivered
+		logs,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	sub := NewEventSubscription(client, NewEventRegistry(ERC20))
+	sub.FromBlock = 8983848
+	sub.ReorgDepth = 0
+	errHandler := errors.New("handler failed")
+	logIndexes := []uint{}
+	fail := true
+	handler := func(event *Event) error {
+		if event.LogIndex == 25 && fail {
+			fail = false
+			return errHandler
+		}
+		logIndexes = append(logIndexes, event.LogIndex)
+		return nil
+	}
+	require.Equal(errHandler, sub.Poll(s.Ctx, handler))
+	require.Equal([]uint{24}, logIndexes)
+	require.EqualValues(8983848, sub.NextBlock())
+
+	require.NoError(sub.Poll(s.Ctx, handler))
+	require.Equal([]uint{24, 25}, logIndexes)
+	require.EqualValues(8983849, sub.NextBlock())
+}
+
+func (s *CrosschainTestSuite) TestEventSubscriptionReorg() {
+	require := s.Require()
+	reorgedLog := strings.Replace(erc20TransferLog, "0x03d633a561d2217f8d7ae529ed90f3f4709fd62a5fb1b0ff6f7ce487f2113ba7", "0x0000000000000000000000000000000000000000000000000000000000000002", 1)
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_blockNumber
+		`"0x891529"`,
+		// eth_getBlockByNumber of the head
+		`{"hash":"0x0000000000000000000000000000000000000000000000000000000000000001"}`,
+		// eth_getLogs
+		`[` + erc20TransferLog + `]`,
+		// eth_blockNumber
+		`"0x891529"`,
+		// eth_getBlockByNumber, both blocks are reorganized
+		`{"hash":"0x0000000000000000000000000000000000000000000000000000000000000003"}`,
+		`{"hash":"0x0000000000000000000000000000000000000000000000000000000000000002"}`,
+		// eth_getBlockByNumber of the head
+		`{"hash":"0x0000000000000000000000000000000000000000000000000000000000000003"}`,
+		// eth_getLogs
+		`[` + reorgedLog + `]`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+
+	sub := NewEventSubscription(client, NewEventRegistry(ERC20))
+	sub.FromBlock = 8983848
+	events := []*Event{}
+	handler := func(event *Event) error {
+		events = append(events, event)
+		return nil
+	}
+	require.NoError(sub.Poll(s.Ctx, handler))
+	require.Len(events, 1)
+	require.EqualValues(8983850, sub.NextBlock())
+
+	require.NoError(sub.Poll(s.Ctx, handler))
+	require.Len(events, 3)
+	require.True(events[1].Removed)
+	require.Equal(events[0].BlockHash, events[1].BlockHash)
+	require.False(events[2].Removed)
+	require.Equal("0x0000000000000000000000000000000000000000000000000000000000000002", events[2].BlockHash)
+	require.False(events[0].Removed)
+	require.EqualValues(8983850, sub.NextBlock())
+	require.Equal(8, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestWatchEvents() {
+	require := s.Require()
+	server1, close1 := test.MockJSONRPC(&s.Suite, []string{`"0x891528"`, erc20TransferBlock, `[` + erc20TransferLog + `]`})
+	defer close1()
+	server2, close2 := test.MockJSONRPC(&s.Suite, errors.New(`{"message": "unavailable", "code": 123}`))
+	defer close2()
+	client1, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.ETH, URL: server1.URL})
+	client2, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.MATIC, URL: server2.URL})
+	registry := NewEventRegistry(ERC20)
+
+	// the latest block of a chain can't be fetched
+	err := WatchEvents(s.Ctx, []*EventSubscription{NewEventSubscription(client2, registry)}, func(event *Event) error {
+		return nil
+	})
+	require.ErrorContains(err, "unavailable")
+
+	// the handler stops watching
+	sub := NewEventSubscription(client1, registry)
+	sub.FromBlock = 8983800
+	errStop := errors.New("stop")
+	err = WatchEvents(s.Ctx, []*EventSubscription{sub}, func(event *Event) error {
+		require.Equal(xc.ETH, event.Chain)
+		return errStop
+	})
+	require.Equal(errStop, err)
+
+	require.NoError(WatchEvents(s.Ctx, nil, nil))
+}
@@ -0,0 +1,262 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// LidoContracts are the Lido stETH and withdrawal queue contracts of a chain
+type LidoContracts struct {
+	StETH           string
+	WithdrawalQueue string
+}
+
+// LidoContractsByChainID lists Lido deployments by EVM chain id
+var LidoContractsByChainID = map[int64]LidoContracts{
+	1: {
+		StETH:           "0xae7ab96520DE3A18E5e111B5EaAb095312D7fE84",
+		WithdrawalQueue: "0x889edC2eDab5f40e902b864aD4d7AdE8E412F9B1",
+	},
+	5: {
+		StETH:           "0x1643E812aE58766192Cf7D2Cf9567dF2C37e9B7F",
+		WithdrawalQueue: "0xCF117961421cA9e546cD7f50bC73abCdB3039533",
+	},
+}
+
+// Lido withdrawal requests must be between 100 wei and 1000 stETH
+var lidoMinWithdrawal = big.NewInt(100)
+var lidoMaxWithdrawal = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+
+const lidoABIJSON = `[
+{"name":"submit","type":"function","stateMutability":"payable","inputs":[{"name":"_referral","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+{"name":"balanceOf","type":"function","stateMutability":"view","inputs":[{"name":"_account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+{"name":"approve","type":"function","stateMutability":"nonpayable","inputs":[{"name":"_spender","type":"address"},{"name":"_amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+{"name":"requestWithdrawals","type":"function","stateMutability":"nonpayable","inputs":[{"name":"_amounts","type":"uint256[]"},{"name":"_owner","type":"address"}],"outputs":[{"name":"requestIds","type":"uint256[]"}]},
+{"name":"claimWithdrawal","type":"function","stateMutability":"nonpayable","inputs":[{"name":"_requestId","type":"uint256"}],"outputs":[]},
+{"name":"getWithdrawalRequests","type":"function","stateMutability":"view","inputs":[{"name":"_owner","type":"address"}],"outputs":[{"name":"requestsIds","type":"uint256[]"}]},
+{"name":"getWithdrawalStatus","type":"function","stateMutability":"view","inputs":[{"name":"_requestIds","type":"uint256[]"}],"outputs":[{"name":"statuses","type":"tuple[]","components":[{"name":"amountOfStETH","type":"uint256"},{"name":"amountOfShares","type":"uint256"},{"name":"owner","type":"address"},{"name":"timestamp","type":"uint256"},{"name":"isFinalized","type":"bool"},{"name":"isClaimed","type":"bool"}]}]}
+]`
+
+// LidoABI is the subset of Lido stETH and WithdrawalQueueERC721 used by crosschain
+var LidoABI abi.ABI
+
+func init() {
+	var err error
+	LidoABI, err = abi.JSON(strings.NewReader(lidoABIJSON))
+	if err != nil {
+		panic(err)
+	}
+}
+
+type lidoWithdrawalStatus struct {
+	AmountOfStETH  *big.Int
+	AmountOfShares *big.Int
+	Owner          common.Address
+	Timestamp      *big.Int
+	IsFinalized    bool
+	IsClaimed      bool
+}
+
+func getLidoContracts(asset xc.ITask) (LidoContracts, error) {
+	chainID := asset.GetNativeAsset().ChainID
+	contracts, ok := LidoContractsByChainID[chainID]
+	if !ok {
+		return LidoContracts{}, fmt.Errorf("lido is not supported on chain id %d", chainID)
+	}
+	return contracts, nil
+}
+
+// splitLidoWithdrawal splits amount into withdrawal requests within Lido limits
+func splitLidoWithdrawal(amount xc.AmountBlockchain) ([]*big.Int, error) {
+	remaining := new(big.Int).Set(amount.Int())
+	if remaining.Cmp(lidoMinWithdrawal) < 0 {
+		return nil, fmt.Errorf("lido withdrawal must be at least %s wei", lidoMinWithdrawal)
+	}
+	amounts := []*big.Int{}
+	for remaining.Cmp(lidoMaxWithdrawal) > 0 {
+		amounts = append(amounts, new(big.Int).Set(lidoMaxWithdrawal))
+		remaining.Sub(remaining, lidoMaxWithdrawal)
+	}
+	if remaining.Cmp(lidoMinWithdrawal) < 0 {
+		// move the dust into the previous request
+		last := amounts[len(amounts)-1]
+		last.Sub(last, lidoMinWithdrawal)
+		remaining.Add(remaining, lidoMinWithdrawal)
+	}
+	return append(amounts, remaining), nil
+}
+
+// lidoTxInput copies input, an evm TxInput or its pointer, with gasLimit as default gas limit
+// so that the default doesn't leak into the caller's input
+func lidoTxInput(input xc.TxInput, gasLimit uint64) (*TxInput, error) {
+	var txInput TxInput
+	switch typed := input.(type) {
+	case TxInput:
+		txInput = typed
+	case *TxInput:
+		txInput = *typed
+	default:
+		return nil, errors.New("xc.TxInput is not from an evm chain")
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = gasLimit
+	}
+	return &txInput, nil
+}
+
+// NewLiquidStake submits ETH to Lido in exchange for stETH
+func (txBuilder TxBuilder) NewLiquidStake(from xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	txInput, err := lidoTxInput(input, 150_000)
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := getLidoContracts(txBuilder.Asset)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := LidoABI.Pack("submit", common.Address{})
+	if err != nil {
+		return nil, err
+	}
+	return txBuilder.buildEvmTxWithPayload(xc.Address(contracts.StETH), amount, payload, txInput)
+}
+
+// NewLiquidUnstakeApproval approves the Lido withdrawal queue to spend amount of stETH, required before NewLiquidUnstake
+func (txBuilder TxBuilder) NewLiquidUnstakeApproval(from xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	txInput, err := lidoTxInput(input, 80_000)
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := getLidoContracts(txBuilder.Asset)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := LidoABI.Pack("approve", common.HexToAddress(contracts.WithdrawalQueue), amount.Int())
+	if err != nil {
+		return nil, err
+	}
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	return txBuilder.buildEvmTxWithPayload(xc.Address(contracts.StETH), zero, payload, txInput)
+}
+
+// NewLiquidUnstake requests a withdrawal of amount of stETH from the Lido withdrawal queue
+// Amounts above the per-request limit are split into several requests
+func (txBuilder TxBuilder) NewLiquidUnstake(from xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	contracts, err := getLidoContracts(txBuilder.Asset)
+	if err != nil {
+		return nil, err
+	}
+	amounts, err := splitLidoWithdrawal(amount)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := HexToAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := LidoABI.Pack("requestWithdrawals", amounts, owner)
+	if err != nil {
+		return nil, err
+	}
+	txInput, err := lidoTxInput(input, 250_000+100_000*uint64(len(amounts)-1))
+	if err != nil {
+		return nil, err
+	}
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	return txBuilder.buildEvmTxWithPayload(xc.Address(contracts.WithdrawalQueue), zero, payload, txInput)
+}
+
+// NewLidoClaimWithdrawal claims ETH of a finalized Lido withdrawal request
+func (txBuilder TxBuilder) NewLidoClaimWithdrawal(from xc.Address, requestID *big.Int, input xc.TxInput) (xc.Tx, error) {
+	txInput, err := lidoTxInput(input, 150_000)
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := getLidoContracts(txBuilder.Asset)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := LidoABI.Pack("claimWithdrawal", requestID)
+	if err != nil {
+		return nil, err
+	}
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	return txBuilder.buildEvmTxWithPayload(xc.Address(contracts.WithdrawalQueue), zero, payload, txInput)
+}
+
+func (client *Client) callLido(ctx context.Context, contract string, method string, args ...interface{}) ([]interface{}, error) {
+	payload, err := LidoABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	to := common.HexToAddress(contract)
+	res, err := client.EthClient.CallContract(ctx, ethereum.CallMsg{To: &to, Data: payload}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %v", method, err)
+	}
+	return LidoABI.Unpack(method, res)
+}
+
+// FetchStakedBalance fetches the Lido stETH balance and pending withdrawals of an address
+func (client *Client) FetchStakedBalance(ctx context.Context, address xc.Address) ([]xc.StakedBalance, error) {
+	contracts, err := getLidoContracts(client.Asset)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := HexToAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.callLido(ctx, contracts.StETH, "balanceOf", owner)
+	if err != nil {
+		return nil, err
+	}
+	balance := res[0].(*big.Int)
+
+	pending := new(big.Int)
+	res, err = client.callLido(ctx, contracts.WithdrawalQueue, "getWithdrawalRequests", owner)
+	if err != nil {
+		return nil, err
+	}
+	requestIDs := res[0].([]*big.Int)
+	if len(requestIDs) > 0 {
+		res, err = client.callLido(ctx, contracts.WithdrawalQueue, "getWithdrawalStatus", requestIDs)
+		if err != nil {
+			return nil, err
+		}
+		statuses := []lidoWithdrawalStatus{}
+		err = LidoABI.Methods["getWithdrawalStatus"].Outputs.Copy(&statuses, res)
+		if err != nil {
+			return nil, err
+		}
+		for _, status := range statuses {
+			if !status.IsClaimed {
+				pending.Add(pending, status.AmountOfStETH)
+			}
+		}
+	}
+	if balance.Sign() == 0 && pending.Sign() == 0 {
+		return []xc.StakedBalance{}, nil
+	}
+
+	// stETH rebases, so it's redeemable 1:1 for ETH
+	return []xc.StakedBalance{
+		{
+			Protocol:   xc.StakingProtocolLido,
+			Address:    address,
+			Contract:   xc.ContractAddress(contracts.StETH),
+			Amount:     xc.AmountBlockchain(*balance),
+			Underlying: xc.AmountBlockchain(*balance),
+			Pending:    xc.AmountBlockchain(*pending),
+		},
+	}, nil
+}
@@ -0,0 +1,133 @@
+package evm
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestSplitLidoWithdrawal() {
+	require := s.Require()
+	eth := big.NewInt(1e18)
+
+	amounts, err := splitLidoWithdrawal(xc.NewAmountBlockchainFromStr("5000000000000000000"))
+	require.Nil(err)
+	require.Equal([]*big.Int{new(big.Int).Mul(big.NewInt(5), eth)}, amounts)
+
+	amounts, err = splitLidoWithdrawal(xc.NewAmountBlockchainFromStr("2500000000000000000000"))
+	require.Nil(err)
+	require.Len(amounts, 3)
+	require.Equal(lidoMaxWithdrawal, amounts[0])
+	require.Equal(new(big.Int).Mul(big.NewInt(500), eth), amounts[2])
+
+	// dust is merged with the previous request
+	amounts, err = splitLidoWithdrawal(xc.NewAmountBlockchainFromStr("1000000000000000000010"))
+	require.Nil(err)
+	require.Len(amounts, 2)
+	require.Equal("999999999999999999900", amounts[0].String())
+	require.Equal("110", amounts[1].String())
+
+	_, err = splitLidoWithdrawal(xc.NewAmountBlockchainFromUint64(99))
+	require.ErrorContains(err, "lido withdrawal must be at least 100 wei")
+}
+
+func (s *CrosschainTestSuite) TestNewLiquidStake() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.ETH, ChainID: 1})
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+
+	tx, err := builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, xc.NewAmountBlockchainFromStr("1000000000000000000"), &TxInput{})
+	require.Nil(err)
+	ethTx := tx.(*Tx).EthTx
+	require.Equal(common.HexToAddress(LidoContractsByChainID[1].StETH), *ethTx.To())
+	require.Equal("1000000000000000000", ethTx.Value().String())
+	require.Equal("a1903eab", hex.EncodeToString(ethTx.Data()[:4]))
+
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.MATIC, ChainID: 137})
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, xc.NewAmountBlockchainFromUint64(1), &TxInput{})
+	require.ErrorContains(err, "lido is not supported on chain id 137")
+}
+
+func (s *CrosschainTestSuite) TestNewLiquidUnstake() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.ETH, ChainID: 1})
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	amount := xc.NewAmountBlockchainFromStr("1500000000000000000000")
+
+	tx, err := builder.(TxBuilder).NewLiquidUnstakeApproval(from, amount, &TxInput{})
+	require.Nil(err)
+	ethTx := tx.(*Tx).EthTx
+	require.Equal(common.HexToAddress(LidoContractsByChainID[1].StETH), *ethTx.To())
+	require.Equal("095ea7b3", hex.EncodeToString(ethTx.Data()[:4]))
+
+	input := &TxInput{}
+	tx, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, amount, input)
+	require.Nil(err)
+	ethTx = tx.(*Tx).EthTx
+	require.Equal(common.HexToAddress(LidoContractsByChainID[1].WithdrawalQueue), *ethTx.To())
+	require.Equal("0", ethTx.Value().String())
+	require.Equal(uint64(350_000), ethTx.Gas())
+	// the default gas limit isn't set on the caller's input
+	require.Equal(uint64(0), input.GasLimit)
+	args, err := LidoABI.Methods["requestWithdrawals"].Inputs.Unpack(ethTx.Data()[4:])
+	require.Nil(err)
+	require.Len(args[0].([]*big.Int), 2)
+	require.Equal(common.HexToAddress(string(from)), args[1].(common.Address))
+
+	tx, err = builder.(TxBuilder).NewLidoClaimWithdrawal(from, big.NewInt(42), &TxInput{})
+	require.Nil(err)
+	require.Equal("f8444436", hex.EncodeToString(tx.(*Tx).EthTx.Data()[:4]))
+
+	// the gas limit of the caller is kept, and inputs are accepted by value
+	tx, err = builder.(TxBuilder).NewLidoClaimWithdrawal(from, big.NewInt(42), TxInput{GasLimit: 200_000})
+	require.Nil(err)
+	require.Equal(uint64(200_000), tx.(*Tx).EthTx.Gas())
+
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, amount, &struct{ xc.TxInputEnvelope }{})
+	require.ErrorContains(err, "not from an evm chain")
+}
+
+func (s *CrosschainTestSuite) TestFetchStakedBalance() {
+	require := s.Require()
+
+	balance, _ := LidoABI.Methods["balanceOf"].Outputs.Pack(big.NewInt(3e18))
+	requests, _ := LidoABI.Methods["getWithdrawalRequests"].Outputs.Pack([]*big.Int{big.NewInt(1), big.NewInt(2)})
+	statuses, err := LidoABI.Methods["getWithdrawalStatus"].Outputs.Pack([]lidoWithdrawalStatus{
+		{AmountOfStETH: big.NewInt(1e18), AmountOfShares: big.NewInt(1), Timestamp: big.NewInt(1), IsFinalized: true, IsClaimed: true},
+		{AmountOfStETH: big.NewInt(2e18), AmountOfShares: big.NewInt(1), Timestamp: big.NewInt(1), IsFinalized: false, IsClaimed: false},
+	})
+	require.Nil(err)
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`"0x` + hex.EncodeToString(balance) + `"`,
+		`"0x` + hex.EncodeToString(requests) + `"`,
+		`"0x` + hex.EncodeToString(statuses) + `"`,
+	})
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH, URL: server.URL, ChainID: 1})
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	positions, err := client.FetchStakedBalance(s.Ctx, from)
+	require.Nil(err)
+	require.Len(positions, 1)
+	require.Equal(xc.StakingProtocolLido, positions[0].Protocol)
+	require.Equal("3000000000000000000", positions[0].Amount.String())
+	require.Equal("3000000000000000000", positions[0].Underlying.String())
+	require.Equal("2000000000000000000", positions[0].Pending.String())
+
+	// no position
+	zero, _ := LidoABI.Methods["balanceOf"].Outputs.Pack(big.NewInt(0))
+	none, _ := LidoABI.Methods["getWithdrawalRequests"].Outputs.Pack([]*big.Int{})
+	server, close = test.MockJSONRPC(&s.Suite, []string{
+		`"0x` + hex.EncodeToString(zero) + `"`,
+		`"0x` + hex.EncodeToString(none) + `"`,
+	})
+	defer close()
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH, URL: server.URL, ChainID: 1})
+	positions, err = client.FetchStakedBalance(s.Ctx, from)
+	require.Nil(err)
+	require.Len(positions, 0)
+}
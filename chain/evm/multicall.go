@@ -0,0 +1,217 @@
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// maxMulticallDepth limits the nesting of batches, e.g. a Safe tx delegating to MultiSend
+const maxMulticallDepth = 4
+
+const multicallABIJSON = `[
+{"name":"aggregate","type":"function","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"callData","type":"bytes"}]}],"outputs":[]},
+{"name":"blockAndAggregate","type":"function","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"callData","type":"bytes"}]}],"outputs":[]},
+{"name":"tryAggregate","type":"function","stateMutability":"payable","inputs":[{"name":"requireSuccess","type":"bool"},{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"callData","type":"bytes"}]}],"outputs":[]},
+{"name":"tryBlockAndAggregate","type":"function","stateMutability":"payable","inputs":[{"name":"requireSuccess","type":"bool"},{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"callData","type":"bytes"}]}],"outputs":[]},
+{"name":"aggregate3","type":"function","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[]},
+{"name":"aggregate3Value","type":"function","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"value","type":"uint256"},{"name":"callData","type":"bytes"}]}],"outputs":[]},
+{"name":"multicall","type":"function","stateMutability":"payable","inputs":[{"name":"data","type":"bytes[]"}],"outputs":[]},
+{"name":"multicall","type":"function","stateMutability":"payable","inputs":[{"name":"deadline","type":"uint256"},{"name":"data","type":"bytes[]"}],"outputs":[]},
+{"name":"multiSend","type":"function","stateMutability":"payable","inputs":[{"name":"transactions","type":"bytes"}],"outputs":[]},
+{"name":"execTransaction","type":"function","stateMutability":"payable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"outputs":[]}
+]`
+
+// MulticallABI is the subset of batching contracts parsed by crosschain: Multicall3, the multicall of
+// routers (Uniswap and forks), and the MultiSend and execTransaction of Safe
+var MulticallABI abi.ABI
+
+func init() {
+	var err error
+	MulticallABI, err = abi.JSON(strings.NewReader(multicallABIJSON))
+	if err != nil {
+		panic(err)
+	}
+}
+
+type multicallCall struct {
+	Target   common.Address
+	CallData []byte
+}
+
+type multicallCall3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicallCall3Value struct {
+	Target       common.Address
+	AllowFailure bool
+	Value        *big.Int
+	CallData     []byte
+}
+
+// safeDelegateCall is the operation of Safe txs and MultiSend txs executed with delegatecall
+const safeDelegateCall = 1
+
+// multicallLeg is a transfer of a batch: of a token if contract is set, else of the native asset
+type multicallLeg struct {
+	from     common.Address
+	to       common.Address
+	contract common.Address
+	amount   *big.Int
+}
+
+// ParseMulticallTransferTx parses the tx payload as a batch of calls, e.g. Multicall3.aggregate3 or Safe
+// MultiSend, into a transfer per call sending a token or the native asset
+// Legs are parsed from the calls, not their execution: calls of reverted txs or failed calls allowed to fail
+// are reported too
+func (tx Tx) ParseMulticallTransferTx(nativeAsset xc.NativeAsset) (parsedTxInfo, error) {
+	res := parsedTxInfo{}
+	if tx.EthTx == nil || tx.EthTx.To() == nil || len(tx.EthTx.Data()) < 4 {
+		return res, errors.New("payload is not a multicall")
+	}
+	if _, err := MulticallABI.MethodById(tx.EthTx.Data()[:4]); err != nil {
+		return res, errors.New("payload is not a multicall")
+	}
+	from, err := HexToAddress(tx.From())
+	if err != nil {
+		return res, err
+	}
+	to := *tx.EthTx.To()
+	// the value of the tx is sent to the batching contract, legs send their own value
+	legs := parseCall(from, to, to, nil, tx.EthTx.Data(), 0)
+	if len(legs) == 0 {
+		return res, errors.New("multicall has no transfers")
+	}
+
+	for _, leg := range legs {
+		contract := xc.ContractAddress("")
+		if leg.contract != (common.Address{}) {
+			contract = xc.ContractAddress(leg.contract.String())
+		}
+		res.Sources = append(res.Sources, &xc.TxInfoEndpoint{
+			Address:         xc.Address(leg.from.String()),
+			ContractAddress: contract,
+			Amount:          xc.AmountBlockchain(*leg.amount),
+			NativeAsset:     nativeAsset,
+		})
+		res.Destinations = append(res.Destinations, &xc.TxInfoEndpoint{
+			Address:         xc.Address(leg.to.String()),
+			ContractAddress: contract,
+			Amount:          xc.AmountBlockchain(*leg.amount),
+			NativeAsset:     nativeAsset,
+		})
+	}
+	return res, nil
+}
+
+// parseCall returns the transfers of a call from sender to target, running as self: target for a call,
+// sender for a delegatecall
+// Batches are parsed recursively, other calls are transfers of their value and ERC20 transfers
+func parseCall(sender common.Address, self common.Address, target common.Address, value *big.Int, data []byte, depth int) []multicallLeg {
+	legs := []multicallLeg{}
+	if depth > maxMulticallDepth {
+		return legs
+	}
+	if len(data) >= 4 {
+		if method, err := MulticallABI.MethodById(data[:4]); err == nil {
+			return parseBatch(method, sender, self, data[4:], depth)
+		}
+	}
+
+	if value != nil && value.Sign() > 0 {
+		legs = append(legs, multicallLeg{from: sender, to: target, amount: value})
+	}
+	if len(data) < 4 {
+		return legs
+	}
+	method, err := ERC20.MethodById(data[:4])
+	if err != nil {
+		return legs
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return legs
+	}
+	switch method.RawName {
+	case "transfer":
+		legs = append(legs, multicallLeg{from: sender, to: args[0].(common.Address), contract: target, amount: args[1].(*big.Int)})
+	case "transferFrom":
+		legs = append(legs, multicallLeg{from: args[0].(common.Address), to: args[1].(common.Address), contract: target, amount: args[2].(*big.Int)})
+	}
+	return legs
+}
+
+// parseBatch returns the transfers of the calls of a batch run as self
+func parseBatch(method *abi.Method, sender common.Address, self common.Address, data []byte, depth int) []multicallLeg {
+	legs := []multicallLeg{}
+	args, err := method.Inputs.Unpack(data)
+	if err != nil {
+		return legs
+	}
+	switch method.RawName {
+	case "aggregate", "blockAndAggregate", "tryAggregate", "tryBlockAndAggregate":
+		// the calls are the last argument, after requireSuccess
+		calls := []multicallCall{}
+		last := len(method.Inputs) - 1
+		if err := method.Inputs[last:].Copy(&calls, args[last:]); err != nil {
+			return legs
+		}
+		for _, call := range calls {
+			legs = append(legs, parseCall(self, call.Target, call.Target, nil, call.CallData, depth+1)...)
+		}
+	case "aggregate3":
+		calls := []multicallCall3{}
+		if err := method.Inputs.Copy(&calls, args); err != nil {
+			return legs
+		}
+		for _, call := range calls {
+			legs = append(legs, parseCall(self, call.Target, call.Target, nil, call.CallData, depth+1)...)
+		}
+	case "aggregate3Value":
+		calls := []multicallCall3Value{}
+		if err := method.Inputs.Copy(&calls, args); err != nil {
+			return legs
+		}
+		for _, call := range calls {
+			legs = append(legs, parseCall(self, call.Target, call.Target, call.Value, call.CallData, depth+1)...)
+		}
+	case "multicall":
+		// delegatecalls of the contract to itself, keeping the sender
+		for _, call := range args[len(args)-1].([][]byte) {
+			legs = append(legs, parseCall(sender, self, self, nil, call, depth+1)...)
+		}
+	case "multiSend":
+		// packed calls: operation (1 byte), to (20 bytes), value (32 bytes), data length (32 bytes), data
+		transactions := args[0].([]byte)
+		for len(transactions) >= 85 {
+			operation := transactions[0]
+			to := common.BytesToAddress(transactions[1:21])
+			value := new(big.Int).SetBytes(transactions[21:53])
+			length := new(big.Int).SetBytes(transactions[53:85])
+			if !length.IsUint64() || length.Uint64() > uint64(len(transactions)-85) {
+				break
+			}
+			end := 85 + int(length.Uint64())
+			legs = append(legs, parseSafeCall(operation, sender, self, to, value, transactions[85:end], depth)...)
+			transactions = transactions[end:]
+		}
+	case "execTransaction":
+		legs = append(legs, parseSafeCall(args[3].(uint8), sender, self, args[0].(common.Address), args[1].(*big.Int), args[2].([]byte), depth)...)
+	}
+	return legs
+}
+
+// parseSafeCall returns the transfers of a call or delegatecall of a Safe, or of MultiSend run by a Safe
+func parseSafeCall(operation uint8, sender common.Address, self common.Address, to common.Address, value *big.Int, data []byte, depth int) []multicallLeg {
+	if operation == safeDelegateCall {
+		return parseCall(sender, self, to, nil, data, depth+1)
+	}
+	return parseCall(self, to, to, value, data, depth+1)
+}
@@ -0,0 +1,98 @@
+package evm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestParseMulticallTransferTx() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	multicall := common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	safe := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	multiSend := common.HexToAddress("0x40A2aCCbd92BCA938b02010E17A5b8929b49130D")
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	alice := common.HexToAddress("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	bob := common.HexToAddress("0x24b3A3F3B8e2D2eC7E44A1c8FBbA0C8d2e7bA0bd")
+
+	transfer, _ := ERC20.Pack("transfer", bob, big.NewInt(7))
+	transferFrom, _ := ERC20.Pack("transferFrom", sender, bob, big.NewInt(5))
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	newTx := func(to common.Address, value int64, data []byte) *Tx {
+		ethTx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 500_000, To: &to, Value: big.NewInt(value), Data: data})
+		require.NoError(err)
+		return &Tx{EthTx: ethTx, Signer: signer}
+	}
+
+	// Multicall3: a native transfer and a token transfer from the sender
+	data, err := MulticallABI.Pack("aggregate3Value", []multicallCall3Value{
+		{Target: alice, Value: big.NewInt(1000), CallData: []byte{}},
+		{Target: usdc, Value: big.NewInt(0), CallData: transferFrom},
+	})
+	require.NoError(err)
+	tx := newTx(multicall, 1000, data)
+	receipt := &types.Receipt{Status: 1}
+	info := parseTxInfo(xc.TxInfo{}, tx.EthTx, receipt, 0, big.NewInt(1), xc.ETH)
+	require.Len(info.Destinations, 2)
+	require.Equal(xc.Address(multicall.String()), info.Sources[0].Address)
+	require.Equal(xc.Address(alice.String()), info.Destinations[0].Address)
+	require.Equal(xc.ContractAddress(""), info.Destinations[0].ContractAddress)
+	require.Equal("1000", info.Destinations[0].Amount.String())
+	require.Equal(xc.Address(sender.String()), info.Sources[1].Address)
+	require.Equal(xc.Address(bob.String()), info.Destinations[1].Address)
+	require.Equal(xc.ContractAddress(usdc.String()), info.Destinations[1].ContractAddress)
+	require.Equal("5", info.Destinations[1].Amount.String())
+	require.Equal(xc.ETH, info.Destinations[1].NativeAsset)
+
+	// Safe: a delegatecall to MultiSend, sending from the Safe
+	packed := []byte{}
+	for _, call := range []struct {
+		to    common.Address
+		value int64
+		data  []byte
+	}{{alice, 3, nil}, {usdc, 0, transfer}} {
+		packed = append(packed, 0)
+		packed = append(packed, call.to.Bytes()...)
+		packed = append(packed, common.LeftPadBytes(big.NewInt(call.value).Bytes(), 32)...)
+		packed = append(packed, common.LeftPadBytes(big.NewInt(int64(len(call.data))).Bytes(), 32)...)
+		packed = append(packed, call.data...)
+	}
+	multiSendData, err := MulticallABI.Pack("multiSend", packed)
+	require.NoError(err)
+	data, err = MulticallABI.Pack("execTransaction", multiSend, big.NewInt(0), multiSendData, uint8(safeDelegateCall),
+		big.NewInt(0), big.NewInt(0), big.NewInt(0), common.Address{}, common.Address{}, []byte{})
+	require.NoError(err)
+	parsed, err := newTx(safe, 0, data).ParseMulticallTransferTx(xc.ETH)
+	require.NoError(err)
+	require.Len(parsed.Destinations, 2)
+	require.Equal(xc.Address(safe.String()), parsed.Sources[0].Address)
+	require.Equal(xc.Address(alice.String()), parsed.Destinations[0].Address)
+	require.Equal("3", parsed.Destinations[0].Amount.String())
+	require.Equal(xc.Address(safe.String()), parsed.Sources[1].Address)
+	require.Equal(xc.Address(bob.String()), parsed.Destinations[1].Address)
+	require.Equal(xc.ContractAddress(usdc.String()), parsed.Destinations[1].ContractAddress)
+
+	// router multicall: delegatecalls to itself keep the sender
+	data, err = MulticallABI.Pack("multicall", [][]byte{transfer})
+	require.NoError(err)
+	parsed, err = newTx(usdc, 0, data).ParseMulticallTransferTx(xc.ETH)
+	require.NoError(err)
+	require.Len(parsed.Destinations, 1)
+	require.Equal(xc.Address(sender.String()), parsed.Sources[0].Address)
+	require.Equal(xc.ContractAddress(usdc.String()), parsed.Destinations[0].ContractAddress)
+
+	// batches without transfers and other calls aren't multicall transfers
+	balanceOf, _ := ERC20.Pack("balanceOf", alice)
+	data, _ = MulticallABI.Pack("aggregate", []multicallCall{{Target: usdc, CallData: balanceOf}})
+	_, err = newTx(multicall, 0, data).ParseMulticallTransferTx(xc.ETH)
+	require.EqualError(err, "multicall has no transfers")
+	_, err = newTx(usdc, 0, transfer).ParseMulticallTransferTx(xc.ETH)
+	require.EqualError(err, "payload is not a multicall")
+	_, err = newTx(multicall, 0, MulticallABI.Methods["aggregate"].ID).ParseMulticallTransferTx(xc.ETH)
+	require.EqualError(err, "multicall has no transfers")
+}
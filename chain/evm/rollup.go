@@ -0,0 +1,78 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+const gasPriceOracleABIJSON = `[
+{"name":"getL1Fee","type":"function","stateMutability":"view","inputs":[{"name":"_data","type":"bytes"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+// GasPriceOracleABI is the subset of the GasPriceOracle predeploy of OP Stack chains used by crosschain
+var GasPriceOracleABI abi.ABI
+
+func init() {
+	var err error
+	GasPriceOracleABI, err = abi.JSON(strings.NewReader(gasPriceOracleABIJSON))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// chargesL1Fee returns true for the OP Stack chains, charging the L1 data fee of txs on top of their gas
+// Orbit chains include the L1 cost in the gas of txs, so their fees need no L1 fee
+func (client *Client) chargesL1Fee() bool {
+	return client.Asset.GetNativeAsset().IsRollup(xc.RollupOPStack)
+}
+
+// EstimateL1Fee estimates the L1 data fee of tx on an OP Stack chain with the gas price oracle, zero on other chains
+func (client *Client) EstimateL1Fee(ctx context.Context, ethTx *types.Transaction) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	if !client.chargesL1Fee() {
+		return zero, nil
+	}
+	// the fee of the unsigned tx, the oracle accounts for the signature
+	unsigned, err := ethTx.MarshalBinary()
+	if err != nil {
+		return zero, err
+	}
+	data, err := GasPriceOracleABI.Pack("getL1Fee", unsigned)
+	if err != nil {
+		return zero, err
+	}
+	oracle := common.HexToAddress(client.Asset.GetNativeAsset().GetRollup().GasOracle)
+	res, err := client.EthClient.CallContract(ctx, ethereum.CallMsg{To: &oracle, Data: data}, nil)
+	if err != nil {
+		return zero, fmt.Errorf("estimating L1 fee: %v", err)
+	}
+	values, err := GasPriceOracleABI.Unpack("getL1Fee", res)
+	if err != nil {
+		return zero, fmt.Errorf("estimating L1 fee: %v", err)
+	}
+	return xc.AmountBlockchain(*values[0].(*big.Int)), nil
+}
+
+// fetchL1Fee returns the L1 data fee paid by a tx on an OP Stack chain, reported by its receipt
+func (client *Client) fetchL1Fee(ctx context.Context, txHash common.Hash) (xc.AmountBlockchain, error) {
+	var receipt struct {
+		L1Fee *hexutil.Big `json:"l1Fee"`
+	}
+	if err := client.RpcClient.CallContext(ctx, &receipt, "eth_getTransactionReceipt", txHash); err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), fmt.Errorf("fetching L1 fee: %v", err)
+	}
+	if receipt.L1Fee == nil {
+		// deposit txs and txs of chains without L1 fees
+		return xc.NewAmountBlockchainFromUint64(0), nil
+	}
+	return xc.AmountBlockchain(*receipt.L1Fee.ToInt()), nil
+}
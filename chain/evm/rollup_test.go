@@ -0,0 +1,82 @@
+package evm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestEstimateFeeL1Fee() {
+	require := s.Require()
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	to := common.HexToAddress("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		// eth_estimateGas
+		`"0x5208"`,
+		// eth_call of getL1Fee: 1000 gwei
+		`"0x000000000000000000000000000000000000000000000000000000e8d4a51000"`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.OptETH, ChainID: 10, URL: server.URL})
+	require.True(client.chargesL1Fee())
+
+	tx := &Tx{EthTx: types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(10), To: &to, GasFeeCap: big.NewInt(1_000_000), GasTipCap: big.NewInt(1)})}
+	fee, err := client.EstimateFee(s.Ctx, from, tx)
+	require.NoError(err)
+	// 21000 gas at 1000000 wei, and the L1 fee
+	require.Equal("1021000000000", fee.String())
+	require.Equal(2, server.Counter)
+
+	// Orbit chains include the L1 cost in the gas
+	server, close = test.MockJSONRPC(&s.Suite, `"0x5208"`)
+	defer close()
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ArbETH, ChainID: 42161, URL: server.URL})
+	require.False(client.chargesL1Fee())
+	fee, err = client.EstimateFee(s.Ctx, from, tx)
+	require.NoError(err)
+	require.Equal("21000000000", fee.String())
+	require.Equal(1, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestFetchL1Fee() {
+	require := s.Require()
+	txHash := common.HexToHash("0x5e3a3a0b0e8e6d2b67bbd4d6ab0a3bd8e8b6b2d3c2d1f0e9a8b7c6d5e4f3a2b1")
+	server, close := test.MockJSONRPC(&s.Suite, `{"status":"0x1","l1Fee":"0x3e8"}`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.OptETH, ChainID: 10, URL: server.URL})
+	l1Fee, err := client.fetchL1Fee(s.Ctx, txHash)
+	require.NoError(err)
+	require.Equal("1000", l1Fee.String())
+
+	// deposit txs
+	server, close = test.MockJSONRPC(&s.Suite, `{"status":"0x1"}`)
+	defer close()
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.OptETH, ChainID: 10, URL: server.URL})
+	l1Fee, err = client.fetchL1Fee(s.Ctx, txHash)
+	require.NoError(err)
+	require.Equal("0", l1Fee.String())
+}
+
+func (s *CrosschainTestSuite) TestSubmitTxSequencer() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	signer := types.LatestSignerForChainID(big.NewInt(10))
+	ethTx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(10), Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5)})
+	tx := &Tx{EthTx: ethTx, Signer: signer}
+
+	server, close := test.MockJSONRPC(&s.Suite, `"0x1"`)
+	defer close()
+	sequencer, closeSequencer := test.MockJSONRPC(&s.Suite, `"`+ethTx.Hash().Hex()+`"`)
+	defer closeSequencer()
+	client, err := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.OptETH, ChainID: 10, URL: server.URL, Rollup: xc.RollupConfig{SequencerURL: sequencer.URL}})
+	require.NoError(err)
+	require.NotNil(client.SequencerClient)
+	require.NoError(client.SubmitTx(s.Ctx, tx))
+	require.Equal(0, server.Counter)
+	require.Equal(1, sequencer.Counter)
+}
@@ -2,6 +2,7 @@ package evm
 
 import (
 	"encoding/hex"
+	"errors"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	xc "github.com/jumpcrypto/crosschain"
@@ -33,3 +34,12 @@ func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.Tx
 	signatureRaw, err := crypto.Sign([]byte(data), ecdsaKey)
 	return xc.TxSignature(signatureRaw), err
 }
+
+// DerivePublicKey returns the compressed public key of an EVM private key
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	ecdsaKey, err := crypto.ToECDSA(privateKey)
+	if err != nil {
+		return nil, errors.New("invalid k256 private key")
+	}
+	return xc.PublicKey(crypto.CompressPubkey(&ecdsaKey.PublicKey)), nil
+}
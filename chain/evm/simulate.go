@@ -0,0 +1,180 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+var errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+var panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+// panicReasons are the reasons of the Panic(uint256) codes of Solidity
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to uninitialized function",
+}
+
+// ErrorRegistry decodes the revert data of calls: Error(string) and Panic(uint256) reverts, and the custom errors
+// of registered ABIs
+type ErrorRegistry struct {
+	mu     sync.RWMutex
+	errors map[[4]byte]abi.Error
+}
+
+// NewErrorRegistry creates an ErrorRegistry decoding the custom errors of abis
+func NewErrorRegistry(abis ...abi.ABI) *ErrorRegistry {
+	registry := &ErrorRegistry{
+		errors: map[[4]byte]abi.Error{},
+	}
+	for _, contractABI := range abis {
+		registry.RegisterABI(contractABI)
+	}
+	return registry
+}
+
+// RegisterABI registers the custom errors of an ABI
+func (registry *ErrorRegistry) RegisterABI(contractABI abi.ABI) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, customError := range contractABI.Errors {
+		var selector [4]byte
+		copy(selector[:], customError.ID[:4])
+		registry.errors[selector] = customError
+	}
+}
+
+// RegisterABIJSON registers the custom errors of a JSON ABI
+func (registry *ErrorRegistry) RegisterABIJSON(abiJSON string) error {
+	contractABI, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return fmt.Errorf("invalid abi: %v", err)
+	}
+	registry.RegisterABI(contractABI)
+	return nil
+}
+
+// DecodeRevert returns a human readable reason of revert data, e.g. InsufficientBalance(available=1, required=2)
+// for a custom error, or the selector of an unknown error
+func (registry *ErrorRegistry) DecodeRevert(data []byte) string {
+	if len(data) == 0 {
+		return "execution reverted"
+	}
+	if len(data) < 4 {
+		return fmt.Sprintf("invalid revert data %s", hexutil.Encode(data))
+	}
+	if bytes.Equal(data[:4], errorSelector) {
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			return reason
+		}
+	}
+	if bytes.Equal(data[:4], panicSelector) && len(data) == 4+32 {
+		code := new(big.Int).SetBytes(data[4:])
+		reason, ok := panicReasons[code.Uint64()]
+		if !ok || !code.IsUint64() {
+			reason = "unknown panic"
+		}
+		return fmt.Sprintf("panic: %s (0x%x)", reason, code)
+	}
+
+	var selector [4]byte
+	copy(selector[:], data[:4])
+	registry.mu.RLock()
+	customError, ok := registry.errors[selector]
+	registry.mu.RUnlock()
+	if !ok {
+		return fmt.Sprintf("unknown error %s", hexutil.Encode(data[:4]))
+	}
+	values, err := customError.Inputs.Unpack(data[4:])
+	if err != nil {
+		return fmt.Sprintf("%s: invalid arguments", customError.Name)
+	}
+	args := make([]string, len(values))
+	for i, value := range values {
+		args[i] = fmt.Sprintf("%s=%v", customError.Inputs[i].Name, value)
+	}
+	return fmt.Sprintf("%s(%s)", customError.Name, strings.Join(args, ", "))
+}
+
+// revertData returns the revert data of the error of a call, if any
+func revertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	switch data := dataErr.ErrorData().(type) {
+	case string:
+		decoded, err := hexutil.Decode(data)
+		return decoded, err == nil
+	case []byte:
+		return data, true
+	}
+	return nil, false
+}
+
+var _ xc.ClientSimulator = &Client{}
+
+// SimulateTx executes tx with eth_call at the latest block, decoding the revert reason of a failure with the
+// ErrorRegistry of the client
+func (client *Client) SimulateTx(ctx context.Context, tx xc.Tx) (*xc.SimulationResult, error) {
+	evmTx, ok := tx.(*Tx)
+	if !ok || evmTx.EthTx == nil {
+		return nil, xc.ErrSimulationNotSupported
+	}
+	ethTx := evmTx.EthTx
+	from, err := types.Sender(types.LatestSignerForChainID(ethTx.ChainId()), ethTx)
+	if err != nil {
+		return nil, fmt.Errorf("could not recover sender of '%v': %v", tx.Hash(), err)
+	}
+	msg := callMsg(from, ethTx)
+	msg.Gas = ethTx.Gas()
+	result := &xc.SimulationResult{
+		Chain:   client.Asset.GetNativeAsset().NativeAsset,
+		TxHash:  tx.Hash(),
+		Success: true,
+	}
+	_, err = client.EthClient.CallContract(ctx, msg, nil)
+	if err == nil {
+		return result, nil
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		// not an error of the execution
+		return nil, fmt.Errorf("simulating transaction '%v': %v", tx.Hash(), err)
+	}
+	result.Success = false
+	result.RawError = err.Error()
+	result.FailureReason = err.Error()
+	if data, ok := revertData(err); ok {
+		result.FailureReason = client.errorRegistry().DecodeRevert(data)
+	}
+	return result, nil
+}
+
+func (client *Client) errorRegistry() *ErrorRegistry {
+	if client.ErrorRegistry != nil {
+		return client.ErrorRegistry
+	}
+	return defaultErrorRegistry
+}
+
+var defaultErrorRegistry = NewErrorRegistry()
@@ -0,0 +1,110 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const insufficientBalanceABI = `[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`
+
+func revertString(reason string) []byte {
+	stringType, _ := abi.NewType("string", "", nil)
+	packed, _ := abi.Arguments{{Type: stringType}}.Pack(reason)
+	return append(append([]byte{}, errorSelector...), packed...)
+}
+
+func revertPanic(code uint64) []byte {
+	return append(append([]byte{}, panicSelector...), common.BigToHash(new(big.Int).SetUint64(code)).Bytes()...)
+}
+
+func insufficientBalance(available int64, required int64) []byte {
+	contractABI, _ := abi.JSON(strings.NewReader(insufficientBalanceABI))
+	customError := contractABI.Errors["InsufficientBalance"]
+	packed, _ := customError.Inputs.Pack(big.NewInt(available), big.NewInt(required))
+	return append(append([]byte{}, customError.ID[:4]...), packed...)
+}
+
+func (s *CrosschainTestSuite) TestErrorRegistryDecodeRevert() {
+	require := s.Require()
+	registry := NewErrorRegistry()
+	require.ErrorContains(registry.RegisterABIJSON("{"), "invalid abi")
+
+	require.Equal("execution reverted", registry.DecodeRevert(nil))
+	require.Equal("invalid revert data 0x0102", registry.DecodeRevert([]byte{1, 2}))
+	require.Equal("ERC20: transfer amount exceeds balance", registry.DecodeRevert(revertString("ERC20: transfer amount exceeds balance")))
+	require.Equal("panic: arithmetic underflow or overflow (0x11)", registry.DecodeRevert(revertPanic(0x11)))
+	require.Equal("panic: unknown panic (0x99)", registry.DecodeRevert(revertPanic(0x99)))
+
+	custom := insufficientBalance(1, 2)
+	require.Equal("unknown error "+hexutil.Encode(custom[:4]), registry.DecodeRevert(custom))
+	require.NoError(registry.RegisterABIJSON(insufficientBalanceABI))
+	require.Equal("InsufficientBalance(available=1, required=2)", registry.DecodeRevert(custom))
+	require.Equal("InsufficientBalance: invalid arguments", registry.DecodeRevert(custom[:20]))
+}
+
+func (s *CrosschainTestSuite) TestSimulateTx() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	ethTx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5)})
+	tx := &Tx{EthTx: ethTx, Signer: signer}
+	revert := func(data []byte) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted","data":"%s"}}`, hexutil.Encode(data))
+	}
+	registry := NewErrorRegistry()
+	require.NoError(registry.RegisterABIJSON(insufficientBalanceABI))
+
+	vectors := []struct {
+		response interface{}
+		registry *ErrorRegistry
+		success  bool
+		reason   string
+		err      string
+	}{
+		{`"0x"`, nil, true, "", ""},
+		{revert(revertString("Ownable: caller is not the owner")), nil, false, "Ownable: caller is not the owner", ""},
+		{revert(revertPanic(0x12)), nil, false, "panic: division or modulo by zero (0x12)", ""},
+		{revert(insufficientBalance(5, 10)), nil, false, "unknown error 0xcf479181", ""},
+		{revert(insufficientBalance(5, 10)), registry, false, "InsufficientBalance(available=5, required=10)", ""},
+		{`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"insufficient funds for gas * price + value"}}`, nil, false, "insufficient funds for gas * price + value", ""},
+		// not an execution error
+		{errors.New(`{"message": "rate limited", "code": 429}`), nil, false, "", "rate limited"},
+	}
+	for i, v := range vectors {
+		server, close := test.MockJSONRPC(&s.Suite, v.response)
+		client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH, URL: server.URL})
+		client.ErrorRegistry = v.registry
+		result, err := client.SimulateTx(s.Ctx, tx)
+		close()
+		if v.err != "" {
+			require.ErrorContains(err, v.err)
+			continue
+		}
+		require.NoError(err, i)
+		require.Equal(xc.ETH, result.Chain)
+		require.Equal(tx.Hash(), result.TxHash)
+		require.Equal(v.success, result.Success, i)
+		require.Equal(v.reason, result.FailureReason, i)
+		if !v.success {
+			require.NotEmpty(result.RawError)
+			require.EqualError(result.Err(), fmt.Sprintf("simulation of tx %s failed: %s", tx.Hash(), v.reason))
+		} else {
+			require.NoError(result.Err())
+		}
+	}
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH})
+	_, err := client.SimulateTx(s.Ctx, &Tx{})
+	require.ErrorIs(err, xc.ErrSimulationNotSupported)
+}
@@ -0,0 +1,128 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// stakingGasLimit is the gas limit of staking contract calls, if not set by the input
+const stakingGasLimit = 300_000
+
+var _ xc.TxStakingBuilder = TxBuilder{}
+
+// parseMethodSignature parses a method signature such as "delegate(address,uint256)"
+func parseMethodSignature(signature string) (abi.Method, error) {
+	open := strings.Index(signature, "(")
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return abi.Method{}, fmt.Errorf("invalid method signature '%s'", signature)
+	}
+	name := signature[:open]
+	params := strings.TrimSpace(signature[open+1 : len(signature)-1])
+	inputs := abi.Arguments{}
+	if params != "" {
+		for _, param := range strings.Split(params, ",") {
+			param = strings.TrimSpace(param)
+			if param != "address" && param != "uint256" {
+				return abi.Method{}, fmt.Errorf("unsupported parameter type '%s' in method signature '%s'", param, signature)
+			}
+			paramType, err := abi.NewType(param, "", nil)
+			if err != nil {
+				return abi.Method{}, err
+			}
+			inputs = append(inputs, abi.Argument{Type: paramType})
+		}
+	}
+	return abi.NewMethod(name, name, abi.Function, "", false, false, inputs, nil), nil
+}
+
+// stakingPayload encodes a call of the staking method signature, passing validator to address parameters and amount to uint256 parameters
+func stakingPayload(signature string, validator xc.Address, amount xc.AmountBlockchain) ([]byte, error) {
+	if signature == "" {
+		return nil, errors.New("staking method is not configured")
+	}
+	method, err := parseMethodSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	args := []interface{}{}
+	for _, input := range method.Inputs {
+		if input.Type.T == abi.AddressTy {
+			address, err := HexToAddress(validator)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, address)
+		} else {
+			args = append(args, new(big.Int).Set(amount.Int()))
+		}
+	}
+	data, err := method.Inputs.Pack(args...)
+	if err != nil {
+		return nil, err
+	}
+	return append(method.ID, data...), nil
+}
+
+// stakingContract returns the configured staking contract of the chain
+func (txBuilder TxBuilder) stakingContract() (xc.StakingContract, error) {
+	staking := txBuilder.Asset.GetNativeAsset().StakingContract
+	if staking.Contract == "" {
+		return staking, fmt.Errorf("no staking contract is configured for %s", txBuilder.Asset.GetNativeAsset().NativeAsset)
+	}
+	return staking, nil
+}
+
+func (txBuilder TxBuilder) newStakingCall(signature string, validator xc.Address, amount xc.AmountBlockchain, value xc.AmountBlockchain, txInput *TxInput) (xc.Tx, error) {
+	staking, err := txBuilder.stakingContract()
+	if err != nil {
+		return nil, err
+	}
+	payload, err := stakingPayload(signature, validator, amount)
+	if err != nil {
+		return nil, err
+	}
+	if txInput.GasLimit == 0 {
+		txInput.GasLimit = stakingGasLimit
+	}
+	return txBuilder.buildEvmTxWithPayload(xc.Address(staking.Contract), value, payload, txInput)
+}
+
+// NewDelegate calls the delegate method of the configured staking contract, sending amount if the contract is payable
+func (txBuilder TxBuilder) NewDelegate(from xc.Address, validator xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	staking, err := txBuilder.stakingContract()
+	if err != nil {
+		return nil, err
+	}
+	value := xc.NewAmountBlockchainFromUint64(0)
+	if staking.Payable {
+		value = amount
+	}
+	return txBuilder.newStakingCall(staking.Delegate, validator, amount, value, txInput)
+}
+
+// NewUndelegate calls the undelegate method of the configured staking contract
+func (txBuilder TxBuilder) NewUndelegate(from xc.Address, validator xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	staking, err := txBuilder.stakingContract()
+	if err != nil {
+		return nil, err
+	}
+	return txBuilder.newStakingCall(staking.Undelegate, validator, amount, xc.NewAmountBlockchainFromUint64(0), txInput)
+}
+
+// NewWithdrawRewards calls the withdraw rewards method of the configured staking contract
+func (txBuilder TxBuilder) NewWithdrawRewards(from xc.Address, validator xc.Address, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	staking, err := txBuilder.stakingContract()
+	if err != nil {
+		return nil, err
+	}
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	return txBuilder.newStakingCall(staking.WithdrawRewards, validator, zero, zero, txInput)
+}
@@ -0,0 +1,80 @@
+package evm
+
+import (
+	"encoding/hex"
+
+	"github.com/ethereum/go-ethereum/common"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestStakingPayload() {
+	require := s.Require()
+	validator := xc.Address("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+
+	payload, err := stakingPayload("delegate(address,uint256)", validator, xc.NewAmountBlockchainFromUint64(1000))
+	require.Nil(err)
+	require.Equal("026e402b", hex.EncodeToString(payload[:4]))
+	require.Len(payload, 4+64)
+	require.Equal(common.HexToAddress(string(validator)).Bytes(), payload[16:36])
+	require.Equal(byte(0x03), payload[66])
+	require.Equal(byte(0xe8), payload[67])
+
+	payload, err = stakingPayload("withdrawRewards()", validator, xc.NewAmountBlockchainFromUint64(0))
+	require.Nil(err)
+	require.Len(payload, 4)
+
+	_, err = stakingPayload("", validator, xc.NewAmountBlockchainFromUint64(0))
+	require.EqualError(err, "staking method is not configured")
+	_, err = stakingPayload("delegate", validator, xc.NewAmountBlockchainFromUint64(0))
+	require.EqualError(err, "invalid method signature 'delegate'")
+	_, err = stakingPayload("delegate(bytes32)", validator, xc.NewAmountBlockchainFromUint64(0))
+	require.EqualError(err, "unsupported parameter type 'bytes32' in method signature 'delegate(bytes32)'")
+}
+
+func (s *CrosschainTestSuite) TestStakingTxs() {
+	require := s.Require()
+	contract := "0x0000000000000000000000000000000000002002"
+	asset := &xc.AssetConfig{NativeAsset: xc.BNB, ChainID: 56, StakingContract: xc.StakingContract{
+		Contract:        contract,
+		Delegate:        "delegate(address,uint256)",
+		Undelegate:      "undelegate(address,uint256)",
+		WithdrawRewards: "claimReward()",
+		Payable:         true,
+	}}
+	builder, _ := NewTxBuilder(asset)
+	stakingBuilder := builder.(xc.TxStakingBuilder)
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	validator := xc.Address("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+	amount := xc.NewAmountBlockchainFromStr("1000000000000000000")
+
+	input := &TxInput{}
+	tx, err := stakingBuilder.NewDelegate(from, validator, amount, input)
+	require.Nil(err)
+	ethTx := tx.(*Tx).EthTx
+	require.Equal(common.HexToAddress(contract), *ethTx.To())
+	require.Equal("1000000000000000000", ethTx.Value().String())
+	require.Equal("026e402b", hex.EncodeToString(ethTx.Data()[:4]))
+	require.EqualValues(stakingGasLimit, input.GasLimit)
+
+	tx, err = stakingBuilder.NewUndelegate(from, validator, amount, &TxInput{})
+	require.Nil(err)
+	ethTx = tx.(*Tx).EthTx
+	require.Equal("0", ethTx.Value().String())
+	require.Equal("4d99dd16", hex.EncodeToString(ethTx.Data()[:4]))
+
+	tx, err = stakingBuilder.NewWithdrawRewards(from, validator, &TxInput{GasLimit: 100_000})
+	require.Nil(err)
+	ethTx = tx.(*Tx).EthTx
+	require.Equal("b88a802f", hex.EncodeToString(ethTx.Data()))
+	require.EqualValues(100_000, ethTx.Gas())
+
+	// not payable
+	asset.StakingContract.Payable = false
+	tx, err = stakingBuilder.NewDelegate(from, validator, amount, &TxInput{})
+	require.Nil(err)
+	require.Equal("0", tx.(*Tx).EthTx.Value().String())
+
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.ETH, ChainID: 1})
+	_, err = builder.(xc.TxStakingBuilder).NewDelegate(from, validator, amount, &TxInput{})
+	require.EqualError(err, "no staking contract is configured for ETH")
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	xc "github.com/jumpcrypto/crosschain"
 	"github.com/shopspring/decimal"
-	"golang.org/x/crypto/sha3"
 )
 
 func (txBuilder TxBuilder) NewTask(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
@@ -188,11 +187,13 @@ func (txBuilder TxBuilder) BuildTaskTx(from xc.Address, to xc.Address, amount xc
 	return txBuilder.buildEvmTxWithPayload(xc.Address(contract), value, payload, txInput)
 }
 
+var (
+	proxySendETHMethodID    = methodID("sendETH(uint256,address)")
+	proxySendTokensMethodID = methodID("sendTokens(address,uint256,address)")
+)
+
 func (txBuilder TxBuilder) BuildProxyPayload(contract xc.ContractAddress, to xc.Address, amount xc.AmountBlockchain) ([]byte, error) {
-	transferFnSignature := []byte("sendETH(uint256,address)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
+	methodID := proxySendETHMethodID
 
 	toAddress, err := HexToAddress(to)
 	if err != nil {
@@ -205,10 +206,7 @@ func (txBuilder TxBuilder) BuildProxyPayload(contract xc.ContractAddress, to xc.
 	var paddedContractAddress []byte
 	if contract != "" {
 		// log.Printf("sending token=%s", contract)
-		transferFnSignature := []byte("sendTokens(address,uint256,address)")
-		hash := sha3.NewLegacyKeccak256()
-		hash.Write(transferFnSignature)
-		methodID = hash.Sum(nil)[:4]
+		methodID = proxySendTokensMethodID
 
 		contractAddress, err := HexToAddress(xc.Address(contract))
 		if err != nil {
@@ -216,9 +214,8 @@ func (txBuilder TxBuilder) BuildProxyPayload(contract xc.ContractAddress, to xc.
 		}
 		paddedContractAddress = common.LeftPadBytes(contractAddress.Bytes(), 32)
 	}
-	// log.Print("Proxy methodID: ", hexutil.Encode(methodID)) // 0xa9059cbb for ERC20 transfer
 
-	var data []byte
+	data := make([]byte, 0, len(methodID)+len(paddedContractAddress)+len(paddedAmount)+len(paddedAddress))
 	data = append(data, methodID...)
 	data = append(data, paddedContractAddress...)
 	data = append(data, paddedAmount...)
@@ -4,11 +4,13 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
 	xc "github.com/jumpcrypto/crosschain"
 	"github.com/jumpcrypto/crosschain/chain/evm/erc20"
 )
@@ -21,6 +23,8 @@ type Tx struct {
 }
 
 var _ xc.Tx = &Tx{}
+var _ xc.TxStreamSerializer = &Tx{}
+var _ xc.TxWithSignPayloads = &Tx{}
 
 type parsedTxInfo struct {
 	Sources      []*xc.TxInfoEndpoint
@@ -44,6 +48,37 @@ func (tx Tx) Sighashes() ([]xc.TxDataToSign, error) {
 	return []xc.TxDataToSign{sighash}, nil
 }
 
+// SignPayloads returns the unsigned encoding of the tx that its sighash is the keccak256 of: the RLP of its fields,
+// with the chain id of replay protected legacy txs (EIP-155), prefixed by the type of typed txs (EIP-2718)
+func (tx Tx) SignPayloads() ([]xc.TxDataToSign, error) {
+	if tx.EthTx == nil {
+		return []xc.TxDataToSign{}, errors.New("transaction not initialized")
+	}
+	ethTx := tx.EthTx
+	var fields []interface{}
+	switch ethTx.Type() {
+	case types.LegacyTxType:
+		fields = []interface{}{ethTx.Nonce(), ethTx.GasPrice(), ethTx.Gas(), ethTx.To(), ethTx.Value(), ethTx.Data()}
+		if chainID := tx.Signer.ChainID(); chainID != nil && chainID.Sign() > 0 {
+			fields = append(fields, chainID, uint(0), uint(0))
+		}
+	case types.AccessListTxType:
+		fields = []interface{}{ethTx.ChainId(), ethTx.Nonce(), ethTx.GasPrice(), ethTx.Gas(), ethTx.To(), ethTx.Value(), ethTx.Data(), ethTx.AccessList()}
+	case types.DynamicFeeTxType:
+		fields = []interface{}{ethTx.ChainId(), ethTx.Nonce(), ethTx.GasTipCap(), ethTx.GasFeeCap(), ethTx.Gas(), ethTx.To(), ethTx.Value(), ethTx.Data(), ethTx.AccessList()}
+	default:
+		return []xc.TxDataToSign{}, fmt.Errorf("unsupported tx type: %d", ethTx.Type())
+	}
+	payload, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		return []xc.TxDataToSign{}, err
+	}
+	if ethTx.Type() != types.LegacyTxType {
+		payload = append([]byte{ethTx.Type()}, payload...)
+	}
+	return []xc.TxDataToSign{payload}, nil
+}
+
 // AddSignatures adds a signature to Tx
 func (tx *Tx) AddSignatures(signatures ...xc.TxSignature) error {
 	if tx.EthTx == nil {
@@ -66,6 +101,23 @@ func (tx Tx) Serialize() ([]byte, error) {
 	return tx.EthTx.MarshalBinary()
 }
 
+// SerializeTo writes the canonical encoding of the tx, as Serialize, into w
+// Legacy txs are streamed, typed txs are encoded by go-ethereum into a single buffer
+func (tx Tx) SerializeTo(w io.Writer) error {
+	if tx.EthTx == nil {
+		return errors.New("transaction not initialized")
+	}
+	if tx.EthTx.Type() == types.LegacyTxType {
+		return tx.EthTx.EncodeRLP(w)
+	}
+	serialized, err := tx.EthTx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(serialized)
+	return err
+}
+
 // ParseTransfer parses a tx and extracts higher-level transfer information
 func (tx *Tx) ParseTransfer(receipt *types.Receipt, nativeAsset xc.NativeAsset) parsedTxInfo {
 	// 1. first try parsing as an abi we natively support.
@@ -83,6 +135,14 @@ func (tx *Tx) ParseTransfer(receipt *types.Receipt, nativeAsset xc.NativeAsset)
 		} else {
 			return info
 		}
+
+		// batches of wallets report a transfer per call, including native transfers absent from the logs
+		info, err = tx.ParseMulticallTransferTx(nativeAsset)
+		if err != nil {
+			// ignore
+		} else {
+			return info
+		}
 	}
 
 	// 2. try parsing using the logs
@@ -111,6 +171,10 @@ func (tx *Tx) parseReceipt(receipt *types.Receipt, nativeAsset xc.NativeAsset) p
 	loggedSources := []*xc.TxInfoEndpoint{}
 	loggedDestinations := []*xc.TxInfoEndpoint{}
 	for _, log := range receipt.Logs {
+		if len(log.Topics) == 0 {
+			// anonymous events
+			continue
+		}
 		event, _ := ERC20.EventByID(log.Topics[0])
 		if event != nil && event.RawName == "Transfer" {
 			erc20, _ := erc20.NewErc20(receipt.ContractAddress, nil)
@@ -139,6 +203,19 @@ func (tx *Tx) parseReceipt(receipt *types.Receipt, nativeAsset xc.NativeAsset) p
 	}
 }
 
+// applyTokenTransfer sets the contract, recipient and amount of a tx calling a contract that moved a single token,
+// e.g. a transfer through a smart wallet, instead of the called contract and the zero value sent to it
+func applyTokenTransfer(result xc.TxInfo, info parsedTxInfo) xc.TxInfo {
+	if result.Amount.Sign() != 0 || len(info.Destinations) != 1 || info.Destinations[0].ContractAddress == "" {
+		return result
+	}
+	destination := info.Destinations[0]
+	result.ContractAddress = destination.ContractAddress
+	result.To = destination.Address
+	result.Amount = destination.Amount
+	return result
+}
+
 // IsContract returns whether a tx is a contract or native transfer
 func (tx Tx) IsContract() bool {
 	if tx.EthTx == nil {
@@ -204,21 +281,23 @@ func (tx Tx) ContractAddress() xc.ContractAddress {
 
 // Fee returns the fee associated to the tx
 func (tx Tx) Fee(baseFeeUint uint64, gasUsedUint uint64) xc.AmountBlockchain {
-	// from Etherscan: (BaseFee + MaxPriority)*GasUsed
-	maxPriority := xc.AmountBlockchain(*tx.EthTx.GasTipCap())
 	gasUsed := xc.NewAmountBlockchainFromUint64(gasUsedUint)
+	gasPrice := tx.EffectiveGasPrice(baseFeeUint)
+	return gasUsed.Mul(&gasPrice)
+}
+
+// EffectiveGasPrice returns the price paid per gas by the tx in a block of baseFee
+func (tx Tx) EffectiveGasPrice(baseFeeUint uint64) xc.AmountBlockchain {
+	// from Etherscan: BaseFee + MaxPriority, capped by the max fee (the gas price of legacy txs)
+	maxPriority := xc.AmountBlockchain(*tx.EthTx.GasTipCap())
 	baseFee := xc.NewAmountBlockchainFromUint64(baseFeeUint)
 	baseFeeAndPriority := baseFee.Add(&maxPriority)
-	fee1 := gasUsed.Mul(&baseFeeAndPriority)
 
-	// old gas price * gas used
 	gasPrice := xc.AmountBlockchain(*tx.EthTx.GasPrice())
-	fee2 := gasPrice.Mul(&gasUsed)
-
-	if fee1.Cmp(&fee2) < 0 {
-		return fee1
+	if baseFeeAndPriority.Cmp(&gasPrice) < 0 {
+		return baseFeeAndPriority
 	}
-	return fee2
+	return gasPrice
 }
 
 func ensure0x(address string) string {
@@ -1,6 +1,15 @@
 package evm
 
-import xc "github.com/jumpcrypto/crosschain"
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
 
 func (s *CrosschainTestSuite) TestTxHashEmpty() {
 	require := s.Require()
@@ -22,3 +31,136 @@ func (s *CrosschainTestSuite) TestTxAddSignatureEmpty() {
 	err := tx.AddSignatures([]xc.TxSignature{}...)
 	require.EqualError(err, "transaction not initialized")
 }
+
+func (s *CrosschainTestSuite) TestTxSerializeTo() {
+	require := s.Require()
+	buf := &bytes.Buffer{}
+	require.EqualError(Tx{}.SerializeTo(buf), "transaction not initialized")
+
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	chainID := big.NewInt(1)
+	for _, inner := range []types.TxData{
+		&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5)},
+		&types.AccessListTx{ChainID: chainID, Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5), Data: []byte{1, 2}},
+		&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5)},
+	} {
+		signer := types.LatestSignerForChainID(chainID)
+		ethTx, err := types.SignNewTx(key, signer, inner)
+		require.NoError(err)
+		tx := &Tx{EthTx: ethTx, Signer: signer}
+
+		serialized, err := tx.Serialize()
+		require.NoError(err)
+		buf.Reset()
+		require.NoError(xc.SerializeTo(buf, tx))
+		require.Equal(serialized, buf.Bytes())
+	}
+}
+
+func (s *CrosschainTestSuite) TestParseTxInfoTokenLogs() {
+	require := s.Require()
+	wallet := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	from := common.HexToAddress("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	to := common.HexToAddress("0x24b3A3F3B8e2D2eC7E44A1c8FBbA0C8d2e7bA0bd")
+	transferTopic := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	transferLog := &types.Log{
+		Address: usdc,
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(wallet.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.LeftPadBytes(big.NewInt(2_000_000).Bytes(), 32),
+	}
+	anonymousLog := &types.Log{Address: wallet}
+
+	// a smart wallet executing a token transfer
+	ethTx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 100_000, To: &wallet, Value: big.NewInt(0), Data: []byte{0xb6, 0x1d, 0x27, 0xf6}})
+	receipt := &types.Receipt{Status: 1, Logs: []*types.Log{anonymousLog, transferLog}}
+	info := parseTxInfo(xc.TxInfo{From: xc.Address(from.String())}, ethTx, receipt, 0, big.NewInt(1), xc.ETH)
+	require.Equal(xc.ContractAddress(usdc.String()), info.ContractAddress)
+	require.Equal(xc.Address(to.String()), info.To)
+	require.Equal("2000000", info.Amount.String())
+	require.Len(info.Destinations, 1)
+	require.Equal(xc.Address(wallet.String()), info.Sources[0].Address)
+
+	// multiple token movements are only in the destinations
+	receipt.Logs = []*types.Log{transferLog, transferLog}
+	info = parseTxInfo(xc.TxInfo{}, ethTx, receipt, 0, big.NewInt(1), xc.ETH)
+	require.Equal(xc.ContractAddress(wallet.String()), info.ContractAddress)
+	require.Equal(xc.Address(wallet.String()), info.To)
+	require.Equal("0", info.Amount.String())
+	require.Len(info.Destinations, 2)
+}
+
+func BenchmarkTxSerialize(b *testing.B) {
+	builder, from, to, amount := newBenchmarkTransfer()
+	builder.Legacy = true
+	tx, _ := builder.NewTokenTransfer(from, to, amount, NewTxInput())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := tx.Serialize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTxSerializePooled(b *testing.B) {
+	builder, from, to, amount := newBenchmarkTransfer()
+	builder.Legacy = true
+	tx, _ := builder.NewTokenTransfer(from, to, amount, NewTxInput())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := xc.SerializePooled(tx, func(serialized []byte) error {
+			return nil
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func (s *CrosschainTestSuite) TestTxEffectiveGasPrice() {
+	require := s.Require()
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	dynamic := Tx{EthTx: types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(100), Gas: 50_000, To: &to})}
+	legacy := Tx{EthTx: types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(30), Gas: 50_000, To: &to})}
+
+	// base fee plus priority fee, capped by the max fee
+	price := dynamic.EffectiveGasPrice(40)
+	require.EqualValues(42, price.Uint64())
+	price = dynamic.EffectiveGasPrice(99)
+	require.EqualValues(100, price.Uint64())
+	price = legacy.EffectiveGasPrice(10)
+	require.EqualValues(30, price.Uint64())
+
+	// the fee is of the gas used, not of the gas limit
+	fee := dynamic.Fee(40, 21_000)
+	require.EqualValues(42*21_000, fee.Uint64())
+}
+
+func (s *CrosschainTestSuite) TestTxSignPayloads() {
+	require := s.Require()
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	chainID := big.NewInt(5)
+	accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{{1}}}}
+	vectors := []struct {
+		tx     *types.Transaction
+		signer types.Signer
+	}{
+		{types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5)}), types.HomesteadSigner{}},
+		{types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5)}), types.NewEIP155Signer(chainID)},
+		{types.NewTx(&types.LegacyTx{Nonce: 2, GasPrice: big.NewInt(10), Gas: 100000, Data: []byte{1, 2}}), types.LatestSignerForChainID(chainID)},
+		{types.NewTx(&types.AccessListTx{ChainID: chainID, Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, AccessList: accessList}), types.LatestSignerForChainID(chainID)},
+		{types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5), Data: []byte{3}, AccessList: accessList}), types.LatestSignerForChainID(chainID)},
+	}
+	for i, v := range vectors {
+		tx := Tx{EthTx: v.tx, Signer: v.signer}
+		payloads, err := tx.SignPayloads()
+		require.NoError(err)
+		require.Len(payloads, 1)
+		sighashes, _ := tx.Sighashes()
+		require.Equal([]byte(sighashes[0]), crypto.Keccak256(payloads[0]), i)
+	}
+
+	_, err := Tx{}.SignPayloads()
+	require.EqualError(err, "transaction not initialized")
+}
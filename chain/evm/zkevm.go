@@ -0,0 +1,74 @@
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const polygonZkEVMABIJSON = `[
+{"name":"forceBatch","type":"function","stateMutability":"nonpayable","inputs":[{"name":"transactions","type":"bytes"},{"name":"polAmount","type":"uint256"}],"outputs":[]}
+]`
+
+// PolygonZkEVMABI is the subset of the L1 rollup contract of Polygon zkEVM used by crosschain
+var PolygonZkEVMABI abi.ABI
+
+func init() {
+	var err error
+	PolygonZkEVMABI, err = abi.JSON(strings.NewReader(polygonZkEVMABIJSON))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// fullEffectivePercentage charges the full gas price of the txs of a batch
+const fullEffectivePercentage = 0xff
+
+// EncodeBatchL2Data encodes signed legacy txs as the L2 data of a Polygon zkEVM batch: for each tx, the RLP of its
+// unsigned fields, its R, S and V (27 or 28), and the percentage of its gas price charged
+func EncodeBatchL2Data(txs ...*types.Transaction) ([]byte, error) {
+	data := []byte{}
+	for _, tx := range txs {
+		if tx.Type() != types.LegacyTxType {
+			return nil, errors.New("only legacy txs can be batched on Polygon zkEVM")
+		}
+		v, r, s := tx.RawSignatureValues()
+		if r == nil || r.Sign() == 0 {
+			return nil, errors.New("unable to batch an unsigned tx")
+		}
+		fields := []interface{}{tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data()}
+		recoveryID := new(big.Int).Sub(v, big.NewInt(27))
+		if tx.Protected() {
+			fields = append(fields, tx.ChainId(), uint(0), uint(0))
+			// V is chain id * 2 + 35 + recovery id
+			recoveryID = new(big.Int).Sub(v, new(big.Int).Add(new(big.Int).Mul(tx.ChainId(), big.NewInt(2)), big.NewInt(35)))
+		}
+		if !recoveryID.IsUint64() || recoveryID.Uint64() > 1 {
+			return nil, errors.New("invalid signature of tx")
+		}
+		encoded, err := rlp.EncodeToBytes(fields)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, encoded...)
+		data = append(data, common.LeftPadBytes(r.Bytes(), 32)...)
+		data = append(data, common.LeftPadBytes(s.Bytes(), 32)...)
+		data = append(data, byte(27+recoveryID.Uint64()), fullEffectivePercentage)
+	}
+	return data, nil
+}
+
+// NewForceBatchPayload returns the call of forceBatch on the L1 rollup contract of Polygon zkEVM (l1_rollup),
+// including txs the sequencer censors, for a forced batch fee of polAmount POL
+func NewForceBatchPayload(txs []*types.Transaction, polAmount *big.Int) ([]byte, error) {
+	data, err := EncodeBatchL2Data(txs...)
+	if err != nil {
+		return nil, err
+	}
+	return PolygonZkEVMABI.Pack("forceBatch", data, polAmount)
+}
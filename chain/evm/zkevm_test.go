@@ -0,0 +1,42 @@
+package evm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func (s *CrosschainTestSuite) TestNewForceBatchPayload() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	to := common.HexToAddress("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+	signer := types.LatestSignerForChainID(big.NewInt(1101))
+	ethTx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5)})
+	require.NoError(err)
+
+	data, err := EncodeBatchL2Data(ethTx)
+	require.NoError(err)
+	unsigned, _ := rlp.EncodeToBytes([]interface{}{uint64(1), big.NewInt(10), uint64(21000), &to, big.NewInt(5), []byte{}, big.NewInt(1101), uint(0), uint(0)})
+	require.Equal(unsigned, data[:len(unsigned)])
+	require.Len(data, len(unsigned)+32+32+1+1)
+	require.Equal(byte(fullEffectivePercentage), data[len(data)-1])
+	// V is the recovery id, recovering the sender from the EIP-155 sighash
+	signature := append(append([]byte{}, data[len(unsigned):len(unsigned)+64]...), data[len(data)-2]-27)
+	publicKey, err := crypto.SigToPub(signer.Hash(ethTx).Bytes(), signature)
+	require.NoError(err)
+	require.Equal(crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*publicKey))
+
+	payload, err := NewForceBatchPayload([]*types.Transaction{ethTx}, big.NewInt(7))
+	require.NoError(err)
+	args, err := PolygonZkEVMABI.Methods["forceBatch"].Inputs.Unpack(payload[4:])
+	require.NoError(err)
+	require.Equal(data, args[0].([]byte))
+	require.Equal("7", args[1].(*big.Int).String())
+
+	dynamicTx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1101), To: &to})
+	_, err = EncodeBatchL2Data(dynamicTx)
+	require.EqualError(err, "only legacy txs can be batched on Polygon zkEVM")
+}
@@ -0,0 +1,249 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// ZkSyncTxType is the type of the EIP-712 txs of zkSync, e.g. paid by a paymaster
+const ZkSyncTxType = 0x71
+
+// DefaultGasPerPubdata is the max gas per byte of pubdata of zkSync txs, the default of zkSync SDKs
+const DefaultGasPerPubdata = 50_000
+
+// zkSyncGasLimit is the gas limit of the transfers of zkSync chains, whose gas includes the cost of pubdata
+// published on L1: unused gas is refunded
+const zkSyncGasLimit = 2_000_000
+
+var (
+	zkSyncDomainTypeHash = crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId)"))
+	zkSyncTxTypeHash     = crypto.Keccak256([]byte("Transaction(uint256 txType,uint256 from,uint256 to,uint256 gasLimit,uint256 gasPerPubdataByteLimit,uint256 maxFeePerGas,uint256 maxPriorityFeePerGas,uint256 paymaster,uint256 nonce,uint256 value,bytes data,bytes32[] factoryDeps,bytes paymasterInput)"))
+)
+
+// ZkSyncTx is an EIP-712 tx of zkSync, signed as typed data
+// Factory dependencies (contract deployments) aren't supported
+type ZkSyncTx struct {
+	ChainID        *big.Int
+	Nonce          uint64
+	GasTipCap      *big.Int
+	GasFeeCap      *big.Int
+	Gas            uint64
+	From           common.Address
+	To             common.Address
+	Value          *big.Int
+	Data           []byte
+	GasPerPubdata  uint64
+	Paymaster      common.Address
+	PaymasterInput []byte
+	// Signature is R || S || V, with V 27 or 28
+	Signature []byte
+}
+
+var _ xc.Tx = &ZkSyncTx{}
+
+type zkSyncPaymasterParams struct {
+	Paymaster      common.Address
+	PaymasterInput []byte
+}
+
+// Hash returns the tx hash: the keccak256 of the signed digest and of the signature, empty until the tx is signed
+func (tx ZkSyncTx) Hash() xc.TxHash {
+	if len(tx.Signature) == 0 {
+		return xc.TxHash("")
+	}
+	return xc.TxHash(crypto.Keccak256Hash(tx.digest(), crypto.Keccak256(tx.Signature)).Hex())
+}
+
+// Sighashes returns the EIP-712 digest of the tx
+func (tx ZkSyncTx) Sighashes() ([]xc.TxDataToSign, error) {
+	if tx.ChainID == nil {
+		return []xc.TxDataToSign{}, errors.New("transaction not initialized")
+	}
+	return []xc.TxDataToSign{tx.digest()}, nil
+}
+
+// AddSignatures adds the signature of the digest, as returned by the EVM signer with a V of 0 or 1
+func (tx *ZkSyncTx) AddSignatures(signatures ...xc.TxSignature) error {
+	if len(signatures) != 1 {
+		return errors.New("expecting 1 signature")
+	}
+	if len(signatures[0]) != crypto.SignatureLength {
+		return fmt.Errorf("invalid signature length %d", len(signatures[0]))
+	}
+	signature := append([]byte{}, signatures[0]...)
+	if signature[crypto.RecoveryIDOffset] < 27 {
+		signature[crypto.RecoveryIDOffset] += 27
+	}
+	tx.Signature = signature
+	return nil
+}
+
+// Serialize returns the signed tx: its type followed by the RLP of its fields
+func (tx ZkSyncTx) Serialize() ([]byte, error) {
+	if len(tx.Signature) == 0 {
+		return []byte{}, errors.New("unable to serialize without first calling AddSignatures(...)")
+	}
+	var paymasterParams *zkSyncPaymasterParams
+	if tx.Paymaster != (common.Address{}) {
+		paymasterParams = &zkSyncPaymasterParams{tx.Paymaster, tx.PaymasterInput}
+	}
+	// the fields of the signature of EIP-1559 txs hold the chain id, the signature is the custom signature
+	encoded, err := rlp.EncodeToBytes(struct {
+		Nonce           uint64
+		GasTipCap       *big.Int
+		GasFeeCap       *big.Int
+		Gas             uint64
+		To              common.Address
+		Value           *big.Int
+		Data            []byte
+		ChainID         *big.Int
+		Empty1          []byte
+		Empty2          []byte
+		ChainID2        *big.Int
+		From            common.Address
+		GasPerPubdata   uint64
+		FactoryDeps     [][]byte
+		CustomSignature []byte
+		PaymasterParams *zkSyncPaymasterParams `rlp:"nil"`
+	}{
+		tx.Nonce, tx.GasTipCap, tx.GasFeeCap, tx.Gas, tx.To, tx.Value, tx.Data,
+		tx.ChainID, []byte{}, []byte{}, tx.ChainID, tx.From,
+		tx.GasPerPubdata, [][]byte{}, tx.Signature, paymasterParams,
+	})
+	if err != nil {
+		return []byte{}, err
+	}
+	return append([]byte{ZkSyncTxType}, encoded...), nil
+}
+
+// digest returns the EIP-712 digest of the tx, in the domain of zkSync
+func (tx ZkSyncTx) digest() []byte {
+	domain := crypto.Keccak256(
+		zkSyncDomainTypeHash,
+		crypto.Keccak256([]byte("zkSync")),
+		crypto.Keccak256([]byte("2")),
+		common.BigToHash(tx.ChainID).Bytes(),
+	)
+	structHash := crypto.Keccak256(
+		zkSyncTxTypeHash,
+		common.BigToHash(big.NewInt(ZkSyncTxType)).Bytes(),
+		common.BytesToHash(tx.From.Bytes()).Bytes(),
+		common.BytesToHash(tx.To.Bytes()).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(tx.Gas)).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(tx.GasPerPubdata)).Bytes(),
+		common.BigToHash(tx.GasFeeCap).Bytes(),
+		common.BigToHash(tx.GasTipCap).Bytes(),
+		common.BytesToHash(tx.Paymaster.Bytes()).Bytes(),
+		common.BigToHash(new(big.Int).SetUint64(tx.Nonce)).Bytes(),
+		common.BigToHash(tx.Value).Bytes(),
+		crypto.Keccak256(tx.Data),
+		// no factory deps
+		crypto.Keccak256(),
+		crypto.Keccak256(tx.PaymasterInput),
+	)
+	return crypto.Keccak256([]byte{0x19, 0x01}, domain, structHash)
+}
+
+// buildZkSyncTx builds the EIP-712 tx of a call paid by the paymaster of a zkSync chain
+func (txBuilder TxBuilder) buildZkSyncTx(from xc.Address, to xc.Address, value xc.AmountBlockchain, data []byte, input *TxInput, rollup xc.RollupConfig) (xc.Tx, error) {
+	fromAddress, err := HexToAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	toAddress, err := HexToAddress(to)
+	if err != nil {
+		return nil, err
+	}
+	paymaster, err := HexToAddress(xc.Address(rollup.Paymaster))
+	if err != nil {
+		return nil, fmt.Errorf("invalid paymaster: %v", err)
+	}
+	paymasterInput := []byte{}
+	if rollup.PaymasterInput != "" {
+		paymasterInput, err = hexutil.Decode(ensure0x(rollup.PaymasterInput))
+		if err != nil {
+			return nil, fmt.Errorf("invalid paymaster input: %v", err)
+		}
+	}
+	gasPerPubdata := input.GasPerPubdata
+	if gasPerPubdata == 0 {
+		gasPerPubdata = DefaultGasPerPubdata
+	}
+	gasTipCap := input.GasTipCap.Int()
+	if gasTipCap.Cmp(input.GasFeeCap.Int()) > 0 {
+		gasTipCap = input.GasFeeCap.Int()
+	}
+	return &ZkSyncTx{
+		ChainID:        new(big.Int).SetInt64(txBuilder.Asset.GetAssetConfig().ChainID),
+		Nonce:          input.Nonce,
+		GasTipCap:      gasTipCap,
+		GasFeeCap:      input.GasFeeCap.Int(),
+		Gas:            input.GasLimit,
+		From:           fromAddress,
+		To:             toAddress,
+		Value:          value.Int(),
+		Data:           data,
+		GasPerPubdata:  gasPerPubdata,
+		Paymaster:      paymaster,
+		PaymasterInput: paymasterInput,
+	}, nil
+}
+
+type zkSyncFee struct {
+	GasLimit             *hexutil.Big `json:"gas_limit"`
+	GasPerPubdataLimit   *hexutil.Big `json:"gas_per_pubdata_limit"`
+	MaxFeePerGas         *hexutil.Big `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *hexutil.Big `json:"max_priority_fee_per_gas"`
+}
+
+// estimateZkSyncFee estimates the max fee of an EIP-712 tx with zks_estimateFee, accounting for its paymaster
+func (client *Client) estimateZkSyncFee(ctx context.Context, tx *ZkSyncTx) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	request := map[string]interface{}{
+		"from":  tx.From,
+		"to":    tx.To,
+		"value": (*hexutil.Big)(tx.Value),
+		"data":  hexutil.Bytes(tx.Data),
+		"type":  hexutil.Uint64(ZkSyncTxType),
+		"eip712Meta": map[string]interface{}{
+			"gasPerPubdata": hexutil.Uint64(tx.GasPerPubdata),
+			"paymasterParams": map[string]interface{}{
+				"paymaster":      tx.Paymaster,
+				"paymasterInput": hexutil.Bytes(tx.PaymasterInput),
+			},
+		},
+	}
+	var fee zkSyncFee
+	if err := client.RpcClient.CallContext(ctx, &fee, "zks_estimateFee", request); err != nil {
+		return zero, fmt.Errorf("estimating fee of transaction '%v': %v", tx.Hash(), err)
+	}
+	if fee.GasLimit == nil || fee.MaxFeePerGas == nil {
+		return zero, errors.New("estimating fee: missing gas limit or max fee per gas")
+	}
+	gasLimit := xc.AmountBlockchain(*fee.GasLimit.ToInt())
+	maxFeePerGas := xc.AmountBlockchain(*fee.MaxFeePerGas.ToInt())
+	return gasLimit.Mul(&maxFeePerGas), nil
+}
+
+// finalizedConfirmations returns the confirmations of a tx of a ZK rollup: 0 until its block is finalized, i.e. its
+// batch is proven on L1, then confirmations counted from the latest block
+func (client *Client) finalizedConfirmations(ctx context.Context, blockNumber *big.Int, confirmations int64) (int64, error) {
+	var finalized *struct {
+		Number *hexutil.Big `json:"number"`
+	}
+	if err := client.RpcClient.CallContext(ctx, &finalized, "eth_getBlockByNumber", "finalized", false); err != nil {
+		return 0, fmt.Errorf("fetching finalized block: %v", err)
+	}
+	if finalized == nil || finalized.Number == nil || finalized.Number.ToInt().Cmp(blockNumber) < 0 {
+		return 0, nil
+	}
+	return confirmations, nil
+}
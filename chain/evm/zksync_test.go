@@ -0,0 +1,117 @@
+package evm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestZkSyncTx() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	from := xc.Address(crypto.PubkeyToAddress(key.PublicKey).String())
+	paymaster := "0x0000000000000000000000000000000000abcdef"
+	asset := &xc.NativeAssetConfig{NativeAsset: xc.ETH, ChainID: 324, Rollup: xc.RollupConfig{
+		Paymaster:      paymaster,
+		PaymasterInput: "0x8c5a3445",
+	}}
+	builder, _ := NewTxBuilder(asset)
+	input := NewTxInput()
+	input.Nonce = 3
+	input.GasFeeCap = xc.NewAmountBlockchainFromUint64(250_000_000)
+	input.GasTipCap = xc.NewAmountBlockchainFromUint64(1_000_000_000)
+	tx, err := builder.NewTransfer(from, "0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD", xc.NewAmountBlockchainFromUint64(1000), input)
+	require.NoError(err)
+	zkSyncTx := tx.(*ZkSyncTx)
+	require.EqualValues(324, zkSyncTx.ChainID.Int64())
+	require.EqualValues(zkSyncGasLimit, zkSyncTx.Gas)
+	require.EqualValues(DefaultGasPerPubdata, zkSyncTx.GasPerPubdata)
+	// the tip is capped to the max fee
+	require.Equal("250000000", zkSyncTx.GasTipCap.String())
+	require.Equal(common.HexToAddress(paymaster), zkSyncTx.Paymaster)
+	require.Equal([]byte{0x8c, 0x5a, 0x34, 0x45}, zkSyncTx.PaymasterInput)
+
+	require.Equal(xc.TxHash(""), tx.Hash())
+	_, err = tx.Serialize()
+	require.Error(err)
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes[0], 32)
+	signer, _ := NewSigner(asset)
+	signature, err := signer.Sign(crypto.FromECDSA(key), sighashes[0])
+	require.NoError(err)
+	require.NoError(tx.AddSignatures(signature))
+	require.EqualValues(27+signature[64], zkSyncTx.Signature[64])
+
+	// the hash commits to the digest and the signature
+	require.Equal(xc.TxHash(crypto.Keccak256Hash(sighashes[0], crypto.Keccak256(zkSyncTx.Signature)).Hex()), tx.Hash())
+	publicKey, err := crypto.SigToPub(sighashes[0], signature)
+	require.NoError(err)
+	require.Equal(string(from), crypto.PubkeyToAddress(*publicKey).String())
+
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	require.EqualValues(ZkSyncTxType, serialized[0])
+	var fields []rlp.RawValue
+	require.NoError(rlp.DecodeBytes(serialized[1:], &fields))
+	require.Len(fields, 16)
+	var decodedFrom common.Address
+	require.NoError(rlp.DecodeBytes(fields[11], &decodedFrom))
+	require.Equal(string(from), decodedFrom.String())
+	var customSignature []byte
+	require.NoError(rlp.DecodeBytes(fields[14], &customSignature))
+	require.Equal(zkSyncTx.Signature, customSignature)
+	var paymasterParams zkSyncPaymasterParams
+	require.NoError(rlp.DecodeBytes(fields[15], &paymasterParams))
+	require.Equal(zkSyncTx.Paymaster, paymasterParams.Paymaster)
+
+	require.EqualError(tx.AddSignatures(signature[:10]), "invalid signature length 10")
+}
+
+func (s *CrosschainTestSuite) TestZkSyncTransferWithoutPaymaster() {
+	require := s.Require()
+	// zkSync accepts EIP-1559 txs, with a gas limit accounting for pubdata
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.ETH, ChainID: 324})
+	tx, err := builder.NewTransfer("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B", "0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD", xc.NewAmountBlockchainFromUint64(1), NewTxInput())
+	require.NoError(err)
+	require.EqualValues(zkSyncGasLimit, tx.(*Tx).EthTx.Gas())
+
+	// other chains keep their gas limit
+	builder, _ = NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.ETH, ChainID: 1101})
+	tx, err = builder.NewTransfer("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B", "0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD", xc.NewAmountBlockchainFromUint64(1), NewTxInput())
+	require.NoError(err)
+	require.EqualValues(90_000, tx.(*Tx).EthTx.Gas())
+}
+
+func (s *CrosschainTestSuite) TestEstimateFeeZkSync() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"gas_limit":"0x30d40","gas_per_pubdata_limit":"0xc350","max_fee_per_gas":"0xee6b280","max_priority_fee_per_gas":"0x0"}`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH, ChainID: 324, URL: server.URL})
+	tx := &ZkSyncTx{ChainID: big.NewInt(324), Value: big.NewInt(0), GasPerPubdata: DefaultGasPerPubdata, Paymaster: common.HexToAddress("0xabcdef")}
+	fee, err := client.EstimateFee(s.Ctx, "0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B", tx)
+	require.NoError(err)
+	// 200000 gas at 0.25 gwei
+	require.Equal("50000000000000", fee.String())
+	require.Equal(1, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestFinalizedConfirmations() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"number":"0x64"}`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.ETH, ChainID: 324, URL: server.URL})
+
+	// proven on L1
+	confirmations, err := client.finalizedConfirmations(s.Ctx, big.NewInt(90), 30)
+	require.NoError(err)
+	require.EqualValues(30, confirmations)
+	// sequenced only
+	confirmations, err = client.finalizedConfirmations(s.Ctx, big.NewInt(101), 3)
+	require.NoError(err)
+	require.EqualValues(0, confirmations)
+}
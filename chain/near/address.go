@@ -0,0 +1,102 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/mr-tron/base58"
+)
+
+// publicKeyPrefix is the prefix of ed25519 public keys in the RPC API, e.g. ed25519:6E8s...
+const publicKeyPrefix = "ed25519:"
+
+// keyTypeEd25519 is the borsh enum of ed25519 keys and signatures
+const keyTypeEd25519 = 0
+
+// accountIDPattern are the named accounts of NEAR: parts of lowercase alphanumerics separated by - or _, separated
+// by dots, e.g. alice.near
+var accountIDPattern = regexp.MustCompile(`^(([a-z\d]+[\-_])*[a-z\d]+\.)*([a-z\d]+[\-_])*[a-z\d]+$`)
+
+// AddressBuilder for NEAR
+type AddressBuilder struct {
+}
+
+var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
+
+// NewAddressBuilder creates a new NEAR AddressBuilder
+func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
+	return AddressBuilder{}, nil
+}
+
+// GetAddressFromPublicKey returns the implicit account of an ed25519 public key: the hex of the key
+// Implicit accounts exist once funded, unlike named accounts (alice.near) created by a tx
+func (ab AddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (xc.Address, error) {
+	if len(publicKeyBytes) != ed25519.PublicKeySize {
+		return xc.Address(""), fmt.Errorf("invalid ed25519 public key length %d", len(publicKeyBytes))
+	}
+	return xc.Address(hex.EncodeToString(publicKeyBytes)), nil
+}
+
+// GetAllPossibleAddressesFromPublicKey returns all PossubleAddress(es) given a public key
+func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]xc.PossibleAddress, error) {
+	address, err := ab.GetAddressFromPublicKey(publicKeyBytes)
+	return []xc.PossibleAddress{
+		{
+			Address: address,
+			Type:    xc.AddressTypeDefault,
+		},
+	}, err
+}
+
+// ValidateAddress checks an address is an account id of NEAR, implicit or named
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	if err := ValidateAccountID(address); err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	return nil
+}
+
+// ValidateAccountID checks an account id is valid: 2 to 64 characters, lowercase
+func ValidateAccountID(accountID xc.Address) error {
+	str := string(accountID)
+	if len(str) < 2 || len(str) > 64 {
+		return fmt.Errorf("invalid length %d", len(str))
+	}
+	if !accountIDPattern.MatchString(str) {
+		return errors.New("invalid characters")
+	}
+	return nil
+}
+
+// ImplicitPublicKey returns the public key of an implicit account, false for named accounts
+func ImplicitPublicKey(accountID xc.Address) ([]byte, bool) {
+	str := string(accountID)
+	if len(str) != 2*ed25519.PublicKeySize || strings.ToLower(str) != str {
+		return nil, false
+	}
+	publicKey, err := hex.DecodeString(str)
+	return publicKey, err == nil
+}
+
+// EncodePublicKey returns a public key in the format of the RPC API: ed25519: and its base58
+func EncodePublicKey(publicKey []byte) string {
+	return publicKeyPrefix + base58.Encode(publicKey)
+}
+
+// ParsePublicKey parses a public key in the format of the RPC API, only ed25519 keys are supported
+func ParsePublicKey(publicKey string) ([]byte, error) {
+	if !strings.HasPrefix(publicKey, publicKeyPrefix) {
+		return nil, fmt.Errorf("invalid public key '%s': expected %s prefix", publicKey, publicKeyPrefix)
+	}
+	decoded, err := base58.Decode(strings.TrimPrefix(publicKey, publicKeyPrefix))
+	if err != nil || len(decoded) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key '%s'", publicKey)
+	}
+	return decoded, nil
+}
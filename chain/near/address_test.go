@@ -0,0 +1,68 @@
+package near
+
+import (
+	"encoding/hex"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// the key of the test 1 of RFC 8032
+const testSeed = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+const testPublicKey = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+const testAddress = xc.Address(testPublicKey)
+
+func (s *CrosschainTestSuite) TestGetAddressFromPublicKey() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.NEAR})
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	address, err := builder.GetAddressFromPublicKey(publicKey)
+	require.NoError(err)
+	require.Equal(testAddress, address)
+
+	addresses, err := builder.GetAllPossibleAddressesFromPublicKey(publicKey)
+	require.NoError(err)
+	require.Len(addresses, 1)
+	require.Equal(testAddress, addresses[0].Address)
+
+	_, err = builder.GetAddressFromPublicKey(publicKey[1:])
+	require.EqualError(err, "invalid ed25519 public key length 31")
+}
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.NEAR})
+	validator := builder.(xc.AddressValidator)
+	for _, address := range []xc.Address{testAddress, "alice.near", "bob.testnet", "app_1-x.alice.near", "aa"} {
+		require.NoError(validator.ValidateAddress(address), address)
+	}
+	for _, address := range []xc.Address{"a", "Alice.near", "alice..near", ".alice", "alice-", "alice@near", testAddress + "00"} {
+		require.Error(validator.ValidateAddress(address), address)
+	}
+}
+
+func (s *CrosschainTestSuite) TestImplicitPublicKey() {
+	require := s.Require()
+	publicKey, ok := ImplicitPublicKey(testAddress)
+	require.True(ok)
+	require.Equal(testPublicKey, hex.EncodeToString(publicKey))
+
+	_, ok = ImplicitPublicKey("alice.near")
+	require.False(ok)
+	_, ok = ImplicitPublicKey(xc.Address("D75A980182B10AB7D54BFED3C964073A0EE172F3DAA62325AF021A68F707511A"))
+	require.False(ok)
+}
+
+func (s *CrosschainTestSuite) TestPublicKey() {
+	require := s.Require()
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	encoded := EncodePublicKey(publicKey)
+	require.Equal("ed25519:FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z", encoded)
+	decoded, err := ParsePublicKey(encoded)
+	require.NoError(err)
+	require.Equal(publicKey, decoded)
+
+	_, err = ParsePublicKey("secp256k1:FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z")
+	require.Error(err)
+	_, err = ParsePublicKey("ed25519:FVen3X669xLzsi6N2V91")
+	require.Error(err)
+}
@@ -0,0 +1,54 @@
+package near
+
+import (
+	"encoding/binary"
+	"math/big"
+)
+
+// borshWriter writes the borsh encoding of NEAR: little endian integers, and sequences prefixed by their u32
+// length
+type borshWriter struct {
+	buf []byte
+}
+
+func (w *borshWriter) u8(v uint8) {
+	w.buf = append(w.buf, v)
+}
+
+func (w *borshWriter) u32(v uint32) {
+	var bytes [4]byte
+	binary.LittleEndian.PutUint32(bytes[:], v)
+	w.buf = append(w.buf, bytes[:]...)
+}
+
+func (w *borshWriter) u64(v uint64) {
+	var bytes [8]byte
+	binary.LittleEndian.PutUint64(bytes[:], v)
+	w.buf = append(w.buf, bytes[:]...)
+}
+
+// u128 writes the 16 bytes of v, which must fit
+func (w *borshWriter) u128(v *big.Int) {
+	var bytes [16]byte
+	v.FillBytes(bytes[:])
+	for i := len(bytes) - 1; i >= 0; i-- {
+		w.buf = append(w.buf, bytes[i])
+	}
+}
+
+func (w *borshWriter) fixed(data []byte) {
+	w.buf = append(w.buf, data...)
+}
+
+func (w *borshWriter) bytes(data []byte) {
+	w.u32(uint32(len(data)))
+	w.fixed(data)
+}
+
+func (w *borshWriter) string(s string) {
+	w.bytes([]byte(s))
+}
+
+func (w *borshWriter) Bytes() []byte {
+	return w.buf
+}
@@ -0,0 +1,129 @@
+package near
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// functionCallGas is the gas of the calls of NEP-141 transfers, 30 Tgas: unused gas is refunded
+const functionCallGas = 30_000_000_000_000
+
+// oneYocto is the deposit of ft_transfer, required by NEP-141 to ensure the call is signed by a full access key
+var oneYocto = xc.NewAmountBlockchainFromUint64(1)
+
+// TxBuilder for NEAR
+type TxBuilder struct {
+	Asset xc.ITask
+}
+
+var _ xc.TxBuilder = &TxBuilder{}
+var _ xc.TxTokenBuilder = &TxBuilder{}
+
+// NewTxBuilder creates a new NEAR TxBuilder
+func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
+	return &TxBuilder{
+		Asset: asset,
+	}, nil
+}
+
+// NewTransfer creates a new transfer for an Asset, either native or token
+func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		return txBuilder.NewTokenTransfer(from, to, amount, input)
+	}
+	return txBuilder.NewNativeTransfer(from, to, amount, input)
+}
+
+// NewNativeTransfer creates a new tx with a Transfer action of NEAR to the recipient
+func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	localInput, err := parseTransfer(from, to, amount, input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	return &Tx{Transaction: newTransaction(localInput, from, to, Action{Kind: actionTransfer, Deposit: amount})}, nil
+}
+
+// NewTokenTransfer creates a new tx calling ft_transfer of a NEP-141 contract, after storage_deposit if the
+// recipient isn't registered with the contract
+func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	localInput, err := parseTransfer(from, to, amount, input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	contract := txBuilder.Asset.GetAssetConfig().Contract
+	if token, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		contract = token.Contract
+	}
+	if err := ValidateAccountID(xc.Address(contract)); err != nil {
+		return &Tx{}, fmt.Errorf("invalid contract '%s': %v", contract, err)
+	}
+
+	actions := []Action{}
+	if localInput.StorageDeposit.Int().Sign() > 0 {
+		args, _ := json.Marshal(storageDepositArgs{AccountID: string(to), RegistrationOnly: true})
+		actions = append(actions, Action{
+			Kind:       actionFunctionCall,
+			MethodName: "storage_deposit",
+			Args:       args,
+			Gas:        functionCallGas,
+			Deposit:    localInput.StorageDeposit,
+		})
+	}
+	args, _ := json.Marshal(ftTransferArgs{ReceiverID: string(to), Amount: amount.String()})
+	actions = append(actions, Action{
+		Kind:       actionFunctionCall,
+		MethodName: "ft_transfer",
+		Args:       args,
+		Gas:        functionCallGas,
+		Deposit:    oneYocto,
+	})
+	return &Tx{Transaction: newTransaction(localInput, from, xc.Address(contract), actions...)}, nil
+}
+
+type storageDepositArgs struct {
+	AccountID        string `json:"account_id"`
+	RegistrationOnly bool   `json:"registration_only"`
+}
+
+type ftTransferArgs struct {
+	ReceiverID string `json:"receiver_id"`
+	Amount     string `json:"amount"`
+	Memo       string `json:"memo,omitempty"`
+}
+
+func parseTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (*TxInput, error) {
+	localInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, errors.New("xc.TxInput is not from a near chain")
+	}
+	if len(localInput.PublicKey) != 32 || len(localInput.BlockHash) != 32 {
+		return nil, errors.New("invalid input: missing access key or block hash")
+	}
+	if err := ValidateAccountID(from); err != nil {
+		return nil, fmt.Errorf("invalid from address '%s': %v", from, err)
+	}
+	if err := ValidateAccountID(to); err != nil {
+		return nil, fmt.Errorf("invalid to address '%s': %v", to, err)
+	}
+	if amount.Int().Sign() <= 0 || amount.Int().Cmp(maxU128) > 0 {
+		return nil, fmt.Errorf("invalid amount %s", amount.String())
+	}
+	return localInput, nil
+}
+
+func newTransaction(input *TxInput, from xc.Address, receiver xc.Address, actions ...Action) Transaction {
+	return Transaction{
+		SignerID:   string(from),
+		PublicKey:  input.PublicKey,
+		Nonce:      input.Nonce,
+		ReceiverID: string(receiver),
+		BlockHash:  input.BlockHash,
+		Actions:    actions,
+	}
+}
@@ -0,0 +1,523 @@
+package near
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/mr-tron/base58"
+)
+
+// finalityFinal is the finality of queries: the state of the final block
+const finalityFinal = "final"
+
+// nep141EventPrefix is the prefix of the logs of NEP-297 events
+const nep141EventPrefix = "EVENT_JSON:"
+
+// txHashSenderSeparator separates the hash of a tx and its sender in the hashes passed to FetchTxInfo, see
+// TxHashWithSender
+const txHashSenderSeparator = "@"
+
+// Client for NEAR, using the JSON-RPC API of nearcore
+type Client struct {
+	Asset           xc.ITask
+	HttpClient      *http.Client
+	URL             string
+	EstimateGasFunc xc.EstimateGasFunc
+}
+
+var _ xc.FullClientWithGas = &Client{}
+
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      string      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type rpcError struct {
+	Name  string `json:"name"`
+	Cause struct {
+		Name string          `json:"name"`
+		Info json.RawMessage `json:"info"`
+	} `json:"cause"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (e *rpcError) Error() string {
+	name := e.Cause.Name
+	if name == "" {
+		name = e.Name
+	}
+	if name == "" {
+		name = e.Message
+	}
+	data := strings.Trim(string(e.Data), `"`)
+	if data == "" || data == "null" {
+		return name
+	}
+	return fmt.Sprintf("%s: %s", name, data)
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcAccessKey struct {
+	Nonce      uint64          `json:"nonce"`
+	Permission json.RawMessage `json:"permission"`
+	BlockHash  string          `json:"block_hash"`
+}
+
+type rpcAccessKeyList struct {
+	Keys []struct {
+		PublicKey string       `json:"public_key"`
+		AccessKey rpcAccessKey `json:"access_key"`
+	} `json:"keys"`
+}
+
+type rpcAccount struct {
+	Amount string `json:"amount"`
+	Locked string `json:"locked"`
+}
+
+type rpcCallResult struct {
+	Result []byte `json:"-"`
+	Raw    []int  `json:"result"`
+	// errors of view calls of old nodes are reported in the result
+	Error string `json:"error"`
+}
+
+type rpcOutcome struct {
+	ID        string `json:"id"`
+	BlockHash string `json:"block_hash"`
+	Outcome   struct {
+		Logs        []string        `json:"logs"`
+		GasBurnt    uint64          `json:"gas_burnt"`
+		TokensBurnt string          `json:"tokens_burnt"`
+		ExecutorID  string          `json:"executor_id"`
+		Status      json.RawMessage `json:"status"`
+	} `json:"outcome"`
+}
+
+type rpcTxStatus struct {
+	FinalExecutionStatus string                     `json:"final_execution_status"`
+	Status               map[string]json.RawMessage `json:"status"`
+	Transaction          struct {
+		SignerID   string            `json:"signer_id"`
+		ReceiverID string            `json:"receiver_id"`
+		Hash       string            `json:"hash"`
+		Actions    []json.RawMessage `json:"actions"`
+	} `json:"transaction"`
+	TransactionOutcome rpcOutcome   `json:"transaction_outcome"`
+	ReceiptsOutcome    []rpcOutcome `json:"receipts_outcome"`
+}
+
+type rpcBlock struct {
+	Header struct {
+		Height    int64  `json:"height"`
+		Hash      string `json:"hash"`
+		Timestamp uint64 `json:"timestamp"`
+	} `json:"header"`
+}
+
+type rpcActionTransfer struct {
+	Transfer *struct {
+		Deposit string `json:"deposit"`
+	} `json:"Transfer"`
+}
+
+type nep141Event struct {
+	Standard string `json:"standard"`
+	Event    string `json:"event"`
+	Data     []struct {
+		OldOwnerID string `json:"old_owner_id"`
+		NewOwnerID string `json:"new_owner_id"`
+		Amount     string `json:"amount"`
+	} `json:"data"`
+}
+
+// NewClient returns a new NEAR Client
+func NewClient(cfgI xc.ITask) (*Client, error) {
+	cfg := cfgI.GetNativeAsset()
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Asset:      cfgI,
+		HttpClient: &http.Client{Transport: transport},
+		URL:        cfg.URL,
+	}, nil
+}
+
+// TxHashWithSender returns the hash of a tx with its sender, as expected by FetchTxInfo: the API of nearcore
+// requires the sender of a tx to find its shard
+func TxHashWithSender(txHash xc.TxHash, sender xc.Address) xc.TxHash {
+	return xc.TxHash(string(txHash) + txHashSenderSeparator + string(sender))
+}
+
+// FetchTxInput returns tx input for a NEAR tx: the next nonce of the access key of the sender, the hash of the
+// final block, and for NEP-141 transfers the storage deposit registering the recipient if needed
+// The access key of implicit accounts is their public key, named accounts must have a single full access key
+func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, to xc.Address) (xc.TxInput, error) {
+	input := NewTxInput()
+	publicKey, ok := ImplicitPublicKey(from)
+	if !ok {
+		var err error
+		if publicKey, err = client.fetchFullAccessKey(ctx, from); err != nil {
+			return input, err
+		}
+	}
+	var accessKey rpcAccessKey
+	err := client.query(ctx, map[string]interface{}{
+		"request_type": "view_access_key",
+		"account_id":   string(from),
+		"public_key":   EncodePublicKey(publicKey),
+	}, &accessKey)
+	if err != nil {
+		return input, fmt.Errorf("fetching access key of '%s': %v", from, err)
+	}
+	blockHash, err := base58.Decode(accessKey.BlockHash)
+	if err != nil || len(blockHash) != 32 {
+		return input, fmt.Errorf("invalid block hash '%s'", accessKey.BlockHash)
+	}
+	input.PublicKey = publicKey
+	input.Nonce = accessKey.Nonce + 1
+	input.BlockHash = blockHash
+	input.StorageDeposit = xc.NewAmountBlockchainFromUint64(0)
+
+	if token, ok := client.Asset.(*xc.TokenAssetConfig); ok {
+		var balance *json.RawMessage
+		if err := client.callFunction(ctx, token.Contract, "storage_balance_of", map[string]interface{}{"account_id": string(to)}, &balance); err != nil {
+			return input, fmt.Errorf("fetching storage balance of '%s': %v", to, err)
+		}
+		if balance == nil || string(*balance) == "null" {
+			var bounds struct {
+				Min string `json:"min"`
+			}
+			if err := client.callFunction(ctx, token.Contract, "storage_balance_bounds", map[string]interface{}{}, &bounds); err != nil {
+				return input, fmt.Errorf("fetching storage balance bounds: %v", err)
+			}
+			input.StorageDeposit = xc.NewAmountBlockchainFromStr(bounds.Min)
+		}
+	}
+	return input, nil
+}
+
+// SubmitTx submits a NEAR tx, without waiting for its execution
+func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		return xc.RecordDryRun(ctx, client.Asset, tx, false)
+	}
+	params := map[string]interface{}{
+		"signed_tx_base64": base64.StdEncoding.EncodeToString(serialized),
+		"wait_until":       "NONE",
+	}
+	var res json.RawMessage
+	return client.call(ctx, "send_tx", params, &res)
+}
+
+// FetchTxInfo returns tx info for a NEAR tx, of a hash with its sender, see TxHashWithSender
+// Txs are executed asynchronously: their actions are executed by receipts in the next blocks, and refunds later
+// still. Txs have no confirmation until all their receipts are executed, then confirmations are counted from the
+// last block executing them to the final block
+func (client *Client) FetchTxInfo(ctx context.Context, txHashWithSender xc.TxHash) (xc.TxInfo, error) {
+	txHash, sender, ok := strings.Cut(string(txHashWithSender), txHashSenderSeparator)
+	if !ok {
+		return xc.TxInfo{}, fmt.Errorf("missing sender of tx '%s', expected <hash>%s<sender>", txHash, txHashSenderSeparator)
+	}
+	var status rpcTxStatus
+	params := map[string]interface{}{
+		"tx_hash":           txHash,
+		"sender_account_id": sender,
+		"wait_until":        "NONE",
+	}
+	if err := client.call(ctx, "tx", params, &status); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching tx '%s': %v", txHash, err)
+	}
+
+	nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+	info := xc.TxInfo{
+		TxID:        txHash,
+		ExplorerURL: fmt.Sprintf("/txns/%s", txHash),
+		From:        xc.Address(status.Transaction.SignerID),
+		Amount:      xc.NewAmountBlockchainFromUint64(0),
+	}
+	if failure, ok := status.Status["Failure"]; ok {
+		info.Status = xc.TxStatusFailure
+		info.Error = string(failure)
+	}
+
+	// fees are burnt by the tx and its receipts
+	fee := new(big.Int)
+	outcomes := append([]rpcOutcome{status.TransactionOutcome}, status.ReceiptsOutcome...)
+	for _, outcome := range outcomes {
+		if burnt, ok := new(big.Int).SetString(outcome.Outcome.TokensBurnt, 10); ok {
+			fee.Add(fee, burnt)
+		}
+		info.GasUsed += outcome.Outcome.GasBurnt
+	}
+	info.Fee = xc.AmountBlockchain(*fee)
+
+	// transfers of NEAR, or of tokens by NEP-141 events
+	for _, raw := range status.Transaction.Actions {
+		var action rpcActionTransfer
+		if err := json.Unmarshal(raw, &action); err != nil || action.Transfer == nil {
+			continue
+		}
+		amount := xc.NewAmountBlockchainFromStr(action.Transfer.Deposit)
+		info.To = xc.Address(status.Transaction.ReceiverID)
+		info.Amount = amount
+		info.Sources = append(info.Sources, &xc.TxInfoEndpoint{Address: info.From, Amount: amount, NativeAsset: nativeAsset})
+		info.Destinations = append(info.Destinations, &xc.TxInfoEndpoint{Address: info.To, Amount: amount, NativeAsset: nativeAsset})
+	}
+	for _, outcome := range status.ReceiptsOutcome {
+		contract := xc.ContractAddress(outcome.Outcome.ExecutorID)
+		for _, event := range parseNep141Events(outcome.Outcome.Logs) {
+			for _, transfer := range event.Data {
+				amount := xc.NewAmountBlockchainFromStr(transfer.Amount)
+				from := xc.Address(transfer.OldOwnerID)
+				to := xc.Address(transfer.NewOwnerID)
+				info.Sources = append(info.Sources, &xc.TxInfoEndpoint{Address: from, ContractAddress: contract, Amount: amount, NativeAsset: nativeAsset})
+				info.Destinations = append(info.Destinations, &xc.TxInfoEndpoint{Address: to, ContractAddress: contract, Amount: amount, NativeAsset: nativeAsset})
+				if info.To == "" && from == info.From {
+					info.To = to
+					info.Amount = amount
+					info.ContractAddress = contract
+				}
+			}
+		}
+	}
+
+	// the block including the tx, and the last block executing its receipts
+	block, err := client.fetchBlock(ctx, map[string]interface{}{"block_id": status.TransactionOutcome.BlockHash})
+	if err != nil {
+		return info, err
+	}
+	info.BlockHash = block.Header.Hash
+	info.BlockIndex = block.Header.Height
+	// timestamps are in nanoseconds
+	info.BlockTime = int64(block.Header.Timestamp / 1_000_000_000)
+	if !executed(status.FinalExecutionStatus) {
+		return info, nil
+	}
+	lastHeight := block.Header.Height
+	fetched := map[string]bool{status.TransactionOutcome.BlockHash: true}
+	for _, outcome := range status.ReceiptsOutcome {
+		if fetched[outcome.BlockHash] {
+			continue
+		}
+		fetched[outcome.BlockHash] = true
+		receiptBlock, err := client.fetchBlock(ctx, map[string]interface{}{"block_id": outcome.BlockHash})
+		if err != nil {
+			return info, err
+		}
+		if receiptBlock.Header.Height > lastHeight {
+			lastHeight = receiptBlock.Header.Height
+		}
+	}
+	final, err := client.fetchBlock(ctx, map[string]interface{}{"finality": finalityFinal})
+	if err != nil {
+		return info, err
+	}
+	if final.Header.Height >= lastHeight {
+		info.Confirmations = final.Header.Height - lastHeight + 1
+	}
+	return info, nil
+}
+
+// FetchBalance fetches the balance of an asset for a NEAR account
+func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	if token, ok := client.Asset.(*xc.TokenAssetConfig); ok {
+		var balance string
+		if err := client.callFunction(ctx, token.Contract, "ft_balance_of", map[string]interface{}{"account_id": string(address)}, &balance); err != nil {
+			return xc.NewAmountBlockchainFromUint64(0), fmt.Errorf("fetching balance of '%s': %v", address, err)
+		}
+		return xc.NewAmountBlockchainFromStr(balance), nil
+	}
+	return client.FetchNativeBalance(ctx, address)
+}
+
+// FetchNativeBalance fetches the NEAR balance of an account, not staked, 0 for implicit accounts not funded yet
+func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	var account rpcAccount
+	err := client.query(ctx, map[string]interface{}{
+		"request_type": "view_account",
+		"account_id":   string(address),
+	}, &account)
+	if err != nil {
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) && rpcErr.Cause.Name == "UNKNOWN_ACCOUNT" {
+			return zero, nil
+		}
+		return zero, fmt.Errorf("fetching account '%s': %v", address, err)
+	}
+	return xc.NewAmountBlockchainFromStr(account.Amount), nil
+}
+
+func (client *Client) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc) {
+	client.EstimateGasFunc = estimateGas
+}
+
+// EstimateGas returns the price of gas of the latest block in yoctoNEAR: gas is bought at the price of the block
+// including the tx, the difference with later blocks refunded
+func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	if client.EstimateGasFunc != nil {
+		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+		if res, err := client.EstimateGasFunc(nativeAsset); err == nil {
+			return res, nil
+		}
+		// continue with default implementation as fallback
+	}
+	var res struct {
+		GasPrice string `json:"gas_price"`
+	}
+	if err := client.call(ctx, "gas_price", []interface{}{nil}, &res); err != nil {
+		return zero, fmt.Errorf("fetching gas price: %v", err)
+	}
+	return xc.NewAmountBlockchainFromStr(res.GasPrice), nil
+}
+
+// fetchFullAccessKey returns the full access key of a named account, which must be unique
+func (client *Client) fetchFullAccessKey(ctx context.Context, accountID xc.Address) ([]byte, error) {
+	var list rpcAccessKeyList
+	err := client.query(ctx, map[string]interface{}{
+		"request_type": "view_access_key_list",
+		"account_id":   string(accountID),
+	}, &list)
+	if err != nil {
+		return nil, fmt.Errorf("fetching access keys of '%s': %v", accountID, err)
+	}
+	fullAccessKeys := []string{}
+	for _, key := range list.Keys {
+		if string(key.AccessKey.Permission) == `"FullAccess"` {
+			fullAccessKeys = append(fullAccessKeys, key.PublicKey)
+		}
+	}
+	if len(fullAccessKeys) != 1 {
+		return nil, fmt.Errorf("expected a single full access key of '%s', found %d", accountID, len(fullAccessKeys))
+	}
+	return ParsePublicKey(fullAccessKeys[0])
+}
+
+func (client *Client) fetchBlock(ctx context.Context, params map[string]interface{}) (rpcBlock, error) {
+	var block rpcBlock
+	if err := client.call(ctx, "block", params, &block); err != nil {
+		return block, fmt.Errorf("fetching block: %v", err)
+	}
+	return block, nil
+}
+
+// callFunction calls a view method of a contract with JSON args, decoding its JSON result into result
+func (client *Client) callFunction(ctx context.Context, contract string, method string, args interface{}, result interface{}) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	var res rpcCallResult
+	err = client.query(ctx, map[string]interface{}{
+		"request_type": "call_function",
+		"account_id":   contract,
+		"method_name":  method,
+		"args_base64":  base64.StdEncoding.EncodeToString(data),
+	}, &res)
+	if err != nil {
+		return err
+	}
+	if res.Error != "" {
+		return errors.New(res.Error)
+	}
+	// the result is returned as an array of bytes
+	raw := make([]byte, len(res.Raw))
+	for i, b := range res.Raw {
+		raw[i] = byte(b)
+	}
+	return json.Unmarshal(raw, result)
+}
+
+// query queries the state of the final block
+func (client *Client) query(ctx context.Context, params map[string]interface{}, result interface{}) error {
+	params["finality"] = finalityFinal
+	return client.call(ctx, "query", params, result)
+}
+
+// call calls a method of the JSON-RPC API, whose params are objects unlike the arrays of go-ethereum's client
+func (client *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	data, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: "crosschain", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.HttpClient.Do(req)
+	if err != nil {
+		return xc.DefaultRedactor.RedactError(err)
+	}
+	defer resp.Body.Close()
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var res rpcResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return fmt.Errorf("%s returned %s: %s", method, resp.Status, xc.DefaultRedactor.Redact(string(data)))
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s: %s", method, resp.Status, xc.DefaultRedactor.Redact(string(data)))
+	}
+	return json.Unmarshal(res.Result, result)
+}
+
+// executed returns true if all the receipts of a tx are executed, in blocks final or not
+func executed(finalExecutionStatus string) bool {
+	switch finalExecutionStatus {
+	case "EXECUTED_OPTIMISTIC", "EXECUTED", "FINAL":
+		return true
+	}
+	return false
+}
+
+// parseNep141Events returns the ft_transfer events of the logs of a receipt
+func parseNep141Events(logs []string) []nep141Event {
+	events := []nep141Event{}
+	for _, log := range logs {
+		if !strings.HasPrefix(log, nep141EventPrefix) {
+			continue
+		}
+		var event nep141Event
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(log, nep141EventPrefix)), &event); err != nil {
+			continue
+		}
+		if event.Standard == "nep141" && event.Event == "ft_transfer" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,263 @@
+package near
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const testAccessKey = `{"nonce":42,"permission":"FullAccess","block_height":150000000,"block_hash":"` + testBlockHash + `"}`
+
+// testCallResult returns the result of a view call returning data, an array of bytes
+func testCallResult(data string) string {
+	raw, _ := json.Marshal([]int{})
+	if data != "" {
+		ints := make([]int, len(data))
+		for i := range data {
+			ints[i] = int(data[i])
+		}
+		raw, _ = json.Marshal(ints)
+	}
+	return fmt.Sprintf(`{"result":%s,"logs":[],"block_height":150000000}`, raw)
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInput() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, testAccessKey)
+	defer close()
+
+	client, err := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.NEAR, URL: server.URL})
+	require.NoError(err)
+	input, err := client.FetchTxInput(s.Ctx, testAddress, "bob.testnet")
+	require.NoError(err)
+	txInput := input.(*TxInput)
+	require.Equal(xc.DriverNear, txInput.Type)
+	require.EqualValues(43, txInput.Nonce)
+	require.Equal(testPublicKey, fmt.Sprintf("%x", txInput.PublicKey))
+	require.Equal(testInput().BlockHash, txInput.BlockHash)
+	require.Equal("0", txInput.StorageDeposit.String())
+
+	// named account
+	server.Counter = 0
+	server.Response = []string{
+		`{"keys":[{"public_key":"ed25519:FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z","access_key":{"nonce":1,"permission":"FullAccess"}},{"public_key":"ed25519:4wBqpZM9xaSheZzJSMawUKKwhdpChKbZ5eu5ky4Vigw","access_key":{"nonce":1,"permission":{"FunctionCall":{"allowance":null,"receiver_id":"app.near","method_names":[]}}}}]}`,
+		testAccessKey,
+	}
+	input, err = client.FetchTxInput(s.Ctx, "alice.testnet", "bob.testnet")
+	require.NoError(err)
+	require.Equal(testPublicKey, fmt.Sprintf("%x", input.(*TxInput).PublicKey))
+
+	server.Counter = 0
+	server.Response = errors.New(`{"name":"HANDLER_ERROR","cause":{"name":"UNKNOWN_ACCESS_KEY","info":{}},"code":-32000,"message":"Server error","data":"Access key for public key ed25519:FVen does not exist"}`)
+	_, err = client.FetchTxInput(s.Ctx, testAddress, "bob.testnet")
+	require.ErrorContains(err, "UNKNOWN_ACCESS_KEY: Access key for public key ed25519:FVen does not exist")
+	require.Equal(xc.TransactionFailure, CheckError(err))
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenTxInput() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		testAccessKey,
+		testCallResult("null"),
+		testCallResult(`{"min":"1250000000000000000000","max":"1250000000000000000000"}`),
+	})
+	defer close()
+
+	token := *testToken
+	token.NativeAssetConfig = &xc.NativeAssetConfig{NativeAsset: xc.NEAR, URL: server.URL}
+	client, _ := NewClient(&token)
+	input, err := client.FetchTxInput(s.Ctx, testAddress, "bob.testnet")
+	require.NoError(err)
+	require.Equal("1250000000000000000000", input.(*TxInput).StorageDeposit.String())
+
+	// registered recipient
+	server.Counter = 0
+	server.Response = []string{
+		testAccessKey,
+		testCallResult(`{"total":"1250000000000000000000","available":"0"}`),
+	}
+	input, err = client.FetchTxInput(s.Ctx, testAddress, "bob.testnet")
+	require.NoError(err)
+	require.Equal("0", input.(*TxInput).StorageDeposit.String())
+
+	server.Counter = 0
+	server.Response = []string{
+		testAccessKey,
+		`{"error":"wasm execution failed with error: MethodNotFound","logs":[],"block_height":150000000}`,
+	}
+	_, err = client.FetchTxInput(s.Ctx, testAddress, "bob.testnet")
+	require.ErrorContains(err, "MethodNotFound")
+}
+
+func (s *CrosschainTestSuite) TestSubmitTx() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"final_execution_status":"NONE"}`)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.NEAR, URL: server.URL})
+	tx, _ := TxBuilder{Asset: client.Asset}.NewNativeTransfer(testAddress, "bob.testnet", xc.NewAmountBlockchainFromUint64(1), testInput())
+	require.Error(client.SubmitTx(s.Ctx, tx))
+	require.NoError(tx.AddSignatures(make([]byte, 64)))
+	require.NoError(client.SubmitTx(s.Ctx, tx))
+	require.Equal(1, server.Counter)
+
+	server.Response = errors.New(`{"name":"HANDLER_ERROR","cause":{"name":"INVALID_TRANSACTION","info":{}},"code":-32000,"message":"Server error","data":{"TxExecutionError":{"InvalidTxError":{"NotEnoughBalance":{}}}}}`)
+	err := client.SubmitTx(s.Ctx, tx)
+	require.Error(err)
+	require.Equal(xc.NoBalance, CheckError(err))
+}
+
+const testTxStatus = `{
+	"final_execution_status": "%s",
+	"status": %s,
+	"transaction": {
+		"signer_id": "` + testPublicKey + `",
+		"receiver_id": "%s",
+		"hash": "8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL",
+		"actions": [%s]
+	},
+	"transaction_outcome": {
+		"id": "8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL",
+		"block_hash": "A1",
+		"outcome": {"logs": [], "gas_burnt": 2428000000000, "tokens_burnt": "242800000000000000000", "executor_id": "` + testPublicKey + `", "status": {"SuccessReceiptId": "R1"}}
+	},
+	"receipts_outcome": [
+		{"id": "R1", "block_hash": "B2", "outcome": {"logs": [%s], "gas_burnt": 2428000000000, "tokens_burnt": "242800000000000000000", "executor_id": "%s", "status": {"SuccessValue": ""}}},
+		{"id": "R2", "block_hash": "B3", "outcome": {"logs": [], "gas_burnt": 0, "tokens_burnt": "0", "executor_id": "` + testPublicKey + `", "status": {"SuccessValue": ""}}}
+	]
+}`
+
+func testBlock(hash string, height int) string {
+	return fmt.Sprintf(`{"header":{"height":%d,"hash":"%s","timestamp":1700000000123456789}}`, height, hash)
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfo() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		fmt.Sprintf(testTxStatus, "FINAL", `{"SuccessValue":""}`, "bob.testnet", `{"Transfer":{"deposit":"1000000000000000000000000"}}`, "", "bob.testnet"),
+		testBlock("A1", 100),
+		testBlock("B2", 101),
+		testBlock("B3", 102),
+		testBlock("F", 110),
+	})
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.NEAR, URL: server.URL})
+	txHash := TxHashWithSender("8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL", testAddress)
+	info, err := client.FetchTxInfo(s.Ctx, txHash)
+	require.NoError(err)
+	require.Equal("8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL", info.TxID)
+	require.Equal(xc.TxStatusSuccess, info.Status)
+	require.Equal(testAddress, info.From)
+	require.Equal(xc.Address("bob.testnet"), info.To)
+	require.Equal("1000000000000000000000000", info.Amount.String())
+	require.Equal("485600000000000000000", info.Fee.String())
+	require.EqualValues(4856000000000, info.GasUsed)
+	require.Equal("A1", info.BlockHash)
+	require.EqualValues(100, info.BlockIndex)
+	require.EqualValues(1700000000, info.BlockTime)
+	// from the last receipt, block 102, to the final block 110
+	require.EqualValues(9, info.Confirmations)
+	require.Len(info.Destinations, 1)
+	require.Equal(5, server.Counter)
+
+	// receipts not executed yet
+	server.Counter = 0
+	server.Response = []string{
+		fmt.Sprintf(testTxStatus, "INCLUDED_FINAL", `{}`, "bob.testnet", `{"Transfer":{"deposit":"1"}}`, "", "bob.testnet"),
+		testBlock("A1", 100),
+	}
+	info, err = client.FetchTxInfo(s.Ctx, txHash)
+	require.NoError(err)
+	require.EqualValues(0, info.Confirmations)
+	require.Equal(2, server.Counter)
+
+	_, err = client.FetchTxInfo(s.Ctx, "8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL")
+	require.EqualError(err, "missing sender of tx '8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL', expected <hash>@<sender>")
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenTxInfo() {
+	require := s.Require()
+	event := `"EVENT_JSON:{\"standard\":\"nep141\",\"version\":\"1.0.0\",\"event\":\"ft_transfer\",\"data\":[{\"old_owner_id\":\"` + testPublicKey + `\",\"new_owner_id\":\"bob.testnet\",\"amount\":\"1000000\"}]}"`
+	ftTransfer := `{"FunctionCall":{"method_name":"ft_transfer","args":"e30=","gas":30000000000000,"deposit":"1"}}`
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		fmt.Sprintf(testTxStatus, "EXECUTED", `{"SuccessValue":""}`, "usdc.fakes.testnet", ftTransfer, event, "usdc.fakes.testnet"),
+		testBlock("A1", 100),
+		testBlock("B2", 101),
+		testBlock("B3", 102),
+		testBlock("F", 101),
+	})
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.NEAR, URL: server.URL})
+	info, err := client.FetchTxInfo(s.Ctx, TxHashWithSender("8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL", testAddress))
+	require.NoError(err)
+	require.Equal(xc.Address("bob.testnet"), info.To)
+	require.Equal(xc.ContractAddress("usdc.fakes.testnet"), info.ContractAddress)
+	require.Equal("1000000", info.Amount.String())
+	require.Len(info.Sources, 1)
+	require.Len(info.Destinations, 1)
+	require.Equal(xc.ContractAddress("usdc.fakes.testnet"), info.Destinations[0].ContractAddress)
+	// the last receipt isn't final yet
+	require.EqualValues(0, info.Confirmations)
+
+	// failed call
+	server.Counter = 0
+	server.Response = []string{
+		fmt.Sprintf(testTxStatus, "FINAL", `{"Failure":{"ActionError":{"index":0,"kind":{"FunctionCallError":{"ExecutionError":"Smart contract panicked: The account doesn't have enough balance"}}}}}`, "usdc.fakes.testnet", ftTransfer, "", "usdc.fakes.testnet"),
+		testBlock("A1", 100),
+		testBlock("B2", 101),
+		testBlock("B3", 102),
+		testBlock("F", 110),
+	}
+	info, err = client.FetchTxInfo(s.Ctx, TxHashWithSender("8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL", testAddress))
+	require.NoError(err)
+	require.Equal(xc.TxStatusFailure, info.Status)
+	require.Contains(info.Error, "Smart contract panicked")
+	require.Empty(info.Destinations)
+}
+
+func (s *CrosschainTestSuite) TestFetchBalance() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"amount":"2000000000000000000000000","locked":"0","storage_usage":182}`)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.NEAR, URL: server.URL})
+	balance, err := client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("2000000000000000000000000", balance.String())
+
+	server.Response = errors.New(`{"name":"HANDLER_ERROR","cause":{"name":"UNKNOWN_ACCOUNT","info":{}},"code":-32000,"message":"Server error","data":"account does not exist"}`)
+	balance, err = client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("0", balance.String())
+
+	token := *testToken
+	token.NativeAssetConfig = &xc.NativeAssetConfig{NativeAsset: xc.NEAR, URL: server.URL}
+	client, _ = NewClient(&token)
+	server.Response = testCallResult(`"1500000"`)
+	balance, err = client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("1500000", balance.String())
+}
+
+func (s *CrosschainTestSuite) TestEstimateGas() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"gas_price":"100000000"}`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.NEAR, URL: server.URL})
+	price, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("100000000", price.String())
+
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(200000000), nil
+	})
+	price, err = client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("200000000", price.String())
+	require.Equal(1, server.Counter)
+}
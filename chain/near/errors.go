@@ -0,0 +1,33 @@
+package near
+
+import (
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// CheckError classifies the errors of the JSON-RPC API of nearcore, named by the cause of the error
+func CheckError(err error) xc.ClientError {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "notenoughbalance") ||
+		strings.Contains(msg, "lackbalanceforstate") {
+		return xc.NoBalance
+	}
+	if strings.Contains(msg, "notenoughallowance") {
+		return xc.NoBalanceForGas
+	}
+	if strings.Contains(msg, "invalidnonce") ||
+		strings.Contains(msg, "expired") ||
+		strings.Contains(msg, "invalid_transaction") ||
+		strings.Contains(msg, "invalidsignature") ||
+		strings.Contains(msg, "unknown_access_key") ||
+		strings.Contains(msg, "functioncallerror") {
+		return xc.TransactionFailure
+	}
+	if strings.Contains(msg, "timeout_error") ||
+		strings.Contains(msg, "response body closed") ||
+		strings.Contains(msg, "eof") {
+		return xc.NetworkError
+	}
+	return xc.UnknownError
+}
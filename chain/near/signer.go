@@ -0,0 +1,60 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/mr-tron/base58"
+)
+
+// privateKeyPrefix is the prefix of the private keys of near-cli and wallets, e.g. ed25519:3D4Y...
+const privateKeyPrefix = "ed25519:"
+
+// Signer for NEAR
+type Signer struct {
+}
+
+var _ xc.Signer = &Signer{}
+var _ xc.PublicKeyDeriver = &Signer{}
+
+// NewSigner creates a new NEAR Signer
+func NewSigner(asset xc.ITask) (xc.Signer, error) {
+	return Signer{}, nil
+}
+
+// ImportPrivateKey imports a NEAR private key: ed25519: and the base58 of the 64 bytes of the key, as exported by
+// near-cli, or the hex of a 32 bytes seed
+func (signer Signer) ImportPrivateKey(privateKey string) (xc.PrivateKey, error) {
+	if strings.HasPrefix(privateKey, privateKeyPrefix) {
+		decoded, err := base58.Decode(strings.TrimPrefix(privateKey, privateKeyPrefix))
+		if err != nil || len(decoded) != ed25519.PrivateKeySize {
+			return nil, errors.New("invalid ed25519 private key")
+		}
+		return xc.PrivateKey(decoded), nil
+	}
+	seed, err := hex.DecodeString(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+	return xc.PrivateKey(ed25519.NewKeyFromSeed(seed)), nil
+}
+
+// Sign the hash of a NEAR tx
+func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+	}
+	return xc.TxSignature(ed25519.Sign(ed25519.PrivateKey(privateKey), []byte(data))), nil
+}
+
+// DerivePublicKey returns the ed25519 public key of a private key
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+	}
+	return xc.PublicKey(ed25519.PrivateKey(privateKey).Public().(ed25519.PublicKey)), nil
+}
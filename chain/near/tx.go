@@ -0,0 +1,139 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/mr-tron/base58"
+)
+
+// The borsh enum of the actions of txs, only function calls and transfers are built
+const (
+	actionFunctionCall = 2
+	actionTransfer     = 3
+)
+
+// TxInput for NEAR
+type TxInput struct {
+	xc.TxInputEnvelope
+	// PublicKey of the access key of the sender signing the tx
+	PublicKey []byte
+	// Nonce of the tx: the nonce of the access key, plus 1
+	Nonce uint64
+	// BlockHash of a recent block, the tx expires about a day after it
+	BlockHash []byte
+	// StorageDeposit registers the recipient of a NEP-141 transfer with the token contract, 0 if it's registered
+	StorageDeposit xc.AmountBlockchain
+}
+
+// NewTxInput returns a new NEAR TxInput
+func NewTxInput() *TxInput {
+	return &TxInput{
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverNear),
+	}
+}
+
+var _ xc.TxInputNonce = &TxInput{}
+
+// GetNonce returns the nonce of the access key signing the tx
+func (txInput *TxInput) GetNonce() uint64 {
+	return txInput.Nonce
+}
+
+// Action of a NEAR tx: a transfer of Deposit, or a call of MethodName with Args, Gas and Deposit
+type Action struct {
+	Kind       uint8
+	MethodName string
+	Args       []byte
+	Gas        uint64
+	Deposit    xc.AmountBlockchain
+}
+
+// Transaction is the unsigned tx: actions of SignerID executed by ReceiverID
+type Transaction struct {
+	SignerID   string
+	PublicKey  []byte
+	Nonce      uint64
+	ReceiverID string
+	BlockHash  []byte
+	Actions    []Action
+}
+
+// Tx for NEAR
+type Tx struct {
+	Transaction
+	signature []byte
+}
+
+var _ xc.Tx = &Tx{}
+
+// Hash returns the hash of the tx with its sender, see TxHashWithSender, as expected by FetchTxInfo
+// The hash alone, shown by explorers, is the base58 of the sha256 of the unsigned tx, see TxInfo.TxID
+func (tx Tx) Hash() xc.TxHash {
+	hash := sha256.Sum256(tx.Transaction.serialize())
+	return TxHashWithSender(xc.TxHash(base58.Encode(hash[:])), xc.Address(tx.SignerID))
+}
+
+// Sighashes returns the sha256 of the unsigned tx, signed by the access key of the sender
+func (tx Tx) Sighashes() ([]xc.TxDataToSign, error) {
+	if len(tx.BlockHash) == 0 {
+		return []xc.TxDataToSign{}, errors.New("transaction not initialized")
+	}
+	hash := sha256.Sum256(tx.Transaction.serialize())
+	return []xc.TxDataToSign{hash[:]}, nil
+}
+
+// AddSignatures adds the ed25519 signature of the tx
+func (tx *Tx) AddSignatures(signatures ...xc.TxSignature) error {
+	if len(signatures) != 1 {
+		return errors.New("expecting 1 signature")
+	}
+	if len(signatures[0]) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature length %d", len(signatures[0]))
+	}
+	tx.signature = signatures[0]
+	return nil
+}
+
+// Serialize returns the borsh of the signed tx
+func (tx Tx) Serialize() ([]byte, error) {
+	if len(tx.signature) == 0 {
+		return []byte{}, errors.New("unable to serialize without first calling AddSignatures(...)")
+	}
+	w := &borshWriter{}
+	w.fixed(tx.Transaction.serialize())
+	w.u8(keyTypeEd25519)
+	w.fixed(tx.signature)
+	return w.Bytes(), nil
+}
+
+func (transaction Transaction) serialize() []byte {
+	w := &borshWriter{}
+	w.string(transaction.SignerID)
+	w.u8(keyTypeEd25519)
+	w.fixed(transaction.PublicKey)
+	w.u64(transaction.Nonce)
+	w.string(transaction.ReceiverID)
+	w.fixed(transaction.BlockHash)
+	w.u32(uint32(len(transaction.Actions)))
+	for _, action := range transaction.Actions {
+		w.u8(action.Kind)
+		switch action.Kind {
+		case actionFunctionCall:
+			w.string(action.MethodName)
+			w.bytes(action.Args)
+			w.u64(action.Gas)
+			w.u128(action.Deposit.Int())
+		case actionTransfer:
+			w.u128(action.Deposit.Int())
+		}
+	}
+	return w.Bytes()
+}
+
+// maxU128 is the max deposit of actions
+var maxU128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
@@ -0,0 +1,153 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/mr-tron/base58"
+)
+
+const testBlockHash = "4wBqpZM9xaSheZzJSMawUKKwhdpChKbZ5eu5ky4Vigw"
+
+var testToken = &xc.TokenAssetConfig{
+	Asset:             "USDC",
+	Contract:          "usdc.fakes.testnet",
+	Decimals:          6,
+	NativeAssetConfig: &xc.NativeAssetConfig{NativeAsset: xc.NEAR},
+}
+
+func testInput() *TxInput {
+	input := NewTxInput()
+	input.PublicKey, _ = hex.DecodeString(testPublicKey)
+	input.BlockHash, _ = base58.Decode(testBlockHash)
+	input.Nonce = 43
+	input.StorageDeposit = xc.NewAmountBlockchainFromUint64(0)
+	return input
+}
+
+func (s *CrosschainTestSuite) TestNewNativeTransfer() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.NEAR})
+	amount := xc.NewAmountBlockchainFromStr("1000000000000000000000000")
+	tx, err := builder.NewTransfer(testAddress, "bob.testnet", amount, testInput())
+	require.NoError(err)
+	nearTx := tx.(*Tx)
+	require.Equal(string(testAddress), nearTx.SignerID)
+	require.Equal("bob.testnet", nearTx.ReceiverID)
+	require.Len(nearTx.Actions, 1)
+	require.EqualValues(actionTransfer, nearTx.Actions[0].Kind)
+
+	// borsh of the unsigned tx, and its hash
+	require.Equal("400000006437356139383031383262313061623764353462666564336339363430373361306565313732663364616136323332356166303231613638663730373531316100d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a2b000000000000000b000000626f622e746573746e65740102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f200100000003000000a1edccce1bc2d3000000000000", hex.EncodeToString(nearTx.Transaction.serialize()))
+	require.Equal(TxHashWithSender("8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL", testAddress), tx.Hash())
+
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes, 1)
+	hash, _ := base58.Decode("8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL")
+	require.Equal(hash, []byte(sighashes[0]))
+
+	_, err = tx.Serialize()
+	require.Error(err)
+	signer, _ := NewSigner(&xc.NativeAssetConfig{NativeAsset: xc.NEAR})
+	privateKey, err := signer.ImportPrivateKey(testSeed)
+	require.NoError(err)
+	signature, err := signer.Sign(privateKey, sighashes[0])
+	require.NoError(err)
+	require.NoError(tx.AddSignatures(signature))
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	require.Equal(nearTx.Transaction.serialize(), serialized[:len(serialized)-65])
+	require.EqualValues(0, serialized[len(serialized)-65])
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	require.True(ed25519.Verify(publicKey, sighashes[0], serialized[len(serialized)-64:]))
+	// the hash doesn't depend on the signature
+	require.Equal(TxHashWithSender("8QTQWh3sh1bqkAY2gLTdPVZ2evaTyVhitSw8q6b3vUKL", testAddress), tx.Hash())
+}
+
+func (s *CrosschainTestSuite) TestNewTokenTransfer() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testToken)
+	amount := xc.NewAmountBlockchainFromUint64(1_000_000)
+	input := testInput()
+	input.StorageDeposit = xc.NewAmountBlockchainFromStr("1250000000000000000000")
+	tx, err := builder.NewTransfer(testAddress, "bob.testnet", amount, input)
+	require.NoError(err)
+	nearTx := tx.(*Tx)
+	require.Equal("usdc.fakes.testnet", nearTx.ReceiverID)
+	require.Len(nearTx.Actions, 2)
+	require.Equal("storage_deposit", nearTx.Actions[0].MethodName)
+	require.Equal(`{"account_id":"bob.testnet","registration_only":true}`, string(nearTx.Actions[0].Args))
+	require.Equal("ft_transfer", nearTx.Actions[1].MethodName)
+	require.Equal(`{"receiver_id":"bob.testnet","amount":"1000000"}`, string(nearTx.Actions[1].Args))
+	require.Equal("1", nearTx.Actions[1].Deposit.String())
+	require.Equal(TxHashWithSender("3vNpZotuvqZtDcA3zcFg5puBFY375aywfK2iBdQ6SG74", testAddress), tx.Hash())
+
+	// registered recipient
+	tx, err = builder.NewTransfer(testAddress, "bob.testnet", amount, testInput())
+	require.NoError(err)
+	require.Len(tx.(*Tx).Actions, 1)
+	require.Equal("ft_transfer", tx.(*Tx).Actions[0].MethodName)
+
+	tokenBuilder := builder.(xc.TxTokenBuilder)
+	_, err = tokenBuilder.NewTokenTransfer(testAddress, "bob.testnet", amount, testInput())
+	require.NoError(err)
+}
+
+func (s *CrosschainTestSuite) TestNewTransferErrors() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.NEAR})
+	amount := xc.NewAmountBlockchainFromUint64(1)
+
+	_, err := builder.NewTransfer(testAddress, "bob.testnet", amount, &xc.TxInputEnvelope{})
+	require.EqualError(err, "xc.TxInput is not from a near chain")
+	_, err = builder.NewTransfer(testAddress, "bob.testnet", amount, NewTxInput())
+	require.EqualError(err, "invalid input: missing access key or block hash")
+	_, err = builder.NewTransfer("Alice", "bob.testnet", amount, testInput())
+	require.ErrorContains(err, "invalid from address 'Alice'")
+	_, err = builder.NewTransfer(testAddress, "bob..testnet", amount, testInput())
+	require.ErrorContains(err, "invalid to address 'bob..testnet'")
+	_, err = builder.NewTransfer(testAddress, "bob.testnet", xc.NewAmountBlockchainFromUint64(0), testInput())
+	require.EqualError(err, "invalid amount 0")
+	_, err = builder.NewTransfer(testAddress, "bob.testnet", xc.NewAmountBlockchainFromStr("340282366920938463463374607431768211456"), testInput())
+	require.EqualError(err, "invalid amount 340282366920938463463374607431768211456")
+
+	builder, _ = NewTxBuilder(&xc.TokenAssetConfig{Contract: "USDC", NativeAssetConfig: &xc.NativeAssetConfig{NativeAsset: xc.NEAR}})
+	_, err = builder.NewTransfer(testAddress, "bob.testnet", amount, testInput())
+	require.ErrorContains(err, "invalid contract 'USDC'")
+}
+
+func (s *CrosschainTestSuite) TestAddSignatures() {
+	require := s.Require()
+	tx := &Tx{}
+	_, err := tx.Sighashes()
+	require.EqualError(err, "transaction not initialized")
+	require.EqualError(tx.AddSignatures(), "expecting 1 signature")
+	require.EqualError(tx.AddSignatures(make([]byte, 65)), "invalid signature length 65")
+}
+
+func (s *CrosschainTestSuite) TestImportPrivateKey() {
+	require := s.Require()
+	signer, _ := NewSigner(&xc.NativeAssetConfig{NativeAsset: xc.NEAR})
+	seed, _ := hex.DecodeString(testSeed)
+	expected := ed25519.NewKeyFromSeed(seed)
+
+	privateKey, err := signer.ImportPrivateKey(testSeed)
+	require.NoError(err)
+	require.Equal([]byte(expected), []byte(privateKey))
+
+	// near-cli format
+	privateKey, err = signer.ImportPrivateKey("ed25519:" + base58.Encode(expected))
+	require.NoError(err)
+	require.Equal([]byte(expected), []byte(privateKey))
+
+	publicKey, err := signer.(xc.PublicKeyDeriver).DerivePublicKey(privateKey)
+	require.NoError(err)
+	require.Equal(testPublicKey, hex.EncodeToString(publicKey))
+
+	_, err = signer.ImportPrivateKey("ed25519:" + base58.Encode(seed))
+	require.EqualError(err, "invalid ed25519 private key")
+	_, err = signer.ImportPrivateKey("00")
+	require.EqualError(err, "invalid ed25519 private key")
+}
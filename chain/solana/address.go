@@ -11,6 +11,8 @@ import (
 type AddressBuilder struct {
 }
 
+var _ xc.AddressValidator = &AddressBuilder{}
+
 // NewAddressBuilder creates a new Solana AddressBuilder
 func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
 	return AddressBuilder{}, nil
@@ -34,3 +36,17 @@ func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []b
 		},
 	}, err
 }
+
+// ValidateAddress checks an address is a base58 encoded 32 bytes public key
+// The key isn't checked to be on the ed25519 curve: program derived addresses, e.g. of token accounts or multisig vaults,
+// are off the curve and valid destinations
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	decoded := base58.Decode(string(address))
+	if len(address) == 0 || len(decoded) == 0 {
+		return fmt.Errorf("invalid address '%s': not base58", address)
+	}
+	if len(decoded) != 32 {
+		return fmt.Errorf("invalid address '%s': expected 32 bytes, got %d bytes", address, len(decoded))
+	}
+	return nil
+}
@@ -45,3 +45,16 @@ func (s *CrosschainTestSuite) TestGetAllPossibleAddressesFromPublicKey() {
 	require.Equal(xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"), addresses[0].Address)
 	require.Equal(xc.AddressTypeDefault, addresses[0].Type)
 }
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{})
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"))
+	// associated token account, off the curve
+	require.NoError(validator.ValidateAddress("DvSgNMRxVSMBpLp4hZeBrmQo8ZRFne72actTZ3PYE3AA"))
+
+	require.EqualError(validator.ValidateAddress("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2H"), "invalid address 'Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2H': expected 32 bytes, got 27 bytes")
+	require.EqualError(validator.ValidateAddress("0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fCe1"), "invalid address '0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fCe1': not base58")
+	require.Error(validator.ValidateAddress(""))
+}
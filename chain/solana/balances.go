@@ -0,0 +1,229 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Layout of SPL token accounts
+const (
+	TokenAccountSize        = 165
+	tokenAccountMintOffset  = 0
+	tokenAccountOwnerOffset = 32
+)
+
+// TokenAccountRent is the rent exempt balance of a token account, in lamports
+const TokenAccountRent = 2_039_280
+
+// MaxMultipleAccounts is the max number of accounts of a getMultipleAccounts request
+const MaxMultipleAccounts = 100
+
+// TokenAccount is an SPL token account and its balance
+type TokenAccount struct {
+	Address xc.Address
+	Mint    xc.ContractAddress
+	Amount  xc.AmountBlockchain
+	// Associated is set for the associated token account of the owner and mint
+	Associated bool
+}
+
+// FetchTokenAccounts returns all SPL token accounts owned by address, of mint if set, sorted by mint and address
+// Funds may be held in token accounts other than the associated token account, which per-mint queries miss.
+// Accounts are enumerated with getProgramAccounts. Strict RPCs that reject the request or its response size
+// are retried listing keys only and paging the accounts with getMultipleAccounts, then with getTokenAccountsByOwner.
+func (client *Client) FetchTokenAccounts(ctx context.Context, address xc.Address, mint xc.ContractAddress) ([]*TokenAccount, error) {
+	owner, accounts, err := client.fetchTokenAccounts(ctx, address, mint)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*TokenAccount{}
+	for key, account := range accounts {
+		tokenAcct, err := decodeTokenAccount(key, account)
+		if err != nil {
+			return nil, err
+		}
+		// getTokenAccountsByOwner isn't filtered by mint when listing all accounts
+		if tokenAcct.Owner != owner || (mint != "" && tokenAcct.Mint.String() != string(mint)) {
+			continue
+		}
+		ata, _, err := solana.FindAssociatedTokenAddress(owner, tokenAcct.Mint)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &TokenAccount{
+			Address:    xc.Address(key.String()),
+			Mint:       xc.ContractAddress(tokenAcct.Mint.String()),
+			Amount:     xc.NewAmountBlockchainFromUint64(tokenAcct.Amount),
+			Associated: ata == key,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Mint != result[j].Mint {
+			return result[i].Mint < result[j].Mint
+		}
+		return result[i].Address < result[j].Address
+	})
+	return result, nil
+}
+
+// fetchTokenAccounts returns the token accounts of address, of mint if set, falling back to the requests of strict RPCs
+// Accounts must still be checked for their owner and mint
+func (client *Client) fetchTokenAccounts(ctx context.Context, address xc.Address, mint xc.ContractAddress) (solana.PublicKey, map[solana.PublicKey]*rpc.Account, error) {
+	owner, err := solana.PublicKeyFromBase58(string(address))
+	if err != nil {
+		return owner, nil, fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	filters := []rpc.RPCFilter{
+		{DataSize: TokenAccountSize},
+		{Memcmp: &rpc.RPCFilterMemcmp{Offset: tokenAccountOwnerOffset, Bytes: owner.Bytes()}},
+	}
+	if mint != "" {
+		mintKey, err := solana.PublicKeyFromBase58(string(mint))
+		if err != nil {
+			return owner, nil, fmt.Errorf("invalid mint '%s': %v", mint, err)
+		}
+		filters = append(filters, rpc.RPCFilter{Memcmp: &rpc.RPCFilterMemcmp{Offset: tokenAccountMintOffset, Bytes: mintKey.Bytes()}})
+	}
+
+	accounts, err := client.fetchProgramTokenAccounts(ctx, filters)
+	if err != nil {
+		accounts, err = client.fetchProgramTokenAccountsPaged(ctx, filters)
+	}
+	if err != nil {
+		accounts, err = client.fetchOwnerTokenAccounts(ctx, owner, mint)
+	}
+	if err != nil {
+		return owner, nil, fmt.Errorf("failed to fetch token accounts of '%s': %v", address, err)
+	}
+	return owner, accounts, nil
+}
+
+func decodeTokenAccount(key solana.PublicKey, account *rpc.Account) (*token.Account, error) {
+	var tokenAcct token.Account
+	if err := bin.NewBinDecoder(account.Data.GetBinary()).Decode(&tokenAcct); err != nil {
+		return nil, fmt.Errorf("failed to decode token account %s: %v", key, err)
+	}
+	return &tokenAcct, nil
+}
+
+// FetchTokenBalances returns the balances of all tokens held by address, summed across its token accounts
+func (client *Client) FetchTokenBalances(ctx context.Context, address xc.Address) (map[xc.ContractAddress]xc.AmountBlockchain, error) {
+	accounts, err := client.FetchTokenAccounts(ctx, address, "")
+	if err != nil {
+		return nil, err
+	}
+	balances := map[xc.ContractAddress]xc.AmountBlockchain{}
+	for _, account := range accounts {
+		balance, ok := balances[account.Mint]
+		if !ok {
+			balance = xc.NewAmountBlockchainFromUint64(0)
+		}
+		balances[account.Mint] = balance.Add(&account.Amount)
+	}
+	return balances, nil
+}
+
+// FetchTotalTokenBalance returns the balance of a token held by address across all its token accounts,
+// unlike FetchBalanceForAsset which only queries the associated token account
+func (client *Client) FetchTotalTokenBalance(ctx context.Context, address xc.Address, mint xc.ContractAddress) (xc.AmountBlockchain, error) {
+	total := xc.NewAmountBlockchainFromUint64(0)
+	accounts, err := client.FetchTokenAccounts(ctx, address, mint)
+	if err != nil {
+		return total, err
+	}
+	for _, account := range accounts {
+		total = total.Add(&account.Amount)
+	}
+	return total, nil
+}
+
+func (client *Client) fetchProgramTokenAccounts(ctx context.Context, filters []rpc.RPCFilter) (map[solana.PublicKey]*rpc.Account, error) {
+	out, err := client.SolClient.GetProgramAccountsWithOpts(ctx, solana.TokenProgramID, &rpc.GetProgramAccountsOpts{
+		Commitment: rpc.CommitmentFinalized,
+		Filters:    filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	accounts := map[solana.PublicKey]*rpc.Account{}
+	for _, keyed := range out {
+		if keyed == nil || keyed.Account == nil || keyed.Account.Data == nil {
+			continue
+		}
+		accounts[keyed.Pubkey] = keyed.Account
+	}
+	return accounts, nil
+}
+
+// fetchProgramTokenAccountsPaged lists the keys of the accounts only, and fetches their data by pages
+func (client *Client) fetchProgramTokenAccountsPaged(ctx context.Context, filters []rpc.RPCFilter) (map[solana.PublicKey]*rpc.Account, error) {
+	offset, length := uint64(0), uint64(0)
+	out, err := client.SolClient.GetProgramAccountsWithOpts(ctx, solana.TokenProgramID, &rpc.GetProgramAccountsOpts{
+		Commitment: rpc.CommitmentFinalized,
+		Filters:    filters,
+		DataSlice:  &rpc.DataSlice{Offset: &offset, Length: &length},
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]solana.PublicKey, 0, len(out))
+	for _, keyed := range out {
+		if keyed != nil {
+			keys = append(keys, keyed.Pubkey)
+		}
+	}
+	accounts := map[solana.PublicKey]*rpc.Account{}
+	for start := 0; start < len(keys); start += MaxMultipleAccounts {
+		end := start + MaxMultipleAccounts
+		if end > len(keys) {
+			end = len(keys)
+		}
+		page, err := client.SolClient.GetMultipleAccountsWithOpts(ctx, keys[start:end], &rpc.GetMultipleAccountsOpts{
+			Commitment: rpc.CommitmentFinalized,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, account := range page.Value {
+			// closed since listed
+			if account == nil || account.Data == nil || start+i >= end {
+				continue
+			}
+			accounts[keys[start+i]] = account
+		}
+	}
+	return accounts, nil
+}
+
+func (client *Client) fetchOwnerTokenAccounts(ctx context.Context, owner solana.PublicKey, mint xc.ContractAddress) (map[solana.PublicKey]*rpc.Account, error) {
+	conf := &rpc.GetTokenAccountsConfig{ProgramId: &solana.TokenProgramID}
+	if mint != "" {
+		mintKey := solana.MustPublicKeyFromBase58(string(mint))
+		conf = &rpc.GetTokenAccountsConfig{Mint: &mintKey}
+	}
+	out, err := client.SolClient.GetTokenAccountsByOwner(ctx, owner, conf, &rpc.GetTokenAccountsOpts{
+		Commitment: rpc.CommitmentFinalized,
+	})
+	if err != nil {
+		return nil, err
+	}
+	accounts := map[solana.PublicKey]*rpc.Account{}
+	if out == nil {
+		return accounts, nil
+	}
+	for _, account := range out.Value {
+		if account == nil || account.Account.Data == nil {
+			continue
+		}
+		accounts[account.Pubkey] = &account.Account
+	}
+	return accounts, nil
+}
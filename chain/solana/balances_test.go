@@ -0,0 +1,133 @@
+package solana
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const (
+	balanceOwner = "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"
+	balanceMint  = "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"
+	balanceMint2 = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	// token account other than the associated token account
+	auxTokenAccount = "So11111111111111111111111111111111111111112"
+)
+
+func tokenAccountData(mint string, owner string, amount uint64) string {
+	data := make([]byte, TokenAccountSize)
+	copy(data[0:32], solana.MustPublicKeyFromBase58(mint).Bytes())
+	copy(data[32:64], solana.MustPublicKeyFromBase58(owner).Bytes())
+	binary.LittleEndian.PutUint64(data[64:72], amount)
+	// initialized
+	data[108] = 1
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func keyedTokenAccount(address string, mint string, owner string, amount uint64) string {
+	return fmt.Sprintf(`{"pubkey":"%s","account":{"data":["%s","base64"],"executable":false,"lamports":2039280,"owner":"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA","rentEpoch":361}}`,
+		address, tokenAccountData(mint, owner, amount))
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenAccounts() {
+	require := s.Require()
+	ata, _ := FindAssociatedTokenAddress(balanceOwner, balanceMint)
+	server, close := test.MockJSONRPC(&s.Suite, `[`+
+		keyedTokenAccount(ata, balanceMint, balanceOwner, 100)+`,`+
+		keyedTokenAccount(auxTokenAccount, balanceMint, balanceOwner, 23)+`]`)
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{URL: server.URL})
+
+	accounts, err := client.FetchTokenAccounts(s.Ctx, balanceOwner, balanceMint)
+	require.NoError(err)
+	require.Equal(1, server.Counter)
+	require.Len(accounts, 2)
+	// sorted by address
+	require.Equal(&TokenAccount{
+		Address:    xc.Address(ata),
+		Mint:       balanceMint,
+		Amount:     xc.NewAmountBlockchainFromUint64(100),
+		Associated: true,
+	}, accounts[0])
+	require.Equal(xc.Address(auxTokenAccount), accounts[1].Address)
+	require.Equal("23", accounts[1].Amount.String())
+	require.False(accounts[1].Associated)
+
+	_, err = client.FetchTokenAccounts(s.Ctx, "invalid", "")
+	require.ErrorContains(err, "invalid address 'invalid'")
+	_, err = client.FetchTokenAccounts(s.Ctx, balanceOwner, "invalid")
+	require.ErrorContains(err, "invalid mint 'invalid'")
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenBalances() {
+	require := s.Require()
+	ata, _ := FindAssociatedTokenAddress(balanceOwner, balanceMint)
+	ata2, _ := FindAssociatedTokenAddress(balanceOwner, balanceMint2)
+	server, close := test.MockJSONRPC(&s.Suite, `[`+
+		keyedTokenAccount(ata, balanceMint, balanceOwner, 100)+`,`+
+		keyedTokenAccount(auxTokenAccount, balanceMint, balanceOwner, 23)+`,`+
+		keyedTokenAccount(ata2, balanceMint2, balanceOwner, 5)+`]`)
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{URL: server.URL})
+
+	balances, err := client.FetchTokenBalances(s.Ctx, balanceOwner)
+	require.NoError(err)
+	require.Len(balances, 2)
+	require.Equal("123", balances[balanceMint].String())
+	require.Equal("5", balances[balanceMint2].String())
+
+	accounts, err := client.FetchTokenAccounts(s.Ctx, balanceOwner, balanceMint2)
+	require.NoError(err)
+	require.Len(accounts, 1)
+	require.True(accounts[0].Associated)
+	require.Equal(xc.Address(ata2), accounts[0].Address)
+}
+
+func (s *CrosschainTestSuite) TestFetchTotalTokenBalanceFallbacks() {
+	require := s.Require()
+	ata, _ := FindAssociatedTokenAddress(balanceOwner, balanceMint)
+
+	// getProgramAccounts is rejected, the keys are listed and paged
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`{"jsonrpc":"2.0","error":{"message":"response too large","code":-32010},"id":0}`,
+		fmt.Sprintf(`[{"pubkey":"%s","account":{"data":["","base64"],"executable":false,"lamports":2039280,"owner":"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA","rentEpoch":361}},{"pubkey":"%s","account":{"data":["","base64"],"executable":false,"lamports":2039280,"owner":"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA","rentEpoch":361}}]`, ata, auxTokenAccount),
+		fmt.Sprintf(`{"context":{"slot":1},"value":[{"data":["%s","base64"],"executable":false,"lamports":2039280,"owner":"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA","rentEpoch":361},null]}`, tokenAccountData(balanceMint, balanceOwner, 100)),
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{URL: server.URL})
+	balance, err := client.FetchTotalTokenBalance(s.Ctx, balanceOwner, balanceMint)
+	require.NoError(err)
+	require.Equal("100", balance.String())
+	require.Equal(3, server.Counter)
+
+	// all methods fail
+	server.Counter = 0
+	server.Response = errors.New(`{"message": "method is not available", "code": -32601}`)
+	balance, err = client.FetchTotalTokenBalance(s.Ctx, balanceOwner, balanceMint)
+	require.ErrorContains(err, "failed to fetch token accounts of '"+balanceOwner+"'")
+	require.Equal("0", balance.String())
+	require.Equal(3, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenAccountsByOwnerFallback() {
+	require := s.Require()
+	ata, _ := FindAssociatedTokenAddress(balanceOwner, balanceMint)
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`{"jsonrpc":"2.0","error":{"message":"method is not available","code":-32601},"id":0}`,
+		`{"jsonrpc":"2.0","error":{"message":"method is not available","code":-32601},"id":0}`,
+		`{"context":{"slot":1},"value":[` +
+			keyedTokenAccount(ata, balanceMint, balanceOwner, 100) + `,` +
+			keyedTokenAccount(auxTokenAccount, balanceMint2, balanceOwner, 7) + `]}`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{URL: server.URL})
+	balance, err := client.FetchTotalTokenBalance(s.Ctx, balanceOwner, balanceMint)
+	require.NoError(err)
+	require.Equal("100", balance.String())
+	require.Equal(3, server.Counter)
+}
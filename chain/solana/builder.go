@@ -25,6 +25,9 @@ func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
 
 // NewTransfer creates a new transfer for an Asset, either native or token
 func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
 	if _, ok := txBuilder.Asset.(*xc.TaskConfig); ok {
 		return txBuilder.NewTask(from, to, amount, input)
 	}
@@ -36,12 +39,18 @@ func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc
 			return txBuilder.NewTokenTransfer(from, to, amount, input)
 		}
 	}
+	if asset := txBuilder.Asset.GetAssetConfig(); asset.Type == xc.AssetTypeToken && asset.Contract != "" {
+		return txBuilder.NewTokenTransfer(from, to, amount, input)
+	}
 
 	return txBuilder.NewNativeTransfer(from, to, amount, input)
 }
 
 // NewNativeTransfer creates a new transfer for a native asset
 func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if txInput := input.(*TxInput); txInput.FromSeed != "" {
+		return txBuilder.newNativeTransferFromSeed(from, to, amount, txInput)
+	}
 	accountFrom, err := solana.PublicKeyFromBase58(string(from))
 	if err != nil {
 		return nil, err
@@ -87,6 +96,9 @@ func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amou
 		}
 	}
 	txInput := input.(*TxInput)
+	if txInput.FromSeed != "" {
+		return txBuilder.newTokenTransferFromSeed(from, to, amount, txInput)
+	}
 
 	contract := asset.Contract
 	if token, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok && contract == "" {
@@ -109,6 +121,21 @@ func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amou
 		return nil, err
 	}
 
+	// from signs, unless it's a multisig signed by its signers
+	payer := accountFrom
+	signers := []solana.PublicKey{accountFrom}
+	if len(txInput.MultisigSigners) > 0 {
+		signers = []solana.PublicKey{}
+		for _, signer := range txInput.MultisigSigners {
+			accountSigner, err := solana.PublicKeyFromBase58(signer)
+			if err != nil {
+				return nil, err
+			}
+			signers = append(signers, accountSigner)
+		}
+		payer = signers[0]
+	}
+
 	ataFromStr, err := FindAssociatedTokenAddress(string(from), string(contract))
 	if err != nil {
 		return nil, err
@@ -136,10 +163,11 @@ func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amou
 	// log.Print(txLog)
 
 	instructions := []solana.Instruction{}
-	if txInput.ShouldCreateATA {
+	// an ATA can only be created for an owner
+	if txInput.ShouldCreateATA && !txInput.ToIsATA {
 		instructions = append(instructions,
 			ata.NewCreateInstruction(
-				accountFrom,
+				payer,
 				accountTo,
 				accountContract,
 			).Build(),
@@ -153,12 +181,10 @@ func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amou
 			accountContract,
 			ataTo,
 			accountFrom,
-			[]solana.PublicKey{
-				accountFrom,
-			},
+			signers,
 		).Build(),
 	)
-	return txBuilder.buildSolanaTx(instructions, accountFrom, txInput)
+	return txBuilder.buildSolanaTx(instructions, payer, txInput)
 }
 
 func (txBuilder TxBuilder) buildSolanaTx(instructions []solana.Instruction, accountFrom solana.PublicKey, txInput *TxInput) (xc.Tx, error) {
@@ -170,9 +196,12 @@ func (txBuilder TxBuilder) buildSolanaTx(instructions []solana.Instruction, acco
 	if err != nil {
 		return nil, err
 	}
-	return &Tx{
+	result := &Tx{
 		SolTx: tx,
-	}, nil
+	}
+	// e.g. expose the token mint via ContractAddress()
+	result.ParseTransfer()
+	return result, nil
 }
 
 func (txBuilder TxBuilder) NewTask(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
@@ -1,6 +1,8 @@
 package solana
 
 import (
+	"testing"
+
 	"github.com/gagliardetto/solana-go"
 	xc "github.com/jumpcrypto/crosschain"
 )
@@ -191,3 +193,123 @@ func (s *CrosschainTestSuite) TestNewTransferAsToken() {
 	require.Equal(1, len(solTx.Message.Instructions))
 	require.Equal(uint16(0x4), solTx.Message.Instructions[0].ProgramIDIndex) // token tx
 }
+
+func (s *CrosschainTestSuite) TestNewTransferTokenConfig() {
+	require := s.Require()
+	contract := "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"
+	builder, _ := NewTxBuilder(&xc.AssetConfig{
+		Type:     xc.AssetTypeToken,
+		Contract: contract,
+		Decimals: 6,
+	})
+	from := xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := xc.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	amount := xc.NewAmountBlockchainFromUint64(1200000) // 1.2 USDC
+	ataToStr, _ := FindAssociatedTokenAddress(string(to), contract)
+
+	// create the recipient ATA, then transfer
+	tx, err := builder.NewTransfer(from, to, amount, &TxInput{ShouldCreateATA: true})
+	require.NoError(err)
+	solTx := tx.(*Tx).SolTx
+	require.Equal(2, len(solTx.Message.Instructions))
+	require.Equal(xc.ContractAddress(contract), tx.(*Tx).ContractAddress())
+	require.Equal(from, tx.(*Tx).From())
+	require.Equal(xc.Address(ataToStr), tx.(*Tx).ToAlt())
+	require.Equal("1200000", tx.(*Tx).Amount().String())
+
+	// an ATA recipient can't have an ATA
+	tx, err = builder.NewTransfer(from, xc.Address(ataToStr), amount, &TxInput{ToIsATA: true, ShouldCreateATA: true})
+	require.NoError(err)
+	require.Equal(1, len(tx.(*Tx).SolTx.Message.Instructions))
+	require.Equal(xc.Address(ataToStr), tx.(*Tx).ToAlt())
+
+	// native assets ignore token fields
+	builder, _ = NewTxBuilder(&xc.AssetConfig{Type: xc.AssetTypeNative, Contract: contract})
+	tx, err = builder.NewTransfer(from, to, amount, &TxInput{})
+	require.NoError(err)
+	require.Equal(xc.ContractAddress(""), tx.(*Tx).ContractAddress())
+}
+
+func newBenchmarkTransfer() (TxBuilder, xc.Address, xc.Address, xc.AmountBlockchain) {
+	builder := TxBuilder{Asset: &xc.AssetConfig{
+		Type:     xc.AssetTypeToken,
+		Net:      xc.Mainnet,
+		Contract: "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
+		Decimals: 6,
+	}}
+	from := xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := xc.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	return builder, from, to, xc.NewAmountBlockchainFromUint64(1_200_000)
+}
+
+// allocation budgets of the hot paths, raise only with a reason
+const (
+	nativeTransferAllocBudget = 65
+	tokenTransferAllocBudget  = 180
+	liquidStakeAllocBudget    = 150
+)
+
+func (s *CrosschainTestSuite) TestAllocationBudget() {
+	require := s.Require()
+	builder, from, to, amount := newBenchmarkTransfer()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		tx, _ := builder.NewNativeTransfer(from, to, amount, &TxInput{})
+		_, _ = tx.Serialize()
+	})
+	require.LessOrEqual(allocs, float64(nativeTransferAllocBudget))
+
+	allocs = testing.AllocsPerRun(100, func() {
+		tx, _ := builder.NewTokenTransfer(from, to, amount, &TxInput{})
+		_, _ = tx.Serialize()
+	})
+	require.LessOrEqual(allocs, float64(tokenTransferAllocBudget))
+
+	allocs = testing.AllocsPerRun(100, func() {
+		tx, _ := builder.NewLiquidStake(from, amount, &TxInput{})
+		_, _ = tx.Serialize()
+	})
+	require.LessOrEqual(allocs, float64(liquidStakeAllocBudget))
+}
+
+func BenchmarkNewNativeTransfer(b *testing.B) {
+	builder, from, to, amount := newBenchmarkTransfer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tx, err := builder.NewNativeTransfer(from, to, amount, &TxInput{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := tx.Serialize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewTokenTransfer(b *testing.B) {
+	builder, from, to, amount := newBenchmarkTransfer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tx, err := builder.NewTokenTransfer(from, to, amount, &TxInput{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := tx.Serialize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewLiquidStake(b *testing.B) {
+	builder, from, _, amount := newBenchmarkTransfer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tx, err := builder.NewLiquidStake(from, amount, &TxInput{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := tx.Serialize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
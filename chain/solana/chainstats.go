@@ -0,0 +1,79 @@
+package solana
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// LamportsPerSignature is the base fee of a tx per signature
+const LamportsPerSignature = 5000
+
+// FetchChainStats returns the latest slot and the average slot time from the recent performance samples
+// The base fee is the fee per signature, and the fee rate the fee of a single signature and single instruction tx
+// with the prioritization fee at DefaultPriorityFeePercentile of the recent prioritization fees; the mempool of
+// Solana isn't exposed
+func (client *Client) FetchChainStats(ctx context.Context) (*xc.ChainStats, error) {
+	slot, err := client.SolClient.GetSlot(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch latest slot: %v", err)
+	}
+	average, err := client.fetchAverageSlotTime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	price, err := client.fetchComputeUnitPrice(ctx, []string{}, DefaultPriorityFeePercentile)
+	if err != nil {
+		return nil, err
+	}
+
+	baseFee := xc.NewAmountBlockchainFromUint64(LamportsPerSignature)
+	priority := prioritizationFee(price, defaultInstructionComputeUnits)
+	return &xc.ChainStats{
+		Chain:            client.Asset.GetNativeAsset().NativeAsset,
+		Height:           slot,
+		AverageBlockTime: average,
+		BaseFee:          baseFee,
+		FeeRate:          baseFee.Add(&priority),
+	}, nil
+}
+
+// fetchAverageSlotTime returns the average slot time of the latest performance sample, zero if there is none
+func (client *Client) fetchAverageSlotTime(ctx context.Context) (time.Duration, error) {
+	// a sample is taken every 60s, about 150 slots
+	limit := uint(1)
+	samples, err := client.SolClient.GetRecentPerformanceSamples(ctx, &limit)
+	if err != nil {
+		return 0, fmt.Errorf("could not fetch performance samples: %v", err)
+	}
+	if len(samples) == 0 || samples[0].NumSlots == 0 {
+		return 0, nil
+	}
+	return time.Duration(samples[0].SamplePeriodSecs) * time.Second / time.Duration(samples[0].NumSlots), nil
+}
+
+// FetchChainHealth returns whether the chain is halted, from the age of the latest finalized block,
+// or in maintenance at the maintenance_url of the asset
+func (client *Client) FetchChainHealth(ctx context.Context) (*xc.ChainHealth, error) {
+	slot, err := client.SolClient.GetSlot(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch latest slot: %v", err)
+	}
+	blockTime, err := client.SolClient.GetBlockTime(ctx, slot)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch time of slot %d: %v", slot, err)
+	}
+	if blockTime == nil {
+		return nil, fmt.Errorf("no time of slot %d", slot)
+	}
+	var average time.Duration
+	if client.Asset.GetNativeAsset().BlockTime <= 0 {
+		if average, err = client.fetchAverageSlotTime(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return xc.CheckChainHealth(ctx, client.Asset.GetNativeAsset(), slot, blockTime.Time(), average, now())
+}
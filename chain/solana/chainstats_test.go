@@ -0,0 +1,84 @@
+package solana
+
+import (
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestFetchChainStats() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`210516307`,
+		`[{"numSlots":150,"numTransactions":420000,"samplePeriodSecs":60,"slot":210516300}]`,
+		// getRecentPrioritizationFees, the 75th percentile is 10_000 micro-lamports per compute unit
+		`[{"slot":210516300,"prioritizationFee":0},{"slot":210516301,"prioritizationFee":5000},{"slot":210516302,"prioritizationFee":10000},{"slot":210516303,"prioritizationFee":50000}]`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+
+	stats, err := client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.SOL, stats.Chain)
+	require.EqualValues(210516307, stats.Height)
+	require.Equal(400*time.Millisecond, stats.AverageBlockTime)
+	require.Equal("5000", stats.BaseFee.String())
+	// 5000 + 10_000 * 200_000 / 1_000_000
+	require.Equal("7000", stats.FeeRate.String())
+	require.Nil(stats.MempoolSize)
+
+	// no sample on a new validator, nor prioritization fees
+	server, close = test.MockJSONRPC(&s.Suite, []string{`210516307`, `[]`, `[]`})
+	defer close()
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+	stats, err = client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.Zero(stats.AverageBlockTime)
+	require.Equal("5000", stats.FeeRate.String())
+}
+
+func (s *CrosschainTestSuite) TestFetchChainStatsError() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"jsonrpc":"2.0","error":{"code":-32000,"message":"node unhealthy"},"id":0}`)
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+
+	_, err := client.FetchChainStats(s.Ctx)
+	require.ErrorContains(err, "could not fetch latest slot")
+}
+
+func (s *CrosschainTestSuite) TestFetchChainHealth() {
+	require := s.Require()
+	blockTime := time.Unix(1683844272, 0)
+	defer func() { now = time.Now }()
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`210516307`,
+		`1683844272`,
+		`[{"numSlots":150,"numTransactions":420000,"samplePeriodSecs":60,"slot":210516300}]`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+	now = func() time.Time { return blockTime.Add(20 * time.Second) }
+	health, err := client.FetchChainHealth(s.Ctx)
+	require.NoError(err)
+	require.True(health.Healthy())
+	require.EqualValues(210516307, health.Height)
+	require.Equal(xc.MinHaltThreshold, health.HaltThreshold)
+
+	now = func() time.Time { return blockTime.Add(10 * time.Minute) }
+	server, close = test.MockJSONRPC(&s.Suite, []string{`210516307`, `1683844272`, `[]`})
+	defer close()
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.SOL, URL: server.URL, BlockTime: 400 * time.Millisecond})
+	health, err = client.FetchChainHealth(s.Ctx)
+	require.NoError(err)
+	require.Equal(xc.ChainStatusHalted, health.Status)
+	require.Equal(2, server.Counter)
+
+	server, close = test.MockJSONRPC(&s.Suite, []string{`210516307`, `null`})
+	defer close()
+	client, _ = NewClient(&xc.AssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+	_, err = client.FetchChainHealth(s.Ctx)
+	require.EqualError(err, "no time of slot 210516307")
+}
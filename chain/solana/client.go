@@ -5,14 +5,19 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
 	"strings"
 
 	xc "github.com/jumpcrypto/crosschain"
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
 	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
 )
 
 // TxInput for Solana
@@ -21,6 +26,15 @@ type TxInput struct {
 	RecentBlockHash solana.Hash
 	ToIsATA         bool
 	ShouldCreateATA bool
+	// FromSeed transfers from the seed account of the sender derived with this seed, see FindAddressWithSeed
+	FromSeed string
+	// RentExemption is the balance of each seed account created by NewCreateSeedAccounts, or of the stake account created by NewDelegate
+	RentExemption uint64
+	// StakeSeed derives the stake account of NewDelegate and NewUndelegate, StakeSeed(validator) if empty
+	StakeSeed string
+	// MultisigSigners sign a token transfer from an SPL token multisig account, the first one paying the fees
+	// Every signer listed must sign: list as many signers as the multisig requires
+	MultisigSigners []string
 }
 
 // NewTxInput returns a new Solana TxInput
@@ -30,18 +44,41 @@ func NewTxInput() *TxInput {
 	}
 }
 
+var _ xc.TxInputFee = &TxInput{}
+
+// MaxFee returns the fee of the signature of the sender, and the rent of the token account of the recipient if created
+func (txInput *TxInput) MaxFee() xc.AmountBlockchain {
+	fee := uint64(LamportsPerSignature)
+	if len(txInput.MultisigSigners) > 0 {
+		fee *= uint64(len(txInput.MultisigSigners))
+	}
+	if txInput.ShouldCreateATA {
+		fee += TokenAccountRent
+	}
+	return xc.NewAmountBlockchainFromUint64(fee)
+}
+
 // Client for Solana
 type Client struct {
 	SolClient *rpc.Client
 	Asset     xc.ITask
 }
 
-var _ xc.Client = &Client{}
+var _ xc.FullClient = &Client{}
 
 // NewClient returns a new JSON-RPC Client to the Solana node
 func NewClient(cfgI xc.ITask) (*Client, error) {
 	cfg := cfgI.GetNativeAsset()
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
 	solClient := rpc.New(cfg.URL)
+	if transport != http.DefaultTransport {
+		solClient = rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(cfg.URL, &jsonrpc.RPCClientOpts{
+			HTTPClient: &http.Client{Transport: transport},
+		}))
+	}
 	return &Client{
 		SolClient: solClient,
 		Asset:     cfgI,
@@ -83,16 +120,13 @@ func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, to xc.A
 		return nil, err
 	}
 	res, err := client.SolClient.GetAccountInfo(ctx, accountTo)
-	if err != nil {
+	if err != nil && err != rpc.ErrNotFound {
+		return nil, err
+	}
+	// "to" without account is an owner that never received lamports
+	if err == nil && !res.Value.Owner.Equals(solana.SystemProgramID) {
+		// The field "to" is not an owner address, therefore is a (possibly custom) ATA
 		txInput.ToIsATA = true
-	} else {
-		ownerAddr := res.Value.Owner.String()
-		// log.Println("owner", ownerAddr)
-		sysAddr := "11111111111111111111111111111111"
-		if ownerAddr != sysAddr {
-			// The field "to" is not an owner address, therefore is a (possibly custom) ATA
-			txInput.ToIsATA = true
-		}
 	}
 
 	// for tokens, get ata account info
@@ -105,23 +139,60 @@ func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, to xc.A
 		ataTo = solana.MustPublicKeyFromBase58(ataToStr)
 	}
 	_, err = client.SolClient.GetAccountInfo(ctx, ataTo)
-	if err != nil {
+	if err == rpc.ErrNotFound {
 		// if the ATA doesn't exist yet, we will create when sending tokens
 		txInput.ShouldCreateATA = true
+	} else if err != nil {
+		return nil, err
+	}
+
+	return txInput, nil
+}
+
+// FetchSeedAccountsInput returns tx input to create seed accounts of the asset, namely a RecentBlockHash and their RentExemption
+func (client *Client) FetchSeedAccountsInput(ctx context.Context) (*TxInput, error) {
+	txInput := NewTxInput()
+	recent, err := client.SolClient.GetRecentBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, err
+	}
+	if recent == nil || recent.Value == nil {
+		return nil, errors.New("error fetching blockhash")
 	}
+	txInput.RecentBlockHash = recent.Value.Blockhash
 
+	_, space := seedAccountOwner(client.Asset)
+	rent, err := client.SolClient.GetMinimumBalanceForRentExemption(ctx, space, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch rent exemption: %v", err)
+	}
+	txInput.RentExemption = rent
 	return txInput, nil
 }
 
 func (client *Client) SubmitTx(ctx context.Context, txInput xc.Tx) error {
-	txData, err := txInput.Serialize()
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		simulated, err := client.simulateTx(ctx, txInput)
+		if err != nil {
+			return err
+		}
+		return xc.RecordDryRun(ctx, client.Asset, txInput, simulated)
+	}
+	var encodedTx string
+	err := xc.SerializePooled(txInput, func(txData []byte) error {
+		encodedTx = base64.StdEncoding.EncodeToString(txData)
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("send transaction: encode transaction: %w", err)
 	}
 
 	_, err = client.SolClient.SendEncodedTransactionWithOpts(
 		ctx,
-		base64.StdEncoding.EncodeToString(txData),
+		encodedTx,
 		rpc.TransactionOpts{
 			SkipPreflight:       false,
 			PreflightCommitment: rpc.CommitmentFinalized,
@@ -130,6 +201,161 @@ func (client *Client) SubmitTx(ctx context.Context, txInput xc.Tx) error {
 	return err
 }
 
+// simulateTx simulates tx, with the preflight checks of SubmitTx, returning false for txs that can't be simulated
+func (client *Client) simulateTx(ctx context.Context, tx xc.Tx) (bool, error) {
+	result, err := client.SimulateTx(ctx, tx)
+	if errors.Is(err, xc.ErrSimulationNotSupported) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("simulate transaction: %w", err)
+	}
+	if !result.Success {
+		return false, fmt.Errorf("simulate transaction: %s, logs: %v", result.FailureReason, result.Logs)
+	}
+	return true, nil
+}
+
+var _ xc.FeeEstimator = &Client{}
+
+// DefaultPriorityFeePercentile is the percentile of the recent prioritization fees of the writable accounts of a tx
+// estimated for txs without a compute unit price
+const DefaultPriorityFeePercentile = 75
+
+// Compute units of txs without a compute unit limit: per instruction other than compute budget instructions, and max
+const (
+	defaultInstructionComputeUnits = 200_000
+	maxTxComputeUnits              = 1_400_000
+)
+
+// EstimateFee returns the fee charged for the message of tx by getFeeForMessage, including the prioritization fee of
+// its compute unit price, or, for txs without, such as the txs built here, with the prioritization fee at
+// DefaultPriorityFeePercentile of the recent prioritization fees of its writable accounts
+func (client *Client) EstimateFee(ctx context.Context, _ xc.Address, tx xc.Tx) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	var solTx *solana.Transaction
+	switch tx := tx.(type) {
+	case *Tx:
+		solTx = tx.SolTx
+	case Tx:
+		solTx = tx.SolTx
+	}
+	if solTx == nil {
+		return zero, errors.New("tx is not a solana tx")
+	}
+	message, err := solTx.Message.MarshalBinary()
+	if err != nil {
+		return zero, fmt.Errorf("could not serialize message: %v", err)
+	}
+	res, err := client.SolClient.GetFeeForMessage(ctx, base64.StdEncoding.EncodeToString(message), rpc.CommitmentFinalized)
+	if err != nil {
+		return zero, fmt.Errorf("could not fetch fee for message: %v", err)
+	}
+	if res == nil || res.Value == nil {
+		// the fee is unknown once the blockhash of the message expired
+		return zero, fmt.Errorf("recent blockhash %s of tx expired", solTx.Message.RecentBlockhash)
+	}
+	fee := xc.NewAmountBlockchainFromUint64(*res.Value)
+	price, units, hasPrice := computeBudget(solTx.Message)
+	if hasPrice {
+		return fee, nil
+	}
+	price, err = client.estimateComputeUnitPrice(ctx, solTx.Message, DefaultPriorityFeePercentile)
+	if err != nil {
+		return zero, err
+	}
+	priority := prioritizationFee(price, units)
+	return fee.Add(&priority), nil
+}
+
+// estimateComputeUnitPrice returns the percentile of the recent prioritization fees, in micro-lamports per compute
+// unit, of txs locking the writable accounts of message
+func (client *Client) estimateComputeUnitPrice(ctx context.Context, message solana.Message, percentile int) (uint64, error) {
+	accounts := []string{}
+	if writable, err := message.Writable(); err == nil {
+		for _, account := range writable {
+			accounts = append(accounts, account.String())
+		}
+	}
+	return client.fetchComputeUnitPrice(ctx, accounts, percentile)
+}
+
+// fetchComputeUnitPrice returns the percentile of the recent prioritization fees of txs locking accounts,
+// of all txs if none is given
+func (client *Client) fetchComputeUnitPrice(ctx context.Context, accounts []string, percentile int) (uint64, error) {
+	recent := []struct {
+		Slot              uint64 `json:"slot"`
+		PrioritizationFee uint64 `json:"prioritizationFee"`
+	}{}
+	if err := client.SolClient.RPCCallForInto(ctx, &recent, "getRecentPrioritizationFees", []interface{}{accounts}); err != nil {
+		return 0, fmt.Errorf("could not fetch recent prioritization fees: %v", err)
+	}
+	fees := make([]uint64, len(recent))
+	for i, fee := range recent {
+		fees[i] = fee.PrioritizationFee
+	}
+	return feePercentile(fees, percentile), nil
+}
+
+// feePercentile returns the nearest-rank percentile of fees, 0 if there are none
+func feePercentile(fees []uint64, percentile int) uint64 {
+	if len(fees) == 0 {
+		return 0
+	}
+	sorted := append([]uint64{}, fees...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := (percentile*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// computeBudget returns the compute unit price of message and its compute units, set by its compute budget
+// instructions or else the default of its instructions
+func computeBudget(message solana.Message) (price uint64, units uint64, hasPrice bool) {
+	instructions := uint64(0)
+	hasLimit := false
+	for _, instruction := range message.Instructions {
+		program, err := message.Program(instruction.ProgramIDIndex)
+		if err != nil || !program.Equals(solana.ComputeBudget) || len(instruction.Data) == 0 {
+			instructions++
+			continue
+		}
+		data := instruction.Data[1:]
+		switch instruction.Data[0] {
+		case computebudget.Instruction_SetComputeUnitLimit:
+			if len(data) >= 4 {
+				units = uint64(bin.LE.Uint32(data))
+				hasLimit = true
+			}
+		case computebudget.Instruction_SetComputeUnitPrice:
+			if len(data) >= 8 {
+				price = bin.LE.Uint64(data)
+				hasPrice = true
+			}
+		}
+	}
+	if !hasLimit {
+		units = instructions * defaultInstructionComputeUnits
+		if units > maxTxComputeUnits {
+			units = maxTxComputeUnits
+		}
+	}
+	return price, units, hasPrice
+}
+
+// prioritizationFee returns the fee in lamports of compute units at a price in micro-lamports, rounded up
+func prioritizationFee(price uint64, units uint64) xc.AmountBlockchain {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(price), new(big.Int).SetUint64(units))
+	fee.Add(fee, big.NewInt(999_999))
+	fee.Div(fee, big.NewInt(1_000_000))
+	return xc.AmountBlockchain(*fee)
+}
+
 func (client *Client) getParsedAccountInfo(ctx context.Context, ataPubKey string) (*token.Account, error) {
 	ata := solana.MustPublicKeyFromBase58(ataPubKey)
 	res, err := client.SolClient.GetAccountInfo(ctx, ata)
@@ -145,6 +371,14 @@ func (client *Client) getParsedAccountInfo(ctx context.Context, ataPubKey string
 	return &tokenAcct, nil
 }
 
+// explorerCluster returns the Solana explorer cluster of a network, named testnets map to testnet
+func explorerCluster(net xc.Net) string {
+	if net.IsMainnet() {
+		return "mainnet-beta"
+	}
+	return string(net.Kind())
+}
+
 // FetchTxInfo returns tx info for a Solana tx
 func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
 	result := xc.TxInfo{}
@@ -197,7 +431,7 @@ func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxI
 	result.Fee = xc.NewAmountBlockchainFromUint64(meta.Fee)
 
 	result.TxID = string(txHash)
-	result.ExplorerURL = client.Asset.GetNativeAsset().ExplorerURL + "/tx/" + result.TxID + "?cluster=" + client.Asset.GetNativeAsset().Net
+	result.ExplorerURL = client.Asset.GetNativeAsset().ExplorerURL + "/tx/" + result.TxID + "?cluster=" + explorerCluster(client.Asset.GetNativeAsset().Net)
 	tx.ParseTransfer()
 
 	// first, check associated token account
@@ -299,3 +533,23 @@ func (client *Client) fetchContractBalance(ctx context.Context, address xc.Addre
 	balance := xc.NewAmountBlockchainFromStr(out.Value.Amount)
 	return balance, nil
 }
+
+// FetchTokenMetadata fetches the decimals of an SPL token mint
+// Symbols are in Metaplex metadata accounts and are not fetched
+func (client *Client) FetchTokenMetadata(ctx context.Context, contract xc.ContractAddress) (xc.TokenMetadata, error) {
+	mint, err := solana.PublicKeyFromBase58(string(contract))
+	if err != nil {
+		return xc.TokenMetadata{}, err
+	}
+	supply, err := client.SolClient.GetTokenSupply(ctx, mint, rpc.CommitmentFinalized)
+	if err != nil {
+		return xc.TokenMetadata{}, fmt.Errorf("failed to fetch mint %s: %v", contract, err)
+	}
+	if supply == nil || supply.Value == nil {
+		return xc.TokenMetadata{}, fmt.Errorf("mint %s not found", contract)
+	}
+	return xc.TokenMetadata{
+		Contract: contract,
+		Decimals: int32(supply.Value.Decimals),
+	}, nil
+}
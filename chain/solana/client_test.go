@@ -4,9 +4,12 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
 	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/rpc"
 	xc "github.com/jumpcrypto/crosschain"
@@ -36,6 +39,14 @@ func (s *CrosschainTestSuite) TestFindAssociatedTokenAddress() {
 	require.Equal("", ata)
 }
 
+func (s *CrosschainTestSuite) TestTxInputMaxFee() {
+	require := s.Require()
+	input := NewTxInput()
+	require.Equal("5000", input.MaxFee().String())
+	input.ShouldCreateATA = true
+	require.Equal("2044280", input.MaxFee().String())
+}
+
 /*
 curl https://api.devnet.solana.com -X POST -H "Content-Type: application/json" -d '
 
@@ -120,13 +131,13 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			[]string{
 				// valid blockhash
 				`{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`,
-				// empty ATA
+				// owner without lamports
 				`{"context":{"apiVersion":"1.13.3","slot":175636079},"value":null}`,
 				// empty ATA
 				`{"context":{"apiVersion":"1.13.3","slot":175636079},"value":null}`,
 			},
 			"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK",
-			true,
+			false,
 			true,
 			"",
 		},
@@ -154,6 +165,19 @@ func (s *CrosschainTestSuite) TestFetchTxInput() {
 			true,
 			"decode: invalid base58 digit",
 		},
+		{
+			&xc.TokenAssetConfig{Type: xc.AssetTypeToken, Contract: "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"},
+			[]string{
+				// valid blockhash
+				`{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`,
+				// failed account lookup isn't an ATA
+				`{"jsonrpc":"2.0","error":{"code":123,"message":"custom RPC error"},"id":0}`,
+			},
+			"",
+			false,
+			false,
+			"custom RPC error",
+		},
 		{
 			&xc.NativeAssetConfig{},
 			`null`,
@@ -244,6 +268,106 @@ func (s *CrosschainTestSuite) TestSubmitTxErr() {
 	require.ErrorContains(err, "unsupported protocol scheme")
 }
 
+func (s *CrosschainTestSuite) TestEstimateFee() {
+	require := s.Require()
+	txbin := "01df5ff457c2cdd23242ab26edd0b308d78499f28c6d43e185149cacdb88b35db171f1779e48ce2224cc80b9b9ce46dd80758319068b08eae34b14dc2cd070ab000100010379726da52d99d60b07ead73b2f6f0bf6083cc85c77a94e34d691d78f8bcafec9fc880863219008406235fa4c8fbb2a86d3da7b6762eac39323b2a1d8c404a4140000000000000000000000000000000000000000000000000000000000000000932bbef1569d58f4a116f41028f766439b2ba52c68c3308bbbea2b21e4716f6701020200010c0200000000ca9a3b00000000"
+	bytes, _ := hex.DecodeString(txbin)
+	solTx, _ := solana.TransactionFromDecoder(bin.NewBinDecoder(bytes))
+	tx := &Tx{SolTx: solTx}
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`{"context":{"slot":1},"value":5000}`,
+		`[{"slot":1,"prioritizationFee":0},{"slot":2,"prioritizationFee":5000},{"slot":3,"prioritizationFee":1000},{"slot":4,"prioritizationFee":2000}]`,
+		`{"context":{"slot":2},"value":null}`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+	// the 75th percentile of recent fees, 2000 micro-lamports, for the 200k compute units of a transfer
+	fee, err := client.EstimateFee(s.Ctx, "", tx)
+	require.NoError(err)
+	require.EqualValues(5000+400, fee.Uint64())
+
+	_, err = client.EstimateFee(s.Ctx, "", tx)
+	require.ErrorContains(err, "of tx expired")
+
+	_, err = client.EstimateFee(s.Ctx, "", &test.MockXcTx{})
+	require.EqualError(err, "tx is not a solana tx")
+
+	server, close = test.MockJSONRPC(&s.Suite, []string{
+		`{"context":{"slot":1},"value":5000}`,
+		`{"jsonrpc":"2.0","error":{"message":"Method not found","code":-32601},"id":0}`,
+	})
+	defer close()
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+	_, err = client.EstimateFee(s.Ctx, "", tx)
+	require.ErrorContains(err, "could not fetch recent prioritization fees")
+
+	// the fee of txs with a compute unit price includes their prioritization fee
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	priced, err := solana.NewTransaction([]solana.Instruction{
+		computebudget.NewSetComputeUnitPriceInstruction(1000).Build(),
+		system.NewTransferInstruction(1, from, from).Build(),
+	}, solTx.Message.RecentBlockhash, solana.TransactionPayer(from))
+	require.NoError(err)
+	server, close = test.MockJSONRPC(&s.Suite, `{"context":{"slot":1},"value":5200}`)
+	defer close()
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+	fee, err = client.EstimateFee(s.Ctx, "", &Tx{SolTx: priced})
+	require.NoError(err)
+	require.EqualValues(5200, fee.Uint64())
+	require.Equal(1, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestComputeBudget() {
+	require := s.Require()
+	from := solana.MustPublicKeyFromBase58("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	transfer := system.NewTransferInstruction(1, from, from).Build()
+	newMessage := func(instructions ...solana.Instruction) solana.Message {
+		tx, err := solana.NewTransaction(instructions, solana.Hash{}, solana.TransactionPayer(from))
+		require.NoError(err)
+		return tx.Message
+	}
+
+	price, units, hasPrice := computeBudget(newMessage(transfer, transfer))
+	require.False(hasPrice)
+	require.Zero(price)
+	require.EqualValues(400_000, units)
+
+	instructions := []solana.Instruction{}
+	for i := 0; i < 10; i++ {
+		instructions = append(instructions, transfer)
+	}
+	_, units, _ = computeBudget(newMessage(instructions...))
+	require.EqualValues(1_400_000, units)
+
+	price, units, hasPrice = computeBudget(newMessage(
+		computebudget.NewSetComputeUnitLimitInstruction(50_000).Build(),
+		computebudget.NewSetComputeUnitPriceInstruction(3).Build(),
+		transfer,
+	))
+	require.True(hasPrice)
+	require.EqualValues(3, price)
+	require.EqualValues(50_000, units)
+
+	// rounded up to the lamport
+	require.Equal("1", prioritizationFee(3, 50_000).String())
+	require.Equal("0", prioritizationFee(0, 200_000).String())
+	require.Equal("400", prioritizationFee(2000, 200_000).String())
+}
+
+func (s *CrosschainTestSuite) TestFeePercentile() {
+	require := s.Require()
+	require.Zero(feePercentile(nil, 75))
+	require.EqualValues(7, feePercentile([]uint64{7}, 75))
+	fees := []uint64{40, 10, 30, 20}
+	require.EqualValues(30, feePercentile(fees, 75))
+	require.EqualValues(20, feePercentile(fees, 50))
+	require.EqualValues(10, feePercentile(fees, 0))
+	require.EqualValues(40, feePercentile(fees, 100))
+	// fees aren't sorted in place
+	require.Equal([]uint64{40, 10, 30, 20}, fees)
+}
+
 func (s *CrosschainTestSuite) TestAccountBalance() {
 	require := s.Require()
 
@@ -342,6 +466,51 @@ func (s *CrosschainTestSuite) TestTokenBalance() {
 	}
 }
 
+func (s *CrosschainTestSuite) TestFetchTokenMetadata() {
+	require := s.Require()
+
+	vectors := []struct {
+		resp     interface{}
+		decimals int32
+		err      string
+	}{
+		{
+			`{"context":{"slot":1114},"value":{"amount":"9864","decimals":6,"uiAmount":0.009864,"uiAmountString":"0.009864"}}`,
+			6,
+			"",
+		},
+		{
+			`{"context":{"slot":1114},"value":null}`,
+			0,
+			"not found",
+		},
+		{
+			errors.New(`{"message": "Invalid param: not a Token mint", "code": -32602}`),
+			0,
+			"not a Token mint",
+		},
+	}
+
+	for _, v := range vectors {
+		server, close := test.MockJSONRPC(&s.Suite, v.resp)
+		defer close()
+
+		client, _ := NewClient(&xc.AssetConfig{URL: server.URL})
+		metadata, err := client.FetchTokenMetadata(s.Ctx, "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU")
+		if v.err != "" {
+			require.ErrorContains(err, v.err)
+		} else {
+			require.NoError(err)
+			require.Equal(xc.ContractAddress("4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"), metadata.Contract)
+			require.Equal(v.decimals, metadata.Decimals)
+		}
+	}
+
+	client, _ := NewClient(&xc.AssetConfig{})
+	_, err := client.FetchTokenMetadata(s.Ctx, "invalid")
+	require.Error(err)
+}
+
 func (s *CrosschainTestSuite) TestFetchTxInfo() {
 	require := s.Require()
 
@@ -501,3 +670,36 @@ func (s *CrosschainTestSuite) TestFetchTxInfo() {
 		}
 	}
 }
+
+func (s *CrosschainTestSuite) TestExplorerCluster() {
+	require := s.Require()
+	require.Equal("mainnet-beta", explorerCluster(xc.Mainnet))
+	require.Equal("devnet", explorerCluster(xc.Devnet))
+	require.Equal("testnet", explorerCluster(xc.Testnet))
+	require.Equal("", explorerCluster(""))
+}
+
+func (s *CrosschainTestSuite) TestNewClientRequestSigning() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"context":{"slot":1114},"value":{"amount":"9864","decimals":2,"uiAmount":98.64,"uiAmountString":"98.64"}}`)
+	defer close()
+
+	signed := []string{}
+	client, err := NewClient(&xc.AssetConfig{
+		URL:      server.URL,
+		Contract: "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU",
+		RequestSigner: xc.RequestSignerFunc(func(req *http.Request, body []byte) error {
+			signed = append(signed, string(body))
+			return nil
+		}),
+	})
+	require.NoError(err)
+	balance, err := client.FetchBalance(s.Ctx, xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"))
+	require.NoError(err)
+	require.Equal("9864", balance.String())
+	require.Len(signed, 1)
+	require.Contains(signed[0], "getTokenAccountBalance")
+
+	_, err = NewClient(&xc.AssetConfig{URL: server.URL, RequestSigning: "oauth"})
+	require.EqualError(err, "unsupported request signing: 'oauth'")
+}
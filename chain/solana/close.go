@@ -0,0 +1,102 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// MaxCloseAccountsPerTx is the number of token accounts closed in a single tx, bounded by the size of a tx
+const MaxCloseAccountsPerTx = 20
+
+const tokenAccountStateFrozen = token.AccountState(2)
+
+// ReclaimableAccount is an empty token account that its owner can close to reclaim its rent
+type ReclaimableAccount struct {
+	Address xc.Address
+	Mint    xc.ContractAddress
+	// Rent is the balance of the account in lamports, transferred to the destination when closed
+	Rent xc.AmountBlockchain
+	// Associated is set for the associated token account of the owner and mint, likely to be used again
+	Associated bool
+}
+
+// FetchReclaimableTokenAccounts returns the empty token accounts of address that it can close, sorted by mint and address
+// Frozen accounts and accounts with another close authority are skipped
+func (client *Client) FetchReclaimableTokenAccounts(ctx context.Context, address xc.Address) ([]*ReclaimableAccount, error) {
+	owner, accounts, err := client.fetchTokenAccounts(ctx, address, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*ReclaimableAccount{}
+	for key, account := range accounts {
+		tokenAcct, err := decodeTokenAccount(key, account)
+		if err != nil {
+			return nil, err
+		}
+		if tokenAcct.Owner != owner || tokenAcct.Amount != 0 || tokenAcct.State == tokenAccountStateFrozen {
+			continue
+		}
+		if tokenAcct.CloseAuthority != nil && *tokenAcct.CloseAuthority != owner {
+			continue
+		}
+		ata, _, err := solana.FindAssociatedTokenAddress(owner, tokenAcct.Mint)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &ReclaimableAccount{
+			Address:    xc.Address(key.String()),
+			Mint:       xc.ContractAddress(tokenAcct.Mint.String()),
+			Rent:       xc.NewAmountBlockchainFromUint64(account.Lamports),
+			Associated: ata == key,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Mint != result[j].Mint {
+			return result[i].Mint < result[j].Mint
+		}
+		return result[i].Address < result[j].Address
+	})
+	return result, nil
+}
+
+// NewCloseTokenAccounts closes empty token accounts of owner, batched in a single tx signed and paid by owner,
+// and transfers their rent to destination, or to owner if empty
+func (txBuilder TxBuilder) NewCloseTokenAccounts(owner xc.Address, accounts []xc.Address, destination xc.Address, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	if len(accounts) == 0 {
+		return nil, errors.New("no token account to close")
+	}
+	if len(accounts) > MaxCloseAccountsPerTx {
+		return nil, fmt.Errorf("too many token accounts to close: %d, max %d per tx", len(accounts), MaxCloseAccountsPerTx)
+	}
+	accountOwner, err := solana.PublicKeyFromBase58(string(owner))
+	if err != nil {
+		return nil, err
+	}
+	accountDestination := accountOwner
+	if destination != "" {
+		accountDestination, err = solana.PublicKeyFromBase58(string(destination))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	instructions := []solana.Instruction{}
+	for _, address := range accounts {
+		account, err := solana.PublicKeyFromBase58(string(address))
+		if err != nil {
+			return nil, fmt.Errorf("invalid token account '%s': %v", address, err)
+		}
+		instructions = append(instructions,
+			token.NewCloseAccountInstruction(account, accountDestination, accountOwner, nil).Build(),
+		)
+	}
+	return txBuilder.buildSolanaTx(instructions, accountOwner, txInput)
+}
@@ -0,0 +1,102 @@
+package solana
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+// keyedTokenAccountWith returns a token account whose data is modified by edit
+func keyedTokenAccountWith(address string, mint string, owner string, amount uint64, edit func(data []byte)) string {
+	data, _ := base64.StdEncoding.DecodeString(tokenAccountData(mint, owner, amount))
+	edit(data)
+	return fmt.Sprintf(`{"pubkey":"%s","account":{"data":["%s","base64"],"executable":false,"lamports":2039280,"owner":"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA","rentEpoch":361}}`,
+		address, base64.StdEncoding.EncodeToString(data))
+}
+
+func (s *CrosschainTestSuite) TestFetchReclaimableTokenAccounts() {
+	require := s.Require()
+	ata, _ := FindAssociatedTokenAddress(balanceOwner, balanceMint)
+	ata2, _ := FindAssociatedTokenAddress(balanceOwner, balanceMint2)
+	frozen := "BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11"
+	delegated := "DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK"
+	server, close := test.MockJSONRPC(&s.Suite, `[`+
+		keyedTokenAccount(ata, balanceMint, balanceOwner, 0)+`,`+
+		keyedTokenAccount(auxTokenAccount, balanceMint, balanceOwner, 0)+`,`+
+		// not empty
+		keyedTokenAccount(ata2, balanceMint2, balanceOwner, 5)+`,`+
+		keyedTokenAccountWith(frozen, balanceMint2, balanceOwner, 0, func(data []byte) {
+			data[108] = 2
+		})+`,`+
+		keyedTokenAccountWith(delegated, balanceMint2, balanceOwner, 0, func(data []byte) {
+			// close authority
+			data[129] = 1
+			copy(data[133:165], solana.MustPublicKeyFromBase58(frozen).Bytes())
+		})+`]`)
+	defer close()
+	client, _ := NewClient(&xc.AssetConfig{URL: server.URL})
+
+	accounts, err := client.FetchReclaimableTokenAccounts(s.Ctx, balanceOwner)
+	require.NoError(err)
+	require.Len(accounts, 2)
+	require.Equal(&ReclaimableAccount{
+		Address:    xc.Address(ata),
+		Mint:       balanceMint,
+		Rent:       xc.NewAmountBlockchainFromUint64(2039280),
+		Associated: true,
+	}, accounts[0])
+	require.Equal(xc.Address(auxTokenAccount), accounts[1].Address)
+	require.False(accounts[1].Associated)
+
+	_, err = client.FetchReclaimableTokenAccounts(s.Ctx, "invalid")
+	require.ErrorContains(err, "invalid address 'invalid'")
+}
+
+func (s *CrosschainTestSuite) TestNewCloseTokenAccounts() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{})
+	ata, _ := FindAssociatedTokenAddress(balanceOwner, balanceMint)
+	destination := xc.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+
+	tx, err := builder.(TxBuilder).NewCloseTokenAccounts(balanceOwner, []xc.Address{xc.Address(ata), auxTokenAccount}, destination, &TxInput{})
+	require.NoError(err)
+	solTx := tx.(*Tx).SolTx
+	require.Len(solTx.Message.Instructions, 2)
+	require.Equal(uint8(1), solTx.Message.Header.NumRequiredSignatures)
+	require.Equal(balanceOwner, solTx.Message.AccountKeys[0].String())
+	for _, instruction := range solTx.Message.Instructions {
+		program, _ := solTx.Message.ResolveProgramIDIndex(instruction.ProgramIDIndex)
+		require.Equal(solana.TokenProgramID, program)
+		accounts, _ := instruction.ResolveInstructionAccounts(&solTx.Message)
+		require.Equal(destination, xc.Address(accounts[1].PublicKey.String()))
+	}
+
+	// rent is reclaimed to the owner by default
+	tx, err = builder.(TxBuilder).NewCloseTokenAccounts(balanceOwner, []xc.Address{xc.Address(ata)}, "", &TxInput{})
+	require.NoError(err)
+	solTx = tx.(*Tx).SolTx
+	accounts, _ := solTx.Message.Instructions[0].ResolveInstructionAccounts(&solTx.Message)
+	require.Equal(balanceOwner, accounts[1].PublicKey.String())
+
+	// a full batch fits in a tx
+	full := []xc.Address{}
+	for i := 0; i < MaxCloseAccountsPerTx; i++ {
+		full = append(full, xc.Address(solana.NewWallet().PublicKey().String()))
+	}
+	tx, err = builder.(TxBuilder).NewCloseTokenAccounts(balanceOwner, full, destination, &TxInput{})
+	require.NoError(err)
+	require.NoError(tx.AddSignatures(make([]byte, solana.SignatureLength)))
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	require.LessOrEqual(len(serialized), 1232)
+
+	_, err = builder.(TxBuilder).NewCloseTokenAccounts(balanceOwner, []xc.Address{}, "", &TxInput{})
+	require.EqualError(err, "no token account to close")
+	_, err = builder.(TxBuilder).NewCloseTokenAccounts(balanceOwner, append(full, auxTokenAccount), "", &TxInput{})
+	require.EqualError(err, "too many token accounts to close: 21, max 20 per tx")
+	_, err = builder.(TxBuilder).NewCloseTokenAccounts(balanceOwner, []xc.Address{"invalid"}, "", &TxInput{})
+	require.ErrorContains(err, "invalid token account 'invalid'")
+}
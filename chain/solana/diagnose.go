@@ -0,0 +1,55 @@
+package solana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// BlockhashExpiry is the time after which the blockhash of a tx has expired (150 blocks),
+// with a margin for the time between fetching the blockhash and submitting the tx
+const BlockhashExpiry = 2 * time.Minute
+
+var now = time.Now
+
+// Diagnose inspects the status of a tx and the health of the node to tell why a tx is not confirmed
+// Solana txs without nonce account can't land once their blockhash has expired
+func (client *Client) Diagnose(ctx context.Context, request xc.DiagnoseRequest) (*xc.Diagnosis, error) {
+	signature, err := solana.SignatureFromBase58(string(request.TxHash))
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := client.SolClient.GetSignatureStatuses(ctx, true, signature)
+	if err != nil && !errors.Is(err, rpc.ErrNotFound) {
+		return nil, fmt.Errorf("could not fetch status of %s: %v", request.TxHash, err)
+	}
+	if statuses != nil && len(statuses.Value) > 0 && statuses.Value[0] != nil {
+		status := statuses.Value[0]
+		if status.Err != nil {
+			details := fmt.Sprintf("%v", status.Err)
+			return xc.NewDiagnosis(request.TxHash, xc.StuckReasonFromError(details), details), nil
+		}
+		if status.ConfirmationStatus == rpc.ConfirmationStatusProcessed {
+			return xc.NewDiagnosis(request.TxHash, xc.StuckReasonPending, "processed, not confirmed yet"), nil
+		}
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonNone, ""), nil
+	}
+
+	// e.g. "Node is behind by 42 slots"
+	if _, err := client.SolClient.GetHealth(ctx); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "behind") {
+			return xc.NewDiagnosis(request.TxHash, xc.StuckReasonProviderLagging, err.Error()), nil
+		}
+		return nil, fmt.Errorf("could not fetch node health: %v", err)
+	}
+	if request.IsPropagating(now()) || now().Sub(request.SubmittedAt) < BlockhashExpiry {
+		return xc.NewDiagnosis(request.TxHash, xc.StuckReasonPending, "not found yet"), nil
+	}
+	return xc.NewDiagnosis(request.TxHash, xc.StuckReasonBlockhashExpired, fmt.Sprintf("not found %s after submission", BlockhashExpiry)), nil
+}
@@ -0,0 +1,49 @@
+package solana
+
+import (
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestDiagnose() {
+	require := s.Require()
+	submittedAt := time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+	defer func() { now = time.Now }()
+
+	notFound := `{"context":{"slot":195000000},"value":[null]}`
+	behind := `{"jsonrpc":"2.0","error":{"code":-32005,"message":"Node is behind by 42 slots","data":{"numSlotsBehind":42}},"id":0}`
+	vectors := []struct {
+		name   string
+		resp   []string
+		now    time.Time
+		reason xc.StuckReason
+	}{
+		{"confirmed", []string{`{"context":{"slot":195000000},"value":[{"slot":194999990,"confirmations":10,"err":null,"status":{"Ok":null},"confirmationStatus":"confirmed"}]}`}, submittedAt, xc.StuckReasonNone},
+		{"processed", []string{`{"context":{"slot":195000000},"value":[{"slot":194999999,"confirmations":0,"err":null,"status":{"Ok":null},"confirmationStatus":"processed"}]}`}, submittedAt, xc.StuckReasonPending},
+		{"out of gas", []string{`{"context":{"slot":195000000},"value":[{"slot":194999990,"confirmations":null,"err":{"InstructionError":[0,"ComputationalBudgetExceeded"]},"status":{"Err":{"InstructionError":[0,"ComputationalBudgetExceeded"]}},"confirmationStatus":"finalized"}]}`}, submittedAt, xc.StuckReasonOutOfGas},
+		{"failed", []string{`{"context":{"slot":195000000},"value":[{"slot":194999990,"confirmations":null,"err":{"InstructionError":[0,{"Custom":1}]},"status":{"Err":{"InstructionError":[0,{"Custom":1}]}},"confirmationStatus":"finalized"}]}`}, submittedAt, xc.StuckReasonFailed},
+		{"lagging", []string{notFound, behind}, submittedAt.Add(time.Hour), xc.StuckReasonProviderLagging},
+		{"pending", []string{notFound, `"ok"`}, submittedAt.Add(time.Minute + 30*time.Second), xc.StuckReasonPending},
+		{"expired", []string{notFound, `"ok"`}, submittedAt.Add(5 * time.Minute), xc.StuckReasonBlockhashExpired},
+	}
+	for _, v := range vectors {
+		server, close := test.MockJSONRPC(&s.Suite, v.resp)
+		client, _ := NewClient(&xc.AssetConfig{URL: server.URL})
+		now = func() time.Time { return v.now }
+
+		diagnosis, err := client.Diagnose(s.Ctx, xc.DiagnoseRequest{
+			TxHash:      "5U2YvvKUS6NUrDAJnABHjx2szwLCVmg8LCRK9BDbZwVAbf2q5j8D9Sc9kUoqanoqpn6ZpDguY3rip9W7N7vwCjSw",
+			SubmittedAt: submittedAt,
+		})
+		require.NoError(err, v.name)
+		require.Equal(v.reason, diagnosis.Reason, v.name)
+		require.Equal(len(v.resp), server.Counter, v.name)
+		close()
+	}
+
+	client, _ := NewClient(&xc.AssetConfig{})
+	_, err := client.Diagnose(s.Ctx, xc.DiagnoseRequest{TxHash: "invalid"})
+	require.Error(err)
+}
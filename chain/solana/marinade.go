@@ -0,0 +1,209 @@
+package solana
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	ata "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// MarinadeAccounts are the Marinade liquid staking program accounts
+type MarinadeAccounts struct {
+	Program             solana.PublicKey
+	State               solana.PublicKey
+	MSolMint            solana.PublicKey
+	LiqPoolMSolLeg      solana.PublicKey
+	TreasuryMSolAccount solana.PublicKey
+}
+
+// MarinadeMainnet are the Marinade accounts on Solana mainnet
+var MarinadeMainnet = MarinadeAccounts{
+	Program:             solana.MustPublicKeyFromBase58("MarBmsSgKXdrN1egZf5sqe1TMai9K1rChYNDJgjq7aD"),
+	State:               solana.MustPublicKeyFromBase58("8szGkuLTAux9XMgZ2vtY39jVSowEcpBfFfD8hXSEqdGC"),
+	MSolMint:            solana.MustPublicKeyFromBase58("mSoLzYCxHdYgdzU16g5QSh3i5K3z3KZK7ytfqcJm7So"),
+	LiqPoolMSolLeg:      solana.MustPublicKeyFromBase58("7GgPYjS5Dza89wV6FpZ23kUJRG5vbQ1GM25ezspYFSoE"),
+	TreasuryMSolAccount: solana.MustPublicKeyFromBase58("8ZUcztoAEhpAeC2ixWewJKQJsSUGYSGPVAjkhDJYf5Gd"),
+}
+
+// MarinadeAccountsByNet are the Marinade accounts of the networks Marinade is deployed on
+var MarinadeAccountsByNet = map[xc.Net]MarinadeAccounts{
+	xc.Mainnet: MarinadeMainnet,
+}
+
+func getMarinadeAccounts(asset xc.ITask) (MarinadeAccounts, error) {
+	net := asset.GetNativeAsset().Net
+	if parsed, err := xc.ParseNet(string(net)); err == nil {
+		net = parsed
+	}
+	accounts, ok := MarinadeAccountsByNet[net.Kind()]
+	if !ok {
+		return MarinadeAccounts{}, fmt.Errorf("marinade is not supported on network '%s'", net)
+	}
+	return accounts, nil
+}
+
+// parseMarinade returns the Marinade accounts of the chain, the TxInput and the u64 amount of a Marinade tx
+func (txBuilder TxBuilder) parseMarinade(amount xc.AmountBlockchain, input xc.TxInput) (MarinadeAccounts, *TxInput, uint64, error) {
+	txInput, ok := input.(*TxInput)
+	if !ok {
+		return MarinadeAccounts{}, nil, 0, errors.New("xc.TxInput is not from a solana chain")
+	}
+	if amount.Int().Sign() < 0 || !amount.Int().IsUint64() {
+		return MarinadeAccounts{}, nil, 0, fmt.Errorf("invalid amount %s: must fit in a u64", amount.String())
+	}
+	marinade, err := getMarinadeAccounts(txBuilder.Asset)
+	return marinade, txInput, amount.Int().Uint64(), err
+}
+
+// program addresses and instruction discriminators are derived once rather than on each tx
+var (
+	pdaCache           sync.Map
+	discriminatorCache sync.Map
+)
+
+type pdaCacheKey struct {
+	program solana.PublicKey
+	state   solana.PublicKey
+	seed    string
+}
+
+func (accounts MarinadeAccounts) pda(seed string) solana.PublicKey {
+	key := pdaCacheKey{accounts.Program, accounts.State, seed}
+	if address, ok := pdaCache.Load(key); ok {
+		return address.(solana.PublicKey)
+	}
+	address, _, _ := solana.FindProgramAddress([][]byte{accounts.State.Bytes(), []byte(seed)}, accounts.Program)
+	pdaCache.Store(key, address)
+	return address
+}
+
+// anchorDiscriminator returns the first 8 bytes of the hash of an anchor instruction name
+func anchorDiscriminator(name string) [8]byte {
+	if discriminator, ok := discriminatorCache.Load(name); ok {
+		return discriminator.([8]byte)
+	}
+	var discriminator [8]byte
+	hash := sha256.Sum256([]byte("global:" + name))
+	copy(discriminator[:], hash[:8])
+	discriminatorCache.Store(name, discriminator)
+	return discriminator
+}
+
+// anchorInstructionData returns the anchor discriminator of an instruction followed by an u64 argument
+func anchorInstructionData(name string, arg uint64) []byte {
+	discriminator := anchorDiscriminator(name)
+	data := make([]byte, 16)
+	copy(data, discriminator[:])
+	binary.LittleEndian.PutUint64(data[8:], arg)
+	return data
+}
+
+// NewLiquidStake deposits SOL to Marinade in exchange for mSOL
+// The mSOL associated token account is created if input.ShouldCreateATA is set
+func (txBuilder TxBuilder) NewLiquidStake(from xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	marinade, txInput, amountU64, err := txBuilder.parseMarinade(amount, input)
+	if err != nil {
+		return nil, err
+	}
+	accountFrom, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	mintTo, _, err := solana.FindAssociatedTokenAddress(accountFrom, marinade.MSolMint)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := []solana.Instruction{}
+	if txInput.ShouldCreateATA {
+		instructions = append(instructions,
+			ata.NewCreateInstruction(accountFrom, accountFrom, marinade.MSolMint).Build(),
+		)
+	}
+	instructions = append(instructions, solana.NewInstruction(
+		marinade.Program,
+		solana.AccountMetaSlice{
+			solana.Meta(marinade.State).WRITE(),
+			solana.Meta(marinade.MSolMint).WRITE(),
+			solana.Meta(marinade.pda("liq_sol")).WRITE(),
+			solana.Meta(marinade.LiqPoolMSolLeg).WRITE(),
+			solana.Meta(marinade.pda("liq_st_sol_authority")),
+			solana.Meta(marinade.pda("reserve")).WRITE(),
+			solana.Meta(accountFrom).WRITE().SIGNER(),
+			solana.Meta(mintTo).WRITE(),
+			solana.Meta(marinade.pda("st_mint")),
+			solana.Meta(solana.SystemProgramID),
+			solana.Meta(solana.TokenProgramID),
+		},
+		anchorInstructionData("deposit", amountU64),
+	))
+	return txBuilder.buildSolanaTx(instructions, accountFrom, txInput)
+}
+
+// NewLiquidUnstake swaps mSOL for SOL via the Marinade liquidity pool
+func (txBuilder TxBuilder) NewLiquidUnstake(from xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	marinade, txInput, amountU64, err := txBuilder.parseMarinade(amount, input)
+	if err != nil {
+		return nil, err
+	}
+	accountFrom, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	msolFrom, _, err := solana.FindAssociatedTokenAddress(accountFrom, marinade.MSolMint)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := []solana.Instruction{
+		solana.NewInstruction(
+			marinade.Program,
+			solana.AccountMetaSlice{
+				solana.Meta(marinade.State).WRITE(),
+				solana.Meta(marinade.MSolMint).WRITE(),
+				solana.Meta(marinade.pda("liq_sol")).WRITE(),
+				solana.Meta(marinade.LiqPoolMSolLeg).WRITE(),
+				solana.Meta(marinade.TreasuryMSolAccount).WRITE(),
+				solana.Meta(msolFrom).WRITE(),
+				solana.Meta(accountFrom).SIGNER(),
+				solana.Meta(accountFrom).WRITE(),
+				solana.Meta(solana.SystemProgramID),
+				solana.Meta(solana.TokenProgramID),
+			},
+			anchorInstructionData("liquid_unstake", amountU64),
+		),
+	}
+	return txBuilder.buildSolanaTx(instructions, accountFrom, txInput)
+}
+
+// FetchStakedBalance fetches the Marinade mSOL balance of an address
+// The underlying SOL amount isn't computed as it requires decoding the Marinade state
+func (client *Client) FetchStakedBalance(ctx context.Context, address xc.Address) ([]xc.StakedBalance, error) {
+	marinade, err := getMarinadeAccounts(client.Asset)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := client.fetchContractBalance(ctx, address, marinade.MSolMint.String())
+	if err != nil {
+		return nil, err
+	}
+	if balance.Sign() == 0 {
+		return []xc.StakedBalance{}, nil
+	}
+	return []xc.StakedBalance{
+		{
+			Protocol:   xc.StakingProtocolMarinade,
+			Address:    address,
+			Contract:   xc.ContractAddress(marinade.MSolMint.String()),
+			Amount:     balance,
+			Underlying: xc.NewAmountBlockchainFromUint64(0),
+			Pending:    xc.NewAmountBlockchainFromUint64(0),
+		},
+	}, nil
+}
@@ -0,0 +1,105 @@
+package solana
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestNewLiquidStake() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.SOL, Net: xc.Mainnet})
+	from := xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	amount := xc.NewAmountBlockchainFromUint64(1_000_000_000)
+
+	tx, err := builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, amount, &TxInput{})
+	require.Nil(err)
+	solTx := tx.(*Tx).SolTx
+	require.Len(solTx.Message.Instructions, 1)
+	instruction := solTx.Message.Instructions[0]
+	require.Equal(MarinadeMainnet.Program, solTx.Message.AccountKeys[instruction.ProgramIDIndex])
+	require.Equal("f223c68952e1f2b600ca9a3b00000000", hex.EncodeToString(instruction.Data))
+	require.Len(instruction.Accounts, 11)
+	msolAta, _ := FindAssociatedTokenAddress(string(from), MarinadeMainnet.MSolMint.String())
+	require.Equal(solana.MustPublicKeyFromBase58(msolAta), solTx.Message.AccountKeys[instruction.Accounts[7]])
+
+	tx, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, amount, &TxInput{ShouldCreateATA: true})
+	require.Nil(err)
+	require.Len(tx.(*Tx).SolTx.Message.Instructions, 2)
+
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidStake("from", amount, &TxInput{})
+	require.EqualError(err, "invalid length, expected 32, got 3")
+
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, amount, &struct{ xc.TxInputEnvelope }{})
+	require.ErrorContains(err, "not from a solana chain")
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, xc.NewAmountBlockchainFromStr("18446744073709551616"), &TxInput{})
+	require.ErrorContains(err, "must fit in a u64")
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, xc.AmountBlockchain(*big.NewInt(-1)), &TxInput{})
+	require.ErrorContains(err, "must fit in a u64")
+
+	// mainnet accounts aren't used on other networks
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.SOL, Net: xc.Devnet})
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidStake(from, amount, &TxInput{})
+	require.EqualError(err, "marinade is not supported on network 'devnet'")
+}
+
+func (s *CrosschainTestSuite) TestNewLiquidUnstake() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.SOL, Net: xc.Mainnet})
+	from := xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+
+	tx, err := builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, xc.NewAmountBlockchainFromUint64(500), &TxInput{})
+	require.Nil(err)
+	solTx := tx.(*Tx).SolTx
+	require.Len(solTx.Message.Instructions, 1)
+	instruction := solTx.Message.Instructions[0]
+	require.Equal("1e1e77f0bfe30c10f401000000000000", hex.EncodeToString(instruction.Data))
+	require.Equal(solana.MustPublicKeyFromBase58(string(from)), solTx.Message.AccountKeys[0])
+
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, xc.NewAmountBlockchainFromStr("18446744073709551616"), &TxInput{})
+	require.ErrorContains(err, "must fit in a u64")
+
+	builder, _ = NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.SOL, Net: xc.Testnet})
+	_, err = builder.(xc.TxLiquidStakingBuilder).NewLiquidUnstake(from, xc.NewAmountBlockchainFromUint64(500), &TxInput{})
+	require.EqualError(err, "marinade is not supported on network 'testnet'")
+}
+
+func (s *CrosschainTestSuite) TestFetchStakedBalance() {
+	require := s.Require()
+
+	vectors := []struct {
+		resp      interface{}
+		positions int
+		val       string
+	}{
+		{
+			`{"context":{"slot":1114},"value":{"amount":"9864","decimals":9,"uiAmount":0.000009864,"uiAmountString":"0.000009864"}}`,
+			1,
+			"9864",
+		},
+		{
+			`{"context":{"slot":1114},"value":{"amount":"0","decimals":9,"uiAmount":0,"uiAmountString":"0"}}`,
+			0,
+			"",
+		},
+	}
+
+	for _, v := range vectors {
+		server, close := test.MockJSONRPC(&s.Suite, v.resp)
+		defer close()
+
+		client, _ := NewClient(&xc.AssetConfig{URL: server.URL, NativeAsset: xc.SOL, Net: xc.Mainnet})
+		from := xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+		positions, err := client.FetchStakedBalance(s.Ctx, from)
+		require.Nil(err)
+		require.Len(positions, v.positions)
+		if v.positions > 0 {
+			require.Equal(xc.StakingProtocolMarinade, positions[0].Protocol)
+			require.Equal(v.val, positions[0].Amount.String())
+			require.Equal(xc.ContractAddress(MarinadeMainnet.MSolMint.String()), positions[0].Contract)
+		}
+	}
+}
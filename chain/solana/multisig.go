@@ -0,0 +1,93 @@
+package solana
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+var _ xc.TxMultisig = Tx{}
+
+// Threshold is the number of signers of a tx signed by several signers, e.g. the signers of an SPL token multisig,
+// 0 for a single signer
+// Every signer of a Solana tx must sign, so the threshold of the multisig is met when the signers are chosen in TxInput.MultisigSigners
+func (tx Tx) Threshold() int {
+	if tx.SolTx == nil || tx.SolTx.Message.Header.NumRequiredSignatures < 2 {
+		return 0
+	}
+	return int(tx.SolTx.Message.Header.NumRequiredSignatures)
+}
+
+// Signers are the public keys of the signers of the tx, the fee payer first
+func (tx Tx) Signers() []xc.PublicKey {
+	signers := []xc.PublicKey{}
+	if tx.SolTx == nil {
+		return signers
+	}
+	for _, account := range tx.signerAccounts() {
+		signers = append(signers, account.Bytes())
+	}
+	return signers
+}
+
+func (tx Tx) signerAccounts() []solana.PublicKey {
+	required := int(tx.SolTx.Message.Header.NumRequiredSignatures)
+	if required > len(tx.SolTx.Message.AccountKeys) {
+		required = len(tx.SolTx.Message.AccountKeys)
+	}
+	return tx.SolTx.Message.AccountKeys[:required]
+}
+
+// SignedBy returns the public keys of the signers whose signatures were added
+func (tx Tx) SignedBy() []xc.PublicKey {
+	signers := []xc.PublicKey{}
+	if tx.SolTx == nil {
+		return signers
+	}
+	for i, account := range tx.signerAccounts() {
+		if i < len(tx.SolTx.Signatures) && !tx.SolTx.Signatures[i].IsZero() {
+			signers = append(signers, account.Bytes())
+		}
+	}
+	return signers
+}
+
+// AddSignaturesOf adds the signature of signer over the message, checked against it, at the position of signer
+func (tx Tx) AddSignaturesOf(signer xc.PublicKey, signatures ...xc.TxSignature) error {
+	if tx.SolTx == nil {
+		return errors.New("transaction not initialized")
+	}
+	if tx.Threshold() == 0 {
+		return errors.New("tx is not sent from a multisig")
+	}
+	index := -1
+	for i, account := range tx.signerAccounts() {
+		if account.Equals(solana.PublicKeyFromBytes(signer)) {
+			index = i
+		}
+	}
+	if len(signer) != solana.PublicKeyLength || index < 0 {
+		return fmt.Errorf("%x is not a signer of the multisig", []byte(signer))
+	}
+	if len(signatures) != 1 {
+		return errors.New("invalid signatures size")
+	}
+	if len(signatures[0]) != solana.SignatureLength {
+		return fmt.Errorf("invalid signature (%d): %x", len(signatures[0]), signatures[0])
+	}
+	message, err := tx.SolTx.Message.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("unable to encode message for signing: %w", err)
+	}
+	signature := solana.SignatureFromBytes(signatures[0])
+	if !solana.PublicKeyFromBytes(signer).Verify(message, signature) {
+		return fmt.Errorf("signature is not signed by %x", []byte(signer))
+	}
+	if len(tx.SolTx.Signatures) != tx.Threshold() {
+		tx.SolTx.Signatures = make([]solana.Signature, tx.Threshold())
+	}
+	tx.SolTx.Signatures[index] = signature
+	return nil
+}
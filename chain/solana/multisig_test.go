@@ -0,0 +1,70 @@
+package solana
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestMultisigTokenTransfer() {
+	require := s.Require()
+	contract := "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"
+	builder, _ := NewTxBuilder(&xc.AssetConfig{Type: xc.AssetTypeToken, Contract: contract, Decimals: 6})
+	multisig := xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := xc.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	privateKeys := []solana.PrivateKey{}
+	signers := []string{}
+	for i := byte(1); i <= 2; i++ {
+		privateKey := solana.PrivateKey(ed25519.NewKeyFromSeed(bytes.Repeat([]byte{i}, 32)))
+		privateKeys = append(privateKeys, privateKey)
+		signers = append(signers, privateKey.PublicKey().String())
+	}
+
+	// 2 of the signers of the multisig sign
+	input := &TxInput{MultisigSigners: signers}
+	require.Equal(xc.NewAmountBlockchainFromUint64(2*LamportsPerSignature), input.MaxFee())
+	tf, err := builder.(xc.TxTokenBuilder).NewTokenTransfer(multisig, to, xc.NewAmountBlockchainFromUint64(1_200_000), input)
+	require.NoError(err)
+	require.Equal(multisig, tf.(*Tx).From())
+	transfer := tf.(*Tx).parsedTransfer.(*token.TransferChecked)
+	require.False(transfer.GetOwnerAccount().IsSigner)
+	require.Len(transfer.Signers, 2)
+
+	tx, ok := xc.AsMultisig(tf)
+	require.True(ok)
+	require.Equal(2, tx.Threshold())
+	publicKeys := []xc.PublicKey{privateKeys[0].PublicKey().Bytes(), privateKeys[1].PublicKey().Bytes()}
+	// fee payer first
+	require.Equal(publicKeys, tx.Signers())
+	require.Empty(tx.SignedBy())
+
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	sign := func(privateKey solana.PrivateKey) xc.TxSignature {
+		signature, err := privateKey.Sign(sighashes[0])
+		require.NoError(err)
+		return signature[:]
+	}
+	err = tx.AddSignaturesOf(publicKeys[0], sign(privateKeys[1]))
+	require.EqualError(err, fmt.Sprintf("signature is not signed by %x", []byte(publicKeys[0])))
+	other := solana.MustPublicKeyFromBase58(string(to))
+	err = tx.AddSignaturesOf(other.Bytes(), sign(privateKeys[0]))
+	require.EqualError(err, fmt.Sprintf("%x is not a signer of the multisig", other.Bytes()))
+
+	require.NoError(tx.AddSignaturesOf(publicKeys[1], sign(privateKeys[1])))
+	require.Equal([]xc.PublicKey{publicKeys[1]}, tx.SignedBy())
+	require.NoError(tx.AddSignaturesOf(publicKeys[0], sign(privateKeys[0])))
+	require.Equal(publicKeys, tx.SignedBy())
+	require.NoError(tf.(*Tx).SolTx.VerifySignatures())
+	require.Equal(xc.TxHash(tf.(*Tx).SolTx.Signatures[0].String()), tf.Hash())
+
+	// single signer txs aren't multisig
+	tf, _ = builder.(xc.TxTokenBuilder).NewTokenTransfer(multisig, to, xc.NewAmountBlockchainFromUint64(1_200_000), &TxInput{})
+	_, ok = xc.AsMultisig(tf)
+	require.False(ok)
+	require.EqualError(tf.(*Tx).AddSignaturesOf(publicKeys[0], sign(privateKeys[0])), "tx is not sent from a multisig")
+}
@@ -0,0 +1,192 @@
+package solana
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	ata "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// MaxSeedAccountsPerTx is the number of seed accounts created in a single tx,
+// bounded by the size of a tx with seeds of the max length
+const MaxSeedAccountsPerTx = 5
+
+// FindAddressWithSeed returns the address derived from base, seed and the owner program, as created by createAccountWithSeed
+// Seed accounts have no private key: base signs for them, so a single key holds many sub-accounts without ATAs
+func FindAddressWithSeed(base string, seed string, owner solana.PublicKey) (string, error) {
+	baseAccount, err := solana.PublicKeyFromBase58(base)
+	if err != nil {
+		return "", err
+	}
+	address, err := solana.CreateWithSeed(baseAccount, seed, owner)
+	if err != nil {
+		return "", fmt.Errorf("invalid seed '%s': %v", seed, err)
+	}
+	return address.String(), nil
+}
+
+// assetContract returns the mint of a token asset, empty for native assets
+func assetContract(asset xc.ITask) string {
+	if token, ok := asset.(*xc.TokenAssetConfig); ok {
+		return string(token.Contract)
+	}
+	return string(asset.GetAssetConfig().Contract)
+}
+
+// seedAccountOwner returns the program owning the seed accounts of an asset and their size:
+// the system program for SOL, the token program for tokens
+func seedAccountOwner(asset xc.ITask) (solana.PublicKey, uint64) {
+	if assetContract(asset) != "" {
+		return solana.TokenProgramID, TokenAccountSize
+	}
+	return solana.SystemProgramID, 0
+}
+
+// FindSeedAddress returns the address of the seed account of base for the asset
+func (txBuilder TxBuilder) FindSeedAddress(base xc.Address, seed string) (xc.Address, error) {
+	owner, _ := seedAccountOwner(txBuilder.Asset)
+	address, err := FindAddressWithSeed(string(base), seed, owner)
+	return xc.Address(address), err
+}
+
+// NewCreateSeedAccounts creates the seed accounts of base for the asset, batched in a single tx funded and signed by base
+// Token accounts are initialized with base as their owner; txInput.RentExemption must be set, see FetchSeedAccountsInput
+func (txBuilder TxBuilder) NewCreateSeedAccounts(base xc.Address, seeds []string, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	if len(seeds) == 0 {
+		return nil, errors.New("no seed to create")
+	}
+	if len(seeds) > MaxSeedAccountsPerTx {
+		return nil, fmt.Errorf("too many seed accounts: %d, max %d per tx", len(seeds), MaxSeedAccountsPerTx)
+	}
+	if txInput.RentExemption == 0 {
+		return nil, errors.New("rent exemption is required to create seed accounts")
+	}
+	baseAccount, err := solana.PublicKeyFromBase58(string(base))
+	if err != nil {
+		return nil, err
+	}
+	owner, space := seedAccountOwner(txBuilder.Asset)
+	contract := assetContract(txBuilder.Asset)
+
+	instructions := []solana.Instruction{}
+	for _, seed := range seeds {
+		address, err := FindAddressWithSeed(string(base), seed, owner)
+		if err != nil {
+			return nil, err
+		}
+		account := solana.MustPublicKeyFromBase58(address)
+		instructions = append(instructions,
+			system.NewCreateAccountWithSeedInstruction(
+				baseAccount,
+				seed,
+				txInput.RentExemption,
+				space,
+				owner,
+				baseAccount,
+				account,
+				baseAccount,
+			).Build(),
+		)
+		if contract != "" {
+			mint, err := solana.PublicKeyFromBase58(contract)
+			if err != nil {
+				return nil, err
+			}
+			instructions = append(instructions,
+				token.NewInitializeAccount3Instruction(baseAccount, account, mint).Build(),
+			)
+		}
+	}
+	return txBuilder.buildSolanaTx(instructions, baseAccount, txInput)
+}
+
+// newNativeTransferFromSeed transfers SOL from the seed account txInput.FromSeed of from
+// Only from signs: the seed account is neither a signer nor the fee payer
+func (txBuilder TxBuilder) newNativeTransferFromSeed(from xc.Address, to xc.Address, amount xc.AmountBlockchain, txInput *TxInput) (xc.Tx, error) {
+	accountFrom, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	accountTo, err := solana.PublicKeyFromBase58(string(to))
+	if err != nil {
+		return nil, err
+	}
+	seedAddress, err := FindAddressWithSeed(string(from), txInput.FromSeed, solana.SystemProgramID)
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := []solana.Instruction{
+		system.NewTransferWithSeedInstruction(
+			amount.Uint64(),
+			txInput.FromSeed,
+			solana.SystemProgramID,
+			solana.MustPublicKeyFromBase58(seedAddress),
+			accountFrom,
+			accountTo,
+		).Build(),
+	}
+	return txBuilder.buildSolanaTx(instructions, accountFrom, txInput)
+}
+
+// newTokenTransferFromSeed transfers tokens from the seed token account txInput.FromSeed of from, its owner
+func (txBuilder TxBuilder) newTokenTransferFromSeed(from xc.Address, to xc.Address, amount xc.AmountBlockchain, txInput *TxInput) (xc.Tx, error) {
+	asset := txBuilder.Asset.GetAssetConfig()
+	contract := assetContract(txBuilder.Asset)
+
+	accountFrom, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	accountContract, err := solana.PublicKeyFromBase58(contract)
+	if err != nil {
+		return nil, err
+	}
+	accountTo, err := solana.PublicKeyFromBase58(string(to))
+	if err != nil {
+		return nil, err
+	}
+	seedAddress, err := FindAddressWithSeed(string(from), txInput.FromSeed, solana.TokenProgramID)
+	if err != nil {
+		return nil, err
+	}
+
+	ataTo := accountTo
+	if !txInput.ToIsATA {
+		ataToStr, err := FindAssociatedTokenAddress(string(to), contract)
+		if err != nil {
+			return nil, err
+		}
+		ataTo = solana.MustPublicKeyFromBase58(ataToStr)
+	}
+
+	instructions := []solana.Instruction{}
+	if txInput.ShouldCreateATA && !txInput.ToIsATA {
+		instructions = append(instructions,
+			ata.NewCreateInstruction(
+				accountFrom,
+				accountTo,
+				accountContract,
+			).Build(),
+		)
+	}
+	instructions = append(instructions,
+		token.NewTransferCheckedInstruction(
+			amount.Uint64(),
+			uint8(asset.Decimals),
+			solana.MustPublicKeyFromBase58(seedAddress),
+			accountContract,
+			ataTo,
+			accountFrom,
+			[]solana.PublicKey{
+				accountFrom,
+			},
+		).Build(),
+	)
+	return txBuilder.buildSolanaTx(instructions, accountFrom, txInput)
+}
@@ -0,0 +1,154 @@
+package solana
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const seedBase = "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"
+const seedContract = "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"
+
+func (s *CrosschainTestSuite) TestFindAddressWithSeed() {
+	require := s.Require()
+	// sha256(base || seed || owner)
+	base := solana.MustPublicKeyFromBase58(seedBase)
+	hash := sha256.Sum256(append(append(base.Bytes(), []byte("deposit-1")...), solana.SystemProgramID.Bytes()...))
+
+	address, err := FindAddressWithSeed(seedBase, "deposit-1", solana.SystemProgramID)
+	require.NoError(err)
+	require.Equal(solana.PublicKeyFromBytes(hash[:]).String(), address)
+
+	tokenAddress, err := FindAddressWithSeed(seedBase, "deposit-1", solana.TokenProgramID)
+	require.NoError(err)
+	require.NotEqual(address, tokenAddress)
+
+	builder, _ := NewTxBuilder(&xc.AssetConfig{Type: xc.AssetTypeToken, Contract: seedContract, Decimals: 6})
+	seedAddress, err := builder.(TxBuilder).FindSeedAddress(seedBase, "deposit-1")
+	require.NoError(err)
+	require.Equal(xc.Address(tokenAddress), seedAddress)
+
+	_, err = FindAddressWithSeed(seedBase, "a-seed-longer-than-the-max-32-chars", solana.SystemProgramID)
+	require.ErrorContains(err, "invalid seed")
+	_, err = FindAddressWithSeed("base", "deposit-1", solana.SystemProgramID)
+	require.Error(err)
+}
+
+func (s *CrosschainTestSuite) TestNewCreateSeedAccounts() {
+	require := s.Require()
+	input := &TxInput{RentExemption: 890880}
+
+	builder, _ := NewTxBuilder(&xc.AssetConfig{})
+	tx, err := builder.(TxBuilder).NewCreateSeedAccounts(seedBase, []string{"deposit-1", "deposit-2"}, input)
+	require.NoError(err)
+	solTx := tx.(*Tx).SolTx
+	require.Len(solTx.Message.Instructions, 2)
+	// only base signs and pays
+	require.Equal(uint8(1), solTx.Message.Header.NumRequiredSignatures)
+	require.Equal(seedBase, solTx.Message.AccountKeys[0].String())
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes, 1)
+	seedAddress, _ := FindAddressWithSeed(seedBase, "deposit-2", solana.SystemProgramID)
+	require.Contains(solTx.Message.AccountKeys, solana.MustPublicKeyFromBase58(seedAddress))
+
+	// tokens accounts are initialized
+	builder, _ = NewTxBuilder(&xc.TokenAssetConfig{Contract: seedContract, Decimals: 6})
+	tx, err = builder.(TxBuilder).NewCreateSeedAccounts(seedBase, []string{"deposit-1", "deposit-2"}, input)
+	require.NoError(err)
+	solTx = tx.(*Tx).SolTx
+	require.Len(solTx.Message.Instructions, 4)
+	require.Equal(uint8(1), solTx.Message.Header.NumRequiredSignatures)
+	program, _ := solTx.Message.ResolveProgramIDIndex(solTx.Message.Instructions[1].ProgramIDIndex)
+	require.Equal(solana.TokenProgramID, program)
+
+	// a full batch with seeds of the max length fits in a tx
+	seeds := []string{}
+	for i := 0; i < MaxSeedAccountsPerTx; i++ {
+		seeds = append(seeds, fmt.Sprintf("%032d", i))
+	}
+	tx, err = builder.(TxBuilder).NewCreateSeedAccounts(seedBase, seeds, input)
+	require.NoError(err)
+	require.NoError(tx.AddSignatures(make([]byte, solana.SignatureLength)))
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	require.LessOrEqual(len(serialized), 1232)
+
+	_, err = builder.(TxBuilder).NewCreateSeedAccounts(seedBase, []string{}, input)
+	require.EqualError(err, "no seed to create")
+	_, err = builder.(TxBuilder).NewCreateSeedAccounts(seedBase, []string{"1", "2", "3", "4", "5", "6"}, input)
+	require.EqualError(err, "too many seed accounts: 6, max 5 per tx")
+	_, err = builder.(TxBuilder).NewCreateSeedAccounts(seedBase, []string{"1"}, &TxInput{})
+	require.EqualError(err, "rent exemption is required to create seed accounts")
+}
+
+func (s *CrosschainTestSuite) TestNewNativeTransferFromSeed() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{})
+	to := xc.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	amount := xc.NewAmountBlockchainFromUint64(1200000)
+
+	tx, err := builder.(xc.TxTokenBuilder).NewNativeTransfer(seedBase, to, amount, &TxInput{FromSeed: "deposit-1"})
+	require.NoError(err)
+	solTx := tx.(*Tx).SolTx
+	require.Len(solTx.Message.Instructions, 1)
+	// the seed account isn't a signer
+	require.Equal(uint8(1), solTx.Message.Header.NumRequiredSignatures)
+	require.Equal(seedBase, solTx.Message.AccountKeys[0].String())
+
+	seedAddress, _ := FindAddressWithSeed(seedBase, "deposit-1", solana.SystemProgramID)
+	parsed := tx.(*Tx)
+	parsed.ParseTransfer()
+	require.IsType(&system.TransferWithSeed{}, parsed.parsedTransfer)
+	require.Equal(xc.Address(seedAddress), parsed.From())
+	require.Equal(to, parsed.To())
+	require.Equal("1200000", parsed.Amount().String())
+
+	_, err = builder.(xc.TxTokenBuilder).NewNativeTransfer(seedBase, to, amount, &TxInput{FromSeed: "a-seed-longer-than-the-max-32-chars"})
+	require.ErrorContains(err, "invalid seed")
+}
+
+func (s *CrosschainTestSuite) TestNewTokenTransferFromSeed() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{Type: xc.AssetTypeToken, Contract: seedContract, Decimals: 6})
+	to := xc.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	amount := xc.NewAmountBlockchainFromUint64(1200000)
+
+	tx, err := builder.(xc.TxTokenBuilder).NewTokenTransfer(seedBase, to, amount, &TxInput{FromSeed: "deposit-1", ShouldCreateATA: true})
+	require.NoError(err)
+	solTx := tx.(*Tx).SolTx
+	require.Len(solTx.Message.Instructions, 2)
+	require.Equal(uint8(1), solTx.Message.Header.NumRequiredSignatures)
+	require.Equal(seedBase, solTx.Message.AccountKeys[0].String())
+
+	parsed := tx.(*Tx)
+	parsed.ParseTransfer()
+	seedAddress, _ := FindAddressWithSeed(seedBase, "deposit-1", solana.TokenProgramID)
+	ataTo, _ := FindAssociatedTokenAddress(string(to), seedContract)
+	transfer, err := parsed.getTokenTransferChecked()
+	require.NoError(err)
+	require.Equal(seedAddress, transfer.GetSourceAccount().PublicKey.String())
+	require.Equal(xc.Address(seedBase), parsed.From())
+	require.Equal(xc.Address(ataTo), parsed.ToAlt())
+	require.Equal(xc.ContractAddress(seedContract), parsed.ContractAddress())
+}
+
+func (s *CrosschainTestSuite) TestFetchSeedAccountsInput() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`{"context":{"slot":83986105},"value":{"blockhash":"DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK","feeCalculator":{"lamportsPerSignature":5000}}}`,
+		`2039280`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.TokenAssetConfig{Contract: seedContract, NativeAssetConfig: &xc.AssetConfig{URL: server.URL}})
+
+	input, err := client.FetchSeedAccountsInput(s.Ctx)
+	require.NoError(err)
+	require.Equal("DvLEyV2GHk86K5GojpqnRsvhfMF5kdZomKMnhVpvHyqK", input.RecentBlockHash.String())
+	require.Equal(uint64(2039280), input.RentExemption)
+	require.Equal(2, server.Counter)
+}
@@ -2,6 +2,7 @@ package solana
 
 import (
 	"crypto/ed25519"
+	"fmt"
 
 	"github.com/btcsuite/btcutil/base58"
 	xc "github.com/jumpcrypto/crosschain"
@@ -26,3 +27,12 @@ func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.Tx
 	signatureRaw := ed25519.Sign(ed25519.PrivateKey(privateKey), []byte(data))
 	return xc.TxSignature(signatureRaw), nil
 }
+
+// DerivePublicKey returns the public key of a Solana private key, made of the seed and the public key
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+	}
+	publicKey := ed25519.PrivateKey(privateKey).Public().(ed25519.PublicKey)
+	return xc.PublicKey(publicKey), nil
+}
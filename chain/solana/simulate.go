@@ -0,0 +1,81 @@
+package solana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// programFailed matches the log of a failed instruction, e.g.
+// "Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA failed: custom program error: 0x1"
+var programFailed = regexp.MustCompile(`^Program \w+ failed: (.+)$`)
+
+// ParseProgramLogs returns the human readable reason of the program logs of a failed tx:
+// the last "Program log: Error: " message of a program, or else the error of the failed instruction
+func ParseProgramLogs(logs []string) string {
+	for i := len(logs) - 1; i >= 0; i-- {
+		if reason := strings.TrimPrefix(logs[i], "Program log: Error: "); reason != logs[i] {
+			return strings.TrimSpace(reason)
+		}
+	}
+	for i := len(logs) - 1; i >= 0; i-- {
+		if match := programFailed.FindStringSubmatch(logs[i]); match != nil {
+			return strings.TrimSpace(match[1])
+		}
+	}
+	return ""
+}
+
+var _ xc.ClientSimulator = &Client{}
+
+// SimulateTx simulates tx with simulateTransaction, with the preflight checks of SubmitTx
+func (client *Client) SimulateTx(ctx context.Context, tx xc.Tx) (*xc.SimulationResult, error) {
+	var solTx *solana.Transaction
+	switch tx := tx.(type) {
+	case *Tx:
+		solTx = tx.SolTx
+	case Tx:
+		solTx = tx.SolTx
+	}
+	if solTx == nil {
+		return nil, xc.ErrSimulationNotSupported
+	}
+	res, err := client.SolClient.SimulateTransactionWithOpts(ctx, solTx, &rpc.SimulateTransactionOpts{
+		SigVerify:  true,
+		Commitment: rpc.CommitmentFinalized,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &xc.SimulationResult{
+		Chain:   client.Asset.GetNativeAsset().NativeAsset,
+		TxHash:  tx.Hash(),
+		Success: true,
+	}
+	if res.Value == nil {
+		return result, nil
+	}
+	result.Logs = res.Value.Logs
+	if res.Value.UnitsConsumed != nil {
+		result.GasUsed = *res.Value.UnitsConsumed
+	}
+	if res.Value.Err != nil {
+		result.Success = false
+		rawError, err := json.Marshal(res.Value.Err)
+		if err != nil {
+			rawError = []byte(fmt.Sprintf("%v", res.Value.Err))
+		}
+		result.RawError = string(rawError)
+		result.FailureReason = ParseProgramLogs(res.Value.Logs)
+		if result.FailureReason == "" {
+			result.FailureReason = result.RawError
+		}
+	}
+	return result, nil
+}
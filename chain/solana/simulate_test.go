@@ -0,0 +1,92 @@
+package solana
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestParseProgramLogs() {
+	require := s.Require()
+	vectors := []struct {
+		logs   []string
+		reason string
+	}{
+		{
+			[]string{
+				"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA invoke [1]",
+				"Program log: Instruction: Transfer",
+				"Program log: Error: insufficient funds",
+				"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA consumed 2816 of 200000 compute units",
+				"Program TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA failed: custom program error: 0x1",
+			},
+			"insufficient funds",
+		},
+		{
+			[]string{
+				"Program 11111111111111111111111111111111 invoke [1]",
+				"Transfer: insufficient lamports 0, need 1000000000",
+				"Program 11111111111111111111111111111111 failed: custom program error: 0x1",
+			},
+			"custom program error: 0x1",
+		},
+		{[]string{"Program 11111111111111111111111111111111 invoke [1]"}, ""},
+		{nil, ""},
+	}
+	for _, v := range vectors {
+		require.Equal(v.reason, ParseProgramLogs(v.logs))
+	}
+}
+
+func (s *CrosschainTestSuite) TestSimulateTx() {
+	require := s.Require()
+	txbin := "01df5ff457c2cdd23242ab26edd0b308d78499f28c6d43e185149cacdb88b35db171f1779e48ce2224cc80b9b9ce46dd80758319068b08eae34b14dc2cd070ab000100010379726da52d99d60b07ead73b2f6f0bf6083cc85c77a94e34d691d78f8bcafec9fc880863219008406235fa4c8fbb2a86d3da7b6762eac39323b2a1d8c404a4140000000000000000000000000000000000000000000000000000000000000000932bbef1569d58f4a116f41028f766439b2ba52c68c3308bbbea2b21e4716f6701020200010c0200000000ca9a3b00000000"
+	bytes, _ := hex.DecodeString(txbin)
+	solTx, _ := solana.TransactionFromDecoder(bin.NewBinDecoder(bytes))
+	tx := &Tx{SolTx: solTx}
+
+	server, close := test.MockJSONRPC(&s.Suite, `{"context":{"slot":1},"value":{"err":null,"logs":["Program 11111111111111111111111111111111 invoke [1]","Program 11111111111111111111111111111111 success"],"unitsConsumed":150}}`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+	result, err := client.SimulateTx(s.Ctx, tx)
+	require.NoError(err)
+	require.Equal(&xc.SimulationResult{
+		Chain:   xc.SOL,
+		TxHash:  tx.Hash(),
+		Success: true,
+		GasUsed: 150,
+		Logs:    []string{"Program 11111111111111111111111111111111 invoke [1]", "Program 11111111111111111111111111111111 success"},
+	}, result)
+	require.NoError(result.Err())
+
+	server, close = test.MockJSONRPC(&s.Suite, `{"context":{"slot":1},"value":{"err":{"InstructionError":[0,{"Custom":1}]},"logs":["Program 11111111111111111111111111111111 invoke [1]","Transfer: insufficient lamports 0, need 1000000000","Program 11111111111111111111111111111111 failed: custom program error: 0x1"],"unitsConsumed":150}}`)
+	defer close()
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+	result, err = client.SimulateTx(s.Ctx, tx)
+	require.NoError(err)
+	require.False(result.Success)
+	require.Equal("custom program error: 0x1", result.FailureReason)
+	require.Equal(`{"InstructionError":[0,{"Custom":1}]}`, result.RawError)
+	require.Len(result.Logs, 3)
+	require.EqualError(result.Err(), fmt.Sprintf("simulation of tx %s failed: custom program error: 0x1", tx.Hash()))
+
+	// failures without logs report the raw error
+	server, close = test.MockJSONRPC(&s.Suite, `{"context":{"slot":1},"value":{"err":"BlockhashNotFound","logs":null}}`)
+	defer close()
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.SOL, URL: server.URL})
+	result, err = client.SimulateTx(s.Ctx, tx)
+	require.NoError(err)
+	require.False(result.Success)
+	require.Equal(`"BlockhashNotFound"`, result.FailureReason)
+
+	// dry runs fail with the reason
+	err = client.SubmitTx(xc.WithDryRun(s.Ctx, &xc.DryRun{}), tx)
+	require.ErrorContains(err, `simulate transaction: "BlockhashNotFound"`)
+
+	_, err = client.SimulateTx(s.Ctx, &test.MockXcTx{})
+	require.ErrorIs(err, xc.ErrSimulationNotSupported)
+}
@@ -0,0 +1,160 @@
+package solana
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// StakeAccountSize is the size of a stake account, in bytes
+const StakeAccountSize = 200
+
+// StakeAccountRent is the rent exempt balance of a stake account, in lamports, used if TxInput.RentExemption isn't set
+const StakeAccountRent = 2_282_880
+
+// StakeConfigID is the config account of the stake program
+var StakeConfigID = solana.MustPublicKeyFromBase58("StakeConfig11111111111111111111111111111111")
+
+// stake program instructions, encoded as little endian u32
+const (
+	stakeInstructionInitialize    = 0
+	stakeInstructionDelegateStake = 2
+	stakeInstructionDeactivate    = 5
+)
+
+var _ xc.TxStakingBuilder = TxBuilder{}
+
+// StakeSeed returns the default seed of the stake account of from delegating to validator, see FindStakeAddress
+func StakeSeed(validator xc.Address) string {
+	seed := "stake:" + string(validator)
+	if len(seed) > solana.MaxSeedLength {
+		seed = seed[:solana.MaxSeedLength]
+	}
+	return seed
+}
+
+// stakeSeed returns txInput.StakeSeed, or the default seed of validator
+func stakeSeed(validator xc.Address, txInput *TxInput) string {
+	if txInput.StakeSeed != "" {
+		return txInput.StakeSeed
+	}
+	return StakeSeed(validator)
+}
+
+// FindStakeAddress returns the address of the stake account of from, derived with seed
+func FindStakeAddress(from xc.Address, seed string) (xc.Address, error) {
+	address, err := FindAddressWithSeed(string(from), seed, solana.StakeProgramID)
+	return xc.Address(address), err
+}
+
+// NewDelegate creates a stake account of from, derived with txInput.StakeSeed or StakeSeed(validator), with amount
+// and delegates it to the vote account validator, with from as its staker and withdrawer
+// Staking more with the same validator requires another stake account, hence another txInput.StakeSeed
+func (txBuilder TxBuilder) NewDelegate(from xc.Address, validator xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	accountFrom, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	voteAccount, err := solana.PublicKeyFromBase58(string(validator))
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+	seed := stakeSeed(validator, txInput)
+	stakeAddress, err := FindStakeAddress(from, seed)
+	if err != nil {
+		return nil, err
+	}
+	stakeAccount := solana.MustPublicKeyFromBase58(string(stakeAddress))
+	rent := txInput.RentExemption
+	if rent == 0 {
+		rent = StakeAccountRent
+	}
+
+	instructions := []solana.Instruction{
+		system.NewCreateAccountWithSeedInstruction(
+			accountFrom,
+			seed,
+			amount.Uint64()+rent,
+			StakeAccountSize,
+			solana.StakeProgramID,
+			accountFrom,
+			stakeAccount,
+			accountFrom,
+		).Build(),
+		newStakeInitializeInstruction(stakeAccount, accountFrom),
+		solana.NewInstruction(
+			solana.StakeProgramID,
+			solana.AccountMetaSlice{
+				solana.Meta(stakeAccount).WRITE(),
+				solana.Meta(voteAccount),
+				solana.Meta(solana.SysVarClockPubkey),
+				solana.Meta(solana.SysVarStakeHistoryPubkey),
+				solana.Meta(StakeConfigID),
+				solana.Meta(accountFrom).SIGNER(),
+			},
+			stakeInstructionData(stakeInstructionDelegateStake),
+		),
+	}
+	return txBuilder.buildSolanaTx(instructions, accountFrom, txInput)
+}
+
+// NewUndelegate deactivates the stake account of from delegating to validator, derived as in NewDelegate
+// Stake accounts are deactivated whole: amount is ignored, split the stake account beforehand to deactivate part of it
+func (txBuilder TxBuilder) NewUndelegate(from xc.Address, validator xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	txInput := input.(*TxInput)
+	accountFrom, err := solana.PublicKeyFromBase58(string(from))
+	if err != nil {
+		return nil, err
+	}
+	stakeAddress, err := FindStakeAddress(from, stakeSeed(validator, txInput))
+	if err != nil {
+		return nil, err
+	}
+	instructions := []solana.Instruction{
+		solana.NewInstruction(
+			solana.StakeProgramID,
+			solana.AccountMetaSlice{
+				solana.Meta(solana.MustPublicKeyFromBase58(string(stakeAddress))).WRITE(),
+				solana.Meta(solana.SysVarClockPubkey),
+				solana.Meta(accountFrom).SIGNER(),
+			},
+			stakeInstructionData(stakeInstructionDeactivate),
+		),
+	}
+	return txBuilder.buildSolanaTx(instructions, accountFrom, txInput)
+}
+
+// NewWithdrawRewards isn't supported: Solana staking rewards are added to the stake accounts every epoch
+func (txBuilder TxBuilder) NewWithdrawRewards(from xc.Address, validator xc.Address, input xc.TxInput) (xc.Tx, error) {
+	return nil, errors.New("solana staking rewards are compounded in stake accounts and can't be withdrawn separately")
+}
+
+func stakeInstructionData(instruction uint32) []byte {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, instruction)
+	return data
+}
+
+// newStakeInitializeInstruction initializes a stake account with authority as its staker and withdrawer, without lockup
+func newStakeInitializeInstruction(stakeAccount solana.PublicKey, authority solana.PublicKey) solana.Instruction {
+	// instruction, staker, withdrawer, then the lockup: unix timestamp, epoch and custodian
+	data := stakeInstructionData(stakeInstructionInitialize)
+	data = append(data, authority.Bytes()...)
+	data = append(data, authority.Bytes()...)
+	data = append(data, make([]byte, 8+8+32)...)
+	return solana.NewInstruction(
+		solana.StakeProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(stakeAccount).WRITE(),
+			solana.Meta(solana.SysVarRentPubkey),
+		},
+		data,
+	)
+}
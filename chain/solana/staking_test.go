@@ -0,0 +1,86 @@
+package solana
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/gagliardetto/solana-go"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestStakeSeed() {
+	require := s.Require()
+	require.Equal("stake:CertusDeBmqN8ZawdkxK5kFGMw", StakeSeed("CertusDeBmqN8ZawdkxK5kFGMwBXTVRYGUVpmcxjNkBD"))
+	require.Equal("stake:abc", StakeSeed("abc"))
+}
+
+func (s *CrosschainTestSuite) TestNewDelegate() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.SOL})
+	stakingBuilder := builder.(xc.TxStakingBuilder)
+	from := xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	validator := xc.Address("CertusDeBmqN8ZawdkxK5kFGMwBXTVRYGUVpmcxjNkBD")
+	amount := xc.NewAmountBlockchainFromUint64(1_000_000_000)
+
+	tx, err := stakingBuilder.NewDelegate(from, validator, amount, &TxInput{})
+	require.Nil(err)
+	solTx := tx.(*Tx).SolTx
+	require.Len(solTx.Message.Instructions, 3)
+	stakeAddress, _ := FindStakeAddress(from, StakeSeed(validator))
+	stakeAccount := solana.MustPublicKeyFromBase58(string(stakeAddress))
+
+	create := solTx.Message.Instructions[0]
+	require.Equal(solana.SystemProgramID, solTx.Message.AccountKeys[create.ProgramIDIndex])
+	require.Equal(stakeAccount, solTx.Message.AccountKeys[create.Accounts[1]])
+	// lamports, after the instruction, the base and the seed
+	seedEnd := 4 + 32 + 8 + len(StakeSeed(validator))
+	require.Equal(uint64(1_000_000_000+StakeAccountRent), binary.LittleEndian.Uint64(create.Data[seedEnd:]))
+	require.Equal(uint64(StakeAccountSize), binary.LittleEndian.Uint64(create.Data[seedEnd+8:]))
+
+	initialize := solTx.Message.Instructions[1]
+	require.Equal(solana.StakeProgramID, solTx.Message.AccountKeys[initialize.ProgramIDIndex])
+	require.Len(initialize.Data, 116)
+	require.Equal("00000000", hex.EncodeToString(initialize.Data[:4]))
+	require.Equal(solana.MustPublicKeyFromBase58(string(from)).Bytes(), []byte(initialize.Data[4:36]))
+	require.Equal(solana.MustPublicKeyFromBase58(string(from)).Bytes(), []byte(initialize.Data[36:68]))
+
+	delegate := solTx.Message.Instructions[2]
+	require.Equal("02000000", hex.EncodeToString(delegate.Data))
+	require.Len(delegate.Accounts, 6)
+	require.Equal(stakeAccount, solTx.Message.AccountKeys[delegate.Accounts[0]])
+	require.Equal(solana.MustPublicKeyFromBase58(string(validator)), solTx.Message.AccountKeys[delegate.Accounts[1]])
+	require.Equal(StakeConfigID, solTx.Message.AccountKeys[delegate.Accounts[4]])
+
+	// another stake account
+	tx, err = stakingBuilder.NewDelegate(from, validator, amount, &TxInput{StakeSeed: "stake:2", RentExemption: 1000})
+	require.Nil(err)
+	solTx = tx.(*Tx).SolTx
+	otherAddress, _ := FindStakeAddress(from, "stake:2")
+	require.NotEqual(stakeAddress, otherAddress)
+	require.Equal(solana.MustPublicKeyFromBase58(string(otherAddress)), solTx.Message.AccountKeys[solTx.Message.Instructions[2].Accounts[0]])
+
+	_, err = stakingBuilder.NewDelegate(from, validator, xc.NewAmountBlockchainFromUint64(0), &TxInput{})
+	require.EqualError(err, "amount must be positive")
+	_, err = stakingBuilder.NewDelegate(from, "vote", amount, &TxInput{})
+	require.ErrorContains(err, "invalid length")
+}
+
+func (s *CrosschainTestSuite) TestNewUndelegate() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.AssetConfig{NativeAsset: xc.SOL})
+	stakingBuilder := builder.(xc.TxStakingBuilder)
+	from := xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	validator := xc.Address("CertusDeBmqN8ZawdkxK5kFGMwBXTVRYGUVpmcxjNkBD")
+
+	tx, err := stakingBuilder.NewUndelegate(from, validator, xc.NewAmountBlockchainFromUint64(0), &TxInput{})
+	require.Nil(err)
+	solTx := tx.(*Tx).SolTx
+	require.Len(solTx.Message.Instructions, 1)
+	deactivate := solTx.Message.Instructions[0]
+	require.Equal("05000000", hex.EncodeToString(deactivate.Data))
+	stakeAddress, _ := FindStakeAddress(from, StakeSeed(validator))
+	require.Equal(solana.MustPublicKeyFromBase58(string(stakeAddress)), solTx.Message.AccountKeys[deactivate.Accounts[0]])
+
+	_, err = stakingBuilder.NewWithdrawRewards(from, validator, &TxInput{})
+	require.ErrorContains(err, "compounded in stake accounts")
+}
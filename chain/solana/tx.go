@@ -3,9 +3,11 @@ package solana
 import (
 	"errors"
 	"fmt"
+	"io"
 
 	xc "github.com/jumpcrypto/crosschain"
 
+	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/programs/system"
 	"github.com/gagliardetto/solana-go/programs/token"
@@ -22,6 +24,7 @@ type Tx struct {
 }
 
 var _ xc.Tx = Tx{}
+var _ xc.TxStreamSerializer = Tx{}
 
 // Hash returns the tx hash or id, for Solana it's signature
 func (tx Tx) Hash() xc.TxHash {
@@ -67,6 +70,11 @@ func (tx *Tx) ParseTransfer() {
 		tx.parsedTransfer = transfer
 		return
 	}
+	transferWithSeed, _ := tx.getSystemTransferWithSeed()
+	if transferWithSeed != nil {
+		tx.parsedTransfer = transferWithSeed
+		return
+	}
 	tokenTC, _ := tx.getTokenTransferChecked()
 	if tokenTC != nil {
 		tx.parsedTransfer = tokenTC
@@ -95,6 +103,9 @@ func (tx Tx) From() xc.Address {
 	case *system.Transfer:
 		from := tf.GetFundingAccount().PublicKey.String()
 		return xc.Address(from)
+	case *system.TransferWithSeed:
+		from := tf.GetFundingAccount().PublicKey.String()
+		return xc.Address(from)
 	case *token.TransferChecked:
 		from := tf.GetOwnerAccount().PublicKey.String()
 		return xc.Address(from)
@@ -120,6 +131,9 @@ func (tx Tx) To() xc.Address {
 	case *system.Transfer:
 		to := tf.GetRecipientAccount().PublicKey.String()
 		return xc.Address(to)
+	case *system.TransferWithSeed:
+		to := tf.GetRecipientAccount().PublicKey.String()
+		return xc.Address(to)
 	case *vote.Withdraw:
 		// https://docs.rs/solana-vote-program/latest/solana_vote_program/vote_instruction/enum.VoteInstruction.html#variant.Withdraw
 		to := tf.GetAccounts()[1].PublicKey.String()
@@ -149,6 +163,8 @@ func (tx Tx) Amount() xc.AmountBlockchain {
 	switch tf := tx.parsedTransfer.(type) {
 	case *system.Transfer:
 		return xc.NewAmountBlockchainFromUint64(*tf.Lamports)
+	case *system.TransferWithSeed:
+		return xc.NewAmountBlockchainFromUint64(*tf.Lamports)
 	case *token.TransferChecked:
 		return xc.NewAmountBlockchainFromUint64(*tf.Amount)
 	case *token.Transfer:
@@ -245,6 +261,36 @@ func (tx Tx) getSystemTransfer() (*system.Transfer, error) {
 	return nil, fmt.Errorf("no tx set")
 }
 
+func (tx Tx) getSystemTransferWithSeed() (*system.TransferWithSeed, error) {
+	if tx.SolTx != nil {
+		message := tx.SolTx.Message
+		for _, instruction := range message.Instructions {
+			program, err := message.ResolveProgramIDIndex(instruction.ProgramIDIndex)
+			if err != nil {
+				continue
+			}
+			if !program.Equals(solana.SystemProgramID) {
+				continue
+			}
+			accs, err := instruction.ResolveInstructionAccounts(&message)
+			if err != nil {
+				continue
+			}
+			inst, err := system.DecodeInstruction(accs, instruction.Data)
+			if err != nil {
+				continue
+			}
+			castedInst, ok := inst.Impl.(*system.TransferWithSeed)
+			if !ok {
+				continue
+			}
+			return castedInst, nil
+		}
+		return nil, fmt.Errorf("no instruction is *system.TransferWithSeed")
+	}
+	return nil, fmt.Errorf("no tx set")
+}
+
 func (tx Tx) getTokenTransferChecked() (*token.TransferChecked, error) {
 	if tx.SolTx != nil {
 		message := tx.SolTx.Message
@@ -312,3 +358,26 @@ func (tx Tx) Serialize() ([]byte, error) {
 	}
 	return tx.SolTx.MarshalBinary()
 }
+
+// SerializeTo writes the serialized tx, as Serialize, into w
+func (tx Tx) SerializeTo(w io.Writer) error {
+	if tx.SolTx == nil {
+		return errors.New("transaction not initialized")
+	}
+	message, err := tx.SolTx.Message.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode tx.Message to binary: %w", err)
+	}
+	signatureCount := make([]byte, 0, 3)
+	bin.EncodeCompactU16Length(&signatureCount, len(tx.SolTx.Signatures))
+	if _, err := w.Write(signatureCount); err != nil {
+		return err
+	}
+	for _, signature := range tx.SolTx.Signatures {
+		if _, err := w.Write(signature[:]); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(message)
+	return err
+}
@@ -1,6 +1,8 @@
 package solana
 
 import (
+	"bytes"
+
 	"encoding/hex"
 
 	bin "github.com/gagliardetto/binary"
@@ -198,3 +200,23 @@ func (s *CrosschainTestSuite) TestTxSerialize() {
 	require.Nil(err)
 	require.Equal(serialized, []byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0})
 }
+
+func (s *CrosschainTestSuite) TestTxSerializeTo() {
+	require := s.Require()
+	buf := &bytes.Buffer{}
+
+	tx := Tx{}
+	err := tx.SerializeTo(buf)
+	require.EqualError(err, "transaction not initialized")
+
+	builder, _, _, _ := newBenchmarkTransfer()
+	from := xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	to := xc.Address("BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+	built, err := builder.NewNativeTransfer(from, to, xc.NewAmountBlockchainFromUint64(1), &TxInput{})
+	require.NoError(err)
+	require.NoError(built.AddSignatures(xc.TxSignature(make([]byte, 64))))
+	serialized, err := built.Serialize()
+	require.NoError(err)
+	require.NoError(xc.SerializeTo(buf, built))
+	require.Equal(serialized, buf.Bytes())
+}
@@ -0,0 +1,119 @@
+package starknet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// contractAddressPrefix is the felt of "STARKNET_CONTRACT_ADDRESS", hashed with the deployment of contracts
+var contractAddressPrefix = new(big.Int).SetBytes([]byte("STARKNET_CONTRACT_ADDRESS"))
+
+// maxContractAddress bounds the addresses of contracts: 2^251 - 256
+var maxContractAddress = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 251), big.NewInt(256))
+
+// AddressBuilder for StarkNet
+type AddressBuilder struct {
+	AccountClassHash *big.Int
+}
+
+var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
+
+// NewAddressBuilder creates a new StarkNet AddressBuilder, with the account class of the chain
+func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
+	classHash, err := ParseFelt(asset.GetNativeAsset().GetStarknet().AccountClassHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account_class_hash: %v", err)
+	}
+	return AddressBuilder{
+		AccountClassHash: classHash,
+	}, nil
+}
+
+// GetAddressFromPublicKey returns the address of the account of a public key, counterfactual until the account is
+// deployed: deployed by the account itself, with the public key as salt and as only constructor argument
+func (ab AddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (xc.Address, error) {
+	if len(publicKeyBytes) != 32 {
+		return xc.Address(""), errors.New("invalid length for stark public key")
+	}
+	publicKey := new(big.Int).SetBytes(publicKeyBytes)
+	if _, err := pointFromX(publicKey); err != nil {
+		return xc.Address(""), fmt.Errorf("invalid stark public key: %v", err)
+	}
+	return FormatAddress(ContractAddress(big.NewInt(0), publicKey, ab.AccountClassHash, publicKey)), nil
+}
+
+// GetAllPossibleAddressesFromPublicKey returns all PossubleAddress(es) given a public key
+func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]xc.PossibleAddress, error) {
+	address, err := ab.GetAddressFromPublicKey(publicKeyBytes)
+	return []xc.PossibleAddress{
+		{
+			Address: address,
+			Type:    xc.AddressTypeDefault,
+		},
+	}, err
+}
+
+// ValidateAddress checks an address is the hex felt of a contract address
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	if _, err := ParseAddress(address); err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	return nil
+}
+
+// ContractAddress returns the address of a contract deployed by deployer (0 for accounts deploying themselves)
+func ContractAddress(deployer *big.Int, salt *big.Int, classHash *big.Int, constructorCalldata ...*big.Int) *big.Int {
+	address := pedersenHashMany(contractAddressPrefix, deployer, salt, classHash, pedersenHashMany(constructorCalldata...))
+	return address.Mod(address, maxContractAddress)
+}
+
+// ParseFelt parses a hex felt, with or without 0x
+func ParseFelt(str string) (*big.Int, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(str), "0x"), "0X")
+	if digits == "" || len(digits) > 64 {
+		return nil, errors.New("not a hex felt")
+	}
+	felt, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return nil, errors.New("not a hex felt")
+	}
+	if felt.Cmp(fieldPrime) >= 0 {
+		return nil, errors.New("felt exceeds the field prime")
+	}
+	return felt, nil
+}
+
+// ParseAddress parses the hex felt of a contract address
+func ParseAddress(address xc.Address) (*big.Int, error) {
+	felt, err := ParseFelt(string(address))
+	if err != nil {
+		return nil, err
+	}
+	if felt.Cmp(maxContractAddress) >= 0 {
+		return nil, errors.New("address exceeds 2^251 - 256")
+	}
+	return felt, nil
+}
+
+// FormatAddress returns the address of a felt, padded to 64 hex digits like explorers and wallets
+func FormatAddress(felt *big.Int) xc.Address {
+	return xc.Address(fmt.Sprintf("0x%064x", felt))
+}
+
+// encodeFelt returns the hex of a felt without leading zeros, as expected by the JSON-RPC API
+func encodeFelt(felt *big.Int) string {
+	return "0x" + felt.Text(16)
+}
+
+func encodeFelts(felts []*big.Int) []string {
+	res := make([]string, len(felts))
+	for i, felt := range felts {
+		res[i] = encodeFelt(felt)
+	}
+	return res
+}
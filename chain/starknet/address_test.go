@@ -0,0 +1,49 @@
+package starknet
+
+import (
+	"encoding/hex"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// testPrivateKey and its public key and OpenZeppelin account
+const (
+	testPrivateKey = "0x1234567890987654321"
+	testPublicKey  = "020c29f1c98f3320d56f01c13372c923123c35828bce54f2153aa1cfe61c44f2"
+	testAddress    = "0x0450a32950c3a8f372ad2d53abf0b327a563afcfd82791b28e8f99feaf160dd7"
+)
+
+func (s *CrosschainTestSuite) TestGetAddressFromPublicKey() {
+	require := s.Require()
+	builder, err := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.STRK})
+	require.NoError(err)
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	address, err := builder.GetAddressFromPublicKey(publicKey)
+	require.NoError(err)
+	require.Equal(xc.Address(testAddress), address)
+
+	// accounts of another class
+	builder, _ = NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.STRK, Starknet: xc.StarknetConfig{AccountClassHash: "0x1"}})
+	address, err = builder.GetAddressFromPublicKey(publicKey)
+	require.NoError(err)
+	require.NotEqual(xc.Address(testAddress), address)
+
+	_, err = builder.GetAddressFromPublicKey(publicKey[1:])
+	require.EqualError(err, "invalid length for stark public key")
+	_, err = NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.STRK, Starknet: xc.StarknetConfig{AccountClassHash: "class"}})
+	require.EqualError(err, "invalid account_class_hash: not a hex felt")
+}
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.STRK})
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress(testAddress))
+	// leading zeros are optional
+	require.NoError(validator.ValidateAddress("0x450a32950c3a8f372ad2d53abf0b327a563afcfd82791b28e8f99feaf160dd7"))
+	require.NoError(validator.ValidateAddress(STRKContract))
+
+	require.EqualError(validator.ValidateAddress("0xzz"), "invalid address '0xzz': not a hex felt")
+	require.EqualError(validator.ValidateAddress("0x0800000000000011000000000000000000000000000000000000000000000001"), "invalid address '0x0800000000000011000000000000000000000000000000000000000000000001': felt exceeds the field prime")
+	require.EqualError(validator.ValidateAddress("0x07ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"), "invalid address '0x07ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff': address exceeds 2^251 - 256")
+}
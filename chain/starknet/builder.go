@@ -0,0 +1,112 @@
+package starknet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Fee tokens of StarkNet, the same contracts on mainnet and Sepolia
+const (
+	STRKContract = "0x04718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d"
+	ETHContract  = "0x049d36570d4e46f48e99674bd3fcc84644ddf6b96f7c741b1562b82f9e004dc7"
+)
+
+var (
+	transferSelector = Selector("transfer")
+	u128Mask         = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+)
+
+// TxBuilder for StarkNet
+type TxBuilder struct {
+	Asset xc.ITask
+}
+
+var _ xc.TxBuilder = &TxBuilder{}
+var _ xc.TxTokenBuilder = &TxBuilder{}
+
+// NewTxBuilder creates a new StarkNet TxBuilder
+func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
+	return &TxBuilder{
+		Asset: asset,
+	}, nil
+}
+
+// NewTransfer creates a new transfer for an Asset, either native or token
+func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		return txBuilder.NewTokenTransfer(from, to, amount, input)
+	}
+	return txBuilder.NewNativeTransfer(from, to, amount, input)
+}
+
+// NewNativeTransfer creates a new transfer of the native asset: the ERC-20 contract of chain_coin, STRK by default
+func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	return txBuilder.newTransfer(from, to, amount, NativeContract(txBuilder.Asset.GetNativeAsset()), input)
+}
+
+// NewTokenTransfer creates a new transfer of a token, an ERC-20 contract like the fee tokens
+func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	contract := txBuilder.Asset.GetAssetConfig().Contract
+	if token, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		contract = token.Contract
+	}
+	return txBuilder.newTransfer(from, to, amount, xc.ContractAddress(contract), input)
+}
+
+// newTransfer creates a tx of the account of from, calling transfer(to, amount) on contract
+func (txBuilder TxBuilder) newTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, contract xc.ContractAddress, input xc.TxInput) (xc.Tx, error) {
+	var localInput TxInput
+	switch typed := input.(type) {
+	case TxInput:
+		localInput = typed
+	case *TxInput:
+		localInput = *typed
+	default:
+		return &Tx{}, errors.New("xc.TxInput is not from a starknet chain")
+	}
+	sender, err := ParseAddress(from)
+	if err != nil {
+		return &Tx{}, fmt.Errorf("invalid from address '%s': %v", from, err)
+	}
+	recipient, err := ParseAddress(to)
+	if err != nil {
+		return &Tx{}, fmt.Errorf("invalid to address '%s': %v", to, err)
+	}
+	contractAddress, err := ParseAddress(xc.Address(contract))
+	if err != nil {
+		return &Tx{}, fmt.Errorf("invalid contract address '%s': %v", contract, err)
+	}
+	value := amount.Int()
+	if value.Sign() < 0 || value.BitLen() > 256 {
+		return &Tx{}, errors.New("invalid amount: not a u256")
+	}
+	// a single call of __execute__ of the account: to, selector and calldata, the amount is a u256 (low, high)
+	calldata := []*big.Int{
+		big.NewInt(1),
+		contractAddress,
+		transferSelector,
+		big.NewInt(3),
+		recipient,
+		new(big.Int).And(value, u128Mask),
+		new(big.Int).Rsh(value, 128),
+	}
+	return &Tx{
+		Input:         localInput,
+		SenderAddress: sender,
+		Calldata:      calldata,
+	}, nil
+}
+
+// NativeContract returns the ERC-20 contract of the native asset of a chain, chain_coin or STRK
+func NativeContract(asset *xc.NativeAssetConfig) xc.ContractAddress {
+	if asset.ChainCoin != "" {
+		return xc.ContractAddress(asset.ChainCoin)
+	}
+	return STRKContract
+}
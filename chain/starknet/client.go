@@ -0,0 +1,403 @@
+package starknet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Max amounts of the resources of transfers, unused resources aren't charged
+// L1 gas is only used by messages to L1 since StarkNet v0.13.4, txs pay for their state diffs with L1 data gas
+const (
+	l1GasMaxAmount     = 0
+	l2GasMaxAmount     = 5_000_000
+	l1DataGasMaxAmount = 1_000
+)
+
+// pendingBlock is the block nonces and fees are estimated at, including the txs not yet in a block
+const pendingBlock = "pending"
+
+var (
+	transferEventKey  = Selector("Transfer")
+	balanceOfSelector = Selector("balanceOf")
+)
+
+// Client for StarkNet
+type Client struct {
+	Asset           xc.ITask
+	RpcClient       *rpc.Client
+	EstimateGasFunc xc.EstimateGasFunc
+}
+
+var _ xc.FullClientWithGas = &Client{}
+var _ xc.FeeEstimator = &Client{}
+
+type rpcResourcePrice struct {
+	PriceInFri string `json:"price_in_fri"`
+	PriceInWei string `json:"price_in_wei"`
+}
+
+type rpcBlock struct {
+	BlockNumber    uint64           `json:"block_number"`
+	Timestamp      int64            `json:"timestamp"`
+	L1GasPrice     rpcResourcePrice `json:"l1_gas_price"`
+	L2GasPrice     rpcResourcePrice `json:"l2_gas_price"`
+	L1DataGasPrice rpcResourcePrice `json:"l1_data_gas_price"`
+}
+
+type rpcEvent struct {
+	FromAddress string   `json:"from_address"`
+	Keys        []string `json:"keys"`
+	Data        []string `json:"data"`
+}
+
+type rpcReceipt struct {
+	TransactionHash string `json:"transaction_hash"`
+	ActualFee       struct {
+		Amount string `json:"amount"`
+		Unit   string `json:"unit"`
+	} `json:"actual_fee"`
+	ExecutionStatus string     `json:"execution_status"`
+	FinalityStatus  string     `json:"finality_status"`
+	RevertReason    string     `json:"revert_reason"`
+	BlockHash       string     `json:"block_hash"`
+	BlockNumber     *uint64    `json:"block_number"`
+	Events          []rpcEvent `json:"events"`
+}
+
+type rpcTransaction struct {
+	Type          string `json:"type"`
+	SenderAddress string `json:"sender_address"`
+}
+
+type rpcFeeEstimate struct {
+	OverallFee string `json:"overall_fee"`
+	Unit       string `json:"unit"`
+}
+
+// NewClient returns a new StarkNet Client
+func NewClient(cfgI xc.ITask) (*Client, error) {
+	cfg := cfgI.GetNativeAsset()
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	client, err := rpc.DialHTTPWithClient(cfg.URL, &http.Client{Transport: transport})
+	if err != nil {
+		return nil, fmt.Errorf("dialing url: %v", cfg.URL)
+	}
+	return &Client{
+		Asset:     cfgI,
+		RpcClient: client,
+	}, nil
+}
+
+// FetchTxInput returns tx input for a StarkNet tx: the chain id, the nonce of the account of the sender and the
+// bounds of the resources of a transfer, at the prices of the latest block with a margin
+func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, _ xc.Address) (xc.TxInput, error) {
+	input := NewTxInput()
+	if err := client.RpcClient.CallContext(ctx, &input.ChainID, "starknet_chainId"); err != nil {
+		return input, fmt.Errorf("fetching chain id: %v", err)
+	}
+	sender, err := ParseAddress(from)
+	if err != nil {
+		return input, fmt.Errorf("invalid from address '%s': %v", from, err)
+	}
+	var nonce string
+	if err := client.RpcClient.CallContext(ctx, &nonce, "starknet_getNonce", pendingBlock, encodeFelt(sender)); err != nil {
+		return input, fmt.Errorf("fetching nonce: %v", err)
+	}
+	nonceFelt, err := ParseFelt(nonce)
+	if err != nil || !nonceFelt.IsUint64() {
+		return input, fmt.Errorf("invalid nonce '%s'", nonce)
+	}
+	input.Nonce = nonceFelt.Uint64()
+
+	block, err := client.fetchBlock(ctx, "latest")
+	if err != nil {
+		return input, err
+	}
+	l1GasPrice, err := ParseFelt(block.L1GasPrice.PriceInFri)
+	if err != nil {
+		return input, fmt.Errorf("invalid l1 gas price: %v", err)
+	}
+	l1DataGasPrice, err := ParseFelt(block.L1DataGasPrice.PriceInFri)
+	if err != nil {
+		return input, fmt.Errorf("invalid l1 data gas price: %v", err)
+	}
+	l2GasPrice, err := client.EstimateGas(ctx)
+	if err != nil {
+		return input, err
+	}
+	input.L1Gas = ResourceBounds{MaxAmount: l1GasMaxAmount, MaxPricePerUnit: client.withMargin(l1GasPrice)}
+	input.L2Gas = ResourceBounds{MaxAmount: l2GasMaxAmount, MaxPricePerUnit: l2GasPrice}
+	input.L1DataGas = ResourceBounds{MaxAmount: l1DataGasMaxAmount, MaxPricePerUnit: client.withMargin(l1DataGasPrice)}
+	return input, nil
+}
+
+// SubmitTx submits a StarkNet tx
+func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		return xc.RecordDryRun(ctx, client.Asset, tx, false)
+	}
+	var res struct {
+		TransactionHash string `json:"transaction_hash"`
+	}
+	return client.RpcClient.CallContext(ctx, &res, "starknet_addInvokeTransaction", json.RawMessage(serialized))
+}
+
+// EstimateFee estimates the fee of a StarkNet tx in fri, simulated without the validation of its signature
+func (client *Client) EstimateFee(ctx context.Context, from xc.Address, tx xc.Tx) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	starknetTx, ok := tx.(*Tx)
+	if !ok {
+		return zero, errors.New("xc.Tx is not from a starknet chain")
+	}
+	var estimates []rpcFeeEstimate
+	request := []invokeTransaction{starknetTx.invokeTransaction()}
+	if err := client.RpcClient.CallContext(ctx, &estimates, "starknet_estimateFee", request, []string{"SKIP_VALIDATE"}, pendingBlock); err != nil {
+		return zero, fmt.Errorf("estimating fee of transaction '%v': %v", tx.Hash(), err)
+	}
+	if len(estimates) != 1 {
+		return zero, fmt.Errorf("estimating fee: expected 1 estimate, got %d", len(estimates))
+	}
+	fee, err := ParseFelt(estimates[0].OverallFee)
+	if err != nil {
+		return zero, fmt.Errorf("invalid fee estimate: %v", err)
+	}
+	return xc.AmountBlockchain(*fee), nil
+}
+
+// FetchTxInfo returns tx info for a StarkNet tx, with a source and a destination per transfer of an ERC-20 contract,
+// the fee token included, but the transfer of its fee
+func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
+	var receipt *rpcReceipt
+	if err := client.RpcClient.CallContext(ctx, &receipt, "starknet_getTransactionReceipt", string(txHash)); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching receipt of '%s': %v", txHash, err)
+	}
+	if receipt == nil {
+		return xc.TxInfo{}, fmt.Errorf("tx not found: %s", txHash)
+	}
+	var tx rpcTransaction
+	if err := client.RpcClient.CallContext(ctx, &tx, "starknet_getTransactionByHash", string(txHash)); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching tx '%s': %v", txHash, err)
+	}
+
+	info := xc.TxInfo{
+		BlockHash:   receipt.BlockHash,
+		TxID:        receipt.TransactionHash,
+		ExplorerURL: fmt.Sprintf("/tx/%s", receipt.TransactionHash),
+		Fee:         xc.NewAmountBlockchainFromUint64(0),
+		Amount:      xc.NewAmountBlockchainFromUint64(0),
+	}
+	if tx.SenderAddress != "" {
+		if sender, err := ParseFelt(tx.SenderAddress); err == nil {
+			info.From = FormatAddress(sender)
+		}
+	}
+	if receipt.ExecutionStatus == "REVERTED" {
+		info.Status = xc.TxStatusFailure
+		info.Error = receipt.RevertReason
+	}
+	if fee, err := ParseFelt(receipt.ActualFee.Amount); err == nil {
+		info.Fee = xc.AmountBlockchain(*fee)
+	}
+	if receipt.BlockNumber != nil {
+		var head uint64
+		if err := client.RpcClient.CallContext(ctx, &head, "starknet_blockNumber"); err != nil {
+			return info, fmt.Errorf("fetching block number: %v", err)
+		}
+		block, err := client.fetchBlock(ctx, map[string]uint64{"block_number": *receipt.BlockNumber})
+		if err != nil {
+			return info, err
+		}
+		info.BlockIndex = int64(*receipt.BlockNumber)
+		info.BlockTime = block.Timestamp
+		info.Confirmations = int64(head) - int64(*receipt.BlockNumber)
+	}
+
+	nativeAsset := client.Asset.GetNativeAsset()
+	nativeContract := xc.NormalizeContractAddress(xc.DriverStarknet, string(NativeContract(nativeAsset)))
+	feeContract := xc.NormalizeContractAddress(xc.DriverStarknet, STRKContract)
+	if receipt.ActualFee.Unit == "WEI" {
+		feeContract = xc.NormalizeContractAddress(xc.DriverStarknet, ETHContract)
+	}
+	transfers := parseTransferEvents(receipt.Events)
+	// the fee is transferred to the sequencer after the execution of the tx
+	for i := len(transfers) - 1; i >= 0; i-- {
+		transfer := transfers[i]
+		if transfer.contract == feeContract && transfer.from == info.From && transfer.amount.Cmp(info.Fee.Int()) == 0 {
+			transfers = append(transfers[:i], transfers[i+1:]...)
+			break
+		}
+	}
+	for _, transfer := range transfers {
+		contract := xc.ContractAddress(transfer.contract)
+		if transfer.contract == nativeContract {
+			contract = ""
+		}
+		amount := xc.AmountBlockchain(*transfer.amount)
+		info.Sources = append(info.Sources, &xc.TxInfoEndpoint{Address: transfer.from, ContractAddress: contract, Amount: amount, NativeAsset: nativeAsset.NativeAsset})
+		info.Destinations = append(info.Destinations, &xc.TxInfoEndpoint{Address: transfer.to, ContractAddress: contract, Amount: amount, NativeAsset: nativeAsset.NativeAsset})
+		if info.To == "" && transfer.from == info.From {
+			info.To = transfer.to
+			info.Amount = amount
+			info.ContractAddress = contract
+		}
+	}
+	return info, nil
+}
+
+// FetchBalance fetches the balance of an asset for a StarkNet address
+func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	if token, ok := client.Asset.(*xc.TokenAssetConfig); ok {
+		return client.fetchBalanceOf(ctx, xc.ContractAddress(token.Contract), address)
+	}
+	return client.FetchNativeBalance(ctx, address)
+}
+
+// FetchNativeBalance fetches the balance of the native asset, the fee token of chain_coin, for a StarkNet address
+func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	return client.fetchBalanceOf(ctx, NativeContract(client.Asset.GetNativeAsset()), address)
+}
+
+// fetchBalanceOf calls balanceOf of an ERC-20 contract, returning a u256 (low, high)
+func (client *Client) fetchBalanceOf(ctx context.Context, contract xc.ContractAddress, address xc.Address) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	owner, err := ParseAddress(address)
+	if err != nil {
+		return zero, fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	contractAddress, err := ParseAddress(xc.Address(contract))
+	if err != nil {
+		return zero, fmt.Errorf("invalid contract address '%s': %v", contract, err)
+	}
+	call := map[string]interface{}{
+		"contract_address":     encodeFelt(contractAddress),
+		"entry_point_selector": encodeFelt(balanceOfSelector),
+		"calldata":             []string{encodeFelt(owner)},
+	}
+	var res []string
+	if err := client.RpcClient.CallContext(ctx, &res, "starknet_call", call, "latest"); err != nil {
+		return zero, err
+	}
+	balance, ok := parseU256(res)
+	if !ok {
+		return zero, fmt.Errorf("invalid balance of '%s': %v", address, res)
+	}
+	return xc.AmountBlockchain(*balance), nil
+}
+
+func (client *Client) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc) {
+	client.EstimateGasFunc = estimateGas
+}
+
+// EstimateGas returns the max price of L2 gas of txs in fri: the price of the latest block with a margin
+// (chain_gas_multiplier, 2 by default) if not estimated by the callback
+func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	if client.EstimateGasFunc != nil {
+		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+		if res, err := client.EstimateGasFunc(nativeAsset); err == nil {
+			return res, nil
+		}
+		// continue with default implementation as fallback
+	}
+	block, err := client.fetchBlock(ctx, "latest")
+	if err != nil {
+		return zero, err
+	}
+	price, err := ParseFelt(block.L2GasPrice.PriceInFri)
+	if err != nil {
+		return zero, fmt.Errorf("invalid l2 gas price: %v", err)
+	}
+	return client.withMargin(price), nil
+}
+
+// withMargin returns a max price per unit of a resource from its price, multiplied by chain_gas_multiplier
+func (client *Client) withMargin(price *big.Int) xc.AmountBlockchain {
+	multiplier := 2.0
+	if client.Asset.GetNativeAsset().ChainGasMultiplier > 0 {
+		multiplier = client.Asset.GetNativeAsset().ChainGasMultiplier
+	}
+	res, _ := new(big.Float).Mul(new(big.Float).SetInt(price), big.NewFloat(multiplier)).Int(nil)
+	return xc.AmountBlockchain(*res)
+}
+
+func (client *Client) fetchBlock(ctx context.Context, blockID interface{}) (rpcBlock, error) {
+	var block *rpcBlock
+	if err := client.RpcClient.CallContext(ctx, &block, "starknet_getBlockWithTxHashes", blockID); err != nil {
+		return rpcBlock{}, fmt.Errorf("fetching block %v: %v", blockID, err)
+	}
+	if block == nil {
+		return rpcBlock{}, fmt.Errorf("block not found: %v", blockID)
+	}
+	return *block, nil
+}
+
+type transferEvent struct {
+	contract string
+	from     xc.Address
+	to       xc.Address
+	amount   *big.Int
+}
+
+// parseTransferEvents returns the transfers of ERC-20 contracts, whose Transfer event has its from and to either as
+// data (Cairo 0 contracts, e.g. ETH) or as keys (Cairo 1 contracts)
+func parseTransferEvents(events []rpcEvent) []transferEvent {
+	transfers := []transferEvent{}
+	for _, event := range events {
+		if len(event.Keys) == 0 {
+			continue
+		}
+		if key, err := ParseFelt(event.Keys[0]); err != nil || key.Cmp(transferEventKey) != 0 {
+			continue
+		}
+		fields := append(append([]string{}, event.Keys[1:]...), event.Data...)
+		if len(fields) != 4 {
+			continue
+		}
+		from, errFrom := ParseFelt(fields[0])
+		to, errTo := ParseFelt(fields[1])
+		amount, ok := parseU256(fields[2:])
+		if errFrom != nil || errTo != nil || !ok {
+			continue
+		}
+		transfers = append(transfers, transferEvent{
+			contract: xc.NormalizeContractAddress(xc.DriverStarknet, event.FromAddress),
+			from:     FormatAddress(from),
+			to:       FormatAddress(to),
+			amount:   amount,
+		})
+	}
+	return transfers
+}
+
+// parseU256 parses a u256 of Cairo: its low and high 128 bits
+func parseU256(felts []string) (*big.Int, bool) {
+	if len(felts) != 2 {
+		return nil, false
+	}
+	low, err := ParseFelt(felts[0])
+	if err != nil || low.BitLen() > 128 {
+		return nil, false
+	}
+	high, err := ParseFelt(felts[1])
+	if err != nil || high.BitLen() > 128 {
+		return nil, false
+	}
+	return new(big.Int).Or(new(big.Int).Lsh(high, 128), low), true
+}
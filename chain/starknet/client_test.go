@@ -0,0 +1,169 @@
+package starknet
+
+import (
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const testBlock = `{"block_number":1000,"timestamp":1730000000,"l1_gas_price":{"price_in_fri":"0x7530","price_in_wei":"0x1"},"l2_gas_price":{"price_in_fri":"0x165a0bc00","price_in_wei":"0x1"},"l1_data_gas_price":{"price_in_fri":"0x3e8","price_in_wei":"0x1"}}`
+
+func (s *CrosschainTestSuite) TestFetchTxInput() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`"0x534e5f5345504f4c4941"`,
+		`"0x7"`,
+		testBlock,
+		testBlock,
+	})
+	defer close()
+
+	client, err := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.STRK, URL: server.URL})
+	require.NoError(err)
+	input, err := client.FetchTxInput(s.Ctx, testAddress, "")
+	require.NoError(err)
+	txInput := input.(*TxInput)
+	require.Equal(xc.DriverStarknet, txInput.Type)
+	require.Equal("0x534e5f5345504f4c4941", txInput.ChainID)
+	require.EqualValues(7, txInput.Nonce)
+	// twice the prices of the latest block
+	require.Equal(ResourceBounds{MaxAmount: l1GasMaxAmount, MaxPricePerUnit: xc.NewAmountBlockchainFromUint64(60_000)}, txInput.L1Gas)
+	require.Equal(ResourceBounds{MaxAmount: l2GasMaxAmount, MaxPricePerUnit: xc.NewAmountBlockchainFromUint64(12_000_000_000)}, txInput.L2Gas)
+	require.Equal(ResourceBounds{MaxAmount: l1DataGasMaxAmount, MaxPricePerUnit: xc.NewAmountBlockchainFromUint64(2_000)}, txInput.L1DataGas)
+
+	server.Response = fmt.Errorf("connection refused")
+	_, err = client.FetchTxInput(s.Ctx, testAddress, "")
+	require.ErrorContains(err, "fetching chain id")
+}
+
+func (s *CrosschainTestSuite) TestEstimateGas() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, testBlock)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.STRK, URL: server.URL, ChainGasMultiplier: 1.5})
+	price, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("9000000000", price.String())
+
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(5), nil
+	})
+	price, err = client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("5", price.String())
+	require.Equal(1, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestSubmitTx() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `{"transaction_hash":"0x1"}`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.STRK, URL: server.URL})
+
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.STRK})
+	tx, _ := builder.NewTransfer(testAddress, "0x1234", xc.NewAmountBlockchainFromUint64(1), newTestInput())
+	require.Error(client.SubmitTx(s.Ctx, tx))
+	require.Equal(0, server.Counter)
+
+	require.NoError(tx.AddSignatures(make([]byte, 64)))
+	require.NoError(client.SubmitTx(s.Ctx, tx))
+	require.Equal(1, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestEstimateFee() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `[{"l1_gas_consumed":"0x0","l2_gas_consumed":"0xf4240","l1_data_gas_consumed":"0x80","overall_fee":"0x2ba7def3000","unit":"FRI"}]`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.STRK, URL: server.URL})
+
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.STRK})
+	tx, _ := builder.NewTransfer(testAddress, "0x1234", xc.NewAmountBlockchainFromUint64(1), newTestInput())
+	fee, err := client.EstimateFee(s.Ctx, testAddress, tx)
+	require.NoError(err)
+	require.Equal("3000000000000", fee.String())
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfo() {
+	require := s.Require()
+	sender := "0x450a32950c3a8f372ad2d53abf0b327a563afcfd82791b28e8f99feaf160dd7"
+	transferKey := "0x99cd8bde557814842a3121e8ddfd433a539b8c9f14bf31ebf108d12e6196e9"
+	receipt := `{
+		"type":"INVOKE",
+		"transaction_hash":"0x7cff19f75ed15bf935acce754a8bb010f81d491721f38a58bc0909c37355a50",
+		"actual_fee":{"amount":"0x2ba7def3000","unit":"FRI"},
+		"execution_status":"SUCCEEDED",
+		"finality_status":"ACCEPTED_ON_L2",
+		"block_hash":"0xabc",
+		"block_number":990,
+		"events":[
+			{"from_address":"` + ETHContract + `","keys":["` + transferKey + `"],"data":["` + sender + `","0x1234","0x64","0x0"]},
+			{"from_address":"` + STRKContract + `","keys":["` + transferKey + `","` + sender + `","0x1235"],"data":["0xde0b6b3a7640000","0x0"]},
+			{"from_address":"` + STRKContract + `","keys":["` + transferKey + `","` + sender + `","0x1"],"data":["0x2ba7def3000","0x0"]}
+		]
+	}`
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		receipt,
+		`{"type":"INVOKE","version":"0x3","sender_address":"` + sender + `"}`,
+		`1000`,
+		testBlock,
+	})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.STRK, URL: server.URL})
+
+	info, err := client.FetchTxInfo(s.Ctx, "0x7cff19f75ed15bf935acce754a8bb010f81d491721f38a58bc0909c37355a50")
+	require.NoError(err)
+	require.Equal(xc.TxStatusSuccess, info.Status)
+	require.Equal(xc.Address(testAddress), info.From)
+	require.Equal("3000000000000", info.Fee.String())
+	require.EqualValues(990, info.BlockIndex)
+	require.EqualValues(1730000000, info.BlockTime)
+	require.EqualValues(10, info.Confirmations)
+	// the first transfer of the sender, the fee transfer excluded
+	require.Equal(xc.Address("0x0000000000000000000000000000000000000000000000000000000000001234"), info.To)
+	require.Equal(xc.ContractAddress("0x49d36570d4e46f48e99674bd3fcc84644ddf6b96f7c741b1562b82f9e004dc7"), info.ContractAddress)
+	require.Equal("100", info.Amount.String())
+	require.Len(info.Destinations, 2)
+	require.Equal(xc.Address("0x0000000000000000000000000000000000000000000000000000000000001235"), info.Destinations[1].Address)
+	require.Equal(xc.ContractAddress(""), info.Destinations[1].ContractAddress)
+	require.Equal("1000000000000000000", info.Destinations[1].Amount.String())
+	require.Equal(xc.STRK, info.Destinations[1].NativeAsset)
+	require.Equal(xc.Address(testAddress), info.Sources[1].Address)
+
+	// reverted
+	server.Counter = 0
+	server.Response = []string{
+		`{"transaction_hash":"0x1","actual_fee":{"amount":"0x10","unit":"FRI"},"execution_status":"REVERTED","revert_reason":"u256_sub Overflow","finality_status":"ACCEPTED_ON_L2","events":[]}`,
+		`{"type":"INVOKE","sender_address":"` + sender + `"}`,
+	}
+	info, err = client.FetchTxInfo(s.Ctx, "0x1")
+	require.NoError(err)
+	require.Equal(xc.TxStatusFailure, info.Status)
+	require.Equal("u256_sub Overflow", info.Error)
+	require.Equal("16", info.Fee.String())
+	require.EqualValues(0, info.Confirmations)
+}
+
+func (s *CrosschainTestSuite) TestFetchBalance() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `["0xde0b6b3a7640000","0x1"]`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.STRK, URL: server.URL})
+	balance, err := client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	// 2^128 + 10^18
+	require.Equal("340282366920938463464374607431768211456", balance.String())
+
+	server.Response = `["0x1"]`
+	_, err = client.FetchNativeBalance(s.Ctx, testAddress)
+	require.ErrorContains(err, "invalid balance")
+}
+
+func (s *CrosschainTestSuite) TestCheckError() {
+	require := s.Require()
+	require.Equal(xc.NoBalanceForGas, CheckError(fmt.Errorf("Account balance is smaller than the transaction's maximal fee")))
+	require.Equal(xc.NoBalance, CheckError(fmt.Errorf("Transaction execution error: u256_sub Overflow")))
+	require.Equal(xc.TransactionExists, CheckError(fmt.Errorf("A transaction with the same hash already exists in the mempool")))
+	require.Equal(xc.TransactionFailure, CheckError(fmt.Errorf("Invalid transaction nonce")))
+	require.Equal(xc.UnknownError, CheckError(fmt.Errorf("unknown")))
+}
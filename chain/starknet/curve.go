@@ -0,0 +1,203 @@
+package starknet
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// The STARK curve: y^2 = x^3 + x + beta over the field of felts
+var (
+	// fieldPrime is the prime of the field of felts: 2^251 + 17 * 2^192 + 1
+	fieldPrime = new(big.Int).Add(new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 251), new(big.Int).Lsh(big.NewInt(17), 192)), big.NewInt(1))
+	curveBeta  = mustParseHex("06f21413efbe40de150e596d72f7a8c5609ad26c15c915c1f4cdfcb99cee9e89")
+	curveOrder = mustParseHex("0800000000000010ffffffffffffffffb781126dcae7b2321e66a241adc64d2f")
+	generator  = point{
+		x: mustParseHex("01ef15c18599971b7beced415a40f0c7deacfd9b0d1819e03d723d8bc943cfca"),
+		y: mustParseHex("005668060aa49730b7be4801df46ec62de53ecd11abe43a32873000c36e8dc1f"),
+	}
+	// maxECDSAValue bounds the hashes and the r and w of signatures: 2^251
+	maxECDSAValue = new(big.Int).Lsh(big.NewInt(1), 251)
+)
+
+// point is an affine point of the STARK curve, the point at infinity if x is nil
+type point struct {
+	x, y *big.Int
+}
+
+func (p point) isInfinity() bool {
+	return p.x == nil
+}
+
+func (p point) neg() point {
+	if p.isInfinity() {
+		return p
+	}
+	return point{p.x, new(big.Int).Sub(fieldPrime, p.y)}
+}
+
+func (p point) add(q point) point {
+	if p.isInfinity() {
+		return q
+	}
+	if q.isInfinity() {
+		return p
+	}
+	var slope *big.Int
+	if p.x.Cmp(q.x) == 0 {
+		if new(big.Int).Add(p.y, q.y).Cmp(fieldPrime) == 0 || p.y.Sign() == 0 {
+			return point{}
+		}
+		// (3x^2 + 1) / 2y
+		numerator := new(big.Int).Mul(p.x, p.x)
+		numerator.Mul(numerator, big.NewInt(3)).Add(numerator, big.NewInt(1))
+		slope = numerator.Mul(numerator, new(big.Int).ModInverse(new(big.Int).Lsh(p.y, 1), fieldPrime))
+	} else {
+		numerator := new(big.Int).Sub(q.y, p.y)
+		denominator := new(big.Int).Sub(q.x, p.x)
+		denominator.Mod(denominator, fieldPrime)
+		slope = numerator.Mul(numerator, denominator.ModInverse(denominator, fieldPrime))
+	}
+	slope.Mod(slope, fieldPrime)
+	x := new(big.Int).Mul(slope, slope)
+	x.Sub(x, p.x).Sub(x, q.x).Mod(x, fieldPrime)
+	y := new(big.Int).Sub(p.x, x)
+	y.Mul(y, slope).Sub(y, p.y).Mod(y, fieldPrime)
+	return point{x, y}
+}
+
+func (p point) mul(scalar *big.Int) point {
+	res := point{}
+	for i := scalar.BitLen() - 1; i >= 0; i-- {
+		res = res.add(res)
+		if scalar.Bit(i) == 1 {
+			res = res.add(p)
+		}
+	}
+	return res
+}
+
+// pointFromX returns a point of the curve with the x coordinate, the public keys of StarkNet
+func pointFromX(x *big.Int) (point, error) {
+	if x.Sign() <= 0 || x.Cmp(fieldPrime) >= 0 {
+		return point{}, errors.New("invalid x coordinate")
+	}
+	ySquared := new(big.Int).Exp(x, big.NewInt(3), fieldPrime)
+	ySquared.Add(ySquared, x).Add(ySquared, curveBeta).Mod(ySquared, fieldPrime)
+	y := new(big.Int).ModSqrt(ySquared, fieldPrime)
+	if y == nil {
+		return point{}, errors.New("x coordinate isn't on the curve")
+	}
+	return point{new(big.Int).Set(x), y}, nil
+}
+
+// sign signs a hash with a private key, r is the x coordinate of k * G and s = (hash + r * privateKey) / k
+// k is deterministic (RFC 6979 with HMAC-SHA256), and skipped while r or 1 / s exceed 2^251
+func sign(privateKey *big.Int, hash *big.Int) (*big.Int, *big.Int, error) {
+	if privateKey.Sign() <= 0 || privateKey.Cmp(curveOrder) >= 0 {
+		return nil, nil, errors.New("invalid private key")
+	}
+	if hash.Sign() < 0 || hash.Cmp(maxECDSAValue) >= 0 {
+		return nil, nil, errors.New("invalid hash to sign: more than 251 bits")
+	}
+	nonces := newNonceGenerator(privateKey, hash)
+	for {
+		k := nonces.next()
+		r := generator.mul(k).x
+		if r == nil || r.Sign() == 0 || r.Cmp(maxECDSAValue) >= 0 {
+			continue
+		}
+		s := new(big.Int).Mul(r, privateKey)
+		s.Add(s, hash).Mul(s, new(big.Int).ModInverse(k, curveOrder)).Mod(s, curveOrder)
+		if s.Sign() == 0 {
+			continue
+		}
+		w := new(big.Int).ModInverse(s, curveOrder)
+		if w.Cmp(maxECDSAValue) >= 0 {
+			continue
+		}
+		return r, s, nil
+	}
+}
+
+// verify verifies the signature of a hash by a public key, the x coordinate of its point
+func verify(publicKey *big.Int, hash *big.Int, r *big.Int, s *big.Int) bool {
+	key, err := pointFromX(publicKey)
+	if err != nil {
+		return false
+	}
+	if r.Sign() <= 0 || r.Cmp(maxECDSAValue) >= 0 || s.Sign() <= 0 || s.Cmp(curveOrder) >= 0 {
+		return false
+	}
+	w := new(big.Int).ModInverse(s, curveOrder)
+	if w == nil || w.Cmp(maxECDSAValue) >= 0 {
+		return false
+	}
+	u1 := new(big.Int).Mul(hash, w)
+	u1.Mod(u1, curveOrder)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, curveOrder)
+	// the y coordinate of the public key is unknown: either point is accepted
+	for _, q := range []point{key, key.neg()} {
+		res := generator.mul(u1).add(q.mul(u2))
+		if !res.isInfinity() && res.x.Cmp(r) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// nonceGenerator generates the nonces of a signature, as in section 3.2 of RFC 6979
+type nonceGenerator struct {
+	k, v []byte
+}
+
+func newNonceGenerator(privateKey *big.Int, hash *big.Int) *nonceGenerator {
+	generator := &nonceGenerator{
+		k: make([]byte, sha256.Size),
+		v: make([]byte, sha256.Size),
+	}
+	for i := range generator.v {
+		generator.v[i] = 0x01
+	}
+	seed := append(int2octets(privateKey), int2octets(new(big.Int).Mod(hash, curveOrder))...)
+	generator.k = generator.mac(generator.k, generator.v, []byte{0x00}, seed)
+	generator.v = generator.mac(generator.k, generator.v)
+	generator.k = generator.mac(generator.k, generator.v, []byte{0x01}, seed)
+	generator.v = generator.mac(generator.k, generator.v)
+	return generator
+}
+
+func (generator *nonceGenerator) next() *big.Int {
+	for {
+		generator.v = generator.mac(generator.k, generator.v)
+		// bits2int: the 252 high bits of the 256 bits of V
+		k := new(big.Int).Rsh(new(big.Int).SetBytes(generator.v), 256-uint(curveOrder.BitLen()))
+		generator.k = generator.mac(generator.k, generator.v, []byte{0x00})
+		generator.v = generator.mac(generator.k, generator.v)
+		if k.Sign() > 0 && k.Cmp(curveOrder) < 0 {
+			return k
+		}
+	}
+}
+
+func (generator *nonceGenerator) mac(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+func int2octets(value *big.Int) []byte {
+	return value.FillBytes(make([]byte, 32))
+}
+
+func mustParseHex(str string) *big.Int {
+	value, ok := new(big.Int).SetString(str, 16)
+	if !ok {
+		panic("invalid hex: " + str)
+	}
+	return value
+}
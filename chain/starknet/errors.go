@@ -0,0 +1,34 @@
+package starknet
+
+import (
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// CheckError classifies the errors of the JSON-RPC API of StarkNet nodes
+func CheckError(err error) xc.ClientError {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "account balance is smaller than the transaction's max") ||
+		strings.Contains(msg, "max fee is smaller than the minimal transaction cost") {
+		return xc.NoBalanceForGas
+	}
+	if strings.Contains(msg, "u256_sub overflow") ||
+		strings.Contains(msg, "transfer amount exceeds balance") {
+		return xc.NoBalance
+	}
+	if strings.Contains(msg, "transaction with the same hash already exists") ||
+		strings.Contains(msg, "duplicate_tx") {
+		return xc.TransactionExists
+	}
+	if strings.Contains(msg, "invalid transaction nonce") ||
+		strings.Contains(msg, "account validation failed") ||
+		strings.Contains(msg, "transaction execution error") {
+		return xc.TransactionFailure
+	}
+	if strings.Contains(msg, "response body closed") ||
+		strings.Contains(msg, "eof") {
+		return xc.NetworkError
+	}
+	return xc.UnknownError
+}
@@ -0,0 +1,136 @@
+package starknet
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// pedersenPoints are the shift point and the points of the low 248 and high 4 bits of both inputs of the Pedersen
+// hash, derived from the digits of pi
+var pedersenPoints = [5]point{
+	{
+		x: mustParseHex("049ee3eba8c1600700ee1b87eb599f16716b0b1022947733551fde4050ca6804"),
+		y: mustParseHex("03ca0cfe4b3bc6ddf346d49d06ea0ed34e621062c0e056c1d0405d266e10268a"),
+	},
+	{
+		x: mustParseHex("0234287dcbaffe7f969c748655fca9e58fa8120b6d56eb0c1080d17957ebe47b"),
+		y: mustParseHex("03b056f100f96fb21e889527d41f4e39940135dd7a6c94cc6ed0268ee89e5615"),
+	},
+	{
+		x: mustParseHex("04fa56f376c83db33f9dab2656558f3399099ec1de5e3018b7a6932dba8aa378"),
+		y: mustParseHex("03fa0984c931c9e38113e0c0e47e4401562761f92a7a23b45168f4e80ff5b54d"),
+	},
+	{
+		x: mustParseHex("04ba4cc166be8dec764910f75b45f74b40c690c74709e90f3aa372f0bd2d6997"),
+		y: mustParseHex("0040301cf5c1751f4b971e46c4ede85fcac5c59a5ce5ae7c48151f27b24b219c"),
+	},
+	{
+		x: mustParseHex("054302dcb0e6cc1c6e44cca8f61a63bb2ca65048d53fb325d36ff12c49a58202"),
+		y: mustParseHex("01b77b3e37d13504b348046268d8ae25ce98ad783c25561a879dcc77e99c2426"),
+	},
+}
+
+var lowBitsMask = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 248), big.NewInt(1))
+
+// pedersenHash returns the Pedersen hash of two felts
+func pedersenHash(a *big.Int, b *big.Int) *big.Int {
+	res := pedersenPoints[0]
+	for i, value := range []*big.Int{a, b} {
+		low := new(big.Int).And(value, lowBitsMask)
+		high := new(big.Int).Rsh(value, 248)
+		res = res.add(pedersenPoints[1+2*i].mul(low))
+		res = res.add(pedersenPoints[2+2*i].mul(high))
+	}
+	return res.x
+}
+
+// pedersenHashMany returns the Pedersen hash of an array of felts: the chained hash of its elements, then of its
+// length
+func pedersenHashMany(values ...*big.Int) *big.Int {
+	res := big.NewInt(0)
+	for _, value := range values {
+		res = pedersenHash(res, value)
+	}
+	return pedersenHash(res, big.NewInt(int64(len(values))))
+}
+
+// Parameters of the Hades permutation of the Poseidon hash of StarkNet, with a state of 3 felts
+const (
+	poseidonFullRounds    = 8
+	poseidonPartialRounds = 83
+)
+
+// poseidonRoundConstants are the constants added to the state at each round, generated like the reference
+// implementation: sha256("Hades" + index) modulo the field prime
+var poseidonRoundConstants = func() [][3]*big.Int {
+	constants := make([][3]*big.Int, poseidonFullRounds+poseidonPartialRounds)
+	for round := range constants {
+		for i := 0; i < 3; i++ {
+			hash := sha256.Sum256([]byte(fmt.Sprintf("Hades%d", 3*round+i)))
+			constants[round][i] = new(big.Int).Mod(new(big.Int).SetBytes(hash[:]), fieldPrime)
+		}
+	}
+	return constants
+}()
+
+// hadesPermutation permutes a state of 3 felts: the first and last half of the full rounds cube the whole state,
+// the partial rounds in between only its last felt
+func hadesPermutation(state [3]*big.Int) [3]*big.Int {
+	var res [3]*big.Int
+	for i := range state {
+		res[i] = new(big.Int).Set(state[i])
+	}
+	for round, constants := range poseidonRoundConstants {
+		for i := range res {
+			res[i].Add(res[i], constants[i])
+		}
+		full := round < poseidonFullRounds/2 || round >= poseidonFullRounds/2+poseidonPartialRounds
+		for i := range res {
+			if full || i == 2 {
+				res[i].Exp(res[i], big.NewInt(3), fieldPrime)
+			}
+		}
+		// MDS matrix [[3, 1, 1], [1, -1, 1], [1, 1, -2]]
+		sum := new(big.Int).Add(res[0], res[1])
+		sum.Add(sum, res[2])
+		res = [3]*big.Int{
+			new(big.Int).Add(sum, new(big.Int).Lsh(res[0], 1)),
+			new(big.Int).Sub(sum, new(big.Int).Lsh(res[1], 1)),
+			new(big.Int).Sub(sum, new(big.Int).Mul(res[2], big.NewInt(3))),
+		}
+		for i := range res {
+			res[i].Mod(res[i], fieldPrime)
+		}
+	}
+	return res
+}
+
+// poseidonHashMany returns the Poseidon hash of an array of felts: the sponge absorbing its elements by pairs,
+// padded with 1 and then 0 to an even length
+func poseidonHashMany(values ...*big.Int) *big.Int {
+	padded := append(append([]*big.Int{}, values...), big.NewInt(1))
+	if len(padded)%2 == 1 {
+		padded = append(padded, big.NewInt(0))
+	}
+	state := [3]*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(0)}
+	for i := 0; i < len(padded); i += 2 {
+		state[0] = new(big.Int).Add(state[0], padded[i])
+		state[1] = new(big.Int).Add(state[1], padded[i+1])
+		state = hadesPermutation(state)
+	}
+	return state[0]
+}
+
+// starknetKeccak returns the keccak256 of data truncated to 250 bits, e.g. the selectors of functions and events
+func starknetKeccak(data []byte) *big.Int {
+	hash := new(big.Int).SetBytes(crypto.Keccak256(data))
+	return hash.And(hash, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 250), big.NewInt(1)))
+}
+
+// Selector returns the selector of a function or of an event
+func Selector(name string) *big.Int {
+	return starknetKeccak([]byte(name))
+}
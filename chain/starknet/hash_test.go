@@ -0,0 +1,35 @@
+package starknet
+
+import (
+	"math/big"
+)
+
+func (s *CrosschainTestSuite) TestPedersenHash() {
+	require := s.Require()
+	// test vector of cairo-lang
+	hash := pedersenHash(
+		mustParseHex("03d937c035c878245caf64531a5756109c53068da139362728feb561405371cb"),
+		mustParseHex("0208a0a10250e382e1e4bbe2880906c2791bf6275695e02fbbc6aeff9cd8b31a"),
+	)
+	require.Equal("0x30e480bed5fe53fa909cc0f8c4d99b8f9f2c016be4c41e13a4848797979c662", encodeFelt(hash))
+}
+
+func (s *CrosschainTestSuite) TestPoseidonHash() {
+	require := s.Require()
+	require.Equal("0x6861759ea556a2339dd92f9562a30b9e58e2ad98109ae4780b7fd8eac77fe6f", encodeFelt(poseidonRoundConstants[0][0]))
+
+	state := hadesPermutation([3]*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(0)})
+	require.Equal("0x79e8d1e78258000a28fc9d49e233bc6852357968577b1e386550ed6a9086133", encodeFelt(state[0]))
+	require.Equal("0x3840d003d0f3f96dbb796ff6aa6a63be5b5404b91ccaabca256154cbb6fb984", encodeFelt(state[1]))
+	require.Equal("0x1eb39da3f7d3b04142d0ac83d9da00c9325a61fb2ef326e50b70eaa8a3c7cc7", encodeFelt(state[2]))
+
+	require.Equal("0x371cb6995ea5e7effcd2e174de264b5b407027a75a231a70c2c8d196107f0e7", encodeFelt(poseidonHashMany(big.NewInt(1), big.NewInt(2))))
+	// padded with 1 and 0
+	require.Equal("0x579e8877c7755365d5ec1ec7d3a94a457eff5d1f40482bbe9729c064cdead2", encodeFelt(poseidonHashMany(big.NewInt(1))))
+}
+
+func (s *CrosschainTestSuite) TestSelector() {
+	require := s.Require()
+	require.Equal("0x83afd3f4caedc6eebf44246fe54e38c95e3179a5ec9ea81740eca5b482d12e", encodeFelt(Selector("transfer")))
+	require.Equal("0x99cd8bde557814842a3121e8ddfd433a539b8c9f14bf31ebf108d12e6196e9", encodeFelt(Selector("Transfer")))
+}
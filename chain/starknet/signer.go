@@ -0,0 +1,57 @@
+package starknet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Signer for StarkNet, signing with stark keys
+type Signer struct {
+}
+
+var _ xc.Signer = &Signer{}
+var _ xc.PublicKeyDeriver = &Signer{}
+
+// NewSigner creates a new StarkNet Signer
+func NewSigner(asset xc.ITask) (xc.Signer, error) {
+	return Signer{}, nil
+}
+
+// ImportPrivateKey imports a StarkNet private key: the hex felt exported by wallets, e.g. Argent or Braavos
+func (signer Signer) ImportPrivateKey(privateKeyString string) (xc.PrivateKey, error) {
+	privateKey, err := ParseFelt(privateKeyString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	if privateKey.Sign() == 0 || privateKey.Cmp(curveOrder) >= 0 {
+		return nil, errors.New("invalid private key: out of the range of the curve order")
+	}
+	return xc.PrivateKey(int2octets(privateKey)), nil
+}
+
+// Sign a StarkNet tx hash, returning r || s
+func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	if len(privateKey) != 32 {
+		return nil, fmt.Errorf("invalid stark private key length %d", len(privateKey))
+	}
+	r, s, err := sign(new(big.Int).SetBytes(privateKey), new(big.Int).SetBytes(data))
+	if err != nil {
+		return nil, err
+	}
+	return xc.TxSignature(append(int2octets(r), int2octets(s)...)), nil
+}
+
+// DerivePublicKey returns the public key of a StarkNet private key: the x coordinate of its point
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	if len(privateKey) != 32 {
+		return nil, fmt.Errorf("invalid stark private key length %d", len(privateKey))
+	}
+	scalar := new(big.Int).SetBytes(privateKey)
+	if scalar.Sign() == 0 || scalar.Cmp(curveOrder) >= 0 {
+		return nil, errors.New("invalid stark private key")
+	}
+	return xc.PublicKey(int2octets(generator.mul(scalar).x)), nil
+}
@@ -0,0 +1,212 @@
+package starknet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// invokePrefix is the felt of "invoke", hashed with invoke txs
+var invokePrefix = new(big.Int).SetBytes([]byte("invoke"))
+
+// invokeVersion is the version of the invoke txs built, paying fees in STRK with resource bounds
+const invokeVersion = 3
+
+// Names of the resources bounded by txs, in the hash of their bounds
+var (
+	l1GasName     = new(big.Int).SetBytes([]byte("L1_GAS"))
+	l2GasName     = new(big.Int).SetBytes([]byte("L2_GAS"))
+	l1DataGasName = new(big.Int).SetBytes([]byte("L1_DATA"))
+)
+
+// ResourceBounds are the max amount of a resource a tx can use and the max price it pays per unit, in fri (the
+// smallest unit of STRK)
+type ResourceBounds struct {
+	MaxAmount       uint64
+	MaxPricePerUnit xc.AmountBlockchain
+}
+
+// hash returns the felt of the bounds of a resource: its name (60 bits), max amount (64 bits) and max price per
+// unit (128 bits)
+func (bounds ResourceBounds) hash(name *big.Int) *big.Int {
+	res := new(big.Int).Lsh(name, 192)
+	res.Or(res, new(big.Int).Lsh(new(big.Int).SetUint64(bounds.MaxAmount), 128))
+	return res.Or(res, bounds.MaxPricePerUnit.Int())
+}
+
+func (bounds ResourceBounds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"max_amount":         encodeFelt(new(big.Int).SetUint64(bounds.MaxAmount)),
+		"max_price_per_unit": encodeFelt(bounds.MaxPricePerUnit.Int()),
+	})
+}
+
+// MaxFee returns the max fee paid with the bounds, in fri
+func (bounds ResourceBounds) MaxFee() xc.AmountBlockchain {
+	amount := xc.NewAmountBlockchainFromUint64(bounds.MaxAmount)
+	return amount.Mul(&bounds.MaxPricePerUnit)
+}
+
+// TxInput for StarkNet
+type TxInput struct {
+	xc.TxInputEnvelope
+	// ChainID is the hex felt of the chain id, e.g. SN_MAIN
+	ChainID   string
+	Nonce     uint64
+	L1Gas     ResourceBounds
+	L2Gas     ResourceBounds
+	L1DataGas ResourceBounds
+	Tip       uint64
+}
+
+// NewTxInput returns a new StarkNet TxInput
+func NewTxInput() *TxInput {
+	return &TxInput{
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverStarknet),
+	}
+}
+
+var _ xc.TxInputNonce = &TxInput{}
+
+// GetNonce returns the nonce of the account contract
+func (txInput *TxInput) GetNonce() uint64 {
+	return txInput.Nonce
+}
+
+// MaxFee returns the max fee paid by the tx for all its resources, in fri
+func (input TxInput) MaxFee() xc.AmountBlockchain {
+	fee := input.L1Gas.MaxFee()
+	l2Fee := input.L2Gas.MaxFee()
+	l1DataFee := input.L1DataGas.MaxFee()
+	fee = fee.Add(&l2Fee)
+	return fee.Add(&l1DataFee)
+}
+
+// Tx for StarkNet: an invoke v3 tx of the account of the sender, executing the calls of its calldata
+type Tx struct {
+	Input         TxInput
+	SenderAddress *big.Int
+	Calldata      []*big.Int
+	// Signature is r and s
+	Signature []*big.Int
+}
+
+var _ xc.Tx = &Tx{}
+
+// Hash returns the tx hash: the Poseidon hash of its fields, the signature excluded
+func (tx Tx) Hash() xc.TxHash {
+	hash, err := tx.hash()
+	if err != nil {
+		return xc.TxHash("")
+	}
+	return xc.TxHash(encodeFelt(hash))
+}
+
+// Sighashes returns the tx hash to sign, validated by the account of the sender
+func (tx Tx) Sighashes() ([]xc.TxDataToSign, error) {
+	hash, err := tx.hash()
+	if err != nil {
+		return []xc.TxDataToSign{}, err
+	}
+	return []xc.TxDataToSign{int2octets(hash)}, nil
+}
+
+// AddSignatures adds the signature of the tx hash: r || s
+func (tx *Tx) AddSignatures(signatures ...xc.TxSignature) error {
+	if len(signatures) != 1 {
+		return errors.New("expecting 1 signature")
+	}
+	if len(signatures[0]) != 64 {
+		return fmt.Errorf("invalid signature length %d", len(signatures[0]))
+	}
+	tx.Signature = []*big.Int{
+		new(big.Int).SetBytes(signatures[0][:32]),
+		new(big.Int).SetBytes(signatures[0][32:]),
+	}
+	return nil
+}
+
+// invokeTransaction is the broadcasted invoke tx of the JSON-RPC API
+type invokeTransaction struct {
+	Type                      string                    `json:"type"`
+	Version                   string                    `json:"version"`
+	SenderAddress             string                    `json:"sender_address"`
+	Calldata                  []string                  `json:"calldata"`
+	Signature                 []string                  `json:"signature"`
+	Nonce                     string                    `json:"nonce"`
+	ResourceBounds            map[string]ResourceBounds `json:"resource_bounds"`
+	Tip                       string                    `json:"tip"`
+	PaymasterData             []string                  `json:"paymaster_data"`
+	AccountDeploymentData     []string                  `json:"account_deployment_data"`
+	NonceDataAvailabilityMode string                    `json:"nonce_data_availability_mode"`
+	FeeDataAvailabilityMode   string                    `json:"fee_data_availability_mode"`
+}
+
+// Serialize returns the JSON of the signed tx, as submitted to the JSON-RPC API
+func (tx Tx) Serialize() ([]byte, error) {
+	if len(tx.Signature) == 0 {
+		return []byte{}, errors.New("unable to serialize without first calling AddSignatures(...)")
+	}
+	return json.Marshal(tx.invokeTransaction())
+}
+
+func (tx Tx) invokeTransaction() invokeTransaction {
+	signature := tx.Signature
+	if signature == nil {
+		signature = []*big.Int{}
+	}
+	return invokeTransaction{
+		Type:          "INVOKE",
+		Version:       encodeFelt(big.NewInt(invokeVersion)),
+		SenderAddress: encodeFelt(tx.SenderAddress),
+		Calldata:      encodeFelts(tx.Calldata),
+		Signature:     encodeFelts(signature),
+		Nonce:         encodeFelt(new(big.Int).SetUint64(tx.Input.Nonce)),
+		ResourceBounds: map[string]ResourceBounds{
+			"l1_gas":      tx.Input.L1Gas,
+			"l2_gas":      tx.Input.L2Gas,
+			"l1_data_gas": tx.Input.L1DataGas,
+		},
+		Tip:                       encodeFelt(new(big.Int).SetUint64(tx.Input.Tip)),
+		PaymasterData:             []string{},
+		AccountDeploymentData:     []string{},
+		NonceDataAvailabilityMode: "L1",
+		FeeDataAvailabilityMode:   "L1",
+	}
+}
+
+// hash returns the hash of an invoke v3 tx without paymaster nor account deployment, its nonce and fee paid on L1
+func (tx Tx) hash() (*big.Int, error) {
+	if tx.SenderAddress == nil {
+		return nil, errors.New("transaction not initialized")
+	}
+	chainID, err := ParseFelt(tx.Input.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain id '%s': %v", tx.Input.ChainID, err)
+	}
+	feeHash := poseidonHashMany(
+		new(big.Int).SetUint64(tx.Input.Tip),
+		tx.Input.L1Gas.hash(l1GasName),
+		tx.Input.L2Gas.hash(l2GasName),
+		tx.Input.L1DataGas.hash(l1DataGasName),
+	)
+	// the data availability modes of the nonce and of the fee (L1 = 0), in 32 bits each
+	dataAvailabilityModes := big.NewInt(0)
+	return poseidonHashMany(
+		invokePrefix,
+		big.NewInt(invokeVersion),
+		tx.SenderAddress,
+		feeHash,
+		// paymaster data
+		poseidonHashMany(),
+		chainID,
+		new(big.Int).SetUint64(tx.Input.Nonce),
+		dataAvailabilityModes,
+		// account deployment data
+		poseidonHashMany(),
+		poseidonHashMany(tx.Calldata...),
+	), nil
+}
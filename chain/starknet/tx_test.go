@@ -0,0 +1,95 @@
+package starknet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func newTestInput() *TxInput {
+	input := NewTxInput()
+	input.ChainID = "0x534e5f5345504f4c4941"
+	input.Nonce = 7
+	input.L1Gas = ResourceBounds{MaxAmount: 0, MaxPricePerUnit: xc.NewAmountBlockchainFromUint64(30_000)}
+	input.L2Gas = ResourceBounds{MaxAmount: 5_000_000, MaxPricePerUnit: xc.NewAmountBlockchainFromUint64(12_000_000_000)}
+	input.L1DataGas = ResourceBounds{MaxAmount: 1_000, MaxPricePerUnit: xc.NewAmountBlockchainFromUint64(2_000)}
+	return input
+}
+
+func (s *CrosschainTestSuite) TestNewTransfer() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.STRK})
+	tx, err := builder.NewTransfer(testAddress, "0x1234", xc.NewAmountBlockchainFromUint64(1_000_000_000_000_000_000), newTestInput())
+	require.NoError(err)
+	starknetTx := tx.(*Tx)
+	require.Equal([]string{"0x1", "0x4718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d", "0x83afd3f4caedc6eebf44246fe54e38c95e3179a5ec9ea81740eca5b482d12e", "0x3", "0x1234", "0xde0b6b3a7640000", "0x0"}, encodeFelts(starknetTx.Calldata))
+	require.Equal(xc.TxHash("0x7cff19f75ed15bf935acce754a8bb010f81d491721f38a58bc0909c37355a50"), tx.Hash())
+
+	// tokens, with a u256 amount
+	builder, _ = NewTxBuilder(&xc.TokenAssetConfig{Contract: ETHContract, NativeAssetConfig: &xc.NativeAssetConfig{NativeAsset: xc.STRK}})
+	amount := xc.AmountBlockchain(*new(big.Int).Lsh(big.NewInt(3), 128))
+	tx, err = builder.NewTransfer(testAddress, "0x1234", amount, newTestInput())
+	require.NoError(err)
+	require.Equal([]string{"0x1", "0x49d36570d4e46f48e99674bd3fcc84644ddf6b96f7c741b1562b82f9e004dc7", "0x83afd3f4caedc6eebf44246fe54e38c95e3179a5ec9ea81740eca5b482d12e", "0x3", "0x1234", "0x0", "0x3"}, encodeFelts(tx.(*Tx).Calldata))
+
+	_, err = builder.NewTransfer("0xzz", "0x1234", amount, newTestInput())
+	require.EqualError(err, "invalid from address '0xzz': not a hex felt")
+	_, err = builder.NewTransfer(testAddress, "0x1234", amount, &xc.TxInputEnvelope{})
+	require.EqualError(err, "xc.TxInput is not from a starknet chain")
+}
+
+func (s *CrosschainTestSuite) TestSignTx() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.STRK})
+	tx, _ := builder.NewTransfer(testAddress, "0x1234", xc.NewAmountBlockchainFromUint64(1), newTestInput())
+	_, err := tx.Serialize()
+	require.EqualError(err, "unable to serialize without first calling AddSignatures(...)")
+
+	signer, _ := NewSigner(&xc.NativeAssetConfig{NativeAsset: xc.STRK})
+	privateKey, err := signer.ImportPrivateKey(testPrivateKey)
+	require.NoError(err)
+	publicKey, err := signer.(xc.PublicKeyDeriver).DerivePublicKey(privateKey)
+	require.NoError(err)
+	require.Equal(testPublicKey, hex.EncodeToString(publicKey))
+
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes, 1)
+	signature, err := signer.Sign(privateKey, sighashes[0])
+	require.NoError(err)
+	require.Len(signature, 64)
+	// deterministic
+	again, _ := signer.Sign(privateKey, sighashes[0])
+	require.Equal(signature, again)
+	r, sig := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	hash := new(big.Int).SetBytes(sighashes[0])
+	require.True(verify(new(big.Int).SetBytes(publicKey), hash, r, sig))
+	require.False(verify(new(big.Int).SetBytes(publicKey), new(big.Int).Add(hash, big.NewInt(1)), r, sig))
+
+	require.NoError(tx.AddSignatures(signature))
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	var decoded map[string]interface{}
+	require.NoError(json.Unmarshal(serialized, &decoded))
+	require.Equal("INVOKE", decoded["type"])
+	require.Equal("0x3", decoded["version"])
+	require.Equal("0x450a32950c3a8f372ad2d53abf0b327a563afcfd82791b28e8f99feaf160dd7", decoded["sender_address"])
+	require.Equal("0x7", decoded["nonce"])
+	require.Equal([]interface{}{encodeFelt(r), encodeFelt(sig)}, decoded["signature"])
+	require.Equal(map[string]interface{}{"max_amount": "0x4c4b40", "max_price_per_unit": "0x2cb417800"}, decoded["resource_bounds"].(map[string]interface{})["l2_gas"])
+	require.Equal("L1", decoded["fee_data_availability_mode"])
+	// the signature isn't hashed
+	require.Equal(xc.TxHash(encodeFelt(hash)), tx.Hash())
+
+	require.EqualError(tx.AddSignatures(signature[:63]), "invalid signature length 63")
+	_, err = signer.ImportPrivateKey("0x0")
+	require.EqualError(err, "invalid private key: out of the range of the curve order")
+}
+
+func (s *CrosschainTestSuite) TestMaxFee() {
+	require := s.Require()
+	// 5M L2 gas at 12 gwei fri and 1000 L1 data gas at 2000 fri
+	require.Equal("60000000002000000", newTestInput().MaxFee().String())
+}
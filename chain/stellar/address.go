@@ -0,0 +1,109 @@
+package stellar
+
+import (
+	"crypto/ed25519"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Version bytes of StrKeys, giving their first character: G for accounts, S for secret seeds
+const (
+	versionAccountID = 6 << 3
+	versionSeed      = 18 << 3
+)
+
+var strKeyEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// AddressBuilder for Stellar
+type AddressBuilder struct {
+}
+
+var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
+
+// NewAddressBuilder creates a new Stellar AddressBuilder
+func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
+	return AddressBuilder{}, nil
+}
+
+// GetAddressFromPublicKey returns the G... account id of an ed25519 public key
+func (ab AddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (xc.Address, error) {
+	if len(publicKeyBytes) != ed25519.PublicKeySize {
+		return xc.Address(""), fmt.Errorf("invalid ed25519 public key length %d", len(publicKeyBytes))
+	}
+	return xc.Address(encodeStrKey(versionAccountID, publicKeyBytes)), nil
+}
+
+// GetAllPossibleAddressesFromPublicKey returns all PossubleAddress(es) given a public key
+func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]xc.PossibleAddress, error) {
+	address, err := ab.GetAddressFromPublicKey(publicKeyBytes)
+	return []xc.PossibleAddress{
+		{
+			Address: address,
+			Type:    xc.AddressTypeDefault,
+		},
+	}, err
+}
+
+// ValidateAddress checks an address is a G... account id
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	if _, err := DecodeAccountID(address); err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	return nil
+}
+
+// DecodeAccountID returns the ed25519 public key of a G... account id
+func DecodeAccountID(address xc.Address) ([]byte, error) {
+	return decodeStrKey(versionAccountID, string(address))
+}
+
+// encodeStrKey returns the base32 of the version byte, the data and the CRC16-XModem of both, little endian
+func encodeStrKey(version byte, data []byte) string {
+	payload := append([]byte{version}, data...)
+	checksum := make([]byte, 2)
+	binary.LittleEndian.PutUint16(checksum, crc16XModem(payload))
+	return strKeyEncoding.EncodeToString(append(payload, checksum...))
+}
+
+// decodeStrKey returns the 32 bytes of a StrKey of a version
+func decodeStrKey(version byte, str string) ([]byte, error) {
+	decoded, err := strKeyEncoding.DecodeString(str)
+	if err != nil {
+		return nil, errors.New("not base32")
+	}
+	if len(decoded) != 1+32+2 {
+		return nil, fmt.Errorf("invalid length %d", len(decoded))
+	}
+	if decoded[0] != version {
+		return nil, fmt.Errorf("invalid version byte %d", decoded[0])
+	}
+	payload, checksum := decoded[:33], decoded[33:]
+	if binary.LittleEndian.Uint16(checksum) != crc16XModem(payload) {
+		return nil, errors.New("invalid checksum")
+	}
+	// the encoding of the last bits is canonical
+	if encodeStrKey(version, payload[1:]) != str {
+		return nil, errors.New("invalid encoding")
+	}
+	return payload[1:], nil
+}
+
+func crc16XModem(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,56 @@
+package stellar
+
+import (
+	"encoding/hex"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// the keys of the tests 1 and 2 of RFC 8032
+const testSeed = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+const testSecretSeed = "SCOWDMM5576VUYF2QRFPJEXMFTCEISOFNF5TE2IZOA52YAY4VZ7WBQNO"
+const testPublicKey = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+const testAddress = xc.Address("GDLVVGABQKYQVN6VJP7NHSLEA45A5YLS6PNKMIZFV4BBU2HXA5IRVHUR")
+const testRecipientPublicKey = "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c"
+const testRecipient = xc.Address("GA6UAF6D5BBYSWUSW4FKOTI3P26JZGBMZ4XMJFUMYDGVL4JK6RTAZGXX")
+
+func (s *CrosschainTestSuite) TestGetAddressFromPublicKey() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.XLM})
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	address, err := builder.GetAddressFromPublicKey(publicKey)
+	require.NoError(err)
+	require.Equal(testAddress, address)
+
+	addresses, err := builder.GetAllPossibleAddressesFromPublicKey(publicKey)
+	require.NoError(err)
+	require.Len(addresses, 1)
+	require.Equal(testAddress, addresses[0].Address)
+
+	// the account of the zero key
+	address, _ = builder.GetAddressFromPublicKey(make([]byte, 32))
+	require.Equal(xc.Address("GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF"), address)
+
+	_, err = builder.GetAddressFromPublicKey(publicKey[1:])
+	require.EqualError(err, "invalid ed25519 public key length 31")
+}
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.XLM})
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress(testAddress))
+	require.NoError(validator.ValidateAddress(testRecipient))
+
+	vectors := map[xc.Address]string{
+		"GDLVVGABQKYQVN6VJP7NHSLEA45A5YLS6PNKMIZFV4BBU2HXA5IRVHUS": "invalid checksum",
+		"GDLVVGABQKYQVN6VJP7NHSLEA45A5YLS6PNKMIZFV4BBU2HXA5IRVHU":  "invalid length 34",
+		"gdlvvgabqkyqvn6vjp7nhslea45a5yls6pnkmizfv4bbu2hxa5irvhur": "not base32",
+		testSecretSeed: "invalid version byte 144",
+		"GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA":         "invalid length 30",
+		"0xd75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a": "not base32",
+	}
+	for address, msg := range vectors {
+		require.EqualError(validator.ValidateAddress(address), "invalid address '"+string(address)+"': "+msg)
+	}
+}
@@ -0,0 +1,132 @@
+package stellar
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// assetCodePattern are the codes of issued assets: 1 to 12 alphanumerics
+var assetCodePattern = regexp.MustCompile(`^[A-Za-z0-9]{1,12}$`)
+
+// TxBuilder for Stellar
+type TxBuilder struct {
+	Asset xc.ITask
+}
+
+var _ xc.TxBuilder = &TxBuilder{}
+var _ xc.TxTokenBuilder = &TxBuilder{}
+
+// NewTxBuilder creates a new Stellar TxBuilder
+func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
+	return &TxBuilder{
+		Asset: asset,
+	}, nil
+}
+
+// NewTransfer creates a new transfer for an Asset, either native or token
+func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		return txBuilder.NewTokenTransfer(from, to, amount, input)
+	}
+	return txBuilder.NewNativeTransfer(from, to, amount, input)
+}
+
+// NewNativeTransfer creates a new tx with a payment of XLM, or creating the recipient with a starting balance if it
+// doesn't exist yet
+func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	localInput, source, destination, err := parseTransfer(from, to, amount, input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	op := Operation{Type: opPayment, Destination: destination, Amount: amount.Int().Int64()}
+	if localInput.CreateAccount {
+		op.Type = opCreateAccount
+	}
+	return txBuilder.newTx(localInput, source, op), nil
+}
+
+// NewTokenTransfer creates a new tx with a payment of an issued asset, whose contract is CODE:ISSUER
+// The recipient must trust the asset
+func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	localInput, source, destination, err := parseTransfer(from, to, amount, input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	contract := txBuilder.Asset.GetAssetConfig().Contract
+	if token, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		contract = token.Contract
+	}
+	asset, err := ParseAsset(contract)
+	if err != nil {
+		return &Tx{}, err
+	}
+	op := Operation{Type: opPayment, Destination: destination, Asset: asset, Amount: amount.Int().Int64()}
+	return txBuilder.newTx(localInput, source, op), nil
+}
+
+// ParseAsset parses an issued asset in its canonical form CODE:ISSUER, e.g. USDC:GA5Z...
+func ParseAsset(contract string) (Asset, error) {
+	code, issuer, ok := strings.Cut(contract, ":")
+	if !ok || !assetCodePattern.MatchString(code) {
+		return Asset{}, fmt.Errorf("invalid asset '%s': expected CODE:ISSUER", contract)
+	}
+	issuerKey, err := DecodeAccountID(xc.Address(issuer))
+	if err != nil {
+		return Asset{}, fmt.Errorf("invalid issuer of asset '%s': %v", contract, err)
+	}
+	return Asset{Code: code, Issuer: issuerKey}, nil
+}
+
+func parseTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (*TxInput, []byte, []byte, error) {
+	localInput, ok := input.(*TxInput)
+	if !ok {
+		return nil, nil, nil, errors.New("xc.TxInput is not from a stellar chain")
+	}
+	source, err := DecodeAccountID(from)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid from address '%s': %v", from, err)
+	}
+	destination, err := DecodeAccountID(to)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid to address '%s': %v", to, err)
+	}
+	if amount.Int().Sign() <= 0 || !amount.Int().IsInt64() {
+		return nil, nil, nil, fmt.Errorf("invalid amount %s: must fit in an int64", amount.String())
+	}
+	if len(localInput.Memo) > maxMemoTextLength {
+		return nil, nil, nil, fmt.Errorf("invalid memo '%s': text memos have up to %d bytes", localInput.Memo, maxMemoTextLength)
+	}
+	if localInput.Sequence <= 0 {
+		return nil, nil, nil, errors.New("invalid input: missing sequence")
+	}
+	return localInput, source, destination, nil
+}
+
+func (txBuilder TxBuilder) newTx(input *TxInput, source []byte, operations ...Operation) *Tx {
+	return &Tx{
+		Transaction: Transaction{
+			Source:     source,
+			Fee:        input.BaseFee * uint32(len(operations)),
+			Sequence:   input.Sequence,
+			MaxTime:    input.MaxTime,
+			Memo:       input.Memo,
+			Operations: operations,
+		},
+		NetworkPassphrase: networkPassphrase(txBuilder.Asset),
+	}
+}
+
+// networkPassphrase returns the passphrase of the network of an asset, its chain_id_str, the public network by default
+func networkPassphrase(asset xc.ITask) string {
+	if passphrase := asset.GetNativeAsset().ChainIDStr; passphrase != "" {
+		return passphrase
+	}
+	return PublicNetworkPassphrase
+}
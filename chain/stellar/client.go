@@ -0,0 +1,372 @@
+package stellar
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// txTimeout is how long txs built from a TxInput are valid: Horizon rejects txs without an upper time bound after a
+// while, and the bound frees the sequence of txs that couldn't be submitted
+const txTimeout = 10 * time.Minute
+
+// decimals of the amounts of Horizon, in XLM or units of issued assets
+const decimals = 7
+
+// Client for Stellar, using the REST API of Horizon
+type Client struct {
+	Asset           xc.ITask
+	HttpClient      *http.Client
+	URL             string
+	EstimateGasFunc xc.EstimateGasFunc
+}
+
+var _ xc.FullClientWithGas = &Client{}
+
+// horizonError is a problem returned by Horizon, with the result codes of failed txs
+type horizonError struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+	Extras struct {
+		ResultCodes struct {
+			Transaction string   `json:"transaction"`
+			Operations  []string `json:"operations"`
+		} `json:"result_codes"`
+	} `json:"extras"`
+}
+
+func (e *horizonError) Error() string {
+	codes := e.Extras.ResultCodes
+	if codes.Transaction != "" {
+		return strings.Join(append([]string{codes.Transaction}, codes.Operations...), ": ")
+	}
+	if e.Detail != "" {
+		return fmt.Sprintf("%s: %s", e.Title, e.Detail)
+	}
+	return e.Title
+}
+
+type horizonBalance struct {
+	Balance     string `json:"balance"`
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code"`
+	AssetIssuer string `json:"asset_issuer"`
+}
+
+type horizonAccount struct {
+	Sequence string           `json:"sequence"`
+	Balances []horizonBalance `json:"balances"`
+}
+
+type horizonFeeStats struct {
+	LastLedgerBaseFee string `json:"last_ledger_base_fee"`
+	FeeCharged        struct {
+		P90 string `json:"p90"`
+	} `json:"fee_charged"`
+}
+
+type horizonTransaction struct {
+	Hash          string `json:"hash"`
+	Ledger        int64  `json:"ledger"`
+	CreatedAt     string `json:"created_at"`
+	SourceAccount string `json:"source_account"`
+	FeeCharged    string `json:"fee_charged"`
+	Successful    bool   `json:"successful"`
+	ResultXDR     string `json:"result_xdr"`
+}
+
+type horizonOperation struct {
+	Type string `json:"type"`
+	// payments
+	From        string `json:"from"`
+	To          string `json:"to"`
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code"`
+	AssetIssuer string `json:"asset_issuer"`
+	Amount      string `json:"amount"`
+	// account creations
+	Funder          string `json:"funder"`
+	Account         string `json:"account"`
+	StartingBalance string `json:"starting_balance"`
+}
+
+type horizonOperations struct {
+	Embedded struct {
+		Records []horizonOperation `json:"records"`
+	} `json:"_embedded"`
+}
+
+type horizonLedger struct {
+	Hash string `json:"hash"`
+}
+
+type horizonRoot struct {
+	HistoryLatestLedger int64 `json:"history_latest_ledger"`
+}
+
+// NewClient returns a new Stellar Client
+func NewClient(cfgI xc.ITask) (*Client, error) {
+	cfg := cfgI.GetNativeAsset()
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Asset:      cfgI,
+		HttpClient: &http.Client{Transport: transport},
+		URL:        strings.TrimSuffix(cfg.URL, "/"),
+	}, nil
+}
+
+// FetchTxInput returns tx input for a Stellar tx: the next sequence of the sender and the base fee, and for
+// transfers of XLM whether the recipient must be created
+func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, to xc.Address) (xc.TxInput, error) {
+	input := NewTxInput()
+	account, err := client.fetchAccount(ctx, from)
+	if err != nil {
+		return input, err
+	}
+	if account == nil {
+		return input, fmt.Errorf("account '%s' doesn't exist, it must be funded first", from)
+	}
+	sequence, err := strconv.ParseInt(account.Sequence, 10, 64)
+	if err != nil {
+		return input, fmt.Errorf("invalid sequence '%s' of '%s'", account.Sequence, from)
+	}
+	input.Sequence = sequence + 1
+
+	baseFee, err := client.EstimateGas(ctx)
+	if err != nil {
+		return input, err
+	}
+	if !baseFee.Int().IsUint64() || baseFee.Uint64() > uint64(^uint32(0)) {
+		return input, fmt.Errorf("invalid base fee %s", baseFee.String())
+	}
+	input.BaseFee = uint32(baseFee.Uint64())
+	input.MaxTime = uint64(time.Now().Add(txTimeout).Unix())
+
+	if _, ok := client.Asset.(*xc.TokenAssetConfig); !ok && to != "" {
+		recipient, err := client.fetchAccount(ctx, to)
+		if err != nil {
+			return input, err
+		}
+		input.CreateAccount = recipient == nil
+	}
+	return input, nil
+}
+
+// SubmitTx submits a Stellar tx, Horizon waits for its inclusion in a ledger
+func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		return xc.RecordDryRun(ctx, client.Asset, tx, false)
+	}
+	form := url.Values{"tx": {base64.StdEncoding.EncodeToString(serialized)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.URL+"/transactions", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var res json.RawMessage
+	return client.do(req, &res)
+}
+
+// FetchTxInfo returns tx info for a Stellar tx: its payments and account creations, final once in a ledger
+func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
+	var tx horizonTransaction
+	if err := client.get(ctx, "/transactions/"+string(txHash), &tx); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching tx '%s': %v", txHash, err)
+	}
+	var operations horizonOperations
+	if err := client.get(ctx, "/transactions/"+string(txHash)+"/operations?limit=200", &operations); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching operations of tx '%s': %v", txHash, err)
+	}
+
+	nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+	info := xc.TxInfo{
+		TxID:        tx.Hash,
+		ExplorerURL: fmt.Sprintf("/tx/%s", tx.Hash),
+		From:        xc.Address(tx.SourceAccount),
+		Amount:      xc.NewAmountBlockchainFromUint64(0),
+		Fee:         xc.NewAmountBlockchainFromStr(tx.FeeCharged),
+		BlockIndex:  tx.Ledger,
+	}
+	if createdAt, err := time.Parse(time.RFC3339, tx.CreatedAt); err == nil {
+		info.BlockTime = createdAt.Unix()
+	}
+	if !tx.Successful {
+		info.Status = xc.TxStatusFailure
+		info.Error = tx.ResultXDR
+	}
+	for _, op := range operations.Embedded.Records {
+		var from, to xc.Address
+		var contract xc.ContractAddress
+		var amount xc.AmountBlockchain
+		switch op.Type {
+		case "payment":
+			from, to, amount = xc.Address(op.From), xc.Address(op.To), parseAmount(op.Amount)
+			if op.AssetType != "native" {
+				contract = xc.ContractAddress(op.AssetCode + ":" + op.AssetIssuer)
+			}
+		case "create_account":
+			from, to, amount = xc.Address(op.Funder), xc.Address(op.Account), parseAmount(op.StartingBalance)
+		default:
+			continue
+		}
+		info.Sources = append(info.Sources, &xc.TxInfoEndpoint{Address: from, ContractAddress: contract, Amount: amount, NativeAsset: nativeAsset})
+		info.Destinations = append(info.Destinations, &xc.TxInfoEndpoint{Address: to, ContractAddress: contract, Amount: amount, NativeAsset: nativeAsset})
+		if info.To == "" {
+			info.To = to
+			info.Amount = amount
+			info.ContractAddress = contract
+		}
+	}
+
+	var ledger horizonLedger
+	if err := client.get(ctx, fmt.Sprintf("/ledgers/%d", tx.Ledger), &ledger); err != nil {
+		return info, fmt.Errorf("fetching ledger %d: %v", tx.Ledger, err)
+	}
+	info.BlockHash = ledger.Hash
+	var root horizonRoot
+	if err := client.get(ctx, "/", &root); err != nil {
+		return info, fmt.Errorf("fetching latest ledger: %v", err)
+	}
+	if root.HistoryLatestLedger >= tx.Ledger {
+		info.Confirmations = root.HistoryLatestLedger - tx.Ledger + 1
+	}
+	return info, nil
+}
+
+// FetchBalance fetches the balance of an asset for a Stellar account, 0 if it doesn't trust the asset
+func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	token, ok := client.Asset.(*xc.TokenAssetConfig)
+	if !ok {
+		return client.FetchNativeBalance(ctx, address)
+	}
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	code, issuer, _ := strings.Cut(token.Contract, ":")
+	account, err := client.fetchAccount(ctx, address)
+	if err != nil || account == nil {
+		return zero, err
+	}
+	for _, balance := range account.Balances {
+		if balance.AssetType != "native" && balance.AssetCode == code && balance.AssetIssuer == issuer {
+			return parseAmount(balance.Balance), nil
+		}
+	}
+	return zero, nil
+}
+
+// FetchNativeBalance fetches the XLM balance of an account, including its reserve, 0 for accounts not created yet
+func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	account, err := client.fetchAccount(ctx, address)
+	if err != nil || account == nil {
+		return zero, err
+	}
+	for _, balance := range account.Balances {
+		if balance.AssetType == "native" {
+			return parseAmount(balance.Balance), nil
+		}
+	}
+	return zero, nil
+}
+
+func (client *Client) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc) {
+	client.EstimateGasFunc = estimateGas
+}
+
+// EstimateGas returns the base fee of txs in stroops, the 90th percentile of the fees charged in the last ledgers:
+// txs pay the base fee of the ledger including them, the base fee of the input is a max
+func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	if client.EstimateGasFunc != nil {
+		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+		if res, err := client.EstimateGasFunc(nativeAsset); err == nil {
+			return res, nil
+		}
+		// continue with default implementation as fallback
+	}
+	var stats horizonFeeStats
+	if err := client.get(ctx, "/fee_stats", &stats); err != nil {
+		return zero, fmt.Errorf("fetching fee stats: %v", err)
+	}
+	fee := xc.NewAmountBlockchainFromStr(stats.FeeCharged.P90)
+	baseFee := xc.NewAmountBlockchainFromStr(stats.LastLedgerBaseFee)
+	if fee.Cmp(&baseFee) < 0 {
+		return baseFee, nil
+	}
+	return fee, nil
+}
+
+// fetchAccount returns an account, nil if it doesn't exist
+func (client *Client) fetchAccount(ctx context.Context, address xc.Address) (*horizonAccount, error) {
+	var account horizonAccount
+	err := client.get(ctx, "/accounts/"+string(address), &account)
+	if err != nil {
+		var horizonErr *horizonError
+		if errors.As(err, &horizonErr) && horizonErr.Status == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fetching account '%s': %v", address, err)
+	}
+	return &account, nil
+}
+
+func (client *Client) get(ctx context.Context, path string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	return client.do(req, result)
+}
+
+func (client *Client) do(req *http.Request, result interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.HttpClient.Do(req)
+	if err != nil {
+		return xc.DefaultRedactor.RedactError(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		horizonErr := &horizonError{}
+		if err := json.Unmarshal(data, horizonErr); err != nil || horizonErr.Title == "" {
+			return fmt.Errorf("%s returned %s: %s", req.URL.Path, resp.Status, xc.DefaultRedactor.Redact(string(data)))
+		}
+		horizonErr.Status = resp.StatusCode
+		return horizonErr
+	}
+	return json.Unmarshal(data, result)
+}
+
+// parseAmount parses an amount of Horizon, with 7 decimals
+func parseAmount(amount string) xc.AmountBlockchain {
+	parsed, err := xc.ParseAmountHumanReadable(amount)
+	if err != nil {
+		return xc.AmountBlockchain(*new(big.Int))
+	}
+	return parsed.ToBlockchain(decimals)
+}
@@ -0,0 +1,211 @@
+package stellar
+
+import (
+	"net/http"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const testTxHash = "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889"
+
+const testAccount = `{"id":"` + string(testAddress) + `","sequence":"4294967296","balances":[
+	{"balance":"120.5000000","limit":"922337203685.4775807","asset_type":"credit_alphanum4","asset_code":"USDC","asset_issuer":"` + string(testAddress) + `"},
+	{"balance":"9999.9999900","asset_type":"native"}]}`
+
+const testFeeStats = `{"last_ledger":"1500000","last_ledger_base_fee":"100","fee_charged":{"max":"10000","min":"100","mode":"100","p90":"250"}}`
+
+const testNotFound = `{"type":"https://stellar.org/horizon-errors/not_found","title":"Resource Missing","status":404,"detail":"The resource at the url requested was not found."}`
+
+func (s *CrosschainTestSuite) TestFetchTxInput() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{testAccount, testFeeStats, testAccount})
+	defer close()
+
+	client, err := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.XLM, URL: server.URL})
+	require.NoError(err)
+	input, err := client.FetchTxInput(s.Ctx, testAddress, testRecipient)
+	require.NoError(err)
+	txInput := input.(*TxInput)
+	require.Equal(xc.DriverStellar, txInput.Type)
+	require.EqualValues(4294967297, txInput.Sequence)
+	require.EqualValues(250, txInput.BaseFee)
+	require.InDelta(time.Now().Add(txTimeout).Unix(), int64(txInput.MaxTime), 5)
+	require.False(txInput.CreateAccount)
+
+	// the recipient doesn't exist yet, and the fees charged are below the base fee
+	server.Counter = 0
+	server.Response = []string{testAccount, `{"last_ledger_base_fee":"100","fee_charged":{"p90":"0"}}`, testNotFound}
+	server.StatusCodes = []int{http.StatusOK, http.StatusOK, http.StatusNotFound}
+	input, err = client.FetchTxInput(s.Ctx, testAddress, testRecipient)
+	require.NoError(err)
+	require.EqualValues(100, input.(*TxInput).BaseFee)
+	require.True(input.(*TxInput).CreateAccount)
+
+	server.Counter = 0
+	server.Response = testNotFound
+	server.StatusCodes = []int{http.StatusNotFound}
+	_, err = client.FetchTxInput(s.Ctx, testAddress, testRecipient)
+	require.EqualError(err, "account '"+string(testAddress)+"' doesn't exist, it must be funded first")
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenTxInput() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{testAccount, testFeeStats})
+	defer close()
+
+	token := *testToken
+	token.NativeAssetConfig = &xc.NativeAssetConfig{NativeAsset: xc.XLM, URL: server.URL}
+	client, _ := NewClient(&token)
+	input, err := client.FetchTxInput(s.Ctx, testAddress, testRecipient)
+	require.NoError(err)
+	// the recipient of tokens isn't fetched, it must exist and trust the asset
+	require.Equal(2, server.Counter)
+	require.False(input.(*TxInput).CreateAccount)
+}
+
+func (s *CrosschainTestSuite) TestSubmitTx() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, `{"hash":"`+testTxHash+`","successful":true}`)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.XLM, URL: server.URL})
+	builder, _ := NewTxBuilder(testNativeAsset)
+	tx, _ := builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(1), testInput())
+	require.Error(client.SubmitTx(s.Ctx, tx))
+	require.NoError(tx.AddSignatures(make([]byte, 64)))
+	require.NoError(client.SubmitTx(s.Ctx, tx))
+
+	server.Counter = 0
+	server.Response = `{"type":"https://stellar.org/horizon-errors/transaction_failed","title":"Transaction Failed","status":400,"extras":{"result_codes":{"transaction":"tx_failed","operations":["op_underfunded"]}}}`
+	server.StatusCodes = []int{http.StatusBadRequest}
+	err := client.SubmitTx(s.Ctx, tx)
+	require.EqualError(err, "tx_failed: op_underfunded")
+	require.Equal(xc.NoBalance, CheckError(err))
+
+	server.Counter = 0
+	server.Response = `{"type":"https://stellar.org/horizon-errors/transaction_failed","title":"Transaction Failed","status":400,"extras":{"result_codes":{"transaction":"tx_bad_seq"}}}`
+	err = client.SubmitTx(s.Ctx, tx)
+	require.EqualError(err, "tx_bad_seq")
+	require.Equal(xc.TransactionFailure, CheckError(err))
+
+	server.Counter = 0
+	server.Response = `bad gateway`
+	server.StatusCodes = []int{http.StatusBadGateway}
+	err = client.SubmitTx(s.Ctx, tx)
+	require.EqualError(err, "/transactions returned 502 Bad Gateway: bad gateway")
+	require.Equal(xc.UnknownError, CheckError(err))
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfo() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{
+		`{"hash":"` + testTxHash + `","ledger":1500000,"created_at":"2023-11-14T22:13:20Z","source_account":"` + string(testAddress) + `","fee_charged":"200","successful":true}`,
+		`{"_embedded":{"records":[
+			{"type":"payment","from":"` + string(testAddress) + `","to":"` + string(testRecipient) + `","asset_type":"native","amount":"1.5000000"},
+			{"type":"create_account","funder":"` + string(testAddress) + `","account":"GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF","starting_balance":"1.0000000"},
+			{"type":"set_options"}]}}`,
+		`{"hash":"bd8ff3e6a3bb0b4f0cc9ab2e1d4d7c1fae2f1f1b5e1d0b0b6c85bbb2c2f3e8a1","sequence":1500000}`,
+		`{"history_latest_ledger":1500009}`,
+	})
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.XLM, URL: server.URL})
+	info, err := client.FetchTxInfo(s.Ctx, testTxHash)
+	require.NoError(err)
+	require.Equal(testTxHash, info.TxID)
+	require.Equal("/tx/"+testTxHash, info.ExplorerURL)
+	require.Equal(testAddress, info.From)
+	require.Equal(testRecipient, info.To)
+	require.Equal("15000000", info.Amount.String())
+	require.Equal("200", info.Fee.String())
+	require.EqualValues(1500000, info.BlockIndex)
+	require.EqualValues(1700000000, info.BlockTime)
+	require.Equal("bd8ff3e6a3bb0b4f0cc9ab2e1d4d7c1fae2f1f1b5e1d0b0b6c85bbb2c2f3e8a1", info.BlockHash)
+	require.EqualValues(10, info.Confirmations)
+	require.Equal(xc.TxStatusSuccess, info.Status)
+	require.Len(info.Sources, 2)
+	require.Len(info.Destinations, 2)
+	require.Equal(xc.Address("GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF"), info.Destinations[1].Address)
+	require.Equal("10000000", info.Destinations[1].Amount.String())
+	require.Equal(xc.XLM, info.Destinations[1].NativeAsset)
+
+	server.Counter = 0
+	server.Response = testNotFound
+	server.StatusCodes = []int{http.StatusNotFound}
+	_, err = client.FetchTxInfo(s.Ctx, testTxHash)
+	require.EqualError(err, "fetching tx '"+testTxHash+"': Resource Missing: The resource at the url requested was not found.")
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenTxInfo() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{
+		`{"hash":"` + testTxHash + `","ledger":1500000,"created_at":"2023-11-14T22:13:20Z","source_account":"` + string(testAddress) + `","fee_charged":"100","successful":false,"result_xdr":"AAAAAAAAAGT/////AAAAAQAAAAAAAAAB/////gAAAAA="}`,
+		`{"_embedded":{"records":[{"type":"payment","from":"` + string(testAddress) + `","to":"` + string(testRecipient) + `","asset_type":"credit_alphanum4","asset_code":"USDC","asset_issuer":"` + string(testAddress) + `","amount":"12.0000000"}]}}`,
+		`{"hash":"bd8ff3e6a3bb0b4f0cc9ab2e1d4d7c1fae2f1f1b5e1d0b0b6c85bbb2c2f3e8a1"}`,
+		`{"history_latest_ledger":1500000}`,
+	})
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.XLM, URL: server.URL})
+	info, err := client.FetchTxInfo(s.Ctx, testTxHash)
+	require.NoError(err)
+	require.Equal(xc.ContractAddress("USDC:"+testAddress), info.ContractAddress)
+	require.Equal("120000000", info.Amount.String())
+	require.EqualValues(1, info.Confirmations)
+	require.Equal(xc.TxStatusFailure, info.Status)
+	require.Equal("AAAAAAAAAGT/////AAAAAQAAAAAAAAAB/////gAAAAA=", info.Error)
+}
+
+func (s *CrosschainTestSuite) TestFetchBalance() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, testAccount)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.XLM, URL: server.URL})
+	balance, err := client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("99999999900", balance.String())
+
+	token := *testToken
+	token.NativeAssetConfig = &xc.NativeAssetConfig{NativeAsset: xc.XLM, URL: server.URL}
+	client, _ = NewClient(&token)
+	balance, err = client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("1205000000", balance.String())
+
+	// an asset not trusted by the account
+	token.Contract = "EURC:" + string(testAddress)
+	client, _ = NewClient(&token)
+	balance, err = client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("0", balance.String())
+
+	// an account not created yet
+	server.Counter = 0
+	server.Response = testNotFound
+	server.StatusCodes = []int{http.StatusNotFound}
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.XLM, URL: server.URL})
+	balance, err = client.FetchNativeBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("0", balance.String())
+}
+
+func (s *CrosschainTestSuite) TestEstimateGas() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, testFeeStats)
+	defer close()
+
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.XLM, URL: server.URL})
+	fee, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("250", fee.String())
+
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(1000), nil
+	})
+	fee, err = client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("1000", fee.String())
+}
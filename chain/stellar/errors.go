@@ -0,0 +1,36 @@
+package stellar
+
+import (
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// CheckError classifies the errors of Horizon, named by the result codes of the tx and its operations
+func CheckError(err error) xc.ClientError {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "op_underfunded") ||
+		strings.Contains(msg, "op_low_reserve") {
+		return xc.NoBalance
+	}
+	if strings.Contains(msg, "tx_insufficient_balance") {
+		return xc.NoBalanceForGas
+	}
+	if strings.Contains(msg, "tx_bad_seq") ||
+		strings.Contains(msg, "tx_too_late") ||
+		strings.Contains(msg, "tx_insufficient_fee") ||
+		strings.Contains(msg, "tx_bad_auth") ||
+		strings.Contains(msg, "op_no_destination") ||
+		strings.Contains(msg, "op_no_trust") ||
+		strings.Contains(msg, "op_not_authorized") ||
+		strings.Contains(msg, "op_line_full") ||
+		strings.Contains(msg, "op_no_issuer") {
+		return xc.TransactionFailure
+	}
+	if strings.Contains(msg, "timeout") ||
+		strings.Contains(msg, "response body closed") ||
+		strings.Contains(msg, "eof") {
+		return xc.NetworkError
+	}
+	return xc.UnknownError
+}
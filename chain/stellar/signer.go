@@ -0,0 +1,55 @@
+package stellar
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Signer for Stellar
+type Signer struct {
+}
+
+var _ xc.Signer = &Signer{}
+var _ xc.PublicKeyDeriver = &Signer{}
+
+// NewSigner creates a new Stellar Signer
+func NewSigner(asset xc.ITask) (xc.Signer, error) {
+	return Signer{}, nil
+}
+
+// ImportPrivateKey imports a Stellar secret seed, S..., or the hex of a 32 bytes seed
+func (signer Signer) ImportPrivateKey(privateKey string) (xc.PrivateKey, error) {
+	if strings.HasPrefix(privateKey, "S") {
+		seed, err := decodeStrKey(versionSeed, privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret seed: %v", err)
+		}
+		return xc.PrivateKey(seed), nil
+	}
+	seed, err := hex.DecodeString(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+	return xc.PrivateKey(seed), nil
+}
+
+// Sign the signature base of a Stellar tx
+func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	if len(privateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+	}
+	return xc.TxSignature(ed25519.Sign(ed25519.NewKeyFromSeed(privateKey), []byte(data))), nil
+}
+
+// DerivePublicKey returns the ed25519 public key of a private key seed
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	if len(privateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+	}
+	return xc.PublicKey(ed25519.NewKeyFromSeed(privateKey).Public().(ed25519.PublicKey)), nil
+}
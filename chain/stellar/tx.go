@@ -0,0 +1,231 @@
+package stellar
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Network passphrases, signed with txs so they can't be replayed on other networks
+const (
+	PublicNetworkPassphrase  = "Public Global Stellar Network ; September 2015"
+	TestnetNetworkPassphrase = "Test SDF Network ; September 2015"
+)
+
+// envelopeTypeTx is the type of the v1 envelopes of txs, also signed with them
+const envelopeTypeTx = 2
+
+// Operations built by the driver
+const (
+	opCreateAccount = 0
+	opPayment       = 1
+)
+
+// Types of the unions of txs, only the variants built by the driver
+const (
+	keyTypeEd25519       = 0
+	preconditionNone     = 0
+	preconditionTime     = 1
+	memoNone             = 0
+	memoText             = 1
+	memoID               = 2
+	assetTypeNative      = 0
+	assetTypeAlphanum4   = 1
+	assetTypeAlphanum12  = 2
+	maxMemoTextLength    = 28
+	signatureHintLength  = 4
+	alphanum4CodeLength  = 4
+	alphanum12CodeLength = 12
+)
+
+// TxInput for Stellar
+type TxInput struct {
+	xc.TxInputEnvelope
+	// Sequence of the tx: the sequence of the source account, plus 1
+	Sequence int64
+	// BaseFee is the max fee per operation, in stroops
+	BaseFee uint32
+	// MaxTime is the unix time after which the tx is rejected, 0 for no limit
+	MaxTime uint64
+	// Memo of the tx: an id memo if it's a uint64, as expected by exchanges, or else a text memo of up to 28 bytes
+	Memo string
+	// CreateAccount sends native transfers to an account that doesn't exist yet by creating it
+	CreateAccount bool
+}
+
+var _ xc.TxInputFee = &TxInput{}
+
+// NewTxInput returns a new Stellar TxInput
+func NewTxInput() *TxInput {
+	return &TxInput{
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverStellar),
+	}
+}
+
+var _ xc.TxInputNonce = &TxInput{}
+
+// GetNonce returns the sequence of the tx
+func (input *TxInput) GetNonce() uint64 {
+	return uint64(input.Sequence)
+}
+
+// MaxFee returns the max fee of txs built with the input, of a single operation
+func (input *TxInput) MaxFee() xc.AmountBlockchain {
+	return xc.NewAmountBlockchainFromUint64(uint64(input.BaseFee))
+}
+
+// Asset of a payment: XLM, or an asset issued by an account
+type Asset struct {
+	// Code of an issued asset, empty for XLM
+	Code   string
+	Issuer []byte
+}
+
+// Operation of a tx: a payment of Amount of Asset to Destination, or the creation of Destination with a starting
+// balance of Amount
+type Operation struct {
+	Type        uint32
+	Destination []byte
+	Asset       Asset
+	Amount      int64
+}
+
+// Transaction is the unsigned tx of the Source account
+type Transaction struct {
+	Source     []byte
+	Fee        uint32
+	Sequence   int64
+	MaxTime    uint64
+	Memo       string
+	Operations []Operation
+}
+
+// Tx for Stellar
+type Tx struct {
+	Transaction
+	NetworkPassphrase string
+	signature         []byte
+}
+
+var _ xc.Tx = &Tx{}
+
+// Hash returns the hash of the tx: the hex of its signature base
+func (tx Tx) Hash() xc.TxHash {
+	hash := tx.signatureBase()
+	return xc.TxHash(hex.EncodeToString(hash[:]))
+}
+
+// Sighashes returns the signature base of the tx: the sha256 of the id of the network, the envelope type and the
+// unsigned tx
+func (tx Tx) Sighashes() ([]xc.TxDataToSign, error) {
+	if len(tx.Source) == 0 {
+		return []xc.TxDataToSign{}, errors.New("transaction not initialized")
+	}
+	hash := tx.signatureBase()
+	return []xc.TxDataToSign{hash[:]}, nil
+}
+
+// AddSignatures adds the ed25519 signature of the source account
+func (tx *Tx) AddSignatures(signatures ...xc.TxSignature) error {
+	if len(signatures) != 1 {
+		return errors.New("expecting 1 signature")
+	}
+	if len(signatures[0]) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid signature length %d", len(signatures[0]))
+	}
+	tx.signature = signatures[0]
+	return nil
+}
+
+// Serialize returns the XDR of the envelope of the signed tx, submitted in base64
+func (tx Tx) Serialize() ([]byte, error) {
+	if len(tx.signature) == 0 {
+		return []byte{}, errors.New("unable to serialize without first calling AddSignatures(...)")
+	}
+	w := &xdrWriter{}
+	w.u32(envelopeTypeTx)
+	w.Write(tx.Transaction.serialize())
+	// a decorated signature: the hint is the last bytes of the public key of the signer
+	w.u32(1)
+	w.fixed(tx.Source[len(tx.Source)-signatureHintLength:])
+	w.opaque(tx.signature)
+	return w.Bytes(), nil
+}
+
+func (tx Tx) signatureBase() [32]byte {
+	networkID := sha256.Sum256([]byte(tx.NetworkPassphrase))
+	w := &xdrWriter{}
+	w.fixed(networkID[:])
+	w.u32(envelopeTypeTx)
+	w.Write(tx.Transaction.serialize())
+	return sha256.Sum256(w.Bytes())
+}
+
+func (transaction Transaction) serialize() []byte {
+	w := &xdrWriter{}
+	w.u32(keyTypeEd25519)
+	w.fixed(transaction.Source)
+	w.u32(transaction.Fee)
+	w.i64(transaction.Sequence)
+	if transaction.MaxTime == 0 {
+		w.u32(preconditionNone)
+	} else {
+		w.u32(preconditionTime)
+		w.u64(0)
+		w.u64(transaction.MaxTime)
+	}
+	if transaction.Memo == "" {
+		w.u32(memoNone)
+	} else if id, err := strconv.ParseUint(transaction.Memo, 10, 64); err == nil {
+		w.u32(memoID)
+		w.u64(id)
+	} else {
+		w.u32(memoText)
+		w.string(transaction.Memo)
+	}
+	w.u32(uint32(len(transaction.Operations)))
+	for _, op := range transaction.Operations {
+		// the source of the operations is the source of the tx
+		w.u32(0)
+		w.u32(op.Type)
+		switch op.Type {
+		case opCreateAccount:
+			w.u32(keyTypeEd25519)
+			w.fixed(op.Destination)
+			w.i64(op.Amount)
+		case opPayment:
+			w.u32(keyTypeEd25519)
+			w.fixed(op.Destination)
+			op.Asset.serialize(w)
+			w.i64(op.Amount)
+		}
+	}
+	// no extension
+	w.u32(0)
+	return w.Bytes()
+}
+
+func (asset Asset) serialize(w *xdrWriter) {
+	switch {
+	case asset.Code == "":
+		w.u32(assetTypeNative)
+		return
+	case len(asset.Code) <= alphanum4CodeLength:
+		w.u32(assetTypeAlphanum4)
+		code := make([]byte, alphanum4CodeLength)
+		copy(code, asset.Code)
+		w.fixed(code)
+	default:
+		w.u32(assetTypeAlphanum12)
+		code := make([]byte, alphanum12CodeLength)
+		copy(code, asset.Code)
+		w.fixed(code)
+	}
+	w.u32(keyTypeEd25519)
+	w.fixed(asset.Issuer)
+}
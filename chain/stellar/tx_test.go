@@ -0,0 +1,190 @@
+package stellar
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+var testNativeAsset = &xc.NativeAssetConfig{NativeAsset: xc.XLM, ChainIDStr: TestnetNetworkPassphrase}
+
+var testToken = &xc.TokenAssetConfig{
+	Asset:             "USDC",
+	Contract:          "USDC:" + string(testAddress),
+	Decimals:          7,
+	NativeAssetConfig: testNativeAsset,
+}
+
+func testInput() *TxInput {
+	input := NewTxInput()
+	input.Sequence = 1
+	input.BaseFee = 100
+	return input
+}
+
+// the XDR of a tx of the test account with a single operation
+func testTransactionXDR(preconditions string, memo string, operation string) string {
+	return strings.Join([]string{
+		"00000000" + testPublicKey, // source
+		"00000064",                 // fee
+		"0000000000000001",         // sequence
+		preconditions,
+		memo,
+		"00000001", // operations
+		"00000000", // source of the operation
+		operation,
+		"00000000", // extension
+	}, "")
+}
+
+func (s *CrosschainTestSuite) TestNewNativeTransfer() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testNativeAsset)
+	amount := xc.NewAmountBlockchainFromUint64(10_000_000)
+	tx, err := builder.NewTransfer(testAddress, testRecipient, amount, testInput())
+	require.NoError(err)
+	stellarTx := tx.(*Tx)
+	require.Equal(TestnetNetworkPassphrase, stellarTx.NetworkPassphrase)
+	require.EqualValues(100, stellarTx.Fee)
+
+	payment := "00000001" + "00000000" + testRecipientPublicKey + "00000000" + "0000000000989680"
+	require.Equal(testTransactionXDR("00000000", "00000000", payment), hex.EncodeToString(stellarTx.Transaction.serialize()))
+
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes, 1)
+	require.Equal(string(tx.Hash()), hex.EncodeToString(sighashes[0]))
+
+	_, err = tx.Serialize()
+	require.Error(err)
+	signer, _ := NewSigner(testNativeAsset)
+	privateKey, err := signer.ImportPrivateKey(testSecretSeed)
+	require.NoError(err)
+	signature, err := signer.Sign(privateKey, sighashes[0])
+	require.NoError(err)
+	require.NoError(tx.AddSignatures(signature))
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+
+	// the envelope: its type, the tx, and the signature with the hint of the signer
+	require.Equal("00000002"+testTransactionXDR("00000000", "00000000", payment)+"00000001"+"f707511a"+"00000040", hex.EncodeToString(serialized[:len(serialized)-64]))
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	require.True(ed25519.Verify(publicKey, sighashes[0], serialized[len(serialized)-64:]))
+}
+
+func (s *CrosschainTestSuite) TestNewNativeTransferCreateAccount() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testNativeAsset)
+	input := testInput()
+	input.CreateAccount = true
+	input.MaxTime = 1700000000
+	tx, err := builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(10_000_000), input)
+	require.NoError(err)
+
+	timeBounds := "00000001" + "0000000000000000" + "000000006553f100"
+	createAccount := "00000000" + "00000000" + testRecipientPublicKey + "0000000000989680"
+	require.Equal(testTransactionXDR(timeBounds, "00000000", createAccount), hex.EncodeToString(tx.(*Tx).Transaction.serialize()))
+}
+
+func (s *CrosschainTestSuite) TestNewTokenTransfer() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testToken)
+	input := testInput()
+	input.Memo = "12345"
+	tx, err := builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(10_000_000), input)
+	require.NoError(err)
+
+	memoID := "00000002" + "0000000000003039"
+	payment := "00000001" + "00000000" + testRecipientPublicKey + "00000001" + "55534443" + "00000000" + testPublicKey + "0000000000989680"
+	require.Equal(testTransactionXDR("00000000", memoID, payment), hex.EncodeToString(tx.(*Tx).Transaction.serialize()))
+
+	// text memos, and codes of more than 4 characters
+	input.Memo = "hello"
+	token := *testToken
+	token.Contract = "USDCX:" + string(testAddress)
+	builder, _ = NewTxBuilder(&token)
+	tx, err = builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(10_000_000), input)
+	require.NoError(err)
+	memoText := "00000001" + "00000005" + "68656c6c6f000000"
+	payment = "00000001" + "00000000" + testRecipientPublicKey + "00000002" + "555344435800000000000000" + "00000000" + testPublicKey + "0000000000989680"
+	require.Equal(testTransactionXDR("00000000", memoText, payment), hex.EncodeToString(tx.(*Tx).Transaction.serialize()))
+}
+
+func (s *CrosschainTestSuite) TestNetworkPassphrase() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.XLM})
+	tx, err := builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(1), testInput())
+	require.NoError(err)
+	require.Equal(PublicNetworkPassphrase, tx.(*Tx).NetworkPassphrase)
+
+	// the same tx signed for another network has another hash
+	testnetTx := *tx.(*Tx)
+	testnetTx.NetworkPassphrase = TestnetNetworkPassphrase
+	require.NotEqual(tx.Hash(), testnetTx.Hash())
+}
+
+func (s *CrosschainTestSuite) TestNewTransferErrors() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(testNativeAsset)
+	amount := xc.NewAmountBlockchainFromUint64(1)
+
+	_, err := builder.NewTransfer(testAddress, testRecipient, amount, &xc.TxInputEnvelope{})
+	require.EqualError(err, "xc.TxInput is not from a stellar chain")
+	_, err = builder.NewTransfer(testAddress, testRecipient, amount, NewTxInput())
+	require.EqualError(err, "invalid input: missing sequence")
+	_, err = builder.NewTransfer("GABC", testRecipient, amount, testInput())
+	require.ErrorContains(err, "invalid from address 'GABC'")
+	_, err = builder.NewTransfer(testAddress, xc.Address(testSecretSeed), amount, testInput())
+	require.ErrorContains(err, "invalid to address '"+testSecretSeed+"'")
+	_, err = builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromUint64(0), testInput())
+	require.EqualError(err, "invalid amount 0: must fit in an int64")
+	_, err = builder.NewTransfer(testAddress, testRecipient, xc.NewAmountBlockchainFromStr("9223372036854775808"), testInput())
+	require.EqualError(err, "invalid amount 9223372036854775808: must fit in an int64")
+	input := testInput()
+	input.Memo = "a memo of more than 28 bytes!"
+	_, err = builder.NewTransfer(testAddress, testRecipient, amount, input)
+	require.EqualError(err, "invalid memo 'a memo of more than 28 bytes!': text memos have up to 28 bytes")
+
+	token := *testToken
+	token.Contract = "USDC"
+	builder, _ = NewTxBuilder(&token)
+	_, err = builder.NewTransfer(testAddress, testRecipient, amount, testInput())
+	require.EqualError(err, "invalid asset 'USDC': expected CODE:ISSUER")
+	token.Contract = "USDC:GABC"
+	_, err = builder.NewTransfer(testAddress, testRecipient, amount, testInput())
+	require.ErrorContains(err, "invalid issuer of asset 'USDC:GABC'")
+}
+
+func (s *CrosschainTestSuite) TestAddSignatures() {
+	require := s.Require()
+	tx := &Tx{}
+	_, err := tx.Sighashes()
+	require.EqualError(err, "transaction not initialized")
+	require.EqualError(tx.AddSignatures(), "expecting 1 signature")
+	require.EqualError(tx.AddSignatures(make([]byte, 65)), "invalid signature length 65")
+}
+
+func (s *CrosschainTestSuite) TestImportPrivateKey() {
+	require := s.Require()
+	signer, _ := NewSigner(testNativeAsset)
+	seed, _ := hex.DecodeString(testSeed)
+
+	privateKey, err := signer.ImportPrivateKey(testSecretSeed)
+	require.NoError(err)
+	require.Equal(seed, []byte(privateKey))
+
+	privateKey, err = signer.ImportPrivateKey(testSeed)
+	require.NoError(err)
+	require.Equal(seed, []byte(privateKey))
+
+	publicKey, err := signer.(xc.PublicKeyDeriver).DerivePublicKey(privateKey)
+	require.NoError(err)
+	require.Equal(testPublicKey, hex.EncodeToString(publicKey))
+
+	_, err = signer.ImportPrivateKey(string(testAddress))
+	require.EqualError(err, "invalid ed25519 private key")
+	_, err = signer.ImportPrivateKey("SCOWDMM5576VUYF2QRFPJEXMFTCEISOFNF5TE2IZOA52YAY4VZ7WBQNP")
+	require.EqualError(err, "invalid secret seed: invalid checksum")
+}
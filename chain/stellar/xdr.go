@@ -0,0 +1,45 @@
+package stellar
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// xdrWriter writes the XDR encoding of txs: big endian, padded to 4 bytes
+type xdrWriter struct {
+	bytes.Buffer
+}
+
+func (w *xdrWriter) u32(v uint32) {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	w.Write(b)
+}
+
+func (w *xdrWriter) u64(v uint64) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	w.Write(b)
+}
+
+func (w *xdrWriter) i64(v int64) {
+	w.u64(uint64(v))
+}
+
+// fixed writes fixed length opaque data
+func (w *xdrWriter) fixed(b []byte) {
+	w.Write(b)
+	if pad := len(b) % 4; pad != 0 {
+		w.Write(make([]byte, 4-pad))
+	}
+}
+
+// opaque writes variable length opaque data, prefixed with its length
+func (w *xdrWriter) opaque(b []byte) {
+	w.u32(uint32(len(b)))
+	w.fixed(b)
+}
+
+func (w *xdrWriter) string(s string) {
+	w.opaque([]byte(s))
+}
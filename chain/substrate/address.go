@@ -0,0 +1,108 @@
+package substrate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/blake2b"
+)
+
+// AddressBuilder for Substrate
+type AddressBuilder struct {
+	Prefix uint16
+}
+
+var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
+
+// NewAddressBuilder creates a new Substrate AddressBuilder, with the SS58 prefix of the chain
+func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
+	return AddressBuilder{
+		Prefix: *asset.GetNativeAsset().GetSubstrate().SS58Prefix,
+	}, nil
+}
+
+// GetAddressFromPublicKey returns the SS58 address of a sr25519 or ed25519 public key, its account id
+func (ab AddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (xc.Address, error) {
+	if len(publicKeyBytes) != 32 {
+		return xc.Address(""), errors.New("invalid length for sr25519 or ed25519 public key")
+	}
+	return EncodeSS58(ab.Prefix, publicKeyBytes), nil
+}
+
+// GetAllPossibleAddressesFromPublicKey returns all PossubleAddress(es) given a public key
+func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]xc.PossibleAddress, error) {
+	address, err := ab.GetAddressFromPublicKey(publicKeyBytes)
+	return []xc.PossibleAddress{
+		{
+			Address: address,
+			Type:    xc.AddressTypeDefault,
+		},
+	}, err
+}
+
+// ValidateAddress checks an address is the SS58 address of an account on the chain
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	prefix, _, err := DecodeSS58(address)
+	if err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	if prefix != ab.Prefix {
+		return fmt.Errorf("invalid address '%s': prefix %d of another network, expected %d", address, prefix, ab.Prefix)
+	}
+	return nil
+}
+
+// EncodeSS58 returns the SS58 address of an account id: base58 of the network prefix, the account id and the
+// checksum
+func EncodeSS58(prefix uint16, accountID []byte) xc.Address {
+	data := append(encodeSS58Prefix(prefix), accountID...)
+	data = append(data, ss58Checksum(data)...)
+	return xc.Address(base58.Encode(data))
+}
+
+// DecodeSS58 returns the network prefix and the account id of a SS58 address
+func DecodeSS58(address xc.Address) (uint16, []byte, error) {
+	data, err := base58.Decode(string(address))
+	if err != nil || len(data) == 0 {
+		return 0, nil, errors.New("not base58")
+	}
+	var prefix uint16
+	prefixLen := 1
+	switch {
+	case data[0] < 64:
+		prefix = uint16(data[0])
+	case data[0] < 128 && len(data) > 1:
+		// 14 bits prefixes: the low 6 bits of the first byte are bits 2 to 7 of the prefix
+		prefix = uint16(data[0]&0x3f)<<2 | uint16(data[1]>>6) | uint16(data[1]&0x3f)<<8
+		prefixLen = 2
+	default:
+		return 0, nil, errors.New("invalid SS58 prefix")
+	}
+	if len(data) != prefixLen+32+2 {
+		return 0, nil, errors.New("invalid length of account id")
+	}
+	checksum := data[len(data)-2:]
+	if !bytes.Equal(ss58Checksum(data[:len(data)-2]), checksum) {
+		return 0, nil, errors.New("invalid checksum")
+	}
+	return prefix, data[prefixLen : len(data)-2], nil
+}
+
+func encodeSS58Prefix(prefix uint16) []byte {
+	if prefix < 64 {
+		return []byte{byte(prefix)}
+	}
+	return []byte{
+		byte((prefix&0xfc)>>2) | 0x40,
+		byte(prefix>>8) | byte(prefix&0x03)<<6,
+	}
+}
+
+func ss58Checksum(data []byte) []byte {
+	hash := blake2b.Sum512(append([]byte("SS58PRE"), data...))
+	return hash[:2]
+}
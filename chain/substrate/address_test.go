@@ -0,0 +1,104 @@
+package substrate
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+
+	schnorrkel "github.com/ChainSafe/go-schnorrkel"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// the well known development account of Alice
+const (
+	aliceSeed      = "e5be9a5092b81bca64be81d212e7f2f9eba183bb7a90954f7b76361f6edb5c0a"
+	alicePublicKey = "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"
+)
+
+func (s *CrosschainTestSuite) TestGetAddressFromPublicKey() {
+	require := s.Require()
+	publicKey, _ := hex.DecodeString(alicePublicKey)
+
+	vectors := []struct {
+		asset   *xc.NativeAssetConfig
+		address xc.Address
+	}{
+		{&xc.NativeAssetConfig{NativeAsset: xc.DOT}, "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5"},
+		{&xc.NativeAssetConfig{NativeAsset: xc.KSM}, "HNZata7iMYWmk5RvZRTiAsSDhV8366zq2YGb3tLH5Upf74F"},
+		// parachains without a known prefix use the generic one
+		{&xc.NativeAssetConfig{NativeAsset: "WND", Driver: string(xc.DriverSubstrate)}, "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"},
+	}
+	for _, v := range vectors {
+		builder, err := NewAddressBuilder(v.asset)
+		require.NoError(err)
+		address, err := builder.GetAddressFromPublicKey(publicKey)
+		require.NoError(err)
+		require.Equal(v.address, address)
+		require.NoError(builder.(xc.AddressValidator).ValidateAddress(address))
+
+		prefix, accountID, err := DecodeSS58(address)
+		require.NoError(err)
+		require.Equal(*v.asset.GetSubstrate().SS58Prefix, prefix)
+		require.Equal(publicKey, accountID)
+	}
+
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.DOT})
+	_, err := builder.GetAddressFromPublicKey([]byte{1, 2, 3})
+	require.EqualError(err, "invalid length for sr25519 or ed25519 public key")
+}
+
+func (s *CrosschainTestSuite) TestSS58TwoBytesPrefix() {
+	require := s.Require()
+	publicKey, _ := hex.DecodeString(alicePublicKey)
+	for _, prefix := range []uint16{64, 255, 1284, 16383} {
+		prefixOut, accountID, err := DecodeSS58(EncodeSS58(prefix, publicKey))
+		require.NoError(err)
+		require.Equal(prefix, prefixOut)
+		require.Equal(publicKey, accountID)
+	}
+}
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{NativeAsset: xc.DOT})
+	validator := builder.(xc.AddressValidator)
+
+	require.NoError(validator.ValidateAddress("15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5"))
+	require.EqualError(validator.ValidateAddress("5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"),
+		"invalid address '5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY': prefix 42 of another network, expected 0")
+	require.EqualError(validator.ValidateAddress("15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp6"),
+		"invalid address '15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp6': invalid checksum")
+	require.EqualError(validator.ValidateAddress("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"),
+		"invalid address '0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d': not base58")
+}
+
+func (s *CrosschainTestSuite) TestSigner() {
+	require := s.Require()
+	signer, _ := NewSigner(&xc.NativeAssetConfig{NativeAsset: xc.DOT})
+	privateKey, err := signer.ImportPrivateKey("0x" + aliceSeed)
+	require.NoError(err)
+	publicKey, err := signer.(xc.PublicKeyDeriver).DerivePublicKey(privateKey)
+	require.NoError(err)
+	require.Equal(alicePublicKey, hex.EncodeToString(publicKey))
+
+	signature, err := signer.Sign(privateKey, []byte("payload"))
+	require.NoError(err)
+	require.Len(signature, 64)
+	var publicKeyBytes [32]byte
+	var signatureBytes [64]byte
+	copy(publicKeyBytes[:], publicKey)
+	copy(signatureBytes[:], signature)
+	sr25519Signature := &schnorrkel.Signature{}
+	require.NoError(sr25519Signature.Decode(signatureBytes))
+	require.True(schnorrkel.NewPublicKey(publicKeyBytes).Verify(sr25519Signature, schnorrkel.NewSigningContext([]byte("substrate"), []byte("payload"))))
+
+	// ed25519 keys of chains configured with them
+	signer, _ = NewSigner(&xc.NativeAssetConfig{NativeAsset: xc.DOT, Substrate: xc.SubstrateConfig{KeyType: xc.Ed255}})
+	publicKey, err = signer.(xc.PublicKeyDeriver).DerivePublicKey(privateKey)
+	require.NoError(err)
+	signature, err = signer.Sign(privateKey, []byte("payload"))
+	require.NoError(err)
+	require.True(ed25519.Verify(ed25519.PublicKey(publicKey), []byte("payload"), signature))
+
+	_, err = signer.ImportPrivateKey("0102")
+	require.EqualError(err, "invalid private key length 2, expected a 32 bytes seed")
+}
@@ -0,0 +1,93 @@
+package substrate
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// TxBuilder for Substrate
+type TxBuilder struct {
+	Asset xc.ITask
+}
+
+var _ xc.TxBuilder = &TxBuilder{}
+
+// NewTxBuilder creates a new Substrate TxBuilder
+func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
+	return &TxBuilder{
+		Asset: asset,
+	}, nil
+}
+
+// NewTransfer creates a new transfer for an Asset, only native assets are supported
+func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		return nil, errors.New("token transfers are not supported on substrate chains")
+	}
+	return txBuilder.NewNativeTransfer(from, to, amount, input)
+}
+
+// NewNativeTransfer creates a new Balances.transfer_keep_alive extrinsic, which can't reap the sender account
+func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	localInput, err := parseTxInput(input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	substrate := txBuilder.Asset.GetNativeAsset().GetSubstrate()
+	callIndex, err := parseCallIndex(substrate.TransferCallIndex)
+	if err != nil {
+		return &Tx{}, err
+	}
+	_, fromID, err := DecodeSS58(from)
+	if err != nil {
+		return &Tx{}, fmt.Errorf("invalid from address '%s': %v", from, err)
+	}
+	_, toID, err := DecodeSS58(to)
+	if err != nil {
+		return &Tx{}, fmt.Errorf("invalid to address '%s': %v", to, err)
+	}
+	if amount.Int().Sign() < 0 {
+		return &Tx{}, errors.New("invalid negative amount")
+	}
+
+	call := append(callIndex, multiAddressID)
+	call = append(call, toID...)
+	call = append(call, encodeCompact(amount.Int())...)
+	return &Tx{
+		Input:             localInput,
+		From:              fromID,
+		Call:              call,
+		KeyType:           substrate.KeyType,
+		CheckMetadataHash: *substrate.CheckMetadataHash,
+	}, nil
+}
+
+// parseTxInput accepts either ptr or full type of TxInput
+func parseTxInput(input xc.TxInput) (TxInput, error) {
+	switch typed := input.(type) {
+	case TxInput:
+		return typed, nil
+	case *TxInput:
+		return *typed, nil
+	}
+	return TxInput{}, errors.New("xc.TxInput is not from a substrate chain")
+}
+
+// parseCallIndex parses the hex pallet and call index of a call, e.g. 0x0503
+func parseCallIndex(callIndex string) ([]byte, error) {
+	if callIndex == "" {
+		return nil, errors.New("missing transfer_call_index of substrate chain")
+	}
+	index, err := hex.DecodeString(strings.TrimPrefix(callIndex, "0x"))
+	if err != nil || len(index) != 2 {
+		return nil, fmt.Errorf("invalid call index '%s': expected 2 bytes of hex", callIndex)
+	}
+	return index, nil
+}
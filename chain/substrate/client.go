@@ -0,0 +1,388 @@
+package substrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	xc "github.com/jumpcrypto/crosschain"
+	"golang.org/x/crypto/blake2b"
+)
+
+// eraPeriod is the number of blocks txs are valid for, about 6 minutes on Polkadot
+const eraPeriod = 64
+
+// maxScanBlocks is the number of blocks searched for a tx by hash, from the finalized head
+// Substrate nodes don't index txs: txs older than this must be looked up by id, i.e. block-index
+const maxScanBlocks = 2 * eraPeriod
+
+// systemAccountPrefix is the storage prefix of System.Account: twox128("System") ++ twox128("Account")
+var systemAccountPrefix = mustDecodeHex("26aa394eea5630e07c48ae0c9558cef7b99d880ec681799c0cf30e8886371da9")
+
+// Client for Substrate
+type Client struct {
+	Asset           xc.ITask
+	RpcClient       *rpc.Client
+	EstimateGasFunc xc.EstimateGasFunc
+}
+
+var _ xc.FullClientWithGas = &Client{}
+
+type rpcHeader struct {
+	ParentHash string `json:"parentHash"`
+	Number     string `json:"number"`
+}
+
+type rpcBlock struct {
+	Block struct {
+		Header     rpcHeader `json:"header"`
+		Extrinsics []string  `json:"extrinsics"`
+	} `json:"block"`
+}
+
+type rpcRuntimeVersion struct {
+	SpecVersion        uint32 `json:"specVersion"`
+	TransactionVersion uint32 `json:"transactionVersion"`
+}
+
+type rpcFeeInfo struct {
+	PartialFee string `json:"partialFee"`
+}
+
+// NewClient returns a new Substrate Client
+func NewClient(cfgI xc.ITask) (*Client, error) {
+	cfg := cfgI.GetNativeAsset()
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	client, err := rpc.DialHTTPWithClient(cfg.URL, &http.Client{Transport: transport})
+	if err != nil {
+		return nil, fmt.Errorf("dialing url: %v", cfg.URL)
+	}
+	return &Client{
+		Asset:     cfgI,
+		RpcClient: client,
+	}, nil
+}
+
+// FetchTxInput returns tx input for a Substrate tx: a mortal era from the finalized head, the nonce of the sender
+// and the runtime versions
+func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, _ xc.Address) (xc.TxInput, error) {
+	input := NewTxInput()
+	var genesisHash string
+	if err := client.RpcClient.CallContext(ctx, &genesisHash, "chain_getBlockHash", 0); err != nil {
+		return input, fmt.Errorf("fetching genesis hash: %v", err)
+	}
+	var headHash string
+	if err := client.RpcClient.CallContext(ctx, &headHash, "chain_getFinalizedHead"); err != nil {
+		return input, fmt.Errorf("fetching finalized head: %v", err)
+	}
+	head, err := client.fetchHeader(ctx, headHash)
+	if err != nil {
+		return input, err
+	}
+	var version rpcRuntimeVersion
+	if err := client.RpcClient.CallContext(ctx, &version, "state_getRuntimeVersion"); err != nil {
+		return input, fmt.Errorf("fetching runtime version: %v", err)
+	}
+	var nonce uint64
+	if err := client.RpcClient.CallContext(ctx, &nonce, "system_accountNextIndex", string(from)); err != nil {
+		return input, fmt.Errorf("fetching nonce: %v", err)
+	}
+	tip, err := client.EstimateGas(ctx)
+	if err != nil {
+		return input, err
+	}
+
+	if input.GenesisHash, err = hexutil.Decode(genesisHash); err != nil {
+		return input, fmt.Errorf("invalid genesis hash: %v", err)
+	}
+	if input.BlockHash, err = hexutil.Decode(headHash); err != nil {
+		return input, fmt.Errorf("invalid block hash: %v", err)
+	}
+	input.BlockNumber = head
+	input.EraPeriod = eraPeriod
+	input.Nonce = nonce
+	input.Tip = tip.Uint64()
+	input.SpecVersion = version.SpecVersion
+	input.TransactionVersion = version.TransactionVersion
+	return input, nil
+}
+
+// SubmitTx submits a Substrate tx
+func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		return xc.RecordDryRun(ctx, client.Asset, tx, false)
+	}
+	var hash string
+	return client.RpcClient.CallContext(ctx, &hash, "author_submitExtrinsic", hexutil.Encode(serialized))
+}
+
+// FetchTxInfo returns tx info for a Substrate tx, identified by its hash or by its id: the block number and the
+// index of the extrinsic in the block, e.g. 1000-2
+// Txs are searched by hash in the blocks of their era, from the finalized head
+// Failed txs are reported as successful: their status is only reported by the events of their block, which need the
+// metadata of the runtime to be decoded
+func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
+	var headHash string
+	if err := client.RpcClient.CallContext(ctx, &headHash, "chain_getFinalizedHead"); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching finalized head: %v", err)
+	}
+	head, err := client.fetchHeader(ctx, headHash)
+	if err != nil {
+		return xc.TxInfo{}, err
+	}
+
+	var block rpcBlock
+	var blockHash string
+	index := -1
+	if number, extrinsicIndex, ok := parseExtrinsicID(string(txHash)); ok {
+		if err := client.RpcClient.CallContext(ctx, &blockHash, "chain_getBlockHash", number); err != nil {
+			return xc.TxInfo{}, fmt.Errorf("fetching block %d: %v", number, err)
+		}
+		if block, err = client.fetchBlock(ctx, blockHash); err != nil {
+			return xc.TxInfo{}, err
+		}
+		if extrinsicIndex >= len(block.Block.Extrinsics) {
+			return xc.TxInfo{}, fmt.Errorf("tx not found: %s", txHash)
+		}
+		index = extrinsicIndex
+	} else {
+		blockHash = headHash
+		for i := 0; i < maxScanBlocks && index < 0 && blockHash != ""; i++ {
+			if block, err = client.fetchBlock(ctx, blockHash); err != nil {
+				return xc.TxInfo{}, err
+			}
+			for j, encoded := range block.Block.Extrinsics {
+				data, err := hexutil.Decode(encoded)
+				if err == nil && strings.EqualFold(string(extrinsicHash(data)), string(txHash)) {
+					index = j
+					break
+				}
+			}
+			if index < 0 {
+				blockHash = block.Block.Header.ParentHash
+			}
+		}
+		if index < 0 {
+			return xc.TxInfo{}, fmt.Errorf("tx not found in the last %d blocks: %s", maxScanBlocks, txHash)
+		}
+	}
+
+	number, err := hexutil.DecodeUint64(block.Block.Header.Number)
+	if err != nil {
+		return xc.TxInfo{}, fmt.Errorf("invalid block number: %v", err)
+	}
+	encoded := block.Block.Extrinsics[index]
+	data, err := hexutil.Decode(encoded)
+	if err != nil {
+		return xc.TxInfo{}, fmt.Errorf("invalid extrinsic: %v", err)
+	}
+	substrate := client.Asset.GetNativeAsset().GetSubstrate()
+	decoded, err := decodeExtrinsic(data, *substrate.CheckMetadataHash)
+	if err != nil {
+		return xc.TxInfo{}, err
+	}
+
+	info := xc.TxInfo{
+		BlockHash:     blockHash,
+		TxID:          string(extrinsicHash(data)),
+		ExplorerURL:   fmt.Sprintf("/extrinsic/%d-%d", number, index),
+		BlockIndex:    int64(number),
+		BlockTime:     blockTime(block),
+		Confirmations: int64(head) - int64(number),
+		Fee:           xc.NewAmountBlockchainFromUint64(0),
+		Amount:        xc.NewAmountBlockchainFromUint64(0),
+	}
+	if decoded.Signed {
+		info.From = EncodeSS58(*substrate.SS58Prefix, decoded.Signer)
+		var fee rpcFeeInfo
+		// the fee charged by the runtime of the parent block, the tip excluded
+		if err := client.RpcClient.CallContext(ctx, &fee, "payment_queryInfo", encoded, block.Block.Header.ParentHash); err == nil {
+			if partialFee, ok := new(big.Int).SetString(fee.PartialFee, 10); ok {
+				info.Fee = xc.AmountBlockchain(*partialFee)
+			}
+		}
+	}
+	if to, amount, ok := parseTransferCall(decoded.Call, substrate.TransferCallIndex); ok {
+		info.To = EncodeSS58(*substrate.SS58Prefix, to)
+		info.Amount = xc.AmountBlockchain(*amount)
+		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+		info.Sources = []*xc.TxInfoEndpoint{{Address: info.From, Amount: info.Amount, NativeAsset: nativeAsset}}
+		info.Destinations = []*xc.TxInfoEndpoint{{Address: info.To, Amount: info.Amount, NativeAsset: nativeAsset}}
+	} else if transfer, ok := parseXCMTransferCall(decoded.Call, substrate.XCMPalletIndex); ok {
+		// the destination is the parachain: the arrival of the transfer is tracked by ClientXCM
+		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+		destination, prefix := xc.NativeAsset(fmt.Sprintf("para-%d", transfer.ParaID)), uint16(xc.GenericSS58Prefix)
+		for asset, route := range substrate.XCM {
+			if route.ParaID == transfer.ParaID {
+				destination, prefix = asset, *route.SS58Prefix
+				break
+			}
+		}
+		info.To = EncodeSS58(prefix, transfer.Beneficiary)
+		info.Amount = xc.AmountBlockchain(*transfer.Amount)
+		info.Sources = []*xc.TxInfoEndpoint{{Address: info.From, Amount: info.Amount, NativeAsset: nativeAsset}}
+		info.Destinations = []*xc.TxInfoEndpoint{{Address: info.To, Amount: info.Amount, NativeAsset: destination, Asset: xc.Asset(nativeAsset)}}
+	}
+	return info, nil
+}
+
+// FetchBalance fetches balance for a Substrate address
+func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	if _, ok := client.Asset.(*xc.TokenAssetConfig); ok {
+		return xc.NewAmountBlockchainFromUint64(0), errors.New("token balances are not supported on substrate chains")
+	}
+	return client.FetchNativeBalance(ctx, address)
+}
+
+// FetchNativeBalance fetches the free balance of a Substrate address, from the System.Account storage
+func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	_, accountID, err := DecodeSS58(address)
+	if err != nil {
+		return zero, fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	// blake2_128_concat of the account id
+	hasher, _ := blake2b.New(16, nil)
+	hasher.Write(accountID)
+	key := append(append(append([]byte{}, systemAccountPrefix...), hasher.Sum(nil)...), accountID...)
+
+	var storage *string
+	if err := client.RpcClient.CallContext(ctx, &storage, "state_getStorage", hexutil.Encode(key)); err != nil {
+		return zero, err
+	}
+	if storage == nil {
+		// accounts below the existential deposit don't exist
+		return zero, nil
+	}
+	data, err := hexutil.Decode(*storage)
+	// AccountInfo: nonce, consumers, providers and sufficients (u32), then the free balance (u128)
+	if err != nil || len(data) < 32 {
+		return zero, fmt.Errorf("invalid account storage of '%s'", address)
+	}
+	return xc.AmountBlockchain(*new(big.Int).SetBytes(reverse(data[16:32]))), nil
+}
+
+func (client *Client) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc) {
+	client.EstimateGasFunc = estimateGas
+}
+
+// EstimateGas returns the tip of txs: fees are set by the runtime from the weight and length of txs, and the tip
+// prioritizes them, chain_gas_tip if not estimated by the callback
+func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	if client.EstimateGasFunc != nil {
+		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+		if res, err := client.EstimateGasFunc(nativeAsset); err == nil {
+			return res, nil
+		}
+		// continue with default implementation as fallback
+	}
+	return xc.NewAmountBlockchainFromUint64(client.Asset.GetNativeAsset().ChainGasTip), nil
+}
+
+func (client *Client) fetchHeader(ctx context.Context, hash string) (uint64, error) {
+	var header rpcHeader
+	if err := client.RpcClient.CallContext(ctx, &header, "chain_getHeader", hash); err != nil {
+		return 0, fmt.Errorf("fetching header: %v", err)
+	}
+	number, err := hexutil.DecodeUint64(header.Number)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block number: %v", err)
+	}
+	return number, nil
+}
+
+func (client *Client) fetchBlock(ctx context.Context, hash string) (rpcBlock, error) {
+	var block *rpcBlock
+	if err := client.RpcClient.CallContext(ctx, &block, "chain_getBlock", hash); err != nil {
+		return rpcBlock{}, fmt.Errorf("fetching block %s: %v", hash, err)
+	}
+	if block == nil {
+		return rpcBlock{}, fmt.Errorf("block not found: %s", hash)
+	}
+	return *block, nil
+}
+
+// parseExtrinsicID parses the id of an extrinsic: its block number and index, e.g. 1000-2
+func parseExtrinsicID(id string) (uint64, int, bool) {
+	number, index, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	blockNumber, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	extrinsicIndex, err := strconv.Atoi(index)
+	if err != nil || extrinsicIndex < 0 {
+		return 0, 0, false
+	}
+	return blockNumber, extrinsicIndex, true
+}
+
+// parseTransferCall returns the destination and the amount of the transfer calls of Balances: transfer_allow_death
+// (0) and transfer_keep_alive (3)
+func parseTransferCall(call []byte, transferCallIndex string) ([]byte, *big.Int, bool) {
+	index, err := parseCallIndex(transferCallIndex)
+	if err != nil || len(call) < 2 || call[0] != index[0] || (call[1] != 0 && call[1] != 3) {
+		return nil, nil, false
+	}
+	reader := bytes.NewReader(call[2:])
+	if addressType, err := reader.ReadByte(); err != nil || addressType != multiAddressID {
+		return nil, nil, false
+	}
+	to := make([]byte, 32)
+	if n, _ := reader.Read(to); n != 32 {
+		return nil, nil, false
+	}
+	amount, err := decodeCompact(reader)
+	if err != nil {
+		return nil, nil, false
+	}
+	return to, amount, true
+}
+
+// blockTime returns the time of a block in seconds, set by the Timestamp.set inherent, the first extrinsic of blocks
+func blockTime(block rpcBlock) int64 {
+	if len(block.Block.Extrinsics) == 0 {
+		return 0
+	}
+	data, err := hexutil.Decode(block.Block.Extrinsics[0])
+	if err != nil {
+		return 0
+	}
+	decoded, err := decodeExtrinsic(data, false)
+	if err != nil || decoded.Signed {
+		return 0
+	}
+	moment, err := decodeCompact(bytes.NewReader(decoded.Call[2:]))
+	if err != nil || !moment.IsUint64() {
+		return 0
+	}
+	return int64(moment.Uint64() / 1000)
+}
+
+func mustDecodeHex(str string) []byte {
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
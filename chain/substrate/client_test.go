@@ -0,0 +1,136 @@
+package substrate
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func (s *CrosschainTestSuite) TestFetchTxInput() {
+	require := s.Require()
+	genesis := "0x91b171bb158e2d3848fa23a9f1c25182fb8e20313b2c1eb49219da7a70ce90c3"
+	head := "0x2222222222222222222222222222222222222222222222222222222222222222"
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`"` + genesis + `"`,
+		`"` + head + `"`,
+		`{"parentHash":"0x11","number":"0x12d687"}`,
+		`{"specName":"polkadot","specVersion":1002000,"transactionVersion":26}`,
+		`7`,
+	})
+	defer close()
+
+	asset := &xc.NativeAssetConfig{NativeAsset: xc.DOT, URL: server.URL, ChainGasTip: 100}
+	client, err := NewClient(asset)
+	require.NoError(err)
+	input, err := client.FetchTxInput(s.Ctx, "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5", "")
+	require.NoError(err)
+	txInput := input.(*TxInput)
+	require.Equal(xc.DriverSubstrate, txInput.Type)
+	require.Equal(genesis, hexutil.Encode(txInput.GenesisHash))
+	require.Equal(head, hexutil.Encode(txInput.BlockHash))
+	require.EqualValues(1234567, txInput.BlockNumber)
+	require.EqualValues(eraPeriod, txInput.EraPeriod)
+	require.EqualValues(7, txInput.Nonce)
+	require.EqualValues(100, txInput.Tip)
+	require.EqualValues(1002000, txInput.SpecVersion)
+	require.EqualValues(26, txInput.TransactionVersion)
+
+	server.Response = fmt.Errorf("connection refused")
+	_, err = client.FetchTxInput(s.Ctx, "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5", "")
+	require.ErrorContains(err, "fetching genesis hash")
+}
+
+func (s *CrosschainTestSuite) TestSubmitTx() {
+	require := s.Require()
+	server, close := test.MockJSONRPC(&s.Suite, `"0x01"`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.DOT, URL: server.URL})
+
+	tx := newTestTransfer(s, &xc.NativeAssetConfig{NativeAsset: xc.DOT})
+	require.Error(client.SubmitTx(s.Ctx, tx))
+	require.Equal(0, server.Counter)
+
+	require.NoError(tx.AddSignatures(make([]byte, 64)))
+	require.NoError(client.SubmitTx(s.Ctx, tx))
+	require.Equal(1, server.Counter)
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfo() {
+	require := s.Require()
+	tx := newTestTransfer(s, &xc.NativeAssetConfig{NativeAsset: xc.DOT})
+	require.NoError(tx.AddSignatures(make([]byte, 64)))
+	serialized, _ := tx.Serialize()
+	// Timestamp.set inherent, at 1700000000000ms
+	inherent := append([]byte{0x04, 0x03, 0x00}, encodeCompactUint64(1700000000000)...)
+	inherent = append(encodeCompactUint64(uint64(len(inherent))), inherent...)
+	headBlock := fmt.Sprintf(`{"block":{"header":{"parentHash":"0xbb","number":"0x66"},"extrinsics":["%s"]}}`, hexutil.Encode(inherent))
+	txBlock := fmt.Sprintf(`{"block":{"header":{"parentHash":"0xcc","number":"0x64"},"extrinsics":["%s","%s"]}}`, hexutil.Encode(inherent), hexutil.Encode(serialized))
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`"0xaa"`,
+		`{"parentHash":"0xbb","number":"0x66"}`,
+		headBlock,
+		txBlock,
+		`{"weight":{"refTime":1,"proofSize":1},"class":"normal","partialFee":"156000000"}`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.DOT, URL: server.URL})
+
+	info, err := client.FetchTxInfo(s.Ctx, tx.Hash())
+	require.NoError(err)
+	require.Equal(string(tx.Hash()), info.TxID)
+	require.Equal("0xbb", info.BlockHash)
+	require.EqualValues(100, info.BlockIndex)
+	require.EqualValues(1700000000, info.BlockTime)
+	require.EqualValues(2, info.Confirmations)
+	require.Equal("/extrinsic/100-1", info.ExplorerURL)
+	require.Equal(xc.Address("15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5"), info.From)
+	require.Equal(EncodeSS58(0, mustDecodeHex(hex.EncodeToString(tx.Call[3:35]))), info.To)
+	require.Equal("12345", info.Amount.String())
+	require.Equal("156000000", info.Fee.String())
+	require.Len(info.Destinations, 1)
+	require.Equal(xc.DOT, info.Destinations[0].NativeAsset)
+
+	// by id
+	server.Counter = 0
+	server.Response = []string{
+		`"0xaa"`,
+		`{"parentHash":"0xbb","number":"0x66"}`,
+		`"0xbb"`,
+		txBlock,
+		`{"partialFee":"156000000"}`,
+	}
+	info, err = client.FetchTxInfo(s.Ctx, "100-1")
+	require.NoError(err)
+	require.Equal(string(tx.Hash()), info.TxID)
+	require.Equal("12345", info.Amount.String())
+
+	server.Counter = 0
+	_, err = client.FetchTxInfo(s.Ctx, "100-2")
+	require.EqualError(err, "tx not found: 100-2")
+}
+
+func (s *CrosschainTestSuite) TestFetchBalance() {
+	require := s.Require()
+	// nonce 1, providers 1 and a free balance of 12345
+	accountInfo := "01000000" + "00000000" + "01000000" + "00000000" + "39300000000000000000000000000000" +
+		"00000000000000000000000000000000" + "00000000000000000000000000000000" + "00000000000000000000000000000080"
+	server, close := test.MockJSONRPC(&s.Suite, []string{`"0x` + accountInfo + `"`, `null`})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.DOT, URL: server.URL})
+
+	balance, err := client.FetchBalance(s.Ctx, "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5")
+	require.NoError(err)
+	require.Equal("12345", balance.String())
+
+	// accounts without balance don't exist
+	balance, err = client.FetchNativeBalance(s.Ctx, "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5")
+	require.NoError(err)
+	require.Equal("0", balance.String())
+
+	_, err = client.FetchBalance(s.Ctx, "0x01")
+	require.ErrorContains(err, "invalid address")
+}
@@ -0,0 +1,34 @@
+package substrate
+
+import (
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// CheckError classifies the errors of the transaction pool of Substrate nodes
+func CheckError(err error) xc.ClientError {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "inability to pay some fees") {
+		return xc.NoBalanceForGas
+	}
+	if strings.Contains(msg, "funds are unavailable") ||
+		strings.Contains(msg, "insufficientbalance") {
+		return xc.NoBalance
+	}
+	if strings.Contains(msg, "already imported") ||
+		strings.Contains(msg, "priority is too low") ||
+		strings.Contains(msg, "temporarily banned") {
+		return xc.TransactionExists
+	}
+	if strings.Contains(msg, "transaction is outdated") ||
+		strings.Contains(msg, "bad signature") ||
+		strings.Contains(msg, "transaction has an ancient birth block") {
+		return xc.TransactionFailure
+	}
+	if strings.Contains(msg, "response body closed") ||
+		strings.Contains(msg, "eof") {
+		return xc.NetworkError
+	}
+	return xc.UnknownError
+}
@@ -0,0 +1,115 @@
+package substrate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"math/bits"
+)
+
+// encodeCompact returns the SCALE compact encoding of an unsigned integer
+func encodeCompact(value *big.Int) []byte {
+	switch {
+	case value.Cmp(big.NewInt(1<<6)) < 0:
+		return []byte{byte(value.Uint64() << 2)}
+	case value.Cmp(big.NewInt(1<<14)) < 0:
+		encoded := uint16(value.Uint64()<<2) | 0b01
+		return []byte{byte(encoded), byte(encoded >> 8)}
+	case value.Cmp(big.NewInt(1<<30)) < 0:
+		encoded := make([]byte, 4)
+		binary.LittleEndian.PutUint32(encoded, uint32(value.Uint64()<<2)|0b10)
+		return encoded
+	}
+	// big integer mode: the number of bytes (at least 4) and the little endian bytes
+	littleEndian := reverse(value.Bytes())
+	for len(littleEndian) < 4 {
+		littleEndian = append(littleEndian, 0)
+	}
+	return append([]byte{byte(len(littleEndian)-4)<<2 | 0b11}, littleEndian...)
+}
+
+func encodeCompactUint64(value uint64) []byte {
+	return encodeCompact(new(big.Int).SetUint64(value))
+}
+
+// decodeCompact reads a SCALE compact unsigned integer
+func decodeCompact(reader *bytes.Reader) (*big.Int, error) {
+	first, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	var encoded []byte
+	switch first & 0b11 {
+	case 0b00:
+		return big.NewInt(int64(first >> 2)), nil
+	case 0b01:
+		encoded = make([]byte, 1)
+	case 0b10:
+		encoded = make([]byte, 3)
+	default:
+		encoded = make([]byte, int(first>>2)+4)
+	}
+	if _, err := io.ReadFull(reader, encoded); err != nil {
+		return nil, err
+	}
+	if first&0b11 == 0b11 {
+		return new(big.Int).SetBytes(reverse(encoded)), nil
+	}
+	// the mode bits are the low bits of the first byte
+	value := new(big.Int).SetBytes(reverse(append([]byte{first}, encoded...)))
+	return value.Rsh(value, 2), nil
+}
+
+// encodeMortalEra returns the era of a tx valid for period blocks from the block number, in the first 4096 blocks
+// of which eras are exact, the period is rounded to a power of two
+// Immortal txs have the era 0x00
+func encodeMortalEra(period uint64, number uint64) []byte {
+	rounded := uint64(4)
+	for rounded < period && rounded < 1<<16 {
+		rounded <<= 1
+	}
+	quantizeFactor := rounded >> 12
+	if quantizeFactor == 0 {
+		quantizeFactor = 1
+	}
+	phase := number % rounded
+	low := bits.TrailingZeros64(rounded) - 1
+	if low < 1 {
+		low = 1
+	}
+	if low > 15 {
+		low = 15
+	}
+	encoded := uint16(low) | uint16(phase/quantizeFactor)<<4
+	return []byte{byte(encoded), byte(encoded >> 8)}
+}
+
+// skipEra reads the era of an extrinsic: a byte for immortal eras, else two
+func skipEra(reader *bytes.Reader) error {
+	first, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	if first != 0 {
+		_, err = reader.ReadByte()
+	}
+	return err
+}
+
+func encodeUint32(value uint32) []byte {
+	encoded := make([]byte, 4)
+	binary.LittleEndian.PutUint32(encoded, value)
+	return encoded
+}
+
+func reverse(data []byte) []byte {
+	reversed := make([]byte, len(data))
+	for i, b := range data {
+		reversed[len(data)-1-i] = b
+	}
+	return reversed
+}
+
+var errTruncated = errors.New("truncated extrinsic")
@@ -0,0 +1,96 @@
+package substrate
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	schnorrkel "github.com/ChainSafe/go-schnorrkel"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// signingContext is the context of the sr25519 signatures of Substrate extrinsics
+var signingContext = []byte("substrate")
+
+// Signer for Substrate, signing with sr25519 or ed25519 keys depending on the key type of the chain
+type Signer struct {
+	KeyType xc.SignatureType
+}
+
+var _ xc.Signer = &Signer{}
+var _ xc.PublicKeyDeriver = &Signer{}
+
+// NewSigner creates a new Substrate Signer
+func NewSigner(asset xc.ITask) (xc.Signer, error) {
+	return &Signer{
+		KeyType: asset.GetNativeAsset().GetSubstrate().KeyType,
+	}, nil
+}
+
+// ImportPrivateKey imports a Substrate private key: the hex 32 bytes seed (mini secret key) of the account, as
+// exported by subkey
+func (signer Signer) ImportPrivateKey(privateKeyString string) (xc.PrivateKey, error) {
+	seed, err := hex.DecodeString(strings.TrimPrefix(privateKeyString, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != 32 {
+		return nil, fmt.Errorf("invalid private key length %d, expected a 32 bytes seed", len(seed))
+	}
+	return xc.PrivateKey(seed), nil
+}
+
+// Sign a Substrate signing payload
+func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	if signer.KeyType == xc.Ed255 {
+		if len(privateKey) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+		}
+		return ed25519.Sign(ed25519.NewKeyFromSeed(privateKey), data), nil
+	}
+	secretKey, err := sr25519SecretKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := secretKey.Sign(schnorrkel.NewSigningContext(signingContext, data))
+	if err != nil {
+		return nil, err
+	}
+	encoded := signature.Encode()
+	return xc.TxSignature(encoded[:]), nil
+}
+
+// DerivePublicKey returns the public key of a Substrate private key seed, its account id
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	if signer.KeyType == xc.Ed255 {
+		if len(privateKey) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid ed25519 private key length %d", len(privateKey))
+		}
+		return xc.PublicKey(ed25519.NewKeyFromSeed(privateKey).Public().(ed25519.PublicKey)), nil
+	}
+	secretKey, err := sr25519SecretKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := secretKey.Public()
+	if err != nil {
+		return nil, err
+	}
+	encoded := publicKey.Encode()
+	return xc.PublicKey(encoded[:]), nil
+}
+
+// sr25519SecretKey expands a seed like Substrate, with the ed25519 expansion of schnorrkel
+func sr25519SecretKey(privateKey xc.PrivateKey) (*schnorrkel.SecretKey, error) {
+	if len(privateKey) != 32 {
+		return nil, fmt.Errorf("invalid sr25519 private key length %d", len(privateKey))
+	}
+	var seed [32]byte
+	copy(seed[:], privateKey)
+	miniSecretKey, err := schnorrkel.NewMiniSecretKeyFromRaw(seed)
+	if err != nil {
+		return nil, err
+	}
+	return miniSecretKey.ExpandEd25519(), nil
+}
@@ -0,0 +1,239 @@
+package substrate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"golang.org/x/crypto/blake2b"
+)
+
+// extrinsicVersion is the version of extrinsics, with the bit of signed extrinsics
+const (
+	extrinsicVersion = 4
+	signedBit        = 0x80
+)
+
+// MultiAddress and MultiSignature variants
+const (
+	multiAddressID      = 0x00
+	multiSignatureEd255 = 0x00
+	multiSignatureSr255 = 0x01
+	multiSignatureECDSA = 0x02
+)
+
+// maxPayloadLength is the length above which signing payloads are hashed before they're signed
+const maxPayloadLength = 256
+
+// TxInput for Substrate
+type TxInput struct {
+	xc.TxInputEnvelope
+	GenesisHash []byte
+	// BlockHash and BlockNumber are the block the mortal era of the tx starts at
+	BlockHash   []byte
+	BlockNumber uint64
+	// EraPeriod is the number of blocks the tx is valid for, immortal if 0
+	EraPeriod          uint64
+	Nonce              uint64
+	Tip                uint64
+	SpecVersion        uint32
+	TransactionVersion uint32
+}
+
+// NewTxInput returns a new Substrate TxInput
+func NewTxInput() *TxInput {
+	return &TxInput{
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverSubstrate),
+	}
+}
+
+var _ xc.TxInputNonce = &TxInput{}
+
+// GetNonce returns the nonce of the sender
+func (txInput *TxInput) GetNonce() uint64 {
+	return txInput.Nonce
+}
+
+// Tx for Substrate: a signed extrinsic of a call
+type Tx struct {
+	Input TxInput
+	// From is the account id of the signer
+	From []byte
+	Call []byte
+	// KeyType and CheckMetadataHash come from the config of the chain
+	KeyType           xc.SignatureType
+	CheckMetadataHash bool
+	signature         []byte
+}
+
+var _ xc.Tx = &Tx{}
+
+// Hash returns the tx hash: the blake2b-256 of the extrinsic, empty until the tx is signed
+func (tx Tx) Hash() xc.TxHash {
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return xc.TxHash("")
+	}
+	return extrinsicHash(serialized)
+}
+
+// Sighashes returns the tx payload to sign: the call, the extra and the additional data of the signed extensions
+// Payloads longer than 256 bytes are replaced by their blake2b-256
+func (tx Tx) Sighashes() ([]xc.TxDataToSign, error) {
+	payload := append([]byte{}, tx.Call...)
+	payload = append(payload, tx.extra()...)
+	payload = append(payload, tx.additional()...)
+	if len(payload) > maxPayloadLength {
+		hash := blake2b.Sum256(payload)
+		payload = hash[:]
+	}
+	return []xc.TxDataToSign{payload}, nil
+}
+
+// AddSignatures adds the signature of the tx: 64 bytes, of the key type of the chain, or an encoded
+// MultiSignature
+func (tx *Tx) AddSignatures(signatures ...xc.TxSignature) error {
+	if len(signatures) != 1 {
+		return errors.New("expecting 1 signature")
+	}
+	signature := signatures[0]
+	switch {
+	case len(signature) == 64 && tx.KeyType == xc.Ed255:
+		tx.signature = append([]byte{multiSignatureEd255}, signature...)
+	case len(signature) == 64:
+		tx.signature = append([]byte{multiSignatureSr255}, signature...)
+	case len(signature) == 65 && (signature[0] == multiSignatureEd255 || signature[0] == multiSignatureSr255):
+		tx.signature = append([]byte{}, signature...)
+	default:
+		return fmt.Errorf("invalid signature length %d", len(signature))
+	}
+	return nil
+}
+
+// Serialize returns the signed extrinsic, prefixed with its length
+func (tx Tx) Serialize() ([]byte, error) {
+	if len(tx.signature) == 0 {
+		return []byte{}, errors.New("unable to serialize without first calling AddSignatures(...)")
+	}
+	if len(tx.From) != 32 {
+		return []byte{}, errors.New("unable to serialize without the account id of the sender")
+	}
+	body := []byte{extrinsicVersion | signedBit, multiAddressID}
+	body = append(body, tx.From...)
+	body = append(body, tx.signature...)
+	body = append(body, tx.extra()...)
+	body = append(body, tx.Call...)
+	return append(encodeCompactUint64(uint64(len(body))), body...), nil
+}
+
+// extra returns the data of the signed extensions included in the extrinsic: era, nonce, tip and the mode of
+// CheckMetadataHash (disabled)
+func (tx Tx) extra() []byte {
+	extra := []byte{0x00}
+	if tx.Input.EraPeriod > 0 {
+		extra = encodeMortalEra(tx.Input.EraPeriod, tx.Input.BlockNumber)
+	}
+	extra = append(extra, encodeCompactUint64(tx.Input.Nonce)...)
+	extra = append(extra, encodeCompactUint64(tx.Input.Tip)...)
+	if tx.CheckMetadataHash {
+		extra = append(extra, 0x00)
+	}
+	return extra
+}
+
+// additional returns the data of the signed extensions only signed: the runtime versions, the genesis hash, the
+// block hash the era starts at (the genesis hash for immortal txs) and the metadata hash (none)
+func (tx Tx) additional() []byte {
+	additional := encodeUint32(tx.Input.SpecVersion)
+	additional = append(additional, encodeUint32(tx.Input.TransactionVersion)...)
+	additional = append(additional, tx.Input.GenesisHash...)
+	if tx.Input.EraPeriod > 0 {
+		additional = append(additional, tx.Input.BlockHash...)
+	} else {
+		additional = append(additional, tx.Input.GenesisHash...)
+	}
+	if tx.CheckMetadataHash {
+		additional = append(additional, 0x00)
+	}
+	return additional
+}
+
+func extrinsicHash(extrinsic []byte) xc.TxHash {
+	hash := blake2b.Sum256(extrinsic)
+	return xc.TxHash("0x" + hex.EncodeToString(hash[:]))
+}
+
+// extrinsic is a decoded extrinsic, signed or not
+type extrinsic struct {
+	Signed bool
+	// Signer is the account id of signed extrinsics
+	Signer []byte
+	// Call is the call index followed by the arguments
+	Call []byte
+}
+
+// decodeExtrinsic decodes an extrinsic prefixed with its length
+// The signed extensions aren't described by the extrinsic: the ones of Tx are expected
+func decodeExtrinsic(data []byte, checkMetadataHash bool) (extrinsic, error) {
+	res := extrinsic{}
+	reader := bytes.NewReader(data)
+	length, err := decodeCompact(reader)
+	if err != nil || !length.IsInt64() || length.Int64() != int64(reader.Len()) {
+		return res, errTruncated
+	}
+	version, err := reader.ReadByte()
+	if err != nil {
+		return res, errTruncated
+	}
+	if version&^signedBit != extrinsicVersion {
+		return res, fmt.Errorf("unsupported extrinsic version %d", version&^signedBit)
+	}
+	if version&signedBit != 0 {
+		res.Signed = true
+		addressType, err := reader.ReadByte()
+		if err != nil {
+			return res, errTruncated
+		}
+		if addressType != multiAddressID {
+			return res, fmt.Errorf("unsupported signer address type %d", addressType)
+		}
+		res.Signer = make([]byte, 32)
+		if _, err := io.ReadFull(reader, res.Signer); err != nil {
+			return res, errTruncated
+		}
+		signatureType, err := reader.ReadByte()
+		if err != nil {
+			return res, errTruncated
+		}
+		signatureLength := int64(64)
+		if signatureType == multiSignatureECDSA {
+			signatureLength = 65
+		}
+		if _, err := reader.Seek(signatureLength, io.SeekCurrent); err != nil {
+			return res, errTruncated
+		}
+		if err := skipEra(reader); err != nil {
+			return res, errTruncated
+		}
+		// nonce and tip
+		for i := 0; i < 2; i++ {
+			if _, err := decodeCompact(reader); err != nil {
+				return res, errTruncated
+			}
+		}
+		if checkMetadataHash {
+			if _, err := reader.ReadByte(); err != nil {
+				return res, errTruncated
+			}
+		}
+	}
+	res.Call = make([]byte, reader.Len())
+	_, _ = reader.Read(res.Call)
+	if len(res.Call) < 2 {
+		return res, errTruncated
+	}
+	return res, nil
+}
@@ -0,0 +1,129 @@
+package substrate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func (s *CrosschainTestSuite) TestCompact() {
+	require := s.Require()
+	vectors := []struct {
+		value   string
+		encoded string
+	}{
+		{"0", "00"},
+		{"1", "04"},
+		{"42", "a8"},
+		{"69", "1501"},
+		{"65535", "feff0300"},
+		{"1073741823", "feffffff"},
+		{"1073741824", "0300000040"},
+		{"100000000000000", "0b00407a10f35a"},
+	}
+	for _, v := range vectors {
+		value, _ := new(big.Int).SetString(v.value, 10)
+		require.Equal(v.encoded, hex.EncodeToString(encodeCompact(value)))
+		encoded, _ := hex.DecodeString(v.encoded)
+		decoded, err := decodeCompact(bytes.NewReader(encoded))
+		require.NoError(err)
+		require.Equal(v.value, decoded.String())
+	}
+}
+
+func (s *CrosschainTestSuite) TestMortalEra() {
+	require := s.Require()
+	require.Equal("a502", hex.EncodeToString(encodeMortalEra(64, 42)))
+	// periods are rounded to a power of two
+	require.Equal("a502", hex.EncodeToString(encodeMortalEra(50, 42)))
+	require.Equal("0100", hex.EncodeToString(encodeMortalEra(1, 0)))
+}
+
+func newTestTransfer(s *CrosschainTestSuite, asset *xc.NativeAssetConfig) *Tx {
+	require := s.Require()
+	builder, _ := NewTxBuilder(asset)
+	input := NewTxInput()
+	input.GenesisHash = bytes.Repeat([]byte{0x91}, 32)
+	input.BlockHash = bytes.Repeat([]byte{0x22}, 32)
+	input.BlockNumber = 42
+	input.EraPeriod = 64
+	input.Nonce = 5
+	input.Tip = 0
+	input.SpecVersion = 1002000
+	input.TransactionVersion = 26
+	from := EncodeSS58(0, mustDecodeHex(alicePublicKey))
+	to := EncodeSS58(0, bytes.Repeat([]byte{0x8e}, 32))
+	tx, err := builder.NewTransfer(from, to, xc.NewAmountBlockchainFromUint64(12345), input)
+	require.NoError(err)
+	return tx.(*Tx)
+}
+
+func (s *CrosschainTestSuite) TestNewTransfer() {
+	require := s.Require()
+	tx := newTestTransfer(s, &xc.NativeAssetConfig{NativeAsset: xc.DOT})
+	to := hex.EncodeToString(bytes.Repeat([]byte{0x8e}, 32))
+	// Balances.transfer_keep_alive, MultiAddress::Id and the compact amount
+	require.Equal("050300"+to+"e5c0", hex.EncodeToString(tx.Call))
+
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Len(sighashes, 1)
+	genesis := hex.EncodeToString(bytes.Repeat([]byte{0x91}, 32))
+	block := hex.EncodeToString(bytes.Repeat([]byte{0x22}, 32))
+	// call, era, nonce, tip, metadata hash mode, spec and tx versions, genesis, era block, no metadata hash
+	require.Equal("050300"+to+"e5c0"+"a502"+"14"+"00"+"00"+"104a0f00"+"1a000000"+genesis+block+"00",
+		hex.EncodeToString(sighashes[0]))
+	require.Equal(xc.TxHash(""), tx.Hash())
+	_, err = tx.Serialize()
+	require.EqualError(err, "unable to serialize without first calling AddSignatures(...)")
+
+	signature := bytes.Repeat([]byte{0x55}, 64)
+	require.NoError(tx.AddSignatures(signature))
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	body := "84" + "00" + alicePublicKey + "01" + hex.EncodeToString(signature) + "a502" + "14" + "00" + "00" + "050300" + to + "e5c0"
+	require.Equal(hex.EncodeToString(encodeCompactUint64(uint64(len(body)/2)))+body, hex.EncodeToString(serialized))
+	require.Len(string(tx.Hash()), 66)
+
+	decoded, err := decodeExtrinsic(serialized, true)
+	require.NoError(err)
+	require.True(decoded.Signed)
+	require.Equal(alicePublicKey, hex.EncodeToString(decoded.Signer))
+	require.Equal(tx.Call, decoded.Call)
+	dest, amount, ok := parseTransferCall(decoded.Call, "0x0503")
+	require.True(ok)
+	require.Equal(to, hex.EncodeToString(dest))
+	require.Equal("12345", amount.String())
+
+	require.EqualError(tx.AddSignatures(signature, signature), "expecting 1 signature")
+	require.EqualError(tx.AddSignatures(signature[:10]), "invalid signature length 10")
+}
+
+func (s *CrosschainTestSuite) TestNewTransferOfParachain() {
+	require := s.Require()
+	// ed25519 keys and a runtime without CheckMetadataHash
+	asset := &xc.NativeAssetConfig{NativeAsset: "PARA", Substrate: xc.SubstrateConfig{
+		TransferCallIndex: "0x0a03",
+		KeyType:           xc.Ed255,
+	}}
+	tx := newTestTransfer(s, asset)
+	require.Equal("0a03", hex.EncodeToString(tx.Call[:2]))
+	sighashes, _ := tx.Sighashes()
+	require.Len(sighashes[0], len(tx.Call)+2+1+1+4+4+32+32)
+	require.NoError(tx.AddSignatures(bytes.Repeat([]byte{0x55}, 64)))
+	serialized, _ := tx.Serialize()
+	decoded, err := decodeExtrinsic(serialized, false)
+	require.NoError(err)
+	require.Equal(tx.Call, decoded.Call)
+	require.Equal(byte(multiSignatureEd255), tx.signature[0])
+
+	// chains without a transfer call index can't transfer
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: "PARA"})
+	_, err = builder.NewTransfer("", "", xc.NewAmountBlockchainFromUint64(1), NewTxInput())
+	require.EqualError(err, "missing transfer_call_index of substrate chain")
+	builder, _ = NewTxBuilder(&xc.TokenAssetConfig{Asset: "USDT", Chain: "DOT"})
+	_, err = builder.NewTransfer("", "", xc.NewAmountBlockchainFromUint64(1), NewTxInput())
+	require.EqualError(err, "token transfers are not supported on substrate chains")
+}
@@ -0,0 +1,281 @@
+package substrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	xc "github.com/jumpcrypto/crosschain"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Calls of XcmPallet
+const (
+	xcmLimitedReserveTransferAssets = 0x08
+	xcmLimitedTeleportAssets        = 0x09
+)
+
+// Versions of the XCM locations and assets, and the variants used by transfers from relay chains
+const (
+	xcmVersion3          = 0x03
+	xcmVersion4          = 0x04
+	xcmJunctionsHere     = 0x00
+	xcmJunctionsX1       = 0x01
+	xcmJunctionParachain = 0x00
+	xcmJunctionAccountID = 0x01
+	xcmAssetIDConcrete   = 0x00
+	xcmFungible          = 0x00
+	xcmWeightUnlimited   = 0x00
+)
+
+// tokensAccountsPrefix is the storage prefix of Tokens.Accounts of orml: twox128("Tokens") ++ twox128("Accounts")
+var tokensAccountsPrefix = mustDecodeHex("99971b5749ac43e0235e41b0d37869188ee7418a6531173d60d1f6a82d8f4d51")
+
+// xcmTransfer is a transfer of the native asset of a relay chain to a parachain
+type xcmTransfer struct {
+	Type        xc.XCMTransferType
+	ParaID      uint32
+	Beneficiary []byte
+	Amount      *big.Int
+}
+
+var _ xc.TxXCMBuilder = &TxBuilder{}
+var _ xc.ClientXCM = &Client{}
+
+// NewXCMTransfer creates a new XcmPallet.limited_reserve_transfer_assets or limited_teleport_assets extrinsic,
+// depending on the destination, the weight of the execution on the destination unlimited
+func (txBuilder TxBuilder) NewXCMTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, destination xc.NativeAsset, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		return nil, errors.New("xcm transfers of tokens are not supported on substrate chains")
+	}
+	localInput, err := parseTxInput(input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	nativeAsset := txBuilder.Asset.GetNativeAsset()
+	substrate := nativeAsset.GetSubstrate()
+	route, ok := substrate.XCM[destination]
+	if !ok {
+		return &Tx{}, fmt.Errorf("no xcm route from %s to %s", nativeAsset.NativeAsset, destination)
+	}
+	callIndex, err := xcmCallIndex(substrate.XCMPalletIndex, route.Type)
+	if err != nil {
+		return &Tx{}, err
+	}
+	_, fromID, err := DecodeSS58(from)
+	if err != nil {
+		return &Tx{}, fmt.Errorf("invalid from address '%s': %v", from, err)
+	}
+	_, toID, err := DecodeSS58(to)
+	if err != nil {
+		return &Tx{}, fmt.Errorf("invalid to address '%s': %v", to, err)
+	}
+	if amount.Int().Sign() <= 0 {
+		return &Tx{}, errors.New("invalid amount: xcm transfers need a positive amount")
+	}
+
+	call := append(callIndex, encodeXCMTransfer(route.ParaID, toID, amount.Int())...)
+	return &Tx{
+		Input:             localInput,
+		From:              fromID,
+		Call:              call,
+		KeyType:           substrate.KeyType,
+		CheckMetadataHash: *substrate.CheckMetadataHash,
+	}, nil
+}
+
+// xcmCallIndex returns the call index of the transfers of a type
+func xcmCallIndex(palletIndex string, transferType xc.XCMTransferType) ([]byte, error) {
+	if palletIndex == "" {
+		return nil, errors.New("missing xcm_pallet_index of substrate chain")
+	}
+	pallet, err := hex.DecodeString(strings.TrimPrefix(palletIndex, "0x"))
+	if err != nil || len(pallet) != 1 {
+		return nil, fmt.Errorf("invalid pallet index '%s': expected 1 byte of hex", palletIndex)
+	}
+	switch transferType {
+	case xc.XCMReserve:
+		return []byte{pallet[0], xcmLimitedReserveTransferAssets}, nil
+	case xc.XCMTeleport:
+		return []byte{pallet[0], xcmLimitedTeleportAssets}, nil
+	}
+	return nil, fmt.Errorf("unsupported xcm transfer type '%s'", transferType)
+}
+
+// encodeXCMTransfer returns the V3 arguments of a transfer to an account of a parachain: the destination, the
+// beneficiary, the assets, the index of the fee asset and the weight limit
+func encodeXCMTransfer(paraID uint32, beneficiary []byte, amount *big.Int) []byte {
+	args := []byte{xcmVersion3, 0, xcmJunctionsX1, xcmJunctionParachain}
+	args = append(args, encodeCompactUint64(uint64(paraID))...)
+	// the network of the account isn't set
+	args = append(args, xcmVersion3, 0, xcmJunctionsX1, xcmJunctionAccountID, 0)
+	args = append(args, beneficiary...)
+	// a single asset: the native asset of the relay chain
+	args = append(args, xcmVersion3)
+	args = append(args, encodeCompactUint64(1)...)
+	args = append(args, xcmAssetIDConcrete, 0, xcmJunctionsHere, xcmFungible)
+	args = append(args, encodeCompact(amount)...)
+	args = append(args, encodeUint32(0)...)
+	return append(args, xcmWeightUnlimited)
+}
+
+// parseXCMTransferCall returns the transfer of the XCM transfer calls of XcmPallet, in V3 or V4, to an account of a
+// parachain
+func parseXCMTransferCall(call []byte, palletIndex string) (*xcmTransfer, bool) {
+	transfer := &xcmTransfer{}
+	pallet, err := hex.DecodeString(strings.TrimPrefix(palletIndex, "0x"))
+	if err != nil || len(pallet) != 1 || len(call) < 2 || call[0] != pallet[0] {
+		return nil, false
+	}
+	switch call[1] {
+	case xcmLimitedReserveTransferAssets:
+		transfer.Type = xc.XCMReserve
+	case xcmLimitedTeleportAssets:
+		transfer.Type = xc.XCMTeleport
+	default:
+		return nil, false
+	}
+	reader := bytes.NewReader(call[2:])
+	expect := func(expected ...byte) bool {
+		for _, b := range expected {
+			if next, err := reader.ReadByte(); err != nil || next != b {
+				return false
+			}
+		}
+		return true
+	}
+
+	// destination
+	version, err := reader.ReadByte()
+	if err != nil || (version != xcmVersion3 && version != xcmVersion4) {
+		return nil, false
+	}
+	if !expect(0, xcmJunctionsX1, xcmJunctionParachain) {
+		return nil, false
+	}
+	paraID, err := decodeCompact(reader)
+	if err != nil || !paraID.IsUint64() || paraID.Uint64() > 1<<32-1 {
+		return nil, false
+	}
+	transfer.ParaID = uint32(paraID.Uint64())
+
+	// beneficiary
+	if !expect(version, 0, xcmJunctionsX1, xcmJunctionAccountID) {
+		return nil, false
+	}
+	if network, err := reader.ReadByte(); err != nil || network != 0 {
+		return nil, false
+	}
+	transfer.Beneficiary = make([]byte, 32)
+	if _, err := io.ReadFull(reader, transfer.Beneficiary); err != nil {
+		return nil, false
+	}
+
+	// a single asset, the native asset, whose id is concrete in V3
+	if !expect(version, 0x04) {
+		return nil, false
+	}
+	if version == xcmVersion3 && !expect(xcmAssetIDConcrete) {
+		return nil, false
+	}
+	if !expect(0, xcmJunctionsHere, xcmFungible) {
+		return nil, false
+	}
+	if transfer.Amount, err = decodeCompact(reader); err != nil {
+		return nil, false
+	}
+	return transfer, true
+}
+
+// FetchXCMBalance returns the free balance of address on destination of the asset transferred there by XCM, from the
+// Tokens.Accounts storage of the destination
+func (client *Client) FetchXCMBalance(ctx context.Context, address xc.Address, destination xc.NativeAsset) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	nativeAsset := client.Asset.GetNativeAsset()
+	route, ok := nativeAsset.GetSubstrate().XCM[destination]
+	if !ok {
+		return zero, fmt.Errorf("no xcm route from %s to %s", nativeAsset.NativeAsset, destination)
+	}
+	if route.URL == "" || route.CurrencyID == "" {
+		return zero, fmt.Errorf("xcm destination %s needs a url and a currency_id to track transfers", destination)
+	}
+	currencyID, err := hexutil.Decode(route.CurrencyID)
+	if err != nil {
+		return zero, fmt.Errorf("invalid currency_id '%s': %v", route.CurrencyID, err)
+	}
+	_, accountID, err := DecodeSS58(address)
+	if err != nil {
+		return zero, fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	rpcClient, err := client.dialXCMDestination(route.URL)
+	if err != nil {
+		return zero, err
+	}
+	defer rpcClient.Close()
+
+	key := tokensAccountKey(accountID, currencyID)
+	var storage *string
+	if err := rpcClient.CallContext(ctx, &storage, "state_getStorage", hexutil.Encode(key)); err != nil {
+		return zero, err
+	}
+	if storage == nil {
+		return zero, nil
+	}
+	data, err := hexutil.Decode(*storage)
+	// AccountData: free, reserved and frozen (u128)
+	if err != nil || len(data) < 16 {
+		return zero, fmt.Errorf("invalid tokens storage of '%s' on %s", address, destination)
+	}
+	return xc.AmountBlockchain(*new(big.Int).SetBytes(reverse(data[:16]))), nil
+}
+
+// FetchXCMArrival returns the amount received by address on destination since its balance was before, zero until a
+// transfer arrives
+// Other transfers to address are counted as well: before is expected to be fetched right before the transfer
+func (client *Client) FetchXCMArrival(ctx context.Context, address xc.Address, destination xc.NativeAsset, before xc.AmountBlockchain) (xc.AmountBlockchain, error) {
+	balance, err := client.FetchXCMBalance(ctx, address, destination)
+	if err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), err
+	}
+	if balance.Cmp(&before) <= 0 {
+		return xc.NewAmountBlockchainFromUint64(0), nil
+	}
+	return balance.Sub(&before), nil
+}
+
+func (client *Client) dialXCMDestination(url string) (*rpc.Client, error) {
+	transport, err := client.Asset.GetNativeAsset().HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	rpcClient, err := rpc.DialHTTPWithClient(url, &http.Client{Transport: transport})
+	if err != nil {
+		return nil, fmt.Errorf("dialing url: %v", url)
+	}
+	return rpcClient, nil
+}
+
+// tokensAccountKey returns the storage key of the balance of an account in a currency: the blake2_128_concat of the
+// account id and the twox64_concat of the currency id
+func tokensAccountKey(accountID []byte, currencyID []byte) []byte {
+	hasher, _ := blake2b.New(16, nil)
+	hasher.Write(accountID)
+	key := append(append([]byte{}, tokensAccountsPrefix...), hasher.Sum(nil)...)
+	key = append(key, accountID...)
+	currencyHash := make([]byte, 8)
+	binary.LittleEndian.PutUint64(currencyHash, xxhash.Sum64(currencyID))
+	return append(append(key, currencyHash...), currencyID...)
+}
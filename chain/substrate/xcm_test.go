@@ -0,0 +1,177 @@
+package substrate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+func newTestXCMTransfer(s *CrosschainTestSuite, asset *xc.NativeAssetConfig, destination xc.NativeAsset) *Tx {
+	require := s.Require()
+	builder := TxBuilder{Asset: asset}
+	input := NewTxInput()
+	input.GenesisHash = bytes.Repeat([]byte{0x91}, 32)
+	input.BlockHash = bytes.Repeat([]byte{0x22}, 32)
+	input.BlockNumber = 42
+	input.EraPeriod = 64
+	input.Nonce = 5
+	from := EncodeSS58(0, mustDecodeHex(alicePublicKey))
+	to := EncodeSS58(10, bytes.Repeat([]byte{0x8e}, 32))
+	tx, err := builder.NewXCMTransfer(from, to, xc.NewAmountBlockchainFromUint64(10_000_000_000), destination, input)
+	require.NoError(err)
+	return tx.(*Tx)
+}
+
+func (s *CrosschainTestSuite) TestNewXCMTransfer() {
+	require := s.Require()
+	to := hex.EncodeToString(bytes.Repeat([]byte{0x8e}, 32))
+
+	// DOT to Acala, a reserve transfer
+	tx := newTestXCMTransfer(s, &xc.NativeAssetConfig{NativeAsset: xc.DOT}, xc.ACA)
+	// XcmPallet.limited_reserve_transfer_assets: V3 destination (Parachain(2000)), beneficiary (AccountId32), assets
+	// (1 DOT), fee asset 0 and unlimited weight
+	require.Equal("6308"+"03000100411f"+"0300010100"+to+"030400000000"+"0700e40b5402"+"00000000"+"00", hex.EncodeToString(tx.Call))
+	require.NoError(tx.AddSignatures(bytes.Repeat([]byte{0x55}, 64)))
+	serialized, _ := tx.Serialize()
+	decoded, err := decodeExtrinsic(serialized, true)
+	require.NoError(err)
+	transfer, ok := parseXCMTransferCall(decoded.Call, "0x63")
+	require.True(ok)
+	require.Equal(xc.XCMReserve, transfer.Type)
+	require.EqualValues(2000, transfer.ParaID)
+	require.Equal(to, hex.EncodeToString(transfer.Beneficiary))
+	require.Equal("10000000000", transfer.Amount.String())
+
+	// KSM to Karura
+	tx = newTestXCMTransfer(s, &xc.NativeAssetConfig{NativeAsset: xc.KSM}, xc.KAR)
+	require.Equal("6308", hex.EncodeToString(tx.Call[:2]))
+
+	// teleports to a configured system parachain
+	asset := &xc.NativeAssetConfig{NativeAsset: xc.DOT, Substrate: xc.SubstrateConfig{
+		XCM: map[xc.NativeAsset]xc.XCMDestination{"DOTAH": {ParaID: 1000, Type: xc.XCMTeleport}},
+	}}
+	tx = newTestXCMTransfer(s, asset, "DOTAH")
+	require.Equal("6309"+"03000100a10f", hex.EncodeToString(tx.Call[:8]))
+	transfer, ok = parseXCMTransferCall(tx.Call, "0x63")
+	require.True(ok)
+	require.Equal(xc.XCMTeleport, transfer.Type)
+	require.EqualValues(1000, transfer.ParaID)
+
+	// V4 calls, whose asset ids aren't concrete
+	v4 := "6308" + "04000100411f" + "0400010100" + to + "0404000000" + "0700e40b5402" + "00000000" + "00"
+	transfer, ok = parseXCMTransferCall(mustDecodeHex(v4), "0x63")
+	require.True(ok)
+	require.EqualValues(2000, transfer.ParaID)
+	require.Equal("10000000000", transfer.Amount.String())
+
+	// other calls
+	_, ok = parseXCMTransferCall(mustDecodeHex("050300"+to+"e5c0"), "0x63")
+	require.False(ok)
+	_, ok = parseXCMTransferCall(mustDecodeHex(v4[:20]), "0x63")
+	require.False(ok)
+}
+
+func (s *CrosschainTestSuite) TestNewXCMTransferErrors() {
+	require := s.Require()
+	from := EncodeSS58(0, mustDecodeHex(alicePublicKey))
+	to := EncodeSS58(10, bytes.Repeat([]byte{0x8e}, 32))
+	amount := xc.NewAmountBlockchainFromUint64(1)
+
+	builder := TxBuilder{Asset: &xc.NativeAssetConfig{NativeAsset: xc.DOT}}
+	_, err := builder.NewXCMTransfer(from, to, amount, xc.KAR, NewTxInput())
+	require.EqualError(err, "no xcm route from DOT to KAR")
+	_, err = builder.NewXCMTransfer(from, "0x01", amount, xc.ACA, NewTxInput())
+	require.ErrorContains(err, "invalid to address")
+	_, err = builder.NewXCMTransfer(from, to, xc.NewAmountBlockchainFromUint64(0), xc.ACA, NewTxInput())
+	require.EqualError(err, "invalid amount: xcm transfers need a positive amount")
+	_, err = builder.NewXCMTransfer(from, to, amount, xc.ACA, &xc.NativeAssetConfig{})
+	require.EqualError(err, "xc.TxInput is not from a substrate chain")
+
+	// parachains need the index of XcmPallet
+	builder = TxBuilder{Asset: &xc.NativeAssetConfig{NativeAsset: "PARA", Substrate: xc.SubstrateConfig{
+		XCM: map[xc.NativeAsset]xc.XCMDestination{"OTHER": {ParaID: 2001}},
+	}}}
+	_, err = builder.NewXCMTransfer(from, to, amount, "OTHER", NewTxInput())
+	require.EqualError(err, "missing xcm_pallet_index of substrate chain")
+	builder = TxBuilder{Asset: &xc.NativeAssetConfig{NativeAsset: xc.DOT, Substrate: xc.SubstrateConfig{
+		XCM: map[xc.NativeAsset]xc.XCMDestination{"OTHER": {ParaID: 2001, Type: "bridge"}},
+	}}}
+	_, err = builder.NewXCMTransfer(from, to, amount, "OTHER", NewTxInput())
+	require.EqualError(err, "unsupported xcm transfer type 'bridge'")
+
+	builder = TxBuilder{Asset: &xc.TokenAssetConfig{Asset: "USDT", Chain: "DOT"}}
+	_, err = builder.NewXCMTransfer(from, to, amount, xc.ACA, NewTxInput())
+	require.EqualError(err, "xcm transfers of tokens are not supported on substrate chains")
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfoXCMTransfer() {
+	require := s.Require()
+	tx := newTestXCMTransfer(s, &xc.NativeAssetConfig{NativeAsset: xc.DOT}, xc.ACA)
+	require.NoError(tx.AddSignatures(make([]byte, 64)))
+	serialized, _ := tx.Serialize()
+	txBlock := fmt.Sprintf(`{"block":{"header":{"parentHash":"0xcc","number":"0x64"},"extrinsics":["%s"]}}`, hexutil.Encode(serialized))
+
+	server, close := test.MockJSONRPC(&s.Suite, []string{
+		`"0xaa"`,
+		`{"parentHash":"0xbb","number":"0x66"}`,
+		`"0xbb"`,
+		txBlock,
+		`{"partialFee":"156000000"}`,
+	})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.DOT, URL: server.URL})
+
+	info, err := client.FetchTxInfo(s.Ctx, "100-0")
+	require.NoError(err)
+	require.Equal(string(tx.Hash()), info.TxID)
+	require.Equal(EncodeSS58(10, bytes.Repeat([]byte{0x8e}, 32)), info.To)
+	require.Equal("10000000000", info.Amount.String())
+	require.Len(info.Destinations, 1)
+	require.Equal(xc.ACA, info.Destinations[0].NativeAsset)
+	require.Equal(xc.Asset(xc.DOT), info.Destinations[0].Asset)
+	require.Equal(xc.DOT, info.Sources[0].NativeAsset)
+}
+
+func (s *CrosschainTestSuite) TestFetchXCMBalance() {
+	require := s.Require()
+	// Tokens.Accounts of Alice in Token(DOT)
+	key := tokensAccountKey(mustDecodeHex(alicePublicKey), []byte{0x00, 0x02})
+	require.Equal("99971b5749ac43e0235e41b0d37869188ee7418a6531173d60d1f6a82d8f4d51"+
+		"de1e86a9a8c739864cf3cc5ec2bea59f"+alicePublicKey+"c483de2de1246ea7"+"0002", hex.EncodeToString(key))
+
+	// a free balance of 12345
+	accountData := "39300000000000000000000000000000" + "00000000000000000000000000000000" + "00000000000000000000000000000000"
+	server, close := test.MockJSONRPC(&s.Suite, []string{`"0x` + accountData + `"`, `null`, `"0x` + accountData + `"`})
+	defer close()
+	asset := &xc.NativeAssetConfig{NativeAsset: xc.DOT, Substrate: xc.SubstrateConfig{
+		XCM: map[xc.NativeAsset]xc.XCMDestination{xc.ACA: {URL: server.URL}},
+	}}
+	client, _ := NewClient(asset)
+	alice := EncodeSS58(10, mustDecodeHex(alicePublicKey))
+
+	balance, err := client.FetchXCMBalance(s.Ctx, alice, xc.ACA)
+	require.NoError(err)
+	require.Equal("12345", balance.String())
+	balance, err = client.FetchXCMBalance(s.Ctx, alice, xc.ACA)
+	require.NoError(err)
+	require.Equal("0", balance.String())
+
+	arrived, err := client.FetchXCMArrival(s.Ctx, alice, xc.ACA, xc.NewAmountBlockchainFromUint64(345))
+	require.NoError(err)
+	require.Equal("12000", arrived.String())
+	server.Counter = 0
+	arrived, err = client.FetchXCMArrival(s.Ctx, alice, xc.ACA, xc.NewAmountBlockchainFromUint64(12345))
+	require.NoError(err)
+	require.Equal("0", arrived.String())
+
+	// destinations need a url
+	client, _ = NewClient(&xc.NativeAssetConfig{NativeAsset: xc.DOT})
+	_, err = client.FetchXCMBalance(s.Ctx, alice, xc.ACA)
+	require.EqualError(err, "xcm destination ACA needs a url and a currency_id to track transfers")
+	_, err = client.FetchXCMBalance(s.Ctx, alice, xc.KAR)
+	require.EqualError(err, "no xcm route from DOT to KAR")
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 
 	xc "github.com/jumpcrypto/crosschain"
 	"golang.org/x/crypto/blake2b"
@@ -19,6 +20,7 @@ type AddressBuilder struct {
 }
 
 var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
 
 // NewAddressBuilder creates a new Template AddressBuilder
 func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
@@ -53,3 +55,18 @@ func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []b
 		},
 	}, err
 }
+
+// ValidateAddress checks an address is 0x and up to 32 bytes of hex, leading zeros may be omitted as in 0x2
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	str := string(address)
+	if !strings.HasPrefix(str, "0x") || len(str) == 2 || len(str) > 2+ADDRESS_LENGTH {
+		return fmt.Errorf("invalid address '%s': expected 0x and up to 32 bytes of hex", address)
+	}
+	if len(str)%2 != 0 {
+		str = "0x0" + str[2:]
+	}
+	if _, err := hex.DecodeString(str[2:]); err != nil {
+		return fmt.Errorf("invalid address '%s': not hex", address)
+	}
+	return nil
+}
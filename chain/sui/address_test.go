@@ -55,3 +55,18 @@ func (s *CrosschainTestSuite) TestGetAllPossibleAddressesFromPublicKey() {
 	require.Equal(xc.Address("0x086d8e59c3ef72ccc8cbf74c55e7f611b0ee9eba788c7153924c4e4a32449a8e"), addresses[0].Address)
 	require.Equal(xc.AddressTypeDefault, addresses[0].Type)
 }
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.AssetConfig{})
+	validator := builder.(xc.AddressValidator)
+	require.NoError(validator.ValidateAddress("0x086d8e59c3ef72ccc8cbf74c55e7f611b0ee9eba788c7153924c4e4a32449a8e"))
+	// leading zeros omitted
+	require.NoError(validator.ValidateAddress("0x1"))
+	require.NoError(validator.ValidateAddress("0x2"))
+
+	require.EqualError(validator.ValidateAddress("086d8e59c3ef72ccc8cbf74c55e7f611b0ee9eba788c7153924c4e4a32449a8e"), "invalid address '086d8e59c3ef72ccc8cbf74c55e7f611b0ee9eba788c7153924c4e4a32449a8e': expected 0x and up to 32 bytes of hex")
+	require.EqualError(validator.ValidateAddress("0x086d8e59c3ef72ccc8cbf74c55e7f611b0ee9eba788c7153924c4e4a32449a8e00"), "invalid address '0x086d8e59c3ef72ccc8cbf74c55e7f611b0ee9eba788c7153924c4e4a32449a8e00': expected 0x and up to 32 bytes of hex")
+	require.EqualError(validator.ValidateAddress("0x1z"), "invalid address '0x1z': not hex")
+	require.Error(validator.ValidateAddress("0x"))
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/coming-chat/go-sui/client"
 	"github.com/coming-chat/go-sui/types"
@@ -21,7 +23,18 @@ type Client struct {
 // NewClient returns a new Aptos Client
 func NewClient(cfgI xc.ITask) (*Client, error) {
 	cfg := cfgI.GetNativeAsset()
-	client, err := client.Dial(cfg.URL)
+	// same defaults as client.Dial
+	transport, err := cfg.HTTPTransport(&http.Transport{
+		MaxIdleConns:    3,
+		IdleConnTimeout: 30 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := client.DialWithClient(cfg.URL, &http.Client{
+		Transport: transport,
+		Timeout:   30 * time.Second,
+	})
 	return &Client{
 		Asset:     cfgI,
 		SuiClient: client,
@@ -171,7 +184,7 @@ func (c *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo,
 		BlockIndex:    resp.Checkpoint.Int64(),
 		Confirmations: int64(latestCheckpoint.GetSequenceNumber()) - int64(txCheckpoint.GetSequenceNumber()),
 
-		ExplorerURL:  fmt.Sprintf("https://explorer.sui.io/txblock/%s?network=%s", resp.Digest, c.Asset.GetAssetConfig().Net),
+		ExplorerURL:  fmt.Sprintf("https://explorer.sui.io/txblock/%s?network=%s", resp.Digest, c.Asset.GetAssetConfig().Net.Kind()),
 		Sources:      sources,
 		Destinations: destinations,
 		Error:        resp.Effects.Data.V1.Status.Error,
@@ -183,7 +196,16 @@ func (c *Client) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc) {
 	c.EstimateGasFunc = estimateGas
 }
 
+// EstimateGas estimates the gas price, floored to the configured min gas price
 func (c *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	gasPrice, err := c.estimateGas(ctx)
+	if err != nil {
+		return xc.NewAmountBlockchainFromUint64(0), err
+	}
+	return gasPrice.ApplyFloor(xc.NewAmountBlockchainFromUint64(uint64(c.Asset.GetNativeAsset().MinGasPrice))), nil
+}
+
+func (c *Client) estimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
 	if c.EstimateGasFunc != nil {
 		nativeAsset := c.Asset.GetNativeAsset().NativeAsset
 		res, err := c.EstimateGasFunc(nativeAsset)
@@ -293,6 +315,9 @@ func (c *Client) FetchTxInput(ctx context.Context, from xc.Address, to xc.Addres
 
 // SubmitTx submits a Sui tx
 func (c *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(c.Asset); err != nil {
+		return err
+	}
 	tx_bz, err := tx.Serialize()
 	if err != nil {
 		return err
@@ -307,6 +332,16 @@ func (c *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
 	for _, sig := range sigs {
 		sigsB64 = append(sigsB64, types.Base64Data(sig))
 	}
+	if xc.IsDryRun(ctx, c.Asset) {
+		resp, err := c.SuiClient.DryRunTransaction(ctx, &types.TransactionBytes{TxBytes: types.Base64Data(tx_bz)})
+		if err != nil {
+			return err
+		}
+		if resp.Effects.Data.V1 != nil && !resp.Effects.Data.IsSuccess() {
+			return fmt.Errorf("dry run failed: %s", resp.Effects.Data.V1.Status.Error)
+		}
+		return xc.RecordDryRun(ctx, c.Asset, tx, true)
+	}
 
 	newTxn, err := c.SuiClient.ExecuteTransactionBlock(
 		ctx,
@@ -766,3 +766,15 @@ func (s *CrosschainTestSuite) TestFetchBalance() {
 		require.EqualValues(v.amount, bal.ToHuman(9).String())
 	}
 }
+
+func (s *CrosschainTestSuite) TestEstimateGasMinGasPrice() {
+	require := s.Require()
+	client, err := NewClient(&xc.AssetConfig{NativeAsset: xc.SUI, MinGasPrice: 1000})
+	require.NoError(err)
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(1), nil
+	})
+	gasPrice, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("1000", gasPrice.String())
+}
@@ -97,9 +97,7 @@ func (input *TxInput) IsNativeTransfer() bool {
 
 func NewTxInput() *TxInput {
 	return &TxInput{
-		TxInputEnvelope: xc.TxInputEnvelope{
-			Type: xc.DriverSui,
-		},
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverSui),
 	}
 }
 
@@ -30,3 +30,8 @@ func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []b
 		},
 	}, err
 }
+
+// ValidateAddress checks the format and checksum of an address
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	return errors.New("not implemented")
+}
@@ -24,3 +24,8 @@ func (signer Signer) ImportPrivateKey(privateKey string) (xc.PrivateKey, error)
 func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
 	return xc.TxSignature([]byte{}), errors.New("not implemented")
 }
+
+// DerivePublicKey returns the public key of a Template private key
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	return xc.PublicKey([]byte{}), errors.New("not implemented")
+}
@@ -0,0 +1,97 @@
+package tron
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// addressPrefix is the first byte of the addresses of Tron mainnet and testnets
+const addressPrefix = 0x41
+
+// addressLength is the length of addresses: the prefix and an EVM address
+const addressLength = 21
+
+// AddressBuilder for Tron
+type AddressBuilder struct {
+}
+
+var _ xc.AddressBuilder = &AddressBuilder{}
+var _ xc.AddressValidator = &AddressBuilder{}
+
+// NewAddressBuilder creates a new Tron AddressBuilder
+func NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
+	return AddressBuilder{}, nil
+}
+
+// GetAddressFromPublicKey returns the base58 address of a k256 public key, compressed or not: the address of
+// Ethereum with the Tron prefix
+func (ab AddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (xc.Address, error) {
+	var publicKey *ecdsa.PublicKey
+	var err error
+	if len(publicKeyBytes) == 33 {
+		publicKey, err = crypto.DecompressPubkey(publicKeyBytes)
+	} else {
+		publicKey, err = crypto.UnmarshalPubkey(publicKeyBytes)
+	}
+	if err != nil {
+		return xc.Address(""), errors.New("invalid k256 public key")
+	}
+	return EncodeAddress(append([]byte{addressPrefix}, crypto.PubkeyToAddress(*publicKey).Bytes()...)), nil
+}
+
+// GetAllPossibleAddressesFromPublicKey returns all PossubleAddress(es) given a public key
+func (ab AddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]xc.PossibleAddress, error) {
+	address, err := ab.GetAddressFromPublicKey(publicKeyBytes)
+	return []xc.PossibleAddress{
+		{
+			Address: address,
+			Type:    xc.AddressTypeDefault,
+		},
+	}, err
+}
+
+// ValidateAddress checks an address is a base58check Tron address, e.g. TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t
+func (ab AddressBuilder) ValidateAddress(address xc.Address) error {
+	if strings.HasPrefix(string(address), "41") {
+		return fmt.Errorf("invalid address '%s': hex addresses aren't supported, expected base58", address)
+	}
+	if _, err := DecodeAddress(address); err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	return nil
+}
+
+// EncodeAddress returns the base58check address of the 21 bytes of an address, its prefix included
+func EncodeAddress(address []byte) xc.Address {
+	return xc.Address(base58.CheckEncode(address[1:], address[0]))
+}
+
+// DecodeAddress returns the 21 bytes of an address, from its base58check or hex encoding (41...)
+func DecodeAddress(address xc.Address) ([]byte, error) {
+	str := strings.TrimSpace(string(address))
+	if len(str) == 2*addressLength && strings.HasPrefix(str, "41") {
+		decoded, err := hex.DecodeString(str)
+		if err != nil {
+			return nil, errors.New("invalid hex")
+		}
+		return decoded, nil
+	}
+	payload, version, err := base58.CheckDecode(str)
+	if err != nil {
+		return nil, err
+	}
+	if version != addressPrefix {
+		return nil, fmt.Errorf("invalid prefix 0x%02x, expected 0x%02x", version, addressPrefix)
+	}
+	if len(payload) != addressLength-1 {
+		return nil, errors.New("invalid length")
+	}
+	return append([]byte{version}, payload...), nil
+}
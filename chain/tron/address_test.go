@@ -0,0 +1,81 @@
+package tron
+
+import (
+	"encoding/hex"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// the address of the private key 1
+const testAddress = xc.Address("TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC")
+
+// USDT on Tron mainnet
+const testContract = xc.Address("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
+
+func (s *CrosschainTestSuite) TestNewAddressBuilder() {
+	require := s.Require()
+	builder, err := NewAddressBuilder(&xc.NativeAssetConfig{})
+	require.NoError(err)
+	require.NotNil(builder)
+}
+
+func (s *CrosschainTestSuite) TestGetAddressFromPublicKey() {
+	require := s.Require()
+	builder, _ := NewAddressBuilder(&xc.NativeAssetConfig{})
+	privateKey, _ := crypto.ToECDSA(common32(1))
+
+	address, err := builder.GetAddressFromPublicKey(crypto.CompressPubkey(&privateKey.PublicKey))
+	require.NoError(err)
+	require.Equal(testAddress, address)
+
+	address, err = builder.GetAddressFromPublicKey(crypto.FromECDSAPub(&privateKey.PublicKey))
+	require.NoError(err)
+	require.Equal(testAddress, address)
+
+	_, err = builder.GetAddressFromPublicKey([]byte{1, 2, 3})
+	require.EqualError(err, "invalid k256 public key")
+
+	addresses, err := builder.GetAllPossibleAddressesFromPublicKey(crypto.CompressPubkey(&privateKey.PublicKey))
+	require.NoError(err)
+	require.Equal([]xc.PossibleAddress{{Address: testAddress, Type: xc.AddressTypeDefault}}, addresses)
+}
+
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	builder := AddressBuilder{}
+	require.NoError(builder.ValidateAddress(testAddress))
+	require.NoError(builder.ValidateAddress(testContract))
+
+	err := builder.ValidateAddress("41a614f803b6fd780986a42c78ec9c7f77e6ded13c")
+	require.ErrorContains(err, "hex addresses aren't supported")
+	// checksum
+	err = builder.ValidateAddress("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u")
+	require.ErrorContains(err, "invalid address")
+	// an address of bitcoin
+	err = builder.ValidateAddress("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+	require.ErrorContains(err, "invalid prefix 0x00")
+}
+
+func (s *CrosschainTestSuite) TestDecodeAddress() {
+	require := s.Require()
+	expected := "41a614f803b6fd780986a42c78ec9c7f77e6ded13c"
+	decoded, err := DecodeAddress(testContract)
+	require.NoError(err)
+	require.Equal(expected, hex.EncodeToString(decoded))
+
+	decoded, err = DecodeAddress(xc.Address(expected))
+	require.NoError(err)
+	require.Equal(expected, hex.EncodeToString(decoded))
+	require.Equal(testContract, EncodeAddress(decoded))
+
+	_, err = DecodeAddress("41zz14f803b6fd780986a42c78ec9c7f77e6ded13c")
+	require.EqualError(err, "invalid hex")
+}
+
+// common32 returns a 32-byte big endian integer
+func common32(n byte) []byte {
+	data := make([]byte, 32)
+	data[31] = n
+	return data
+}
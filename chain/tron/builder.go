@@ -0,0 +1,127 @@
+package tron
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// trc20TransferMethodID is the method id of transfer(address,uint256)
+var trc20TransferMethodID = []byte{0xa9, 0x05, 0x9c, 0xbb}
+
+// TxBuilder for Tron
+type TxBuilder struct {
+	Asset xc.ITask
+}
+
+var _ xc.TxBuilder = &TxBuilder{}
+var _ xc.TxTokenBuilder = &TxBuilder{}
+
+// NewTxBuilder creates a new Tron TxBuilder
+func NewTxBuilder(asset xc.ITask) (xc.TxBuilder, error) {
+	return &TxBuilder{
+		Asset: asset,
+	}, nil
+}
+
+// NewTransfer creates a new transfer for an Asset, either native or token
+func (txBuilder TxBuilder) NewTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	if err := xc.CheckSendAllowed(txBuilder.Asset); err != nil {
+		return nil, err
+	}
+	if _, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		return txBuilder.NewTokenTransfer(from, to, amount, input)
+	}
+	return txBuilder.NewNativeTransfer(from, to, amount, input)
+}
+
+// NewNativeTransfer creates a new TransferContract of TRX
+func (txBuilder TxBuilder) NewNativeTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	localInput, owner, recipient, err := parseTransfer(from, to, amount, input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	if !amount.Int().IsInt64() {
+		return &Tx{}, errors.New("invalid amount: exceeds int64")
+	}
+	contract := transferContract{
+		OwnerAddress: owner,
+		ToAddress:    recipient,
+		Amount:       amount.Int().Int64(),
+	}
+	return &Tx{Raw: newRawTransaction(localInput, TransferContractType, contract.marshal(), 0)}, nil
+}
+
+// NewTokenTransfer creates a new TriggerSmartContract calling transfer of a TRC-20 contract, burning up to the fee
+// limit of the input for its energy
+func (txBuilder TxBuilder) NewTokenTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (xc.Tx, error) {
+	localInput, owner, recipient, err := parseTransfer(from, to, amount, input)
+	if err != nil {
+		return &Tx{}, err
+	}
+	contractAddress := txBuilder.Asset.GetAssetConfig().Contract
+	if token, ok := txBuilder.Asset.(*xc.TokenAssetConfig); ok {
+		contractAddress = token.Contract
+	}
+	tokenContract, err := DecodeAddress(xc.Address(contractAddress))
+	if err != nil {
+		return &Tx{}, fmt.Errorf("invalid contract address '%s': %v", contractAddress, err)
+	}
+	if amount.Int().BitLen() > 256 {
+		return &Tx{}, errors.New("invalid amount: exceeds uint256")
+	}
+	// the ABI encoding of the TVM, with EVM addresses
+	data := append([]byte{}, trc20TransferMethodID...)
+	data = append(data, common.LeftPadBytes(recipient[1:], 32)...)
+	data = append(data, common.LeftPadBytes(amount.Int().Bytes(), 32)...)
+	contract := triggerSmartContract{
+		OwnerAddress:    owner,
+		ContractAddress: tokenContract,
+		Data:            data,
+	}
+	return &Tx{Raw: newRawTransaction(localInput, TriggerSmartContractType, contract.marshal(), localInput.FeeLimit)}, nil
+}
+
+func parseTransfer(from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput) (TxInput, []byte, []byte, error) {
+	var localInput TxInput
+	switch typed := input.(type) {
+	case TxInput:
+		localInput = typed
+	case *TxInput:
+		localInput = *typed
+	default:
+		return localInput, nil, nil, errors.New("xc.TxInput is not from a tron chain")
+	}
+	owner, err := DecodeAddress(from)
+	if err != nil {
+		return localInput, nil, nil, fmt.Errorf("invalid from address '%s': %v", from, err)
+	}
+	recipient, err := DecodeAddress(to)
+	if err != nil {
+		return localInput, nil, nil, fmt.Errorf("invalid to address '%s': %v", to, err)
+	}
+	if amount.Int().Sign() < 0 {
+		return localInput, nil, nil, errors.New("invalid negative amount")
+	}
+	return localInput, owner, recipient, nil
+}
+
+func newRawTransaction(input TxInput, contractType ContractType, parameter []byte, feeLimit int64) rawTransaction {
+	return rawTransaction{
+		RefBlockBytes: input.RefBlockBytes,
+		RefBlockHash:  input.RefBlockHash,
+		Expiration:    input.Expiration,
+		ContractType:  contractType,
+		Parameter:     parameter,
+		Timestamp:     input.Timestamp,
+		FeeLimit:      feeLimit,
+	}
+}
+
+// abiAmount decodes a uint256 of the ABI encoding
+func abiAmount(data []byte) *big.Int {
+	return new(big.Int).SetBytes(data)
+}
@@ -0,0 +1,409 @@
+package tron
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// expirationPeriod is the time txs are valid for, in milliseconds: 10 minutes, below the 24 hours allowed by
+// java-tron
+const expirationPeriod = 10 * 60 * 1000
+
+// trc20EnergyLimit is the energy of the fee limit of TRC-20 transfers: a transfer of USDT to a new holder burns
+// about 130000 energy
+const trc20EnergyLimit = 150_000
+
+// trc20TransferTopic is the topic of Transfer(address,address,uint256) events
+const trc20TransferTopic = "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// Client for Tron, using the HTTP API of java-tron nodes
+type Client struct {
+	Asset           xc.ITask
+	HttpClient      *http.Client
+	URL             string
+	EstimateGasFunc xc.EstimateGasFunc
+}
+
+var _ xc.FullClientWithGas = &Client{}
+
+type apiBlock struct {
+	BlockID     string `json:"blockID"`
+	BlockHeader struct {
+		RawData struct {
+			Number    int64 `json:"number"`
+			Timestamp int64 `json:"timestamp"`
+		} `json:"raw_data"`
+	} `json:"block_header"`
+}
+
+type apiChainParameters struct {
+	ChainParameter []struct {
+		Key   string `json:"key"`
+		Value int64  `json:"value"`
+	} `json:"chainParameter"`
+}
+
+type apiBroadcast struct {
+	Result  bool   `json:"result"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type apiTransaction struct {
+	TxID string `json:"txID"`
+	Ret  []struct {
+		ContractRet string `json:"contractRet"`
+	} `json:"ret"`
+	RawData struct {
+		Contract []struct {
+			Type      string `json:"type"`
+			Parameter struct {
+				Value struct {
+					OwnerAddress    string `json:"owner_address"`
+					ToAddress       string `json:"to_address"`
+					Amount          int64  `json:"amount"`
+					ContractAddress string `json:"contract_address"`
+				} `json:"value"`
+			} `json:"parameter"`
+		} `json:"contract"`
+	} `json:"raw_data"`
+}
+
+type apiTransactionInfo struct {
+	ID             string `json:"id"`
+	Fee            int64  `json:"fee"`
+	BlockNumber    int64  `json:"blockNumber"`
+	BlockTimeStamp int64  `json:"blockTimeStamp"`
+	Receipt        struct {
+		EnergyUsageTotal int64  `json:"energy_usage_total"`
+		EnergyFee        int64  `json:"energy_fee"`
+		NetUsage         int64  `json:"net_usage"`
+		NetFee           int64  `json:"net_fee"`
+		Result           string `json:"result"`
+	} `json:"receipt"`
+	Log []struct {
+		Address string   `json:"address"`
+		Topics  []string `json:"topics"`
+		Data    string   `json:"data"`
+	} `json:"log"`
+	ResMessage string `json:"resMessage"`
+}
+
+type apiAccount struct {
+	Balance int64 `json:"balance"`
+}
+
+type apiConstantContract struct {
+	ConstantResult []string `json:"constant_result"`
+	Result         struct {
+		Result  bool   `json:"result"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"result"`
+}
+
+// NewClient returns a new Tron Client
+func NewClient(cfgI xc.ITask) (*Client, error) {
+	cfg := cfgI.GetNativeAsset()
+	transport, err := cfg.HTTPTransport(http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Asset:      cfgI,
+		HttpClient: &http.Client{Transport: transport},
+		URL:        strings.TrimSuffix(cfg.URL, "/"),
+	}, nil
+}
+
+// FetchTxInput returns tx input for a Tron tx: the latest block as reference block, and the fee limit of contract
+// calls at the energy price of the chain
+func (client *Client) FetchTxInput(ctx context.Context, _ xc.Address, _ xc.Address) (xc.TxInput, error) {
+	input := NewTxInput()
+	block, err := client.fetchNowBlock(ctx)
+	if err != nil {
+		return input, err
+	}
+	blockID, err := hex.DecodeString(block.BlockID)
+	if err != nil || len(blockID) != 32 {
+		return input, fmt.Errorf("invalid block id '%s'", block.BlockID)
+	}
+	number := new(big.Int).SetInt64(block.BlockHeader.RawData.Number)
+	input.RefBlockBytes = common.LeftPadBytes(number.Bytes(), 8)[6:8]
+	input.RefBlockHash = blockID[8:16]
+	input.Timestamp = block.BlockHeader.RawData.Timestamp
+	input.Expiration = block.BlockHeader.RawData.Timestamp + expirationPeriod
+
+	energyPrice, err := client.EstimateGas(ctx)
+	if err != nil {
+		return input, err
+	}
+	feeLimit := new(big.Int).Mul(energyPrice.Int(), big.NewInt(trc20EnergyLimit))
+	if !feeLimit.IsInt64() {
+		return input, fmt.Errorf("invalid energy price %s", energyPrice.String())
+	}
+	input.FeeLimit = feeLimit.Int64()
+	return input, nil
+}
+
+// SubmitTx submits a Tron tx
+func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := xc.CheckSendAllowed(client.Asset); err != nil {
+		return err
+	}
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	if xc.IsDryRun(ctx, client.Asset) {
+		return xc.RecordDryRun(ctx, client.Asset, tx, false)
+	}
+	var res apiBroadcast
+	if err := client.post(ctx, "/wallet/broadcasthex", map[string]interface{}{"transaction": hex.EncodeToString(serialized)}, &res); err != nil {
+		return err
+	}
+	if !res.Result {
+		// messages are hex encoded
+		message := res.Message
+		if decoded, err := hex.DecodeString(message); err == nil {
+			message = string(decoded)
+		}
+		return fmt.Errorf("%s: %s", res.Code, message)
+	}
+	return nil
+}
+
+// FetchTxInfo returns tx info for a Tron tx, with its fee: the TRX burnt for energy and bandwidth not covered by
+// staking, and the energy used as gas
+func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
+	id := strings.TrimPrefix(string(txHash), "0x")
+	var tx apiTransaction
+	if err := client.post(ctx, "/wallet/gettransactionbyid", map[string]interface{}{"value": id}, &tx); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching tx '%s': %v", id, err)
+	}
+	if tx.TxID == "" {
+		return xc.TxInfo{}, fmt.Errorf("tx not found: %s", id)
+	}
+	var txInfo apiTransactionInfo
+	if err := client.post(ctx, "/wallet/gettransactioninfobyid", map[string]interface{}{"value": id}, &txInfo); err != nil {
+		return xc.TxInfo{}, fmt.Errorf("fetching tx info '%s': %v", id, err)
+	}
+
+	info := xc.TxInfo{
+		TxID:        tx.TxID,
+		ExplorerURL: fmt.Sprintf("/#/transaction/%s", tx.TxID),
+		Fee:         xc.NewAmountBlockchainFromUint64(uint64(txInfo.Fee)),
+		GasUsed:     uint64(txInfo.Receipt.EnergyUsageTotal),
+		Amount:      xc.NewAmountBlockchainFromUint64(0),
+		BlockIndex:  txInfo.BlockNumber,
+		BlockTime:   txInfo.BlockTimeStamp / 1000,
+	}
+	if len(tx.Ret) > 0 && tx.Ret[0].ContractRet != "" && tx.Ret[0].ContractRet != "SUCCESS" {
+		info.Status = xc.TxStatusFailure
+		info.Error = tx.Ret[0].ContractRet
+		if message, err := hex.DecodeString(txInfo.ResMessage); err == nil && len(message) > 0 {
+			info.Error = fmt.Sprintf("%s: %s", info.Error, message)
+		}
+	}
+	if txInfo.BlockNumber > 0 {
+		block, err := client.fetchNowBlock(ctx)
+		if err != nil {
+			return info, err
+		}
+		info.Confirmations = block.BlockHeader.RawData.Number - txInfo.BlockNumber
+	}
+
+	nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+	if len(tx.RawData.Contract) == 0 {
+		return info, nil
+	}
+	contract := tx.RawData.Contract[0]
+	info.From = hexToAddress(contract.Parameter.Value.OwnerAddress)
+	switch contract.Type {
+	case transferContractName:
+		info.To = hexToAddress(contract.Parameter.Value.ToAddress)
+		info.Amount = xc.NewAmountBlockchainFromUint64(uint64(contract.Parameter.Value.Amount))
+		info.Sources = []*xc.TxInfoEndpoint{{Address: info.From, Amount: info.Amount, NativeAsset: nativeAsset}}
+		info.Destinations = []*xc.TxInfoEndpoint{{Address: info.To, Amount: info.Amount, NativeAsset: nativeAsset}}
+	case triggerSmartContractName:
+		for _, log := range txInfo.Log {
+			// Transfer(from indexed, to indexed, value)
+			if len(log.Topics) != 3 || !strings.EqualFold(log.Topics[0], trc20TransferTopic) {
+				continue
+			}
+			data, err := hex.DecodeString(log.Data)
+			if err != nil || len(data) != 32 {
+				continue
+			}
+			from := topicToAddress(log.Topics[1])
+			to := topicToAddress(log.Topics[2])
+			tokenContract := xc.ContractAddress(hexToAddress(log.Address))
+			amount := xc.AmountBlockchain(*abiAmount(data))
+			info.Sources = append(info.Sources, &xc.TxInfoEndpoint{Address: from, ContractAddress: tokenContract, Amount: amount, NativeAsset: nativeAsset})
+			info.Destinations = append(info.Destinations, &xc.TxInfoEndpoint{Address: to, ContractAddress: tokenContract, Amount: amount, NativeAsset: nativeAsset})
+			if info.To == "" && from == info.From {
+				info.To = to
+				info.Amount = amount
+				info.ContractAddress = tokenContract
+			}
+		}
+	}
+	return info, nil
+}
+
+// FetchBalance fetches the balance of an asset for a Tron address
+func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	if token, ok := client.Asset.(*xc.TokenAssetConfig); ok {
+		return client.fetchBalanceOf(ctx, xc.ContractAddress(token.Contract), address)
+	}
+	return client.FetchNativeBalance(ctx, address)
+}
+
+// FetchNativeBalance fetches the TRX balance of a Tron address, 0 for accounts not activated
+func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	owner, err := DecodeAddress(address)
+	if err != nil {
+		return zero, fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	var account apiAccount
+	if err := client.post(ctx, "/wallet/getaccount", map[string]interface{}{"address": hex.EncodeToString(owner)}, &account); err != nil {
+		return zero, err
+	}
+	return xc.NewAmountBlockchainFromUint64(uint64(account.Balance)), nil
+}
+
+// fetchBalanceOf calls balanceOf of a TRC-20 contract
+func (client *Client) fetchBalanceOf(ctx context.Context, contract xc.ContractAddress, address xc.Address) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	owner, err := DecodeAddress(address)
+	if err != nil {
+		return zero, fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+	contractAddress, err := DecodeAddress(xc.Address(contract))
+	if err != nil {
+		return zero, fmt.Errorf("invalid contract address '%s': %v", contract, err)
+	}
+	call := map[string]interface{}{
+		"owner_address":     hex.EncodeToString(owner),
+		"contract_address":  hex.EncodeToString(contractAddress),
+		"function_selector": "balanceOf(address)",
+		"parameter":         hex.EncodeToString(common.LeftPadBytes(owner[1:], 32)),
+	}
+	var res apiConstantContract
+	if err := client.post(ctx, "/wallet/triggerconstantcontract", call, &res); err != nil {
+		return zero, err
+	}
+	if len(res.ConstantResult) != 1 {
+		return zero, fmt.Errorf("invalid balance of '%s': %s", address, res.Result.Message)
+	}
+	data, err := hex.DecodeString(res.ConstantResult[0])
+	if err != nil || len(data) != 32 {
+		return zero, fmt.Errorf("invalid balance of '%s': %s", address, res.ConstantResult[0])
+	}
+	return xc.AmountBlockchain(*abiAmount(data)), nil
+}
+
+func (client *Client) RegisterEstimateGasCallback(estimateGas xc.EstimateGasFunc) {
+	client.EstimateGasFunc = estimateGas
+}
+
+// EstimateGas returns the price of energy in sun, the getEnergyFee parameter of the chain: energy not covered by
+// staking is paid by burning TRX, up to the fee limit of txs
+func (client *Client) EstimateGas(ctx context.Context) (xc.AmountBlockchain, error) {
+	zero := xc.NewAmountBlockchainFromUint64(0)
+	if client.EstimateGasFunc != nil {
+		nativeAsset := client.Asset.GetNativeAsset().NativeAsset
+		if res, err := client.EstimateGasFunc(nativeAsset); err == nil {
+			return res, nil
+		}
+		// continue with default implementation as fallback
+	}
+	var params apiChainParameters
+	if err := client.post(ctx, "/wallet/getchainparameters", nil, &params); err != nil {
+		return zero, fmt.Errorf("fetching chain parameters: %v", err)
+	}
+	for _, param := range params.ChainParameter {
+		if param.Key == "getEnergyFee" {
+			return xc.NewAmountBlockchainFromUint64(uint64(param.Value)), nil
+		}
+	}
+	return zero, errors.New("missing getEnergyFee chain parameter")
+}
+
+func (client *Client) fetchNowBlock(ctx context.Context) (apiBlock, error) {
+	var block apiBlock
+	if err := client.post(ctx, "/wallet/getnowblock", nil, &block); err != nil {
+		return block, fmt.Errorf("fetching latest block: %v", err)
+	}
+	return block, nil
+}
+
+// post sends a JSON request to the HTTP API of java-tron and decodes the JSON response into result
+// Addresses are exchanged in hex, 41 followed by the EVM address
+func (client *Client) post(ctx context.Context, path string, body interface{}, result interface{}) error {
+	if body == nil {
+		body = map[string]interface{}{}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.URL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.HttpClient.Do(req)
+	if err != nil {
+		return xc.DefaultRedactor.RedactError(err)
+	}
+	defer resp.Body.Close()
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s: %s", path, resp.Status, xc.DefaultRedactor.Redact(string(data)))
+	}
+	// errors of requests are reported with a status of 200
+	var apiError struct {
+		Error string `json:"Error"`
+	}
+	if err := json.Unmarshal(data, &apiError); err == nil && apiError.Error != "" {
+		return errors.New(apiError.Error)
+	}
+	return json.Unmarshal(data, result)
+}
+
+// hexToAddress returns the base58 address of a hex address, with or without its 41 prefix
+func hexToAddress(address string) xc.Address {
+	data, err := hex.DecodeString(address)
+	if err != nil {
+		return xc.Address(address)
+	}
+	if len(data) == addressLength-1 {
+		data = append([]byte{addressPrefix}, data...)
+	}
+	return EncodeAddress(data)
+}
+
+// topicToAddress returns the base58 address of an indexed address of an event
+func topicToAddress(topic string) xc.Address {
+	data, err := hex.DecodeString(topic)
+	if err != nil || len(data) != 32 {
+		return xc.Address(topic)
+	}
+	return EncodeAddress(append([]byte{addressPrefix}, data[12:]...))
+}
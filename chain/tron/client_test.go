@@ -0,0 +1,205 @@
+package tron
+
+import (
+	"errors"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/test"
+)
+
+const testNowBlock = `{"blockID":"0000000002faf0801122334455667788aabbccddeeff00112233445566778899","block_header":{"raw_data":{"number":50000000,"timestamp":1700000000000}}}`
+
+const testChainParameters = `{"chainParameter":[{"key":"getMaintenanceTimeInterval","value":21600000},{"key":"getEnergyFee","value":420}]}`
+
+func (s *CrosschainTestSuite) TestFetchTxInput() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{testNowBlock, testChainParameters})
+	defer close()
+
+	client, err := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.TRX, URL: server.URL})
+	require.NoError(err)
+	input, err := client.FetchTxInput(s.Ctx, testAddress, testContract)
+	require.NoError(err)
+	txInput := input.(*TxInput)
+	require.Equal(xc.DriverTron, txInput.Type)
+	// 50000000 is 0x2faf080
+	require.Equal([]byte{0xf0, 0x80}, txInput.RefBlockBytes)
+	require.Equal([]byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}, txInput.RefBlockHash)
+	require.EqualValues(1700000000000, txInput.Timestamp)
+	require.EqualValues(1700000600000, txInput.Expiration)
+	require.EqualValues(420*trc20EnergyLimit, txInput.FeeLimit)
+
+	server.Counter = 0
+	server.Response = `{"blockID":"00"}`
+	_, err = client.FetchTxInput(s.Ctx, testAddress, testContract)
+	require.EqualError(err, "invalid block id '00'")
+}
+
+func (s *CrosschainTestSuite) TestEstimateGas() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, testChainParameters)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.TRX, URL: server.URL})
+	price, err := client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("420", price.String())
+
+	client.RegisterEstimateGasCallback(func(native xc.NativeAsset) (xc.AmountBlockchain, error) {
+		return xc.NewAmountBlockchainFromUint64(210), nil
+	})
+	price, err = client.EstimateGas(s.Ctx)
+	require.NoError(err)
+	require.Equal("210", price.String())
+	require.Equal(1, server.Counter)
+
+	client.EstimateGasFunc = nil
+	server.Response = `{"chainParameter":[]}`
+	_, err = client.EstimateGas(s.Ctx)
+	require.EqualError(err, "missing getEnergyFee chain parameter")
+}
+
+func (s *CrosschainTestSuite) TestSubmitTx() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, `{"result":true,"txid":"b873765df891c17caf370d61f35932977842777800532f644be080a603dd3f3b"}`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.TRX, URL: server.URL})
+
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.TRX})
+	tx, _ := builder.NewTransfer(testAddress, testContract, xc.NewAmountBlockchainFromUint64(1_000_000), testTxInput())
+	signer, _ := NewSigner(&xc.NativeAssetConfig{})
+	sighashes, _ := tx.Sighashes()
+	signature, _ := signer.Sign(common32(1), sighashes[0])
+	require.NoError(tx.AddSignatures(signature))
+	require.NoError(client.SubmitTx(s.Ctx, tx))
+
+	// "balance is not sufficient."
+	server.Response = `{"result":false,"code":"CONTRACT_VALIDATE_ERROR","message":"62616c616e6365206973206e6f742073756666696369656e742e"}`
+	err := client.SubmitTx(s.Ctx, tx)
+	require.EqualError(err, "CONTRACT_VALIDATE_ERROR: balance is not sufficient.")
+	require.Equal(xc.NoBalance, CheckError(err))
+
+	server.Response = `{"Error":"class org.tron.core.exception.BadItemException : invalid transaction"}`
+	err = client.SubmitTx(s.Ctx, tx)
+	require.ErrorContains(err, "invalid transaction")
+
+	require.ErrorContains(client.SubmitTx(s.Ctx, &Tx{}), "unable to serialize")
+}
+
+const testNativeTx = `{"ret":[{"contractRet":"SUCCESS"}],"txID":"b873765df891c17caf370d61f35932977842777800532f644be080a603dd3f3b","raw_data":{"contract":[{"parameter":{"value":{"amount":1000000,"owner_address":"417e5f4552091a69125d5dfcb7b8c2659029395bdf","to_address":"41a614f803b6fd780986a42c78ec9c7f77e6ded13c"},"type_url":"type.googleapis.com/protocol.TransferContract"},"type":"TransferContract"}]}}`
+
+const testNativeTxInfo = `{"id":"b873765df891c17caf370d61f35932977842777800532f644be080a603dd3f3b","fee":1100000,"blockNumber":49999990,"blockTimeStamp":1699999970000,"receipt":{"net_fee":100000}}`
+
+const testTokenTx = `{"ret":[{"contractRet":"SUCCESS"}],"txID":"316bfc17f4b2e065d76b5efda744902e4ed4c55b2c97086a84eaa7ac5ce731f3","raw_data":{"contract":[{"parameter":{"value":{"data":"a9059cbb","owner_address":"417e5f4552091a69125d5dfcb7b8c2659029395bdf","contract_address":"41eca9bc828a3005b9a3b909f2cc5c2a54794de05f"},"type_url":"type.googleapis.com/protocol.TriggerSmartContract"},"type":"TriggerSmartContract"}]}}`
+
+const testTokenTxInfo = `{"id":"316bfc17f4b2e065d76b5efda744902e4ed4c55b2c97086a84eaa7ac5ce731f3","fee":13844850,"blockNumber":49999990,"blockTimeStamp":1699999970000,"contract_address":"41eca9bc828a3005b9a3b909f2cc5c2a54794de05f","receipt":{"energy_fee":13499850,"energy_usage_total":64285,"net_fee":345000,"result":"SUCCESS"},"log":[{"address":"eca9bc828a3005b9a3b909f2cc5c2a54794de05f","topics":["ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef","0000000000000000000000007e5f4552091a69125d5dfcb7b8c2659029395bdf","000000000000000000000000a614f803b6fd780986a42c78ec9c7f77e6ded13c"],"data":"00000000000000000000000000000000000000000000000000000000000f4240"}]}`
+
+func (s *CrosschainTestSuite) TestFetchTxInfo() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{testNativeTx, testNativeTxInfo, testNowBlock})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.TRX, URL: server.URL})
+
+	info, err := client.FetchTxInfo(s.Ctx, "b873765df891c17caf370d61f35932977842777800532f644be080a603dd3f3b")
+	require.NoError(err)
+	require.Equal(xc.TxStatusSuccess, info.Status)
+	require.Equal(testAddress, info.From)
+	require.Equal(testContract, info.To)
+	require.Equal("1000000", info.Amount.String())
+	// bandwidth burnt and the fee of activating the recipient
+	require.Equal("1100000", info.Fee.String())
+	require.EqualValues(0, info.GasUsed)
+	require.EqualValues(49999990, info.BlockIndex)
+	require.EqualValues(1699999970, info.BlockTime)
+	require.EqualValues(10, info.Confirmations)
+	require.Len(info.Sources, 1)
+	require.Equal(xc.TRX, info.Destinations[0].NativeAsset)
+	require.Equal(xc.ContractAddress(""), info.Destinations[0].ContractAddress)
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfoToken() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, []string{testTokenTx, testTokenTxInfo, testNowBlock})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.TRX, URL: server.URL})
+
+	info, err := client.FetchTxInfo(s.Ctx, "0x316bfc17f4b2e065d76b5efda744902e4ed4c55b2c97086a84eaa7ac5ce731f3")
+	require.NoError(err)
+	require.Equal(testAddress, info.From)
+	require.Equal(testContract, info.To)
+	require.Equal(xc.ContractAddress("TXYZopYRdj2D9XRtbG411XZZ3kM5VkAeBf"), info.ContractAddress)
+	require.Equal("1000000", info.Amount.String())
+	// energy and bandwidth burnt
+	require.Equal("13844850", info.Fee.String())
+	require.EqualValues(64285, info.GasUsed)
+	require.Len(info.Destinations, 1)
+	require.Equal(testContract, info.Destinations[0].Address)
+	require.Equal(xc.ContractAddress("TXYZopYRdj2D9XRtbG411XZZ3kM5VkAeBf"), info.Destinations[0].ContractAddress)
+}
+
+func (s *CrosschainTestSuite) TestFetchTxInfoFailed() {
+	require := s.Require()
+	failed := `{"ret":[{"contractRet":"OUT_OF_ENERGY"}],"txID":"316bfc17f4b2e065d76b5efda744902e4ed4c55b2c97086a84eaa7ac5ce731f3","raw_data":{"contract":[]}}`
+	server, close := test.MockHTTP(&s.Suite, []string{failed, `{"fee":63000000,"blockNumber":49999990,"receipt":{"energy_usage_total":150000,"result":"OUT_OF_ENERGY"}}`, testNowBlock})
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.TRX, URL: server.URL})
+
+	info, err := client.FetchTxInfo(s.Ctx, "316bfc17f4b2e065d76b5efda744902e4ed4c55b2c97086a84eaa7ac5ce731f3")
+	require.NoError(err)
+	require.Equal(xc.TxStatusFailure, info.Status)
+	require.Equal("OUT_OF_ENERGY", info.Error)
+	require.Equal("63000000", info.Fee.String())
+
+	server.Counter = 0
+	server.Response = `{}`
+	_, err = client.FetchTxInfo(s.Ctx, "316bfc17f4b2e065d76b5efda744902e4ed4c55b2c97086a84eaa7ac5ce731f3")
+	require.EqualError(err, "tx not found: 316bfc17f4b2e065d76b5efda744902e4ed4c55b2c97086a84eaa7ac5ce731f3")
+}
+
+func (s *CrosschainTestSuite) TestFetchBalance() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, `{"address":"417e5f4552091a69125d5dfcb7b8c2659029395bdf","balance":12345678}`)
+	defer close()
+	client, _ := NewClient(&xc.NativeAssetConfig{NativeAsset: xc.TRX, URL: server.URL})
+	balance, err := client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("12345678", balance.String())
+
+	// accounts not activated
+	server.Response = `{}`
+	balance, err = client.FetchNativeBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("0", balance.String())
+
+	_, err = client.FetchBalance(s.Ctx, "invalid")
+	require.ErrorContains(err, "invalid address 'invalid'")
+
+	server.Response = errors.New("server error")
+	_, err = client.FetchBalance(s.Ctx, testAddress)
+	require.ErrorContains(err, "returned 400")
+}
+
+func (s *CrosschainTestSuite) TestFetchTokenBalance() {
+	require := s.Require()
+	server, close := test.MockHTTP(&s.Suite, fmt.Sprintf(`{"result":{"result":true},"constant_result":["%064x"]}`, 5_000_000))
+	defer close()
+	asset := &xc.TokenAssetConfig{Asset: "USDT", Contract: "TXYZopYRdj2D9XRtbG411XZZ3kM5VkAeBf", NativeAssetConfig: &xc.NativeAssetConfig{NativeAsset: xc.TRX, URL: server.URL}}
+	client, _ := NewClient(asset)
+	balance, err := client.FetchBalance(s.Ctx, testAddress)
+	require.NoError(err)
+	require.Equal("5000000", balance.String())
+
+	server.Response = `{"result":{"code":"CONTRACT_VALIDATE_ERROR","message":"contract not found"}}`
+	_, err = client.FetchBalance(s.Ctx, testAddress)
+	require.ErrorContains(err, "contract not found")
+}
+
+func (s *CrosschainTestSuite) TestCheckError() {
+	require := s.Require()
+	require.Equal(xc.NoBalanceForGas, CheckError(errors.New("BANDWITH_ERROR: Account resource insufficient error.")))
+	require.Equal(xc.TransactionExists, CheckError(errors.New("DUP_TRANSACTION_ERROR: dup trans")))
+	require.Equal(xc.TransactionFailure, CheckError(errors.New("TAPOS_ERROR: Tapos check error")))
+	require.Equal(xc.TransactionFailure, CheckError(errors.New("SIGERROR: validate signature error")))
+	require.Equal(xc.NetworkError, CheckError(errors.New("SERVER_BUSY: Server busy")))
+	require.Equal(xc.UnknownError, CheckError(errors.New("unknown")))
+}
@@ -0,0 +1,34 @@
+package tron
+
+import (
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// CheckError classifies the errors of broadcasting Tron txs, reported as their code and message
+func CheckError(err error) xc.ClientError {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "bandwith_error") ||
+		strings.Contains(msg, "account resource insufficient") {
+		return xc.NoBalanceForGas
+	}
+	if strings.Contains(msg, "balance is not sufficient") ||
+		strings.Contains(msg, "balance is not enough") {
+		return xc.NoBalance
+	}
+	if strings.Contains(msg, "dup_transaction_error") {
+		return xc.TransactionExists
+	}
+	if strings.Contains(msg, "sigerror") ||
+		strings.Contains(msg, "tapos_error") ||
+		strings.Contains(msg, "transaction_expiration_error") ||
+		strings.Contains(msg, "contract_validate_error") {
+		return xc.TransactionFailure
+	}
+	if strings.Contains(msg, "server_busy") ||
+		strings.Contains(msg, "eof") {
+		return xc.NetworkError
+	}
+	return xc.UnknownError
+}
@@ -0,0 +1,111 @@
+package tron
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The protobuf messages of java-tron used by crosschain, encoded by hand like the protocol definitions
+// (core/Tron.proto and core/contract/*.proto)
+
+// ContractType is the type of the contract (operation) of a tx
+type ContractType int32
+
+// List of supported ContractType
+const (
+	TransferContractType     = ContractType(1)
+	TriggerSmartContractType = ContractType(31)
+)
+
+// Type URLs of the parameters of contracts, and their names in the JSON of the HTTP API
+const (
+	transferContractName     = "TransferContract"
+	triggerSmartContractName = "TriggerSmartContract"
+	typeURLPrefix            = "type.googleapis.com/protocol."
+)
+
+// transferContract is a transfer of TRX
+type transferContract struct {
+	OwnerAddress []byte
+	ToAddress    []byte
+	Amount       int64
+}
+
+func (contract transferContract) marshal() []byte {
+	data := appendBytes(nil, 1, contract.OwnerAddress)
+	data = appendBytes(data, 2, contract.ToAddress)
+	return appendVarint(data, 3, uint64(contract.Amount))
+}
+
+// triggerSmartContract is a call of a contract of the TVM, e.g. a TRC-20 transfer
+type triggerSmartContract struct {
+	OwnerAddress    []byte
+	ContractAddress []byte
+	CallValue       int64
+	Data            []byte
+}
+
+func (contract triggerSmartContract) marshal() []byte {
+	data := appendBytes(nil, 1, contract.OwnerAddress)
+	data = appendBytes(data, 2, contract.ContractAddress)
+	data = appendVarint(data, 3, uint64(contract.CallValue))
+	return appendBytes(data, 4, contract.Data)
+}
+
+// rawTransaction is the signed part of a tx (Transaction.raw)
+type rawTransaction struct {
+	RefBlockBytes []byte
+	RefBlockHash  []byte
+	Expiration    int64
+	ContractType  ContractType
+	// Parameter is the marshalled contract of ContractType
+	Parameter []byte
+	Timestamp int64
+	FeeLimit  int64
+}
+
+func (raw rawTransaction) marshal() []byte {
+	typeURL := typeURLPrefix + transferContractName
+	if raw.ContractType == TriggerSmartContractType {
+		typeURL = typeURLPrefix + triggerSmartContractName
+	}
+	// google.protobuf.Any
+	parameter := appendBytes(nil, 1, []byte(typeURL))
+	parameter = appendBytes(parameter, 2, raw.Parameter)
+	// Transaction.Contract
+	contract := appendVarint(nil, 1, uint64(raw.ContractType))
+	contract = appendBytes(contract, 2, parameter)
+
+	data := appendBytes(nil, 1, raw.RefBlockBytes)
+	data = appendBytes(data, 4, raw.RefBlockHash)
+	data = appendVarint(data, 8, uint64(raw.Expiration))
+	data = appendBytes(data, 11, contract)
+	data = appendVarint(data, 14, uint64(raw.Timestamp))
+	return appendVarint(data, 18, uint64(raw.FeeLimit))
+}
+
+// marshalTransaction returns a signed tx (Transaction): its raw data and signatures
+func marshalTransaction(raw []byte, signatures [][]byte) []byte {
+	data := appendBytes(nil, 1, raw)
+	for _, signature := range signatures {
+		data = appendBytes(data, 2, signature)
+	}
+	return data
+}
+
+// appendBytes appends a bytes field, omitted if empty like proto3
+func appendBytes(data []byte, field protowire.Number, value []byte) []byte {
+	if len(value) == 0 {
+		return data
+	}
+	data = protowire.AppendTag(data, field, protowire.BytesType)
+	return protowire.AppendBytes(data, value)
+}
+
+// appendVarint appends a varint field, omitted if zero like proto3
+func appendVarint(data []byte, field protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return data
+	}
+	data = protowire.AppendTag(data, field, protowire.VarintType)
+	return protowire.AppendVarint(data, value)
+}
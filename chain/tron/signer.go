@@ -0,0 +1,53 @@
+package tron
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Signer for Tron, signing with k256 keys like Ethereum
+type Signer struct {
+}
+
+var _ xc.Signer = &Signer{}
+var _ xc.PublicKeyDeriver = &Signer{}
+
+// NewSigner creates a new Tron Signer
+func NewSigner(asset xc.ITask) (xc.Signer, error) {
+	return Signer{}, nil
+}
+
+// ImportPrivateKey imports a Tron private key: 32 bytes of hex, as exported by TronLink
+func (signer Signer) ImportPrivateKey(privateKey string) (xc.PrivateKey, error) {
+	bytesPri, err := hex.DecodeString(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := crypto.ToECDSA(bytesPri); err != nil {
+		return nil, errors.New("invalid k256 private key")
+	}
+	return xc.PrivateKey(bytesPri), nil
+}
+
+// Sign a Tron tx id, returning R || S || V
+func (signer Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	ecdsaKey, err := crypto.ToECDSA(privateKey)
+	if err != nil {
+		return nil, errors.New("invalid k256 private key")
+	}
+	signature, err := crypto.Sign([]byte(data), ecdsaKey)
+	return xc.TxSignature(signature), err
+}
+
+// DerivePublicKey returns the compressed public key of a Tron private key
+func (signer Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	ecdsaKey, err := crypto.ToECDSA(privateKey)
+	if err != nil {
+		return nil, errors.New("invalid k256 private key")
+	}
+	return xc.PublicKey(crypto.CompressPubkey(&ecdsaKey.PublicKey)), nil
+}
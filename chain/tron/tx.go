@@ -0,0 +1,79 @@
+package tron
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// TxInput for Tron
+type TxInput struct {
+	xc.TxInputEnvelope
+	// RefBlockBytes and RefBlockHash reference a recent block (TaPoS): bytes 6 to 8 of its number and 8 to 16 of
+	// its id
+	RefBlockBytes []byte
+	RefBlockHash  []byte
+	// Timestamp and Expiration of the tx, in milliseconds
+	Timestamp  int64
+	Expiration int64
+	// FeeLimit is the max TRX burnt for the energy of contract calls, in sun
+	FeeLimit int64
+}
+
+// NewTxInput returns a new Tron TxInput
+func NewTxInput() *TxInput {
+	return &TxInput{
+		TxInputEnvelope: *xc.NewTxInputEnvelope(xc.DriverTron),
+	}
+}
+
+// Tx for Tron: a tx of a single contract, TransferContract or TriggerSmartContract
+type Tx struct {
+	Raw        rawTransaction
+	signatures [][]byte
+}
+
+var _ xc.Tx = &Tx{}
+
+// Hash returns the tx id: the hex sha256 of its raw data, without 0x
+func (tx Tx) Hash() xc.TxHash {
+	id := sha256.Sum256(tx.Raw.marshal())
+	return xc.TxHash(hex.EncodeToString(id[:]))
+}
+
+// Sighashes returns the tx id to sign
+func (tx Tx) Sighashes() ([]xc.TxDataToSign, error) {
+	if len(tx.Raw.Parameter) == 0 {
+		return []xc.TxDataToSign{}, errors.New("transaction not initialized")
+	}
+	id := sha256.Sum256(tx.Raw.marshal())
+	return []xc.TxDataToSign{id[:]}, nil
+}
+
+// AddSignatures adds the signature of the tx id: R || S || V, V converted to 27 or 28 like TronWeb
+func (tx *Tx) AddSignatures(signatures ...xc.TxSignature) error {
+	if len(signatures) != 1 {
+		return errors.New("expecting 1 signature")
+	}
+	if len(signatures[0]) != crypto.SignatureLength {
+		return fmt.Errorf("invalid signature length %d", len(signatures[0]))
+	}
+	signature := append([]byte{}, signatures[0]...)
+	if signature[crypto.RecoveryIDOffset] < 27 {
+		signature[crypto.RecoveryIDOffset] += 27
+	}
+	tx.signatures = [][]byte{signature}
+	return nil
+}
+
+// Serialize returns the protobuf of the signed tx
+func (tx Tx) Serialize() ([]byte, error) {
+	if len(tx.signatures) == 0 {
+		return []byte{}, errors.New("unable to serialize without first calling AddSignatures(...)")
+	}
+	return marshalTransaction(tx.Raw.marshal(), tx.signatures), nil
+}
@@ -0,0 +1,106 @@
+package tron
+
+import (
+	"encoding/hex"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func testTxInput() *TxInput {
+	input := NewTxInput()
+	input.RefBlockBytes = []byte{0x12, 0x34}
+	input.RefBlockHash = []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	input.Timestamp = 1700000000000
+	input.Expiration = 1700000600000
+	input.FeeLimit = 63_000_000
+	return input
+}
+
+func (s *CrosschainTestSuite) TestNewNativeTransfer() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.TRX})
+	tx, err := builder.NewTransfer(testAddress, testContract, xc.NewAmountBlockchainFromUint64(1_000_000), testTxInput())
+	require.NoError(err)
+
+	// the fee limit is only set for contract calls
+	raw := "0a0212342208010203040506070840c09fbaffbc315a67080112630a2d747970652e676f6f676c65617069732e636f6d2f70726f746f636f6c2e5472616e73666572436f6e747261637412320a15417e5f4552091a69125d5dfcb7b8c2659029395bdf121541a614f803b6fd780986a42c78ec9c7f77e6ded13c18c0843d7080d095ffbc31"
+	require.Equal(raw, hex.EncodeToString(tx.(*Tx).Raw.marshal()))
+	require.Equal(xc.TxHash("b873765df891c17caf370d61f35932977842777800532f644be080a603dd3f3b"), tx.Hash())
+	sighashes, err := tx.Sighashes()
+	require.NoError(err)
+	require.Equal(tx.Hash(), xc.TxHash(hex.EncodeToString(sighashes[0])))
+}
+
+func (s *CrosschainTestSuite) TestNewTokenTransfer() {
+	require := s.Require()
+	asset := &xc.TokenAssetConfig{Asset: "USDT", Contract: "TXYZopYRdj2D9XRtbG411XZZ3kM5VkAeBf", Decimals: 6, NativeAssetConfig: &xc.NativeAssetConfig{NativeAsset: xc.TRX}}
+	builder, _ := NewTxBuilder(asset)
+	tx, err := builder.NewTransfer(testAddress, testContract, xc.NewAmountBlockchainFromUint64(1_000_000), testTxInput())
+	require.NoError(err)
+
+	raw := "0a0212342208010203040506070840c09fbaffbc315aae01081f12a9010a31747970652e676f6f676c65617069732e636f6d2f70726f746f636f6c2e54726967676572536d617274436f6e747261637412740a15417e5f4552091a69125d5dfcb7b8c2659029395bdf121541eca9bc828a3005b9a3b909f2cc5c2a54794de05f2244a9059cbb000000000000000000000000a614f803b6fd780986a42c78ec9c7f77e6ded13c00000000000000000000000000000000000000000000000000000000000f42407080d095ffbc319001c09b851e"
+	require.Equal(raw, hex.EncodeToString(tx.(*Tx).Raw.marshal()))
+	require.Equal(xc.TxHash("316bfc17f4b2e065d76b5efda744902e4ed4c55b2c97086a84eaa7ac5ce731f3"), tx.Hash())
+}
+
+func (s *CrosschainTestSuite) TestNewTransferErrors() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.TRX})
+	amount := xc.NewAmountBlockchainFromUint64(1)
+
+	_, err := builder.NewTransfer(testAddress, testContract, amount, &struct{ xc.TxInputEnvelope }{})
+	require.EqualError(err, "xc.TxInput is not from a tron chain")
+	_, err = builder.NewTransfer("0x7e5f4552091a69125d5dfcb7b8c2659029395bdf", testContract, amount, testTxInput())
+	require.ErrorContains(err, "invalid from address")
+	_, err = builder.NewTransfer(testAddress, "invalid", amount, testTxInput())
+	require.ErrorContains(err, "invalid to address")
+
+	token, _ := NewTxBuilder(&xc.TokenAssetConfig{Asset: "USDT", NativeAssetConfig: &xc.NativeAssetConfig{NativeAsset: xc.TRX}})
+	_, err = token.NewTransfer(testAddress, testContract, amount, testTxInput())
+	require.ErrorContains(err, "invalid contract address")
+
+	_, err = (&Tx{}).Sighashes()
+	require.EqualError(err, "transaction not initialized")
+}
+
+func (s *CrosschainTestSuite) TestTxSignAndSerialize() {
+	require := s.Require()
+	builder, _ := NewTxBuilder(&xc.NativeAssetConfig{NativeAsset: xc.TRX})
+	tx, _ := builder.NewTransfer(testAddress, testContract, xc.NewAmountBlockchainFromUint64(1_000_000), testTxInput())
+
+	_, err := tx.Serialize()
+	require.ErrorContains(err, "unable to serialize without first calling AddSignatures")
+	require.EqualError(tx.AddSignatures([]byte{1, 2}), "invalid signature length 2")
+
+	signer, _ := NewSigner(&xc.NativeAssetConfig{})
+	privateKey, err := signer.ImportPrivateKey(hex.EncodeToString(common32(1)))
+	require.NoError(err)
+	sighashes, _ := tx.Sighashes()
+	signature, err := signer.Sign(privateKey, sighashes[0])
+	require.NoError(err)
+	require.NoError(tx.AddSignatures(signature))
+
+	serialized, err := tx.Serialize()
+	require.NoError(err)
+	raw := tx.(*Tx).Raw.marshal()
+	// Transaction: raw_data (1) and a signature (2) with a V of 27 or 28
+	require.Equal(byte(0x0a), serialized[0])
+	require.Equal(raw, serialized[3:3+len(raw)])
+	require.Equal([]byte{0x12, 65}, serialized[3+len(raw):5+len(raw)])
+	sig := serialized[5+len(raw):]
+	require.Len(sig, 65)
+	require.Contains([]byte{27, 28}, sig[64])
+
+	// the signer recovers to the address of the sender
+	recovered := append([]byte{}, sig...)
+	recovered[64] -= 27
+	publicKey, err := crypto.SigToPub(sighashes[0], recovered)
+	require.NoError(err)
+	address, _ := AddressBuilder{}.GetAddressFromPublicKey(crypto.FromECDSAPub(publicKey))
+	require.Equal(testAddress, address)
+
+	publicKeyBytes, err := signer.(xc.PublicKeyDeriver).DerivePublicKey(privateKey)
+	require.NoError(err)
+	require.Len(publicKeyBytes, 33)
+}
@@ -0,0 +1,37 @@
+package crosschain
+
+import (
+	"context"
+	"time"
+)
+
+// ChainStatsBlocks is the number of recent blocks over which the average block time is measured
+const ChainStatsBlocks = 20
+
+// ChainStats are statistics of the recent blocks of a chain, used by dashboards and fee strategies
+type ChainStats struct {
+	Chain  NativeAsset `json:"chain"`
+	Height uint64      `json:"height"`
+	// AverageBlockTime over the last ChainStatsBlocks blocks, or slots
+	AverageBlockTime time.Duration `json:"average_block_time"`
+	// BaseFee is the fee per unit required by the protocol, e.g. the EIP-1559 base fee, zero if none
+	BaseFee AmountBlockchain `json:"base_fee"`
+	// FeeRate is the recommended fee per unit, in the unit of the chain client EstimateGas
+	FeeRate AmountBlockchain `json:"fee_rate"`
+	// MempoolSize is the number of pending txs, nil if the provider doesn't expose its mempool
+	MempoolSize *uint64 `json:"mempool_size,omitempty"`
+}
+
+// ClientChainStats is a Client that can fetch statistics of the recent blocks of its chain
+type ClientChainStats interface {
+	FetchChainStats(ctx context.Context) (*ChainStats, error)
+}
+
+// AverageBlockTime returns the average time between blocks from the block at fromHeight to the block at toHeight,
+// zero if the heights are not increasing
+func AverageBlockTime(fromHeight uint64, fromTime time.Time, toHeight uint64, toTime time.Time) time.Duration {
+	if toHeight <= fromHeight {
+		return 0
+	}
+	return toTime.Sub(fromTime) / time.Duration(toHeight-fromHeight)
+}
@@ -0,0 +1,14 @@
+package crosschain
+
+import (
+	"time"
+)
+
+func (s *CrosschainTestSuite) TestAverageBlockTime() {
+	require := s.Require()
+	from := time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+	require.Equal(12*time.Second, AverageBlockTime(100, from, 120, from.Add(240*time.Second)))
+	require.Equal(400*time.Millisecond, AverageBlockTime(100, from, 250, from.Add(time.Minute)))
+	require.Zero(AverageBlockTime(100, from, 100, from))
+	require.Zero(AverageBlockTime(120, from, 100, from.Add(time.Minute)))
+}
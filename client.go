@@ -1,8 +1,12 @@
 package crosschain
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 // Client is a client that can fetch data and submit tx to a public blockchain
+// Clients are safe for concurrent use, see the package documentation
 type Client interface {
 	FetchTxInput(ctx context.Context, from Address, to Address) (TxInput, error)
 	FetchTxInfo(ctx context.Context, txHash TxHash) (TxInfo, error)
@@ -14,9 +18,16 @@ type EstimateGasFunc func(native NativeAsset) (AmountBlockchain, error)
 // GasEstimator is a specific Client that can estimate gas - not implemented yet
 type GasEstimator interface {
 	EstimateGas(ctx context.Context) (AmountBlockchain, error)
+	// RegisterEstimateGasCallback must be called before the client is used concurrently
 	RegisterEstimateGasCallback(fn EstimateGasFunc)
 }
 
+// FeeEstimator is a specific Client that can estimate the fee of a tx, in the native asset, before it's signed
+type FeeEstimator interface {
+	// EstimateFee estimates the fee paid by from to send tx, e.g. by simulating it
+	EstimateFee(ctx context.Context, from Address, tx Tx) (AmountBlockchain, error)
+}
+
 // ClientBalance is a specific Client that can fetch balances
 type ClientBalance interface {
 	// Fetch the balance of the asset that this client is configured for
@@ -24,6 +35,21 @@ type ClientBalance interface {
 	FetchNativeBalance(ctx context.Context, address Address) (AmountBlockchain, error)
 }
 
+// TokenMetadata is the metadata of a token read on chain
+// Symbols are chosen by token deployers: don't identify a token by its symbol
+type TokenMetadata struct {
+	Contract ContractAddress
+	Symbol   string
+	Decimals int32
+}
+
+// ClientTokenMetadata is a specific Client that can fetch the metadata of tokens
+type ClientTokenMetadata interface {
+	FetchTokenMetadata(ctx context.Context, contract ContractAddress) (TokenMetadata, error)
+}
+
+// FullClient is the chain agnostic client of transfers, implemented by the clients of all drivers
+// Use factory.NewClient to create the client of an asset
 type FullClient interface {
 	Client
 	ClientBalance
@@ -54,3 +80,9 @@ const NetworkError ClientError = "NetworkError"
 
 // No outcome for this error known
 const UnknownError ClientError = "UnknownError"
+
+// IsTxNotFound returns true for an error of FetchTxInfo of a tx unknown to the chain, e.g. never submitted or dropped
+// Drivers wrap the "not found" errors of their nodes, so it's matched by message
+func IsTxNotFound(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "not found")
+}
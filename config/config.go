@@ -124,7 +124,11 @@ func GetSecret(uri string) (string, error) {
 		if err != nil {
 			return "", err
 		}
-		data, _ := secret.Data["data"].(map[string]interface{})
+		// KV v2 nests the secret under data, KV v1 doesn't
+		data, ok := secret.Data["data"].(map[string]interface{})
+		if !ok {
+			data = secret.Data
+		}
 		result, _ := data[vaultKey].(string)
 		return strings.TrimSpace(result), nil
 	}
@@ -107,6 +107,9 @@ func (s *CrosschainTestSuite) TestGetSecretVault() {
 				"data": {
 					"secret2": "mysecret2"
 				}
+			},
+			"kv1/to": {
+				"secret": "mysecret1"
 			}
 		}`
 		data := make(map[string]interface{})
@@ -137,6 +140,10 @@ func (s *CrosschainTestSuite) TestGetSecretVault() {
 	require.NoError(err)
 	require.Equal("mysecret2", secret)
 
+	secret, err = GetSecret("vault:https://example.com,kv1/to/secret")
+	require.NoError(err)
+	require.Equal("mysecret1", secret)
+
 	secret, err = GetSecret("vault:https://example.com,path2/to/secret_none")
 	require.NoError(err)
 	require.Equal("", secret)
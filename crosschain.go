@@ -3,5 +3,14 @@ Package crosschain is a Go library to interact with multiple blockchains.
 
 Crosschain main design principle is to isolate network Client, Signer and tx Builder.
 This way you can build applications or micro services using just what you need and with the convenience of a unified interface.
+
+# Concurrency
+
+The Factory, Clients, Signers, AddressBuilders and TxBuilders are safe for concurrent use by multiple goroutines,
+and are meant to be created once and shared.
+Callbacks and detectors set with Register* methods on a Client (e.g. RegisterEstimateGasCallback) are configuration:
+register them before sharing the Client.
+TxInput and Tx values are not safe to share: a TxBuilder updates the TxInput it is given (e.g. its gas limit),
+so fetch or copy one TxInput per tx being built.
 */
 package crosschain
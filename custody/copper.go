@@ -0,0 +1,182 @@
+package custody
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// DefaultCopperURL is the URL of the Copper API
+const DefaultCopperURL = "https://api.copper.co"
+
+// CopperSigner signs sighashes with sign orders of a Copper portfolio, polled until executed
+// The private keys it's given are portfolio ids, see ImportPrivateKey
+type CopperSigner struct {
+	poller
+	Format SignatureFormat
+	// Currency is the Copper currency whose key signs, defaults to the asset of the chain
+	Currency string
+	// PublicKey recovers the recovery id of EVM and Bitcoin signatures, when orders don't return it
+	PublicKey xc.PublicKey
+}
+
+var _ xc.Signer = &CopperSigner{}
+
+// NewCopperSigner creates a CopperSigner for the chain of asset, authenticated with an API key and secret.
+// baseURL defaults to DefaultCopperURL
+func NewCopperSigner(asset xc.ITask, baseURL string, apiKey string, apiSecret string) (*CopperSigner, error) {
+	format, err := NewSignatureFormat(asset)
+	if err != nil {
+		return nil, err
+	}
+	if apiSecret == "" {
+		return nil, errors.New("missing secret for copper request signing")
+	}
+	if baseURL == "" {
+		baseURL = DefaultCopperURL
+	}
+	requestSigner := xc.RequestSignerFunc(func(req *http.Request, body []byte) error {
+		return signCopperRequest(req, body, apiKey, apiSecret, time.Now())
+	})
+	return &CopperSigner{
+		poller: poller{
+			Client:       &http.Client{Transport: xc.NewSigningTransport(nil, requestSigner), Timeout: 30 * time.Second},
+			BaseURL:      baseURL,
+			PollInterval: DefaultPollInterval,
+			Timeout:      DefaultTimeout,
+		},
+		Format:   format,
+		Currency: string(asset.GetNativeAsset().NativeAsset),
+	}, nil
+}
+
+// signCopperRequest sets the Copper authentication headers:
+// X-Signature is hex(HMAC-SHA256(secret, timestamp + method + path + body)), with a timestamp in milliseconds
+func signCopperRequest(req *http.Request, body []byte, apiKey string, apiSecret string, now time.Time) error {
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(apiSecret))
+	mac.Write([]byte(timestamp + req.Method + req.URL.RequestURI()))
+	mac.Write(body)
+
+	req.Header.Set("Authorization", "ApiKey "+apiKey)
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+// ImportPrivateKey returns a reference to the portfolio privateKey
+func (signer *CopperSigner) ImportPrivateKey(privateKey string) (xc.PrivateKey, error) {
+	portfolioID := strings.TrimSpace(privateKey)
+	if portfolioID == "" || strings.Contains(portfolioID, "/") {
+		return nil, fmt.Errorf("invalid copper portfolio id: '%s'", privateKey)
+	}
+	return xc.PrivateKey(portfolioID), nil
+}
+
+// copperOrderRequest is a sign order of a payload:
+// {"externalOrderId", "orderType": "sign", "portfolioId", "currency", "payload": {"data", "algorithm"}}
+type copperOrderRequest struct {
+	ExternalOrderID string `json:"externalOrderId"`
+	OrderType       string `json:"orderType"`
+	PortfolioID     string `json:"portfolioId"`
+	Currency        string `json:"currency"`
+	Payload         struct {
+		Data      string `json:"data"`
+		Algorithm string `json:"algorithm"`
+	} `json:"payload"`
+}
+
+type copperOrder struct {
+	OrderID string `json:"orderId"`
+	Status  string `json:"status"`
+	Extra   struct {
+		Signature string `json:"signature"`
+		PublicKey string `json:"publicKey"`
+		Reason    string `json:"reason"`
+	} `json:"extra"`
+}
+
+// Copper order statuses without signature
+var copperFailedStatuses = []string{"error", "rejected", "canceled"}
+
+// Sign creates a sign order of data from the portfolio privateKey and waits for its signature
+func (signer *CopperSigner) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	return signer.SignContext(context.Background(), privateKey, data)
+}
+
+// SignContext is Sign with a context
+func (signer *CopperSigner) SignContext(ctx context.Context, privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	request := copperOrderRequest{
+		ExternalOrderID: uuid.NewString(),
+		OrderType:       "sign",
+		PortfolioID:     string(privateKey),
+		Currency:        signer.Currency,
+	}
+	request.Payload.Data = hex.EncodeToString(data)
+	request.Payload.Algorithm = "ecdsa-secp256k1"
+	if signer.Format.Algorithm == xc.Ed255 {
+		request.Payload.Algorithm = "eddsa-ed25519"
+	}
+
+	created := copperOrder{}
+	if err := signer.do(ctx, http.MethodPost, "/platform/orders", request, &created); err != nil {
+		return nil, fmt.Errorf("could not create copper sign order: %v", err)
+	}
+	if created.OrderID == "" {
+		return nil, fmt.Errorf("copper sign order without id, status '%s'", created.Status)
+	}
+
+	var order copperOrder
+	err := signer.poll(ctx, func(ctx context.Context) (bool, error) {
+		order = copperOrder{}
+		if err := signer.do(ctx, http.MethodGet, "/platform/orders/"+created.OrderID, nil, &order); err != nil {
+			return false, fmt.Errorf("could not fetch copper order %s: %v", created.OrderID, err)
+		}
+		for _, status := range copperFailedStatuses {
+			if order.Status == status {
+				return false, fmt.Errorf("%w: copper order %s is %s (%s)", ErrSigningRejected, created.OrderID, order.Status, order.Extra.Reason)
+			}
+		}
+		return order.Status == "executed", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(order.Extra.Signature, "0x"))
+	if err != nil || len(signature) != 64 {
+		return nil, fmt.Errorf("invalid signature of copper order %s", created.OrderID)
+	}
+	if signer.Format.Algorithm == xc.Ed255 {
+		return xc.TxSignature(signature), nil
+	}
+	// R || S, with a low S
+	signature, err = NormalizeSignature(new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:]))
+	if err != nil {
+		return nil, err
+	}
+	if !signer.Format.Recoverable {
+		return xc.TxSignature(signature), nil
+	}
+	publicKey := signer.PublicKey
+	if order.Extra.PublicKey != "" {
+		if publicKey, err = hex.DecodeString(strings.TrimPrefix(order.Extra.PublicKey, "0x")); err != nil {
+			return nil, fmt.Errorf("invalid public key of copper order %s", created.OrderID)
+		}
+	}
+	if len(publicKey) == 0 {
+		return nil, errors.New("copper signer requires a public key to recover the signature")
+	}
+	return RecoverableSignature(signature, data, publicKey)
+}
@@ -0,0 +1,151 @@
+package custody
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func hmacHex(secret string, message string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// copperServer executes sign orders after a poll, signing with sign
+func copperServer(status string, sign func(data []byte) map[string]interface{}, orders *[]map[string]interface{}) *httptest.Server {
+	polls := 0
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		signature := hmacHex("secret", req.Header.Get("X-Timestamp")+req.Method+req.URL.RequestURI()+string(body))
+		if req.Header.Get("Authorization") != "ApiKey api-key" || req.Header.Get("X-Signature") != signature {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if req.Method == http.MethodPost && req.URL.Path == "/platform/orders" {
+			order := map[string]interface{}{}
+			json.Unmarshal(body, &order)
+			*orders = append(*orders, order)
+			rw.Write([]byte(`{"orderId":"order-1","status":"awaiting-settlement"}`))
+			return
+		}
+		if req.Method != http.MethodGet || req.URL.Path != "/platform/orders/order-1" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		polls++
+		if polls == 1 {
+			rw.Write([]byte(`{"orderId":"order-1","status":"working"}`))
+			return
+		}
+		order := (*orders)[len(*orders)-1]
+		data, _ := hex.DecodeString(order["payload"].(map[string]interface{})["data"].(string))
+		extra := sign(data)
+		extra["reason"] = "declined by approver"
+		json.NewEncoder(rw).Encode(map[string]interface{}{"orderId": "order-1", "status": status, "extra": extra})
+	}))
+}
+
+func (s *CrosschainTestSuite) TestCopperSignerSecp256k1() {
+	require := s.Require()
+	hash := crypto.Keccak256([]byte("tx"))
+	orders := []map[string]interface{}{}
+	server := copperServer("executed", func(data []byte) map[string]interface{} {
+		r, sig, _ := testSignature(data, true)
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		sig.FillBytes(signature[32:])
+		return map[string]interface{}{"signature": hex.EncodeToString(signature)}
+	}, &orders)
+	defer server.Close()
+
+	signer, err := NewCopperSigner(&xc.AssetConfig{Driver: string(xc.DriverEVM), NativeAsset: xc.ETH}, server.URL, "api-key", "secret")
+	require.NoError(err)
+	signer.PollInterval = time.Millisecond
+	privateKey, err := signer.ImportPrivateKey("portfolio-1")
+	require.NoError(err)
+
+	// the recovery id requires the public key
+	_, err = signer.Sign(privateKey, hash)
+	require.EqualError(err, "copper signer requires a public key to recover the signature")
+
+	signer.PublicKey = testPublicKey()
+	_, _, expected := testSignature(hash, false)
+	signature, err := signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected), hex.EncodeToString(signature))
+	require.Equal("sign", orders[1]["orderType"])
+	require.Equal("portfolio-1", orders[1]["portfolioId"])
+	require.Equal("ETH", orders[1]["currency"])
+	require.Equal("ecdsa-secp256k1", orders[1]["payload"].(map[string]interface{})["algorithm"])
+	require.NotEqual(orders[0]["externalOrderId"], orders[1]["externalOrderId"])
+
+	// cosmos signatures have no recovery id
+	signer, err = NewCopperSigner(&xc.AssetConfig{Driver: string(xc.DriverCosmos), NativeAsset: xc.ATOM}, server.URL, "api-key", "secret")
+	require.NoError(err)
+	signer.PollInterval = time.Millisecond
+	signature, err = signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected[:64]), hex.EncodeToString(signature))
+}
+
+func (s *CrosschainTestSuite) TestCopperSignerEd25519() {
+	require := s.Require()
+	publicKey, key, _ := ed25519.GenerateKey(nil)
+	orders := []map[string]interface{}{}
+	server := copperServer("executed", func(data []byte) map[string]interface{} {
+		return map[string]interface{}{"signature": hex.EncodeToString(ed25519.Sign(key, data))}
+	}, &orders)
+	defer server.Close()
+
+	signer, err := NewCopperSigner(&xc.AssetConfig{Driver: string(xc.DriverSolana), NativeAsset: xc.SOL}, server.URL, "api-key", "secret")
+	require.NoError(err)
+	signer.PollInterval = time.Millisecond
+	signature, err := signer.Sign(xc.PrivateKey("portfolio-1"), []byte("message"))
+	require.NoError(err)
+	require.True(ed25519.Verify(publicKey, []byte("message"), signature))
+	require.Equal("eddsa-ed25519", orders[0]["payload"].(map[string]interface{})["algorithm"])
+}
+
+func (s *CrosschainTestSuite) TestCopperSignerErrors() {
+	require := s.Require()
+	orders := []map[string]interface{}{}
+	server := copperServer("rejected", func(data []byte) map[string]interface{} {
+		return map[string]interface{}{}
+	}, &orders)
+	defer server.Close()
+
+	_, err := NewCopperSigner(&xc.AssetConfig{Driver: string(xc.DriverSolana)}, server.URL, "api-key", "")
+	require.EqualError(err, "missing secret for copper request signing")
+
+	signer, err := NewCopperSigner(&xc.AssetConfig{Driver: string(xc.DriverSolana)}, server.URL, "api-key", "secret")
+	require.NoError(err)
+	signer.PollInterval = time.Millisecond
+	_, err = signer.ImportPrivateKey(" ")
+	require.EqualError(err, "invalid copper portfolio id: ' '")
+
+	_, err = signer.Sign(xc.PrivateKey("portfolio-1"), []byte("message"))
+	require.True(errors.Is(err, ErrSigningRejected))
+	require.ErrorContains(err, "copper order order-1 is rejected (declined by approver)")
+}
+
+func (s *CrosschainTestSuite) TestSignCopperRequest() {
+	require := s.Require()
+	req, _ := http.NewRequest(http.MethodPost, "https://api.copper.co/platform/orders?a=b", nil)
+	err := signCopperRequest(req, []byte(`{}`), "api-key", "secret", time.UnixMilli(1700000000123))
+	require.NoError(err)
+	require.Equal("ApiKey api-key", req.Header.Get("Authorization"))
+	require.Equal("1700000000123", req.Header.Get("X-Timestamp"))
+	expected := hmacHex("secret", `1700000000123POST/platform/orders?a=b{}`)
+	require.Equal(expected, req.Header.Get("X-Signature"))
+}
@@ -0,0 +1,181 @@
+package custody
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Defaults of custody signers
+const (
+	DefaultPollInterval = 2 * time.Second
+	DefaultTimeout      = 10 * time.Minute
+)
+
+// ErrSigningRejected is returned when a signing request reaches a terminal state without a signature,
+// e.g. rejected by an approver or blocked by a policy
+var ErrSigningRejected = errors.New("signing request rejected")
+
+// SignatureFormat is the signature format expected by the txs of a chain
+type SignatureFormat struct {
+	Algorithm xc.SignatureType
+	// Recoverable k256 signatures end with the recovery id
+	Recoverable bool
+}
+
+// NewSignatureFormat returns the signature format of the chain of asset
+func NewSignatureFormat(asset xc.ITask) (SignatureFormat, error) {
+	driver := xc.Driver(asset.GetDriver())
+	algorithm := driver.SignatureAlgorithm()
+	if algorithm != xc.K256 && algorithm != xc.Ed255 {
+		return SignatureFormat{}, fmt.Errorf("unsupported driver for custody signing: '%s'", driver)
+	}
+	return SignatureFormat{
+		Algorithm:   algorithm,
+		Recoverable: algorithm == xc.K256 && driver.RecoverableSignature(),
+	}, nil
+}
+
+// poller polls a custody API for the result of a signing request
+type poller struct {
+	Client       *http.Client
+	BaseURL      string
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// do sends a JSON request and decodes the JSON response into result
+func (p *poller) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(p.BaseURL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return xc.DefaultRedactor.RedactError(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, xc.DefaultRedactor.Redact(string(data)))
+	}
+	return json.Unmarshal(data, result)
+}
+
+// poll calls check every PollInterval until it's done, or Timeout
+func (p *poller) poll(ctx context.Context, check func(ctx context.Context) (bool, error)) error {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	interval := p.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		done, err := check(ctx)
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("signing request not completed after %v", timeout)
+		case <-time.After(interval):
+		}
+	}
+}
+
+var secp256k1N = crypto.S256().Params().N
+var secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+
+// ParseDERSignature returns the R || S form of an ASN.1 DER secp256k1 signature, with a low S as required by most chains
+func ParseDERSignature(der []byte) ([]byte, error) {
+	var signature struct {
+		R *big.Int
+		S *big.Int
+	}
+	rest, err := asn1.Unmarshal(der, &signature)
+	if err != nil || len(rest) > 0 || signature.R == nil || signature.S == nil {
+		return nil, errors.New("invalid DER signature")
+	}
+	return NormalizeSignature(signature.R, signature.S)
+}
+
+// NormalizeSignature returns the R || S form of a secp256k1 signature, with a low S
+func NormalizeSignature(r *big.Int, s *big.Int) ([]byte, error) {
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s = new(big.Int).Sub(secp256k1N, s)
+	}
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.BitLen() > 256 || s.BitLen() > 256 {
+		return nil, errors.New("invalid secp256k1 signature")
+	}
+	result := make([]byte, 64)
+	r.FillBytes(result[:32])
+	s.FillBytes(result[32:])
+	return result, nil
+}
+
+// RecoverableSignature appends to an R || S signature of hash the recovery id that recovers publicKey
+func RecoverableSignature(signature []byte, hash []byte, publicKey xc.PublicKey) (xc.TxSignature, error) {
+	if len(signature) != 64 {
+		return nil, fmt.Errorf("invalid secp256k1 signature length: %d", len(signature))
+	}
+	recoverable := make([]byte, 65)
+	copy(recoverable, signature)
+	for v := byte(0); v < 2; v++ {
+		recoverable[64] = v
+		recovered, err := crypto.SigToPub(hash, recoverable)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(crypto.CompressPubkey(recovered), publicKey) || bytes.Equal(crypto.FromECDSAPub(recovered), publicKey) {
+			return xc.TxSignature(recoverable), nil
+		}
+	}
+	return nil, errors.New("signature doesn't match the public key")
+}
+
+// ParsePublicKeyInfo returns the compressed secp256k1 public key of a DER encoded SubjectPublicKeyInfo,
+// the format of the public keys of KMS and HSM, as x509 doesn't support the secp256k1 curve
+func ParsePublicKeyInfo(der []byte) (xc.PublicKey, error) {
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+	publicKey, err := crypto.UnmarshalPubkey(info.PublicKey.Bytes)
+	if err != nil {
+		if publicKey, err = crypto.DecompressPubkey(info.PublicKey.Bytes); err != nil {
+			return nil, err
+		}
+	}
+	return crypto.CompressPubkey(publicKey), nil
+}
@@ -0,0 +1,107 @@
+package custody
+
+import (
+	"context"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestCustodyTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+// testSignature signs like custody platforms, returning R and S with a high S if highS
+func testSignature(hash []byte, highS bool) (*big.Int, *big.Int, []byte) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	signature, _ := crypto.Sign(hash, key)
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:64])
+	if highS {
+		s = new(big.Int).Sub(secp256k1N, s)
+	}
+	return r, s, signature
+}
+
+func testPublicKey() []byte {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	return crypto.CompressPubkey(&key.PublicKey)
+}
+
+func (s *CrosschainTestSuite) TestNewSignatureFormat() {
+	require := s.Require()
+	format, err := NewSignatureFormat(&xc.AssetConfig{Driver: string(xc.DriverEVM)})
+	require.NoError(err)
+	require.Equal(SignatureFormat{Algorithm: xc.K256, Recoverable: true}, format)
+
+	format, err = NewSignatureFormat(&xc.AssetConfig{Driver: string(xc.DriverCosmos)})
+	require.NoError(err)
+	require.Equal(SignatureFormat{Algorithm: xc.K256}, format)
+
+	format, err = NewSignatureFormat(&xc.AssetConfig{Driver: string(xc.DriverSolana)})
+	require.NoError(err)
+	require.Equal(SignatureFormat{Algorithm: xc.Ed255}, format)
+
+	_, err = NewSignatureFormat(&xc.AssetConfig{Driver: "unknown"})
+	require.EqualError(err, "unsupported driver for custody signing: 'unknown'")
+}
+
+func (s *CrosschainTestSuite) TestParseDERSignature() {
+	require := s.Require()
+	hash := crypto.Keccak256([]byte("tx"))
+	for _, highS := range []bool{false, true} {
+		r, sig, expected := testSignature(hash, highS)
+		der, _ := asn1.Marshal(struct{ R, S *big.Int }{r, sig})
+		signature, err := ParseDERSignature(der)
+		require.NoError(err)
+		require.Equal(hex.EncodeToString(expected[:64]), hex.EncodeToString(signature))
+	}
+
+	_, err := ParseDERSignature([]byte{1, 2, 3})
+	require.EqualError(err, "invalid DER signature")
+	_, err = NormalizeSignature(big.NewInt(0), big.NewInt(1))
+	require.EqualError(err, "invalid secp256k1 signature")
+}
+
+func (s *CrosschainTestSuite) TestRecoverableSignature() {
+	require := s.Require()
+	hash := crypto.Keccak256([]byte("tx"))
+	_, _, expected := testSignature(hash, false)
+
+	signature, err := RecoverableSignature(expected[:64], hash, testPublicKey())
+	require.NoError(err)
+	require.Equal(expected, []byte(signature))
+
+	other, _ := crypto.GenerateKey()
+	_, err = RecoverableSignature(expected[:64], hash, crypto.FromECDSAPub(&other.PublicKey))
+	require.EqualError(err, "signature doesn't match the public key")
+	_, err = RecoverableSignature(expected, hash, testPublicKey())
+	require.EqualError(err, "invalid secp256k1 signature length: 65")
+}
+
+func (s *CrosschainTestSuite) TestPollTimeout() {
+	require := s.Require()
+	p := &poller{PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond}
+	checks := 0
+	err := p.poll(s.Ctx, func(ctx context.Context) (bool, error) {
+		checks++
+		return false, nil
+	})
+	require.EqualError(err, "signing request not completed after 20ms")
+	require.Greater(checks, 1)
+}
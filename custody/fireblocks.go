@@ -0,0 +1,201 @@
+package custody
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// DefaultFireblocksURL is the URL of the Fireblocks API
+const DefaultFireblocksURL = "https://api.fireblocks.io"
+
+// Fireblocks RAW signing algorithms
+const (
+	FireblocksECDSASecp256k1 = "MPC_ECDSA_SECP256K1"
+	FireblocksEdDSAEd25519   = "MPC_EDDSA_ED25519"
+)
+
+// fireblocksAssetIDs are the Fireblocks asset ids of chains whose id isn't their native asset, for key derivation
+var fireblocksAssetIDs = map[xc.NativeAsset]string{
+	xc.ATOM:  "ATOM_COS",
+	xc.BNB:   "BNB_BSC",
+	xc.MATIC: "MATIC_POLYGON",
+	xc.FTM:   "FTM_FANTOM",
+}
+
+// FireblocksSigner signs sighashes with RAW signing transactions of a Fireblocks vault account, polled until signed
+// The private keys it's given are vault account ids, see ImportPrivateKey
+type FireblocksSigner struct {
+	poller
+	Format SignatureFormat
+	// AssetID is the Fireblocks asset whose key signs, defaults to the asset of the chain
+	AssetID string
+	// Note is set on the RAW signing transactions, e.g. to help approvers
+	Note string
+}
+
+var _ xc.Signer = &FireblocksSigner{}
+
+// NewFireblocksSigner creates a FireblocksSigner for the chain of asset, authenticated with an API key and
+// its PEM encoded private key. baseURL defaults to DefaultFireblocksURL
+func NewFireblocksSigner(asset xc.ITask, baseURL string, apiKey string, privateKeyPEM string) (*FireblocksSigner, error) {
+	format, err := NewSignatureFormat(asset)
+	if err != nil {
+		return nil, err
+	}
+	requestSigner, err := xc.NewJWTSigner(apiKey, privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if baseURL == "" {
+		baseURL = DefaultFireblocksURL
+	}
+	native := asset.GetNativeAsset().NativeAsset
+	assetID, ok := fireblocksAssetIDs[native]
+	if !ok {
+		assetID = string(native)
+	}
+	return &FireblocksSigner{
+		poller: poller{
+			Client:       &http.Client{Transport: xc.NewSigningTransport(nil, requestSigner), Timeout: 30 * time.Second},
+			BaseURL:      baseURL,
+			PollInterval: DefaultPollInterval,
+			Timeout:      DefaultTimeout,
+		},
+		Format:  format,
+		AssetID: assetID,
+	}, nil
+}
+
+// ImportPrivateKey returns a reference to the vault account privateKey
+func (signer *FireblocksSigner) ImportPrivateKey(privateKey string) (xc.PrivateKey, error) {
+	vaultAccountID := strings.TrimSpace(privateKey)
+	if vaultAccountID == "" || strings.Contains(vaultAccountID, "/") {
+		return nil, fmt.Errorf("invalid fireblocks vault account id: '%s'", privateKey)
+	}
+	return xc.PrivateKey(vaultAccountID), nil
+}
+
+type fireblocksRawMessage struct {
+	Content string `json:"content"`
+}
+
+type fireblocksTransactionRequest struct {
+	Operation string `json:"operation"`
+	AssetID   string `json:"assetId"`
+	Source    struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	} `json:"source"`
+	Note            string `json:"note,omitempty"`
+	ExtraParameters struct {
+		RawMessageData struct {
+			Messages  []fireblocksRawMessage `json:"messages"`
+			Algorithm string                 `json:"algorithm"`
+		} `json:"rawMessageData"`
+	} `json:"extraParameters"`
+}
+
+type fireblocksTransaction struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	SubStatus      string `json:"subStatus"`
+	SignedMessages []struct {
+		Content   string `json:"content"`
+		PublicKey string `json:"publicKey"`
+		Signature struct {
+			FullSig string `json:"fullSig"`
+			R       string `json:"r"`
+			S       string `json:"s"`
+			V       *int   `json:"v"`
+		} `json:"signature"`
+	} `json:"signedMessages"`
+}
+
+// Fireblocks transaction statuses without signature
+var fireblocksFailedStatuses = []string{"FAILED", "REJECTED", "BLOCKED", "CANCELLED", "TIMEOUT"}
+
+// Sign creates a RAW signing transaction of data from the vault account privateKey and waits for its signature
+func (signer *FireblocksSigner) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	return signer.SignContext(context.Background(), privateKey, data)
+}
+
+// SignContext is Sign with a context
+func (signer *FireblocksSigner) SignContext(ctx context.Context, privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	request := fireblocksTransactionRequest{
+		Operation: "RAW",
+		AssetID:   signer.AssetID,
+		Note:      signer.Note,
+	}
+	request.Source.Type = "VAULT_ACCOUNT"
+	request.Source.ID = string(privateKey)
+	request.ExtraParameters.RawMessageData.Messages = []fireblocksRawMessage{{Content: hex.EncodeToString(data)}}
+	request.ExtraParameters.RawMessageData.Algorithm = FireblocksECDSASecp256k1
+	if signer.Format.Algorithm == xc.Ed255 {
+		request.ExtraParameters.RawMessageData.Algorithm = FireblocksEdDSAEd25519
+	}
+
+	created := fireblocksTransaction{}
+	if err := signer.do(ctx, http.MethodPost, "/v1/transactions", request, &created); err != nil {
+		return nil, fmt.Errorf("could not create fireblocks raw signing transaction: %v", err)
+	}
+	if created.ID == "" {
+		return nil, fmt.Errorf("fireblocks raw signing transaction without id, status '%s'", created.Status)
+	}
+
+	var signed fireblocksTransaction
+	err := signer.poll(ctx, func(ctx context.Context) (bool, error) {
+		signed = fireblocksTransaction{}
+		if err := signer.do(ctx, http.MethodGet, "/v1/transactions/"+created.ID, nil, &signed); err != nil {
+			return false, fmt.Errorf("could not fetch fireblocks transaction %s: %v", created.ID, err)
+		}
+		for _, status := range fireblocksFailedStatuses {
+			if signed.Status == status {
+				return false, fmt.Errorf("%w: fireblocks transaction %s is %s (%s)", ErrSigningRejected, created.ID, signed.Status, signed.SubStatus)
+			}
+		}
+		return signed.Status == "COMPLETED", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(signed.SignedMessages) != 1 {
+		return nil, fmt.Errorf("fireblocks transaction %s has %d signed messages", created.ID, len(signed.SignedMessages))
+	}
+	message := signed.SignedMessages[0]
+	if !strings.EqualFold(message.Content, hex.EncodeToString(data)) {
+		return nil, fmt.Errorf("fireblocks transaction %s signed another message", created.ID)
+	}
+
+	if signer.Format.Algorithm == xc.Ed255 {
+		signature, err := hex.DecodeString(message.Signature.FullSig)
+		if err != nil || len(signature) != 64 {
+			return nil, fmt.Errorf("invalid ed25519 signature of fireblocks transaction %s", created.ID)
+		}
+		return xc.TxSignature(signature), nil
+	}
+	r, okR := new(big.Int).SetString(message.Signature.R, 16)
+	s, okS := new(big.Int).SetString(message.Signature.S, 16)
+	if !okR || !okS {
+		return nil, fmt.Errorf("invalid secp256k1 signature of fireblocks transaction %s", created.ID)
+	}
+	signature, err := NormalizeSignature(r, s)
+	if err != nil {
+		return nil, err
+	}
+	if !signer.Format.Recoverable {
+		return xc.TxSignature(signature), nil
+	}
+	// the recovery id changes if S was normalized, recover it from the public key
+	publicKey, err := hex.DecodeString(message.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of fireblocks transaction %s", created.ID)
+	}
+	return RecoverableSignature(signature, data, publicKey)
+}
@@ -0,0 +1,151 @@
+package custody
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+func fireblocksAPIKey() string {
+	_, key, _ := ed25519.GenerateKey(nil)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(key)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+}
+
+// fireblocksServer completes RAW signing transactions after a poll, signing with sign
+func fireblocksServer(status string, sign func(content []byte) map[string]interface{}, requests *[]map[string]interface{}) *httptest.Server {
+	polls := 0
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-API-Key") != "api-key" || !strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ") {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if req.Method == http.MethodPost && req.URL.Path == "/v1/transactions" {
+			request := map[string]interface{}{}
+			json.NewDecoder(req.Body).Decode(&request)
+			*requests = append(*requests, request)
+			rw.Write([]byte(`{"id":"tx-1","status":"SUBMITTED"}`))
+			return
+		}
+		if req.Method != http.MethodGet || req.URL.Path != "/v1/transactions/tx-1" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		polls++
+		if polls == 1 {
+			rw.Write([]byte(`{"id":"tx-1","status":"PENDING_SIGNATURE"}`))
+			return
+		}
+		request := (*requests)[len(*requests)-1]
+		messages := request["extraParameters"].(map[string]interface{})["rawMessageData"].(map[string]interface{})["messages"].([]interface{})
+		content := messages[0].(map[string]interface{})["content"].(string)
+		data, _ := hex.DecodeString(content)
+		signed := sign(data)
+		signed["content"] = content
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"id":             "tx-1",
+			"status":         status,
+			"subStatus":      "BLOCKED_BY_POLICY",
+			"signedMessages": []interface{}{signed},
+		})
+	}))
+}
+
+func (s *CrosschainTestSuite) TestFireblocksSignerSecp256k1() {
+	require := s.Require()
+	hash := crypto.Keccak256([]byte("tx"))
+	requests := []map[string]interface{}{}
+	server := fireblocksServer("COMPLETED", func(content []byte) map[string]interface{} {
+		r, sig, _ := testSignature(content, true)
+		return map[string]interface{}{
+			"publicKey": hex.EncodeToString(testPublicKey()),
+			"signature": map[string]interface{}{"r": r.Text(16), "s": sig.Text(16), "v": 1},
+		}
+	}, &requests)
+	defer server.Close()
+
+	signer, err := NewFireblocksSigner(&xc.AssetConfig{Driver: string(xc.DriverEVM), NativeAsset: xc.MATIC}, server.URL, "api-key", fireblocksAPIKey())
+	require.NoError(err)
+	signer.PollInterval = time.Millisecond
+	require.Equal("MATIC_POLYGON", signer.AssetID)
+	privateKey, err := signer.ImportPrivateKey("7")
+	require.NoError(err)
+
+	// high S is normalized, with the recovery id of the normalized signature
+	_, _, expected := testSignature(hash, false)
+	signature, err := signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected), hex.EncodeToString(signature))
+	require.Equal("RAW", requests[0]["operation"])
+	require.Equal("MATIC_POLYGON", requests[0]["assetId"])
+	require.Equal(map[string]interface{}{"type": "VAULT_ACCOUNT", "id": "7"}, requests[0]["source"])
+	require.Equal(FireblocksECDSASecp256k1, requests[0]["extraParameters"].(map[string]interface{})["rawMessageData"].(map[string]interface{})["algorithm"])
+
+	// cosmos signatures have no recovery id
+	signer, err = NewFireblocksSigner(&xc.AssetConfig{Driver: string(xc.DriverCosmos), NativeAsset: xc.ATOM}, server.URL, "api-key", fireblocksAPIKey())
+	require.NoError(err)
+	signer.PollInterval = time.Millisecond
+	require.Equal("ATOM_COS", signer.AssetID)
+	signature, err = signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected[:64]), hex.EncodeToString(signature))
+}
+
+func (s *CrosschainTestSuite) TestFireblocksSignerEd25519() {
+	require := s.Require()
+	publicKey, key, _ := ed25519.GenerateKey(nil)
+	requests := []map[string]interface{}{}
+	server := fireblocksServer("COMPLETED", func(content []byte) map[string]interface{} {
+		return map[string]interface{}{
+			"signature": map[string]interface{}{"fullSig": hex.EncodeToString(ed25519.Sign(key, content))},
+		}
+	}, &requests)
+	defer server.Close()
+
+	signer, err := NewFireblocksSigner(&xc.AssetConfig{Driver: string(xc.DriverSolana), NativeAsset: xc.SOL}, server.URL, "api-key", fireblocksAPIKey())
+	require.NoError(err)
+	signer.PollInterval = time.Millisecond
+	require.Equal("SOL", signer.AssetID)
+
+	signature, err := signer.Sign(xc.PrivateKey("7"), []byte("message"))
+	require.NoError(err)
+	require.True(ed25519.Verify(publicKey, []byte("message"), signature))
+	require.Equal(FireblocksEdDSAEd25519, requests[0]["extraParameters"].(map[string]interface{})["rawMessageData"].(map[string]interface{})["algorithm"])
+}
+
+func (s *CrosschainTestSuite) TestFireblocksSignerErrors() {
+	require := s.Require()
+	requests := []map[string]interface{}{}
+	server := fireblocksServer("BLOCKED", func(content []byte) map[string]interface{} {
+		return map[string]interface{}{}
+	}, &requests)
+	defer server.Close()
+
+	_, err := NewFireblocksSigner(&xc.AssetConfig{Driver: string(xc.DriverSolana)}, server.URL, "api-key", "")
+	require.ErrorContains(err, "no PEM block")
+
+	signer, err := NewFireblocksSigner(&xc.AssetConfig{Driver: string(xc.DriverSolana)}, server.URL, "api-key", fireblocksAPIKey())
+	require.NoError(err)
+	signer.PollInterval = time.Millisecond
+	_, err = signer.ImportPrivateKey("../users")
+	require.EqualError(err, "invalid fireblocks vault account id: '../users'")
+
+	_, err = signer.Sign(xc.PrivateKey("7"), []byte("message"))
+	require.True(errors.Is(err, ErrSigningRejected))
+	require.ErrorContains(err, "fireblocks transaction tx-1 is BLOCKED (BLOCKED_BY_POLICY)")
+
+	requestSigner, _ := xc.NewJWTSigner("other-key", fireblocksAPIKey())
+	signer.Client.Transport = xc.NewSigningTransport(nil, requestSigner)
+	_, err = signer.Sign(xc.PrivateKey("7"), []byte("message"))
+	require.EqualError(err, "could not create fireblocks raw signing transaction: POST /v1/transactions returned 401 Unauthorized: ")
+}
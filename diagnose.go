@@ -0,0 +1,141 @@
+package crosschain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// StuckReason is why a tx is not confirmed, or failed
+type StuckReason string
+
+// List of StuckReason
+const (
+	// StuckReasonNone is returned for confirmed txs
+	StuckReasonNone StuckReason = ""
+	// StuckReasonPending is returned for txs that are expected to confirm
+	StuckReasonPending          StuckReason = "pending"
+	StuckReasonFeeTooLow        StuckReason = "fee_too_low"
+	StuckReasonNonceGap         StuckReason = "nonce_gap"
+	StuckReasonBlockhashExpired StuckReason = "blockhash_expired"
+	StuckReasonOutOfGas         StuckReason = "out_of_gas"
+	StuckReasonMemoRequired     StuckReason = "memo_required"
+	StuckReasonProviderLagging  StuckReason = "provider_lagging"
+	// StuckReasonDropped is returned for txs unknown to the chain after being submitted
+	StuckReasonDropped StuckReason = "dropped"
+	// StuckReasonFailed is returned for txs that failed for another reason
+	StuckReasonFailed StuckReason = "failed"
+)
+
+// Remediation is the action an orchestrator should apply to a stuck tx
+type Remediation string
+
+// List of Remediation
+const (
+	RemediationNone Remediation = "none"
+	// RemediationWait is to check the tx again later
+	RemediationWait Remediation = "wait"
+	// RemediationBumpFee is to replace the tx with a higher fee, keeping its nonce
+	RemediationBumpFee Remediation = "bump_fee"
+	// RemediationFillNonceGap is to submit the missing txs of lower nonces first
+	RemediationFillNonceGap Remediation = "fill_nonce_gap"
+	// RemediationRebuild is to build, sign and submit the transfer again, e.g. with a new blockhash
+	RemediationRebuild Remediation = "rebuild"
+	// RemediationRaiseGasLimit is to build the transfer again with a higher gas limit
+	RemediationRaiseGasLimit Remediation = "raise_gas_limit"
+	// RemediationAddMemo is to build the transfer again with the memo required by the destination
+	RemediationAddMemo Remediation = "add_memo"
+	// RemediationSwitchProvider is to check the tx again with another RPC provider
+	RemediationSwitchProvider Remediation = "switch_provider"
+	// RemediationManual requires an operator
+	RemediationManual Remediation = "manual"
+)
+
+// Remediation returns the recommended action for reason
+func (reason StuckReason) Remediation() Remediation {
+	switch reason {
+	case StuckReasonNone:
+		return RemediationNone
+	case StuckReasonPending:
+		return RemediationWait
+	case StuckReasonFeeTooLow:
+		return RemediationBumpFee
+	case StuckReasonNonceGap:
+		return RemediationFillNonceGap
+	case StuckReasonBlockhashExpired, StuckReasonDropped:
+		return RemediationRebuild
+	case StuckReasonOutOfGas:
+		return RemediationRaiseGasLimit
+	case StuckReasonMemoRequired:
+		return RemediationAddMemo
+	case StuckReasonProviderLagging:
+		return RemediationSwitchProvider
+	}
+	return RemediationManual
+}
+
+// DefaultPropagationDelay is the time after submission during which a tx unknown to the chain is still propagating
+const DefaultPropagationDelay = time.Minute
+
+// DefaultProviderLagThreshold is the age of the latest block after which a provider is considered lagging
+const DefaultProviderLagThreshold = 5 * time.Minute
+
+// DiagnoseRequest identifies the tx to diagnose, e.g. from the state of a transfer
+type DiagnoseRequest struct {
+	TxHash TxHash
+	From   Address
+	To     Address
+	// SubmittedAt tells txs still propagating from dropped txs, if set
+	SubmittedAt time.Time
+}
+
+// Diagnosis is the reason a tx is stuck and the recommended remediation
+type Diagnosis struct {
+	TxHash      TxHash      `json:"tx_hash"`
+	Reason      StuckReason `json:"reason"`
+	Remediation Remediation `json:"remediation"`
+	// Details are human readable, e.g. the error of a failed tx
+	Details string `json:"details,omitempty"`
+}
+
+// NewDiagnosis creates a Diagnosis with the remediation of reason
+func NewDiagnosis(txHash TxHash, reason StuckReason, details string) *Diagnosis {
+	return &Diagnosis{
+		TxHash:      txHash,
+		Reason:      reason,
+		Remediation: reason.Remediation(),
+		Details:     details,
+	}
+}
+
+// ClientDiagnose is a Client that can inspect the chain to diagnose stuck txs
+type ClientDiagnose interface {
+	Diagnose(ctx context.Context, request DiagnoseRequest) (*Diagnosis, error)
+}
+
+// IsPropagating returns true if a tx unknown to the chain may still be propagating
+func (request *DiagnoseRequest) IsPropagating(now time.Time) bool {
+	return request.SubmittedAt.IsZero() || now.Sub(request.SubmittedAt) < DefaultPropagationDelay
+}
+
+// StuckReasonFromError classifies the error message of a failed or rejected tx
+func StuckReasonFromError(message string) StuckReason {
+	msg := strings.ToLower(message)
+	switch {
+	case msg == "":
+		return StuckReasonNone
+	case strings.Contains(msg, "out of gas"), strings.Contains(msg, "intrinsic gas too low"),
+		strings.Contains(msg, "computationalbudgetexceeded"), strings.Contains(msg, "insufficient gas"):
+		return StuckReasonOutOfGas
+	case strings.Contains(msg, "memo"), strings.Contains(msg, "destination tag"):
+		return StuckReasonMemoRequired
+	case strings.Contains(msg, "blockhash not found"), strings.Contains(msg, "block height exceeded"):
+		return StuckReasonBlockhashExpired
+	case strings.Contains(msg, "underpriced"), strings.Contains(msg, "fee too low"),
+		strings.Contains(msg, "insufficient fee"), strings.Contains(msg, "max fee per gas less than block base fee"):
+		return StuckReasonFeeTooLow
+	case strings.Contains(msg, "nonce too high"):
+		return StuckReasonNonceGap
+	}
+	return StuckReasonFailed
+}
@@ -0,0 +1,53 @@
+package crosschain
+
+import (
+	"time"
+)
+
+func (s *CrosschainTestSuite) TestStuckReasonRemediation() {
+	require := s.Require()
+	require.Equal(RemediationNone, StuckReasonNone.Remediation())
+	require.Equal(RemediationWait, StuckReasonPending.Remediation())
+	require.Equal(RemediationBumpFee, StuckReasonFeeTooLow.Remediation())
+	require.Equal(RemediationFillNonceGap, StuckReasonNonceGap.Remediation())
+	require.Equal(RemediationRebuild, StuckReasonBlockhashExpired.Remediation())
+	require.Equal(RemediationRebuild, StuckReasonDropped.Remediation())
+	require.Equal(RemediationRaiseGasLimit, StuckReasonOutOfGas.Remediation())
+	require.Equal(RemediationAddMemo, StuckReasonMemoRequired.Remediation())
+	require.Equal(RemediationSwitchProvider, StuckReasonProviderLagging.Remediation())
+	require.Equal(RemediationManual, StuckReasonFailed.Remediation())
+	require.Equal(RemediationManual, StuckReason("unknown").Remediation())
+
+	diagnosis := NewDiagnosis("0xabc", StuckReasonFeeTooLow, "details")
+	require.Equal(&Diagnosis{TxHash: "0xabc", Reason: StuckReasonFeeTooLow, Remediation: RemediationBumpFee, Details: "details"}, diagnosis)
+}
+
+func (s *CrosschainTestSuite) TestStuckReasonFromError() {
+	require := s.Require()
+	vectors := []struct {
+		message string
+		reason  StuckReason
+	}{
+		{"", StuckReasonNone},
+		{"out of gas", StuckReasonOutOfGas},
+		{"intrinsic gas too low", StuckReasonOutOfGas},
+		{"{InstructionError:[0 ComputationalBudgetExceeded]}", StuckReasonOutOfGas},
+		{"tx_malformed: destination tag required", StuckReasonMemoRequired},
+		{"Transaction simulation failed: Blockhash not found", StuckReasonBlockhashExpired},
+		{"replacement transaction underpriced", StuckReasonFeeTooLow},
+		{"insufficient fee; got: 10uatom required: 20uatom", StuckReasonFeeTooLow},
+		{"nonce too high", StuckReasonNonceGap},
+		{"execution reverted", StuckReasonFailed},
+	}
+	for _, v := range vectors {
+		require.Equal(v.reason, StuckReasonFromError(v.message), v.message)
+	}
+}
+
+func (s *CrosschainTestSuite) TestDiagnoseRequestIsPropagating() {
+	require := s.Require()
+	now := time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+	require.True((&DiagnoseRequest{}).IsPropagating(now))
+	require.True((&DiagnoseRequest{SubmittedAt: now.Add(-30 * time.Second)}).IsPropagating(now))
+	require.False((&DiagnoseRequest{SubmittedAt: now.Add(-2 * time.Minute)}).IsPropagating(now))
+}
@@ -0,0 +1,74 @@
+package crosschain
+
+import (
+	"context"
+	"sync"
+)
+
+// DryRunSubmission is a tx that a dry run didn't broadcast
+type DryRunSubmission struct {
+	Chain  NativeAsset `json:"chain"`
+	TxHash TxHash      `json:"tx_hash"`
+	// Serialized is the tx that would have been broadcast
+	Serialized []byte `json:"serialized"`
+	// Simulated is true if the node simulated the tx successfully, false if the driver can't simulate txs
+	Simulated bool `json:"simulated"`
+}
+
+// DryRun records the txs of SubmitTx calls that weren't broadcast
+type DryRun struct {
+	mu          sync.Mutex
+	submissions []*DryRunSubmission
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context whose SubmitTx calls simulate txs without broadcasting them, recorded in dryRun
+func WithDryRun(ctx context.Context, dryRun *DryRun) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, dryRun)
+}
+
+// DryRunFromContext returns the DryRun of ctx, nil if none
+func DryRunFromContext(ctx context.Context) *DryRun {
+	dryRun, _ := ctx.Value(dryRunKey{}).(*DryRun)
+	return dryRun
+}
+
+// IsDryRun returns true if txs of asset submitted with ctx must not be broadcast,
+// for a DryRun in ctx or the dry_run of the chain
+func IsDryRun(ctx context.Context, asset ITask) bool {
+	if DryRunFromContext(ctx) != nil {
+		return true
+	}
+	return asset != nil && asset.GetNativeAsset().DryRun
+}
+
+// RecordDryRun records tx as not broadcast by SubmitTx, in the DryRun of ctx if any, and logs it
+func RecordDryRun(ctx context.Context, asset ITask, tx Tx, simulated bool) error {
+	serialized, err := tx.Serialize()
+	if err != nil {
+		return err
+	}
+	submission := &DryRunSubmission{
+		TxHash:     tx.Hash(),
+		Serialized: serialized,
+		Simulated:  simulated,
+	}
+	if asset != nil {
+		submission.Chain = asset.GetNativeAsset().NativeAsset
+	}
+	if dryRun := DryRunFromContext(ctx); dryRun != nil {
+		dryRun.mu.Lock()
+		dryRun.submissions = append(dryRun.submissions, submission)
+		dryRun.mu.Unlock()
+	}
+	LogEntry(ctx).WithField("chain", submission.Chain).WithField("tx_hash", submission.TxHash).WithField("simulated", simulated).Info("dry run: tx not broadcast")
+	return nil
+}
+
+// Submissions returns the txs that weren't broadcast, in order
+func (dryRun *DryRun) Submissions() []*DryRunSubmission {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	return append([]*DryRunSubmission{}, dryRun.submissions...)
+}
@@ -0,0 +1,34 @@
+package crosschain
+
+import (
+	"context"
+	"errors"
+)
+
+func (s *CrosschainTestSuite) TestDryRun() {
+	require := s.Require()
+	asset := &AssetConfig{NativeAsset: ETH}
+	require.False(IsDryRun(context.Background(), asset))
+	require.Nil(DryRunFromContext(context.Background()))
+
+	// per chain
+	require.True(IsDryRun(context.Background(), &AssetConfig{NativeAsset: ETH, DryRun: true}))
+	require.NoError(RecordDryRun(context.Background(), asset, bufferTestTx{serialized: []byte{1}}, false))
+
+	// per call
+	dryRun := &DryRun{}
+	ctx := WithDryRun(context.Background(), dryRun)
+	require.True(IsDryRun(ctx, asset))
+	require.True(IsDryRun(ctx, nil))
+	require.Equal(dryRun, DryRunFromContext(ctx))
+	require.NoError(RecordDryRun(ctx, asset, bufferTestTx{serialized: []byte{1, 2}}, true))
+	require.NoError(RecordDryRun(ctx, &AssetConfig{NativeAsset: SOL}, bufferTestTx{serialized: []byte{3}}, false))
+	require.Equal([]*DryRunSubmission{
+		{Chain: ETH, Serialized: []byte{1, 2}, Simulated: true},
+		{Chain: SOL, Serialized: []byte{3}},
+	}, dryRun.Submissions())
+
+	err := RecordDryRun(ctx, asset, bufferTestTx{err: errors.New("not signed")}, true)
+	require.EqualError(err, "not signed")
+	require.Len(dryRun.Submissions(), 2)
+}
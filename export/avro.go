@@ -0,0 +1,131 @@
+package export
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jumpcrypto/crosschain/firehose"
+)
+
+// AvroRecordName is the full name of the Avro record of a transfer
+const AvroRecordName = "crosschain.Transfer"
+
+// DefaultAvroBlockSize is the number of transfers per block of an Avro file
+const DefaultAvroBlockSize = 1000
+
+var avroMagic = []byte{'O', 'b', 'j', 1}
+
+// AvroSchema returns the Avro schema of exported transfers
+func AvroSchema() string {
+	type avroField struct {
+		Name string     `json:"name"`
+		Type ColumnType `json:"type"`
+		Doc  string     `json:"doc"`
+	}
+	fields := make([]avroField, len(TransferColumns))
+	for i, column := range TransferColumns {
+		fields[i] = avroField{Name: column.Name, Type: column.Type, Doc: column.Doc}
+	}
+	schema, _ := json.Marshal(map[string]interface{}{
+		"type":   "record",
+		"name":   AvroRecordName,
+		"fields": fields,
+	})
+	return string(schema)
+}
+
+// AvroEncoder writes transfers into an Avro object container file (null codec)
+type AvroEncoder struct {
+	// BlockSize is the number of transfers buffered before writing a block
+	BlockSize int
+	writer    io.Writer
+	sync      [16]byte
+	block     bytes.Buffer
+	count     int64
+	scratch   [binary.MaxVarintLen64]byte
+}
+
+var _ Encoder = &AvroEncoder{}
+
+// NewAvroEncoder creates a new AvroEncoder and writes the header of the file
+func NewAvroEncoder(writer io.Writer) (*AvroEncoder, error) {
+	encoder := &AvroEncoder{
+		BlockSize: DefaultAvroBlockSize,
+		writer:    writer,
+	}
+	if _, err := rand.Read(encoder.sync[:]); err != nil {
+		return nil, err
+	}
+	var header bytes.Buffer
+	header.Write(avroMagic)
+	// file metadata is a map of bytes, in a single block
+	encoder.writeLong(&header, 2)
+	encoder.writeString(&header, "avro.schema")
+	encoder.writeString(&header, AvroSchema())
+	encoder.writeString(&header, "avro.codec")
+	encoder.writeString(&header, "null")
+	encoder.writeLong(&header, 0)
+	header.Write(encoder.sync[:])
+	if _, err := writer.Write(header.Bytes()); err != nil {
+		return nil, err
+	}
+	return encoder, nil
+}
+
+// writeLong writes a zigzag varint
+func (encoder *AvroEncoder) writeLong(buf *bytes.Buffer, value int64) {
+	n := binary.PutVarint(encoder.scratch[:], value)
+	buf.Write(encoder.scratch[:n])
+}
+
+// writeString writes a length prefixed string
+func (encoder *AvroEncoder) writeString(buf *bytes.Buffer, value string) {
+	encoder.writeLong(buf, int64(len(value)))
+	buf.WriteString(value)
+}
+
+// Write buffers a transfer, and writes a block when BlockSize transfers are buffered
+func (encoder *AvroEncoder) Write(transfer *firehose.Transfer) error {
+	for _, column := range TransferColumns {
+		switch value := column.value(transfer).(type) {
+		case string:
+			encoder.writeString(&encoder.block, value)
+		case int64:
+			encoder.writeLong(&encoder.block, value)
+		default:
+			return fmt.Errorf("unsupported value %T of column %s", value, column.Name)
+		}
+	}
+	encoder.count++
+	if encoder.count >= int64(encoder.BlockSize) {
+		return encoder.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered transfers as a block
+func (encoder *AvroEncoder) Flush() error {
+	if encoder.count == 0 {
+		return nil
+	}
+	var header bytes.Buffer
+	encoder.writeLong(&header, encoder.count)
+	encoder.writeLong(&header, int64(encoder.block.Len()))
+	for _, part := range [][]byte{header.Bytes(), encoder.block.Bytes(), encoder.sync[:]} {
+		if _, err := encoder.writer.Write(part); err != nil {
+			return err
+		}
+	}
+	encoder.block.Reset()
+	encoder.count = 0
+	return nil
+}
+
+// Close writes the buffered transfers, it doesn't close the underlying writer
+func (encoder *AvroEncoder) Close() error {
+	return encoder.Flush()
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+)
+
+// readAvroFile decodes an Avro object container file of transfers into rows
+func readAvroFile(data []byte) (map[string]string, [][]interface{}, error) {
+	reader := bufio.NewReader(bytes.NewReader(data))
+	readString := func() (string, error) {
+		size, err := binary.ReadVarint(reader)
+		if err != nil {
+			return "", err
+		}
+		buf := make([]byte, size)
+		_, err = io.ReadFull(reader, buf)
+		return string(buf), err
+	}
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(reader, magic); err != nil {
+		return nil, nil, err
+	}
+	metadata := map[string]string{}
+	for {
+		count, err := binary.ReadVarint(reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		if count == 0 {
+			break
+		}
+		for i := int64(0); i < count; i++ {
+			key, _ := readString()
+			value, err := readString()
+			if err != nil {
+				return nil, nil, err
+			}
+			metadata[key] = value
+		}
+	}
+	sync := make([]byte, 16)
+	io.ReadFull(reader, sync)
+
+	rows := [][]interface{}{}
+	for {
+		count, err := binary.ReadVarint(reader)
+		if err == io.EOF {
+			return metadata, rows, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := binary.ReadVarint(reader); err != nil {
+			return nil, nil, err
+		}
+		for i := int64(0); i < count; i++ {
+			row := []interface{}{}
+			for _, column := range TransferColumns {
+				if column.Type == ColumnLong {
+					value, err := binary.ReadVarint(reader)
+					if err != nil {
+						return nil, nil, err
+					}
+					row = append(row, value)
+				} else {
+					value, err := readString()
+					if err != nil {
+						return nil, nil, err
+					}
+					row = append(row, value)
+				}
+			}
+			rows = append(rows, row)
+		}
+		marker := make([]byte, 16)
+		io.ReadFull(reader, marker)
+		if !bytes.Equal(marker, sync) {
+			return nil, nil, io.ErrUnexpectedEOF
+		}
+	}
+}
+
+func (s *CrosschainTestSuite) TestAvroSchema() {
+	require := s.Require()
+	var schema struct {
+		Type   string
+		Name   string
+		Fields []struct {
+			Name string
+			Type string
+		}
+	}
+	require.NoError(json.Unmarshal([]byte(AvroSchema()), &schema))
+	require.Equal("record", schema.Type)
+	require.Equal(AvroRecordName, schema.Name)
+	require.Len(schema.Fields, len(TransferColumns))
+	require.Equal("block_index", schema.Fields[1].Name)
+	require.Equal("long", schema.Fields[1].Type)
+	require.Equal("amount", schema.Fields[7].Name)
+	require.Equal("string", schema.Fields[7].Type)
+}
+
+func (s *CrosschainTestSuite) TestAvroEncoder() {
+	require := s.Require()
+	var buf bytes.Buffer
+	encoder, err := NewAvroEncoder(&buf)
+	require.NoError(err)
+	encoder.BlockSize = 1
+	for _, transfer := range testTransfers() {
+		require.NoError(encoder.Write(transfer))
+	}
+	require.NoError(encoder.Close())
+	require.Equal(avroMagic, buf.Bytes()[:4])
+
+	metadata, rows, err := readAvroFile(buf.Bytes())
+	require.NoError(err)
+	require.Equal(AvroSchema(), metadata["avro.schema"])
+	require.Equal("null", metadata["avro.codec"])
+	require.Equal([][]interface{}{
+		{"ETH", int64(100), int64(1650000000), "0xabc", "0xfrom", "0xto", "", "1000000000000000000000000", int64(0)},
+		{"SOL", int64(200), int64(1650000001), "sig", "from", "to", "mint", "5", int64(1)},
+	}, rows)
+}
+
+func (s *CrosschainTestSuite) TestAvroEncoderEmpty() {
+	require := s.Require()
+	var buf bytes.Buffer
+	encoder, err := NewAvroEncoder(&buf)
+	require.NoError(err)
+	require.NoError(encoder.Close())
+	_, rows, err := readAvroFile(buf.Bytes())
+	require.NoError(err)
+	require.Empty(rows)
+}
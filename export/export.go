@@ -0,0 +1,59 @@
+// Package export encodes normalized transfers into files for analytical pipelines (Spark, BigQuery...)
+//
+// Avro object container files and Parquet files are supported, both with the schema of TransferColumns.
+// The schema is stable: columns are only ever appended, never renamed, retyped or removed.
+package export
+
+import (
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/firehose"
+)
+
+// ColumnType is the type of an exported column
+type ColumnType string
+
+// ColumnType values
+const (
+	ColumnString ColumnType = "string"
+	ColumnLong   ColumnType = "long"
+)
+
+// Column is a column of the exported transfers
+type Column struct {
+	Name string
+	Type ColumnType
+	Doc  string
+	// value returns the value of the column for a transfer, a string or an int64
+	value func(transfer *firehose.Transfer) interface{}
+}
+
+// TransferColumns is the schema of exported transfers
+// Amounts are decimal strings in blockchain units: they don't fit in 64 bits
+var TransferColumns = []Column{
+	{Name: "chain", Type: ColumnString, Doc: "native asset of the chain", value: func(t *firehose.Transfer) interface{} { return string(t.Chain) }},
+	{Name: "block_index", Type: ColumnLong, Doc: "height of the block", value: func(t *firehose.Transfer) interface{} { return t.BlockIndex }},
+	{Name: "block_time", Type: ColumnLong, Doc: "unix time of the block in seconds", value: func(t *firehose.Transfer) interface{} { return t.BlockTime }},
+	{Name: "tx_hash", Type: ColumnString, Doc: "hash of the tx", value: func(t *firehose.Transfer) interface{} { return t.TxHash }},
+	{Name: "from", Type: ColumnString, Doc: "source address", value: func(t *firehose.Transfer) interface{} { return string(t.From) }},
+	{Name: "to", Type: ColumnString, Doc: "destination address", value: func(t *firehose.Transfer) interface{} { return string(t.To) }},
+	{Name: "contract_address", Type: ColumnString, Doc: "contract of the token, empty for the native asset", value: func(t *firehose.Transfer) interface{} { return string(t.ContractAddress) }},
+	{Name: "amount", Type: ColumnString, Doc: "decimal amount in blockchain units", value: func(t *firehose.Transfer) interface{} { return t.Amount }},
+	{Name: "status", Type: ColumnLong, Doc: "status of the tx: 0 success, 1 failure", value: func(t *firehose.Transfer) interface{} { return int64(t.Status) }},
+}
+
+// Encoder streams transfers into a file
+// Close must be called to flush buffered transfers and write the end of the file
+type Encoder interface {
+	Write(transfer *firehose.Transfer) error
+	Close() error
+}
+
+// WriteTxInfo writes the transfers of a tx
+func WriteTxInfo(encoder Encoder, chain xc.NativeAsset, info xc.TxInfo) error {
+	for _, transfer := range firehose.Transfers(chain, info) {
+		if err := encoder.Write(transfer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
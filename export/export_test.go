@@ -0,0 +1,71 @@
+package export
+
+import (
+	"context"
+	"testing"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/firehose"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestExportTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+type recordingEncoder struct {
+	transfers []*firehose.Transfer
+}
+
+func (encoder *recordingEncoder) Write(transfer *firehose.Transfer) error {
+	encoder.transfers = append(encoder.transfers, transfer)
+	return nil
+}
+
+func (encoder *recordingEncoder) Close() error {
+	return nil
+}
+
+func testTransfers() []*firehose.Transfer {
+	return []*firehose.Transfer{
+		{Chain: xc.ETH, BlockIndex: 100, BlockTime: 1650000000, TxHash: "0xabc", From: "0xfrom", To: "0xto", Amount: "1000000000000000000000000", Status: xc.TxStatusSuccess},
+		{Chain: xc.SOL, BlockIndex: 200, BlockTime: 1650000001, TxHash: "sig", From: "from", To: "to", ContractAddress: "mint", Amount: "5", Status: xc.TxStatusFailure},
+	}
+}
+
+func (s *CrosschainTestSuite) TestStableSchema() {
+	require := s.Require()
+	// columns may only be appended
+	names := []string{"chain", "block_index", "block_time", "tx_hash", "from", "to", "contract_address", "amount", "status"}
+	require.GreaterOrEqual(len(TransferColumns), len(names))
+	for i, name := range names {
+		require.Equal(name, TransferColumns[i].Name)
+	}
+}
+
+func (s *CrosschainTestSuite) TestWriteTxInfo() {
+	require := s.Require()
+	encoder := &recordingEncoder{}
+	err := WriteTxInfo(encoder, xc.ETH, xc.TxInfo{
+		TxID:       "0xabc",
+		From:       "0xfrom",
+		BlockIndex: 100,
+		Destinations: []*xc.TxInfoEndpoint{
+			{Address: "0xto1", Amount: xc.NewAmountBlockchainFromUint64(1)},
+			{Address: "0xto2", Amount: xc.NewAmountBlockchainFromUint64(2)},
+		},
+	})
+	require.NoError(err)
+	require.Len(encoder.transfers, 2)
+	require.Equal(xc.Address("0xto2"), encoder.transfers[1].To)
+	require.Equal("2", encoder.transfers[1].Amount)
+}
@@ -0,0 +1,297 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/jumpcrypto/crosschain/firehose"
+)
+
+// DefaultParquetRowGroupSize is the number of transfers per row group of a Parquet file
+const DefaultParquetRowGroupSize = 10000
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet enum values of the format specification
+const (
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+	parquetRequired      = 0
+	parquetConvertedUTF8 = 0
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+	parquetUncompressed  = 0
+	parquetDataPage      = 0
+)
+
+// ParquetEncoder writes transfers into a Parquet file
+// Columns are required and PLAIN encoded in uncompressed pages, one page per column chunk
+type ParquetEncoder struct {
+	// RowGroupSize is the number of transfers buffered before writing a row group
+	RowGroupSize int
+	writer       io.Writer
+	offset       int64
+	columns      []bytes.Buffer
+	rows         int64
+	totalRows    int64
+	rowGroups    []parquetRowGroup
+}
+
+type parquetRowGroup struct {
+	columns   []parquetColumnChunk
+	totalSize int64
+	rows      int64
+}
+
+type parquetColumnChunk struct {
+	offset int64
+	size   int64
+}
+
+var _ Encoder = &ParquetEncoder{}
+
+// NewParquetEncoder creates a new ParquetEncoder and writes the header of the file
+func NewParquetEncoder(writer io.Writer) (*ParquetEncoder, error) {
+	encoder := &ParquetEncoder{
+		RowGroupSize: DefaultParquetRowGroupSize,
+		writer:       writer,
+		columns:      make([]bytes.Buffer, len(TransferColumns)),
+	}
+	if err := encoder.write(parquetMagic); err != nil {
+		return nil, err
+	}
+	return encoder, nil
+}
+
+func (encoder *ParquetEncoder) write(data []byte) error {
+	n, err := encoder.writer.Write(data)
+	encoder.offset += int64(n)
+	return err
+}
+
+// Write buffers a transfer, and writes a row group when RowGroupSize transfers are buffered
+func (encoder *ParquetEncoder) Write(transfer *firehose.Transfer) error {
+	for i, column := range TransferColumns {
+		buf := &encoder.columns[i]
+		switch value := column.value(transfer).(type) {
+		case string:
+			binary.Write(buf, binary.LittleEndian, uint32(len(value)))
+			buf.WriteString(value)
+		case int64:
+			binary.Write(buf, binary.LittleEndian, value)
+		default:
+			return fmt.Errorf("unsupported value %T of column %s", value, column.Name)
+		}
+	}
+	encoder.rows++
+	if encoder.rows >= int64(encoder.RowGroupSize) {
+		return encoder.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered transfers as a row group
+func (encoder *ParquetEncoder) Flush() error {
+	if encoder.rows == 0 {
+		return nil
+	}
+	rowGroup := parquetRowGroup{rows: encoder.rows}
+	for i := range TransferColumns {
+		data := encoder.columns[i].Bytes()
+		header := &thriftWriter{}
+		header.beginStruct()
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(len(data)))
+		header.i32(3, int32(len(data)))
+		header.structField(5, func() {
+			header.i32(1, int32(encoder.rows))
+			header.i32(2, parquetEncodingPlain)
+			header.i32(3, parquetEncodingRLE)
+			header.i32(4, parquetEncodingRLE)
+		})
+		header.endStruct()
+
+		chunk := parquetColumnChunk{offset: encoder.offset, size: int64(header.buf.Len() + len(data))}
+		if err := encoder.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := encoder.write(data); err != nil {
+			return err
+		}
+		rowGroup.columns = append(rowGroup.columns, chunk)
+		rowGroup.totalSize += chunk.size
+		encoder.columns[i].Reset()
+	}
+	encoder.rowGroups = append(encoder.rowGroups, rowGroup)
+	encoder.totalRows += encoder.rows
+	encoder.rows = 0
+	return nil
+}
+
+// Close writes the buffered transfers and the footer, it doesn't close the underlying writer
+func (encoder *ParquetEncoder) Close() error {
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	footer := encoder.fileMetaData()
+	length := make([]byte, 4)
+	binary.LittleEndian.PutUint32(length, uint32(len(footer)))
+	for _, part := range [][]byte{footer, length, parquetMagic} {
+		if err := encoder.write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileMetaData encodes the FileMetaData of the footer
+func (encoder *ParquetEncoder) fileMetaData() []byte {
+	w := &thriftWriter{}
+	w.beginStruct()
+	w.i32(1, 1)
+	w.list(2, thriftStruct, len(TransferColumns)+1)
+	w.beginStruct()
+	w.binary(4, "schema")
+	w.i32(5, int32(len(TransferColumns)))
+	w.endStruct()
+	for _, column := range TransferColumns {
+		w.beginStruct()
+		w.i32(1, parquetType(column.Type))
+		w.i32(3, parquetRequired)
+		w.binary(4, column.Name)
+		if column.Type == ColumnString {
+			w.i32(6, parquetConvertedUTF8)
+		}
+		w.endStruct()
+	}
+	w.i64(3, encoder.totalRows)
+	w.list(4, thriftStruct, len(encoder.rowGroups))
+	for _, rowGroup := range encoder.rowGroups {
+		w.beginStruct()
+		w.list(1, thriftStruct, len(rowGroup.columns))
+		for i, chunk := range rowGroup.columns {
+			column := TransferColumns[i]
+			w.beginStruct()
+			w.i64(2, chunk.offset)
+			w.structField(3, func() {
+				w.i32(1, parquetType(column.Type))
+				w.list(2, thriftI32, 2)
+				w.rawI32(parquetEncodingPlain)
+				w.rawI32(parquetEncodingRLE)
+				w.list(3, thriftBinary, 1)
+				w.rawBinary(column.Name)
+				w.i32(4, parquetUncompressed)
+				w.i64(5, rowGroup.rows)
+				w.i64(6, chunk.size)
+				w.i64(7, chunk.size)
+				w.i64(9, chunk.offset)
+			})
+			w.endStruct()
+		}
+		w.i64(2, rowGroup.totalSize)
+		w.i64(3, rowGroup.rows)
+		w.endStruct()
+	}
+	w.binary(6, "crosschain")
+	w.endStruct()
+	return w.buf.Bytes()
+}
+
+func parquetType(columnType ColumnType) int32 {
+	if columnType == ColumnLong {
+		return parquetTypeInt64
+	}
+	return parquetTypeByteArray
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol, used by Parquet metadata
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastIDs []int16
+	lastID  int16
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (w *thriftWriter) uvarint(value uint64) {
+	n := binary.PutUvarint(w.scratch[:], value)
+	w.buf.Write(w.scratch[:n])
+}
+
+func (w *thriftWriter) varint(value int64) {
+	n := binary.PutVarint(w.scratch[:], value)
+	w.buf.Write(w.scratch[:n])
+}
+
+func (w *thriftWriter) field(id int16, fieldType byte) {
+	if delta := id - w.lastID; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.varint(int64(id))
+	}
+	w.lastID = id
+}
+
+func (w *thriftWriter) beginStruct() {
+	w.lastIDs = append(w.lastIDs, w.lastID)
+	w.lastID = 0
+}
+
+func (w *thriftWriter) endStruct() {
+	w.buf.WriteByte(0)
+	w.lastID = w.lastIDs[len(w.lastIDs)-1]
+	w.lastIDs = w.lastIDs[:len(w.lastIDs)-1]
+}
+
+func (w *thriftWriter) i32(id int16, value int32) {
+	w.field(id, thriftI32)
+	w.rawI32(value)
+}
+
+func (w *thriftWriter) i64(id int16, value int64) {
+	w.field(id, thriftI64)
+	w.varint(value)
+}
+
+func (w *thriftWriter) binary(id int16, value string) {
+	w.field(id, thriftBinary)
+	w.rawBinary(value)
+}
+
+func (w *thriftWriter) structField(id int16, fn func()) {
+	w.field(id, thriftStruct)
+	w.beginStruct()
+	fn()
+	w.endStruct()
+}
+
+// list writes the header of a list, followed by size raw elements
+func (w *thriftWriter) list(id int16, elementType byte, size int) {
+	w.field(id, thriftList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elementType)
+	} else {
+		w.buf.WriteByte(0xf0 | elementType)
+		w.uvarint(uint64(size))
+	}
+}
+
+func (w *thriftWriter) rawI32(value int32) {
+	w.varint(int64(value))
+}
+
+func (w *thriftWriter) rawBinary(value string) {
+	w.uvarint(uint64(len(value)))
+	w.buf.WriteString(value)
+}
@@ -0,0 +1,164 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// readThrift decodes a Thrift compact struct into a map of field ids to values
+func readThrift(reader *bufio.Reader) (map[int16]interface{}, error) {
+	fields := map[int16]interface{}{}
+	var lastID int16
+	for {
+		header, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return fields, nil
+		}
+		id := lastID + int16(header>>4)
+		if header>>4 == 0 {
+			long, err := binary.ReadVarint(reader)
+			if err != nil {
+				return nil, err
+			}
+			id = int16(long)
+		}
+		lastID = id
+		fields[id], err = readThriftValue(reader, header&0x0f)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func readThriftValue(reader *bufio.Reader, valueType byte) (interface{}, error) {
+	switch valueType {
+	case thriftI32, thriftI64:
+		return binary.ReadVarint(reader)
+	case thriftBinary:
+		size, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size)
+		_, err = io.ReadFull(reader, buf)
+		return string(buf), err
+	case thriftStruct:
+		return readThrift(reader)
+	case thriftList:
+		header, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = binary.ReadUvarint(reader); err != nil {
+				return nil, err
+			}
+		}
+		items := []interface{}{}
+		for i := uint64(0); i < size; i++ {
+			item, err := readThriftValue(reader, header&0x0f)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return nil, errors.New("unsupported thrift type")
+}
+
+// readParquetFile decodes the footer and the values of each column of a Parquet file
+func readParquetFile(data []byte) (map[int16]interface{}, [][]interface{}, error) {
+	if !bytes.Equal(data[:4], parquetMagic) || !bytes.Equal(data[len(data)-4:], parquetMagic) {
+		return nil, nil, errors.New("invalid magic")
+	}
+	length := binary.LittleEndian.Uint32(data[len(data)-8:])
+	footerOffset := len(data) - 8 - int(length)
+	metadata, err := readThrift(bufio.NewReader(bytes.NewReader(data[footerOffset : len(data)-8])))
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make([][]interface{}, len(TransferColumns))
+	for _, rowGroup := range metadata[4].([]interface{}) {
+		for i, chunk := range rowGroup.(map[int16]interface{})[1].([]interface{}) {
+			columnMetadata := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			offset := columnMetadata[9].(int64)
+			size := columnMetadata[7].(int64)
+			reader := bufio.NewReader(bytes.NewReader(data[offset : offset+size]))
+			page, err := readThrift(reader)
+			if err != nil {
+				return nil, nil, err
+			}
+			numValues := page[5].(map[int16]interface{})[1].(int64)
+			for j := int64(0); j < numValues; j++ {
+				if TransferColumns[i].Type == ColumnLong {
+					var value int64
+					if err := binary.Read(reader, binary.LittleEndian, &value); err != nil {
+						return nil, nil, err
+					}
+					columns[i] = append(columns[i], value)
+				} else {
+					var size uint32
+					if err := binary.Read(reader, binary.LittleEndian, &size); err != nil {
+						return nil, nil, err
+					}
+					buf := make([]byte, size)
+					if _, err := io.ReadFull(reader, buf); err != nil {
+						return nil, nil, err
+					}
+					columns[i] = append(columns[i], string(buf))
+				}
+			}
+		}
+	}
+	return metadata, columns, nil
+}
+
+func (s *CrosschainTestSuite) TestParquetEncoder() {
+	require := s.Require()
+	var buf bytes.Buffer
+	encoder, err := NewParquetEncoder(&buf)
+	require.NoError(err)
+	encoder.RowGroupSize = 1
+	for _, transfer := range testTransfers() {
+		require.NoError(encoder.Write(transfer))
+	}
+	require.NoError(encoder.Close())
+
+	metadata, columns, err := readParquetFile(buf.Bytes())
+	require.NoError(err)
+	require.Equal(int64(2), metadata[3])
+	require.Len(metadata[4], 2)
+	require.Equal("crosschain", metadata[6])
+
+	schema := metadata[2].([]interface{})
+	require.Len(schema, len(TransferColumns)+1)
+	require.Equal(int64(len(TransferColumns)), schema[0].(map[int16]interface{})[5])
+	require.Equal("tx_hash", schema[4].(map[int16]interface{})[4])
+	require.Equal(int64(parquetTypeByteArray), schema[4].(map[int16]interface{})[1])
+	require.Equal(int64(parquetTypeInt64), schema[2].(map[int16]interface{})[1])
+
+	require.Equal([]interface{}{"ETH", "SOL"}, columns[0])
+	require.Equal([]interface{}{int64(100), int64(200)}, columns[1])
+	require.Equal([]interface{}{"", "mint"}, columns[6])
+	require.Equal([]interface{}{"1000000000000000000000000", "5"}, columns[7])
+	require.Equal([]interface{}{int64(0), int64(1)}, columns[8])
+}
+
+func (s *CrosschainTestSuite) TestParquetEncoderEmpty() {
+	require := s.Require()
+	var buf bytes.Buffer
+	encoder, err := NewParquetEncoder(&buf)
+	require.NoError(err)
+	require.NoError(encoder.Close())
+	metadata, _, err := readParquetFile(buf.Bytes())
+	require.NoError(err)
+	require.Equal(int64(0), metadata[3])
+}
@@ -0,0 +1,181 @@
+package factory
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jinzhu/copier"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	. "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/config"
+)
+
+// LoadConfig loads and validates the config of a YAML file, see ReadConfig
+func LoadConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open config: %v", err)
+	}
+	defer file.Close()
+	return ReadConfig(file)
+}
+
+// ReadConfig parses a YAML config, either the crosschain section or a document containing it, and validates it:
+//   - chains have a supported driver, and EVM chains a chain_id and a supported rollup stack if set
+//   - chains of the registry default to its driver and decimals, see RegisterChain
+//   - tokens have a contract, decimals and a configured chain, whose net they take
+//   - assets are unique, and their auth references (env:, file:, vault:) are resolved to AuthSecret
+//
+// Derived fields (NativeAsset, Type) are set, and AllAssets lists the chains then the tokens, see Config.GetAsset
+func ReadConfig(r io.Reader) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config: %v", err)
+	}
+	var document struct {
+		Crosschain *Config `yaml:"crosschain"`
+	}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", err)
+	}
+	cfg := document.Crosschain
+	if cfg == nil {
+		cfg = &Config{}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("invalid config: %v", err)
+		}
+	}
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func validateConfig(cfg *Config) error {
+	cfg.AllAssets = []ITask{}
+	chains := map[AssetID]*NativeAssetConfig{}
+	for i, chain := range cfg.Chains {
+		if chain == nil || strings.TrimSpace(chain.Asset) == "" {
+			return fmt.Errorf("invalid chain #%d: missing asset", i)
+		}
+		if err := validateChain(chain); err != nil {
+			return fmt.Errorf("invalid chain %s: %v", chain.Asset, err)
+		}
+		if _, ok := chains[chain.ID()]; ok {
+			return fmt.Errorf("duplicate chain: %s", chain.ID())
+		}
+		chains[chain.ID()] = chain
+		cfg.AllAssets = append(cfg.AllAssets, chain)
+	}
+
+	tokens := map[AssetID]bool{}
+	for i, token := range cfg.Tokens {
+		if token == nil || strings.TrimSpace(token.Asset) == "" {
+			return fmt.Errorf("invalid token #%d: missing asset", i)
+		}
+		if strings.TrimSpace(token.Chain) == "" {
+			return fmt.Errorf("invalid token %s: missing chain", token.Asset)
+		}
+		chain, ok := chains[GetAssetIDFromAsset("", token.Chain)]
+		if !ok {
+			return fmt.Errorf("invalid token %s: unknown chain: %s", token.ID(), token.Chain)
+		}
+		if err := validateToken(token, chain); err != nil {
+			return fmt.Errorf("invalid token %s: %v", token.ID(), err)
+		}
+		if _, ok := chains[token.ID()]; ok || tokens[token.ID()] {
+			return fmt.Errorf("duplicate token: %s", token.ID())
+		}
+		tokens[token.ID()] = true
+		cfg.AllAssets = append(cfg.AllAssets, token)
+	}
+
+	for _, task := range cfg.AllTasks {
+		task.AllowList = parseAllowList(task.Allow)
+	}
+	return nil
+}
+
+func validateChain(chain *NativeAssetConfig) error {
+	chain.Type = AssetTypeNative
+	chain.Chain = chain.Asset
+	chain.NativeAsset = NativeAsset(chain.Asset)
+	// normalize aliases, unknown networks are kept as is
+	if net, err := ParseNet(string(chain.Net)); err == nil {
+		chain.Net = net
+	}
+
+	// defaults of registered chains
+	if chain.Driver == "" {
+		chain.Driver = string(chain.NativeAsset.Driver())
+	}
+	if chain.Decimals == 0 {
+		chain.Decimals = chain.NativeAsset.Decimals()
+	}
+	if !Driver(chain.Driver).IsSupported() {
+		return fmt.Errorf("unsupported driver: '%s'", chain.Driver)
+	}
+	switch Driver(chain.Driver) {
+	case DriverEVM, DriverEVMLegacy:
+		if chain.ChainID == 0 {
+			return fmt.Errorf("missing chain_id")
+		}
+		if chain.Rollup.Stack != "" {
+			stack, err := ParseRollupStack(string(chain.Rollup.Stack))
+			if err != nil {
+				return err
+			}
+			chain.Rollup.Stack = stack
+		}
+	default:
+		if chain.Rollup != (RollupConfig{}) {
+			return fmt.Errorf("rollup is only supported by EVM chains")
+		}
+	}
+
+	if chain.Auth != "" {
+		secret, err := config.GetSecret(chain.Auth)
+		if err != nil {
+			// the error of an invalid reference doesn't include it, it may be a secret itself
+			return fmt.Errorf("could not resolve auth: %v", err)
+		}
+		chain.AuthSecret = secret
+	}
+	return nil
+}
+
+func validateToken(token *TokenAssetConfig, chain *NativeAssetConfig) error {
+	if strings.TrimSpace(token.Contract) == "" {
+		return fmt.Errorf("missing contract")
+	}
+	if token.Decimals <= 0 {
+		return fmt.Errorf("missing decimals")
+	}
+	// denoms of Cosmos chains may be configured as native
+	if token.Type == "" {
+		token.Type = AssetTypeToken
+	}
+
+	enrichTokenFromChain(token, chain)
+	copier.CopyWithOption(&token.AssetConfig, token, copier.Option{IgnoreEmpty: false, DeepCopy: false})
+	token.AssetConfig.Chain = chain.Asset
+	token.NativeAsset = chain.NativeAsset
+	return nil
+}
+
+// NewFactoryFromConfig creates a new Factory given a config loaded by LoadConfig or ReadConfig
+func NewFactoryFromConfig(cfg *Config) *Factory {
+	f := &Factory{
+		AllTasks:     cfg.AllTasks,
+		AllPipelines: cfg.AllPipelines,
+	}
+	for _, asset := range cfg.AllAssets {
+		f.AllAssets.Store(asset.ID(), prepareAssetConfig(asset))
+	}
+	log.WithField("fingerprint", f.Fingerprint()).WithField("assets", len(cfg.AllAssets)).Info("loaded crosschain config")
+	return f
+}
@@ -0,0 +1,146 @@
+package factory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+const testConfigYAML = `
+crosschain:
+  chains:
+  - asset: ETH
+    net: goerli
+    url: 'https://goerli.infura.io/v3'
+    auth: 'env:XCTEST_CONFIG_AUTH'
+    chain_id: 5
+    decimals: 18
+  - asset: SOL
+    driver: solana
+    net: devnet
+    decimals: 9
+  tokens:
+  - asset: USDC
+    chain: ETH
+    decimals: 6
+    contract: 0x07865c6e87b9f70255377e024ace6630c1eaa37f
+  - asset: USDC
+    chain: SOL
+    decimals: 6
+    contract: 4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU
+  tasks:
+  - name: eth-wrap
+    code: WrapTx
+    allow:
+    - ETH -> WETH.ETH
+`
+
+func (s *CrosschainTestSuite) TestLoadConfig() {
+	require := s.Require()
+	os.Setenv("XCTEST_CONFIG_AUTH", "mysecret")
+	path := filepath.Join(s.T().TempDir(), "crosschain.yaml")
+	require.NoError(os.WriteFile(path, []byte(testConfigYAML), 0600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(err)
+	require.Len(cfg.AllAssets, 4)
+
+	eth, err := cfg.GetAsset("ETH")
+	require.NoError(err)
+	require.Equal(xc.AssetTypeNative, eth.GetAssetConfig().Type)
+	require.Equal(xc.ETH, eth.GetAssetConfig().NativeAsset)
+	require.Equal("evm", eth.GetAssetConfig().Driver)
+	require.Equal(xc.Goerli, eth.GetAssetConfig().Net)
+	require.Equal("mysecret", eth.GetAssetConfig().AuthSecret)
+
+	usdc, err := cfg.GetAsset("USDC.SOL")
+	require.NoError(err)
+	require.Equal(xc.AssetTypeToken, usdc.GetAssetConfig().Type)
+	require.Equal(xc.SOL, usdc.GetAssetConfig().NativeAsset)
+	require.Equal("solana", usdc.GetAssetConfig().Driver)
+	require.Equal(int32(6), usdc.GetAssetConfig().Decimals)
+	require.Equal("SOL", usdc.GetNativeAsset().Asset)
+
+	usdcEth, err := cfg.GetAsset("USDC.ETH")
+	require.NoError(err)
+	require.Equal("mysecret", usdcEth.GetAssetConfig().AuthSecret)
+	require.Equal(int64(5), usdcEth.GetAssetConfig().ChainID)
+	require.Equal(xc.Goerli, usdcEth.GetAssetConfig().Net)
+
+	_, err = cfg.GetAsset("DAI")
+	require.ErrorContains(err, "could not lookup asset")
+
+	require.Len(cfg.AllTasks, 1)
+	require.Len(cfg.AllTasks[0].AllowList, 1)
+
+	f := NewFactoryFromConfig(cfg)
+	asset, err := f.GetAssetConfig("USDC", "SOL")
+	require.NoError(err)
+	require.Equal("4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU", asset.GetAssetConfig().Contract)
+}
+
+func (s *CrosschainTestSuite) TestReadConfigSection() {
+	require := s.Require()
+	cfg, err := ReadConfig(strings.NewReader(`
+chains:
+- asset: BTC
+  net: mainnet
+  decimals: 8
+`))
+	require.NoError(err)
+	btc, err := cfg.GetAsset("BTC")
+	require.NoError(err)
+	require.Equal("bitcoin", btc.GetAssetConfig().Driver)
+
+	cfg, err = ReadConfig(strings.NewReader(`
+chains:
+- asset: XYZ
+  driver: evm
+  chain_id: 123
+  rollup:
+    stack: OP-Stack
+    l1_bridge: '0x1111111111111111111111111111111111111111'
+`))
+	require.NoError(err)
+	rollup := cfg.Chains[0].GetRollup()
+	require.Equal(xc.RollupOPStack, rollup.Stack)
+	require.Equal("0x1111111111111111111111111111111111111111", rollup.L1Bridge)
+	require.Equal("0x420000000000000000000000000000000000000F", rollup.GasOracle)
+}
+
+func (s *CrosschainTestSuite) TestReadConfigInvalid() {
+	require := s.Require()
+	vectors := []struct {
+		yaml string
+		err  string
+	}{
+		{"chains: [{asset: MATIC, driver: evm}]", "invalid chain MATIC: missing chain_id"},
+		{"chains: [{asset: XYZ}]", "invalid chain XYZ: unsupported driver: ''"},
+		{"chains: [{asset: BTC}, {asset: BTC}]", "duplicate chain: BTC"},
+		{"chains: [{driver: bitcoin}]", "invalid chain #0: missing asset"},
+		{"chains: [{asset: SOL, auth: 'invalid'}]", "invalid chain SOL: could not resolve auth"},
+		{"tokens: [{asset: USDC, chain: SOL, decimals: 6, contract: abc}]", "invalid token USDC.SOL: unknown chain: SOL"},
+		{"chains: [{asset: SOL}]\ntokens: [{asset: USDC, chain: SOL, decimals: 6}]", "invalid token USDC.SOL: missing contract"},
+		{"chains: [{asset: SOL}]\ntokens: [{asset: USDC, chain: SOL, contract: abc}]", "invalid token USDC.SOL: missing decimals"},
+		{"chains: [{asset: SOL}]\ntokens: [{asset: USDC, chain: SOL, decimals: 6, contract: abc}, {asset: USDC, chain: SOL, decimals: 6, contract: def}]", "duplicate token: USDC.SOL"},
+		{"chains: [{asset: OptETH, chain_id: 10, rollup: {stack: zk-stack}}]", "invalid chain OptETH: unsupported rollup stack: 'zk-stack'"},
+		{"chains: [{asset: SOL, rollup: {stack: op-stack}}]", "invalid chain SOL: rollup is only supported by EVM chains"},
+		{"chains: [{asset: [SOL]}]", "invalid config"},
+	}
+	for _, v := range vectors {
+		_, err := ReadConfig(strings.NewReader(v.yaml))
+		require.ErrorContains(err, v.err, v.yaml)
+	}
+
+	_, err := LoadConfig(filepath.Join(s.T().TempDir(), "missing.yaml"))
+	require.ErrorContains(err, "could not open config")
+}
+
+func (s *CrosschainTestSuite) TestLoadConfigOfRepo() {
+	require := s.Require()
+	cfg, err := LoadConfig("../crosschain.yaml")
+	require.NoError(err)
+	require.Equal(len(cfg.Chains)+len(cfg.Tokens), len(cfg.AllAssets))
+}
@@ -1,15 +1,31 @@
 package factory
 
 import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/coming-chat/go-sui/types"
 	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/algorand"
 	"github.com/jumpcrypto/crosschain/chain/aptos"
+	"github.com/jumpcrypto/crosschain/chain/avalanche"
 	"github.com/jumpcrypto/crosschain/chain/bitcoin"
 	"github.com/jumpcrypto/crosschain/chain/cosmos"
 	"github.com/jumpcrypto/crosschain/chain/evm"
+	"github.com/jumpcrypto/crosschain/chain/near"
 	"github.com/jumpcrypto/crosschain/chain/solana"
+	"github.com/jumpcrypto/crosschain/chain/starknet"
+	"github.com/jumpcrypto/crosschain/chain/stellar"
+	"github.com/jumpcrypto/crosschain/chain/substrate"
+	"github.com/jumpcrypto/crosschain/chain/sui"
+	"github.com/jumpcrypto/crosschain/chain/tron"
+	"github.com/jumpcrypto/crosschain/test"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/suite"
 )
@@ -60,6 +76,21 @@ func (s *CrosschainTestSuite) TestNewClient() {
 	require.EqualError(err, "unsupported asset")
 }
 
+func (s *CrosschainTestSuite) TestNewClientWithoutFactory() {
+	require := s.Require()
+	for _, asset := range s.TestAssetConfigs {
+		client, err := NewClient(asset)
+		require.NoError(err)
+		require.NotNil(client)
+	}
+	client, err := NewClient(&xc.AssetConfig{Asset: "TEST", Driver: string(xc.DriverCosmos), URL: "http://localhost"})
+	require.NoError(err)
+	require.NotNil(client)
+
+	_, err = NewClient(&xc.AssetConfig{Asset: "TEST"})
+	require.EqualError(err, "unsupported asset")
+}
+
 func (s *CrosschainTestSuite) TestNewTxBuilder() {
 	require := s.Require()
 	for _, asset := range s.TestAssetConfigs {
@@ -72,6 +103,76 @@ func (s *CrosschainTestSuite) TestNewTxBuilder() {
 	require.EqualError(err, "unsupported asset")
 }
 
+func (s *CrosschainTestSuite) TestNewTxBuilderMiddleware() {
+	require := s.Require()
+	asset, _ := s.Factory.GetAssetConfig("", "ETH")
+	builder, _ := s.Factory.NewTxBuilder(asset)
+	require.IsType(evm.TxBuilder{}, builder)
+
+	methods := []xc.TxBuildMethod{}
+	s.Factory.UseTxBuilderMiddleware(xc.BeforeBuild(func(req *xc.TxBuildRequest) error {
+		methods = append(methods, req.Method)
+		if req.To == "0x0000000000000000000000000000000000000000" {
+			return errors.New("burn address")
+		}
+		return nil
+	}))
+	builder, err := s.Factory.NewTxBuilder(asset)
+	require.NoError(err)
+	require.IsType(evm.TxBuilder{}, xc.UnwrapTxBuilder(builder))
+	// evm builds tasks
+	_, ok := builder.(xc.TxXTransferBuilder)
+	require.True(ok)
+
+	from := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	input := evm.NewTxInput()
+	_, err = builder.NewTransfer(from, "0x0000000000000000000000000000000000000000", xc.NewAmountBlockchainFromUint64(1), input)
+	require.EqualError(err, "burn address")
+	_, err = builder.NewTransfer(from, from, xc.NewAmountBlockchainFromUint64(1), input)
+	require.NoError(err)
+	require.Equal([]xc.TxBuildMethod{xc.TxBuildTransfer, xc.TxBuildTransfer}, methods)
+}
+
+func (s *CrosschainTestSuite) TestNewClientQuorumReads() {
+	require := s.Require()
+	primary, closePrimary := test.MockJSONRPC(&s.Suite, []string{`"0x64"`, `"0x64"`})
+	defer closePrimary()
+	backup, closeBackup := test.MockJSONRPC(&s.Suite, []string{`"0x64"`, `"0x3e8"`})
+	defer closeBackup()
+
+	asset, _ := s.Factory.PutAssetConfig(&xc.AssetConfig{
+		Asset:       "ETHQ",
+		NativeAsset: xc.ETH,
+		Driver:      string(xc.DriverEVM),
+		Net:         "mainnet",
+		URL:         primary.URL,
+		Endpoints:   []xc.Endpoint{{URL: backup.URL, Provider: "backup"}},
+		QuorumReads: 2,
+	})
+	divergences := []*xc.ProviderDivergence{}
+	s.Factory.RegisterProviderDivergenceCallback(func(divergence *xc.ProviderDivergence) {
+		divergences = append(divergences, divergence)
+	})
+	client, err := s.Factory.NewClient(asset)
+	require.NoError(err)
+	require.IsType(&xc.QuorumClient{}, client)
+	require.IsType(&evm.Client{}, xc.UnwrapClient(client))
+
+	address := xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	balance, err := client.(xc.ClientBalance).FetchNativeBalance(context.Background(), address)
+	require.NoError(err)
+	require.EqualValues(100, balance.Uint64())
+	_, err = client.(xc.ClientBalance).FetchNativeBalance(context.Background(), address)
+	require.ErrorIs(err, xc.ErrProviderDivergence)
+	require.Len(divergences, 1)
+	require.Equal(xc.ProviderAnswer{Provider: "backup", Answer: "1000"}, divergences[0].Answers[1])
+
+	// quorum reads need enough endpoints
+	asset.GetNativeAsset().QuorumReads = 3
+	_, err = s.Factory.NewClient(asset)
+	require.ErrorContains(err, "quorum_reads of ETHQ is 3, but it has 2 endpoints")
+}
+
 func (s *CrosschainTestSuite) TestNewSigner() {
 	require := s.Require()
 	for _, asset := range s.TestAssetConfigs {
@@ -84,6 +185,51 @@ func (s *CrosschainTestSuite) TestNewSigner() {
 	require.EqualError(err, "unsupported asset")
 }
 
+func (s *CrosschainTestSuite) TestNewLocalSigner() {
+	require := s.Require()
+	k256Key := "289c2857d4598e37fb9647507e47a309d6133539bf21a8b9cb6df88fd5232032"
+	ed255Seed, _ := hex.DecodeString("4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb")
+	ed255Key := ed25519.NewKeyFromSeed(ed255Seed)
+	ed255PublicKey := ed255Key.Public().(ed25519.PublicKey)
+
+	vectors := []struct {
+		asset      string
+		privateKey string
+		publicKey  string
+		address    string
+	}{
+		{"ETH", k256Key, "", "0x970E8128AB834E8EAC17Ab8E3812F010678CF791"},
+		{"BTC", k256Key, "", ""},
+		{"ATOM", k256Key, "", ""},
+		{"SOL", base58.Encode(ed255Key), hex.EncodeToString(ed255PublicKey), base58.Encode(ed255PublicKey)},
+		{"APTOS", hex.EncodeToString(ed255Seed), hex.EncodeToString(ed255PublicKey), ""},
+		{"SUI", hex.EncodeToString(ed255Seed), hex.EncodeToString(ed255PublicKey), ""},
+	}
+	for _, v := range vectors {
+		asset, _ := s.Factory.GetAssetConfig("", v.asset)
+		signer, err := s.Factory.NewLocalSigner(asset, v.privateKey)
+		require.NoError(err, v.asset)
+		publicKey, err := signer.PublicKey()
+		require.NoError(err)
+		if v.publicKey != "" {
+			require.Equal(v.publicKey, hex.EncodeToString(publicKey), v.asset)
+		}
+		address, err := s.Factory.GetAddressFromPublicKey(asset, publicKey)
+		require.NoError(err, v.asset)
+		require.NoError(s.Factory.ValidateAddress(asset, address), v.asset)
+		if v.address != "" {
+			require.Equal(xc.Address(v.address), address)
+		}
+		signature, err := signer.Sign(xc.TxDataToSign(make([]byte, 32)))
+		require.NoError(err)
+		require.NotEmpty(signature)
+	}
+
+	asset, _ := s.Factory.GetAssetConfig("", "SOL")
+	_, err := s.Factory.NewLocalSigner(asset, hex.EncodeToString(ed255Seed))
+	require.EqualError(err, "invalid ed25519 private key length 0")
+}
+
 func (s *CrosschainTestSuite) TestNewAddressBuilder() {
 	require := s.Require()
 	for _, asset := range s.TestAssetConfigs {
@@ -114,6 +260,20 @@ func (s *CrosschainTestSuite) TestGetAllPossibleAddressesFromPublicKey() {
 	}
 }
 
+func (s *CrosschainTestSuite) TestValidateAddress() {
+	require := s.Require()
+	eth, _ := s.Factory.GetAssetConfig("ETH", "")
+	require.NoError(s.Factory.ValidateAddress(eth, "0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fCe1"))
+	require.ErrorContains(s.Factory.ValidateAddress(eth, "0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fcE1"), "invalid EIP-55 checksum")
+
+	sol, _ := s.Factory.GetAssetConfig("SOL", "")
+	require.NoError(s.Factory.ValidateAddress(sol, "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"))
+	require.Error(s.Factory.ValidateAddress(sol, "0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fCe1"))
+
+	asset, _ := s.Factory.PutAssetConfig(&xc.AssetConfig{Asset: "TEST"})
+	require.EqualError(s.Factory.ValidateAddress(asset, "0x5891906fEf64A5ae924C7Fc5ed48c0F64a55fCe1"), "unsupported asset")
+}
+
 // MustObject functions
 
 func (s *CrosschainTestSuite) TestMustAmountBlockchain() {
@@ -382,6 +542,77 @@ func (s *CrosschainTestSuite) TestGetAssetConfigByContract() {
 	require.Equal("", asset.Asset)
 }
 
+func (s *CrosschainTestSuite) TestEnrichDestinationsUnknownToken() {
+	require := s.Require()
+	newTxInfo := func() xc.TxInfo {
+		return xc.TxInfo{
+			Destinations: []*xc.TxInfoEndpoint{
+				{Address: "0xto1", ContractAddress: "0xb4fbf271143f4fbf7b91a5ded31805e42b2208d6", Amount: xc.NewAmountBlockchainFromUint64(1)},
+				{Address: "0xto2", ContractAddress: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Amount: xc.NewAmountBlockchainFromUint64(2)},
+				{Address: "0xto3", Amount: xc.NewAmountBlockchainFromUint64(3)},
+			},
+		}
+	}
+	asset, err := s.Factory.GetAssetConfig("", "ETH")
+	require.NoError(err)
+
+	// include by default
+	info, err := s.Factory.EnrichDestinations(asset, newTxInfo())
+	require.NoError(err)
+	require.Len(info.Destinations, 3)
+	require.Equal(xc.Asset("WETH"), info.Destinations[0].Asset)
+	require.Equal(xc.ContractAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"), info.Destinations[1].ContractAddress)
+	require.Nil(info.Destinations[1].AssetConfig)
+	require.Equal(xc.ETH, info.Destinations[1].NativeAsset)
+	require.Equal("ETH", info.Destinations[2].AssetConfig.Asset)
+
+	s.Factory.SetUnknownTokenPolicy(xc.UnknownTokenDrop)
+	info, err = s.Factory.EnrichDestinations(asset, newTxInfo())
+	require.NoError(err)
+	require.Len(info.Destinations, 2)
+	require.Equal(xc.Address("0xto1"), info.Destinations[0].Address)
+	require.Equal(xc.Address("0xto3"), info.Destinations[1].Address)
+
+	// symbol() returns "USDT", decimals() returns 6
+	server, close := test.MockJSONRPC(&s.Suite, []string{`"0x000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000045553445400000000000000000000000000000000000000000000000000000000"`, `"0x0000000000000000000000000000000000000000000000000000000000000006"`})
+	defer close()
+	native := *asset.GetNativeAsset()
+	native.URL = server.URL
+	s.Factory.SetUnknownTokenPolicy(xc.UnknownTokenResolve)
+	for i := 0; i < 2; i++ {
+		// the second lookup is cached
+		info, err = s.Factory.EnrichDestinations(&native, newTxInfo())
+		require.NoError(err)
+		require.Len(info.Destinations, 3)
+		// the symbol claimed by the contract isn't trusted
+		require.Equal(xc.Asset(""), info.Destinations[1].Asset)
+		require.Equal("", info.Destinations[1].AssetConfig.Asset)
+		require.Equal("USDT", info.Destinations[1].AssetConfig.Name)
+		require.True(info.Destinations[1].AssetConfig.Unverified)
+		require.Equal(int32(6), info.Destinations[1].AssetConfig.Decimals)
+		require.Equal(xc.AssetTypeToken, info.Destinations[1].AssetConfig.Type)
+		require.Equal("0xdAC17F958D2ee523a2206206994597C13D831ec7", info.Destinations[1].AssetConfig.Contract)
+	}
+	require.Equal(2, server.Counter)
+	// resolved tokens aren't added to the config
+	_, err = s.Factory.GetAssetConfigByContract("0xdAC17F958D2ee523a2206206994597C13D831ec7", "ETH")
+	require.Error(err)
+
+	// resolution failures fall back to include
+	sol, err := s.Factory.GetAssetConfig("", "SOL")
+	require.NoError(err)
+	solServer, solClose := test.MockJSONRPC(&s.Suite, errors.New(`{"message": "Invalid param: not a Token mint", "code": -32602}`))
+	defer solClose()
+	solNative := *sol.GetNativeAsset()
+	solNative.URL = solServer.URL
+	info, err = s.Factory.EnrichDestinations(&solNative, xc.TxInfo{
+		Destinations: []*xc.TxInfoEndpoint{{Address: "to", ContractAddress: "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB"}},
+	})
+	require.NoError(err)
+	require.Len(info.Destinations, 1)
+	require.Nil(info.Destinations[0].AssetConfig)
+}
+
 func (s *CrosschainTestSuite) TestNormalizeAddressString() {
 	require := s.Require()
 	address := ""
@@ -397,6 +628,9 @@ func (s *CrosschainTestSuite) TestNormalizeAddressString() {
 
 	address = NormalizeAddressString("0x0ECE", "ETH")
 	require.Equal("0x0ece", address)
+
+	address = NormalizeAddressString("0x4718F5A0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d", "STRK")
+	require.Equal("0x04718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d", address)
 }
 
 func (s *CrosschainTestSuite) TestPutAssetConfig() {
@@ -419,12 +653,63 @@ func (s *CrosschainTestSuite) TestPutAssetConfig() {
 	require.Equal(assetName, asset.Asset)
 }
 
+func (s *CrosschainTestSuite) TestPutAssetConfigSecrets() {
+	require := s.Require()
+	redacted := func(text string) string {
+		return xc.DefaultRedactor.Redact(text)
+	}
+	_, err := s.Factory.PutAssetConfig(&xc.NativeAssetConfig{Asset: "SECRET", NativeAsset: "SECRET", AuthSecret: "old-s3cr3t"})
+	require.NoError(err)
+	require.Equal("key ***", redacted("key old-s3cr3t"))
+
+	// rotated secrets are no longer redacted
+	_, err = s.Factory.PutAssetConfig(&xc.NativeAssetConfig{Asset: "SECRET", NativeAsset: "SECRET", AuthSecret: "new-s3cr3t"})
+	require.NoError(err)
+	require.Equal("key old-s3cr3t ***", redacted("key old-s3cr3t new-s3cr3t"))
+	xc.DefaultRedactor.RemoveSecrets("new-s3cr3t")
+}
+
 func (s *CrosschainTestSuite) TestConfig() {
 	require := s.Require()
 	cfg := s.Factory.Config()
 	require.NotNil(cfg)
 }
 
+func (s *CrosschainTestSuite) TestFingerprint() {
+	require := s.Require()
+	fingerprint := s.Factory.Fingerprint()
+	require.Len(fingerprint, 64)
+	// identical definitions
+	require.Equal(fingerprint, NewDefaultFactory().Fingerprint())
+
+	_, err := s.Factory.PutAssetConfig(&xc.TokenAssetConfig{Asset: "TEST", Chain: "ETH", Decimals: 6})
+	require.NoError(err)
+	require.NotEqual(fingerprint, s.Factory.Fingerprint())
+}
+
+func (s *CrosschainTestSuite) TestAssetStatusConfig() {
+	require := s.Require()
+	f := NewDefaultFactoryWithConfig(map[string]interface{}{
+		"chains": []map[string]interface{}{
+			{"asset": "ETH", "driver": "evm", "net": "mainnet", "decimals": 18},
+			{"asset": "SOL", "driver": "solana", "net": "mainnet", "decimals": 9, "enabled": false},
+		},
+		"tokens": []map[string]interface{}{
+			{"asset": "USDC", "chain": "ETH", "net": "mainnet", "decimals": 6, "contract": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "paused_with_reason": "depeg"},
+			{"asset": "USDT", "chain": "ETH", "net": "mainnet", "decimals": 6, "contract": "0xdAC17F958D2ee523a2206206994597C13D831ec7", "receive_only": true},
+		},
+	})
+	eth, _ := f.GetAssetConfig("ETH", "")
+	require.NoError(xc.CheckSendAllowed(eth))
+	sol, _ := f.GetAssetConfig("SOL", "")
+	require.EqualError(xc.CheckSendAllowed(sol), "asset is disabled: SOL")
+	usdc, _ := f.GetAssetConfig("USDC", "ETH")
+	require.EqualError(xc.CheckSendAllowed(usdc), "asset is paused: USDC.ETH: depeg")
+	usdt, _ := f.GetAssetConfig("USDT", "ETH")
+	require.EqualError(xc.CheckSendAllowed(usdt), "asset is receive-only: USDT.ETH")
+	require.NoError(xc.CheckReceiveAllowed(usdt))
+}
+
 func (s *CrosschainTestSuite) TestTxInputSerDeser() {
 	require := s.Require()
 
@@ -505,6 +790,20 @@ func (s *CrosschainTestSuite) TestAllTxInputSerDeser() {
 			input = bitcoin.NewTxInput()
 		case xc.DriverSui:
 			input = bitcoin.NewTxInput()
+		case xc.DriverSubstrate:
+			input = substrate.NewTxInput()
+		case xc.DriverStarknet:
+			input = starknet.NewTxInput()
+		case xc.DriverTron:
+			input = tron.NewTxInput()
+		case xc.DriverAvalanche:
+			input = avalanche.NewTxInput()
+		case xc.DriverNear:
+			input = near.NewTxInput()
+		case xc.DriverStellar:
+			input = stellar.NewTxInput()
+		case xc.DriverAlgorand:
+			input = algorand.NewTxInput()
 		default:
 			require.Fail("must add driver to test: " + string(driver))
 		}
@@ -538,3 +837,131 @@ func (s *CrosschainTestSuite) TestMoveAddressNormalize() {
 	naddr = NormalizeMoveAddress("coin::Coin<0x1::coin::NAME>")
 	require.Equal("0x1::coin::NAME", naddr)
 }
+
+func (s *CrosschainTestSuite) TestConcurrentFactory() {
+	require := s.Require()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				token, err := s.Factory.GetAssetConfig("USDC", "SOL")
+				require.NoError(err)
+				require.Equal(xc.AssetTypeToken, token.GetAssetConfig().Type)
+				native, err := s.Factory.GetAssetConfig("", "ETH")
+				require.NoError(err)
+				require.Equal(xc.ETH, native.GetNativeAsset().NativeAsset)
+				_, err = s.Factory.NewTxBuilder(token)
+				require.NoError(err)
+				_, err = s.Factory.NewClient(native)
+				require.NoError(err)
+				_, err = s.Factory.GetAssetConfigByContract("4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU", "SOL")
+				require.NoError(err)
+				s.Factory.RegisterGetAssetConfigCallback(func(assetID xc.AssetID) (xc.ITask, error) {
+					return nil, fmt.Errorf("not found: %s", assetID)
+				})
+				_, err = s.Factory.GetAssetConfig("UNKNOWN", "SOL")
+				require.Error(err)
+				s.Factory.UnregisterGetAssetConfigCallback()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *CrosschainTestSuite) TestTxInputYAML() {
+	require := s.Require()
+	inputEvm := evm.NewTxInput()
+	inputEvm.Nonce = 7
+	inputEvm.GasLimit = 21000
+	inputEvm.GasFeeCap = xc.NewAmountBlockchainFromStr("1000000000000000000000000000000")
+	inputEvm.GasTipCap = xc.NewAmountBlockchainFromUint64(18446744073709551615)
+	inputEvm.Params = []string{"0x01"}
+
+	inputSolana := solana.NewTxInput()
+	inputSolana.RecentBlockHash = [32]byte{1, 2, 3}
+	inputSolana.MultisigSigners = []string{"Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"}
+
+	inputCosmos := cosmos.NewTxInput()
+	inputCosmos.FromPublicKey = []byte{1, 2, 3}
+	inputCosmos.GasPrice = 4.5
+	inputCosmos.Memo = "123"
+	inputCosmos.MultisigThreshold = 2
+	inputCosmos.MultisigPublicKeys = [][]byte{{1}, {2}, {3}}
+
+	inputBtc := bitcoin.NewTxInput()
+	inputBtc.UnspentOutputs = []bitcoin.Output{{Outpoint: bitcoin.Outpoint{Hash: []byte{1, 2}, Index: 1}, Value: xc.NewAmountBlockchainFromUint64(100)}}
+	inputBtc.GasPricePerByte = xc.NewAmountBlockchainFromUint64(12)
+
+	inputAptos := aptos.NewTxInput()
+	inputAptos.SequenceNumber = 3
+	inputAptos.ChainId = 1
+	inputAptos.Pubkey = []byte{4, 5, 6}
+
+	gasCoin := types.Coin{
+		CoinType:     "0x2::sui::SUI",
+		CoinObjectId: types.HexData{0x81, 0x92},
+		Version:      decimal.NewFromInt(1852477),
+		Digest:       "HmMNQCsgudhDdXGe9X75WVyPbJnjFApq1EvFhaRzNB1n",
+		Balance:      types.NewSafeSuiBigInt(uint64(10_000_000_000)),
+	}
+	inputSui := sui.NewTxInput()
+	inputSui.GasBudget = 100
+	inputSui.GasCoin = gasCoin
+	inputSui.Coins = []*types.Coin{&gasCoin}
+
+	for _, input := range []xc.TxInput{inputEvm, inputSolana, inputCosmos, inputBtc, inputAptos, inputSui} {
+		data, err := MarshalTxInputYAML(input)
+		require.NoError(err)
+		require.Contains(string(data), "version: 1")
+		deser, err := UnmarshalTxInput(data)
+		require.NoError(err)
+		require.IsType(input, deser)
+
+		expected, _ := MarshalTxInput(input)
+		actual, _ := MarshalTxInput(deser)
+		require.JSONEq(string(expected), string(actual), string(data))
+	}
+
+	// large integers aren't parsed as floats
+	data, _ := MarshalTxInputYAML(inputEvm)
+	require.Contains(string(data), `GasFeeCap: "1000000000000000000000000000000"`)
+	require.Contains(string(data), "GasTipCap: 18446744073709551615")
+	deser, _ := UnmarshalTxInput(data)
+	require.Equal("1000000000000000000000000000000", deser.(*evm.TxInput).GasFeeCap.String())
+}
+
+func (s *CrosschainTestSuite) TestUnmarshalTxInputVersions() {
+	require := s.Require()
+	// before versioning
+	input, err := UnmarshalTxInput([]byte(`{"type":"evm","Nonce":2}`))
+	require.NoError(err)
+	require.EqualValues(2, input.(*evm.TxInput).Nonce)
+	require.Equal(0, input.(*evm.TxInput).Version)
+
+	_, err = UnmarshalTxInput([]byte(`{"type":"evm","version":2,"Nonce":2}`))
+	require.EqualError(err, "unsupported TxInput version 2 of evm, up to version 1 is supported")
+	_, err = UnmarshalTxInput([]byte("type: evm\nversion: 2\n"))
+	require.EqualError(err, "unsupported TxInput version 2 of evm, up to version 1 is supported")
+	_, err = UnmarshalTxInput([]byte(`{"type":"unknown"}`))
+	require.EqualError(err, "invalid TxInput type: unknown")
+}
+
+func (s *CrosschainTestSuite) TestUnmarshalTxInputOfChain() {
+	require := s.Require()
+	data, _ := MarshalTxInput(evm.NewTxInput())
+	input, err := UnmarshalTxInputOfChain(xc.ETH, data)
+	require.NoError(err)
+	require.IsType(&evm.TxInput{}, input)
+
+	// evm-legacy chains use the inputs of evm
+	legacy := evm.NewTxInput()
+	legacy.Type = xc.DriverEVMLegacy
+	data, _ = MarshalTxInput(legacy)
+	_, err = UnmarshalTxInputOfChain(xc.ETH, data)
+	require.NoError(err)
+
+	_, err = UnmarshalTxInputOfChain(xc.SOL, data)
+	require.EqualError(err, "TxInput of evm-legacy is not an input of SOL")
+}
@@ -1,26 +1,38 @@
 package factory
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/jinzhu/copier"
 	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 
 	. "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/algorand"
 	"github.com/jumpcrypto/crosschain/chain/aptos"
+	"github.com/jumpcrypto/crosschain/chain/avalanche"
 	"github.com/jumpcrypto/crosschain/chain/bitcoin"
 	"github.com/jumpcrypto/crosschain/chain/cosmos"
 	"github.com/jumpcrypto/crosschain/chain/evm"
+	"github.com/jumpcrypto/crosschain/chain/near"
 	"github.com/jumpcrypto/crosschain/chain/solana"
+	"github.com/jumpcrypto/crosschain/chain/starknet"
+	"github.com/jumpcrypto/crosschain/chain/stellar"
+	"github.com/jumpcrypto/crosschain/chain/substrate"
 	"github.com/jumpcrypto/crosschain/chain/sui"
+	"github.com/jumpcrypto/crosschain/chain/tron"
 	"github.com/jumpcrypto/crosschain/config"
 )
 
@@ -29,6 +41,7 @@ type FactoryContext interface {
 	NewClient(asset ITask) (Client, error)
 	NewTxBuilder(asset ITask) (TxBuilder, error)
 	NewSigner(asset ITask) (Signer, error)
+	NewLocalSigner(asset ITask, privateKey string) (KeySigner, error)
 	NewAddressBuilder(asset ITask) (AddressBuilder, error)
 
 	MarshalTxInput(input TxInput) ([]byte, error)
@@ -36,6 +49,7 @@ type FactoryContext interface {
 
 	GetAddressFromPublicKey(asset ITask, publicKey []byte) (Address, error)
 	GetAllPossibleAddressesFromPublicKey(asset ITask, publicKey []byte) ([]PossibleAddress, error)
+	ValidateAddress(asset ITask, address Address) error
 
 	MustAmountBlockchain(asset ITask, humanAmountStr string) AmountBlockchain
 	MustAddress(asset ITask, addressStr string) Address
@@ -52,6 +66,7 @@ type FactoryContext interface {
 	GetAssetConfigByContract(contract string, nativeAsset string) (ITask, error)
 	PutAssetConfig(config ITask) (ITask, error)
 	Config() interface{}
+	Fingerprint() string
 
 	GetAllAssets() []ITask
 	GetAllTasks() []*TaskConfig
@@ -64,6 +79,9 @@ type FactoryContext interface {
 	UnregisterGetAssetConfigCallback()
 	RegisterGetAssetConfigByContractCallback(callback func(contract string, nativeAsset string) (ITask, error))
 	UnregisterGetAssetConfigByContractCallback()
+	SetUnknownTokenPolicy(policy UnknownTokenPolicy)
+	UseTxBuilderMiddleware(middlewares ...TxBuilderMiddleware)
+	RegisterProviderDivergenceCallback(callback func(divergence *ProviderDivergence))
 }
 
 // Factory is the main Factory implementation, holding the config
@@ -71,8 +89,14 @@ type Factory struct {
 	AllAssets                        sync.Map
 	AllTasks                         []*TaskConfig
 	AllPipelines                     []*PipelineConfig
+	callbackMu                       sync.RWMutex
 	callbackGetAssetConfig           func(assetID AssetID) (ITask, error)
 	callbackGetAssetConfigByContract func(contract string, nativeAsset string) (ITask, error)
+	unknownTokenPolicy               UnknownTokenPolicy
+	txBuilderMiddlewares             []TxBuilderMiddleware
+	onProviderDivergence             func(divergence *ProviderDivergence)
+	// token metadata fetched on chain by UnknownTokenResolve, by native asset and contract
+	resolvedTokens sync.Map
 }
 
 var _ FactoryContext = &Factory{}
@@ -109,27 +133,36 @@ func (f *Factory) GetAllTasks() []*TaskConfig {
 func (f *Factory) cfgFromAsset(assetID AssetID) (ITask, error) {
 	cfgI, found := f.AllAssets.Load(assetID)
 	if !found {
-		if f.callbackGetAssetConfig != nil {
-			return f.callbackGetAssetConfig(assetID)
+		if callback := f.getAssetConfigCallback(); callback != nil {
+			return callback(assetID)
 		}
 		return &NativeAssetConfig{}, fmt.Errorf("could not lookup asset: '%s'", assetID)
 	}
-	if cfg, ok := cfgI.(*NativeAssetConfig); ok {
+	// stored configs are shared across goroutines: normalize a copy rather than the stored config
+	if stored, ok := cfgI.(*NativeAssetConfig); ok {
 		// native asset
-		cfg.Type = AssetTypeNative
-		cfg.Chain = cfg.Asset
-		cfg.NativeAsset = NativeAsset(cfg.Asset)
+		cfg := normalizeNativeAssetConfig(stored)
 		return cfg, nil
 	}
-	if cfg, ok := cfgI.(*TokenAssetConfig); ok {
+	if stored, ok := cfgI.(*TokenAssetConfig); ok {
 		// token
+		cfg := *stored
 		copier.CopyWithOption(&cfg.AssetConfig, &cfg, copier.Option{IgnoreEmpty: false, DeepCopy: false})
-		cfg, _ = f.cfgEnrichAssetConfig(cfg)
-		return cfg, nil
+		enriched, _ := f.cfgEnrichAssetConfig(&cfg)
+		return enriched, nil
 	}
 	return &NativeAssetConfig{}, fmt.Errorf("invalid asset: '%s'", assetID)
 }
 
+// normalizeNativeAssetConfig returns a copy of a stored native asset config with its derived fields set
+func normalizeNativeAssetConfig(stored *NativeAssetConfig) *NativeAssetConfig {
+	cfg := *stored
+	cfg.Type = AssetTypeNative
+	cfg.Chain = cfg.Asset
+	cfg.NativeAsset = NativeAsset(cfg.Asset)
+	return &cfg
+}
+
 func (f *Factory) cfgFromAssetByContract(contract string, nativeAsset string) (ITask, error) {
 	var res ITask
 	contract = NormalizeAddressString(contract, nativeAsset)
@@ -150,8 +183,8 @@ func (f *Factory) cfgFromAssetByContract(contract string, nativeAsset string) (I
 	if res != nil {
 		return f.cfgFromAsset(res.ID())
 	} else {
-		if f.callbackGetAssetConfigByContract != nil {
-			return f.callbackGetAssetConfigByContract(contract, nativeAsset)
+		if callback := f.getAssetConfigByContractCallback(); callback != nil {
+			return callback(contract, nativeAsset)
 		}
 	}
 	return &TokenAssetConfig{}, fmt.Errorf("invalid contract: '%s'", contract)
@@ -318,42 +351,64 @@ func (f *Factory) cfgEnrichAssetConfig(partialCfg *TokenAssetConfig) (*TokenAsse
 		if !found {
 			return cfg, fmt.Errorf("unsupported native asset: %s", nativeAsset)
 		}
-		chain := chainI.(*NativeAssetConfig)
-		cfg.NativeAssetConfig = chain
-		if cfg.NativeAssetConfig.NativeAsset == "" {
-			cfg.NativeAssetConfig.NativeAsset = NativeAsset(cfg.NativeAssetConfig.Asset)
-		}
-		// deprecated fields below
-		cfg.Driver = chain.Driver
-		cfg.Net = chain.Net
-		cfg.URL = chain.URL
-		cfg.FcdURL = chain.FcdURL
-		cfg.Auth = chain.Auth
-		cfg.AuthSecret = chain.AuthSecret
-		cfg.Provider = chain.Provider
-		cfg.ChainID = chain.ChainID
-		cfg.ChainIDStr = chain.ChainIDStr
-		cfg.ChainGasMultiplier = chain.ChainGasMultiplier
-		cfg.ExplorerURL = chain.ExplorerURL
-		cfg.NoGasFees = chain.NoGasFees
-		cfg.GasCoin = chain.GasCoin
-		cfg.ChainPrefix = chain.ChainPrefix
+		enrichTokenFromChain(cfg, normalizeNativeAssetConfig(chainI.(*NativeAssetConfig)))
 	} else {
 		return cfg, fmt.Errorf("unsupported native asset: (empty)")
 	}
 	return cfg, nil
 }
 
+// enrichTokenFromChain sets the chain of a token and copies the fields of the chain it's sent with
+func enrichTokenFromChain(cfg *TokenAssetConfig, chain *NativeAssetConfig) {
+	cfg.NativeAssetConfig = chain
+	// deprecated fields below
+	cfg.Driver = chain.Driver
+	cfg.Net = chain.Net
+	cfg.URL = chain.URL
+	cfg.FcdURL = chain.FcdURL
+	cfg.Auth = chain.Auth
+	cfg.AuthSecret = chain.AuthSecret
+	cfg.AuthKeyID = chain.AuthKeyID
+	cfg.RequestSigning = chain.RequestSigning
+	cfg.RequestSigner = chain.RequestSigner
+	cfg.Region = chain.Region
+	cfg.Endpoints = chain.Endpoints
+	cfg.AllowedRegions = chain.AllowedRegions
+	cfg.DeniedRegions = chain.DeniedRegions
+	cfg.HostRegions = chain.HostRegions
+	cfg.QuorumReads = chain.QuorumReads
+	cfg.Provider = chain.Provider
+	cfg.ChainID = chain.ChainID
+	cfg.ChainIDStr = chain.ChainIDStr
+	cfg.ChainGasMultiplier = chain.ChainGasMultiplier
+	cfg.ExplorerURL = chain.ExplorerURL
+	cfg.NoGasFees = chain.NoGasFees
+	cfg.GasCoin = chain.GasCoin
+	cfg.ChainPrefix = chain.ChainPrefix
+	cfg.Rollup = chain.Rollup
+}
+
 func (f *Factory) cfgEnrichDestinations(activity ITask, txInfo TxInfo) (TxInfo, error) {
 	asset := activity.GetAssetConfig()
 	result := txInfo
 	nativeAssetCfg := activity.GetNativeAsset()
-	for i, dst := range txInfo.Destinations {
+	policy := f.getUnknownTokenPolicy()
+	destinations := make([]*TxInfoEndpoint, 0, len(txInfo.Destinations))
+	for _, dst := range txInfo.Destinations {
 		dst.NativeAsset = asset.NativeAsset
 		if dst.ContractAddress != "" {
 			assetCfgI, err := f.cfgFromAssetByContract(string(dst.ContractAddress), string(dst.NativeAsset))
 			if err != nil {
 				// we shouldn't set the amount, if we don't know the contract
+				switch policy {
+				case UnknownTokenDrop:
+					continue
+				case UnknownTokenResolve:
+					if assetCfg, err := f.resolveToken(nativeAssetCfg, dst.ContractAddress); err == nil {
+						dst.AssetConfig = assetCfg
+					}
+				}
+				destinations = append(destinations, dst)
 				continue
 			}
 			assetCfg := assetCfgI.GetAssetConfig()
@@ -363,19 +418,78 @@ func (f *Factory) cfgEnrichDestinations(activity ITask, txInfo TxInfo) (TxInfo,
 		} else {
 			dst.AssetConfig = nativeAssetCfg
 		}
-		result.Destinations[i] = dst
+		destinations = append(destinations, dst)
 	}
+	result.Destinations = destinations
 	return result, nil
 }
 
+// ResolveTokenTimeout is the timeout of the metadata fetch of a token resolved by UnknownTokenResolve
+const ResolveTokenTimeout = 10 * time.Second
+
+// resolveToken fetches the metadata of a token missing from the config, results are cached
+// The resolved config isn't added to the config, and is Unverified: its symbol can't be trusted
+func (f *Factory) resolveToken(nativeAssetCfg *NativeAssetConfig, contract ContractAddress) (*AssetConfig, error) {
+	if nativeAssetCfg == nil {
+		return nil, errors.New("unknown native asset")
+	}
+	key := string(nativeAssetCfg.NativeAsset) + "/" + NormalizeContractAddress(Driver(nativeAssetCfg.Driver), string(contract))
+	if cached, ok := f.resolvedTokens.Load(key); ok {
+		return cached.(*AssetConfig), nil
+	}
+	client, err := f.NewClient(nativeAssetCfg)
+	if err != nil {
+		return nil, err
+	}
+	metadataClient, ok := UnwrapClient(client).(ClientTokenMetadata)
+	if !ok {
+		return nil, fmt.Errorf("token metadata is not supported by %s", nativeAssetCfg.Driver)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ResolveTokenTimeout)
+	defer cancel()
+	metadata, err := metadataClient.FetchTokenMetadata(ctx, contract)
+	if err != nil {
+		return nil, err
+	}
+	assetCfg := &AssetConfig{
+		Name:        metadata.Symbol,
+		Unverified:  true,
+		Chain:       string(nativeAssetCfg.NativeAsset),
+		Contract:    string(contract),
+		Decimals:    metadata.Decimals,
+		Net:         nativeAssetCfg.Net,
+		Driver:      nativeAssetCfg.Driver,
+		Type:        AssetTypeToken,
+		NativeAsset: nativeAssetCfg.NativeAsset,
+	}
+	f.resolvedTokens.Store(key, assetCfg)
+	return assetCfg, nil
+}
+
 // NewClient creates a new Client
+// The client of a chain with quorum_reads is a QuorumClient, cross-checking critical reads across its endpoints:
+// check the optional interfaces of clients, e.g. ClientDiagnose, on UnwrapClient(client)
 func (f *Factory) NewClient(cfg ITask) (Client, error) {
-	return newClient(cfg)
+	client, err := newClient(cfg)
+	if err != nil {
+		return client, err
+	}
+	f.callbackMu.RLock()
+	onDivergence := f.onProviderDivergence
+	f.callbackMu.RUnlock()
+	return newQuorumClient(cfg, client, onDivergence)
 }
 
 // NewTxBuilder creates a new TxBuilder
 func (f *Factory) NewTxBuilder(cfg ITask) (TxBuilder, error) {
-	return newTxBuilder(cfg)
+	builder, err := newTxBuilder(cfg)
+	if err != nil {
+		return builder, err
+	}
+	f.callbackMu.RLock()
+	middlewares := f.txBuilderMiddlewares
+	f.callbackMu.RUnlock()
+	return WrapTxBuilder(cfg, builder, middlewares...), nil
 }
 
 // NewSigner creates a new Signer
@@ -383,6 +497,19 @@ func (f *Factory) NewSigner(cfg ITask) (Signer, error) {
 	return newSigner(cfg)
 }
 
+// NewLocalSigner creates a new KeySigner of a private key of the chain of an asset, imported by its Signer
+func (f *Factory) NewLocalSigner(cfg ITask, privateKey string) (KeySigner, error) {
+	signer, err := newSigner(cfg)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := signer.ImportPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewLocalSigner(signer, imported)
+}
+
 // NewAddressBuilder creates a new AddressBuilder
 func (f *Factory) NewAddressBuilder(cfg ITask) (AddressBuilder, error) {
 	return newAddressBuilder(cfg)
@@ -412,6 +539,12 @@ func (f *Factory) GetAllPossibleAddressesFromPublicKey(cfg ITask, publicKey []by
 	return builder.GetAllPossibleAddressesFromPublicKey(publicKey)
 }
 
+// ValidateAddress checks the format and checksum of an address of the chain of an asset,
+// e.g. EIP-55 for EVM chains, the bech32 prefix for Cosmos chains and the network version for UTXO chains
+func (f *Factory) ValidateAddress(cfg ITask, address Address) error {
+	return validateAddress(cfg, address)
+}
+
 // ConvertAmountToHuman converts an AmountBlockchain into AmountHumanReadable, dividing by the appropriate number of decimals
 func (f *Factory) ConvertAmountToHuman(cfg ITask, blockchainAmount AmountBlockchain) (AmountHumanReadable, error) {
 	return convertAmountToHuman(cfg, blockchainAmount)
@@ -457,19 +590,67 @@ func (f *Factory) GetTaskConfigBySrcDstAssets(srcAsset ITask, dstAsset ITask) ([
 	return f.getTaskConfigBySrcDstAssets(srcAsset, dstAsset)
 }
 
+func (f *Factory) getAssetConfigCallback() func(assetID AssetID) (ITask, error) {
+	f.callbackMu.RLock()
+	defer f.callbackMu.RUnlock()
+	return f.callbackGetAssetConfig
+}
+
+func (f *Factory) getAssetConfigByContractCallback() func(contract string, nativeAsset string) (ITask, error) {
+	f.callbackMu.RLock()
+	defer f.callbackMu.RUnlock()
+	return f.callbackGetAssetConfigByContract
+}
+
+func (f *Factory) getUnknownTokenPolicy() UnknownTokenPolicy {
+	f.callbackMu.RLock()
+	defer f.callbackMu.RUnlock()
+	return f.unknownTokenPolicy
+}
+
+// SetUnknownTokenPolicy sets how EnrichDestinations handles tokens missing from the config, UnknownTokenInclude by default
+func (f *Factory) SetUnknownTokenPolicy(policy UnknownTokenPolicy) {
+	f.callbackMu.Lock()
+	defer f.callbackMu.Unlock()
+	f.unknownTokenPolicy = policy
+}
+
+// UseTxBuilderMiddleware adds middlewares to the TxBuilders created next, after the middlewares already used
+func (f *Factory) UseTxBuilderMiddleware(middlewares ...TxBuilderMiddleware) {
+	f.callbackMu.Lock()
+	defer f.callbackMu.Unlock()
+	f.txBuilderMiddlewares = append(append([]TxBuilderMiddleware{}, f.txBuilderMiddlewares...), middlewares...)
+}
+
+// RegisterProviderDivergenceCallback sets a callback called with the divergences of the quorum reads of the clients
+// created next, e.g. to alert on or count faulty providers
+func (f *Factory) RegisterProviderDivergenceCallback(callback func(divergence *ProviderDivergence)) {
+	f.callbackMu.Lock()
+	defer f.callbackMu.Unlock()
+	f.onProviderDivergence = callback
+}
+
 func (f *Factory) RegisterGetAssetConfigCallback(callback func(assetID AssetID) (ITask, error)) {
+	f.callbackMu.Lock()
+	defer f.callbackMu.Unlock()
 	f.callbackGetAssetConfig = callback
 }
 
 func (f *Factory) UnregisterGetAssetConfigCallback() {
+	f.callbackMu.Lock()
+	defer f.callbackMu.Unlock()
 	f.callbackGetAssetConfig = nil
 }
 
 func (f *Factory) RegisterGetAssetConfigByContractCallback(callback func(contract string, nativeAsset string) (ITask, error)) {
+	f.callbackMu.Lock()
+	defer f.callbackMu.Unlock()
 	f.callbackGetAssetConfigByContract = callback
 }
 
 func (f *Factory) UnregisterGetAssetConfigByContractCallback() {
+	f.callbackMu.Lock()
+	defer f.callbackMu.Unlock()
 	f.callbackGetAssetConfigByContract = nil
 }
 
@@ -483,9 +664,13 @@ func (f *Factory) GetAssetConfigByContract(contract string, nativeAsset string)
 	return f.cfgFromAssetByContract(contract, nativeAsset)
 }
 
-// PutAssetConfig adds an AssetConfig to the current Config cache
+// PutAssetConfig adds an AssetConfig to the current Config cache, replacing the config of the same id
 func (f *Factory) PutAssetConfig(cfgI ITask) (ITask, error) {
-	f.AllAssets.Store(cfgI.ID(), cfgI)
+	previous, replaced := f.AllAssets.Load(cfgI.ID())
+	f.AllAssets.Store(cfgI.ID(), prepareAssetConfig(cfgI))
+	if replaced {
+		releaseAssetConfig(previous.(ITask))
+	}
 	return f.cfgFromAsset(cfgI.ID())
 }
 
@@ -494,6 +679,12 @@ func (f *Factory) Config() interface{} {
 	return f.AllAssets
 }
 
+// Fingerprint returns a stable hash of the assets, tasks and pipelines of the Factory, see ConfigFingerprint:
+// services loading identical definitions have the same fingerprint
+func (f *Factory) Fingerprint() string {
+	return ConfigFingerprint(f.GetAllAssets(), f.AllTasks, f.AllPipelines)
+}
+
 // MustAddress coverts a string to Address, panic if error
 func (f *Factory) MustAddress(cfg ITask, addressStr string) Address {
 	return Address(addressStr)
@@ -528,6 +719,10 @@ func assetsFromConfig(configMap map[string]interface{}) []ITask {
 
 	var allAssets []ITask
 	for _, c := range mainConfig.Chains {
+		// normalize aliases, unknown networks are kept as is
+		if net, err := ParseNet(string(c.Net)); err == nil {
+			c.Net = net
+		}
 		allAssets = append(allAssets, c)
 	}
 
@@ -603,11 +798,13 @@ func NewDefaultFactoryWithConfig(cfg map[string]interface{}) *Factory {
 	tasksList := tasksFromConfig(cfg)
 	pipelinesList := pipelinesFromConfig(cfg)
 
-	return &Factory{
+	f := &Factory{
 		AllAssets:    assetsMap,
 		AllTasks:     tasksList,
 		AllPipelines: pipelinesList,
 	}
+	log.WithField("fingerprint", f.Fingerprint()).WithField("assets", len(assetsList)).Info("loaded crosschain config")
+	return f
 }
 
 // AssetsToMap loads chains config without config file
@@ -622,11 +819,67 @@ func AssetsToMap(assetsList []ITask) sync.Map {
 				// ignore error
 			}
 		}
-		assetsMap.Store(cfgI.ID(), cfgI)
+		assetsMap.Store(cfgI.ID(), prepareAssetConfig(cfgI))
 	}
 	return assetsMap
 }
 
+// prepareAssetConfig fills the embedded AssetConfig of a token before it's stored,
+// stored configs are then only read
+func prepareAssetConfig(cfgI ITask) ITask {
+	if cfg, ok := cfgI.(*TokenAssetConfig); ok {
+		copier.CopyWithOption(&cfg.AssetConfig, &cfg, copier.Option{IgnoreEmpty: false, DeepCopy: false})
+	}
+	// secrets may be part of urls or errors of providers, e.g. an API key in the path of an EVM url
+	DefaultRedactor.AddSecrets(cfgI.GetAssetConfig().AuthSecret)
+	return cfgI
+}
+
+// releaseAssetConfig unregisters the secrets of a config prepared by prepareAssetConfig once it's replaced
+func releaseAssetConfig(cfgI ITask) {
+	DefaultRedactor.RemoveSecrets(cfgI.GetAssetConfig().AuthSecret)
+}
+
+// NewClient creates the client of the driver of an asset, for chain agnostic code without a Factory
+// The root package can't create clients itself, as chain packages depend on it
+func NewClient(cfg ITask) (FullClient, error) {
+	client, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if client, err = newQuorumClient(cfg, client, nil); err != nil {
+		return nil, err
+	}
+	fullClient, ok := client.(FullClient)
+	if !ok {
+		return nil, fmt.Errorf("balances are not supported by driver %s", cfg.GetDriver())
+	}
+	return fullClient, nil
+}
+
+// newQuorumClient wraps the client of a chain with quorum_reads in a QuorumClient of a client per endpoint
+func newQuorumClient(cfg ITask, client Client, onDivergence func(divergence *ProviderDivergence)) (Client, error) {
+	nativeAsset := cfg.GetNativeAsset()
+	if cfg.GetTask() != nil || nativeAsset == nil {
+		return client, nil
+	}
+	endpoints, err := nativeAsset.QuorumEndpoints()
+	if err != nil || len(endpoints) == 0 {
+		return client, err
+	}
+	providers := []ProviderClient{}
+	for _, endpoint := range endpoints {
+		providerClient, err := newClient(EndpointAsset(cfg, endpoint))
+		if err != nil {
+			return client, err
+		}
+		providers = append(providers, ProviderClient{Provider: endpoint.ProviderName(), Client: providerClient})
+	}
+	quorumClient := NewQuorumClient(cfg, client, providers, nativeAsset.QuorumReads)
+	quorumClient.OnDivergence = onDivergence
+	return quorumClient, nil
+}
+
 func newClient(cfg ITask) (Client, error) {
 	switch Driver(cfg.GetDriver()) {
 	case DriverEVM:
@@ -639,11 +892,29 @@ func newClient(cfg ITask) (Client, error) {
 		return solana.NewClient(cfg)
 	case DriverAptos:
 		return aptos.NewClient(cfg)
+	case DriverSubstrate:
+		return substrate.NewClient(cfg)
+	case DriverStarknet:
+		return starknet.NewClient(cfg)
+	case DriverTron:
+		return tron.NewClient(cfg)
+	case DriverAvalanche:
+		return avalanche.NewClient(cfg)
+	case DriverNear:
+		return near.NewClient(cfg)
+	case DriverStellar:
+		return stellar.NewClient(cfg)
+	case DriverAlgorand:
+		return algorand.NewClient(cfg)
 	case DriverSui:
 		return sui.NewClient(cfg)
 	case DriverBitcoin:
 		return bitcoin.NewClient(cfg)
 	}
+	// drivers implemented outside of crosschain
+	if constructors, ok := LookupDriver(Driver(cfg.GetDriver())); ok && constructors.NewClient != nil {
+		return constructors.NewClient(cfg)
+	}
 	return nil, errors.New("unsupported asset")
 }
 
@@ -659,11 +930,29 @@ func newTxBuilder(cfg ITask) (TxBuilder, error) {
 		return solana.NewTxBuilder(cfg)
 	case DriverAptos:
 		return aptos.NewTxBuilder(cfg)
+	case DriverSubstrate:
+		return substrate.NewTxBuilder(cfg)
+	case DriverStarknet:
+		return starknet.NewTxBuilder(cfg)
+	case DriverTron:
+		return tron.NewTxBuilder(cfg)
+	case DriverAvalanche:
+		return avalanche.NewTxBuilder(cfg)
+	case DriverNear:
+		return near.NewTxBuilder(cfg)
+	case DriverStellar:
+		return stellar.NewTxBuilder(cfg)
+	case DriverAlgorand:
+		return algorand.NewTxBuilder(cfg)
 	case DriverSui:
 		return sui.NewTxBuilder(cfg)
 	case DriverBitcoin:
 		return bitcoin.NewTxBuilder(cfg)
 	}
+	// drivers implemented outside of crosschain
+	if constructors, ok := LookupDriver(Driver(cfg.GetDriver())); ok && constructors.NewTxBuilder != nil {
+		return constructors.NewTxBuilder(cfg)
+	}
 	return nil, errors.New("unsupported asset")
 }
 
@@ -677,11 +966,29 @@ func newSigner(cfg ITask) (Signer, error) {
 		return solana.NewSigner(cfg)
 	case DriverAptos:
 		return aptos.NewSigner(cfg)
+	case DriverSubstrate:
+		return substrate.NewSigner(cfg)
+	case DriverStarknet:
+		return starknet.NewSigner(cfg)
+	case DriverTron:
+		return tron.NewSigner(cfg)
+	case DriverAvalanche:
+		return avalanche.NewSigner(cfg)
+	case DriverNear:
+		return near.NewSigner(cfg)
+	case DriverStellar:
+		return stellar.NewSigner(cfg)
+	case DriverAlgorand:
+		return algorand.NewSigner(cfg)
 	case DriverBitcoin:
 		return bitcoin.NewSigner(cfg)
 	case DriverSui:
 		return sui.NewSigner(cfg)
 	}
+	// drivers implemented outside of crosschain
+	if constructors, ok := LookupDriver(Driver(cfg.GetDriver())); ok && constructors.NewSigner != nil {
+		return constructors.NewSigner(cfg)
+	}
 	return nil, errors.New("unsupported asset")
 }
 
@@ -695,53 +1002,197 @@ func newAddressBuilder(cfg ITask) (AddressBuilder, error) {
 		return solana.NewAddressBuilder(cfg)
 	case DriverAptos:
 		return aptos.NewAddressBuilder(cfg)
+	case DriverSubstrate:
+		return substrate.NewAddressBuilder(cfg)
+	case DriverStarknet:
+		return starknet.NewAddressBuilder(cfg)
+	case DriverTron:
+		return tron.NewAddressBuilder(cfg)
+	case DriverAvalanche:
+		return avalanche.NewAddressBuilder(cfg)
+	case DriverNear:
+		return near.NewAddressBuilder(cfg)
+	case DriverStellar:
+		return stellar.NewAddressBuilder(cfg)
+	case DriverAlgorand:
+		return algorand.NewAddressBuilder(cfg)
 	case DriverBitcoin:
 		return bitcoin.NewAddressBuilder(cfg)
 	case DriverSui:
 		return sui.NewAddressBuilder(cfg)
 	}
+	// drivers implemented outside of crosschain
+	if constructors, ok := LookupDriver(Driver(cfg.GetDriver())); ok && constructors.NewAddressBuilder != nil {
+		return constructors.NewAddressBuilder(cfg)
+	}
 	return nil, errors.New("unsupported asset")
 }
 
+func validateAddress(cfg ITask, address Address) error {
+	builder, err := newAddressBuilder(cfg)
+	if err != nil {
+		return err
+	}
+	validator, ok := builder.(AddressValidator)
+	if !ok {
+		return fmt.Errorf("address validation is not supported for %s", cfg.ID())
+	}
+	return validator.ValidateAddress(address)
+}
+
 func MarshalTxInput(txInput TxInput) ([]byte, error) {
 	return json.Marshal(txInput)
 }
 
+// MarshalTxInputYAML marshals a TxInput in YAML, with the fields of its JSON serialization, see UnmarshalTxInput
+func MarshalTxInputYAML(txInput TxInput) ([]byte, error) {
+	data, err := json.Marshal(txInput)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(jsonToYAML(fields))
+}
+
+// jsonToYAML converts JSON numbers to YAML numbers, and integers beyond 64 bits to strings so they're not parsed as floats
+func jsonToYAML(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			value[key] = jsonToYAML(field)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = jsonToYAML(item)
+		}
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+			return u
+		}
+		if strings.ContainsAny(value.String(), ".eE") {
+			f, _ := value.Float64()
+			return f
+		}
+		return value.String()
+	}
+	return value
+}
+
+// yamlToJSON converts the maps of YAML, with keys of any type, to JSON objects
+func yamlToJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		fields := map[string]interface{}{}
+		for key, field := range value {
+			fields[fmt.Sprint(key)] = yamlToJSON(field)
+		}
+		return fields
+	case []interface{}:
+		for i, item := range value {
+			value[i] = yamlToJSON(item)
+		}
+	}
+	return value
+}
+
+// UnmarshalTxInput unmarshals a TxInput marshalled in JSON or YAML, into the TxInput of its driver
+// Inputs of a newer version than TxInputVersion are rejected, their fields may not be understood
 func UnmarshalTxInput(data []byte) (TxInput, error) {
+	txInput, _, err := unmarshalTxInput(data)
+	return txInput, err
+}
+
+func unmarshalTxInput(data []byte) (TxInput, Driver, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] != '{' {
+		var fields interface{}
+		if err := yaml.Unmarshal(data, &fields); err != nil {
+			return nil, "", err
+		}
+		var err error
+		if data, err = json.Marshal(yamlToJSON(fields)); err != nil {
+			return nil, "", err
+		}
+	}
 	var env TxInputEnvelope
-	buf := []byte(data)
-	err := json.Unmarshal(buf, &env)
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, "", err
+	}
+	if env.Version > TxInputVersion {
+		return nil, "", fmt.Errorf("unsupported TxInput version %d of %s, up to version %d is supported", env.Version, env.Type, TxInputVersion)
+	}
+	txInput, err := newTxInput(env.Type)
+	if err != nil {
+		return nil, "", err
+	}
+	err = json.Unmarshal(data, txInput)
+	return txInput, env.Type, err
+}
+
+// UnmarshalTxInputOfChain unmarshals a TxInput of a chain, see UnmarshalTxInput,
+// checking it's of the driver of the chain, e.g. before signing it
+func UnmarshalTxInputOfChain(nativeAsset NativeAsset, data []byte) (TxInput, error) {
+	txInput, driver, err := unmarshalTxInput(data)
 	if err != nil {
 		return nil, err
 	}
-	switch env.Type {
+	if driverFamily(driver) != driverFamily(nativeAsset.Driver()) {
+		return nil, fmt.Errorf("TxInput of %s is not an input of %s", driver, nativeAsset)
+	}
+	return txInput, nil
+}
+
+// driverFamily returns the driver of the inputs of a driver, e.g. evm for evm-legacy
+func driverFamily(driver Driver) Driver {
+	switch driver {
+	case DriverEVMLegacy:
+		return DriverEVM
+	case DriverCosmosEvmos:
+		return DriverCosmos
+	}
+	return driver
+}
+
+func newTxInput(driver Driver) (TxInput, error) {
+	switch driver {
 	case DriverAptos:
-		var txInput aptos.TxInput
-		err := json.Unmarshal(buf, &txInput)
-		return &txInput, err
+		return &aptos.TxInput{}, nil
+	case DriverSubstrate:
+		return &substrate.TxInput{}, nil
+	case DriverStarknet:
+		return &starknet.TxInput{}, nil
+	case DriverTron:
+		return &tron.TxInput{}, nil
+	case DriverAvalanche:
+		return &avalanche.TxInput{}, nil
+	case DriverNear:
+		return &near.TxInput{}, nil
+	case DriverStellar:
+		return &stellar.TxInput{}, nil
+	case DriverAlgorand:
+		return &algorand.TxInput{}, nil
 	case DriverCosmos, DriverCosmosEvmos:
-		var txInput cosmos.TxInput
-		err := json.Unmarshal(buf, &txInput)
-		return &txInput, err
+		return &cosmos.TxInput{}, nil
 	case DriverEVM, DriverEVMLegacy:
-		var txInput evm.TxInput
-		err := json.Unmarshal(buf, &txInput)
-		return &txInput, err
+		return &evm.TxInput{}, nil
 	case DriverSolana:
-		var txInput solana.TxInput
-		err := json.Unmarshal(buf, &txInput)
-		return &txInput, err
+		return &solana.TxInput{}, nil
 	case DriverBitcoin:
-		var txInput bitcoin.TxInput
-		err := json.Unmarshal(buf, &txInput)
-		return &txInput, err
+		return &bitcoin.TxInput{}, nil
 	case DriverSui:
-		var txInput sui.TxInput
-		err := json.Unmarshal(buf, &txInput)
-		return &txInput, err
-	default:
-		return nil, fmt.Errorf("invalid TxInput type: %s", env.Type)
+		return &sui.TxInput{}, nil
+	}
+	if constructors, ok := LookupDriver(driver); ok && constructors.NewTxInput != nil {
+		return constructors.NewTxInput(), nil
 	}
+	return nil, fmt.Errorf("invalid TxInput type: %s", driver)
 }
 
 func getAddressFromPublicKey(cfg ITask, publicKey []byte) (Address, error) {
@@ -819,6 +1270,8 @@ func NormalizeAddressString(address string, nativeAsset string) string {
 	case APTOS, SUI:
 		return NormalizeMoveAddress(address)
 	default:
+		// registered chains
+		return NormalizeAddressStringByDriver(address, NativeAsset(nativeAsset).Driver())
 	}
 	return address
 }
@@ -842,6 +1295,11 @@ func NormalizeAddressStringByDriver(address string, driver Driver) string {
 		}
 	case DriverAptos, DriverSui:
 		return NormalizeMoveAddress(address)
+	case DriverStarknet:
+		// felts padded to 64 hex digits
+		if felt, err := starknet.ParseFelt(address); err == nil {
+			return string(starknet.FormatAddress(felt))
+		}
 
 	default:
 	}
@@ -858,6 +1316,20 @@ func CheckError(driver Driver, err error) ClientError {
 		return solana.CheckError(err)
 	case DriverAptos:
 		return aptos.CheckError(err)
+	case DriverSubstrate:
+		return substrate.CheckError(err)
+	case DriverStarknet:
+		return starknet.CheckError(err)
+	case DriverTron:
+		return tron.CheckError(err)
+	case DriverAvalanche:
+		return avalanche.CheckError(err)
+	case DriverNear:
+		return near.CheckError(err)
+	case DriverStellar:
+		return stellar.CheckError(err)
+	case DriverAlgorand:
+		return algorand.CheckError(err)
 	case DriverBitcoin:
 		return bitcoin.CheckError(err)
 	}
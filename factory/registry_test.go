@@ -0,0 +1,54 @@
+package factory
+
+import (
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/evm"
+)
+
+func (s *CrosschainTestSuite) TestRegisteredDriver() {
+	require := s.Require()
+	// a private chain, with a driver built outside of crosschain
+	driver := xc.Driver("xctest-private")
+	require.NoError(xc.RegisterDriver(driver, xc.DriverConstructors{
+		SignatureAlgorithm: xc.K256,
+		NewClient: func(asset xc.ITask) (xc.Client, error) {
+			return evm.NewClient(asset)
+		},
+		NewAddressBuilder: func(asset xc.ITask) (xc.AddressBuilder, error) {
+			return evm.NewAddressBuilder(asset)
+		},
+	}))
+	require.NoError(xc.RegisterChain(xc.ChainInfo{NativeAsset: "PRIV", Driver: driver, Decimals: 12}))
+
+	cfg, err := ReadConfig(strings.NewReader(`
+chains:
+- asset: PRIV
+  url: 'http://localhost:8545'
+`))
+	require.NoError(err)
+	asset, err := cfg.GetAsset("PRIV")
+	require.NoError(err)
+	require.Equal(string(driver), asset.GetDriver())
+	require.EqualValues(12, asset.GetAssetConfig().Decimals)
+
+	f := NewFactoryFromConfig(cfg)
+	client, err := f.NewClient(asset)
+	require.NoError(err)
+	require.IsType(&evm.Client{}, client)
+	_, err = f.NewAddressBuilder(asset)
+	require.NoError(err)
+
+	// constructors that aren't registered
+	_, err = f.NewTxBuilder(asset)
+	require.EqualError(err, "unsupported asset")
+	_, err = f.NewSigner(asset)
+	require.EqualError(err, "unsupported asset")
+}
+
+func (s *CrosschainTestSuite) TestNormalizeAddressStringRegisteredChain() {
+	require := s.Require()
+	require.NoError(xc.RegisterChain(xc.ChainInfo{NativeAsset: "XCTESTEVM", Driver: xc.DriverEVM}))
+	require.Equal("0x0ec9f48533bb2a03f53f341ef5cc1b057892b10b", NormalizeAddressString("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B", "XCTESTEVM"))
+}
@@ -0,0 +1,105 @@
+package crosschain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint returns a stable hash of the definition of an asset or task, to verify that services use identical definitions
+// The definition is made of the fields set in config: internal fields such as AuthSecret are excluded,
+// and fields not set are omitted so that a new optional field doesn't change the fingerprints of configs not using it
+func Fingerprint(cfg interface{}) string {
+	return fingerprint(canonical(reflect.ValueOf(cfg)))
+}
+
+// ConfigFingerprint returns the fingerprint of a set of assets, tasks and pipelines, independent of their order
+func ConfigFingerprint(assets []ITask, tasks []*TaskConfig, pipelines []*PipelineConfig) string {
+	definitions := map[string]map[string]interface{}{
+		"assets":    {},
+		"tasks":     {},
+		"pipelines": {},
+	}
+	for _, asset := range assets {
+		if asset == nil {
+			continue
+		}
+		definitions["assets"][string(asset.ID())] = canonical(reflect.ValueOf(asset))
+	}
+	for _, task := range tasks {
+		definitions["tasks"][string(task.ID())] = canonical(reflect.ValueOf(task))
+	}
+	for _, pipeline := range pipelines {
+		definitions["pipelines"][pipeline.ID] = canonical(reflect.ValueOf(pipeline))
+	}
+	return fingerprint(definitions)
+}
+
+// Fingerprint returns the fingerprint of the chains, tokens, tasks and pipelines of a Config, see ConfigFingerprint
+func (c *Config) Fingerprint() string {
+	assets := []ITask{}
+	for _, chain := range c.Chains {
+		assets = append(assets, chain)
+	}
+	for _, token := range c.Tokens {
+		assets = append(assets, token)
+	}
+	return ConfigFingerprint(assets, c.AllTasks, c.AllPipelines)
+}
+
+func fingerprint(definition interface{}) string {
+	// maps are marshalled with sorted keys
+	data, _ := json.Marshal(definition)
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// canonical returns the fields set of a config by their yaml names, as json values
+func canonical(value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return canonical(value.Elem())
+	case reflect.Struct:
+		fields := map[string]interface{}{}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "-" || !field.IsExported() || value.Field(i).IsZero() {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			fields[name] = canonical(value.Field(i))
+		}
+		return fields
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = canonical(value.Index(i))
+		}
+		return items
+	case reflect.Map:
+		// e.g. map[interface{}]interface{} of yaml
+		entries := map[string]interface{}{}
+		iter := value.MapRange()
+		for iter.Next() {
+			entries[fmt.Sprint(iter.Key().Interface())] = canonical(iter.Value())
+		}
+		return entries
+	case reflect.Float32, reflect.Float64:
+		// also NaN and infinities
+		return strconv.FormatFloat(value.Float(), 'g', -1, 64)
+	case reflect.Invalid, reflect.Func, reflect.Chan:
+		return nil
+	default:
+		return value.Interface()
+	}
+}
@@ -0,0 +1,63 @@
+package crosschain
+
+func (s *CrosschainTestSuite) TestFingerprint() {
+	require := s.Require()
+	eth := &NativeAssetConfig{Asset: "ETH", Driver: "evm", Net: "mainnet", URL: "https://eth.example.com", ChainID: 1, Decimals: 18}
+	fingerprint := Fingerprint(eth)
+	require.Len(fingerprint, 64)
+
+	// secrets and internal fields are excluded
+	withSecret := *eth
+	withSecret.AuthSecret = "secret"
+	withSecret.NativeAsset = ETH
+	withSecret.Type = AssetTypeNative
+	require.Equal(fingerprint, Fingerprint(&withSecret))
+
+	// definitions differ
+	other := *eth
+	other.Decimals = 6
+	require.NotEqual(fingerprint, Fingerprint(&other))
+	other = *eth
+	other.ChainGasMultiplier = 1.5
+	require.NotEqual(fingerprint, Fingerprint(&other))
+	other = *eth
+	other.Endpoints = []Endpoint{{URL: "https://backup.example.com"}}
+	require.NotEqual(fingerprint, Fingerprint(&other))
+
+	token := &TokenAssetConfig{Asset: "USDC", Chain: "ETH", Net: "mainnet", Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6}
+	tokenFingerprint := Fingerprint(token)
+	// filled when loaded
+	token.AssetConfig = *eth
+	token.NativeAssetConfig = eth
+	require.Equal(tokenFingerprint, Fingerprint(token))
+	require.NotEqual(fingerprint, tokenFingerprint)
+
+	require.Equal(Fingerprint(nil), Fingerprint(nil))
+}
+
+func (s *CrosschainTestSuite) TestConfigFingerprint() {
+	require := s.Require()
+	eth := &NativeAssetConfig{Asset: "ETH", Driver: "evm", Net: "mainnet", Decimals: 18}
+	sol := &NativeAssetConfig{Asset: "SOL", Driver: "solana", Net: "mainnet", Decimals: 9}
+	usdc := &TokenAssetConfig{Asset: "USDC", Chain: "ETH", Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6}
+	task := &TaskConfig{Name: "wormhole-transfer", Code: "WormholeTransferTx", Allow: []string{"ETH -> SOL"}, DefaultParams: map[string]interface{}{"arbiter_fee_usd": 2}}
+	pipeline := &PipelineConfig{ID: "wormhole", Tasks: []string{"wormhole-transfer"}}
+
+	config := &Config{
+		Chains:       []*NativeAssetConfig{eth, sol},
+		Tokens:       []*TokenAssetConfig{usdc},
+		AllTasks:     []*TaskConfig{task},
+		AllPipelines: []*PipelineConfig{pipeline},
+	}
+	fingerprint := config.Fingerprint()
+	require.Equal(fingerprint, ConfigFingerprint([]ITask{usdc, sol, eth}, []*TaskConfig{task}, []*PipelineConfig{pipeline}))
+
+	// yaml maps
+	task.DefaultParams = map[string]interface{}{"arbiter_fee_usd": 3, "nested": map[interface{}]interface{}{"key": "value"}}
+	require.NotEqual(fingerprint, config.Fingerprint())
+	task.DefaultParams = map[string]interface{}{"arbiter_fee_usd": 2}
+	require.Equal(fingerprint, config.Fingerprint())
+
+	config.Chains = []*NativeAssetConfig{eth}
+	require.NotEqual(fingerprint, config.Fingerprint())
+}
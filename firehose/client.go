@@ -0,0 +1,49 @@
+package firehose
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Client consumes the firehose of a Server
+type Client struct {
+	Conn *grpc.ClientConn
+}
+
+// NewClient creates a new Client
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{
+		Conn: conn,
+	}
+}
+
+// Stream receives transfers
+type Stream struct {
+	stream grpc.ClientStream
+}
+
+// Stream opens a stream of the transfers selected by req
+func (client *Client) Stream(ctx context.Context, req *StreamRequest) (*Stream, error) {
+	desc := &serviceDesc.Streams[0]
+	stream, err := client.Conn.NewStream(ctx, desc, "/"+ServiceName+"/"+desc.StreamName, grpc.CallContentSubtype(CodecName))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &Stream{stream: stream}, nil
+}
+
+// Recv receives the next transfer, use its Cursor to resume a stream
+func (stream *Stream) Recv() (*Transfer, error) {
+	transfer := &Transfer{}
+	if err := stream.stream.RecvMsg(transfer); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
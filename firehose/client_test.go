@@ -0,0 +1,57 @@
+package firehose
+
+import (
+	"context"
+	"net"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func (s *CrosschainTestSuite) TestClientStream() {
+	require := s.Require()
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	server := NewServer(map[xc.NativeAsset]BlockSource{xc.ETH: &fakeSource{latest: 12}})
+	server.PollInterval = time.Millisecond
+	server.Register(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.DialContext(s.Ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	require.NoError(err)
+	defer conn.Close()
+	client := NewClient(conn)
+
+	ctx, cancel := context.WithCancel(s.Ctx)
+	defer cancel()
+	stream, err := client.Stream(ctx, &StreamRequest{Cursor: "ETH=10.0"})
+	require.NoError(err)
+	transfer, err := stream.Recv()
+	require.NoError(err)
+	require.Equal(&Transfer{Cursor: "ETH=10.1", Chain: xc.ETH, BlockIndex: 10, TxHash: "tx10", To: "token", ContractAddress: "usdc", Amount: "1"}, transfer)
+	transfer, err = stream.Recv()
+	require.NoError(err)
+	require.Equal(xc.Address("to11"), transfer.To)
+	cancel()
+
+	// resume from the cursor of the last transfer received
+	stream, err = client.Stream(s.Ctx, &StreamRequest{Cursor: transfer.Cursor})
+	require.NoError(err)
+	transfer, err = stream.Recv()
+	require.NoError(err)
+	require.Equal(xc.Address("to12"), transfer.To)
+
+	// errors are returned by Recv
+	stream, err = client.Stream(s.Ctx, &StreamRequest{Chains: []xc.NativeAsset{xc.BTC}})
+	require.NoError(err)
+	_, err = stream.Recv()
+	require.ErrorContains(err, "unsupported chain: BTC")
+}
@@ -0,0 +1,117 @@
+package firehose
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// BlockSource fetches the parsed txs of a chain block by block
+// Clients of chains supporting the firehose implement it
+type BlockSource interface {
+	FetchLatestBlock(ctx context.Context) (int64, error)
+	FetchBlockTxInfos(ctx context.Context, height int64) ([]xc.TxInfo, error)
+}
+
+// StreamRequest selects the transfers of a stream
+type StreamRequest struct {
+	// Chains to stream, all chains served if empty
+	Chains []xc.NativeAsset `json:"chains,omitempty"`
+	// Contracts of tokens to stream, all assets if empty
+	Contracts []xc.ContractAddress `json:"contracts,omitempty"`
+	// Cursor of the last transfer received, streaming starts from the tip of each chain if empty
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// Transfer is a normalized transfer of any chain
+type Transfer struct {
+	// Cursor to resume the stream after this transfer
+	Cursor          string             `json:"cursor"`
+	Chain           xc.NativeAsset     `json:"chain"`
+	BlockIndex      int64              `json:"block_index"`
+	BlockTime       int64              `json:"block_time"`
+	TxHash          string             `json:"tx_hash"`
+	From            xc.Address         `json:"from"`
+	To              xc.Address         `json:"to"`
+	ContractAddress xc.ContractAddress `json:"contract_address,omitempty"`
+	Amount          string             `json:"amount"`
+	Status          xc.TxStatus        `json:"status"`
+}
+
+// Position is the position of a transfer in a chain: its block and ordinal in the block
+type Position struct {
+	Height  int64
+	Ordinal int
+}
+
+// Cursor is the position of the last transfer streamed per chain
+type Cursor map[xc.NativeAsset]Position
+
+// ParseCursor parses a cursor formatted as CHAIN=height.ordinal,...
+func ParseCursor(cursor string) (Cursor, error) {
+	positions := Cursor{}
+	if cursor == "" {
+		return positions, nil
+	}
+	for _, part := range strings.Split(cursor, ",") {
+		chain, position, ok := strings.Cut(part, "=")
+		height, ordinal, ok2 := strings.Cut(position, ".")
+		if !ok || !ok2 || chain == "" {
+			return nil, fmt.Errorf("invalid cursor: %s", cursor)
+		}
+		h, err := strconv.ParseInt(height, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor height: %s", part)
+		}
+		o, err := strconv.Atoi(ordinal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor ordinal: %s", part)
+		}
+		positions[xc.NativeAsset(chain)] = Position{Height: h, Ordinal: o}
+	}
+	return positions, nil
+}
+
+// String formats the cursor, chains are sorted for a stable format
+func (cursor Cursor) String() string {
+	chains := make([]string, 0, len(cursor))
+	for chain := range cursor {
+		chains = append(chains, string(chain))
+	}
+	sort.Strings(chains)
+	parts := make([]string, len(chains))
+	for i, chain := range chains {
+		position := cursor[xc.NativeAsset(chain)]
+		parts[i] = fmt.Sprintf("%s=%d.%d", chain, position.Height, position.Ordinal)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Transfers returns the transfers of a tx, one per destination
+func Transfers(chain xc.NativeAsset, info xc.TxInfo) []*Transfer {
+	transfers := []*Transfer{}
+	newTransfer := func(to xc.Address, contract xc.ContractAddress, amount xc.AmountBlockchain) *Transfer {
+		return &Transfer{
+			Chain:           chain,
+			BlockIndex:      info.BlockIndex,
+			BlockTime:       info.BlockTime,
+			TxHash:          info.TxID,
+			From:            info.From,
+			To:              to,
+			ContractAddress: contract,
+			Amount:          amount.String(),
+			Status:          info.Status,
+		}
+	}
+	for _, destination := range info.Destinations {
+		transfers = append(transfers, newTransfer(destination.Address, destination.ContractAddress, destination.Amount))
+	}
+	if len(info.Destinations) == 0 && info.To != "" {
+		transfers = append(transfers, newTransfer(info.To, info.ContractAddress, info.Amount))
+	}
+	return transfers
+}
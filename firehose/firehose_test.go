@@ -0,0 +1,62 @@
+package firehose
+
+import (
+	"context"
+	"testing"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestFirehoseTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func (s *CrosschainTestSuite) TestCursor() {
+	require := s.Require()
+	cursor, err := ParseCursor("")
+	require.NoError(err)
+	require.Empty(cursor)
+
+	cursor, err = ParseCursor("SOL=100.3,ETH=20.-1")
+	require.NoError(err)
+	require.Equal(Cursor{xc.SOL: {Height: 100, Ordinal: 3}, xc.ETH: {Height: 20, Ordinal: -1}}, cursor)
+	require.Equal("ETH=20.-1,SOL=100.3", cursor.String())
+
+	for _, invalid := range []string{"SOL", "SOL=100", "=1.2", "SOL=a.1", "SOL=1.b"} {
+		_, err = ParseCursor(invalid)
+		require.Error(err, invalid)
+	}
+}
+
+func (s *CrosschainTestSuite) TestTransfers() {
+	require := s.Require()
+	info := xc.TxInfo{
+		TxID:       "hash",
+		From:       "from",
+		BlockIndex: 10,
+		Destinations: []*xc.TxInfoEndpoint{
+			{Address: "to1", Amount: xc.NewAmountBlockchainFromUint64(1)},
+			{Address: "to2", ContractAddress: "usdc", Amount: xc.NewAmountBlockchainFromUint64(2)},
+		},
+	}
+	transfers := Transfers(xc.ETH, info)
+	require.Len(transfers, 2)
+	require.Equal(&Transfer{Chain: xc.ETH, BlockIndex: 10, TxHash: "hash", From: "from", To: "to1", Amount: "1"}, transfers[0])
+	require.Equal(xc.ContractAddress("usdc"), transfers[1].ContractAddress)
+
+	// txs without destinations
+	transfers = Transfers(xc.ETH, xc.TxInfo{TxID: "hash", To: "to", Amount: xc.NewAmountBlockchainFromUint64(3)})
+	require.Len(transfers, 1)
+	require.Equal("3", transfers[0].Amount)
+	require.Empty(Transfers(xc.ETH, xc.TxInfo{TxID: "hash"}))
+}
@@ -0,0 +1,155 @@
+package firehose
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"google.golang.org/grpc"
+)
+
+// ServiceName is the gRPC service of the firehose
+const ServiceName = "crosschain.Firehose"
+
+// DefaultPollInterval is the interval between polls of the tip of each chain
+const DefaultPollInterval = 2 * time.Second
+
+// Server streams the transfers of the configured chains
+type Server struct {
+	Sources      map[xc.NativeAsset]BlockSource
+	PollInterval time.Duration
+}
+
+// NewServer creates a new Server
+func NewServer(sources map[xc.NativeAsset]BlockSource) *Server {
+	return &Server{
+		Sources:      sources,
+		PollInterval: DefaultPollInterval,
+	}
+}
+
+// TransferSender sends streamed transfers, e.g. a gRPC server stream
+type TransferSender interface {
+	Context() context.Context
+	Send(*Transfer) error
+}
+
+type grpcTransferSender struct {
+	grpc.ServerStream
+}
+
+func (sender *grpcTransferSender) Send(transfer *Transfer) error {
+	return sender.ServerStream.SendMsg(transfer)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Stream",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			req := &StreamRequest{}
+			if err := stream.RecvMsg(req); err != nil {
+				return err
+			}
+			return srv.(*Server).Stream(req, &grpcTransferSender{stream})
+		},
+	}},
+	Metadata: "firehose",
+}
+
+// Register registers the firehose service on a gRPC server
+func (server *Server) Register(grpcServer *grpc.Server) {
+	grpcServer.RegisterService(&serviceDesc, server)
+}
+
+// Stream sends the transfers selected by req until the stream context is done
+func (server *Server) Stream(req *StreamRequest, sender TransferSender) error {
+	ctx := sender.Context()
+	cursor, err := ParseCursor(req.Cursor)
+	if err != nil {
+		return err
+	}
+	chains := req.Chains
+	if len(chains) == 0 {
+		for chain := range server.Sources {
+			chains = append(chains, chain)
+		}
+	}
+	for _, chain := range chains {
+		if _, ok := server.Sources[chain]; !ok {
+			return fmt.Errorf("unsupported chain: %s", chain)
+		}
+	}
+	contracts := map[xc.ContractAddress]bool{}
+	for _, contract := range req.Contracts {
+		contracts[contract] = true
+	}
+
+	interval := server.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, chain := range chains {
+			err := server.streamChain(ctx, chain, cursor, contracts, sender)
+			if err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// streamChain sends the transfers of chain from its cursor position to its tip
+func (server *Server) streamChain(ctx context.Context, chain xc.NativeAsset, cursor Cursor, contracts map[xc.ContractAddress]bool, sender TransferSender) error {
+	source := server.Sources[chain]
+	latest, err := source.FetchLatestBlock(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch latest %s block: %v", chain, err)
+	}
+	position, ok := cursor[chain]
+	if !ok {
+		// start from the tip
+		cursor[chain] = Position{Height: latest, Ordinal: -1}
+		position = cursor[chain]
+	}
+	for height := position.Height; height <= latest; height++ {
+		infos, err := source.FetchBlockTxInfos(ctx, height)
+		if err != nil {
+			return fmt.Errorf("could not fetch %s block %d: %v", chain, height, err)
+		}
+		ordinal := 0
+		for _, info := range infos {
+			for _, transfer := range Transfers(chain, info) {
+				if height == position.Height && ordinal <= position.Ordinal {
+					ordinal++
+					continue
+				}
+				cursor[chain] = Position{Height: height, Ordinal: ordinal}
+				ordinal++
+				if len(contracts) > 0 && !contracts[transfer.ContractAddress] {
+					continue
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				transfer.Cursor = cursor.String()
+				if err := sender.Send(transfer); err != nil {
+					return err
+				}
+			}
+		}
+		// resume after the last transfer of a complete block
+		cursor[chain] = Position{Height: height, Ordinal: ordinal - 1}
+	}
+	return nil
+}
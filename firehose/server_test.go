@@ -0,0 +1,141 @@
+package firehose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// fakeSource has one tx per block, with a transfer to "to<height>"
+// and a token transfer on even blocks
+type fakeSource struct {
+	mu     sync.Mutex
+	latest int64
+}
+
+func (source *fakeSource) FetchLatestBlock(ctx context.Context) (int64, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	return source.latest, nil
+}
+
+func (source *fakeSource) setLatest(latest int64) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	source.latest = latest
+}
+
+func (source *fakeSource) FetchBlockTxInfos(ctx context.Context, height int64) ([]xc.TxInfo, error) {
+	destinations := []*xc.TxInfoEndpoint{{Address: xc.Address(fmt.Sprintf("to%d", height)), Amount: xc.NewAmountBlockchainFromUint64(uint64(height))}}
+	if height%2 == 0 {
+		destinations = append(destinations, &xc.TxInfoEndpoint{Address: "token", ContractAddress: "usdc", Amount: xc.NewAmountBlockchainFromUint64(1)})
+	}
+	return []xc.TxInfo{{TxID: fmt.Sprintf("tx%d", height), BlockIndex: height, Destinations: destinations}}, nil
+}
+
+type fakeSender struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	max       int
+	transfers []*Transfer
+}
+
+func newFakeSender(ctx context.Context, max int) *fakeSender {
+	ctx, cancel := context.WithCancel(ctx)
+	return &fakeSender{ctx: ctx, cancel: cancel, max: max}
+}
+
+func (sender *fakeSender) Context() context.Context {
+	return sender.ctx
+}
+
+func (sender *fakeSender) Send(transfer *Transfer) error {
+	sender.transfers = append(sender.transfers, transfer)
+	if len(sender.transfers) >= sender.max {
+		sender.cancel()
+	}
+	return nil
+}
+
+func (sender *fakeSender) recipients() []xc.Address {
+	recipients := []xc.Address{}
+	for _, transfer := range sender.transfers {
+		recipients = append(recipients, transfer.To)
+	}
+	return recipients
+}
+
+func (s *CrosschainTestSuite) TestStreamFromTip() {
+	require := s.Require()
+	source := &fakeSource{latest: 10}
+	server := NewServer(map[xc.NativeAsset]BlockSource{xc.ETH: source})
+	server.PollInterval = time.Millisecond
+
+	sender := newFakeSender(s.Ctx, 3)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		source.setLatest(11)
+	}()
+	err := server.Stream(&StreamRequest{}, sender)
+	require.ErrorIs(err, context.Canceled)
+	require.Equal([]xc.Address{"to10", "token", "to11"}, sender.recipients())
+	require.Equal("ETH=10.1", sender.transfers[1].Cursor)
+	require.Equal("ETH=11.0", sender.transfers[2].Cursor)
+}
+
+func (s *CrosschainTestSuite) TestStreamResume() {
+	require := s.Require()
+	source := &fakeSource{latest: 12}
+	server := NewServer(map[xc.NativeAsset]BlockSource{xc.ETH: source})
+	server.PollInterval = time.Millisecond
+
+	// resume in the middle of block 10
+	sender := newFakeSender(s.Ctx, 3)
+	err := server.Stream(&StreamRequest{Cursor: "ETH=10.0"}, sender)
+	require.ErrorIs(err, context.Canceled)
+	require.Equal([]xc.Address{"token", "to11", "to12"}, sender.recipients())
+
+	// resume after a complete block
+	sender = newFakeSender(s.Ctx, 1)
+	err = server.Stream(&StreamRequest{Cursor: "ETH=11.0"}, sender)
+	require.ErrorIs(err, context.Canceled)
+	require.Equal([]xc.Address{"to12"}, sender.recipients())
+}
+
+func (s *CrosschainTestSuite) TestStreamFilter() {
+	require := s.Require()
+	source := &fakeSource{latest: 14}
+	server := NewServer(map[xc.NativeAsset]BlockSource{xc.ETH: source, xc.SOL: &fakeSource{latest: 1}})
+	server.PollInterval = time.Millisecond
+
+	sender := newFakeSender(s.Ctx, 2)
+	err := server.Stream(&StreamRequest{Chains: []xc.NativeAsset{xc.ETH}, Contracts: []xc.ContractAddress{"usdc"}, Cursor: "ETH=10.1"}, sender)
+	require.ErrorIs(err, context.Canceled)
+	require.Equal([]xc.Address{"token", "token"}, sender.recipients())
+	require.Equal("ETH=12.1", sender.transfers[0].Cursor)
+	require.Equal("ETH=14.1", sender.transfers[1].Cursor)
+
+	err = server.Stream(&StreamRequest{Chains: []xc.NativeAsset{xc.BTC}}, newFakeSender(s.Ctx, 1))
+	require.EqualError(err, "unsupported chain: BTC")
+	err = server.Stream(&StreamRequest{Cursor: "invalid"}, newFakeSender(s.Ctx, 1))
+	require.Error(err)
+}
+
+type failingSource struct {
+	fakeSource
+}
+
+func (source *failingSource) FetchBlockTxInfos(ctx context.Context, height int64) ([]xc.TxInfo, error) {
+	return nil, errors.New("rpc down")
+}
+
+func (s *CrosschainTestSuite) TestStreamSourceError() {
+	require := s.Require()
+	server := NewServer(map[xc.NativeAsset]BlockSource{xc.ETH: &failingSource{fakeSource{latest: 1}}})
+	err := server.Stream(&StreamRequest{}, newFakeSender(s.Ctx, 1))
+	require.EqualError(err, "could not fetch ETH block 1: rpc down")
+}
@@ -0,0 +1,244 @@
+package hdwallet
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	bip39 "github.com/cosmos/go-bip39"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/bitcoin"
+	"github.com/jumpcrypto/crosschain/factory"
+)
+
+// HardenedKeyStart is the offset of hardened indexes, written with a ' in derivation paths
+const HardenedKeyStart = hdkeychain.HardenedKeyStart
+
+// ParsePath parses a derivation path such as m/44'/60'/0'/0/0 into its indexes
+func ParsePath(path string) ([]uint32, error) {
+	steps := strings.Split(strings.TrimSpace(path), "/")
+	if len(steps) == 0 || steps[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path '%s'", path)
+	}
+	indexes := []uint32{}
+	for _, step := range steps[1:] {
+		offset := uint32(0)
+		if strings.HasSuffix(step, "'") || strings.HasSuffix(step, "h") {
+			step = step[:len(step)-1]
+			offset = HardenedKeyStart
+		}
+		index, err := strconv.ParseUint(step, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, fmt.Errorf("invalid derivation step '%s' of path '%s'", step, path)
+		}
+		indexes = append(indexes, uint32(index)+offset)
+	}
+	return indexes, nil
+}
+
+// FormatPath formats indexes as a derivation path, see ParsePath
+func FormatPath(indexes []uint32) string {
+	path := "m"
+	for _, index := range indexes {
+		if index >= HardenedKeyStart {
+			path += fmt.Sprintf("/%d'", index-HardenedKeyStart)
+		} else {
+			path += fmt.Sprintf("/%d", index)
+		}
+	}
+	return path
+}
+
+// SeedFromMnemonic returns the BIP-39 seed of a mnemonic, with an optional passphrase
+func SeedFromMnemonic(mnemonic string, passphrase string) ([]byte, error) {
+	return bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+}
+
+// Wallet derives the keys and addresses of a chain from a seed, e.g. deposit addresses
+// The key at index is derived at the derivation path of the chain, with its last index replaced by index,
+// e.g. m/44'/60'/0'/0/index for EVM chains and m/44'/501'/0'/index' for Solana
+// Addresses of an extended public key are derived by a watch-only account, see AccountExtendedPublicKey
+type Wallet struct {
+	Asset xc.ITask
+	// Path is the derivation path of the first key, see AssetConfig.GetDerivationPath
+	Path []uint32
+
+	seed           []byte
+	addressBuilder xc.AddressBuilder
+}
+
+// NewWallet creates a new Wallet of asset given a seed, see SeedFromMnemonic
+func NewWallet(f factory.FactoryContext, asset xc.ITask, seed []byte) (*Wallet, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length %d", len(seed))
+	}
+	native := asset.GetNativeAsset()
+	path, err := ParsePath(native.GetDerivationPath())
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("no derivation path of %s", asset.ID())
+	}
+	algorithm := signatureAlgorithm(asset)
+	switch algorithm {
+	case xc.K256:
+	case xc.Ed255:
+		for _, index := range path {
+			if index < HardenedKeyStart {
+				return nil, fmt.Errorf("ed25519 keys only support hardened derivation: '%s'", FormatPath(path))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("derivation is not supported for %s", asset.ID())
+	}
+	addressBuilder, err := f.NewAddressBuilder(asset)
+	if err != nil {
+		return nil, err
+	}
+	return &Wallet{
+		Asset:          asset,
+		Path:           path,
+		seed:           seed,
+		addressBuilder: addressBuilder,
+	}, nil
+}
+
+func signatureAlgorithm(asset xc.ITask) xc.SignatureType {
+	if driver := xc.Driver(asset.GetDriver()); driver != "" {
+		return driver.SignatureAlgorithm()
+	}
+	return asset.GetNativeAsset().NativeAsset.SignatureAlgorithm()
+}
+
+// PathAt returns the derivation path of the key at index
+func (wallet *Wallet) PathAt(index uint32) ([]uint32, error) {
+	if index >= HardenedKeyStart {
+		return nil, fmt.Errorf("invalid index %d", index)
+	}
+	path := append([]uint32{}, wallet.Path...)
+	last := len(path) - 1
+	if path[last] >= HardenedKeyStart {
+		index += HardenedKeyStart
+	}
+	path[last] = index
+	return path, nil
+}
+
+// DeriveKey derives the private and public keys at index, in the format of the signer and address builder of the chain
+func (wallet *Wallet) DeriveKey(index uint32) (xc.PrivateKey, xc.PublicKey, error) {
+	path, err := wallet.PathAt(index)
+	if err != nil {
+		return nil, nil, err
+	}
+	if signatureAlgorithm(wallet.Asset) == xc.Ed255 {
+		key := deriveEd25519(wallet.seed, path)
+		privateKey := ed25519.NewKeyFromSeed(key)
+		publicKey := privateKey.Public().(ed25519.PublicKey)
+		driver := xc.Driver(wallet.Asset.GetDriver())
+		if driver == xc.DriverSolana || driver == xc.DriverNear || wallet.Asset.GetNativeAsset().NativeAsset == xc.SOL {
+			// Solana and NEAR sign with the private key and public key, the other chains with the seed of the private key
+			return xc.PrivateKey(privateKey), xc.PublicKey(publicKey), nil
+		}
+		return xc.PrivateKey(key), xc.PublicKey(publicKey), nil
+	}
+
+	extendedKey, err := wallet.deriveExtendedKey(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey, err := extendedKey.ECPrivKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return xc.PrivateKey(privateKey.Serialize()), xc.PublicKey(privateKey.PubKey().SerializeCompressed()), nil
+}
+
+// DeriveAddress derives the address at index
+func (wallet *Wallet) DeriveAddress(index uint32) (xc.Address, error) {
+	_, publicKey, err := wallet.DeriveKey(index)
+	if err != nil {
+		return "", err
+	}
+	return wallet.addressBuilder.GetAddressFromPublicKey(publicKey)
+}
+
+// DeriveAddresses derives count addresses starting at index
+func (wallet *Wallet) DeriveAddresses(index uint32, count int) ([]xc.Address, error) {
+	addresses := []xc.Address{}
+	for i := 0; i < count; i++ {
+		address, err := wallet.DeriveAddress(index + uint32(i))
+		if err != nil {
+			return addresses, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// AccountExtendedPublicKey returns the extended public key of the account of the Wallet, e.g. at m/44'/60'/0',
+// to derive its addresses without the seed with watchonly.NewAccount
+// Only secp256k1 paths ending with non-hardened chain and index levels have an account-level extended public key
+func (wallet *Wallet) AccountExtendedPublicKey() (string, error) {
+	if signatureAlgorithm(wallet.Asset) != xc.K256 {
+		return "", fmt.Errorf("extended public keys are not supported for %s", wallet.Asset.ID())
+	}
+	last := len(wallet.Path)
+	if last < 2 || wallet.Path[last-1] >= HardenedKeyStart || wallet.Path[last-2] >= HardenedKeyStart {
+		return "", fmt.Errorf("no account-level extended public key of path '%s'", FormatPath(wallet.Path))
+	}
+	extendedKey, err := wallet.deriveExtendedKey(wallet.Path[:last-2])
+	if err != nil {
+		return "", err
+	}
+	publicKey, err := extendedKey.Neuter()
+	if err != nil {
+		return "", err
+	}
+	return publicKey.String(), nil
+}
+
+// deriveExtendedKey derives the BIP-32 key at path, versioned for the network of UTXO chains
+func (wallet *Wallet) deriveExtendedKey(path []uint32) (*hdkeychain.ExtendedKey, error) {
+	params := &chaincfg.MainNetParams
+	if xc.Driver(wallet.Asset.GetDriver()) == xc.DriverBitcoin {
+		var err error
+		if params, err = bitcoin.GetParams(wallet.Asset.GetNativeAsset()); err != nil {
+			return nil, err
+		}
+	}
+	key, err := hdkeychain.NewMaster(wallet.seed, params)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range path {
+		if key, err = key.Derive(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// deriveEd25519 derives the ed25519 private key seed at path, of hardened indexes only (SLIP-10)
+func deriveEd25519(seed []byte, path []uint32) []byte {
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+	for _, index := range path {
+		data := make([]byte, 1+32+4)
+		copy(data[1:], key)
+		binary.BigEndian.PutUint32(data[33:], index)
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+		key, chainCode = sum[:32], sum[32:]
+	}
+	return key
+}
@@ -0,0 +1,151 @@
+package hdwallet
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/jumpcrypto/crosschain/watchonly"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx     context.Context
+	Factory testutil.TestFactory
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+	s.Factory = testutil.NewDefaultFactoryWithConfig(map[string]interface{}{})
+}
+
+func TestHDWalletTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+const mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+var ethAsset = &xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, Driver: "evm"}
+
+func (s *CrosschainTestSuite) TestParsePath() {
+	require := s.Require()
+	path, err := ParsePath("m/44'/60'/0'/0/1")
+	require.NoError(err)
+	require.Equal([]uint32{44 + HardenedKeyStart, 60 + HardenedKeyStart, HardenedKeyStart, 0, 1}, path)
+	require.Equal("m/44'/60'/0'/0/1", FormatPath(path))
+	path, _ = ParsePath("m/44h/501h")
+	require.Equal("m/44'/501'", FormatPath(path))
+
+	_, err = ParsePath("44'/60'")
+	require.EqualError(err, "invalid derivation path '44'/60''")
+	_, err = ParsePath("m/44'/x")
+	require.EqualError(err, "invalid derivation step 'x' of path 'm/44'/x'")
+	_, err = ParsePath("m/2147483648")
+	require.ErrorContains(err, "invalid derivation step")
+}
+
+func (s *CrosschainTestSuite) TestDeriveAddress() {
+	require := s.Require()
+	seed, err := SeedFromMnemonic(mnemonic, "")
+	require.NoError(err)
+	vectors := []struct {
+		asset   xc.ITask
+		index   uint32
+		address xc.Address
+	}{
+		{&xc.AssetConfig{Asset: "BTC", NativeAsset: xc.BTC, Driver: "bitcoin", Net: "mainnet"}, 0, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+		{ethAsset, 0, "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"},
+		{ethAsset, 1, "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"},
+		{&xc.AssetConfig{Asset: "ATOM", NativeAsset: xc.ATOM, Driver: "cosmos", ChainPrefix: "cosmos"}, 0, "cosmos19rl4cm2hmr8afy4kldpxz3fka4jguq0auqdal4"},
+		{&xc.AssetConfig{Asset: "SOL", NativeAsset: xc.SOL, Driver: "solana"}, 0, "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk"},
+	}
+	for _, v := range vectors {
+		wallet, err := NewWallet(&s.Factory, v.asset, seed)
+		require.NoError(err)
+		address, err := wallet.DeriveAddress(v.index)
+		require.NoError(err)
+		require.Equal(v.address, address, v.asset.ID())
+	}
+
+	// overridden path
+	wallet, err := NewWallet(&s.Factory, &xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, Driver: "evm", DerivationPath: "m/44'/60'/0'/0/1"}, seed)
+	require.NoError(err)
+	address, _ := wallet.DeriveAddress(1)
+	require.Equal(xc.Address("0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"), address)
+	addresses, err := wallet.DeriveAddresses(0, 2)
+	require.NoError(err)
+	require.Equal([]xc.Address{"0x9858EfFD232B4033E47d90003D41EC34EcaEda94", "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"}, addresses)
+
+	_, err = NewWallet(&s.Factory, &xc.AssetConfig{Asset: "SOL", NativeAsset: xc.SOL, Driver: "solana", DerivationPath: "m/44'/501'/0'/0"}, seed)
+	require.EqualError(err, "ed25519 keys only support hardened derivation: 'm/44'/501'/0'/0'")
+	_, err = NewWallet(&s.Factory, ethAsset, seed[:8])
+	require.EqualError(err, "invalid seed length 8")
+	_, err = SeedFromMnemonic("abandon abandon", "")
+	require.Error(err)
+}
+
+func (s *CrosschainTestSuite) TestDeriveKey() {
+	require := s.Require()
+	seed, _ := SeedFromMnemonic(mnemonic, "")
+	wallet, _ := NewWallet(&s.Factory, ethAsset, seed)
+	privateKey, publicKey, err := wallet.DeriveKey(0)
+	require.NoError(err)
+	require.Equal("1ab42cc412b618bdea3a599e3c9bae199ebf030895b039e9db1e30dafb12b727", hex.EncodeToString(privateKey))
+	require.Len(publicKey, 33)
+	path, _ := wallet.PathAt(7)
+	require.Equal("m/44'/60'/0'/0/7", FormatPath(path))
+	_, _, err = wallet.DeriveKey(HardenedKeyStart)
+	require.EqualError(err, "invalid index 2147483648")
+
+	// SLIP-10 test vector 1
+	slip10Seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.Equal("2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7", hex.EncodeToString(deriveEd25519(slip10Seed, nil)))
+	require.Equal("68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3", hex.EncodeToString(deriveEd25519(slip10Seed, []uint32{HardenedKeyStart})))
+
+	// solana signs with the private key and public key
+	wallet, _ = NewWallet(&s.Factory, &xc.AssetConfig{Asset: "SOL", NativeAsset: xc.SOL, Driver: "solana"}, seed)
+	privateKey, publicKey, _ = wallet.DeriveKey(0)
+	require.Len(privateKey, 64)
+	require.Equal([]byte(publicKey), []byte(privateKey[32:]))
+	path, _ = wallet.PathAt(3)
+	require.Equal("m/44'/501'/0'/3'", FormatPath(path))
+	wallet, _ = NewWallet(&s.Factory, &xc.AssetConfig{Asset: "APTOS", NativeAsset: xc.APTOS, Driver: "aptos"}, seed)
+	privateKey, _, _ = wallet.DeriveKey(0)
+	require.Len(privateKey, 32)
+
+	// so does near, its implicit accounts are the hex of the public key
+	wallet, _ = NewWallet(&s.Factory, &xc.AssetConfig{Asset: "NEAR", NativeAsset: xc.NEAR, Driver: "near"}, seed)
+	privateKey, publicKey, _ = wallet.DeriveKey(0)
+	require.Len(privateKey, 64)
+	require.Equal([]byte(publicKey), []byte(privateKey[32:]))
+	path, _ = wallet.PathAt(1)
+	require.Equal("m/44'/397'/1'", FormatPath(path))
+	address, _ := wallet.DeriveAddress(0)
+	require.Equal(xc.Address(hex.EncodeToString(publicKey)), address)
+}
+
+func (s *CrosschainTestSuite) TestAccountExtendedPublicKey() {
+	require := s.Require()
+	seed, _ := SeedFromMnemonic(mnemonic, "")
+	wallet, _ := NewWallet(&s.Factory, ethAsset, seed)
+	xpub, err := wallet.AccountExtendedPublicKey()
+	require.NoError(err)
+	require.Equal("xpub6DCoCpSuQZB2jawqnGMEPS63ePKWkwWPH4TU45Q7LPXWuNd8TMtVxRrgjtEshuqpK3mdhaWHPFsBngh5GFZaM6si3yZdUsT8ddYM3PwnATt", xpub)
+
+	// the watch-only account derives the same addresses
+	account, err := watchonly.NewAccount(&s.Factory, ethAsset, xpub)
+	require.NoError(err)
+	for index := uint32(0); index < 3; index++ {
+		expected, _ := wallet.DeriveAddress(index)
+		address, err := account.DeriveAddress(watchonly.ExternalChain, index)
+		require.NoError(err)
+		require.Equal(expected, address)
+	}
+
+	wallet, _ = NewWallet(&s.Factory, &xc.AssetConfig{Asset: "SOL", NativeAsset: xc.SOL, Driver: "solana"}, seed)
+	_, err = wallet.AccountExtendedPublicKey()
+	require.EqualError(err, "extended public keys are not supported for SOL")
+}
@@ -0,0 +1,146 @@
+package crosschain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ChainStatus is whether txs can be broadcast to a chain
+type ChainStatus string
+
+// List of ChainStatus
+const (
+	ChainStatusHealthy ChainStatus = "healthy"
+	// ChainStatusHalted is returned when no block was produced for DefaultHaltBlocks block times, e.g. during a Cosmos upgrade
+	ChainStatusHalted ChainStatus = "halted"
+	// ChainStatusMaintenance is returned during a maintenance of the provider announced at maintenance_url
+	ChainStatusMaintenance ChainStatus = "maintenance"
+)
+
+// DefaultHaltBlocks is the number of expected block times without a new block after which a chain is considered halted
+const DefaultHaltBlocks = 10
+
+// MinHaltThreshold is the minimum age of the latest block of a halted chain, for chains of sub-second blocks
+const MinHaltThreshold = time.Minute
+
+// ChainHealth is the status of a chain, so orchestrators can pause broadcasting instead of accumulating failures
+type ChainHealth struct {
+	Chain  NativeAsset `json:"chain"`
+	Status ChainStatus `json:"status"`
+	Height uint64      `json:"height"`
+	// LatestBlockTime is the time of the block at Height
+	LatestBlockTime time.Time `json:"latest_block_time"`
+	// HaltThreshold is the age of the latest block after which the chain is considered halted
+	HaltThreshold time.Duration `json:"halt_threshold"`
+	// Details are human readable, e.g. the name of the maintenance
+	Details string `json:"details,omitempty"`
+}
+
+// ClientChainHealth is a Client that can tell if its chain is halted
+type ClientChainHealth interface {
+	FetchChainHealth(ctx context.Context) (*ChainHealth, error)
+}
+
+// Healthy returns true if txs can be broadcast
+func (health *ChainHealth) Healthy() bool {
+	return health.Status == ChainStatusHealthy
+}
+
+// HaltThreshold returns the age of the latest block after which the chain is considered halted:
+// halt_blocks (or DefaultHaltBlocks) times block_time, or averageBlockTime if block_time isn't configured
+// Zero if neither is known
+func (asset *NativeAssetConfig) HaltThreshold(averageBlockTime time.Duration) time.Duration {
+	blockTime := asset.BlockTime
+	if blockTime <= 0 {
+		blockTime = averageBlockTime
+	}
+	if blockTime <= 0 {
+		return 0
+	}
+	haltBlocks := asset.HaltBlocks
+	if haltBlocks <= 0 {
+		haltBlocks = DefaultHaltBlocks
+	}
+	threshold := blockTime * time.Duration(haltBlocks)
+	if threshold < MinHaltThreshold {
+		threshold = MinHaltThreshold
+	}
+	return threshold
+}
+
+// CheckChainHealth returns the health of the chain of asset given its latest block, measured at now
+// and the maintenance announced at the maintenance_url of asset, if any
+func CheckChainHealth(ctx context.Context, asset *NativeAssetConfig, height uint64, latestBlockTime time.Time, averageBlockTime time.Duration, now time.Time) (*ChainHealth, error) {
+	health := &ChainHealth{
+		Chain:           asset.NativeAsset,
+		Status:          ChainStatusHealthy,
+		Height:          height,
+		LatestBlockTime: latestBlockTime,
+		HaltThreshold:   asset.HaltThreshold(averageBlockTime),
+	}
+	if asset.MaintenanceURL != "" {
+		maintenances, err := FetchMaintenances(ctx, asset.MaintenanceURL)
+		if err != nil {
+			return nil, err
+		}
+		if len(maintenances) > 0 {
+			health.Status = ChainStatusMaintenance
+			health.Details = maintenances[0].Name
+			return health, nil
+		}
+	}
+	age := now.Sub(latestBlockTime)
+	if health.HaltThreshold > 0 && age > health.HaltThreshold {
+		health.Status = ChainStatusHalted
+		health.Details = fmt.Sprintf("latest block %d is %s old", height, age.Round(time.Second))
+	}
+	return health, nil
+}
+
+// Maintenance is a scheduled maintenance of a provider, in the format of Statuspage status pages
+type Maintenance struct {
+	Name           string    `json:"name"`
+	Status         string    `json:"status"`
+	ScheduledFor   time.Time `json:"scheduled_for"`
+	ScheduledUntil time.Time `json:"scheduled_until"`
+}
+
+// InProgress returns true if the maintenance started and isn't completed
+func (maintenance *Maintenance) InProgress() bool {
+	return maintenance.Status == "in_progress" || maintenance.Status == "verifying"
+}
+
+// FetchMaintenances returns the maintenances in progress listed at url,
+// e.g. https://status.example.com/api/v2/scheduled-maintenances/active.json
+func FetchMaintenances(ctx context.Context, url string) ([]*Maintenance, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch maintenances: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch maintenances: %s", resp.Status)
+	}
+	page := struct {
+		ScheduledMaintenances []*Maintenance `json:"scheduled_maintenances"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("could not decode maintenances: %v", err)
+	}
+	maintenances := []*Maintenance{}
+	for _, maintenance := range page.ScheduledMaintenances {
+		maintenance.Status = strings.ToLower(maintenance.Status)
+		if maintenance.InProgress() {
+			maintenances = append(maintenances, maintenance)
+		}
+	}
+	return maintenances, nil
+}
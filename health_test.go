@@ -0,0 +1,80 @@
+package crosschain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func (s *CrosschainTestSuite) TestHaltThreshold() {
+	require := s.Require()
+	asset := &NativeAssetConfig{}
+	require.Zero(asset.HaltThreshold(0))
+	require.Equal(2*time.Minute, asset.HaltThreshold(12*time.Second))
+	// sub-second blocks
+	require.Equal(MinHaltThreshold, asset.HaltThreshold(400*time.Millisecond))
+
+	asset.BlockTime = 6 * time.Second
+	asset.HaltBlocks = 20
+	require.Equal(2*time.Minute, asset.HaltThreshold(12*time.Second))
+}
+
+func (s *CrosschainTestSuite) TestCheckChainHealth() {
+	require := s.Require()
+	latest := time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+	asset := &NativeAssetConfig{NativeAsset: ATOM}
+
+	health, err := CheckChainHealth(s.Ctx, asset, 100, latest, 6*time.Second, latest.Add(30*time.Second))
+	require.NoError(err)
+	require.True(health.Healthy())
+	require.Equal(ATOM, health.Chain)
+	require.EqualValues(100, health.Height)
+	require.Equal(time.Minute, health.HaltThreshold)
+
+	health, err = CheckChainHealth(s.Ctx, asset, 100, latest, 6*time.Second, latest.Add(90*time.Second))
+	require.NoError(err)
+	require.False(health.Healthy())
+	require.Equal(ChainStatusHalted, health.Status)
+	require.Equal("latest block 100 is 1m30s old", health.Details)
+
+	// unknown block time
+	health, err = CheckChainHealth(s.Ctx, asset, 100, latest, 0, latest.Add(time.Hour))
+	require.NoError(err)
+	require.True(health.Healthy())
+}
+
+func (s *CrosschainTestSuite) TestCheckChainHealthMaintenance() {
+	require := s.Require()
+	body := `{"page":{"id":"p"},"scheduled_maintenances":[
+		{"name":"Database upgrade","status":"completed"},
+		{"name":"Cosmos Hub v10 upgrade","status":"in_progress","scheduled_for":"2023-05-03T13:55:00Z","scheduled_until":"2023-05-03T15:00:00Z"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(body))
+	}))
+	defer server.Close()
+	latest := time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+	asset := &NativeAssetConfig{NativeAsset: ATOM, MaintenanceURL: server.URL}
+
+	maintenances, err := FetchMaintenances(s.Ctx, server.URL)
+	require.NoError(err)
+	require.Len(maintenances, 1)
+	require.Equal(latest.Add(-5*time.Minute), maintenances[0].ScheduledFor)
+
+	health, err := CheckChainHealth(s.Ctx, asset, 100, latest, 6*time.Second, latest)
+	require.NoError(err)
+	require.Equal(ChainStatusMaintenance, health.Status)
+	require.Equal("Cosmos Hub v10 upgrade", health.Details)
+
+	body = `{"scheduled_maintenances":[]}`
+	health, err = CheckChainHealth(s.Ctx, asset, 100, latest, 6*time.Second, latest)
+	require.NoError(err)
+	require.True(health.Healthy())
+
+	body = `<html>`
+	_, err = CheckChainHealth(s.Ctx, asset, 100, latest, 6*time.Second, latest)
+	require.ErrorContains(err, "could not decode maintenances")
+
+	server.Close()
+	_, err = FetchMaintenances(s.Ctx, server.URL)
+	require.ErrorContains(err, "could not fetch maintenances")
+}
@@ -0,0 +1,58 @@
+package intent
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Client submits intents to a Server
+type Client struct {
+	Conn *grpc.ClientConn
+}
+
+// NewClient creates a new Client
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{
+		Conn: conn,
+	}
+}
+
+// Submit submits an intent
+func (client *Client) Submit(ctx context.Context, intent *TransferIntent) (*SubmitResponse, error) {
+	res := &SubmitResponse{}
+	if err := client.Conn.Invoke(ctx, "/"+ServiceName+"/Submit", intent, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Watcher receives the intents accepted by a Server
+type Watcher struct {
+	stream grpc.ClientStream
+}
+
+// Watch opens a stream of the intents selected by req
+func (client *Client) Watch(ctx context.Context, req *WatchRequest) (*Watcher, error) {
+	desc := &serviceDesc.Streams[0]
+	stream, err := client.Conn.NewStream(ctx, desc, "/"+ServiceName+"/"+desc.StreamName)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &Watcher{stream: stream}, nil
+}
+
+// Recv receives the next intent
+func (watcher *Watcher) Recv() (*TransferIntent, error) {
+	intent := &TransferIntent{}
+	if err := watcher.stream.RecvMsg(intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
@@ -0,0 +1,322 @@
+// Package intent is the chain-agnostic schema of transfer intents, defined in v1/intent.proto, with the
+// conversion of intents to the transfers of the orchestrator and a gRPC service submitting them and emitting
+// the intents accepted.
+//
+// Intents are emitted by the intent service, not the firehose: the firehose streams the transfers parsed from
+// blocks, resumable from their position on chain, while intents are requests accepted before anything is on
+// chain, with no position to resume from.
+//
+// The messages are written by hand with the protobuf struct tags of protoc-gen-gogo, so that they are encoded
+// as the messages generated from v1/intent.proto by any other language.
+package intent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+	"github.com/jumpcrypto/crosschain/queue"
+	"github.com/jumpcrypto/crosschain/storage"
+	"github.com/shopspring/decimal"
+)
+
+// SchemaVersion is the version of the schema of the messages, the last part of their proto package
+const SchemaVersion = "v1"
+
+// ProtoPackage is the proto package of the messages
+const ProtoPackage = "crosschain.intent." + SchemaVersion
+
+// MetadataSchemaVersion is the metadata key of the schema version of the intent of a transfer
+const MetadataSchemaVersion = "intent_version"
+
+// AmountUnit is the unit of an Amount
+type AmountUnit int32
+
+// List of AmountUnit
+const (
+	AmountUnitUnspecified   AmountUnit = 0
+	AmountUnitBlockchain    AmountUnit = 1
+	AmountUnitHumanReadable AmountUnit = 2
+)
+
+var amountUnitNames = map[int32]string{
+	0: "AMOUNT_UNIT_UNSPECIFIED",
+	1: "AMOUNT_UNIT_BLOCKCHAIN",
+	2: "AMOUNT_UNIT_HUMAN_READABLE",
+}
+
+var amountUnitValues = map[string]int32{
+	"AMOUNT_UNIT_UNSPECIFIED":    0,
+	"AMOUNT_UNIT_BLOCKCHAIN":     1,
+	"AMOUNT_UNIT_HUMAN_READABLE": 2,
+}
+
+func (unit AmountUnit) String() string {
+	return proto.EnumName(amountUnitNames, int32(unit))
+}
+
+// Priority is the priority of the execution of an intent
+type Priority int32
+
+// List of Priority
+const (
+	PriorityUnspecified Priority = 0
+	PriorityHigh        Priority = 1
+	PriorityNormal      Priority = 2
+	PriorityLow         Priority = 3
+)
+
+var priorityNames = map[int32]string{
+	0: "PRIORITY_UNSPECIFIED",
+	1: "PRIORITY_HIGH",
+	2: "PRIORITY_NORMAL",
+	3: "PRIORITY_LOW",
+}
+
+var priorityValues = map[string]int32{
+	"PRIORITY_UNSPECIFIED": 0,
+	"PRIORITY_HIGH":        1,
+	"PRIORITY_NORMAL":      2,
+	"PRIORITY_LOW":         3,
+}
+
+func (priority Priority) String() string {
+	return proto.EnumName(priorityNames, int32(priority))
+}
+
+// QueuePriority returns the priority of the queue of the orchestrator, unspecified is served as normal
+func (priority Priority) QueuePriority() (queue.Priority, error) {
+	switch priority {
+	case PriorityHigh:
+		return queue.PriorityHigh, nil
+	case PriorityUnspecified, PriorityNormal:
+		return queue.PriorityNormal, nil
+	case PriorityLow:
+		return queue.PriorityLow, nil
+	}
+	return "", fmt.Errorf("invalid priority %d", priority)
+}
+
+// TransferIntent is crosschain.intent.v1.TransferIntent, a request to transfer an amount of an asset
+// from a source to a destination
+type TransferIntent struct {
+	ID          string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Source      *Endpoint         `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Destination *Endpoint         `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`
+	Asset       *Asset            `protobuf:"bytes,4,opt,name=asset,proto3" json:"asset,omitempty"`
+	Amount      *Amount           `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Constraints *Constraints      `protobuf:"bytes,6,opt,name=constraints,proto3" json:"constraints,omitempty"`
+	Metadata    map[string]string `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *TransferIntent) Reset()         { *m = TransferIntent{} }
+func (m *TransferIntent) String() string { return proto.CompactTextString(m) }
+func (*TransferIntent) ProtoMessage()    {}
+
+// Endpoint is crosschain.intent.v1.Endpoint, an address on a chain
+type Endpoint struct {
+	Chain   string `protobuf:"bytes,1,opt,name=chain,proto3" json:"chain,omitempty"`
+	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Memo    string `protobuf:"bytes,3,opt,name=memo,proto3" json:"memo,omitempty"`
+}
+
+func (m *Endpoint) Reset()         { *m = Endpoint{} }
+func (m *Endpoint) String() string { return proto.CompactTextString(m) }
+func (*Endpoint) ProtoMessage()    {}
+
+// Asset is crosschain.intent.v1.Asset, an asset by symbol or by contract
+type Asset struct {
+	Chain    string `protobuf:"bytes,1,opt,name=chain,proto3" json:"chain,omitempty"`
+	Symbol   string `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Contract string `protobuf:"bytes,3,opt,name=contract,proto3" json:"contract,omitempty"`
+}
+
+func (m *Asset) Reset()         { *m = Asset{} }
+func (m *Asset) String() string { return proto.CompactTextString(m) }
+func (*Asset) ProtoMessage()    {}
+
+// Amount is crosschain.intent.v1.Amount
+type Amount struct {
+	Value string     `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Unit  AmountUnit `protobuf:"varint,2,opt,name=unit,proto3,enum=crosschain.intent.v1.AmountUnit" json:"unit,omitempty"`
+}
+
+func (m *Amount) Reset()         { *m = Amount{} }
+func (m *Amount) String() string { return proto.CompactTextString(m) }
+func (*Amount) ProtoMessage()    {}
+
+// Constraints is crosschain.intent.v1.Constraints, the optional constraints of the execution of an intent
+type Constraints struct {
+	MaxFee           string   `protobuf:"bytes,1,opt,name=max_fee,json=maxFee,proto3" json:"max_fee,omitempty"`
+	Deadline         int64    `protobuf:"varint,2,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	Priority         Priority `protobuf:"varint,3,opt,name=priority,proto3,enum=crosschain.intent.v1.Priority" json:"priority,omitempty"`
+	MinConfirmations uint32   `protobuf:"varint,4,opt,name=min_confirmations,json=minConfirmations,proto3" json:"min_confirmations,omitempty"`
+}
+
+func (m *Constraints) Reset()         { *m = Constraints{} }
+func (m *Constraints) String() string { return proto.CompactTextString(m) }
+func (*Constraints) ProtoMessage()    {}
+
+// SubmitResponse is crosschain.intent.v1.SubmitResponse
+type SubmitResponse struct {
+	ID      string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (m *SubmitResponse) Reset()         { *m = SubmitResponse{} }
+func (m *SubmitResponse) String() string { return proto.CompactTextString(m) }
+func (*SubmitResponse) ProtoMessage()    {}
+
+// WatchRequest is crosschain.intent.v1.WatchRequest, selecting the intents emitted by Watch
+type WatchRequest struct {
+	Chains []string `protobuf:"bytes,1,rep,name=chains,proto3" json:"chains,omitempty"`
+}
+
+func (m *WatchRequest) Reset()         { *m = WatchRequest{} }
+func (m *WatchRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRequest) ProtoMessage()    {}
+
+func init() {
+	proto.RegisterEnum(ProtoPackage+".AmountUnit", amountUnitNames, amountUnitValues)
+	proto.RegisterEnum(ProtoPackage+".Priority", priorityNames, priorityValues)
+	proto.RegisterType((*TransferIntent)(nil), ProtoPackage+".TransferIntent")
+	proto.RegisterType((*Endpoint)(nil), ProtoPackage+".Endpoint")
+	proto.RegisterType((*Asset)(nil), ProtoPackage+".Asset")
+	proto.RegisterType((*Amount)(nil), ProtoPackage+".Amount")
+	proto.RegisterType((*Constraints)(nil), ProtoPackage+".Constraints")
+	proto.RegisterType((*SubmitResponse)(nil), ProtoPackage+".SubmitResponse")
+	proto.RegisterType((*WatchRequest)(nil), ProtoPackage+".WatchRequest")
+}
+
+// Validate checks the fields of an intent, independently of the configured chains
+func (m *TransferIntent) Validate() error {
+	if strings.TrimSpace(m.ID) == "" {
+		return errors.New("invalid intent: missing id")
+	}
+	if err := m.Source.validate("source"); err != nil {
+		return err
+	}
+	if err := m.Destination.validate("destination"); err != nil {
+		return err
+	}
+	if m.Asset == nil || m.Asset.Chain == "" || (m.Asset.Symbol == "" && m.Asset.Contract == "") {
+		return errors.New("invalid intent: missing asset")
+	}
+	if m.Asset.Chain != m.Source.Chain {
+		return fmt.Errorf("invalid intent: asset on %s but source on %s", m.Asset.Chain, m.Source.Chain)
+	}
+	if m.Amount == nil || m.Amount.Value == "" {
+		return errors.New("invalid intent: missing amount")
+	}
+	switch m.Amount.Unit {
+	case AmountUnitBlockchain:
+		amount, err := xc.ParseAmountBlockchain(m.Amount.Value)
+		if err != nil || amount.Sign() <= 0 {
+			return fmt.Errorf("invalid intent: invalid amount '%s'", m.Amount.Value)
+		}
+	case AmountUnitHumanReadable:
+		amount, err := xc.ParseAmountHumanReadable(m.Amount.Value)
+		if err != nil || decimal.Decimal(amount).Sign() <= 0 {
+			return fmt.Errorf("invalid intent: invalid amount '%s'", m.Amount.Value)
+		}
+	default:
+		return fmt.Errorf("invalid intent: invalid amount unit %s", m.Amount.Unit)
+	}
+	if constraints := m.Constraints; constraints != nil {
+		if constraints.MaxFee != "" {
+			if _, err := xc.ParseAmountBlockchain(constraints.MaxFee); err != nil {
+				return fmt.Errorf("invalid intent: invalid max fee '%s'", constraints.MaxFee)
+			}
+		}
+		if constraints.Deadline < 0 {
+			return fmt.Errorf("invalid intent: invalid deadline %d", constraints.Deadline)
+		}
+		if _, err := constraints.Priority.QueuePriority(); err != nil {
+			return fmt.Errorf("invalid intent: %v", err)
+		}
+	}
+	return nil
+}
+
+func (m *Endpoint) validate(name string) error {
+	if m == nil || m.Chain == "" {
+		return fmt.Errorf("invalid intent: missing %s chain", name)
+	}
+	if m.Address == "" {
+		return fmt.Errorf("invalid intent: missing %s address", name)
+	}
+	return nil
+}
+
+// Expired returns true if the deadline of the intent is before t
+func (m *TransferIntent) Expired(t time.Time) bool {
+	return m.Constraints != nil && m.Constraints.Deadline > 0 && t.Unix() > m.Constraints.Deadline
+}
+
+// Priority returns the priority of the queue of the orchestrator
+func (m *TransferIntent) Priority() queue.Priority {
+	if m.Constraints == nil {
+		return queue.PriorityNormal
+	}
+	priority, err := m.Constraints.Priority.QueuePriority()
+	if err != nil {
+		return queue.PriorityNormal
+	}
+	return priority
+}
+
+// Transfer converts a valid intent to the transfer created by the orchestrator, resolving its assets with the
+// configuration of f. The asset on the destination is the asset of the same symbol on the chain of the destination.
+// The memo and the constraints are not part of the transfer and are read from the intent.
+func (m *TransferIntent) Transfer(f factory.FactoryContext) (*storage.Transfer, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	var srcAsset xc.ITask
+	var err error
+	if m.Asset.Contract != "" {
+		srcAsset, err = f.GetAssetConfigByContract(m.Asset.Contract, m.Asset.Chain)
+	} else {
+		srcAsset, err = f.GetAssetConfig(m.Asset.Symbol, m.Asset.Chain)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unsupported asset: %v", err)
+	}
+	dstAsset := srcAsset
+	if m.Destination.Chain != m.Source.Chain {
+		dstAsset, err = f.GetAssetConfig(srcAsset.GetAssetConfig().Asset, m.Destination.Chain)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported destination asset: %v", err)
+		}
+	}
+
+	var amount xc.AmountBlockchain
+	if m.Amount.Unit == AmountUnitHumanReadable {
+		amount, err = f.ConvertAmountStrToBlockchain(srcAsset, m.Amount.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intent: invalid amount '%s': %v", m.Amount.Value, err)
+		}
+	} else {
+		amount = xc.NewAmountBlockchainFromStr(m.Amount.Value)
+	}
+
+	metadata := xc.Metadata{}
+	for key, value := range m.Metadata {
+		metadata[key] = value
+	}
+	metadata[MetadataSchemaVersion] = SchemaVersion
+	return &storage.Transfer{
+		ID:       m.ID,
+		SrcAsset: srcAsset.ID(),
+		DstAsset: dstAsset.ID(),
+		From:     xc.Address(m.Source.Address),
+		To:       xc.Address(m.Destination.Address),
+		Amount:   amount,
+		State:    storage.TransferStateCreated,
+		Metadata: metadata,
+	}, nil
+}
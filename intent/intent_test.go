@@ -0,0 +1,249 @@
+package intent
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/dynamic"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/queue"
+	"github.com/jumpcrypto/crosschain/storage"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestIntentTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func newIntent() *TransferIntent {
+	return &TransferIntent{
+		ID:          "order-1",
+		Source:      &Endpoint{Chain: "SOL", Address: "from"},
+		Destination: &Endpoint{Chain: "SOL", Address: "to", Memo: "123"},
+		Asset:       &Asset{Chain: "SOL", Symbol: "USDC"},
+		Amount:      &Amount{Value: "1.5", Unit: AmountUnitHumanReadable},
+		Metadata:    map[string]string{"desk": "otc"},
+	}
+}
+
+func (s *CrosschainTestSuite) TestEncoding() {
+	require := s.Require()
+	intent := &TransferIntent{
+		ID:          "a",
+		Source:      &Endpoint{Chain: "ETH"},
+		Amount:      &Amount{Value: "1", Unit: AmountUnitHumanReadable},
+		Constraints: &Constraints{Priority: PriorityLow},
+		Metadata:    map[string]string{"k": "v"},
+	}
+	// encoded as by protoc from v1/intent.proto
+	encoded, err := proto.Marshal(intent)
+	require.NoError(err)
+	require.Equal("0a016112050a034554482a050a01311002320218033a060a016b120176", hex.EncodeToString(encoded))
+
+	decoded := &TransferIntent{}
+	require.NoError(proto.Unmarshal(encoded, decoded))
+	require.Equal(intent, decoded)
+	require.Equal(`id:"a" source:<chain:"ETH" > amount:<value:"1" unit:AMOUNT_UNIT_HUMAN_READABLE > constraints:<priority:PRIORITY_LOW > metadata:<key:"k" value:"v" > `, decoded.String())
+}
+
+// parseSchema parses v1/intent.proto, the schema of the messages
+func parseSchema(s *CrosschainTestSuite) *desc.FileDescriptor {
+	require := s.Require()
+	files, err := (&protoparse.Parser{ImportPaths: []string{"v1"}}).ParseFiles("intent.proto")
+	require.NoError(err)
+	require.Len(files, 1)
+	return files[0]
+}
+
+func (s *CrosschainTestSuite) TestSchema() {
+	require := s.Require()
+	schema := parseSchema(s)
+	require.Equal(ProtoPackage, schema.GetPackage())
+	for _, message := range schema.GetMessageTypes() {
+		require.NotNil(proto.MessageType(message.GetFullyQualifiedName()), message.GetFullyQualifiedName())
+	}
+	for _, enum := range schema.GetEnumTypes() {
+		require.NotNil(proto.EnumValueMap(enum.GetFullyQualifiedName()), enum.GetFullyQualifiedName())
+	}
+	service := schema.FindService(ServiceName)
+	require.NotNil(service)
+	require.Len(service.GetMethods(), len(serviceDesc.Methods)+len(serviceDesc.Streams))
+	for _, method := range serviceDesc.Methods {
+		require.NotNil(service.FindMethodByName(method.MethodName), method.MethodName)
+	}
+	for _, stream := range serviceDesc.Streams {
+		method := service.FindMethodByName(stream.StreamName)
+		require.NotNil(method, stream.StreamName)
+		require.Equal(stream.ServerStreams, method.IsServerStreaming())
+		require.Equal(stream.ClientStreams, method.IsClientStreaming())
+	}
+
+	// every field set, encoded from the schema
+	message := func(name string) *dynamic.Message {
+		return dynamic.NewMessage(schema.FindMessage(ProtoPackage + "." + name))
+	}
+	source := message("Endpoint")
+	source.SetFieldByName("chain", "ETH")
+	source.SetFieldByName("address", "0xfrom")
+	destination := message("Endpoint")
+	destination.SetFieldByName("chain", "ETH")
+	destination.SetFieldByName("address", "0xto")
+	destination.SetFieldByName("memo", "42")
+	asset := message("Asset")
+	asset.SetFieldByName("chain", "ETH")
+	asset.SetFieldByName("symbol", "USDC")
+	asset.SetFieldByName("contract", "0xusdc")
+	amount := message("Amount")
+	amount.SetFieldByName("value", "100")
+	amount.SetFieldByName("unit", int32(AmountUnitBlockchain))
+	constraints := message("Constraints")
+	constraints.SetFieldByName("max_fee", "5000")
+	constraints.SetFieldByName("deadline", int64(1700000000))
+	constraints.SetFieldByName("priority", int32(PriorityHigh))
+	constraints.SetFieldByName("min_confirmations", uint32(12))
+	expected := message("TransferIntent")
+	expected.SetFieldByName("id", "order-1")
+	expected.SetFieldByName("source", source)
+	expected.SetFieldByName("destination", destination)
+	expected.SetFieldByName("asset", asset)
+	expected.SetFieldByName("amount", amount)
+	expected.SetFieldByName("constraints", constraints)
+	expected.PutMapFieldByName("metadata", "desk", "otc")
+	golden, err := expected.MarshalDeterministic()
+	require.NoError(err)
+	require.Equal("0a076f726465722d31120d0a034554481206307866726f6d1a0f0a0345544812043078746f1a023432"+
+		"22130a034554481204555344431a063078757364632a070a03313030100132100a04353030301080e2cfaa061801200c"+
+		"3a0b0a046465736b12036f7463", hex.EncodeToString(golden))
+
+	intent := &TransferIntent{
+		ID:          "order-1",
+		Source:      &Endpoint{Chain: "ETH", Address: "0xfrom"},
+		Destination: &Endpoint{Chain: "ETH", Address: "0xto", Memo: "42"},
+		Asset:       &Asset{Chain: "ETH", Symbol: "USDC", Contract: "0xusdc"},
+		Amount:      &Amount{Value: "100", Unit: AmountUnitBlockchain},
+		Constraints: &Constraints{MaxFee: "5000", Deadline: 1700000000, Priority: PriorityHigh, MinConfirmations: 12},
+		Metadata:    map[string]string{"desk": "otc"},
+	}
+	encoded, err := proto.Marshal(intent)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(golden), hex.EncodeToString(encoded))
+	decoded := &TransferIntent{}
+	require.NoError(proto.Unmarshal(golden, decoded))
+	require.Equal(intent, decoded)
+
+	watch := message("WatchRequest")
+	watch.SetFieldByName("chains", []string{"ETH", "SOL"})
+	golden, err = watch.MarshalDeterministic()
+	require.NoError(err)
+	encoded, err = proto.Marshal(&WatchRequest{Chains: []string{"ETH", "SOL"}})
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(golden), hex.EncodeToString(encoded))
+
+	response := message("SubmitResponse")
+	response.SetFieldByName("id", "order-1")
+	response.SetFieldByName("version", SchemaVersion)
+	golden, err = response.MarshalDeterministic()
+	require.NoError(err)
+	encoded, err = proto.Marshal(&SubmitResponse{ID: "order-1", Version: SchemaVersion})
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(golden), hex.EncodeToString(encoded))
+}
+
+func (s *CrosschainTestSuite) TestValidate() {
+	require := s.Require()
+	require.NoError(newIntent().Validate())
+
+	vectors := []struct {
+		update func(intent *TransferIntent)
+		err    string
+	}{
+		{func(intent *TransferIntent) { intent.ID = " " }, "invalid intent: missing id"},
+		{func(intent *TransferIntent) { intent.Source = nil }, "invalid intent: missing source chain"},
+		{func(intent *TransferIntent) { intent.Destination.Address = "" }, "invalid intent: missing destination address"},
+		{func(intent *TransferIntent) { intent.Asset = &Asset{Chain: "SOL"} }, "invalid intent: missing asset"},
+		{func(intent *TransferIntent) { intent.Asset.Chain = "ETH" }, "invalid intent: asset on ETH but source on SOL"},
+		{func(intent *TransferIntent) { intent.Amount = nil }, "invalid intent: missing amount"},
+		{func(intent *TransferIntent) { intent.Amount.Value = "-1" }, "invalid intent: invalid amount '-1'"},
+		{func(intent *TransferIntent) { intent.Amount.Unit = AmountUnitBlockchain }, "invalid intent: invalid amount '1.5'"},
+		{func(intent *TransferIntent) { intent.Amount.Unit = AmountUnitUnspecified }, "invalid intent: invalid amount unit AMOUNT_UNIT_UNSPECIFIED"},
+		{func(intent *TransferIntent) { intent.Constraints = &Constraints{MaxFee: "0.1"} }, "invalid intent: invalid max fee '0.1'"},
+		{func(intent *TransferIntent) { intent.Constraints = &Constraints{Deadline: -1} }, "invalid intent: invalid deadline -1"},
+		{func(intent *TransferIntent) { intent.Constraints = &Constraints{Priority: 7} }, "invalid intent: invalid priority 7"},
+	}
+	for _, v := range vectors {
+		intent := newIntent()
+		v.update(intent)
+		require.EqualError(intent.Validate(), v.err)
+	}
+}
+
+func (s *CrosschainTestSuite) TestConstraints() {
+	require := s.Require()
+	intent := newIntent()
+	require.False(intent.Expired(time.Now()))
+	require.Equal(queue.PriorityNormal, intent.Priority())
+
+	intent.Constraints = &Constraints{Deadline: 1000, Priority: PriorityHigh}
+	require.False(intent.Expired(time.Unix(1000, 0)))
+	require.True(intent.Expired(time.Unix(1001, 0)))
+	require.Equal(queue.PriorityHigh, intent.Priority())
+}
+
+func (s *CrosschainTestSuite) TestTransfer() {
+	require := s.Require()
+	f := testutil.NewDefaultFactory().DefaultFactory
+
+	transfer, err := newIntent().Transfer(f)
+	require.NoError(err)
+	require.Equal(&storage.Transfer{
+		ID:       "order-1",
+		SrcAsset: "USDC.SOL",
+		DstAsset: "USDC.SOL",
+		From:     "from",
+		To:       "to",
+		Amount:   xc.NewAmountBlockchainFromStr("1500000"),
+		State:    storage.TransferStateCreated,
+		Metadata: xc.Metadata{"desk": "otc", MetadataSchemaVersion: "v1"},
+	}, transfer)
+
+	// by contract, to another chain, in the smallest unit
+	intent := newIntent()
+	intent.Asset = &Asset{Chain: "SOL", Contract: "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"}
+	intent.Destination.Chain = "ETH"
+	intent.Amount = &Amount{Value: "1000", Unit: AmountUnitBlockchain}
+	transfer, err = intent.Transfer(f)
+	require.NoError(err)
+	require.Equal(xc.AssetID("USDC.SOL"), transfer.SrcAsset)
+	require.Equal(xc.AssetID("USDC.ETH"), transfer.DstAsset)
+	require.Equal("1000", transfer.Amount.String())
+
+	intent = newIntent()
+	intent.Asset.Symbol = "XYZ"
+	_, err = intent.Transfer(f)
+	require.ErrorContains(err, "unsupported asset")
+
+	intent = newIntent()
+	intent.Destination.Chain = "BTC"
+	_, err = intent.Transfer(f)
+	require.ErrorContains(err, "unsupported destination asset")
+
+	intent = newIntent()
+	intent.ID = ""
+	_, err = intent.Transfer(f)
+	require.EqualError(err, "invalid intent: missing id")
+}
@@ -0,0 +1,195 @@
+package intent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jumpcrypto/crosschain/lease"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the gRPC service of intents, as defined in v1/intent.proto
+const ServiceName = ProtoPackage + ".IntentService"
+
+// Handler hands a valid intent to the orchestrator, e.g. converting it with TransferIntent.Transfer and queuing it
+type Handler func(ctx context.Context, intent *TransferIntent) error
+
+// Server accepts the intents submitted by upstream systems
+type Server struct {
+	Handler Handler
+	// Locker, if set, leases each intent while it's handed to the handler, so that an intent submitted to several
+	// replicas at once is handled once at a time; the handler deduplicates intents handled already
+	Locker   lease.Locker
+	LeaseTTL time.Duration
+
+	mu       sync.Mutex
+	watchers map[chan *TransferIntent]*WatchRequest
+}
+
+// watchBufferSize is the number of intents buffered per watcher, watchers falling further behind are dropped
+const watchBufferSize = 256
+
+// IntentSender sends watched intents, e.g. a gRPC server stream
+type IntentSender interface {
+	Context() context.Context
+	Send(*TransferIntent) error
+}
+
+type grpcIntentSender struct {
+	grpc.ServerStream
+}
+
+func (sender *grpcIntentSender) Send(intent *TransferIntent) error {
+	return sender.ServerStream.SendMsg(intent)
+}
+
+// NewServer creates a new Server
+func NewServer(handler Handler) *Server {
+	return &Server{
+		Handler: handler,
+	}
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Submit",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := &TransferIntent{}
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			submit := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.(*Server).Submit(ctx, req.(*TransferIntent))
+			}
+			if interceptor == nil {
+				return submit(ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/" + ServiceName + "/Submit",
+			}
+			return interceptor(ctx, req, info, submit)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Watch",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			req := &WatchRequest{}
+			if err := stream.RecvMsg(req); err != nil {
+				return err
+			}
+			return srv.(*Server).Watch(req, &grpcIntentSender{stream})
+		},
+	}},
+	Metadata: "intent/v1/intent.proto",
+}
+
+// Register registers the intent service on a gRPC server
+func (server *Server) Register(grpcServer *grpc.Server) {
+	grpcServer.RegisterService(&serviceDesc, server)
+}
+
+// Submit validates an intent and hands it to the handler, invalid intents are rejected with InvalidArgument
+func (server *Server) Submit(ctx context.Context, intent *TransferIntent) (*SubmitResponse, error) {
+	if err := intent.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := server.handle(ctx, intent); err != nil {
+		if errors.Is(err, lease.ErrLeaseHeld) {
+			return nil, status.Error(codes.Aborted, fmt.Sprintf("intent %s is being handled by another replica", intent.ID))
+		}
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	server.emit(intent)
+	return &SubmitResponse{
+		ID:      intent.ID,
+		Version: SchemaVersion,
+	}, nil
+}
+
+// handle hands an intent to the handler, holding its lease with a Locker
+func (server *Server) handle(ctx context.Context, intent *TransferIntent) error {
+	if server.Locker == nil {
+		return server.Handler(ctx, intent)
+	}
+	ttl := server.LeaseTTL
+	if ttl <= 0 {
+		ttl = lease.DefaultTTL
+	}
+	return lease.WithLease(ctx, server.Locker, lease.IntentKey(intent.ID), ttl, func(ctx context.Context) error {
+		return server.Handler(ctx, intent)
+	})
+}
+
+// Watch sends the intents accepted by Submit from now on, selected by req, until the stream context is done
+// Watchers that can't keep up are dropped with ResourceExhausted, and may watch again
+func (server *Server) Watch(req *WatchRequest, sender IntentSender) error {
+	ctx := sender.Context()
+	intents := make(chan *TransferIntent, watchBufferSize)
+	server.mu.Lock()
+	if server.watchers == nil {
+		server.watchers = map[chan *TransferIntent]*WatchRequest{}
+	}
+	server.watchers[intents] = req
+	server.mu.Unlock()
+	defer func() {
+		server.mu.Lock()
+		delete(server.watchers, intents)
+		server.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case intent, ok := <-intents:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher is too slow")
+			}
+			if err := sender.Send(intent); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// emit sends an accepted intent to the watchers selecting it
+func (server *Server) emit(intent *TransferIntent) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	for intents, req := range server.watchers {
+		if !req.selects(intent) {
+			continue
+		}
+		select {
+		case intents <- intent:
+		default:
+			close(intents)
+			delete(server.watchers, intents)
+		}
+	}
+}
+
+// selects returns true if the chain of the source of intent is watched
+func (req *WatchRequest) selects(intent *TransferIntent) bool {
+	if len(req.Chains) == 0 {
+		return true
+	}
+	for _, chain := range req.Chains {
+		if chain == intent.Source.Chain {
+			return true
+		}
+	}
+	return false
+}
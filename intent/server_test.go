@@ -0,0 +1,198 @@
+package intent
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/jumpcrypto/crosschain/lease"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func (s *CrosschainTestSuite) TestServerSubmit() {
+	require := s.Require()
+	submitted := []*TransferIntent{}
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	server := NewServer(func(ctx context.Context, intent *TransferIntent) error {
+		if intent.ID == "fail" {
+			return errors.New("queue is full")
+		}
+		submitted = append(submitted, intent)
+		return nil
+	})
+	server.Register(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.DialContext(s.Ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	require.NoError(err)
+	defer conn.Close()
+	client := NewClient(conn)
+
+	res, err := client.Submit(s.Ctx, newIntent())
+	require.NoError(err)
+	require.Equal(&SubmitResponse{ID: "order-1", Version: "v1"}, res)
+	require.Len(submitted, 1)
+	require.Equal(newIntent(), submitted[0])
+
+	intent := newIntent()
+	intent.Amount = nil
+	_, err = client.Submit(s.Ctx, intent)
+	require.Equal(codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(err, "invalid intent: missing amount")
+
+	intent = newIntent()
+	intent.ID = "fail"
+	_, err = client.Submit(s.Ctx, intent)
+	require.Equal(codes.Internal, status.Code(err))
+	require.ErrorContains(err, "queue is full")
+	require.Len(submitted, 1)
+}
+
+func (s *CrosschainTestSuite) TestServerSubmitLeased() {
+	require := s.Require()
+	submitted := []*TransferIntent{}
+	server := NewServer(func(ctx context.Context, intent *TransferIntent) error {
+		submitted = append(submitted, intent)
+		return nil
+	})
+	locker := lease.NewMemoryLocker()
+	server.Locker = locker
+
+	// another replica handles the intent
+	held, err := locker.Acquire(s.Ctx, lease.IntentKey("order-1"), time.Minute)
+	require.NoError(err)
+	_, err = server.Submit(s.Ctx, newIntent())
+	require.Equal(codes.Aborted, status.Code(err))
+	require.ErrorContains(err, "intent order-1 is being handled by another replica")
+	require.Empty(submitted)
+	require.NoError(held.Release(s.Ctx))
+
+	_, err = server.Submit(s.Ctx, newIntent())
+	require.NoError(err)
+	require.Len(submitted, 1)
+}
+
+func (s *CrosschainTestSuite) TestServerWatch() {
+	require := s.Require()
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	server := NewServer(func(ctx context.Context, intent *TransferIntent) error {
+		if intent.ID == "fail" {
+			return errors.New("queue is full")
+		}
+		return nil
+	})
+	server.Register(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.DialContext(s.Ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	require.NoError(err)
+	defer conn.Close()
+	client := NewClient(conn)
+
+	ctx, cancel := context.WithCancel(s.Ctx)
+	defer cancel()
+	watcher, err := client.Watch(ctx, &WatchRequest{Chains: []string{"SOL"}})
+	require.NoError(err)
+	others, err := client.Watch(ctx, &WatchRequest{Chains: []string{"ETH"}})
+	require.NoError(err)
+	require.Eventually(func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.watchers) == 2
+	}, time.Second, time.Millisecond)
+
+	// rejected intents aren't emitted
+	intent := newIntent()
+	intent.ID = "fail"
+	_, err = client.Submit(s.Ctx, intent)
+	require.Error(err)
+	_, err = client.Submit(s.Ctx, newIntent())
+	require.NoError(err)
+
+	received, err := watcher.Recv()
+	require.NoError(err)
+	require.Equal(newIntent(), received)
+
+	intent = newIntent()
+	intent.ID = "order-2"
+	intent.Source.Chain = "ETH"
+	intent.Asset.Chain = "ETH"
+	_, err = client.Submit(s.Ctx, intent)
+	require.NoError(err)
+	received, err = others.Recv()
+	require.NoError(err)
+	require.Equal("order-2", received.ID)
+
+	cancel()
+	require.Eventually(func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.watchers) == 0
+	}, time.Second, time.Millisecond)
+}
+
+type blockedSender struct {
+	ctx     context.Context
+	release chan struct{}
+	sent    int
+}
+
+func (sender *blockedSender) Context() context.Context {
+	return sender.ctx
+}
+
+func (sender *blockedSender) Send(*TransferIntent) error {
+	<-sender.release
+	sender.sent++
+	return nil
+}
+
+func (s *CrosschainTestSuite) TestServerWatchSlow() {
+	require := s.Require()
+	server := NewServer(func(ctx context.Context, intent *TransferIntent) error {
+		return nil
+	})
+	sender := &blockedSender{ctx: s.Ctx, release: make(chan struct{})}
+	done := make(chan error)
+	go func() {
+		done <- server.Watch(&WatchRequest{}, sender)
+	}()
+	require.Eventually(func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.watchers) == 1
+	}, time.Second, time.Millisecond)
+
+	// the watcher blocks on the first intent, and is dropped once its buffer is full
+	for i := 0; i < watchBufferSize+2; i++ {
+		_, err := server.Submit(s.Ctx, newIntent())
+		require.NoError(err)
+	}
+	server.mu.Lock()
+	require.Empty(server.watchers)
+	server.mu.Unlock()
+
+	// buffered intents are sent before the watcher is closed
+	close(sender.release)
+	err := <-done
+	require.Equal(codes.ResourceExhausted, status.Code(err))
+	require.Equal(watchBufferSize+1, sender.sent)
+}
@@ -0,0 +1,229 @@
+package crosschain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KeyID identifies a key held by a KeyRing, e.g. the id of a custody key ceremony
+type KeyID string
+
+// ErrUnknownKeyID is returned when a tx expects a key that the KeyRing doesn't hold
+var ErrUnknownKeyID = errors.New("unknown key id")
+
+// Key is a private key held by a KeyRing, with the public key its addresses are derived from
+type Key struct {
+	ID         KeyID
+	PrivateKey PrivateKey
+	PublicKey  PublicKey
+}
+
+// KeyedTx is a tx that must be signed by a specific key, see KeyRing.SignTx
+// Clients expect the tx of their chain: submit the embedded Tx
+type KeyedTx struct {
+	Tx
+	KeyID KeyID
+}
+
+// NewKeyedTx returns tx expecting to be signed by the key keyID
+func NewKeyedTx(tx Tx, keyID KeyID) *KeyedTx {
+	return &KeyedTx{
+		Tx:    tx,
+		KeyID: keyID,
+	}
+}
+
+// KeyRing signs with multiple keys of a chain, so that old and new keys are both usable during a rotation
+// The active key signs txs that don't expect a specific key
+type KeyRing struct {
+	Signer Signer
+
+	mu     sync.RWMutex
+	keys   map[KeyID]*Key
+	active KeyID
+}
+
+// NewKeyRing creates an empty KeyRing signing with signer
+func NewKeyRing(signer Signer) *KeyRing {
+	return &KeyRing{
+		Signer: signer,
+		keys:   map[KeyID]*Key{},
+	}
+}
+
+// AddKey adds a key, the first key added is active
+func (ring *KeyRing) AddKey(id KeyID, privateKey PrivateKey, publicKey PublicKey) error {
+	if id == "" {
+		return errors.New("key id is required")
+	}
+	if len(privateKey) == 0 {
+		return fmt.Errorf("empty private key for key '%s'", id)
+	}
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if _, ok := ring.keys[id]; ok {
+		return fmt.Errorf("key '%s' already exists", id)
+	}
+	ring.keys[id] = &Key{ID: id, PrivateKey: privateKey, PublicKey: publicKey}
+	if ring.active == "" {
+		ring.active = id
+	}
+	return nil
+}
+
+// ImportKey imports a private key with the Signer of the KeyRing and adds it
+func (ring *KeyRing) ImportKey(id KeyID, privateKey string, publicKey PublicKey) error {
+	imported, err := ring.Signer.ImportPrivateKey(privateKey)
+	if err != nil {
+		return fmt.Errorf("could not import key '%s': %v", id, err)
+	}
+	return ring.AddKey(id, imported, publicKey)
+}
+
+// Rotate makes the key id active, the previous key can still sign txs expecting it until it's retired
+func (ring *KeyRing) Rotate(id KeyID) error {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if _, ok := ring.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKeyID, id)
+	}
+	ring.active = id
+	return nil
+}
+
+// Retire removes a key at the end of a rotation, the active key can't be retired
+func (ring *KeyRing) Retire(id KeyID) error {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	if _, ok := ring.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKeyID, id)
+	}
+	if id == ring.active {
+		return fmt.Errorf("key '%s' is active, rotate to another key first", id)
+	}
+	delete(ring.keys, id)
+	return nil
+}
+
+// ActiveKeyID returns the id of the active key, empty if the KeyRing is empty
+func (ring *KeyRing) ActiveKeyID() KeyID {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+	return ring.active
+}
+
+// KeyIDs returns the ids of the keys held, sorted
+func (ring *KeyRing) KeyIDs() []KeyID {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+	ids := make([]KeyID, 0, len(ring.keys))
+	for id := range ring.keys {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// PublicKey returns the public key of the key id
+func (ring *KeyRing) PublicKey(id KeyID) (PublicKey, error) {
+	key, err := ring.key(id)
+	if err != nil {
+		return nil, err
+	}
+	return key.PublicKey, nil
+}
+
+func (ring *KeyRing) key(id KeyID) (*Key, error) {
+	ring.mu.RLock()
+	defer ring.mu.RUnlock()
+	if id == "" {
+		id = ring.active
+	}
+	key, ok := ring.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownKeyID, id)
+	}
+	return key, nil
+}
+
+// Sign signs data with the key id, or the active key if id is empty
+func (ring *KeyRing) Sign(id KeyID, data TxDataToSign) (TxSignature, error) {
+	key, err := ring.key(id)
+	if err != nil {
+		return nil, err
+	}
+	return ring.Signer.Sign(key.PrivateKey, data)
+}
+
+// SignTx signs and adds the signatures of a tx, with the key expected by a KeyedTx or the active key
+// A KeyedTx is never signed by another key than the one it expects
+func (ring *KeyRing) SignTx(tx Tx) (KeyID, error) {
+	var id KeyID
+	if keyed, ok := tx.(*KeyedTx); ok {
+		if keyed.KeyID == "" {
+			return "", errors.New("keyed tx without key id")
+		}
+		id = keyed.KeyID
+	}
+	key, err := ring.key(id)
+	if err != nil {
+		return "", err
+	}
+	sighashes, err := tx.Sighashes()
+	if err != nil {
+		return "", err
+	}
+	signatures := make([]TxSignature, len(sighashes))
+	for i, sighash := range sighashes {
+		signatures[i], err = ring.Signer.Sign(key.PrivateKey, sighash)
+		if err != nil {
+			return "", err
+		}
+	}
+	return key.ID, tx.AddSignatures(signatures...)
+}
+
+// KeyIDOfAddress returns the id of the key an address is derived from, among the addresses builder derives from each key
+func (ring *KeyRing) KeyIDOfAddress(builder AddressBuilder, address Address) (KeyID, error) {
+	for _, id := range ring.KeyIDs() {
+		publicKey, err := ring.PublicKey(id)
+		if err != nil || len(publicKey) == 0 {
+			continue
+		}
+		possibles, err := builder.GetAllPossibleAddressesFromPublicKey(publicKey)
+		if err != nil {
+			return "", fmt.Errorf("could not derive addresses of key '%s': %v", id, err)
+		}
+		for _, possible := range possibles {
+			if sameAddress(possible.Address, address) {
+				return id, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("address %s doesn't belong to any key", address)
+}
+
+// ConfirmAddress returns an error unless address is derived from the key id
+func (ring *KeyRing) ConfirmAddress(builder AddressBuilder, id KeyID, address Address) error {
+	owner, err := ring.KeyIDOfAddress(builder, address)
+	if err != nil {
+		return err
+	}
+	if owner != id {
+		return fmt.Errorf("address %s belongs to key '%s', not '%s'", address, owner, id)
+	}
+	return nil
+}
+
+// sameAddress compares addresses, hex addresses are case insensitive (EIP-55 checksums)
+func sameAddress(a Address, b Address) bool {
+	if strings.HasPrefix(string(a), "0x") && strings.HasPrefix(string(b), "0x") {
+		return strings.EqualFold(string(a), string(b))
+	}
+	return a == b
+}
@@ -0,0 +1,135 @@
+package crosschain
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// testKeySigner signs with the concatenation of the private key and the data
+type testKeySigner struct{}
+
+func (signer testKeySigner) ImportPrivateKey(privateKey string) (PrivateKey, error) {
+	return hex.DecodeString(privateKey)
+}
+
+func (signer testKeySigner) Sign(privateKey PrivateKey, data TxDataToSign) (TxSignature, error) {
+	return TxSignature(append(append([]byte{}, privateKey...), data...)), nil
+}
+
+// testKeyAddressBuilder derives the hex of the public key, uppercase as a checksum
+type testKeyAddressBuilder struct{}
+
+func (builder testKeyAddressBuilder) GetAddressFromPublicKey(publicKeyBytes []byte) (Address, error) {
+	return Address("0x" + strings.ToUpper(hex.EncodeToString(publicKeyBytes))), nil
+}
+
+func (builder testKeyAddressBuilder) GetAllPossibleAddressesFromPublicKey(publicKeyBytes []byte) ([]PossibleAddress, error) {
+	address, err := builder.GetAddressFromPublicKey(publicKeyBytes)
+	return []PossibleAddress{{Address: address, Type: AddressTypeDefault}}, err
+}
+
+type testKeyTx struct {
+	sighashes  []TxDataToSign
+	signatures []TxSignature
+}
+
+func (tx *testKeyTx) Hash() TxHash {
+	return "hash"
+}
+
+func (tx *testKeyTx) Sighashes() ([]TxDataToSign, error) {
+	return tx.sighashes, nil
+}
+
+func (tx *testKeyTx) AddSignatures(signatures ...TxSignature) error {
+	tx.signatures = append(tx.signatures, signatures...)
+	return nil
+}
+
+func (tx *testKeyTx) Serialize() ([]byte, error) {
+	return []byte{}, nil
+}
+
+func (s *CrosschainTestSuite) TestKeyRingRotation() {
+	require := s.Require()
+	ring := NewKeyRing(testKeySigner{})
+	require.Equal(KeyID(""), ring.ActiveKeyID())
+	_, err := ring.SignTx(&testKeyTx{sighashes: []TxDataToSign{{0x01}}})
+	require.ErrorIs(err, ErrUnknownKeyID)
+
+	require.NoError(ring.AddKey("2023-q1", PrivateKey{0xa1}, PublicKey{0xaa}))
+	require.NoError(ring.ImportKey("2023-q2", "b1", PublicKey{0xbb}))
+	require.ErrorContains(ring.AddKey("2023-q1", PrivateKey{0xa1}, PublicKey{0xaa}), "already exists")
+	require.ErrorContains(ring.AddKey("", PrivateKey{0xa1}, nil), "key id is required")
+	require.ErrorContains(ring.AddKey("empty", nil, nil), "empty private key")
+	require.ErrorContains(ring.ImportKey("invalid", "zz", nil), "could not import key 'invalid'")
+	require.Equal(KeyID("2023-q1"), ring.ActiveKeyID())
+	require.Equal([]KeyID{"2023-q1", "2023-q2"}, ring.KeyIDs())
+
+	// txs without key id are signed by the active key
+	tx := &testKeyTx{sighashes: []TxDataToSign{{0x01}, {0x02}}}
+	id, err := ring.SignTx(tx)
+	require.NoError(err)
+	require.Equal(KeyID("2023-q1"), id)
+	require.Equal([]TxSignature{{0xa1, 0x01}, {0xa1, 0x02}}, tx.signatures)
+
+	// during the rotation, both keys sign txs expecting them
+	require.NoError(ring.Rotate("2023-q2"))
+	require.Equal(KeyID("2023-q2"), ring.ActiveKeyID())
+	tx = &testKeyTx{sighashes: []TxDataToSign{{0x03}}}
+	id, err = ring.SignTx(NewKeyedTx(tx, "2023-q1"))
+	require.NoError(err)
+	require.Equal(KeyID("2023-q1"), id)
+	require.Equal([]TxSignature{{0xa1, 0x03}}, tx.signatures)
+	tx = &testKeyTx{sighashes: []TxDataToSign{{0x03}}}
+	id, err = ring.SignTx(tx)
+	require.NoError(err)
+	require.Equal(KeyID("2023-q2"), id)
+	require.Equal([]TxSignature{{0xb1, 0x03}}, tx.signatures)
+
+	signature, err := ring.Sign("", TxDataToSign{0x04})
+	require.NoError(err)
+	require.Equal(TxSignature{0xb1, 0x04}, signature)
+
+	// the old key is retired, txs expecting it are never signed by another key
+	require.ErrorContains(ring.Retire("2023-q2"), "is active")
+	require.NoError(ring.Retire("2023-q1"))
+	require.ErrorIs(ring.Retire("2023-q1"), ErrUnknownKeyID)
+	require.ErrorIs(ring.Rotate("2023-q1"), ErrUnknownKeyID)
+	tx = &testKeyTx{sighashes: []TxDataToSign{{0x05}}}
+	_, err = ring.SignTx(NewKeyedTx(tx, "2023-q1"))
+	require.True(errors.Is(err, ErrUnknownKeyID))
+	require.Empty(tx.signatures)
+	_, err = ring.SignTx(NewKeyedTx(tx, ""))
+	require.ErrorContains(err, "keyed tx without key id")
+	require.Equal([]KeyID{"2023-q2"}, ring.KeyIDs())
+}
+
+func (s *CrosschainTestSuite) TestKeyRingAddresses() {
+	require := s.Require()
+	ring := NewKeyRing(testKeySigner{})
+	builder := testKeyAddressBuilder{}
+	require.NoError(ring.AddKey("old", PrivateKey{0xa1}, PublicKey{0xaa}))
+	require.NoError(ring.AddKey("new", PrivateKey{0xb1}, PublicKey{0xbb}))
+	require.NoError(ring.AddKey("no-public-key", PrivateKey{0xc1}, nil))
+
+	id, err := ring.KeyIDOfAddress(builder, "0xAA")
+	require.NoError(err)
+	require.Equal(KeyID("old"), id)
+	// hex addresses are case insensitive
+	id, err = ring.KeyIDOfAddress(builder, "0xbb")
+	require.NoError(err)
+	require.Equal(KeyID("new"), id)
+	_, err = ring.KeyIDOfAddress(builder, "0xcc")
+	require.ErrorContains(err, "doesn't belong to any key")
+
+	require.NoError(ring.ConfirmAddress(builder, "new", "0xBB"))
+	require.ErrorContains(ring.ConfirmAddress(builder, "old", "0xBB"), "belongs to key 'new', not 'old'")
+
+	publicKey, err := ring.PublicKey("old")
+	require.NoError(err)
+	require.Equal(PublicKey{0xaa}, publicKey)
+	_, err = ring.PublicKey("missing")
+	require.ErrorIs(err, ErrUnknownKeyID)
+}
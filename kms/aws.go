@@ -0,0 +1,55 @@
+package kms
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+)
+
+// AWSAPI is the part of the AWS KMS API used by AWSBackend, e.g. *kms.KMS
+type AWSAPI interface {
+	SignWithContext(ctx aws.Context, input *awskms.SignInput, opts ...request.Option) (*awskms.SignOutput, error)
+	GetPublicKeyWithContext(ctx aws.Context, input *awskms.GetPublicKeyInput, opts ...request.Option) (*awskms.GetPublicKeyOutput, error)
+}
+
+var _ AWSAPI = &awskms.KMS{}
+
+// AWSBackend signs with AWS KMS keys of spec ECC_SECG_P256K1
+// Key ids are key ids, key ARNs, alias names or alias ARNs
+type AWSBackend struct {
+	API AWSAPI
+}
+
+var _ Backend = &AWSBackend{}
+
+// NewAWSBackend creates a new AWSBackend, e.g. of kms.New(session.Must(session.NewSession()))
+func NewAWSBackend(api AWSAPI) *AWSBackend {
+	return &AWSBackend{API: api}
+}
+
+// SignDigest signs a digest with ECDSA_SHA_256, without hashing it again
+func (backend *AWSBackend) SignDigest(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	output, err := backend.API.SignWithContext(ctx, &awskms.SignInput{
+		KeyId:            aws.String(keyID),
+		Message:          digest,
+		MessageType:      aws.String(awskms.MessageTypeDigest),
+		SigningAlgorithm: aws.String(awskms.SigningAlgorithmSpecEcdsaSha256),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.Signature, nil
+}
+
+// GetPublicKey returns the public key of a key
+func (backend *AWSBackend) GetPublicKey(ctx context.Context, keyID string) ([]byte, error) {
+	output, err := backend.API.GetPublicKeyWithContext(ctx, &awskms.GetPublicKeyInput{
+		KeyId: aws.String(keyID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.PublicKey, nil
+}
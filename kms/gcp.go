@@ -0,0 +1,98 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// DefaultGCPURL is the URL of the GCP Cloud KMS REST API
+const DefaultGCPURL = "https://cloudkms.googleapis.com/v1"
+
+// GCPBackend signs with GCP Cloud KMS keys of algorithm EC_SIGN_SECP256K1_SHA256, with the REST API
+// Key ids are the resource names of key versions, projects/*/locations/*/keyRings/*/cryptoKeys/*/cryptoKeyVersions/*
+type GCPBackend struct {
+	// Client authenticates requests, e.g. the client of google.DefaultClient with the cloudkms scope
+	Client  *http.Client
+	BaseURL string
+}
+
+var _ Backend = &GCPBackend{}
+
+// NewGCPBackend creates a new GCPBackend of an authenticated client
+func NewGCPBackend(client *http.Client) *GCPBackend {
+	return &GCPBackend{
+		Client:  client,
+		BaseURL: DefaultGCPURL,
+	}
+}
+
+// do sends a JSON request and decodes the JSON response into result
+func (backend *GCPBackend) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(backend.BaseURL, "/")+"/"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := backend.Client.Do(req)
+	if err != nil {
+		return xc.DefaultRedactor.RedactError(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, xc.DefaultRedactor.Redact(string(data)))
+	}
+	return json.Unmarshal(data, result)
+}
+
+// SignDigest signs a sha256 sized digest, without hashing it again
+func (backend *GCPBackend) SignDigest(ctx context.Context, keyID string, digest []byte) ([]byte, error) {
+	request := map[string]interface{}{
+		"digest": map[string]string{
+			"sha256": base64.StdEncoding.EncodeToString(digest),
+		},
+	}
+	var response struct {
+		Signature string `json:"signature"`
+	}
+	if err := backend.do(ctx, http.MethodPost, keyID+":asymmetricSign", request, &response); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(response.Signature)
+}
+
+// GetPublicKey returns the public key of a key version
+func (backend *GCPBackend) GetPublicKey(ctx context.Context, keyID string) ([]byte, error) {
+	var response struct {
+		Pem string `json:"pem"`
+	}
+	if err := backend.do(ctx, http.MethodGet, keyID+"/publicKey", nil, &response); err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(response.Pem))
+	if block == nil {
+		return nil, errors.New("not PEM encoded")
+	}
+	return block.Bytes, nil
+}
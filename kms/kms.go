@@ -0,0 +1,129 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/custody"
+)
+
+// DefaultTimeout of KMS requests
+const DefaultTimeout = 30 * time.Second
+
+// Backend is a KMS holding asymmetric secp256k1 keys, e.g. AWS KMS or GCP Cloud KMS
+type Backend interface {
+	// SignDigest returns the ASN.1 DER ECDSA signature of a 32 bytes digest with the key keyID
+	SignDigest(ctx context.Context, keyID string, digest []byte) ([]byte, error)
+	// GetPublicKey returns the DER encoded SubjectPublicKeyInfo of the key keyID
+	GetPublicKey(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// Signer signs with secp256k1 keys held by a KMS, keys never leave the KMS
+// The private keys it's given are the ids of KMS keys, see ImportPrivateKey
+// KMS signatures are normalized to a low S and, for chains expecting it, completed with the recovery id
+type Signer struct {
+	Backend Backend
+	// Recoverable appends the recovery id to signatures, as expected by EVM and Bitcoin txs
+	Recoverable bool
+	// Timeout of each KMS request, DefaultTimeout if 0
+	Timeout time.Duration
+
+	mu         sync.Mutex
+	publicKeys map[string]xc.PublicKey
+}
+
+var _ xc.Signer = &Signer{}
+var _ xc.PublicKeyDeriver = &Signer{}
+
+// NewSigner creates a Signer for the chain of asset, ed25519 chains aren't supported
+func NewSigner(backend Backend, asset xc.ITask) (*Signer, error) {
+	format, err := custody.NewSignatureFormat(asset)
+	if err != nil {
+		return nil, err
+	}
+	if format.Algorithm != xc.K256 {
+		return nil, fmt.Errorf("unsupported driver for KMS signing: '%s'", asset.GetDriver())
+	}
+	return &Signer{
+		Backend:     backend,
+		Recoverable: format.Recoverable,
+		publicKeys:  map[string]xc.PublicKey{},
+	}, nil
+}
+
+// ImportPrivateKey returns a reference to the KMS key privateKey, e.g. a key id, alias or resource name
+func (signer *Signer) ImportPrivateKey(privateKey string) (xc.PrivateKey, error) {
+	keyID := strings.TrimSpace(privateKey)
+	if keyID == "" {
+		return nil, fmt.Errorf("invalid KMS key id: '%s'", privateKey)
+	}
+	return xc.PrivateKey(keyID), nil
+}
+
+func (signer *Signer) context() (context.Context, context.CancelFunc) {
+	timeout := signer.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// Sign signs the sighash data with the KMS key referenced by privateKey
+// Signatures are R || S with a low S, followed by the recovery id if Recoverable
+func (signer *Signer) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	keyID := string(privateKey)
+	if len(data) != 32 {
+		return nil, fmt.Errorf("invalid sighash length: %d", len(data))
+	}
+	ctx, cancel := signer.context()
+	defer cancel()
+	der, err := signer.Backend.SignDigest(ctx, keyID, data)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign with KMS key '%s': %v", keyID, err)
+	}
+	signature, err := custody.ParseDERSignature(der)
+	if err != nil {
+		return nil, err
+	}
+	if !signer.Recoverable {
+		return xc.TxSignature(signature), nil
+	}
+	publicKey, err := signer.PublicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return custody.RecoverableSignature(signature, data, publicKey)
+}
+
+// PublicKey returns the compressed public key of a KMS key, fetched once
+func (signer *Signer) PublicKey(keyID string) (xc.PublicKey, error) {
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+	if publicKey, ok := signer.publicKeys[keyID]; ok {
+		return publicKey, nil
+	}
+	ctx, cancel := signer.context()
+	defer cancel()
+	der, err := signer.Backend.GetPublicKey(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get public key of KMS key '%s': %v", keyID, err)
+	}
+	publicKey, err := custody.ParsePublicKeyInfo(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of KMS key '%s': %v", keyID, err)
+	}
+	if signer.publicKeys == nil {
+		signer.publicKeys = map[string]xc.PublicKey{}
+	}
+	signer.publicKeys[keyID] = publicKey
+	return publicKey, nil
+}
+
+// DerivePublicKey returns the public key of the KMS key referenced by privateKey, see PublicKey
+func (signer *Signer) DerivePublicKey(privateKey xc.PrivateKey) (xc.PublicKey, error) {
+	return signer.PublicKey(string(privateKey))
+}
@@ -0,0 +1,211 @@
+package kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+}
+
+func TestKMSTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+// secp256k1PublicKeyInfo returns the DER encoded SubjectPublicKeyInfo of a secp256k1 public key
+func secp256k1PublicKeyInfo(key *ecdsa.PrivateKey) []byte {
+	publicKey := crypto.FromECDSAPub(&key.PublicKey)
+	info, _ := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1},
+			Parameters: asn1.RawValue{FullBytes: []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}},
+		},
+		PublicKey: asn1.BitString{Bytes: publicKey, BitLength: len(publicKey) * 8},
+	})
+	return info
+}
+
+// derSignature signs like a KMS: DER encoded, and S is high if highS
+func derSignature(key *ecdsa.PrivateKey, digest []byte, highS bool) []byte {
+	signature, _ := crypto.Sign(digest, key)
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:64])
+	if highS {
+		s = new(big.Int).Sub(crypto.S256().Params().N, s)
+	}
+	der, _ := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	return der
+}
+
+// mockAWS signs like AWS KMS, with a key held in memory
+type mockAWS struct {
+	key        *ecdsa.PrivateKey
+	highS      bool
+	signInputs []*awskms.SignInput
+	reads      int
+	err        error
+}
+
+var _ AWSAPI = &mockAWS{}
+
+func (m *mockAWS) SignWithContext(ctx aws.Context, input *awskms.SignInput, opts ...request.Option) (*awskms.SignOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.signInputs = append(m.signInputs, input)
+	return &awskms.SignOutput{
+		KeyId:     input.KeyId,
+		Signature: derSignature(m.key, input.Message, m.highS),
+	}, nil
+}
+
+func (m *mockAWS) GetPublicKeyWithContext(ctx aws.Context, input *awskms.GetPublicKeyInput, opts ...request.Option) (*awskms.GetPublicKeyOutput, error) {
+	m.reads++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &awskms.GetPublicKeyOutput{
+		KeyId:     input.KeyId,
+		PublicKey: secp256k1PublicKeyInfo(m.key),
+	}, nil
+}
+
+func (s *CrosschainTestSuite) TestAWSSigner() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	hash := crypto.Keccak256([]byte("tx"))
+	expected, _ := crypto.Sign(hash, key)
+
+	api := &mockAWS{key: key}
+	signer, err := NewSigner(NewAWSBackend(api), &xc.AssetConfig{Driver: string(xc.DriverEVM)})
+	require.NoError(err)
+	require.True(signer.Recoverable)
+	privateKey, err := signer.ImportPrivateKey(" alias/hot ")
+	require.NoError(err)
+	require.Equal(xc.PrivateKey("alias/hot"), privateKey)
+
+	signature, err := signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected), hex.EncodeToString(signature))
+	require.Equal("alias/hot", *api.signInputs[0].KeyId)
+	require.Equal(awskms.MessageTypeDigest, *api.signInputs[0].MessageType)
+	require.Equal(awskms.SigningAlgorithmSpecEcdsaSha256, *api.signInputs[0].SigningAlgorithm)
+
+	// high S is normalized
+	api.highS = true
+	signature, err = signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected), hex.EncodeToString(signature))
+
+	publicKey, err := signer.DerivePublicKey(privateKey)
+	require.NoError(err)
+	require.Equal(crypto.CompressPubkey(&key.PublicKey), []byte(publicKey))
+	require.Equal(1, api.reads)
+
+	// cosmos signatures have no recovery id
+	signer, err = NewSigner(NewAWSBackend(api), &xc.AssetConfig{Driver: string(xc.DriverCosmos)})
+	require.NoError(err)
+	require.False(signer.Recoverable)
+	signature, err = signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected[:64]), hex.EncodeToString(signature))
+
+	// as a KeySigner
+	local, err := xc.NewLocalSigner(signer, privateKey)
+	require.NoError(err)
+	signature, err = local.Sign(hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected[:64]), hex.EncodeToString(signature))
+}
+
+func (s *CrosschainTestSuite) TestAWSSignerErrors() {
+	require := s.Require()
+	key, _ := crypto.GenerateKey()
+	api := &mockAWS{key: key}
+
+	_, err := NewSigner(NewAWSBackend(api), &xc.AssetConfig{Driver: string(xc.DriverSolana)})
+	require.EqualError(err, "unsupported driver for KMS signing: 'solana'")
+
+	signer, _ := NewSigner(NewAWSBackend(api), &xc.AssetConfig{Driver: string(xc.DriverEVM)})
+	_, err = signer.ImportPrivateKey(" ")
+	require.EqualError(err, "invalid KMS key id: ' '")
+	_, err = signer.Sign(xc.PrivateKey("hot"), []byte("not a hash"))
+	require.EqualError(err, "invalid sighash length: 10")
+
+	// signed by another key
+	other, _ := crypto.GenerateKey()
+	signer.publicKeys["hot"] = crypto.CompressPubkey(&other.PublicKey)
+	_, err = signer.Sign(xc.PrivateKey("hot"), crypto.Keccak256([]byte("tx")))
+	require.ErrorContains(err, "doesn't match the public key")
+
+	api.err = errors.New("AccessDeniedException")
+	_, err = signer.Sign(xc.PrivateKey("hot"), crypto.Keccak256([]byte("tx")))
+	require.EqualError(err, "could not sign with KMS key 'hot': AccessDeniedException")
+	_, err = signer.PublicKey("cold")
+	require.EqualError(err, "could not get public key of KMS key 'cold': AccessDeniedException")
+}
+
+func (s *CrosschainTestSuite) TestGCPSigner() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	hash := crypto.Keccak256([]byte("tx"))
+	expected, _ := crypto.Sign(hash, key)
+	keyID := "projects/p/locations/global/keyRings/r/cryptoKeys/hot/cryptoKeyVersions/1"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/"+keyID+":asymmetricSign":
+			var request struct {
+				Digest struct {
+					Sha256 []byte `json:"sha256"`
+				} `json:"digest"`
+			}
+			json.NewDecoder(r.Body).Decode(&request)
+			json.NewEncoder(w).Encode(map[string]string{
+				"name":      keyID,
+				"signature": base64.StdEncoding.EncodeToString(derSignature(key, request.Digest.Sha256, true)),
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/"+keyID+"/publicKey":
+			json.NewEncoder(w).Encode(map[string]string{
+				"algorithm": "EC_SIGN_SECP256K1_SHA256",
+				"pem":       string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: secp256k1PublicKeyInfo(key)})),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	backend := NewGCPBackend(server.Client())
+	backend.BaseURL = server.URL + "/v1/"
+	signer, err := NewSigner(backend, &xc.AssetConfig{Driver: string(xc.DriverBitcoin)})
+	require.NoError(err)
+	signature, err := signer.Sign(xc.PrivateKey(keyID), hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected), hex.EncodeToString(signature))
+
+	_, err = signer.Sign(xc.PrivateKey("projects/p/unknown"), hash)
+	require.ErrorContains(err, "could not sign with KMS key 'projects/p/unknown': POST projects/p/unknown:asymmetricSign returned 404 Not Found")
+}
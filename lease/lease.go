@@ -0,0 +1,113 @@
+package lease
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLeaseHeld is returned when acquiring a lease held by another owner
+var ErrLeaseHeld = errors.New("lease is held by another owner")
+
+// ErrLeaseLost is returned when renewing or releasing a lease that expired or was taken over
+var ErrLeaseLost = errors.New("lease was lost")
+
+// Lease is an exclusive, expiring right to act on a key, e.g. to broadcast a transfer
+type Lease interface {
+	Key() string
+	// Renew extends the lease by its ttl
+	Renew(ctx context.Context) error
+	Release(ctx context.Context) error
+}
+
+// Locker acquires leases shared across replicas of a service
+type Locker interface {
+	// Acquire acquires the lease on key for ttl, or returns ErrLeaseHeld
+	Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error)
+}
+
+// DefaultTTL is the ttl of the leases of orchestrators without a ttl set
+const DefaultTTL = 30 * time.Second
+
+// TransferKey returns the lease key of a transfer
+func TransferKey(transferID string) string {
+	return "transfer/" + transferID
+}
+
+// SagaKey returns the lease key of a saga
+func SagaKey(sagaID string) string {
+	return "saga/" + sagaID
+}
+
+// AtomicKey returns the lease key of an atomic transfer, distinct from TransferKey so that the replica leasing a
+// queued transfer can run its atomic transfer
+func AtomicKey(transferID string) string {
+	return "atomic/" + transferID
+}
+
+// IntentKey returns the lease key of an intent while it's handed to the orchestrator
+func IntentKey(intentID string) string {
+	return "intent/" + intentID
+}
+
+// newToken returns a random token identifying the owner of a lease
+func newToken() string {
+	token := make([]byte, 16)
+	_, _ = rand.Read(token)
+	return hex.EncodeToString(token)
+}
+
+// WithLease runs fn while holding the lease on key, renewing it every ttl/2
+// The context passed to fn is cancelled if the lease is lost
+func WithLease(ctx context.Context, locker Locker, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	if ttl <= 0 {
+		return fmt.Errorf("invalid lease ttl: %v", ttl)
+	}
+	lease, err := locker.Acquire(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	leaseCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// renewErr is only written by the renew goroutine and read once it's done
+	var renewErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-leaseCtx.Done():
+				return
+			case <-ticker.C:
+				if err := lease.Renew(leaseCtx); err != nil {
+					// a renew interrupted by fn returning or by the parent context isn't a lost lease
+					if leaseCtx.Err() == nil {
+						renewErr = err
+						cancel()
+					}
+					return
+				}
+			}
+		}
+	}()
+
+	err = fn(leaseCtx)
+	cancel()
+	// don't release while a renew is in flight
+	<-done
+	// release with the parent context: leaseCtx is done
+	releaseErr := lease.Release(ctx)
+	if err == nil && renewErr != nil {
+		err = renewErr
+	}
+	if err == nil && releaseErr != nil && !errors.Is(releaseErr, ErrLeaseLost) {
+		err = releaseErr
+	}
+	return err
+}
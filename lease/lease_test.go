@@ -0,0 +1,131 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestLeaseTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func (s *CrosschainTestSuite) TestTransferKey() {
+	require := s.Require()
+	require.Equal("transfer/t1", TransferKey("t1"))
+	require.Equal("saga/s1", SagaKey("s1"))
+	require.Equal("atomic/t1", AtomicKey("t1"))
+	require.Equal("intent/i1", IntentKey("i1"))
+}
+
+func (s *CrosschainTestSuite) TestWithLease() {
+	require := s.Require()
+	locker := NewMemoryLocker()
+
+	// the lease is renewed while fn runs longer than its ttl
+	err := WithLease(s.Ctx, locker, "key", 20*time.Millisecond, func(ctx context.Context) error {
+		_, err := locker.Acquire(ctx, "key", time.Second)
+		require.ErrorIs(err, ErrLeaseHeld)
+		time.Sleep(50 * time.Millisecond)
+		_, err = locker.Acquire(ctx, "key", time.Second)
+		require.ErrorIs(err, ErrLeaseHeld)
+		return nil
+	})
+	require.NoError(err)
+
+	// released once fn returns
+	lease, err := locker.Acquire(s.Ctx, "key", time.Second)
+	require.NoError(err)
+	err = WithLease(s.Ctx, locker, "key", time.Second, func(ctx context.Context) error {
+		return nil
+	})
+	require.ErrorIs(err, ErrLeaseHeld)
+	require.NoError(lease.Release(s.Ctx))
+
+	err = WithLease(s.Ctx, locker, "key", time.Second, func(ctx context.Context) error {
+		return errors.New("broadcast failed")
+	})
+	require.EqualError(err, "broadcast failed")
+}
+
+func (s *CrosschainTestSuite) TestWithLeaseLost() {
+	require := s.Require()
+	locker := NewMemoryLocker()
+	err := WithLease(s.Ctx, locker, "key", 20*time.Millisecond, func(ctx context.Context) error {
+		// another owner takes over
+		locker.mu.Lock()
+		locker.leases["key"] = memoryEntry{token: "other", expires: time.Now().Add(time.Hour)}
+		locker.mu.Unlock()
+		<-ctx.Done()
+		return nil
+	})
+	require.ErrorIs(err, ErrLeaseLost)
+}
+
+func (s *CrosschainTestSuite) TestWithLeaseInvalidTTL() {
+	require := s.Require()
+	called := false
+	err := WithLease(s.Ctx, NewMemoryLocker(), "key", 0, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	require.EqualError(err, "invalid lease ttl: 0s")
+	require.False(called)
+}
+
+// slowRenewLease is a lease whose Renew blocks until its context is done
+type slowRenewLease struct {
+	renewing chan struct{}
+	renewed  bool
+	released bool
+}
+
+func (lease *slowRenewLease) Key() string {
+	return "key"
+}
+
+func (lease *slowRenewLease) Renew(ctx context.Context) error {
+	lease.renewed = true
+	close(lease.renewing)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (lease *slowRenewLease) Release(ctx context.Context) error {
+	lease.released = true
+	return nil
+}
+
+type slowRenewLocker struct {
+	lease *slowRenewLease
+}
+
+func (l *slowRenewLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	return l.lease, nil
+}
+
+func (s *CrosschainTestSuite) TestWithLeaseRenewInFlight() {
+	require := s.Require()
+	lease := &slowRenewLease{renewing: make(chan struct{})}
+	// fn returns while a renew is in flight: it isn't reported as a lost lease,
+	// and the lease is only released once the renew returned
+	err := WithLease(s.Ctx, &slowRenewLocker{lease}, "key", 10*time.Millisecond, func(ctx context.Context) error {
+		<-lease.renewing
+		return nil
+	})
+	require.NoError(err)
+	require.True(lease.renewed)
+	require.True(lease.released)
+}
@@ -0,0 +1,76 @@
+package lease
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLocker is a Locker for a single process, e.g. for tests
+type MemoryLocker struct {
+	mu     sync.Mutex
+	leases map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	token   string
+	expires time.Time
+}
+
+var _ Locker = &MemoryLocker{}
+
+// NewMemoryLocker creates a new MemoryLocker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		leases: map[string]memoryEntry{},
+	}
+}
+
+// Acquire acquires the lease on key for ttl
+func (l *MemoryLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry, ok := l.leases[key]; ok && time.Now().Before(entry.expires) {
+		return nil, ErrLeaseHeld
+	}
+	lease := &memoryLease{locker: l, key: key, token: newToken(), ttl: ttl}
+	l.leases[key] = memoryEntry{token: lease.token, expires: time.Now().Add(ttl)}
+	return lease, nil
+}
+
+type memoryLease struct {
+	locker *MemoryLocker
+	key    string
+	token  string
+	ttl    time.Duration
+}
+
+func (lease *memoryLease) Key() string {
+	return lease.key
+}
+
+// owned returns true if the lease is still held by its owner, must be called with the lock held
+func (lease *memoryLease) owned() bool {
+	entry, ok := lease.locker.leases[lease.key]
+	return ok && entry.token == lease.token && time.Now().Before(entry.expires)
+}
+
+func (lease *memoryLease) Renew(ctx context.Context) error {
+	lease.locker.mu.Lock()
+	defer lease.locker.mu.Unlock()
+	if !lease.owned() {
+		return ErrLeaseLost
+	}
+	lease.locker.leases[lease.key] = memoryEntry{token: lease.token, expires: time.Now().Add(lease.ttl)}
+	return nil
+}
+
+func (lease *memoryLease) Release(ctx context.Context) error {
+	lease.locker.mu.Lock()
+	defer lease.locker.mu.Unlock()
+	if !lease.owned() {
+		return ErrLeaseLost
+	}
+	delete(lease.locker.leases, lease.key)
+	return nil
+}
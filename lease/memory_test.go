@@ -0,0 +1,29 @@
+package lease
+
+import (
+	"time"
+)
+
+func (s *CrosschainTestSuite) TestMemoryLocker() {
+	require := s.Require()
+	locker := NewMemoryLocker()
+
+	lease, err := locker.Acquire(s.Ctx, "key", 20*time.Millisecond)
+	require.NoError(err)
+	require.Equal("key", lease.Key())
+	_, err = locker.Acquire(s.Ctx, "key", time.Second)
+	require.ErrorIs(err, ErrLeaseHeld)
+	_, err = locker.Acquire(s.Ctx, "other", time.Second)
+	require.NoError(err)
+	require.NoError(lease.Renew(s.Ctx))
+
+	// expired leases can be taken over
+	time.Sleep(30 * time.Millisecond)
+	lease2, err := locker.Acquire(s.Ctx, "key", time.Second)
+	require.NoError(err)
+	require.ErrorIs(lease.Renew(s.Ctx), ErrLeaseLost)
+	require.ErrorIs(lease.Release(s.Ctx), ErrLeaseLost)
+	require.NoError(lease2.Release(s.Ctx))
+	_, err = locker.Acquire(s.Ctx, "key", time.Second)
+	require.NoError(err)
+}
@@ -0,0 +1,92 @@
+package lease
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"time"
+)
+
+// PostgresLocker is a Locker using Postgres session-level advisory locks
+// Each lease holds a dedicated connection: the lock is released if the connection drops,
+// so the ttl is not enforced and Renew only checks the connection
+type PostgresLocker struct {
+	DB *sql.DB
+}
+
+var _ Locker = &PostgresLocker{}
+
+// releaseTimeout bounds the unlock of an advisory lock on Release
+const releaseTimeout = 5 * time.Second
+
+// NewPostgresLocker creates a new PostgresLocker
+func NewPostgresLocker(db *sql.DB) *PostgresLocker {
+	return &PostgresLocker{
+		DB: db,
+	}
+}
+
+// advisoryLockID maps a key to the bigint id of an advisory lock
+func advisoryLockID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// Acquire acquires the advisory lock of key
+func (l *PostgresLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	conn, err := l.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", advisoryLockID(key)).Scan(&acquired)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !acquired {
+		conn.Close()
+		return nil, ErrLeaseHeld
+	}
+	return &postgresLease{conn: conn, key: key}, nil
+}
+
+type postgresLease struct {
+	conn *sql.Conn
+	key  string
+}
+
+func (lease *postgresLease) Key() string {
+	return lease.key
+}
+
+func (lease *postgresLease) Renew(ctx context.Context) error {
+	if err := lease.conn.PingContext(ctx); err != nil {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release unlocks the advisory lock with its own timeout, so that a cancelled ctx doesn't leave the lock
+// held on a pooled connection. If the unlock fails the connection is discarded, which ends its session and lock.
+func (lease *postgresLease) Release(ctx context.Context) error {
+	unlockCtx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	var released bool
+	err := lease.conn.QueryRowContext(unlockCtx, "SELECT pg_advisory_unlock($1)", advisoryLockID(lease.key)).Scan(&released)
+	if err != nil || !released {
+		_ = lease.conn.Raw(func(interface{}) error {
+			return driver.ErrBadConn
+		})
+	}
+	lease.conn.Close()
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrLeaseLost
+	}
+	return nil
+}
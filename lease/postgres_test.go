@@ -0,0 +1,102 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func (s *CrosschainTestSuite) TestAdvisoryLockID() {
+	require := s.Require()
+	require.Equal(advisoryLockID("transfer/t1"), advisoryLockID("transfer/t1"))
+	require.NotEqual(advisoryLockID("transfer/t1"), advisoryLockID("transfer/t2"))
+}
+
+func (s *CrosschainTestSuite) TestPostgresLocker() {
+	require := s.Require()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(err)
+	defer db.Close()
+	locker := NewPostgresLocker(db)
+	id := advisoryLockID("transfer/t1")
+
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(\$1\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	lease, err := locker.Acquire(s.Ctx, "transfer/t1", time.Minute)
+	require.NoError(err)
+	require.Equal("transfer/t1", lease.Key())
+
+	mock.ExpectPing()
+	require.NoError(lease.Renew(s.Ctx))
+	mock.ExpectPing().WillReturnError(errors.New("connection reset"))
+	require.ErrorIs(lease.Renew(s.Ctx), ErrLeaseLost)
+
+	mock.ExpectQuery(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_advisory_unlock"}).AddRow(true))
+	require.NoError(lease.Release(s.Ctx))
+	require.NoError(mock.ExpectationsWereMet())
+}
+
+func (s *CrosschainTestSuite) TestPostgresLockerHeld() {
+	require := s.Require()
+	db, mock, err := sqlmock.New()
+	require.NoError(err)
+	defer db.Close()
+	locker := NewPostgresLocker(db)
+	id := advisoryLockID("transfer/t1")
+
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(\$1\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+	_, err = locker.Acquire(s.Ctx, "transfer/t1", time.Minute)
+	require.ErrorIs(err, ErrLeaseHeld)
+
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(\$1\)`).WithArgs(id).
+		WillReturnError(errors.New("connection refused"))
+	_, err = locker.Acquire(s.Ctx, "transfer/t1", time.Minute)
+	require.EqualError(err, "connection refused")
+
+	// the session of the lock ended, e.g. the connection dropped
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(\$1\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	lease, err := locker.Acquire(s.Ctx, "transfer/t1", time.Minute)
+	require.NoError(err)
+	mock.ExpectQuery(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_advisory_unlock"}).AddRow(false))
+	require.ErrorIs(lease.Release(s.Ctx), ErrLeaseLost)
+	require.NoError(mock.ExpectationsWereMet())
+}
+
+func (s *CrosschainTestSuite) TestPostgresLockerReleaseCancelled() {
+	require := s.Require()
+	db, mock, err := sqlmock.New()
+	require.NoError(err)
+	defer db.Close()
+	locker := NewPostgresLocker(db)
+	id := advisoryLockID("transfer/t1")
+
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(\$1\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	lease, err := locker.Acquire(s.Ctx, "transfer/t1", time.Minute)
+	require.NoError(err)
+
+	// the lock is still released, and the connection pooled
+	ctx, cancel := context.WithCancel(s.Ctx)
+	cancel()
+	mock.ExpectQuery(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_advisory_unlock"}).AddRow(true))
+	require.NoError(lease.Release(ctx))
+	require.Equal(1, db.Stats().Idle)
+
+	// a failed unlock discards the connection instead of pooling it with the lock held
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(\$1\)`).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	lease, err = locker.Acquire(s.Ctx, "transfer/t1", time.Minute)
+	require.NoError(err)
+	mock.ExpectQuery(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(id).
+		WillReturnError(errors.New("connection reset"))
+	require.EqualError(lease.Release(s.Ctx), "connection reset")
+	require.Equal(0, db.Stats().Idle)
+	require.NoError(mock.ExpectationsWereMet())
+}
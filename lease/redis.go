@@ -0,0 +1,171 @@
+package lease
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Lua scripts checking the owner of a lease before changing it
+const (
+	redisReleaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+	redisRenewScript   = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+)
+
+// RedisLocker is a Locker using Redis keys with an expiry (single instance locking)
+type RedisLocker struct {
+	Addr     string
+	Password string
+	// Prefix of the redis keys of leases
+	Prefix  string
+	Timeout time.Duration
+}
+
+var _ Locker = &RedisLocker{}
+
+// NewRedisLocker creates a new RedisLocker
+func NewRedisLocker(addr string, password string) *RedisLocker {
+	return &RedisLocker{
+		Addr:     addr,
+		Password: password,
+		Prefix:   "xc:lease:",
+		Timeout:  5 * time.Second,
+	}
+}
+
+// do sends a command to redis and returns its reply
+func (l *RedisLocker) do(ctx context.Context, args ...string) (interface{}, error) {
+	dialer := net.Dialer{Timeout: l.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", l.Addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(l.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	reader := bufio.NewReader(conn)
+	if l.Password != "" {
+		if _, err := conn.Write(encodeRedisCommand("AUTH", l.Password)); err != nil {
+			return nil, err
+		}
+		if _, err := readRedisReply(reader); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := conn.Write(encodeRedisCommand(args...)); err != nil {
+		return nil, err
+	}
+	return readRedisReply(reader)
+}
+
+// Acquire sets the key of the lease if it doesn't exist
+func (l *RedisLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	token := newToken()
+	reply, err := l.do(ctx, "SET", l.Prefix+key, token, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrLeaseHeld
+	}
+	return &redisLease{locker: l, key: key, token: token, ttl: ttl}, nil
+}
+
+type redisLease struct {
+	locker *RedisLocker
+	key    string
+	token  string
+	ttl    time.Duration
+}
+
+func (lease *redisLease) Key() string {
+	return lease.key
+}
+
+func (lease *redisLease) eval(ctx context.Context, script string, args ...string) error {
+	cmd := append([]string{"EVAL", script, "1", lease.locker.Prefix + lease.key, lease.token}, args...)
+	reply, err := lease.locker.do(ctx, cmd...)
+	if err != nil {
+		return err
+	}
+	if n, ok := reply.(int64); !ok || n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (lease *redisLease) Renew(ctx context.Context) error {
+	return lease.eval(ctx, redisRenewScript, strconv.FormatInt(lease.ttl.Milliseconds(), 10))
+}
+
+func (lease *redisLease) Release(ctx context.Context) error {
+	return lease.eval(ctx, redisReleaseScript)
+}
+
+// encodeRedisCommand encodes a command as a RESP array of bulk strings
+func encodeRedisCommand(args ...string) []byte {
+	buf := []byte(fmt.Sprintf("*%d\r\n", len(args)))
+	for _, arg := range args {
+		buf = append(buf, fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)...)
+	}
+	return buf
+}
+
+// readRedisReply reads a RESP reply: string, int64, nil, []interface{} or an error
+func readRedisReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("invalid redis reply")
+	}
+	value := line[1 : len(line)-2]
+	switch line[0] {
+	case '+':
+		return value, nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", value)
+	case ':':
+		return strconv.ParseInt(value, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, size)
+		for i := range items {
+			items[i], err = readRedisReply(reader)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("invalid redis reply type: %q", line[0])
+}
@@ -0,0 +1,129 @@
+package lease
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeRedis serves the subset of redis commands used by RedisLocker
+type fakeRedis struct {
+	listener net.Listener
+	mu       sync.Mutex
+	values   map[string]string
+	expires  map[string]time.Time
+	commands []string
+}
+
+func newFakeRedis() *fakeRedis {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	server := &fakeRedis{listener: listener, values: map[string]string{}, expires: map[string]time.Time{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (r *fakeRedis) get(key string) (string, bool) {
+	value, ok := r.values[key]
+	if ok && time.Now().After(r.expires[key]) {
+		delete(r.values, key)
+		return "", false
+	}
+	return value, ok
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		reply, err := readRedisReply(reader)
+		if err != nil {
+			return
+		}
+		args := []string{}
+		for _, arg := range reply.([]interface{}) {
+			args = append(args, arg.(string))
+		}
+		r.mu.Lock()
+		r.commands = append(r.commands, args[0])
+		switch args[0] {
+		case "AUTH":
+			conn.Write([]byte("+OK\r\n"))
+		case "SET":
+			if _, ok := r.get(args[1]); ok {
+				conn.Write([]byte("$-1\r\n"))
+			} else {
+				ttl, _ := time.ParseDuration(args[5] + "ms")
+				r.values[args[1]] = args[2]
+				r.expires[args[1]] = time.Now().Add(ttl)
+				conn.Write([]byte("+OK\r\n"))
+			}
+		case "EVAL":
+			key, token := args[3], args[4]
+			if value, ok := r.get(key); !ok || value != token {
+				conn.Write([]byte(":0\r\n"))
+			} else if strings.Contains(args[1], "pexpire") {
+				ttl, _ := time.ParseDuration(args[5] + "ms")
+				r.expires[key] = time.Now().Add(ttl)
+				conn.Write([]byte(":1\r\n"))
+			} else {
+				delete(r.values, key)
+				conn.Write([]byte(":1\r\n"))
+			}
+		default:
+			conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+		r.mu.Unlock()
+	}
+}
+
+func (s *CrosschainTestSuite) TestRedisLocker() {
+	require := s.Require()
+	server := newFakeRedis()
+	defer server.listener.Close()
+	locker := NewRedisLocker(server.listener.Addr().String(), "secret")
+
+	lease, err := locker.Acquire(s.Ctx, "transfer/t1", time.Second)
+	require.NoError(err)
+	require.Equal("transfer/t1", lease.Key())
+	require.Contains(server.values, "xc:lease:transfer/t1")
+	_, err = locker.Acquire(s.Ctx, "transfer/t1", time.Second)
+	require.ErrorIs(err, ErrLeaseHeld)
+
+	require.NoError(lease.Renew(s.Ctx))
+	require.NoError(lease.Release(s.Ctx))
+	require.ErrorIs(lease.Release(s.Ctx), ErrLeaseLost)
+	require.ErrorIs(lease.Renew(s.Ctx), ErrLeaseLost)
+	require.Equal("AUTH", server.commands[0])
+
+	_, err = locker.Acquire(s.Ctx, "transfer/t1", time.Second)
+	require.NoError(err)
+}
+
+func (s *CrosschainTestSuite) TestRedisReply() {
+	require := s.Require()
+	vectors := map[string]interface{}{
+		"+OK\r\n":                 "OK",
+		":12\r\n":                 int64(12),
+		"$5\r\nhello\r\n":         "hello",
+		"$-1\r\n":                 nil,
+		"*2\r\n$1\r\na\r\n:1\r\n": []interface{}{"a", int64(1)},
+	}
+	for input, expected := range vectors {
+		reply, err := readRedisReply(bufio.NewReader(strings.NewReader(input)))
+		require.NoError(err)
+		require.Equal(expected, reply)
+	}
+	_, err := readRedisReply(bufio.NewReader(strings.NewReader("-ERR wrong type\r\n")))
+	require.EqualError(err, "redis: ERR wrong type")
+	require.Equal("*2\r\n$3\r\nGET\r\n$1\r\nk\r\n", string(encodeRedisCommand("GET", "k")))
+}
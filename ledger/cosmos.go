@@ -0,0 +1,127 @@
+package ledger
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/custody"
+)
+
+// DefaultCosmosPath is the derivation path of the first account of the Cosmos app
+const DefaultCosmosPath = "m/44'/118'/0'/0/0"
+
+// APDUs of the Cosmos app (app-cosmos)
+const (
+	cosmosCLA        = 0x55
+	cosmosSign       = 0x02
+	cosmosGetAddress = 0x04
+	// P1 of the chunks of a sign doc: the path, then the sign doc
+	cosmosChunkInit = 0x00
+	cosmosChunkAdd  = 0x01
+	cosmosChunkLast = 0x02
+	// cosmosChunkSize is the size of the chunks of a sign doc
+	cosmosChunkSize = 250
+)
+
+// CosmosSigner signs Cosmos txs with the Cosmos app, which parses and displays the sign doc to sign
+// The app only signs amino JSON sign docs: txs must be built with SIGN_MODE_LEGACY_AMINO_JSON
+// Chains with Ethereum keys (Evmos, Injective) can't be signed by the Cosmos app
+// It's a PayloadSigner: the app signs the payloads of txs, not their sighashes
+type CosmosSigner struct {
+	transport Transport
+	hrp       string
+	path      []uint32
+
+	mu        sync.Mutex
+	publicKey xc.PublicKey
+}
+
+var _ xc.PayloadSigner = &CosmosSigner{}
+
+// NewCosmosSigner creates a CosmosSigner with the key at path, DefaultCosmosPath if empty, for the chain of the
+// bech32 prefix hrp
+func NewCosmosSigner(transport Transport, hrp string, path string) (*CosmosSigner, error) {
+	parsed, err := parsePathOrDefault(path, DefaultCosmosPath)
+	if err != nil {
+		return nil, err
+	}
+	// the app only derives keys of 5 components
+	if len(parsed) != 5 {
+		return nil, fmt.Errorf("invalid derivation path for the Cosmos app: '%s'", path)
+	}
+	if hrp == "" {
+		return nil, errors.New("missing bech32 prefix of the chain")
+	}
+	return &CosmosSigner{
+		transport: transport,
+		hrp:       hrp,
+		path:      parsed,
+	}, nil
+}
+
+// encodedPath returns the path in the little endian encoding of the Cosmos app
+func (signer *CosmosSigner) encodedPath() []byte {
+	encoded := make([]byte, 4*len(signer.path))
+	for i, index := range signer.path {
+		binary.LittleEndian.PutUint32(encoded[4*i:], index)
+	}
+	return encoded
+}
+
+// PublicKey returns the compressed public key of the signer, fetched once
+func (signer *CosmosSigner) PublicKey() (xc.PublicKey, error) {
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+	if signer.publicKey != nil {
+		return signer.publicKey, nil
+	}
+	data := append(append([]byte{byte(len(signer.hrp))}, signer.hrp...), signer.encodedPath()...)
+	response, err := exchange(signer.transport, cosmosCLA, cosmosGetAddress, 0, 0, data)
+	if err != nil {
+		return nil, err
+	}
+	// compressed public key, bech32 address
+	if len(response) < 33 {
+		return nil, errors.New("ledger: invalid public key response")
+	}
+	signer.publicKey = xc.PublicKey(append([]byte{}, response[:33]...))
+	return signer.publicKey, nil
+}
+
+// Sign fails, the Cosmos app can't sign sighashes, see SignPayload
+func (signer *CosmosSigner) Sign(data xc.TxDataToSign) (xc.TxSignature, error) {
+	return nil, errors.New("ledger: the Cosmos app signs sign docs, not sighashes")
+}
+
+// SignPayload signs an amino JSON sign doc, after its confirmation on the device
+// Signatures are R || S with a low S
+func (signer *CosmosSigner) SignPayload(payload xc.TxDataToSign) (xc.TxSignature, error) {
+	if !json.Valid(payload) {
+		return nil, errors.New("ledger: the Cosmos app only signs amino JSON sign docs, build the tx with SIGN_MODE_LEGACY_AMINO_JSON")
+	}
+	_, err := exchange(signer.transport, cosmosCLA, cosmosSign, cosmosChunkInit, 0, signer.encodedPath())
+	if err != nil {
+		return nil, err
+	}
+	var response []byte
+	payloadChunks := chunks(payload, cosmosChunkSize)
+	for i, chunk := range payloadChunks {
+		p1 := byte(cosmosChunkAdd)
+		if i == len(payloadChunks)-1 {
+			p1 = cosmosChunkLast
+		}
+		response, err = exchange(signer.transport, cosmosCLA, cosmosSign, p1, 0, chunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	signature, err := custody.ParseDERSignature(response)
+	if err != nil {
+		return nil, err
+	}
+	return xc.TxSignature(signature), nil
+}
@@ -0,0 +1,106 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/custody"
+)
+
+// DefaultEthereumPath is the derivation path of the first account of Ledger Live
+const DefaultEthereumPath = "m/44'/60'/0'/0/0"
+
+// APDUs of the Ethereum app (app-ethereum)
+const (
+	ethereumCLA          = 0xe0
+	ethereumGetPublicKey = 0x02
+	ethereumSignTx       = 0x04
+	// ethereumMoreData is P1 of the chunks of a tx after the first
+	ethereumMoreData = 0x80
+)
+
+// EthereumSigner signs EVM txs with the Ethereum app, which parses and displays the unsigned tx to sign
+// It's a PayloadSigner: the app signs the payloads of txs, not their sighashes
+type EthereumSigner struct {
+	transport Transport
+	path      []uint32
+
+	mu        sync.Mutex
+	publicKey xc.PublicKey
+}
+
+var _ xc.PayloadSigner = &EthereumSigner{}
+
+// NewEthereumSigner creates an EthereumSigner with the key at path, DefaultEthereumPath if empty
+func NewEthereumSigner(transport Transport, path string) (*EthereumSigner, error) {
+	parsed, err := parsePathOrDefault(path, DefaultEthereumPath)
+	if err != nil {
+		return nil, err
+	}
+	return &EthereumSigner{
+		transport: transport,
+		path:      parsed,
+	}, nil
+}
+
+// PublicKey returns the compressed public key of the signer, fetched once
+func (signer *EthereumSigner) PublicKey() (xc.PublicKey, error) {
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+	if signer.publicKey != nil {
+		return signer.publicKey, nil
+	}
+	response, err := exchange(signer.transport, ethereumCLA, ethereumGetPublicKey, 0, 0, encodePath(signer.path))
+	if err != nil {
+		return nil, err
+	}
+	// public key length, uncompressed public key, address length, hex address
+	if len(response) < 1 || len(response) < 1+int(response[0]) {
+		return nil, errors.New("ledger: invalid public key response")
+	}
+	publicKey, err := crypto.UnmarshalPubkey(response[1 : 1+int(response[0])])
+	if err != nil {
+		return nil, fmt.Errorf("ledger: invalid public key: %v", err)
+	}
+	signer.publicKey = crypto.CompressPubkey(publicKey)
+	return signer.publicKey, nil
+}
+
+// Sign fails, the Ethereum app can't sign sighashes, see SignPayload
+func (signer *EthereumSigner) Sign(data xc.TxDataToSign) (xc.TxSignature, error) {
+	return nil, errors.New("ledger: the Ethereum app signs txs, not sighashes")
+}
+
+// SignPayload signs the unsigned encoding of a tx, after its confirmation on the device
+// Signatures are R || S || V, with the recovery id V of the keccak256 sighash of the payload
+func (signer *EthereumSigner) SignPayload(payload xc.TxDataToSign) (xc.TxSignature, error) {
+	publicKey, err := signer.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	data := append(encodePath(signer.path), payload...)
+	var response []byte
+	for i, chunk := range chunks(data, maxAPDUData) {
+		p1 := byte(0)
+		if i > 0 {
+			p1 = ethereumMoreData
+		}
+		response, err = exchange(signer.transport, ethereumCLA, ethereumSignTx, p1, 0, chunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// V, truncated for large chain ids, R, S
+	if len(response) != 65 {
+		return nil, errors.New("ledger: invalid signature response")
+	}
+	signature, err := custody.NormalizeSignature(new(big.Int).SetBytes(response[1:33]), new(big.Int).SetBytes(response[33:65]))
+	if err != nil {
+		return nil, err
+	}
+	return custody.RecoverableSignature(signature, crypto.Keccak256(payload), publicKey)
+}
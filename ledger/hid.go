@@ -0,0 +1,125 @@
+package ledger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/karalabe/usb"
+)
+
+// LedgerVendorID is the USB vendor id of Ledger devices
+const LedgerVendorID = 0x2c97
+
+// ledgerUsagePage is the HID usage page of the interface of the apps, on platforms reporting it
+const ledgerUsagePage = 0xffa0
+
+// HID framing of APDUs: packets of 64 bytes starting with the channel, the tag and the sequence index,
+// the first packet of an APDU followed by its length
+const (
+	hidPacketSize = 64
+	hidChannel    = 0x0101
+	hidTag        = 0x05
+)
+
+// HIDTransport exchanges APDUs with a Ledger device connected over USB
+type HIDTransport struct {
+	device io.ReadWriteCloser
+	mu     sync.Mutex
+}
+
+var _ Transport = &HIDTransport{}
+
+// OpenHID opens the first Ledger device connected, unlocked
+func OpenHID() (*HIDTransport, error) {
+	if !usb.Supported() {
+		return nil, errors.New("ledger: USB is not supported on this platform")
+	}
+	infos, err := usb.EnumerateHid(LedgerVendorID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("ledger: could not enumerate devices: %v", err)
+	}
+	for _, info := range infos {
+		// the interface of the apps is 0, or identified by its usage page on macOS and Windows
+		if info.Interface == 0 || info.UsagePage == ledgerUsagePage {
+			device, err := info.Open()
+			if err != nil {
+				return nil, fmt.Errorf("ledger: could not open device: %v", err)
+			}
+			return NewHIDTransport(device), nil
+		}
+	}
+	return nil, errors.New("ledger: no device found")
+}
+
+// NewHIDTransport creates an HIDTransport exchanging HID packets with device
+func NewHIDTransport(device io.ReadWriteCloser) *HIDTransport {
+	return &HIDTransport{device: device}
+}
+
+// Exchange sends a command APDU and returns the response APDU
+func (transport *HIDTransport) Exchange(apdu []byte) ([]byte, error) {
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	for _, packet := range wrapAPDU(apdu) {
+		if _, err := transport.device.Write(packet); err != nil {
+			return nil, fmt.Errorf("ledger: could not write to device: %v", err)
+		}
+	}
+	return readAPDU(transport.device)
+}
+
+// Close closes the device
+func (transport *HIDTransport) Close() error {
+	return transport.device.Close()
+}
+
+// wrapAPDU splits an APDU into HID packets
+func wrapAPDU(apdu []byte) [][]byte {
+	data := make([]byte, 2+len(apdu))
+	binary.BigEndian.PutUint16(data, uint16(len(apdu)))
+	copy(data[2:], apdu)
+	packets := [][]byte{}
+	for seq := 0; len(data) > 0 || seq == 0; seq++ {
+		packet := make([]byte, hidPacketSize)
+		binary.BigEndian.PutUint16(packet, hidChannel)
+		packet[2] = hidTag
+		binary.BigEndian.PutUint16(packet[3:], uint16(seq))
+		n := copy(packet[5:], data)
+		data = data[n:]
+		packets = append(packets, packet)
+	}
+	return packets
+}
+
+// readAPDU reads the HID packets of a response APDU
+func readAPDU(device io.Reader) ([]byte, error) {
+	var apdu []byte
+	length := -1
+	for seq := 0; length < 0 || len(apdu) < length; seq++ {
+		packet := make([]byte, hidPacketSize)
+		n, err := device.Read(packet)
+		if err != nil {
+			return nil, fmt.Errorf("ledger: could not read from device: %v", err)
+		}
+		packet = packet[:n]
+		if len(packet) < 5 || binary.BigEndian.Uint16(packet) != hidChannel || packet[2] != hidTag {
+			return nil, errors.New("ledger: invalid response packet")
+		}
+		if int(binary.BigEndian.Uint16(packet[3:])) != seq {
+			return nil, errors.New("ledger: unexpected response packet sequence")
+		}
+		data := packet[5:]
+		if seq == 0 {
+			if len(data) < 2 {
+				return nil, errors.New("ledger: invalid response packet")
+			}
+			length = int(binary.BigEndian.Uint16(data))
+			data = data[2:]
+		}
+		apdu = append(apdu, data...)
+	}
+	return apdu[:length], nil
+}
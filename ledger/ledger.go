@@ -0,0 +1,133 @@
+package ledger
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Transport exchanges APDUs with a Ledger device, e.g. over HID
+type Transport interface {
+	// Exchange sends a command APDU and returns the response APDU, ending with the status word
+	Exchange(apdu []byte) ([]byte, error)
+}
+
+// StatusOK is the status word of successful commands
+const StatusOK = 0x9000
+
+// statusMessages are the meanings of common status words, shared by the apps
+var statusMessages = map[uint16]string{
+	0x5515: "the device is locked",
+	0x6982: "the device is locked",
+	0x6985: "rejected on the device",
+	0x6a80: "invalid data",
+	0x6b00: "invalid parameters",
+	0x6d00: "instruction not supported, is the app of the chain open?",
+	0x6e00: "class not supported, is the app of the chain open?",
+	0x6e01: "the app of the chain is not open",
+}
+
+// StatusError is the error status word of a command
+type StatusError struct {
+	Code uint16
+}
+
+func (err *StatusError) Error() string {
+	if message, ok := statusMessages[err.Code]; ok {
+		return fmt.Sprintf("ledger: %s (0x%04x)", message, err.Code)
+	}
+	return fmt.Sprintf("ledger: error status 0x%04x", err.Code)
+}
+
+// maxAPDUData is the maximum length of the data of a command APDU
+const maxAPDUData = 255
+
+// exchange sends a command and returns the data of its response, or a StatusError
+func exchange(transport Transport, cla byte, ins byte, p1 byte, p2 byte, data []byte) ([]byte, error) {
+	if len(data) > maxAPDUData {
+		return nil, fmt.Errorf("ledger: apdu data too long: %d", len(data))
+	}
+	apdu := append([]byte{cla, ins, p1, p2, byte(len(data))}, data...)
+	response, err := transport.Exchange(apdu)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < 2 {
+		return nil, errors.New("ledger: invalid response")
+	}
+	status := binary.BigEndian.Uint16(response[len(response)-2:])
+	if status != StatusOK {
+		return nil, &StatusError{Code: status}
+	}
+	return response[:len(response)-2], nil
+}
+
+// chunks splits data in chunks of size, at least one
+func chunks(data []byte, size int) [][]byte {
+	result := [][]byte{}
+	for len(data) > size {
+		result = append(result, data[:size])
+		data = data[size:]
+	}
+	return append(result, data)
+}
+
+// hardened is the offset of hardened BIP32 indexes
+const hardened = 0x80000000
+
+// ParsePath parses a BIP32 derivation path, e.g. m/44'/60'/0'/0/0, hardened indexes ending with ' or h
+func ParsePath(path string) ([]uint32, error) {
+	components := strings.Split(strings.TrimPrefix(strings.TrimSpace(path), "m/"), "/")
+	result := make([]uint32, len(components))
+	for i, component := range components {
+		offset := uint32(0)
+		if strings.HasSuffix(component, "'") || strings.HasSuffix(component, "h") {
+			offset = hardened
+			component = component[:len(component)-1]
+		}
+		index, err := strconv.ParseUint(component, 10, 32)
+		if err != nil || index >= hardened {
+			return nil, fmt.Errorf("invalid derivation path: '%s'", path)
+		}
+		result[i] = uint32(index) + offset
+	}
+	return result, nil
+}
+
+// encodePath encodes a path as its length followed by its big endian indexes, as the Ethereum and Solana apps
+func encodePath(path []uint32) []byte {
+	encoded := make([]byte, 1+4*len(path))
+	encoded[0] = byte(len(path))
+	for i, index := range path {
+		binary.BigEndian.PutUint32(encoded[1+4*i:], index)
+	}
+	return encoded
+}
+
+// NewSigner creates a signer with the key at path of the Ledger app of the chain of asset, the default path of the
+// chain if empty
+// EVM txs are signed by the Ethereum app, Cosmos txs by the Cosmos app and Solana txs by the Solana app
+func NewSigner(transport Transport, asset xc.ITask, path string) (xc.KeySigner, error) {
+	switch driver := xc.Driver(asset.GetDriver()); driver {
+	case xc.DriverEVM, xc.DriverEVMLegacy:
+		return NewEthereumSigner(transport, path)
+	case xc.DriverCosmos:
+		return NewCosmosSigner(transport, asset.GetNativeAsset().ChainPrefix, path)
+	case xc.DriverSolana:
+		return NewSolanaSigner(transport, path)
+	default:
+		return nil, fmt.Errorf("unsupported driver for Ledger signing: '%s'", driver)
+	}
+}
+
+// parsePathOrDefault parses path, or defaultPath if empty
+func parsePathOrDefault(path string, defaultPath string) ([]uint32, error) {
+	if strings.TrimSpace(path) == "" {
+		path = defaultPath
+	}
+	return ParsePath(path)
+}
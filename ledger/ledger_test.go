@@ -0,0 +1,321 @@
+package ledger
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/evm"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+}
+
+func TestLedgerTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+// testTransport records the APDUs it's sent and answers them with handle
+type testTransport struct {
+	apdus  [][]byte
+	handle func(apdu []byte) []byte
+}
+
+func (transport *testTransport) Exchange(apdu []byte) ([]byte, error) {
+	transport.apdus = append(transport.apdus, apdu)
+	return transport.handle(apdu), nil
+}
+
+// ok returns a response APDU of data with StatusOK
+func ok(data ...byte) []byte {
+	return append(data, 0x90, 0x00)
+}
+
+// testEthereumApp answers the commands of the Ethereum app, signing with key
+func testEthereumApp(key *btcec.PrivateKey) func(apdu []byte) []byte {
+	var payload []byte
+	return func(apdu []byte) []byte {
+		switch apdu[1] {
+		case ethereumGetPublicKey:
+			publicKey := key.PubKey().SerializeUncompressed()
+			address := []byte(crypto.PubkeyToAddress(*key.PubKey().ToECDSA()).Hex()[2:])
+			return ok(append(append(append([]byte{byte(len(publicKey))}, publicKey...), byte(len(address))), address...)...)
+		case ethereumSignTx:
+			data := apdu[5:]
+			if apdu[2] == 0 {
+				// skip the path
+				data = data[1+4*int(data[0]):]
+				payload = nil
+			}
+			payload = append(payload, data...)
+			signature, _ := crypto.Sign(crypto.Keccak256(payload), key.ToECDSA())
+			// V of a tx of chain id 1
+			return ok(append([]byte{signature[64] + 37}, signature[:64]...)...)
+		}
+		return []byte{0x6d, 0x00}
+	}
+}
+
+func (s *CrosschainTestSuite) TestParsePath() {
+	require := s.Require()
+	path, err := ParsePath("m/44'/60'/0'/0/1")
+	require.NoError(err)
+	require.Equal([]uint32{hardened + 44, hardened + 60, hardened, 0, 1}, path)
+	path, err = ParsePath("44h/501h/2h")
+	require.NoError(err)
+	require.Equal([]uint32{hardened + 44, hardened + 501, hardened + 2}, path)
+	require.Equal([]byte{3, 0x80, 0, 0, 44, 0x80, 0, 0x01, 0xf5, 0x80, 0, 0, 2}, encodePath(path))
+
+	for _, invalid := range []string{"", "m/", "m/44'/x", "m/44''", "m/4294967296", "m/2147483648'"} {
+		_, err = ParsePath(invalid)
+		require.ErrorContains(err, "invalid derivation path", invalid)
+	}
+}
+
+func (s *CrosschainTestSuite) TestExchangeStatus() {
+	require := s.Require()
+	transport := &testTransport{handle: func(apdu []byte) []byte { return []byte{0x69, 0x85} }}
+	_, err := exchange(transport, 0xe0, 0x02, 0, 0, []byte{1})
+	require.EqualError(err, "ledger: rejected on the device (0x6985)")
+	var statusErr *StatusError
+	require.ErrorAs(err, &statusErr)
+	require.EqualValues(0x6985, statusErr.Code)
+	require.Equal([][]byte{{0xe0, 0x02, 0, 0, 1, 1}}, transport.apdus)
+
+	transport.handle = func(apdu []byte) []byte { return []byte{0x6f, 0x42} }
+	_, err = exchange(transport, 0xe0, 0x02, 0, 0, nil)
+	require.EqualError(err, "ledger: error status 0x6f42")
+	transport.handle = func(apdu []byte) []byte { return []byte{0x90} }
+	_, err = exchange(transport, 0xe0, 0x02, 0, 0, nil)
+	require.EqualError(err, "ledger: invalid response")
+	_, err = exchange(transport, 0xe0, 0x02, 0, 0, make([]byte, 256))
+	require.EqualError(err, "ledger: apdu data too long: 256")
+}
+
+// testDevice is an HID device replying with packets
+type testDevice struct {
+	written [][]byte
+	packets [][]byte
+}
+
+func (device *testDevice) Write(packet []byte) (int, error) {
+	device.written = append(device.written, packet)
+	return len(packet), nil
+}
+
+func (device *testDevice) Read(packet []byte) (int, error) {
+	if len(device.packets) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(packet, device.packets[0])
+	device.packets = device.packets[1:]
+	return n, nil
+}
+
+func (device *testDevice) Close() error {
+	return nil
+}
+
+func (s *CrosschainTestSuite) TestHIDTransport() {
+	require := s.Require()
+	apdu := bytes.Repeat([]byte{7}, 100)
+	packets := wrapAPDU(apdu)
+	require.Len(packets, 2)
+	require.Equal([]byte{0x01, 0x01, 0x05, 0, 0, 0, 100, 7}, packets[0][:8])
+	require.Equal([]byte{0x01, 0x01, 0x05, 0, 1, 7}, packets[1][:6])
+	for _, packet := range packets {
+		require.Len(packet, hidPacketSize)
+	}
+	require.Len(wrapAPDU(nil), 1)
+
+	device := &testDevice{packets: wrapAPDU(ok(1, 2, 3))}
+	transport := NewHIDTransport(device)
+	response, err := transport.Exchange(apdu)
+	require.NoError(err)
+	require.Equal(ok(1, 2, 3), response)
+	require.Equal(packets, device.written)
+
+	// multiple packets
+	device.packets = wrapAPDU(ok(apdu...))
+	response, err = transport.Exchange([]byte{1})
+	require.NoError(err)
+	require.Equal(ok(apdu...), response)
+
+	invalid := wrapAPDU(ok())
+	invalid[0][0] = 0x02
+	device.packets = invalid
+	_, err = transport.Exchange([]byte{1})
+	require.EqualError(err, "ledger: invalid response packet")
+	outOfOrder := wrapAPDU(ok(apdu...))
+	device.packets = [][]byte{outOfOrder[0], outOfOrder[0]}
+	_, err = transport.Exchange([]byte{1})
+	require.EqualError(err, "ledger: unexpected response packet sequence")
+	_, err = transport.Exchange([]byte{1})
+	require.ErrorContains(err, "could not read from device")
+}
+
+func (s *CrosschainTestSuite) TestEthereumSigner() {
+	require := s.Require()
+	key, _ := btcec.NewPrivateKey(btcec.S256())
+	transport := &testTransport{handle: testEthereumApp(key)}
+	signer, err := NewEthereumSigner(transport, "")
+	require.NoError(err)
+
+	publicKey, err := signer.PublicKey()
+	require.NoError(err)
+	require.Equal(xc.PublicKey(key.PubKey().SerializeCompressed()), publicKey)
+	require.Equal(append([]byte{ethereumCLA, ethereumGetPublicKey, 0, 0, 21}, encodePath([]uint32{hardened + 44, hardened + 60, hardened, 0, 0})...), transport.apdus[0])
+
+	// a tx with a payload of 2 chunks
+	to := common.HexToAddress("0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d")
+	chainID := big.NewInt(1)
+	tx := &evm.Tx{
+		EthTx:  types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10), Gas: 100_000, To: &to, Data: bytes.Repeat([]byte{1}, 300)}),
+		Signer: types.LatestSignerForChainID(chainID),
+	}
+	transport.apdus = nil
+	err = xc.SignTx(signer, tx)
+	require.NoError(err)
+	require.Len(transport.apdus, 2)
+	require.EqualValues(0, transport.apdus[0][2])
+	require.EqualValues(ethereumMoreData, transport.apdus[1][2])
+	require.Equal(xc.Address(crypto.PubkeyToAddress(*key.PubKey().ToECDSA()).Hex()), tx.From())
+
+	_, err = signer.Sign(xc.TxDataToSign{1})
+	require.EqualError(err, "ledger: the Ethereum app signs txs, not sighashes")
+	// the signature of another key
+	other, _ := btcec.NewPrivateKey(btcec.S256())
+	signer.publicKey = xc.PublicKey(other.PubKey().SerializeCompressed())
+	_, err = signer.SignPayload(xc.TxDataToSign{0xc0})
+	require.EqualError(err, "signature doesn't match the public key")
+	_, err = NewEthereumSigner(transport, "m/x")
+	require.ErrorContains(err, "invalid derivation path")
+}
+
+func (s *CrosschainTestSuite) TestCosmosSigner() {
+	require := s.Require()
+	key, _ := btcec.NewPrivateKey(btcec.S256())
+	var signDoc []byte
+	transport := &testTransport{handle: func(apdu []byte) []byte {
+		switch apdu[1] {
+		case cosmosGetAddress:
+			return ok(append(key.PubKey().SerializeCompressed(), "cosmos1address"...)...)
+		case cosmosSign:
+			switch apdu[2] {
+			case cosmosChunkInit:
+				signDoc = nil
+			case cosmosChunkAdd:
+				signDoc = append(signDoc, apdu[5:]...)
+			case cosmosChunkLast:
+				signDoc = append(signDoc, apdu[5:]...)
+				hash := sha256.Sum256(signDoc)
+				signature, _ := key.Sign(hash[:])
+				return ok(signature.Serialize()...)
+			}
+			return ok()
+		}
+		return []byte{0x6e, 0x01}
+	}}
+	signer, err := NewCosmosSigner(transport, "cosmos", "")
+	require.NoError(err)
+	publicKey, err := signer.PublicKey()
+	require.NoError(err)
+	require.Equal(xc.PublicKey(key.PubKey().SerializeCompressed()), publicKey)
+	path := []byte{44, 0, 0, 0x80, 118, 0, 0, 0x80, 0, 0, 0, 0x80, 0, 0, 0, 0, 0, 0, 0, 0}
+	require.Equal(append([]byte{cosmosCLA, cosmosGetAddress, 0, 0, 27, 6}, append([]byte("cosmos"), path...)...), transport.apdus[0])
+
+	payload := []byte(`{"account_number":"1","chain_id":"cosmoshub-4","memo":"` + strings.Repeat("m", 300) + `","sequence":"2"}`)
+	transport.apdus = nil
+	signature, err := signer.SignPayload(payload)
+	require.NoError(err)
+	require.Len(transport.apdus, 3)
+	require.Equal(append([]byte{cosmosCLA, cosmosSign, cosmosChunkInit, 0, 20}, path...), transport.apdus[0])
+	require.EqualValues(cosmosChunkAdd, transport.apdus[1][2])
+	require.EqualValues(cosmosChunkLast, transport.apdus[2][2])
+	require.Equal(payload, signDoc)
+	require.Len(signature, 64)
+	hash := sha256.Sum256(payload)
+	parsed := &btcec.Signature{R: new(big.Int).SetBytes(signature[:32]), S: new(big.Int).SetBytes(signature[32:])}
+	require.True(parsed.Verify(hash[:], key.PubKey()))
+
+	_, err = signer.SignPayload(xc.TxDataToSign{0x0a, 0x01})
+	require.ErrorContains(err, "build the tx with SIGN_MODE_LEGACY_AMINO_JSON")
+	_, err = signer.Sign(xc.TxDataToSign{1})
+	require.EqualError(err, "ledger: the Cosmos app signs sign docs, not sighashes")
+	_, err = NewCosmosSigner(transport, "cosmos", "m/44'/118'/0'")
+	require.ErrorContains(err, "invalid derivation path for the Cosmos app")
+	_, err = NewCosmosSigner(transport, "", "")
+	require.EqualError(err, "missing bech32 prefix of the chain")
+}
+
+func (s *CrosschainTestSuite) TestSolanaSigner() {
+	require := s.Require()
+	publicKey, privateKey, _ := ed25519.GenerateKey(nil)
+	var payload []byte
+	transport := &testTransport{handle: func(apdu []byte) []byte {
+		switch apdu[1] {
+		case solanaGetPublicKey:
+			return ok(publicKey...)
+		case solanaSignMessage:
+			if apdu[3]&solanaExtend == 0 {
+				payload = nil
+			}
+			payload = append(payload, apdu[5:]...)
+			if apdu[3]&solanaMore != 0 {
+				return ok()
+			}
+			// a single signer and its path
+			message := payload[2+4*int(payload[1]):]
+			return ok(ed25519.Sign(privateKey, message)...)
+		}
+		return []byte{0x6d, 0x00}
+	}}
+	signer, err := NewSolanaSigner(transport, "")
+	require.NoError(err)
+	key, err := signer.PublicKey()
+	require.NoError(err)
+	require.Equal(xc.PublicKey(publicKey), key)
+
+	message := bytes.Repeat([]byte{3}, 600)
+	transport.apdus = nil
+	signature, err := signer.Sign(message)
+	require.NoError(err)
+	require.True(ed25519.Verify(publicKey, message, signature))
+	require.Len(transport.apdus, 3)
+	require.Equal([]byte{solanaConfirm, solanaMore}, transport.apdus[0][2:4])
+	require.Equal([]byte{solanaConfirm, solanaExtend | solanaMore}, transport.apdus[1][2:4])
+	require.Equal([]byte{solanaConfirm, solanaExtend}, transport.apdus[2][2:4])
+
+	_, err = NewSolanaSigner(transport, "m/44'/501'/0'/0")
+	require.EqualError(err, "invalid derivation path for the Solana app: indexes must be hardened")
+}
+
+func (s *CrosschainTestSuite) TestNewSigner() {
+	require := s.Require()
+	transport := &testTransport{handle: func(apdu []byte) []byte { return ok() }}
+	signer, err := NewSigner(transport, &xc.NativeAssetConfig{NativeAsset: xc.ETH, Driver: string(xc.DriverEVM)}, "")
+	require.NoError(err)
+	require.IsType(&EthereumSigner{}, signer)
+	signer, err = NewSigner(transport, &xc.NativeAssetConfig{NativeAsset: xc.ATOM, Driver: string(xc.DriverCosmos), ChainPrefix: "cosmos"}, "")
+	require.NoError(err)
+	require.IsType(&CosmosSigner{}, signer)
+	signer, err = NewSigner(transport, &xc.NativeAssetConfig{NativeAsset: xc.SOL, Driver: string(xc.DriverSolana)}, "")
+	require.NoError(err)
+	require.IsType(&SolanaSigner{}, signer)
+
+	_, err = NewSigner(transport, &xc.NativeAssetConfig{NativeAsset: xc.BTC, Driver: string(xc.DriverBitcoin)}, "")
+	require.EqualError(err, "unsupported driver for Ledger signing: 'bitcoin'")
+	_, err = NewSigner(transport, &xc.NativeAssetConfig{NativeAsset: xc.INJ, Driver: string(xc.DriverCosmosEvmos)}, "")
+	require.EqualError(err, "unsupported driver for Ledger signing: 'evmos'")
+}
@@ -0,0 +1,97 @@
+package ledger
+
+import (
+	"errors"
+	"sync"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// DefaultSolanaPath is the derivation path of the first account of Ledger Live
+const DefaultSolanaPath = "m/44'/501'/0'/0'"
+
+// APDUs of the Solana app (app-solana)
+const (
+	solanaCLA          = 0xe0
+	solanaGetPublicKey = 0x05
+	solanaSignMessage  = 0x06
+	// solanaConfirm is P1 of commands confirmed on the device
+	solanaConfirm = 0x01
+	// P2 flags of the chunks of a message: following a chunk, followed by a chunk
+	solanaExtend = 0x01
+	solanaMore   = 0x02
+)
+
+// SolanaSigner signs Solana txs with the Solana app, which parses and displays the message to sign
+// The sighash of Solana txs is their message, so it's a plain KeySigner
+type SolanaSigner struct {
+	transport Transport
+	path      []uint32
+
+	mu        sync.Mutex
+	publicKey xc.PublicKey
+}
+
+var _ xc.KeySigner = &SolanaSigner{}
+
+// NewSolanaSigner creates a SolanaSigner with the key at path, DefaultSolanaPath if empty
+func NewSolanaSigner(transport Transport, path string) (*SolanaSigner, error) {
+	parsed, err := parsePathOrDefault(path, DefaultSolanaPath)
+	if err != nil {
+		return nil, err
+	}
+	// the app only derives hardened ed25519 keys
+	for _, index := range parsed {
+		if index < hardened {
+			return nil, errors.New("invalid derivation path for the Solana app: indexes must be hardened")
+		}
+	}
+	return &SolanaSigner{
+		transport: transport,
+		path:      parsed,
+	}, nil
+}
+
+// PublicKey returns the ed25519 public key of the signer, fetched once
+func (signer *SolanaSigner) PublicKey() (xc.PublicKey, error) {
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+	if signer.publicKey != nil {
+		return signer.publicKey, nil
+	}
+	response, err := exchange(signer.transport, solanaCLA, solanaGetPublicKey, 0, 0, encodePath(signer.path))
+	if err != nil {
+		return nil, err
+	}
+	if len(response) != 32 {
+		return nil, errors.New("ledger: invalid public key response")
+	}
+	signer.publicKey = xc.PublicKey(response)
+	return signer.publicKey, nil
+}
+
+// Sign signs a message, the sighash of a Solana tx, after its confirmation on the device
+func (signer *SolanaSigner) Sign(data xc.TxDataToSign) (xc.TxSignature, error) {
+	// a single signer, its path, the message
+	payload := append(append([]byte{1}, encodePath(signer.path)...), data...)
+	payloadChunks := chunks(payload, maxAPDUData)
+	var response []byte
+	var err error
+	for i, chunk := range payloadChunks {
+		p2 := byte(0)
+		if i > 0 {
+			p2 |= solanaExtend
+		}
+		if i < len(payloadChunks)-1 {
+			p2 |= solanaMore
+		}
+		response, err = exchange(signer.transport, solanaCLA, solanaSignMessage, solanaConfirm, p2, chunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(response) != 64 {
+		return nil, errors.New("ledger: invalid signature response")
+	}
+	return xc.TxSignature(response), nil
+}
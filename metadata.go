@@ -0,0 +1,81 @@
+package crosschain
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Metadata is caller defined key/values attached to a transfer, e.g. an order id or a customer id
+// Metadata follows a transfer through building, events, logs and storage, but is never part of a tx
+type Metadata map[string]string
+
+type metadataKey struct{}
+
+// Clone returns a copy of metadata
+func (metadata Metadata) Clone() Metadata {
+	if metadata == nil {
+		return nil
+	}
+	cloned := make(Metadata, len(metadata))
+	for key, value := range metadata {
+		cloned[key] = value
+	}
+	return cloned
+}
+
+// Merge returns a copy of metadata with the values of other, other takes precedence
+func (metadata Metadata) Merge(other Metadata) Metadata {
+	if len(other) == 0 {
+		return metadata.Clone()
+	}
+	merged := make(Metadata, len(metadata)+len(other))
+	for key, value := range metadata {
+		merged[key] = value
+	}
+	for key, value := range other {
+		merged[key] = value
+	}
+	return merged
+}
+
+// String formats metadata as key=value pairs sorted by key
+func (metadata Metadata) String() string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + metadata[key]
+	}
+	return strings.Join(parts, " ")
+}
+
+// LogFields returns metadata as log fields
+func (metadata Metadata) LogFields() logrus.Fields {
+	fields := make(logrus.Fields, len(metadata))
+	for key, value := range metadata {
+		fields[key] = value
+	}
+	return fields
+}
+
+// WithMetadata returns a context carrying metadata, merged with the metadata already in ctx
+func WithMetadata(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, MetadataFromContext(ctx).Merge(metadata))
+}
+
+// MetadataFromContext returns the metadata carried by ctx, nil if none
+func MetadataFromContext(ctx context.Context) Metadata {
+	metadata, _ := ctx.Value(metadataKey{}).(Metadata)
+	return metadata
+}
+
+// LogEntry returns a log entry with the metadata carried by ctx
+func LogEntry(ctx context.Context) *logrus.Entry {
+	return logrus.WithContext(ctx).WithFields(MetadataFromContext(ctx).LogFields())
+}
@@ -0,0 +1,43 @@
+package crosschain
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (s *CrosschainTestSuite) TestMetadata() {
+	require := s.Require()
+	metadata := Metadata{"order_id": "42", "customer_id": "c1"}
+	require.Equal("customer_id=c1 order_id=42", metadata.String())
+	require.Equal("", Metadata(nil).String())
+
+	cloned := metadata.Clone()
+	cloned["order_id"] = "43"
+	require.Equal("42", metadata["order_id"])
+	require.Nil(Metadata(nil).Clone())
+
+	merged := metadata.Merge(Metadata{"order_id": "43", "desk": "otc"})
+	require.Equal(Metadata{"order_id": "43", "customer_id": "c1", "desk": "otc"}, merged)
+	require.Equal("42", metadata["order_id"])
+	require.Equal(logrus.Fields{"order_id": "42", "customer_id": "c1"}, metadata.LogFields())
+}
+
+func (s *CrosschainTestSuite) TestMetadataContext() {
+	require := s.Require()
+	require.Nil(MetadataFromContext(context.Background()))
+
+	ctx := WithMetadata(context.Background(), Metadata{"order_id": "42"})
+	ctx = WithMetadata(ctx, Metadata{"customer_id": "c1"})
+	require.Equal(Metadata{"order_id": "42", "customer_id": "c1"}, MetadataFromContext(ctx))
+
+	var buf bytes.Buffer
+	logger := logrus.StandardLogger()
+	out := logger.Out
+	defer logger.SetOutput(out)
+	logger.SetOutput(&buf)
+	LogEntry(ctx).Info("submitted")
+	require.Contains(buf.String(), "order_id=42")
+	require.Contains(buf.String(), "customer_id=c1")
+}
@@ -0,0 +1,203 @@
+package crosschain
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TxBuildMethod is the method of a TxBuilder called to build a tx
+type TxBuildMethod string
+
+// List of TxBuildMethod
+const (
+	TxBuildTransfer       = TxBuildMethod("NewTransfer")
+	TxBuildNativeTransfer = TxBuildMethod("NewNativeTransfer")
+	TxBuildTokenTransfer  = TxBuildMethod("NewTokenTransfer")
+	TxBuildTask           = TxBuildMethod("NewTask")
+)
+
+// TxBuildRequest is a tx to build, that middlewares may change before it's built
+type TxBuildRequest struct {
+	Method TxBuildMethod
+	Asset  ITask
+	From   Address
+	To     Address
+	Amount AmountBlockchain
+	Input  TxInput
+	// Metadata of the transfer built, see TxBuilderWithMetadata, for middlewares only: it's never part of the tx
+	Metadata Metadata
+}
+
+// LogEntry returns a log entry with the metadata of the request
+func (req *TxBuildRequest) LogEntry() *logrus.Entry {
+	return logrus.WithFields(req.Metadata.LogFields())
+}
+
+// TxBuildFunc builds the tx of a request
+type TxBuildFunc func(req *TxBuildRequest) (Tx, error)
+
+// TxBuilderMiddleware wraps the build of txs, to layer concerns such as policies, memos, fee caps or metrics over all chains:
+// a middleware may change the request or its TxInput, inspect or replace the built Tx, or veto the build by returning an error
+type TxBuilderMiddleware func(next TxBuildFunc) TxBuildFunc
+
+// BeforeBuild returns a middleware calling hook before building, which may change the request or veto it with an error
+func BeforeBuild(hook func(req *TxBuildRequest) error) TxBuilderMiddleware {
+	return func(next TxBuildFunc) TxBuildFunc {
+		return func(req *TxBuildRequest) (Tx, error) {
+			if err := hook(req); err != nil {
+				return nil, err
+			}
+			return next(req)
+		}
+	}
+}
+
+// AfterBuild returns a middleware calling hook with each tx built, which may inspect it or veto it with an error
+func AfterBuild(hook func(req *TxBuildRequest, tx Tx) error) TxBuilderMiddleware {
+	return func(next TxBuildFunc) TxBuildFunc {
+		return func(req *TxBuildRequest) (Tx, error) {
+			tx, err := next(req)
+			if err != nil {
+				return tx, err
+			}
+			if err := hook(req, tx); err != nil {
+				return nil, err
+			}
+			return tx, nil
+		}
+	}
+}
+
+// ChainTxBuilderMiddlewares composes middlewares into one, the first middleware being the outermost
+func ChainTxBuilderMiddlewares(middlewares ...TxBuilderMiddleware) TxBuilderMiddleware {
+	return func(next TxBuildFunc) TxBuildFunc {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// MiddlewareTxBuilder is a TxBuilder whose transfers and tasks are built through middlewares, see WrapTxBuilder
+// Other builds, e.g. staking, aren't supported: use Unwrap to build them without the middlewares
+type MiddlewareTxBuilder struct {
+	Asset      ITask
+	builder    TxBuilder
+	middleware TxBuilderMiddleware
+	metadata   Metadata
+}
+
+var _ TxTokenBuilder = &MiddlewareTxBuilder{}
+
+// MiddlewareTxXTransferBuilder is a MiddlewareTxBuilder of a builder that can build tasks
+type MiddlewareTxXTransferBuilder struct {
+	*MiddlewareTxBuilder
+}
+
+var _ TxXTransferBuilder = &MiddlewareTxXTransferBuilder{}
+
+// WrapTxBuilder returns a TxBuilder of asset building txs through middlewares, the first middleware being the outermost
+// The builder returned can build tasks if builder can
+func WrapTxBuilder(asset ITask, builder TxBuilder, middlewares ...TxBuilderMiddleware) TxBuilder {
+	if len(middlewares) == 0 {
+		return builder
+	}
+	wrapped := &MiddlewareTxBuilder{
+		Asset:      asset,
+		builder:    builder,
+		middleware: ChainTxBuilderMiddlewares(middlewares...),
+	}
+	if _, ok := builder.(TxXTransferBuilder); ok {
+		return &MiddlewareTxXTransferBuilder{wrapped}
+	}
+	return wrapped
+}
+
+// UnwrapTxBuilder returns the builder wrapped by WrapTxBuilder, or builder itself if it isn't wrapped
+func UnwrapTxBuilder(builder TxBuilder) TxBuilder {
+	if wrapped, ok := builder.(interface{ Unwrap() TxBuilder }); ok {
+		return UnwrapTxBuilder(wrapped.Unwrap())
+	}
+	return builder
+}
+
+// TxBuilderWithMetadata returns a TxBuilder passing metadata to the middlewares of builder in TxBuildRequest.Metadata,
+// e.g. the metadata of the transfer built; builders without middlewares are returned as is
+func TxBuilderWithMetadata(builder TxBuilder, metadata Metadata) TxBuilder {
+	switch wrapped := builder.(type) {
+	case *MiddlewareTxXTransferBuilder:
+		return &MiddlewareTxXTransferBuilder{wrapped.withMetadata(metadata)}
+	case *MiddlewareTxBuilder:
+		return wrapped.withMetadata(metadata)
+	}
+	return builder
+}
+
+func (txBuilder *MiddlewareTxBuilder) withMetadata(metadata Metadata) *MiddlewareTxBuilder {
+	copied := *txBuilder
+	copied.metadata = metadata.Clone()
+	return &copied
+}
+
+// Unwrap returns the builder without the middlewares
+func (txBuilder *MiddlewareTxBuilder) Unwrap() TxBuilder {
+	return txBuilder.builder
+}
+
+func (txBuilder *MiddlewareTxBuilder) build(method TxBuildMethod, from Address, to Address, amount AmountBlockchain, input TxInput) (Tx, error) {
+	req := &TxBuildRequest{
+		Method:   method,
+		Asset:    txBuilder.Asset,
+		From:     from,
+		To:       to,
+		Amount:   amount,
+		Input:    input,
+		Metadata: txBuilder.metadata.Clone(),
+	}
+	return txBuilder.middleware(txBuilder.buildRequest)(req)
+}
+
+// buildRequest is the innermost TxBuildFunc, calling the wrapped builder
+func (txBuilder *MiddlewareTxBuilder) buildRequest(req *TxBuildRequest) (Tx, error) {
+	if req.Method == TxBuildTransfer {
+		return txBuilder.builder.NewTransfer(req.From, req.To, req.Amount, req.Input)
+	}
+	if req.Method == TxBuildTask {
+		if taskBuilder, ok := txBuilder.builder.(TxXTransferBuilder); ok {
+			return taskBuilder.NewTask(req.From, req.To, req.Amount, req.Input)
+		}
+		return nil, fmt.Errorf("tasks are not supported for %s", txBuilder.Asset.ID())
+	}
+	tokenBuilder, ok := txBuilder.builder.(TxTokenBuilder)
+	if !ok {
+		return nil, fmt.Errorf("%s is not supported for %s", req.Method, txBuilder.Asset.ID())
+	}
+	if req.Method == TxBuildNativeTransfer {
+		return tokenBuilder.NewNativeTransfer(req.From, req.To, req.Amount, req.Input)
+	}
+	if req.Method == TxBuildTokenTransfer {
+		return tokenBuilder.NewTokenTransfer(req.From, req.To, req.Amount, req.Input)
+	}
+	return nil, fmt.Errorf("invalid build method: %s", req.Method)
+}
+
+// NewTransfer creates a new transfer for an Asset, either native or token, through the middlewares
+func (txBuilder *MiddlewareTxBuilder) NewTransfer(from Address, to Address, amount AmountBlockchain, input TxInput) (Tx, error) {
+	return txBuilder.build(TxBuildTransfer, from, to, amount, input)
+}
+
+// NewNativeTransfer creates a new transfer for a native asset through the middlewares
+func (txBuilder *MiddlewareTxBuilder) NewNativeTransfer(from Address, to Address, amount AmountBlockchain, input TxInput) (Tx, error) {
+	return txBuilder.build(TxBuildNativeTransfer, from, to, amount, input)
+}
+
+// NewTokenTransfer creates a new transfer for a token asset through the middlewares
+func (txBuilder *MiddlewareTxBuilder) NewTokenTransfer(from Address, to Address, amount AmountBlockchain, input TxInput) (Tx, error) {
+	return txBuilder.build(TxBuildTokenTransfer, from, to, amount, input)
+}
+
+// NewTask creates a new task through the middlewares
+func (txBuilder *MiddlewareTxXTransferBuilder) NewTask(from Address, to Address, amount AmountBlockchain, input TxInput) (Tx, error) {
+	return txBuilder.build(TxBuildTask, from, to, amount, input)
+}
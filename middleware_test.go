@@ -0,0 +1,165 @@
+package crosschain
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+type middlewareTestTx struct {
+	bufferTestTx
+	req TxBuildRequest
+}
+
+type middlewareTestInput struct {
+	TxInputEnvelope
+	Memo string
+}
+
+// middlewareTestBuilder builds txs recording their request
+type middlewareTestBuilder struct{}
+
+func (builder middlewareTestBuilder) NewTransfer(from Address, to Address, amount AmountBlockchain, input TxInput) (Tx, error) {
+	return &middlewareTestTx{req: TxBuildRequest{Method: TxBuildTransfer, From: from, To: to, Amount: amount, Input: input}}, nil
+}
+
+func (builder middlewareTestBuilder) NewNativeTransfer(from Address, to Address, amount AmountBlockchain, input TxInput) (Tx, error) {
+	return &middlewareTestTx{req: TxBuildRequest{Method: TxBuildNativeTransfer, From: from, To: to, Amount: amount, Input: input}}, nil
+}
+
+func (builder middlewareTestBuilder) NewTokenTransfer(from Address, to Address, amount AmountBlockchain, input TxInput) (Tx, error) {
+	return &middlewareTestTx{req: TxBuildRequest{Method: TxBuildTokenTransfer, From: from, To: to, Amount: amount, Input: input}}, nil
+}
+
+type middlewareTestTaskBuilder struct {
+	middlewareTestBuilder
+}
+
+func (builder middlewareTestTaskBuilder) NewTask(from Address, to Address, amount AmountBlockchain, input TxInput) (Tx, error) {
+	return &middlewareTestTx{req: TxBuildRequest{Method: TxBuildTask, From: from, To: to, Amount: amount, Input: input}}, nil
+}
+
+func (s *CrosschainTestSuite) TestWrapTxBuilder() {
+	require := s.Require()
+	asset := &NativeAssetConfig{NativeAsset: ETH}
+	builder := middlewareTestBuilder{}
+	require.Equal(builder, WrapTxBuilder(asset, builder))
+
+	calls := []string{}
+	stampMemo := BeforeBuild(func(req *TxBuildRequest) error {
+		calls = append(calls, "memo")
+		req.Input.(*middlewareTestInput).Memo = "stamped"
+		return nil
+	})
+	capAmount := BeforeBuild(func(req *TxBuildRequest) error {
+		calls = append(calls, "cap")
+		if amountCap := NewAmountBlockchainFromUint64(100); req.Amount.Cmp(&amountCap) > 0 {
+			return errors.New("amount over cap")
+		}
+		return nil
+	})
+	inspect := AfterBuild(func(req *TxBuildRequest, tx Tx) error {
+		calls = append(calls, "inspect "+string(req.Method))
+		return nil
+	})
+
+	wrapped := WrapTxBuilder(asset, builder, stampMemo, capAmount, inspect)
+	require.IsType(&MiddlewareTxBuilder{}, wrapped)
+	require.Equal(builder, UnwrapTxBuilder(wrapped))
+
+	input := &middlewareTestInput{}
+	tx, err := wrapped.NewTransfer("from", "to", NewAmountBlockchainFromUint64(10), input)
+	require.NoError(err)
+	require.Equal("stamped", tx.(*middlewareTestTx).req.Input.(*middlewareTestInput).Memo)
+	require.Equal(Address("to"), tx.(*middlewareTestTx).req.To)
+	require.Equal([]string{"memo", "cap", "inspect NewTransfer"}, calls)
+
+	calls = []string{}
+	tx, err = wrapped.(TxTokenBuilder).NewTokenTransfer("from", "to", NewAmountBlockchainFromUint64(10), input)
+	require.NoError(err)
+	require.Equal(TxBuildTokenTransfer, tx.(*middlewareTestTx).req.Method)
+	require.Equal([]string{"memo", "cap", "inspect NewTokenTransfer"}, calls)
+
+	// veto
+	calls = []string{}
+	tx, err = wrapped.(TxTokenBuilder).NewNativeTransfer("from", "to", NewAmountBlockchainFromUint64(1000), input)
+	require.EqualError(err, "amount over cap")
+	require.Nil(tx)
+	require.Equal([]string{"memo", "cap"}, calls)
+
+	vetoTx := AfterBuild(func(req *TxBuildRequest, tx Tx) error {
+		return errors.New("vetoed")
+	})
+	tx, err = WrapTxBuilder(asset, builder, vetoTx).NewTransfer("from", "to", NewAmountBlockchainFromUint64(10), input)
+	require.EqualError(err, "vetoed")
+	require.Nil(tx)
+
+	// tasks only if the builder supports them
+	_, ok := wrapped.(TxXTransferBuilder)
+	require.False(ok)
+	wrapped = WrapTxBuilder(asset, middlewareTestTaskBuilder{}, inspect)
+	tx, err = wrapped.(TxXTransferBuilder).NewTask("from", "to", NewAmountBlockchainFromUint64(10), input)
+	require.NoError(err)
+	require.Equal(TxBuildTask, tx.(*middlewareTestTx).req.Method)
+
+	// wrapping twice
+	wrapped = WrapTxBuilder(asset, wrapped, stampMemo)
+	require.Equal(middlewareTestTaskBuilder{}, UnwrapTxBuilder(wrapped))
+}
+
+func (s *CrosschainTestSuite) TestChainTxBuilderMiddlewares() {
+	require := s.Require()
+	order := []string{}
+	trace := func(name string) TxBuilderMiddleware {
+		return func(next TxBuildFunc) TxBuildFunc {
+			return func(req *TxBuildRequest) (Tx, error) {
+				order = append(order, "before "+name)
+				tx, err := next(req)
+				order = append(order, "after "+name)
+				return tx, err
+			}
+		}
+	}
+	build := ChainTxBuilderMiddlewares(trace("a"), trace("b"))(func(req *TxBuildRequest) (Tx, error) {
+		order = append(order, "build")
+		return nil, nil
+	})
+	_, err := build(&TxBuildRequest{})
+	require.NoError(err)
+	require.Equal([]string{"before a", "before b", "build", "after b", "after a"}, order)
+}
+
+func (s *CrosschainTestSuite) TestTxBuilderWithMetadata() {
+	require := s.Require()
+	asset := &NativeAssetConfig{NativeAsset: ETH}
+	builder := middlewareTestBuilder{}
+	require.Equal(builder, TxBuilderWithMetadata(builder, Metadata{"order_id": "42"}))
+
+	var seen Metadata
+	var entry *logrus.Entry
+	record := BeforeBuild(func(req *TxBuildRequest) error {
+		seen = req.Metadata
+		entry = req.LogEntry()
+		return nil
+	})
+	wrapped := WrapTxBuilder(asset, builder, record)
+	metadata := Metadata{"order_id": "42"}
+	tagged := TxBuilderWithMetadata(wrapped, metadata)
+	metadata["order_id"] = "43"
+	tx, err := tagged.NewTransfer("from", "to", NewAmountBlockchainFromUint64(10), &middlewareTestInput{})
+	require.NoError(err)
+	require.Equal(Metadata{"order_id": "42"}, seen)
+	require.Equal("42", entry.Data["order_id"])
+	// never part of the tx
+	require.Nil(tx.(*middlewareTestTx).req.Metadata)
+
+	// the wrapped builder is unchanged
+	_, err = wrapped.NewTransfer("from", "to", NewAmountBlockchainFromUint64(10), &middlewareTestInput{})
+	require.NoError(err)
+	require.Nil(seen)
+
+	tagged = TxBuilderWithMetadata(WrapTxBuilder(asset, middlewareTestTaskBuilder{}, record), Metadata{"order_id": "44"})
+	_, err = tagged.(TxXTransferBuilder).NewTask("from", "to", NewAmountBlockchainFromUint64(10), &middlewareTestInput{})
+	require.NoError(err)
+	require.Equal(Metadata{"order_id": "44"}, seen)
+}
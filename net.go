@@ -0,0 +1,80 @@
+package crosschain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Net is the network of a chain, e.g. mainnet or a named testnet
+type Net string
+
+// List of network kinds
+const (
+	Mainnet = Net("mainnet")
+	Testnet = Net("testnet")
+	Devnet  = Net("devnet")
+	Regtest = Net("regtest")
+)
+
+// List of named testnets
+const (
+	Sepolia   = Net("sepolia")           // Ethereum
+	Goerli    = Net("goerli")            // Ethereum
+	Holesky   = Net("holesky")           // Ethereum
+	Fuji      = Net("fuji")              // Avalanche
+	Mumbai    = Net("mumbai")            // Polygon
+	OsmoTest5 = Net("osmo-test-5")       // Osmosis
+	Theta     = Net("theta-testnet-001") // Cosmos Hub
+)
+
+var netAliases = map[string]Net{
+	"main":         Mainnet,
+	"mainnet-beta": Mainnet,
+	"test":         Testnet,
+	"testnet3":     Testnet,
+	"dev":          Devnet,
+	"local":        Devnet,
+	"localnet":     Devnet,
+}
+
+var namedTestnets = []Net{Sepolia, Goerli, Holesky, Fuji, Mumbai, OsmoTest5, Theta}
+
+// ParseNet parses a network, case insensitive and accepting common aliases
+func ParseNet(net string) (Net, error) {
+	normalized := strings.ToLower(strings.TrimSpace(net))
+	if alias, ok := netAliases[normalized]; ok {
+		return alias, nil
+	}
+	switch Net(normalized) {
+	case Mainnet, Testnet, Devnet, Regtest:
+		return Net(normalized), nil
+	}
+	for _, named := range namedTestnets {
+		if Net(normalized) == named {
+			return named, nil
+		}
+	}
+	return "", fmt.Errorf("unknown network: '%s'", net)
+}
+
+// Kind returns the kind of a network, i.e. Testnet for named testnets
+// Unknown networks are returned as is
+func (net Net) Kind() Net {
+	for _, named := range namedTestnets {
+		if net == named {
+			return Testnet
+		}
+	}
+	return net
+}
+
+// IsMainnet returns true for mainnet
+func (net Net) IsMainnet() bool {
+	return net.Kind() == Mainnet
+}
+
+// Valid returns true if net is a known network
+func (net Net) Valid() bool {
+	_, err := ParseNet(string(net))
+	return err == nil
+}
@@ -0,0 +1,39 @@
+package crosschain
+
+func (s *CrosschainTestSuite) TestParseNet() {
+	require := s.Require()
+	vectors := map[string]Net{
+		"mainnet":      Mainnet,
+		"Mainnet":      Mainnet,
+		"mainnet-beta": Mainnet,
+		" testnet ":    Testnet,
+		"testnet3":     Testnet,
+		"devnet":       Devnet,
+		"localnet":     Devnet,
+		"regtest":      Regtest,
+		"Sepolia":      Sepolia,
+		"osmo-test-5":  OsmoTest5,
+	}
+	for input, expected := range vectors {
+		net, err := ParseNet(input)
+		require.NoError(err, input)
+		require.Equal(expected, net)
+	}
+
+	_, err := ParseNet("mynet")
+	require.EqualError(err, "unknown network: 'mynet'")
+	_, err = ParseNet("")
+	require.Error(err)
+}
+
+func (s *CrosschainTestSuite) TestNetKind() {
+	require := s.Require()
+	require.Equal(Testnet, Sepolia.Kind())
+	require.Equal(Testnet, OsmoTest5.Kind())
+	require.Equal(Mainnet, Mainnet.Kind())
+	require.Equal(Net("mynet"), Net("mynet").Kind())
+	require.True(Mainnet.IsMainnet())
+	require.False(Sepolia.IsMainnet())
+	require.True(Sepolia.Valid())
+	require.False(Net("mynet").Valid())
+}
@@ -0,0 +1,175 @@
+package poisoning
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+)
+
+// Defaults of a Detector
+const (
+	// DefaultAffixLength is the number of characters of the start and the end of addresses that wallets display
+	// when truncating them, that look-alike addresses of address poisoning attacks copy
+	DefaultAffixLength   = 4
+	DefaultMaxAddresses  = 1000
+	DefaultRetentionTime = 90 * 24 * time.Hour
+)
+
+var now = time.Now
+
+// Warning is a destination looking like an address recently sent to, without being it
+type Warning struct {
+	Chain xc.NativeAsset `json:"chain"`
+	// Address is the destination checked
+	Address xc.Address `json:"address"`
+	// LookAlike is the address recently sent to that the destination looks like
+	LookAlike xc.Address `json:"look_alike"`
+	LastUsed  time.Time  `json:"last_used"`
+	// MatchingPrefix and MatchingSuffix are the number of matching characters, after the common prefix of the chain
+	// such as 0x or the bech32 prefix
+	MatchingPrefix int `json:"matching_prefix"`
+	MatchingSuffix int `json:"matching_suffix"`
+}
+
+// Detector flags destinations looking like the recently used addresses of a chain, a sign of address poisoning:
+// an attacker sends dust from an address with the same start and end as a counterparty, so it's copied from the history
+// Only record the addresses of transfers that were verified, never the senders of incoming transfers
+type Detector struct {
+	// PrefixLength and SuffixLength are the min numbers of matching characters to flag an address
+	PrefixLength int
+	SuffixLength int
+	// MaxAddresses per chain, the least recently used addresses are forgotten first
+	MaxAddresses int
+	// RetentionTime after which an address is forgotten
+	RetentionTime time.Duration
+
+	mu        sync.Mutex
+	addresses map[xc.NativeAsset]map[string]*usedAddress
+}
+
+type usedAddress struct {
+	address  xc.Address
+	lastUsed time.Time
+}
+
+// NewDetector creates a new Detector without history
+func NewDetector() *Detector {
+	return &Detector{
+		PrefixLength:  DefaultAffixLength,
+		SuffixLength:  DefaultAffixLength,
+		MaxAddresses:  DefaultMaxAddresses,
+		RetentionTime: DefaultRetentionTime,
+		addresses:     map[xc.NativeAsset]map[string]*usedAddress{},
+	}
+}
+
+// normalize returns the address as compared, and the part of the address after the common prefix of the chain
+func normalize(chain xc.NativeAsset, address xc.Address) (string, string) {
+	normalized := factory.NormalizeAddressString(string(address), string(chain))
+	body := normalized
+	if index := strings.LastIndex(body, ":"); index >= 0 {
+		body = body[index+1:]
+	}
+	if strings.HasPrefix(body, "0x") {
+		body = body[2:]
+	} else if index := strings.LastIndex(body, "1"); index > 0 && body == strings.ToLower(body) && isHumanReadablePart(body[:index]) {
+		// bech32
+		body = body[index+1:]
+	}
+	return normalized, body
+}
+
+func isHumanReadablePart(hrp string) bool {
+	for _, c := range hrp {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Record records address as sent to on chain
+func (detector *Detector) Record(chain xc.NativeAsset, address xc.Address) {
+	detector.mu.Lock()
+	defer detector.mu.Unlock()
+	normalized, _ := normalize(chain, address)
+	addresses, ok := detector.addresses[chain]
+	if !ok {
+		addresses = map[string]*usedAddress{}
+		detector.addresses[chain] = addresses
+	}
+	addresses[normalized] = &usedAddress{address: address, lastUsed: now()}
+	detector.expire(chain)
+}
+
+// expire forgets the addresses of chain past the retention time, and the least recently used addresses over MaxAddresses
+func (detector *Detector) expire(chain xc.NativeAsset) {
+	addresses := detector.addresses[chain]
+	for key, used := range addresses {
+		if detector.RetentionTime > 0 && now().Sub(used.lastUsed) > detector.RetentionTime {
+			delete(addresses, key)
+		}
+	}
+	if detector.MaxAddresses <= 0 || len(addresses) <= detector.MaxAddresses {
+		return
+	}
+	keys := make([]string, 0, len(addresses))
+	for key := range addresses {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return addresses[keys[i]].lastUsed.Before(addresses[keys[j]].lastUsed)
+	})
+	for _, key := range keys[:len(keys)-detector.MaxAddresses] {
+		delete(addresses, key)
+	}
+}
+
+// Check returns the warnings of a destination looking like addresses recently sent to on chain, most recently used first
+// A destination sent to before isn't flagged
+func (detector *Detector) Check(chain xc.NativeAsset, to xc.Address) []*Warning {
+	detector.mu.Lock()
+	defer detector.mu.Unlock()
+	detector.expire(chain)
+	warnings := []*Warning{}
+	normalized, body := normalize(chain, to)
+	addresses := detector.addresses[chain]
+	if _, ok := addresses[normalized]; ok {
+		return warnings
+	}
+	for _, used := range addresses {
+		_, usedBody := normalize(chain, used.address)
+		prefix, suffix := commonAffixes(body, usedBody)
+		if prefix >= detector.PrefixLength && suffix >= detector.SuffixLength {
+			warnings = append(warnings, &Warning{
+				Chain:          chain,
+				Address:        to,
+				LookAlike:      used.address,
+				LastUsed:       used.lastUsed,
+				MatchingPrefix: prefix,
+				MatchingSuffix: suffix,
+			})
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].LastUsed.After(warnings[j].LastUsed)
+	})
+	return warnings
+}
+
+// commonAffixes returns the lengths of the common prefix and suffix of different strings a and b
+func commonAffixes(a string, b string) (int, int) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return prefix, suffix
+}
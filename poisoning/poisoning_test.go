@@ -0,0 +1,79 @@
+package poisoning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestPoisoningTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func (s *CrosschainTestSuite) TestCheck() {
+	require := s.Require()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return start }
+	defer func() { now = time.Now }()
+
+	detector := NewDetector()
+	detector.Record(xc.ETH, "0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+	now = func() time.Time { return start.Add(time.Hour) }
+	detector.Record(xc.ETH, "0x0eC9aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaB10B")
+	detector.Record(xc.ATOM, "cosmos19rl4cm2hmr8afy4kldpxz3fka4jguq0auqdal4")
+
+	// sent to before, whatever the case
+	require.Empty(detector.Check(xc.ETH, "0x0ec9f48533bb2a03f53f341ef5cc1b057892b10b"))
+	// unrelated
+	require.Empty(detector.Check(xc.ETH, "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"))
+
+	// same start and end, most recently used first
+	warnings := detector.Check(xc.ETH, "0x0ec9000000000000000000000000000000000b10b")
+	require.Len(warnings, 2)
+	require.Equal(xc.Address("0x0eC9aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaB10B"), warnings[0].LookAlike)
+	require.Equal(xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B"), warnings[1].LookAlike)
+	require.Equal(4, warnings[1].MatchingPrefix)
+	require.Equal(4, warnings[1].MatchingSuffix)
+	require.Equal(start, warnings[1].LastUsed)
+	// the prefix of the chain doesn't count
+	require.Empty(detector.Check(xc.ETH, "0x0ec1000000000000000000000000000000000b10b"))
+	require.Empty(detector.Check(xc.ATOM, "cosmos1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaadal4"))
+	warnings = detector.Check(xc.ATOM, "cosmos19rl4aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaadal4")
+	require.Len(warnings, 1)
+	require.Equal(4, warnings[0].MatchingPrefix)
+	// per chain
+	require.Empty(detector.Check(xc.MATIC, "0x0ec9000000000000000000000000000000000b10b"))
+
+	// forgotten after the retention time
+	now = func() time.Time { return start.Add(DefaultRetentionTime + time.Minute) }
+	require.Len(detector.Check(xc.ETH, "0x0ec9000000000000000000000000000000000b10b"), 1)
+}
+
+func (s *CrosschainTestSuite) TestMaxAddresses() {
+	require := s.Require()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer func() { now = time.Now }()
+	detector := NewDetector()
+	detector.MaxAddresses = 1
+	now = func() time.Time { return start }
+	detector.Record(xc.SOL, "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+	now = func() time.Time { return start.Add(time.Second) }
+	detector.Record(xc.SOL, "BWbmXj5ckAaWCAtzMZ97qnJhBAKegoXtgNrv9BUpAB11")
+
+	require.Empty(detector.Check(xc.SOL, "Hzn3zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzSGtb"))
+	require.Len(detector.Check(xc.SOL, "BWbmzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzAB11"), 1)
+	// base58 is case sensitive
+	require.Empty(detector.Check(xc.SOL, "bwbmzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzab11"))
+}
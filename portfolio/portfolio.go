@@ -0,0 +1,234 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/audit"
+	"github.com/shopspring/decimal"
+)
+
+// ChainSource fetches the historical balances and the block times of a chain, e.g. from an archive node
+type ChainSource interface {
+	audit.BalanceSource
+	FetchLatestBlock(ctx context.Context) (int64, error)
+	FetchBlockTime(ctx context.Context, height int64) (time.Time, error)
+}
+
+// PriceSource is the price provider, returning the price of an asset in a currency, e.g. USD or EUR, at a time
+type PriceSource interface {
+	FetchPriceAt(ctx context.Context, asset xc.AssetID, currency string, t time.Time) (xc.AmountHumanReadable, error)
+}
+
+// Holding is an address whose balance of an asset is valued
+type Holding struct {
+	Asset   xc.ITask
+	Address xc.Address
+}
+
+// Position is the valuation of a Holding at the last block of its chain before the snapshot time
+// Amounts and values are decimal strings
+type Position struct {
+	Chain     xc.NativeAsset      `json:"chain"`
+	Asset     xc.AssetID          `json:"asset"`
+	Contract  xc.ContractAddress  `json:"contract,omitempty"`
+	Address   xc.Address          `json:"address"`
+	Height    int64               `json:"height"`
+	BlockTime time.Time           `json:"block_time"`
+	Balance   xc.AmountBlockchain `json:"balance"`
+	Amount    string              `json:"amount"`
+	// Prices and Values per currency, at the block time
+	Prices map[string]string `json:"prices"`
+	Values map[string]string `json:"values"`
+}
+
+// Failure is a Holding that couldn't be valued
+type Failure struct {
+	Asset   xc.AssetID `json:"asset"`
+	Address xc.Address `json:"address"`
+	Error   string     `json:"error"`
+}
+
+// Report is a point-in-time valuation of holdings in several currencies
+type Report struct {
+	Time       time.Time `json:"time"`
+	Currencies []string  `json:"currencies"`
+	// Positions sorted by chain, asset and address
+	Positions []*Position `json:"positions"`
+	// Totals per currency, of the positions valued
+	Totals   map[string]string `json:"totals"`
+	Failures []*Failure        `json:"failures"`
+}
+
+// Valuer values holdings at a point in time from historical balances and prices
+type Valuer struct {
+	Chains map[xc.NativeAsset]ChainSource
+	Prices PriceSource
+}
+
+// NewValuer creates a new Valuer
+func NewValuer(chains map[xc.NativeAsset]ChainSource, prices PriceSource) *Valuer {
+	return &Valuer{
+		Chains: chains,
+		Prices: prices,
+	}
+}
+
+// HeightAt returns the last block of a chain produced at or before t
+func HeightAt(ctx context.Context, source ChainSource, t time.Time) (int64, time.Time, error) {
+	latest, err := source.FetchLatestBlock(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	latestTime, err := source.FetchBlockTime(ctx, latest)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if !latestTime.After(t) {
+		return latest, latestTime, nil
+	}
+	// blocks low and high are produced at or before and after t
+	low, high := int64(0), latest
+	lowTime := time.Time{}
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		midTime, err := source.FetchBlockTime(ctx, mid)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		if midTime.After(t) {
+			high = mid
+		} else {
+			low, lowTime = mid, midTime
+		}
+	}
+	if low == 0 {
+		return 0, time.Time{}, fmt.Errorf("no block before %s", t.UTC().Format(time.RFC3339))
+	}
+	return low, lowTime, nil
+}
+
+type chainHeight struct {
+	height    int64
+	blockTime time.Time
+	err       error
+}
+
+// Snapshot values holdings in currencies at t: the balance of each holding is read at the last block of its chain
+// at or before t, and priced at the time of that block
+// A holding failing to be valued is reported as a Failure and excluded from the totals
+func (valuer *Valuer) Snapshot(ctx context.Context, holdings []*Holding, currencies []string, t time.Time) (*Report, error) {
+	if len(currencies) == 0 {
+		return nil, errors.New("no currency to value holdings in")
+	}
+	report := &Report{
+		Time:       t,
+		Currencies: currencies,
+		Positions:  []*Position{},
+		Totals:     map[string]string{},
+		Failures:   []*Failure{},
+	}
+	totals := map[string]decimal.Decimal{}
+	for _, currency := range currencies {
+		totals[currency] = decimal.Zero
+	}
+	heights := map[xc.NativeAsset]*chainHeight{}
+
+	for _, holding := range holdings {
+		chain := holding.Asset.GetNativeAsset().NativeAsset
+		height, ok := heights[chain]
+		if !ok {
+			height = &chainHeight{}
+			source, ok := valuer.Chains[chain]
+			if ok {
+				height.height, height.blockTime, height.err = HeightAt(ctx, source, t)
+			} else {
+				height.err = fmt.Errorf("no source of chain %s", chain)
+			}
+			heights[chain] = height
+		}
+		if height.err != nil {
+			report.Failures = append(report.Failures, newFailure(holding, height.err))
+			continue
+		}
+		position, values, err := valuer.value(ctx, holding, height, currencies)
+		if err != nil {
+			report.Failures = append(report.Failures, newFailure(holding, err))
+			continue
+		}
+		report.Positions = append(report.Positions, position)
+		for currency, value := range values {
+			totals[currency] = totals[currency].Add(value)
+		}
+	}
+
+	for currency, total := range totals {
+		report.Totals[currency] = total.String()
+	}
+	sort.Slice(report.Positions, func(i, j int) bool {
+		a, b := report.Positions[i], report.Positions[j]
+		if a.Chain != b.Chain {
+			return a.Chain < b.Chain
+		}
+		if a.Asset != b.Asset {
+			return a.Asset < b.Asset
+		}
+		return a.Address < b.Address
+	})
+	return report, nil
+}
+
+func (valuer *Valuer) value(ctx context.Context, holding *Holding, height *chainHeight, currencies []string) (*Position, map[string]decimal.Decimal, error) {
+	chain := holding.Asset.GetNativeAsset().NativeAsset
+	contract, decimals := assetContract(holding.Asset)
+	balance, err := valuer.Chains[chain].FetchBalanceAt(ctx, holding.Address, contract, height.height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not fetch balance at height %d: %v", height.height, err)
+	}
+	amount := decimal.Decimal(balance.ToHuman(decimals))
+	position := &Position{
+		Chain:     chain,
+		Asset:     holding.Asset.ID(),
+		Contract:  contract,
+		Address:   holding.Address,
+		Height:    height.height,
+		BlockTime: height.blockTime,
+		Balance:   balance,
+		Amount:    amount.String(),
+		Prices:    map[string]string{},
+		Values:    map[string]string{},
+	}
+	values := map[string]decimal.Decimal{}
+	for _, currency := range currencies {
+		price, err := valuer.Prices.FetchPriceAt(ctx, holding.Asset.ID(), currency, height.blockTime)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not fetch price in %s at %s: %v", currency, height.blockTime.UTC().Format(time.RFC3339), err)
+		}
+		value := amount.Mul(decimal.Decimal(price))
+		position.Prices[currency] = price.String()
+		position.Values[currency] = value.String()
+		values[currency] = value
+	}
+	return position, values, nil
+}
+
+func newFailure(holding *Holding, err error) *Failure {
+	return &Failure{
+		Asset:   holding.Asset.ID(),
+		Address: holding.Address,
+		Error:   err.Error(),
+	}
+}
+
+// assetContract returns the contract of a token asset, empty for native assets, and its decimals
+func assetContract(asset xc.ITask) (xc.ContractAddress, int32) {
+	if token, ok := asset.(*xc.TokenAssetConfig); ok {
+		return xc.ContractAddress(token.Contract), token.Decimals
+	}
+	config := asset.GetAssetConfig()
+	return xc.ContractAddress(config.Contract), config.Decimals
+}
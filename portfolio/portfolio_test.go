@@ -0,0 +1,183 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestPortfolioTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+var genesis = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// mockChain produces a block every blockTime from genesis, and a balance per contract increasing by 1 per block
+type mockChain struct {
+	latest    int64
+	blockTime time.Duration
+	balances  map[xc.ContractAddress]uint64
+	fetched   []int64
+}
+
+func (chain *mockChain) FetchLatestBlock(ctx context.Context) (int64, error) {
+	return chain.latest, nil
+}
+
+func (chain *mockChain) FetchBlockTime(ctx context.Context, height int64) (time.Time, error) {
+	chain.fetched = append(chain.fetched, height)
+	return genesis.Add(time.Duration(height) * chain.blockTime), nil
+}
+
+func (chain *mockChain) FetchBalanceAt(ctx context.Context, address xc.Address, contract xc.ContractAddress, height int64) (xc.AmountBlockchain, error) {
+	balance, ok := chain.balances[contract]
+	if !ok {
+		return xc.AmountBlockchain{}, errors.New("unknown contract")
+	}
+	return xc.NewAmountBlockchainFromUint64(balance + uint64(height)), nil
+}
+
+// mockPrices prices assets in USD, and EUR at 0.5 USD
+type mockPrices struct {
+	usd    map[xc.AssetID]string
+	called []time.Time
+}
+
+func (prices *mockPrices) FetchPriceAt(ctx context.Context, asset xc.AssetID, currency string, t time.Time) (xc.AmountHumanReadable, error) {
+	prices.called = append(prices.called, t)
+	price, ok := prices.usd[asset]
+	if !ok {
+		return xc.AmountHumanReadable{}, errors.New("no price")
+	}
+	if currency == "EUR" {
+		return xc.NewAmountHumanReadableFromStr(price).Div(xc.NewAmountHumanReadableFromStr("0.5")), nil
+	}
+	return xc.NewAmountHumanReadableFromStr(price), nil
+}
+
+var ethAsset = &xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, Decimals: 18}
+var usdcAsset = &xc.TokenAssetConfig{
+	Asset: "USDC", Chain: "ETH", Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6,
+	AssetConfig:       xc.AssetConfig{Asset: "USDC", NativeAsset: xc.ETH, Type: xc.AssetTypeToken, Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6},
+	NativeAssetConfig: ethAsset,
+}
+var solAsset = &xc.AssetConfig{Asset: "SOL", NativeAsset: xc.SOL, Decimals: 9}
+
+func (s *CrosschainTestSuite) TestHeightAt() {
+	require := s.Require()
+	chain := &mockChain{latest: 1000, blockTime: 12 * time.Second}
+
+	height, blockTime, err := HeightAt(s.Ctx, chain, genesis.Add(time.Hour))
+	require.NoError(err)
+	require.EqualValues(300, height)
+	require.Equal(genesis.Add(time.Hour), blockTime)
+
+	height, _, err = HeightAt(s.Ctx, chain, genesis.Add(time.Hour+11*time.Second))
+	require.NoError(err)
+	require.EqualValues(300, height)
+	require.Less(len(chain.fetched), 30)
+
+	// after the latest block
+	height, _, err = HeightAt(s.Ctx, chain, genesis.Add(24*time.Hour))
+	require.NoError(err)
+	require.EqualValues(1000, height)
+
+	_, _, err = HeightAt(s.Ctx, chain, genesis.Add(-time.Hour))
+	require.EqualError(err, "no block before 2022-12-31T23:00:00Z")
+}
+
+func (s *CrosschainTestSuite) TestSnapshot() {
+	require := s.Require()
+	eth := &mockChain{latest: 1000, blockTime: 12 * time.Second, balances: map[xc.ContractAddress]uint64{
+		"": 2_000_000_000_000_000_000 - 300,
+		"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48": 1_500_000 - 300,
+	}}
+	sol := &mockChain{latest: 100_000, blockTime: 400 * time.Millisecond, balances: map[xc.ContractAddress]uint64{
+		"": 3_000_000_000 - 9000,
+	}}
+	prices := &mockPrices{usd: map[xc.AssetID]string{"ETH": "1500", "USDC.ETH": "1", "SOL": "20"}}
+	valuer := NewValuer(map[xc.NativeAsset]ChainSource{xc.ETH: eth, xc.SOL: sol}, prices)
+
+	t := genesis.Add(time.Hour)
+	report, err := valuer.Snapshot(s.Ctx, []*Holding{
+		{Asset: solAsset, Address: "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"},
+		{Asset: usdcAsset, Address: "0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B"},
+		{Asset: ethAsset, Address: "0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B"},
+	}, []string{"USD", "EUR"}, t)
+	require.NoError(err)
+	require.Empty(report.Failures)
+	require.Len(report.Positions, 3)
+
+	position := report.Positions[0]
+	require.Equal(xc.AssetID("ETH"), position.Asset)
+	require.EqualValues(300, position.Height)
+	require.Equal("2", position.Amount)
+	require.Equal("1500", position.Prices["USD"])
+	require.Equal("3000", position.Values["USD"])
+	require.Equal("6000", position.Values["EUR"])
+
+	position = report.Positions[1]
+	require.Equal(xc.AssetID("USDC.ETH"), position.Asset)
+	require.Equal(xc.ContractAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), position.Contract)
+	require.Equal("1.5", position.Amount)
+	require.Equal("1.5", position.Values["USD"])
+
+	position = report.Positions[2]
+	require.Equal(xc.AssetID("SOL"), position.Asset)
+	require.EqualValues(9000, position.Height)
+	require.Equal("60", position.Values["USD"])
+
+	require.Equal("3061.5", report.Totals["USD"])
+	require.Equal("6123", report.Totals["EUR"])
+	// priced at block time
+	for _, called := range prices.called {
+		require.Equal(t, called)
+	}
+
+	data, err := json.Marshal(report)
+	require.NoError(err)
+	require.Contains(string(data), `"values":{"EUR":"6000","USD":"3000"}`)
+}
+
+func (s *CrosschainTestSuite) TestSnapshotFailures() {
+	require := s.Require()
+	eth := &mockChain{latest: 1000, blockTime: 12 * time.Second, balances: map[xc.ContractAddress]uint64{"": 1_000_000_000_000_000_000}}
+	prices := &mockPrices{usd: map[xc.AssetID]string{"ETH": "1500"}}
+	valuer := NewValuer(map[xc.NativeAsset]ChainSource{xc.ETH: eth}, prices)
+
+	report, err := valuer.Snapshot(s.Ctx, []*Holding{
+		{Asset: ethAsset, Address: "0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B"},
+		{Asset: usdcAsset, Address: "0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B"},
+		{Asset: solAsset, Address: "Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb"},
+	}, []string{"USD"}, genesis.Add(24*time.Hour))
+	require.NoError(err)
+	require.Len(report.Positions, 1)
+	require.Len(report.Failures, 2)
+	require.Contains(report.Failures[0].Error, "unknown contract")
+	require.Equal("no source of chain SOL", report.Failures[1].Error)
+	require.Equal("1500.0000000000015", report.Totals["USD"])
+
+	// no price
+	prices.usd = map[xc.AssetID]string{}
+	report, err = valuer.Snapshot(s.Ctx, []*Holding{{Asset: ethAsset, Address: "0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B"}}, []string{"USD"}, genesis.Add(time.Hour))
+	require.NoError(err)
+	require.Contains(report.Failures[0].Error, "could not fetch price in USD at 2023-01-01T01:00:00Z: no price")
+	require.Equal("0", report.Totals["USD"])
+
+	_, err = valuer.Snapshot(s.Ctx, nil, nil, genesis)
+	require.EqualError(err, "no currency to value holdings in")
+}
@@ -0,0 +1,402 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jumpcrypto/crosschain/lease"
+	"github.com/jumpcrypto/crosschain/storage"
+)
+
+// Priority is the class of a queued transfer
+type Priority string
+
+// List of Priority, served from the highest
+const (
+	PriorityHigh   = Priority("high")
+	PriorityNormal = Priority("normal")
+	PriorityLow    = Priority("low")
+)
+
+// priorities are the priorities from the highest
+var priorities = []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+// Valid returns true for a known priority
+func (priority Priority) Valid() bool {
+	for _, valid := range priorities {
+		if priority == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultStarvationTimeout is the wait after which a transfer is served before transfers of higher priorities
+const DefaultStarvationTimeout = 10 * time.Minute
+
+// Errors returned by TxQueue
+var (
+	ErrEmpty       = errors.New("queue is empty")
+	ErrQueued      = errors.New("transfer is already queued")
+	ErrUserLimit   = errors.New("too many transfers queued for user")
+	ErrBadPriority = errors.New("unknown priority")
+)
+
+// Item is a transfer queued for a user
+type Item struct {
+	Transfer   *storage.Transfer
+	User       string
+	Priority   Priority
+	EnqueuedAt time.Time
+}
+
+// Wait returns how long the item has been queued at t
+func (item *Item) Wait(t time.Time) time.Duration {
+	return t.Sub(item.EnqueuedAt)
+}
+
+// class is the transfers of a priority, queued per user, with the users served in turn
+type class struct {
+	users []string
+	items map[string][]*Item
+	// served, totalWait and maxWait are metrics of the popped items
+	served    int
+	totalWait time.Duration
+	maxWait   time.Duration
+}
+
+func newClass() *class {
+	return &class{
+		items: map[string][]*Item{},
+	}
+}
+
+func (c *class) push(item *Item) {
+	if len(c.items[item.User]) == 0 {
+		c.users = append(c.users, item.User)
+	}
+	c.items[item.User] = append(c.items[item.User], item)
+}
+
+// pop removes the first item of user, who is then served after the other users, and returns the former turn of user
+func (c *class) pop(user string) (*Item, int) {
+	items := c.items[user]
+	item := items[0]
+	turn := c.removeUser(user)
+	if len(items) == 1 {
+		delete(c.items, user)
+	} else {
+		c.items[user] = items[1:]
+		c.users = append(c.users, user)
+	}
+	return item, turn
+}
+
+// unpop queues a popped item again as the first item of its user, who is served at turn again
+func (c *class) unpop(item *Item, turn int) {
+	c.removeUser(item.User)
+	c.items[item.User] = append([]*Item{item}, c.items[item.User]...)
+	if turn > len(c.users) {
+		turn = len(c.users)
+	}
+	c.users = append(c.users[:turn], append([]string{item.User}, c.users[turn:]...)...)
+}
+
+// removeUser removes user from the turns, and returns its former turn, or -1
+func (c *class) removeUser(user string) int {
+	for i := range c.users {
+		if c.users[i] == user {
+			c.users = append(c.users[:i], c.users[i+1:]...)
+			return i
+		}
+	}
+	return -1
+}
+
+// oldest returns the oldest item, among the first items of users
+func (c *class) oldest() *Item {
+	var oldest *Item
+	for _, user := range c.users {
+		if item := c.items[user][0]; oldest == nil || item.EnqueuedAt.Before(oldest.EnqueuedAt) {
+			oldest = item
+		}
+	}
+	return oldest
+}
+
+func (c *class) len() int {
+	n := 0
+	for _, items := range c.items {
+		n += len(items)
+	}
+	return n
+}
+
+// TxQueue schedules transfers, e.g. withdrawals, by priority, and serves the users of a priority in turn
+// so that a user queuing many transfers doesn't delay the others
+// Transfers waiting longer than StarvationTimeout are served first, oldest first, whatever their priority
+// TxQueue is safe for concurrent use
+type TxQueue struct {
+	// StarvationTimeout is DefaultStarvationTimeout if zero, and disabled if negative
+	StarvationTimeout time.Duration
+	// MaxPerUser bounds the transfers queued per user, 0 for no limit
+	MaxPerUser int
+
+	mu       sync.Mutex
+	classes  map[Priority]*class
+	queued   map[string]*Item
+	promoted int
+}
+
+// NewTxQueue creates a new TxQueue
+func NewTxQueue() *TxQueue {
+	queue := &TxQueue{
+		StarvationTimeout: DefaultStarvationTimeout,
+		classes:           map[Priority]*class{},
+		queued:            map[string]*Item{},
+	}
+	for _, priority := range priorities {
+		queue.classes[priority] = newClass()
+	}
+	return queue
+}
+
+// Push queues transfer for user with priority
+func (queue *TxQueue) Push(transfer *storage.Transfer, user string, priority Priority) error {
+	if !priority.Valid() {
+		return fmt.Errorf("%w: %s", ErrBadPriority, priority)
+	}
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if _, ok := queue.queued[transfer.ID]; ok {
+		return fmt.Errorf("%w: %s", ErrQueued, transfer.ID)
+	}
+	if queue.MaxPerUser > 0 && queue.userLen(user) >= queue.MaxPerUser {
+		return fmt.Errorf("%w: %s", ErrUserLimit, user)
+	}
+	item := &Item{
+		Transfer:   transfer,
+		User:       user,
+		Priority:   priority,
+		EnqueuedAt: now(),
+	}
+	queue.classes[priority].push(item)
+	queue.queued[transfer.ID] = item
+	return nil
+}
+
+func (queue *TxQueue) userLen(user string) int {
+	n := 0
+	for _, c := range queue.classes {
+		n += len(c.items[user])
+	}
+	return n
+}
+
+// popped is a popped item, with what the pop changed to queue it again as if it hadn't been popped
+type popped struct {
+	item     *Item
+	turn     int
+	wait     time.Duration
+	maxWait  time.Duration
+	promoted bool
+}
+
+// Pop removes and returns the next transfer to process, or ErrEmpty
+func (queue *TxQueue) Pop() (*Item, error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	p, err := queue.popNext(now())
+	if err != nil {
+		return nil, err
+	}
+	return p.item, nil
+}
+
+func (queue *TxQueue) popNext(t time.Time) (*popped, error) {
+	// starving transfers first, oldest first
+	if timeout := queue.starvationTimeout(); timeout > 0 {
+		var starving *Item
+		for _, priority := range priorities {
+			oldest := queue.classes[priority].oldest()
+			if oldest != nil && oldest.Wait(t) >= timeout && (starving == nil || oldest.EnqueuedAt.Before(starving.EnqueuedAt)) {
+				starving = oldest
+			}
+		}
+		if starving != nil {
+			promoted := queue.higherQueued(starving.Priority)
+			if promoted {
+				queue.promoted++
+			}
+			p := queue.pop(starving.Priority, starving.User, t)
+			p.promoted = promoted
+			return p, nil
+		}
+	}
+	for _, priority := range priorities {
+		if c := queue.classes[priority]; len(c.users) > 0 {
+			return queue.pop(priority, c.users[0], t), nil
+		}
+	}
+	return nil, ErrEmpty
+}
+
+// PopLeased pops the next transfer, like Pop, and acquires its lease, see lease.TransferKey: the caller processes the
+// transfer while holding the lease, renewing it, and releases it once done
+// A transfer whose lease is held is processed by another replica: it's dropped from the queue and the next is popped
+// On other errors of the locker, the transfer is queued again, next to be popped as if it hadn't been
+func (queue *TxQueue) PopLeased(ctx context.Context, locker lease.Locker, ttl time.Duration) (*Item, lease.Lease, error) {
+	for {
+		queue.mu.Lock()
+		p, err := queue.popNext(now())
+		queue.mu.Unlock()
+		if err != nil {
+			return nil, nil, err
+		}
+		held, err := locker.Acquire(ctx, lease.TransferKey(p.item.Transfer.ID), ttl)
+		if errors.Is(err, lease.ErrLeaseHeld) {
+			continue
+		}
+		if err != nil {
+			queue.unpop(p)
+			return nil, nil, fmt.Errorf("leasing transfer %s: %w", p.item.Transfer.ID, err)
+		}
+		return p.item, held, nil
+	}
+}
+
+// unpop queues a popped item again, at its former place, and rolls back the metrics of its pop
+func (queue *TxQueue) unpop(p *popped) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	item := p.item
+	if _, ok := queue.queued[item.Transfer.ID]; ok {
+		return
+	}
+	c := queue.classes[item.Priority]
+	c.unpop(item, p.turn)
+	queue.queued[item.Transfer.ID] = item
+	c.served--
+	c.totalWait -= p.wait
+	if c.maxWait == p.wait {
+		c.maxWait = p.maxWait
+	}
+	if p.promoted {
+		queue.promoted--
+	}
+}
+
+func (queue *TxQueue) pop(priority Priority, user string, t time.Time) *popped {
+	c := queue.classes[priority]
+	item, turn := c.pop(user)
+	delete(queue.queued, item.Transfer.ID)
+	p := &popped{item: item, turn: turn, wait: item.Wait(t), maxWait: c.maxWait}
+	c.served++
+	c.totalWait += p.wait
+	if p.wait > c.maxWait {
+		c.maxWait = p.wait
+	}
+	return p
+}
+
+// higherQueued returns true if transfers of a priority higher than priority are queued
+func (queue *TxQueue) higherQueued(priority Priority) bool {
+	for _, higher := range priorities {
+		if higher == priority {
+			return false
+		}
+		if len(queue.classes[higher].users) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (queue *TxQueue) starvationTimeout() time.Duration {
+	if queue.StarvationTimeout == 0 {
+		return DefaultStarvationTimeout
+	}
+	return queue.StarvationTimeout
+}
+
+// Remove removes a queued transfer, e.g. a cancelled withdrawal, and returns false if it isn't queued
+func (queue *TxQueue) Remove(transferID string) bool {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	item, ok := queue.queued[transferID]
+	if !ok {
+		return false
+	}
+	delete(queue.queued, transferID)
+	c := queue.classes[item.Priority]
+	items := c.items[item.User]
+	for i := range items {
+		if items[i] == item {
+			items = append(items[:i:i], items[i+1:]...)
+			break
+		}
+	}
+	if len(items) > 0 {
+		c.items[item.User] = items
+		return true
+	}
+	delete(c.items, item.User)
+	c.removeUser(item.User)
+	return true
+}
+
+// Len returns the number of queued transfers
+func (queue *TxQueue) Len() int {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	return len(queue.queued)
+}
+
+// ClassStats are the metrics of the transfers of a priority
+type ClassStats struct {
+	// Depth is the number of queued transfers, and OldestWait the wait of the oldest
+	Depth      int           `json:"depth"`
+	OldestWait time.Duration `json:"oldest_wait"`
+	// Served is the number of popped transfers, and MeanWait and MaxWait their wait in the queue
+	Served   int           `json:"served"`
+	MeanWait time.Duration `json:"mean_wait"`
+	MaxWait  time.Duration `json:"max_wait"`
+}
+
+// Stats are the metrics of a TxQueue, e.g. to export to a monitoring system
+type Stats struct {
+	Classes map[Priority]*ClassStats `json:"classes"`
+	// Promoted is the number of starving transfers served before transfers of higher priorities
+	Promoted int `json:"promoted"`
+}
+
+// Stats returns the metrics of the queue
+func (queue *TxQueue) Stats() *Stats {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	t := now()
+	stats := &Stats{
+		Classes:  map[Priority]*ClassStats{},
+		Promoted: queue.promoted,
+	}
+	for priority, c := range queue.classes {
+		classStats := &ClassStats{
+			Depth:   c.len(),
+			Served:  c.served,
+			MaxWait: c.maxWait,
+		}
+		if oldest := c.oldest(); oldest != nil {
+			classStats.OldestWait = oldest.Wait(t)
+		}
+		if c.served > 0 {
+			classStats.MeanWait = c.totalWait / time.Duration(c.served)
+		}
+		stats.Classes[priority] = classStats
+	}
+	return stats
+}
+
+var now = time.Now
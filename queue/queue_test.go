@@ -0,0 +1,252 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jumpcrypto/crosschain/lease"
+	"github.com/jumpcrypto/crosschain/storage"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	t time.Time
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.t = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time {
+		return s.t
+	}
+}
+
+func (s *CrosschainTestSuite) TearDownTest() {
+	now = time.Now
+}
+
+func TestQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func (s *CrosschainTestSuite) push(queue *TxQueue, id string, user string, priority Priority) {
+	s.Require().NoError(queue.Push(&storage.Transfer{ID: id}, user, priority))
+	s.t = s.t.Add(time.Second)
+}
+
+func (s *CrosschainTestSuite) popIDs(queue *TxQueue) []string {
+	ids := []string{}
+	for {
+		item, err := queue.Pop()
+		if errors.Is(err, ErrEmpty) {
+			return ids
+		}
+		s.Require().NoError(err)
+		ids = append(ids, item.Transfer.ID)
+	}
+}
+
+func (s *CrosschainTestSuite) TestPopPriorities() {
+	require := s.Require()
+	queue := NewTxQueue()
+	s.push(queue, "low", "alice", PriorityLow)
+	s.push(queue, "normal", "alice", PriorityNormal)
+	s.push(queue, "high", "alice", PriorityHigh)
+	require.Equal(3, queue.Len())
+	require.Equal([]string{"high", "normal", "low"}, s.popIDs(queue))
+	require.Equal(0, queue.Len())
+}
+
+func (s *CrosschainTestSuite) TestPopFairness() {
+	require := s.Require()
+	queue := NewTxQueue()
+	// alice queues a burst before bob and carol
+	s.push(queue, "a1", "alice", PriorityNormal)
+	s.push(queue, "a2", "alice", PriorityNormal)
+	s.push(queue, "a3", "alice", PriorityNormal)
+	s.push(queue, "b1", "bob", PriorityNormal)
+	s.push(queue, "b2", "bob", PriorityNormal)
+	s.push(queue, "c1", "carol", PriorityNormal)
+	require.Equal([]string{"a1", "b1", "c1", "a2", "b2", "a3"}, s.popIDs(queue))
+}
+
+func (s *CrosschainTestSuite) TestPopStarvation() {
+	require := s.Require()
+	queue := NewTxQueue()
+	queue.StarvationTimeout = time.Minute
+	s.push(queue, "low", "alice", PriorityLow)
+	s.push(queue, "high1", "bob", PriorityHigh)
+	s.push(queue, "high2", "bob", PriorityHigh)
+
+	item, err := queue.Pop()
+	require.NoError(err)
+	require.Equal("high1", item.Transfer.ID)
+
+	// the low priority transfer is served first once starving
+	s.t = s.t.Add(time.Minute)
+	item, err = queue.Pop()
+	require.NoError(err)
+	require.Equal("low", item.Transfer.ID)
+	require.Equal(63*time.Second, item.Wait(s.t))
+	require.Equal(1, queue.Stats().Promoted)
+
+	// a starving high priority transfer isn't a promotion
+	item, err = queue.Pop()
+	require.NoError(err)
+	require.Equal("high2", item.Transfer.ID)
+	require.Equal(1, queue.Stats().Promoted)
+
+	// disabled
+	queue.StarvationTimeout = -1
+	s.push(queue, "low", "alice", PriorityLow)
+	s.push(queue, "high", "bob", PriorityHigh)
+	s.t = s.t.Add(time.Hour)
+	require.Equal([]string{"high", "low"}, s.popIDs(queue))
+	require.Equal(1, queue.Stats().Promoted)
+}
+
+func (s *CrosschainTestSuite) TestPushErrors() {
+	require := s.Require()
+	queue := NewTxQueue()
+	queue.MaxPerUser = 2
+	s.push(queue, "a1", "alice", PriorityNormal)
+	s.push(queue, "a2", "alice", PriorityHigh)
+
+	err := queue.Push(&storage.Transfer{ID: "a3"}, "alice", PriorityLow)
+	require.True(errors.Is(err, ErrUserLimit))
+	require.EqualError(err, "too many transfers queued for user: alice")
+	s.push(queue, "b1", "bob", PriorityLow)
+
+	err = queue.Push(&storage.Transfer{ID: "a1"}, "bob", PriorityLow)
+	require.True(errors.Is(err, ErrQueued))
+
+	err = queue.Push(&storage.Transfer{ID: "b2"}, "bob", Priority("urgent"))
+	require.True(errors.Is(err, ErrBadPriority))
+	require.Equal(3, queue.Len())
+
+	// alice may queue again once served
+	_, err = queue.Pop()
+	require.NoError(err)
+	s.push(queue, "a3", "alice", PriorityLow)
+}
+
+func (s *CrosschainTestSuite) TestRemove() {
+	require := s.Require()
+	queue := NewTxQueue()
+	s.push(queue, "a1", "alice", PriorityNormal)
+	s.push(queue, "a2", "alice", PriorityNormal)
+	s.push(queue, "b1", "bob", PriorityNormal)
+
+	require.True(queue.Remove("a1"))
+	require.True(queue.Remove("b1"))
+	require.False(queue.Remove("b1"))
+	require.False(queue.Remove("unknown"))
+	require.Equal([]string{"a2"}, s.popIDs(queue))
+	_, err := queue.Pop()
+	require.Equal(ErrEmpty, err)
+}
+
+// failingLocker is a Locker whose backend is unreachable
+type failingLocker struct{}
+
+func (failingLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (lease.Lease, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (s *CrosschainTestSuite) TestPopLeased() {
+	require := s.Require()
+	ctx := context.Background()
+	queue := NewTxQueue()
+	locker := lease.NewMemoryLocker()
+	s.push(queue, "a1", "alice", PriorityNormal)
+	s.push(queue, "b1", "bob", PriorityNormal)
+	s.push(queue, "c1", "carol", PriorityNormal)
+
+	// a1 is processed by another replica: dropped
+	held, err := locker.Acquire(ctx, lease.TransferKey("a1"), time.Minute)
+	require.NoError(err)
+	item, leased, err := queue.PopLeased(ctx, locker, time.Minute)
+	require.NoError(err)
+	require.Equal("b1", item.Transfer.ID)
+	require.Equal("transfer/b1", leased.Key())
+	_, err = locker.Acquire(ctx, lease.TransferKey("b1"), time.Minute)
+	require.ErrorIs(err, lease.ErrLeaseHeld)
+	require.NoError(leased.Release(ctx))
+	require.NoError(held.Release(ctx))
+
+	// the locker failing, c1 stays queued
+	_, _, err = queue.PopLeased(ctx, failingLocker{}, time.Minute)
+	require.EqualError(err, "leasing transfer c1: connection refused")
+	require.Equal(1, queue.Len())
+	item, leased, err = queue.PopLeased(ctx, locker, time.Minute)
+	require.NoError(err)
+	require.Equal("c1", item.Transfer.ID)
+	require.NoError(leased.Release(ctx))
+
+	_, _, err = queue.PopLeased(ctx, locker, time.Minute)
+	require.Equal(ErrEmpty, err)
+}
+
+func (s *CrosschainTestSuite) TestPopLeasedRequeue() {
+	require := s.Require()
+	ctx := context.Background()
+	queue := NewTxQueue()
+	queue.StarvationTimeout = time.Minute
+	s.push(queue, "n1", "carol", PriorityNormal)
+	s.push(queue, "a1", "alice", PriorityHigh)
+	s.push(queue, "a2", "alice", PriorityHigh)
+	s.push(queue, "b1", "bob", PriorityHigh)
+	s.t = s.t.Add(time.Minute)
+
+	// n1 starving, it's promoted, and queued again as if it hadn't been popped
+	_, _, err := queue.PopLeased(ctx, failingLocker{}, time.Minute)
+	require.EqualError(err, "leasing transfer n1: connection refused")
+	stats := queue.Stats()
+	require.Equal(0, stats.Promoted)
+	require.Equal(0, stats.Classes[PriorityNormal].Served)
+	require.Equal(time.Duration(0), stats.Classes[PriorityNormal].MaxWait)
+	require.Equal(64*time.Second, stats.Classes[PriorityNormal].OldestWait)
+	item, err := queue.Pop()
+	require.NoError(err)
+	require.Equal("n1", item.Transfer.ID)
+	require.Equal(1, queue.Stats().Promoted)
+
+	// a1 stays the first of alice, whose turn is kept
+	queue.StarvationTimeout = -1
+	_, _, err = queue.PopLeased(ctx, failingLocker{}, time.Minute)
+	require.EqualError(err, "leasing transfer a1: connection refused")
+	require.Equal(0, queue.Stats().Classes[PriorityHigh].Served)
+	require.Equal([]string{"a1", "b1", "a2"}, s.popIDs(queue))
+}
+
+func (s *CrosschainTestSuite) TestStats() {
+	require := s.Require()
+	queue := NewTxQueue()
+	s.push(queue, "h1", "alice", PriorityHigh)
+	s.push(queue, "h2", "bob", PriorityHigh)
+	s.push(queue, "n1", "alice", PriorityNormal)
+	s.t = s.t.Add(7 * time.Second)
+
+	stats := queue.Stats()
+	require.Len(stats.Classes, 3)
+	require.Equal(2, stats.Classes[PriorityHigh].Depth)
+	require.Equal(10*time.Second, stats.Classes[PriorityHigh].OldestWait)
+	require.Equal(1, stats.Classes[PriorityNormal].Depth)
+	require.Equal(0, stats.Classes[PriorityLow].Depth)
+	require.Equal(time.Duration(0), stats.Classes[PriorityLow].OldestWait)
+
+	// h1 waited 10s, h2 9s
+	_, err := queue.Pop()
+	require.NoError(err)
+	_, err = queue.Pop()
+	require.NoError(err)
+	stats = queue.Stats()
+	require.Equal(0, stats.Classes[PriorityHigh].Depth)
+	require.Equal(2, stats.Classes[PriorityHigh].Served)
+	require.Equal(9500*time.Millisecond, stats.Classes[PriorityHigh].MeanWait)
+	require.Equal(10*time.Second, stats.Classes[PriorityHigh].MaxWait)
+	require.Equal(8*time.Second, stats.Classes[PriorityNormal].OldestWait)
+	require.Equal(0, stats.Classes[PriorityNormal].Served)
+}
@@ -0,0 +1,296 @@
+package crosschain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// QuorumRead is a critical read cross-checked across providers
+type QuorumRead string
+
+// List of QuorumRead
+const (
+	// QuorumReadBalance is the balance of the asset of an address, e.g. before sweeping it
+	QuorumReadBalance = QuorumRead("balance")
+	// QuorumReadNativeBalance is the balance of the native asset of an address
+	QuorumReadNativeBalance = QuorumRead("native_balance")
+	// QuorumReadNonce is the nonce of the sender of a tx, before signing it, see TxInputNonce
+	QuorumReadNonce = QuorumRead("nonce")
+)
+
+// ErrProviderDivergence is returned when providers answer a quorum read differently, see ProviderDivergence
+var ErrProviderDivergence = errors.New("providers diverged")
+
+// ErrNoQuorum is returned when fewer providers than quorum_reads answer a quorum read
+var ErrNoQuorum = errors.New("not enough providers answered")
+
+// ProviderAnswer is the answer of a provider to a quorum read, or its error
+type ProviderAnswer struct {
+	Provider string `json:"provider"`
+	Answer   string `json:"answer,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ProviderDivergence is a quorum read that providers answered differently: one of them is faulty, or malicious
+type ProviderDivergence struct {
+	Asset   AssetID          `json:"asset"`
+	Read    QuorumRead       `json:"read"`
+	Address Address          `json:"address"`
+	Answers []ProviderAnswer `json:"answers"`
+}
+
+var _ error = &ProviderDivergence{}
+
+func (divergence *ProviderDivergence) Error() string {
+	answers := []string{}
+	for _, answer := range divergence.Answers {
+		if answer.Error != "" {
+			answers = append(answers, fmt.Sprintf("%s: error %s", answer.Provider, answer.Error))
+		} else {
+			answers = append(answers, fmt.Sprintf("%s: %s", answer.Provider, answer.Answer))
+		}
+	}
+	return fmt.Sprintf("%v on %s of %s on %s: %s", ErrProviderDivergence, divergence.Read, divergence.Address,
+		divergence.Asset, strings.Join(answers, ", "))
+}
+
+// Is makes errors.Is(err, ErrProviderDivergence) true
+func (divergence *ProviderDivergence) Is(target error) bool {
+	return target == ErrProviderDivergence
+}
+
+// ProviderClient is the client of a chain sending its requests to a single provider
+type ProviderClient struct {
+	Provider string
+	Client   Client
+}
+
+// QuorumClient is a Client cross-checking critical reads across providers: balances, and the nonces of TxInputs
+// Reads are sent to all providers concurrently, at least Quorum of them must answer, and all their answers must be
+// equal, or else the read fails with a ProviderDivergence, passed to OnDivergence before it's returned
+// Other calls are sent to the primary client; use UnwrapClient for its other features, e.g. gas estimates
+type QuorumClient struct {
+	Asset        ITask
+	Primary      Client
+	Providers    []ProviderClient
+	Quorum       int
+	OnDivergence func(divergence *ProviderDivergence)
+}
+
+var _ FullClient = &QuorumClient{}
+
+// NewQuorumClient creates a new QuorumClient
+func NewQuorumClient(asset ITask, primary Client, providers []ProviderClient, quorum int) *QuorumClient {
+	return &QuorumClient{
+		Asset:     asset,
+		Primary:   primary,
+		Providers: providers,
+		Quorum:    quorum,
+	}
+}
+
+// UnwrapClient returns the primary client of a QuorumClient, or client itself if it isn't wrapped
+func UnwrapClient(client Client) Client {
+	if wrapped, ok := client.(interface{ Unwrap() Client }); ok {
+		return UnwrapClient(wrapped.Unwrap())
+	}
+	return client
+}
+
+// Unwrap returns the primary client
+func (client *QuorumClient) Unwrap() Client {
+	return client.Primary
+}
+
+// FetchTxInput fetches the input of a tx from all providers, cross-checking its nonce if it's a TxInputNonce
+// The input returned is the input of the first provider answering, in order
+func (client *QuorumClient) FetchTxInput(ctx context.Context, from Address, to Address) (TxInput, error) {
+	inputs := make([]TxInput, len(client.Providers))
+	err := client.read(ctx, QuorumReadNonce, from, func(i int, provider Client) (string, error) {
+		input, err := provider.FetchTxInput(ctx, from, to)
+		if err != nil {
+			return "", err
+		}
+		inputs[i] = input
+		if withNonce, ok := input.(TxInputNonce); ok {
+			return fmt.Sprint(withNonce.GetNonce()), nil
+		}
+		// inputs without nonce, e.g. of UTXO chains, aren't compared
+		return "", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, input := range inputs {
+		if input != nil {
+			return input, nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s", ErrNoQuorum, client.Asset.ID())
+}
+
+// FetchTxInfo fetches a tx from the primary client
+func (client *QuorumClient) FetchTxInfo(ctx context.Context, txHash TxHash) (TxInfo, error) {
+	return client.Primary.FetchTxInfo(ctx, txHash)
+}
+
+// SubmitTx submits a tx with the primary client
+func (client *QuorumClient) SubmitTx(ctx context.Context, tx Tx) error {
+	return client.Primary.SubmitTx(ctx, tx)
+}
+
+// FetchBalance fetches the balance of an address from all providers
+func (client *QuorumClient) FetchBalance(ctx context.Context, address Address) (AmountBlockchain, error) {
+	return client.readBalance(ctx, QuorumReadBalance, address, func(provider ClientBalance) (AmountBlockchain, error) {
+		return provider.FetchBalance(ctx, address)
+	})
+}
+
+// FetchNativeBalance fetches the native balance of an address from all providers
+func (client *QuorumClient) FetchNativeBalance(ctx context.Context, address Address) (AmountBlockchain, error) {
+	return client.readBalance(ctx, QuorumReadNativeBalance, address, func(provider ClientBalance) (AmountBlockchain, error) {
+		return provider.FetchNativeBalance(ctx, address)
+	})
+}
+
+func (client *QuorumClient) readBalance(ctx context.Context, read QuorumRead, address Address, fetch func(provider ClientBalance) (AmountBlockchain, error)) (AmountBlockchain, error) {
+	balances := make([]*AmountBlockchain, len(client.Providers))
+	err := client.read(ctx, read, address, func(i int, provider Client) (string, error) {
+		balanceClient, ok := provider.(ClientBalance)
+		if !ok {
+			return "", fmt.Errorf("%s is not supported for %s", read, client.Asset.ID())
+		}
+		balance, err := fetch(balanceClient)
+		if err != nil {
+			return "", err
+		}
+		balances[i] = &balance
+		return balance.String(), nil
+	})
+	if err != nil {
+		return AmountBlockchain{}, err
+	}
+	for _, balance := range balances {
+		if balance != nil {
+			return *balance, nil
+		}
+	}
+	return AmountBlockchain{}, fmt.Errorf("%w for %s", ErrNoQuorum, client.Asset.ID())
+}
+
+// read sends a read to all providers concurrently, and checks the quorum and the equality of their answers
+func (client *QuorumClient) read(ctx context.Context, read QuorumRead, address Address, fetch func(i int, provider Client) (string, error)) error {
+	answers := make([]ProviderAnswer, len(client.Providers))
+	var wg sync.WaitGroup
+	for i, provider := range client.Providers {
+		wg.Add(1)
+		go func(i int, provider ProviderClient) {
+			defer wg.Done()
+			answer, err := fetch(i, provider.Client)
+			answers[i] = ProviderAnswer{Provider: provider.Provider, Answer: answer}
+			if err != nil {
+				answers[i].Error = DefaultRedactor.Redact(err.Error())
+			}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	answered := 0
+	diverged := false
+	var first *ProviderAnswer
+	for i := range answers {
+		if answers[i].Error != "" {
+			continue
+		}
+		answered++
+		if first == nil {
+			first = &answers[i]
+		} else if answers[i].Answer != first.Answer {
+			diverged = true
+		}
+	}
+	if diverged {
+		divergence := &ProviderDivergence{
+			Asset:   client.Asset.ID(),
+			Read:    read,
+			Address: address,
+			Answers: answers,
+		}
+		if client.OnDivergence != nil {
+			client.OnDivergence(divergence)
+		}
+		return divergence
+	}
+	if answered < client.Quorum {
+		errs := []string{}
+		for _, answer := range answers {
+			if answer.Error != "" {
+				errs = append(errs, fmt.Sprintf("%s: %s", answer.Provider, answer.Error))
+			}
+		}
+		return fmt.Errorf("%w on %s of %s on %s: %d of %d, %s", ErrNoQuorum, read, address, client.Asset.ID(),
+			answered, client.Quorum, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// QuorumEndpoints returns the endpoints cross-checked by quorum reads, url and the failover endpoints in allowed
+// regions, or nil if quorum reads are disabled
+func (asset *NativeAssetConfig) QuorumEndpoints() ([]Endpoint, error) {
+	if asset.QuorumReads <= 0 {
+		return nil, nil
+	}
+	endpoints, err := asset.AllowedEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	if len(endpoints) < asset.QuorumReads {
+		return nil, fmt.Errorf("quorum_reads of %s is %d, but it has %d endpoints", asset.ID(), asset.QuorumReads, len(endpoints))
+	}
+	return endpoints, nil
+}
+
+// ProviderName returns the provider of an endpoint, or the host of its url, which doesn't hold API keys
+func (endpoint Endpoint) ProviderName() string {
+	if endpoint.Provider != "" {
+		return endpoint.Provider
+	}
+	if parsed, err := url.Parse(endpoint.URL); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	return Redacted
+}
+
+// EndpointAsset returns a copy of an asset sending its requests to endpoint only, without failover nor quorum reads
+// Tasks are returned as is
+func EndpointAsset(asset ITask, endpoint Endpoint) ITask {
+	pin := func(cfg *AssetConfig) {
+		cfg.URL = endpoint.URL
+		cfg.Region = endpoint.Region
+		if endpoint.Provider != "" {
+			cfg.Provider = endpoint.Provider
+		}
+		cfg.Endpoints = nil
+		cfg.QuorumReads = 0
+	}
+	switch asset := asset.(type) {
+	case *NativeAssetConfig:
+		native := *asset
+		pin(&native)
+		return &native
+	case *TokenAssetConfig:
+		token := *asset
+		pin(&token.AssetConfig)
+		if token.NativeAssetConfig != nil {
+			native := *token.NativeAssetConfig
+			pin(&native)
+			token.NativeAssetConfig = &native
+		}
+		return &token
+	}
+	return asset
+}
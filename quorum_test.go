@@ -0,0 +1,133 @@
+package crosschain
+
+import (
+	"context"
+	"errors"
+)
+
+type quorumTestInput struct {
+	nonce uint64
+}
+
+func (input *quorumTestInput) GetNonce() uint64 {
+	return input.nonce
+}
+
+type quorumTestClient struct {
+	balance string
+	nonce   uint64
+	err     error
+}
+
+func (client *quorumTestClient) FetchTxInput(ctx context.Context, from Address, to Address) (TxInput, error) {
+	return &quorumTestInput{nonce: client.nonce}, client.err
+}
+
+func (client *quorumTestClient) FetchTxInfo(ctx context.Context, txHash TxHash) (TxInfo, error) {
+	return TxInfo{TxID: string(txHash)}, nil
+}
+
+func (client *quorumTestClient) SubmitTx(ctx context.Context, tx Tx) error {
+	return nil
+}
+
+func (client *quorumTestClient) FetchBalance(ctx context.Context, address Address) (AmountBlockchain, error) {
+	return NewAmountBlockchainFromStr(client.balance), client.err
+}
+
+func (client *quorumTestClient) FetchNativeBalance(ctx context.Context, address Address) (AmountBlockchain, error) {
+	return client.FetchBalance(ctx, address)
+}
+
+func (s *CrosschainTestSuite) TestQuorumClient() {
+	require := s.Require()
+	ctx := context.Background()
+	asset := &NativeAssetConfig{Asset: "ETH", NativeAsset: ETH}
+	a := &quorumTestClient{balance: "100", nonce: 7}
+	b := &quorumTestClient{balance: "100", nonce: 7}
+	c := &quorumTestClient{balance: "100", nonce: 7}
+	client := NewQuorumClient(asset, a, []ProviderClient{{"a", a}, {"b", b}, {"c", c}}, 2)
+	divergences := []*ProviderDivergence{}
+	client.OnDivergence = func(divergence *ProviderDivergence) {
+		divergences = append(divergences, divergence)
+	}
+
+	balance, err := client.FetchBalance(ctx, "0xabc")
+	require.NoError(err)
+	require.Equal("100", balance.String())
+	input, err := client.FetchTxInput(ctx, "0xabc", "0xdef")
+	require.NoError(err)
+	require.EqualValues(7, input.(TxInputNonce).GetNonce())
+
+	// a provider failing is tolerated within the quorum
+	c.err = errors.New("connection refused")
+	_, err = client.FetchNativeBalance(ctx, "0xabc")
+	require.NoError(err)
+	b.err = errors.New("connection refused")
+	_, err = client.FetchNativeBalance(ctx, "0xabc")
+	require.ErrorIs(err, ErrNoQuorum)
+	require.ErrorContains(err, "1 of 2")
+	b.err, c.err = nil, nil
+	require.Empty(divergences)
+
+	// a single provider diverging fails the read
+	b.balance = "1000"
+	_, err = client.FetchBalance(ctx, "0xabc")
+	require.ErrorIs(err, ErrProviderDivergence)
+	require.Equal("providers diverged on balance of 0xabc on ETH: a: 100, b: 1000, c: 100", err.Error())
+	c.nonce = 6
+	_, err = client.FetchTxInput(ctx, "0xabc", "0xdef")
+	require.ErrorIs(err, ErrProviderDivergence)
+	require.Len(divergences, 2)
+	require.Equal(QuorumReadNonce, divergences[1].Read)
+	require.Equal(ProviderAnswer{Provider: "c", Answer: "6"}, divergences[1].Answers[2])
+
+	// other calls are sent to the primary client
+	info, err := client.FetchTxInfo(ctx, "0x123")
+	require.NoError(err)
+	require.Equal("0x123", info.TxID)
+	require.Same(a, UnwrapClient(client))
+	require.Same(a, UnwrapClient(a))
+}
+
+func (s *CrosschainTestSuite) TestQuorumEndpoints() {
+	require := s.Require()
+	asset := &NativeAssetConfig{
+		Asset:       "ETH",
+		NativeAsset: ETH,
+		URL:         "https://rpc.example/v2/key",
+		Provider:    "primary",
+		Endpoints: []Endpoint{
+			{URL: "https://eth.backup.example/key", Region: "eu"},
+		},
+	}
+	endpoints, err := asset.QuorumEndpoints()
+	require.NoError(err)
+	require.Nil(endpoints)
+
+	asset.QuorumReads = 2
+	endpoints, err = asset.QuorumEndpoints()
+	require.NoError(err)
+	require.Len(endpoints, 2)
+	require.Equal("primary", endpoints[0].ProviderName())
+	require.Equal("eth.backup.example", endpoints[1].ProviderName())
+
+	asset.QuorumReads = 3
+	_, err = asset.QuorumEndpoints()
+	require.ErrorContains(err, "quorum_reads of ETH is 3, but it has 2 endpoints")
+
+	pinned := EndpointAsset(asset, endpoints[1]).GetNativeAsset()
+	require.Equal("https://eth.backup.example/key", pinned.URL)
+	require.EqualValues("eu", pinned.Region)
+	require.Equal("primary", pinned.Provider)
+	require.Empty(pinned.Endpoints)
+	require.Zero(pinned.QuorumReads)
+	require.EqualValues(3, asset.QuorumReads)
+
+	token := &TokenAssetConfig{Asset: "USDC", NativeAssetConfig: asset}
+	token.URL = asset.URL
+	pinnedToken := EndpointAsset(token, endpoints[1]).(*TokenAssetConfig)
+	require.Equal("https://eth.backup.example/key", pinnedToken.URL)
+	require.Equal("https://eth.backup.example/key", pinnedToken.GetNativeAsset().URL)
+	require.Equal("https://rpc.example/v2/key", token.GetNativeAsset().URL)
+}
@@ -0,0 +1,163 @@
+package quote
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/budget"
+	"github.com/jumpcrypto/crosschain/factory"
+)
+
+// Fee is the fee charged and the time taken by a task of a route, e.g. a bridge transfer
+type Fee struct {
+	// Fixed is charged in the source asset
+	Fixed xc.AmountHumanReadable
+	// Bps of the amount, charged in the source asset
+	Bps uint64
+	// DeliveryTime is the expected time from submitting the task to the funds being available
+	DeliveryTime time.Duration
+	// Relayed tasks complete on the destination chain without tx of the sender, e.g. automatic relaying
+	Relayed bool
+}
+
+// Leg is a tx sent by the sender of a transfer
+type Leg struct {
+	Chain xc.NativeAsset `json:"chain"`
+	Asset xc.AssetID     `json:"asset"`
+	// Fee is the estimated network fee of the tx, in the native asset of Chain
+	Fee xc.AmountBlockchain `json:"fee"`
+}
+
+// Quote is the cost and time of a transfer of Amount of the Source asset, received as the Destination asset
+type Quote struct {
+	Source      xc.AssetID          `json:"source"`
+	Destination xc.AssetID          `json:"destination"`
+	Amount      xc.AmountBlockchain `json:"amount"`
+	// Tasks of the route, empty for transfers of an asset
+	Tasks []string `json:"tasks"`
+	// Legs are the txs of the sender, on the source chain and on the destination chain unless relayed
+	Legs []*Leg `json:"legs"`
+	// BridgeFee is the fee of the tasks, in the source asset
+	BridgeFee xc.AmountBlockchain `json:"bridge_fee"`
+	// IntegrationFee is the fee of the integrator, in the source asset
+	IntegrationFee xc.AmountBlockchain `json:"integration_fee"`
+	// Received is the expected amount received, in the destination asset
+	Received     xc.AmountBlockchain `json:"received"`
+	DeliveryTime time.Duration       `json:"delivery_time"`
+}
+
+// Quoter quotes transfers across assets and chains before they're initiated
+type Quoter struct {
+	Factory factory.FactoryContext
+	// Fees of tasks, by task name
+	Fees map[string]Fee
+	// IntegrationFeeBps of the amount, charged in the source asset
+	IntegrationFeeBps uint64
+	// Wallets are the addresses of the sender whose txs are estimated, by chain
+	Wallets map[xc.NativeAsset]xc.Address
+	// Planner estimates network fees
+	Planner *budget.Planner
+}
+
+// NewQuoter creates a new Quoter estimating the network fees of wallets
+func NewQuoter(f factory.FactoryContext, wallets map[xc.NativeAsset]xc.Address) *Quoter {
+	return &Quoter{
+		Factory: f,
+		Fees:    map[string]Fee{},
+		Wallets: wallets,
+		Planner: budget.NewPlanner(f),
+	}
+}
+
+// Quote returns the fees and delivery time of a transfer of amount of source, received as destination
+// The network fee of a leg is estimated as the fee of a transfer of its asset between the wallet of its chain
+func (quoter *Quoter) Quote(ctx context.Context, source xc.ITask, destination xc.ITask, amount xc.AmountBlockchain) (*Quote, error) {
+	quote := &Quote{
+		Source:         source.ID(),
+		Destination:    destination.ID(),
+		Amount:         amount,
+		Tasks:          []string{},
+		Legs:           []*Leg{},
+		BridgeFee:      xc.NewAmountBlockchainFromUint64(0),
+		IntegrationFee: bps(amount, quoter.IntegrationFeeBps),
+	}
+
+	relayed := false
+	if source.ID() != destination.ID() {
+		route, err := quoter.Factory.GetTaskConfigBySrcDstAssets(source, destination)
+		if err != nil {
+			return nil, err
+		}
+		relayed = true
+		for _, task := range route {
+			name := task.GetTask().Name
+			fee, ok := quoter.Fees[name]
+			if !ok {
+				return nil, fmt.Errorf("no fee configured for task '%s'", name)
+			}
+			fixed, err := quoter.Factory.ConvertAmountToBlockchain(source, fee.Fixed)
+			if err != nil {
+				return nil, err
+			}
+			quote.BridgeFee = add(quote.BridgeFee, add(fixed, bps(amount, fee.Bps)))
+			quote.DeliveryTime += fee.DeliveryTime
+			quote.Tasks = append(quote.Tasks, name)
+			relayed = relayed && fee.Relayed
+		}
+	}
+
+	fees := add(quote.BridgeFee, quote.IntegrationFee)
+	if fees.Cmp(&amount) >= 0 {
+		return nil, fmt.Errorf("amount %s of %s doesn't cover the fees %s", amount.String(), source.ID(), fees.String())
+	}
+	net := xc.AmountBlockchain(*new(big.Int).Sub(amount.Int(), fees.Int()))
+	human, err := quoter.Factory.ConvertAmountToHuman(source, net)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Received, err = quoter.Factory.ConvertAmountToBlockchain(destination, human); err != nil {
+		return nil, err
+	}
+
+	leg, err := quoter.estimateLeg(ctx, source, amount)
+	if err != nil {
+		return nil, err
+	}
+	quote.Legs = append(quote.Legs, leg)
+	if source.GetNativeAsset().NativeAsset != destination.GetNativeAsset().NativeAsset && !relayed {
+		leg, err := quoter.estimateLeg(ctx, destination, quote.Received)
+		if err != nil {
+			return nil, err
+		}
+		quote.Legs = append(quote.Legs, leg)
+	}
+	return quote, nil
+}
+
+// estimateLeg estimates the network fee of a transfer of amount of asset by the wallet of its chain
+func (quoter *Quoter) estimateLeg(ctx context.Context, asset xc.ITask, amount xc.AmountBlockchain) (*Leg, error) {
+	chain := asset.GetNativeAsset().NativeAsset
+	wallet, ok := quoter.Wallets[chain]
+	if !ok {
+		return nil, fmt.Errorf("no wallet to estimate fees on %s", chain)
+	}
+	fee, err := quoter.Planner.EstimateFee(ctx, &budget.Transfer{Asset: asset, From: wallet, To: wallet, Amount: amount})
+	if err != nil {
+		return nil, fmt.Errorf("could not estimate fee on %s: %v", chain, err)
+	}
+	return &Leg{Chain: chain, Asset: asset.ID(), Fee: fee}, nil
+}
+
+// bps returns the basis points of amount, rounded down
+func bps(amount xc.AmountBlockchain, bps uint64) xc.AmountBlockchain {
+	result := new(big.Int).Mul(amount.Int(), new(big.Int).SetUint64(bps))
+	return xc.AmountBlockchain(*result.Quo(result, big.NewInt(10_000)))
+}
+
+// add allocates the sum, as AmountBlockchain.Add may reuse the memory of its receiver
+func add(x xc.AmountBlockchain, y xc.AmountBlockchain) xc.AmountBlockchain {
+	return xc.AmountBlockchain(*new(big.Int).Add(x.Int(), y.Int()))
+}
@@ -0,0 +1,138 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/evm"
+	"github.com/jumpcrypto/crosschain/chain/solana"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestQuoteTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+const evmWallet = xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+const solWallet = xc.Address("Hzn3n914JaSpnxo5mBbmuCDmGL6mxWN9Ac2HzEXFSGtb")
+
+func evmInput() *evm.TxInput {
+	input := evm.NewTxInput()
+	input.GasFeeCap = xc.NewAmountBlockchainFromUint64(10)
+	return input
+}
+
+func newTestQuoter(clients map[xc.NativeAsset]*testutil.MockedClient) (*Quoter, *testutil.TestFactory) {
+	f := testutil.NewDefaultFactory()
+	f.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		client, ok := clients[asset.GetNativeAsset().NativeAsset]
+		if !ok {
+			return nil, errors.New("no client")
+		}
+		return client, nil
+	}
+	quoter := NewQuoter(&f, map[xc.NativeAsset]xc.Address{xc.ETH: evmWallet, xc.MATIC: evmWallet, xc.SOL: solWallet})
+	quoter.Fees = map[string]Fee{
+		"wormhole-approve":  {},
+		"wormhole-transfer": {Fixed: xc.NewAmountHumanReadableFromStr("0.001"), Bps: 10, DeliveryTime: 15 * time.Minute},
+	}
+	return quoter, &f
+}
+
+func (s *CrosschainTestSuite) TestQuote() {
+	require := s.Require()
+	matic := &testutil.MockedClient{}
+	matic.On("FetchTxInput", mock.Anything, evmWallet, evmWallet).Return(evmInput(), nil)
+	sol := &testutil.MockedClient{}
+	sol.On("FetchTxInput", mock.Anything, solWallet, solWallet).Return(solana.NewTxInput(), nil)
+	quoter, f := newTestQuoter(map[xc.NativeAsset]*testutil.MockedClient{xc.MATIC: matic, xc.SOL: sol})
+	quoter.IntegrationFeeBps = 5
+
+	source, _ := f.GetAssetConfig("WETH", "MATIC")
+	destination, _ := f.GetAssetConfig("WETH", "SOL")
+	amount := xc.NewAmountBlockchainFromStr("2000000000000000000") // 2 WETH
+	quote, err := quoter.Quote(s.Ctx, source, destination, amount)
+	require.NoError(err)
+	require.Equal([]string{"wormhole-approve", "wormhole-transfer"}, quote.Tasks)
+	// 0.001 + 10 bps
+	require.Equal("3000000000000000", quote.BridgeFee.String())
+	require.Equal("1000000000000000", quote.IntegrationFee.String())
+	// 1.996 WETH with 8 decimals
+	require.Equal("199600000", quote.Received.String())
+	require.Equal(15*time.Minute, quote.DeliveryTime)
+
+	require.Len(quote.Legs, 2)
+	require.Equal(xc.MATIC, quote.Legs[0].Chain)
+	require.Equal(xc.AssetID("WETH.MATIC"), quote.Legs[0].Asset)
+	require.Equal("3500000", quote.Legs[0].Fee.String())
+	require.Equal(xc.SOL, quote.Legs[1].Chain)
+	require.Equal("5000", quote.Legs[1].Fee.String())
+
+	// relayed transfers have no destination leg
+	fee := quoter.Fees["wormhole-transfer"]
+	fee.Relayed = true
+	quoter.Fees["wormhole-transfer"] = fee
+	quoter.Fees["wormhole-approve"] = Fee{Relayed: true}
+	quote, err = quoter.Quote(s.Ctx, source, destination, amount)
+	require.NoError(err)
+	require.Len(quote.Legs, 1)
+}
+
+func (s *CrosschainTestSuite) TestQuoteTransfer() {
+	require := s.Require()
+	eth := &testutil.MockedClient{}
+	eth.On("FetchTxInput", mock.Anything, evmWallet, evmWallet).Return(evmInput(), nil)
+	quoter, f := newTestQuoter(map[xc.NativeAsset]*testutil.MockedClient{xc.ETH: eth})
+
+	asset, _ := f.GetAssetConfig("ETH", "")
+	quote, err := quoter.Quote(s.Ctx, asset, asset, xc.NewAmountBlockchainFromUint64(1_000_000))
+	require.NoError(err)
+	require.Empty(quote.Tasks)
+	require.Equal("0", quote.BridgeFee.String())
+	require.Equal("1000000", quote.Received.String())
+	require.Equal(time.Duration(0), quote.DeliveryTime)
+	require.Len(quote.Legs, 1)
+	require.Equal("900000", quote.Legs[0].Fee.String())
+}
+
+func (s *CrosschainTestSuite) TestQuoteErrors() {
+	require := s.Require()
+	matic := &testutil.MockedClient{}
+	matic.On("FetchTxInput", mock.Anything, evmWallet, evmWallet).Return(evmInput(), errors.New("rpc unavailable"))
+	quoter, f := newTestQuoter(map[xc.NativeAsset]*testutil.MockedClient{xc.MATIC: matic})
+	source, _ := f.GetAssetConfig("WETH", "MATIC")
+	destination, _ := f.GetAssetConfig("WETH", "SOL")
+	amount := xc.NewAmountBlockchainFromStr("2000000000000000000")
+
+	_, err := quoter.Quote(s.Ctx, source, destination, amount)
+	require.EqualError(err, "could not estimate fee on MATIC: rpc unavailable")
+
+	_, err = quoter.Quote(s.Ctx, source, destination, xc.NewAmountBlockchainFromUint64(1000))
+	require.ErrorContains(err, "doesn't cover the fees")
+
+	delete(quoter.Wallets, xc.MATIC)
+	_, err = quoter.Quote(s.Ctx, source, destination, amount)
+	require.EqualError(err, "no wallet to estimate fees on MATIC")
+
+	delete(quoter.Fees, "wormhole-approve")
+	_, err = quoter.Quote(s.Ctx, source, destination, amount)
+	require.EqualError(err, "no fee configured for task 'wormhole-approve'")
+
+	eth, _ := f.GetAssetConfig("ETH", "")
+	_, err = quoter.Quote(s.Ctx, eth, destination, amount)
+	require.ErrorContains(err, "invalid path")
+}
@@ -0,0 +1,232 @@
+package crosschain
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces secrets by default
+const Redacted = "***"
+
+// DefaultRedactedQueryParams are the query params of URLs holding API keys or tokens
+var DefaultRedactedQueryParams = []string{"apikey", "api_key", "api-key", "key", "token", "access_token", "auth", "secret", "password"}
+
+// minSecretLength avoids redacting short common strings, e.g. an empty or test secret
+const minSecretLength = 4
+
+// Redactor removes secrets from text before it's logged, returned in errors or published in events:
+// registered secrets (e.g. AuthSecret), passwords of URLs and the values of sensitive query params
+type Redactor struct {
+	// Replacement of secrets, Redacted if empty
+	Replacement string
+	// QueryParams are the names of the query params redacted from URLs, case insensitive
+	QueryParams []string
+
+	mu sync.RWMutex
+	// secrets are sorted longest first, and counted by the number of times they were added
+	secrets []string
+	refs    map[string]int
+}
+
+// DefaultRedactor is the Redactor of asset configs, errors of clients and, once installed, of the standard logger
+// Secrets of the assets loaded by factories are registered to it, and removed when the assets are replaced
+var DefaultRedactor = NewRedactor()
+
+var _ logrus.Hook = &Redactor{}
+
+var installRedactor sync.Once
+
+// InstallRedactor adds DefaultRedactor as a hook of the standard logger of logrus, once
+// Other loggers can add it, or their own Redactor, with AddHook
+func InstallRedactor() {
+	installRedactor.Do(func() {
+		logrus.AddHook(DefaultRedactor)
+	})
+}
+
+// NewRedactor creates a Redactor of secrets, URLs and DefaultRedactedQueryParams
+func NewRedactor(secrets ...string) *Redactor {
+	redactor := &Redactor{
+		Replacement: Redacted,
+		QueryParams: DefaultRedactedQueryParams,
+		refs:        map[string]int{},
+	}
+	redactor.AddSecrets(secrets...)
+	return redactor
+}
+
+// AddSecrets registers secrets to redact, secrets too short to be told from common strings are ignored
+// A secret added n times is redacted until it's removed n times, e.g. shared by assets of several factories
+func (redactor *Redactor) AddSecrets(secrets ...string) {
+	redactor.mu.Lock()
+	defer redactor.mu.Unlock()
+	if redactor.refs == nil {
+		redactor.refs = map[string]int{}
+	}
+	for _, secret := range secrets {
+		secret = strings.TrimSpace(secret)
+		if len(secret) < minSecretLength {
+			continue
+		}
+		redactor.refs[secret]++
+		if redactor.refs[secret] == 1 {
+			redactor.secrets = append(redactor.secrets, secret)
+		}
+	}
+	// longest first, so that a secret containing another is fully redacted
+	sort.SliceStable(redactor.secrets, func(i, j int) bool {
+		return len(redactor.secrets[i]) > len(redactor.secrets[j])
+	})
+}
+
+// RemoveSecrets unregisters secrets added by AddSecrets, e.g. rotated or of assets removed
+func (redactor *Redactor) RemoveSecrets(secrets ...string) {
+	redactor.mu.Lock()
+	defer redactor.mu.Unlock()
+	for _, secret := range secrets {
+		secret = strings.TrimSpace(secret)
+		if redactor.refs[secret] == 0 {
+			continue
+		}
+		redactor.refs[secret]--
+		if redactor.refs[secret] > 0 {
+			continue
+		}
+		delete(redactor.refs, secret)
+		for i := range redactor.secrets {
+			if redactor.secrets[i] == secret {
+				redactor.secrets = append(redactor.secrets[:i], redactor.secrets[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (redactor *Redactor) replacement() string {
+	if redactor.Replacement == "" {
+		return Redacted
+	}
+	return redactor.Replacement
+}
+
+// Redact replaces the registered secrets and the credentials of URLs in text
+func (redactor *Redactor) Redact(text string) string {
+	if text == "" {
+		return text
+	}
+	redactor.mu.RLock()
+	for _, secret := range redactor.secrets {
+		text = strings.ReplaceAll(text, secret, redactor.replacement())
+	}
+	redactor.mu.RUnlock()
+	if strings.Contains(text, "://") {
+		words := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ' ' || r == '"' || r == '\'' || r == '\n' || r == '\t'
+		})
+		for _, word := range words {
+			index := strings.Index(word, "://")
+			if index <= 0 {
+				continue
+			}
+			// the url starts at its scheme, e.g. in url=https://...
+			start := index
+			for start > 0 && isSchemeChar(word[start-1]) {
+				start--
+			}
+			rawURL := word[start:]
+			if redacted := redactor.redactURL(rawURL); redacted != rawURL {
+				text = strings.ReplaceAll(text, rawURL, redacted)
+			}
+		}
+	}
+	return text
+}
+
+func isSchemeChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '+' || c == '-' || c == '.'
+}
+
+func (redactor *Redactor) redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	changed := false
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), redactor.replacement())
+		changed = true
+	}
+	query := parsed.Query()
+	for name := range query {
+		for _, param := range redactor.QueryParams {
+			if strings.EqualFold(name, param) {
+				query.Set(name, redactor.replacement())
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return rawURL
+	}
+	parsed.RawQuery = query.Encode()
+	// keep the replacement readable, it's not part of a request
+	redacted, _ := url.PathUnescape(parsed.String())
+	return redacted
+}
+
+// redactedError is an error with a redacted message, that still unwraps to the original error
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (err *redactedError) Error() string {
+	return err.message
+}
+
+func (err *redactedError) Unwrap() error {
+	return err.err
+}
+
+// RedactError returns err with a redacted message, nil if err is nil
+// errors.Is and errors.As still match the original error
+func (redactor *Redactor) RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	redacted := redactor.Redact(message)
+	if redacted == message {
+		return err
+	}
+	return &redactedError{message: redacted, err: err}
+}
+
+// RedactFields redacts the string and error values of log fields, in place
+func (redactor *Redactor) RedactFields(fields logrus.Fields) logrus.Fields {
+	for key, value := range fields {
+		switch value := value.(type) {
+		case string:
+			fields[key] = redactor.Redact(value)
+		case error:
+			fields[key] = redactor.RedactError(value)
+		}
+	}
+	return fields
+}
+
+// Levels of log entries redacted, as a logrus.Hook
+func (redactor *Redactor) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire redacts the message and fields of a log entry, as a logrus.Hook
+func (redactor *Redactor) Fire(entry *logrus.Entry) error {
+	entry.Message = redactor.Redact(entry.Message)
+	redactor.RedactFields(entry.Data)
+	return nil
+}
@@ -0,0 +1,169 @@
+package crosschain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Region is the jurisdiction an endpoint is hosted in, e.g. "us", "eu" or "sg"
+type Region string
+
+// Endpoint is a failover endpoint of a chain, tried in order after url
+type Endpoint struct {
+	URL      string `yaml:"url"`
+	Region   Region `yaml:"region"`
+	Provider string `yaml:"provider"`
+}
+
+// ErrNoAllowedEndpoint is returned when region constraints disallow all the endpoints of a chain
+var ErrNoAllowedEndpoint = errors.New("no endpoint in an allowed region")
+
+// ErrRegionDenied is returned for requests to a host outside the allowed regions, see HostAllowed
+var ErrRegionDenied = errors.New("host is not in an allowed region")
+
+// RegionAllowed returns true if traffic may be sent to an endpoint in region
+// Once allowed_regions is set, endpoints without a region are disallowed: their jurisdiction is unknown
+func (asset *NativeAssetConfig) RegionAllowed(region Region) bool {
+	for _, denied := range asset.DeniedRegions {
+		if strings.EqualFold(string(denied), string(region)) {
+			return false
+		}
+	}
+	if len(asset.AllowedRegions) == 0 {
+		return true
+	}
+	for _, allowed := range asset.AllowedRegions {
+		if strings.EqualFold(string(allowed), string(region)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRegionConstraints returns true if endpoints are pinned to regions
+func (asset *NativeAssetConfig) hasRegionConstraints() bool {
+	return len(asset.AllowedRegions) > 0 || len(asset.DeniedRegions) > 0
+}
+
+// HostAllowed returns true if requests may be sent to host, a host name with its port if any: the region of the host
+// is the region of url or of the endpoint on it, or else its region in host_regions
+func (asset *NativeAssetConfig) HostAllowed(host string) bool {
+	if !asset.hasRegionConstraints() {
+		return true
+	}
+	all := append([]Endpoint{{URL: asset.URL, Region: asset.Region}}, asset.Endpoints...)
+	for _, endpoint := range all {
+		if parsed, err := url.Parse(endpoint.URL); err == nil && strings.EqualFold(parsed.Host, host) {
+			return asset.RegionAllowed(endpoint.Region)
+		}
+	}
+	for hostName, region := range asset.HostRegions {
+		if strings.EqualFold(hostName, host) {
+			return asset.RegionAllowed(region)
+		}
+	}
+	return asset.RegionAllowed("")
+}
+
+// AllowedEndpoints returns url and the failover endpoints, in order, without the ones in disallowed regions
+func (asset *NativeAssetConfig) AllowedEndpoints() ([]Endpoint, error) {
+	endpoints := []Endpoint{}
+	all := append([]Endpoint{{URL: asset.URL, Region: asset.Region, Provider: asset.Provider}}, asset.Endpoints...)
+	for _, endpoint := range all {
+		if endpoint.URL == "" || !asset.RegionAllowed(endpoint.Region) {
+			continue
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return endpoints, fmt.Errorf("%w for %s", ErrNoAllowedEndpoint, asset.ID())
+	}
+	return endpoints, nil
+}
+
+// FailoverTransport is a http.RoundTripper sending requests to the first available endpoint
+// Requests to the primary url are rewritten to each endpoint in order, on connection errors and 429 or 5xx responses
+// Requests to other URLs, e.g. an indexer, are sent as is if AllowHost allows their host, or rejected with
+// ErrRegionDenied
+type FailoverTransport struct {
+	Transport http.RoundTripper
+	Primary   string
+	Endpoints []Endpoint
+	// AllowHost checks the host of requests to other URLs, all hosts are allowed if nil
+	AllowHost func(host string) bool
+}
+
+var _ http.RoundTripper = &FailoverTransport{}
+
+// NewFailoverTransport creates a FailoverTransport from primary to endpoints, transport defaults to http.DefaultTransport
+func NewFailoverTransport(transport http.RoundTripper, primary string, endpoints []Endpoint) *FailoverTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &FailoverTransport{
+		Transport: transport,
+		Primary:   strings.TrimSuffix(primary, "/"),
+		Endpoints: endpoints,
+	}
+}
+
+// RoundTrip sends req to the first endpoint available
+func (t *FailoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestURL := req.URL.String()
+	if t.Primary == "" || !strings.HasPrefix(requestURL, t.Primary) {
+		if t.AllowHost != nil && !t.AllowHost(req.URL.Host) {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, fmt.Errorf("%w: %s", ErrRegionDenied, req.URL.Host)
+		}
+		return t.Transport.RoundTrip(req)
+	}
+	suffix := strings.TrimPrefix(requestURL, t.Primary)
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	var lastErr error
+	for i, endpoint := range t.Endpoints {
+		endpointURL, err := url.Parse(strings.TrimSuffix(endpoint.URL, "/") + suffix)
+		if err != nil {
+			lastErr = fmt.Errorf("invalid endpoint: %v", DefaultRedactor.RedactError(err))
+			continue
+		}
+		attempt := req.Clone(req.Context())
+		attempt.URL = endpointURL
+		attempt.Host = endpointURL.Host
+		attempt.Body = io.NopCloser(bytes.NewReader(body))
+		attempt.ContentLength = int64(len(body))
+		resp, err := t.Transport.RoundTrip(attempt)
+		last := i == len(t.Endpoints)-1
+		if err != nil {
+			lastErr = err
+			if req.Context().Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !last && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("endpoint returned %s", resp.Status)
+			continue
+		}
+		return resp, nil
+	}
+	if lastErr == nil {
+		lastErr = ErrNoAllowedEndpoint
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,162 @@
+package crosschain
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (s *CrosschainTestSuite) TestRegionAllowed() {
+	require := s.Require()
+	asset := &NativeAssetConfig{}
+	require.True(asset.RegionAllowed(""))
+	require.True(asset.RegionAllowed("us"))
+
+	asset.DeniedRegions = []Region{"ir", "kp"}
+	require.False(asset.RegionAllowed("IR"))
+	require.True(asset.RegionAllowed("us"))
+	require.True(asset.RegionAllowed(""))
+
+	// pinned, unknown regions are disallowed
+	asset.AllowedRegions = []Region{"us", "eu"}
+	require.True(asset.RegionAllowed("EU"))
+	require.False(asset.RegionAllowed("sg"))
+	require.False(asset.RegionAllowed(""))
+}
+
+func (s *CrosschainTestSuite) TestAllowedEndpoints() {
+	require := s.Require()
+	asset := &NativeAssetConfig{
+		Asset:  "ETH",
+		URL:    "https://us.example.com",
+		Region: "us",
+		Endpoints: []Endpoint{
+			{URL: "https://sg.example.com", Region: "sg"},
+			{URL: "https://eu.example.com", Region: "eu", Provider: "other"},
+			{URL: "https://unknown.example.com"},
+		},
+	}
+	endpoints, err := asset.AllowedEndpoints()
+	require.NoError(err)
+	require.Len(endpoints, 4)
+	require.Equal("https://us.example.com", endpoints[0].URL)
+
+	asset.AllowedRegions = []Region{"us", "eu"}
+	endpoints, err = asset.AllowedEndpoints()
+	require.NoError(err)
+	require.Equal([]Endpoint{
+		{URL: "https://us.example.com", Region: "us"},
+		{URL: "https://eu.example.com", Region: "eu", Provider: "other"},
+	}, endpoints)
+
+	asset.DeniedRegions = []Region{"us", "eu"}
+	_, err = asset.AllowedEndpoints()
+	require.True(errors.Is(err, ErrNoAllowedEndpoint))
+	_, err = asset.HTTPTransport(http.DefaultTransport)
+	require.True(errors.Is(err, ErrNoAllowedEndpoint))
+}
+
+func (s *CrosschainTestSuite) TestFailoverTransport() {
+	require := s.Require()
+	requests := map[string][]string{}
+	newServer := func(name string, status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			requests[name] = append(requests[name], req.URL.Path+" "+string(body))
+			rw.WriteHeader(status)
+			rw.Write([]byte(name))
+		}))
+	}
+	primary := newServer("primary", http.StatusServiceUnavailable)
+	defer primary.Close()
+	denied := newServer("denied", http.StatusOK)
+	defer denied.Close()
+	secondary := newServer("secondary", http.StatusOK)
+	defer secondary.Close()
+
+	asset := &NativeAssetConfig{
+		URL:    primary.URL,
+		Region: "us",
+		Endpoints: []Endpoint{
+			{URL: denied.URL + "/key", Region: "ru"},
+			{URL: secondary.URL + "/key", Region: "eu"},
+		},
+		DeniedRegions: []Region{"ru"},
+	}
+	transport, err := asset.HTTPTransport(http.DefaultTransport)
+	require.NoError(err)
+	client := &http.Client{Transport: transport}
+
+	res, err := client.Post(primary.URL+"/rpc", "application/json", strings.NewReader(`{"id":1}`))
+	require.NoError(err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.Equal("secondary", string(body))
+	require.Equal([]string{`/rpc {"id":1}`}, requests["primary"])
+	require.Equal([]string{`/key/rpc {"id":1}`}, requests["secondary"])
+	require.Empty(requests["denied"])
+
+	// the last endpoint's response is returned
+	secondaryDown := newServer("secondary", http.StatusBadGateway)
+	defer secondaryDown.Close()
+	asset.Endpoints[1].URL = secondaryDown.URL
+	transport, err = asset.HTTPTransport(http.DefaultTransport)
+	require.NoError(err)
+	res, err = (&http.Client{Transport: transport}).Get(primary.URL)
+	require.NoError(err)
+	res.Body.Close()
+	require.Equal(http.StatusBadGateway, res.StatusCode)
+	require.Empty(requests["denied"])
+
+	// other URLs are sent as is, to hosts in allowed regions
+	indexer := newServer("indexer", http.StatusOK)
+	defer indexer.Close()
+	_, err = (&http.Client{Transport: transport}).Get(denied.URL + "/indexer")
+	require.ErrorIs(err, ErrRegionDenied)
+	require.Empty(requests["denied"])
+	res, err = (&http.Client{Transport: transport}).Get(indexer.URL + "/indexer")
+	require.NoError(err)
+	res.Body.Close()
+	require.Equal([]string{"/indexer "}, requests["indexer"])
+
+	// once pinned to regions, hosts of unknown regions are denied
+	asset.AllowedRegions = []Region{"us", "eu"}
+	transport, err = asset.HTTPTransport(http.DefaultTransport)
+	require.NoError(err)
+	_, err = (&http.Client{Transport: transport}).Get(indexer.URL + "/indexer")
+	require.ErrorIs(err, ErrRegionDenied)
+	asset.HostRegions = map[string]Region{strings.TrimPrefix(indexer.URL, "http://"): "eu"}
+	res, err = (&http.Client{Transport: transport}).Get(indexer.URL + "/indexer")
+	require.NoError(err)
+	res.Body.Close()
+	require.Len(requests["indexer"], 2)
+}
+
+func (s *CrosschainTestSuite) TestHostAllowed() {
+	require := s.Require()
+	asset := &NativeAssetConfig{
+		URL:    "https://us.example.com/v2/key",
+		Region: "us",
+		Endpoints: []Endpoint{
+			{URL: "https://sg.example.com", Region: "sg"},
+		},
+		HostRegions: map[string]Region{"fcd.example.com": "eu", "indexer.example.com:8080": "sg"},
+	}
+	// no constraints
+	require.True(asset.HostAllowed("sg.example.com"))
+	require.True(asset.HostAllowed("unknown.example.com"))
+
+	asset.DeniedRegions = []Region{"sg"}
+	require.True(asset.HostAllowed("US.example.com"))
+	require.False(asset.HostAllowed("sg.example.com"))
+	require.True(asset.HostAllowed("fcd.example.com"))
+	require.False(asset.HostAllowed("indexer.example.com:8080"))
+	require.True(asset.HostAllowed("unknown.example.com"))
+
+	asset.AllowedRegions = []Region{"us"}
+	require.True(asset.HostAllowed("us.example.com"))
+	require.False(asset.HostAllowed("fcd.example.com"))
+	require.False(asset.HostAllowed("unknown.example.com"))
+}
@@ -0,0 +1,182 @@
+package crosschain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ChainInfo is the description of a chain in the chain registry, see RegisterChain
+type ChainInfo struct {
+	NativeAsset NativeAsset
+	ChainType   ChainType
+	Driver      Driver
+	// Decimals of the native asset, the default of chains configured without decimals
+	Decimals int32
+	// CoinType is the SLIP-44 coin type used to derive keys, Ethereum's for EVM chains registered without one
+	CoinType uint32
+}
+
+// DriverConstructors creates the components of a driver implemented outside of crosschain, see RegisterDriver
+// Constructors that aren't set make the factory return an unsupported error
+type DriverConstructors struct {
+	SignatureAlgorithm SignatureType
+	NewClient          func(asset ITask) (Client, error)
+	NewTxBuilder       func(asset ITask) (TxBuilder, error)
+	NewSigner          func(asset ITask) (Signer, error)
+	NewAddressBuilder  func(asset ITask) (AddressBuilder, error)
+	// NewTxInput returns an empty TxInput of the driver, to unmarshal serialized inputs
+	NewTxInput func() TxInput
+}
+
+var registry = struct {
+	sync.RWMutex
+	chains  map[NativeAsset]ChainInfo
+	drivers map[Driver]DriverConstructors
+}{
+	chains:  map[NativeAsset]ChainInfo{},
+	drivers: map[Driver]DriverConstructors{},
+}
+
+// builtinChains are the chains supported by crosschain, registered at init
+var builtinChains = []ChainInfo{
+	// UTXO
+	{NativeAsset: BCH, ChainType: ChainTypeUTXO, Driver: DriverBitcoin, Decimals: 8, CoinType: 145},
+	{NativeAsset: BTC, ChainType: ChainTypeUTXO, Driver: DriverBitcoin, Decimals: 8, CoinType: 0},
+	{NativeAsset: DASH, ChainType: ChainTypeUTXO, Driver: DriverBitcoin, Decimals: 8, CoinType: 5},
+	{NativeAsset: DOGE, ChainType: ChainTypeUTXO, Driver: DriverBitcoin, Decimals: 8, CoinType: 3},
+	{NativeAsset: LTC, ChainType: ChainTypeUTXO, Driver: DriverBitcoin, Decimals: 8, CoinType: 2},
+	// the X-Chain and the P-Chain of Avalanche share the addresses of keys
+	{NativeAsset: PAVAX, ChainType: ChainTypeUTXO, Driver: DriverAvalanche, Decimals: 9, CoinType: 9000},
+	{NativeAsset: XAVAX, ChainType: ChainTypeUTXO, Driver: DriverAvalanche, Decimals: 9, CoinType: 9000},
+
+	// Account-based
+	{NativeAsset: ACA, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 60},
+	{NativeAsset: ALGO, ChainType: ChainTypeAccount, Driver: DriverAlgorand, Decimals: 6, CoinType: 283},
+	{NativeAsset: APTOS, ChainType: ChainTypeAccount, Driver: DriverAptos, Decimals: 8, CoinType: 637},
+	{NativeAsset: ArbETH, ChainType: ChainTypeAccount, Driver: DriverEVM, Decimals: 18, CoinType: 60},
+	{NativeAsset: ATOM, ChainType: ChainTypeAccount, Driver: DriverCosmos, Decimals: 6, CoinType: 118},
+	{NativeAsset: AurETH, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 60},
+	{NativeAsset: AVAX, ChainType: ChainTypeAccount, Driver: DriverEVM, Decimals: 18, CoinType: 60},
+	{NativeAsset: BNB, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 60},
+	{NativeAsset: CELO, ChainType: ChainTypeAccount, Driver: DriverEVM, Decimals: 18, CoinType: 52752},
+	{NativeAsset: CHZ, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 60},
+	{NativeAsset: CHZ2, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 60},
+	{NativeAsset: DOT, ChainType: ChainTypeAccount, Driver: DriverSubstrate, Decimals: 10, CoinType: 354},
+	{NativeAsset: ETC, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 61},
+	{NativeAsset: ETH, ChainType: ChainTypeAccount, Driver: DriverEVM, Decimals: 18, CoinType: 60},
+	{NativeAsset: ETHW, ChainType: ChainTypeAccount, Driver: DriverEVM, Decimals: 18, CoinType: 60},
+	{NativeAsset: FTM, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 60},
+	// ethermint chains derive keys like Ethereum
+	{NativeAsset: INJ, ChainType: ChainTypeAccount, Driver: DriverCosmos, Decimals: 18, CoinType: 60},
+	{NativeAsset: KAR, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 60},
+	{NativeAsset: KLAY, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 8217},
+	{NativeAsset: KSM, ChainType: ChainTypeAccount, Driver: DriverSubstrate, Decimals: 12, CoinType: 434},
+	{NativeAsset: LUNA, ChainType: ChainTypeAccount, Driver: DriverCosmos, Decimals: 6, CoinType: 330},
+	{NativeAsset: LUNC, ChainType: ChainTypeAccount, Driver: DriverCosmos, Decimals: 6, CoinType: 330},
+	{NativeAsset: MATIC, ChainType: ChainTypeAccount, Driver: DriverEVM, Decimals: 18, CoinType: 60},
+	{NativeAsset: XDC, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 550},
+	{NativeAsset: NEAR, ChainType: ChainTypeAccount, Driver: DriverNear, Decimals: 24, CoinType: 397},
+	{NativeAsset: OAS, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 60},
+	{NativeAsset: OasisROSE, ChainType: ChainTypeAccount, Driver: DriverEVM, Decimals: 18, CoinType: 60},
+	{NativeAsset: OptETH, ChainType: ChainTypeAccount, Driver: DriverEVM, Decimals: 18, CoinType: 60},
+	{NativeAsset: OSMO, ChainType: ChainTypeAccount, Driver: DriverCosmos, Decimals: 6, CoinType: 118},
+	{NativeAsset: ROSE, ChainType: ChainTypeAccount, Driver: DriverEVMLegacy, Decimals: 18, CoinType: 60},
+	{NativeAsset: SOL, ChainType: ChainTypeAccount, Driver: DriverSolana, Decimals: 9, CoinType: 501},
+	{NativeAsset: STRK, ChainType: ChainTypeAccount, Driver: DriverStarknet, Decimals: 18, CoinType: 9004},
+	{NativeAsset: SUI, ChainType: ChainTypeAccount, Driver: DriverSui, Decimals: 9, CoinType: 784},
+	{NativeAsset: TIA, ChainType: ChainTypeAccount, Driver: DriverCosmos, Decimals: 6, CoinType: 118},
+	{NativeAsset: TRX, ChainType: ChainTypeAccount, Driver: DriverTron, Decimals: 6, CoinType: 195},
+	{NativeAsset: XLM, ChainType: ChainTypeAccount, Driver: DriverStellar, Decimals: 7, CoinType: 148},
+	{NativeAsset: XPLA, ChainType: ChainTypeAccount, Driver: DriverCosmos, Decimals: 18, CoinType: 60},
+}
+
+func init() {
+	for _, info := range builtinChains {
+		if err := RegisterChain(info); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterChain adds a chain to the registry, e.g. at init of the package of a custom or private chain, or replaces
+// the registered chain of the same NativeAsset
+// The driver of the chain must be supported: built in, or registered with RegisterDriver before
+func RegisterChain(info ChainInfo) error {
+	if info.NativeAsset == "" {
+		return errors.New("missing native asset of chain")
+	}
+	if !info.Driver.IsSupported() {
+		return fmt.Errorf("unsupported driver of chain %s: '%s'", info.NativeAsset, info.Driver)
+	}
+	if info.ChainType == "" {
+		info.ChainType = ChainTypeAccount
+	}
+	if info.CoinType == 0 && (info.Driver == DriverEVM || info.Driver == DriverEVMLegacy) {
+		info.CoinType = 60
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.chains[info.NativeAsset] = info
+	return nil
+}
+
+// LookupChain returns the registered chain of a NativeAsset
+func LookupChain(native NativeAsset) (ChainInfo, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	info, ok := registry.chains[native]
+	return info, ok
+}
+
+// RegisteredChains returns the registered chains, sorted by NativeAsset
+func RegisteredChains() []ChainInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+	chains := make([]ChainInfo, 0, len(registry.chains))
+	for _, info := range registry.chains {
+		chains = append(chains, info)
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i].NativeAsset < chains[j].NativeAsset
+	})
+	return chains
+}
+
+// RegisterDriver adds a driver implemented outside of crosschain, whose chains can then be registered with
+// RegisterChain and configured with its driver name
+// Built in drivers can't be replaced
+func RegisterDriver(driver Driver, constructors DriverConstructors) error {
+	if driver == "" {
+		return errors.New("missing driver name")
+	}
+	for _, builtin := range SupportedDrivers {
+		if driver == builtin {
+			return fmt.Errorf("driver '%s' is built in", driver)
+		}
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.drivers[driver] = constructors
+	return nil
+}
+
+// LookupDriver returns the constructors of a driver registered with RegisterDriver
+func LookupDriver(driver Driver) (DriverConstructors, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	constructors, ok := registry.drivers[driver]
+	return constructors, ok
+}
+
+// IsSupported returns true for the built in drivers and the drivers registered with RegisterDriver
+func (driver Driver) IsSupported() bool {
+	for _, builtin := range SupportedDrivers {
+		if driver == builtin {
+			return true
+		}
+	}
+	_, ok := LookupDriver(driver)
+	return ok
+}
@@ -0,0 +1,59 @@
+package crosschain
+
+func (s *CrosschainTestSuite) TestRegistryBuiltinChains() {
+	require := s.Require()
+	info, ok := LookupChain(ETH)
+	require.True(ok)
+	require.Equal(ChainInfo{NativeAsset: ETH, ChainType: ChainTypeAccount, Driver: DriverEVM, Decimals: 18, CoinType: 60}, info)
+	require.Equal(DriverEVM, ArbETH.Driver())
+	require.EqualValues(8, BTC.Decimals())
+	require.EqualValues(0, NativeAsset("unknown").Decimals())
+	require.Equal(Driver(""), NativeAsset("unknown").Driver())
+
+	chains := RegisteredChains()
+	require.GreaterOrEqual(len(chains), len(builtinChains))
+	for i := 1; i < len(chains); i++ {
+		require.Less(chains[i-1].NativeAsset, chains[i].NativeAsset)
+	}
+}
+
+func (s *CrosschainTestSuite) TestRegisterChain() {
+	require := s.Require()
+	native := NativeAsset("XCTESTCHAIN")
+	require.Equal(AssetTypeToken, Asset(native).AssetType())
+	require.Equal(ChainTypeUnknown, native.ChainType())
+
+	require.NoError(RegisterChain(ChainInfo{NativeAsset: native, Driver: DriverEVMLegacy, Decimals: 18}))
+	require.Equal(AssetTypeNative, Asset(native).AssetType())
+	require.Equal(ChainTypeAccount, native.ChainType())
+	require.Equal(DriverEVMLegacy, native.Driver())
+	require.EqualValues(60, native.CoinType())
+	require.Equal("m/44'/60'/0'/0/0", native.DerivationPath())
+	require.Equal(K256, native.SignatureAlgorithm())
+	require.Equal(AssetID("USDC.XCTESTCHAIN"), GetAssetIDFromAsset("USDC", string(native)))
+
+	require.EqualError(RegisterChain(ChainInfo{Driver: DriverEVM}), "missing native asset of chain")
+	require.EqualError(RegisterChain(ChainInfo{NativeAsset: "XCTESTOTHER", Driver: "xctest-unknown"}), "unsupported driver of chain XCTESTOTHER: 'xctest-unknown'")
+}
+
+func (s *CrosschainTestSuite) TestRegisterDriver() {
+	require := s.Require()
+	driver := Driver("xctest-driver")
+	require.False(driver.IsSupported())
+	_, ok := LookupDriver(driver)
+	require.False(ok)
+
+	require.NoError(RegisterDriver(driver, DriverConstructors{SignatureAlgorithm: Ed255}))
+	require.True(driver.IsSupported())
+	require.Equal(Ed255, driver.SignatureAlgorithm())
+	_, ok = LookupDriver(driver)
+	require.True(ok)
+
+	// chains of the driver
+	require.NoError(RegisterChain(ChainInfo{NativeAsset: "XCTESTDRIVERCHAIN", Driver: driver, Decimals: 6, CoinType: 1234}))
+	require.Equal(Ed255, NativeAsset("XCTESTDRIVERCHAIN").SignatureAlgorithm())
+	require.EqualValues(1234, NativeAsset("XCTESTDRIVERCHAIN").CoinType())
+
+	require.EqualError(RegisterDriver(DriverEVM, DriverConstructors{}), "driver 'evm' is built in")
+	require.EqualError(RegisterDriver("", DriverConstructors{}), "missing driver name")
+}
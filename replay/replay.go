@@ -0,0 +1,145 @@
+package replay
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+)
+
+// ErrHashMismatch is returned when the rebuilt tx of a Record doesn't have the recorded hash
+var ErrHashMismatch = errors.New("rebuilt tx hash does not match")
+
+// Record is the stored state a transfer was built and signed from, so it can be rebuilt for forensics
+type Record struct {
+	Chain  xc.NativeAsset      `json:"chain"`
+	Asset  string              `json:"asset"`
+	From   xc.Address          `json:"from"`
+	To     xc.Address          `json:"to"`
+	Amount xc.AmountBlockchain `json:"amount"`
+	// TxInput as marshalled by the factory, including its driver
+	TxInput    json.RawMessage  `json:"tx_input"`
+	Signatures []xc.TxSignature `json:"signatures"`
+	TxHash     xc.TxHash        `json:"tx_hash"`
+}
+
+// NewRecord records the parameters of a transfer of asset, once signed
+// input must be recorded as passed to the TxBuilder, before any change by a later build
+func NewRecord(f factory.FactoryContext, asset xc.ITask, from xc.Address, to xc.Address, amount xc.AmountBlockchain, input xc.TxInput, signatures []xc.TxSignature, txHash xc.TxHash) (*Record, error) {
+	data, err := f.MarshalTxInput(input)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal tx input: %v", err)
+	}
+	return &Record{
+		Chain:      asset.GetNativeAsset().NativeAsset,
+		Asset:      asset.GetAssetConfig().Asset,
+		From:       from,
+		To:         to,
+		Amount:     amount,
+		TxInput:    data,
+		Signatures: signatures,
+		TxHash:     txHash,
+	}, nil
+}
+
+// Result is the outcome of replaying a Record
+type Result struct {
+	Record *Record `json:"record"`
+	// TxHash is the hash of the rebuilt tx
+	TxHash xc.TxHash `json:"tx_hash"`
+	// Match is true if the rebuilt tx has the recorded hash
+	Match bool `json:"match"`
+	// TxInfo is the tx on chain, if fetched
+	TxInfo *xc.TxInfo `json:"tx_info,omitempty"`
+}
+
+// Replayer rebuilds transfers from their Record and checks them against the txs on chain
+type Replayer struct {
+	Factory factory.FactoryContext
+}
+
+// NewReplayer creates a new Replayer creating the builders and clients of assets with f
+func NewReplayer(f factory.FactoryContext) *Replayer {
+	return &Replayer{
+		Factory: f,
+	}
+}
+
+// Rebuild reconstructs the TxInput of record and rebuilds the signed tx
+func (replayer *Replayer) Rebuild(record *Record) (xc.Tx, error) {
+	asset, err := replayer.Factory.GetAssetConfig(record.Asset, string(record.Chain))
+	if err != nil {
+		return nil, fmt.Errorf("could not find asset %s of %s: %v", record.Asset, record.Chain, err)
+	}
+	input, err := replayer.Factory.UnmarshalTxInput(record.TxInput)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal tx input: %v", err)
+	}
+	builder, err := replayer.Factory.NewTxBuilder(asset)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := builder.NewTransfer(record.From, record.To, record.Amount, input)
+	if err != nil {
+		return nil, fmt.Errorf("could not rebuild tx: %v", err)
+	}
+	if len(record.Signatures) == 0 {
+		return nil, errors.New("record has no signatures")
+	}
+	if err := tx.AddSignatures(record.Signatures...); err != nil {
+		return nil, fmt.Errorf("could not add signatures: %v", err)
+	}
+	return tx, nil
+}
+
+// Replay rebuilds the tx of record and compares its hash with the recorded hash,
+// then fetches the tx on chain to prove that the recorded parameters produced it
+// A mismatch is reported by the Result and ErrHashMismatch
+func (replayer *Replayer) Replay(ctx context.Context, record *Record) (*Result, error) {
+	tx, err := replayer.Rebuild(record)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{
+		Record: record,
+		TxHash: tx.Hash(),
+		Match:  SameTxHash(tx.Hash(), record.TxHash),
+	}
+	if !result.Match {
+		return result, fmt.Errorf("%w: rebuilt %s, recorded %s", ErrHashMismatch, result.TxHash, record.TxHash)
+	}
+
+	asset, err := replayer.Factory.GetAssetConfig(record.Asset, string(record.Chain))
+	if err != nil {
+		return result, err
+	}
+	client, err := replayer.Factory.NewClient(asset)
+	if err != nil {
+		return result, err
+	}
+	info, err := client.FetchTxInfo(ctx, record.TxHash)
+	if err != nil {
+		return result, fmt.Errorf("could not fetch tx %s on chain: %v", record.TxHash, err)
+	}
+	result.TxInfo = &info
+	return result, nil
+}
+
+// SameTxHash returns true if two tx hashes are equal, ignoring the 0x prefix and the case of hex hashes
+func SameTxHash(a xc.TxHash, b xc.TxHash) bool {
+	return normalizeTxHash(a) == normalizeTxHash(b)
+}
+
+// normalizeTxHash lowercases hex hashes, other encodings such as base58 are case sensitive
+func normalizeTxHash(txHash xc.TxHash) string {
+	hash := strings.TrimPrefix(strings.TrimPrefix(string(txHash), "0x"), "0X")
+	if _, err := hex.DecodeString(hash); err == nil {
+		return strings.ToLower(hash)
+	}
+	return string(txHash)
+}
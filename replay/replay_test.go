@@ -0,0 +1,95 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/evm"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestReplayTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+// signedTestTransfer builds and signs a transfer of ETH, and returns its record
+func (s *CrosschainTestSuite) signedTestTransfer(f *testutil.TestFactory) *Record {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	from := xc.Address(crypto.PubkeyToAddress(key.PublicKey).Hex())
+	to := xc.Address("0x24b3A3f3B8e2D2eC7e44A1C8fBBa0C8d2E7BA0BD")
+	amount := xc.NewAmountBlockchainFromUint64(1_500_000)
+	asset, err := f.GetAssetConfig("", "ETH")
+	require.NoError(err)
+
+	input := evm.NewTxInput()
+	input.Nonce = 12
+	input.GasTipCap = xc.NewAmountBlockchainFromUint64(1_000_000_000)
+	input.GasFeeCap = xc.NewAmountBlockchainFromUint64(40_000_000_000)
+	builder, _ := f.NewTxBuilder(asset)
+	tx, err := builder.NewTransfer(from, to, amount, input)
+	require.NoError(err)
+	sighashes, _ := tx.Sighashes()
+	signature, err := crypto.Sign(sighashes[0], key)
+	require.NoError(err)
+	require.NoError(tx.AddSignatures(signature))
+
+	record, err := NewRecord(f, asset, from, to, amount, input, []xc.TxSignature{signature}, tx.Hash())
+	require.NoError(err)
+	return record
+}
+
+func (s *CrosschainTestSuite) TestReplay() {
+	require := s.Require()
+	f := testutil.NewDefaultFactory()
+	record := s.signedTestTransfer(&f)
+	client := &testutil.MockedClient{}
+	client.On("FetchTxInfo", mock.Anything, record.TxHash).Return(xc.TxInfo{TxID: string(record.TxHash), BlockIndex: 100}, nil)
+	f.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		return client, nil
+	}
+	replayer := NewReplayer(&f)
+
+	result, err := replayer.Replay(s.Ctx, record)
+	require.NoError(err)
+	require.True(result.Match)
+	require.Equal(record.TxHash, result.TxHash)
+	require.EqualValues(100, result.TxInfo.BlockIndex)
+
+	// another recorded nonce produces another tx
+	tampered := *record
+	tampered.TxInput = []byte(strings.Replace(string(record.TxInput), `"Nonce":12`, `"Nonce":13`, 1))
+	require.NotEqual(record.TxInput, tampered.TxInput)
+	result, err = replayer.Replay(s.Ctx, &tampered)
+	require.True(errors.Is(err, ErrHashMismatch))
+	require.False(result.Match)
+	require.Nil(result.TxInfo)
+
+	noSignatures := *record
+	noSignatures.Signatures = nil
+	_, err = replayer.Replay(s.Ctx, &noSignatures)
+	require.EqualError(err, "record has no signatures")
+}
+
+func (s *CrosschainTestSuite) TestSameTxHash() {
+	require := s.Require()
+	require.True(SameTxHash("0xABCDEF", "abcdef"))
+	require.True(SameTxHash("5xYz", "5xYz"))
+	require.False(SameTxHash("5xYz", "5xyz"))
+	require.False(SameTxHash("0xabcdef", "0xabcdee"))
+}
@@ -0,0 +1,137 @@
+package rewards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+)
+
+// Target is an address whose staking rewards are tracked
+type Target struct {
+	Asset   xc.ITask
+	Address xc.Address
+	// Compound delegates the claimed native rewards back to the same validators
+	Compound bool
+}
+
+// Policy decides when rewards are worth claiming given the claim tx cost
+type Policy struct {
+	// MinRewardToCostRatio is the minimum ratio of native rewards to the claim tx fee
+	MinRewardToCostRatio float64
+	// MinReward is the minimum amount of native rewards to claim, if set
+	MinReward xc.AmountBlockchain
+}
+
+// DefaultPolicy claims once native rewards are worth 10x the claim tx fee
+var DefaultPolicy = Policy{
+	MinRewardToCostRatio: 10,
+}
+
+// Estimate is the evaluation of the claimable rewards of a Target
+type Estimate struct {
+	Target  Target
+	Rewards []xc.ClaimableReward
+	// Total of native rewards
+	Total xc.AmountBlockchain
+	// Cost is the fee of the claim tx
+	Cost xc.AmountBlockchain
+	// Tx is the unsigned claim (or compound) tx
+	Tx xc.Tx
+	// ShouldClaim is set if rewards are worth claiming according to the Policy
+	ShouldClaim bool
+}
+
+// txWithFee is a Tx that can report its fee before being submitted
+type txWithFee interface {
+	Fee() xc.AmountBlockchain
+}
+
+// Rewards queries claimable rewards and builds claim txs for any chain supporting them
+type Rewards struct {
+	Factory factory.FactoryContext
+	Policy  Policy
+}
+
+// NewRewards creates a new Rewards
+func NewRewards(f factory.FactoryContext, policy Policy) *Rewards {
+	return &Rewards{
+		Factory: f,
+		Policy:  policy,
+	}
+}
+
+// ShouldClaim returns true if total native rewards are worth claiming at the given cost
+func (policy Policy) ShouldClaim(total xc.AmountBlockchain, cost xc.AmountBlockchain) bool {
+	if total.Sign() <= 0 {
+		return false
+	}
+	minReward := policy.MinReward
+	if total.Cmp(&minReward) < 0 {
+		return false
+	}
+	minTotal := new(big.Float).Mul(new(big.Float).SetInt(cost.Int()), big.NewFloat(policy.MinRewardToCostRatio))
+	return new(big.Float).SetInt(total.Int()).Cmp(minTotal) >= 0
+}
+
+// Estimate fetches the rewards of target, builds the claim tx and evaluates it against the Policy
+func (r *Rewards) Estimate(ctx context.Context, target Target) (Estimate, error) {
+	estimate := Estimate{
+		Target: target,
+		Total:  xc.NewAmountBlockchainFromUint64(0),
+		Cost:   xc.NewAmountBlockchainFromUint64(0),
+	}
+	client, err := r.Factory.NewClient(target.Asset)
+	if err != nil {
+		return estimate, err
+	}
+	rewardsClient, ok := xc.UnwrapClient(client).(xc.ClientRewards)
+	if !ok {
+		return estimate, fmt.Errorf("rewards are not supported for %s", target.Asset.ID())
+	}
+	builder, err := r.Factory.NewTxBuilder(target.Asset)
+	if err != nil {
+		return estimate, err
+	}
+	// claims aren't transfers built through middlewares
+	rewardsBuilder, ok := xc.UnwrapTxBuilder(builder).(xc.TxRewardsBuilder)
+	if !ok {
+		return estimate, fmt.Errorf("claiming rewards is not supported for %s", target.Asset.ID())
+	}
+
+	estimate.Rewards, err = rewardsClient.FetchClaimableRewards(ctx, target.Address)
+	if err != nil {
+		return estimate, err
+	}
+	for _, reward := range estimate.Rewards {
+		if reward.Contract == "" {
+			estimate.Total = estimate.Total.Add(&reward.Amount)
+		}
+	}
+	if len(estimate.Rewards) == 0 {
+		return estimate, nil
+	}
+
+	input, err := client.FetchTxInput(ctx, target.Address, target.Address)
+	if err != nil {
+		return estimate, err
+	}
+	if target.Compound {
+		estimate.Tx, err = rewardsBuilder.NewCompoundRewards(target.Address, estimate.Rewards, input)
+	} else {
+		estimate.Tx, err = rewardsBuilder.NewClaimRewards(target.Address, estimate.Rewards, input)
+	}
+	if err != nil {
+		return estimate, err
+	}
+	tx, ok := estimate.Tx.(txWithFee)
+	if !ok {
+		return estimate, errors.New("unable to estimate the claim tx cost")
+	}
+	estimate.Cost = tx.Fee()
+	estimate.ShouldClaim = r.Policy.ShouldClaim(estimate.Total, estimate.Cost)
+	return estimate, nil
+}
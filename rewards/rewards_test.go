@@ -0,0 +1,106 @@
+package rewards
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/cosmos"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestRewardsTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+var lunaAsset = &xc.AssetConfig{Asset: "LUNA", NativeAsset: "LUNA", Driver: "cosmos", ChainCoin: "uluna", ChainPrefix: "terra"}
+var lunaAddress = xc.Address("terra1dp3q305hgttt8n34rt8rg9xpanc42z4ye7upfg")
+
+func newTestRewards(client *testutil.MockedClient, policy Policy) *Rewards {
+	f := testutil.NewDefaultFactoryWithConfig(map[string]interface{}{})
+	f.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		return client, nil
+	}
+	return NewRewards(&f, policy)
+}
+
+func (s *CrosschainTestSuite) TestPolicyShouldClaim() {
+	require := s.Require()
+	policy := DefaultPolicy
+	require.True(policy.ShouldClaim(xc.NewAmountBlockchainFromUint64(1000), xc.NewAmountBlockchainFromUint64(100)))
+	require.False(policy.ShouldClaim(xc.NewAmountBlockchainFromUint64(999), xc.NewAmountBlockchainFromUint64(100)))
+	require.False(policy.ShouldClaim(xc.NewAmountBlockchainFromUint64(0), xc.NewAmountBlockchainFromUint64(0)))
+
+	policy.MinReward = xc.NewAmountBlockchainFromUint64(5000)
+	require.False(policy.ShouldClaim(xc.NewAmountBlockchainFromUint64(1000), xc.NewAmountBlockchainFromUint64(100)))
+	require.True(policy.ShouldClaim(xc.NewAmountBlockchainFromUint64(5000), xc.NewAmountBlockchainFromUint64(100)))
+}
+
+func (s *CrosschainTestSuite) TestEstimate() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	client.On("FetchClaimableRewards", mock.Anything, lunaAddress).Return([]xc.ClaimableReward{
+		{Validator: "terravaloper1a", Amount: xc.NewAmountBlockchainFromUint64(100_000)},
+		{Validator: "terravaloper1a", Contract: "ukrw", Amount: xc.NewAmountBlockchainFromUint64(5)},
+		{Validator: "terravaloper1b", Amount: xc.NewAmountBlockchainFromUint64(200_000)},
+	}, nil)
+	client.On("FetchTxInput", mock.Anything, lunaAddress, lunaAddress).Return(&cosmos.TxInput{GasPrice: 0.01}, nil)
+
+	r := newTestRewards(client, DefaultPolicy)
+	estimate, err := r.Estimate(s.Ctx, Target{Asset: lunaAsset, Address: lunaAddress})
+	require.Nil(err)
+	require.Len(estimate.Rewards, 3)
+	require.Equal("300000", estimate.Total.String())
+	// 400k gas for 2 withdrawals at 0.01
+	require.Equal("4000", estimate.Cost.String())
+	require.True(estimate.ShouldClaim)
+	require.Len(estimate.Tx.(*cosmos.Tx).ParsedTransfers, 2)
+
+	r.Policy.MinRewardToCostRatio = 100
+	estimate, err = r.Estimate(s.Ctx, Target{Asset: lunaAsset, Address: lunaAddress, Compound: true})
+	require.Nil(err)
+	require.False(estimate.ShouldClaim)
+	require.Len(estimate.Tx.(*cosmos.Tx).ParsedTransfers, 4)
+}
+
+func (s *CrosschainTestSuite) TestEstimateNoRewards() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	client.On("FetchClaimableRewards", mock.Anything, lunaAddress).Return([]xc.ClaimableReward{}, nil)
+
+	r := newTestRewards(client, DefaultPolicy)
+	estimate, err := r.Estimate(s.Ctx, Target{Asset: lunaAsset, Address: lunaAddress})
+	require.Nil(err)
+	require.False(estimate.ShouldClaim)
+	require.Nil(estimate.Tx)
+	require.Equal("0", estimate.Total.String())
+	client.AssertNotCalled(s.T(), "FetchTxInput", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *CrosschainTestSuite) TestEstimateErr() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	client.On("FetchClaimableRewards", mock.Anything, lunaAddress).Return([]xc.ClaimableReward{}, errors.New("rpc error"))
+
+	r := newTestRewards(client, DefaultPolicy)
+	_, err := r.Estimate(s.Ctx, Target{Asset: lunaAsset, Address: lunaAddress})
+	require.EqualError(err, "rpc error")
+
+	// bitcoin doesn't support rewards
+	f := testutil.NewDefaultFactoryWithConfig(map[string]interface{}{})
+	r = NewRewards(&f, DefaultPolicy)
+	_, err = r.Estimate(s.Ctx, Target{Asset: &xc.AssetConfig{NativeAsset: "BTC", Driver: "bitcoin"}, Address: "address"})
+	require.ErrorContains(err, "not supported")
+}
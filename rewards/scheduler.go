@@ -0,0 +1,71 @@
+package rewards
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Handler receives the estimates worth claiming, e.g. to sign and submit the claim tx via a pipeline
+type Handler func(ctx context.Context, estimate Estimate) error
+
+// Scheduler periodically evaluates rewards of a list of targets
+type Scheduler struct {
+	Rewards  *Rewards
+	Targets  []Target
+	Interval time.Duration
+	Handler  Handler
+}
+
+// NewScheduler creates a new Scheduler
+func NewScheduler(rewards *Rewards, targets []Target, interval time.Duration, handler Handler) *Scheduler {
+	return &Scheduler{
+		Rewards:  rewards,
+		Targets:  targets,
+		Interval: interval,
+		Handler:  handler,
+	}
+}
+
+// RunOnce evaluates every target once, passing estimates worth claiming to the Handler
+// A failing target doesn't prevent the others from being processed
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	errs := []string{}
+	for _, target := range s.Targets {
+		estimate, err := s.Rewards.Estimate(ctx, target)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s: %v", target.Asset.ID(), target.Address, err))
+			continue
+		}
+		if !estimate.ShouldClaim {
+			continue
+		}
+		err = s.Handler(ctx, estimate)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s: %v", target.Asset.ID(), target.Address, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to process rewards: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Run calls RunOnce every Interval until ctx is done
+// Errors of a run are passed to onError, if set
+func (s *Scheduler) Run(ctx context.Context, onError func(error)) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		err := s.RunOnce(ctx)
+		if err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
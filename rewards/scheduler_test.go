@@ -0,0 +1,63 @@
+package rewards
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/cosmos"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *CrosschainTestSuite) TestSchedulerRunOnce() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	client.On("FetchClaimableRewards", mock.Anything, lunaAddress).Return([]xc.ClaimableReward{
+		{Validator: "terravaloper1a", Amount: xc.NewAmountBlockchainFromUint64(100_000)},
+	}, nil)
+	client.On("FetchClaimableRewards", mock.Anything, xc.Address("terra1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq486l9a")).Return([]xc.ClaimableReward{
+		{Validator: "terravaloper1a", Amount: xc.NewAmountBlockchainFromUint64(1)},
+	}, nil)
+	client.On("FetchTxInput", mock.Anything, mock.Anything, mock.Anything).Return(&cosmos.TxInput{GasPrice: 0.01}, nil)
+
+	claimed := []Estimate{}
+	scheduler := NewScheduler(newTestRewards(client, DefaultPolicy), []Target{
+		{Asset: lunaAsset, Address: lunaAddress},
+		{Asset: lunaAsset, Address: "terra1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq486l9a"},
+	}, time.Minute, func(ctx context.Context, estimate Estimate) error {
+		claimed = append(claimed, estimate)
+		return nil
+	})
+	err := scheduler.RunOnce(s.Ctx)
+	require.Nil(err)
+	require.Len(claimed, 1)
+	require.Equal(lunaAddress, claimed[0].Target.Address)
+
+	// handler errors are reported
+	scheduler.Handler = func(ctx context.Context, estimate Estimate) error {
+		return errors.New("submit failed")
+	}
+	err = scheduler.RunOnce(s.Ctx)
+	require.ErrorContains(err, "submit failed")
+}
+
+func (s *CrosschainTestSuite) TestSchedulerRun() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	client.On("FetchClaimableRewards", mock.Anything, lunaAddress).Return([]xc.ClaimableReward{}, errors.New("rpc error"))
+
+	errs := []error{}
+	scheduler := NewScheduler(newTestRewards(client, DefaultPolicy), []Target{
+		{Asset: lunaAsset, Address: lunaAddress},
+	}, time.Millisecond, nil)
+	ctx, cancel := context.WithTimeout(s.Ctx, 20*time.Millisecond)
+	defer cancel()
+	err := scheduler.Run(ctx, func(err error) {
+		errs = append(errs, err)
+	})
+	require.ErrorIs(err, context.DeadlineExceeded)
+	require.NotEmpty(errs)
+	require.ErrorContains(errs[0], "rpc error")
+}
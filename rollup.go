@@ -0,0 +1,192 @@
+package crosschain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RollupStack is the framework an EVM rollup is built with, selecting its L2 specific behaviors:
+// OP Stack chains charge an L1 data fee on top of the gas, which Orbit chains include in the gas
+// ZK rollups (zkSync Era and Polygon zkEVM) only finalize txs once their batch is proven on L1, zkSync charges
+// pubdata in gas and accepts EIP-712 txs paid by a paymaster, Polygon zkEVM only prices legacy txs
+type RollupStack string
+
+// List of supported RollupStack
+const (
+	RollupOPStack      = RollupStack("op-stack")
+	RollupOrbit        = RollupStack("orbit")
+	RollupZkSync       = RollupStack("zksync")
+	RollupPolygonZkEVM = RollupStack("polygon-zkevm")
+)
+
+// RollupConfig is the config of an EVM rollup, only its stack is required: the template of the stack and of
+// known chains (by chain_id) complete it, see GetRollup
+type RollupConfig struct {
+	Stack     RollupStack `yaml:"stack"`
+	L1ChainID int64       `yaml:"l1_chain_id"`
+	// GasOracle prices the L1 cost of txs: the GasPriceOracle predeploy (OP Stack) or the ArbGasInfo precompile (Orbit)
+	GasOracle string `yaml:"gas_oracle"`
+	// L1Bridge and L2Bridge are the standard bridges (OP Stack) or gateway routers (Orbit) of the chain
+	L1Bridge string `yaml:"l1_bridge"`
+	L2Bridge string `yaml:"l2_bridge"`
+	// SequencerURL receives txs directly instead of url, e.g. for a faster inclusion
+	SequencerURL string `yaml:"sequencer_url"`
+	// SequencerFeedURL streams the txs sequenced before they're batched to L1
+	SequencerFeedURL string `yaml:"sequencer_feed_url"`
+	// L1Rollup is the contract of the rollup on L1, e.g. receiving the forced batches of Polygon zkEVM
+	L1Rollup string `yaml:"l1_rollup"`
+	// Paymaster pays the fees of the txs of zkSync chains, with the hex paymaster_input, e.g. of its general flow
+	Paymaster      string `yaml:"paymaster"`
+	PaymasterInput string `yaml:"paymaster_input"`
+}
+
+// RollupTemplates are the contracts shared by all the chains of a stack
+var RollupTemplates = map[RollupStack]RollupConfig{
+	RollupOPStack: {
+		Stack:     RollupOPStack,
+		GasOracle: "0x420000000000000000000000000000000000000F",
+		L2Bridge:  "0x4200000000000000000000000000000000000010",
+	},
+	RollupOrbit: {
+		Stack:     RollupOrbit,
+		GasOracle: "0x000000000000000000000000000000000000006C",
+	},
+	RollupZkSync: {
+		Stack: RollupZkSync,
+	},
+	RollupPolygonZkEVM: {
+		Stack:    RollupPolygonZkEVM,
+		L2Bridge: "0x2a3DD3EB832aF982ec71669E178424b10Dca2EDe",
+	},
+}
+
+// KnownRollups are the templates of rollups by chain id, their stack included
+var KnownRollups = map[int64]RollupConfig{
+	// OP Mainnet
+	10: {
+		Stack:     RollupOPStack,
+		L1ChainID: 1,
+		L1Bridge:  "0x99C9fc46f92E8a1c0deC1b1747d010903E884bE1",
+	},
+	// Base
+	8453: {
+		Stack:     RollupOPStack,
+		L1ChainID: 1,
+		L1Bridge:  "0x3154Cf16ccdb4C6d922629664174b904d80F2C35",
+	},
+	// Arbitrum One
+	42161: {
+		Stack:            RollupOrbit,
+		L1ChainID:        1,
+		L1Bridge:         "0x72Ce9c846789fdB6fC1f34aC4AD25Dd9ef7031ef",
+		L2Bridge:         "0x5288c571Fd7aD117beA99bF60FE0846C4E84F933",
+		SequencerFeedURL: "wss://arb1.arbitrum.io/feed",
+	},
+	// Arbitrum Nova
+	42170: {
+		Stack:            RollupOrbit,
+		L1ChainID:        1,
+		L1Bridge:         "0xC840838Bc438d73C16c2f8b22D2Ce3669963cD48",
+		L2Bridge:         "0x21903d3F8176b1a0c17E953Cd896610Be9fFDFa8",
+		SequencerFeedURL: "wss://nova.arbitrum.io/feed",
+	},
+	// zkSync Era
+	324: {
+		Stack:     RollupZkSync,
+		L1ChainID: 1,
+		L1Bridge:  "0x57891966931Eb4Bb6FB81430E6cE0A03AAbDe063",
+		L1Rollup:  "0x32400084C286CF3E17e7B677ea9583e60a000324",
+	},
+	// zkSync Era Sepolia
+	300: {
+		Stack:     RollupZkSync,
+		L1ChainID: 11155111,
+	},
+	// Polygon zkEVM
+	1101: {
+		Stack:     RollupPolygonZkEVM,
+		L1ChainID: 1,
+		L1Bridge:  "0x2a3DD3EB832aF982ec71669E178424b10Dca2EDe",
+	},
+	// Polygon zkEVM Cardona
+	2442: {
+		Stack:     RollupPolygonZkEVM,
+		L1ChainID: 11155111,
+	},
+}
+
+// ParseRollupStack parses a rollup stack, case insensitive
+func ParseRollupStack(stack string) (RollupStack, error) {
+	normalized := RollupStack(strings.ToLower(strings.TrimSpace(stack)))
+	if _, ok := RollupTemplates[normalized]; ok {
+		return normalized, nil
+	}
+	return "", fmt.Errorf("unsupported rollup stack: '%s'", stack)
+}
+
+// GetRollup returns the rollup config of a chain, completed with the template of its chain id and of its stack
+// The stack of known chains and of OptETH and ArbETH is inferred, other chains without a stack aren't rollups
+func (asset *NativeAssetConfig) GetRollup() RollupConfig {
+	rollup := asset.Rollup
+	known, isKnown := KnownRollups[asset.ChainID]
+	if rollup.Stack == "" {
+		switch {
+		case isKnown:
+			rollup.Stack = known.Stack
+		case asset.NativeAsset == OptETH:
+			rollup.Stack = RollupOPStack
+		case asset.NativeAsset == ArbETH:
+			rollup.Stack = RollupOrbit
+		default:
+			return rollup
+		}
+	}
+	if stack, err := ParseRollupStack(string(rollup.Stack)); err == nil {
+		rollup.Stack = stack
+	}
+	if isKnown && known.Stack == rollup.Stack {
+		rollup = rollup.withDefaults(known)
+	}
+	return rollup.withDefaults(RollupTemplates[rollup.Stack])
+}
+
+// IsRollup returns true for the chains of a rollup stack
+func (asset *NativeAssetConfig) IsRollup(stack RollupStack) bool {
+	return asset.GetRollup().Stack == stack
+}
+
+// IsZkRollup returns true for the chains of a ZK rollup stack, whose txs are final once proven on L1
+func (asset *NativeAssetConfig) IsZkRollup() bool {
+	stack := asset.GetRollup().Stack
+	return stack == RollupZkSync || stack == RollupPolygonZkEVM
+}
+
+// withDefaults fills the fields that aren't set from template
+func (rollup RollupConfig) withDefaults(template RollupConfig) RollupConfig {
+	if rollup.L1ChainID == 0 {
+		rollup.L1ChainID = template.L1ChainID
+	}
+	if rollup.GasOracle == "" {
+		rollup.GasOracle = template.GasOracle
+	}
+	if rollup.L1Bridge == "" {
+		rollup.L1Bridge = template.L1Bridge
+	}
+	if rollup.L2Bridge == "" {
+		rollup.L2Bridge = template.L2Bridge
+	}
+	if rollup.SequencerURL == "" {
+		rollup.SequencerURL = template.SequencerURL
+	}
+	if rollup.SequencerFeedURL == "" {
+		rollup.SequencerFeedURL = template.SequencerFeedURL
+	}
+	if rollup.L1Rollup == "" {
+		rollup.L1Rollup = template.L1Rollup
+	}
+	if rollup.Paymaster == "" {
+		rollup.Paymaster = template.Paymaster
+		rollup.PaymasterInput = template.PaymasterInput
+	}
+	return rollup
+}
@@ -0,0 +1,81 @@
+package crosschain
+
+func (s *CrosschainTestSuite) TestGetRollup() {
+	require := s.Require()
+
+	// not a rollup
+	asset := &NativeAssetConfig{NativeAsset: ETH, ChainID: 1}
+	require.Equal(RollupConfig{}, asset.GetRollup())
+	require.False(asset.IsRollup(RollupOPStack))
+
+	// known chains, by chain id
+	asset = &NativeAssetConfig{NativeAsset: ETH, ChainID: 8453}
+	rollup := asset.GetRollup()
+	require.Equal(RollupOPStack, rollup.Stack)
+	require.EqualValues(1, rollup.L1ChainID)
+	require.Equal("0x420000000000000000000000000000000000000F", rollup.GasOracle)
+	require.Equal("0x4200000000000000000000000000000000000010", rollup.L2Bridge)
+	require.Equal("0x3154Cf16ccdb4C6d922629664174b904d80F2C35", rollup.L1Bridge)
+	require.True(asset.IsRollup(RollupOPStack))
+
+	asset = &NativeAssetConfig{NativeAsset: ArbETH, ChainID: 42161}
+	rollup = asset.GetRollup()
+	require.Equal(RollupOrbit, rollup.Stack)
+	require.Equal("0x000000000000000000000000000000000000006C", rollup.GasOracle)
+	require.Equal("wss://arb1.arbitrum.io/feed", rollup.SequencerFeedURL)
+
+	// known native assets on other networks, with the template of the stack only
+	asset = &NativeAssetConfig{NativeAsset: OptETH, ChainID: 11155420}
+	rollup = asset.GetRollup()
+	require.Equal(RollupOPStack, rollup.Stack)
+	require.Equal("0x420000000000000000000000000000000000000F", rollup.GasOracle)
+	require.Equal("", rollup.L1Bridge)
+
+	// a new app-chain, onboarded with its stack and its own contracts
+	asset = &NativeAssetConfig{NativeAsset: "XYZ", ChainID: 123, Rollup: RollupConfig{
+		Stack:            "Orbit",
+		L1ChainID:        42161,
+		L1Bridge:         "0x1111111111111111111111111111111111111111",
+		SequencerURL:     "https://sequencer.example.com",
+		SequencerFeedURL: "wss://feed.example.com",
+	}}
+	rollup = asset.GetRollup()
+	require.Equal(RollupOrbit, rollup.Stack)
+	require.EqualValues(42161, rollup.L1ChainID)
+	require.Equal("0x000000000000000000000000000000000000006C", rollup.GasOracle)
+	require.Equal("0x1111111111111111111111111111111111111111", rollup.L1Bridge)
+	require.Equal("https://sequencer.example.com", rollup.SequencerURL)
+	require.True(asset.IsRollup(RollupOrbit))
+
+	// overrides of known chains are kept
+	asset = &NativeAssetConfig{NativeAsset: OptETH, ChainID: 10, Rollup: RollupConfig{GasOracle: "0x2222222222222222222222222222222222222222"}}
+	rollup = asset.GetRollup()
+	require.Equal("0x2222222222222222222222222222222222222222", rollup.GasOracle)
+	require.Equal("0x99C9fc46f92E8a1c0deC1b1747d010903E884bE1", rollup.L1Bridge)
+
+	// zk rollups
+	asset = &NativeAssetConfig{NativeAsset: ETH, ChainID: 324, Rollup: RollupConfig{Paymaster: "0x3333333333333333333333333333333333333333"}}
+	rollup = asset.GetRollup()
+	require.Equal(RollupZkSync, rollup.Stack)
+	require.Equal("0x32400084C286CF3E17e7B677ea9583e60a000324", rollup.L1Rollup)
+	require.Equal("0x3333333333333333333333333333333333333333", rollup.Paymaster)
+	require.True(asset.IsZkRollup())
+	asset = &NativeAssetConfig{NativeAsset: ETH, ChainID: 1101}
+	require.True(asset.IsRollup(RollupPolygonZkEVM))
+	require.Equal("0x2a3DD3EB832aF982ec71669E178424b10Dca2EDe", asset.GetRollup().L2Bridge)
+	require.True(asset.IsZkRollup())
+	asset = &NativeAssetConfig{NativeAsset: OptETH, ChainID: 10}
+	require.False(asset.IsZkRollup())
+}
+
+func (s *CrosschainTestSuite) TestParseRollupStack() {
+	require := s.Require()
+	stack, err := ParseRollupStack(" OP-Stack ")
+	require.NoError(err)
+	require.Equal(RollupOPStack, stack)
+	stack, err = ParseRollupStack("Polygon-zkEVM")
+	require.NoError(err)
+	require.Equal(RollupPolygonZkEVM, stack)
+	_, err = ParseRollupStack("zk-stack")
+	require.EqualError(err, "unsupported rollup stack: 'zk-stack'")
+}
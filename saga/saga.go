@@ -0,0 +1,406 @@
+// Package saga orchestrates multi-step transfers whose txs depend on each other, e.g. a gas top-up then a token
+// sweep, or a bridge deposit then its claim: the steps are submitted in order, each once the previous one is
+// confirmed, and once a step fails the steps done are compensated in reverse order, e.g. the gas topped up is sent
+// back. Steps stuck unconfirmed and compensations that fail are escalated, so that a flow completed partially never
+// strands funds silently.
+package saga
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+	"github.com/jumpcrypto/crosschain/lease"
+)
+
+// DefaultPollInterval is the interval between the steps of Run
+const DefaultPollInterval = 2 * time.Second
+
+// DefaultStepTimeout is the time after which a step submitted and not confirmed is escalated
+const DefaultStepTimeout = 10 * time.Minute
+
+// DefaultMaxAttempts is the number of failed submissions of a step after which the step fails
+const DefaultMaxAttempts = 3
+
+// Action submits the tx of a step and returns its hash
+// The hash should be returned along with the error of a submission that may have been accepted anyway
+type Action func(ctx context.Context, saga *Saga, step *Step) (xc.TxHash, error)
+
+// StepState is the state of a Step
+type StepState string
+
+// List of StepState
+const (
+	// StepPending is a step whose tx isn't submitted yet
+	StepPending = StepState("pending")
+	// StepSubmitted is a step whose tx is submitted, not confirmed yet
+	StepSubmitted = StepState("submitted")
+	// StepDone is a step whose tx is confirmed
+	StepDone = StepState("done")
+	// StepFailed is a step whose tx failed, or that couldn't be submitted
+	StepFailed = StepState("failed")
+	// StepCompensating is a done step whose compensation is submitted, not confirmed yet
+	StepCompensating = StepState("compensating")
+	// StepCompensated is a done step whose compensation is confirmed
+	StepCompensated = StepState("compensated")
+)
+
+// State is the state of a Saga
+type State string
+
+// List of State
+const (
+	// StateRunning is a saga whose steps are being submitted
+	StateRunning = State("running")
+	// StateCompleted is a saga whose steps are all done
+	StateCompleted = State("completed")
+	// StateCompensating is a saga with a failed step, whose done steps are being compensated
+	StateCompensating = State("compensating")
+	// StateCompensated is a saga whose done steps are all compensated, or kept as they have no compensation
+	StateCompensated = State("compensated")
+	// StateStranded is a saga whose compensation failed: its funds need a manual intervention, see Saga.Error
+	StateStranded = State("stranded")
+)
+
+// Done returns true for the final states
+func (state State) Done() bool {
+	return state == StateCompleted || state == StateCompensated || state == StateStranded
+}
+
+// Step is a tx of a saga, checked on the chain of Asset
+type Step struct {
+	Name  string
+	Asset xc.ITask
+	// Execute submits the tx of the step
+	Execute Action
+	// Compensate submits the tx undoing the step once done, nil if the step is kept, e.g. a fee paid
+	Compensate Action
+	// Timeout after which the step submitted and not confirmed is escalated, 0 for DefaultStepTimeout
+	Timeout time.Duration
+
+	State          StepState
+	TxHash         xc.TxHash
+	CompensationTx xc.TxHash
+	SubmittedAt    time.Time
+	// Attempts is the number of failed submissions of the tx in the current state
+	Attempts int
+	// Escalated is true once the step is escalated in its current state
+	Escalated bool
+	Error     string
+}
+
+// NewStep creates a new pending Step
+func NewStep(name string, asset xc.ITask, execute Action, compensate Action) *Step {
+	return &Step{
+		Name:       name,
+		Asset:      asset,
+		Execute:    execute,
+		Compensate: compensate,
+		State:      StepPending,
+	}
+}
+
+// Saga is a multi-step transfer
+type Saga struct {
+	ID    string
+	Steps []*Step
+	State State
+	Error string
+}
+
+// NewSaga creates a new running Saga
+func NewSaga(id string, steps ...*Step) *Saga {
+	return &Saga{
+		ID:    id,
+		Steps: steps,
+		State: StateRunning,
+	}
+}
+
+// Escalation is a step that needs the attention of an operator
+type Escalation struct {
+	Saga   *Saga
+	Step   *Step
+	Reason string
+	// Diagnosis of the tx of a stuck step, if the client of its chain can diagnose txs
+	Diagnosis *xc.Diagnosis
+}
+
+// Orchestrator moves Sagas through their states
+// Sagas are updated in place, e.g. to be persisted by the caller after each Step
+type Orchestrator struct {
+	Factory factory.FactoryContext
+	// Escalate is called once for each step stuck, and for stranded sagas
+	Escalate     func(ctx context.Context, escalation *Escalation)
+	PollInterval time.Duration
+	MaxAttempts  int
+	// Locker, if set, leases each saga while it's stepped, so that replicas don't submit its txs twice
+	Locker   lease.Locker
+	LeaseTTL time.Duration
+}
+
+// NewOrchestrator creates a new Orchestrator
+func NewOrchestrator(f factory.FactoryContext, escalate func(ctx context.Context, escalation *Escalation)) *Orchestrator {
+	return &Orchestrator{
+		Factory:      f,
+		Escalate:     escalate,
+		PollInterval: DefaultPollInterval,
+		MaxAttempts:  DefaultMaxAttempts,
+	}
+}
+
+var now = time.Now
+
+// Step advances a saga by at most one state of one of its steps: submits a tx, or checks the tx submitted
+// The hash of a tx is recorded before it's checked: a Step after a failed submission checks if the tx was accepted
+// anyway before submitting another
+// With a Locker, Step returns lease.ErrLeaseHeld while another replica steps the saga
+func (o *Orchestrator) Step(ctx context.Context, saga *Saga) error {
+	if o.Locker == nil {
+		return o.advance(ctx, saga)
+	}
+	ttl := o.LeaseTTL
+	if ttl <= 0 {
+		ttl = lease.DefaultTTL
+	}
+	return lease.WithLease(ctx, o.Locker, lease.SagaKey(saga.ID), ttl, func(ctx context.Context) error {
+		return o.advance(ctx, saga)
+	})
+}
+
+func (o *Orchestrator) advance(ctx context.Context, saga *Saga) error {
+	switch saga.State {
+	case StateRunning:
+		for _, step := range saga.Steps {
+			if step.State != StepDone {
+				return o.execute(ctx, saga, step)
+			}
+		}
+		saga.State = StateCompleted
+		return nil
+	case StateCompensating:
+		for i := len(saga.Steps) - 1; i >= 0; i-- {
+			step := saga.Steps[i]
+			if step.Compensate != nil && (step.State == StepDone || step.State == StepCompensating) {
+				return o.compensate(ctx, saga, step)
+			}
+		}
+		saga.State = StateCompensated
+		return nil
+	case StateCompleted, StateCompensated, StateStranded:
+		return nil
+	}
+	return fmt.Errorf("invalid state of saga %s: '%s'", saga.ID, saga.State)
+}
+
+// Run steps a saga every PollInterval until it's done
+// Errors of steps are passed to onError, if set, and retried
+func (o *Orchestrator) Run(ctx context.Context, saga *Saga, onError func(error)) error {
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := o.Step(ctx, saga)
+		if err != nil && onError != nil {
+			onError(err)
+		}
+		if saga.State.Done() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Orchestrator) execute(ctx context.Context, saga *Saga, step *Step) error {
+	switch step.State {
+	case StepPending:
+		if step.TxHash != "" {
+			found, err := o.found(ctx, step.Asset, step.TxHash)
+			if err != nil {
+				return fmt.Errorf("fetching tx %s of step %s: %v", step.TxHash, step.Name, err)
+			}
+			if found {
+				o.submitted(step, StepSubmitted)
+				return nil
+			}
+		}
+		txHash, err := step.Execute(ctx, saga, step)
+		if txHash != "" {
+			step.TxHash = txHash
+			step.SubmittedAt = now()
+		}
+		if err != nil {
+			err = fmt.Errorf("submitting step %s of saga %s: %v", step.Name, saga.ID, err)
+			if o.failedAttempt(step) {
+				o.fail(saga, step, err.Error())
+			}
+			return err
+		}
+		o.submitted(step, StepSubmitted)
+		return nil
+	case StepSubmitted:
+		confirmed, failed, err := o.check(ctx, saga, step, step.TxHash)
+		if err != nil {
+			return err
+		}
+		if failed {
+			o.fail(saga, step, step.Error)
+		} else if confirmed {
+			o.settled(step, StepDone)
+		}
+		return nil
+	case StepFailed:
+		// a failed step is only set along with a compensating saga
+		saga.State = StateCompensating
+		return nil
+	}
+	return fmt.Errorf("invalid state of step %s of saga %s: '%s'", step.Name, saga.ID, step.State)
+}
+
+func (o *Orchestrator) compensate(ctx context.Context, saga *Saga, step *Step) error {
+	if step.State == StepDone {
+		if step.CompensationTx != "" {
+			found, err := o.found(ctx, step.Asset, step.CompensationTx)
+			if err != nil {
+				return fmt.Errorf("fetching compensation tx %s of step %s: %v", step.CompensationTx, step.Name, err)
+			}
+			if found {
+				o.submitted(step, StepCompensating)
+				return nil
+			}
+		}
+		txHash, err := step.Compensate(ctx, saga, step)
+		if txHash != "" {
+			step.CompensationTx = txHash
+			step.SubmittedAt = now()
+		}
+		if err != nil {
+			err = fmt.Errorf("compensating step %s of saga %s: %v", step.Name, saga.ID, err)
+			if o.failedAttempt(step) {
+				o.strand(ctx, saga, step, err.Error())
+			}
+			return err
+		}
+		o.submitted(step, StepCompensating)
+		return nil
+	}
+	confirmed, failed, err := o.check(ctx, saga, step, step.CompensationTx)
+	if err != nil {
+		return err
+	}
+	if failed {
+		o.strand(ctx, saga, step, fmt.Sprintf("compensation of step %s failed: %s", step.Name, step.Error))
+	} else if confirmed {
+		o.settled(step, StepCompensated)
+	}
+	return nil
+}
+
+// check returns if the tx of a step is confirmed or failed, setting the error of the step if it failed, and
+// escalates the step once stuck for longer than its timeout
+func (o *Orchestrator) check(ctx context.Context, saga *Saga, step *Step, txHash xc.TxHash) (confirmed bool, failed bool, err error) {
+	client, err := o.Factory.NewClient(step.Asset)
+	if err != nil {
+		return false, false, err
+	}
+	info, err := client.FetchTxInfo(ctx, txHash)
+	if err == nil && info.Status == xc.TxStatusFailure {
+		step.Error = fmt.Sprintf("tx %s failed: %s", txHash, info.Error)
+		return false, true, nil
+	}
+	if err == nil && info.Confirmations > 0 {
+		return true, false, nil
+	}
+
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = DefaultStepTimeout
+	}
+	if !step.Escalated && now().Sub(step.SubmittedAt) > timeout {
+		step.Escalated = true
+		escalation := &Escalation{
+			Saga:   saga,
+			Step:   step,
+			Reason: fmt.Sprintf("tx %s of step %s not confirmed after %s", txHash, step.Name, timeout),
+		}
+		if diagnose, ok := xc.UnwrapClient(client).(xc.ClientDiagnose); ok {
+			escalation.Diagnosis, _ = diagnose.Diagnose(ctx, xc.DiagnoseRequest{TxHash: txHash, SubmittedAt: step.SubmittedAt})
+		}
+		o.escalate(ctx, escalation)
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("fetching tx %s of step %s: %v", txHash, step.Name, err)
+	}
+	return false, false, nil
+}
+
+// found returns true if a tx is known to its chain, pending or confirmed, e.g. submitted despite the error of its
+// submission: only a tx not found may be submitted again, other errors are returned
+func (o *Orchestrator) found(ctx context.Context, asset xc.ITask, txHash xc.TxHash) (bool, error) {
+	client, err := o.Factory.NewClient(asset)
+	if err != nil {
+		return false, err
+	}
+	_, err = client.FetchTxInfo(ctx, txHash)
+	if xc.IsTxNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// failedAttempt counts a failed submission and returns true once the step has no attempt left
+func (o *Orchestrator) failedAttempt(step *Step) bool {
+	maxAttempts := o.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	step.Attempts++
+	return step.Attempts >= maxAttempts
+}
+
+func (o *Orchestrator) submitted(step *Step, state StepState) {
+	step.State = state
+	step.Attempts = 0
+	step.Escalated = false
+}
+
+func (o *Orchestrator) settled(step *Step, state StepState) {
+	step.State = state
+	step.Attempts = 0
+	step.Escalated = false
+	step.Error = ""
+}
+
+// fail fails a step, compensating the steps done before it
+func (o *Orchestrator) fail(saga *Saga, step *Step, reason string) {
+	step.State = StepFailed
+	step.Error = reason
+	saga.State = StateCompensating
+	saga.Error = fmt.Sprintf("step %s failed: %s", step.Name, reason)
+}
+
+// strand stops the compensation of a saga, leaving its funds to an operator
+func (o *Orchestrator) strand(ctx context.Context, saga *Saga, step *Step, reason string) {
+	step.Error = reason
+	saga.State = StateStranded
+	if saga.Error != "" {
+		reason = saga.Error + ", " + reason
+	}
+	saga.Error = reason
+	o.escalate(ctx, &Escalation{Saga: saga, Step: step, Reason: reason})
+}
+
+func (o *Orchestrator) escalate(ctx context.Context, escalation *Escalation) {
+	if o.Escalate != nil {
+		o.Escalate(ctx, escalation)
+	}
+}
@@ -0,0 +1,329 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/lease"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+	t   time.Time
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+	s.t = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time {
+		return s.t
+	}
+}
+
+func (s *CrosschainTestSuite) TearDownTest() {
+	now = time.Now
+}
+
+func TestSagaTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+var ethAsset = &xc.NativeAssetConfig{NativeAsset: xc.ETH, Driver: string(xc.DriverEVM), Net: "testnet"}
+
+// diagnoseClient is a client that can diagnose stuck txs
+type diagnoseClient struct {
+	*testutil.MockedClient
+}
+
+func (client *diagnoseClient) Diagnose(ctx context.Context, request xc.DiagnoseRequest) (*xc.Diagnosis, error) {
+	return xc.NewDiagnosis(request.TxHash, xc.StuckReasonFeeTooLow, ""), nil
+}
+
+func (s *CrosschainTestSuite) newOrchestrator(client xc.Client, escalations *[]*Escalation) *Orchestrator {
+	f := testutil.NewDefaultFactoryWithConfig(map[string]interface{}{})
+	f.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		return client, nil
+	}
+	return NewOrchestrator(&f, func(ctx context.Context, escalation *Escalation) {
+		*escalations = append(*escalations, escalation)
+	})
+}
+
+// submit returns an Action submitting txHash, recording the calls
+func submit(txHash xc.TxHash, calls *[]string) Action {
+	return func(ctx context.Context, saga *Saga, step *Step) (xc.TxHash, error) {
+		*calls = append(*calls, string(txHash))
+		return txHash, nil
+	}
+}
+
+func confirmed() xc.TxInfo {
+	return xc.TxInfo{Status: xc.TxStatusSuccess, Confirmations: 1}
+}
+
+func (s *CrosschainTestSuite) TestStepCompleted() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	escalations := []*Escalation{}
+	orchestrator := s.newOrchestrator(client, &escalations)
+	calls := []string{}
+	saga := NewSaga("1",
+		NewStep("top-up", ethAsset, submit("0x1", &calls), submit("0x1-back", &calls)),
+		NewStep("sweep", ethAsset, submit("0x2", &calls), nil),
+	)
+
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepSubmitted, saga.Steps[0].State)
+	require.Equal(xc.TxHash("0x1"), saga.Steps[0].TxHash)
+	require.Equal(s.t, saga.Steps[0].SubmittedAt)
+
+	// not confirmed yet
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1")).Return(xc.TxInfo{}, nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepSubmitted, saga.Steps[0].State)
+
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1")).Return(confirmed(), nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepDone, saga.Steps[0].State)
+
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x2")).Return(confirmed(), nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepDone, saga.Steps[1].State)
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StateCompleted, saga.State)
+	require.True(saga.State.Done())
+	require.Equal([]string{"0x1", "0x2"}, calls)
+	require.Empty(escalations)
+}
+
+func (s *CrosschainTestSuite) TestStepCompensated() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	escalations := []*Escalation{}
+	orchestrator := s.newOrchestrator(client, &escalations)
+	calls := []string{}
+	saga := NewSaga("1",
+		NewStep("fee", ethAsset, submit("0x0", &calls), nil),
+		NewStep("deposit", ethAsset, submit("0x1", &calls), submit("0x1-back", &calls)),
+		NewStep("claim", ethAsset, submit("0x2", &calls), submit("0x2-back", &calls)),
+	)
+	saga.Steps[0].State = StepDone
+	saga.Steps[1].State = StepDone
+
+	// the claim fails
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x2")).Return(xc.TxInfo{Status: xc.TxStatusFailure, Error: "reverted"}, nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepFailed, saga.Steps[2].State)
+	require.Equal(StateCompensating, saga.State)
+	require.Equal("step claim failed: tx 0x2 failed: reverted", saga.Error)
+
+	// only the deposit is compensated: the claim failed and the fee is kept
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepCompensating, saga.Steps[1].State)
+	require.Equal(xc.TxHash("0x1-back"), saga.Steps[1].CompensationTx)
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1-back")).Return(confirmed(), nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepCompensated, saga.Steps[1].State)
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StateCompensated, saga.State)
+	require.Equal(StepDone, saga.Steps[0].State)
+	require.Equal([]string{"0x2", "0x1-back"}, calls)
+	require.Empty(escalations)
+}
+
+func (s *CrosschainTestSuite) TestStepMaxAttempts() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	escalations := []*Escalation{}
+	orchestrator := s.newOrchestrator(client, &escalations)
+	orchestrator.MaxAttempts = 2
+	calls := []string{}
+	saga := NewSaga("1",
+		NewStep("top-up", ethAsset, submit("0x1", &calls), submit("0x1-back", &calls)),
+		NewStep("sweep", ethAsset, func(ctx context.Context, saga *Saga, step *Step) (xc.TxHash, error) {
+			return "", errors.New("insufficient funds")
+		}, nil),
+	)
+	saga.Steps[0].State = StepDone
+
+	err := orchestrator.Step(s.Ctx, saga)
+	require.EqualError(err, "submitting step sweep of saga 1: insufficient funds")
+	require.Equal(StepPending, saga.Steps[1].State)
+	require.Equal(1, saga.Steps[1].Attempts)
+	require.Equal(StateRunning, saga.State)
+
+	err = orchestrator.Step(s.Ctx, saga)
+	require.Error(err)
+	require.Equal(StepFailed, saga.Steps[1].State)
+	require.Equal(StateCompensating, saga.State)
+
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepCompensating, saga.Steps[0].State)
+	require.Equal([]string{"0x1-back"}, calls)
+}
+
+func (s *CrosschainTestSuite) TestStepSubmittedAnyway() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	escalations := []*Escalation{}
+	orchestrator := s.newOrchestrator(client, &escalations)
+	saga := NewSaga("1",
+		NewStep("sweep", ethAsset, func(ctx context.Context, saga *Saga, step *Step) (xc.TxHash, error) {
+			return "0x1", errors.New("timeout")
+		}, nil),
+	)
+
+	err := orchestrator.Step(s.Ctx, saga)
+	require.ErrorContains(err, "timeout")
+	require.Equal(xc.TxHash("0x1"), saga.Steps[0].TxHash)
+
+	// the tx was accepted: it isn't submitted again
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1")).Return(confirmed(), nil)
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepSubmitted, saga.Steps[0].State)
+	require.Equal(0, saga.Steps[0].Attempts)
+}
+
+func (s *CrosschainTestSuite) TestStepSubmittedPending() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	escalations := []*Escalation{}
+	orchestrator := s.newOrchestrator(client, &escalations)
+	calls := []string{}
+	saga := NewSaga("1",
+		NewStep("sweep", ethAsset, func(ctx context.Context, saga *Saga, step *Step) (xc.TxHash, error) {
+			calls = append(calls, "0x1")
+			return "0x1", errors.New("timeout")
+		}, nil),
+	)
+	require.ErrorContains(orchestrator.Step(s.Ctx, saga), "timeout")
+
+	// the node is unreachable: the tx may be pending, it isn't submitted again
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1")).Return(xc.TxInfo{}, errors.New("connection refused")).Once()
+	require.ErrorContains(orchestrator.Step(s.Ctx, saga), "connection refused")
+	require.Equal(StepPending, saga.Steps[0].State)
+
+	// not found: submitted again
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1")).Return(xc.TxInfo{}, errors.New("not found")).Once()
+	require.ErrorContains(orchestrator.Step(s.Ctx, saga), "timeout")
+	require.Equal([]string{"0x1", "0x1"}, calls)
+
+	// pending, not confirmed yet
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1")).Return(xc.TxInfo{}, nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepSubmitted, saga.Steps[0].State)
+	require.Len(calls, 2)
+}
+
+func (s *CrosschainTestSuite) TestStepLeased() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	escalations := []*Escalation{}
+	orchestrator := s.newOrchestrator(client, &escalations)
+	locker := lease.NewMemoryLocker()
+	orchestrator.Locker = locker
+	calls := []string{}
+	saga := NewSaga("1", NewStep("sweep", ethAsset, submit("0x1", &calls), nil))
+
+	// another replica steps the saga
+	held, err := locker.Acquire(s.Ctx, lease.SagaKey("1"), time.Minute)
+	require.NoError(err)
+	require.ErrorIs(orchestrator.Step(s.Ctx, saga), lease.ErrLeaseHeld)
+	require.Equal(StepPending, saga.Steps[0].State)
+	require.NoError(held.Release(s.Ctx))
+
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StepSubmitted, saga.Steps[0].State)
+	require.Equal([]string{"0x1"}, calls)
+	// released after the step
+	held, err = locker.Acquire(s.Ctx, lease.SagaKey("1"), time.Minute)
+	require.NoError(err)
+	require.NoError(held.Release(s.Ctx))
+}
+
+func (s *CrosschainTestSuite) TestStepStuck() {
+	require := s.Require()
+	client := &diagnoseClient{&testutil.MockedClient{}}
+	escalations := []*Escalation{}
+	// clients cross-checking reads across providers still diagnose
+	quorumClient := xc.NewQuorumClient(ethAsset, client, []xc.ProviderClient{{Provider: "a", Client: client}}, 1)
+	orchestrator := s.newOrchestrator(quorumClient, &escalations)
+	calls := []string{}
+	saga := NewSaga("1", NewStep("sweep", ethAsset, submit("0x1", &calls), nil))
+	saga.Steps[0].Timeout = time.Minute
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1")).Return(xc.TxInfo{}, errors.New("not found"))
+	s.t = s.t.Add(time.Minute)
+	require.ErrorContains(orchestrator.Step(s.Ctx, saga), "fetching tx 0x1 of step sweep: not found")
+	require.Empty(escalations)
+
+	// escalated once, and kept submitted as the tx may still confirm
+	s.t = s.t.Add(time.Second)
+	require.Error(orchestrator.Step(s.Ctx, saga))
+	require.Error(orchestrator.Step(s.Ctx, saga))
+	require.Len(escalations, 1)
+	require.Equal("tx 0x1 of step sweep not confirmed after 1m0s", escalations[0].Reason)
+	require.Equal(saga.Steps[0], escalations[0].Step)
+	require.Equal(xc.RemediationBumpFee, escalations[0].Diagnosis.Remediation)
+	require.Equal(StepSubmitted, saga.Steps[0].State)
+	require.Equal(StateRunning, saga.State)
+}
+
+func (s *CrosschainTestSuite) TestStepStranded() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	escalations := []*Escalation{}
+	orchestrator := s.newOrchestrator(client, &escalations)
+	calls := []string{}
+	saga := NewSaga("1",
+		NewStep("deposit", ethAsset, submit("0x1", &calls), submit("0x1-back", &calls)),
+		NewStep("claim", ethAsset, submit("0x2", &calls), nil),
+	)
+	saga.Steps[0].State = StepDone
+	saga.Steps[1].State = StepFailed
+	saga.State = StateCompensating
+	saga.Error = "step claim failed"
+
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1-back")).Return(xc.TxInfo{Status: xc.TxStatusFailure, Error: "reverted"}, nil).Once()
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal(StateStranded, saga.State)
+	require.True(saga.State.Done())
+	require.Equal("step claim failed, compensation of step deposit failed: tx 0x1-back failed: reverted", saga.Error)
+	require.Len(escalations, 1)
+	require.Equal(saga.Error, escalations[0].Reason)
+	require.Nil(escalations[0].Diagnosis)
+
+	// stranded sagas are left to an operator
+	require.NoError(orchestrator.Step(s.Ctx, saga))
+	require.Equal([]string{"0x1-back"}, calls)
+}
+
+func (s *CrosschainTestSuite) TestRun() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	escalations := []*Escalation{}
+	orchestrator := s.newOrchestrator(client, &escalations)
+	orchestrator.PollInterval = time.Millisecond
+	calls := []string{}
+	saga := NewSaga("1", NewStep("sweep", ethAsset, submit("0x1", &calls), nil))
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x1")).Return(confirmed(), nil)
+	require.NoError(orchestrator.Run(s.Ctx, saga, nil))
+	require.Equal(StateCompleted, saga.State)
+
+	ctx, cancel := context.WithCancel(s.Ctx)
+	cancel()
+	saga = NewSaga("2", NewStep("sweep", ethAsset, submit("0x2", &calls), nil))
+	client.On("FetchTxInfo", mock.Anything, xc.TxHash("0x2")).Return(xc.TxInfo{}, nil)
+	require.ErrorIs(orchestrator.Run(ctx, saga, nil), context.Canceled)
+}
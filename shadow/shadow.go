@@ -0,0 +1,361 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Defaults of a Client
+const (
+	DefaultHeightTolerance = 2
+	DefaultTimeout         = 30 * time.Second
+	DefaultMaxInFlight     = 16
+	DefaultMaxMismatches   = 100
+)
+
+// Methods whose reads are mirrored
+const (
+	MethodFetchTxInfo        = "FetchTxInfo"
+	MethodFetchBalance       = "FetchBalance"
+	MethodFetchNativeBalance = "FetchNativeBalance"
+	MethodFetchChainStats    = "FetchChainStats"
+)
+
+var now = time.Now
+
+// Mismatch is a read whose results differ between the primary and the secondary provider
+type Mismatch struct {
+	Method string `json:"method"`
+	// Key is the argument of the read, e.g. the address or the tx hash, empty for heights
+	Key string `json:"key,omitempty"`
+	// Differences are "field: primary != secondary"
+	Differences []string  `json:"differences"`
+	Time        time.Time `json:"time"`
+}
+
+// MethodStats counts the mirrored reads of a method
+type MethodStats struct {
+	Compared   int `json:"compared"`
+	Mismatched int `json:"mismatched"`
+	// SecondaryErrors are reads failing on the secondary provider only
+	SecondaryErrors int `json:"secondary_errors"`
+	// Skipped are reads not mirrored: failing on the primary provider, or over MaxInFlight
+	Skipped int `json:"skipped"`
+}
+
+// Report is the comparison of the providers since the Client was created or Reset
+type Report struct {
+	Since   time.Time               `json:"since"`
+	Methods map[string]*MethodStats `json:"methods"`
+	// Mismatches are the last MaxMismatches mismatches, oldest first
+	Mismatches []*Mismatch `json:"mismatches"`
+}
+
+// Matching returns true if reads were compared and none mismatched or failed on the secondary provider
+func (report *Report) Matching() bool {
+	compared := 0
+	for _, stats := range report.Methods {
+		if stats.Mismatched > 0 || stats.SecondaryErrors > 0 {
+			return false
+		}
+		compared += stats.Compared
+	}
+	return compared > 0
+}
+
+// Client serves reads and txs from the primary provider, and mirrors the reads to the secondary provider
+// in the background, diffing the results, to validate a new provider before switching to it
+// Txs are never sent to the secondary provider, and its results are never returned
+// Balances changing between the reads of both providers are reported as mismatches: compare idle addresses
+type Client struct {
+	Primary   xc.Client
+	Secondary xc.Client
+	// HeightTolerance is the number of blocks the heights of the providers may differ by,
+	// also applied to the confirmations of txs
+	HeightTolerance uint64
+	// Timeout of the mirrored reads
+	Timeout time.Duration
+	// MaxInFlight is the max number of concurrent mirrored reads, reads over it aren't mirrored
+	MaxInFlight   int
+	MaxMismatches int
+
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	inFlight int
+	report   *Report
+}
+
+var _ xc.FullClient = &Client{}
+var _ xc.ClientChainStats = &Client{}
+
+// NewClient creates a new Client mirroring the reads of primary to secondary
+func NewClient(primary xc.Client, secondary xc.Client) *Client {
+	return &Client{
+		Primary:         primary,
+		Secondary:       secondary,
+		HeightTolerance: DefaultHeightTolerance,
+		Timeout:         DefaultTimeout,
+		MaxInFlight:     DefaultMaxInFlight,
+		MaxMismatches:   DefaultMaxMismatches,
+		report:          newReport(),
+	}
+}
+
+func newReport() *Report {
+	return &Report{
+		Since:      now(),
+		Methods:    map[string]*MethodStats{},
+		Mismatches: []*Mismatch{},
+	}
+}
+
+// Report returns a copy of the comparison of the providers
+func (client *Client) Report() *Report {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	report := &Report{
+		Since:      client.report.Since,
+		Methods:    map[string]*MethodStats{},
+		Mismatches: append([]*Mismatch{}, client.report.Mismatches...),
+	}
+	for method, stats := range client.report.Methods {
+		copied := *stats
+		report.Methods[method] = &copied
+	}
+	return report
+}
+
+// Reset clears the comparison, e.g. after fixing the configuration of the secondary provider
+func (client *Client) Reset() {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.report = newReport()
+}
+
+// Wait waits for the mirrored reads in flight
+func (client *Client) Wait() {
+	client.wg.Wait()
+}
+
+func (client *Client) stats(method string) *MethodStats {
+	stats, ok := client.report.Methods[method]
+	if !ok {
+		stats = &MethodStats{}
+		client.report.Methods[method] = stats
+	}
+	return stats
+}
+
+func (client *Client) skip(method string) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.stats(method).Skipped++
+}
+
+// mirror runs read on the secondary provider in the background, and records the differences returned by compare
+func (client *Client) mirror(ctx context.Context, method string, key string, read func(ctx context.Context) ([]string, error)) {
+	client.mu.Lock()
+	if client.MaxInFlight > 0 && client.inFlight >= client.MaxInFlight {
+		client.stats(method).Skipped++
+		client.mu.Unlock()
+		return
+	}
+	client.inFlight++
+	client.mu.Unlock()
+
+	client.wg.Add(1)
+	go func() {
+		defer client.wg.Done()
+		// not canceled with the request of the primary provider
+		timeout := client.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		mirrorCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		differences, err := read(mirrorCtx)
+
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		client.inFlight--
+		stats := client.stats(method)
+		if err != nil {
+			stats.SecondaryErrors++
+			xc.LogEntry(ctx).WithField("method", method).WithField("key", key).WithError(err).Warn("shadow: secondary provider failed")
+			return
+		}
+		stats.Compared++
+		if len(differences) == 0 {
+			return
+		}
+		stats.Mismatched++
+		client.report.Mismatches = append(client.report.Mismatches, &Mismatch{
+			Method:      method,
+			Key:         key,
+			Differences: differences,
+			Time:        now(),
+		})
+		if client.MaxMismatches > 0 && len(client.report.Mismatches) > client.MaxMismatches {
+			client.report.Mismatches = client.report.Mismatches[len(client.report.Mismatches)-client.MaxMismatches:]
+		}
+		xc.LogEntry(ctx).WithField("method", method).WithField("key", key).WithField("differences", differences).Warn("shadow: providers mismatch")
+	}()
+}
+
+// FetchTxInput fetches the tx input from the primary provider only, as it's specific to the tx about to be sent
+func (client *Client) FetchTxInput(ctx context.Context, from xc.Address, to xc.Address) (xc.TxInput, error) {
+	return client.Primary.FetchTxInput(ctx, from, to)
+}
+
+// SubmitTx submits tx to the primary provider only
+func (client *Client) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	return client.Primary.SubmitTx(ctx, tx)
+}
+
+// FetchTxInfo fetches tx info from the primary provider, and compares it with the secondary provider
+func (client *Client) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
+	info, err := client.Primary.FetchTxInfo(ctx, txHash)
+	if err != nil {
+		client.skip(MethodFetchTxInfo)
+		return info, err
+	}
+	client.mirror(ctx, MethodFetchTxInfo, string(txHash), func(ctx context.Context) ([]string, error) {
+		secondary, err := client.Secondary.FetchTxInfo(ctx, txHash)
+		if err != nil {
+			return nil, err
+		}
+		return diffTxInfo(info, secondary, client.HeightTolerance), nil
+	})
+	return info, nil
+}
+
+// FetchBalance fetches the balance from the primary provider, and compares it with the secondary provider
+func (client *Client) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	return client.fetchBalance(ctx, MethodFetchBalance, address)
+}
+
+// FetchNativeBalance fetches the native balance from the primary provider, and compares it with the secondary provider
+func (client *Client) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	return client.fetchBalance(ctx, MethodFetchNativeBalance, address)
+}
+
+func balanceFetcher(c xc.Client, method string) (func(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error), error) {
+	balanceClient, ok := c.(xc.ClientBalance)
+	if !ok {
+		return nil, errors.New("client can't fetch balances")
+	}
+	if method == MethodFetchNativeBalance {
+		return balanceClient.FetchNativeBalance, nil
+	}
+	return balanceClient.FetchBalance, nil
+}
+
+func (client *Client) fetchBalance(ctx context.Context, method string, address xc.Address) (xc.AmountBlockchain, error) {
+	fetch, err := balanceFetcher(client.Primary, method)
+	if err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	balance, err := fetch(ctx, address)
+	if err != nil {
+		client.skip(method)
+		return balance, err
+	}
+	client.mirror(ctx, method, string(address), func(ctx context.Context) ([]string, error) {
+		fetch, err := balanceFetcher(client.Secondary, method)
+		if err != nil {
+			return nil, err
+		}
+		secondary, err := fetch(ctx, address)
+		if err != nil {
+			return nil, err
+		}
+		return diff(nil, "balance", balance.String(), secondary.String()), nil
+	})
+	return balance, nil
+}
+
+// FetchChainStats fetches the chain stats from the primary provider, and compares its height with the secondary provider
+func (client *Client) FetchChainStats(ctx context.Context) (*xc.ChainStats, error) {
+	statsClient, ok := xc.UnwrapClient(client.Primary).(xc.ClientChainStats)
+	if !ok {
+		return nil, errors.New("client can't fetch chain stats")
+	}
+	stats, err := statsClient.FetchChainStats(ctx)
+	if err != nil {
+		client.skip(MethodFetchChainStats)
+		return stats, err
+	}
+	client.mirror(ctx, MethodFetchChainStats, "", func(ctx context.Context) ([]string, error) {
+		secondaryClient, ok := xc.UnwrapClient(client.Secondary).(xc.ClientChainStats)
+		if !ok {
+			return nil, errors.New("client can't fetch chain stats")
+		}
+		secondary, err := secondaryClient.FetchChainStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if distance(int64(stats.Height), int64(secondary.Height)) > client.HeightTolerance {
+			return diff(nil, "height", stats.Height, secondary.Height), nil
+		}
+		return nil, nil
+	})
+	return stats, nil
+}
+
+func distance(a int64, b int64) uint64 {
+	if a > b {
+		return uint64(a - b)
+	}
+	return uint64(b - a)
+}
+
+// diff appends "field: primary != secondary" to differences if the values differ
+func diff(differences []string, field string, primary interface{}, secondary interface{}) []string {
+	a, b := fmt.Sprint(primary), fmt.Sprint(secondary)
+	if a != b {
+		differences = append(differences, fmt.Sprintf("%s: %s != %s", field, a, b))
+	}
+	return differences
+}
+
+// diffTxInfo compares the fields of tx infos read from the chain, ignoring the fields computed by the client
+// such as the explorer url and the time received
+func diffTxInfo(primary xc.TxInfo, secondary xc.TxInfo, heightTolerance uint64) []string {
+	differences := []string{}
+	differences = diff(differences, "tx_id", primary.TxID, secondary.TxID)
+	differences = diff(differences, "block_hash", primary.BlockHash, secondary.BlockHash)
+	differences = diff(differences, "block_index", primary.BlockIndex, secondary.BlockIndex)
+	differences = diff(differences, "block_time", primary.BlockTime, secondary.BlockTime)
+	if distance(primary.Confirmations, secondary.Confirmations) > heightTolerance {
+		differences = diff(differences, "confirmations", primary.Confirmations, secondary.Confirmations)
+	}
+	differences = diff(differences, "status", primary.Status, secondary.Status)
+	differences = diff(differences, "from", primary.From, secondary.From)
+	differences = diff(differences, "to", primary.To, secondary.To)
+	differences = diff(differences, "contract", primary.ContractAddress, secondary.ContractAddress)
+	differences = diff(differences, "amount", primary.Amount.String(), secondary.Amount.String())
+	differences = diff(differences, "fee", primary.Fee.String(), secondary.Fee.String())
+	differences = diff(differences, "error", primary.Error, secondary.Error)
+	differences = diffEndpoints(differences, "sources", primary.Sources, secondary.Sources)
+	differences = diffEndpoints(differences, "destinations", primary.Destinations, secondary.Destinations)
+	differences = diffEndpoints(differences, "change", primary.Change, secondary.Change)
+	return differences
+}
+
+func diffEndpoints(differences []string, field string, primary []*xc.TxInfoEndpoint, secondary []*xc.TxInfoEndpoint) []string {
+	if len(primary) != len(secondary) {
+		return diff(differences, field+" length", len(primary), len(secondary))
+	}
+	for i := range primary {
+		prefix := fmt.Sprintf("%s[%d].", field, i)
+		differences = diff(differences, prefix+"address", primary[i].Address, secondary[i].Address)
+		differences = diff(differences, prefix+"contract", primary[i].ContractAddress, secondary[i].ContractAddress)
+		differences = diff(differences, prefix+"amount", primary[i].Amount.String(), secondary[i].Amount.String())
+	}
+	return differences
+}
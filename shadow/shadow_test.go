@@ -0,0 +1,164 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestShadowTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+// statsClient is a client whose chain stats are at height
+type statsClient struct {
+	testutil.MockedClient
+	height uint64
+}
+
+func (client *statsClient) FetchChainStats(ctx context.Context) (*xc.ChainStats, error) {
+	return &xc.ChainStats{Height: client.height}, nil
+}
+
+func (s *CrosschainTestSuite) TestShadowBalances() {
+	require := s.Require()
+	primary := &testutil.MockedClient{}
+	secondary := &testutil.MockedClient{}
+	primary.On("FetchBalance", mock.Anything, xc.Address("a")).Return(xc.NewAmountBlockchainFromUint64(10), nil)
+	primary.On("FetchBalance", mock.Anything, xc.Address("b")).Return(xc.NewAmountBlockchainFromUint64(20), nil)
+	primary.On("FetchNativeBalance", mock.Anything, xc.Address("a")).Return(xc.NewAmountBlockchainFromUint64(1), nil)
+	primary.On("FetchBalance", mock.Anything, xc.Address("c")).Return(xc.AmountBlockchain{}, errors.New("primary down"))
+	secondary.On("FetchBalance", mock.Anything, xc.Address("a")).Return(xc.NewAmountBlockchainFromUint64(10), nil)
+	secondary.On("FetchBalance", mock.Anything, xc.Address("b")).Return(xc.NewAmountBlockchainFromUint64(21), nil)
+	secondary.On("FetchNativeBalance", mock.Anything, xc.Address("a")).Return(xc.AmountBlockchain{}, errors.New("secondary down"))
+	client := NewClient(primary, secondary)
+
+	// the results of the primary provider are returned
+	balance, err := client.FetchBalance(s.Ctx, "a")
+	require.NoError(err)
+	require.EqualValues(10, balance.Uint64())
+	balance, _ = client.FetchBalance(s.Ctx, "b")
+	require.EqualValues(20, balance.Uint64())
+	balance, err = client.FetchNativeBalance(s.Ctx, "a")
+	require.NoError(err)
+	require.EqualValues(1, balance.Uint64())
+	_, err = client.FetchBalance(s.Ctx, "c")
+	require.EqualError(err, "primary down")
+	client.Wait()
+
+	report := client.Report()
+	require.False(report.Matching())
+	require.Equal(&MethodStats{Compared: 2, Mismatched: 1, Skipped: 1}, report.Methods[MethodFetchBalance])
+	require.Equal(&MethodStats{SecondaryErrors: 1}, report.Methods[MethodFetchNativeBalance])
+	require.Len(report.Mismatches, 1)
+	require.Equal(MethodFetchBalance, report.Mismatches[0].Method)
+	require.Equal("b", report.Mismatches[0].Key)
+	require.Equal([]string{"balance: 20 != 21"}, report.Mismatches[0].Differences)
+	secondary.AssertNotCalled(s.T(), "FetchBalance", mock.Anything, xc.Address("c"))
+
+	client.Reset()
+	client.FetchBalance(s.Ctx, "a")
+	client.Wait()
+	require.True(client.Report().Matching())
+}
+
+func (s *CrosschainTestSuite) TestShadowTxInfo() {
+	require := s.Require()
+	primary := &testutil.MockedClient{}
+	secondary := &testutil.MockedClient{}
+	info := xc.TxInfo{
+		TxID: "0xhash", BlockIndex: 100, Confirmations: 10, Status: xc.TxStatusSuccess, Amount: xc.NewAmountBlockchainFromUint64(5),
+		ExplorerURL:  "https://etherscan.io/tx/0xhash",
+		Destinations: []*xc.TxInfoEndpoint{{Address: "to", Amount: xc.NewAmountBlockchainFromUint64(5)}},
+	}
+	other := info
+	other.Confirmations = 12
+	other.ExplorerURL = ""
+	other.Destinations = []*xc.TxInfoEndpoint{{Address: "to", Amount: xc.NewAmountBlockchainFromUint64(5)}}
+	primary.On("FetchTxInfo", mock.Anything, xc.TxHash("0xhash")).Return(info, nil)
+	secondary.On("FetchTxInfo", mock.Anything, xc.TxHash("0xhash")).Return(other, nil).Once()
+
+	client := NewClient(primary, secondary)
+	result, err := client.FetchTxInfo(s.Ctx, "0xhash")
+	require.NoError(err)
+	require.Equal(info, result)
+	client.Wait()
+	require.True(client.Report().Matching())
+
+	// reorged on the secondary provider
+	other.BlockIndex = 101
+	other.Confirmations = 5
+	other.Destinations = []*xc.TxInfoEndpoint{{Address: "other", Amount: xc.NewAmountBlockchainFromUint64(5)}}
+	secondary.On("FetchTxInfo", mock.Anything, xc.TxHash("0xhash")).Return(other, nil).Once()
+	client.FetchTxInfo(s.Ctx, "0xhash")
+	client.Wait()
+	report := client.Report()
+	require.Len(report.Mismatches, 1)
+	require.Equal([]string{"block_index: 100 != 101", "confirmations: 10 != 5", "destinations[0].address: to != other"}, report.Mismatches[0].Differences)
+}
+
+func (s *CrosschainTestSuite) TestShadowHeights() {
+	require := s.Require()
+	primary := &statsClient{height: 1000}
+	secondary := &statsClient{height: 998}
+	client := NewClient(primary, secondary)
+	stats, err := client.FetchChainStats(s.Ctx)
+	require.NoError(err)
+	require.EqualValues(1000, stats.Height)
+	client.Wait()
+	require.True(client.Report().Matching())
+
+	secondary.height = 990
+	client.FetchChainStats(s.Ctx)
+	client.Wait()
+	require.Equal([]string{"height: 1000 != 990"}, client.Report().Mismatches[0].Differences)
+
+	// a client without chain stats
+	client = NewClient(&testutil.MockedClient{}, secondary)
+	_, err = client.FetchChainStats(s.Ctx)
+	require.EqualError(err, "client can't fetch chain stats")
+}
+
+func (s *CrosschainTestSuite) TestShadowLimits() {
+	require := s.Require()
+	primary := &testutil.MockedClient{}
+	secondary := &testutil.MockedClient{}
+	release := make(chan time.Time)
+	primary.On("FetchBalance", mock.Anything, mock.Anything).Return(xc.NewAmountBlockchainFromUint64(1), nil)
+	primary.On("SubmitTx", mock.Anything, mock.Anything).Return(nil)
+	secondary.On("FetchBalance", mock.Anything, mock.Anything).WaitUntil(release).Return(xc.NewAmountBlockchainFromUint64(2), nil)
+	client := NewClient(primary, secondary)
+	client.MaxInFlight = 1
+	client.MaxMismatches = 1
+
+	// the second read isn't mirrored while the first one is in flight
+	client.FetchBalance(s.Ctx, "a")
+	client.FetchBalance(s.Ctx, "b")
+	close(release)
+	client.Wait()
+	client.FetchBalance(s.Ctx, "c")
+	client.Wait()
+	report := client.Report()
+	require.Equal(&MethodStats{Compared: 2, Mismatched: 2, Skipped: 1}, report.Methods[MethodFetchBalance])
+	require.Len(report.Mismatches, 1)
+	require.Equal("c", report.Mismatches[0].Key)
+
+	// txs are only submitted to the primary provider
+	require.NoError(client.SubmitTx(s.Ctx, nil))
+	secondary.AssertNotCalled(s.T(), "SubmitTx", mock.Anything, mock.Anything)
+}
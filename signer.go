@@ -1,5 +1,7 @@
 package crosschain
 
+import "errors"
+
 // PrivateKey is a private key or reference to private key
 type PrivateKey []byte
 
@@ -11,3 +13,89 @@ type Signer interface {
 	ImportPrivateKey(privateKey string) (PrivateKey, error)
 	Sign(privateKey PrivateKey, data TxDataToSign) (TxSignature, error)
 }
+
+// PublicKeyDeriver is a Signer that can derive the public key of a private key, in the format of its AddressBuilder
+type PublicKeyDeriver interface {
+	DerivePublicKey(privateKey PrivateKey) (PublicKey, error)
+}
+
+// KeySigner signs with a single key, held in memory or externally, e.g. by an MPC service or a HSM
+// It signs the sighashes of a tx between Tx.Sighashes and Tx.AddSignatures, see SignTx
+type KeySigner interface {
+	Sign(data TxDataToSign) (TxSignature, error)
+	PublicKey() (PublicKey, error)
+}
+
+// TxWithSignPayloads is a Tx that can return the payloads its sighashes are hashed from, in the same order
+type TxWithSignPayloads interface {
+	SignPayloads() ([]TxDataToSign, error)
+}
+
+// PayloadSigner is a KeySigner signing the payloads of txs rather than their sighashes, e.g. a hardware wallet
+// parsing the tx to display it, see TxWithSignPayloads
+type PayloadSigner interface {
+	KeySigner
+	SignPayload(payload TxDataToSign) (TxSignature, error)
+}
+
+// LocalSigner is a KeySigner holding a private key in memory, signing with the Signer of its chain
+type LocalSigner struct {
+	Signer     Signer
+	privateKey PrivateKey
+	publicKey  PublicKey
+}
+
+var _ KeySigner = &LocalSigner{}
+
+// NewLocalSigner creates a new LocalSigner of privateKey, imported by signer
+func NewLocalSigner(signer Signer, privateKey PrivateKey) (*LocalSigner, error) {
+	deriver, ok := signer.(PublicKeyDeriver)
+	if !ok {
+		return nil, errors.New("signer can't derive public keys")
+	}
+	publicKey, err := deriver.DerivePublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalSigner{
+		Signer:     signer,
+		privateKey: privateKey,
+		publicKey:  publicKey,
+	}, nil
+}
+
+// Sign signs data with the private key
+func (signer *LocalSigner) Sign(data TxDataToSign) (TxSignature, error) {
+	return signer.Signer.Sign(signer.privateKey, data)
+}
+
+// PublicKey returns the public key of the private key
+func (signer *LocalSigner) PublicKey() (PublicKey, error) {
+	return signer.publicKey, nil
+}
+
+// SignTx signs the sighashes of tx with signer and adds the signatures
+// A PayloadSigner signs the payloads of the tx instead, which must then be a TxWithSignPayloads
+func SignTx(signer KeySigner, tx Tx) error {
+	sign := signer.Sign
+	sighashes, err := tx.Sighashes()
+	if payloadSigner, ok := signer.(PayloadSigner); ok {
+		txWithPayloads, ok := tx.(TxWithSignPayloads)
+		if !ok {
+			return errors.New("tx can't be signed by a payload signer")
+		}
+		sign = payloadSigner.SignPayload
+		sighashes, err = txWithPayloads.SignPayloads()
+	}
+	if err != nil {
+		return err
+	}
+	signatures := make([]TxSignature, len(sighashes))
+	for i, sighash := range sighashes {
+		signatures[i], err = sign(sighash)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.AddSignatures(signatures...)
+}
@@ -0,0 +1,98 @@
+package crosschain
+
+import "errors"
+
+// testDerivingSigner derives the reversed private key as public key
+type testDerivingSigner struct {
+	testKeySigner
+}
+
+func (signer testDerivingSigner) DerivePublicKey(privateKey PrivateKey) (PublicKey, error) {
+	if len(privateKey) == 0 {
+		return nil, errors.New("empty private key")
+	}
+	publicKey := PublicKey{}
+	for i := len(privateKey) - 1; i >= 0; i-- {
+		publicKey = append(publicKey, privateKey[i])
+	}
+	return publicKey, nil
+}
+
+// failingKeySigner is a KeySigner whose signatures fail, e.g. a remote signer down
+type failingKeySigner struct{}
+
+func (signer failingKeySigner) Sign(data TxDataToSign) (TxSignature, error) {
+	return nil, errors.New("signer unavailable")
+}
+
+func (signer failingKeySigner) PublicKey() (PublicKey, error) {
+	return nil, errors.New("signer unavailable")
+}
+
+// testPayloadTx is a testKeyTx with the payloads of its sighashes
+type testPayloadTx struct {
+	testKeyTx
+	payloads []TxDataToSign
+}
+
+func (tx *testPayloadTx) SignPayloads() ([]TxDataToSign, error) {
+	return tx.payloads, nil
+}
+
+// testPayloadSigner signs payloads with the prefix 7, and can't sign sighashes
+type testPayloadSigner struct {
+	failingKeySigner
+}
+
+func (signer testPayloadSigner) SignPayload(payload TxDataToSign) (TxSignature, error) {
+	return TxSignature(append([]byte{7}, payload...)), nil
+}
+
+func (s *CrosschainTestSuite) TestLocalSigner() {
+	require := s.Require()
+	signer, err := NewLocalSigner(testDerivingSigner{}, PrivateKey{1, 2})
+	require.NoError(err)
+	publicKey, err := signer.PublicKey()
+	require.NoError(err)
+	require.Equal(PublicKey{2, 1}, publicKey)
+	signature, err := signer.Sign(TxDataToSign{9})
+	require.NoError(err)
+	require.Equal(TxSignature{1, 2, 9}, signature)
+
+	_, err = NewLocalSigner(testDerivingSigner{}, PrivateKey{})
+	require.EqualError(err, "empty private key")
+	_, err = NewLocalSigner(testKeySigner{}, PrivateKey{1, 2})
+	require.EqualError(err, "signer can't derive public keys")
+}
+
+func (s *CrosschainTestSuite) TestSignTx() {
+	require := s.Require()
+	signer, _ := NewLocalSigner(testDerivingSigner{}, PrivateKey{1})
+	tx := &testKeyTx{sighashes: []TxDataToSign{{2}, {3}}}
+	err := SignTx(signer, tx)
+	require.NoError(err)
+	require.Equal([]TxSignature{{1, 2}, {1, 3}}, tx.signatures)
+
+	tx = &testKeyTx{sighashes: []TxDataToSign{{2}}}
+	err = SignTx(failingKeySigner{}, tx)
+	require.EqualError(err, "signer unavailable")
+	require.Empty(tx.signatures)
+}
+
+func (s *CrosschainTestSuite) TestSignTxPayloads() {
+	require := s.Require()
+	tx := &testPayloadTx{testKeyTx: testKeyTx{sighashes: []TxDataToSign{{2}}}, payloads: []TxDataToSign{{4, 5}}}
+	err := SignTx(testPayloadSigner{}, tx)
+	require.NoError(err)
+	require.Equal([]TxSignature{{7, 4, 5}}, tx.signatures)
+
+	// sighashes are signed by other signers
+	tx = &testPayloadTx{testKeyTx: testKeyTx{sighashes: []TxDataToSign{{2}}}, payloads: []TxDataToSign{{4, 5}}}
+	signer, _ := NewLocalSigner(testDerivingSigner{}, PrivateKey{1})
+	err = SignTx(signer, tx)
+	require.NoError(err)
+	require.Equal([]TxSignature{{1, 2}}, tx.signatures)
+
+	err = SignTx(testPayloadSigner{}, &testKeyTx{sighashes: []TxDataToSign{{2}}})
+	require.EqualError(err, "tx can't be signed by a payload signer")
+}
@@ -0,0 +1,53 @@
+package crosschain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSimulationNotSupported is returned by SimulateTx for txs that the client can't simulate
+var ErrSimulationNotSupported = errors.New("simulation is not supported")
+
+// SimulationResult is the outcome of executing a tx without broadcasting it
+// A tx failing its execution, e.g. reverted, is a result with Success false rather than an error
+type SimulationResult struct {
+	Chain   NativeAsset `json:"chain"`
+	TxHash  TxHash      `json:"tx_hash"`
+	Success bool        `json:"success"`
+	// GasUsed is the gas or compute units used, 0 if the node doesn't report it
+	GasUsed uint64 `json:"gas_used,omitempty"`
+	// FailureReason is a human readable reason of the failure, e.g. a decoded revert reason or custom error
+	FailureReason string `json:"failure_reason,omitempty"`
+	// RawError is the error of the node the FailureReason is decoded from
+	RawError string `json:"raw_error,omitempty"`
+	// Logs of the execution, e.g. Solana program logs or Cosmos ABCI logs
+	Logs []string `json:"logs,omitempty"`
+}
+
+// ClientSimulator is a Client that can simulate txs
+type ClientSimulator interface {
+	// SimulateTx executes tx without broadcasting it, returning an error only if the tx couldn't be simulated
+	SimulateTx(ctx context.Context, tx Tx) (*SimulationResult, error)
+}
+
+// Err returns an error with the failure reason of a failed simulation, nil if it succeeded
+func (result *SimulationResult) Err() error {
+	if result.Success {
+		return nil
+	}
+	reason := result.FailureReason
+	if reason == "" {
+		reason = result.RawError
+	}
+	return &SimulationError{Result: result, reason: reason}
+}
+
+// SimulationError is the error of a failed simulation, see SimulationResult.Err
+type SimulationError struct {
+	Result *SimulationResult
+	reason string
+}
+
+func (err *SimulationError) Error() string {
+	return "simulation of tx " + string(err.Result.TxHash) + " failed: " + err.reason
+}
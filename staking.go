@@ -0,0 +1,83 @@
+package crosschain
+
+import "context"
+
+// StakingProtocol identifies a liquid staking protocol
+type StakingProtocol string
+
+// List of supported liquid staking protocols
+const (
+	StakingProtocolLido     = StakingProtocol("lido")
+	StakingProtocolMarinade = StakingProtocol("marinade")
+	StakingProtocolStride   = StakingProtocol("stride")
+)
+
+// StakedBalance is a position held in a liquid staking protocol
+type StakedBalance struct {
+	Protocol StakingProtocol `json:"protocol"`
+	Address  Address         `json:"address"`
+	// Contract of the liquid staking token, e.g. stETH or mSOL
+	Contract ContractAddress `json:"contract"`
+	// Amount of liquid staking token held
+	Amount AmountBlockchain `json:"amount"`
+	// Amount of native asset the position can be redeemed for, if known
+	Underlying AmountBlockchain `json:"underlying"`
+	// Amount of native asset requested for withdrawal but not claimed yet
+	Pending AmountBlockchain `json:"pending"`
+}
+
+// ClientStakedBalance is a specific Client that can fetch liquid staking positions
+type ClientStakedBalance interface {
+	FetchStakedBalance(ctx context.Context, address Address) ([]StakedBalance, error)
+}
+
+// TxLiquidStakingBuilder is a Builder that can enter and exit liquid staking positions
+type TxLiquidStakingBuilder interface {
+	// NewLiquidStake deposits amount of native asset in exchange for liquid staking tokens
+	NewLiquidStake(from Address, amount AmountBlockchain, input TxInput) (Tx, error)
+	// NewLiquidUnstake redeems amount of liquid staking tokens, possibly via a withdrawal queue
+	NewLiquidUnstake(from Address, amount AmountBlockchain, input TxInput) (Tx, error)
+}
+
+// ClaimableReward is a staking reward accrued with a validator that can be claimed
+type ClaimableReward struct {
+	Validator Address `json:"validator"`
+	// Contract of the reward asset, e.g. a denom, or empty for the native asset
+	Contract ContractAddress  `json:"contract"`
+	Amount   AmountBlockchain `json:"amount"`
+}
+
+// ClientRewards is a specific Client that can fetch claimable staking rewards
+type ClientRewards interface {
+	FetchClaimableRewards(ctx context.Context, address Address) ([]ClaimableReward, error)
+}
+
+// TxRewardsBuilder is a Builder that can claim staking rewards
+type TxRewardsBuilder interface {
+	// NewClaimRewards claims rewards from all validators in rewards
+	NewClaimRewards(from Address, rewards []ClaimableReward, input TxInput) (Tx, error)
+	// NewCompoundRewards claims rewards and delegates the native rewards back to the same validators
+	NewCompoundRewards(from Address, rewards []ClaimableReward, input TxInput) (Tx, error)
+}
+
+// TxStakingBuilder is a Builder that can delegate the native asset to validators
+type TxStakingBuilder interface {
+	// NewDelegate delegates amount to validator
+	NewDelegate(from Address, validator Address, amount AmountBlockchain, input TxInput) (Tx, error)
+	// NewUndelegate starts unbonding amount delegated to validator
+	NewUndelegate(from Address, validator Address, amount AmountBlockchain, input TxInput) (Tx, error)
+	// NewWithdrawRewards withdraws the rewards accrued with validator, see TxRewardsBuilder to claim from many validators
+	NewWithdrawRewards(from Address, validator Address, input TxInput) (Tx, error)
+}
+
+// StakingContract configures the contract EVM chains delegate to validators with, e.g. a system staking contract
+// The methods are signatures such as "delegate(address,uint256)": parameters of type address are passed the validator,
+// and of type uint256 the amount
+type StakingContract struct {
+	Contract        string `yaml:"contract"`
+	Delegate        string `yaml:"delegate"`
+	Undelegate      string `yaml:"undelegate"`
+	WithdrawRewards string `yaml:"withdraw_rewards"`
+	// Payable sends the delegated amount as the value of the delegate call, for contracts staking the native asset
+	Payable bool `yaml:"payable"`
+}
@@ -0,0 +1,21 @@
+package crosschain
+
+// StarknetConfig is the config of a StarkNet chain, see GetStarknet
+// The native asset of the chain is the ERC-20 contract paying fees, chain_coin, STRK if not set
+type StarknetConfig struct {
+	// AccountClassHash is the class of the accounts of the addresses derived from public keys, deployed with the
+	// public key as salt and as only constructor argument, e.g. OpenZeppelin accounts
+	AccountClassHash string `yaml:"account_class_hash"`
+}
+
+// OpenZeppelinAccountClassHash is the class hash of the account of OpenZeppelin Contracts for Cairo v0.8.1
+const OpenZeppelinAccountClassHash = "0x061dac032f228abef9c6626f995015233097ae253a7f72d68552db02f2971b8f"
+
+// GetStarknet returns the StarkNet config of a chain, with OpenZeppelin accounts by default
+func (asset *NativeAssetConfig) GetStarknet() StarknetConfig {
+	starknet := asset.Starknet
+	if starknet.AccountClassHash == "" {
+		starknet.AccountClassHash = OpenZeppelinAccountClassHash
+	}
+	return starknet
+}
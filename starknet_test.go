@@ -0,0 +1,17 @@
+package crosschain
+
+func (s *CrosschainTestSuite) TestGetStarknet() {
+	require := s.Require()
+
+	asset := &NativeAssetConfig{NativeAsset: STRK}
+	require.Equal(OpenZeppelinAccountClassHash, asset.GetStarknet().AccountClassHash)
+	asset = &NativeAssetConfig{NativeAsset: STRK, Starknet: StarknetConfig{AccountClassHash: "0x1"}}
+	require.Equal("0x1", asset.GetStarknet().AccountClassHash)
+
+	require.Equal(DriverStarknet, STRK.Driver())
+	require.Equal(Stark, STRK.SignatureAlgorithm())
+	require.EqualValues(18, STRK.Decimals())
+
+	require.Equal("0x4718f5a0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d", NormalizeContractAddress(DriverStarknet, "0x04718F5A0fc34cc1af16a1cdee98ffb20c31f5cd61d6ab07201858f4287c938d"))
+	require.Equal("", NormalizeContractAddress(DriverStarknet, "0x000"))
+}
@@ -0,0 +1,72 @@
+package crosschain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors of assets whose txs can't be built or submitted, see CheckSendAllowed
+var (
+	ErrAssetDisabled    = errors.New("asset is disabled")
+	ErrAssetReceiveOnly = errors.New("asset is receive-only")
+	ErrAssetPaused      = errors.New("asset is paused")
+)
+
+// IsEnabled returns true unless the asset is disabled with enabled: false
+func (asset *AssetConfig) IsEnabled() bool {
+	return asset.Enabled == nil || *asset.Enabled
+}
+
+// CheckSendAllowed returns an error if txs sending asset must not be built or submitted:
+// the asset or its chain is disabled, receive-only or paused
+// It's enforced by the NewTransfer of builders and the SubmitTx of clients, so that operators can halt
+// the withdrawals of a single asset by changing its config
+func CheckSendAllowed(asset ITask) error {
+	if asset == nil {
+		return nil
+	}
+	if task := asset.GetTask(); task != nil && task.SrcAsset == nil {
+		return nil
+	}
+	if err := checkSendAllowed(asset.ID(), asset.GetAssetConfig()); err != nil {
+		return err
+	}
+	if native := asset.GetNativeAsset(); native != nil {
+		return checkSendAllowed(native.ID(), native)
+	}
+	return nil
+}
+
+func checkSendAllowed(id AssetID, asset *AssetConfig) error {
+	if asset == nil {
+		return nil
+	}
+	if !asset.IsEnabled() {
+		return fmt.Errorf("%w: %s", ErrAssetDisabled, id)
+	}
+	if asset.PausedWithReason != "" {
+		return fmt.Errorf("%w: %s: %s", ErrAssetPaused, id, asset.PausedWithReason)
+	}
+	if asset.ReceiveOnly {
+		return fmt.Errorf("%w: %s", ErrAssetReceiveOnly, id)
+	}
+	return nil
+}
+
+// CheckReceiveAllowed returns an error if asset or its chain is disabled,
+// e.g. before handing out a deposit address: receive-only and paused assets may still be received
+func CheckReceiveAllowed(asset ITask) error {
+	if asset == nil {
+		return nil
+	}
+	if task := asset.GetTask(); task != nil && task.SrcAsset == nil {
+		return nil
+	}
+	if !asset.GetAssetConfig().IsEnabled() {
+		return fmt.Errorf("%w: %s", ErrAssetDisabled, asset.ID())
+	}
+	if native := asset.GetNativeAsset(); native != nil && !native.IsEnabled() {
+		return fmt.Errorf("%w: %s", ErrAssetDisabled, native.ID())
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package crosschain
+
+import "errors"
+
+func (s *CrosschainTestSuite) TestCheckSendAllowed() {
+	require := s.Require()
+	enabled, disabled := true, false
+	eth := &NativeAssetConfig{Asset: "ETH", NativeAsset: ETH}
+	require.True(eth.IsEnabled())
+	require.NoError(CheckSendAllowed(eth))
+	eth.Enabled = &enabled
+	require.NoError(CheckSendAllowed(eth))
+
+	eth.Enabled = &disabled
+	err := CheckSendAllowed(eth)
+	require.EqualError(err, "asset is disabled: ETH")
+	require.True(errors.Is(err, ErrAssetDisabled))
+	require.EqualError(CheckReceiveAllowed(eth), "asset is disabled: ETH")
+
+	eth.Enabled = nil
+	eth.PausedWithReason = "incident #42"
+	err = CheckSendAllowed(eth)
+	require.EqualError(err, "asset is paused: ETH: incident #42")
+	require.True(errors.Is(err, ErrAssetPaused))
+	require.NoError(CheckReceiveAllowed(eth))
+
+	eth.PausedWithReason = ""
+	eth.ReceiveOnly = true
+	err = CheckSendAllowed(eth)
+	require.EqualError(err, "asset is receive-only: ETH")
+	require.True(errors.Is(err, ErrAssetReceiveOnly))
+	require.NoError(CheckReceiveAllowed(eth))
+	eth.ReceiveOnly = false
+
+	// tokens, and their chain
+	usdc := &TokenAssetConfig{Asset: "USDC", Chain: "ETH", NativeAssetConfig: eth}
+	usdc.AssetConfig = AssetConfig{Asset: "USDC", Chain: "ETH", ReceiveOnly: true}
+	require.EqualError(CheckSendAllowed(usdc), "asset is receive-only: USDC.ETH")
+	require.NoError(CheckSendAllowed(eth))
+
+	usdc.AssetConfig.ReceiveOnly = false
+	require.NoError(CheckSendAllowed(usdc))
+	eth.PausedWithReason = "reorg"
+	require.EqualError(CheckSendAllowed(usdc), "asset is paused: ETH: reorg")
+	eth.Enabled = &disabled
+	require.EqualError(CheckReceiveAllowed(usdc), "asset is disabled: ETH")
+
+	require.NoError(CheckSendAllowed(nil))
+	require.NoError(CheckSendAllowed(&TaskConfig{Name: "task"}))
+}
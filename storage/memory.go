@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// MemoryStorage is a Storage keeping transfers in memory, e.g. for tests
+type MemoryStorage struct {
+	mu          sync.Mutex
+	transfers   map[string]*Transfer
+	transitions map[string][]StateTransition
+}
+
+var _ Storage = &MemoryStorage{}
+
+// NewMemoryStorage creates a new MemoryStorage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		transfers:   map[string]*Transfer{},
+		transitions: map[string][]StateTransition{},
+	}
+}
+
+// Save inserts or updates a transfer
+func (s *MemoryStorage) Save(ctx context.Context, transfer *Transfer) error {
+	if transfer.ID == "" {
+		return errors.New("transfer id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *transfer
+	saved.Error = xc.DefaultRedactor.Redact(transfer.Error)
+	saved.Metadata = transfer.Metadata.Clone()
+	saved.UpdatedAt = now()
+	if existing, ok := s.transfers[transfer.ID]; ok {
+		saved.State = existing.State
+		saved.CreatedAt = existing.CreatedAt
+	} else {
+		if saved.State == "" {
+			saved.State = TransferStateCreated
+		}
+		saved.CreatedAt = saved.UpdatedAt
+	}
+	s.transfers[transfer.ID] = &saved
+	*transfer = saved
+	transfer.Metadata = saved.Metadata.Clone()
+	return nil
+}
+
+// Load returns a transfer given its id
+func (s *MemoryStorage) Load(ctx context.Context, id string) (*Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transfer, ok := s.transfers[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	loaded := *transfer
+	loaded.Metadata = transfer.Metadata.Clone()
+	return &loaded, nil
+}
+
+// List returns transfers matching opts, ordered by creation time
+func (s *MemoryStorage) List(ctx context.Context, opts ListOptions) ([]*Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transfers := []*Transfer{}
+	for _, transfer := range s.transfers {
+		if opts.State != "" && transfer.State != opts.State {
+			continue
+		}
+		if opts.Pipeline != "" && transfer.Pipeline != opts.Pipeline {
+			continue
+		}
+		loaded := *transfer
+		loaded.Metadata = transfer.Metadata.Clone()
+		transfers = append(transfers, &loaded)
+	}
+	sort.Slice(transfers, func(i, j int) bool {
+		if transfers[i].CreatedAt.Equal(transfers[j].CreatedAt) {
+			return transfers[i].ID < transfers[j].ID
+		}
+		return transfers[i].CreatedAt.Before(transfers[j].CreatedAt)
+	})
+	if opts.Limit > 0 && len(transfers) > opts.Limit {
+		transfers = transfers[:opts.Limit]
+	}
+	return transfers, nil
+}
+
+// Transition moves a transfer to a new state
+func (s *MemoryStorage) Transition(ctx context.Context, id string, to TransferState, note string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transfer, ok := s.transfers[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := checkTransition(id, transfer.State, to); err != nil {
+		return err
+	}
+	at := now()
+	s.transitions[id] = append(s.transitions[id], StateTransition{
+		TransferID: id,
+		From:       transfer.State,
+		To:         to,
+		Note:       xc.DefaultRedactor.Redact(note),
+		At:         at,
+		Metadata:   transitionMetadata(ctx, transfer.Metadata),
+	})
+	transfer.State = to
+	transfer.UpdatedAt = at
+	return nil
+}
+
+// Transitions returns the state transitions of a transfer, in order
+func (s *MemoryStorage) Transitions(ctx context.Context, id string) ([]StateTransition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.transfers[id]; !ok {
+		return nil, ErrNotFound
+	}
+	transitions := append([]StateTransition{}, s.transitions[id]...)
+	for i := range transitions {
+		transitions[i].Metadata = transitions[i].Metadata.Clone()
+	}
+	return transitions, nil
+}
@@ -0,0 +1,330 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Dialect is the SQL flavor of a database
+type Dialect struct {
+	Name string
+	// numbered placeholders ($1, $2...) instead of ?
+	numberedPlaceholders bool
+	// row lock clause of a SELECT in a transaction
+	forUpdate string
+}
+
+// Supported Dialect
+var (
+	Postgres = Dialect{Name: "postgres", numberedPlaceholders: true, forUpdate: " FOR UPDATE"}
+	// SQLite locks the database on write, and requires 3.24+ for upserts
+	SQLite = Dialect{Name: "sqlite"}
+)
+
+// rebind replaces ? placeholders of query with the placeholders of the dialect
+func (d Dialect) rebind(query string) string {
+	if !d.numberedPlaceholders {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// Schema of the SQL storage, created by Migrate
+var Schema = []string{
+	`CREATE TABLE IF NOT EXISTS xc_transfers (
+		id TEXT PRIMARY KEY,
+		pipeline TEXT NOT NULL,
+		src_asset TEXT NOT NULL,
+		dst_asset TEXT NOT NULL,
+		from_address TEXT NOT NULL,
+		to_address TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		state TEXT NOT NULL,
+		tx_hash TEXT NOT NULL,
+		error TEXT NOT NULL,
+		metadata TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS xc_transfers_state ON xc_transfers (state, created_at)`,
+	`CREATE TABLE IF NOT EXISTS xc_transfer_transitions (
+		transfer_id TEXT NOT NULL,
+		seq INTEGER NOT NULL,
+		from_state TEXT NOT NULL,
+		to_state TEXT NOT NULL,
+		note TEXT NOT NULL,
+		at TIMESTAMP NOT NULL,
+		metadata TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (transfer_id, seq)
+	)`,
+}
+
+// columnMigrations add the columns missing from the tables of earlier schemas, and fail once they exist
+var columnMigrations = []string{
+	`ALTER TABLE xc_transfers ADD COLUMN metadata TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE xc_transfer_transitions ADD COLUMN metadata TEXT NOT NULL DEFAULT ''`,
+}
+
+// isDuplicateColumn returns true if err is the error of adding a column that exists
+// Postgres supports ADD COLUMN IF NOT EXISTS, but SQLite doesn't
+func isDuplicateColumn(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "duplicate column") || strings.Contains(msg, "already exists")
+}
+
+const transferColumns = "id, pipeline, src_asset, dst_asset, from_address, to_address, amount, state, tx_hash, error, metadata, created_at, updated_at"
+
+// SQLStorage is a Storage backed by a SQL database, e.g. Postgres or SQLite
+// The database driver must be registered by the caller, e.g. by importing github.com/lib/pq
+type SQLStorage struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+var _ Storage = &SQLStorage{}
+
+// NewSQLStorage creates a new SQLStorage
+func NewSQLStorage(db *sql.DB, dialect Dialect) *SQLStorage {
+	return &SQLStorage{
+		DB:      db,
+		Dialect: dialect,
+	}
+}
+
+// NewPostgresStorage creates a new SQLStorage for Postgres
+func NewPostgresStorage(db *sql.DB) *SQLStorage {
+	return NewSQLStorage(db, Postgres)
+}
+
+// NewSQLiteStorage creates a new SQLStorage for SQLite
+func NewSQLiteStorage(db *sql.DB) *SQLStorage {
+	return NewSQLStorage(db, SQLite)
+}
+
+// Migrate creates the tables of the storage if they don't exist
+func (s *SQLStorage) Migrate(ctx context.Context) error {
+	for _, statement := range Schema {
+		if _, err := s.DB.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to migrate: %v", err)
+		}
+	}
+	for _, statement := range columnMigrations {
+		if _, err := s.DB.ExecContext(ctx, statement); err != nil && !isDuplicateColumn(err) {
+			return fmt.Errorf("failed to migrate: %v", err)
+		}
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTransfer(row rowScanner) (*Transfer, error) {
+	transfer := &Transfer{}
+	var amount, metadata string
+	err := row.Scan(
+		&transfer.ID, &transfer.Pipeline, &transfer.SrcAsset, &transfer.DstAsset, &transfer.From, &transfer.To,
+		&amount, &transfer.State, &transfer.TxHash, &transfer.Error, &metadata, &transfer.CreatedAt, &transfer.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Amount = xc.NewAmountBlockchainFromStr(amount)
+	if transfer.Metadata, err = unmarshalMetadata(metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata of transfer %s: %v", transfer.ID, err)
+	}
+	return transfer, nil
+}
+
+// marshalMetadata encodes metadata as a JSON object, empty if there is no metadata
+func marshalMetadata(metadata xc.Metadata) (string, error) {
+	if len(metadata) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(metadata)
+	return string(data), err
+}
+
+func unmarshalMetadata(data string) (xc.Metadata, error) {
+	if data == "" {
+		return nil, nil
+	}
+	metadata := xc.Metadata{}
+	err := json.Unmarshal([]byte(data), &metadata)
+	return metadata, err
+}
+
+// Save inserts or updates a transfer, without changing its state if it exists
+func (s *SQLStorage) Save(ctx context.Context, transfer *Transfer) error {
+	if transfer.ID == "" {
+		return errors.New("transfer id is required")
+	}
+	state := transfer.State
+	if state == "" {
+		state = TransferStateCreated
+	}
+	metadata, err := marshalMetadata(transfer.Metadata)
+	if err != nil {
+		return err
+	}
+	at := now()
+	query := s.Dialect.rebind(`INSERT INTO xc_transfers (` + transferColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			pipeline = excluded.pipeline, src_asset = excluded.src_asset, dst_asset = excluded.dst_asset,
+			from_address = excluded.from_address, to_address = excluded.to_address, amount = excluded.amount,
+			tx_hash = excluded.tx_hash, error = excluded.error, metadata = excluded.metadata, updated_at = excluded.updated_at`)
+	_, err = s.DB.ExecContext(ctx, query,
+		transfer.ID, transfer.Pipeline, transfer.SrcAsset, transfer.DstAsset, transfer.From, transfer.To,
+		transfer.Amount.String(), state, transfer.TxHash, xc.DefaultRedactor.Redact(transfer.Error), metadata, at, at,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save transfer %s: %v", transfer.ID, err)
+	}
+	saved, err := s.Load(ctx, transfer.ID)
+	if err != nil {
+		return err
+	}
+	*transfer = *saved
+	return nil
+}
+
+// Load returns a transfer given its id
+func (s *SQLStorage) Load(ctx context.Context, id string) (*Transfer, error) {
+	query := s.Dialect.rebind(`SELECT ` + transferColumns + ` FROM xc_transfers WHERE id = ?`)
+	transfer, err := scanTransfer(s.DB.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return transfer, err
+}
+
+// List returns transfers matching opts, ordered by creation time
+func (s *SQLStorage) List(ctx context.Context, opts ListOptions) ([]*Transfer, error) {
+	query := `SELECT ` + transferColumns + ` FROM xc_transfers WHERE 1 = 1`
+	args := []interface{}{}
+	if opts.State != "" {
+		query += ` AND state = ?`
+		args = append(args, opts.State)
+	}
+	if opts.Pipeline != "" {
+		query += ` AND pipeline = ?`
+		args = append(args, opts.Pipeline)
+	}
+	query += ` ORDER BY created_at, id`
+	if opts.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, opts.Limit)
+	}
+	rows, err := s.DB.QueryContext(ctx, s.Dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	transfers := []*Transfer{}
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, rows.Err()
+}
+
+// Transition moves a transfer to a new state in a db transaction
+func (s *SQLStorage) Transition(ctx context.Context, id string, to TransferState, note string) error {
+	dbTx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	var from TransferState
+	var seq int
+	var transferMetadata string
+	query := s.Dialect.rebind(`SELECT state, metadata FROM xc_transfers WHERE id = ?` + s.Dialect.forUpdate)
+	err = dbTx.QueryRowContext(ctx, query, id).Scan(&from, &transferMetadata)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	metadata, err := unmarshalMetadata(transferMetadata)
+	if err != nil {
+		return fmt.Errorf("invalid metadata of transfer %s: %v", id, err)
+	}
+	recorded, err := marshalMetadata(transitionMetadata(ctx, metadata))
+	if err != nil {
+		return err
+	}
+	if err := checkTransition(id, from, to); err != nil {
+		return err
+	}
+
+	at := now()
+	// guard on the previous state in case the row wasn't locked
+	query = s.Dialect.rebind(`UPDATE xc_transfers SET state = ?, updated_at = ? WHERE id = ? AND state = ?`)
+	res, err := dbTx.ExecContext(ctx, query, to, at, id, from)
+	if err != nil {
+		return err
+	}
+	if updated, err := res.RowsAffected(); err == nil && updated == 0 {
+		return fmt.Errorf("%w: %s was updated concurrently", ErrInvalidTransition, id)
+	}
+
+	query = s.Dialect.rebind(`SELECT COUNT(*) FROM xc_transfer_transitions WHERE transfer_id = ?`)
+	if err := dbTx.QueryRowContext(ctx, query, id).Scan(&seq); err != nil {
+		return err
+	}
+	query = s.Dialect.rebind(`INSERT INTO xc_transfer_transitions (transfer_id, seq, from_state, to_state, note, at, metadata) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if _, err := dbTx.ExecContext(ctx, query, id, seq+1, from, to, xc.DefaultRedactor.Redact(note), at, recorded); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
+
+// Transitions returns the state transitions of a transfer, in order
+func (s *SQLStorage) Transitions(ctx context.Context, id string) ([]StateTransition, error) {
+	if _, err := s.Load(ctx, id); err != nil {
+		return nil, err
+	}
+	query := s.Dialect.rebind(`SELECT transfer_id, from_state, to_state, note, at, metadata FROM xc_transfer_transitions WHERE transfer_id = ? ORDER BY seq`)
+	rows, err := s.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	transitions := []StateTransition{}
+	for rows.Next() {
+		transition := StateTransition{}
+		var metadata string
+		err := rows.Scan(&transition.TransferID, &transition.From, &transition.To, &transition.Note, &transition.At, &metadata)
+		if err != nil {
+			return nil, err
+		}
+		if transition.Metadata, err = unmarshalMetadata(metadata); err != nil {
+			return nil, fmt.Errorf("invalid metadata of transition of transfer %s: %v", id, err)
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions, rows.Err()
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// TransferState is the state of a Transfer in the pipeline
+type TransferState string
+
+// List of TransferState
+const (
+	TransferStateCreated   = TransferState("created")
+	TransferStateBuilt     = TransferState("built")
+	TransferStateSigned    = TransferState("signed")
+	TransferStateSubmitted = TransferState("submitted")
+	TransferStateConfirmed = TransferState("confirmed")
+	TransferStateFailed    = TransferState("failed")
+)
+
+// validTransitions lists the states a Transfer can move to from each state
+// A submitted transfer can be built again, e.g. to bump its fee
+var validTransitions = map[TransferState][]TransferState{
+	TransferStateCreated:   {TransferStateBuilt, TransferStateFailed},
+	TransferStateBuilt:     {TransferStateSigned, TransferStateFailed},
+	TransferStateSigned:    {TransferStateSubmitted, TransferStateFailed},
+	TransferStateSubmitted: {TransferStateConfirmed, TransferStateBuilt, TransferStateFailed},
+}
+
+// IsFinal returns true if no transition is possible from state
+func (state TransferState) IsFinal() bool {
+	return len(validTransitions[state]) == 0
+}
+
+// CanTransition returns true if a Transfer can move from state to next
+func (state TransferState) CanTransition(next TransferState) bool {
+	for _, valid := range validTransitions[state] {
+		if valid == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returned by Storage
+var (
+	ErrNotFound          = errors.New("transfer not found")
+	ErrInvalidTransition = errors.New("invalid state transition")
+)
+
+// Transfer is a transfer processed by the pipeline
+type Transfer struct {
+	ID        string              `json:"id"`
+	Pipeline  string              `json:"pipeline"`
+	SrcAsset  xc.AssetID          `json:"src_asset"`
+	DstAsset  xc.AssetID          `json:"dst_asset"`
+	From      xc.Address          `json:"from"`
+	To        xc.Address          `json:"to"`
+	Amount    xc.AmountBlockchain `json:"amount"`
+	State     TransferState       `json:"state"`
+	TxHash    xc.TxHash           `json:"tx_hash"`
+	Error     string              `json:"error"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+	// Metadata of the caller, e.g. an order id, never sent on chain
+	Metadata xc.Metadata `json:"metadata,omitempty"`
+}
+
+// Context returns a context carrying the metadata of the transfer and its id,
+// so that logs and calls made while processing the transfer are tagged
+func (transfer *Transfer) Context(ctx context.Context) context.Context {
+	return xc.WithMetadata(ctx, transfer.Metadata.Merge(xc.Metadata{MetadataTransferID: transfer.ID}))
+}
+
+// DiagnoseRequest returns the request to diagnose the tx of a transfer
+// The submission time is known while the transfer is in the submitted state
+func (transfer *Transfer) DiagnoseRequest() xc.DiagnoseRequest {
+	request := xc.DiagnoseRequest{
+		TxHash: transfer.TxHash,
+		From:   transfer.From,
+		To:     transfer.To,
+	}
+	if transfer.State == TransferStateSubmitted {
+		request.SubmittedAt = transfer.UpdatedAt
+	}
+	return request
+}
+
+// MetadataTransferID is the metadata key of the transfer id set by Transfer.Context
+const MetadataTransferID = "transfer_id"
+
+// StateTransition is a change of state of a Transfer
+type StateTransition struct {
+	TransferID string        `json:"transfer_id"`
+	From       TransferState `json:"from"`
+	To         TransferState `json:"to"`
+	Note       string        `json:"note"`
+	At         time.Time     `json:"at"`
+	// Metadata of the transfer when it transitioned, with the metadata of the context of the transition
+	Metadata xc.Metadata `json:"metadata,omitempty"`
+}
+
+// ListOptions filters transfers returned by List, zero values match all
+type ListOptions struct {
+	State    TransferState
+	Pipeline string
+	// Limit is the max number of transfers, 0 for no limit
+	Limit int
+}
+
+// Storage persists transfers and their state transitions
+type Storage interface {
+	// Save inserts or updates a transfer, without changing its state if it exists
+	Save(ctx context.Context, transfer *Transfer) error
+	Load(ctx context.Context, id string) (*Transfer, error)
+	// List returns transfers ordered by creation time
+	List(ctx context.Context, opts ListOptions) ([]*Transfer, error)
+	// Transition atomically moves a transfer to a new state and records the transition
+	Transition(ctx context.Context, id string, to TransferState, note string) error
+	Transitions(ctx context.Context, id string) ([]StateTransition, error)
+}
+
+// transitionMetadata returns the metadata recorded with a transition of transfer: its metadata merged with the
+// metadata of ctx, e.g. set by Transfer.Context, without the transfer id
+func transitionMetadata(ctx context.Context, metadata xc.Metadata) xc.Metadata {
+	merged := metadata.Merge(xc.MetadataFromContext(ctx))
+	delete(merged, MetadataTransferID)
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+func checkTransition(id string, from TransferState, to TransferState) error {
+	if !from.CanTransition(to) {
+		return fmt.Errorf("%w: %s from %s to %s", ErrInvalidTransition, id, from, to)
+	}
+	return nil
+}
+
+var now = func() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestStorageTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func (s *CrosschainTestSuite) TestTransferStateTransitions() {
+	require := s.Require()
+	require.True(TransferStateCreated.CanTransition(TransferStateBuilt))
+	require.True(TransferStateSubmitted.CanTransition(TransferStateBuilt))
+	require.True(TransferStateSigned.CanTransition(TransferStateFailed))
+	require.False(TransferStateCreated.CanTransition(TransferStateSubmitted))
+	require.False(TransferStateConfirmed.CanTransition(TransferStateFailed))
+
+	require.True(TransferStateConfirmed.IsFinal())
+	require.True(TransferStateFailed.IsFinal())
+	require.False(TransferStateSubmitted.IsFinal())
+
+	err := checkTransition("id", TransferStateCreated, TransferStateConfirmed)
+	require.ErrorIs(err, ErrInvalidTransition)
+	require.EqualError(err, "invalid state transition: id from created to confirmed")
+}
+
+func (s *CrosschainTestSuite) TestTransferContext() {
+	require := s.Require()
+	transfer := &Transfer{ID: "t1", Metadata: xc.Metadata{"order_id": "42"}}
+	ctx := transfer.Context(s.Ctx)
+	require.Equal(xc.Metadata{"order_id": "42", MetadataTransferID: "t1"}, xc.MetadataFromContext(ctx))
+	require.Equal(xc.Metadata{"order_id": "42"}, transfer.Metadata)
+}
+
+func (s *CrosschainTestSuite) TestTransferDiagnoseRequest() {
+	require := s.Require()
+	updatedAt := time.Date(2023, 5, 3, 14, 0, 0, 0, time.UTC)
+	transfer := &Transfer{ID: "t1", From: "from", To: "to", TxHash: "0xabc", State: TransferStateSubmitted, UpdatedAt: updatedAt}
+	require.Equal(xc.DiagnoseRequest{TxHash: "0xabc", From: "from", To: "to", SubmittedAt: updatedAt}, transfer.DiagnoseRequest())
+
+	transfer.State = TransferStateConfirmed
+	require.True(transfer.DiagnoseRequest().SubmittedAt.IsZero())
+}
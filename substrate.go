@@ -0,0 +1,118 @@
+package crosschain
+
+// SubstrateConfig is the config of a Substrate chain, e.g. a parachain: known chains (by native asset) complete
+// it, see GetSubstrate
+type SubstrateConfig struct {
+	// SS58Prefix is the network prefix of the addresses of the chain, the generic Substrate prefix (42) if not set
+	SS58Prefix *uint16 `yaml:"ss58_prefix"`
+	// TransferCallIndex is the hex pallet and call index of Balances.transfer_keep_alive, e.g. 0x0503 on Polkadot
+	TransferCallIndex string `yaml:"transfer_call_index"`
+	// CheckMetadataHash is set if the runtime of the chain includes the CheckMetadataHash signed extension
+	CheckMetadataHash *bool `yaml:"check_metadata_hash"`
+	// KeyType is the signature scheme of the keys of the chain: sr25519 (default) or ed255
+	KeyType SignatureType `yaml:"key_type"`
+	// XCMPalletIndex is the hex pallet index of XcmPallet on relay chains, e.g. 0x63 on Polkadot, see TxXCMBuilder
+	XCMPalletIndex string `yaml:"xcm_pallet_index"`
+	// XCM are the parachains receiving XCM transfers, by native asset, completed with the known ones
+	XCM map[NativeAsset]XCMDestination `yaml:"xcm"`
+}
+
+// GenericSS58Prefix is the SS58 prefix of the Substrate chains without a registered prefix
+const GenericSS58Prefix = 42
+
+// KnownSubstrateChains are the templates of Substrate chains by native asset
+var KnownSubstrateChains = map[NativeAsset]SubstrateConfig{
+	DOT: {
+		SS58Prefix:        newUint16(0),
+		TransferCallIndex: "0x0503",
+		CheckMetadataHash: newBool(true),
+		XCMPalletIndex:    "0x63",
+		XCM: map[NativeAsset]XCMDestination{
+			ACA: {ParaID: 2000, Type: XCMReserve, SS58Prefix: newUint16(10), CurrencyID: "0x0002"},
+		},
+	},
+	KSM: {
+		SS58Prefix:        newUint16(2),
+		TransferCallIndex: "0x0403",
+		CheckMetadataHash: newBool(true),
+		XCMPalletIndex:    "0x63",
+		XCM: map[NativeAsset]XCMDestination{
+			KAR: {ParaID: 2000, Type: XCMReserve, SS58Prefix: newUint16(8), CurrencyID: "0x0082"},
+		},
+	},
+}
+
+// GetSubstrate returns the Substrate config of a chain, completed with the template of its native asset
+func (asset *NativeAssetConfig) GetSubstrate() SubstrateConfig {
+	substrate := asset.Substrate
+	known := KnownSubstrateChains[asset.NativeAsset]
+	if substrate.SS58Prefix == nil {
+		substrate.SS58Prefix = known.SS58Prefix
+	}
+	if substrate.SS58Prefix == nil {
+		substrate.SS58Prefix = newUint16(GenericSS58Prefix)
+	}
+	if substrate.TransferCallIndex == "" {
+		substrate.TransferCallIndex = known.TransferCallIndex
+	}
+	if substrate.CheckMetadataHash == nil {
+		substrate.CheckMetadataHash = known.CheckMetadataHash
+	}
+	if substrate.CheckMetadataHash == nil {
+		substrate.CheckMetadataHash = newBool(false)
+	}
+	if substrate.KeyType == "" {
+		substrate.KeyType = Sr25519
+	}
+	if substrate.XCMPalletIndex == "" {
+		substrate.XCMPalletIndex = known.XCMPalletIndex
+	}
+	substrate.XCM = mergeXCMDestinations(substrate.XCM, known.XCM)
+	return substrate
+}
+
+// mergeXCMDestinations completes the configured destinations with the known ones, e.g. with the url of a known
+// parachain
+func mergeXCMDestinations(configured map[NativeAsset]XCMDestination, known map[NativeAsset]XCMDestination) map[NativeAsset]XCMDestination {
+	merged := map[NativeAsset]XCMDestination{}
+	for asset, destination := range known {
+		merged[asset] = destination
+	}
+	for asset, destination := range configured {
+		template := known[asset]
+		if destination.ParaID == 0 {
+			destination.ParaID = template.ParaID
+		}
+		if destination.Type == "" {
+			destination.Type = template.Type
+		}
+		if destination.SS58Prefix == nil {
+			destination.SS58Prefix = template.SS58Prefix
+		}
+		if destination.URL == "" {
+			destination.URL = template.URL
+		}
+		if destination.CurrencyID == "" {
+			destination.CurrencyID = template.CurrencyID
+		}
+		merged[asset] = destination
+	}
+	for asset, destination := range merged {
+		if destination.Type == "" {
+			destination.Type = XCMReserve
+		}
+		if destination.SS58Prefix == nil {
+			destination.SS58Prefix = newUint16(GenericSS58Prefix)
+		}
+		merged[asset] = destination
+	}
+	return merged
+}
+
+func newUint16(value uint16) *uint16 {
+	return &value
+}
+
+func newBool(value bool) *bool {
+	return &value
+}
@@ -0,0 +1,46 @@
+package crosschain
+
+func (s *CrosschainTestSuite) TestGetSubstrate() {
+	require := s.Require()
+
+	// known chains
+	asset := &NativeAssetConfig{NativeAsset: KSM}
+	substrate := asset.GetSubstrate()
+	require.EqualValues(2, *substrate.SS58Prefix)
+	require.Equal("0x0403", substrate.TransferCallIndex)
+	require.True(*substrate.CheckMetadataHash)
+	require.Equal(Sr25519, substrate.KeyType)
+
+	// the config overrides the template, e.g. Polkadot on a testnet
+	prefix := uint16(42)
+	asset = &NativeAssetConfig{NativeAsset: DOT, Substrate: SubstrateConfig{SS58Prefix: &prefix, TransferCallIndex: "0x0403"}}
+	substrate = asset.GetSubstrate()
+	require.EqualValues(42, *substrate.SS58Prefix)
+	require.Equal("0x0403", substrate.TransferCallIndex)
+	require.True(*substrate.CheckMetadataHash)
+
+	// other chains need their call index
+	asset = &NativeAssetConfig{NativeAsset: "PARA"}
+	substrate = asset.GetSubstrate()
+	require.EqualValues(GenericSS58Prefix, *substrate.SS58Prefix)
+	require.Equal("", substrate.TransferCallIndex)
+	require.False(*substrate.CheckMetadataHash)
+
+	// xcm destinations complete the known ones
+	asset = &NativeAssetConfig{NativeAsset: DOT, Substrate: SubstrateConfig{XCM: map[NativeAsset]XCMDestination{
+		ACA:     {URL: "https://acala.example"},
+		"DOTAH": {ParaID: 1000, Type: XCMTeleport},
+	}}}
+	substrate = asset.GetSubstrate()
+	require.Equal("0x63", substrate.XCMPalletIndex)
+	require.Len(substrate.XCM, 2)
+	require.Equal(XCMDestination{ParaID: 2000, Type: XCMReserve, SS58Prefix: newUint16(10), URL: "https://acala.example", CurrencyID: "0x0002"}, substrate.XCM[ACA])
+	require.EqualValues(XCMTeleport, substrate.XCM["DOTAH"].Type)
+	require.EqualValues(GenericSS58Prefix, *substrate.XCM["DOTAH"].SS58Prefix)
+	require.EqualValues(2000, (&NativeAssetConfig{NativeAsset: KSM}).GetSubstrate().XCM[KAR].ParaID)
+
+	require.Equal(DriverSubstrate, DOT.Driver())
+	require.Equal(Sr25519, DOT.SignatureAlgorithm())
+	require.EqualValues(10, DOT.Decimals())
+	require.Equal("m/44'/354'/0'/0'/0'", DOT.DerivationPath())
+}
@@ -0,0 +1,78 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitedClient is a Client waiting on a rate limiter before each request
+// Errors of the client are redacted, as provider errors may include URLs with API keys
+type RateLimitedClient struct {
+	Client  xc.Client
+	Limiter *rate.Limiter
+}
+
+var _ xc.ClientBalance = &RateLimitedClient{}
+
+// FetchTxInput fetches tx input, rate limited
+func (client *RateLimitedClient) FetchTxInput(ctx context.Context, from xc.Address, to xc.Address) (xc.TxInput, error) {
+	if err := client.Limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	input, err := client.Client.FetchTxInput(ctx, from, to)
+	return input, xc.DefaultRedactor.RedactError(err)
+}
+
+// FetchTxInfo fetches tx info, rate limited
+func (client *RateLimitedClient) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
+	if err := client.Limiter.Wait(ctx); err != nil {
+		return xc.TxInfo{}, err
+	}
+	info, err := client.Client.FetchTxInfo(ctx, txHash)
+	return info, xc.DefaultRedactor.RedactError(err)
+}
+
+// SubmitTx submits a tx, rate limited
+func (client *RateLimitedClient) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := client.Limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return xc.DefaultRedactor.RedactError(client.Client.SubmitTx(ctx, tx))
+}
+
+func (client *RateLimitedClient) balanceClient() (xc.ClientBalance, error) {
+	balanceClient, ok := client.Client.(xc.ClientBalance)
+	if !ok {
+		return nil, fmt.Errorf("balances are not supported by %T", client.Client)
+	}
+	return balanceClient, nil
+}
+
+// FetchBalance fetches balance, rate limited
+func (client *RateLimitedClient) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	balanceClient, err := client.balanceClient()
+	if err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	if err := client.Limiter.Wait(ctx); err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	balance, err := balanceClient.FetchBalance(ctx, address)
+	return balance, xc.DefaultRedactor.RedactError(err)
+}
+
+// FetchNativeBalance fetches native asset balance, rate limited
+func (client *RateLimitedClient) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	balanceClient, err := client.balanceClient()
+	if err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	if err := client.Limiter.Wait(ctx); err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	balance, err := balanceClient.FetchNativeBalance(ctx, address)
+	return balance, xc.DefaultRedactor.RedactError(err)
+}
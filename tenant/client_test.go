@@ -0,0 +1,49 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/time/rate"
+)
+
+func (s *CrosschainTestSuite) TestRateLimitedClient() {
+	require := s.Require()
+	mocked := &testutil.MockedClient{}
+	mocked.On("FetchBalance", mock.Anything, xc.Address("address")).Return(xc.NewAmountBlockchainFromUint64(10), nil)
+	client := &RateLimitedClient{
+		Client:  mocked,
+		Limiter: rate.NewLimiter(rate.Every(time.Hour), 1),
+	}
+
+	balance, err := client.FetchBalance(s.Ctx, "address")
+	require.NoError(err)
+	require.EqualValues(10, balance.Uint64())
+
+	// the burst is consumed, the next request can't be served before the deadline
+	ctx, cancel := context.WithTimeout(s.Ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = client.FetchBalance(ctx, "address")
+	require.Error(err)
+	mocked.AssertNumberOfCalls(s.T(), "FetchBalance", 1)
+}
+
+func (s *CrosschainTestSuite) TestRateLimitedClientRedaction() {
+	require := s.Require()
+	xc.DefaultRedactor.AddSecrets("tenant-api-key")
+	mocked := &testutil.MockedClient{}
+	cause := errors.New(`Post "https://eth.provider.io/tenant-api-key": timeout`)
+	mocked.On("FetchTxInfo", mock.Anything, xc.TxHash("0xhash")).Return(xc.TxInfo{}, cause)
+	client := &RateLimitedClient{
+		Client:  mocked,
+		Limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+
+	_, err := client.FetchTxInfo(s.Ctx, "0xhash")
+	require.ErrorIs(err, cause)
+	require.Equal(`Post "https://eth.provider.io/***": timeout`, err.Error())
+}
@@ -0,0 +1,84 @@
+package tenant
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Registry holds the tenants of a deployment
+type Registry struct {
+	mu      sync.RWMutex
+	tenants map[string]*Tenant
+}
+
+// NewRegistry creates a new empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		tenants: map[string]*Tenant{},
+	}
+}
+
+// NewRegistryFromConfig creates a new Registry given a config map with a tenants section
+func NewRegistryFromConfig(cfg map[string]interface{}) (*Registry, error) {
+	yamlStr, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var mainConfig struct {
+		Tenants []Config `yaml:"tenants"`
+	}
+	err = yaml.Unmarshal(yamlStr, &mainConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := NewRegistry()
+	for _, tenantCfg := range mainConfig.Tenants {
+		tenant, err := NewTenant(tenantCfg)
+		if err != nil {
+			return nil, err
+		}
+		err = registry.Add(tenant)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// Add adds a tenant, failing if a tenant with the same id exists
+func (r *Registry) Add(tenant *Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tenants[tenant.ID]; ok {
+		return fmt.Errorf("duplicate tenant: '%s'", tenant.ID)
+	}
+	r.tenants[tenant.ID] = tenant
+	return nil
+}
+
+// Get returns a tenant given its id
+func (r *Registry) Get(id string) (*Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tenant, ok := r.tenants[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown tenant: '%s'", id)
+	}
+	return tenant, nil
+}
+
+// IDs returns the ids of all tenants, sorted
+func (r *Registry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := []string{}
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,42 @@
+package tenant
+
+func (s *CrosschainTestSuite) TestRegistry() {
+	require := s.Require()
+	registry, err := NewRegistryFromConfig(map[string]interface{}{
+		"tenants": []interface{}{
+			map[string]interface{}{
+				"id":         "beta",
+				"rate_limit": 10,
+				"crosschain": map[string]interface{}{
+					"chains": []interface{}{
+						map[string]interface{}{"asset": "SOL", "driver": "solana"},
+					},
+				},
+			},
+			map[string]interface{}{"id": "alpha"},
+		},
+	})
+	require.NoError(err)
+	require.Equal([]string{"alpha", "beta"}, registry.IDs())
+
+	beta, err := registry.Get("beta")
+	require.NoError(err)
+	require.Equal(10.0, beta.RateLimit)
+	_, err = beta.GetAssetConfig("SOL", "")
+	require.NoError(err)
+
+	// tenants don't see each other's assets
+	alpha, _ := registry.Get("alpha")
+	_, err = alpha.GetAssetConfig("SOL", "")
+	require.Error(err)
+
+	_, err = registry.Get("gamma")
+	require.EqualError(err, "unknown tenant: 'gamma'")
+	err = registry.Add(alpha)
+	require.EqualError(err, "duplicate tenant: 'alpha'")
+
+	_, err = NewRegistryFromConfig(map[string]interface{}{
+		"tenants": []interface{}{map[string]interface{}{"id": ""}},
+	})
+	require.Error(err)
+}
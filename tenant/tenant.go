@@ -0,0 +1,205 @@
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/config"
+	"github.com/jumpcrypto/crosschain/factory"
+	"golang.org/x/time/rate"
+)
+
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Config is the config of a Tenant
+//
+//	tenants:
+//	  - id: acme
+//	    rate_limit: 10
+//	    key_dir: /keys/acme
+//	    crosschain:
+//	      chains:
+//	        - asset: ETH
+//	          auth: env:RPC_KEY # resolved as env:TENANT_ACME_RPC_KEY
+type Config struct {
+	ID string `yaml:"id"`
+	// Crosschain is the crosschain config of the tenant: chains, tokens, tasks and pipelines
+	Crosschain map[string]interface{} `yaml:"crosschain"`
+	// RateLimit is the number of client requests per second, 0 for unlimited
+	RateLimit float64 `yaml:"rate_limit"`
+	RateBurst int     `yaml:"rate_burst"`
+	// KeyDir is the directory of file: secrets of the tenant
+	KeyDir string `yaml:"key_dir"`
+	// VaultPrefix is the path prefix of vault: secrets of the tenant
+	VaultPrefix string `yaml:"vault_prefix"`
+}
+
+// Tenant scopes config, clients, rate limits and secret references of a customer
+type Tenant struct {
+	Config
+	Factory *factory.Factory
+	limiter *rate.Limiter
+}
+
+// NewTenant creates a new Tenant given its config
+// Secret references of the tenant config (e.g. chains auth) are scoped to the tenant
+func NewTenant(cfg Config) (*Tenant, error) {
+	if !validID.MatchString(cfg.ID) {
+		return nil, fmt.Errorf("invalid tenant id: '%s'", cfg.ID)
+	}
+	tenant := &Tenant{
+		Config: cfg,
+	}
+	if cfg.RateLimit > 0 {
+		burst := cfg.RateBurst
+		if burst <= 0 {
+			burst = 1
+		}
+		tenant.limiter = rate.NewLimiter(rate.Limit(cfg.RateLimit), burst)
+	}
+
+	crosschainCfg, err := tenant.scopeConfig(cfg.Crosschain)
+	if err != nil {
+		return nil, err
+	}
+	tenant.Factory = factory.NewDefaultFactoryWithConfig(crosschainCfg)
+	return tenant, nil
+}
+
+// scopeConfig returns a copy of a crosschain config with the chains auth scoped to the tenant
+func (tenant *Tenant) scopeConfig(cfg map[string]interface{}) (map[string]interface{}, error) {
+	scoped := map[string]interface{}{}
+	for key, value := range cfg {
+		scoped[key] = value
+	}
+	chains, _ := cfg["chains"].([]interface{})
+	scopedChains := []interface{}{}
+	for _, chainI := range chains {
+		chain := map[string]interface{}{}
+		switch c := chainI.(type) {
+		case map[string]interface{}:
+			for key, value := range c {
+				chain[key] = value
+			}
+		case map[interface{}]interface{}:
+			for key, value := range c {
+				chain[fmt.Sprint(key)] = value
+			}
+		default:
+			return nil, fmt.Errorf("invalid chain config: %v", chainI)
+		}
+		if auth, ok := chain["auth"].(string); ok && auth != "" {
+			ref, err := tenant.SecretRef(auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth of chain '%v': %v", chain["asset"], err)
+			}
+			chain["auth"] = ref
+		}
+		scopedChains = append(scopedChains, chain)
+	}
+	if len(scopedChains) > 0 {
+		scoped["chains"] = scopedChains
+	}
+	return scoped, nil
+}
+
+// SecretRef namespaces a secret reference, as supported by config.GetSecret, to the tenant:
+//   - env:NAME is read from TENANT_<ID>_NAME
+//   - file:path is read relative to KeyDir
+//   - vault:url,path is read relative to VaultPrefix
+func (tenant *Tenant) SecretRef(ref string) (string, error) {
+	splits := strings.SplitN(ref, ":", 2)
+	if len(splits) != 2 || splits[1] == "" {
+		return "", errors.New("invalid secret reference")
+	}
+	value := splits[1]
+	switch splits[0] {
+	case "env":
+		namespace := strings.ToUpper(strings.ReplaceAll(tenant.ID, "-", "_"))
+		return "env:TENANT_" + namespace + "_" + value, nil
+	case "file":
+		if tenant.KeyDir == "" {
+			return "", errors.New("file secrets require a key_dir")
+		}
+		relative, err := relativePath(value)
+		if err != nil {
+			return "", err
+		}
+		return "file:" + path.Join(tenant.KeyDir, relative), nil
+	case "vault":
+		if tenant.VaultPrefix == "" {
+			return "", errors.New("vault secrets require a vault_prefix")
+		}
+		vaultArgs := strings.Split(value, ",")
+		if len(vaultArgs) != 2 {
+			return "", errors.New("vault secret has 2 comma separated arguments (url,path)")
+		}
+		relative, err := relativePath(vaultArgs[1])
+		if err != nil {
+			return "", err
+		}
+		return "vault:" + vaultArgs[0] + "," + path.Join(tenant.VaultPrefix, relative), nil
+	}
+	return "", errors.New("invalid secret reference")
+}
+
+// relativePath validates that p cannot escape the directory it is joined to
+func relativePath(p string) (string, error) {
+	if path.IsAbs(p) {
+		return "", errors.New("secret path must be relative")
+	}
+	cleaned := path.Clean(p)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.New("secret path must not leave the tenant directory")
+	}
+	return cleaned, nil
+}
+
+// GetSecret returns a secret of the tenant
+func (tenant *Tenant) GetSecret(ref string) (string, error) {
+	scoped, err := tenant.SecretRef(ref)
+	if err != nil {
+		return "", err
+	}
+	return config.GetSecret(scoped)
+}
+
+// ImportPrivateKey imports a private key of the tenant given its secret reference
+func (tenant *Tenant) ImportPrivateKey(asset xc.ITask, ref string) (xc.PrivateKey, error) {
+	secret, err := tenant.GetSecret(ref)
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("empty private key for tenant '%s'", tenant.ID)
+	}
+	signer, err := tenant.Factory.NewSigner(asset)
+	if err != nil {
+		return nil, err
+	}
+	return signer.ImportPrivateKey(secret)
+}
+
+// GetAssetConfig returns the config of an asset of the tenant
+func (tenant *Tenant) GetAssetConfig(asset string, nativeAsset string) (xc.ITask, error) {
+	return tenant.Factory.GetAssetConfig(asset, nativeAsset)
+}
+
+// NewClient creates a new Client, rate limited if the tenant has a rate limit
+func (tenant *Tenant) NewClient(asset xc.ITask) (xc.Client, error) {
+	client, err := tenant.Factory.NewClient(asset)
+	if err != nil {
+		return nil, err
+	}
+	if tenant.limiter == nil {
+		return client, nil
+	}
+	return &RateLimitedClient{
+		Client:  client,
+		Limiter: tenant.limiter,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package tenant
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestTenantTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func newTestConfig(id string, rpcURL string) Config {
+	return Config{
+		ID:     id,
+		KeyDir: "/keys/" + id,
+		Crosschain: map[string]interface{}{
+			"chains": []interface{}{
+				map[string]interface{}{"asset": "ETH", "driver": "evm", "url": rpcURL, "auth": "env:RPC_KEY"},
+			},
+		},
+	}
+}
+
+func (s *CrosschainTestSuite) TestNewTenant() {
+	require := s.Require()
+	os.Setenv("TENANT_ACME_CORP_RPC_KEY", "acme-secret")
+	os.Setenv("RPC_KEY", "global-secret")
+	defer os.Unsetenv("TENANT_ACME_CORP_RPC_KEY")
+	defer os.Unsetenv("RPC_KEY")
+
+	tenant, err := NewTenant(newTestConfig("acme-corp", "https://acme"))
+	require.NoError(err)
+	asset, err := tenant.GetAssetConfig("ETH", "")
+	require.NoError(err)
+	require.Equal("https://acme", asset.GetNativeAsset().URL)
+	require.Equal("env:TENANT_ACME_CORP_RPC_KEY", asset.GetNativeAsset().Auth)
+	require.Equal("acme-secret", asset.GetNativeAsset().AuthSecret)
+
+	_, err = tenant.GetAssetConfig("SOL", "")
+	require.Error(err)
+
+	_, err = NewTenant(Config{ID: "Bad Id"})
+	require.EqualError(err, "invalid tenant id: 'Bad Id'")
+	cfg := newTestConfig("acme", "")
+	cfg.Crosschain["chains"].([]interface{})[0].(map[string]interface{})["auth"] = "file:/etc/passwd"
+	_, err = NewTenant(cfg)
+	require.ErrorContains(err, "secret path must be relative")
+}
+
+func (s *CrosschainTestSuite) TestSecretRef() {
+	require := s.Require()
+	tenant := &Tenant{Config: Config{ID: "acme", KeyDir: "/keys/acme", VaultPrefix: "secret/data/acme"}}
+	vectors := map[string]string{
+		"env:PRIVATE_KEY":                  "env:TENANT_ACME_PRIVATE_KEY",
+		"file:eth/key.txt":                 "file:/keys/acme/eth/key.txt",
+		"file:./eth/../key.txt":            "file:/keys/acme/key.txt",
+		"vault:https://vault,eth/key":      "vault:https://vault,secret/data/acme/eth/key",
+		"vault:https://vault:8200,eth/key": "vault:https://vault:8200,secret/data/acme/eth/key",
+	}
+	for ref, expected := range vectors {
+		scoped, err := tenant.SecretRef(ref)
+		require.NoError(err, ref)
+		require.Equal(expected, scoped)
+	}
+
+	errors := map[string]string{
+		"file:../other/key.txt":       "secret path must not leave the tenant directory",
+		"file:/keys/other/key.txt":    "secret path must be relative",
+		"vault:https://vault,../eth":  "secret path must not leave the tenant directory",
+		"vault:https://vault":         "vault secret has 2 comma separated arguments (url,path)",
+		"env:":                        "invalid secret reference",
+		"plain":                       "invalid secret reference",
+		"http://example.com/password": "invalid secret reference",
+	}
+	for ref, expected := range errors {
+		_, err := tenant.SecretRef(ref)
+		require.EqualError(err, expected, ref)
+	}
+
+	_, err := (&Tenant{Config: Config{ID: "acme"}}).SecretRef("file:key.txt")
+	require.EqualError(err, "file secrets require a key_dir")
+}
+
+func (s *CrosschainTestSuite) TestImportPrivateKey() {
+	require := s.Require()
+	os.Setenv("TENANT_ACME_ETH_KEY", "0000000000000000000000000000000000000000000000000000000000000001")
+	defer os.Unsetenv("TENANT_ACME_ETH_KEY")
+
+	tenant, _ := NewTenant(newTestConfig("acme", ""))
+	asset, _ := tenant.GetAssetConfig("ETH", "")
+	key, err := tenant.ImportPrivateKey(asset, "env:ETH_KEY")
+	require.NoError(err)
+	require.Len(key, 32)
+
+	_, err = tenant.ImportPrivateKey(asset, "env:MISSING")
+	require.EqualError(err, "empty private key for tenant 'acme'")
+}
+
+func (s *CrosschainTestSuite) TestNewClient() {
+	require := s.Require()
+	tenant, _ := NewTenant(newTestConfig("acme", "https://acme"))
+	asset, _ := tenant.GetAssetConfig("ETH", "")
+	client, err := tenant.NewClient(asset)
+	require.NoError(err)
+	require.NotNil(client)
+	_, isLimited := client.(*RateLimitedClient)
+	require.False(isLimited)
+
+	cfg := newTestConfig("limited", "https://limited")
+	cfg.RateLimit = 5
+	tenant, _ = NewTenant(cfg)
+	asset, _ = tenant.GetAssetConfig("ETH", "")
+	client, err = tenant.NewClient(asset)
+	require.NoError(err)
+	require.IsType(&RateLimitedClient{}, client)
+	require.Equal(1, client.(*RateLimitedClient).Limiter.Burst())
+	var _ xc.ClientBalance = client.(*RateLimitedClient)
+}
@@ -0,0 +1,319 @@
+package test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// RecordEnv is the environment variable enabling the recording of fixtures by ReplayServer
+const RecordEnv = "XC_RECORD_FIXTURES"
+
+// Interaction is an RPC request and its response
+// Bodies that aren't JSON, e.g. plain text or binary, are stored as base64 strings with their flag set
+type Interaction struct {
+	Method         string          `json:"method"`
+	Path           string          `json:"path"`
+	Request        json.RawMessage `json:"request,omitempty"`
+	RequestBase64  bool            `json:"request_base64,omitempty"`
+	Status         int             `json:"status"`
+	Response       json.RawMessage `json:"response"`
+	ResponseBase64 bool            `json:"response_base64,omitempty"`
+	// ContentType of responses that aren't JSON
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// RecordReplayTransport is a http.RoundTripper recording RPC interactions into a fixture file,
+// or replaying them from the fixture without network access
+// JSON-RPC ids are ignored when matching requests, and replaced in replayed responses
+type RecordReplayTransport struct {
+	// Transport to the real RPC when recording
+	Transport http.RoundTripper
+	// URL of the real RPC, prefixed to the path of recorded requests if set
+	URL       string
+	Recording bool
+	Fixture   string
+
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+var _ http.RoundTripper = &RecordReplayTransport{}
+
+// NewRecordingTransport creates a RecordReplayTransport recording interactions, saved into fixture by Save
+func NewRecordingTransport(fixture string, rpcURL string, transport http.RoundTripper) *RecordReplayTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &RecordReplayTransport{
+		Transport: transport,
+		URL:       rpcURL,
+		Recording: true,
+		Fixture:   fixture,
+	}
+}
+
+// NewReplayTransport creates a RecordReplayTransport replaying the interactions of fixture
+func NewReplayTransport(fixture string) (*RecordReplayTransport, error) {
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		return nil, err
+	}
+	interactions := []Interaction{}
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %v", fixture, err)
+	}
+	return &RecordReplayTransport{
+		Fixture:      fixture,
+		interactions: interactions,
+		replayed:     make([]bool, len(interactions)),
+	}, nil
+}
+
+// RoundTrip records or replays a request
+func (t *RecordReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	if t.Recording {
+		return t.record(req, body)
+	}
+	return t.replay(req, body)
+}
+
+func (t *RecordReplayTransport) record(req *http.Request, body []byte) (*http.Response, error) {
+	forwarded := req.Clone(req.Context())
+	forwarded.Body = io.NopCloser(bytes.NewReader(body))
+	forwarded.RequestURI = ""
+	if t.URL != "" {
+		target, err := url.Parse(strings.TrimSuffix(t.URL, "/") + req.URL.Path)
+		if err != nil {
+			return nil, err
+		}
+		target.RawQuery = req.URL.RawQuery
+		forwarded.URL = target
+		forwarded.Host = target.Host
+	}
+	res, err := t.Transport.RoundTrip(forwarded)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	response, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	interaction := Interaction{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Status: res.StatusCode,
+	}
+	interaction.Request, interaction.RequestBase64 = encodeBody(body)
+	interaction.Response, interaction.ResponseBase64 = encodeBody(response)
+	if interaction.ResponseBase64 {
+		interaction.ContentType = res.Header.Get("Content-Type")
+	}
+	t.mu.Lock()
+	t.interactions = append(t.interactions, interaction)
+	t.mu.Unlock()
+	res.Body = io.NopCloser(bytes.NewReader(response))
+	return res, nil
+}
+
+func (t *RecordReplayTransport) replay(req *http.Request, body []byte) (*http.Response, error) {
+	request := withoutRPCID(body)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, interaction := range t.interactions {
+		if t.replayed[i] || interaction.Method != req.Method || interaction.Path != req.URL.Path {
+			continue
+		}
+		recorded, err := decodeBody(interaction.Request, interaction.RequestBase64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request of interaction %d in %s: %v", i, t.Fixture, err)
+		}
+		if !bytes.Equal(withoutRPCID(recorded), request) {
+			continue
+		}
+		response, err := decodeBody(interaction.Response, interaction.ResponseBase64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid response of interaction %d in %s: %v", i, t.Fixture, err)
+		}
+		contentType := "application/json"
+		if interaction.ResponseBase64 {
+			contentType = interaction.ContentType
+		} else {
+			response = withRPCID(response, body)
+		}
+		t.replayed[i] = true
+		return &http.Response{
+			Status:        http.StatusText(interaction.Status),
+			StatusCode:    interaction.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{contentType}},
+			Body:          io.NopCloser(bytes.NewReader(response)),
+			ContentLength: int64(len(response)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no interaction recorded in %s for %s %s %s", t.Fixture, req.Method, req.URL.Path, string(body))
+}
+
+// Save writes the recorded interactions into the fixture
+func (t *RecordReplayTransport) Save() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data, err := json.MarshalIndent(t.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.Fixture), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.Fixture, append(data, '\n'), 0644)
+}
+
+// Unreplayed returns the recorded interactions that were not replayed
+func (t *RecordReplayTransport) Unreplayed() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	interactions := []Interaction{}
+	for i, interaction := range t.interactions {
+		if !t.replayed[i] {
+			interactions = append(interactions, interaction)
+		}
+	}
+	return interactions
+}
+
+// encodeBody returns data as JSON, or as a base64 JSON string and true if it's not valid JSON
+func encodeBody(data []byte) (json.RawMessage, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+	if json.Valid(data) {
+		return json.RawMessage(data), false
+	}
+	encoded, _ := json.Marshal(base64.StdEncoding.EncodeToString(data))
+	return json.RawMessage(encoded), true
+}
+
+// decodeBody returns the body encoded by encodeBody
+func decodeBody(encoded json.RawMessage, base64Encoded bool) ([]byte, error) {
+	if !base64Encoded {
+		return encoded, nil
+	}
+	var data string
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(data)
+}
+
+// withoutRPCID returns a canonical JSON-RPC request (or batch), without its ids
+func withoutRPCID(data []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		delete(v, "id")
+	case []interface{}:
+		for _, item := range v {
+			if item, ok := item.(map[string]interface{}); ok {
+				delete(item, "id")
+			}
+		}
+	}
+	canonical, _ := json.Marshal(v)
+	return canonical
+}
+
+// withRPCID sets the ids of the request on a JSON-RPC response (or batch)
+func withRPCID(response []byte, request []byte) []byte {
+	var req, res interface{}
+	if json.Unmarshal(request, &req) != nil || json.Unmarshal(response, &res) != nil {
+		return response
+	}
+	switch res := res.(type) {
+	case map[string]interface{}:
+		if req, ok := req.(map[string]interface{}); ok {
+			if _, ok := res["id"]; ok {
+				res["id"] = req["id"]
+			}
+		}
+	case []interface{}:
+		// batch responses are in the order of requests
+		if req, ok := req.([]interface{}); ok && len(req) == len(res) {
+			for i := range res {
+				resItem, ok1 := res[i].(map[string]interface{})
+				reqItem, ok2 := req[i].(map[string]interface{})
+				if ok1 && ok2 {
+					resItem["id"] = reqItem["id"]
+				}
+			}
+		}
+	default:
+		return response
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return response
+	}
+	return data
+}
+
+// ReplayServer creates a server replaying the RPC interactions of fixture, for deterministic and offline tests
+// When XC_RECORD_FIXTURES is set, requests are forwarded to rpcURL and the fixture is (re)recorded on close
+func ReplayServer(s *suite.Suite, fixture string, rpcURL string) (server *httptest.Server, close func()) {
+	require := s.Require()
+	var transport *RecordReplayTransport
+	if os.Getenv(RecordEnv) != "" {
+		transport = NewRecordingTransport(fixture, rpcURL, nil)
+	} else {
+		var err error
+		transport, err = NewReplayTransport(fixture)
+		require.NoError(err)
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		res, err := transport.RoundTrip(req)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer res.Body.Close()
+		for key, values := range res.Header {
+			for _, value := range values {
+				rw.Header().Add(key, value)
+			}
+		}
+		rw.WriteHeader(res.StatusCode)
+		io.Copy(rw, res.Body)
+	}))
+	return server, func() {
+		server.Close()
+		if transport.Recording {
+			require.NoError(transport.Save())
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func post(client *http.Client, url string, body string) (int, string, error) {
+	res, err := client.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	return res.StatusCode, string(data), err
+}
+
+func (s *CrosschainTestSuite) TestRecordReplay() {
+	require := s.Require()
+	upstream, closeUpstream := MockJSONRPC(&s.Suite, []string{`"0x1"`, `"0x2"`})
+	defer closeUpstream()
+	fixture := filepath.Join(s.T().TempDir(), "testdata", "fixture.json")
+
+	recorder := NewRecordingTransport(fixture, upstream.URL, nil)
+	client := &http.Client{Transport: recorder}
+	status, body, err := post(client, "http://rpc.invalid/", `{"jsonrpc":"2.0","id":0,"method":"eth_blockNumber","params":[]}`)
+	require.NoError(err)
+	require.Equal(200, status)
+	require.JSONEq(`{"jsonrpc":"2.0","result":"0x1","id":0}`, body)
+	_, _, err = post(client, "http://rpc.invalid/", `{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)
+	require.NoError(err)
+	require.NoError(recorder.Save())
+	_, err = os.Stat(fixture)
+	require.NoError(err)
+
+	replayer, err := NewReplayTransport(fixture)
+	require.NoError(err)
+	require.Len(replayer.Unreplayed(), 2)
+	client = &http.Client{Transport: replayer}
+	// ids are ignored when matching, and set from the request
+	status, body, err = post(client, "http://rpc.invalid/", `{"jsonrpc":"2.0","id":7,"method":"eth_chainId","params":[]}`)
+	require.NoError(err)
+	require.Equal(200, status)
+	require.JSONEq(`{"jsonrpc":"2.0","result":"0x2","id":7}`, body)
+	require.Len(replayer.Unreplayed(), 1)
+
+	// interactions are replayed once
+	_, _, err = post(client, "http://rpc.invalid/", `{"jsonrpc":"2.0","id":8,"method":"eth_chainId","params":[]}`)
+	require.ErrorContains(err, "no interaction recorded")
+	_, _, err = post(client, "http://rpc.invalid/other", `{"jsonrpc":"2.0","id":9,"method":"eth_blockNumber","params":[]}`)
+	require.ErrorContains(err, "no interaction recorded")
+}
+
+func (s *CrosschainTestSuite) TestRPCID() {
+	require := s.Require()
+	require.JSONEq(`{"method":"m","params":[1]}`, string(withoutRPCID([]byte(`{"id":3,"method":"m","params":[1]}`))))
+	require.JSONEq(`[{"method":"a"},{"method":"b"}]`, string(withoutRPCID([]byte(`[{"id":1,"method":"a"},{"id":2,"method":"b"}]`))))
+	require.Equal("not json", string(withoutRPCID([]byte("not json"))))
+
+	require.JSONEq(`{"id":5,"result":1}`, string(withRPCID([]byte(`{"id":1,"result":1}`), []byte(`{"id":5}`))))
+	require.JSONEq(`[{"id":"a","result":1},{"id":"b","result":2}]`, string(withRPCID([]byte(`[{"id":1,"result":1},{"id":2,"result":2}]`), []byte(`[{"id":"a"},{"id":"b"}]`))))
+	// REST responses are unchanged
+	require.JSONEq(`{"balance":"1"}`, string(withRPCID([]byte(`{"balance":"1"}`), []byte(`{"id":5}`))))
+}
+
+func (s *CrosschainTestSuite) TestReplayServer() {
+	require := s.Require()
+	upstream, closeUpstream := MockHTTP(&s.Suite, `{"balance":"10"}`)
+	defer closeUpstream()
+	fixture := filepath.Join(s.T().TempDir(), "fixture.json")
+
+	os.Setenv(RecordEnv, "1")
+	server, close := ReplayServer(&s.Suite, fixture, upstream.URL)
+	os.Unsetenv(RecordEnv)
+	_, body, err := post(server.Client(), server.URL+"/balance", `{"address":"a"}`)
+	require.NoError(err)
+	require.JSONEq(`{"balance":"10"}`, body)
+	close()
+
+	server, close = ReplayServer(&s.Suite, fixture, "")
+	defer close()
+	_, body, err = post(server.Client(), server.URL+"/balance", `{"address":"a"}`)
+	require.NoError(err)
+	require.JSONEq(`{"balance":"10"}`, body)
+	status, _, err := post(server.Client(), server.URL+"/balance", `{"address":"b"}`)
+	require.NoError(err)
+	require.Equal(http.StatusBadGateway, status)
+}
+
+func (s *CrosschainTestSuite) TestRecordReplayRaw() {
+	require := s.Require()
+	upstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain")
+		rw.Write([]byte("rate limited\n"))
+	}))
+	defer upstream.Close()
+	fixture := filepath.Join(s.T().TempDir(), "fixture.json")
+	binary := string([]byte{0x00, 0xff, 0x10})
+
+	recorder := NewRecordingTransport(fixture, upstream.URL, nil)
+	client := &http.Client{Transport: recorder}
+	_, body, err := post(client, "http://rpc.invalid/submit", binary)
+	require.NoError(err)
+	require.Equal("rate limited\n", body)
+	require.NoError(recorder.Save())
+	data, err := os.ReadFile(fixture)
+	require.NoError(err)
+	require.Contains(string(data), `"response": "cmF0ZSBsaW1pdGVkCg=="`)
+	require.Contains(string(data), `"response_base64": true`)
+
+	// bodies are replayed as recorded
+	replayer, err := NewReplayTransport(fixture)
+	require.NoError(err)
+	client = &http.Client{Transport: replayer}
+	res, err := client.Post("http://rpc.invalid/submit", "application/octet-stream", strings.NewReader(binary))
+	require.NoError(err)
+	defer res.Body.Close()
+	response, err := io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal("rate limited\n", string(response))
+	require.Equal("text/plain", res.Header.Get("Content-Type"))
+	require.Len(replayer.Unreplayed(), 0)
+}
+
+func (s *CrosschainTestSuite) TestEncodeBody() {
+	require := s.Require()
+	encoded, base64Encoded := encodeBody([]byte(`{"a":1}`))
+	require.False(base64Encoded)
+	require.Equal(`{"a":1}`, string(encoded))
+	encoded, base64Encoded = encodeBody([]byte("\"not closed"))
+	require.True(base64Encoded)
+	decoded, err := decodeBody(encoded, base64Encoded)
+	require.NoError(err)
+	require.Equal("\"not closed", string(decoded))
+	encoded, base64Encoded = encodeBody(nil)
+	require.Nil(encoded)
+	require.False(base64Encoded)
+	_, err = decodeBody(json.RawMessage(`"!"`), true)
+	require.Error(err)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -22,6 +23,8 @@ func wrapRPCError(err string) string {
 // MockJSONRPCServer is a mocked RPC server
 type MockJSONRPCServer struct {
 	*httptest.Server
+	// serializes requests, so that clients can be tested concurrently
+	mu       sync.Mutex
 	body     []byte
 	Counter  int
 	Response interface{}
@@ -33,6 +36,8 @@ func MockJSONRPC(s *suite.Suite, response interface{}) (mock *MockJSONRPCServer,
 	mock = &MockJSONRPCServer{
 		Response: response,
 		Server: httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			mock.mu.Lock()
+			defer mock.mu.Unlock()
 			curResponse := mock.Response
 			if a, ok := mock.Response.([]string); ok {
 				curResponse = a[mock.Counter]
@@ -78,6 +83,7 @@ func MockJSONRPC(s *suite.Suite, response interface{}) (mock *MockJSONRPCServer,
 // MockHTTPServer is a mocked HTTP server
 type MockHTTPServer struct {
 	*httptest.Server
+	mu          sync.Mutex
 	body        []byte
 	Counter     int
 	Response    interface{}
@@ -91,6 +97,8 @@ func MockHTTP(s *suite.Suite, response interface{}) (mock *MockHTTPServer, close
 		Response:    response,
 		StatusCodes: []int{},
 		Server: httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			mock.mu.Lock()
+			defer mock.mu.Unlock()
 			curResponse := mock.Response
 			if a, ok := mock.Response.([]string); ok {
 				curResponse = a[mock.Counter]
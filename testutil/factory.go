@@ -42,6 +42,11 @@ func (f *TestFactory) NewSigner(asset xc.ITask) (xc.Signer, error) {
 	return f.DefaultFactory.NewSigner(asset)
 }
 
+// NewLocalSigner creates a new KeySigner of a private key
+func (f *TestFactory) NewLocalSigner(asset xc.ITask, privateKey string) (xc.KeySigner, error) {
+	return f.DefaultFactory.NewLocalSigner(asset, privateKey)
+}
+
 // NewAddressBuilder creates a new AddressBuilder
 func (f *TestFactory) NewAddressBuilder(asset xc.ITask) (xc.AddressBuilder, error) {
 	if f.NewAddressBuilderFunc != nil {
@@ -77,6 +82,11 @@ func (f *TestFactory) GetAllPossibleAddressesFromPublicKey(asset xc.ITask, publi
 	return f.DefaultFactory.GetAllPossibleAddressesFromPublicKey(asset, publicKey)
 }
 
+// ValidateAddress checks the format and checksum of an address of the chain of an asset
+func (f *TestFactory) ValidateAddress(asset xc.ITask, address xc.Address) error {
+	return f.DefaultFactory.ValidateAddress(asset, address)
+}
+
 // ConvertAmountToHuman converts an AmountBlockchain into AmountHumanReadable, dividing by the appropriate number of decimals
 func (f *TestFactory) ConvertAmountToHuman(asset xc.ITask, blockchainAmount xc.AmountBlockchain) (xc.AmountHumanReadable, error) {
 	return f.DefaultFactory.ConvertAmountToHuman(asset, blockchainAmount)
@@ -108,7 +118,7 @@ func (f *TestFactory) GetTaskConfigByNameSrcDstAssetIDs(taskName string, srcAsse
 
 // GetTaskConfigBySrcDstAssets returns an AssetConfig by source and destination assets
 func (f *TestFactory) GetTaskConfigBySrcDstAssets(srcAsset xc.ITask, dstAsset xc.ITask) ([]xc.ITask, error) {
-	return f.DefaultFactory.GetTaskConfigBySrcDstAssets(srcAsset, srcAsset)
+	return f.DefaultFactory.GetTaskConfigBySrcDstAssets(srcAsset, dstAsset)
 }
 
 // GetMultiAssetConfig returns an AssetConfig by source and destination assetIDs
@@ -126,6 +136,21 @@ func (f *TestFactory) EnrichAssetConfig(partialCfg *xc.TokenAssetConfig) (*xc.To
 	return f.DefaultFactory.EnrichAssetConfig(partialCfg)
 }
 
+// SetUnknownTokenPolicy sets how EnrichDestinations handles tokens missing from the config
+func (f *TestFactory) SetUnknownTokenPolicy(policy xc.UnknownTokenPolicy) {
+	f.DefaultFactory.SetUnknownTokenPolicy(policy)
+}
+
+// UseTxBuilderMiddleware adds middlewares to the TxBuilders created next
+func (f *TestFactory) UseTxBuilderMiddleware(middlewares ...xc.TxBuilderMiddleware) {
+	f.DefaultFactory.UseTxBuilderMiddleware(middlewares...)
+}
+
+// RegisterProviderDivergenceCallback sets a callback called with the divergences of quorum reads
+func (f *TestFactory) RegisterProviderDivergenceCallback(callback func(divergence *xc.ProviderDivergence)) {
+	f.DefaultFactory.RegisterProviderDivergenceCallback(callback)
+}
+
 // EnrichDestinations augments a TxInfo by resolving assets and amounts in TxInfo.Destinations
 func (f *TestFactory) EnrichDestinations(activity xc.ITask, txInfo xc.TxInfo) (xc.TxInfo, error) {
 	return f.DefaultFactory.EnrichDestinations(activity, txInfo)
@@ -157,6 +182,11 @@ func (f *TestFactory) Config() interface{} {
 	return f.DefaultFactory.Config()
 }
 
+// Fingerprint returns a stable hash of the assets, tasks and pipelines of the Factory
+func (f *TestFactory) Fingerprint() string {
+	return f.DefaultFactory.Fingerprint()
+}
+
 // MustAddress coverts a string to Address, panic if error
 func (f *TestFactory) MustAddress(asset xc.ITask, addressStr string) xc.Address {
 	return f.DefaultFactory.MustAddress(asset, addressStr)
@@ -0,0 +1,216 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// ErrInjectedFault is returned by a FaultyClient for a simulated provider failure
+var ErrInjectedFault = errors.New("injected fault")
+
+// ErrDroppedResponse is returned by a FaultyClient when a request succeeded but its response was dropped
+var ErrDroppedResponse = errors.New("injected fault: response dropped")
+
+// Faults configures the failures injected by a FaultyClient
+// Rates are probabilities between 0 and 1
+type Faults struct {
+	// Latency added to each request, plus a random jitter up to LatencyJitter
+	Latency       time.Duration
+	LatencyJitter time.Duration
+	// ErrorRate of requests failing before reaching the provider (flaky provider)
+	ErrorRate float64
+	// DropRate of requests reaching the provider but failing to return (e.g. a submitted tx reported as failed)
+	DropRate float64
+	// StaleNonce is subtracted from the nonce (or sequence) of fetched tx inputs
+	StaleNonce uint64
+	// ReorgDepth is subtracted from the confirmations of fetched tx infos,
+	// and txs with fewer confirmations are reported as not found
+	ReorgDepth int64
+}
+
+// FaultyClient is a Client injecting faults in front of another Client, to test recovery logic
+type FaultyClient struct {
+	Client xc.Client
+	mu     sync.Mutex
+	faults Faults
+	rand   *rand.Rand
+}
+
+var _ xc.ClientBalance = &FaultyClient{}
+
+// NewFaultyClient creates a new FaultyClient, seed makes the injected faults reproducible
+func NewFaultyClient(client xc.Client, faults Faults, seed int64) *FaultyClient {
+	return &FaultyClient{
+		Client: client,
+		faults: faults,
+		rand:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// SetFaults changes the injected faults, e.g. to recover a provider in the middle of a test
+func (client *FaultyClient) SetFaults(faults Faults) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.faults = faults
+}
+
+// Faults returns the injected faults
+func (client *FaultyClient) Faults() Faults {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return client.faults
+}
+
+// before waits for the injected latency and returns an error for a flaky provider
+func (client *FaultyClient) before(ctx context.Context) error {
+	client.mu.Lock()
+	faults := client.faults
+	latency := faults.Latency
+	if faults.LatencyJitter > 0 {
+		latency += time.Duration(client.rand.Int63n(int64(faults.LatencyJitter)))
+	}
+	fail := faults.ErrorRate > 0 && client.rand.Float64() < faults.ErrorRate
+	client.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if fail {
+		return ErrInjectedFault
+	}
+	return nil
+}
+
+// dropped returns true if the response of a request is dropped
+func (client *FaultyClient) dropped() bool {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return client.faults.DropRate > 0 && client.rand.Float64() < client.faults.DropRate
+}
+
+// FetchTxInput fetches tx input, with faults and a stale nonce
+func (client *FaultyClient) FetchTxInput(ctx context.Context, from xc.Address, to xc.Address) (xc.TxInput, error) {
+	if err := client.before(ctx); err != nil {
+		return nil, err
+	}
+	input, err := client.Client.FetchTxInput(ctx, from, to)
+	if err != nil {
+		return input, err
+	}
+	if client.dropped() {
+		return nil, ErrDroppedResponse
+	}
+	if stale := client.Faults().StaleNonce; stale > 0 {
+		return WithStaleNonce(input, stale)
+	}
+	return input, nil
+}
+
+// FetchTxInfo fetches tx info, with faults and a reorg
+func (client *FaultyClient) FetchTxInfo(ctx context.Context, txHash xc.TxHash) (xc.TxInfo, error) {
+	if err := client.before(ctx); err != nil {
+		return xc.TxInfo{}, err
+	}
+	info, err := client.Client.FetchTxInfo(ctx, txHash)
+	if err != nil {
+		return info, err
+	}
+	if client.dropped() {
+		return xc.TxInfo{}, ErrDroppedResponse
+	}
+	if depth := client.Faults().ReorgDepth; depth > 0 {
+		return Reorg(info, depth)
+	}
+	return info, nil
+}
+
+// SubmitTx submits a tx, with faults: a dropped response means the tx was still submitted
+func (client *FaultyClient) SubmitTx(ctx context.Context, tx xc.Tx) error {
+	if err := client.before(ctx); err != nil {
+		return err
+	}
+	if err := client.Client.SubmitTx(ctx, tx); err != nil {
+		return err
+	}
+	if client.dropped() {
+		return ErrDroppedResponse
+	}
+	return nil
+}
+
+func (client *FaultyClient) fetchBalance(ctx context.Context, fetch func(xc.ClientBalance) (xc.AmountBlockchain, error)) (xc.AmountBlockchain, error) {
+	balanceClient, ok := client.Client.(xc.ClientBalance)
+	if !ok {
+		return xc.AmountBlockchain{}, fmt.Errorf("balances are not supported by %T", client.Client)
+	}
+	if err := client.before(ctx); err != nil {
+		return xc.AmountBlockchain{}, err
+	}
+	balance, err := fetch(balanceClient)
+	if err != nil {
+		return balance, err
+	}
+	if client.dropped() {
+		return xc.AmountBlockchain{}, ErrDroppedResponse
+	}
+	return balance, nil
+}
+
+// FetchBalance fetches balance, with faults
+func (client *FaultyClient) FetchBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	return client.fetchBalance(ctx, func(balanceClient xc.ClientBalance) (xc.AmountBlockchain, error) {
+		return balanceClient.FetchBalance(ctx, address)
+	})
+}
+
+// FetchNativeBalance fetches native asset balance, with faults
+func (client *FaultyClient) FetchNativeBalance(ctx context.Context, address xc.Address) (xc.AmountBlockchain, error) {
+	return client.fetchBalance(ctx, func(balanceClient xc.ClientBalance) (xc.AmountBlockchain, error) {
+		return balanceClient.FetchNativeBalance(ctx, address)
+	})
+}
+
+// WithStaleNonce returns a copy of input with its Nonce (or Sequence) field decreased by stale
+func WithStaleNonce(input xc.TxInput, stale uint64) (xc.TxInput, error) {
+	value := reflect.ValueOf(input)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported tx input %T", input)
+	}
+	copied := reflect.New(value.Elem().Type())
+	copied.Elem().Set(value.Elem())
+	for _, name := range []string{"Nonce", "Sequence"} {
+		field := copied.Elem().FieldByName(name)
+		if field.IsValid() && field.CanSet() && field.Kind() == reflect.Uint64 {
+			if field.Uint() < stale {
+				field.SetUint(0)
+			} else {
+				field.SetUint(field.Uint() - stale)
+			}
+			return copied.Interface().(xc.TxInput), nil
+		}
+	}
+	return nil, fmt.Errorf("tx input %T has no nonce", input)
+}
+
+// Reorg returns info as if the last depth blocks were reorganized
+// Txs included in these blocks are reported as not found
+func Reorg(info xc.TxInfo, depth int64) (xc.TxInfo, error) {
+	if info.Confirmations <= depth {
+		return xc.TxInfo{}, fmt.Errorf("%w: tx %s not found after reorg", ErrInjectedFault, info.TxID)
+	}
+	info.Confirmations -= depth
+	return info, nil
+}
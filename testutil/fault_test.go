@@ -0,0 +1,112 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/evm"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+}
+
+func TestTestutilTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+func (s *CrosschainTestSuite) TestFaultyClientPassthrough() {
+	require := s.Require()
+	mocked := &MockedClient{}
+	mocked.On("FetchBalance", mock.Anything, xc.Address("addr")).Return(xc.NewAmountBlockchainFromUint64(10), nil)
+	mocked.On("SubmitTx", mock.Anything, mock.Anything).Return(nil)
+
+	client := NewFaultyClient(mocked, Faults{}, 1)
+	balance, err := client.FetchBalance(s.Ctx, "addr")
+	require.NoError(err)
+	require.Equal("10", balance.String())
+	require.NoError(client.SubmitTx(s.Ctx, nil))
+}
+
+func (s *CrosschainTestSuite) TestFaultyClientErrors() {
+	require := s.Require()
+	mocked := &MockedClient{}
+	mocked.On("SubmitTx", mock.Anything, mock.Anything).Return(nil)
+
+	// a flaky provider never receives the request
+	client := NewFaultyClient(mocked, Faults{ErrorRate: 1}, 1)
+	require.ErrorIs(client.SubmitTx(s.Ctx, nil), ErrInjectedFault)
+	mocked.AssertNotCalled(s.T(), "SubmitTx", mock.Anything, mock.Anything)
+
+	// a dropped response still submits the tx
+	client.SetFaults(Faults{DropRate: 1})
+	require.ErrorIs(client.SubmitTx(s.Ctx, nil), ErrDroppedResponse)
+	mocked.AssertNumberOfCalls(s.T(), "SubmitTx", 1)
+
+	// rates are sampled with the seed
+	client = NewFaultyClient(mocked, Faults{ErrorRate: 0.5}, 1)
+	failed := 0
+	for i := 0; i < 100; i++ {
+		if errors.Is(client.SubmitTx(s.Ctx, nil), ErrInjectedFault) {
+			failed++
+		}
+	}
+	require.InDelta(50, failed, 20)
+}
+
+func (s *CrosschainTestSuite) TestFaultyClientLatency() {
+	require := s.Require()
+	mocked := &MockedClient{}
+	mocked.On("SubmitTx", mock.Anything, mock.Anything).Return(nil)
+
+	client := NewFaultyClient(mocked, Faults{Latency: 20 * time.Millisecond}, 1)
+	start := time.Now()
+	require.NoError(client.SubmitTx(s.Ctx, nil))
+	require.GreaterOrEqual(time.Since(start), 20*time.Millisecond)
+
+	client.SetFaults(Faults{Latency: time.Hour})
+	ctx, cancel := context.WithTimeout(s.Ctx, 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(client.SubmitTx(ctx, nil), context.DeadlineExceeded)
+}
+
+func (s *CrosschainTestSuite) TestFaultyClientStaleNonce() {
+	require := s.Require()
+	input := evm.NewTxInput()
+	input.Nonce = 5
+	mocked := &MockedClient{}
+	mocked.On("FetchTxInput", mock.Anything, mock.Anything, mock.Anything).Return(input, nil)
+
+	client := NewFaultyClient(mocked, Faults{StaleNonce: 2}, 1)
+	stale, err := client.FetchTxInput(s.Ctx, "from", "to")
+	require.NoError(err)
+	require.Equal(uint64(3), stale.(*evm.TxInput).Nonce)
+	require.Equal(uint64(5), input.Nonce)
+
+	_, err = WithStaleNonce(&struct{ xc.TxInputEnvelope }{}, 1)
+	require.ErrorContains(err, "has no nonce")
+}
+
+func (s *CrosschainTestSuite) TestFaultyClientReorg() {
+	require := s.Require()
+	mocked := &MockedClient{}
+	mocked.On("FetchTxInfo", mock.Anything, xc.TxHash("deep")).Return(xc.TxInfo{TxID: "deep", Confirmations: 10}, nil)
+	mocked.On("FetchTxInfo", mock.Anything, xc.TxHash("shallow")).Return(xc.TxInfo{TxID: "shallow", Confirmations: 2}, nil)
+
+	client := NewFaultyClient(mocked, Faults{ReorgDepth: 2}, 1)
+	info, err := client.FetchTxInfo(s.Ctx, "deep")
+	require.NoError(err)
+	require.EqualValues(8, info.Confirmations)
+	_, err = client.FetchTxInfo(s.Ctx, "shallow")
+	require.ErrorIs(err, ErrInjectedFault)
+}
@@ -53,3 +53,21 @@ func (m *MockedClient) UpdateAsset(assetCfg xc.AssetConfig) error {
 	args := m.Called(assetCfg)
 	return args.Error(1)
 }
+
+// FetchStakedBalance fetches liquid staking positions, mocked
+func (m *MockedClient) FetchStakedBalance(ctx context.Context, address xc.Address) ([]xc.StakedBalance, error) {
+	args := m.Called(ctx, address)
+	return args.Get(0).([]xc.StakedBalance), args.Error(1)
+}
+
+// FetchClaimableRewards fetches claimable staking rewards, mocked
+func (m *MockedClient) FetchClaimableRewards(ctx context.Context, address xc.Address) ([]xc.ClaimableReward, error) {
+	args := m.Called(ctx, address)
+	return args.Get(0).([]xc.ClaimableReward), args.Error(1)
+}
+
+// FetchImportInput fetches the input of an atomic import, mocked
+func (m *MockedClient) FetchImportInput(ctx context.Context, address xc.Address, source xc.NativeAsset) (xc.TxInput, error) {
+	args := m.Called(ctx, address, source)
+	return args.Get(0).(xc.TxInput), args.Error(1)
+}
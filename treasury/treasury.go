@@ -0,0 +1,198 @@
+package treasury
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/factory"
+)
+
+// Wallet is a hot wallet of an asset, replenished from a cold address
+type Wallet struct {
+	Asset xc.ITask
+	Hot   xc.Address
+	Cold  xc.Address
+	// Threshold is the hot balance below which a replenishment is proposed
+	Threshold xc.AmountBlockchain
+	// Target is the hot balance to replenish to, at least Threshold
+	Target xc.AmountBlockchain
+}
+
+// Proposal is a replenishment transfer from a cold to a hot address, to approve before building and signing it
+// Proposals carry no tx: the tx input of the cold address, e.g. a nonce or a recent blockhash, is fetched once approved
+type Proposal struct {
+	// ID identifies the proposal for the approval pipeline, e.g. to deduplicate it
+	ID          string              `json:"id"`
+	Asset       xc.AssetID          `json:"asset"`
+	From        xc.Address          `json:"from"`
+	To          xc.Address          `json:"to"`
+	Amount      xc.AmountBlockchain `json:"amount"`
+	HotBalance  xc.AmountBlockchain `json:"hot_balance"`
+	ColdBalance xc.AmountBlockchain `json:"cold_balance"`
+	Threshold   xc.AmountBlockchain `json:"threshold"`
+	Target      xc.AmountBlockchain `json:"target"`
+	// Partial is set if the cold balance doesn't cover the replenishment to Target
+	Partial   bool      `json:"partial"`
+	CreatedAt time.Time `json:"created_at"`
+	// Wallet is the replenished Wallet
+	Wallet *Wallet `json:"-"`
+}
+
+// Handler receives the proposals, e.g. to submit them to an approval and policy pipeline
+// Proposals must never be signed automatically
+type Handler func(ctx context.Context, proposal *Proposal) error
+
+// Monitor periodically checks the balances of hot wallets and proposes replenishments from their cold addresses
+type Monitor struct {
+	Factory  factory.FactoryContext
+	Wallets  []*Wallet
+	Interval time.Duration
+	Handler  Handler
+	// Cooldown is the delay before proposing again the replenishment of a wallet, e.g. while a proposal awaits approval
+	Cooldown time.Duration
+
+	mu       sync.Mutex
+	proposed map[string]time.Time
+}
+
+// NewMonitor creates a new Monitor
+func NewMonitor(f factory.FactoryContext, wallets []*Wallet, interval time.Duration, handler Handler) *Monitor {
+	return &Monitor{
+		Factory:  f,
+		Wallets:  wallets,
+		Interval: interval,
+		Handler:  handler,
+		Cooldown: interval,
+		proposed: map[string]time.Time{},
+	}
+}
+
+func walletKey(wallet *Wallet) string {
+	return fmt.Sprintf("%s:%s", wallet.Asset.ID(), wallet.Hot)
+}
+
+// Check fetches the balances of wallet and returns a replenishment proposal, or nil if none is needed
+func (monitor *Monitor) Check(ctx context.Context, wallet *Wallet) (*Proposal, error) {
+	if wallet.Target.Cmp(&wallet.Threshold) < 0 {
+		return nil, fmt.Errorf("target %s is below threshold %s", wallet.Target.String(), wallet.Threshold.String())
+	}
+	client, err := monitor.Factory.NewClient(wallet.Asset)
+	if err != nil {
+		return nil, err
+	}
+	balanceClient, ok := client.(xc.ClientBalance)
+	if !ok {
+		return nil, fmt.Errorf("balances are not supported for %s", wallet.Asset.ID())
+	}
+	hotBalance, err := balanceClient.FetchBalance(ctx, wallet.Hot)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch balance of %s: %v", wallet.Hot, err)
+	}
+	if hotBalance.Cmp(&wallet.Threshold) >= 0 {
+		return nil, nil
+	}
+	coldBalance, err := balanceClient.FetchBalance(ctx, wallet.Cold)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch balance of %s: %v", wallet.Cold, err)
+	}
+
+	amount := xc.AmountBlockchain(*new(big.Int).Sub(wallet.Target.Int(), hotBalance.Int()))
+	partial := false
+	if amount.Cmp(&coldBalance) > 0 {
+		amount = xc.AmountBlockchain(*new(big.Int).Set(coldBalance.Int()))
+		partial = true
+	}
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("cold address %s has no balance to replenish %s", wallet.Cold, wallet.Hot)
+	}
+	createdAt := now()
+	return &Proposal{
+		ID:          fmt.Sprintf("%s:%d", walletKey(wallet), createdAt.Unix()),
+		Asset:       wallet.Asset.ID(),
+		From:        wallet.Cold,
+		To:          wallet.Hot,
+		Amount:      amount,
+		HotBalance:  hotBalance,
+		ColdBalance: coldBalance,
+		Threshold:   wallet.Threshold,
+		Target:      wallet.Target,
+		Partial:     partial,
+		CreatedAt:   createdAt,
+		Wallet:      wallet,
+	}, nil
+}
+
+// RunOnce checks every wallet once, passing proposals to the Handler, except for wallets proposed within the Cooldown
+// A failing wallet doesn't prevent the others from being processed
+func (monitor *Monitor) RunOnce(ctx context.Context) error {
+	if monitor.Handler == nil {
+		return errors.New("monitor has no handler")
+	}
+	errs := []string{}
+	for _, wallet := range monitor.Wallets {
+		key := walletKey(wallet)
+		if monitor.coolingDown(key) {
+			continue
+		}
+		proposal, err := monitor.Check(ctx, wallet)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		if proposal == nil {
+			continue
+		}
+		err = monitor.Handler(ctx, proposal)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		monitor.mu.Lock()
+		monitor.proposed[key] = proposal.CreatedAt
+		monitor.mu.Unlock()
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to check hot wallets: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (monitor *Monitor) coolingDown(key string) bool {
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+	proposedAt, ok := monitor.proposed[key]
+	return ok && now().Sub(proposedAt) < monitor.Cooldown
+}
+
+// Reset allows proposing again the replenishment of wallet, e.g. once its proposal is rejected or executed
+func (monitor *Monitor) Reset(wallet *Wallet) {
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+	delete(monitor.proposed, walletKey(wallet))
+}
+
+// Run calls RunOnce every Interval until ctx is done
+// Errors of a run are passed to onError, if set
+func (monitor *Monitor) Run(ctx context.Context, onError func(error)) error {
+	ticker := time.NewTicker(monitor.Interval)
+	defer ticker.Stop()
+	for {
+		err := monitor.RunOnce(ctx)
+		if err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+var now = time.Now
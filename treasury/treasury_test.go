@@ -0,0 +1,151 @@
+package treasury
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx context.Context
+	t   time.Time
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+	s.t = time.Unix(1_700_000_000, 0)
+	now = func() time.Time {
+		return s.t
+	}
+}
+
+func (s *CrosschainTestSuite) TearDownTest() {
+	now = time.Now
+}
+
+func TestTreasuryTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+var ethAsset = &xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, Driver: "evm", Decimals: 18}
+
+const hotWallet = xc.Address("0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+const coldWallet = xc.Address("0x24b3A3F3B8e2D2eC7E44A1c8FBbA0C8d2e7bA0bd")
+
+func newTestMonitor(client *testutil.MockedClient, handler Handler) *Monitor {
+	f := testutil.NewDefaultFactory()
+	f.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		return client, nil
+	}
+	wallet := &Wallet{
+		Asset:     ethAsset,
+		Hot:       hotWallet,
+		Cold:      coldWallet,
+		Threshold: xc.NewAmountBlockchainFromUint64(1000),
+		Target:    xc.NewAmountBlockchainFromUint64(5000),
+	}
+	return NewMonitor(&f, []*Wallet{wallet}, time.Minute, handler)
+}
+
+func (s *CrosschainTestSuite) TestCheck() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	client.On("FetchBalance", mock.Anything, hotWallet).Return(xc.NewAmountBlockchainFromUint64(400), nil)
+	client.On("FetchBalance", mock.Anything, coldWallet).Return(xc.NewAmountBlockchainFromUint64(100_000), nil)
+	monitor := newTestMonitor(client, nil)
+
+	proposal, err := monitor.Check(s.Ctx, monitor.Wallets[0])
+	require.NoError(err)
+	require.Equal("ETH:0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B:1700000000", proposal.ID)
+	require.Equal(xc.AssetID("ETH"), proposal.Asset)
+	require.Equal(coldWallet, proposal.From)
+	require.Equal(hotWallet, proposal.To)
+	require.EqualValues("4600", proposal.Amount.String())
+	require.EqualValues("400", proposal.HotBalance.String())
+	require.False(proposal.Partial)
+
+	// limited by the cold balance
+	client = &testutil.MockedClient{}
+	client.On("FetchBalance", mock.Anything, hotWallet).Return(xc.NewAmountBlockchainFromUint64(400), nil)
+	client.On("FetchBalance", mock.Anything, coldWallet).Return(xc.NewAmountBlockchainFromUint64(3000), nil)
+	monitor = newTestMonitor(client, nil)
+	proposal, err = monitor.Check(s.Ctx, monitor.Wallets[0])
+	require.NoError(err)
+	require.EqualValues("3000", proposal.Amount.String())
+	require.True(proposal.Partial)
+
+	// above the threshold
+	client = &testutil.MockedClient{}
+	client.On("FetchBalance", mock.Anything, hotWallet).Return(xc.NewAmountBlockchainFromUint64(1000), nil)
+	monitor = newTestMonitor(client, nil)
+	proposal, err = monitor.Check(s.Ctx, monitor.Wallets[0])
+	require.NoError(err)
+	require.Nil(proposal)
+	client.AssertNotCalled(s.T(), "FetchBalance", mock.Anything, coldWallet)
+}
+
+func (s *CrosschainTestSuite) TestCheckErrors() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	client.On("FetchBalance", mock.Anything, hotWallet).Return(xc.NewAmountBlockchainFromUint64(0), nil)
+	client.On("FetchBalance", mock.Anything, coldWallet).Return(xc.NewAmountBlockchainFromUint64(0), nil)
+	monitor := newTestMonitor(client, nil)
+	_, err := monitor.Check(s.Ctx, monitor.Wallets[0])
+	require.EqualError(err, "cold address 0x24b3A3F3B8e2D2eC7E44A1c8FBbA0C8d2e7bA0bd has no balance to replenish 0x0eC9f48533bb2A03F53F341EF5cc1B057892B10B")
+
+	client = &testutil.MockedClient{}
+	client.On("FetchBalance", mock.Anything, hotWallet).Return(xc.AmountBlockchain{}, errors.New("rpc error"))
+	monitor = newTestMonitor(client, nil)
+	_, err = monitor.Check(s.Ctx, monitor.Wallets[0])
+	require.ErrorContains(err, "rpc error")
+
+	monitor.Wallets[0].Target = xc.NewAmountBlockchainFromUint64(10)
+	_, err = monitor.Check(s.Ctx, monitor.Wallets[0])
+	require.EqualError(err, "target 10 is below threshold 1000")
+}
+
+func (s *CrosschainTestSuite) TestRunOnce() {
+	require := s.Require()
+	client := &testutil.MockedClient{}
+	client.On("FetchBalance", mock.Anything, hotWallet).Return(xc.NewAmountBlockchainFromUint64(400), nil)
+	client.On("FetchBalance", mock.Anything, coldWallet).Return(xc.NewAmountBlockchainFromUint64(100_000), nil)
+	proposals := []*Proposal{}
+	monitor := newTestMonitor(client, func(ctx context.Context, proposal *Proposal) error {
+		proposals = append(proposals, proposal)
+		return nil
+	})
+
+	require.NoError(monitor.RunOnce(s.Ctx))
+	require.Len(proposals, 1)
+	require.Equal(monitor.Wallets[0], proposals[0].Wallet)
+
+	// not proposed again during the cooldown
+	s.t = s.t.Add(30 * time.Second)
+	require.NoError(monitor.RunOnce(s.Ctx))
+	require.Len(proposals, 1)
+	monitor.Reset(monitor.Wallets[0])
+	require.NoError(monitor.RunOnce(s.Ctx))
+	require.Len(proposals, 2)
+	s.t = s.t.Add(time.Minute)
+	require.NoError(monitor.RunOnce(s.Ctx))
+	require.Len(proposals, 3)
+
+	// a proposal rejected by the handler is proposed again
+	monitor.Handler = func(ctx context.Context, proposal *Proposal) error {
+		return errors.New("pipeline unavailable")
+	}
+	s.t = s.t.Add(time.Minute)
+	err := monitor.RunOnce(s.Ctx)
+	require.ErrorContains(err, "pipeline unavailable")
+	require.False(monitor.coolingDown(walletKey(monitor.Wallets[0])))
+
+	monitor.Handler = nil
+	require.EqualError(monitor.RunOnce(s.Ctx), "monitor has no handler")
+}
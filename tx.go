@@ -1,6 +1,9 @@
 package crosschain
 
-import "encoding/base64"
+import (
+	"encoding/base64"
+	"io"
+)
 
 // TxInput is input data to a tx. Depending on the blockchain it can include nonce, recent block hash, account id, ...
 type TxInput interface {
@@ -13,13 +16,35 @@ type TxInputWithPublicKey interface {
 	SetPublicKeyFromStr(string) error
 }
 
+// TxInputFee is a TxInput that tells the max fee paid in the native asset by the tx built with it
+// Builders set the gas limit of the input, so the max fee is known once the tx is built
+type TxInputFee interface {
+	TxInput
+	MaxFee() AmountBlockchain
+}
+
+// TxInputNonce is a TxInput of an account-based chain, whose txs are ordered by the nonce, or sequence, of their sender
+// A tx signed with the wrong nonce is rejected or replaces another tx, so the nonce is cross-checked by quorum reads
+type TxInputNonce interface {
+	TxInput
+	GetNonce() uint64
+}
+
+// TxInputVersion is the version of the serialization of TxInputs, incremented on breaking changes of their fields:
+// inputs fetched by a service can be signed and broadcast by services of older versions supporting their version
+const TxInputVersion = 1
+
+// TxInputEnvelope tags the serialization of a TxInput with its driver and version, see factory.UnmarshalTxInput
 type TxInputEnvelope struct {
 	Type Driver `json:"type"`
+	// Version is 0 for inputs serialized before versioning, compatible with version 1
+	Version int `json:"version,omitempty"`
 }
 
 func NewTxInputEnvelope(envType Driver) *TxInputEnvelope {
 	return &TxInputEnvelope{
-		Type: envType,
+		Type:    envType,
+		Version: TxInputVersion,
 	}
 }
 
@@ -52,19 +77,41 @@ type TxInfo struct {
 	ToAlt           Address
 	ContractAddress ContractAddress
 	Amount          AmountBlockchain
-	Fee             AmountBlockchain
-	BlockIndex      int64
-	BlockTime       int64
-	Confirmations   int64
-	Status          TxStatus
-	Sources         []*TxInfoEndpoint
-	Destinations    []*TxInfoEndpoint
-	Time            int64
-	TimeReceived    int64
+	// Fee actually paid by the confirmed tx, net of gas refunds, rather than its estimate or limit
+	Fee AmountBlockchain
+	// GasUsed by the confirmed tx, 0 for chains without gas
+	GasUsed uint64
+	// GasPrice is the effective price paid per gas by the confirmed tx (EVM), e.g. the base fee plus the
+	// priority fee of a dynamic fee tx, unset if unknown
+	GasPrice      AmountBlockchain
+	BlockIndex    int64
+	BlockTime     int64
+	Confirmations int64
+	Status        TxStatus
+	Sources       []*TxInfoEndpoint
+	Destinations  []*TxInfoEndpoint
+	Time          int64
+	TimeReceived  int64
+	// Outputs returning funds to the sender (UTXO chains), not included in Destinations
+	Change []*TxInfoEndpoint
 	// If this transaction failed, this is the reason why.
 	Error string
 }
 
+// UnknownTokenPolicy is how destinations of tokens missing from the config are handled when enriching a TxInfo
+type UnknownTokenPolicy string
+
+// List of UnknownTokenPolicy
+const (
+	// UnknownTokenInclude keeps the destination with its raw contract, without AssetConfig since decimals are unknown
+	UnknownTokenInclude = UnknownTokenPolicy("include")
+	// UnknownTokenDrop removes the destination
+	UnknownTokenDrop = UnknownTokenPolicy("drop")
+	// UnknownTokenResolve fetches the token metadata on chain, falling back to UnknownTokenInclude on failure
+	// Resolved configs are Unverified, without Asset: contracts choose their symbol, e.g. a fake USDC
+	UnknownTokenResolve = UnknownTokenPolicy("resolve")
+)
+
 // TxHash is a tx hash or id
 type TxHash string
 
@@ -94,3 +141,32 @@ type Tx interface {
 	AddSignatures(...TxSignature) error
 	Serialize() ([]byte, error)
 }
+
+// TxMultisig is a Tx sent from an N-of-M multisig account, e.g. a Cosmos multisig account, a Bitcoin multisig script
+// or a Solana token multisig, see AsMultisig
+// Every signer signs the same Sighashes and adds its signatures, one per sighash, with AddSignaturesOf, in any order
+// The tx is signed once Threshold signers have signed
+type TxMultisig interface {
+	Tx
+	// Threshold is the number N of signers required, 0 for a tx of a single signer
+	Threshold() int
+	// Signers are the public keys of the signers that may sign
+	Signers() []PublicKey
+	AddSignaturesOf(signer PublicKey, signatures ...TxSignature) error
+	// SignedBy returns the public keys of the signers that have signed
+	SignedBy() []PublicKey
+}
+
+// AsMultisig returns tx as a TxMultisig if it's sent from a multisig account
+func AsMultisig(tx Tx) (TxMultisig, bool) {
+	multisigTx, ok := tx.(TxMultisig)
+	if !ok || multisigTx.Threshold() == 0 {
+		return nil, false
+	}
+	return multisigTx, true
+}
+
+// TxStreamSerializer is a Tx able to serialize directly into a writer, e.g. a pooled buffer
+type TxStreamSerializer interface {
+	SerializeTo(w io.Writer) error
+}
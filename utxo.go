@@ -0,0 +1,197 @@
+package crosschain
+
+// UTXOSigHash is the signature hashing of the txs of a Bitcoin-family chain
+type UTXOSigHash string
+
+// List of UTXOSigHash
+const (
+	// UTXOSigHashSegwit is the legacy sighash of Bitcoin, and BIP-143 for segwit inputs
+	UTXOSigHashSegwit = UTXOSigHash("segwit")
+	// UTXOSigHashForkID is BIP-143 for all inputs, with SIGHASH_FORKID, e.g. on Bitcoin Cash
+	UTXOSigHashForkID = UTXOSigHash("forkid")
+)
+
+// UTXOConfig is the config of the network of a Bitcoin-family chain: known chains (by native asset and network
+// kind) complete it, see GetUTXO, and other chains are supported by configuring it
+type UTXOConfig struct {
+	// PubKeyHashAddrID and ScriptHashAddrID are the versions of base58 P2PKH and P2SH addresses, e.g. 48 and 50 on
+	// Litecoin, PrivateKeyID the version of WIF private keys
+	PubKeyHashAddrID *uint8 `yaml:"pub_key_hash_addr_id"`
+	ScriptHashAddrID *uint8 `yaml:"script_hash_addr_id"`
+	PrivateKeyID     *uint8 `yaml:"private_key_id"`
+	// Bech32HRP is the human readable part of segwit addresses, e.g. ltc, empty for chains without segwit
+	Bech32HRP string `yaml:"bech32_hrp"`
+	// CashAddrPrefix is the prefix of cashaddr addresses, e.g. bitcoincash, for chains with cashaddr addresses
+	CashAddrPrefix string `yaml:"cashaddr_prefix"`
+	// HDPublicKeyID and HDPrivateKeyID are the hex versions of BIP-32 extended keys, e.g. 0488b21e for xpub
+	HDPublicKeyID  string `yaml:"hd_public_key_id"`
+	HDPrivateKeyID string `yaml:"hd_private_key_id"`
+	// SigHash is the signature hashing of txs, UTXOSigHashSegwit if not set
+	SigHash UTXOSigHash `yaml:"sighash"`
+	// NetMagic is the magic of the messages of the p2p network, little-endian as wire.BitcoinNet, e.g. 0xdbb6c0fb
+	// on Litecoin
+	NetMagic uint32 `yaml:"net_magic"`
+	// InputSize is the estimated size of an input in bytes, to estimate fees, DefaultUTXOInputSize if not set
+	InputSize int64 `yaml:"input_size"`
+	// DustThreshold is the smallest change output in sats, the default of the coin selection if not set
+	DustThreshold uint64 `yaml:"dust_threshold"`
+}
+
+// DefaultUTXOInputSize is the estimated size of an input of a Bitcoin tx in bytes
+const DefaultUTXOInputSize = 255
+
+// Versions of BIP-32 extended keys
+const (
+	HDPublicKeyIDXpub  = "0488b21e"
+	HDPrivateKeyIDXprv = "0488ade4"
+	HDPublicKeyIDTpub  = "043587cf"
+	HDPrivateKeyIDTprv = "04358394"
+)
+
+var bitcoinMainnet = UTXOConfig{
+	PubKeyHashAddrID: newUint8(0),
+	ScriptHashAddrID: newUint8(5),
+	PrivateKeyID:     newUint8(128),
+	Bech32HRP:        "bc",
+	HDPublicKeyID:    HDPublicKeyIDXpub,
+	HDPrivateKeyID:   HDPrivateKeyIDXprv,
+	NetMagic:         0xd9b4bef9,
+}
+
+var bitcoinTestnet = UTXOConfig{
+	PubKeyHashAddrID: newUint8(111),
+	ScriptHashAddrID: newUint8(196),
+	PrivateKeyID:     newUint8(239),
+	Bech32HRP:        "tb",
+	HDPublicKeyID:    HDPublicKeyIDTpub,
+	HDPrivateKeyID:   HDPrivateKeyIDTprv,
+	NetMagic:         0x0709110b,
+}
+
+var bitcoinRegtest = UTXOConfig{
+	PubKeyHashAddrID: newUint8(111),
+	ScriptHashAddrID: newUint8(196),
+	PrivateKeyID:     newUint8(239),
+	Bech32HRP:        "bcrt",
+	HDPublicKeyID:    HDPublicKeyIDTpub,
+	HDPrivateKeyID:   HDPrivateKeyIDTprv,
+	NetMagic:         0xdab5bffa,
+}
+
+// bitcoinCash is a Bitcoin network without segwit, with cashaddr addresses and SIGHASH_FORKID
+func bitcoinCash(bitcoin UTXOConfig, prefix string, magic uint32) UTXOConfig {
+	bitcoin.Bech32HRP = ""
+	bitcoin.CashAddrPrefix = prefix
+	bitcoin.SigHash = UTXOSigHashForkID
+	bitcoin.NetMagic = magic
+	bitcoin.InputSize = 300
+	return bitcoin
+}
+
+// KnownUTXOChains are the templates of Bitcoin-family chains by native asset and network kind: Mainnet, Testnet,
+// and Regtest for the other networks
+// Dogecoin has no segwit, its HRPs keep its addresses from colliding with the addresses of other chains, and its
+// dust threshold is 0.01 DOGE, the dust limit of Dogecoin Core 1.14
+var KnownUTXOChains = map[NativeAsset]map[Net]UTXOConfig{
+	BTC: {
+		Mainnet: bitcoinMainnet,
+		Testnet: bitcoinTestnet,
+		Regtest: bitcoinRegtest,
+	},
+	BCH: {
+		Mainnet: bitcoinCash(bitcoinMainnet, "bitcoincash", 0xe8f3e1e3),
+		Testnet: bitcoinCash(bitcoinTestnet, "bchtest", 0xf4f3e5f4),
+		Regtest: bitcoinCash(bitcoinRegtest, "bchreg", 0xfabfb5da),
+	},
+	DOGE: {
+		Mainnet: {PubKeyHashAddrID: newUint8(30), ScriptHashAddrID: newUint8(22), PrivateKeyID: newUint8(158), Bech32HRP: "doge",
+			HDPublicKeyID: "02facafd", HDPrivateKeyID: "02fac398", NetMagic: 0xc0c0c0c0, DustThreshold: 1_000_000},
+		Testnet: {PubKeyHashAddrID: newUint8(113), ScriptHashAddrID: newUint8(196), PrivateKeyID: newUint8(241), Bech32HRP: "doget",
+			HDPublicKeyID: HDPublicKeyIDTpub, HDPrivateKeyID: HDPrivateKeyIDTprv, NetMagic: 0xdcb7c1fc, DustThreshold: 1_000_000},
+		Regtest: {PubKeyHashAddrID: newUint8(111), ScriptHashAddrID: newUint8(196), PrivateKeyID: newUint8(239), Bech32HRP: "dogert",
+			HDPublicKeyID: HDPublicKeyIDTpub, HDPrivateKeyID: HDPrivateKeyIDTprv, NetMagic: 0xdab5bffa, DustThreshold: 1_000_000},
+	},
+	LTC: {
+		Mainnet: {PubKeyHashAddrID: newUint8(48), ScriptHashAddrID: newUint8(50), PrivateKeyID: newUint8(176), Bech32HRP: "ltc",
+			HDPublicKeyID: HDPublicKeyIDXpub, HDPrivateKeyID: HDPrivateKeyIDXprv, NetMagic: 0xdbb6c0fb},
+		Testnet: {PubKeyHashAddrID: newUint8(111), ScriptHashAddrID: newUint8(196), PrivateKeyID: newUint8(239), Bech32HRP: "tltc",
+			HDPublicKeyID: HDPublicKeyIDTpub, HDPrivateKeyID: HDPrivateKeyIDTprv, NetMagic: 0xf1c8d2fd},
+		Regtest: {PubKeyHashAddrID: newUint8(111), ScriptHashAddrID: newUint8(196), PrivateKeyID: newUint8(239), Bech32HRP: "rltc",
+			HDPublicKeyID: HDPublicKeyIDTpub, HDPrivateKeyID: HDPrivateKeyIDTprv, NetMagic: 0xdab5bffa},
+	},
+	DASH: {
+		Mainnet: {PubKeyHashAddrID: newUint8(76), ScriptHashAddrID: newUint8(16), PrivateKeyID: newUint8(204),
+			HDPublicKeyID: HDPublicKeyIDXpub, HDPrivateKeyID: HDPrivateKeyIDXprv, NetMagic: 0xbd6b0cbf},
+		Testnet: {PubKeyHashAddrID: newUint8(140), ScriptHashAddrID: newUint8(19), PrivateKeyID: newUint8(239),
+			HDPublicKeyID: HDPublicKeyIDTpub, HDPrivateKeyID: HDPrivateKeyIDTprv, NetMagic: 0xffcae2ce},
+		Regtest: {PubKeyHashAddrID: newUint8(140), ScriptHashAddrID: newUint8(19), PrivateKeyID: newUint8(239),
+			HDPublicKeyID: HDPublicKeyIDTpub, HDPrivateKeyID: HDPrivateKeyIDTprv, NetMagic: 0xdcb7c1fc},
+	},
+}
+
+// GetUTXO returns the UTXO config of a chain, completed with the template of its native asset on its network kind
+// The versions of addresses are nil for unknown chains without config
+func (asset *NativeAssetConfig) GetUTXO() UTXOConfig {
+	utxo := asset.UTXO
+	kind := asset.Net.Kind()
+	if kind != Mainnet && kind != Testnet {
+		kind = Regtest
+	}
+	known := KnownUTXOChains[asset.NativeAsset][kind]
+	if utxo.PubKeyHashAddrID == nil {
+		utxo.PubKeyHashAddrID = known.PubKeyHashAddrID
+	}
+	if utxo.ScriptHashAddrID == nil {
+		utxo.ScriptHashAddrID = known.ScriptHashAddrID
+	}
+	if utxo.PrivateKeyID == nil {
+		utxo.PrivateKeyID = known.PrivateKeyID
+	}
+	if utxo.Bech32HRP == "" {
+		utxo.Bech32HRP = known.Bech32HRP
+	}
+	if utxo.CashAddrPrefix == "" {
+		utxo.CashAddrPrefix = known.CashAddrPrefix
+	}
+	if utxo.HDPublicKeyID == "" {
+		utxo.HDPublicKeyID = known.HDPublicKeyID
+	}
+	if utxo.HDPrivateKeyID == "" {
+		utxo.HDPrivateKeyID = known.HDPrivateKeyID
+	}
+	if utxo.HDPublicKeyID == "" {
+		utxo.HDPublicKeyID = HDPublicKeyIDXpub
+		if kind != Mainnet {
+			utxo.HDPublicKeyID = HDPublicKeyIDTpub
+		}
+	}
+	if utxo.HDPrivateKeyID == "" {
+		utxo.HDPrivateKeyID = HDPrivateKeyIDXprv
+		if kind != Mainnet {
+			utxo.HDPrivateKeyID = HDPrivateKeyIDTprv
+		}
+	}
+	if utxo.SigHash == "" {
+		utxo.SigHash = known.SigHash
+	}
+	if utxo.SigHash == "" {
+		utxo.SigHash = UTXOSigHashSegwit
+	}
+	if utxo.NetMagic == 0 {
+		utxo.NetMagic = known.NetMagic
+	}
+	if utxo.InputSize == 0 {
+		utxo.InputSize = known.InputSize
+	}
+	if utxo.InputSize == 0 {
+		utxo.InputSize = DefaultUTXOInputSize
+	}
+	if utxo.DustThreshold == 0 {
+		utxo.DustThreshold = known.DustThreshold
+	}
+	return utxo
+}
+
+func newUint8(value uint8) *uint8 {
+	return &value
+}
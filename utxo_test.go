@@ -0,0 +1,48 @@
+package crosschain
+
+func (s *CrosschainTestSuite) TestGetUTXO() {
+	require := s.Require()
+
+	// known chains
+	asset := &NativeAssetConfig{NativeAsset: LTC, Net: "mainnet"}
+	utxo := asset.GetUTXO()
+	require.EqualValues(48, *utxo.PubKeyHashAddrID)
+	require.EqualValues(50, *utxo.ScriptHashAddrID)
+	require.Equal("ltc", utxo.Bech32HRP)
+	require.Equal(HDPublicKeyIDXpub, utxo.HDPublicKeyID)
+	require.Equal(UTXOSigHashSegwit, utxo.SigHash)
+	require.EqualValues(DefaultUTXOInputSize, utxo.InputSize)
+
+	// networks other than mainnet and testnet are regtests
+	asset = &NativeAssetConfig{NativeAsset: LTC, Net: "devnet"}
+	require.Equal("rltc", asset.GetUTXO().Bech32HRP)
+
+	// bitcoin cash
+	asset = &NativeAssetConfig{NativeAsset: BCH, Net: "testnet"}
+	utxo = asset.GetUTXO()
+	require.Equal("", utxo.Bech32HRP)
+	require.Equal("bchtest", utxo.CashAddrPrefix)
+	require.Equal(UTXOSigHashForkID, utxo.SigHash)
+	require.EqualValues(300, utxo.InputSize)
+
+	// the config overrides the template
+	asset = &NativeAssetConfig{NativeAsset: DASH, Net: "testnet", UTXO: UTXOConfig{PubKeyHashAddrID: newUint8(111), DustThreshold: 1000}}
+	utxo = asset.GetUTXO()
+	require.EqualValues(111, *utxo.PubKeyHashAddrID)
+	require.EqualValues(19, *utxo.ScriptHashAddrID)
+	require.Equal("", utxo.Bech32HRP)
+	require.Equal(HDPublicKeyIDTpub, utxo.HDPublicKeyID)
+	require.EqualValues(1000, utxo.DustThreshold)
+
+	// other chains need their versions
+	asset = &NativeAssetConfig{NativeAsset: "XYZ", Net: "mainnet"}
+	utxo = asset.GetUTXO()
+	require.Nil(utxo.PubKeyHashAddrID)
+	require.Nil(utxo.ScriptHashAddrID)
+	require.Equal(HDPublicKeyIDXpub, utxo.HDPublicKeyID)
+	require.Equal(HDPrivateKeyIDXprv, utxo.HDPrivateKeyID)
+
+	require.Equal(DriverBitcoin, DASH.Driver())
+	require.EqualValues(8, DASH.Decimals())
+	require.Equal("m/44'/5'/0'/0/0", DASH.DerivationPath())
+}
@@ -0,0 +1,163 @@
+package vault
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	vault "github.com/hashicorp/vault/api"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/custody"
+)
+
+// DefaultMount is the default path of the transit secrets engine
+const DefaultMount = "transit"
+
+// Logical is the part of the Vault API used by TransitSigner, e.g. (*vault.Client).Logical()
+type Logical interface {
+	Read(path string) (*vault.Secret, error)
+	Write(path string, data map[string]interface{}) (*vault.Secret, error)
+}
+
+var _ Logical = &vault.Logical{}
+
+// TransitSigner signs with keys held by the Vault transit secrets engine, keys never leave Vault
+// The private keys it's given are the names of transit keys, see ImportPrivateKey
+// secp256k1 keys require a transit engine supporting them, e.g. a plugin mounted as transit
+type TransitSigner struct {
+	Logical   Logical
+	Mount     string
+	Algorithm xc.SignatureType
+	// Recoverable appends the recovery id to secp256k1 signatures, as expected by EVM and Bitcoin txs
+	Recoverable bool
+
+	mu         sync.Mutex
+	publicKeys map[string]xc.PublicKey
+}
+
+var _ xc.Signer = &TransitSigner{}
+
+// NewTransitSigner creates a TransitSigner for the chain of asset, mount defaults to DefaultMount
+func NewTransitSigner(logical Logical, mount string, asset xc.ITask) (*TransitSigner, error) {
+	format, err := custody.NewSignatureFormat(asset)
+	if err != nil {
+		return nil, err
+	}
+	if mount == "" {
+		mount = DefaultMount
+	}
+	return &TransitSigner{
+		Logical:     logical,
+		Mount:       strings.Trim(mount, "/"),
+		Algorithm:   format.Algorithm,
+		Recoverable: format.Recoverable,
+		publicKeys:  map[string]xc.PublicKey{},
+	}, nil
+}
+
+// ImportPrivateKey returns a reference to the transit key named privateKey
+func (signer *TransitSigner) ImportPrivateKey(privateKey string) (xc.PrivateKey, error) {
+	name := strings.TrimSpace(privateKey)
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid transit key name: '%s'", privateKey)
+	}
+	return xc.PrivateKey(name), nil
+}
+
+// Sign signs data with the transit key referenced by privateKey
+// secp256k1 signatures are R || S with a low S, followed by the recovery id if Recoverable
+func (signer *TransitSigner) Sign(privateKey xc.PrivateKey, data xc.TxDataToSign) (xc.TxSignature, error) {
+	name := string(privateKey)
+	request := map[string]interface{}{
+		"input": base64.StdEncoding.EncodeToString(data),
+	}
+	if signer.Algorithm == xc.K256 {
+		// data is the sighash of the tx
+		request["prehashed"] = true
+		request["marshaling_algorithm"] = "asn1"
+	}
+	secret, err := signer.Logical.Write(signer.Mount+"/sign/"+name, request)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign with transit key '%s': %v", name, err)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("transit key '%s' not found", name)
+	}
+	encoded, _ := secret.Data["signature"].(string)
+	// vault:v<version>:<base64>
+	parts := strings.Split(encoded, ":")
+	raw, err := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+	if err != nil || len(parts) != 3 {
+		return nil, fmt.Errorf("invalid transit signature: '%s'", encoded)
+	}
+
+	if signer.Algorithm == xc.Ed255 {
+		if len(raw) != ed25519.SignatureSize {
+			return nil, fmt.Errorf("invalid ed25519 signature length: %d", len(raw))
+		}
+		return xc.TxSignature(raw), nil
+	}
+	signature, err := custody.ParseDERSignature(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !signer.Recoverable {
+		return xc.TxSignature(signature), nil
+	}
+	publicKey, err := signer.PublicKey(name)
+	if err != nil {
+		return nil, err
+	}
+	return custody.RecoverableSignature(signature, data, publicKey)
+}
+
+// PublicKey returns the public key of the latest version of a transit key, fetched once
+// secp256k1 public keys are compressed
+func (signer *TransitSigner) PublicKey(name string) (xc.PublicKey, error) {
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+	if publicKey, ok := signer.publicKeys[name]; ok {
+		return publicKey, nil
+	}
+	secret, err := signer.Logical.Read(signer.Mount + "/keys/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("could not read transit key '%s': %v", name, err)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("transit key '%s' not found", name)
+	}
+	keys, _ := secret.Data["keys"].(map[string]interface{})
+	version, _ := keys[fmt.Sprint(secret.Data["latest_version"])].(map[string]interface{})
+	encoded, _ := version["public_key"].(string)
+	if encoded == "" {
+		return nil, fmt.Errorf("transit key '%s' has no public key", name)
+	}
+
+	var publicKey xc.PublicKey
+	if signer.Algorithm == xc.Ed255 {
+		publicKey, err = base64.StdEncoding.DecodeString(encoded)
+		if err == nil && len(publicKey) != ed25519.PublicKeySize {
+			err = fmt.Errorf("invalid length %d", len(publicKey))
+		}
+	} else {
+		publicKey, err = parsePEMPublicKey(encoded)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of transit key '%s': %v", name, err)
+	}
+	signer.publicKeys[name] = publicKey
+	return publicKey, nil
+}
+
+// parsePEMPublicKey returns the compressed secp256k1 public key of a PEM encoded SubjectPublicKeyInfo
+func parsePEMPublicKey(encoded string) (xc.PublicKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("not PEM encoded")
+	}
+	return custody.ParsePublicKeyInfo(block.Bytes)
+}
@@ -0,0 +1,193 @@
+package vault
+
+import (
+	"crypto/ed25519"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	vault "github.com/hashicorp/vault/api"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+}
+
+func TestVaultTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+// mockTransit signs like the transit engine, with keys held in memory
+type mockTransit struct {
+	sign     func(input []byte) []byte
+	keys     map[string]interface{}
+	writes   []map[string]interface{}
+	reads    int
+	writeErr error
+}
+
+var _ Logical = &mockTransit{}
+
+func (m *mockTransit) Read(path string) (*vault.Secret, error) {
+	m.reads++
+	if path != "transit/keys/hot" {
+		return nil, nil
+	}
+	return &vault.Secret{Data: m.keys}, nil
+}
+
+func (m *mockTransit) Write(path string, data map[string]interface{}) (*vault.Secret, error) {
+	if m.writeErr != nil {
+		return nil, m.writeErr
+	}
+	if path != "transit/sign/hot" {
+		return nil, nil
+	}
+	m.writes = append(m.writes, data)
+	input, _ := base64.StdEncoding.DecodeString(data["input"].(string))
+	return &vault.Secret{Data: map[string]interface{}{
+		"signature": "vault:v2:" + base64.StdEncoding.EncodeToString(m.sign(input)),
+	}}, nil
+}
+
+func transitKeys(publicKey string) map[string]interface{} {
+	return map[string]interface{}{
+		"latest_version": 2,
+		"keys": map[string]interface{}{
+			"1": map[string]interface{}{"public_key": "rotated"},
+			"2": map[string]interface{}{"public_key": publicKey},
+		},
+	}
+}
+
+func secp256k1PEM(publicKey []byte) string {
+	info, _ := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1},
+			Parameters: asn1.RawValue{FullBytes: []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}},
+		},
+		PublicKey: asn1.BitString{Bytes: publicKey, BitLength: len(publicKey) * 8},
+	})
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: info}))
+}
+
+func (s *CrosschainTestSuite) TestTransitSignerSecp256k1() {
+	require := s.Require()
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	hash := crypto.Keccak256([]byte("tx"))
+	expected, _ := crypto.Sign(hash, key)
+
+	highS := false
+	transit := &mockTransit{
+		keys: transitKeys(secp256k1PEM(crypto.FromECDSAPub(&key.PublicKey))),
+		sign: func(input []byte) []byte {
+			signature, _ := crypto.Sign(input, key)
+			r := new(big.Int).SetBytes(signature[:32])
+			s := new(big.Int).SetBytes(signature[32:64])
+			if highS {
+				s = new(big.Int).Sub(crypto.S256().Params().N, s)
+			}
+			der, _ := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+			return der
+		},
+	}
+	signer, err := NewTransitSigner(transit, "", &xc.AssetConfig{Driver: string(xc.DriverEVM)})
+	require.NoError(err)
+	require.True(signer.Recoverable)
+	privateKey, err := signer.ImportPrivateKey("hot")
+	require.NoError(err)
+
+	signature, err := signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected), hex.EncodeToString(signature))
+	require.Equal(true, transit.writes[0]["prehashed"])
+
+	// high S is normalized
+	highS = true
+	signature, err = signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected), hex.EncodeToString(signature))
+
+	publicKey, err := signer.PublicKey("hot")
+	require.NoError(err)
+	require.Equal(crypto.CompressPubkey(&key.PublicKey), []byte(publicKey))
+	require.Equal(1, transit.reads)
+
+	// cosmos signatures have no recovery id
+	signer, err = NewTransitSigner(transit, "/transit/", &xc.AssetConfig{Driver: string(xc.DriverCosmos)})
+	require.NoError(err)
+	require.False(signer.Recoverable)
+	signature, err = signer.Sign(privateKey, hash)
+	require.NoError(err)
+	require.Equal(hex.EncodeToString(expected[:64]), hex.EncodeToString(signature))
+
+	// signed by another key
+	other, _ := crypto.GenerateKey()
+	signer, _ = NewTransitSigner(transit, "", &xc.AssetConfig{Driver: string(xc.DriverEVM)})
+	signer.publicKeys["hot"] = crypto.CompressPubkey(&other.PublicKey)
+	_, err = signer.Sign(privateKey, hash)
+	require.ErrorContains(err, "doesn't match the public key")
+}
+
+func (s *CrosschainTestSuite) TestTransitSignerEd25519() {
+	require := s.Require()
+	publicKey, key, _ := ed25519.GenerateKey(nil)
+	transit := &mockTransit{
+		keys: transitKeys(base64.StdEncoding.EncodeToString(publicKey)),
+		sign: func(input []byte) []byte {
+			return ed25519.Sign(key, input)
+		},
+	}
+	signer, err := NewTransitSigner(transit, "", &xc.AssetConfig{Driver: string(xc.DriverSolana)})
+	require.NoError(err)
+	require.Equal(xc.Ed255, signer.Algorithm)
+
+	signature, err := signer.Sign(xc.PrivateKey("hot"), []byte("message"))
+	require.NoError(err)
+	require.True(ed25519.Verify(publicKey, []byte("message"), signature))
+	require.Nil(transit.writes[0]["prehashed"])
+
+	fetched, err := signer.PublicKey("hot")
+	require.NoError(err)
+	require.Equal([]byte(publicKey), []byte(fetched))
+}
+
+func (s *CrosschainTestSuite) TestTransitSignerErrors() {
+	require := s.Require()
+	_, err := NewTransitSigner(&mockTransit{}, "", &xc.AssetConfig{Driver: "unknown"})
+	require.ErrorContains(err, "unsupported driver")
+
+	signer, _ := NewTransitSigner(&mockTransit{}, "", &xc.AssetConfig{Driver: string(xc.DriverSolana)})
+	_, err = signer.ImportPrivateKey("")
+	require.ErrorContains(err, "invalid transit key name")
+	_, err = signer.ImportPrivateKey("../sys")
+	require.ErrorContains(err, "invalid transit key name")
+
+	_, err = signer.Sign(xc.PrivateKey("cold"), []byte("message"))
+	require.EqualError(err, "transit key 'cold' not found")
+	_, err = signer.PublicKey("cold")
+	require.EqualError(err, "transit key 'cold' not found")
+
+	signer.Logical = &mockTransit{writeErr: errors.New("permission denied")}
+	_, err = signer.Sign(xc.PrivateKey("hot"), []byte("message"))
+	require.EqualError(err, "could not sign with transit key 'hot': permission denied")
+
+	signer.Logical = &mockTransit{sign: func(input []byte) []byte { return []byte{1, 2} }}
+	_, err = signer.Sign(xc.PrivateKey("hot"), []byte("message"))
+	require.EqualError(err, "invalid ed25519 signature length: 2")
+
+	signer.Logical = &mockTransit{keys: transitKeys("")}
+	_, err = signer.PublicKey("hot")
+	require.EqualError(err, "transit key 'hot' has no public key")
+}
@@ -0,0 +1,190 @@
+package watchonly
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/hdkeychain"
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/chain/bitcoin"
+	"github.com/jumpcrypto/crosschain/factory"
+)
+
+// BIP-44 chains of an account
+const (
+	ExternalChain = uint32(0)
+	InternalChain = uint32(1)
+)
+
+// DefaultGapLimit is the number of consecutive unused addresses after which scanning stops (BIP-44)
+const DefaultGapLimit = 20
+
+// Account is a watch-only account derived from an account-level extended public key,
+// e.g. xpub/ypub/zpub for the BTC family or the xpub at m/44'/60'/0' for EVM chains
+type Account struct {
+	Asset       xc.ITask
+	ExtendedKey *hdkeychain.ExtendedKey
+	GapLimit    int
+
+	factory     factory.FactoryContext
+	addressType xc.AddressType
+	descriptor  *Descriptor
+	getAddress  func(publicKey []byte) (xc.Address, error)
+}
+
+// typedAddressBuilder is an AddressBuilder supporting several address types for the same key
+type typedAddressBuilder interface {
+	GetAddressFromPublicKeyWithType(publicKeyBytes []byte, addressType xc.AddressType) (xc.Address, error)
+}
+
+// NewAccount creates a new watch-only Account given an extended public key
+func NewAccount(f factory.FactoryContext, asset xc.ITask, extendedKey string) (*Account, error) {
+	if xc.Driver(asset.GetDriver()).SignatureAlgorithm() != xc.K256 {
+		return nil, fmt.Errorf("extended public keys are not supported for %s", asset.ID())
+	}
+	key, err := hdkeychain.NewKeyFromString(extendedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended public key: %v", err)
+	}
+	if key.IsPrivate() {
+		return nil, errors.New("expected an extended public key, got a private key")
+	}
+	return newAccount(f, asset, key)
+}
+
+// NewDescriptorAccount creates a new watch-only Account of a UTXO chain given output descriptors of the same key,
+// e.g. a single wpkh(.../<0;1>/*) or the receive and change descriptors exported by Bitcoin Core
+// The script of the descriptors takes precedence over the version of the extended public key
+func NewDescriptorAccount(f factory.FactoryContext, asset xc.ITask, descriptors ...string) (*Account, error) {
+	if xc.Driver(asset.GetDriver()) != xc.DriverBitcoin {
+		return nil, fmt.Errorf("descriptors are not supported for %s", asset.ID())
+	}
+	parsed := make([]*Descriptor, len(descriptors))
+	for i, descriptor := range descriptors {
+		var err error
+		if parsed[i], err = ParseDescriptor(descriptor); err != nil {
+			return nil, err
+		}
+	}
+	descriptor, err := mergeDescriptors(parsed)
+	if err != nil {
+		return nil, err
+	}
+	account, err := newAccount(f, asset, descriptor.ExtendedKey)
+	if err != nil {
+		return nil, err
+	}
+	account.addressType = descriptor.AddressType
+	account.descriptor = descriptor
+	return account, nil
+}
+
+func newAccount(f factory.FactoryContext, asset xc.ITask, key *hdkeychain.ExtendedKey) (*Account, error) {
+	addressBuilder, err := f.NewAddressBuilder(asset)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{
+		Asset:       asset,
+		ExtendedKey: key,
+		GapLimit:    DefaultGapLimit,
+		factory:     f,
+		addressType: xc.AddressTypeDefault,
+		getAddress:  addressBuilder.GetAddressFromPublicKey,
+	}
+	if typedBuilder, ok := addressBuilder.(typedAddressBuilder); ok {
+		account.addressType = bitcoin.ExtendedKeyAddressType(key.Version())
+		account.getAddress = func(publicKey []byte) (xc.Address, error) {
+			return typedBuilder.GetAddressFromPublicKeyWithType(publicKey, account.addressType)
+		}
+	}
+	return account, nil
+}
+
+// AddressType returns the type of addresses derived by the Account
+func (account *Account) AddressType() xc.AddressType {
+	return account.addressType
+}
+
+// Descriptor returns the descriptor of the Account, nil if created from an extended public key
+func (account *Account) Descriptor() *Descriptor {
+	return account.descriptor
+}
+
+// Chains returns the chains used by the Account: change addresses are only used by UTXO chains,
+// and descriptor accounts use the chains of their descriptors
+func (account *Account) Chains() []uint32 {
+	if account.descriptor != nil {
+		return account.descriptor.Chains
+	}
+	if xc.Driver(account.Asset.GetDriver()) == xc.DriverBitcoin {
+		return []uint32{ExternalChain, InternalChain}
+	}
+	return []uint32{ExternalChain}
+}
+
+// DeriveAddress derives the address at chain/index of the Account
+func (account *Account) DeriveAddress(chain uint32, index uint32) (xc.Address, error) {
+	if chain >= hdkeychain.HardenedKeyStart || index >= hdkeychain.HardenedKeyStart {
+		return "", errors.New("hardened derivation requires a private key")
+	}
+	chainKey, err := account.ExtendedKey.Derive(chain)
+	if err != nil {
+		return "", err
+	}
+	key, err := chainKey.Derive(index)
+	if err != nil {
+		return "", err
+	}
+	publicKey, err := key.ECPubKey()
+	if err != nil {
+		return "", err
+	}
+	return account.getAddress(publicKey.SerializeCompressed())
+}
+
+// ChangeChain returns the chain of change addresses, the external chain if the Account has no internal chain
+func (account *Account) ChangeChain() uint32 {
+	for _, chain := range account.Chains() {
+		if chain == InternalChain {
+			return InternalChain
+		}
+	}
+	return ExternalChain
+}
+
+// DeriveChangeAddress derives the change address at index of the Account, e.g. the NextIndex of its change chain after a Scan
+func (account *Account) DeriveChangeAddress(index uint32) (xc.Address, error) {
+	return account.DeriveAddress(account.ChangeChain(), index)
+}
+
+// DeriveAddresses derives count addresses of chain starting at index
+func (account *Account) DeriveAddresses(chain uint32, index uint32, count int) ([]xc.Address, error) {
+	addresses := []xc.Address{}
+	for i := 0; i < count; i++ {
+		address, err := account.DeriveAddress(chain, index+uint32(i))
+		if err != nil {
+			return addresses, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// ChangeDetector returns a bitcoin.ChangeDetector recognizing the first count addresses of the change chain of the Account,
+// to classify outputs returning funds to the wallet when parsing its txs
+// Receive addresses aren't recognized, so that payments to the wallet stay destinations
+func (account *Account) ChangeDetector(count int) (bitcoin.ChangeDetector, error) {
+	addresses, err := account.DeriveAddresses(account.ChangeChain(), 0, count)
+	if err != nil {
+		return nil, err
+	}
+	owned := map[xc.Address]bool{}
+	for _, address := range addresses {
+		owned[address] = true
+	}
+	return func(address xc.Address) bool {
+		return owned[address]
+	}, nil
+}
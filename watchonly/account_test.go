@@ -0,0 +1,95 @@
+package watchonly
+
+import (
+	"context"
+	"testing"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type CrosschainTestSuite struct {
+	suite.Suite
+	Ctx     context.Context
+	Factory testutil.TestFactory
+}
+
+func (s *CrosschainTestSuite) SetupTest() {
+	s.Ctx = context.Background()
+	s.Factory = testutil.NewDefaultFactoryWithConfig(map[string]interface{}{})
+}
+
+func TestWatchOnlyTestSuite(t *testing.T) {
+	suite.Run(t, new(CrosschainTestSuite))
+}
+
+// account-level keys of the "abandon ... about" test mnemonic
+const (
+	xpubBIP44 = "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj"
+	ypubBIP49 = "ypub6Ww3ibxVfGzLrAH1PNcjyAWenMTbbAosGNB6VvmSEgytSER9azLDWCxoJwW7Ke7icmizBMXrzBx9979FfaHxHcrArf3zbeJJJUZPf663zsP"
+	zpubBIP84 = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+	xpubETH   = "xpub6DCoCpSuQZB2jawqnGMEPS63ePKWkwWPH4TU45Q7LPXWuNd8TMtVxRrgjtEshuqpK3mdhaWHPFsBngh5GFZaM6si3yZdUsT8ddYM3PwnATt"
+	xpubATOM  = "xpub6DGzViq8bmgMLYdVZ3xnLVEdKwzBnGdzzJZ4suG8kVb9TTLAbrwv8YdKBb8FWKdBNinaHKmBv7JpQvqBYx4rxch7WnHzNFzSVrMf8hQepTP"
+)
+
+var btcAsset = &xc.AssetConfig{Asset: "BTC", NativeAsset: xc.BTC, Driver: "bitcoin", Net: "mainnet"}
+
+func (s *CrosschainTestSuite) TestDeriveAddress() {
+	require := s.Require()
+	vectors := []struct {
+		asset   xc.ITask
+		key     string
+		chain   uint32
+		address xc.Address
+	}{
+		{btcAsset, xpubBIP44, ExternalChain, "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"},
+		{btcAsset, ypubBIP49, ExternalChain, "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf"},
+		{btcAsset, zpubBIP84, ExternalChain, "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{btcAsset, zpubBIP84, InternalChain, "bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el"},
+		{&xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, Driver: "evm"}, xpubETH, ExternalChain, "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"},
+		{&xc.AssetConfig{Asset: "ATOM", NativeAsset: xc.ATOM, Driver: "cosmos", ChainPrefix: "cosmos"}, xpubATOM, ExternalChain, "cosmos19rl4cm2hmr8afy4kldpxz3fka4jguq0auqdal4"},
+	}
+	for _, v := range vectors {
+		account, err := NewAccount(&s.Factory, v.asset, v.key)
+		require.Nil(err)
+		address, err := account.DeriveAddress(v.chain, 0)
+		require.Nil(err)
+		require.Equal(v.address, address)
+	}
+
+	account, _ := NewAccount(&s.Factory, btcAsset, zpubBIP84)
+	require.Equal(xc.AddressTypeP2WPKH, account.AddressType())
+	require.Equal([]uint32{ExternalChain, InternalChain}, account.Chains())
+	addresses, err := account.DeriveAddresses(ExternalChain, 0, 3)
+	require.Nil(err)
+	require.Len(addresses, 3)
+	require.Equal(xc.Address("bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"), addresses[0])
+
+	_, err = account.DeriveAddress(ExternalChain, 1<<31)
+	require.ErrorContains(err, "hardened derivation")
+}
+
+func (s *CrosschainTestSuite) TestChangeDetector() {
+	require := s.Require()
+	account, _ := NewAccount(&s.Factory, btcAsset, zpubBIP84)
+	detector, err := account.ChangeDetector(5)
+	require.Nil(err)
+	require.True(detector("bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el"))
+	// receive addresses are paid by third parties: not change
+	require.False(detector("bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"))
+	require.False(detector("1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA"))
+}
+
+func (s *CrosschainTestSuite) TestNewAccountErr() {
+	require := s.Require()
+	_, err := NewAccount(&s.Factory, btcAsset, "xpub")
+	require.ErrorContains(err, "invalid extended public key")
+
+	// private keys are rejected
+	_, err = NewAccount(&s.Factory, btcAsset, "xprv9s21ZrQH143K3GJpoapnV8SFfukcVBSfeCficPSGfubmSFDxo1kuHnLisriDvSnRRuL2Qrg5ggqHKNVpxR86QEC8w35uxmGoggxtQTPvfUu")
+	require.ErrorContains(err, "got a private key")
+
+	_, err = NewAccount(&s.Factory, &xc.AssetConfig{Asset: "SOL", NativeAsset: xc.SOL, Driver: "solana"}, xpubBIP44)
+	require.ErrorContains(err, "not supported")
+}
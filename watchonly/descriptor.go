@@ -0,0 +1,227 @@
+package watchonly
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcutil/hdkeychain"
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// Character sets of descriptor checksums (BIP-380)
+const (
+	descriptorInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// Descriptor is a ranged output descriptor (BIP-380) defining a watched wallet, as exported by Bitcoin Core, e.g.
+// wpkh([d34db33f/84'/0'/0']xpub.../<0;1>/*)
+// Supported scripts are pkh, wpkh, sh(wpkh) and tr without script tree
+type Descriptor struct {
+	AddressType xc.AddressType
+	// Origin is the optional key origin without brackets, e.g. d34db33f/84'/0'/0'
+	Origin string
+	// ExtendedKey is the key the Chains are derived from
+	ExtendedKey *hdkeychain.ExtendedKey
+	// Chains are the derivation steps before the wildcard, e.g. 0 for /0/*, 0 and 1 for /<0;1>/* (BIP-389)
+	Chains []uint32
+}
+
+var descriptorScripts = []struct {
+	prefix      string
+	suffix      string
+	addressType xc.AddressType
+}{
+	{"sh(wpkh(", "))", xc.AddressTypeP2SH},
+	{"wpkh(", ")", xc.AddressTypeP2WPKH},
+	{"pkh(", ")", xc.AddressTypeP2PKH},
+	{"tr(", ")", xc.AddressTypeP2TR},
+}
+
+// ParseDescriptor parses a ranged descriptor, verifying its checksum if present
+func ParseDescriptor(descriptor string) (*Descriptor, error) {
+	descriptor = strings.TrimSpace(descriptor)
+	if i := strings.LastIndex(descriptor, "#"); i >= 0 {
+		checksum, err := DescriptorChecksum(descriptor[:i])
+		if err != nil {
+			return nil, err
+		}
+		if checksum != descriptor[i+1:] {
+			return nil, fmt.Errorf("invalid descriptor checksum '%s', expected '%s'", descriptor[i+1:], checksum)
+		}
+		descriptor = descriptor[:i]
+	}
+
+	parsed := &Descriptor{}
+	keyExpr := ""
+	for _, script := range descriptorScripts {
+		if strings.HasPrefix(descriptor, script.prefix) && strings.HasSuffix(descriptor, script.suffix) {
+			parsed.AddressType = script.addressType
+			keyExpr = strings.TrimSuffix(strings.TrimPrefix(descriptor, script.prefix), script.suffix)
+			break
+		}
+	}
+	if keyExpr == "" {
+		return nil, fmt.Errorf("unsupported descriptor: '%s'", descriptor)
+	}
+	if strings.ContainsAny(keyExpr, "(),") {
+		return nil, fmt.Errorf("unsupported descriptor: '%s'", descriptor)
+	}
+
+	if strings.HasPrefix(keyExpr, "[") {
+		end := strings.Index(keyExpr, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid key origin: '%s'", keyExpr)
+		}
+		parsed.Origin = keyExpr[1:end]
+		keyExpr = keyExpr[end+1:]
+	}
+	steps := strings.Split(keyExpr, "/")
+	key, err := hdkeychain.NewKeyFromString(steps[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended public key: %v", err)
+	}
+	if key.IsPrivate() {
+		return nil, errors.New("expected an extended public key, got a private key")
+	}
+	// key/<steps>/<chains>/*
+	if len(steps) < 3 || steps[len(steps)-1] != "*" {
+		return nil, fmt.Errorf("descriptor must derive addresses as .../<chain>/*: '%s'", keyExpr)
+	}
+	for _, step := range steps[1 : len(steps)-2] {
+		index, err := parseDescriptorStep(step)
+		if err != nil {
+			return nil, err
+		}
+		if key, err = key.Derive(index); err != nil {
+			return nil, err
+		}
+	}
+	parsed.ExtendedKey = key
+
+	chains := steps[len(steps)-2]
+	if strings.HasPrefix(chains, "<") && strings.HasSuffix(chains, ">") {
+		for _, step := range strings.Split(chains[1:len(chains)-1], ";") {
+			chain, err := parseDescriptorStep(step)
+			if err != nil {
+				return nil, err
+			}
+			parsed.Chains = append(parsed.Chains, chain)
+		}
+	} else {
+		chain, err := parseDescriptorStep(chains)
+		if err != nil {
+			return nil, err
+		}
+		parsed.Chains = []uint32{chain}
+	}
+	return parsed, nil
+}
+
+// unhardened steps only, watched wallets have no private key
+func parseDescriptorStep(step string) (uint32, error) {
+	if strings.HasSuffix(step, "'") || strings.HasSuffix(step, "h") {
+		return 0, fmt.Errorf("hardened derivation requires a private key: '%s'", step)
+	}
+	index, err := strconv.ParseUint(step, 10, 32)
+	if err != nil || index >= hdkeychain.HardenedKeyStart {
+		return 0, fmt.Errorf("invalid derivation step: '%s'", step)
+	}
+	return uint32(index), nil
+}
+
+// String returns the descriptor with its checksum
+func (descriptor *Descriptor) String() string {
+	key := descriptor.ExtendedKey.String()
+	if descriptor.Origin != "" {
+		key = "[" + descriptor.Origin + "]" + key
+	}
+	chains := make([]string, len(descriptor.Chains))
+	for i, chain := range descriptor.Chains {
+		chains[i] = strconv.FormatUint(uint64(chain), 10)
+	}
+	if len(chains) == 1 {
+		key += "/" + chains[0] + "/*"
+	} else {
+		key += "/<" + strings.Join(chains, ";") + ">/*"
+	}
+	formatted := ""
+	for _, script := range descriptorScripts {
+		if script.addressType == descriptor.AddressType {
+			formatted = script.prefix + key + script.suffix
+			break
+		}
+	}
+	// the charset only rejects characters not found in keys
+	checksum, _ := DescriptorChecksum(formatted)
+	return formatted + "#" + checksum
+}
+
+// mergeDescriptors merges descriptors of the same key, e.g. the receive and change descriptors exported by Bitcoin Core
+func mergeDescriptors(descriptors []*Descriptor) (*Descriptor, error) {
+	if len(descriptors) == 0 {
+		return nil, errors.New("no descriptor")
+	}
+	merged := *descriptors[0]
+	seen := map[uint32]bool{}
+	merged.Chains = []uint32{}
+	for _, descriptor := range descriptors {
+		if descriptor.AddressType != merged.AddressType || descriptor.ExtendedKey.String() != merged.ExtendedKey.String() {
+			return nil, errors.New("descriptors must have the same script and key")
+		}
+		for _, chain := range descriptor.Chains {
+			if !seen[chain] {
+				seen[chain] = true
+				merged.Chains = append(merged.Chains, chain)
+			}
+		}
+	}
+	sort.Slice(merged.Chains, func(i, j int) bool { return merged.Chains[i] < merged.Chains[j] })
+	return &merged, nil
+}
+
+// DescriptorChecksum returns the checksum of a descriptor without checksum (BIP-380)
+func DescriptorChecksum(descriptor string) (string, error) {
+	c := uint64(1)
+	class, classCount := 0, 0
+	for _, ch := range descriptor {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos < 0 {
+			return "", fmt.Errorf("invalid descriptor character: '%c'", ch)
+		}
+		c = descriptorPolymod(c, pos&31)
+		class = class*3 + (pos >> 5)
+		classCount++
+		if classCount == 3 {
+			c = descriptorPolymod(c, class)
+			class, classCount = 0, 0
+		}
+	}
+	if classCount > 0 {
+		c = descriptorPolymod(c, class)
+	}
+	for i := 0; i < 8; i++ {
+		c = descriptorPolymod(c, 0)
+	}
+	c ^= 1
+	checksum := make([]byte, 8)
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*(7-i)))&31]
+	}
+	return string(checksum), nil
+}
+
+func descriptorPolymod(c uint64, value int) uint64 {
+	generator := []uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+	top := c >> 35
+	c = (c&0x7ffffffff)<<5 ^ uint64(value)
+	for i := 0; i < 5; i++ {
+		if (top>>uint(i))&1 == 1 {
+			c ^= generator[i]
+		}
+	}
+	return c
+}
@@ -0,0 +1,173 @@
+package watchonly
+
+import (
+	"strings"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+)
+
+// account-level keys of the "abandon ... about" test mnemonic, as used in descriptors
+const (
+	xpubBIP49 = "xpub6C6nQwHaWbSrzs5tZ1q7m5R9cPK9eYpNMFesiXsYrgc1P8bvLLAet9JfHjYXKjToD8cBRswJXXbbFpXgwsswVPAZzKMa1jUp2kVkGVUaJa7"
+	xpubBIP84 = "xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V"
+	xpubBIP86 = "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ"
+)
+
+func (s *CrosschainTestSuite) TestDescriptorChecksum() {
+	require := s.Require()
+	// BIP-380
+	checksum, err := DescriptorChecksum("raw(deadbeef)")
+	require.NoError(err)
+	require.Equal("89f8spxm", checksum)
+
+	_, err = DescriptorChecksum("raw(deadbeef)é")
+	require.ErrorContains(err, "invalid descriptor character")
+}
+
+func (s *CrosschainTestSuite) TestParseDescriptor() {
+	require := s.Require()
+	descriptor, err := ParseDescriptor("wpkh([73c5da0a/84'/0'/0']" + xpubBIP84 + "/<0;1>/*)")
+	require.NoError(err)
+	require.Equal(xc.AddressTypeP2WPKH, descriptor.AddressType)
+	require.Equal("73c5da0a/84'/0'/0'", descriptor.Origin)
+	require.Equal(xpubBIP84, descriptor.ExtendedKey.String())
+	require.Equal([]uint32{0, 1}, descriptor.Chains)
+
+	// round trip with checksum
+	formatted := descriptor.String()
+	require.Contains(formatted, "#")
+	reparsed, err := ParseDescriptor(formatted)
+	require.NoError(err)
+	require.Equal(descriptor, reparsed)
+	_, err = ParseDescriptor(formatted[:len(formatted)-1] + "x")
+	require.ErrorContains(err, "invalid descriptor checksum")
+
+	descriptor, err = ParseDescriptor("sh(wpkh(" + xpubBIP49 + "/1/*))")
+	require.NoError(err)
+	require.Equal(xc.AddressTypeP2SH, descriptor.AddressType)
+	require.Equal([]uint32{1}, descriptor.Chains)
+	require.Equal("", descriptor.Origin)
+
+	// fixed steps are derived
+	descriptor, err = ParseDescriptor("tr(" + xpubBIP86 + "/7/0/*)")
+	require.NoError(err)
+	require.Equal(xc.AddressTypeP2TR, descriptor.AddressType)
+	require.NotEqual(xpubBIP86, descriptor.ExtendedKey.String())
+	require.Equal([]uint32{0}, descriptor.Chains)
+
+	vectors := []struct {
+		descriptor string
+		err        string
+	}{
+		{"wsh(multi(1," + xpubBIP84 + "/0/*))", "unsupported descriptor"},
+		{"tr(" + xpubBIP86 + "/0/*,pk(" + xpubBIP84 + "/0/*))", "unsupported descriptor"},
+		{"wpkh(" + xpubBIP84 + "/0/1)", "must derive addresses"},
+		{"wpkh(" + xpubBIP84 + "/*)", "must derive addresses"},
+		{"wpkh(" + xpubBIP84 + "/0h/*)", "hardened derivation"},
+		{"wpkh(" + xpubBIP84 + "/<0;1'>/*)", "hardened derivation"},
+		{"wpkh(" + xpubBIP84 + "/x/*)", "invalid derivation step"},
+		{"wpkh([73c5da0a" + xpubBIP84 + "/0/*)", "invalid key origin"},
+		{"wpkh(xpub/0/*)", "invalid extended public key"},
+		{"wpkh(xprv9s21ZrQH143K3GJpoapnV8SFfukcVBSfeCficPSGfubmSFDxo1kuHnLisriDvSnRRuL2Qrg5ggqHKNVpxR86QEC8w35uxmGoggxtQTPvfUu/0/*)", "got a private key"},
+	}
+	for _, v := range vectors {
+		_, err := ParseDescriptor(v.descriptor)
+		require.ErrorContains(err, v.err, v.descriptor)
+	}
+}
+
+func (s *CrosschainTestSuite) TestDescriptorAccount() {
+	require := s.Require()
+	vectors := []struct {
+		descriptors []string
+		addressType xc.AddressType
+		receive     xc.Address
+		change      xc.Address
+	}{
+		{
+			[]string{"wpkh([73c5da0a/84'/0'/0']" + xpubBIP84 + "/<0;1>/*)"},
+			xc.AddressTypeP2WPKH,
+			"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+			"bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el",
+		},
+		{
+			// receive and change descriptors exported separately
+			[]string{"sh(wpkh(" + xpubBIP49 + "/0/*))", "sh(wpkh(" + xpubBIP49 + "/1/*))"},
+			xc.AddressTypeP2SH,
+			"37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf",
+			"34K56kSjgUCUSD8GTtuF7c9Zzwokbs6uZ7",
+		},
+		{
+			// BIP-86
+			[]string{"tr(" + xpubBIP86 + "/<0;1>/*)"},
+			xc.AddressTypeP2TR,
+			"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+			"bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7",
+		},
+	}
+	for _, v := range vectors {
+		account, err := NewDescriptorAccount(&s.Factory, btcAsset, v.descriptors...)
+		require.NoError(err)
+		require.Equal(v.addressType, account.AddressType())
+		require.Equal([]uint32{ExternalChain, InternalChain}, account.Chains())
+		require.Equal(InternalChain, account.ChangeChain())
+		address, err := account.DeriveAddress(ExternalChain, 0)
+		require.NoError(err)
+		require.Equal(v.receive, address)
+		address, err = account.DeriveChangeAddress(0)
+		require.NoError(err)
+		require.Equal(v.change, address)
+
+		detector, err := account.ChangeDetector(1)
+		require.NoError(err)
+		require.True(detector(v.change))
+	}
+
+	// without change descriptor, change goes to the receive chain
+	account, err := NewDescriptorAccount(&s.Factory, btcAsset, "wpkh("+xpubBIP84+"/0/*)")
+	require.NoError(err)
+	require.Equal([]uint32{ExternalChain}, account.Chains())
+	require.Equal(ExternalChain, account.ChangeChain())
+	address, err := account.DeriveChangeAddress(0)
+	require.NoError(err)
+	require.Equal(xc.Address("bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"), address)
+	require.True(strings.HasPrefix(account.Descriptor().String(), "wpkh("+xpubBIP84+"/0/*)#"))
+
+	// xpub accounts have no descriptor
+	account, _ = NewAccount(&s.Factory, btcAsset, zpubBIP84)
+	require.Nil(account.Descriptor())
+}
+
+func (s *CrosschainTestSuite) TestDescriptorAccountErr() {
+	require := s.Require()
+	_, err := NewDescriptorAccount(&s.Factory, btcAsset)
+	require.ErrorContains(err, "no descriptor")
+	_, err = NewDescriptorAccount(&s.Factory, btcAsset, "wpkh("+xpubBIP84+"/0/*)", "sh(wpkh("+xpubBIP84+"/1/*))")
+	require.ErrorContains(err, "same script and key")
+	_, err = NewDescriptorAccount(&s.Factory, btcAsset, "wpkh("+xpubBIP84+"/0/*)", "wpkh("+xpubBIP49+"/1/*)")
+	require.ErrorContains(err, "same script and key")
+	_, err = NewDescriptorAccount(&s.Factory, btcAsset, "wpkh(xpub/0/*)")
+	require.ErrorContains(err, "invalid extended public key")
+	_, err = NewDescriptorAccount(&s.Factory, &xc.AssetConfig{Asset: "ETH", NativeAsset: xc.ETH, Driver: "evm"}, "wpkh("+xpubBIP84+"/0/*)")
+	require.ErrorContains(err, "not supported")
+}
+
+func (s *CrosschainTestSuite) TestDescriptorAccountScan() {
+	require := s.Require()
+	account, _ := NewDescriptorAccount(&s.Factory, btcAsset, "tr("+xpubBIP86+"/<0;1>/*)")
+	account.GapLimit = 2
+	client := &testutil.MockedClient{}
+	client.On("FetchBalance", mock.Anything, xc.Address("bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr")).Return(xc.NewAmountBlockchainFromUint64(1000), nil)
+	client.On("FetchBalance", mock.Anything, mock.Anything).Return(xc.NewAmountBlockchainFromUint64(0), nil)
+	s.Factory.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		return client, nil
+	}
+
+	result, err := account.Scan(s.Ctx)
+	require.NoError(err)
+	require.Equal("1000", result.Total.String())
+	require.Equal(map[uint32]uint32{ExternalChain: 1, InternalChain: 0}, result.NextIndex)
+	client.AssertNumberOfCalls(s.T(), "FetchBalance", 3+2)
+}
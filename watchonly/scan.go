@@ -0,0 +1,72 @@
+package watchonly
+
+import (
+	"context"
+	"fmt"
+
+	xc "github.com/jumpcrypto/crosschain"
+)
+
+// AddressBalance is the balance of a derived address
+type AddressBalance struct {
+	Address xc.Address          `json:"address"`
+	Chain   uint32              `json:"chain"`
+	Index   uint32              `json:"index"`
+	Balance xc.AmountBlockchain `json:"balance"`
+}
+
+// ScanResult is the result of a gap-limit scan of an Account
+type ScanResult struct {
+	// Balances of funded addresses
+	Balances []AddressBalance `json:"balances"`
+	// Total balance of the Account
+	Total xc.AmountBlockchain `json:"total"`
+	// NextIndex is the first index after the last funded address, per chain
+	NextIndex map[uint32]uint32 `json:"next_index"`
+}
+
+// Scan fetches balances of derived addresses until GapLimit consecutive addresses are empty
+// Without tx history, an address is considered used when it has a positive balance
+func (account *Account) Scan(ctx context.Context) (*ScanResult, error) {
+	client, err := account.factory.NewClient(account.Asset)
+	if err != nil {
+		return nil, err
+	}
+	balanceClient, ok := client.(xc.ClientBalance)
+	if !ok {
+		return nil, fmt.Errorf("balances are not supported for %s", account.Asset.ID())
+	}
+
+	result := &ScanResult{
+		Balances:  []AddressBalance{},
+		Total:     xc.NewAmountBlockchainFromUint64(0),
+		NextIndex: map[uint32]uint32{},
+	}
+	for _, chain := range account.Chains() {
+		result.NextIndex[chain] = 0
+		for index, gap := uint32(0), 0; gap < account.GapLimit; index++ {
+			address, err := account.DeriveAddress(chain, index)
+			if err != nil {
+				return result, err
+			}
+			balance, err := balanceClient.FetchBalance(ctx, address)
+			if err != nil {
+				return result, fmt.Errorf("failed to fetch balance of %s: %v", address, err)
+			}
+			if balance.Sign() <= 0 {
+				gap++
+				continue
+			}
+			gap = 0
+			result.Balances = append(result.Balances, AddressBalance{
+				Address: address,
+				Chain:   chain,
+				Index:   index,
+				Balance: balance,
+			})
+			result.Total = result.Total.Add(&balance)
+			result.NextIndex[chain] = index + 1
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package watchonly
+
+import (
+	"errors"
+
+	xc "github.com/jumpcrypto/crosschain"
+	"github.com/jumpcrypto/crosschain/testutil"
+	"github.com/stretchr/testify/mock"
+)
+
+func (s *CrosschainTestSuite) TestScan() {
+	require := s.Require()
+	account, _ := NewAccount(&s.Factory, btcAsset, zpubBIP84)
+	account.GapLimit = 3
+	funded := map[xc.Address]uint64{}
+	// receive addresses 0 and 2, change address 1
+	receive, _ := account.DeriveAddresses(ExternalChain, 0, 3)
+	change, _ := account.DeriveAddresses(InternalChain, 0, 2)
+	funded[receive[0]] = 1000
+	funded[receive[2]] = 500
+	funded[change[1]] = 20
+
+	client := &testutil.MockedClient{}
+	for i := uint32(0); i < 8; i++ {
+		for _, chain := range []uint32{ExternalChain, InternalChain} {
+			address, _ := account.DeriveAddress(chain, i)
+			client.On("FetchBalance", mock.Anything, address).Return(xc.NewAmountBlockchainFromUint64(funded[address]), nil)
+		}
+	}
+	s.Factory.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		return client, nil
+	}
+
+	result, err := account.Scan(s.Ctx)
+	require.Nil(err)
+	require.Equal("1520", result.Total.String())
+	require.Len(result.Balances, 3)
+	require.Equal(receive[2], result.Balances[1].Address)
+	require.Equal(uint32(2), result.Balances[1].Index)
+	require.Equal(InternalChain, result.Balances[2].Chain)
+	require.Equal(map[uint32]uint32{ExternalChain: 3, InternalChain: 2}, result.NextIndex)
+	// gap limit of 3 after the last funded address
+	client.AssertNumberOfCalls(s.T(), "FetchBalance", 6+5)
+}
+
+func (s *CrosschainTestSuite) TestScanErr() {
+	require := s.Require()
+	account, _ := NewAccount(&s.Factory, btcAsset, zpubBIP84)
+	client := &testutil.MockedClient{}
+	client.On("FetchBalance", mock.Anything, mock.Anything).Return(xc.NewAmountBlockchainFromUint64(0), errors.New("rpc error"))
+	s.Factory.NewClientFunc = func(asset xc.ITask) (xc.Client, error) {
+		return client, nil
+	}
+	_, err := account.Scan(s.Ctx)
+	require.ErrorContains(err, "rpc error")
+}
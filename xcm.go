@@ -0,0 +1,46 @@
+package crosschain
+
+import "context"
+
+// XCMTransferType is the way an XCM transfer moves assets to the destination chain
+type XCMTransferType string
+
+const (
+	// XCMReserve transfers keep the assets on the source chain, the reserve, in the sovereign account of the
+	// destination, which mints derivatives of them
+	XCMReserve = XCMTransferType("reserve")
+	// XCMTeleport transfers burn the assets on the source chain and mint them on the destination, only between chains
+	// trusting each other, e.g. a relay chain and its system parachains
+	XCMTeleport = XCMTransferType("teleport")
+)
+
+// XCMDestination is a parachain receiving XCM transfers of the native asset of its relay chain, see SubstrateConfig
+type XCMDestination struct {
+	// ParaID is the id of the parachain, e.g. 2000 for Acala
+	ParaID uint32 `yaml:"para_id"`
+	// Type of the transfers, reserve if not set
+	Type XCMTransferType `yaml:"type"`
+	// SS58Prefix is the network prefix of the addresses of the parachain
+	SS58Prefix *uint16 `yaml:"ss58_prefix"`
+	// URL is the Substrate RPC of the parachain, to track the arrival of transfers, see ClientXCM
+	URL string `yaml:"url"`
+	// CurrencyID is the hex SCALE CurrencyId of the transferred asset in the Tokens pallet of the parachain, e.g.
+	// 0x0002 for Token(DOT) on Acala
+	CurrencyID string `yaml:"currency_id"`
+}
+
+// TxXCMBuilder is a Builder of XCM transfers, from a relay chain to its parachains
+type TxXCMBuilder interface {
+	// NewXCMTransfer transfers amount of the native asset of the chain to the address to on the destination parachain
+	NewXCMTransfer(from Address, to Address, amount AmountBlockchain, destination NativeAsset, input TxInput) (Tx, error)
+}
+
+// ClientXCM is a specific Client tracking XCM transfers on their destination: the messages are executed by the
+// destination a few blocks after the transfer, with the fee of the execution deducted from the transferred amount
+type ClientXCM interface {
+	// FetchXCMBalance returns the balance of address on destination of the asset transferred there by XCM
+	FetchXCMBalance(ctx context.Context, address Address, destination NativeAsset) (AmountBlockchain, error)
+	// FetchXCMArrival returns the amount received by address on destination since its balance was before, zero until
+	// a transfer arrives
+	FetchXCMArrival(ctx context.Context, address Address, destination NativeAsset, before AmountBlockchain) (AmountBlockchain, error)
+}